@@ -5,13 +5,17 @@ import (
 	"fmt"
 
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/diagnostics"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 )
 
@@ -115,8 +119,9 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{string(org.RoleViewer)},
 	}
 
-	// when running oss or enterprise without a license all users should be able to query data sources
-	if !hs.License.FeatureEnabled("dspermissions.enforcement") {
+	// when running oss or enterprise without a license all users should be able to query data sources,
+	// unless the query permissions are enforced and viewers are granted access to data sources one by one
+	if !hs.License.FeatureEnabled("dspermissions.enforcement") && !hs.Cfg.DataSourceEnforceQueryPermissions {
 		datasourcesReaderRole.Grants = []string{string(org.RoleViewer)}
 	}
 
@@ -562,6 +567,19 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	dashboardsSecretsReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:dashboards.secrets:reader",
+			DisplayName: "Dashboard secrets reader",
+			Description: "Read the secure panel options of all dashboards.",
+			Group:       "Dashboards",
+			Permissions: []ac.Permission{
+				{Action: dashboards.ActionDashboardsSecretsRead, Scope: dashboards.ScopeDashboardsAll},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
 	featuremgmtReaderRole := ac.RoleRegistration{
 		Role: ac.RoleDTO{
 			Name:        "fixed:featuremgmt:reader",
@@ -588,14 +606,96 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	shortURLsSlugsCreatorRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:shorturls.slugs:creator",
+			DisplayName: "Short URL slug creator",
+			Description: "Create short URLs with a custom slug.",
+			Group:       "Short URLs",
+			Permissions: []ac.Permission{
+				{Action: shorturls.ActionSlugsCreate},
+			},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
+	playlistDevicesReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:playlists.devices:reader",
+			DisplayName: "Playlist device reader",
+			Description: "List the playlist devices and receive their playback commands.",
+			Group:       "Playlists",
+			Permissions: []ac.Permission{
+				{Action: playlist.ActionDevicesRead},
+			},
+		},
+		Grants: []string{string(org.RoleViewer)},
+	}
+
+	playlistDevicesWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:playlists.devices:writer",
+			DisplayName: "Playlist device writer",
+			Description: "Register and remove playlist devices.",
+			Group:       "Playlists",
+			Permissions: []ac.Permission{
+				{Action: playlist.ActionDevicesRead},
+				{Action: playlist.ActionDevicesWrite},
+			},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
+	playlistDevicesControllerRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:playlists.devices:controller",
+			DisplayName: "Playlist device controller",
+			Description: "Control the playlist playback of the playlist devices.",
+			Group:       "Playlists",
+			Permissions: []ac.Permission{
+				{Action: playlist.ActionDevicesRead},
+				{Action: playlist.ActionDevicesControl},
+			},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
+	auditLogReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:auditlog:reader",
+			DisplayName: "Audit log reader",
+			Description: "Search the admin actions recorded in the audit log of all organizations.",
+			Group:       "Audit log",
+			Permissions: []ac.Permission{
+				{Action: auditlog.ActionRead},
+			},
+		},
+		Grants: []string{ac.RoleGrafanaAdmin},
+	}
+
+	diagnosticsProfilerRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:diagnostics:profiler",
+			DisplayName: "Diagnostics profiler",
+			Description: "Capture CPU, heap, goroutine and mutex profiles of the server.",
+			Group:       "Diagnostics",
+			Permissions: []ac.Permission{
+				{Action: diagnostics.ActionProfilesCreate},
+			},
+		},
+		Grants: []string{ac.RoleGrafanaAdmin},
+	}
+
 	roles := []ac.RoleRegistration{provisioningWriterRole, datasourcesReaderRole, builtInDatasourceReader, datasourcesWriterRole,
 		datasourcesIdReaderRole, datasourcesCreatorRole, orgReaderRole, orgWriterRole,
 		orgMaintainerRole, teamsCreatorRole, teamsWriterRole, teamsReaderRole, datasourcesExplorerRole,
 		annotationsReaderRole, dashboardAnnotationsWriterRole, annotationsWriterRole,
 		dashboardsCreatorRole, dashboardsReaderRole, dashboardsWriterRole,
 		foldersCreatorRole, foldersReaderRole, foldersWriterRole, apikeyReaderRole, apikeyWriterRole,
-		publicDashboardsWriterRole, featuremgmtReaderRole, featuremgmtWriterRole, libraryPanelsCreatorRole,
-		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole}
+		publicDashboardsWriterRole, dashboardsSecretsReaderRole, featuremgmtReaderRole, featuremgmtWriterRole, libraryPanelsCreatorRole,
+		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole,
+		shortURLsSlugsCreatorRole, playlistDevicesReaderRole, playlistDevicesWriterRole, playlistDevicesControllerRole,
+		auditLogReaderRole, diagnosticsProfilerRole}
 
 	if hs.Features.IsEnabled(context.Background(), featuremgmt.FlagAnnotationPermissionUpdate) {
 		allAnnotationsReaderRole := ac.RoleRegistration{
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminGetCleanupTasks returns the schedule and the outcome of the last run of the registered clean up tasks.
+func (hs *HTTPServer) AdminGetCleanupTasks(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.cleanUpService.Tasks())
+}
+
+// AdminRunCleanupTask runs a clean up task now and returns its status once it completed.
+func (hs *HTTPServer) AdminRunCleanupTask(c *contextmodel.ReqContext) response.Response {
+	status, err := hs.cleanUpService.RunTask(c.Req.Context(), web.Params(c.Req)[":name"])
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/diagnostics"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type CaptureProfileCommand struct {
+	// Seconds is the duration of the CPU and mutex profiles, 30 by default.
+	Seconds int `json:"seconds"`
+	// Profiles to capture among cpu, heap, goroutine and mutex, all of them by default.
+	Profiles []diagnostics.Profile `json:"profiles"`
+}
+
+// AdminCaptureProfile captures profiles of the server and returns them in a gzipped tarball.
+func (hs *HTTPServer) AdminCaptureProfile(c *contextmodel.ReqContext) response.Response {
+	cmd := CaptureProfileCommand{}
+	if c.Req.ContentLength != 0 {
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+	}
+	if cmd.Seconds < 0 {
+		return response.Error(http.StatusBadRequest, "seconds can not be negative", nil)
+	}
+
+	bundle, err := hs.diagnosticsService.Capture(c.Req.Context(), diagnostics.CaptureOptions{
+		Duration: time.Duration(cmd.Seconds) * time.Second,
+		Profiles: cmd.Profiles,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, diagnostics.ErrDisabled):
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, diagnostics.ErrInvalidProfile):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, diagnostics.ErrCaptureInProgress):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		case errors.Is(err, diagnostics.ErrRateLimited):
+			return response.Error(http.StatusTooManyRequests, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to capture profiles", err)
+	}
+
+	hs.log.Info("Captured profiles", "profiles", cmd.Profiles, "seconds", cmd.Seconds, "user", c.SignedInUser.Login)
+	filename := fmt.Sprintf("grafana-profile-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	return response.Respond(http.StatusOK, bundle).
+		SetHeader("Content-Type", "application/tar+gzip").
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, filename))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/diagnostics"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAdminCaptureProfile(t *testing.T) {
+	type testCase struct {
+		desc         string
+		body         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should be able to capture profiles with the correct permission",
+			body:         `{"seconds": 1, "profiles": ["goroutine"]}`,
+			permissions:  []accesscontrol.Permission{{Action: diagnostics.ActionProfilesCreate}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to capture profiles without the correct permission",
+			body:         `{"seconds": 1, "profiles": ["goroutine"]}`,
+			permissions:  []accesscontrol.Permission{},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not be able to capture unknown profiles",
+			body:         `{"seconds": 1, "profiles": ["block"]}`,
+			permissions:  []accesscontrol.Permission{{Action: diagnostics.ActionProfilesCreate}},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			diagnosticsService, err := diagnostics.ProvideService(setting.NewCfg())
+			require.NoError(t, err)
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.log = log.New("test")
+				hs.diagnosticsService = diagnosticsService
+			})
+
+			req := server.NewRequest(http.MethodPost, "/api/admin/diagnostics/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, "application/tar+gzip", res.Header.Get("Content-Type"))
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+// AdminGetDrainStatus returns the progress of the drain of the server.
+func (hs *HTTPServer) AdminGetDrainStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.drainService.Status())
+}
+
+// AdminDrain starts draining the server before a restart and returns the progress of the drain.
+func (hs *HTTPServer) AdminDrain(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.drainService.Drain("Admin API request by "+c.SignedInUser.Login))
+}
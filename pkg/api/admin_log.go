@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultLogLevelDuration = 10 * time.Minute
+	maxLogLevelDuration     = 24 * time.Hour
+)
+
+type SetLogLevelsCommand struct {
+	// Filters are composed with the logger name and the level, for example tsdb.loki=debug.
+	Filters []string `json:"filters"`
+	// Duration after which the configured levels are restored, 10m by default.
+	Duration string `json:"duration"`
+}
+
+// AdminGetLogLevels returns the levels of the registered loggers.
+func (hs *HTTPServer) AdminGetLogLevels(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, log.GetLevels())
+}
+
+// AdminSetLogLevels changes the levels of loggers until the duration of the command is elapsed,
+// and returns the levels of the registered loggers.
+func (hs *HTTPServer) AdminSetLogLevels(c *contextmodel.ReqContext) response.Response {
+	cmd := SetLogLevelsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if len(cmd.Filters) == 0 {
+		return response.Error(http.StatusBadRequest, "at least one filter is required", nil)
+	}
+
+	duration := defaultLogLevelDuration
+	if cmd.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(cmd.Duration); err != nil {
+			return response.Error(http.StatusBadRequest, "invalid duration", err)
+		}
+	}
+	if duration > maxLogLevelDuration {
+		return response.Error(http.StatusBadRequest, "duration can not be longer than "+maxLogLevelDuration.String(), nil)
+	}
+
+	if err := log.SetLevels(cmd.Filters, duration); err != nil {
+		if errors.Is(err, log.ErrInvalidLevelFilter) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to change log levels", err)
+	}
+
+	hs.log.Info("Changed log levels", "filters", cmd.Filters, "duration", duration, "user", c.SignedInUser.Login)
+	return response.JSON(http.StatusOK, log.GetLevels())
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAdminSetLogLevels(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.log = log.New("test")
+	})
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin, IsGrafanaAdmin: true, Login: "admin"}
+
+	send := func(t *testing.T, body string, signedInUser *user.SignedInUser) *http.Response {
+		t.Helper()
+		req := webtest.RequestWithSignedInUser(server.NewRequest(http.MethodPut, "/api/admin/log/level", strings.NewReader(body)), signedInUser)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("requires a Grafana Admin", func(t *testing.T) {
+		res := send(t, `{"filters": ["tsdb.loki=debug"]}`, &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleAdmin})
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("rejects invalid filters and durations", func(t *testing.T) {
+		for _, body := range []string{
+			`{"filters": []}`,
+			`{"filters": ["tsdb.loki=verbose"]}`,
+			`{"filters": ["tsdb.loki=debug"], "duration": "forever"}`,
+			`{"filters": ["tsdb.loki=debug"], "duration": "48h"}`,
+		} {
+			res := send(t, body, admin)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		}
+	})
+
+	t.Run("changes the levels of loggers", func(t *testing.T) {
+		res := send(t, `{"filters": ["test.admin.log=debug"], "duration": "1m"}`, admin)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var levels []log.LoggerLevel
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&levels))
+		var found bool
+		for _, l := range levels {
+			if l.Name == "test.admin.log" {
+				found = true
+				require.Equal(t, "debug", l.Override.Level)
+			}
+		}
+		require.True(t, found)
+	})
+}
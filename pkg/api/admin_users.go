@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -60,6 +61,9 @@ func (hs *HTTPServer) AdminCreateUser(c *contextmodel.ReqContext) response.Respo
 	if len(cmd.Password) < 4 {
 		return response.Error(400, "Password is missing or too short", nil)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), &user.User{Login: cmd.Login, Email: cmd.Email}, cmd.Password); err != nil {
+		return response.Err(err)
+	}
 
 	usr, err := hs.userService.Create(c.Req.Context(), &cmd)
 	if err != nil {
@@ -74,6 +78,7 @@ func (hs *HTTPServer) AdminCreateUser(c *contextmodel.ReqContext) response.Respo
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create user", err)
 	}
 
+	hs.recordPassword(c, usr.ID, usr.Password, usr.Salt)
 	metrics.MApiAdminUserCreate.Inc()
 
 	result := user.AdminCreateUserResponse{
@@ -120,6 +125,9 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *contextmodel.ReqContext) respon
 	if err != nil {
 		return response.Error(500, "Could not read user from database", err)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), usr, form.Password); err != nil {
+		return response.Err(err)
+	}
 
 	passwordHashed, err := util.EncodePassword(form.Password, usr.Salt)
 	if err != nil {
@@ -134,6 +142,7 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *contextmodel.ReqContext) respon
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
 		return response.Error(500, "Failed to update user password", err)
 	}
+	hs.recordPassword(c, userID, passwordHashed, usr.Salt)
 
 	return response.Success("User password updated")
 }
@@ -181,6 +190,13 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 		return response.Error(500, "Failed to update user permissions", err)
 	}
 
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		Action:       auditlog.ActionUserGrafanaAdminUpdate,
+		ResourceType: auditlog.ResourceTypeUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		After:        map[string]any{"isGrafanaAdmin": form.IsGrafanaAdmin},
+	})
+
 	return response.Success("User permissions updated")
 }
 
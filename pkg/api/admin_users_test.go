@@ -16,12 +16,14 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	"github.com/grafana/grafana/pkg/services/auditlog/auditlogtest"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy/passwordpolicytest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -332,6 +334,7 @@ func Test_AdminUpdateUserPermissions(t *testing.T) {
 				authInfoService: authInfoService,
 				SocialService:   socialService,
 				userService:     usertest.NewUserServiceFake(),
+				auditLogService: auditlogtest.NewFakeService(),
 			}
 
 			sc := setupScenarioContext(t, "/api/admin/users/1/permissions")
@@ -359,6 +362,7 @@ func putAdminScenario(t *testing.T, desc string, url string, routePattern string
 			SQLStore:        sqlStore,
 			authInfoService: &authinfotest.FakeService{},
 			userService:     userSvc,
+			auditLogService: auditlogtest.NewFakeService(),
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -518,7 +522,8 @@ func adminDeleteUserScenario(t *testing.T, desc string, url string, routePattern
 func adminCreateUserScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.AdminCreateUserForm, svc *usertest.FakeUserService, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		hs := HTTPServer{
-			userService: svc,
+			userService:    svc,
+			passwordPolicy: passwordpolicytest.NewFakeService(),
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/comments"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /annotations/{annotation_id}/comments annotations getAnnotationComments
+//
+// Get the comments of an annotation.
+//
+// Returns the comments of the annotation as threads, the top level comments with their replies.
+//
+// Responses:
+// 200: getAnnotationCommentsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetAnnotationComments(c *contextmodel.ReqContext) response.Response {
+	annotation, resp := hs.annotationFromParams(c)
+	if resp != nil {
+		return resp
+	}
+
+	threads, err := hs.annotationComments.List(c.Req.Context(), c.SignedInUser.GetOrgID(), annotation.ID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get annotation comments", err)
+	}
+
+	setCommentAvatars(threads)
+
+	return response.JSON(http.StatusOK, threads)
+}
+
+// swagger:route POST /annotations/{annotation_id}/comments annotations postAnnotationComment
+//
+// Comment an annotation.
+//
+// Creates a comment, or a reply to a comment when parentId is set. Comments can be created by the
+// users that can edit the annotation. The members of the organization mentioned as @login in the
+// text are notified by email.
+//
+// Responses:
+// 200: postAnnotationCommentResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) PostAnnotationComment(c *contextmodel.ReqContext) response.Response {
+	cmd := comments.CreateCommentCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	annotation, resp := hs.annotationFromParams(c)
+	if resp != nil {
+		return resp
+	}
+
+	if !hs.Features.IsEnabled(c.Req.Context(), featuremgmt.FlagAnnotationPermissionUpdate) {
+		if canSave, err := hs.canSaveAnnotation(c, annotation); err != nil || !canSave {
+			return dashboardGuardianResponse(err)
+		}
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.AnnotationID = annotation.ID
+	cmd.UserID = c.SignedInUser.UserID
+	cmd.Link = annotationLink(annotation, cmd.OrgID)
+
+	comment, err := hs.annotationComments.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create annotation comment", err)
+	}
+
+	return response.JSON(http.StatusOK, comment)
+}
+
+// swagger:route DELETE /annotations/{annotation_id}/comments/{comment_id} annotations deleteAnnotationComment
+//
+// Delete a comment of an annotation.
+//
+// Deletes the comment and its replies. Comments can be deleted by their author, and by the users that
+// can edit the annotation.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteAnnotationComment(c *contextmodel.ReqContext) response.Response {
+	commentID, err := strconv.ParseInt(web.Params(c.Req)[":commentId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "commentId is invalid", err)
+	}
+
+	annotation, resp := hs.annotationFromParams(c)
+	if resp != nil {
+		return resp
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	comment, err := hs.annotationComments.Get(c.Req.Context(), orgID, annotation.ID, commentID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get annotation comment", err)
+	}
+
+	if comment.UserID != c.SignedInUser.UserID {
+		if canSave, err := hs.canSaveAnnotation(c, annotation); err != nil || !canSave {
+			return dashboardGuardianResponse(err)
+		}
+	}
+
+	if err := hs.annotationComments.Delete(c.Req.Context(), orgID, annotation.ID, commentID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete annotation comment", err)
+	}
+
+	return response.Success("Comment deleted")
+}
+
+func (hs *HTTPServer) annotationFromParams(c *contextmodel.ReqContext) (*annotations.ItemDTO, response.Response) {
+	annotationID, err := strconv.ParseInt(web.Params(c.Req)[":annotationId"], 10, 64)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "annotationId is invalid", err)
+	}
+
+	return findAnnotationByID(c.Req.Context(), hs.annotationsRepo, annotationID, c.SignedInUser)
+}
+
+// annotationLink returns the URL of the dashboard, or the panel, of the annotation. Organization
+// annotations link to the home page.
+func annotationLink(annotation *annotations.ItemDTO, orgID int64) string {
+	if annotation.DashboardUID == nil || *annotation.DashboardUID == "" {
+		return setting.ToAbsUrl("")
+	}
+
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(orgID, 10))
+	if annotation.PanelID != 0 {
+		params.Set("viewPanel", strconv.FormatInt(annotation.PanelID, 10))
+	}
+	return setting.ToAbsUrl(fmt.Sprintf("d/%s?%s", *annotation.DashboardUID, params.Encode()))
+}
+
+func setCommentAvatars(threads []*comments.CommentDTO) {
+	for _, comment := range threads {
+		if comment.Email != "" {
+			comment.AvatarURL = dtos.GetGravatarUrl(comment.Email)
+		}
+		setCommentAvatars(comment.Replies)
+	}
+}
+
+// swagger:parameters getAnnotationComments
+type GetAnnotationCommentsParams struct {
+	// in:path
+	// required:true
+	AnnotationID string `json:"annotation_id"`
+}
+
+// swagger:parameters postAnnotationComment
+type PostAnnotationCommentParams struct {
+	// in:path
+	// required:true
+	AnnotationID string `json:"annotation_id"`
+	// in:body
+	// required:true
+	Body comments.CreateCommentCommand `json:"body"`
+}
+
+// swagger:parameters deleteAnnotationComment
+type DeleteAnnotationCommentParams struct {
+	// in:path
+	// required:true
+	AnnotationID string `json:"annotation_id"`
+	// in:path
+	// required:true
+	CommentID string `json:"comment_id"`
+}
+
+// swagger:response getAnnotationCommentsResponse
+type GetAnnotationCommentsResponse struct {
+	// The response message
+	// in: body
+	Body []*comments.CommentDTO `json:"body"`
+}
+
+// swagger:response postAnnotationCommentResponse
+type PostAnnotationCommentResponse struct {
+	// The response message
+	// in: body
+	Body *comments.Comment `json:"body"`
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -33,6 +36,150 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Response {
+	items, rsp := hs.findAnnotations(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	for _, item := range items {
+		if item.Email != "" {
+			item.AvatarURL = dtos.GetGravatarUrl(item.Email)
+		}
+	}
+
+	return response.JSON(http.StatusOK, items)
+}
+
+// swagger:route GET /annotations/export annotations exportAnnotations
+//
+// Export Annotations as CSV.
+//
+// Streams the annotations matching the query as an RFC 4180 CSV document. Accepts the same filters as the find annotations endpoint.
+// All the matching annotations are exported, unless a limit is given.
+//
+// Produces:
+// - text/csv
+//
+// Responses:
+// 200: exportAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) ExportAnnotations(c *contextmodel.ReqContext) response.Response {
+	query, rsp := hs.annotationsQuery(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	// The limit caps the number of exported annotations, all the matching annotations are exported without it.
+	total := query.Limit
+	query.Limit = annotationExportPageSize
+	if total > 0 && total < query.Limit {
+		query.Limit = total
+	}
+	query.Page = 1
+
+	// The first page is read before the response is started, so that errors can still be returned.
+	dashboardCache := make(map[int64]*string)
+	items, err := hs.findAnnotationItems(c, query, dashboardCache)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+
+	filename := fmt.Sprintf("annotations-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	return response.CSVDownload(http.StatusOK, filename, func(w *csv.Writer) error {
+		if err := w.Write(annotationCSVHeader); err != nil {
+			return err
+		}
+		written := int64(0)
+		for {
+			for _, item := range items {
+				if total > 0 && written >= total {
+					return nil
+				}
+				if err := w.Write(annotationCSVRecord(item)); err != nil {
+					return err
+				}
+				written++
+			}
+			// Each page is written to the response before the next one is read.
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+			if int64(len(items)) < query.Limit || (total > 0 && written >= total) {
+				return nil
+			}
+
+			query.Page++
+			items, err = hs.findAnnotationItems(c, query, dashboardCache)
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// annotationExportPageSize is the number of annotations read from the store at once by an export.
+const annotationExportPageSize = 1000
+
+var annotationCSVHeader = []string{"id", "time", "timeEnd", "dashboardUID", "panelId", "alertId", "newState", "text", "tags", "login"}
+
+func annotationCSVRecord(item *annotations.ItemDTO) []string {
+	formatEpoch := func(ms int64) string {
+		if ms == 0 {
+			return ""
+		}
+		return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+	}
+	timeEnd := ""
+	if item.TimeEnd != item.Time {
+		timeEnd = formatEpoch(item.TimeEnd)
+	}
+	dashboardUID := ""
+	if item.DashboardUID != nil {
+		dashboardUID = *item.DashboardUID
+	}
+	return []string{
+		strconv.FormatInt(item.ID, 10),
+		formatEpoch(item.Time),
+		timeEnd,
+		csvCell(dashboardUID),
+		strconv.FormatInt(item.PanelID, 10),
+		strconv.FormatInt(item.AlertID, 10),
+		csvCell(item.NewState),
+		csvCell(item.Text),
+		csvCell(strings.Join(item.Tags, ",")),
+		csvCell(item.Login),
+	}
+}
+
+// csvCell prefixes the text cells that spreadsheet applications would evaluate as formulas with a quote,
+// so that opening an export cannot run a formula written in an annotation.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// findAnnotations runs the annotation query described by the request query
+// parameters and fills in the dashboard UIDs of the results.
+func (hs *HTTPServer) findAnnotations(c *contextmodel.ReqContext) ([]*annotations.ItemDTO, response.Response) {
+	query, rsp := hs.annotationsQuery(c)
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	items, err := hs.findAnnotationItems(c, query, make(map[int64]*string))
+	if err != nil {
+		return nil, response.Error(500, "Failed to get annotations", err)
+	}
+	return items, nil
+}
+
+// annotationsQuery returns the annotation query described by the request query parameters.
+func (hs *HTTPServer) annotationsQuery(c *contextmodel.ReqContext) (*annotations.ItemQuery, response.Response) {
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -54,24 +201,24 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		dq := dashboards.GetDashboardQuery{UID: query.DashboardUID, OrgID: c.SignedInUser.GetOrgID()}
 		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
 		if err != nil {
-			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+			return nil, response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
 		} else {
 			query.DashboardID = dqResult.ID
 		}
 	}
 
+	return query, nil
+}
+
+// findAnnotationItems runs the annotation query and fills in the dashboard UIDs of the results.
+// The dashboard UIDs are cached in dashboardCache, since there are several annotations per dashboard.
+func (hs *HTTPServer) findAnnotationItems(c *contextmodel.ReqContext, query *annotations.ItemQuery, dashboardCache map[int64]*string) ([]*annotations.ItemDTO, error) {
 	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
 	if err != nil {
-		return response.Error(500, "Failed to get annotations", err)
+		return nil, err
 	}
 
-	// since there are several annotations per dashboard, we can cache dashboard uid
-	dashboardCache := make(map[int64]*string)
 	for _, item := range items {
-		if item.Email != "" {
-			item.AvatarURL = dtos.GetGravatarUrl(item.Email)
-		}
-
 		if item.DashboardID != 0 {
 			if val, ok := dashboardCache[item.DashboardID]; ok {
 				item.DashboardUID = val
@@ -86,7 +233,7 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		}
 	}
 
-	return response.JSON(http.StatusOK, items)
+	return items, nil
 }
 
 type AnnotationError struct {
@@ -595,6 +742,44 @@ func (hs *HTTPServer) GetAnnotationTags(c *contextmodel.ReqContext) response.Res
 	return response.JSON(http.StatusOK, annotations.GetAnnotationTagsResponse{Result: result})
 }
 
+// swagger:route GET /annotations/tags/stats annotations getAnnotationTagStats
+//
+// Get Annotation Tag Statistics.
+//
+// Counts the annotations per tag over a time range, optionally scoped to a dashboard. Tags are ordered by descending count.
+//
+// Responses:
+// 200: getAnnotationTagStatsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetAnnotationTagStats(c *contextmodel.ReqContext) response.Response {
+	query := &annotations.TagStatsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		From:         c.QueryInt64("from"),
+		To:           c.QueryInt64("to"),
+		DashboardID:  c.QueryInt64("dashboardId"),
+		Limit:        c.QueryInt64("limit"),
+		SignedInUser: c.SignedInUser,
+	}
+
+	if dashboardUID := c.Query("dashboardUID"); dashboardUID != "" {
+		dq := dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: c.SignedInUser.GetOrgID()}
+		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+		}
+		query.DashboardID = dqResult.ID
+	}
+
+	result, err := hs.annotationsRepo.FindTagStats(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotation tag statistics", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 // AnnotationTypeScopeResolver provides an ScopeAttributeResolver able to
 // resolve annotation types. Scope "annotations:id:<id>" will be translated to "annotations:type:<type>,
 // where <type> is the type of annotation with id <id>.
@@ -743,7 +928,7 @@ type DeleteAnnotationByIDParams struct {
 	AnnotationID string `json:"annotation_id"`
 }
 
-// swagger:parameters getAnnotations
+// swagger:parameters getAnnotations exportAnnotations
 type GetAnnotationsParams struct {
 	// Find annotations created after specific epoch datetime in milliseconds.
 	// in:query
@@ -797,6 +982,30 @@ type GetAnnotationsParams struct {
 	MatchAny bool `json:"matchAny"`
 }
 
+// swagger:parameters getAnnotationTagStats
+type GetAnnotationTagStatsParams struct {
+	// Count annotations ending after specific epoch datetime in milliseconds.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Count annotations starting before specific epoch datetime in milliseconds.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// Only count annotations of a specific dashboard.
+	// in:query
+	// required:false
+	DashboardID int64 `json:"dashboardId"`
+	// Only count annotations of a specific dashboard.
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUID"`
+	// Max number of tags returned.
+	// in:query
+	// required:false
+	Limit int64 `json:"limit"`
+}
+
 // swagger:parameters getAnnotationTags
 type GetAnnotationTagsParams struct {
 	// Tag is a string that you can use to filter tags.
@@ -858,6 +1067,20 @@ type GetAnnotationsResponse struct {
 	Body []*annotations.ItemDTO `json:"body"`
 }
 
+// swagger:response getAnnotationTagStatsResponse
+type GetAnnotationTagStatsResponse struct {
+	// The response message
+	// in: body
+	Body annotations.FindTagStatsResult `json:"body"`
+}
+
+// swagger:response exportAnnotationsResponse
+type ExportAnnotationsResponse struct {
+	// CSV document with one annotation per row
+	// in: body
+	Body []byte `json:"body"`
+}
+
 // swagger:response getAnnotationByIDResponse
 type GetAnnotationByIDResponse struct {
 	// The response message
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/csv"
 	"io"
 	"net/http"
 	"strings"
@@ -53,6 +54,27 @@ func TestAPI_Annotations(t *testing.T) {
 			expectedCode: http.StatusForbidden,
 			permissions:  []accesscontrol.Permission{},
 		},
+		{
+			desc:         "should be able to export annotations with correct permission",
+			path:         "/api/annotations/export?tags=deploy",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
+		{
+			desc:         "should be able to fetch annotation tag stats with correct permission",
+			path:         "/api/annotations/tags/stats?from=1&to=2",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
+		{
+			desc:         "should not be able to export annotations without correct permission",
+			path:         "/api/annotations/export",
+			method:       http.MethodGet,
+			expectedCode: http.StatusForbidden,
+			permissions:  []accesscontrol.Permission{},
+		},
 		{
 			desc:         "should be able to fetch annotation by id with correct permission",
 			path:         "/api/annotations/1",
@@ -360,6 +382,15 @@ func TestAPI_Annotations(t *testing.T) {
 			expectedCode: http.StatusOK,
 			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsDelete, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashUID)}},
 		},
+		{
+			desc:         "should not be able to comment an annotation with only the read permission",
+			path:         "/api/annotations/1/comments",
+			body:         "{\"text\": \"Rolling back\"}",
+			method:       http.MethodPost,
+			featureFlags: []any{featuremgmt.FlagAnnotationPermissionUpdate},
+			expectedCode: http.StatusForbidden,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
 		{
 			desc:         "should be able to mass delete dashboard annotation with correct folder scope with annotationPermissionUpdate enabled",
 			path:         "/api/annotations/mass-delete",
@@ -419,6 +450,96 @@ func TestAPI_Annotations(t *testing.T) {
 	}
 }
 
+// pagedAnnotationsRepo returns its annotations page by page like the annotation store.
+type pagedAnnotationsRepo struct {
+	annotations.Repository
+	items   []*annotations.ItemDTO
+	queries []annotations.ItemQuery
+}
+
+func (r *pagedAnnotationsRepo) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	r.queries = append(r.queries, *query)
+	start := int64(0)
+	if query.Page > 1 {
+		start = (query.Page - 1) * query.Limit
+	}
+	if start >= int64(len(r.items)) {
+		return []*annotations.ItemDTO{}, nil
+	}
+	end := start + query.Limit
+	if end > int64(len(r.items)) {
+		end = int64(len(r.items))
+	}
+	return r.items[start:end], nil
+}
+
+func TestExportAnnotations(t *testing.T) {
+	dashUID := "dash"
+	repo := &pagedAnnotationsRepo{items: []*annotations.ItemDTO{
+		{
+			ID:          1,
+			DashboardID: 1,
+			PanelID:     2,
+			Time:        1700000000000,
+			TimeEnd:     1700000060000,
+			Text:        "deploy \"api\", v2\nrollback plan",
+			Tags:        []string{"deploy", "api"},
+			Login:       "admin",
+		},
+		{ID: 2, Time: 1700000000000, TimeEnd: 1700000000000, Text: "=HYPERLINK(\"http://example.com\")", Tags: []string{"+1", "@ops"}},
+	}}
+	for id := int64(3); id <= annotationExportPageSize+1; id++ {
+		repo.items = append(repo.items, &annotations.ItemDTO{ID: id, Time: 1700000000000, TimeEnd: 1700000000000})
+	}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.annotationsRepo = repo
+		dashService := &dashboards.FakeDashboardService{}
+		dashService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: dashUID}, nil)
+		hs.DashboardService = dashService
+	})
+	permissions := []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}}
+
+	export := func(t *testing.T, path string) string {
+		t.Helper()
+		repo.queries = nil
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest(path), authedUserWithPermissions(1, 1, permissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return string(body)
+	}
+
+	t.Run("should export all the annotations page by page", func(t *testing.T) {
+		body := export(t, "/api/annotations/export")
+		require.Len(t, repo.queries, 2)
+		assert.Equal(t, int64(1), repo.queries[0].Page)
+		assert.Equal(t, int64(2), repo.queries[1].Page)
+		assert.Equal(t, int64(annotationExportPageSize), repo.queries[1].Limit)
+
+		records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, annotationExportPageSize+2)
+	})
+
+	t.Run("should terminate records with CRLF and escape formulas", func(t *testing.T) {
+		body := export(t, "/api/annotations/export?limit=3")
+		require.Len(t, repo.queries, 1)
+		assert.Equal(t, int64(3), repo.queries[0].Limit)
+
+		expected := "id,time,timeEnd,dashboardUID,panelId,alertId,newState,text,tags,login\r\n" +
+			"1,2023-11-14T22:13:20Z,2023-11-14T22:14:20Z,dash,2,0,,\"deploy \"\"api\"\", v2\r\nrollback plan\",\"deploy,api\",admin\r\n" +
+			"2,2023-11-14T22:13:20Z,,,0,0,,\"'=HYPERLINK(\"\"http://example.com\"\")\",\"'+1,@ops\",\r\n" +
+			"3,2023-11-14T22:13:20Z,,,0,0,,,,\r\n"
+		assert.Equal(t, expected, body)
+	})
+}
+
 func TestService_AnnotationTypeScopeResolver(t *testing.T) {
 	rootDashUID := "root-dashboard"
 	folderDashUID := "folder-dashboard"
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 // ToDashboardErrorResponse returns a different response status according to the dashboard error type
@@ -23,11 +24,11 @@ func ToDashboardErrorResponse(ctx context.Context, pluginStore pluginstore.Store
 		if dashboardErr.StatusCode != http.StatusBadRequest {
 			return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
 		}
-		return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), nil)
+		return badRequest(dashboardErr.Error(), dashboardErr)
 	}
 
 	if errors.Is(err, dashboards.ErrFolderNotFound) {
-		return response.Error(http.StatusBadRequest, err.Error(), nil)
+		return badRequest(err.Error(), err)
 	}
 
 	var validationErr alerting.ValidationError
@@ -47,3 +48,12 @@ func ToDashboardErrorResponse(ctx context.Context, pluginStore pluginstore.Store
 
 	return response.Error(http.StatusInternalServerError, "Failed to save dashboard", err)
 }
+
+// badRequest returns a bad request response for an error caused by the
+// client, which is not logged.
+func badRequest(message string, err error) response.Response {
+	return response.JSON(http.StatusBadRequest, util.DynMap{
+		"message": message,
+		"code":    errutil.Code(http.StatusBadRequest, err),
+	})
+}
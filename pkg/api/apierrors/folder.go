@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 // ToFolderErrorResponse returns a different response status according to the folder error type
@@ -19,7 +20,7 @@ func ToFolderErrorResponse(err error) response.Response {
 		errors.Is(err, dashboards.ErrDashboardTypeMismatch) ||
 		errors.Is(err, dashboards.ErrDashboardInvalidUid) ||
 		errors.Is(err, dashboards.ErrDashboardUidTooLong) {
-		return response.Error(400, err.Error(), nil)
+		return badRequest(err.Error(), err)
 	}
 
 	if errors.Is(err, dashboards.ErrFolderAccessDenied) {
@@ -27,16 +28,16 @@ func ToFolderErrorResponse(err error) response.Response {
 	}
 
 	if errors.Is(err, dashboards.ErrFolderNotFound) {
-		return response.JSON(404, util.DynMap{"status": "not-found", "message": dashboards.ErrFolderNotFound.Error()})
+		return response.JSON(404, util.DynMap{"status": "not-found", "message": dashboards.ErrFolderNotFound.Error(), "code": errutil.Code(404, err)})
 	}
 
 	if errors.Is(err, dashboards.ErrFolderSameNameExists) ||
 		errors.Is(err, dashboards.ErrFolderWithSameUIDExists) {
-		return response.Error(409, err.Error(), nil)
+		return response.JSON(409, util.DynMap{"message": err.Error(), "code": errutil.Code(409, err)})
 	}
 
 	if errors.Is(err, dashboards.ErrFolderVersionMismatch) {
-		return response.JSON(412, util.DynMap{"status": "version-mismatch", "message": dashboards.ErrFolderVersionMismatch.Error()})
+		return response.JSON(412, util.DynMap{"status": "version-mismatch", "message": dashboards.ErrFolderVersionMismatch.Error(), "code": errutil.Code(412, err)})
 	}
 
 	return response.ErrOrFallback(500, "Folder API error", err)
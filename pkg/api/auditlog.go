@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// swagger:route GET /admin/audit-log admin searchAuditLog
+//
+// Search the audit log.
+//
+// Lists the admin actions recorded in the audit log, most recent first.
+// You need to have a permission with action `auditlog:read`.
+//
+// Responses:
+// 200: searchAuditLogResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) SearchAuditLog(c *contextmodel.ReqContext) response.Response {
+	query := &auditlog.SearchQuery{
+		ActorLogin:   c.Query("actor"),
+		Action:       auditlog.Action(c.Query("action")),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+	if c.Req.URL.Query().Has("orgId") {
+		orgID := c.QueryInt64("orgId")
+		query.OrgID = &orgID
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := hs.auditLogService.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search the audit log", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// recordAuditLog records a successful admin action of the signed in user.
+func (hs *HTTPServer) recordAuditLog(c *contextmodel.ReqContext, cmd *auditlog.RecordCommand) {
+	cmd.Actor = c.SignedInUser
+	cmd.IPAddress = c.RemoteAddr()
+	hs.auditLogService.Record(c.Req.Context(), cmd)
+}
+
+// dataSourceAuditSummary summarizes a data source for the audit log, leaving out its secrets.
+func dataSourceAuditSummary(ds *datasources.DataSource) map[string]any {
+	return map[string]any{
+		"uid":       ds.UID,
+		"name":      ds.Name,
+		"type":      ds.Type,
+		"url":       ds.URL,
+		"access":    ds.Access,
+		"isDefault": ds.IsDefault,
+		"basicAuth": ds.BasicAuth,
+		"database":  ds.Database,
+		"user":      ds.User,
+		"readOnly":  ds.ReadOnly,
+		"version":   ds.Version,
+	}
+}
+
+// swagger:parameters searchAuditLog
+type SearchAuditLogParams struct {
+	// Only returns the entries of the organization.
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// Login of the actor.
+	// in:query
+	// required:false
+	Actor string `json:"actor"`
+	// in:query
+	// required:false
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	ResourceType string `json:"resourceType"`
+	// in:query
+	// required:false
+	ResourceID string `json:"resourceId"`
+	// Start of the time range, in epoch milliseconds.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// End of the time range, in epoch milliseconds.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+}
+
+// swagger:response searchAuditLogResponse
+type SearchAuditLogResponse struct {
+	// in:body
+	Body *auditlog.SearchResult `json:"body"`
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/auditlog/auditlogtest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestSearchAuditLog(t *testing.T) {
+	expected := &auditlog.SearchResult{
+		TotalCount: 1,
+		Entries: []*auditlog.Entry{
+			{ID: 1, OrgID: 1, ActorLogin: "admin", Action: auditlog.ActionDataSourceDelete, ResourceType: auditlog.ResourceTypeDataSource, ResourceID: "abc"},
+		},
+		Page:    1,
+		PerPage: 100,
+	}
+
+	type testCase struct {
+		desc         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should be able to search the audit log with the correct permission",
+			permissions:  []accesscontrol.Permission{{Action: auditlog.ActionRead}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to search the audit log without the correct permission",
+			permissions:  []accesscontrol.Permission{},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.auditLogService = &auditlogtest.FakeService{ExpectedResult: expected}
+			})
+
+			res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/audit-log?action=datasource.delete"), userWithPermissions(1, tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var result auditlog.SearchResult
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+				assert.Equal(t, expected, &result)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestAddDataSource_RecordsAuditLog(t *testing.T) {
+	auditLogService := auditlogtest.NewFakeService()
+	hs := &HTTPServer{
+		DataSourcesService: &dataSourcesServiceMock{
+			expectedDatasource: &datasources.DataSource{ID: 1, OrgID: 1, UID: "abc", Name: "Test", Type: "test", URL: "http://localhost:5432"},
+		},
+		Cfg:                  setting.NewCfg(),
+		AccessControl:        acimpl.ProvideAccessControl(setting.NewCfg()),
+		accesscontrolService: actest.FakeService{},
+		auditLogService:      auditLogService,
+	}
+
+	sc := setupScenarioContext(t, "/api/datasources")
+	sc.m.Post(sc.url, routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+		c.Req.Body = mockRequestBody(datasources.AddDataSourceCommand{
+			Name:           "Test",
+			URL:            "http://localhost:5432",
+			Access:         "proxy",
+			Type:           "test",
+			SecureJsonData: map[string]string{"password": "secret"},
+		})
+		c.SignedInUser = authedUserWithPermissions(1, 1, []accesscontrol.Permission{})
+		return hs.AddDataSource(c)
+	}))
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+	require.Equal(t, http.StatusOK, sc.resp.Code)
+
+	require.Len(t, auditLogService.Recorded, 1)
+	recorded := auditLogService.Recorded[0]
+	assert.Equal(t, int64(1), recorded.OrgID)
+	assert.Equal(t, auditlog.ActionDataSourceCreate, recorded.Action)
+	assert.Equal(t, auditlog.ResourceTypeDataSource, recorded.ResourceType)
+	assert.Equal(t, "abc", recorded.ResourceID)
+	assert.Nil(t, recorded.Before)
+	assert.NotContains(t, recorded.After, "secureJsonData")
+	assert.Equal(t, "Test", recorded.After.(map[string]any)["name"])
+}
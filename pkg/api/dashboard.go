@@ -22,12 +22,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -36,6 +38,9 @@ import (
 
 const (
 	anonString = "Anonymous"
+
+	defaultPanelQueryErrorsLimit = 10
+	maxPanelQueryErrorsLimit     = 100
 )
 
 func (hs *HTTPServer) isDashboardStarredByUser(c *contextmodel.ReqContext, dashID int64) (bool, error) {
@@ -70,7 +75,8 @@ func dashboardGuardianResponse(err error) response.Response {
 //
 // Get dashboard by uid.
 //
-// Will return the dashboard given the dashboard unique identifier (uid).
+// Will return the dashboard given the dashboard unique identifier (uid). The ETag header of the response identifies the version of the dashboard.
+// When the dashboard redaction policy is enabled, users who cannot edit the dashboard get it without the redacted fields.
 //
 // Responses:
 // 200: dashboardResponse
@@ -124,6 +130,12 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 	if canView, err := guardian.CanView(); err != nil || !canView {
 		return dashboardGuardianResponse(err)
 	}
+
+	etag := dashboardETag(dash)
+	if ifNoneMatch := c.Req.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		return response.Empty(http.StatusNotModified).SetHeader("ETag", etag)
+	}
+
 	canEdit, _ := guardian.CanEdit()
 	canSave, _ := guardian.CanSave()
 	canAdmin, _ := guardian.CanAdmin()
@@ -212,6 +224,16 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		}
 	}
 
+	if rsp := hs.revealSecureOptions(c, dash); rsp != nil {
+		return rsp
+	}
+
+	// the users who cannot edit the dashboard get it without the fields of the redaction policy
+	if hs.Cfg.DashboardRedaction.Enabled && !canEdit {
+		dashboards.RedactFields(dash.Data, hs.Cfg.DashboardRedaction)
+		meta.Redacted = true
+	}
+
 	// make sure db version is in sync with json model version
 	dash.Data.Set("version", dash.Version)
 
@@ -221,7 +243,7 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 	}
 
 	c.TimeRequest(metrics.MApiDashboardGet)
-	return response.JSON(http.StatusOK, dto)
+	return response.JSON(http.StatusOK, dto).SetHeader("ETag", etag)
 }
 
 func (hs *HTTPServer) getAnnotationPermissionsByScope(c *contextmodel.ReqContext, actions *dtos.AnnotationActions, scope string) {
@@ -255,6 +277,44 @@ func (hs *HTTPServer) getUserLogin(ctx context.Context, userID int64) string {
 	return user.Login
 }
 
+// revealSecureOptions reveals the secure options of the panels of the dashboard to users allowed to read the
+// dashboard secrets. The dashboard service returns dashboards with their secure options redacted.
+func (hs *HTTPServer) revealSecureOptions(c *contextmodel.ReqContext, dash *dashboards.Dashboard) response.Response {
+	canRead, err := hs.canReadSecureOptions(c, dash.UID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking dashboard secrets permissions", err)
+	}
+	if !canRead {
+		return nil
+	}
+	if err := hs.DashboardService.RevealSecureOptions(c.Req.Context(), dash); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to decrypt dashboard secure options", err)
+	}
+	return nil
+}
+
+// revealVersionSecureOptions decrypts the secure options of the panels of a dashboard version for users allowed
+// to read the dashboard secrets, and redacts them for everyone else.
+func (hs *HTTPServer) revealVersionSecureOptions(c *contextmodel.ReqContext, dashUID string, data *simplejson.Json) response.Response {
+	canRead, err := hs.canReadSecureOptions(c, dashUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking dashboard secrets permissions", err)
+	}
+	if !canRead {
+		dashboards.RedactSecureOptions(data)
+		return nil
+	}
+	if err := hs.DashboardService.DecryptSecureOptions(c.Req.Context(), data); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to decrypt dashboard secure options", err)
+	}
+	return nil
+}
+
+func (hs *HTTPServer) canReadSecureOptions(c *contextmodel.ReqContext, dashUID string) (bool, error) {
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsSecretsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashUID))
+	return hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+}
+
 func (hs *HTTPServer) getDashboardHelper(ctx context.Context, orgID int64, id int64, uid string) (*dashboards.Dashboard, response.Response) {
 	var query dashboards.GetDashboardQuery
 
@@ -364,6 +424,7 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 // 404: notFoundError
 // 412: preconditionFailedError
 // 422: unprocessableEntityError
+// 428: preconditionRequiredError
 // 500: internalServerError
 func (hs *HTTPServer) PostDashboard(c *contextmodel.ReqContext) response.Response {
 	cmd := dashboards.SaveDashboardCommand{}
@@ -392,6 +453,9 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	cmd.UserID = userID
 
 	dash := cmd.GetDashboardModel()
+	if rsp := hs.checkDashboardIfMatch(c.Req, &cmd, dash); rsp != nil {
+		return rsp
+	}
 	newDashboard := dash.ID == 0
 	if newDashboard {
 		limitReached, err := hs.QuotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
@@ -478,8 +542,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
-	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":    "success",
 		"slug":      dashboard.Slug,
 		"version":   dashboard.Version,
@@ -487,7 +550,33 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
+	}
+	if warnings := hs.validateDashboardVariables(c, dashboard); len(warnings) > 0 {
+		result["variableWarnings"] = warnings
+	}
+
+	c.TimeRequest(metrics.MApiDashboardSave)
+	return response.JSON(http.StatusOK, result).SetHeader("ETag", dashboardETag(dashboard))
+}
+
+// validateDashboardVariables returns the warnings of the template variables of a saved dashboard. The
+// dashboard is saved regardless of them, so errors of the validation are only logged.
+func (hs *HTTPServer) validateDashboardVariables(c *contextmodel.ReqContext, dashboard *dashboards.Dashboard) []dashboardvariables.Warning {
+	if !hs.Cfg.DashboardValidateVariables || dashboard.Data == nil {
+		return nil
+	}
+	result, err := hs.dashboardVariableService.Validate(c.Req.Context(), &dashboardvariables.ValidateQuery{
+		OrgID:      c.SignedInUser.GetOrgID(),
+		Dashboard:  dashboard.Data,
+		RunQueries: hs.Cfg.DashboardValidateVariablesRunQueries,
+		Timeout:    hs.Cfg.DashboardValidateVariablesTimeout,
+		User:       c.SignedInUser,
 	})
+	if err != nil {
+		hs.log.Warn("Failed to validate the template variables of the dashboard", "uid", dashboard.UID, "error", err)
+		return nil
+	}
+	return result.Warnings
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -775,6 +864,10 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 		creator = hs.getUserLogin(c.Req.Context(), res.CreatedBy)
 	}
 
+	if rsp := hs.revealVersionSecureOptions(c, dash.UID, res.Data); rsp != nil {
+		return rsp
+	}
+
 	dashVersionMeta := &dashver.DashboardVersionMeta{
 		ID:            res.ID,
 		DashboardID:   res.DashboardID,
@@ -791,6 +884,85 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// swagger:route GET /dashboards/uid/{uid}/query-stats dashboards getDashboardQueryStats
+//
+// Get the result stats of the panel queries of a dashboard.
+//
+// Returns the rows, result size, backend latency and cache status of the queries of each panel of the dashboard
+// served by this instance since it started, the most expensive panels first. The stats are only collected when
+// `result_stats` is enabled in the `query` section of the configuration.
+//
+// Responses:
+// 200: dashboardQueryStatsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardQueryStats(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	return response.JSON(http.StatusOK, hs.queryDataService.GetDashboardResultStats(dash.OrgID, dash.UID))
+}
+
+// swagger:route GET /dashboards/query-errors dashboards getPanelQueryErrors
+//
+// Get the panels of the organization whose queries fail the most.
+//
+// Returns the error rate, the latency percentiles and the hourly error rate of the queries of the panels served by
+// all the instances during the retention period, the panels with the highest error rate first. Only the panels with
+// errors of the dashboards the user can view are returned. The errors are only collected when `error_stats` is
+// enabled in the `query` section of the configuration.
+//
+// Responses:
+// 200: panelQueryErrorsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetPanelQueryErrors(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 || limit > maxPanelQueryErrorsLimit {
+		limit = defaultPanelQueryErrorsLimit
+	}
+
+	panels, err := hs.queryDataService.GetPanelErrorStats(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the query errors of the panels", err)
+	}
+
+	result := make([]query.PanelErrorStats, 0, limit)
+	canView := map[string]bool{}
+	for _, stats := range panels {
+		allowed, ok := canView[stats.DashboardUID]
+		if !ok {
+			evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(stats.DashboardUID))
+			var err error
+			if allowed, err = hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+			}
+			canView[stats.DashboardUID] = allowed
+		}
+		if !allowed {
+			continue
+		}
+		result = append(result, stats)
+		if len(result) == limit {
+			break
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 // swagger:route POST /dashboards/calculate-diff dashboards calculateDashboardDiff
 //
 // Perform diff on two dashboards.
@@ -874,6 +1046,9 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 
 	baseData := baseVersionRes.Data
 	newData := newVersionRes.Data
+	// secure options are encrypted with a random nonce, their ciphertext changes on every save
+	dashboards.RedactSecureOptions(baseData)
+	dashboards.RedactSecureOptions(newData)
 
 	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &options, baseData, newData)
 
@@ -969,6 +1144,10 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 	saveCmd.OrgID = c.SignedInUser.GetOrgID()
 	saveCmd.UserID = userID
 	saveCmd.Dashboard = version.Data
+	// the secure options are encrypted again when saving the restored version
+	if err := hs.DashboardService.DecryptSecureOptions(c.Req.Context(), saveCmd.Dashboard); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to decrypt dashboard secure options", err)
+	}
 	saveCmd.Dashboard.Set("version", dash.Version)
 	saveCmd.Dashboard.Set("uid", dash.UID)
 	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
@@ -1047,6 +1226,22 @@ type GetDashboardVersionsByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getDashboardQueryStats
+type GetDashboardQueryStatsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getPanelQueryErrors
+type GetPanelQueryErrorsParams struct {
+	// The maximum number of panels to return, at most 100.
+	// in:query
+	// required:false
+	// default:10
+	Limit int `json:"limit"`
+}
+
 // swagger:parameters restoreDashboardVersionByUID
 type RestoreDashboardVersionByUIDParams struct {
 	// in:body
@@ -1094,6 +1289,9 @@ type GetDashboardByUIDParams struct {
 	// in:path
 	// required:true
 	UID string `json:"uid"`
+	// The ETag of the dashboard version known by the client. The response is 304 Not Modified when it matches.
+	// in:header
+	IfNoneMatch string `json:"If-None-Match"`
 }
 
 // swagger:parameters deleteDashboardByUID
@@ -1108,6 +1306,9 @@ type PostDashboardParams struct {
 	// in:body
 	// required:true
 	Body dashboards.SaveDashboardCommand
+	// The ETag of the updated dashboard version. The update is rejected when the dashboard has changed since.
+	// in:header
+	IfMatch string `json:"If-Match"`
 }
 
 // swagger:parameters calculateDashboardDiff
@@ -1192,6 +1393,10 @@ type PostDashboardResponse struct {
 		// FolderUID The unique identifier (uid) of the folder the dashboard belongs to.
 		// required: false
 		FolderUID string `json:"folderUid"`
+
+		// VariableWarnings The template variables of the dashboard that would fail to load.
+		// required: false
+		VariableWarnings []dashboardvariables.Warning `json:"variableWarnings,omitempty"`
 	} `json:"body"`
 }
 
@@ -1231,6 +1436,18 @@ type DashboardVersionsResponse struct {
 	Body []dashver.DashboardVersionMeta `json:"body"`
 }
 
+// swagger:response dashboardQueryStatsResponse
+type DashboardQueryStatsResponse struct {
+	// in: body
+	Body []query.PanelResultStats `json:"body"`
+}
+
+// swagger:response panelQueryErrorsResponse
+type PanelQueryErrorsResponse struct {
+	// in: body
+	Body []query.PanelErrorStats `json:"body"`
+}
+
 // swagger:response dashboardVersionResponse
 type DashboardVersionResponse struct {
 	// in: body
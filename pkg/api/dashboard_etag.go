@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// dashboardETag returns the strong ETag of the version of the dashboard.
+func dashboardETag(dash *dashboards.Dashboard) string {
+	return `"` + dash.UID + "-" + strconv.Itoa(dash.Version) + `"`
+}
+
+// etagMatches reports whether the value of an If-Match or If-None-Match
+// header matches the ETag. The weak ETags only match with the weak
+// comparison of If-None-Match.
+func etagMatches(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDashboardIfMatch checks the If-Match header of an update of the
+// dashboard, required in the orgs of [dashboards] require_if_match_orgs. When
+// the header matches, the version of the update is the version of the ETag, so
+// that the writes in between are rejected as version mismatches.
+func (hs *HTTPServer) checkDashboardIfMatch(r *http.Request, cmd *dashboards.SaveDashboardCommand, dash *dashboards.Dashboard) response.Response {
+	header := r.Header.Get("If-Match")
+	required := hs.Cfg.DashboardRequireIfMatchOrgs[dash.OrgID]
+	if header == "" && !required {
+		return nil
+	}
+
+	existing, err := hs.getExistingDashboard(r, dash)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	if existing == nil {
+		if header != "" {
+			return response.Error(http.StatusPreconditionFailed, "Dashboard not found", nil)
+		}
+		return nil
+	}
+	if header == "" {
+		return response.Error(http.StatusPreconditionRequired, "Updating dashboards requires the If-Match header", nil)
+	}
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+
+	if !etagMatches(header, dashboardETag(existing), false) {
+		return response.JSON(http.StatusPreconditionFailed, dashboards.ErrDashboardVersionMismatch.Body()).
+			SetHeader("ETag", dashboardETag(existing))
+	}
+	cmd.Overwrite = false
+	dash.SetVersion(existing.Version)
+	return nil
+}
+
+// getExistingDashboard returns the dashboard updated by the save, or nil when
+// the save creates a dashboard.
+func (hs *HTTPServer) getExistingDashboard(r *http.Request, dash *dashboards.Dashboard) (*dashboards.Dashboard, error) {
+	if dash.ID == 0 && dash.UID == "" {
+		return nil, nil
+	}
+	// nolint:staticcheck
+	existing, err := hs.DashboardService.GetDashboard(r.Context(), &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dash.OrgID})
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return nil, nil
+	}
+	return existing, err
+}
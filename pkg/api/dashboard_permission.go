@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -14,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -84,6 +88,203 @@ func (hs *HTTPServer) GetDashboardPermissionList(c *contextmodel.ReqContext) res
 	return response.JSON(http.StatusOK, filteredACLs)
 }
 
+// dashboardEffectiveActions are the dashboard actions reported by the effective permissions endpoint.
+var dashboardEffectiveActions = []string{
+	dashboards.ActionDashboardsRead,
+	dashboards.ActionDashboardsWrite,
+	dashboards.ActionDashboardsDelete,
+	dashboards.ActionDashboardsPermissionsRead,
+	dashboards.ActionDashboardsPermissionsWrite,
+}
+
+// swagger:route GET /dashboards/uid/{uid}/permissions/effective dashboard_permissions getDashboardEffectivePermissions
+//
+// Explains the permissions a user has on the given dashboard.
+//
+// Returns the resolved dashboard actions of the user together with the chain of
+// user, team and role permissions, on the dashboard or one of its parent folders, granting them.
+//
+// Responses:
+// 200: getDashboardEffectivePermissionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardEffectivePermissions(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	userID, err := strconv.ParseInt(c.Query("userId"), 10, 64)
+	if err != nil || userID <= 0 {
+		return response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(ctx, orgID, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	target, err := hs.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: userID, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, "User not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	// users outside of the organization or hidden from the requester are reported as not found
+	if target.OrgID != orgID || dtos.IsHiddenUser(target.Login, c.SignedInUser, hs.Cfg) {
+		return response.Error(http.StatusNotFound, "User not found", user.ErrUserNotFound)
+	}
+
+	folderUID := dash.FolderUID
+	if folderUID == "" {
+		folderUID = accesscontrol.GeneralFolderUID
+	}
+	inheritedScopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, hs.folderService)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard folders", err)
+	}
+	scopes := append([]string{
+		dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.UID),
+		dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID),
+	}, inheritedScopes...)
+
+	permissions, err := hs.accesscontrolService.GetUserPermissions(ctx, target, accesscontrol.Options{ReloadCache: true})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user permissions", err)
+	}
+
+	userPermissions := accesscontrol.GroupScopesByAction(permissions)
+	actions := make([]string, 0, len(dashboardEffectiveActions))
+	for _, action := range dashboardEffectiveActions {
+		evaluators := make([]accesscontrol.Evaluator, 0, len(scopes))
+		for _, scope := range scopes {
+			evaluators = append(evaluators, accesscontrol.EvalPermission(action, scope))
+		}
+		if accesscontrol.EvalAny(evaluators...).Evaluate(userPermissions) {
+			actions = append(actions, action)
+		}
+	}
+
+	resourcePermissions, err := hs.dashboardPermissionsService.GetPermissions(ctx, c.SignedInUser, dash.UID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard permissions", err)
+	}
+
+	chain, explained := hs.getDashboardPermissionChain(target, resourcePermissions)
+
+	// whatever is not explained by a managed permission comes from the roles assigned to the user
+	var fromRoles []string
+	for _, action := range actions {
+		if _, ok := explained[action]; !ok {
+			fromRoles = append(fromRoles, action)
+		}
+	}
+	if len(fromRoles) > 0 {
+		chain = append(chain, dtos.DashboardPermissionSource{
+			Kind:       dtos.PermissionSourceRole,
+			Role:       string(target.OrgRole),
+			Permission: dashboardPermissionLevel(fromRoles),
+			Actions:    fromRoles,
+		})
+	}
+
+	return response.JSON(http.StatusOK, dtos.DashboardEffectivePermissions{
+		UserID:       target.UserID,
+		UserLogin:    target.Login,
+		DashboardUID: dash.UID,
+		Permission:   dashboardPermissionLevel(actions),
+		Actions:      actions,
+		Chain:        chain,
+	})
+}
+
+// getDashboardPermissionChain returns the managed permissions applying to the target user,
+// direct ones first, together with the dashboard actions they grant.
+func (hs *HTTPServer) getDashboardPermissionChain(target *user.SignedInUser, permissions []accesscontrol.ResourcePermission) ([]dtos.DashboardPermissionSource, map[string]struct{}) {
+	teams := make(map[int64]struct{}, len(target.Teams))
+	for _, id := range target.Teams {
+		teams[id] = struct{}{}
+	}
+	roles := make(map[string]struct{})
+	for _, role := range accesscontrol.GetOrgRoles(target) {
+		roles[role] = struct{}{}
+	}
+
+	chain := make([]dtos.DashboardPermissionSource, 0, len(permissions))
+	explained := make(map[string]struct{})
+	for _, p := range permissions {
+		if !p.IsManaged && !p.IsInherited {
+			continue
+		}
+
+		source := dtos.DashboardPermissionSource{
+			Scope:      p.Scope,
+			Inherited:  p.IsInherited,
+			Permission: hs.dashboardPermissionsService.MapActions(p),
+			Actions:    p.Actions,
+		}
+		if p.IsInherited {
+			source.FolderUID = strings.TrimPrefix(p.Scope, dashboards.ScopeFoldersPrefix)
+		}
+
+		_, inTeam := teams[p.TeamId]
+		_, hasRole := roles[p.BuiltInRole]
+		switch {
+		case p.UserId != 0 && p.UserId == target.UserID:
+			source.Kind = dtos.PermissionSourceUser
+		case p.TeamId != 0 && inTeam:
+			source.Kind = dtos.PermissionSourceTeam
+			source.TeamID = p.TeamId
+			source.Team = p.Team
+		case p.BuiltInRole != "" && hasRole:
+			source.Kind = dtos.PermissionSourceBuiltInRole
+			source.Role = p.BuiltInRole
+		default:
+			continue
+		}
+
+		for _, action := range p.Actions {
+			explained[action] = struct{}{}
+		}
+		chain = append(chain, source)
+	}
+
+	sort.SliceStable(chain, func(i, j int) bool {
+		return !chain[i].Inherited && chain[j].Inherited
+	})
+
+	return chain, explained
+}
+
+// dashboardPermissionLevel maps a set of dashboard actions to the highest permission level they fully cover.
+func dashboardPermissionLevel(actions []string) string {
+	granted := make(map[string]struct{}, len(actions))
+	for _, action := range actions {
+		granted[action] = struct{}{}
+	}
+
+	has := func(actions ...string) bool {
+		for _, action := range actions {
+			if _, ok := granted[action]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case has(dashboards.ActionDashboardsRead, dashboards.ActionDashboardsWrite, dashboards.ActionDashboardsPermissionsWrite):
+		return dashboardaccess.PERMISSION_ADMIN.String()
+	case has(dashboards.ActionDashboardsRead, dashboards.ActionDashboardsWrite):
+		return dashboardaccess.PERMISSION_EDIT.String()
+	case has(dashboards.ActionDashboardsRead):
+		return dashboardaccess.PERMISSION_VIEW.String()
+	}
+	return ""
+}
+
 // swagger:route POST /dashboards/uid/{uid}/permissions dashboard_permissions updateDashboardPermissionsByUID
 //
 // Updates permissions for a dashboard.
@@ -362,3 +563,19 @@ type GetDashboardPermissionsResponse struct {
 	// in: body
 	Body []*dashboards.DashboardACLInfoDTO `json:"body"`
 }
+
+// swagger:parameters getDashboardEffectivePermissions
+type GetDashboardEffectivePermissionsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:query
+	// required:true
+	UserID int64 `json:"userId"`
+}
+
+// swagger:response getDashboardEffectivePermissionsResponse
+type GetDashboardEffectivePermissionsResponse struct {
+	// in: body
+	Body dtos.DashboardEffectivePermissions `json:"body"`
+}
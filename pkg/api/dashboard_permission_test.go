@@ -10,9 +10,15 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -157,3 +163,104 @@ func TestHTTPServer_UpdateDashboardPermissions(t *testing.T) {
 		require.NoError(t, res.Body.Close())
 	})
 }
+
+func TestHTTPServer_GetDashboardEffectivePermissions(t *testing.T) {
+	readPermissions := []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:1"},
+	}
+
+	setup := func(t *testing.T, target *user.SignedInUser, userPermissions []accesscontrol.Permission, resourcePermissions []accesscontrol.ResourcePermission) *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1", OrgID: 1, FolderUID: "child"}, nil).Maybe()
+			hs.DashboardService = svc
+			hs.folderService = &foldertest.FakeService{ExpectedFolders: []*folder.Folder{{UID: "parent"}}}
+			hs.userService = &usertest.FakeUserService{ExpectedSignedInUser: target}
+			hs.accesscontrolService = actest.FakeService{ExpectedPermissions: userPermissions}
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{
+				ExpectedPermissions:  resourcePermissions,
+				ExpectedMappedAction: "View",
+			}
+		})
+	}
+
+	t.Run("should not be able to explain permissions without permission to read the acl", func(t *testing.T) {
+		server := setup(t, nil, nil, nil)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not be able to explain permissions of a dashboard outside of the scope of the acl permission", func(t *testing.T) {
+		server := setup(t, &user.SignedInUser{UserID: 2, OrgID: 1, Login: "bob"}, nil, nil)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:2"},
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should require a user id", func(t *testing.T) {
+		server := setup(t, nil, nil, nil)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective"), userWithPermissions(1, readPermissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return not found for users outside of the organization", func(t *testing.T) {
+		server := setup(t, &user.SignedInUser{UserID: 2, OrgID: 0}, nil, nil)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, readPermissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should explain the permissions granted to the user", func(t *testing.T) {
+		target := &user.SignedInUser{UserID: 2, OrgID: 1, Login: "bob", OrgRole: org.RoleViewer, Teams: []int64{3}}
+		server := setup(t, target,
+			[]accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: "folders:uid:parent"},
+				{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:1"},
+				{Action: dashboards.ActionDashboardsDelete, Scope: "dashboards:*"},
+			},
+			[]accesscontrol.ResourcePermission{
+				{Scope: "folders:uid:parent", TeamId: 3, Team: "devs", Actions: []string{dashboards.ActionDashboardsRead}, IsInherited: true},
+				{Scope: "dashboards:uid:1", UserId: 2, Actions: []string{dashboards.ActionDashboardsWrite}, IsManaged: true},
+				{Scope: "dashboards:uid:1", UserId: 4, Actions: []string{dashboards.ActionDashboardsPermissionsWrite}, IsManaged: true},
+				{Scope: "dashboards:uid:1", BuiltInRole: "Editor", Actions: []string{dashboards.ActionDashboardsWrite}, IsManaged: true},
+			},
+		)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, readPermissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result dtos.DashboardEffectivePermissions
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.NoError(t, res.Body.Close())
+
+		assert.Equal(t, "bob", result.UserLogin)
+		assert.Equal(t, "Edit", result.Permission)
+		assert.Equal(t, []string{dashboards.ActionDashboardsRead, dashboards.ActionDashboardsWrite, dashboards.ActionDashboardsDelete}, result.Actions)
+
+		require.Len(t, result.Chain, 3)
+		assert.Equal(t, dtos.PermissionSourceUser, result.Chain[0].Kind)
+		assert.False(t, result.Chain[0].Inherited)
+
+		assert.Equal(t, dtos.PermissionSourceTeam, result.Chain[1].Kind)
+		assert.True(t, result.Chain[1].Inherited)
+		assert.Equal(t, "parent", result.Chain[1].FolderUID)
+		assert.Equal(t, "devs", result.Chain[1].Team)
+
+		assert.Equal(t, dtos.PermissionSourceRole, result.Chain[2].Kind)
+		assert.Equal(t, string(org.RoleViewer), result.Chain[2].Role)
+		assert.Equal(t, []string{dashboards.ActionDashboardsDelete}, result.Chain[2].Actions)
+	})
+}
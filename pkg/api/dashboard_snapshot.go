@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -425,9 +428,26 @@ func (hs *HTTPServer) SearchDashboardSnapshots(c *contextmodel.ReqContext) respo
 		Name:         query,
 		Limit:        limit,
 		OrgID:        c.SignedInUser.GetOrgID(),
+		CreatedBy:    c.QueryInt64("createdBy"),
 		SignedInUser: c.SignedInUser,
 	}
 
+	if external := c.Query("external"); external != "" {
+		isExternal, err := strconv.ParseBool(external)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "external must be a boolean", err)
+		}
+		searchQuery.External = &isExternal
+	}
+
+	if olderThan := c.Query("olderThan"); olderThan != "" {
+		age, err := gtime.ParseDuration(olderThan)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "olderThan must be a duration, such as 30d", err)
+		}
+		searchQuery.CreatedBefore = time.Now().Add(-age)
+	}
+
 	searchQueryResult, err := hs.dashboardsnapshotsService.SearchDashboardSnapshots(c.Req.Context(), &searchQuery)
 	if err != nil {
 		return response.Error(500, "Search failed", err)
@@ -452,6 +472,64 @@ func (hs *HTTPServer) SearchDashboardSnapshots(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, dto)
 }
 
+// swagger:route POST /dashboard/snapshots/delete snapshots deleteDashboardSnapshots
+//
+// Delete several snapshots.
+//
+// Deletes the snapshots of the organization with the given keys, and their external copies. Snapshots that do
+// not exist are ignored.
+//
+// Responses:
+// 200: deleteDashboardSnapshotsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteDashboardSnapshots(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		c.JsonApiErr(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+		return nil
+	}
+
+	form := dtos.DeleteDashboardSnapshotsForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if len(form.Keys) == 0 {
+		return response.Error(http.StatusBadRequest, "keys are required", nil)
+	}
+
+	cmd := &dashboardsnapshots.DeleteDashboardSnapshotsCommand{OrgID: c.SignedInUser.GetOrgID()}
+	for _, key := range form.Keys {
+		snapshot, err := hs.dashboardsnapshotsService.GetDashboardSnapshot(c.Req.Context(), &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key})
+		if err != nil {
+			if errors.Is(err, dashboardsnapshots.ErrBaseNotFound) {
+				continue
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to get dashboard snapshot", err)
+		}
+		if snapshot.OrgID != cmd.OrgID {
+			continue
+		}
+
+		if snapshot.External {
+			if err := deleteExternalDashboardSnapshot(snapshot.ExternalDeleteURL); err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to delete external dashboard", err)
+			}
+		}
+		cmd.DeleteKeys = append(cmd.DeleteKeys, snapshot.DeleteKey)
+	}
+
+	if err := hs.dashboardsnapshotsService.DeleteDashboardSnapshots(c.Req.Context(), cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete dashboard snapshots", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Snapshots deleted. It might take an hour before they are cleared from any CDN caches.",
+		"deleted": cmd.DeletedRows,
+	})
+}
+
 // swagger:parameters createDashboardSnapshot
 type CreateSnapshotParams struct {
 	// in:body
@@ -468,6 +546,22 @@ type GetSnapshotsParams struct {
 	// in:query
 	// default:1000
 	Limit int64 `json:"limit"`
+	// Only return the snapshots created by the user with this id
+	// in:query
+	CreatedBy int64 `json:"createdBy"`
+	// Only return the external snapshots if true, or the local snapshots if false
+	// in:query
+	External bool `json:"external"`
+	// Only return the snapshots created before this duration ago, such as 30d
+	// in:query
+	OlderThan string `json:"olderThan"`
+}
+
+// swagger:parameters deleteDashboardSnapshots
+type DeleteDashboardSnapshotsParams struct {
+	// in:body
+	// required:true
+	Body dtos.DeleteDashboardSnapshotsForm `json:"body"`
 }
 
 // swagger:parameters getDashboardSnapshot
@@ -509,6 +603,16 @@ type SearchDashboardSnapshotsResponse struct {
 	Body []*dashboardsnapshots.DashboardSnapshotDTO `json:"body"`
 }
 
+// swagger:response deleteDashboardSnapshotsResponse
+type DeleteDashboardSnapshotsResponse struct {
+	// in:body
+	Body struct {
+		Message string `json:"message"`
+		// The number of deleted snapshots
+		Deleted int64 `json:"deleted"`
+	} `json:"body"`
+}
+
 // swagger:response getDashboardSnapshotResponse
 type GetDashboardSnapshotResponse DashboardResponse
 
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -123,6 +124,61 @@ func TestHTTPServer_DeleteDashboardSnapshot(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_DeleteDashboardSnapshots(t *testing.T) {
+	setup := func(t *testing.T, svc dashboardsnapshots.Service) *webtest.Server {
+		t.Helper()
+
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			cfg := setting.NewCfg()
+			cfg.SnapshotEnabled = true
+			hs.Cfg = cfg
+			hs.dashboardsnapshotsService = svc
+		})
+	}
+
+	t.Run("Org admin should delete the snapshots of the org and ignore the others", func(t *testing.T) {
+		svc := dashboardsnapshots.NewMockService(t)
+		svc.On("GetDashboardSnapshot", mock.Anything, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "a"}).
+			Return(&dashboardsnapshots.DashboardSnapshot{Key: "a", DeleteKey: "delete-a", OrgID: 1}, nil)
+		svc.On("GetDashboardSnapshot", mock.Anything, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "b"}).
+			Return(&dashboardsnapshots.DashboardSnapshot{Key: "b", DeleteKey: "delete-b", OrgID: 2}, nil)
+		svc.On("GetDashboardSnapshot", mock.Anything, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "c"}).
+			Return(nil, dashboardsnapshots.ErrBaseNotFound.Errorf(""))
+		svc.On("DeleteDashboardSnapshots", mock.Anything, mock.MatchedBy(func(cmd *dashboardsnapshots.DeleteDashboardSnapshotsCommand) bool {
+			return cmd.OrgID == 1 && len(cmd.DeleteKeys) == 1 && cmd.DeleteKeys[0] == "delete-a"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*dashboardsnapshots.DeleteDashboardSnapshotsCommand).DeletedRows = 1
+		}).Return(nil)
+
+		server := setup(t, svc)
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+			server.NewPostRequest("/api/dashboard/snapshots/delete", strings.NewReader(`{"keys": ["a", "b", "c"]}`)),
+			&user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin},
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var body struct {
+			Deleted int64 `json:"deleted"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, int64(1), body.Deleted)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Editor should not be able to delete snapshots in bulk", func(t *testing.T) {
+		server := setup(t, dashboardsnapshots.NewMockService(t))
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+			server.NewPostRequest("/api/dashboard/snapshots/delete", strings.NewReader(`{"keys": ["a"]}`)),
+			&user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleEditor},
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
 func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 	setupRemoteServer := func(fn func(http.ResponseWriter, *http.Request)) *httptest.Server {
 		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,6 +39,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
@@ -46,12 +49,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/playlist/playlisttest"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/api"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
@@ -127,7 +132,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, drain.ProvideService(cfg), playlisttest.NewPlaylistServiveFake())
 	require.NoError(t, err)
 	return gLive
 }
@@ -250,6 +255,167 @@ func TestHTTPServer_GetDashboard_AccessControl(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_GetDashboard_Redaction(t *testing.T) {
+	setup := func() *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			dash := dashboards.NewDashboard("some dash")
+			dash.ID = 1
+			dash.UID = "1"
+			dash.Data.Set("description", "See https://wiki.internal/dash")
+			dash.Data.Set("panels", []any{map[string]any{"id": 1, "datasource": map[string]any{"type": "prometheus", "uid": "prom"}}})
+
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(dash, nil).Maybe()
+			hs.DashboardService = dashSvc
+
+			hs.Cfg = setting.NewCfg()
+			hs.Cfg.DashboardRedaction = setting.DashboardRedactionSettings{
+				Enabled:             true,
+				DatasourceUIDs:      true,
+				DescriptionPatterns: []*regexp.Regexp{regexp.MustCompile(`\.internal`)},
+			}
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+			hs.starService = startest.NewStarServiceFake()
+			hs.dashboardProvisioningService = mockDashboardProvisioningService{}
+
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+	}
+
+	getDashboard := func(t *testing.T, permissions []accesscontrol.Permission) dtos.DashboardFullWithMeta {
+		t.Helper()
+		server := setup()
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var data dtos.DashboardFullWithMeta
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+		require.NoError(t, res.Body.Close())
+		return data
+	}
+
+	t.Run("Should redact the dashboard for users who cannot edit it", func(t *testing.T) {
+		data := getDashboard(t, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"}})
+
+		assert.True(t, data.Meta.Redacted)
+		_, ok := data.Dashboard.CheckGet("description")
+		assert.False(t, ok)
+		assert.Equal(t, map[string]any{"type": "prometheus"}, data.Dashboard.Get("panels").GetIndex(0).Get("datasource").MustMap())
+	})
+
+	t.Run("Should not redact the dashboard for users who can edit it", func(t *testing.T) {
+		data := getDashboard(t, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"},
+			{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:1"},
+		})
+
+		assert.False(t, data.Meta.Redacted)
+		assert.Equal(t, "See https://wiki.internal/dash", data.Dashboard.Get("description").MustString())
+		assert.Equal(t, "prom", data.Dashboard.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+	})
+}
+
+func TestHTTPServer_DashboardETag(t *testing.T) {
+	setup := func(t *testing.T, requireIfMatch bool) (*webtest.Server, *dashboards.FakeDashboardService) {
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			dash := dashboards.NewDashboard("some dash")
+			dash.ID = 1
+			dash.UID = "1"
+			dash.OrgID = 1
+			dash.Version = 3
+			dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(dash, nil).Maybe()
+			hs.DashboardService = dashSvc
+
+			hs.Cfg = setting.NewCfg()
+			hs.Cfg.DashboardRequireIfMatchOrgs = map[int64]bool{1: requireIfMatch}
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+			hs.starService = startest.NewStarServiceFake()
+			hs.dashboardProvisioningService = mockDashboardProvisioningService{}
+			hs.LibraryPanelService = &mockLibraryPanelService{}
+			hs.log = log.New("test-logger")
+
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+		return server, dashSvc
+	}
+
+	permissions := []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"},
+		{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:1"},
+	}
+
+	getDashboard := func(t *testing.T, server *webtest.Server, ifNoneMatch string) *http.Response {
+		req := server.NewGetRequest("/api/dashboards/uid/1")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+
+	postDashboard := func(t *testing.T, server *webtest.Server, ifMatch string) *http.Response {
+		req := server.NewPostRequest("/api/dashboards/db", strings.NewReader(`{"dashboard": {"id": 1, "uid": "1", "title": "some dash", "version": 1}, "overwrite": true}`))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+
+	t.Run("Should return the ETag of the dashboard version", func(t *testing.T) {
+		server, _ := setup(t, false)
+
+		res := getDashboard(t, server, "")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `"1-3"`, res.Header.Get("ETag"))
+	})
+
+	t.Run("Should return not modified when If-None-Match matches", func(t *testing.T) {
+		server, _ := setup(t, false)
+
+		res := getDashboard(t, server, `"1-2", "1-3"`)
+		assert.Equal(t, http.StatusNotModified, res.StatusCode)
+		assert.Equal(t, `"1-3"`, res.Header.Get("ETag"))
+
+		res = getDashboard(t, server, `"1-2"`)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Should reject the update when If-Match does not match", func(t *testing.T) {
+		server, dashSvc := setup(t, false)
+
+		res := postDashboard(t, server, `"1-2"`)
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+		assert.Equal(t, `"1-3"`, res.Header.Get("ETag"))
+		dashSvc.AssertNotCalled(t, "SaveDashboard", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Should save the version of If-Match without overwrite", func(t *testing.T) {
+		server, dashSvc := setup(t, false)
+		dashSvc.On("SaveDashboard", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+			return dto.Dashboard.Version == 3 && !dto.Overwrite
+		}), mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1", Version: 4}, nil)
+
+		res := postDashboard(t, server, `"1-3"`)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `"1-4"`, res.Header.Get("ETag"))
+	})
+
+	t.Run("Should require If-Match in the orgs of require_if_match_orgs", func(t *testing.T) {
+		server, dashSvc := setup(t, true)
+
+		res := postDashboard(t, server, "")
+		assert.Equal(t, http.StatusPreconditionRequired, res.StatusCode)
+		dashSvc.AssertNotCalled(t, "SaveDashboard", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestHTTPServer_DeleteDashboardByUID_AccessControl(t *testing.T) {
 	setup := func() *webtest.Server {
 		return SetupAPITestServer(t, func(hs *HTTPServer) {
@@ -373,6 +539,102 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_GetDashboardQueryStats_AccessControl(t *testing.T) {
+	stats := []query.PanelResultStats{{PanelID: "2", Requests: 1, Queries: 2, Rows: 10, Bytes: 160}}
+	setup := func() *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			dash := dashboards.NewDashboard("some dash")
+			dash.ID = 1
+			dash.UID = "1"
+
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(dash, nil).Maybe()
+			hs.DashboardService = dashSvc
+
+			qds := &query.FakeQueryService{}
+			qds.On("GetDashboardResultStats", mock.Anything, "1").Return(stats).Maybe()
+			hs.queryDataService = qds
+
+			hs.Cfg = setting.NewCfg()
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+	}
+
+	getQueryStats := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/query-stats"), userWithPermissions(1, permissions)))
+	}
+
+	t.Run("Should not be able to get query stats without correct permission", func(t *testing.T) {
+		server := setup()
+
+		res, err := getQueryStats(server, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Should be able to get query stats when user has permission to read dashboard", func(t *testing.T) {
+		server := setup()
+
+		res, err := getQueryStats(server, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var data []query.PanelResultStats
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+		assert.Equal(t, stats, data)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestHTTPServer_GetPanelQueryErrors(t *testing.T) {
+	stats := []query.PanelErrorStats{
+		{DashboardUID: "1", PanelID: "2", Requests: 4, Errors: 4, ErrorRate: 1},
+		{DashboardUID: "2", PanelID: "1", Requests: 2, Errors: 1, ErrorRate: 0.5},
+		{DashboardUID: "1", PanelID: "3", Requests: 4, Errors: 1, ErrorRate: 0.25},
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		qds := &query.FakeQueryService{}
+		qds.On("GetPanelErrorStats", mock.Anything, int64(1)).Return(stats, nil).Maybe()
+		hs.queryDataService = qds
+
+		hs.Cfg = setting.NewCfg()
+		hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+	})
+
+	getQueryErrors := func(t *testing.T, url string, permissions []accesscontrol.Permission) ([]query.PanelErrorStats, int) {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest(url), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		var data []query.PanelErrorStats
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+		}
+		return data, res.StatusCode
+	}
+
+	t.Run("Should not be able to get query errors without permission to read dashboards", func(t *testing.T) {
+		_, code := getQueryErrors(t, "/api/dashboards/query-errors", nil)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Should only return the panels of the dashboards the user can read", func(t *testing.T) {
+		data, code := getQueryErrors(t, "/api/dashboards/query-errors", []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []query.PanelErrorStats{stats[0], stats[2]}, data)
+	})
+
+	t.Run("Should limit the number of panels", func(t *testing.T) {
+		data, code := getQueryErrors(t, "/api/dashboards/query-errors?limit=2", []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsAll}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, stats[:2], data)
+	})
+}
+
 func TestDashboardAPIEndpoint(t *testing.T) {
 	t.Run("Given two dashboards with the same title in different folders", func(t *testing.T) {
 		dashOne := dashboards.NewDashboard("dash")
@@ -576,6 +838,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 		dashboardService := dashboards.NewFakeDashboardService(t)
 		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+		dashboardService.On("DecryptSecureOptions", mock.Anything, mock.AnythingOfType("*simplejson.Json")).Return(nil)
 		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
 			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
 			cmd.Dashboard = &dashboards.Dashboard{
@@ -616,6 +879,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 		dashboardService := dashboards.NewFakeDashboardService(t)
 		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+		dashboardService.On("DecryptSecureOptions", mock.Anything, mock.AnythingOfType("*simplejson.Json")).Return(nil)
 		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
 			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
 			cmd.Dashboard = &dashboards.Dashboard{
@@ -843,14 +1107,14 @@ func getDashboardShouldReturn200WithConfig(t *testing.T, sc *scenarioContext, pr
 	if dashboardService == nil {
 		dashboardService, err = service.ProvideDashboardServiceImpl(
 			cfg, dashboardStore, folderStore, nil, features, folderPermissions, dashboardPermissions,
-			ac, folderSvc, nil,
+			ac, folderSvc, nil, nil,
 		)
 		require.NoError(t, err)
 	}
 
 	dashboardProvisioningService, err := service.ProvideDashboardServiceImpl(
 		cfg, dashboardStore, folderStore, nil, features, folderPermissions, dashboardPermissions,
-		ac, folderSvc, nil,
+		ac, folderSvc, nil, nil,
 	)
 	require.NoError(t, err)
 
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RotateDataSourceCredentials replaces the credentials of a data source with
+// the ones of the secrets provider once they are verified.
+func (hs *HTTPServer) RotateDataSourceCredentials(c *contextmodel.ReqContext) response.Response {
+	ds, err := hs.DataSourcesService.GetDataSource(c.Req.Context(), &datasources.GetDataSourceQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasources", err)
+	}
+
+	if ds.Share != nil {
+		return response.Err(datasources.ErrDataSourceIsShared)
+	}
+
+	result, err := hs.dataSourceRotation.Rotate(c.Req.Context(), ds)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to rotate data source credentials", err)
+	}
+
+	if result.Rotated {
+		hs.Live.HandleDatasourceUpdate(ds.OrgID, ds.UID)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// AdminRotateDataSourcesCredentials rotates the credentials of all the data
+// sources the secrets provider has credentials for.
+func (hs *HTTPServer) AdminRotateDataSourcesCredentials(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.dataSourceRotation.RotateAll(c.Req.Context()))
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetDataSourceShares returns the orgs a data source of the org is shared with.
+func (hs *HTTPServer) GetDataSourceShares(c *contextmodel.ReqContext) response.Response {
+	shares, err := hs.DataSourcesService.GetDataSourceShares(c.Req.Context(), &datasources.GetDataSourceSharesQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return dataSourceShareError(err, "Failed to get data source shares")
+	}
+	return response.JSON(http.StatusOK, shares)
+}
+
+// ShareDataSource shares a data source of the org with another org, or
+// updates the minimum role required to query it in that org.
+func (hs *HTTPServer) ShareDataSource(c *contextmodel.ReqContext) response.Response {
+	cmd := datasources.ShareDataSourceCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	share, err := hs.DataSourcesService.ShareDataSource(c.Req.Context(), &cmd)
+	if err != nil {
+		return dataSourceShareError(err, "Failed to share data source")
+	}
+	return response.JSON(http.StatusOK, share)
+}
+
+// UnshareDataSource stops sharing a data source of the org with another org.
+func (hs *HTTPServer) UnshareDataSource(c *contextmodel.ReqContext) response.Response {
+	consumerOrgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	err = hs.DataSourcesService.UnshareDataSource(c.Req.Context(), &datasources.UnshareDataSourceCommand{
+		UID:           web.Params(c.Req)[":uid"],
+		OrgID:         c.SignedInUser.GetOrgID(),
+		ConsumerOrgID: consumerOrgID,
+	})
+	if err != nil {
+		return dataSourceShareError(err, "Failed to unshare data source")
+	}
+	return response.Success("Data source unshared")
+}
+
+func dataSourceShareError(err error, message string) response.Response {
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return response.Error(http.StatusNotFound, "Data source not found", nil)
+	}
+	if errors.Is(err, datasources.ErrDataSourceIdentifierNotSet) {
+		return response.Error(http.StatusBadRequest, "Datasource uid is missing", nil)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetDataSourceSecureJSONDataVersions returns the previous versions of the
+// secure json data of a data source of the org, without their values.
+func (hs *HTTPServer) GetDataSourceSecureJSONDataVersions(c *contextmodel.ReqContext) response.Response {
+	versions, err := hs.DataSourcesService.GetSecureJSONDataVersions(c.Req.Context(), &datasources.GetSecureJSONDataVersionsQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return dataSourceVersionError(err, "Failed to get the versions of the data source secure json data")
+	}
+	return response.JSON(http.StatusOK, versions)
+}
+
+// RestoreDataSourceSecureJSONDataVersion replaces the secure json data of a
+// data source of the org with one of its previous versions.
+func (hs *HTTPServer) RestoreDataSourceSecureJSONDataVersion(c *contextmodel.ReqContext) response.Response {
+	cmd := datasources.RestoreSecureJSONDataVersionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+
+	ds, err := hs.DataSourcesService.RestoreSecureJSONDataVersion(c.Req.Context(), &cmd)
+	if err != nil {
+		return dataSourceVersionError(err, "Failed to restore the data source secure json data")
+	}
+
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        ds.OrgID,
+		Action:       auditlog.ActionDataSourceUpdate,
+		ResourceType: auditlog.ResourceTypeDataSource,
+		ResourceID:   ds.UID,
+		After:        map[string]any{"secureJsonDataVersion": cmd.Version},
+	})
+	hs.Live.HandleDatasourceUpdate(ds.OrgID, ds.UID)
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":    "Data source secure json data restored",
+		"datasource": hs.convertModelToDtos(c.Req.Context(), ds),
+	})
+}
+
+func dataSourceVersionError(err error, message string) response.Response {
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return response.Error(http.StatusNotFound, "Data source not found", nil)
+	}
+	if errors.Is(err, datasources.ErrDatasourceIsReadOnly) {
+		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
+	}
+	if errors.Is(err, datasources.ErrDataSourceUpdatingOldVersion) {
+		return response.Error(http.StatusConflict, "Datasource has already been updated by someone else. Please reload and try again", err)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+}
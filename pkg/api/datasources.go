@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -47,14 +48,20 @@ var secretsPluginError datasources.ErrDatasourceSecretsPluginUserFriendly
 // 403: forbiddenError
 // 500: internalServerError
 func (hs *HTTPServer) GetDataSources(c *contextmodel.ReqContext) response.Response {
-	query := datasources.GetDataSourcesQuery{OrgID: c.SignedInUser.GetOrgID(), DataSourceLimit: hs.Cfg.DataSourceLimit}
+	query := datasources.GetDataSourcesQuery{OrgID: c.SignedInUser.GetOrgID(), DataSourceLimit: hs.Cfg.DataSourceLimit, User: c.SignedInUser}
 
 	dataSources, err := hs.DataSourcesService.GetDataSources(c.Req.Context(), &query)
 	if err != nil {
 		return response.Error(500, "Failed to query datasources", err)
 	}
 
-	filtered, err := hs.dsGuardian.New(c.SignedInUser.OrgID, c.SignedInUser).FilterDatasourcesByQueryPermissions(dataSources)
+	var filtered []*datasources.DataSource
+	if hs.Cfg.DataSourceEnforceQueryPermissions {
+		// the metadata of data sources can be read without the permission to query them
+		filtered, err = hs.filterDataSourcesByReadPermissions(c, dataSources)
+	} else {
+		filtered, err = hs.dsGuardian.New(c.SignedInUser.OrgID, c.SignedInUser).FilterDatasourcesByQueryPermissions(dataSources)
+	}
 	if err != nil {
 		return response.Error(500, "Failed to query datasources", err)
 	}
@@ -75,7 +82,7 @@ func (hs *HTTPServer) GetDataSources(c *contextmodel.ReqContext) response.Respon
 			BasicAuth: ds.BasicAuth,
 			IsDefault: ds.IsDefault,
 			JsonData:  ds.JsonData,
-			ReadOnly:  ds.ReadOnly,
+			ReadOnly:  ds.ReadOnly || ds.Share != nil,
 		}
 
 		if plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), ds.Type); exists {
@@ -94,6 +101,20 @@ func (hs *HTTPServer) GetDataSources(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, &result)
 }
 
+func (hs *HTTPServer) filterDataSourcesByReadPermissions(c *contextmodel.ReqContext, dataSources []*datasources.DataSource) ([]*datasources.DataSource, error) {
+	filtered := make([]*datasources.DataSource, 0, len(dataSources))
+	for _, ds := range dataSources {
+		canRead, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(ds.UID)))
+		if err != nil {
+			return nil, err
+		}
+		if canRead {
+			filtered = append(filtered, ds)
+		}
+	}
+	return filtered, nil
+}
+
 // swagger:route GET /datasources/{id} datasources getDataSourceByID
 //
 // Get a single data source by Id.
@@ -180,6 +201,10 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if ds.Share != nil {
+		return response.Err(datasources.ErrDataSourceIsShared)
+	}
+
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -191,6 +216,7 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	hs.recordDataSourceDelete(c, ds)
 
 	return response.Success("Data source deleted")
 }
@@ -259,6 +285,10 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if ds.Share != nil {
+		return response.Err(datasources.ErrDataSourceIsShared)
+	}
+
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -270,6 +300,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	hs.recordDataSourceDelete(c, ds)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -310,6 +341,10 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if dataSource.Share != nil {
+		return response.Err(datasources.ErrDataSourceIsShared)
+	}
+
 	cmd := &datasources.DeleteDataSourceCommand{Name: name, OrgID: c.SignedInUser.GetOrgID()}
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
 	if err != nil {
@@ -320,6 +355,7 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), dataSource.UID)
+	hs.recordDataSourceDelete(c, dataSource)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -478,6 +514,14 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
 
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        dataSource.OrgID,
+		Action:       auditlog.ActionDataSourceCreate,
+		ResourceType: auditlog.ResourceTypeDataSource,
+		ResourceID:   dataSource.UID,
+		After:        dataSourceAuditSummary(dataSource),
+	})
+
 	ds := hs.convertModelToDtos(c.Req.Context(), dataSource)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource added",
@@ -616,6 +660,11 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		return response.Error(403, "Cannot update read-only data source", nil)
 	}
 
+	if ds.Share != nil {
+		return response.Err(datasources.ErrDataSourceIsShared)
+	}
+
+	cmd.UserID = c.SignedInUser.UserID
 	_, err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
 		if errors.Is(err, datasources.ErrDataSourceNameExists) {
@@ -646,6 +695,15 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		return response.Error(500, "Failed to query datasource", err)
 	}
 
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        dataSource.OrgID,
+		Action:       auditlog.ActionDataSourceUpdate,
+		ResourceType: auditlog.ResourceTypeDataSource,
+		ResourceID:   dataSource.UID,
+		Before:       dataSourceAuditSummary(ds),
+		After:        dataSourceAuditSummary(dataSource),
+	})
+
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), dataSource)
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
@@ -658,6 +716,16 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	})
 }
 
+func (hs *HTTPServer) recordDataSourceDelete(c *contextmodel.ReqContext, ds *datasources.DataSource) {
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        ds.OrgID,
+		Action:       auditlog.ActionDataSourceDelete,
+		ResourceType: auditlog.ResourceTypeDataSource,
+		ResourceID:   ds.UID,
+		Before:       dataSourceAuditSummary(ds),
+	})
+}
+
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
 	query := datasources.GetDataSourceQuery{
 		ID:    id,
@@ -839,7 +907,7 @@ func (hs *HTTPServer) convertModelToDtos(ctx context.Context, ds *datasources.Da
 		JsonData:         ds.JsonData,
 		SecureJsonFields: map[string]bool{},
 		Version:          ds.Version,
-		ReadOnly:         ds.ReadOnly,
+		ReadOnly:         ds.ReadOnly || ds.Share != nil,
 	}
 
 	if hs.pluginStore != nil {
@@ -19,6 +19,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auditlog/auditlogtest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
@@ -53,7 +54,7 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 			DataSourcesService: &dataSourcesServiceMock{
 				expectedDatasources: ds,
 			},
-			dsGuardian: guardian.ProvideGuardian(),
+			dsGuardian: guardian.ProvideGuardian(setting.NewCfg(), actest.FakeAccessControl{}),
 		}
 		sc.handlerFunc = hs.GetDataSources
 		sc.fakeReq("GET", "/api/datasources").exec()
@@ -117,6 +118,7 @@ func TestAddDataSource_URLWithoutProtocol(t *testing.T) {
 		Cfg:                  setting.NewCfg(),
 		AccessControl:        acimpl.ProvideAccessControl(setting.NewCfg()),
 		accesscontrolService: actest.FakeService{},
+		auditLogService:      auditlogtest.NewFakeService(),
 	}
 
 	sc := setupScenarioContext(t, "/api/datasources")
@@ -295,6 +297,7 @@ func TestUpdateDataSourceTeamHTTPHeaders_InvalidJSONData(t *testing.T) {
 					ExpectedEvaluate: true,
 					ExpectedErr:      nil,
 				},
+				auditLogService: auditlogtest.NewFakeService(),
 			}
 			sc := setupScenarioContext(t, fmt.Sprintf("/api/datasources/%s", tenantID))
 			hs.Cfg.AuthProxyEnabled = true
@@ -334,6 +337,7 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 		Cfg:                  setting.NewCfg(),
 		AccessControl:        acimpl.ProvideAccessControl(setting.NewCfg()),
 		accesscontrolService: actest.FakeService{},
+		auditLogService:      auditlogtest.NewFakeService(),
 	}
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
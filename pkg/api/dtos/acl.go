@@ -23,3 +23,42 @@ type DashboardACLUpdateItem struct {
 	// Enum: 1,2,4
 	Permission dashboardaccess.PermissionType `json:"permission"`
 }
+
+// Kinds of sources that can grant a user access to a dashboard.
+const (
+	PermissionSourceUser        = "user"
+	PermissionSourceTeam        = "team"
+	PermissionSourceBuiltInRole = "builtInRole"
+	PermissionSourceRole        = "role"
+)
+
+// swagger:model
+type DashboardEffectivePermissions struct {
+	UserID       int64  `json:"userId"`
+	UserLogin    string `json:"userLogin"`
+	DashboardUID string `json:"dashboardUid"`
+	// Resolved permission level of the user on the dashboard, empty if the user has no access.
+	Permission string `json:"permission"`
+	// Dashboard actions the user is allowed to perform.
+	Actions []string `json:"actions"`
+	// Sources granting the user access: permissions on the dashboard first, then permissions inherited
+	// from parent folders and finally the roles assigned to the user.
+	Chain []DashboardPermissionSource `json:"chain"`
+}
+
+// swagger:model
+type DashboardPermissionSource struct {
+	// Kind of the source, one of `user`, `team`, `builtInRole` or `role`.
+	Kind string `json:"kind"`
+	// Scope the permission is granted on.
+	Scope string `json:"scope,omitempty"`
+	// Inherited is set when the permission is granted on one of the parent folders.
+	Inherited bool   `json:"inherited"`
+	FolderUID string `json:"folderUid,omitempty"`
+	TeamID    int64  `json:"teamId,omitempty"`
+	Team      string `json:"team,omitempty"`
+	Role      string `json:"role,omitempty"`
+	// Permission level granted by the source, empty when it does not map to a level.
+	Permission string   `json:"permission,omitempty"`
+	Actions    []string `json:"actions"`
+}
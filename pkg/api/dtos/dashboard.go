@@ -35,6 +35,8 @@ type DashboardMeta struct {
 	AnnotationsPermissions *AnnotationPermission `json:"annotationsPermissions"`
 	PublicDashboardUID     string                `json:"publicDashboardUid,omitempty"`
 	PublicDashboardEnabled bool                  `json:"publicDashboardEnabled,omitempty"`
+	// Redacted is true when fields of the dashboard were removed by the redaction policy.
+	Redacted bool `json:"redacted,omitempty"`
 }
 type AnnotationPermission struct {
 	Dashboard    AnnotationActions `json:"dashboard"`
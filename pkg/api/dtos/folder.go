@@ -36,3 +36,31 @@ type FolderSearchHit struct {
 	Title     string `json:"title"`
 	ParentUID string `json:"parentUid,omitempty"`
 }
+
+// FolderTree is the tree of the folders a user can view, with the number of their dashboards and the
+// permissions of the user on each folder.
+type FolderTree struct {
+	// Folders are the folders at the root, and the folders whose parent folder the user cannot view.
+	Folders []*FolderTreeNode `json:"folders"`
+	// GeneralDashboardCount is the number of dashboards the user can view that are not in a folder.
+	GeneralDashboardCount int `json:"generalDashboardCount"`
+	// GeneralStarredDashboardCount is the number of dashboards starred by the user that are not in a folder.
+	GeneralStarredDashboardCount int `json:"generalStarredDashboardCount"`
+}
+
+type FolderTreeNode struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	ParentUID string `json:"parentUid,omitempty"`
+	// DashboardCount is the number of dashboards of the folder the user can view, without the dashboards of its subfolders.
+	DashboardCount int `json:"dashboardCount"`
+	// StarredDashboardCount is the number of dashboards of the folder starred by the user.
+	StarredDashboardCount int               `json:"starredDashboardCount"`
+	CanSave               bool              `json:"canSave"`
+	CanEdit               bool              `json:"canEdit"`
+	CanAdmin              bool              `json:"canAdmin"`
+	CanDelete             bool              `json:"canDelete"`
+	CanCreateDashboards   bool              `json:"canCreateDashboards"`
+	Children              []*FolderTreeNode `json:"children"`
+}
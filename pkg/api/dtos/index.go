@@ -25,6 +25,7 @@ type IndexViewData struct {
 	AppNameBodyClass                    string
 	FavIcon                             template.URL
 	AppleTouchIcon                      template.URL
+	MaskIcon                            template.URL
 	AppTitle                            string
 	ContentDeliveryURL                  string
 	LoadingLogo                         template.URL
@@ -39,11 +40,14 @@ type IndexViewData struct {
 
 type EntryPointAssets struct {
 	JSFiles  []EntryPointAsset
-	CSSDark  string
-	CSSLight string
+	CSSDark  EntryPointAsset
+	CSSLight EntryPointAsset
 }
 
 type EntryPointAsset struct {
 	FilePath  string
 	Integrity string
+	// URL is the URL the asset is loaded from. It is set when the index is
+	// rendered, as it can be on the CDN and signed.
+	URL string `json:",omitempty"`
 }
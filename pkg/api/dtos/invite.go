@@ -7,6 +7,24 @@ type AddInviteForm struct {
 	Name         string       `json:"name"`
 	Role         org.RoleType `json:"role" binding:"Required"`
 	SendEmail    bool         `json:"sendEmail"`
+	// SSOProvider is the auth module the invite must be accepted with, e.g. oauth_okta. The invite can be
+	// accepted with a password if it is empty.
+	SSOProvider string `json:"ssoProvider"`
+	// EmailDomain is the domain of the email address the invite must be accepted with.
+	EmailDomain string `json:"emailDomain"`
+	// ExpiresIn is how long the invite remains valid, e.g. 6h or 2d, the max lifetime of invites by default.
+	ExpiresIn string `json:"expiresIn"`
+}
+
+type BulkAddInviteForm struct {
+	Invites []AddInviteForm `json:"invites" binding:"Required"`
+}
+
+type BulkAddInviteResult struct {
+	LoginOrEmail string `json:"loginOrEmail"`
+	// Status is the HTTP status code of the invite, as if it was added on its own.
+	Status  int    `json:"status"`
+	Message string `json:"message"`
 }
 
 type InviteInfo struct {
@@ -14,6 +32,11 @@ type InviteInfo struct {
 	Name      string `json:"name"`
 	Username  string `json:"username"`
 	InvitedBy string `json:"invitedBy"`
+	// SSOProvider is the auth module the invite must be accepted with, and SSOProviderLabel its name.
+	SSOProvider      string `json:"ssoProvider,omitempty"`
+	SSOProviderLabel string `json:"ssoProviderLabel,omitempty"`
+	EmailDomain      string `json:"emailDomain,omitempty"`
+	Expires          int64  `json:"expires,omitempty"`
 }
 
 type CompleteInviteForm struct {
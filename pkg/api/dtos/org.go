@@ -1,5 +1,7 @@
 package dtos
 
+import "github.com/grafana/grafana/pkg/services/navlinks"
+
 type UpdateOrgForm struct {
 	Name string `json:"name" binding:"Required"`
 }
@@ -12,3 +14,18 @@ type UpdateOrgAddressForm struct {
 	State    string `json:"state"`
 	Country  string `json:"country"`
 }
+
+type UpdateOrgLoginSettingsForm struct {
+	LogoURL     string `json:"logoUrl"`
+	WelcomeText string `json:"welcomeText"`
+	// Providers are the auth providers shown on the login page, all of them when empty.
+	Providers            []string `json:"providers"`
+	AutoRedirectProvider string   `json:"autoRedirectProvider"`
+	// Hosts are the hosts serving the login page of the organization, unchanged when omitted.
+	Hosts []string `json:"hosts"`
+}
+
+type OrgNavLinks struct {
+	// Links are the additional navigation items of the organization, in menu order.
+	Links []*navlinks.Link `json:"links"`
+}
@@ -22,3 +22,10 @@ func (slice PlaylistDashboardsSlice) Less(i, j int) bool {
 func (slice PlaylistDashboardsSlice) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
+
+type PlaylistDeviceControlCmd struct {
+	// Action is one of next, previous, pause, resume and play.
+	Action string `json:"action" binding:"Required"`
+	// PlaylistUID is the playlist to play, required by the play action.
+	PlaylistUID string `json:"playlistUid"`
+}
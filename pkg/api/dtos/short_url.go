@@ -7,4 +7,18 @@ type ShortURL struct {
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// UID is an optional slug for the short URL, it requires the shorturls.slugs:create permission.
+	UID string `json:"uid,omitempty"`
+	// TTL is an optional duration after which the short URL expires, such as 7d or 12h.
+	TTL string `json:"ttl,omitempty"`
+}
+
+type ShortURLDetails struct {
+	UID        string `json:"uid"`
+	URL        string `json:"url"`
+	Path       string `json:"path"`
+	CreatedAt  int64  `json:"createdAt"`
+	LastSeenAt int64  `json:"lastSeenAt"`
+	ExpiresAt  int64  `json:"expiresAt"`
+	HitCount   int64  `json:"hitCount"`
 }
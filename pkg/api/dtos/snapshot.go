@@ -0,0 +1,6 @@
+package dtos
+
+type DeleteDashboardSnapshotsForm struct {
+	// The keys of the snapshots to delete
+	Keys []string `json:"keys" binding:"Required"`
+}
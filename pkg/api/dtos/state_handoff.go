@@ -0,0 +1,25 @@
+package dtos
+
+import "encoding/json"
+
+type CreateStateHandoffCmd struct {
+	// URL is the relative URL to return to after logging in.
+	URL string `json:"url"`
+	// State is opaque client state to restore after logging in.
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+type StateHandoffToken struct {
+	Token    string `json:"token"`
+	LoginURL string `json:"loginUrl"`
+}
+
+type RedeemStateHandoffCmd struct {
+	// Token is the token to redeem, the token of the state handoff cookie is redeemed when it is empty.
+	Token string `json:"token"`
+}
+
+type StateHandoff struct {
+	URL   string          `json:"url"`
+	State json.RawMessage `json:"state,omitempty"`
+}
@@ -0,0 +1,12 @@
+package dtos
+
+import "time"
+
+// UserIdentity is an identity of an authentication provider linked to a user.
+type UserIdentity struct {
+	AuthModule string `json:"authModule"`
+	AuthLabel  string `json:"authLabel"`
+	// IsPrimary is true for the identity that the user attributes and roles are synced from.
+	IsPrimary bool      `json:"isPrimary"`
+	Created   time.Time `json:"created"`
+}
@@ -5,8 +5,11 @@ import "time"
 type UserToken struct {
 	Id                     int64     `json:"id"`
 	IsActive               bool      `json:"isActive"`
+	IsCurrentDevice        bool      `json:"isCurrentDevice"`
 	ClientIp               string    `json:"clientIp"`
 	Device                 string    `json:"device"`
+	DeviceName             string    `json:"deviceName"`
+	DeviceId               string    `json:"deviceId,omitempty"`
 	OperatingSystem        string    `json:"os"`
 	OperatingSystemVersion string    `json:"osVersion"`
 	Browser                string    `json:"browser"`
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// EmailDeliveryEvents is the webhook of the delivery events of the email
+// provider. It is authenticated with the token of the configuration, as the
+// providers call it without a Grafana user.
+func (hs *HTTPServer) EmailDeliveryEvents(c *contextmodel.ReqContext) response.Response {
+	token := hs.Cfg.Smtp.EventsWebhookToken
+	if token == "" {
+		return response.Error(http.StatusNotFound, "Not found", nil)
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(token)) != 1 {
+		return response.Error(http.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	if err := hs.NotificationService.HandleDeliveryWebhook(c.Req); err != nil {
+		switch {
+		case errors.Is(err, notifications.ErrDeliveryEventsNotSupported):
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, notifications.ErrInvalidDeliveryEvents):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to handle delivery events", err)
+	}
+	return response.Success("Delivery events handled")
+}
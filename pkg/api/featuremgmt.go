@@ -30,10 +30,12 @@ func (hs *HTTPServer) GetFeatureToggles(ctx *contextmodel.ReqContext) response.R
 			continue
 		}
 		dto := featuremgmt.FeatureToggleDTO{
-			Name:        ft.Name,
-			Description: ft.Description,
-			Enabled:     enabledFeatures[ft.Name],
-			ReadOnly:    !isFeatureWriteable(ft, cfg.ReadOnlyToggles) || !isFeatureEditingAllowed(*hs.Cfg),
+			Name:          ft.Name,
+			Description:   ft.Description,
+			Enabled:       enabledFeatures[ft.Name],
+			ReadOnly:      !isFeatureWriteable(ft, cfg.ReadOnlyToggles) || !isFeatureEditingAllowed(*hs.Cfg),
+			DependsOn:     ft.DependsOn,
+			ConflictsWith: ft.ConflictsWith,
 		}
 
 		dtos = append(dtos, dto)
@@ -64,18 +66,26 @@ func (hs *HTTPServer) UpdateFeatureToggle(ctx *contextmodel.ReqContext) response
 		FeatureToggles: make(map[string]string, len(cmd.FeatureToggles)),
 		User:           ctx.SignedInUser.Email,
 	}
+	// the toggles enabled once the update is applied
+	enabled := hs.Features.GetEnabled(ctx.Req.Context())
 
 	for _, t := range cmd.FeatureToggles {
 		// make sure flag exists, and only continue if flag is writeable
 		if f, ok := hs.Features.LookupFlag(t.Name); ok && isFeatureWriteable(f, hs.Cfg.FeatureManagement.ReadOnlyToggles) {
 			hs.log.Info("UpdateFeatureToggle: updating toggle", "toggle_name", t.Name, "enabled", t.Enabled, "username", ctx.SignedInUser.Login)
 			payload.FeatureToggles[t.Name] = strconv.FormatBool(t.Enabled)
+			enabled[t.Name] = t.Enabled
 		} else {
 			hs.log.Warn("UpdateFeatureToggle: invalid toggle passed in", "toggle_name", t.Name)
 			return response.Error(http.StatusBadRequest, "invalid toggle passed in", fmt.Errorf("invalid toggle passed in: %s", t.Name))
 		}
 	}
 
+	if err := hs.Features.ValidateEnabled(enabled); err != nil {
+		hs.log.Warn("UpdateFeatureToggle: unsupported combination of toggles", "error", err)
+		return response.Err(err)
+	}
+
 	err := sendWebhookUpdate(featureMgmtCfg, payload, hs.log)
 	if err != nil {
 		hs.log.Error("UpdateFeatureToggle: Failed to perform webhook request", "error", err)
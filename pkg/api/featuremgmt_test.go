@@ -211,6 +211,39 @@ func TestSetFeatureToggles(t *testing.T) {
 		assert.Equal(t, "feature toggles service is misconfigured", p["message"])
 	})
 
+	t.Run("fails when the update breaks a dependency between toggles", func(t *testing.T) {
+		features := []*featuremgmt.FeatureFlag{
+			{
+				Name:           "toggle1",
+				Enabled:        true,
+				Stage:          featuremgmt.FeatureStageGeneralAvailability,
+				AllowSelfServe: truePtr,
+			}, {
+				Name:           "toggle2",
+				Enabled:        true,
+				Stage:          featuremgmt.FeatureStageGeneralAvailability,
+				AllowSelfServe: truePtr,
+				DependsOn:      []string{"toggle1"},
+			},
+		}
+
+		updates := []featuremgmt.FeatureToggleDTO{
+			{
+				Name:    "toggle1",
+				Enabled: false,
+			},
+		}
+
+		s := setting.FeatureMgmtSettings{
+			AllowEditing:  true,
+			UpdateWebhook: "random",
+		}
+		res := runSetScenario(t, features, updates, s, writePermissions, http.StatusBadRequest)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		p := readBody(t, res.Body)
+		assert.Equal(t, "Feature toggle toggle2 requires the feature toggle toggle1 to be enabled", p["message"])
+	})
+
 	t.Run("fails with non-existent toggle", func(t *testing.T) {
 		features := []*featuremgmt.FeatureFlag{
 			{
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+)
+
+// folderTreePageSize is the number of folders or dashboards fetched per search query when building the folder tree.
+const folderTreePageSize = 5000
+
+// swagger:route GET /folders/tree folders getFolderTree
+//
+// Gets the tree of the folders the user can view.
+//
+// Returns the folders with the number of dashboards the user can view and has starred in each folder, and the
+// permissions of the user on each folder, so that the whole tree can be shown without a request per folder.
+//
+// Responses:
+// 200: getFolderTreeResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderTree(c *contextmodel.ReqContext) response.Response {
+	folders, err := hs.searchAllHits(c, model.DashHitFolder)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search folders", err)
+	}
+	dashboardHits, err := hs.searchAllHits(c, model.DashHitDB)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search dashboards", err)
+	}
+
+	tree := dtos.FolderTree{Folders: []*dtos.FolderTreeNode{}}
+	nodes := make(map[string]*dtos.FolderTreeNode, len(folders))
+	uids := make(map[string]bool, len(folders))
+	for _, hit := range folders {
+		nodes[hit.UID] = &dtos.FolderTreeNode{
+			UID:       hit.UID,
+			Title:     hit.Title,
+			URL:       hit.URL,
+			ParentUID: hit.FolderUID,
+			Children:  []*dtos.FolderTreeNode{},
+		}
+		uids[hit.UID] = true
+	}
+
+	for _, hit := range dashboardHits {
+		if node, ok := nodes[hit.FolderUID]; ok {
+			node.DashboardCount++
+			if hit.IsStarred {
+				node.StarredDashboardCount++
+			}
+			continue
+		}
+		// The dashboards of the folders the user cannot view are not counted.
+		if hit.FolderUID == "" {
+			tree.GeneralDashboardCount++
+			if hit.IsStarred {
+				tree.GeneralStarredDashboardCount++
+			}
+		}
+	}
+
+	// The permissions of the user on all folders are computed at once from the permissions of the request.
+	metadata := accesscontrol.GetResourcesMetadata(c.Req.Context(), c.SignedInUser.GetPermissions(), dashboards.ScopeFoldersPrefix, uids)
+	for _, hit := range folders {
+		node := nodes[hit.UID]
+		actions := inheritedFolderActions(node, nodes, metadata)
+		node.CanSave = actions[dashboards.ActionFoldersWrite]
+		node.CanEdit = actions[dashboards.ActionFoldersWrite] || hs.Cfg.ViewersCanEdit
+		node.CanAdmin = actions[dashboards.ActionFoldersPermissionsRead] && actions[dashboards.ActionFoldersPermissionsWrite]
+		node.CanDelete = actions[dashboards.ActionFoldersDelete]
+		node.CanCreateDashboards = actions[dashboards.ActionDashboardsCreate]
+
+		if parent, ok := nodes[node.ParentUID]; ok && node.ParentUID != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Folders = append(tree.Folders, node)
+		}
+	}
+
+	return response.JSON(http.StatusOK, tree)
+}
+
+// inheritedFolderActions returns the actions the user can perform on a folder, including the actions inherited
+// from the parent folders the user can view.
+func inheritedFolderActions(node *dtos.FolderTreeNode, nodes map[string]*dtos.FolderTreeNode, metadata map[string]accesscontrol.Metadata) accesscontrol.Metadata {
+	actions := accesscontrol.Metadata{}
+	visited := map[string]bool{}
+	for node != nil && !visited[node.UID] {
+		visited[node.UID] = true
+		for action := range metadata[node.UID] {
+			actions[action] = true
+		}
+		node = nodes[node.ParentUID]
+	}
+	return actions
+}
+
+// searchAllHits returns all the folders or dashboards the user can view, sorted by title.
+func (hs *HTTPServer) searchAllHits(c *contextmodel.ReqContext, hitType model.HitType) (model.HitList, error) {
+	var hits model.HitList
+	for page := int64(1); ; page++ {
+		result, err := hs.SearchService.SearchHandler(c.Req.Context(), &search.Query{
+			SignedInUser: c.SignedInUser,
+			OrgId:        c.SignedInUser.GetOrgID(),
+			Type:         string(hitType),
+			Permission:   dashboardaccess.PERMISSION_VIEW,
+			Limit:        folderTreePageSize,
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, result...)
+		if len(result) < folderTreePageSize {
+			return hits, nil
+		}
+	}
+}
+
+// swagger:response getFolderTreeResponse
+type GetFolderTreeResponse struct {
+	// in: body
+	Body dtos.FolderTree `json:"body"`
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type folderTreeSearchService struct {
+	hits    map[string]model.HitList
+	queries []*search.Query
+}
+
+func (f *folderTreeSearchService) SearchHandler(_ context.Context, q *search.Query) (model.HitList, error) {
+	f.queries = append(f.queries, q)
+	if q.Page > 1 {
+		return model.HitList{}, nil
+	}
+	return f.hits[q.Type], nil
+}
+
+func (f *folderTreeSearchService) SortOptions() []model.SortOption { return nil }
+
+func TestGetFolderTree(t *testing.T) {
+	searchService := &folderTreeSearchService{hits: map[string]model.HitList{
+		string(model.DashHitFolder): {
+			{UID: "prod", Title: "Production", URL: "/dashboards/f/prod/production", Type: model.DashHitFolder},
+			{UID: "prod-db", Title: "Databases", URL: "/dashboards/f/prod-db/databases", Type: model.DashHitFolder, FolderUID: "prod"},
+			{UID: "orphan", Title: "Orphan", Type: model.DashHitFolder, FolderUID: "hidden"},
+		},
+		string(model.DashHitDB): {
+			{UID: "a", Type: model.DashHitDB, FolderUID: "prod", IsStarred: true},
+			{UID: "b", Type: model.DashHitDB, FolderUID: "prod"},
+			{UID: "c", Type: model.DashHitDB, FolderUID: "prod-db"},
+			{UID: "d", Type: model.DashHitDB, IsStarred: true},
+			{UID: "e", Type: model.DashHitDB, FolderUID: "hidden"},
+		},
+	}}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.SearchService = searchService
+	})
+
+	req := server.NewGetRequest("/api/folders/tree")
+	webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: accesscontrol.GroupScopesByAction([]accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+			{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("prod")},
+			{Action: dashboards.ActionFoldersDelete, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("prod-db")},
+			{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("orphan")},
+		}),
+	}})
+	res, err := server.Send(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	defer func() { require.NoError(t, res.Body.Close()) }()
+
+	tree := dtos.FolderTree{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tree))
+
+	assert.Equal(t, 1, tree.GeneralDashboardCount)
+	assert.Equal(t, 1, tree.GeneralStarredDashboardCount)
+	require.Len(t, tree.Folders, 2)
+
+	prod := tree.Folders[0]
+	assert.Equal(t, "prod", prod.UID)
+	assert.Equal(t, 2, prod.DashboardCount)
+	assert.Equal(t, 1, prod.StarredDashboardCount)
+	assert.True(t, prod.CanSave)
+	assert.True(t, prod.CanEdit)
+	assert.False(t, prod.CanDelete)
+	assert.False(t, prod.CanAdmin)
+
+	require.Len(t, prod.Children, 1)
+	db := prod.Children[0]
+	assert.Equal(t, "prod-db", db.UID)
+	assert.Equal(t, 1, db.DashboardCount)
+	assert.Zero(t, db.StarredDashboardCount)
+	// The permissions on the parent folders are inherited.
+	assert.True(t, db.CanSave)
+	assert.True(t, db.CanDelete)
+	assert.False(t, db.CanCreateDashboards)
+
+	orphan := tree.Folders[1]
+	assert.Equal(t, "orphan", orphan.UID)
+	assert.Zero(t, orphan.DashboardCount)
+	assert.False(t, orphan.CanSave)
+	assert.True(t, orphan.CanCreateDashboards)
+	assert.Empty(t, orphan.Children)
+
+	// One search per kind, without a request per folder.
+	require.Len(t, searchService.queries, 2)
+	for _, q := range searchService.queries {
+		assert.Equal(t, int64(folderTreePageSize), q.Limit)
+		assert.Equal(t, int64(1), q.OrgId)
+	}
+}
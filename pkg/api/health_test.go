@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/registry/backgroundsvcs/readiness"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -59,6 +60,34 @@ func TestHealthAPI_VersionEnterprise(t *testing.T) {
 	require.JSONEq(t, expectedBody, rec.Body.String())
 }
 
+func TestHealthAPI_Services(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t, func(cfg *setting.Cfg) {
+		cfg.BuildVersion = "7.4.0"
+		cfg.BuildCommit = "59906ab1bf"
+	})
+	hs.serviceReadiness = readiness.ProvideTracker()
+	hs.serviceReadiness.Set("*api.HTTPServer", readiness.StateReady, "")
+	hs.serviceReadiness.Set("*ngalert.AlertNG", readiness.StateFailed, "failed to load alert rules")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	expectedBody := `
+		{
+			"database": "ok",
+			"version": "7.4.0",
+			"commit": "59906ab1bf",
+			"services": {
+				"*api.HTTPServer": {"state": "ready"},
+				"*ngalert.AlertNG": {"state": "failed", "reason": "failed to load alert rules"}
+			}
+		}
+	`
+	require.JSONEq(t, expectedBody, rec.Body.String())
+}
+
 func TestHealthAPI_AnonymousHideVersion(t *testing.T) {
 	m, hs := setupHealthAPITestEnvironment(t)
 	hs.Cfg.AnonymousHideVersion = true
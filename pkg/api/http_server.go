@@ -42,26 +42,38 @@ import (
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/csrf"
+	"github.com/grafana/grafana/pkg/middleware/loadshedding"
 	"github.com/grafana/grafana/pkg/middleware/loggermw"
+	"github.com/grafana/grafana/pkg/middleware/ratelimit"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
+	"github.com/grafana/grafana/pkg/registry/backgroundsvcs/readiness"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/comments"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/apiusage"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
+	"github.com/grafana/grafana/pkg/services/datasources/rotation"
+	"github.com/grafana/grafana/pkg/services/diagnostics"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/explorepanels"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/hooks"
@@ -72,11 +84,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/login"
 	loginAttempt "github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+	"github.com/grafana/grafana/pkg/services/navlinks"
 	"github.com/grafana/grafana/pkg/services/navtree"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
@@ -89,6 +104,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/searchusers"
@@ -172,6 +188,18 @@ type HTTPServer struct {
 	secretsPluginMigrator        spm.SecretMigrationProvider
 	DataSourcesService           datasources.DataSourceService
 	cleanUpService               *cleanup.CleanUpService
+	serviceReadiness             *readiness.Tracker
+	drainService                 *drain.Service
+	dataSourceRotation           *rotation.Service
+	auditLogService              auditlog.Service
+	passwordPolicy               passwordpolicy.Service
+	apiUsageService              apiusage.Service
+	savedSearchService           savedsearch.Service
+	rateLimiter                  *ratelimit.RateLimiter
+	loadShedder                  *loadshedding.LoadShedder
+	diagnosticsService           *diagnostics.Service
+	loginSettingsService         loginsettings.Service
+	navLinksService              navlinks.Service
 	tracer                       tracing.Tracer
 	grafanaUpdateChecker         *updatechecker.GrafanaService
 	pluginsUpdateChecker         *updatechecker.PluginsService
@@ -193,6 +221,9 @@ type HTTPServer struct {
 	folderPermissionsService     accesscontrol.FolderPermissionsService
 	dashboardPermissionsService  accesscontrol.DashboardPermissionsService
 	dashboardVersionService      dashver.Service
+	dashboardVariableService     dashboardvariables.Service
+	dashboardSchemaService       dashboardschema.Service
+	explorePanelService          explorepanels.Service
 	PublicDashboardsApi          *publicdashboardsApi.Api
 	starService                  star.Service
 	playlistService              playlist.Service
@@ -207,6 +238,7 @@ type HTTPServer struct {
 	teamService          team.Service
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
+	annotationComments   *comments.Service
 	tagService           tag.Service
 	oauthTokenService    oauthtoken.OAuthTokenService
 	statsService         stats.Service
@@ -217,6 +249,11 @@ type HTTPServer struct {
 	clientConfigProvider grafanaapiserver.DirectRestConfigProvider
 	namespacer           request.NamespaceMapper
 	anonService          anonymous.Service
+
+	// openAPISpec is generated once, when it is first requested.
+	openAPIOnce sync.Once
+	openAPISpec []byte
+	openAPIErr  error
 }
 
 type ServerOptions struct {
@@ -249,6 +286,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	avatarCacheServer *avatar.AvatarCacheServer, preferenceService pref.Service,
 	folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, dashboardVersionService dashver.Service,
+	dashboardVariableService dashboardvariables.Service, dashboardSchemaService dashboardschema.Service,
+	explorePanelService explorepanels.Service,
 	starService star.Service, csrfService csrf.Service,
 	playlistService playlist.Service, apiKeyService apikey.Service, kvStore kvstore.KVStore,
 	secretsMigrator secrets.Migrator, secretsPluginManager plugins.SecretsPluginManager, secretsService secrets.Service,
@@ -256,9 +295,13 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	publicDashboardsApi *publicdashboardsApi.Api, userService user.Service, tempUserService tempUser.Service,
 	loginAttemptService loginAttempt.Service, orgService org.Service, teamService team.Service,
 	accesscontrolService accesscontrol.Service, navTreeService navtree.Service,
-	annotationRepo annotations.Repository, tagService tag.Service, searchv2HTTPService searchV2.SearchHTTPService, oauthTokenService oauthtoken.OAuthTokenService,
+	annotationRepo annotations.Repository, annotationComments *comments.Service, tagService tag.Service, searchv2HTTPService searchV2.SearchHTTPService, oauthTokenService oauthtoken.OAuthTokenService,
 	statsService stats.Service, authnService authn.Service, pluginsCDNService *pluginscdn.Service, promGatherer prometheus.Gatherer,
 	starApi *starApi.API, promRegister prometheus.Registerer, clientConfigProvider grafanaapiserver.DirectRestConfigProvider, anonService anonymous.Service,
+	serviceReadiness *readiness.Tracker, drainService *drain.Service, dataSourceRotation *rotation.Service,
+	auditLogService auditlog.Service, rateLimiter *ratelimit.RateLimiter, loadShedder *loadshedding.LoadShedder,
+	loginSettingsService loginsettings.Service, navLinksService navlinks.Service, diagnosticsService *diagnostics.Service,
+	passwordPolicy passwordpolicy.Service, apiUsageService apiusage.Service, savedSearchService savedsearch.Service,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -287,6 +330,18 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		DataSourceCache:              dataSourceCache,
 		AuthTokenService:             userTokenService,
 		cleanUpService:               cleanUpService,
+		serviceReadiness:             serviceReadiness,
+		drainService:                 drainService,
+		dataSourceRotation:           dataSourceRotation,
+		auditLogService:              auditLogService,
+		passwordPolicy:               passwordPolicy,
+		apiUsageService:              apiUsageService,
+		savedSearchService:           savedSearchService,
+		rateLimiter:                  rateLimiter,
+		loadShedder:                  loadShedder,
+		diagnosticsService:           diagnosticsService,
+		loginSettingsService:         loginSettingsService,
+		navLinksService:              navLinksService,
 		ShortURLService:              shortURLService,
 		QueryHistoryService:          queryHistoryService,
 		CorrelationsService:          correlationsService,
@@ -337,6 +392,9 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		folderPermissionsService:     folderPermissionsService,
 		dashboardPermissionsService:  dashboardPermissionsService,
 		dashboardVersionService:      dashboardVersionService,
+		dashboardVariableService:     dashboardVariableService,
+		dashboardSchemaService:       dashboardSchemaService,
+		explorePanelService:          explorePanelService,
 		starService:                  starService,
 		playlistService:              playlistService,
 		apiKeyService:                apiKeyService,
@@ -350,6 +408,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		navTreeService:               navTreeService,
 		accesscontrolService:         accesscontrolService,
 		annotationsRepo:              annotationRepo,
+		annotationComments:           annotationComments,
 		tagService:                   tagService,
 		oauthTokenService:            oauthTokenService,
 		statsService:                 statsService,
@@ -647,6 +706,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m := hs.web
 
 	m.Use(requestmeta.SetupRequestMetadata())
+	m.Use(middleware.ClientIP(hs.Cfg))
 	m.Use(middleware.RequestTracing(hs.tracer))
 	m.Use(middleware.RequestMetrics(hs.Features, hs.Cfg, hs.promRegister))
 
@@ -656,7 +716,9 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		m.UseMiddleware(middleware.Gziper())
 	}
 
+	m.UseMiddleware(middleware.ErrorCode(hs.Cfg))
 	m.UseMiddleware(middleware.Recovery(hs.Cfg))
+	m.UseMiddleware(hs.loadShedder.Middleware())
 	m.UseMiddleware(hs.Csrf.Middleware())
 
 	hs.mapStatic(m, hs.Cfg.StaticRootPath, "build", "public/build")
@@ -690,6 +752,9 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.UseMiddleware(hs.ContextHandler.Middleware)
 	m.Use(middleware.OrgRedirect(hs.Cfg, hs.userService))
+	// needs to be after context handler to count the requests by org, user or token
+	m.Use(hs.rateLimiter.Middleware())
+	m.Use(hs.apiUsageService.Middleware())
 
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
@@ -741,7 +806,8 @@ func (hs *HTTPServer) healthzHandler(ctx *web.Context) {
 
 // apiHealthHandler will return ok if Grafana's web server is running and it
 // can access the database. If the database cannot be accessed it will return
-// http status code 503.
+// http status code 503. Unless the version is hidden, it also reports the
+// readiness of the background services.
 func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
 	if notHeadOrGet || ctx.Req.URL.Path != "/api/health" {
@@ -756,6 +822,9 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 		if hs.Cfg.EnterpriseBuildCommit != "NA" && hs.Cfg.EnterpriseBuildCommit != "" {
 			data.Set("enterpriseCommit", hs.Cfg.EnterpriseBuildCommit)
 		}
+		if hs.serviceReadiness != nil {
+			data.Set("services", hs.serviceReadiness.Statuses())
+		}
 	}
 
 	if !hs.databaseHealthy(ctx.Req.Context()) {
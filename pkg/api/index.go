@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"strings"
 
@@ -99,7 +100,7 @@ func (hs *HTTPServer) setIndexViewData(c *contextmodel.ReqContext) (*dtos.IndexV
 
 	hasAccess := ac.HasAccess(hs.AccessControl, c)
 	hasEditPerm := hasAccess(ac.EvalAny(ac.EvalPermission(dashboards.ActionDashboardsCreate), ac.EvalPermission(dashboards.ActionFoldersCreate)))
-	cdnURL, err := hs.Cfg.GetContentDeliveryURL(hs.License.ContentDeliveryPrefix())
+	cdn, err := webassets.NewContentDelivery(hs.Cfg, hs.License.ContentDeliveryPrefix())
 	if err != nil {
 		return nil, err
 	}
@@ -148,10 +149,11 @@ func (hs *HTTPServer) setIndexViewData(c *contextmodel.ReqContext) (*dtos.IndexV
 		AppTitle:                            "Grafana",
 		NavTree:                             navTree,
 		Nonce:                               c.RequestNonce,
-		ContentDeliveryURL:                  cdnURL,
+		ContentDeliveryURL:                  cdn.BaseURL(),
+		MaskIcon:                            template.URL(cdn.URL("public/img/grafana_mask_icon.svg")),
 		LoadingLogo:                         "public/img/grafana_icon.svg",
 		IsDevelopmentEnv:                    hs.Cfg.Env == setting.Dev,
-		Assets:                              assets,
+		Assets:                              cdn.Resolve(assets),
 	}
 
 	if hs.Cfg.CSPEnabled {
@@ -13,12 +13,14 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	loginservice "github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -31,6 +33,7 @@ const (
 	loginErrorCookieName = "login_error"
 	// #nosec G101 - this is not a hardcoded secret
 	postLogoutRedirectParam = "post_logout_redirect_uri"
+	loginHintParam          = "login_hint"
 )
 
 var setIndexViewData = (*HTTPServer).setIndexViewData
@@ -104,6 +107,13 @@ func (hs *HTTPServer) LoginView(c *contextmodel.ReqContext) {
 		return
 	}
 
+	var loginSettings *loginsettings.LoginSettings
+	if !c.IsSignedIn {
+		if loginSettings = hs.resolveLoginSettings(c); loginSettings != nil {
+			applyLoginSettings(viewData.Settings, loginSettings)
+		}
+	}
+
 	urlParams := c.Req.URL.Query()
 	if _, disableAutoLogin := urlParams["disableAutoLogin"]; disableAutoLogin {
 		hs.log.Debug("Auto login manually disabled")
@@ -122,6 +132,18 @@ func (hs *HTTPServer) LoginView(c *contextmodel.ReqContext) {
 		return
 	}
 
+	if loginSettings != nil && loginSettings.AutoRedirectProvider != "" {
+		redirectURL := hs.Cfg.AppSubURL + "/login/" + loginSettings.AutoRedirectProvider
+		c.Logger.Info("Login page of the organization redirects to " + redirectURL)
+		c.Redirect(redirectURL, 307)
+		return
+	}
+
+	// If user is not authenticated and gave their email, try the provider selected by its email domain
+	if email := urlParams.Get(loginHintParam); !c.IsSignedIn && email != "" && hs.tryAutoLoginByEmail(c, email, loginSettings) {
+		return
+	}
+
 	// If user is not authenticated try auto-login
 	if !c.IsSignedIn && hs.tryAutoLogin(c) {
 		return
@@ -195,6 +217,30 @@ func (hs *HTTPServer) tryAutoLogin(c *contextmodel.ReqContext) bool {
 	return false
 }
 
+// tryAutoLoginByEmail redirects to the OAuth provider with the most specific auto-login email domain pattern matching
+// the email, passing the email on as login hint. Users whose email matches no provider see the provider picker.
+func (hs *HTTPServer) tryAutoLoginByEmail(c *contextmodel.ReqContext, email string, loginSettings *loginsettings.LoginSettings) bool {
+	providerName, match := "", ""
+	for name, provider := range hs.SocialService.GetOAuthInfoProviders() {
+		if loginSettings != nil && !loginSettings.ShowsProvider(name) {
+			continue
+		}
+		pattern, ok := provider.MatchAutoLoginEmailDomain(email)
+		if !ok || len(pattern) < len(match) || (len(pattern) == len(match) && name > providerName) {
+			continue
+		}
+		providerName, match = name, pattern
+	}
+	if providerName == "" {
+		return false
+	}
+
+	redirectUrl := hs.Cfg.AppSubURL + "/login/" + providerName + "?" + url.Values{loginHintParam: {email}}.Encode()
+	c.Logger.Info("Email domain matches auto login rule. Redirecting to "+providerName, "pattern", match)
+	c.Redirect(redirectUrl, 307)
+	return true
+}
+
 func (hs *HTTPServer) LoginAPIPing(c *contextmodel.ReqContext) response.Response {
 	if c.IsSignedIn || c.IsAnonymous {
 		return response.JSON(http.StatusOK, util.DynMap{"message": "Logged in"})
@@ -217,6 +263,22 @@ func (hs *HTTPServer) LoginPost(c *contextmodel.ReqContext) response.Response {
 	return authn.HandleLoginResponse(c.Req, c.Resp, hs.Cfg, identity, hs.ValidateRedirectTo)
 }
 
+// LoginWebAuthnPost logs in with a passkey. The body is the credential returned by navigator.credentials.get() for
+// the options of /login/webauthn/begin, in the JSON format of PublicKeyCredential.toJSON().
+func (hs *HTTPServer) LoginWebAuthnPost(c *contextmodel.ReqContext) response.Response {
+	identity, err := hs.authnService.Login(c.Req.Context(), authn.ClientWebAuthn, &authn.Request{HTTPRequest: c.Req, Resp: c.Resp})
+	if err != nil {
+		tokenErr := &auth.CreateTokenErr{}
+		if errors.As(err, &tokenErr) {
+			return response.Error(tokenErr.StatusCode, tokenErr.ExternalErr, tokenErr.InternalErr)
+		}
+		return response.Err(err)
+	}
+
+	metrics.MApiLoginPost.Inc()
+	return authn.HandleLoginResponse(c.Req, c.Resp, hs.Cfg, identity, hs.ValidateRedirectTo)
+}
+
 func (hs *HTTPServer) loginUserWithUser(user *user.User, c *contextmodel.ReqContext) error {
 	if user == nil {
 		return errors.New("could not login user")
@@ -231,7 +293,12 @@ func (hs *HTTPServer) loginUserWithUser(user *user.User, c *contextmodel.ReqCont
 
 	hs.log.Debug("Got IP address from client address", "addr", addr, "ip", ip)
 	ctx := context.WithValue(c.Req.Context(), loginservice.RequestURIKey{}, c.Req.RequestURI)
-	userToken, err := hs.AuthTokenService.CreateToken(ctx, user, ip, c.Req.UserAgent())
+	userToken, err := hs.AuthTokenService.CreateToken(ctx, &auth.CreateTokenCommand{
+		User:      user,
+		ClientIP:  ip,
+		UserAgent: c.Req.UserAgent(),
+		DeviceID:  c.Req.Header.Get(anonymous.DeviceIDHeader),
+	})
 	if err != nil {
 		return fmt.Errorf("%v: %w", "failed to create auth token", err)
 	}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	loginservice "github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/loginsettings/loginsettingstest"
 	"github.com/grafana/grafana/pkg/services/navtree"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
@@ -54,8 +56,8 @@ func fakeSetIndexViewData(t *testing.T) {
 			NavTree:  &navtree.NavTreeRoot{},
 			Assets: &dtos.EntryPointAssets{
 				JSFiles:  []dtos.EntryPointAsset{},
-				CSSDark:  "dark.css",
-				CSSLight: "light.css",
+				CSSDark:  dtos.EntryPointAsset{FilePath: "dark.css"},
+				CSSLight: dtos.EntryPointAsset{FilePath: "light.css"},
 			},
 		}
 		return data, nil
@@ -108,12 +110,13 @@ func TestLoginErrorCookieAPIEndpoint(t *testing.T) {
 	cfg := setting.NewCfg()
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
 	hs := &HTTPServer{
-		Cfg:              cfg,
-		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
-		License:          &licensing.OSSLicensingService{},
-		SocialService:    &mockSocialService{},
-		SecretsService:   secretsService,
-		Features:         featuremgmt.WithFeatures(),
+		Cfg:                  cfg,
+		SettingsProvider:     &setting.OSSImpl{Cfg: cfg},
+		License:              &licensing.OSSLicensingService{},
+		SocialService:        &mockSocialService{},
+		SecretsService:       secretsService,
+		Features:             featuremgmt.WithFeatures(),
+		loginSettingsService: loginsettingstest.NewFakeLoginSettingsService(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
@@ -493,11 +496,12 @@ func TestLoginOAuthRedirect(t *testing.T) {
 		oAuthInfos: oAuthInfos,
 	}
 	hs := &HTTPServer{
-		Cfg:              cfg,
-		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
-		License:          &licensing.OSSLicensingService{},
-		SocialService:    mock,
-		Features:         featuremgmt.WithFeatures(),
+		Cfg:                  cfg,
+		SettingsProvider:     &setting.OSSImpl{Cfg: cfg},
+		License:              &licensing.OSSLicensingService{},
+		SocialService:        mock,
+		Features:             featuremgmt.WithFeatures(),
+		loginSettingsService: loginsettingstest.NewFakeLoginSettingsService(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
@@ -515,16 +519,74 @@ func TestLoginOAuthRedirect(t *testing.T) {
 	assert.Equal(t, "/login/github", location[0])
 }
 
+func TestLoginAutoLoginByEmailDomain(t *testing.T) {
+	fakeSetIndexViewData(t)
+
+	tests := []struct {
+		desc             string
+		loginHint        string
+		expectedCode     int
+		expectedLocation string
+	}{
+		{
+			desc:             "should redirect to the provider matching the email domain",
+			loginHint:        "jane@corp.com",
+			expectedCode:     http.StatusTemporaryRedirect,
+			expectedLocation: "/login/azuread?login_hint=jane%40corp.com",
+		},
+		{
+			desc:             "should prefer the most specific email domain pattern",
+			loginHint:        "john@contractors.corp.com",
+			expectedCode:     http.StatusTemporaryRedirect,
+			expectedLocation: "/login/github?login_hint=john%40contractors.corp.com",
+		},
+		{
+			desc:         "should show the provider picker when no email domain pattern matches",
+			loginHint:    "jane@example.com",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sc := setupScenarioContext(t, "/login")
+			cfg := setting.NewCfg()
+			hs := &HTTPServer{
+				Cfg:              cfg,
+				SettingsProvider: &setting.OSSImpl{Cfg: cfg},
+				License:          &licensing.OSSLicensingService{},
+				SocialService: &mockSocialService{oAuthInfos: map[string]*social.OAuthInfo{
+					"azuread": {Enabled: true, Name: "Microsoft", AutoLoginEmailDomains: []string{"corp.com", "*.corp.com"}},
+					"github":  {Enabled: true, Name: "GitHub", AutoLoginEmailDomains: []string{"contractors.corp.com"}},
+				}},
+				Features:             featuremgmt.WithFeatures(),
+				loginSettingsService: loginsettingstest.NewFakeLoginSettingsService(),
+			}
+
+			sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				hs.LoginView(c)
+				return response.Empty(http.StatusOK)
+			})
+			sc.m.Get(sc.url, sc.defaultHandler)
+			sc.fakeReqNoAssertions("GET", sc.url+"?login_hint="+url.QueryEscape(tt.loginHint)).exec()
+
+			require.Equal(t, tt.expectedCode, sc.resp.Code)
+			assert.Equal(t, tt.expectedLocation, sc.resp.Header().Get("Location"))
+		})
+	}
+}
+
 func TestLoginInternal(t *testing.T) {
 	fakeSetIndexViewData(t)
 
 	fakeViewIndex(t)
 	sc := setupScenarioContext(t, "/login")
 	hs := &HTTPServer{
-		Cfg:      setting.NewCfg(),
-		License:  &licensing.OSSLicensingService{},
-		log:      log.New("test"),
-		Features: featuremgmt.WithFeatures(),
+		Cfg:                  setting.NewCfg(),
+		License:              &licensing.OSSLicensingService{},
+		log:                  log.New("test"),
+		Features:             featuremgmt.WithFeatures(),
+		loginSettingsService: loginsettingstest.NewFakeLoginSettingsService(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/api/openapi"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	alertingspec "github.com/grafana/grafana/pkg/services/ngalert/api/tooling"
+)
+
+// GetOpenAPISpec returns the OpenAPI 3.1 specification of the HTTP API. It is generated from the
+// routes registered on the server, so it only has the endpoints of the enabled features.
+func (hs *HTTPServer) GetOpenAPISpec(c *contextmodel.ReqContext) response.Response {
+	// The routes are all registered before the server starts.
+	hs.openAPIOnce.Do(func() {
+		hs.openAPISpec, hs.openAPIErr = hs.generateOpenAPISpec()
+	})
+	if hs.openAPIErr != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to generate OpenAPI specification", hs.openAPIErr)
+	}
+	return response.CreateNormalResponse(http.Header{"Content-Type": []string{"application/json"}}, hs.openAPISpec, http.StatusOK)
+}
+
+func (hs *HTTPServer) generateOpenAPISpec() ([]byte, error) {
+	recorder := &openapi.RouteRecorder{}
+	hs.RouteRegister.Register(recorder)
+
+	// The specification of the HTTP API is built with the frontend. The routes it does not
+	// document are still part of the generated specification.
+	var specs [][]byte
+	merged, err := os.ReadFile(filepath.Join(hs.Cfg.StaticRootPath, "api-merged.json"))
+	if err != nil {
+		hs.log.Warn("Failed to read the Swagger specification of the HTTP API", "error", err)
+	} else {
+		specs = append(specs, merged)
+	}
+	specs = append(specs, alertingspec.Spec)
+
+	serverURL := hs.Cfg.AppSubURL
+	if serverURL == "" {
+		serverURL = "/"
+	}
+	return openapi.Generate(recorder.Routes, openapi.Options{
+		Version:   hs.Cfg.BuildVersion,
+		ServerURL: serverURL,
+		Specs:     specs,
+	})
+}
@@ -0,0 +1,444 @@
+// Package openapi generates the OpenAPI 3.1 specification of the HTTP API from the routes
+// registered on the server, using the Swagger 2.0 specifications of the API to document them.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const Version = "3.1.0"
+
+var (
+	// routeParamRegex matches the parameters of the routes, as they are matched by the router.
+	routeParamRegex = regexp.MustCompile(`^:([a-zA-Z0-9]+)`)
+	versionRegex    = regexp.MustCompile(`^v\d+`)
+	// anyMethods are the methods documented for the routes registered for any method.
+	anyMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	// errorResponses are the error responses of the operations that are not documented, by status code.
+	errorResponses = map[string]string{
+		"400": "badRequestError",
+		"401": "unauthorisedError",
+		"403": "forbiddenError",
+		"404": "notFoundError",
+		"500": "internalServerError",
+	}
+	httpMethods = map[string]bool{
+		http.MethodGet: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+		http.MethodDelete: true, http.MethodHead: true, http.MethodOptions: true,
+	}
+	componentSections = []string{"schemas", "responses", "parameters", "requestBodies", "headers", "securitySchemes"}
+)
+
+// Route is a route registered on the server.
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+// RouteRecorder is a routing.Router recording the routes registered to it, without serving them.
+type RouteRecorder struct {
+	Routes []Route
+}
+
+func (r *RouteRecorder) Handle(method, pattern string, _ []web.Handler) {
+	r.Routes = append(r.Routes, Route{Method: method, Pattern: pattern})
+}
+
+func (r *RouteRecorder) Get(pattern string, _ ...web.Handler) {
+	r.Handle(http.MethodGet, pattern, nil)
+}
+
+// Options are the options of Generate.
+type Options struct {
+	// Version is the version of Grafana.
+	Version string
+	// ServerURL is the URL the paths are relative to, such as the sub path Grafana is served from.
+	ServerURL string
+	// Specs are the Swagger 2.0 specifications documenting the routes. When several of them
+	// document a route, the first one is used.
+	Specs [][]byte
+}
+
+type documentedOperation struct {
+	path      string
+	operation map[string]any
+}
+
+type generator struct {
+	components map[string]map[string]any
+	operations map[string]documentedOperation
+	tags       map[string]map[string]any
+	security   any
+}
+
+// Generate returns the OpenAPI 3.1 specification of the routes of the API, the routes under /api.
+// Routes missing from the Swagger specifications are documented with their path parameters and
+// the error responses shared by the API. The request and response bodies of all the operations
+// have an example built from their schema.
+func Generate(routes []Route, opts Options) ([]byte, error) {
+	g := &generator{
+		components: map[string]map[string]any{},
+		operations: map[string]documentedOperation{},
+		tags:       map[string]map[string]any{},
+	}
+	for _, section := range componentSections {
+		g.components[section] = map[string]any{}
+	}
+	for i, spec := range opts.Specs {
+		if err := g.addSpec(spec); err != nil {
+			return nil, fmt.Errorf("failed to read specification %d: %w", i, err)
+		}
+	}
+	g.addErrorComponents()
+
+	paths := map[string]any{}
+	operationIDs := map[string]bool{}
+	var undocumented []Route
+	seen := map[string]bool{}
+	for _, r := range routes {
+		if !strings.HasPrefix(r.Pattern, "/api/") {
+			continue
+		}
+		methods := []string{r.Method}
+		if r.Method == "*" {
+			methods = anyMethods
+		}
+		for _, method := range methods {
+			key := method + " " + normalizePath(r.Pattern)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			documented, ok := g.operations[key]
+			if !ok {
+				undocumented = append(undocumented, Route{Method: method, Pattern: r.Pattern})
+				continue
+			}
+			operation := documented.operation
+			if id, ok := operation["operationId"].(string); ok {
+				operationIDs[id] = true
+			}
+			addResponses(operation, "500")
+			addPath(paths, documented.path, method, operation)
+		}
+	}
+
+	// The operations that are not documented are added last, so that their IDs do not
+	// collide with the IDs of the documented ones.
+	for _, r := range undocumented {
+		path, params := openAPIPath(r.Pattern)
+		operation := g.undocumentedOperation(r.Method, path, params, operationIDs)
+		addPath(paths, path, r.Method, operation)
+	}
+
+	doc := map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":       "Grafana HTTP API",
+			"description": "The Grafana backend exposes an HTTP API, the same API is used by the frontend to do everything from saving dashboards, creating users and updating data sources.",
+			"version":     opts.Version,
+		},
+		"servers":    []any{map[string]any{"url": opts.ServerURL}},
+		"paths":      paths,
+		"components": g.componentsDocument(),
+		"tags":       g.tagsDocument(paths),
+	}
+	if g.security != nil {
+		doc["security"] = g.security
+	}
+
+	addExamples(doc)
+	convertSchemas(doc)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// addSpec adds the operations and components of a Swagger 2.0 specification.
+func (g *generator) addSpec(spec []byte) error {
+	var doc2 openapi2.T
+	if err := json.Unmarshal(spec, &doc2); err != nil {
+		return err
+	}
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(doc3)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	components, _ := doc["components"].(map[string]any)
+	for section, entries := range components {
+		if _, ok := g.components[section]; !ok {
+			continue
+		}
+		for name, component := range asMap(entries) {
+			if _, ok := g.components[section][name]; !ok {
+				g.components[section][name] = component
+			}
+		}
+	}
+	for _, tag := range asSlice(doc["tags"]) {
+		tag := asMap(tag)
+		if name, ok := tag["name"].(string); ok && g.tags[name] == nil {
+			g.tags[name] = tag
+		}
+	}
+	if security, ok := doc["security"]; ok && g.security == nil {
+		g.security = security
+	}
+
+	basePath := strings.TrimSuffix(doc2.BasePath, "/")
+	for path, item := range asMap(doc["paths"]) {
+		item := asMap(item)
+		// Some specifications document the full paths of their operations, regardless of their base path.
+		fullPath := path
+		if !strings.HasPrefix(path, "/api/") {
+			fullPath = basePath + path
+		}
+
+		shared := asSlice(item["parameters"])
+		for method, operation := range item {
+			method = strings.ToUpper(method)
+			operation, ok := operation.(map[string]any)
+			if !ok || !httpMethods[method] {
+				continue
+			}
+			key := method + " " + normalizePath(fullPath)
+			if _, ok := g.operations[key]; ok {
+				continue
+			}
+			if len(shared) > 0 {
+				parameters := append([]any{}, shared...)
+				operation["parameters"] = append(parameters, asSlice(operation["parameters"])...)
+			}
+			g.operations[key] = documentedOperation{path: fullPath, operation: operation}
+		}
+	}
+	return nil
+}
+
+// addErrorComponents adds the error responses shared by the API, when no specification has them.
+func (g *generator) addErrorComponents() {
+	if _, ok := g.components["schemas"]["ErrorResponseBody"]; !ok {
+		g.components["schemas"]["ErrorResponseBody"] = map[string]any{
+			"type":     "object",
+			"required": []any{"message"},
+			"properties": map[string]any{
+				"message": map[string]any{"type": "string", "description": "a human readable version of the error"},
+				"error":   map[string]any{"type": "string", "description": "An optional detailed description of the actual error. Only included if running in developer mode."},
+				"status":  map[string]any{"type": "string", "description": "An optional status to denote the cause of the error."},
+			},
+		}
+	}
+	for code, name := range errorResponses {
+		if _, ok := g.components["responses"][name]; ok {
+			continue
+		}
+		g.components["responses"][name] = map[string]any{
+			"description": http.StatusText(status(code)),
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{"$ref": "#/components/schemas/ErrorResponseBody"},
+				},
+			},
+		}
+	}
+}
+
+func (g *generator) undocumentedOperation(method, path string, params []string, operationIDs map[string]bool) map[string]any {
+	parameters := make([]any, 0, len(params))
+	for _, param := range params {
+		parameters = append(parameters, map[string]any{
+			"name":     param,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+
+	operation := map[string]any{
+		"operationId": uniqueOperationID(method, path, operationIDs),
+		"tags":        []any{pathTag(path)},
+		"description": "This operation is not documented yet, its request and response bodies are unknown.",
+		"parameters":  parameters,
+		"responses": map[string]any{
+			"200": map[string]any{"description": "The request succeeded."},
+		},
+	}
+	codes := []string{"401", "403", "500"}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		operation["requestBody"] = map[string]any{
+			"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{}}},
+		}
+		codes = append(codes, "400")
+	}
+	if len(params) > 0 {
+		codes = append(codes, "404")
+	}
+	addResponses(operation, codes...)
+	return operation
+}
+
+func (g *generator) componentsDocument() map[string]any {
+	components := map[string]any{}
+	for section, entries := range g.components {
+		if len(entries) > 0 {
+			components[section] = entries
+		}
+	}
+	return components
+}
+
+// tagsDocument returns the tags of the operations, with the descriptions of the specifications.
+func (g *generator) tagsDocument(paths map[string]any) []any {
+	names := map[string]bool{}
+	for _, item := range paths {
+		for _, operation := range asMap(item) {
+			for _, tag := range asSlice(asMap(operation)["tags"]) {
+				if name, ok := tag.(string); ok {
+					names[name] = true
+				}
+			}
+		}
+	}
+
+	tags := make([]any, 0, len(names))
+	for _, name := range sortedKeys(names) {
+		tag, ok := g.tags[name]
+		if !ok {
+			tag = map[string]any{"name": name}
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// addResponses adds the shared error responses with the status codes the operation does not document.
+func addResponses(operation map[string]any, codes ...string) {
+	responses, ok := operation["responses"].(map[string]any)
+	if !ok {
+		responses = map[string]any{}
+		operation["responses"] = responses
+	}
+	for _, code := range codes {
+		if _, ok := responses[code]; !ok {
+			responses[code] = map[string]any{"$ref": "#/components/responses/" + errorResponses[code]}
+		}
+	}
+}
+
+func addPath(paths map[string]any, path, method string, operation map[string]any) {
+	item, ok := paths[path].(map[string]any)
+	if !ok {
+		item = map[string]any{}
+		paths[path] = item
+	}
+	item[strings.ToLower(method)] = operation
+}
+
+// normalizePath returns the path without the names of its parameters, so that the paths of
+// the routes match the paths of the specifications.
+func normalizePath(path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{") || segment == "*" {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// openAPIPath returns the OpenAPI path of a route pattern and the names of its parameters. The
+// wildcard of a pattern, which matches the rest of the path, is the path parameter.
+func openAPIPath(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	var params []string
+	for i, segment := range segments {
+		name := ""
+		if segment == "*" {
+			name = "path"
+		} else if m := routeParamRegex.FindStringSubmatch(segment); m != nil {
+			name = m[1]
+		}
+		if name != "" {
+			segments[i] = "{" + name + "}"
+			params = append(params, name)
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// uniqueOperationID returns an ID such as getDashboardsUidByUid for GET /api/dashboards/uid/{uid}.
+func uniqueOperationID(method, path string, operationIDs map[string]bool) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	id := b.String()
+	for i := 2; operationIDs[id]; i++ {
+		id = fmt.Sprintf("%s%d", b.String(), i)
+	}
+	operationIDs[id] = true
+	return id
+}
+
+// pathTag returns the tag of an operation that is not documented, the first segment of its path
+// that is not a version.
+func pathTag(path string) string {
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") && !versionRegex.MatchString(segment) {
+			return segment
+		}
+	}
+	return "api"
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func status(code string) int {
+	n, _ := strconv.Atoi(code)
+	return n
+}
@@ -0,0 +1,165 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	alertingspec "github.com/grafana/grafana/pkg/services/ngalert/api/tooling"
+)
+
+const testSpec = `{
+  "swagger": "2.0",
+  "basePath": "/api",
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "security": [{"basic": []}],
+  "tags": [{"name": "dashboards", "description": "Dashboards"}],
+  "paths": {
+    "/dashboards/uid/{uid}": {
+      "get": {
+        "operationId": "getDashboardByUID",
+        "tags": ["dashboards"],
+        "parameters": [{"name": "uid", "in": "path", "required": true, "type": "string"}],
+        "responses": {
+          "200": {"description": "The dashboard", "schema": {"$ref": "#/definitions/Dashboard"}},
+          "404": {"$ref": "#/responses/notFoundError"}
+        }
+      }
+    }
+  },
+  "definitions": {
+    "Dashboard": {
+      "type": "object",
+      "properties": {
+        "title": {"type": "string", "example": "Overview"},
+        "version": {"type": "integer", "minimum": 0, "exclusiveMinimum": true},
+        "folder": {"$ref": "#/definitions/Folder"},
+        "description": {"type": "string", "x-nullable": true},
+        "tags": {"type": "array", "items": {"type": "string"}},
+        "created": {"type": "string", "format": "date-time"}
+      }
+    },
+    "Folder": {"type": "object", "properties": {"uid": {"type": "string"}}},
+    "ErrorResponseBody": {"type": "object", "properties": {"message": {"type": "string"}}}
+  },
+  "responses": {
+    "notFoundError": {"description": "NotFoundError", "schema": {"$ref": "#/definitions/ErrorResponseBody"}}
+  }
+}`
+
+func TestGenerate(t *testing.T) {
+	rr := routing.NewRouteRegister()
+	rr.Get("/api/dashboards/uid/:uid", nil)
+	rr.Group("/api/folders", func(r routing.RouteRegister) {
+		r.Post("/:uid/move", nil)
+	})
+	rr.Any("/api/plugins/:pluginId/resources/*", nil)
+	rr.Get("/dashboards", nil)
+
+	recorder := &RouteRecorder{}
+	rr.Register(recorder)
+
+	raw, err := Generate(recorder.Routes, Options{Version: "10.3.0", ServerURL: "/grafana", Specs: [][]byte{[]byte(testSpec)}})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(raw, &doc))
+
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.Equal(t, "10.3.0", asMap(doc["info"])["version"])
+	assert.Equal(t, []any{map[string]any{"url": "/grafana"}}, doc["servers"])
+	assert.Equal(t, []any{map[string]any{"basic": []any{}}}, doc["security"])
+
+	paths := asMap(doc["paths"])
+	assert.ElementsMatch(t, []string{
+		"/api/dashboards/uid/{uid}",
+		"/api/folders/{uid}/move",
+		"/api/plugins/{pluginId}/resources/{path}",
+	}, sortedKeys(paths))
+
+	t.Run("documented routes should use the operation of the specification", func(t *testing.T) {
+		op := asMap(asMap(paths["/api/dashboards/uid/{uid}"])["get"])
+		assert.Equal(t, "getDashboardByUID", op["operationId"])
+		responses := asMap(op["responses"])
+		assert.Equal(t, map[string]any{"$ref": "#/components/responses/notFoundError"}, responses["404"])
+		assert.Equal(t, map[string]any{"$ref": "#/components/responses/internalServerError"}, responses["500"])
+
+		mediaType := asMap(asMap(asMap(responses["200"])["content"])["application/json"])
+		assert.Equal(t, map[string]any{"$ref": "#/components/schemas/Dashboard"}, mediaType["schema"])
+		assert.Equal(t, map[string]any{
+			"title":       "Overview",
+			"version":     float64(0),
+			"folder":      map[string]any{"uid": "string"},
+			"description": "string",
+			"tags":        []any{"string"},
+			"created":     "2006-01-02T15:04:05Z",
+		}, mediaType["example"])
+	})
+
+	t.Run("undocumented routes should have a generated operation", func(t *testing.T) {
+		op := asMap(asMap(paths["/api/folders/{uid}/move"])["post"])
+		assert.Equal(t, "postFoldersByUidMove", op["operationId"])
+		assert.Equal(t, []any{"folders"}, op["tags"])
+		assert.Equal(t, []any{map[string]any{"name": "uid", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}, op["parameters"])
+		assert.NotNil(t, op["requestBody"])
+
+		responses := asMap(op["responses"])
+		for code, name := range map[string]string{"400": "badRequestError", "401": "unauthorisedError", "403": "forbiddenError", "404": "notFoundError", "500": "internalServerError"} {
+			assert.Equal(t, map[string]any{"$ref": "#/components/responses/" + name}, responses[code], code)
+		}
+
+		// the routes registered for any method are documented for every method
+		item := asMap(paths["/api/plugins/{pluginId}/resources/{path}"])
+		assert.ElementsMatch(t, []string{"get", "post", "put", "patch", "delete"}, sortedKeys(item))
+		assert.Equal(t, "getPluginsByPluginIdResourcesByPath", asMap(item["get"])["operationId"])
+	})
+
+	t.Run("schemas should be converted to OpenAPI 3.1", func(t *testing.T) {
+		components := asMap(doc["components"])
+		properties := asMap(asMap(asMap(components["schemas"])["Dashboard"])["properties"])
+		assert.Equal(t, map[string]any{"type": "string", "examples": []any{"Overview"}}, properties["title"])
+		assert.Equal(t, map[string]any{"type": "integer", "exclusiveMinimum": float64(0)}, properties["version"])
+		assert.Equal(t, map[string]any{"type": []any{"string", "null"}}, properties["description"])
+
+		// the error responses missing from the specifications are added
+		responses := asMap(components["responses"])
+		assert.Contains(t, responses, "unauthorisedError")
+		assert.Equal(t, "NotFoundError", asMap(responses["notFoundError"])["description"])
+
+		assert.Equal(t, []any{
+			map[string]any{"name": "dashboards", "description": "Dashboards"},
+			map[string]any{"name": "folders"},
+			map[string]any{"name": "plugins"},
+		}, doc["tags"])
+	})
+}
+
+func TestGenerateWithAPISpecifications(t *testing.T) {
+	merged, err := os.ReadFile("../../../public/api-merged.json")
+	require.NoError(t, err)
+
+	routes := []Route{
+		{Method: http.MethodGet, Pattern: "/api/dashboards/uid/:uid"},
+		{Method: http.MethodGet, Pattern: "/api/ruler/grafana/api/v1/rules"},
+		{Method: http.MethodGet, Pattern: "/api/v1/provisioning/alert-rules/:UID"},
+		{Method: http.MethodGet, Pattern: "/api/access-control/roles"},
+	}
+	raw, err := Generate(routes, Options{ServerURL: "/", Specs: [][]byte{merged, alertingspec.Spec}})
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	paths := asMap(doc["paths"])
+	for path, id := range map[string]string{
+		"/api/dashboards/uid/{uid}":              "getDashboardByUID",
+		"/api/ruler/grafana/api/v1/rules":        "RouteGetGrafanaRulesConfig",
+		"/api/v1/provisioning/alert-rules/{UID}": "RouteGetAlertRule",
+		"/api/access-control/roles":              "listRoles",
+	} {
+		assert.Equal(t, id, asMap(asMap(paths[path])["get"])["operationId"], path)
+	}
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import "strings"
+
+// maxExampleDepth is the maximum depth of the references followed to build an example, so
+// that the examples of recursive schemas are finite.
+const maxExampleDepth = 5
+
+// addExamples adds an example to the request and response bodies that have none.
+func addExamples(doc map[string]any) {
+	schemas := asMap(asMap(doc["components"])["schemas"])
+	addContentExamples := func(v any) {
+		for _, mediaType := range asMap(asMap(v)["content"]) {
+			mediaType := asMap(mediaType)
+			if _, ok := mediaType["example"]; ok {
+				continue
+			}
+			if example, ok := schemaExample(schemas, asMap(mediaType["schema"]), 0); ok {
+				mediaType["example"] = example
+			}
+		}
+	}
+
+	components := asMap(doc["components"])
+	for _, section := range []string{"requestBodies", "responses"} {
+		for _, v := range asMap(components[section]) {
+			addContentExamples(v)
+		}
+	}
+	forEachOperation(doc, func(operation map[string]any) {
+		addContentExamples(operation["requestBody"])
+		for _, response := range asMap(operation["responses"]) {
+			addContentExamples(response)
+		}
+	})
+}
+
+// schemaExample returns an example of the schema, using the examples of the schema and of its
+// properties when they have one.
+func schemaExample(schemas map[string]any, schema map[string]any, depth int) (any, bool) {
+	if schema == nil || depth > maxExampleDepth {
+		return nil, false
+	}
+	if example, ok := schema["example"]; ok {
+		return example, true
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		return schemaExample(schemas, asMap(schemas[strings.TrimPrefix(ref, "#/components/schemas/")]), depth+1)
+	}
+	if enum := asSlice(schema["enum"]); len(enum) > 0 {
+		return enum[0], true
+	}
+	if of := asSlice(schema["allOf"]); len(of) > 0 {
+		merged := map[string]any{}
+		for _, s := range of {
+			if example, ok := schemaExample(schemas, asMap(s), depth+1); ok {
+				for k, v := range asMap(example) {
+					merged[k] = v
+				}
+			}
+		}
+		return merged, true
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if of := asSlice(schema[keyword]); len(of) > 0 {
+			return schemaExample(schemas, asMap(of[0]), depth+1)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		example := map[string]any{}
+		for name, property := range asMap(schema["properties"]) {
+			if v, ok := schemaExample(schemas, asMap(property), depth+1); ok {
+				example[name] = v
+			}
+		}
+		if additional := asMap(schema["additionalProperties"]); len(additional) > 0 && len(example) == 0 {
+			if v, ok := schemaExample(schemas, additional, depth+1); ok {
+				example["key"] = v
+			}
+		}
+		return example, true
+	case "array":
+		if v, ok := schemaExample(schemas, asMap(schema["items"]), depth+1); ok {
+			return []any{v}, true
+		}
+		return []any{}, true
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2006-01-02T15:04:05Z", true
+		case "date":
+			return "2006-01-02", true
+		case "byte":
+			return "", true
+		}
+		return "string", true
+	case "integer", "number":
+		return 0, true
+	case "boolean":
+		return false, true
+	}
+	return nil, false
+}
+
+// convertSchemas converts the OpenAPI 3.0 schemas of the document, which are the schemas of the
+// Swagger specifications once converted, to the JSON Schema of OpenAPI 3.1.
+func convertSchemas(doc map[string]any) {
+	convertContent := func(v any) {
+		body := asMap(v)
+		for _, mediaType := range asMap(body["content"]) {
+			convertSchemaField(asMap(mediaType), "schema")
+		}
+		for _, header := range asMap(body["headers"]) {
+			convertSchemaField(asMap(header), "schema")
+		}
+	}
+	convertParameters := func(v any) {
+		for _, parameter := range asSlice(v) {
+			convertSchemaField(asMap(parameter), "schema")
+		}
+	}
+
+	components := asMap(doc["components"])
+	schemas := asMap(components["schemas"])
+	for name := range schemas {
+		convertSchemaField(schemas, name)
+	}
+	for _, parameter := range asMap(components["parameters"]) {
+		convertSchemaField(asMap(parameter), "schema")
+	}
+	for _, header := range asMap(components["headers"]) {
+		convertSchemaField(asMap(header), "schema")
+	}
+	for _, section := range []string{"requestBodies", "responses"} {
+		for _, v := range asMap(components[section]) {
+			convertContent(v)
+		}
+	}
+
+	forEachOperation(doc, func(operation map[string]any) {
+		convertParameters(operation["parameters"])
+		convertContent(operation["requestBody"])
+		for _, response := range asMap(operation["responses"]) {
+			convertContent(response)
+		}
+	})
+}
+
+// convertSchemaField converts the schema in m[key], when there is one.
+func convertSchemaField(m map[string]any, key string) {
+	if schema, ok := m[key].(map[string]any); ok {
+		m[key] = convertSchema(schema)
+	}
+}
+
+// convertSchema returns the OpenAPI 3.1 form of a schema: nullable schemas accept the null type,
+// examples are a list, and the exclusive bounds are numbers.
+func convertSchema(schema map[string]any) map[string]any {
+	for name := range asMap(schema["properties"]) {
+		convertSchemaField(asMap(schema["properties"]), name)
+	}
+	convertSchemaField(schema, "items")
+	convertSchemaField(schema, "additionalProperties")
+	convertSchemaField(schema, "not")
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		for i, s := range asSlice(schema[keyword]) {
+			if s, ok := s.(map[string]any); ok {
+				asSlice(schema[keyword])[i] = convertSchema(s)
+			}
+		}
+	}
+
+	if example, ok := schema["example"]; ok {
+		delete(schema, "example")
+		schema["examples"] = []any{example}
+	}
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		exclusive := "exclusive" + bound
+		limit := strings.ToLower(bound)
+		if v, ok := schema[exclusive].(bool); ok {
+			delete(schema, exclusive)
+			if v {
+				schema[exclusive] = schema[limit]
+				delete(schema, limit)
+			}
+		}
+	}
+
+	nullable, _ := schema["nullable"].(bool)
+	if xNullable, _ := schema["x-nullable"].(bool); xNullable {
+		nullable = true
+	}
+	delete(schema, "nullable")
+	delete(schema, "x-nullable")
+	if !nullable {
+		return schema
+	}
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []any{t, "null"}
+		if enum := asSlice(schema["enum"]); len(enum) > 0 {
+			schema["enum"] = append(enum, nil)
+		}
+	case nil:
+		// A reference, or a combination of schemas, is made nullable by accepting null as an alternative.
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+	return schema
+}
+
+func forEachOperation(doc map[string]any, fn func(operation map[string]any)) {
+	for _, item := range asMap(doc["paths"]) {
+		for _, operation := range asMap(item) {
+			if operation, ok := operation.(map[string]any); ok {
+				fn(operation)
+			}
+		}
+	}
+}
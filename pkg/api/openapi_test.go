@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.log = log.New("test")
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.StaticRootPath = "../../public"
+		hs.Cfg.BuildVersion = "10.3.0"
+	})
+
+	t.Run("returns the specification of the registered routes", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/openapi/v3"), &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer})
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+		var spec struct {
+			OpenAPI string                    `json:"openapi"`
+			Info    struct{ Version string }  `json:"info"`
+			Paths   map[string]map[string]any `json:"paths"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&spec))
+		assert.Equal(t, "3.1.0", spec.OpenAPI)
+		assert.Equal(t, "10.3.0", spec.Info.Version)
+		assert.Contains(t, spec.Paths["/api/dashboards/uid/{uid}"], "get")
+		assert.Contains(t, spec.Paths["/api/admin/log/level"], "put")
+		assert.NotContains(t, spec.Paths, "/openapi/v3")
+	})
+}
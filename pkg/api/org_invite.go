@@ -2,11 +2,15 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -15,6 +19,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -64,12 +69,63 @@ func (hs *HTTPServer) AddOrgInvite(c *contextmodel.ReqContext) response.Response
 	if err := web.Bind(c.Req, &inviteDto); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
+	return hs.addOrgInvite(c, &inviteDto)
+}
+
+// swagger:route POST /org/invites/bulk org_invites addOrgInvites
+//
+// Add invites.
+//
+// Adds the invites one by one, and returns the result of each of them.
+//
+// Responses:
+// 200: addOrgInvitesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AddOrgInvites(c *contextmodel.ReqContext) response.Response {
+	form := dtos.BulkAddInviteForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if len(form.Invites) > maxBulkInvites {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Cannot add more than %d invites at once", maxBulkInvites), nil)
+	}
+
+	results := make([]dtos.BulkAddInviteResult, 0, len(form.Invites))
+	for i := range form.Invites {
+		rsp := hs.addOrgInvite(c, &form.Invites[i])
+		result := dtos.BulkAddInviteResult{LoginOrEmail: form.Invites[i].LoginOrEmail, Status: rsp.Status()}
+		var body struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(rsp.Body(), &body); err == nil {
+			result.Message = body.Message
+		}
+		results = append(results, result)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{"results": results})
+}
+
+// maxBulkInvites is the max number of invites added at once.
+const maxBulkInvites = 100
+
+func (hs *HTTPServer) addOrgInvite(c *contextmodel.ReqContext, inviteDto *dtos.AddInviteForm) response.Response {
 	if !inviteDto.Role.IsValid() {
 		return response.Error(http.StatusBadRequest, "Invalid role specified", nil)
 	}
 	if !c.SignedInUser.GetOrgRole().Includes(inviteDto.Role) && !c.SignedInUser.GetIsGrafanaAdmin() {
 		return response.Error(http.StatusForbidden, "Cannot assign a role higher than user's role", nil)
 	}
+	if inviteDto.SSOProvider != "" && !login.IsProviderEnabled(hs.Cfg, inviteDto.SSOProvider) {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("SSO provider %s is not enabled", inviteDto.SSOProvider), nil)
+	}
+	expires, err := hs.inviteExpires(inviteDto.ExpiresIn)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
 
 	// first try get existing user
 	userQuery := user.GetUserByLoginQuery{LoginOrEmail: inviteDto.LoginOrEmail}
@@ -88,10 +144,18 @@ func (hs *HTTPServer) AddOrgInvite(c *contextmodel.ReqContext) response.Response
 		if !hasAccess {
 			return response.Error(http.StatusForbidden, "Permission denied: not permitted to add an existing user to this organisation", err)
 		}
-		return hs.inviteExistingUserToOrg(c, usr, &inviteDto)
+		if inviteDto.EmailDomain != "" && !tempuser.HasEmailDomain(usr.Email, inviteDto.EmailDomain) {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("The email address of %s is not in the domain %s", inviteDto.LoginOrEmail, inviteDto.EmailDomain), nil)
+		}
+		return hs.inviteExistingUserToOrg(c, usr, inviteDto)
+	}
+
+	if inviteDto.EmailDomain != "" && !tempuser.HasEmailDomain(inviteDto.LoginOrEmail, inviteDto.EmailDomain) {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("%s is not an email address in the domain %s", inviteDto.LoginOrEmail, inviteDto.EmailDomain), nil)
 	}
 
-	if hs.Cfg.DisableLoginForm {
+	// The invites bound to an SSO provider are accepted by logging in with it, not with the login form.
+	if hs.Cfg.DisableLoginForm && inviteDto.SSOProvider == "" {
 		return response.Error(http.StatusBadRequest, "Cannot invite external user when login is disabled.", nil)
 	}
 
@@ -119,6 +183,9 @@ func (hs *HTTPServer) AddOrgInvite(c *contextmodel.ReqContext) response.Response
 	}
 	cmd.Role = inviteDto.Role
 	cmd.RemoteAddr = c.RemoteAddr()
+	cmd.SSOProvider = inviteDto.SSOProvider
+	cmd.EmailDomain = inviteDto.EmailDomain
+	cmd.Expires = expires
 
 	cmdResult, err := hs.tempUserService.CreateTempUser(c.Req.Context(), &cmd)
 	if err != nil {
@@ -127,35 +194,116 @@ func (hs *HTTPServer) AddOrgInvite(c *contextmodel.ReqContext) response.Response
 
 	// send invite email
 	if inviteDto.SendEmail && util.IsEmail(inviteDto.LoginOrEmail) {
-		emailCmd := notifications.SendEmailCommand{
-			To:       []string{inviteDto.LoginOrEmail},
-			Template: "new_user_invite",
-			Data: map[string]any{
-				"Name":      util.StringsFallback2(cmd.Name, cmd.Email),
-				"OrgName":   c.SignedInUser.GetOrgName(),
-				"Email":     c.SignedInUser.GetEmail(),
-				"LinkUrl":   setting.ToAbsUrl("invite/" + cmd.Code),
-				"InvitedBy": c.SignedInUser.GetDisplayName(),
-			},
+		if ok, rsp := hs.sendInviteEmail(c, inviteDto.LoginOrEmail, util.StringsFallback2(cmd.Name, cmd.Email), cmdResult.Code); !ok {
+			return rsp
 		}
 
-		if err := hs.AlertNG.NotificationService.SendEmailCommandHandler(c.Req.Context(), &emailCmd); err != nil {
-			if errors.Is(err, notifications.ErrSmtpNotEnabled) {
-				return response.Error(http.StatusPreconditionFailed, err.Error(), err)
-			}
+		return response.Success(fmt.Sprintf("Sent invite to %s", inviteDto.LoginOrEmail))
+	}
+
+	return response.Success(fmt.Sprintf("Created invite for %s", inviteDto.LoginOrEmail))
+}
 
-			return response.Error(http.StatusInternalServerError, "Failed to send email invite", err)
+// inviteExpires returns when an invite created now expires, given how long it remains valid.
+func (hs *HTTPServer) inviteExpires(expiresIn string) (int64, error) {
+	lifetime := hs.Cfg.UserInviteMaxLifetime
+	if expiresIn != "" {
+		duration, err := gtime.ParseDuration(expiresIn)
+		if err != nil || duration <= 0 {
+			return 0, fmt.Errorf("invalid expiresIn %s", expiresIn)
 		}
+		if lifetime > 0 && duration > lifetime {
+			return 0, fmt.Errorf("expiresIn can not exceed the max lifetime of invites %s", lifetime)
+		}
+		lifetime = duration
+	}
+	if lifetime <= 0 {
+		return 0, nil
+	}
+	return time.Now().Add(lifetime).Unix(), nil
+}
+
+func (hs *HTTPServer) sendInviteEmail(c *contextmodel.ReqContext, email string, name string, code string) (bool, response.Response) {
+	emailCmd := notifications.SendEmailCommand{
+		To:       []string{email},
+		Template: "new_user_invite",
+		OrgID:    c.SignedInUser.GetOrgID(),
+		Data: map[string]any{
+			"Name":      name,
+			"OrgName":   c.SignedInUser.GetOrgName(),
+			"Email":     c.SignedInUser.GetEmail(),
+			"LinkUrl":   setting.ToAbsUrl("invite/" + code),
+			"InvitedBy": c.SignedInUser.GetDisplayName(),
+		},
+	}
 
-		emailSentCmd := tempuser.UpdateTempUserWithEmailSentCommand{Code: cmdResult.Code}
-		if err := hs.tempUserService.UpdateTempUserWithEmailSent(c.Req.Context(), &emailSentCmd); err != nil {
-			return response.Error(http.StatusInternalServerError, "Failed to update invite with email sent info", err)
+	if err := hs.AlertNG.NotificationService.SendEmailCommandHandler(c.Req.Context(), &emailCmd); err != nil {
+		if errors.Is(err, notifications.ErrSmtpNotEnabled) {
+			return false, response.Error(http.StatusPreconditionFailed, err.Error(), err)
 		}
 
-		return response.Success(fmt.Sprintf("Sent invite to %s", inviteDto.LoginOrEmail))
+		return false, response.Error(http.StatusInternalServerError, "Failed to send email invite", err)
 	}
 
-	return response.Success(fmt.Sprintf("Created invite for %s", inviteDto.LoginOrEmail))
+	emailSentCmd := tempuser.UpdateTempUserWithEmailSentCommand{Code: code}
+	if err := hs.tempUserService.UpdateTempUserWithEmailSent(c.Req.Context(), &emailSentCmd); err != nil {
+		return false, response.Error(http.StatusInternalServerError, "Failed to update invite with email sent info", err)
+	}
+
+	return true, nil
+}
+
+// swagger:route POST /org/invites/{invitation_code}/resend org_invites resendOrgInvite
+//
+// Resend invite.
+//
+// Sends the invite email again, and renews the expiry of the invite.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+func (hs *HTTPServer) ResendOrgInvite(c *contextmodel.ReqContext) response.Response {
+	query := tempuser.GetTempUserByCodeQuery{Code: web.Params(c.Req)[":code"]}
+	invite, err := hs.tempUserService.GetTempUserByCode(c.Req.Context(), &query)
+	if err != nil {
+		if errors.Is(err, tempuser.ErrTempUserNotFound) {
+			return response.Error(http.StatusNotFound, "Invite not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get invite", err)
+	}
+	if invite.OrgID != c.SignedInUser.GetOrgID() {
+		return response.Error(http.StatusNotFound, "Invite not found", nil)
+	}
+	if invite.Status != tempuser.TmpUserInvitePending && invite.Status != tempuser.TmpUserExpired {
+		return response.Error(http.StatusPreconditionFailed, fmt.Sprintf("Invite cannot be resent in status %s", invite.Status), nil)
+	}
+	if !util.IsEmail(invite.Email) {
+		return response.Error(http.StatusBadRequest, "Invite has no email address", nil)
+	}
+
+	expires, err := hs.inviteExpires("")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to renew invite", err)
+	}
+	if err := hs.tempUserService.UpdateTempUserExpires(c.Req.Context(), &tempuser.UpdateTempUserExpiresCommand{Code: invite.Code, Expires: expires}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to renew invite", err)
+	}
+	if invite.Status == tempuser.TmpUserExpired {
+		if ok, rsp := hs.updateTempUserStatus(c.Req.Context(), invite.Code, tempuser.TmpUserInvitePending); !ok {
+			return rsp
+		}
+	}
+
+	if ok, rsp := hs.sendInviteEmail(c, invite.Email, util.StringsFallback2(invite.Name, invite.Email), invite.Code); !ok {
+		return rsp
+	}
+
+	return response.Success(fmt.Sprintf("Sent invite to %s", invite.Email))
 }
 
 func (hs *HTTPServer) inviteExistingUserToOrg(c *contextmodel.ReqContext, user *user.User, inviteDto *dtos.AddInviteForm) response.Response {
@@ -172,6 +320,8 @@ func (hs *HTTPServer) inviteExistingUserToOrg(c *contextmodel.ReqContext, user *
 		emailCmd := notifications.SendEmailCommand{
 			To:       []string{user.Email},
 			Template: "invited_to_org",
+			OrgID:    c.SignedInUser.GetOrgID(),
+			UserID:   user.ID,
 			Data: map[string]any{
 				"Name":      user.NameOrFallback(),
 				"OrgName":   c.SignedInUser.GetOrgName(),
@@ -222,18 +372,90 @@ func (hs *HTTPServer) GetInviteInfoByCode(c *contextmodel.ReqContext) response.R
 	}
 
 	invite := queryResult
-	if invite.Status != tempuser.TmpUserInvitePending {
+	if invite.Status != tempuser.TmpUserInvitePending || invite.IsExpired(time.Now()) {
 		return response.Error(404, "Invite not found", nil)
 	}
 
-	return response.JSON(http.StatusOK, dtos.InviteInfo{
-		Email:     invite.Email,
-		Name:      invite.Name,
-		Username:  invite.Email,
-		InvitedBy: util.StringsFallback3(invite.InvitedByName, invite.InvitedByLogin, invite.InvitedByEmail),
+	info := dtos.InviteInfo{
+		Email:       invite.Email,
+		Name:        invite.Name,
+		Username:    invite.Email,
+		InvitedBy:   util.StringsFallback3(invite.InvitedByName, invite.InvitedByLogin, invite.InvitedByEmail),
+		SSOProvider: invite.SSOProvider,
+		EmailDomain: invite.EmailDomain,
+		Expires:     invite.Expires,
+	}
+	if invite.SSOProvider != "" {
+		info.SSOProviderLabel = login.GetAuthProviderLabel(invite.SSOProvider)
+	}
+	return response.JSON(http.StatusOK, info)
+}
+
+// AcceptInvite accepts a pending invite for the signed in user, who must be logged in with the SSO provider
+// of the invite if it has one.
+func (hs *HTTPServer) AcceptInvite(c *contextmodel.ReqContext) response.Response {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return response.Error(http.StatusForbidden, "Only users can accept invites", nil)
+	}
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unrecognized user", err)
+	}
+
+	query := tempuser.GetTempUserByCodeQuery{Code: web.Params(c.Req)[":code"]}
+	invite, err := hs.tempUserService.GetTempUserByCode(c.Req.Context(), &query)
+	if err != nil {
+		if errors.Is(err, tempuser.ErrTempUserNotFound) {
+			return response.Error(http.StatusNotFound, "Invite not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get invite", err)
+	}
+	if invite.Status != tempuser.TmpUserInvitePending {
+		return response.Error(http.StatusPreconditionFailed, fmt.Sprintf("Invite cannot be used in status %s", invite.Status), nil)
+	}
+
+	usr, err := hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+
+	authModule := c.SignedInUser.GetAuthenticatedBy()
+	if invite.SSOProvider != "" && authModule != invite.SSOProvider {
+		// The sessions do not keep the module the user logged in with, which is found in the auth info of the user.
+		if _, err := hs.authInfoService.GetAuthInfo(c.Req.Context(), &login.GetAuthInfoQuery{UserId: usr.ID, AuthModule: invite.SSOProvider}); err == nil {
+			authModule = invite.SSOProvider
+		}
+	}
+	if err := invite.CanBeAcceptedBy(usr.Email, authModule, time.Now()); err != nil {
+		return inviteNotAcceptedResponse(invite, err)
+	}
+	if inviteMail, err := ValidateAndNormalizeEmail(invite.Email); err == nil && inviteMail != "" && !strings.EqualFold(usr.Email, inviteMail) {
+		return inviteNotAcceptedResponse(invite, tempuser.ErrInviteEmail)
+	}
+
+	if ok, rsp := hs.applyUserInvite(c.Req.Context(), usr, invite, true); !ok {
+		return rsp
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Invite accepted",
+		"orgId":   invite.OrgID,
 	})
 }
 
+func inviteNotAcceptedResponse(invite *tempuser.TempUserDTO, err error) response.Response {
+	switch {
+	case errors.Is(err, tempuser.ErrInviteExpired):
+		return response.Error(http.StatusPreconditionFailed, "Invite has expired", nil)
+	case errors.Is(err, tempuser.ErrInviteSSORequired):
+		return response.Error(http.StatusPreconditionFailed, fmt.Sprintf("Invite must be accepted by logging in with %s", login.GetAuthProviderLabel(invite.SSOProvider)), nil)
+	case errors.Is(err, tempuser.ErrInviteEmailDomain), errors.Is(err, tempuser.ErrInviteEmail):
+		return response.Error(http.StatusForbidden, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to accept invite", err)
+}
+
 func (hs *HTTPServer) CompleteInvite(c *contextmodel.ReqContext) response.Response {
 	completeInvite := dtos.CompleteInviteForm{}
 	var err error
@@ -262,6 +484,10 @@ func (hs *HTTPServer) CompleteInvite(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusPreconditionFailed, fmt.Sprintf("Invite cannot be used in status %s", invite.Status), nil)
 	}
 
+	if err := invite.CanBeAcceptedBy(completeInvite.Email, login.PasswordAuthModule, time.Now()); err != nil {
+		return inviteNotAcceptedResponse(invite, err)
+	}
+
 	// In case the user is invited by email address
 	if inviteMail, err := ValidateAndNormalizeEmail(invite.Email); err == nil {
 		// Make sure that the email address is not amended
@@ -277,6 +503,9 @@ func (hs *HTTPServer) CompleteInvite(c *contextmodel.ReqContext) response.Respon
 		Password:     completeInvite.Password,
 		SkipOrgSetup: true,
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), &user.User{Login: cmd.Login, Email: cmd.Email}, cmd.Password); err != nil {
+		return response.Err(err)
+	}
 
 	usr, err := hs.userService.Create(c.Req.Context(), &cmd)
 	if err != nil {
@@ -286,6 +515,7 @@ func (hs *HTTPServer) CompleteInvite(c *contextmodel.ReqContext) response.Respon
 
 		return response.Error(500, "failed to create user", err)
 	}
+	hs.recordPassword(c, usr.ID, usr.Password, usr.Salt)
 
 	if err := hs.bus.Publish(c.Req.Context(), &events.SignUpCompleted{
 		Name:  usr.NameOrFallback(),
@@ -353,7 +583,14 @@ type AddInviteParams struct {
 	Body dtos.AddInviteForm `json:"body"`
 }
 
-// swagger:parameters revokeInvite
+// swagger:parameters addOrgInvites
+type AddInvitesParams struct {
+	// in:body
+	// required:true
+	Body dtos.BulkAddInviteForm `json:"body"`
+}
+
+// swagger:parameters revokeInvite resendOrgInvite
 type RevokeInviteParams struct {
 	// in:path
 	// required:true
@@ -366,3 +603,11 @@ type GetPendingOrgInvitesResponse struct {
 	// in: body
 	Body []*tempuser.TempUserDTO `json:"body"`
 }
+
+// swagger:response addOrgInvitesResponse
+type AddOrgInvitesResponse struct {
+	// in: body
+	Body struct {
+		Results []dtos.BulkAddInviteResult `json:"results"`
+	} `json:"body"`
+}
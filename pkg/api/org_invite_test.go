@@ -1,15 +1,23 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
+	"github.com/grafana/grafana/pkg/services/temp_user/tempusertest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -75,3 +83,132 @@ func TestOrgInvitesAPIEndpoint_RBAC(t *testing.T) {
 		})
 	}
 }
+
+func TestOrgInvitesAPIEndpoint_AddOrgInvites(t *testing.T) {
+	tempUserService := &tempusertest.FakeTempUserService{}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.UserInviteMaxLifetime = 24 * time.Hour
+		hs.orgService = orgtest.NewOrgServiceFake()
+		hs.userService = &usertest.FakeUserService{ExpectedError: user.ErrUserNotFound}
+		hs.tempUserService = tempUserService
+	})
+
+	body := `{"invites": [
+		{"loginOrEmail": "a@grafana.com", "role": "Viewer", "emailDomain": "grafana.com"},
+		{"loginOrEmail": "b@example.com", "role": "Viewer", "emailDomain": "grafana.com"},
+		{"loginOrEmail": "c@grafana.com", "role": "Viewer", "expiresIn": "48h"},
+		{"loginOrEmail": "d@grafana.com", "role": "Viewer", "ssoProvider": "oauth_github"}
+	]}`
+	permissions := []accesscontrol.Permission{{Action: accesscontrol.ActionOrgUsersAdd, Scope: "users:*"}}
+	req := webtest.RequestWithSignedInUser(server.NewPostRequest("/api/org/invites/bulk", strings.NewReader(body)), userWithPermissions(1, permissions))
+	res, err := server.SendJSON(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var result struct {
+		Results []dtos.BulkAddInviteResult `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	require.NoError(t, res.Body.Close())
+	require.Len(t, result.Results, 4)
+	assert.Equal(t, http.StatusOK, result.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, result.Results[1].Status)
+	assert.Equal(t, "b@example.com is not an email address in the domain grafana.com", result.Results[1].Message)
+	assert.Equal(t, http.StatusBadRequest, result.Results[2].Status)
+	assert.Equal(t, http.StatusBadRequest, result.Results[3].Status)
+}
+
+func TestOrgInvitesAPIEndpoint_AcceptInvite(t *testing.T) {
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer, Email: "user@grafana.com"}
+
+	tests := []struct {
+		desc           string
+		invite         tempuser.TempUserDTO
+		authModule     string
+		userAuthErr    error
+		expectedCode   int
+		expectedStatus tempuser.TempUserStatus
+	}{
+		{
+			desc:           "should accept an invite bound to the SSO provider the user logged in with",
+			invite:         tempuser.TempUserDTO{SSOProvider: login.GithubAuthModule, EmailDomain: "grafana.com"},
+			authModule:     login.GithubAuthModule,
+			expectedCode:   http.StatusOK,
+			expectedStatus: tempuser.TmpUserCompleted,
+		},
+		{
+			desc:           "should accept an invite bound to an SSO provider the user is linked with",
+			invite:         tempuser.TempUserDTO{SSOProvider: login.GithubAuthModule},
+			expectedCode:   http.StatusOK,
+			expectedStatus: tempuser.TmpUserCompleted,
+		},
+		{
+			desc:         "should not accept an invite bound to an SSO provider the user did not log in with",
+			invite:       tempuser.TempUserDTO{SSOProvider: login.GithubAuthModule},
+			authModule:   login.PasswordAuthModule,
+			userAuthErr:  user.ErrUserNotFound,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			desc:         "should not accept an invite for another email domain",
+			invite:       tempuser.TempUserDTO{EmailDomain: "example.com"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not accept an invite for another email address",
+			invite:       tempuser.TempUserDTO{Email: "other@grafana.com"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not accept an expired invite",
+			invite:       tempuser.TempUserDTO{Expires: time.Now().Add(-time.Minute).Unix()},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			invite := tt.invite
+			invite.OrgID = 2
+			invite.Code = "code"
+			invite.Role = org.RoleViewer
+			invite.Status = tempuser.TmpUserInvitePending
+			tempUserService := &tempusertest.FakeTempUserService{ExpectedTempUserDTO: &invite}
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.orgService = orgtest.NewOrgServiceFake()
+				hs.userService = &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Email: signedInUser.Email}}
+				hs.authInfoService = &authinfotest.FakeService{ExpectedError: tt.userAuthErr}
+				hs.tempUserService = tempUserService
+			})
+
+			usr := *signedInUser
+			usr.AuthenticatedBy = tt.authModule
+			req := webtest.RequestWithSignedInUser(server.NewPostRequest("/api/user/invite/code/accept", nil), &usr)
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, tt.expectedStatus, tempUserService.LatestStatus)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestOrgInvitesAPIEndpoint_CompleteInviteBoundToSSOProvider(t *testing.T) {
+	tempUserService := &tempusertest.FakeTempUserService{ExpectedTempUserDTO: &tempuser.TempUserDTO{
+		OrgID:       1,
+		Code:        "code",
+		Email:       "user@grafana.com",
+		Status:      tempuser.TmpUserInvitePending,
+		SSOProvider: login.GithubAuthModule,
+	}}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.tempUserService = tempUserService
+	})
+
+	body := `{"inviteCode": "code", "email": "user@grafana.com", "username": "user", "password": "password", "confirmPassword": "password"}`
+	res, err := server.SendJSON(server.NewPostRequest("/api/user/invite/complete", strings.NewReader(body)))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+}
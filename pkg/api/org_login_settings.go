@@ -0,0 +1,269 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /org/login-settings org getCurrentOrgLoginSettings
+//
+// Get the login page customization of the current organization.
+//
+// Responses:
+// 200: getOrgLoginSettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetCurrentOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	return hs.getOrgLoginSettingsHelper(c, c.SignedInUser.GetOrgID())
+}
+
+// swagger:route PUT /org/login-settings org updateCurrentOrgLoginSettings
+//
+// Update the login page customization of the current organization.
+//
+// Only Grafana admins can change the hosts serving the login page of the organization.
+//
+// Responses:
+// 200: getOrgLoginSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateCurrentOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	return hs.updateOrgLoginSettingsHelper(c, c.SignedInUser.GetOrgID())
+}
+
+// swagger:route DELETE /org/login-settings org deleteCurrentOrgLoginSettings
+//
+// Reset the login page customization of the current organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteCurrentOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	return hs.deleteOrgLoginSettingsHelper(c, c.SignedInUser.GetOrgID())
+}
+
+// swagger:route GET /orgs/{org_id}/login-settings orgs getOrgLoginSettings
+//
+// Get the login page customization of an organization.
+//
+// Responses:
+// 200: getOrgLoginSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	return hs.getOrgLoginSettingsHelper(c, orgID)
+}
+
+// swagger:route PUT /orgs/{org_id}/login-settings orgs updateOrgLoginSettings
+//
+// Update the login page customization of an organization.
+//
+// Only Grafana admins can change the hosts serving the login page of the organization.
+//
+// Responses:
+// 200: getOrgLoginSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	return hs.updateOrgLoginSettingsHelper(c, orgID)
+}
+
+// swagger:route DELETE /orgs/{org_id}/login-settings orgs deleteOrgLoginSettings
+//
+// Reset the login page customization of an organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteOrgLoginSettings(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	return hs.deleteOrgLoginSettingsHelper(c, orgID)
+}
+
+func (hs *HTTPServer) getOrgLoginSettingsHelper(c *contextmodel.ReqContext, orgID int64) response.Response {
+	settings, err := hs.loginSettingsService.Get(c.Req.Context(), orgID)
+	if errors.Is(err, loginsettings.ErrLoginSettingsNotFound) {
+		return response.JSON(http.StatusOK, &loginsettings.LoginSettings{OrgID: orgID})
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get login settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+func (hs *HTTPServer) updateOrgLoginSettingsHelper(c *contextmodel.ReqContext, orgID int64) response.Response {
+	form := dtos.UpdateOrgLoginSettingsForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	existing, err := hs.loginSettingsService.Get(c.Req.Context(), orgID)
+	if errors.Is(err, loginsettings.ErrLoginSettingsNotFound) {
+		existing = &loginsettings.LoginSettings{OrgID: orgID}
+	} else if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get login settings", err)
+	}
+
+	settings := &loginsettings.LoginSettings{
+		OrgID:                orgID,
+		LogoURL:              form.LogoURL,
+		WelcomeText:          form.WelcomeText,
+		Providers:            form.Providers,
+		AutoRedirectProvider: form.AutoRedirectProvider,
+		Hosts:                existing.Hosts,
+	}
+	if form.Hosts != nil {
+		if !c.SignedInUser.GetIsGrafanaAdmin() && !slices.Equal(form.Hosts, existing.Hosts) {
+			return response.Error(http.StatusForbidden, "Only Grafana admins can change the hosts of the login page", nil)
+		}
+		settings.Hosts = form.Hosts
+	}
+
+	if err := hs.validateLoginProviders(settings); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
+	if err := hs.loginSettingsService.Save(c.Req.Context(), settings); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save login settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+func (hs *HTTPServer) deleteOrgLoginSettingsHelper(c *contextmodel.ReqContext, orgID int64) response.Response {
+	if err := hs.loginSettingsService.Delete(c.Req.Context(), orgID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset login settings", err)
+	}
+	return response.Success("Login settings reset")
+}
+
+// validateLoginProviders checks that the visible providers and the auto-redirect provider of the login settings
+// are configured.
+func (hs *HTTPServer) validateLoginProviders(settings *loginsettings.LoginSettings) error {
+	oauthProviders := hs.SocialService.GetOAuthInfoProviders()
+	for _, provider := range settings.Providers {
+		if _, ok := oauthProviders[provider]; ok || provider == loginsettings.ProviderLoginForm || provider == loginsettings.ProviderSAML {
+			continue
+		}
+		return errors.New("unknown login provider " + provider)
+	}
+
+	if provider := settings.AutoRedirectProvider; provider != "" {
+		_, ok := oauthProviders[provider]
+		if !ok && !(provider == loginsettings.ProviderSAML && hs.samlEnabled()) {
+			return errors.New("the auto-redirect provider must be an enabled OAuth or SAML provider")
+		}
+		if !settings.ShowsProvider(provider) {
+			return errors.New("the auto-redirect provider must be a visible provider")
+		}
+	}
+	return nil
+}
+
+// resolveLoginSettings returns the login settings of the organization of the login page, given by the orgId query
+// parameter or else by the host of the request. It returns nil when the login page is not customized.
+func (hs *HTTPServer) resolveLoginSettings(c *contextmodel.ReqContext) *loginsettings.LoginSettings {
+	var settings *loginsettings.LoginSettings
+	var err error
+	if orgID := c.QueryInt64("orgId"); orgID > 0 {
+		settings, err = hs.loginSettingsService.Get(c.Req.Context(), orgID)
+	} else {
+		settings, err = hs.loginSettingsService.GetByHost(c.Req.Context(), c.Req.Host)
+	}
+	if err != nil {
+		if !errors.Is(err, loginsettings.ErrLoginSettingsNotFound) {
+			hs.log.Warn("Failed to get the login settings", "error", err)
+		}
+		return nil
+	}
+	return settings
+}
+
+// applyLoginSettings customizes the settings of the login page.
+func applyLoginSettings(settings *dtos.FrontendSettingsDTO, loginSettings *loginsettings.LoginSettings) {
+	if loginSettings.LogoURL != "" || loginSettings.WelcomeText != "" {
+		if settings.Whitelabeling == nil {
+			settings.Whitelabeling = &dtos.FrontendSettingsWhitelabelingDTO{}
+		}
+		if loginSettings.LogoURL != "" {
+			settings.Whitelabeling.LoginLogo = &loginSettings.LogoURL
+		}
+		if loginSettings.WelcomeText != "" {
+			settings.Whitelabeling.LoginSubtitle = &loginSettings.WelcomeText
+		}
+	}
+
+	for provider := range settings.Oauth {
+		if !loginSettings.ShowsProvider(provider) {
+			delete(settings.Oauth, provider)
+		}
+	}
+	if !loginSettings.ShowsProvider(loginsettings.ProviderSAML) {
+		settings.SamlEnabled = false
+	}
+	if !loginSettings.ShowsProvider(loginsettings.ProviderLoginForm) {
+		settings.DisableLoginForm = true
+	}
+}
+
+// swagger:response getOrgLoginSettingsResponse
+type GetOrgLoginSettingsResponse struct {
+	// in:body
+	Body loginsettings.LoginSettings `json:"body"`
+}
+
+// swagger:parameters updateCurrentOrgLoginSettings
+type UpdateCurrentOrgLoginSettingsParams struct {
+	// in:body
+	// required:true
+	Body dtos.UpdateOrgLoginSettingsForm `json:"body"`
+}
+
+// swagger:parameters updateOrgLoginSettings
+type UpdateOrgLoginSettingsParams struct {
+	// in:body
+	// required:true
+	Body dtos.UpdateOrgLoginSettingsForm `json:"body"`
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters getOrgLoginSettings deleteOrgLoginSettings
+type OrgLoginSettingsParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+	"github.com/grafana/grafana/pkg/services/loginsettings/loginsettingstest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestOrgLoginSettingsAPI(t *testing.T) {
+	setup := func(t *testing.T) (*webtest.Server, *loginsettingstest.FakeLoginSettingsService) {
+		t.Helper()
+		fake := loginsettingstest.NewFakeLoginSettingsService()
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.loginSettingsService = fake
+			hs.SocialService = &mockSocialService{oAuthInfos: oAuthInfos}
+		})
+		return server, fake
+	}
+
+	put := func(t *testing.T, server *webtest.Server, body string, permissions ...accesscontrol.Permission) *http.Response {
+		t.Helper()
+		req := server.NewRequest(http.MethodPut, "/api/org/login-settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+	writePermission := accesscontrol.Permission{Action: accesscontrol.ActionOrgsWrite}
+
+	t.Run("Should return the default settings when the login page is not customized", func(t *testing.T) {
+		server, _ := setup(t)
+		req := server.NewGetRequest("/api/org/login-settings")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsRead}})))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Should save the settings", func(t *testing.T) {
+		server, fake := setup(t)
+		res := put(t, server, `{"logoUrl": "/public/logo.svg", "welcomeText": "Welcome", "providers": ["github", "grafana"], "autoRedirectProvider": "github"}`, writePermission)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NotNil(t, fake.SavedSettings)
+		assert.Equal(t, int64(1), fake.SavedSettings.OrgID)
+		assert.Equal(t, []string{"github", "grafana"}, fake.SavedSettings.Providers)
+	})
+
+	t.Run("Should reject unknown providers", func(t *testing.T) {
+		server, _ := setup(t)
+		res := put(t, server, `{"providers": ["okta"]}`, writePermission)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Should reject an auto-redirect provider hidden from the login page", func(t *testing.T) {
+		server, _ := setup(t)
+		res := put(t, server, `{"providers": ["grafana"], "autoRedirectProvider": "github"}`, writePermission)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Should only let Grafana admins change the hosts", func(t *testing.T) {
+		server, _ := setup(t)
+		res := put(t, server, `{"hosts": ["tenant.example.com"]}`, writePermission)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Should require the permission to write the org", func(t *testing.T) {
+		server, _ := setup(t)
+		res := put(t, server, `{"welcomeText": "Welcome"}`)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
+
+func TestApplyLoginSettings(t *testing.T) {
+	settings := &dtos.FrontendSettingsDTO{
+		Oauth:       map[string]any{"github": nil, "gitlab": nil},
+		SamlEnabled: true,
+	}
+	applyLoginSettings(settings, &loginsettings.LoginSettings{
+		LogoURL:     "/public/logo.svg",
+		WelcomeText: "Welcome",
+		Providers:   []string{"github"},
+	})
+
+	assert.Equal(t, map[string]any{"github": nil}, settings.Oauth)
+	assert.False(t, settings.SamlEnabled)
+	assert.True(t, settings.DisableLoginForm)
+	require.NotNil(t, settings.Whitelabeling)
+	assert.Equal(t, "/public/logo.svg", *settings.Whitelabeling.LoginLogo)
+	assert.Equal(t, "Welcome", *settings.Whitelabeling.LoginSubtitle)
+}
+
+func TestLoginViewOrgAutoRedirect(t *testing.T) {
+	fakeSetIndexViewData(t)
+
+	sc := setupScenarioContext(t, "/login")
+	cfg := setting.NewCfg()
+	fake := loginsettingstest.NewFakeLoginSettingsService()
+	fake.ExpectedSettings = &loginsettings.LoginSettings{OrgID: 2, AutoRedirectProvider: "github"}
+	hs := &HTTPServer{
+		Cfg:                  cfg,
+		SettingsProvider:     &setting.OSSImpl{Cfg: cfg},
+		License:              &licensing.OSSLicensingService{},
+		SocialService:        &mockSocialService{oAuthInfos: oAuthInfos},
+		Features:             featuremgmt.WithFeatures(),
+		loginSettingsService: fake,
+	}
+
+	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+		hs.LoginView(c)
+		return response.Empty(http.StatusOK)
+	})
+	sc.m.Get(sc.url, sc.defaultHandler)
+	sc.fakeReqNoAssertions("GET", sc.url).exec()
+
+	require.Equal(t, http.StatusTemporaryRedirect, sc.resp.Code)
+	assert.Equal(t, "/login/github", sc.resp.Header().Get("Location"))
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/navlinks"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /org/nav-links org getCurrentOrgNavLinks
+//
+// Get the navigation links of the current organization.
+//
+// Responses:
+// 200: getOrgNavLinksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgNavLinks(c *contextmodel.ReqContext) response.Response {
+	links, err := hs.navLinksService.Get(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get navigation links", err)
+	}
+	return response.JSON(http.StatusOK, dtos.OrgNavLinks{Links: links})
+}
+
+// swagger:route PUT /org/nav-links org updateCurrentOrgNavLinks
+//
+// Replace the navigation links of the current organization.
+//
+// The links are added to the navigation menu of the users of the organization. A link with a required action
+// is only shown to the users having that action.
+//
+// Responses:
+// 200: getOrgNavLinksResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateOrgNavLinks(c *contextmodel.ReqContext) response.Response {
+	form := dtos.OrgNavLinks{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	for _, link := range form.Links {
+		if link == nil {
+			return response.Error(http.StatusBadRequest, "bad request data", nil)
+		}
+	}
+
+	if err := hs.navLinksService.Save(c.Req.Context(), c.SignedInUser.GetOrgID(), form.Links); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save navigation links", err)
+	}
+	if form.Links == nil {
+		form.Links = []*navlinks.Link{}
+	}
+
+	resp := response.JSON(http.StatusOK, form)
+	hs.recordOrgPreferencesUpdate(c, resp, form)
+	return resp
+}
+
+// swagger:response getOrgNavLinksResponse
+type GetOrgNavLinksResponse struct {
+	// in:body
+	Body dtos.OrgNavLinks `json:"body"`
+}
+
+// swagger:parameters updateCurrentOrgNavLinks
+type UpdateCurrentOrgNavLinksParams struct {
+	// in:body
+	// required:true
+	Body dtos.OrgNavLinks `json:"body"`
+}
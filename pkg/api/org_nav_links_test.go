@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/navlinks"
+	"github.com/grafana/grafana/pkg/services/navlinks/navlinkstest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestOrgNavLinksAPI(t *testing.T) {
+	setup := func(t *testing.T) (*webtest.Server, *navlinkstest.FakeNavLinksService) {
+		t.Helper()
+		fake := navlinkstest.NewFakeNavLinksService()
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.navLinksService = fake
+		})
+		return server, fake
+	}
+
+	put := func(t *testing.T, server *webtest.Server, body string, permissions ...accesscontrol.Permission) *http.Response {
+		t.Helper()
+		req := server.NewRequest(http.MethodPut, "/api/org/nav-links", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+	writePermission := accesscontrol.Permission{Action: accesscontrol.ActionOrgsPreferencesWrite}
+
+	t.Run("Should return the links of the organization", func(t *testing.T) {
+		server, fake := setup(t)
+		fake.ExpectedLinks = []*navlinks.Link{{ID: "runbooks", Title: "Runbooks", URL: "https://wiki.example.com"}}
+
+		req := server.NewGetRequest("/api/org/nav-links")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsPreferencesRead}})))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result dtos.OrgNavLinks
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.NoError(t, res.Body.Close())
+		require.Len(t, result.Links, 1)
+		assert.Equal(t, "Runbooks", result.Links[0].Title)
+	})
+
+	t.Run("Should require permission to read the preferences of the organization", func(t *testing.T) {
+		server, _ := setup(t)
+
+		req := server.NewGetRequest("/api/org/nav-links")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Should save the links", func(t *testing.T) {
+		server, fake := setup(t)
+
+		res := put(t, server, `{"links": [{"title": "Runbooks", "icon": "book", "url": "/d/runbooks", "action": "dashboards:read"}]}`, writePermission)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, fake.SavedLinks, 1)
+		assert.Equal(t, "dashboards:read", fake.SavedLinks[0].Action)
+	})
+
+	t.Run("Should return the validation errors of the service", func(t *testing.T) {
+		server, fake := setup(t)
+		fake.ExpectedError = navlinks.ErrInvalidURL.Errorf("invalid URL")
+
+		res := put(t, server, `{"links": [{"title": "Runbooks", "url": "javascript:alert(1)"}]}`, writePermission)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Should require permission to write the preferences of the organization", func(t *testing.T) {
+		server, fake := setup(t)
+
+		res := put(t, server, `{"links": []}`, accesscontrol.Permission{Action: accesscontrol.ActionOrgsPreferencesRead})
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Nil(t, fake.SavedLinks)
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/apiusage"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /org/usage getCurrentOrg getCurrentOrgUsage
+//
+// Fetch the monthly API usage of the current organization.
+//
+// Returns the API requests and the data source queries of the organization per month, most recent month first.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:read` and scope `org:id:1` (orgIDScope).
+//
+// Responses:
+// 200: getOrgUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetCurrentOrgUsage(c *contextmodel.ReqContext) response.Response {
+	return hs.getOrgUsageHelper(c, c.SignedInUser.GetOrgID())
+}
+
+// swagger:route GET /orgs/{org_id}/usage orgs getOrgUsage
+//
+// Fetch the monthly API usage of an organization.
+//
+// Returns the API requests and the data source queries of the organization per month, most recent month first.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:read` and scope `org:id:1` (orgIDScope).
+//
+// Responses:
+// 200: getOrgUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgUsage(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Err(apiusage.ErrBadRequest.Errorf("orgId is invalid: %w", err))
+	}
+	return hs.getOrgUsageHelper(c, orgID)
+}
+
+func (hs *HTTPServer) getOrgUsageHelper(c *contextmodel.ReqContext, orgID int64) response.Response {
+	usage, err := hs.apiUsageService.GetUsage(c.Req.Context(), &apiusage.GetUsageQuery{
+		OrgID:  orgID,
+		Months: c.QueryInt("months"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get organization usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// swagger:parameters getCurrentOrgUsage
+type GetCurrentOrgUsageParams struct {
+	// Number of months to return, including the current month.
+	// in:query
+	// required:false
+	// default:12
+	Months int `json:"months"`
+}
+
+// swagger:parameters getOrgUsage
+type GetOrgUsageParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+	// Number of months to return, including the current month.
+	// in:query
+	// required:false
+	// default:12
+	Months int `json:"months"`
+}
+
+// swagger:response getOrgUsageResponse
+type GetOrgUsageResponse struct {
+	// in:body
+	Body []*apiusage.Usage `json:"body"`
+}
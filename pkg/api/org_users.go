@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -425,6 +426,7 @@ func (hs *HTTPServer) updateOrgUserHelper(c *contextmodel.ReqContext, cmd org.Up
 		return response.Err(org.ErrCannotChangeRoleForExternallySyncedUser.Errorf("Cannot change role for externally synced user"))
 	}
 
+	previousRole := hs.getOrgUserRole(c.Req.Context(), cmd.OrgID, cmd.UserID)
 	if err := hs.orgService.UpdateOrgUser(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, org.ErrLastOrgAdmin) {
 			return response.Error(http.StatusBadRequest, "Cannot change role so that there is no organization admin left", nil)
@@ -437,9 +439,33 @@ func (hs *HTTPServer) updateOrgUserHelper(c *contextmodel.ReqContext, cmd org.Up
 		OrgID:  cmd.OrgID,
 	})
 
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        cmd.OrgID,
+		Action:       auditlog.ActionOrgUserRoleUpdate,
+		ResourceType: auditlog.ResourceTypeUser,
+		ResourceID:   strconv.FormatInt(cmd.UserID, 10),
+		Before:       map[string]any{"role": previousRole},
+		After:        map[string]any{"role": cmd.Role},
+	})
+
 	return response.Success("Organization user updated")
 }
 
+// getOrgUserRole returns the role of a user in an organization, or an empty role if it cannot be found.
+func (hs *HTTPServer) getOrgUserRole(ctx context.Context, orgID, userID int64) org.RoleType {
+	orgs, err := hs.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		hs.log.Warn("Failed to get the organizations of user", "userId", userID, "error", err)
+		return ""
+	}
+	for _, o := range orgs {
+		if o.OrgID == orgID {
+			return o.Role
+		}
+	}
+	return ""
+}
+
 // swagger:route DELETE /org/users/{user_id} org removeOrgUserForCurrentOrg
 //
 // Delete user in current organization.
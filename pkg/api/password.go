@@ -86,6 +86,9 @@ func (hs *HTTPServer) ResetPassword(c *contextmodel.ReqContext) response.Respons
 	if password.IsWeak() {
 		return response.Error(400, "New password is too short", nil)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), userResult, form.NewPassword); err != nil {
+		return response.Err(err)
+	}
 
 	cmd := user.ChangeUserPasswordCommand{}
 	cmd.UserID = userResult.ID
@@ -97,6 +100,7 @@ func (hs *HTTPServer) ResetPassword(c *contextmodel.ReqContext) response.Respons
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
 		return response.Error(500, "Failed to change user password", err)
 	}
+	hs.recordPassword(c, userResult.ID, cmd.NewPassword, userResult.Salt)
 
 	if err := hs.loginAttemptService.Reset(c.Req.Context(), username); err != nil {
 		c.Logger.Warn("could not reset login attempts", "err", err, "username", username)
@@ -104,3 +108,12 @@ func (hs *HTTPServer) ResetPassword(c *contextmodel.ReqContext) response.Respons
 
 	return response.Success("User password changed")
 }
+
+// recordPassword records the new password of a user in its password history,
+// so that the password policy can prevent its reuse. Failing to record it
+// does not fail the request, the password is already changed.
+func (hs *HTTPServer) recordPassword(c *contextmodel.ReqContext, userID int64, hashedPassword, salt string) {
+	if err := hs.passwordPolicy.RecordPassword(c.Req.Context(), userID, hashedPassword, salt); err != nil {
+		c.Logger.Warn("Failed to record the password in the password history", "userId", userID, "error", err)
+	}
+}
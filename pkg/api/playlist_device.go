@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (hs *HTTPServer) registerPlaylistDeviceAPI(apiRoute routing.RouteRegister) {
+	authorize := ac.Middleware(hs.AccessControl)
+
+	apiRoute.Group("/playlists/devices", func(deviceRoute routing.RouteRegister) {
+		deviceRoute.Get("/", authorize(ac.EvalPermission(playlist.ActionDevicesRead)), routing.Wrap(hs.SearchPlaylistDevices))
+		deviceRoute.Post("/", authorize(ac.EvalPermission(playlist.ActionDevicesWrite)), routing.Wrap(hs.RegisterPlaylistDevice))
+		deviceRoute.Get("/:deviceUid", authorize(ac.EvalPermission(playlist.ActionDevicesRead)), routing.Wrap(hs.GetPlaylistDevice))
+		deviceRoute.Delete("/:deviceUid", authorize(ac.EvalPermission(playlist.ActionDevicesWrite)), routing.Wrap(hs.DeletePlaylistDevice))
+		deviceRoute.Post("/:deviceUid/control", authorize(ac.EvalPermission(playlist.ActionDevicesControl)), routing.Wrap(hs.ControlPlaylistDevice))
+	})
+}
+
+// swagger:route GET /playlists/devices playlists searchPlaylistDevices
+//
+// Get playlist devices.
+//
+// Returns the devices of the organization whose playlist playback can be controlled remotely.
+//
+// Responses:
+// 200: searchPlaylistDevicesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) SearchPlaylistDevices(c *contextmodel.ReqContext) response.Response {
+	devices, err := hs.playlistService.SearchDevices(c.Req.Context(), &playlist.GetDevicesQuery{OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get playlist devices", err)
+	}
+
+	return response.JSON(http.StatusOK, devices)
+}
+
+// swagger:route GET /playlists/devices/{deviceUid} playlists getPlaylistDevice
+//
+// Get playlist device.
+//
+// Responses:
+// 200: getPlaylistDeviceResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetPlaylistDevice(c *contextmodel.ReqContext) response.Response {
+	device, err := hs.playlistService.GetDevice(c.Req.Context(), &playlist.GetDeviceQuery{
+		UID:   web.Params(c.Req)[":deviceUid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return playlistDeviceError(err, "Failed to get playlist device")
+	}
+
+	return response.JSON(http.StatusOK, device)
+}
+
+// swagger:route POST /playlists/devices playlists registerPlaylistDevice
+//
+// Register playlist device.
+//
+// Registers a device, such as a kiosk screen, so that its playlist playback can be controlled remotely. A device
+// that registers again with its uid is updated.
+//
+// Responses:
+// 200: getPlaylistDeviceResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RegisterPlaylistDevice(c *contextmodel.ReqContext) response.Response {
+	cmd := playlist.RegisterDeviceCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	if cmd.UID != "" {
+		if err := util.ValidateUID(cmd.UID); err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid device uid", err)
+		}
+	}
+	if cmd.PlaylistUID != "" {
+		if resp := hs.validateDevicePlaylist(c, cmd.PlaylistUID); resp != nil {
+			return resp
+		}
+	}
+
+	device, err := hs.playlistService.RegisterDevice(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to register playlist device", err)
+	}
+
+	return response.JSON(http.StatusOK, device)
+}
+
+// swagger:route DELETE /playlists/devices/{deviceUid} playlists deletePlaylistDevice
+//
+// Delete playlist device.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeletePlaylistDevice(c *contextmodel.ReqContext) response.Response {
+	err := hs.playlistService.DeleteDevice(c.Req.Context(), &playlist.DeleteDeviceCommand{
+		UID:   web.Params(c.Req)[":deviceUid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return playlistDeviceError(err, "Failed to delete playlist device")
+	}
+
+	return response.Success("Playlist device deleted")
+}
+
+// swagger:route POST /playlists/devices/{deviceUid}/control playlists controlPlaylistDevice
+//
+// Control playlist playback on a device.
+//
+// Sends a playback command to a device through its Grafana Live channel: next, previous, pause, resume, or play to
+// switch to another playlist.
+//
+// Responses:
+// 200: controlPlaylistDeviceResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) ControlPlaylistDevice(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.PlaylistDeviceControlCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	action := playlist.ControlAction(cmd.Action)
+	if !action.IsValid() {
+		return response.Error(http.StatusBadRequest, "Action must be one of next, previous, pause, resume and play", nil)
+	}
+	if action == playlist.ControlPlay && cmd.PlaylistUID == "" {
+		return response.Error(http.StatusBadRequest, "The play action requires a playlistUid", nil)
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	device, err := hs.playlistService.GetDevice(c.Req.Context(), &playlist.GetDeviceQuery{
+		UID:   web.Params(c.Req)[":deviceUid"],
+		OrgID: orgID,
+	})
+	if err != nil {
+		return playlistDeviceError(err, "Failed to get playlist device")
+	}
+
+	if action == playlist.ControlPlay {
+		if resp := hs.validateDevicePlaylist(c, cmd.PlaylistUID); resp != nil {
+			return resp
+		}
+		err := hs.playlistService.SetDevicePlaylist(c.Req.Context(), &playlist.SetDevicePlaylistCommand{
+			UID:         device.UID,
+			OrgID:       orgID,
+			PlaylistUID: cmd.PlaylistUID,
+		})
+		if err != nil {
+			return playlistDeviceError(err, "Failed to update playlist device")
+		}
+	}
+
+	msg, err := json.Marshal(playlist.ControlEvent{Action: action, PlaylistUID: cmd.PlaylistUID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to encode playback command", err)
+	}
+
+	connected := false
+	if hs.Live != nil {
+		channel := playlist.DeviceChannel(device.UID)
+		if err := hs.Live.Publish(orgID, channel, msg); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to send playback command", err)
+		}
+		count, err := hs.Live.ClientCount(orgID, channel)
+		if err != nil {
+			hs.log.Warn("Failed to get the number of clients of a playlist device", "device", device.UID, "error", err)
+		}
+		connected = count > 0
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":   "Playback command sent",
+		"connected": connected,
+	})
+}
+
+func (hs *HTTPServer) validateDevicePlaylist(c *contextmodel.ReqContext, playlistUID string) response.Response {
+	_, err := hs.playlistService.GetWithoutItems(c.Req.Context(), &playlist.GetPlaylistByUidQuery{
+		UID:   playlistUID,
+		OrgId: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return response.Error(http.StatusBadRequest, "Playlist not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get playlist", err)
+	}
+	return nil
+}
+
+func playlistDeviceError(err error, message string) response.Response {
+	if errors.Is(err, playlist.ErrDeviceNotFound) {
+		return response.Error(http.StatusNotFound, "Playlist device not found", err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters getPlaylistDevice
+type GetPlaylistDeviceParams struct {
+	// in:path
+	// required:true
+	DeviceUID string `json:"deviceUid"`
+}
+
+// swagger:parameters deletePlaylistDevice
+type DeletePlaylistDeviceParams struct {
+	// in:path
+	// required:true
+	DeviceUID string `json:"deviceUid"`
+}
+
+// swagger:parameters registerPlaylistDevice
+type RegisterPlaylistDeviceParams struct {
+	// in:body
+	// required:true
+	Body playlist.RegisterDeviceCommand
+}
+
+// swagger:parameters controlPlaylistDevice
+type ControlPlaylistDeviceParams struct {
+	// in:body
+	// required:true
+	Body dtos.PlaylistDeviceControlCmd
+	// in:path
+	// required:true
+	DeviceUID string `json:"deviceUid"`
+}
+
+// swagger:response searchPlaylistDevicesResponse
+type SearchPlaylistDevicesResponse struct {
+	// in: body
+	Body []*playlist.Device `json:"body"`
+}
+
+// swagger:response getPlaylistDeviceResponse
+type GetPlaylistDeviceResponse struct {
+	// in: body
+	Body *playlist.Device `json:"body"`
+}
+
+// swagger:response controlPlaylistDeviceResponse
+type ControlPlaylistDeviceResponse struct {
+	// in: body
+	Body struct {
+		Message string `json:"message"`
+		// Connected is true if the device is subscribed to its Grafana Live channel.
+		Connected bool `json:"connected"`
+	} `json:"body"`
+}
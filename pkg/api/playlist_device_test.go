@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/playlist/playlisttest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestPlaylistDeviceAPI(t *testing.T) {
+	setup := func(t *testing.T, svc *playlisttest.FakePlaylistService) *webtest.Server {
+		t.Helper()
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.playlistService = svc
+		})
+	}
+
+	reader := userWithPermissions(1, []accesscontrol.Permission{{Action: playlist.ActionDevicesRead}})
+	controller := userWithPermissions(1, []accesscontrol.Permission{{Action: playlist.ActionDevicesRead}, {Action: playlist.ActionDevicesControl}})
+
+	t.Run("should list the devices with the read permission", func(t *testing.T) {
+		server := setup(t, &playlisttest.FakePlaylistService{ExpectedDevices: []*playlist.Device{{UID: "wall-1", Name: "NOC left screen"}}})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/playlists/devices"), reader))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var devices []*playlist.Device
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&devices))
+		require.Len(t, devices, 1)
+		assert.Equal(t, "wall-1", devices[0].UID)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not register a device without the write permission", func(t *testing.T) {
+		server := setup(t, &playlisttest.FakePlaylistService{})
+
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+			server.NewPostRequest("/api/playlists/devices", strings.NewReader(`{"name": "NOC left screen"}`)), reader))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not control a device without the control permission", func(t *testing.T) {
+		server := setup(t, &playlisttest.FakePlaylistService{ExpectedDevice: &playlist.Device{UID: "wall-1"}})
+
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+			server.NewPostRequest("/api/playlists/devices/wall-1/control", strings.NewReader(`{"action": "next"}`)), reader))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should send the playback commands", func(t *testing.T) {
+		server := setup(t, &playlisttest.FakePlaylistService{
+			ExpectedDevice:   &playlist.Device{UID: "wall-1"},
+			ExpectedPlaylist: &playlist.Playlist{UID: "noc"},
+		})
+
+		for body, status := range map[string]int{
+			`{"action": "next"}`:                       http.StatusOK,
+			`{"action": "pause"}`:                      http.StatusOK,
+			`{"action": "play", "playlistUid": "noc"}`: http.StatusOK,
+			`{"action": "play"}`:                       http.StatusBadRequest,
+			`{"action": "rewind"}`:                     http.StatusBadRequest,
+		} {
+			res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+				server.NewPostRequest("/api/playlists/devices/wall-1/control", strings.NewReader(body)), controller))
+			require.NoError(t, err)
+			assert.Equal(t, status, res.StatusCode, body)
+			require.NoError(t, res.Body.Close())
+		}
+	})
+
+	t.Run("should return 404 for an unknown device", func(t *testing.T) {
+		server := setup(t, &playlisttest.FakePlaylistService{ExpectedError: playlist.ErrDeviceNotFound})
+
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(
+			server.NewPostRequest("/api/playlists/devices/wall-1/control", strings.NewReader(`{"action": "next"}`)), controller))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
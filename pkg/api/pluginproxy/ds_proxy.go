@@ -259,14 +259,12 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 		}, proxy.cfg)
 	}
 
-	if proxy.oAuthTokenService.IsOAuthPassThruEnabled(proxy.ds) {
-		if token := proxy.oAuthTokenService.GetCurrentOAuthToken(req.Context(), proxy.ctx.SignedInUser); token != nil {
-			req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type(), token.AccessToken))
+	if token := proxy.oAuthTokenService.GetForwardedOAuthToken(req.Context(), proxy.ctx.SignedInUser, proxy.ds); token != nil {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type(), token.AccessToken))
 
-			idToken, ok := token.Extra("id_token").(string)
-			if ok && idToken != "" {
-				req.Header.Set("X-ID-Token", idToken)
-			}
+		idToken, ok := token.Extra("id_token").(string)
+		if ok && idToken != "" {
+			req.Header.Set("X-ID-Token", idToken)
 		}
 	}
 
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/plugindef"
 	"github.com/grafana/grafana/pkg/plugins/repo"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -263,6 +264,24 @@ func (hs *HTTPServer) UpdatePluginSetting(c *contextmodel.ReqContext) response.R
 
 	hs.pluginContextProvider.InvalidateSettingsCache(c.Req.Context(), pluginID)
 
+	secureFields := make([]string, 0, len(cmd.SecureJsonData))
+	for k := range cmd.SecureJsonData {
+		secureFields = append(secureFields, k)
+	}
+	sort.Strings(secureFields)
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        cmd.OrgId,
+		Action:       auditlog.ActionPluginSettingsUpdate,
+		ResourceType: auditlog.ResourceTypePlugin,
+		ResourceID:   pluginID,
+		After: map[string]any{
+			"enabled":                 cmd.Enabled,
+			"pinned":                  cmd.Pinned,
+			"pluginVersion":           cmd.PluginVersion,
+			"updatedSecureJsonFields": secureFields,
+		},
+	})
+
 	return response.Success("Plugin settings updated")
 }
 
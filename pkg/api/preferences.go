@@ -3,10 +3,12 @@ package api
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/kinds/preferences"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -193,7 +195,9 @@ func (hs *HTTPServer) UpdateOrgPreferences(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	return prefapi.UpdatePreferencesFor(c.Req.Context(), hs.DashboardService, hs.preferenceService, c.SignedInUser.GetOrgID(), 0, 0, &dtoCmd)
+	resp := prefapi.UpdatePreferencesFor(c.Req.Context(), hs.DashboardService, hs.preferenceService, c.SignedInUser.GetOrgID(), 0, 0, &dtoCmd)
+	hs.recordOrgPreferencesUpdate(c, resp, dtoCmd)
+	return resp
 }
 
 // swagger:route PATCH /org/preferences org_preferences patchOrgPreferences
@@ -211,7 +215,22 @@ func (hs *HTTPServer) PatchOrgPreferences(c *contextmodel.ReqContext) response.R
 	if err := web.Bind(c.Req, &dtoCmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
-	return hs.patchPreferencesFor(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, 0, &dtoCmd)
+	resp := hs.patchPreferencesFor(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, 0, &dtoCmd)
+	hs.recordOrgPreferencesUpdate(c, resp, dtoCmd)
+	return resp
+}
+
+func (hs *HTTPServer) recordOrgPreferencesUpdate(c *contextmodel.ReqContext, resp response.Response, cmd any) {
+	if resp.Status() != http.StatusOK {
+		return
+	}
+	hs.recordAuditLog(c, &auditlog.RecordCommand{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		Action:       auditlog.ActionOrgPreferencesUpdate,
+		ResourceType: auditlog.ResourceTypeOrg,
+		ResourceID:   strconv.FormatInt(c.SignedInUser.GetOrgID(), 10),
+		After:        cmd,
+	})
 }
 
 // swagger:parameters  updateUserPreferences
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -74,6 +75,7 @@ func (hs *HTTPServer) RenderToPng(c *contextmodel.ReqContext) {
 		Timezone:          queryReader.Get("tz", ""),
 		Encoding:          queryReader.Get("encoding", ""),
 		ConcurrentLimit:   hs.Cfg.RendererConcurrentRequestLimit,
+		Class:             rendering.RenderClassInteractive,
 		DeviceScaleFactor: scale,
 		Headers:           headers,
 		Theme:             models.ThemeDark,
@@ -83,6 +85,10 @@ func (hs *HTTPServer) RenderToPng(c *contextmodel.ReqContext) {
 			c.Handle(hs.Cfg, 500, err.Error(), err)
 			return
 		}
+		if errors.Is(err, drain.ErrDraining) {
+			c.Handle(hs.Cfg, http.StatusServiceUnavailable, "Rendering unavailable, server is draining", err)
+			return
+		}
 
 		c.Handle(hs.Cfg, 500, "Rendering failed.", err)
 		return
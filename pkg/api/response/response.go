@@ -3,6 +3,7 @@ package response
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -167,6 +168,47 @@ func (r StreamingResponse) WriteTo(ctx *contextmodel.ReqContext) {
 	}
 }
 
+// CSVStreamingResponse is a response that streams CSV records back to the client.
+type CSVStreamingResponse struct {
+	status    int
+	header    http.Header
+	writeRows func(w *csv.Writer) error
+}
+
+// Status gets the response's status.
+// Required to implement api.Response.
+func (r CSVStreamingResponse) Status() int {
+	return r.status
+}
+
+// Body gets the response's body.
+// Required to implement api.Response.
+func (r CSVStreamingResponse) Body() []byte {
+	return nil
+}
+
+// WriteTo writes the response to the provided context.
+// Required to implement api.Response.
+func (r CSVStreamingResponse) WriteTo(ctx *contextmodel.ReqContext) {
+	header := ctx.Resp.Header()
+	for k, v := range r.header {
+		header[k] = v
+	}
+	ctx.Resp.WriteHeader(r.status)
+
+	// Records are terminated with CRLF as required by RFC 4180.
+	w := csv.NewWriter(ctx.Resp)
+	w.UseCRLF = true
+	if err := r.writeRows(w); err != nil {
+		ctx.Logger.Error("Error writing to response", "err", err)
+		return
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		ctx.Logger.Error("Error writing to response", "err", err)
+	}
+}
+
 // RedirectResponse represents a redirect response.
 type RedirectResponse struct {
 	location string
@@ -212,6 +254,18 @@ func JSONDownload(status int, body any, filename string) *NormalResponse {
 		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, filename))
 }
 
+// CSVDownload creates a streaming CSV response indicating that it should be downloaded.
+func CSVDownload(status int, filename string, writeRows func(w *csv.Writer) error) CSVStreamingResponse {
+	header := make(http.Header)
+	header.Set("Content-Type", "text/csv; charset=utf-8")
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, filename))
+	return CSVStreamingResponse{
+		status:    status,
+		header:    header,
+		writeRows: writeRows,
+	}
+}
+
 // YAML creates a YAML response.
 func YAML(status int, body any) *NormalResponse {
 	b, err := yaml.Marshal(body)
@@ -251,6 +305,7 @@ func Error(status int, message string, err error) *NormalResponse {
 	if message != "" {
 		data["message"] = message
 	}
+	data["code"] = errutil.Code(status, err)
 
 	resp := JSON(status, data)
 
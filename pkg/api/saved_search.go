@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /search/saved search getSavedSearches
+//
+// Get the saved searches of the signed in user.
+//
+// Returns the saved dashboard searches of the user in the current organization, sorted by name.
+//
+// Responses:
+// 200: getSavedSearchesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetSavedSearches(c *contextmodel.ReqContext) response.Response {
+	searches, err := hs.savedSearchService.List(c.Req.Context(), &savedsearch.ListQuery{
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get saved searches", err)
+	}
+	return response.JSON(http.StatusOK, searches)
+}
+
+// swagger:route GET /search/saved/{saved_search_uid} search getSavedSearch
+//
+// Get a saved search of the signed in user.
+//
+// Responses:
+// 200: savedSearchResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetSavedSearch(c *contextmodel.ReqContext) response.Response {
+	search, err := hs.savedSearchService.Get(c.Req.Context(), &savedsearch.GetQuery{
+		UID:    web.Params(c.Req)[":uid"],
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get saved search", err)
+	}
+	return response.JSON(http.StatusOK, search)
+}
+
+// swagger:route POST /search/saved search createSavedSearch
+//
+// Save a search.
+//
+// Saves a dashboard search filter of the signed in user under a name. When subscribed is true, the
+// user is notified by email of the dashboards that match the filter from now on.
+//
+// Responses:
+// 200: savedSearchResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) CreateSavedSearch(c *contextmodel.ReqContext) response.Response {
+	cmd := savedsearch.CreateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.SignedInUser = c.SignedInUser
+
+	search, err := hs.savedSearchService.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save search", err)
+	}
+	return response.JSON(http.StatusOK, search)
+}
+
+// swagger:route PUT /search/saved/{saved_search_uid} search updateSavedSearch
+//
+// Update a saved search.
+//
+// Replaces the name, the filter and the subscription of a saved search of the signed in user. When
+// the filter changes or the user subscribes, only the dashboards that match the filter from now on
+// are notified.
+//
+// Responses:
+// 200: savedSearchResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateSavedSearch(c *contextmodel.ReqContext) response.Response {
+	cmd := savedsearch.UpdateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.SignedInUser = c.SignedInUser
+
+	search, err := hs.savedSearchService.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update saved search", err)
+	}
+	return response.JSON(http.StatusOK, search)
+}
+
+// swagger:route DELETE /search/saved/{saved_search_uid} search deleteSavedSearch
+//
+// Delete a saved search.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteSavedSearch(c *contextmodel.ReqContext) response.Response {
+	err := hs.savedSearchService.Delete(c.Req.Context(), &savedsearch.DeleteCommand{
+		UID:    web.Params(c.Req)[":uid"],
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete saved search", err)
+	}
+	return response.Success("Saved search deleted")
+}
+
+// swagger:parameters getSavedSearch deleteSavedSearch
+type SavedSearchParams struct {
+	// in:path
+	// required:true
+	UID string `json:"saved_search_uid"`
+}
+
+// swagger:parameters createSavedSearch
+type CreateSavedSearchParams struct {
+	// in:body
+	// required:true
+	Body savedsearch.CreateCommand `json:"body"`
+}
+
+// swagger:parameters updateSavedSearch
+type UpdateSavedSearchParams struct {
+	// in:path
+	// required:true
+	UID string `json:"saved_search_uid"`
+	// in:body
+	// required:true
+	Body savedsearch.UpdateCommand `json:"body"`
+}
+
+// swagger:response getSavedSearchesResponse
+type GetSavedSearchesResponse struct {
+	// in: body
+	Body []*savedsearch.SavedSearch `json:"body"`
+}
+
+// swagger:response savedSearchResponse
+type SavedSearchResponse struct {
+	// in: body
+	Body *savedsearch.SavedSearch `json:"body"`
+}
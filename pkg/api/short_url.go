@@ -5,8 +5,11 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/setting"
@@ -21,12 +24,31 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
 	}
 	hs.log.Debug("Received request to create short URL", "path", cmd.Path)
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+
+	createCmd := &shorturls.CreateShortURLCommand{Path: cmd.Path, UID: cmd.UID}
+	if cmd.UID != "" {
+		allowed, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(shorturls.ActionSlugsCreate))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !allowed {
+			return response.Error(http.StatusForbidden, "Not allowed to create short URLs with a custom slug", nil)
+		}
+	}
+	if cmd.TTL != "" {
+		ttl, err := gtime.ParseDuration(cmd.TTL)
+		if err != nil || ttl <= 0 {
+			return response.Err(shorturls.ErrShortURLInvalidTTL.Errorf("invalid expiry %q", cmd.TTL))
+		}
+		createCmd.TTL = ttl
+	}
+
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, createCmd)
 	if err != nil {
 		return response.Err(err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.SignedInUser.GetOrgID())
+	url := shortURLLink(shortURL)
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
@@ -37,6 +59,33 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, dto)
 }
 
+// getShortURL handles requests to get the details of a short URL, including its expiry and how many times it was visited.
+func (hs *HTTPServer) getShortURL(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !util.IsValidShortUID(uid) {
+		return response.Err(shorturls.ErrShortURLNotFound.Errorf("invalid short URL uid: %s", uid))
+	}
+
+	shortURL, err := hs.ShortURLService.GetShortURLByUID(c.Req.Context(), c.SignedInUser, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.ShortURLDetails{
+		UID:        shortURL.Uid,
+		URL:        shortURLLink(shortURL),
+		Path:       shortURL.Path,
+		CreatedAt:  shortURL.CreatedAt,
+		LastSeenAt: shortURL.LastSeenAt,
+		ExpiresAt:  shortURL.ExpiresAt,
+		HitCount:   shortURL.HitCount,
+	})
+}
+
+func shortURLLink(shortURL *shorturls.ShortUrl) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, shortURL.OrgId)
+}
+
 func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 	shortURLUID := web.Params(c.Req)[":uid"]
 
@@ -50,6 +99,10 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 			hs.log.Debug("Not redirecting short URL since not found")
 			return
 		}
+		if shorturls.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since expired")
+			return
+		}
 
 		hs.log.Error("Short URL redirection error", "err", err)
 		return
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -31,12 +33,12 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
 
-		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service, false,
 			func(sc *scenarioContext) {
 				callCreateShortURL(sc)
 
@@ -47,17 +49,63 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 				require.Equal(t, fmt.Sprintf("/goto/%s?orgId=%d", createResp.Uid, createResp.OrgId), shortUrl.URL)
 			})
 	})
+
+	t.Run("Given a request for creating a shortUrl with a slug and expiry", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path: "explore?left=1",
+			UID:  "incident-42",
+			TTL:  "7d",
+		}
+
+		var received *shorturls.CreateShortURLCommand
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+				received = cmd
+				return &shorturls.ShortUrl{OrgId: testOrgID, Uid: cmd.UID, Path: cmd.Path}, nil
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST with the slug permission on", "/api/short-urls", "/api/short-urls", cmd, service, true,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+
+				require.Equal(t, 200, sc.resp.Code)
+				require.Equal(t, "incident-42", received.UID)
+				require.Equal(t, 7*24*time.Hour, received.TTL)
+			})
+
+		createShortURLScenario(t, "When calling POST without the slug permission on", "/api/short-urls", "/api/short-urls", cmd, service, false,
+			func(sc *scenarioContext) {
+				received = nil
+				callCreateShortURL(sc)
+
+				require.Equal(t, 403, sc.resp.Code)
+				require.Nil(t, received)
+			})
+
+		cmd.UID = ""
+		cmd.TTL = "-1h"
+		createShortURLScenario(t, "When calling POST with an invalid expiry on", "/api/short-urls", "/api/short-urls", cmd, service, false,
+			func(sc *scenarioContext) {
+				received = nil
+				callCreateShortURL(sc)
+
+				require.Equal(t, 400, sc.resp.Code)
+				require.Nil(t, received)
+			})
+	})
 }
 
 func callCreateShortURL(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
 
-func createShortURLScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.CreateShortURLCmd, shortURLService shorturls.Service, fn scenarioFunc) {
+func createShortURLScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.CreateShortURLCmd, shortURLService shorturls.Service, canCreateSlugs bool, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		hs := HTTPServer{
 			Cfg:             setting.NewCfg(),
 			ShortURLService: shortURLService,
+			AccessControl:   &actest.FakeAccessControl{ExpectedEvaluate: canCreateSlugs},
 			log:             log.New("test"),
 		}
 
@@ -78,16 +126,16 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
@@ -100,3 +148,7 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *sh
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return nil
 }
+
+func (s *fakeShortURLService) DeleteExpiredShortURLs(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return nil
+}
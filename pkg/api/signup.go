@@ -101,6 +101,10 @@ func (hs *HTTPServer) SignUpStep2(c *contextmodel.ReqContext) response.Response
 		OrgName:  form.OrgName,
 	}
 
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), &user.User{Login: form.Username, Email: form.Email}, form.Password); err != nil {
+		return response.Err(err)
+	}
+
 	// verify email
 	if setting.VerifyEmailEnabled {
 		if ok, rsp := hs.verifyUserSignUpEmail(c.Req.Context(), form.Email, form.Code); !ok {
@@ -117,6 +121,7 @@ func (hs *HTTPServer) SignUpStep2(c *contextmodel.ReqContext) response.Response
 
 		return response.Error(500, "Failed to create user", err)
 	}
+	hs.recordPassword(c, usr.ID, usr.Password, usr.Salt)
 
 	// publish signup event
 	if err := hs.bus.Publish(c.Req.Context(), &events.SignUpCompleted{
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// stateHandoffCookieName is the cookie that holds the state handoff token until it is redeemed, so that the token
+	// is not part of any URL.
+	stateHandoffCookieName = "grafana_state_handoff"
+	// stateHandoffCookieMaxAge is the lifetime of the state handoff tokens, in seconds.
+	stateHandoffCookieMaxAge = 10 * 60
+)
+
+// swagger:route POST /login/handoff login createStateHandoff
+//
+// Hands off the state of an anonymous session to the session created at login.
+//
+// Stores the URL and client state of an anonymous session that hit a permission wall, and returns a short-lived
+// token that can be redeemed once after logging in. The token is also set as `grafana_state_handoff` cookie, and the
+// user is redirected to the URL after logging in.
+//
+// Responses:
+// 200: createStateHandoffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) CreateStateHandoff(c *contextmodel.ReqContext) response.Response {
+	if !c.SignedInUser.IsAnonymous {
+		return response.Error(http.StatusBadRequest, "Only anonymous sessions can hand off their state", nil)
+	}
+
+	cmd := dtos.CreateStateHandoffCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := hs.ValidateRedirectTo(cmd.URL); err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid URL", err)
+	}
+
+	token, err := hs.authnService.MintStateHandoff(c.Req.Context(), c.SignedInUser.GetOrgID(), &authn.StateHandoff{
+		URL:   cmd.URL,
+		State: cmd.State,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create state handoff", err)
+	}
+
+	// The login flows return to the redirect_to cookie, so the user is sent back to the URL. The token is kept in a
+	// cookie rather than in the URL, where it could leak through the logs and the Referer header.
+	cookies.WriteCookie(c.Resp, "redirect_to", url.QueryEscape(cmd.URL), 0, hs.CookieOptionsFromCfg)
+	cookies.WriteCookie(c.Resp, stateHandoffCookieName, token, stateHandoffCookieMaxAge, hs.CookieOptionsFromCfg)
+
+	return response.JSON(http.StatusOK, dtos.StateHandoffToken{
+		Token:    token,
+		LoginURL: hs.Cfg.AppSubURL + "/login",
+	})
+}
+
+// swagger:route POST /login/handoff/redeem login redeemStateHandoff
+//
+// Redeems a state handoff token after logging in.
+//
+// Returns the URL and client state stored for the token of the body, or of the `grafana_state_handoff` cookie when
+// the body has none. A token can only be redeemed once, by a user signed in to the organization of the anonymous
+// session that created it.
+//
+// Responses:
+// 200: redeemStateHandoffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RedeemStateHandoff(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.RedeemStateHandoffCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	token := cmd.Token
+	if token == "" {
+		token = c.GetCookie(stateHandoffCookieName)
+	}
+
+	handoff, err := hs.authnService.RedeemStateHandoff(c.Req.Context(), c.SignedInUser.GetOrgID(), token)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to redeem state handoff", err)
+	}
+	cookies.DeleteCookie(c.Resp, stateHandoffCookieName, hs.CookieOptionsFromCfg)
+
+	return response.JSON(http.StatusOK, dtos.StateHandoff{
+		URL:   handoff.URL,
+		State: handoff.State,
+	})
+}
+
+// swagger:parameters createStateHandoff
+type CreateStateHandoffParams struct {
+	// in:body
+	// required:true
+	Body dtos.CreateStateHandoffCmd `json:"body"`
+}
+
+// swagger:parameters redeemStateHandoff
+type RedeemStateHandoffParams struct {
+	// in:body
+	// required:true
+	Body dtos.RedeemStateHandoffCmd `json:"body"`
+}
+
+// swagger:response createStateHandoffResponse
+type CreateStateHandoffResponse struct {
+	// in: body
+	Body dtos.StateHandoffToken `json:"body"`
+}
+
+// swagger:response redeemStateHandoffResponse
+type RedeemStateHandoffResponse struct {
+	// in: body
+	Body dtos.StateHandoff `json:"body"`
+}
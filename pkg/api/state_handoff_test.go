@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestCreateStateHandoff(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.authnService = &authntest.FakeService{ExpectedToken: "handoff-token"}
+	})
+
+	send := func(t *testing.T, usr *user.SignedInUser, body string) *http.Response {
+		t.Helper()
+		req := server.NewRequest(http.MethodPost, "/api/login/handoff", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		webtest.RequestWithSignedInUser(req, usr)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("should return a token and redirect to the URL after login", func(t *testing.T) {
+		res := send(t, &user.SignedInUser{OrgID: 1, IsAnonymous: true}, `{"url":"/d/abc?orgId=1#panel","state":{"dirty":true}}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		token := dtos.StateHandoffToken{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&token))
+		assert.Equal(t, "handoff-token", token.Token)
+		assert.Equal(t, "/login", token.LoginURL)
+
+		var redirectTo string
+		var handoffCookie *http.Cookie
+		for _, cookie := range res.Cookies() {
+			switch cookie.Name {
+			case "redirect_to":
+				redirectTo, _ = url.QueryUnescape(cookie.Value)
+			case stateHandoffCookieName:
+				handoffCookie = cookie
+			}
+		}
+		assert.Equal(t, "/d/abc?orgId=1#panel", redirectTo, "the token should not be part of the URL")
+		require.NotNil(t, handoffCookie)
+		assert.Equal(t, "handoff-token", handoffCookie.Value)
+		assert.True(t, handoffCookie.HttpOnly)
+	})
+
+	t.Run("should reject signed in users", func(t *testing.T) {
+		res := send(t, &user.SignedInUser{UserID: 1, OrgID: 1}, `{"url":"/d/abc"}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should reject absolute URLs", func(t *testing.T) {
+		res := send(t, &user.SignedInUser{OrgID: 1, IsAnonymous: true}, `{"url":"https://example.com/d/abc"}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestRedeemStateHandoff(t *testing.T) {
+	var handoff *authn.StateHandoff
+	var redeemErr error
+	var redeemed string
+	authnService := &authntest.MockService{
+		RedeemStateHandoffFunc: func(_ context.Context, _ int64, token string) (*authn.StateHandoff, error) {
+			redeemed = token
+			return handoff, redeemErr
+		},
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.authnService = authnService
+	})
+
+	send := func(t *testing.T, body string, cookies ...*http.Cookie) *http.Response {
+		t.Helper()
+		req := server.NewRequest(http.MethodPost, "/api/login/handoff/redeem", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1})
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("should return the state of the handoff", func(t *testing.T) {
+		handoff = &authn.StateHandoff{URL: "/d/abc", State: json.RawMessage(`{"dirty":true}`)}
+		res := send(t, `{"token":"handoff-token"}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "handoff-token", redeemed)
+
+		result := dtos.StateHandoff{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, "/d/abc", result.URL)
+		assert.JSONEq(t, `{"dirty":true}`, string(result.State))
+	})
+
+	t.Run("should redeem the token of the cookie and delete the cookie", func(t *testing.T) {
+		res := send(t, `{}`, &http.Cookie{Name: stateHandoffCookieName, Value: "cookie-token"})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "cookie-token", redeemed)
+
+		var deleted bool
+		for _, cookie := range res.Cookies() {
+			if cookie.Name == stateHandoffCookieName {
+				deleted = cookie.MaxAge < 0
+			}
+		}
+		assert.True(t, deleted)
+	})
+
+	t.Run("should return not found for an unknown token", func(t *testing.T) {
+		redeemErr = authn.ErrStateHandoffNotFound.Errorf("not found")
+		res := send(t, `{"token":"handoff-token"}`)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
@@ -15,6 +15,10 @@ type ErrorResponseBody struct {
 	// required: true
 	Message string `json:"message"`
 
+	// Code A stable machine-readable code identifying the error, such as dashboard.version-conflict.
+	// required: true
+	Code string `json:"code"`
+
 	// Error An optional detailed description of the actual error. Only included if running in developer mode.
 	Error string `json:"error"`
 
@@ -62,6 +66,11 @@ type ConflictError GenericError
 // swagger:response preconditionFailedError
 type PreconditionFailedError GenericError
 
+// PreconditionRequiredError
+//
+// swagger:response preconditionRequiredError
+type PreconditionRequiredError GenericError
+
 // UnprocessableEntityError
 //
 // swagger:response unprocessableEntityError
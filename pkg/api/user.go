@@ -504,6 +504,9 @@ func (hs *HTTPServer) ChangeUserPassword(c *contextmodel.ReqContext) response.Re
 	if password.IsWeak() {
 		return response.Error(http.StatusBadRequest, "New password is too short", nil)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), usr, cmd.NewPassword); err != nil {
+		return response.Err(err)
+	}
 
 	cmd.UserID = userID
 	cmd.NewPassword, err = util.EncodePassword(cmd.NewPassword, usr.Salt)
@@ -514,6 +517,7 @@ func (hs *HTTPServer) ChangeUserPassword(c *contextmodel.ReqContext) response.Re
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to change user password", err)
 	}
+	hs.recordPassword(c, userID, cmd.NewPassword, usr.Salt)
 
 	return response.Success("User password changed")
 }
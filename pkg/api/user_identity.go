@@ -0,0 +1,240 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /user/identities signed_in_user getUserIdentities
+//
+// Identities of the actual User.
+//
+// Returns the identities of the authentication providers linked to the actual user. The attributes and roles of the user are synced from the primary identity.
+//
+// Responses:
+// 200: getUserIdentitiesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetUserIdentities(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	return hs.getUserIdentitiesInternal(c, userID)
+}
+
+// swagger:route PUT /user/identities/{auth_module}/primary signed_in_user setUserPrimaryIdentity
+//
+// Set the primary identity of the actual User.
+//
+// The attributes and roles of the user are synced from the primary identity on the next logins, the logins with the other identities no longer update them.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetUserPrimaryIdentity(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	return hs.setPrimaryIdentityInternal(c, userID)
+}
+
+// swagger:route DELETE /user/identities/{auth_module} signed_in_user unlinkUserIdentity
+//
+// Unlink an identity of the actual User.
+//
+// The primary identity cannot be unlinked, another identity must be made primary first.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UnlinkUserIdentity(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	return hs.unlinkIdentityInternal(c, userID)
+}
+
+// swagger:route GET /admin/users/{user_id}/identities admin_users adminGetUserIdentities
+//
+// Identities of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUserIdentitiesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetUserIdentities(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return hs.getUserIdentitiesInternal(c, userID)
+}
+
+// swagger:route PUT /admin/users/{user_id}/identities/{auth_module}/primary admin_users adminSetUserPrimaryIdentity
+//
+// Set the primary identity of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSetUserPrimaryIdentity(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return hs.setPrimaryIdentityInternal(c, userID)
+}
+
+// swagger:route DELETE /admin/users/{user_id}/identities/{auth_module} admin_users adminUnlinkUserIdentity
+//
+// Unlink an identity of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminUnlinkUserIdentity(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return hs.unlinkIdentityInternal(c, userID)
+}
+
+func signedInUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return 0, response.Error(http.StatusForbidden, "entity has no identities", nil)
+	}
+
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+	return userID, nil
+}
+
+func (hs *HTTPServer) getUserIdentitiesInternal(c *contextmodel.ReqContext, userID int64) response.Response {
+	if _, err := hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID}); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, "User not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+
+	userAuths, err := hs.authInfoService.GetUserAuthInfos(c.Req.Context(), &login.GetUserAuthInfosQuery{UserID: userID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user identities", err)
+	}
+
+	result := make([]*dtos.UserIdentity, 0, len(userAuths))
+	for _, userAuth := range userAuths {
+		result = append(result, &dtos.UserIdentity{
+			AuthModule: userAuth.AuthModule,
+			AuthLabel:  login.GetAuthProviderLabel(userAuth.AuthModule),
+			IsPrimary:  userAuth.IsPrimary,
+			Created:    userAuth.Created,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) setPrimaryIdentityInternal(c *contextmodel.ReqContext, userID int64) response.Response {
+	cmd := &login.SetPrimaryAuthInfoCommand{UserID: userID, AuthModule: web.Params(c.Req)[":authModule"]}
+	if err := hs.authInfoService.SetPrimaryAuthInfo(c.Req.Context(), cmd); err != nil {
+		if errors.Is(err, login.ErrAuthInfoNotFound) {
+			return response.Error(http.StatusNotFound, "Identity not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set primary identity", err)
+	}
+	return response.Success("Primary identity updated")
+}
+
+func (hs *HTTPServer) unlinkIdentityInternal(c *contextmodel.ReqContext, userID int64) response.Response {
+	cmd := &login.UnlinkAuthInfoCommand{UserID: userID, AuthModule: web.Params(c.Req)[":authModule"]}
+	if err := hs.authInfoService.UnlinkAuthInfo(c.Req.Context(), cmd); err != nil {
+		switch {
+		case errors.Is(err, login.ErrAuthInfoNotFound):
+			return response.Error(http.StatusNotFound, "Identity not found", err)
+		case errors.Is(err, login.ErrPrimaryAuthInfo):
+			return response.Error(http.StatusBadRequest, "The primary identity cannot be unlinked, make another identity primary first", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to unlink identity", err)
+	}
+	return response.Success("Identity unlinked")
+}
+
+// swagger:parameters setUserPrimaryIdentity unlinkUserIdentity
+type UserIdentityParams struct {
+	// in:path
+	// required:true
+	AuthModule string `json:"auth_module"`
+}
+
+// swagger:parameters adminGetUserIdentities
+type AdminGetUserIdentitiesParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters adminSetUserPrimaryIdentity adminUnlinkUserIdentity
+type AdminUserIdentityParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+	// in:path
+	// required:true
+	AuthModule string `json:"auth_module"`
+}
+
+// swagger:response getUserIdentitiesResponse
+type GetUserIdentitiesResponse struct {
+	// in:body
+	Body []*dtos.UserIdentity `json:"body"`
+}
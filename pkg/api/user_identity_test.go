@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestHTTPServer_UserIdentities(t *testing.T) {
+	var primary *login.SetPrimaryAuthInfoCommand
+	authInfoService := &authinfotest.FakeService{
+		ExpectedUserAuths: []*login.UserAuth{
+			{UserId: 1, AuthModule: login.AzureADAuthModule, IsPrimary: true},
+			{UserId: 1, AuthModule: login.GithubAuthModule},
+		},
+		SetPrimaryAuthInfoFn: func(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error {
+			primary = cmd
+			return nil
+		},
+		UnlinkAuthInfoFn: func(ctx context.Context, cmd *login.UnlinkAuthInfoCommand) error {
+			if cmd.AuthModule == login.AzureADAuthModule {
+				return login.ErrPrimaryAuthInfo
+			}
+			return login.ErrAuthInfoNotFound
+		},
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.authInfoService = authInfoService
+		hs.userService = &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1}}
+	})
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer, Login: "jane"}
+
+	send := func(t *testing.T, method, url string, usr *user.SignedInUser) *http.Response {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(method, url, nil), usr))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("lists the identities of the signed in user", func(t *testing.T) {
+		res := send(t, http.MethodGet, "/api/user/identities", signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var identities []dtos.UserIdentity
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&identities))
+		require.Len(t, identities, 2)
+		assert.Equal(t, login.AzureADLabel, identities[0].AuthLabel)
+		assert.True(t, identities[0].IsPrimary)
+		assert.Equal(t, login.GithubLabel, identities[1].AuthLabel)
+	})
+
+	t.Run("sets the primary identity of the signed in user", func(t *testing.T) {
+		res := send(t, http.MethodPut, "/api/user/identities/oauth_github/primary", signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, &login.SetPrimaryAuthInfoCommand{UserID: 1, AuthModule: login.GithubAuthModule}, primary)
+	})
+
+	t.Run("refuses to unlink the primary identity", func(t *testing.T) {
+		res := send(t, http.MethodDelete, "/api/user/identities/oauth_azuread", signedInUser)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res = send(t, http.MethodDelete, "/api/user/identities/oauth_okta", signedInUser)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("admin endpoints require the users permissions", func(t *testing.T) {
+		res := send(t, http.MethodGet, "/api/admin/users/1/identities", signedInUser)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		admin := authedUserWithPermissions(2, 1, []accesscontrol.Permission{
+			{Action: accesscontrol.ActionUsersRead, Scope: "global.users:*"},
+			{Action: accesscontrol.ActionUsersWrite, Scope: "global.users:*"},
+		})
+		res = send(t, http.MethodGet, "/api/admin/users/1/identities", admin)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		res = send(t, http.MethodPut, "/api/admin/users/1/identities/oauth_azuread/primary", admin)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, &login.SetPrimaryAuthInfoCommand{UserID: 1, AuthModule: login.AzureADAuthModule}, primary)
+	})
+}
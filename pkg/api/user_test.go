@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +28,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy/passwordpolicytest"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/searchusers"
 	"github.com/grafana/grafana/pkg/services/searchusers/filters"
@@ -37,6 +40,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
 func TestUserAPIEndpoint_userLoggedIn(t *testing.T) {
@@ -446,3 +451,51 @@ func updateSignedInUserScenario(t *testing.T, ctx updateUserContext, hs *HTTPSer
 		ctx.fn(sc)
 	})
 }
+
+func TestHTTPServer_ChangeUserPassword_PasswordPolicy(t *testing.T) {
+	salt := "salt"
+	hashed, err := util.EncodePassword("old-password", salt)
+	require.NoError(t, err)
+
+	policy := passwordpolicytest.NewFakeService()
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.userService = &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Salt: salt, Password: hashed}}
+		hs.authInfoService = &authinfotest.FakeService{ExpectedError: user.ErrUserNotFound}
+		hs.passwordPolicy = policy
+	})
+	changePassword := func(t *testing.T) *http.Response {
+		body := `{"oldPassword": "old-password", "newPassword": "new-password"}`
+		req := server.NewRequest(http.MethodPut, "/api/user/password", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1}))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("returns the violations of the policy", func(t *testing.T) {
+		policy.ExpectedError = passwordpolicy.NewViolationError([]passwordpolicy.Violation{
+			{Rule: passwordpolicy.RuleDigit, Message: "The password must contain a digit"},
+		})
+		res := changePassword(t)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		var body struct {
+			MessageID string `json:"messageId"`
+			Extra     struct {
+				Violations []passwordpolicy.Violation `json:"violations"`
+			} `json:"extra"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, "password-policy.violation", body.MessageID)
+		assert.Equal(t, []passwordpolicy.Violation{{Rule: passwordpolicy.RuleDigit, Message: "The password must contain a digit"}}, body.Extra.Violations)
+		assert.Empty(t, policy.Recorded)
+	})
+
+	t.Run("records the new password", func(t *testing.T) {
+		policy.ExpectedError = nil
+		res := changePassword(t)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []int64{1}, policy.Recorded)
+	})
+}
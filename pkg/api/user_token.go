@@ -6,11 +6,10 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/ua-parser/uap-go/uaparser"
-
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/authn"
@@ -76,6 +75,58 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /user/revoke-other-auth-tokens signed_in_user revokeOtherUserAuthTokens
+//
+// Revoke the other auth tokens of the actual User.
+//
+// Revokes all the auth tokens (devices) of the actual user except the one of the request. The user is logged out of all the other devices.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeOtherUserAuthTokens(c *contextmodel.ReqContext) response.Response {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	if c.UserToken == nil {
+		return response.Error(http.StatusBadRequest, "Request is not authenticated by a user auth token", nil)
+	}
+
+	tokens, err := hs.AuthTokenService.GetUserTokens(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user auth tokens", err)
+	}
+
+	revoked := 0
+	for _, token := range tokens {
+		if token.Id == c.UserToken.Id {
+			continue
+		}
+		if err := hs.AuthTokenService.RevokeToken(c.Req.Context(), token, false); err != nil {
+			if errors.Is(err, auth.ErrUserTokenNotFound) {
+				continue
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to revoke user auth token", err)
+		}
+		revoked++
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "User auth tokens revoked",
+		"revoked": revoked,
+	})
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -184,6 +235,13 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 		return response.Error(500, "Failed to get user auth tokens", err)
 	}
 
+	// The sessions of the browser of the request are marked, the device ID of the browser is kept
+	// when it logs in again, or the ID sent by the browser is used.
+	currentDeviceID := c.Req.Header.Get(anonymous.DeviceIDHeader)
+	if c.UserToken != nil && c.UserToken.DeviceId != "" {
+		currentDeviceID = c.UserToken.DeviceId
+	}
+
 	result := []*dtos.UserToken{}
 	for _, token := range tokens {
 		isActive := false
@@ -191,26 +249,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
-
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
-
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
-
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
-
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
-		}
+		device := auth.ParseDevice(token.UserAgent)
 
 		createdAt := time.Unix(token.CreatedAt, 0)
 		seenAt := time.Unix(token.SeenAt, 0)
@@ -222,12 +261,15 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 		result = append(result, &dtos.UserToken{
 			Id:                     token.Id,
 			IsActive:               isActive,
+			IsCurrentDevice:        isActive || (currentDeviceID != "" && token.DeviceId == currentDeviceID),
 			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
+			Device:                 device.Device,
+			DeviceName:             device.Name(),
+			DeviceId:               token.DeviceId,
+			OperatingSystem:        device.OS,
+			OperatingSystemVersion: device.OSVersion,
+			Browser:                device.Browser,
+			BrowserVersion:         device.BrowserVersion,
 			CreatedAt:              createdAt,
 			SeenAt:                 seenAt,
 		})
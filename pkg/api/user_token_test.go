@@ -134,6 +134,8 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "72.0", resultOne.Get("browserVersion").MustString())
 			assert.Equal(t, "Linux", resultOne.Get("os").MustString())
 			assert.Empty(t, resultOne.Get("osVersion").MustString())
+			assert.Equal(t, "Chrome 72.0 on Linux", resultOne.Get("deviceName").MustString())
+			assert.True(t, resultOne.Get("isCurrentDevice").MustBool())
 
 			resultTwo := result.GetIndex(1)
 			assert.Equal(t, tokens[1].Id, resultTwo.Get("id").MustInt64())
@@ -147,8 +149,55 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "11.0", resultTwo.Get("browserVersion").MustString())
 			assert.Equal(t, "iOS", resultTwo.Get("os").MustString())
 			assert.Equal(t, "11.0", resultTwo.Get("osVersion").MustString())
+			assert.Equal(t, "Mobile Safari 11.0 on iOS 11.0", resultTwo.Get("deviceName").MustString())
+			assert.False(t, resultTwo.Get("isCurrentDevice").MustBool())
 		}, mockUser)
 	})
+
+	t.Run("When gets auth tokens of the device of the request", func(t *testing.T) {
+		currentToken := &auth.UserToken{Id: 1, DeviceId: "device-1"}
+		getUserAuthTokensInternalScenario(t, "Should mark the tokens of the device", currentToken, func(sc *scenarioContext) {
+			sc.userAuthTokenService.GetUserTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return []*auth.UserToken{
+					{Id: 1, DeviceId: "device-1"},
+					{Id: 2, DeviceId: "device-1"},
+					{Id: 3, DeviceId: "device-2"},
+				}, nil
+			}
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, 200, sc.resp.Code)
+			result := sc.ToJSON()
+			assert.True(t, result.GetIndex(0).Get("isCurrentDevice").MustBool())
+			assert.True(t, result.GetIndex(1).Get("isCurrentDevice").MustBool())
+			assert.False(t, result.GetIndex(1).Get("isActive").MustBool())
+			assert.False(t, result.GetIndex(2).Get("isCurrentDevice").MustBool())
+			assert.Equal(t, "Unknown device", result.GetIndex(2).Get("deviceName").MustString())
+		}, usertest.NewUserServiceFake())
+	})
+
+	t.Run("When revoking the other auth tokens", func(t *testing.T) {
+		revokeOtherUserAuthTokensScenario(t, "Should revoke all the tokens except the active one", &auth.UserToken{Id: 2}, func(sc *scenarioContext) {
+			sc.userAuthTokenService.GetUserTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return []*auth.UserToken{{Id: 1}, {Id: 2}, {Id: 3}}, nil
+			}
+			var revoked []int64
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *auth.UserToken, soft bool) error {
+				revoked = append(revoked, token.Id)
+				return nil
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, []int64{1, 3}, revoked)
+			assert.Equal(t, int64(2), sc.ToJSON().Get("revoked").MustInt64())
+		})
+
+		revokeOtherUserAuthTokensScenario(t, "Should fail without an active token", nil, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	})
 }
 
 func TestHTTPServer_RotateUserAuthToken(t *testing.T) {
@@ -296,6 +345,30 @@ func getUserAuthTokensScenario(t *testing.T, desc string, url string, routePatte
 	})
 }
 
+func revokeOtherUserAuthTokensScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.OrgRole = org.RoleAdmin
+			sc.context.UserToken = token
+
+			return hs.RevokeOtherUserAuthTokens(c)
+		})
+		sc.m.Post("/", sc.defaultHandler)
+		fn(sc)
+	})
+}
+
 func logoutUserFromAllDevicesInternalScenario(t *testing.T, desc string, userId int64, fn scenarioFunc, userService user.Service) {
 	t.Run(desc, func(t *testing.T) {
 		hs := HTTPServer{
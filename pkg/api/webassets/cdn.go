@@ -0,0 +1,127 @@
+package webassets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ContentDelivery resolves the URLs the web assets are loaded from when the
+// index is rendered, so that they can be served from a CDN without a reverse
+// proxy rewriting them.
+//
+// The assets under the signed paths are loaded through URLs with expires and
+// signature query parameters. The signature is the unpadded base64url encoding
+// of the HMAC-SHA256, keyed with the signing key, of the path of the URL and
+// the expiration separated by a newline, for example
+// "/grafana-oss/10.3.0/public/build/app.js\n1700000000". The CDN must only
+// serve these assets when the signature is valid and has not expired.
+type ContentDelivery struct {
+	baseURL     string
+	basePath    string
+	version     string
+	signingKey  []byte
+	signedPaths []string
+	expiration  time.Duration
+	now         func() time.Time
+}
+
+// NewContentDelivery returns the ContentDelivery of the CDN of the edition
+// with the prefix. The assets are served by Grafana when no CDN is configured.
+func NewContentDelivery(cfg *setting.Cfg, prefix string) (*ContentDelivery, error) {
+	baseURL, err := cfg.GetContentDeliveryURL(prefix)
+	if err != nil {
+		return nil, err
+	}
+	cd := &ContentDelivery{
+		baseURL:     baseURL,
+		version:     cfg.BuildVersion,
+		signingKey:  []byte(cfg.CDNSigningKey),
+		signedPaths: make([]string, 0, len(cfg.CDNSignedPaths)),
+		expiration:  cfg.CDNSignedURLExpiration,
+		now:         time.Now,
+	}
+	for _, p := range cfg.CDNSignedPaths {
+		cd.signedPaths = append(cd.signedPaths, strings.TrimPrefix(p, "/"))
+	}
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		cd.basePath = u.Path
+	}
+	return cd, nil
+}
+
+// BaseURL returns the URL of the root of the assets on the CDN, or an empty
+// string when no CDN is configured.
+func (cd *ContentDelivery) BaseURL() string {
+	return cd.baseURL
+}
+
+// URL returns the URL of the asset with the path, relative to the root of the
+// assets, for example public/img/grafana_icon.svg.
+//
+// The assets served by Grafana that are not fingerprinted by the build have the
+// version as query parameter, so that the browsers reload them after upgrades.
+// The CDN URLs already contain the version.
+func (cd *ContentDelivery) URL(assetPath string) string {
+	if cd.baseURL == "" {
+		if cd.version == "" || strings.HasPrefix(assetPath, "public/build/") {
+			return assetPath
+		}
+		return assetPath + "?" + url.Values{"v": {cd.version}}.Encode()
+	}
+
+	u := cd.baseURL + assetPath
+	if !cd.isSigned(assetPath) {
+		return u
+	}
+	expires := strconv.FormatInt(cd.now().Add(cd.expiration).Unix(), 10)
+	return u + "?" + url.Values{
+		"expires":   {expires},
+		"signature": {cd.sign(cd.basePath+assetPath, expires)},
+	}.Encode()
+}
+
+// Resolve returns a copy of the assets with their URLs. The assets are copied
+// because the signed URLs expire and the assets are cached.
+func (cd *ContentDelivery) Resolve(assets *dtos.EntryPointAssets) *dtos.EntryPointAssets {
+	resolved := &dtos.EntryPointAssets{
+		JSFiles:  make([]dtos.EntryPointAsset, 0, len(assets.JSFiles)),
+		CSSDark:  cd.resolve(assets.CSSDark),
+		CSSLight: cd.resolve(assets.CSSLight),
+	}
+	for _, asset := range assets.JSFiles {
+		resolved.JSFiles = append(resolved.JSFiles, cd.resolve(asset))
+	}
+	return resolved
+}
+
+func (cd *ContentDelivery) resolve(asset dtos.EntryPointAsset) dtos.EntryPointAsset {
+	asset.URL = cd.URL(asset.FilePath)
+	return asset
+}
+
+func (cd *ContentDelivery) isSigned(assetPath string) bool {
+	for _, prefix := range cd.signedPaths {
+		if strings.HasPrefix(assetPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cd *ContentDelivery) sign(urlPath, expires string) string {
+	mac := hmac.New(sha256.New, cd.signingKey)
+	mac.Write([]byte(urlPath + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,63 @@
+package webassets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestContentDelivery(t *testing.T) {
+	assets := &dtos.EntryPointAssets{
+		JSFiles:  []dtos.EntryPointAsset{{FilePath: "public/build/app.123.js", Integrity: "sha256-abc"}},
+		CSSDark:  dtos.EntryPointAsset{FilePath: "public/build/grafana.dark.123.css"},
+		CSSLight: dtos.EntryPointAsset{FilePath: "public/build/grafana.light.123.css"},
+	}
+
+	t.Run("serves the assets from Grafana without a CDN", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.BuildVersion = "10.3.0"
+
+		cd, err := NewContentDelivery(cfg, "grafana-oss")
+		require.NoError(t, err)
+		assert.Empty(t, cd.BaseURL())
+		assert.Equal(t, "public/build/app.123.js", cd.Resolve(assets).JSFiles[0].URL)
+		assert.Equal(t, "public/img/grafana_icon.svg?v=10.3.0", cd.URL("public/img/grafana_icon.svg"))
+	})
+
+	t.Run("serves the assets from the CDN and signs the restricted ones", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.BuildVersion = "10.3.0"
+		cfg.CDNRootURL, _ = url.Parse("https://cdn.example.com/assets")
+		cfg.CDNSigningKey = "secret"
+		cfg.CDNSignedPaths = []string{"/public/build/"}
+		cfg.CDNSignedURLExpiration = time.Hour
+
+		cd, err := NewContentDelivery(cfg, "grafana-oss")
+		require.NoError(t, err)
+		cd.now = func() time.Time { return time.Unix(1700000000, 0) }
+		assert.Equal(t, "https://cdn.example.com/assets/grafana-oss/10.3.0/", cd.BaseURL())
+		assert.Equal(t, "https://cdn.example.com/assets/grafana-oss/10.3.0/public/img/grafana_icon.svg", cd.URL("public/img/grafana_icon.svg"))
+
+		resolved := cd.Resolve(assets)
+		assert.Empty(t, assets.JSFiles[0].URL, "the cached assets are not modified")
+		assert.Equal(t, "sha256-abc", resolved.JSFiles[0].Integrity)
+
+		u, err := url.Parse(resolved.JSFiles[0].URL)
+		require.NoError(t, err)
+		assert.Equal(t, "/assets/grafana-oss/10.3.0/public/build/app.123.js", u.Path)
+		assert.Equal(t, "1700003600", u.Query().Get("expires"))
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("/assets/grafana-oss/10.3.0/public/build/app.123.js\n1700003600"))
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), u.Query().Get("signature"))
+	})
+}
@@ -84,8 +84,14 @@ func readWebAssets(manifestpath string) (*dtos.EntryPointAssets, error) {
 	}
 
 	return &dtos.EntryPointAssets{
-		JSFiles:  entryPointJSAssets,
-		CSSDark:  entryPoints.Dark.Assets.CSS[0],
-		CSSLight: entryPoints.Light.Assets.CSS[0],
+		JSFiles: entryPointJSAssets,
+		CSSDark: dtos.EntryPointAsset{
+			FilePath:  entryPoints.Dark.Assets.CSS[0],
+			Integrity: integrity[entryPoints.Dark.Assets.CSS[0]],
+		},
+		CSSLight: dtos.EntryPointAsset{
+			FilePath:  entryPoints.Light.Assets.CSS[0],
+			Integrity: integrity[entryPoints.Light.Assets.CSS[0]],
+		},
 	}, nil
 }
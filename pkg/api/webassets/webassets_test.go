@@ -42,7 +42,13 @@ func TestReadWebassets(t *testing.T) {
 			"Integrity": "sha256-q6muaKY7BuN2Ff+00aw69628MXatcFnLNzWRnAD98DI= sha384-gv6lAbkngOHR05bvyOR8dm/J3wIjQQWSjyxK7W8vt2rG9uxcjvvDQV7aI6YbUhfX sha512-o/0mSlJ/OoqrpGdOIWCE3ZCe8n+qqLbgNCERtx9G8FIzsv++CvIWSGbbILjOTGfnEfEQWcKMH0macVpVBSe1Og=="
 		  }
 		],
-		"CSSDark": "public/build/grafana.dark.a28b24b45b2bbcc628cc.css",
-		"CSSLight": "public/build/grafana.light.3572f6d5f8b7daa8d8d0.css"
+		"CSSDark": {
+			"FilePath": "public/build/grafana.dark.a28b24b45b2bbcc628cc.css",
+			"Integrity": "sha256-jO3tO2VnIu6Rl8QIpq4rgw4Gqap0MlEqpku9v1JGOjU= sha384-Ss8rDO0Wo5gL536UcB45IeODOOl4uyLCXRQd3T8uthyAQ2/0QuVu1sxfaypdtOnK sha512-2HEPGHMfrbWJdEuXh5DK5YV8lLm0VnRj6LqoGmIb7790bfTNRm4HHue2j0xCuLvwFAxft4oBRyi8QpbTgW7BbQ=="
+		},
+		"CSSLight": {
+			"FilePath": "public/build/grafana.light.3572f6d5f8b7daa8d8d0.css",
+			"Integrity": "sha256-O/s1VmwrGVCvjzjaHLbE4g1VS7sDVTnnGC5Bww2yROY= sha384-VZUqyZU+WUrMEYYurCifJH9QH+lf+IajBnKQDNzi/BX86lPRZO0ulpe38rXsSkiL sha512-RY3MIXlux98zLH4icMUHQgxOdn+gSSGG6gFZNrzaenKWHnj17QGBf0zZaxNtY674FvgqfmUU3gZsiGR2xFqZUQ=="
+		}
 	  }`, string(dto))
 }
@@ -166,6 +166,24 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Runs alerting commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "run-rule-tests",
+				Usage:  "Runs the unit tests of the alert rules of an organization. Returns an error if a test fails.",
+				Action: runRunnerCommand(runRuleTestsCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization",
+						Value: 1,
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
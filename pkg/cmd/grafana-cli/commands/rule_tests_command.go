@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/fatih/color"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/server"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/ruletest"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// runRuleTestsCommand runs the unit tests of the alert rules of an organization against the current version of the
+// rules. It returns an error if a test fails so that it can be used in CI.
+func runRuleTestsCommand(c utils.CommandLine, runner server.Runner) error {
+	ctx := context.Background()
+	orgID := int64(c.Int("org-id"))
+
+	tracer, err := tracing.ProvideService(runner.Cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	appURL, err := url.Parse(runner.Cfg.AppURL)
+	if err != nil {
+		appURL = nil
+	}
+	dbStore := store.DBstore{
+		Cfg:            runner.Cfg.UnifiedAlerting,
+		FeatureToggles: runner.Features,
+		SQLStore:       runner.SQLStore,
+		Logger:         log.New("ngalert.dbstore"),
+	}
+	expressions := expr.ProvideService(runner.Cfg, nil, nil, runner.Features, prometheus.NewRegistry(), tracer)
+	testRunner := ruletest.NewRunner(runner.Cfg.UnifiedAlerting, expressions, appURL, tracer)
+
+	tests, err := dbStore.ListAlertRuleTests(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	rules := make(map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup)
+	failed := 0
+	for _, test := range tests {
+		key := test.GetGroupKey()
+		group, ok := rules[key]
+		if !ok {
+			group, err = dbStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+				OrgID:         key.OrgID,
+				NamespaceUIDs: []string{key.NamespaceUID},
+				RuleGroup:     key.RuleGroup,
+			})
+			if err != nil {
+				return err
+			}
+			rules[key] = group
+		}
+
+		name := fmt.Sprintf("%s/%s: %s", key.NamespaceUID, key.RuleGroup, test.Title)
+		res, err := testRunner.Run(ctx, schedule.SchedulerUserFor(orgID), group, test)
+		switch {
+		case err != nil:
+			failed++
+			logger.Infof("%s %s\n  %s\n", color.RedString("✗"), name, err)
+		case !res.Passed():
+			failed++
+			logger.Infof("%s %s\n", color.RedString("✗"), name)
+			for _, f := range res.Failures {
+				logger.Infof("  rule %s at %s: %s\n", f.Expectation.RuleUID, f.Expectation.At, f.Message)
+			}
+		default:
+			logger.Infof("%s %s\n", color.GreenString("✔"), name)
+		}
+	}
+
+	logger.Infof("\n%d tests, %d failed\n", len(tests), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d alert rule tests failed", failed, len(tests))
+	}
+	return nil
+}
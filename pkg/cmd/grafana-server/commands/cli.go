@@ -148,12 +148,21 @@ type gserver interface {
 	Shutdown(context.Context, string) error
 }
 
+// drainer is satisfied by the servers supporting drain mode.
+type drainer interface {
+	Drain(reason string)
+}
+
 func listenToSystemSignals(ctx context.Context, s gserver) {
 	signalChan := make(chan os.Signal, 1)
 	sighupChan := make(chan os.Signal, 1)
+	drainChan := make(chan os.Signal, 1)
 
 	signal.Notify(sighupChan, syscall.SIGHUP)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	if len(drainSignals) > 0 {
+		signal.Notify(drainChan, drainSignals...)
+	}
 
 	for {
 		select {
@@ -161,6 +170,10 @@ func listenToSystemSignals(ctx context.Context, s gserver) {
 			if err := log.Reload(); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to reload loggers: %s\n", err)
 			}
+		case sig := <-drainChan:
+			if d, ok := s.(drainer); ok {
+				d.Drain(fmt.Sprintf("System signal: %s", sig))
+			}
 		case sig := <-signalChan:
 			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
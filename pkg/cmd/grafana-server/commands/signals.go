@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// drainSignals are the signals that start draining the server.
+var drainSignals = []os.Signal{syscall.SIGUSR1}
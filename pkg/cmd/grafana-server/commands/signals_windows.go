@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package commands
+
+import (
+	"os"
+)
+
+// drainSignals is empty since SIGUSR1 does not exist on Windows, use the drain admin API instead.
+var drainSignals []os.Signal
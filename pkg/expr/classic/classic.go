@@ -46,6 +46,9 @@ type condition struct {
 	// min and max functions.
 	Reducer reducer
 
+	// Window is the sliding window of the most recent points reduced by the sliding_window reducer.
+	Window *slidingWindow
+
 	// Evaluator evaluates the reduced time series, instant metric, or result of another expression
 	// against an evaluator. An example of an evaluator is checking if it exceeds a threshold,
 	// falls within a range, or does not contain a value.
@@ -177,7 +180,7 @@ func (cmd *ConditionsCmd) executeCond(_ context.Context, _ time.Time, cond condi
 			number = v
 		case mathexp.Series:
 			name = v.GetName()
-			number = cond.Reducer.Reduce(v)
+			number = cond.reduce(v)
 		default:
 			return false, false, nil, fmt.Errorf("can only reduce type series, got type %v", v.Type())
 		}
@@ -272,7 +275,9 @@ type ConditionQueryJSON struct {
 
 type ConditionReducerJSON struct {
 	Type string `json:"type"`
-	// Params []any `json:"params"` (Unused)
+	// Params are only used by the sliding_window reducer: the number of points of the window
+	// followed by the reducer of these points, for example [3, "diff"].
+	Params []any `json:"params"`
 }
 
 // UnmarshalConditionsCmd creates a new ConditionsCmd.
@@ -308,6 +313,12 @@ func UnmarshalConditionsCmd(rawQuery map[string]any, refID string) (*ConditionsC
 		if !cond.Reducer.ValidReduceFunc() {
 			return nil, fmt.Errorf("invalid reducer '%v' in condition %v", cond.Reducer, i+1)
 		}
+		if cond.Reducer == reducerSlidingWindow {
+			cond.Window, err = newSlidingWindow(cj.Reducer.Params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reducer '%v' in condition %v: %w", cond.Reducer, i+1, err)
+			}
+		}
 
 		cond.Evaluator, err = newAlertEvaluator(cj.Evaluator)
 		if err != nil {
@@ -1,6 +1,8 @@
 package classic
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"sort"
 
@@ -9,16 +11,62 @@ import (
 
 type reducer string
 
+// reducerSlidingWindow reduces the most recent points of the series with another reducer.
+const reducerSlidingWindow reducer = "sliding_window"
+
 func (cr reducer) ValidReduceFunc() bool {
 	switch cr {
 	case "avg", "sum", "min", "max", "count", "last", "median":
 		return true
 	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
 		return true
+	case "rate_of_change", reducerSlidingWindow:
+		return true
 	}
 	return false
 }
 
+// slidingWindow are the settings of the sliding_window reducer.
+type slidingWindow struct {
+	size    int
+	reducer reducer
+}
+
+func newSlidingWindow(params []any) (*slidingWindow, error) {
+	if len(params) != 2 {
+		return nil, errors.New("the number of points and the reducer of the window must be set")
+	}
+	size, ok := params[0].(float64)
+	if !ok || size < 1 || size != math.Trunc(size) {
+		return nil, fmt.Errorf("the number of points of the window must be a whole number greater than 0, got %v", params[0])
+	}
+	r, ok := params[1].(string)
+	if !ok || reducer(r) == reducerSlidingWindow || !reducer(r).ValidReduceFunc() {
+		return nil, fmt.Errorf("invalid reducer of the window '%v'", params[1])
+	}
+	return &slidingWindow{size: int(size), reducer: reducer(r)}, nil
+}
+
+// reduce reduces the series with the reducer of the condition.
+func (c condition) reduce(series mathexp.Series) mathexp.Number {
+	if c.Reducer == reducerSlidingWindow && c.Window != nil {
+		return c.Window.reducer.Reduce(lastPoints(series, c.Window.size))
+	}
+	return c.Reducer.Reduce(series)
+}
+
+// lastPoints returns a series with the last n points of the series.
+func lastPoints(series mathexp.Series, n int) mathexp.Series {
+	if series.Len() <= n {
+		return series
+	}
+	window := mathexp.NewSeries(series.Frame.RefID, series.GetLabels(), 0)
+	for i := series.Len() - n; i < series.Len(); i++ {
+		window.AppendPoint(series.GetPoint(i))
+	}
+	return window
+}
+
 //nolint:gocyclo
 func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 	num := mathexp.NewNumber("", nil)
@@ -132,6 +180,8 @@ func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 		allNull, value = calculateDiff(ff, allNull, value, percentDiff)
 	case "percent_diff_abs":
 		allNull, value = calculateDiff(ff, allNull, value, percentDiffAbs)
+	case "rate_of_change":
+		allNull, value = calculateRateOfChange(series)
 	case "count_non_null":
 		for i := 0; i < ff.Len(); i++ {
 			f := ff.GetValue(i)
@@ -182,6 +232,28 @@ func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func
 	return allNull, value
 }
 
+// calculateRateOfChange returns the change per second between the oldest and the newest points.
+func calculateRateOfChange(series mathexp.Series) (bool, float64) {
+	first, last := -1, -1
+	for i := 0; i < series.Len(); i++ {
+		if nilOrNaN(series.GetValue(i)) {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		last = i
+	}
+	if first == -1 || first == last {
+		return true, 0
+	}
+	seconds := series.GetTime(last).Sub(series.GetTime(first)).Seconds()
+	if seconds == 0 {
+		return true, 0
+	}
+	return false, (*series.GetValue(last) - *series.GetValue(first)) / seconds
+}
+
 func nilOrNaN(f *float64) bool {
 	return f == nil || math.IsNaN(*f)
 }
@@ -126,6 +126,74 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestRateOfChangeReducer(t *testing.T) {
+	var tests = []struct {
+		name           string
+		inputSeries    mathexp.Series
+		expectedNumber mathexp.Number
+	}{
+		{
+			name:           "rate of change of one point",
+			inputSeries:    newSeries(util.Pointer(30.0)),
+			expectedNumber: newNumber(nil),
+		},
+		{
+			name:           "rate of change of increasing points",
+			inputSeries:    newSeries(util.Pointer(30.0), util.Pointer(40.0), util.Pointer(50.0)),
+			expectedNumber: newNumber(util.Pointer(10.0)),
+		},
+		{
+			name:           "rate of change of decreasing points",
+			inputSeries:    newSeries(util.Pointer(30.0), util.Pointer(10.0)),
+			expectedNumber: newNumber(util.Pointer(-20.0)),
+		},
+		{
+			name:           "rate of change ignores nulls",
+			inputSeries:    newSeries(nil, util.Pointer(30.0), nil, util.Pointer(50.0), nil),
+			expectedNumber: newNumber(util.Pointer(10.0)),
+		},
+		{
+			name:           "rate of change with only nulls",
+			inputSeries:    newSeries(nil, nil),
+			expectedNumber: newNumber(nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num := reducer("rate_of_change").Reduce(tt.inputSeries)
+			require.Equal(t, tt.expectedNumber, num)
+		})
+	}
+}
+
+func TestSlidingWindowReducer(t *testing.T) {
+	series := newSeries(util.Pointer(10.0), util.Pointer(100.0), util.Pointer(1.0), util.Pointer(2.0), util.Pointer(4.0))
+
+	t.Run("reduces the last points of the series", func(t *testing.T) {
+		cond := condition{Reducer: reducerSlidingWindow, Window: &slidingWindow{size: 3, reducer: "max"}}
+		require.Equal(t, newNumber(util.Pointer(4.0)), cond.reduce(series))
+
+		cond = condition{Reducer: reducerSlidingWindow, Window: &slidingWindow{size: 3, reducer: "diff"}}
+		require.Equal(t, newNumber(util.Pointer(3.0)), cond.reduce(series))
+	})
+
+	t.Run("reduces the whole series if the window is larger than the series", func(t *testing.T) {
+		cond := condition{Reducer: reducerSlidingWindow, Window: &slidingWindow{size: 10, reducer: "max"}}
+		require.Equal(t, newNumber(util.Pointer(100.0)), cond.reduce(series))
+	})
+
+	t.Run("validates the params of the window", func(t *testing.T) {
+		w, err := newSlidingWindow([]any{3.0, "diff"})
+		require.NoError(t, err)
+		require.Equal(t, &slidingWindow{size: 3, reducer: "diff"}, w)
+
+		for _, params := range [][]any{nil, {3.0}, {0.0, "diff"}, {1.5, "diff"}, {"3", "diff"}, {3.0, "foo"}, {3.0, "sliding_window"}} {
+			_, err := newSlidingWindow(params)
+			require.Error(t, err, "params %v", params)
+		}
+	})
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
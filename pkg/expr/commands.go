@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -75,17 +76,29 @@ func (gm *MathCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Va
 	return gm.Expression.Execute(gm.refID, vars, tracer)
 }
 
+// reducerSlidingWindow is the reducer reducing the last points of a timeseries with another reducer.
+const reducerSlidingWindow = "sliding_window"
+
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      string
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
+	// Window is set if the reducer is sliding_window.
+	Window *SlidingWindow
+}
+
+// SlidingWindow are the settings of the sliding_window reducer, which reduces the
+// last Size points of a timeseries with Reducer.
+type SlidingWindow struct {
+	Size    int
+	Reducer string
 }
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	_, err := mathexp.GetSeriesReduceFunc(reducer)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +111,24 @@ func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceM
 	}, nil
 }
 
+// NewSlidingWindowReduceCommand creates a new ReduceCMD reducing the last points of a timeseries.
+func NewSlidingWindowReduceCommand(refID string, window SlidingWindow, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	if window.Size <= 0 {
+		return nil, fmt.Errorf("the size of the sliding window must be greater than 0, got %d", window.Size)
+	}
+	if _, err := mathexp.GetSeriesReduceFunc(window.Reducer); err != nil {
+		return nil, fmt.Errorf("invalid reducer of the sliding window: %w", err)
+	}
+
+	return &ReduceCommand{
+		Reducer:      reducerSlidingWindow,
+		VarToReduce:  varToReduce,
+		refID:        refID,
+		seriesMapper: mapper,
+		Window:       &window,
+	}, nil
+}
+
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
 func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	rawVar, ok := rn.Query["expression"]
@@ -120,10 +151,18 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	}
 
 	var mapper mathexp.ReduceMapper = nil
+	var window *SlidingWindow
 	settings, ok := rn.Query["settings"]
 	if ok {
 		switch s := settings.(type) {
 		case map[string]any:
+			if redFunc == reducerSlidingWindow {
+				w, err := unmarshalSlidingWindow(s)
+				if err != nil {
+					return nil, err
+				}
+				window = &w
+			}
 			mode, ok := s["mode"]
 			if ok && mode != "" {
 				switch mode {
@@ -148,9 +187,35 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
+	if redFunc == reducerSlidingWindow {
+		if window == nil {
+			return nil, errors.New("settings window and windowReducer must be specified when reducer is 'sliding_window'")
+		}
+		return NewSlidingWindowReduceCommand(rn.RefID, *window, varToReduce, mapper)
+	}
 	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
 }
 
+func unmarshalSlidingWindow(settings map[string]any) (SlidingWindow, error) {
+	rawSize, ok := settings["window"]
+	if !ok {
+		return SlidingWindow{}, errors.New("setting window must be specified when reducer is 'sliding_window'")
+	}
+	size, ok := rawSize.(float64)
+	if !ok || size != math.Trunc(size) {
+		return SlidingWindow{}, fmt.Errorf("setting window must be a whole number of points, got %v", rawSize)
+	}
+	rawReducer, ok := settings["windowReducer"]
+	if !ok {
+		return SlidingWindow{}, errors.New("setting windowReducer must be specified when reducer is 'sliding_window'")
+	}
+	reducer, ok := rawReducer.(string)
+	if !ok {
+		return SlidingWindow{}, fmt.Errorf("setting windowReducer must be a string, got %T", rawReducer)
+	}
+	return SlidingWindow{Size: int(size), Reducer: reducer}, nil
+}
+
 // NeedsVars returns the variable names (refIds) that are dependencies
 // to execute the command and allows the command to fulfill the Command interface.
 func (gr *ReduceCommand) NeedsVars() []string {
@@ -169,7 +234,13 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			var num mathexp.Number
+			var err error
+			if gr.Window != nil {
+				num, err = v.ReduceWindow(gr.refID, gr.Window.Size, gr.Window.Reducer, gr.seriesMapper)
+			} else {
+				num, err = v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			}
 			if err != nil {
 				return newRes, err
 			}
@@ -323,6 +394,8 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeJoin is the CMDType for joining the time series of two queries.
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -335,6 +408,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -353,6 +428,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -99,6 +99,67 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_SlidingWindow(t *testing.T) {
+	var tests = []struct {
+		name           string
+		querySettings  string
+		isError        bool
+		expectedWindow *SlidingWindow
+	}{
+		{
+			name:           "sliding window with the size and the reducer of the window",
+			querySettings:  `, "settings" : { "window": 3, "windowReducer": "rate_of_change" }`,
+			expectedWindow: &SlidingWindow{Size: 3, Reducer: "rate_of_change"},
+		},
+		{
+			name:          "error when settings is not specified",
+			querySettings: ``,
+			isError:       true,
+		},
+		{
+			name:          "error when window is not specified",
+			querySettings: `, "settings" : { "windowReducer": "sum" }`,
+			isError:       true,
+		},
+		{
+			name:          "error when window is not a whole number",
+			querySettings: `, "settings" : { "window": 2.5, "windowReducer": "sum" }`,
+			isError:       true,
+		},
+		{
+			name:          "error when window is not greater than 0",
+			querySettings: `, "settings" : { "window": 0, "windowReducer": "sum" }`,
+			isError:       true,
+		},
+		{
+			name:          "error when windowReducer is not known",
+			querySettings: `, "settings" : { "window": 3, "windowReducer": "test" }`,
+			isError:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := fmt.Sprintf(`{ "expression" : "$A", "reducer": "sliding_window"%s }`, test.querySettings)
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID: "A",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedWindow, cmd.Window)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
@@ -0,0 +1,47 @@
+package expr
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+var errPluginsNotAvailable = errors.New("plugins are not available when the data source queries are served by a data handler")
+
+// WithDataHandler returns a copy of the service that sends the data source queries to the handler instead of the
+// data source plugins. The data sources are neither loaded nor authorized, which allows to evaluate expressions
+// against fixture data. Nodes that call plugins directly, like machine learning nodes, fail.
+func (s *Service) WithDataHandler(handler backend.QueryDataHandler) *Service {
+	svc := *s
+	svc.dataService = handler
+	svc.pCtxProvider = dataHandlerPluginContextProvider{}
+	svc.pluginsClient = nil
+	return &svc
+}
+
+// dataHandlerPluginContextProvider returns the plugin context of the queries sent to a data handler.
+type dataHandlerPluginContextProvider struct{}
+
+func (dataHandlerPluginContextProvider) Get(context.Context, string, identity.Requester, int64) (backend.PluginContext, error) {
+	return backend.PluginContext{}, errPluginsNotAvailable
+}
+
+func (dataHandlerPluginContextProvider) GetWithDataSource(_ context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	pCtx := backend.PluginContext{
+		PluginID: pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			ID:   ds.ID,
+			UID:  ds.UID,
+			Type: ds.Type,
+			Name: ds.Name,
+		},
+	}
+	if user != nil {
+		pCtx.OrgID = user.GetOrgID()
+	}
+	return pCtx, nil
+}
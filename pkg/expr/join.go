@@ -0,0 +1,328 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// JoinMode is how the series and the points of the two sides of a join are kept when they have no match.
+type JoinMode string
+
+const (
+	// JoinInner keeps only the series and the points that are in both sides.
+	JoinInner JoinMode = "inner"
+	// JoinLeft keeps all the series and the points of the left side.
+	JoinLeft JoinMode = "left"
+	// JoinOuter keeps all the series and the points of both sides.
+	JoinOuter JoinMode = "outer"
+)
+
+// JoinCommand is an expression command that joins the time series of two queries, which can come from different
+// data sources, and evaluates a math expression on each pair of joined series. Series are joined on the values
+// of a set of labels, and points are joined when their timestamps are within a tolerance of each other.
+type JoinCommand struct {
+	Left      string
+	Right     string
+	Mode      JoinMode
+	On        []string
+	Tolerance time.Duration
+	Math      *MathCommand
+	refID     string
+}
+
+// JoinCommandConfig is the model of a join expression.
+type JoinCommandConfig struct {
+	// Left and Right are the refIds of the queries or expressions to join.
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Mode is one of inner, left and outer. It is inner if empty.
+	Mode JoinMode `json:"mode"`
+	// On are the labels whose values must be equal for two series to be joined. If empty, the series are joined
+	// when all their labels are equal.
+	On []string `json:"on"`
+	// Tolerance is the maximum distance between the timestamps of two points to join them, e.g. 30s.
+	Tolerance string `json:"tolerance"`
+	// Expression is the math expression evaluated on the joined series, e.g. $A / $B.
+	Expression string `json:"expression"`
+}
+
+// NewJoinCommand creates a new JoinCommand.
+func NewJoinCommand(refID string, cfg JoinCommandConfig) (*JoinCommand, error) {
+	if cfg.Left == "" || cfg.Right == "" {
+		return nil, errors.New("join expression requires a left and a right input")
+	}
+	if cfg.Left == cfg.Right {
+		return nil, fmt.Errorf("join expression cannot join %s with itself", cfg.Left)
+	}
+
+	mode := cfg.Mode
+	switch mode {
+	case "":
+		mode = JoinInner
+	case JoinInner, JoinLeft, JoinOuter:
+	default:
+		return nil, fmt.Errorf("expected join mode to be one of [%s, %s, %s], got %s", JoinInner, JoinLeft, JoinOuter, mode)
+	}
+
+	var tolerance time.Duration
+	if cfg.Tolerance != "" {
+		var err error
+		tolerance, err = gtime.ParseDuration(cfg.Tolerance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse join tolerance '%v': %w", cfg.Tolerance, err)
+		}
+		if tolerance < 0 {
+			return nil, fmt.Errorf("join tolerance must not be negative, got %v", cfg.Tolerance)
+		}
+	}
+
+	if cfg.Expression == "" {
+		return nil, errors.New("join expression requires a math expression to evaluate on the joined series")
+	}
+	mathCmd, err := NewMathCommand(refID, cfg.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid join math expression: %w", err)
+	}
+	for _, v := range mathCmd.NeedsVars() {
+		if v != cfg.Left && v != cfg.Right {
+			return nil, fmt.Errorf("join math expression can only reference %s and %s, got %s", cfg.Left, cfg.Right, v)
+		}
+	}
+
+	return &JoinCommand{
+		Left:      cfg.Left,
+		Right:     cfg.Right,
+		Mode:      mode,
+		On:        cfg.On,
+		Tolerance: tolerance,
+		Math:      mathCmd,
+		refID:     refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	cfg := JoinCommandConfig{}
+	if err := json.Unmarshal(rn.QueryRaw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the join command: %w", err)
+	}
+	return NewJoinCommand(rn.RefID, cfg)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return []string{jc.Left, jc.Right}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteJoin")
+	span.SetAttributes(attribute.String("mode", string(jc.Mode)))
+	defer span.End()
+
+	left, err := joinInput(jc.Left, vars[jc.Left])
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	right, err := joinInput(jc.Right, vars[jc.Right])
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+
+	newRes := mathexp.Results{}
+	for _, pair := range jc.joinSeries(left, right) {
+		l, r := jc.joinPoints(pair.left, pair.right, pair.labels)
+		res, err := jc.Math.Execute(ctx, now, mathexp.Vars{
+			jc.Left:  mathexp.Results{Values: mathexp.Values{l}},
+			jc.Right: mathexp.Results{Values: mathexp.Values{r}},
+		}, tracer)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		for _, v := range res.Values {
+			if _, ok := v.(mathexp.NoData); ok {
+				continue
+			}
+			newRes.Values = append(newRes.Values, v)
+		}
+	}
+
+	if len(newRes.Values) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	return newRes, nil
+}
+
+// joinInput returns the series of the results of a side of the join.
+func joinInput(refID string, res mathexp.Results) ([]mathexp.Series, error) {
+	series := make([]mathexp.Series, 0, len(res.Values))
+	for _, val := range res.Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			series = append(series, v)
+		case mathexp.NoData:
+		default:
+			return nil, fmt.Errorf("can only join time series, got type %v for %s", val.Type(), refID)
+		}
+	}
+	return series, nil
+}
+
+type joinedSeries struct {
+	// left or right is nil if the series had no match.
+	left, right *mathexp.Series
+	labels      data.Labels
+}
+
+// joinSeries pairs the series of the two sides that have the same values for the labels of the join.
+// Each series is paired with all the series of the other side it matches, like the rows of a SQL join.
+func (jc *JoinCommand) joinSeries(left, right []mathexp.Series) []joinedSeries {
+	rightByKey := make(map[string][]int)
+	for i, s := range right {
+		key := jc.joinKey(s.GetLabels())
+		rightByKey[key] = append(rightByKey[key], i)
+	}
+
+	var pairs []joinedSeries
+	matchedRight := make(map[int]bool)
+	for i := range left {
+		l := &left[i]
+		matches := rightByKey[jc.joinKey(l.GetLabels())]
+		if len(matches) == 0 && jc.Mode != JoinInner {
+			pairs = append(pairs, joinedSeries{left: l, labels: l.GetLabels().Copy()})
+		}
+		for _, j := range matches {
+			matchedRight[j] = true
+			r := &right[j]
+			pairs = append(pairs, joinedSeries{left: l, right: r, labels: mergeLabels(l.GetLabels(), r.GetLabels())})
+		}
+	}
+
+	if jc.Mode == JoinOuter {
+		for j := range right {
+			if !matchedRight[j] {
+				pairs = append(pairs, joinedSeries{right: &right[j], labels: right[j].GetLabels().Copy()})
+			}
+		}
+	}
+	return pairs
+}
+
+func (jc *JoinCommand) joinKey(labels data.Labels) string {
+	if len(jc.On) == 0 {
+		return labels.String()
+	}
+	values := make([]string, 0, len(jc.On))
+	for _, name := range jc.On {
+		values = append(values, name+"="+labels[name])
+	}
+	return strings.Join(values, ",")
+}
+
+// mergeLabels returns the labels of the left series with the labels of the right series it does not have.
+func mergeLabels(left, right data.Labels) data.Labels {
+	labels := left.Copy()
+	if labels == nil {
+		labels = data.Labels{}
+	}
+	for k, v := range right {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// joinPoints aligns the points of a pair of joined series on the same timestamps, so that they can be
+// combined by a math expression. Each point is joined with the nearest point of the other series within
+// the tolerance that was not joined yet, and takes the timestamp of the left point. A point without a
+// match is dropped in an inner join, and has a null value on the other side otherwise.
+func (jc *JoinCommand) joinPoints(left, right *mathexp.Series, labels data.Labels) (mathexp.Series, mathexp.Series) {
+	l := mathexp.NewSeries(jc.Left, labels, 0)
+	r := mathexp.NewSeries(jc.Right, labels, 0)
+
+	lp, rp := sortedPoints(left), sortedPoints(right)
+	i, j := 0, 0
+	for i < len(lp) || j < len(rp) {
+		switch {
+		case j >= len(rp) || (i < len(lp) && lp[i].t.Before(rp[j].t) && !jc.withinTolerance(lp[i].t, rp[j].t)):
+			if jc.Mode != JoinInner {
+				l.AppendPoint(lp[i].t, lp[i].v)
+				r.AppendPoint(lp[i].t, nil)
+			}
+			i++
+		case i >= len(lp) || (rp[j].t.Before(lp[i].t) && !jc.withinTolerance(lp[i].t, rp[j].t)):
+			if jc.Mode == JoinOuter {
+				l.AppendPoint(rp[j].t, nil)
+				r.AppendPoint(rp[j].t, rp[j].v)
+			}
+			j++
+		case i+1 < len(lp) && absDuration(lp[i+1].t.Sub(rp[j].t)) < absDuration(lp[i].t.Sub(rp[j].t)):
+			// The points are within the tolerance, but the next left point is closer to the right one.
+			if jc.Mode != JoinInner {
+				l.AppendPoint(lp[i].t, lp[i].v)
+				r.AppendPoint(lp[i].t, nil)
+			}
+			i++
+		case j+1 < len(rp) && absDuration(rp[j+1].t.Sub(lp[i].t)) < absDuration(rp[j].t.Sub(lp[i].t)):
+			// The points are within the tolerance, but the next right point is closer to the left one.
+			if jc.Mode == JoinOuter {
+				l.AppendPoint(rp[j].t, nil)
+				r.AppendPoint(rp[j].t, rp[j].v)
+			}
+			j++
+		default:
+			l.AppendPoint(lp[i].t, lp[i].v)
+			r.AppendPoint(lp[i].t, rp[j].v)
+			i++
+			j++
+		}
+	}
+
+	return l, r
+}
+
+func (jc *JoinCommand) withinTolerance(a, b time.Time) bool {
+	return absDuration(a.Sub(b)) <= jc.Tolerance
+}
+
+type joinPoint struct {
+	t time.Time
+	v *float64
+}
+
+func sortedPoints(s *mathexp.Series) []joinPoint {
+	if s == nil {
+		return nil
+	}
+	points := make([]joinPoint, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		points = append(points, joinPoint{t: t, v: v})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+	return points
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
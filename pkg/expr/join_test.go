@@ -0,0 +1,195 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+		assert        func(*testing.T, *JoinCommand)
+	}{
+		{
+			description: "unmarshal proper object",
+			query:       `{"type": "join", "left": "A", "right": "B", "mode": "outer", "on": ["service"], "tolerance": "30s", "expression": "$A / $B"}`,
+			assert: func(t *testing.T, cmd *JoinCommand) {
+				require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+				require.Equal(t, JoinOuter, cmd.Mode)
+				require.Equal(t, []string{"service"}, cmd.On)
+				require.Equal(t, 30*time.Second, cmd.Tolerance)
+				require.Equal(t, "$A / $B", cmd.Math.RawExpression)
+			},
+		},
+		{
+			description: "inner join without tolerance by default",
+			query:       `{"type": "join", "left": "A", "right": "B", "expression": "$A - $B"}`,
+			assert: func(t *testing.T, cmd *JoinCommand) {
+				require.Equal(t, JoinInner, cmd.Mode)
+				require.Zero(t, cmd.Tolerance)
+			},
+		},
+		{
+			description:   "missing right input",
+			query:         `{"type": "join", "left": "A", "expression": "$A"}`,
+			expectedError: "requires a left and a right input",
+		},
+		{
+			description:   "join with itself",
+			query:         `{"type": "join", "left": "A", "right": "A", "expression": "$A"}`,
+			expectedError: "cannot join A with itself",
+		},
+		{
+			description:   "unknown mode",
+			query:         `{"type": "join", "left": "A", "right": "B", "mode": "cross", "expression": "$A / $B"}`,
+			expectedError: "expected join mode to be one of",
+		},
+		{
+			description:   "invalid tolerance",
+			query:         `{"type": "join", "left": "A", "right": "B", "tolerance": "soon", "expression": "$A / $B"}`,
+			expectedError: "failed to parse join tolerance",
+		},
+		{
+			description:   "missing expression",
+			query:         `{"type": "join", "left": "A", "right": "B"}`,
+			expectedError: "requires a math expression",
+		},
+		{
+			description:   "expression referencing another variable",
+			query:         `{"type": "join", "left": "A", "right": "B", "expression": "$A / $C"}`,
+			expectedError: "can only reference A and B, got C",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+
+			cmd, err := UnmarshalJoinCommand(&rawNode{
+				RefID:    "C",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			tc.assert(t, cmd)
+		})
+	}
+}
+
+func TestJoinExecute(t *testing.T) {
+	// Errors are logged by Loki every minute, and requests are scraped by Prometheus at a 10s offset.
+	errors := newJoinSeries("A", data.Labels{"app": "api", "job": "loki"}, map[int64]float64{0: 1, 60: 2, 120: 4})
+	requests := newJoinSeries("B", data.Labels{"app": "api", "instance": "1"}, map[int64]float64{10: 10, 70: 20, 190: 40})
+	otherRequests := newJoinSeries("B", data.Labels{"app": "db"}, map[int64]float64{10: 5})
+
+	execute := func(t *testing.T, cfg JoinCommandConfig) mathexp.Results {
+		t.Helper()
+		cfg.Left, cfg.Right, cfg.Expression = "A", "B", "$A / $B"
+		cmd, err := NewJoinCommand("C", cfg)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": {Values: mathexp.Values{errors}},
+			"B": {Values: mathexp.Values{requests, otherRequests}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("inner join keeps the points matched within the tolerance", func(t *testing.T) {
+		res := execute(t, JoinCommandConfig{On: []string{"app"}, Tolerance: "15s"})
+
+		require.Len(t, res.Values, 1)
+		series := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"app": "api", "job": "loki", "instance": "1"}, series.GetLabels())
+		require.Equal(t, map[int64]*float64{0: util.Pointer(0.1), 60: util.Pointer(0.1)}, joinSeriesPoints(series))
+	})
+
+	t.Run("left join keeps all the points and series of the left side", func(t *testing.T) {
+		res := execute(t, JoinCommandConfig{On: []string{"app"}, Tolerance: "15s", Mode: JoinLeft})
+
+		require.Len(t, res.Values, 1)
+		require.Equal(t, map[int64]*float64{0: util.Pointer(0.1), 60: util.Pointer(0.1), 120: nil}, joinSeriesPoints(res.Values[0].(mathexp.Series)))
+	})
+
+	t.Run("outer join keeps all the points and series of both sides", func(t *testing.T) {
+		res := execute(t, JoinCommandConfig{On: []string{"app"}, Tolerance: "15s", Mode: JoinOuter})
+
+		require.Len(t, res.Values, 2)
+		require.Equal(t, map[int64]*float64{0: util.Pointer(0.1), 60: util.Pointer(0.1), 120: nil, 190: nil}, joinSeriesPoints(res.Values[0].(mathexp.Series)))
+		require.Equal(t, data.Labels{"app": "db"}, res.Values[1].GetLabels())
+		require.Equal(t, map[int64]*float64{10: nil}, joinSeriesPoints(res.Values[1].(mathexp.Series)))
+	})
+
+	t.Run("points are not joined outside of the tolerance", func(t *testing.T) {
+		res := execute(t, JoinCommandConfig{On: []string{"app"}, Tolerance: "5s"})
+
+		require.Len(t, res.Values, 1)
+		require.Empty(t, joinSeriesPoints(res.Values[0].(mathexp.Series)))
+	})
+
+	t.Run("points are joined with the nearest point", func(t *testing.T) {
+		left := newJoinSeries("A", nil, map[int64]float64{0: 1, 10: 2})
+		right := newJoinSeries("B", nil, map[int64]float64{9: 10})
+		cmd, err := NewJoinCommand("C", JoinCommandConfig{Left: "A", Right: "B", Tolerance: "10s", Expression: "$A + $B"})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": {Values: mathexp.Values{left}},
+			"B": {Values: mathexp.Values{right}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, map[int64]*float64{10: util.Pointer(12.0)}, joinSeriesPoints(res.Values[0].(mathexp.Series)))
+	})
+
+	t.Run("series are joined on all their labels by default", func(t *testing.T) {
+		res := execute(t, JoinCommandConfig{Tolerance: "15s"})
+
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("fails to join numbers", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", JoinCommandConfig{Left: "A", Right: "B", Expression: "$A / $B"})
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": {Values: mathexp.Values{mathexp.GenerateNumber(util.Pointer(1.0))}},
+			"B": {Values: mathexp.Values{requests}},
+		}, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "can only join time series")
+	})
+}
+
+func newJoinSeries(refID string, labels data.Labels, points map[int64]float64) mathexp.Series {
+	s := mathexp.NewSeries(refID, labels, 0)
+	for ts, v := range points {
+		s.AppendPoint(time.Unix(ts, 0), util.Pointer(v))
+	}
+	s.SortByTime(false)
+	return s
+}
+
+func joinSeriesPoints(s mathexp.Series) map[int64]*float64 {
+	points := make(map[int64]*float64, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		points[t.Unix()] = v
+	}
+	return points
+}
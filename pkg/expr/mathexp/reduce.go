@@ -81,6 +81,45 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+// RateOfChange returns the change per second between the first and the last point of the series.
+// The points of the series must be sorted by time.
+func RateOfChange(s Series) *float64 {
+	nan := math.NaN()
+	if s.Len() < 2 {
+		return &nan
+	}
+	for i := 0; i < s.Len(); i++ {
+		if v := s.GetValue(i); v == nil || math.IsNaN(*v) {
+			return &nan
+		}
+	}
+	first, last := 0, s.Len()-1
+	seconds := s.GetTime(last).Sub(s.GetTime(first)).Seconds()
+	if seconds == 0 {
+		return &nan
+	}
+	f := (*s.GetValue(last) - *s.GetValue(first)) / seconds
+	return &f
+}
+
+// SeriesReducerFunc reduces a series into a single value. Unlike ReducerFunc, it has access to the time of the points.
+type SeriesReducerFunc = func(s Series) *float64
+
+// GetSeriesReduceFunc returns the function reducing series with the reduction function of the given name.
+func GetSeriesReduceFunc(rFunc string) (SeriesReducerFunc, error) {
+	if strings.ToLower(rFunc) == "rate_of_change" {
+		return RateOfChange, nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Series) *float64 {
+		floatField := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+		return reduceFunc(&floatField)
+	}, nil
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 	switch strings.ToLower(rFunc) {
 	case "sum":
@@ -102,7 +141,7 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "rate_of_change"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
@@ -119,13 +158,11 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -133,6 +170,32 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	return number, nil
 }
 
+// ReduceWindow turns the Series into a Number by reducing its last points, the sliding window, with the given
+// reduction function. If ReduceMapper is defined, the window is taken from the mapped series, so that the points
+// dropped by the mapper are not part of the window.
+func (s Series) ReduceWindow(refID string, size int, rFunc string, mapper ReduceMapper) (Number, error) {
+	if size <= 0 {
+		return NewNumber(refID, nil), fmt.Errorf("invalid expression '%s': the size of the sliding window must be greater than 0", refID)
+	}
+	series := s
+	if mapper != nil {
+		series = mapSeries(s, mapper)
+	}
+	return series.lastPoints(size).Reduce(refID, rFunc, mapper)
+}
+
+// lastPoints returns a series with the last n points of the series.
+func (s Series) lastPoints(n int) Series {
+	if s.Len() <= n {
+		return s
+	}
+	newSeries := NewSeries(s.Frame.RefID, s.GetLabels(), 0)
+	for i := s.Len() - n; i < s.Len(); i++ {
+		newSeries.AppendPoint(s.GetPoint(i))
+	}
+	return newSeries
+}
+
 type ReduceMapper interface {
 	MapInput(s *float64) *float64
 	MapOutput(v *float64) *float64
@@ -179,6 +179,33 @@ func TestSeriesReduce(t *testing.T) {
 			resultsIs:   require.Equal,
 			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:        "rate_of_change series",
+			red:         "rate_of_change",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(-0.2))),
+		},
+		{
+			name:        "rate_of_change series with a nil value",
+			red:         "rate_of_change",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "rate_of_change empty series",
+			red:         "rate_of_change",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
 	}
 
 	for _, tt := range tests {
@@ -204,6 +231,47 @@ func TestSeriesReduce(t *testing.T) {
 	}
 }
 
+func TestSeriesReduceWindow(t *testing.T) {
+	series := makeSeries("temp", nil,
+		tp{time.Unix(5, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(2)},
+		tp{time.Unix(15, 0), nil},
+		tp{time.Unix(20, 0), float64Pointer(8)},
+		tp{time.Unix(25, 0), float64Pointer(12)},
+	)
+
+	t.Run("reduces the last points", func(t *testing.T) {
+		num, err := series.ReduceWindow("", 2, "sum", nil)
+		require.NoError(t, err)
+		require.Equal(t, float64Pointer(20), num.GetFloat64Value())
+
+		num, err = series.ReduceWindow("", 2, "rate_of_change", nil)
+		require.NoError(t, err)
+		require.Equal(t, float64Pointer(0.8), num.GetFloat64Value())
+	})
+
+	t.Run("reduces the whole series if the window is larger than the series", func(t *testing.T) {
+		num, err := series.ReduceWindow("", 10, "count", nil)
+		require.NoError(t, err)
+		require.Equal(t, float64Pointer(5), num.GetFloat64Value())
+	})
+
+	t.Run("takes the window from the mapped series", func(t *testing.T) {
+		num, err := series.ReduceWindow("", 3, "rate_of_change", DropNonNumber{})
+		require.NoError(t, err)
+		require.Equal(t, float64Pointer(10.0/15), num.GetFloat64Value())
+
+		num, err = series.ReduceWindow("", 3, "rate_of_change", nil)
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(*num.GetFloat64Value()))
+	})
+
+	t.Run("fails if the window is empty", func(t *testing.T) {
+		_, err := series.ReduceWindow("", 0, "sum", nil)
+		require.Error(t, err)
+	})
+}
+
 var seriesNonNumbers = Vars{
 	"A": resultValuesNoErr(
 		makeSeries("temp", nil,
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/secrets/secretrefs"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -42,7 +43,7 @@ func TestService(t *testing.T) {
 		PluginList: []pluginstore.Plugin{
 			{JSONData: plugins.JSONData{ID: "test"}},
 		},
-	}, &datafakes.FakeDataSourceService{}, nil, fakes.NewFakeLicensingService(), &config.Cfg{})
+	}, &datafakes.FakeDataSourceService{}, nil, fakes.NewFakeLicensingService(), &config.Cfg{}, secretrefs.ProvideService(setting.NewCfg()))
 
 	s := Service{
 		cfg:          setting.NewCfg(),
@@ -128,7 +129,7 @@ func TestDSQueryError(t *testing.T) {
 		PluginList: []pluginstore.Plugin{
 			{JSONData: plugins.JSONData{ID: "test"}},
 		},
-	}, &datafakes.FakeDataSourceService{}, nil, nil, &config.Cfg{})
+	}, &datafakes.FakeDataSourceService{}, nil, nil, &config.Cfg{}, secretrefs.ProvideService(setting.NewCfg()))
 
 	s := Service{
 		cfg:          setting.NewCfg(),
@@ -50,10 +50,15 @@ type DB interface {
 
 type Session = sqlstore.DBSession
 type InitTestDBOpt = sqlstore.InitTestDBOpt
+type SessionOption = sqlstore.SessionOption
+type RetryPolicy = sqlstore.RetryPolicy
 
 var InitTestDB = sqlstore.InitTestDB
 var InitTestDBwithCfg = sqlstore.InitTestDBWithCfg
 var ProvideService = sqlstore.ProvideService
+var WithSessionOptions = sqlstore.WithSessionOptions
+var WithRetryPolicy = sqlstore.WithRetryPolicy
+var WithStatementTimeout = sqlstore.WithStatementTimeout
 
 func IsTestDbSQLite() bool {
 	if db, present := os.LookupEnv("GRAFANA_TEST_DB"); !present || db == "sqlite" {
@@ -5,6 +5,7 @@ import (
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/metrics/metricutil"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -97,7 +98,9 @@ func executeMiddleware(next http.RoundTripper, labels prometheus.Labels) http.Ro
 
 		res, err := promhttp.InstrumentRoundTripperDuration(requestHistogram,
 			promhttp.InstrumentRoundTripperCounter(requestCounter,
-				promhttp.InstrumentRoundTripperInFlight(requestInFlight, next))).
+				promhttp.InstrumentRoundTripperInFlight(requestInFlight, next),
+				promhttp.WithExemplarFromContext(metrics.ExemplarFromContext)),
+			promhttp.WithExemplarFromContext(metrics.ExemplarFromContext)).
 			RoundTrip(r)
 		if err != nil {
 			return nil, err
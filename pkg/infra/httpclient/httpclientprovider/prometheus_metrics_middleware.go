@@ -6,6 +6,8 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/prometheus/client_golang/prometheus"
+
+	inframetrics "github.com/grafana/grafana/pkg/infra/metrics"
 )
 
 // PrometheusMetrics groups some metrics for a PrometheusMetricsMiddleware
@@ -77,7 +79,7 @@ func PrometheusMetricsMiddleware(metrics *PrometheusMetrics) httpclient.Middlewa
 
 			metrics.inFlightGauge.Dec()
 			metrics.requestsCounter.Inc()
-			metrics.durationSecondsHistogram.Observe(time.Since(startTime).Seconds())
+			inframetrics.ObserveWithExemplar(req.Context(), metrics.durationSecondsHistogram, time.Since(startTime).Seconds())
 			if err != nil || (res != nil && !(res.StatusCode >= 200 && res.StatusCode <= 299)) {
 				metrics.failureCounter.Inc()
 			}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+var ErrInvalidLevelFilter = errors.New("invalid log level filter")
+
+// LoggerLevel is the level of a named logger.
+type LoggerLevel struct {
+	Name string `json:"name"`
+	// Levels are the levels of the logger by log mode, such as console or file.
+	Levels map[string]string `json:"levels"`
+	// Override is set when the level of the logger was changed at runtime.
+	Override *LevelOverride `json:"override,omitempty"`
+}
+
+// LevelOverride is a level of a named logger changed at runtime, until it expires.
+type LevelOverride struct {
+	Level   string    `json:"level"`
+	Expires time.Time `json:"expires"`
+}
+
+type levelOverride struct {
+	LevelOverride
+	option level.Option
+	timer  *time.Timer
+}
+
+// SetLevels changes the levels of named loggers at runtime in all log modes. The filters are composed
+// with the logger name and the level, for example tsdb.loki=debug or tsdb.loki:debug. The configured
+// levels are restored once the duration is elapsed.
+func SetLevels(filters []string, duration time.Duration) error {
+	return root.setLevels(filters, duration)
+}
+
+// GetLevels returns the levels of the registered loggers.
+func GetLevels() []LoggerLevel {
+	return root.getLevels()
+}
+
+func (lm *logManager) setLevels(filters []string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("%w: the levels must be restored after a positive duration", ErrInvalidLevelFilter)
+	}
+
+	levels := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		name, levelName, ok := strings.Cut(strings.TrimSpace(filter), "=")
+		if !ok {
+			name, levelName, ok = strings.Cut(strings.TrimSpace(filter), ":")
+		}
+		levelName = strings.ToLower(strings.TrimSpace(levelName))
+		if _, known := logLevels[levelName]; !ok || !known || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: %q, expected a logger name and one of the levels trace, debug, info, warn, error or critical", ErrInvalidLevelFilter, filter)
+		}
+		levels[strings.TrimSpace(name)] = levelName
+	}
+
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	expires := now().Add(duration)
+	for name, levelName := range levels {
+		if previous, ok := lm.overrides[name]; ok {
+			previous.timer.Stop()
+		}
+
+		override := &levelOverride{
+			LevelOverride: LevelOverride{Level: levelName, Expires: expires},
+			option:        logLevels[levelName],
+		}
+		name := name
+		override.timer = time.AfterFunc(duration, func() {
+			lm.restoreLevel(name, override)
+		})
+		lm.overrides[name] = override
+		lm.swapNamedLogger(name)
+	}
+	return nil
+}
+
+// restoreLevel restores the configured level of a named logger, unless its level was changed again since.
+func (lm *logManager) restoreLevel(name string, override *levelOverride) {
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	if lm.overrides[name] != override {
+		return
+	}
+	delete(lm.overrides, name)
+	lm.swapNamedLogger(name)
+}
+
+func (lm *logManager) getLevels() []LoggerLevel {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	names := make(map[string]struct{}, len(lm.loggersByName)+len(lm.overrides))
+	for name := range lm.loggersByName {
+		names[name] = struct{}{}
+	}
+	for name := range lm.overrides {
+		names[name] = struct{}{}
+	}
+
+	levels := make([]LoggerLevel, 0, len(names))
+	for name := range names {
+		loggerLevel := LoggerLevel{Name: name, Levels: make(map[string]string, len(lm.logFilters))}
+		override, overridden := lm.overrides[name]
+		if overridden {
+			loggerLevel.Override = &LevelOverride{Level: override.Level, Expires: override.Expires}
+		}
+
+		for _, logger := range lm.logFilters {
+			switch {
+			case overridden:
+				loggerLevel.Levels[logger.mode] = override.Level
+			case logger.filterNames[name] != "":
+				loggerLevel.Levels[logger.mode] = logger.filterNames[name]
+			default:
+				loggerLevel.Levels[logger.mode] = logger.levelName
+			}
+		}
+		levels = append(levels, loggerLevel)
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Name < levels[j].Name
+	})
+	return levels
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevels(t *testing.T) {
+	newScenario := func(t *testing.T) *[][]any {
+		t.Helper()
+		newLoggerScenario(t)
+		logged := [][]any{}
+		l := gokitlog.LoggerFunc(func(i ...any) error {
+			logged = append(logged, i)
+			return nil
+		})
+		root.initialize([]logWithFilters{{
+			val:         l,
+			maxLevel:    level.AllowInfo(),
+			filters:     map[string]level.Option{"filtered": level.AllowDebug()},
+			mode:        "console",
+			levelName:   "info",
+			filterNames: map[string]string{"filtered": "debug"},
+		}})
+		return &logged
+	}
+
+	t.Run("changes the level of existing and future loggers until the duration is elapsed", func(t *testing.T) {
+		logged := newScenario(t)
+		existing := New("existing")
+		existing.Debug("before")
+		require.Len(t, *logged, 0)
+
+		require.NoError(t, SetLevels([]string{"existing=debug", "future:debug"}, 50*time.Millisecond))
+		existing.Debug("during")
+		New("future").Debug("during")
+		New("other").Debug("during")
+		require.Len(t, *logged, 2)
+
+		require.Eventually(t, func() bool {
+			return len(GetLevels()) == 3 && GetLevels()[0].Override == nil
+		}, time.Second, 10*time.Millisecond)
+		existing.Debug("after")
+		New("future").Debug("after")
+		require.Len(t, *logged, 2)
+	})
+
+	t.Run("lists the levels of the loggers", func(t *testing.T) {
+		newScenario(t)
+		New("filtered")
+		New("other")
+		require.NoError(t, SetLevels([]string{"other=error"}, time.Minute))
+
+		levels := GetLevels()
+		require.Len(t, levels, 2)
+		require.Equal(t, LoggerLevel{Name: "filtered", Levels: map[string]string{"console": "debug"}}, levels[0])
+		require.Equal(t, "other", levels[1].Name)
+		require.Equal(t, map[string]string{"console": "error"}, levels[1].Levels)
+		require.Equal(t, "error", levels[1].Override.Level)
+		require.Equal(t, now().Add(time.Minute), levels[1].Override.Expires)
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		newScenario(t)
+		for _, filters := range [][]string{{"tsdb.loki"}, {"tsdb.loki=verbose"}, {"=debug"}} {
+			require.ErrorIs(t, SetLevels(filters, time.Minute), ErrInvalidLevelFilter)
+		}
+		require.ErrorIs(t, SetLevels([]string{"tsdb.loki=debug"}, 0), ErrInvalidLevelFilter)
+		require.Empty(t, GetLevels())
+	})
+}
@@ -66,16 +66,31 @@ type logManager struct {
 	*ConcreteLogger
 	loggersByName map[string]*ConcreteLogger
 	logFilters    []logWithFilters
-	mutex         sync.RWMutex
+	// overrides are the levels of named loggers changed at runtime.
+	overrides map[string]*levelOverride
+	// samplingRules sample the messages of named loggers.
+	samplingRules    map[string]samplingRule
+	samplingInterval time.Duration
+	mutex            sync.RWMutex
 }
 
 func newManager(logger gokitlog.Logger) *logManager {
 	return &logManager{
 		ConcreteLogger: newConcreteLogger(logger),
 		loggersByName:  map[string]*ConcreteLogger{},
+		overrides:      map[string]*levelOverride{},
 	}
 }
 
+// setSampling sets the sampling rules applied to named loggers when they are initialized.
+func (lm *logManager) setSampling(rules map[string]samplingRule, interval time.Duration) {
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	lm.samplingRules = rules
+	lm.samplingInterval = interval
+}
+
 func (lm *logManager) initialize(loggers []logWithFilters) {
 	lm.mutex.Lock()
 	defer lm.mutex.Unlock()
@@ -95,19 +110,42 @@ func (lm *logManager) initialize(loggers []logWithFilters) {
 	sort.Strings(loggersByName)
 
 	for _, name := range loggersByName {
-		ctxLoggers := make([]gokitlog.Logger, len(loggers))
-
-		for index, logger := range loggers {
-			ctxLogger := gokitlog.With(logger.val, lm.loggersByName[name].ctx...)
-			if filterLevel, exists := logger.filters[name]; !exists {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, logger.maxLevel)
-			} else {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, filterLevel)
-			}
-		}
+		lm.swapNamedLogger(name)
+	}
+}
+
+// swapNamedLogger applies the current level of a named logger to all log modes.
+// The caller must hold the lock of the manager.
+func (lm *logManager) swapNamedLogger(name string) {
+	namedLogger, exists := lm.loggersByName[name]
+	if !exists || len(lm.logFilters) == 0 {
+		return
+	}
+
+	ctxLoggers := make([]gokitlog.Logger, len(lm.logFilters))
+	for index, logger := range lm.logFilters {
+		ctxLogger := gokitlog.With(logger.val, namedLogger.ctx...)
+		ctxLoggers[index] = level.NewFilter(ctxLogger, lm.levelFor(logger, name))
+	}
+
+	var logger gokitlog.Logger = &compositeLogger{loggers: ctxLoggers}
+	if rule, ok := lm.samplingRules[name]; ok {
+		logger = newSamplingLogger(logger, rule, lm.samplingInterval)
+	}
+	namedLogger.Swap(logger)
+}
 
-		lm.loggersByName[name].Swap(&compositeLogger{loggers: ctxLoggers})
+// levelFor returns the level of a named logger in a log mode: the level changed at runtime, or else
+// the level of the filters of the mode, or else the level of the mode.
+// The caller must hold the lock of the manager.
+func (lm *logManager) levelFor(logger logWithFilters, name string) level.Option {
+	if override, ok := lm.overrides[name]; ok {
+		return override.option
+	}
+	if filterLevel, ok := logger.filters[name]; ok {
+		return filterLevel
 	}
+	return logger.maxLevel
 }
 
 func (lm *logManager) New(ctx ...any) *ConcreteLogger {
@@ -133,20 +171,9 @@ func (lm *logManager) New(ctx ...any) *ConcreteLogger {
 		return ctxLogger
 	}
 
-	compositeLogger := newCompositeLogger()
-	for _, logWithFilter := range lm.logFilters {
-		filterLevel, ok := logWithFilter.filters[loggerName]
-		if ok {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, filterLevel)
-		} else {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, logWithFilter.maxLevel)
-		}
-
-		compositeLogger.loggers = append(compositeLogger.loggers, logWithFilter.val)
-	}
-
-	ctxLogger := newConcreteLogger(compositeLogger, ctx...)
+	ctxLogger := &ConcreteLogger{ctx: ctx}
 	lm.loggersByName[loggerName] = ctxLogger
+	lm.swapNamedLogger(loggerName)
 	return ctxLogger
 }
 
@@ -320,6 +347,15 @@ func getLogLevelFromString(levelName string) level.Option {
 // the filter is composed with logger name and level
 func getFilters(filterStrArray []string) map[string]level.Option {
 	filterMap := make(map[string]level.Option)
+	for name, levelName := range getFilterLevelNames(filterStrArray) {
+		filterMap[name] = getLogLevelFromString(levelName)
+	}
+	return filterMap
+}
+
+// getFilterLevelNames returns the names of the levels of the filters by logger name
+func getFilterLevelNames(filterStrArray []string) map[string]string {
+	filterMap := make(map[string]string)
 
 	for i := 0; i < len(filterStrArray); i++ {
 		filterStr := strings.TrimSpace(filterStrArray[i])
@@ -333,7 +369,7 @@ func getFilters(filterStrArray []string) map[string]level.Option {
 
 		parts := strings.Split(filterStr, ":")
 		if len(parts) > 1 {
-			filterMap[parts[0]] = getLogLevelFromString(parts[1])
+			filterMap[parts[0]] = parts[1]
 		}
 	}
 
@@ -415,6 +451,11 @@ type logWithFilters struct {
 	val      gokitlog.Logger
 	filters  map[string]level.Option
 	maxLevel level.Option
+
+	// names of the mode and of its levels, to list the levels of the loggers
+	mode        string
+	levelName   string
+	filterNames map[string]string
 }
 
 func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
@@ -428,7 +469,15 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 	}
 
 	defaultLevelName, _ := getLogLevelFromConfig("log", "info", cfg)
-	defaultFilters := getFilters(util.SplitString(cfg.Section("log").Key("filters").String()))
+	defaultFilters := getFilterLevelNames(util.SplitString(cfg.Section("log").Key("filters").String()))
+	samplingRules, err := getSamplingRules(util.SplitString(cfg.Section("log").Key("sampling").String()))
+	if err != nil {
+		return err
+	}
+	samplingInterval := cfg.Section("log").Key("sampling_interval").MustDuration(defaultSamplingInterval)
+	if samplingInterval <= 0 {
+		return fmt.Errorf("invalid log sampling interval %s", samplingInterval)
+	}
 
 	var configLoggers []logWithFilters
 	for _, mode := range modes {
@@ -440,8 +489,8 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 		}
 
 		// Log level.
-		_, leveloption := getLogLevelFromConfig("log."+mode, defaultLevelName, cfg)
-		modeFilters := getFilters(util.SplitString(sec.Key("filters").String()))
+		levelName, leveloption := getLogLevelFromConfig("log."+mode, defaultLevelName, cfg)
+		modeFilters := getFilterLevelNames(util.SplitString(sec.Key("filters").String()))
 
 		format := getLogFormat(sec.Key("format").MustString(""))
 
@@ -489,11 +538,18 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 			}
 		}
 
-		handler.filters = modeFilters
+		handler.filters = make(map[string]level.Option, len(modeFilters))
+		for name, filterLevelName := range modeFilters {
+			handler.filters[name] = getLogLevelFromString(filterLevelName)
+		}
 		handler.maxLevel = leveloption
+		handler.mode = mode
+		handler.levelName = levelName
+		handler.filterNames = modeFilters
 		configLoggers = append(configLoggers, handler)
 	}
 	if len(configLoggers) > 0 {
+		root.setSampling(samplingRules, samplingInterval)
 		root.initialize(configLoggers)
 	}
 
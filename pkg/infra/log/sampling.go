@@ -0,0 +1,167 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const defaultSamplingInterval = time.Second
+
+// samplingRule samples the messages of a logger: the first messages of each key are logged during an
+// interval, and then one in every thereafter of them.
+type samplingRule struct {
+	first      int
+	thereafter int
+}
+
+// getSamplingRules parses sampling rules composed with the logger name, the number of messages logged
+// per interval and the sampling rate of the next ones, for example tsdb.loki:10:100.
+// A sampling rate of 0 suppresses all the next messages.
+func getSamplingRules(ruleStrArray []string) (map[string]samplingRule, error) {
+	rules := make(map[string]samplingRule)
+	for _, ruleStr := range ruleStrArray {
+		ruleStr = strings.TrimSpace(ruleStr)
+		if ruleStr == "" {
+			continue
+		}
+
+		parts := strings.Split(ruleStr, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid log sampling rule %q, expected <logger>:<first>:<thereafter>", ruleStr)
+		}
+		first, err := strconv.Atoi(parts[1])
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid number of messages logged per interval in log sampling rule %q", ruleStr)
+		}
+		thereafter, err := strconv.Atoi(parts[2])
+		if err != nil || thereafter < 0 {
+			return nil, fmt.Errorf("invalid sampling rate in log sampling rule %q", ruleStr)
+		}
+		rules[parts[0]] = samplingRule{first: first, thereafter: thereafter}
+	}
+	return rules, nil
+}
+
+// samplingLogger samples the messages of a logger by level and message, and logs the number of messages
+// it suppressed when each interval is over.
+type samplingLogger struct {
+	logger   gokitlog.Logger
+	rule     samplingRule
+	interval time.Duration
+
+	mu      sync.Mutex
+	end     time.Time
+	counts  map[samplingKey]*samplingCount
+	flusher *time.Timer
+}
+
+type samplingKey struct {
+	level any
+	msg   string
+}
+
+type samplingCount struct {
+	seen       int
+	suppressed int
+}
+
+func newSamplingLogger(logger gokitlog.Logger, rule samplingRule, interval time.Duration) *samplingLogger {
+	return &samplingLogger{
+		logger:   logger,
+		rule:     rule,
+		interval: interval,
+		counts:   make(map[samplingKey]*samplingCount),
+	}
+}
+
+func (s *samplingLogger) Log(keyvals ...any) error {
+	key := getSamplingKey(keyvals)
+
+	s.mu.Lock()
+	summaries := s.rollover(false)
+	count, ok := s.counts[key]
+	if !ok {
+		count = &samplingCount{}
+		s.counts[key] = count
+	}
+	count.seen++
+	sampled := count.seen <= s.rule.first || (s.rule.thereafter > 0 && (count.seen-s.rule.first)%s.rule.thereafter == 0)
+	if !sampled {
+		count.suppressed++
+		// The summary of the suppressed messages is logged even if no message is logged after the interval.
+		if s.flusher == nil {
+			s.flusher = time.AfterFunc(s.end.Sub(now()), s.flush)
+		}
+	}
+	s.mu.Unlock()
+
+	s.logSummaries(summaries)
+	if !sampled {
+		return nil
+	}
+	return s.logger.Log(keyvals...)
+}
+
+func (s *samplingLogger) flush() {
+	s.mu.Lock()
+	summaries := s.rollover(true)
+	s.mu.Unlock()
+
+	s.logSummaries(summaries)
+}
+
+// rollover starts a new interval once the current one is over, or when forced by the flusher at the end
+// of the interval, and returns the counts of the messages suppressed during the interval.
+// The caller must hold the lock.
+func (s *samplingLogger) rollover(force bool) map[samplingKey]int {
+	t := now()
+	if !force && t.Before(s.end) {
+		return nil
+	}
+	if s.flusher != nil {
+		s.flusher.Stop()
+		s.flusher = nil
+	}
+
+	var summaries map[samplingKey]int
+	for key, count := range s.counts {
+		if count.suppressed > 0 {
+			if summaries == nil {
+				summaries = make(map[samplingKey]int)
+			}
+			summaries[key] = count.suppressed
+		}
+	}
+	s.counts = make(map[samplingKey]*samplingCount)
+	s.end = t.Add(s.interval)
+	return summaries
+}
+
+func (s *samplingLogger) logSummaries(summaries map[samplingKey]int) {
+	for key, suppressed := range summaries {
+		keyvals := []any{"msg", "Log messages suppressed by sampling", "sampledMsg", key.msg, "suppressed", suppressed, "interval", s.interval}
+		if key.level != nil {
+			keyvals = append([]any{level.Key(), key.level}, keyvals...)
+		}
+		_ = s.logger.Log(keyvals...)
+	}
+}
+
+func getSamplingKey(keyvals []any) samplingKey {
+	var key samplingKey
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case level.Key():
+			key.level = keyvals[i+1]
+		case "msg":
+			key.msg = fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return key
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSamplingRules(t *testing.T) {
+	rules, err := getSamplingRules([]string{"tsdb.loki:10:100", " plugins.backend:0:0 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]samplingRule{
+		"tsdb.loki":       {first: 10, thereafter: 100},
+		"plugins.backend": {first: 0, thereafter: 0},
+	}, rules)
+
+	for _, rule := range []string{"tsdb.loki", "tsdb.loki:10", ":10:100", "tsdb.loki:ten:100", "tsdb.loki:10:-1"} {
+		_, err := getSamplingRules([]string{rule})
+		require.Error(t, err, rule)
+	}
+}
+
+func TestSamplingLogger(t *testing.T) {
+	var mu sync.Mutex
+	logged := [][]any{}
+	logger := gokitlog.LoggerFunc(func(keyvals ...any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, keyvals)
+		return nil
+	})
+	loggedLines := func() [][]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([][]any{}, logged...)
+	}
+
+	t.Run("samples messages by level and message and summarizes the suppressed ones", func(t *testing.T) {
+		logged = [][]any{}
+		current := time.Now()
+		origNow := now
+		now = func() time.Time { return current }
+		t.Cleanup(func() { now = origNow })
+
+		s := newSamplingLogger(logger, samplingRule{first: 2, thereafter: 3}, time.Hour)
+		for i := 0; i < 8; i++ {
+			require.NoError(t, s.Log(level.Key(), level.ErrorValue(), "msg", "Query failed"))
+		}
+		require.NoError(t, s.Log(level.Key(), level.WarnValue(), "msg", "Query failed"))
+		// The first 2 errors, then 1 in 3 of the next ones, and the warning.
+		require.Len(t, loggedLines(), 5)
+
+		current = current.Add(time.Hour)
+		require.NoError(t, s.Log(level.Key(), level.ErrorValue(), "msg", "Query failed"))
+		lines := loggedLines()
+		require.Len(t, lines, 7)
+		require.Equal(t, []any{
+			level.Key(), level.ErrorValue(),
+			"msg", "Log messages suppressed by sampling",
+			"sampledMsg", "Query failed",
+			"suppressed", 4,
+			"interval", time.Hour,
+		}, lines[5])
+		require.Equal(t, []any{level.Key(), level.ErrorValue(), "msg", "Query failed"}, lines[6])
+	})
+
+	t.Run("summarizes the suppressed messages at the end of the interval", func(t *testing.T) {
+		logged = [][]any{}
+		s := newSamplingLogger(logger, samplingRule{first: 1, thereafter: 0}, 20*time.Millisecond)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, s.Log("msg", "Query failed"))
+		}
+		require.Len(t, loggedLines(), 1)
+
+		require.Eventually(t, func() bool {
+			return len(loggedLines()) == 2
+		}, time.Second, 5*time.Millisecond)
+		require.Equal(t, []any{
+			"msg", "Log messages suppressed by sampling",
+			"sampledMsg", "Query failed",
+			"suppressed", 2,
+			"interval", 20 * time.Millisecond,
+		}, loggedLines()[1])
+	})
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// ExemplarTraceIDLabel is the label of the exemplars that holds the ID of the trace of an observation.
+const ExemplarTraceIDLabel = "traceID"
+
+// ExemplarFromContext returns the exemplar labels linking an observation to the trace of the context.
+// It returns nil when tracing is disabled or the trace is not sampled, since the trace cannot be found then.
+func ExemplarFromContext(ctx context.Context) prometheus.Labels {
+	traceID := tracing.TraceIDFromContext(ctx, true)
+	if traceID == "" {
+		return nil
+	}
+	return prometheus.Labels{ExemplarTraceIDLabel: traceID}
+}
+
+// ObserveWithExemplar observes the value with an exemplar linking it to the trace of the context, so that
+// the trace of an outlier can be found from the histogram. The value is observed without exemplar when
+// there is no sampled trace or the observer doesn't support exemplars.
+func ObserveWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	if exemplar := ExemplarFromContext(ctx); exemplar != nil {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(value, exemplar)
+			return
+		}
+	}
+	observer.Observe(value)
+}
+
+// AddWithExemplar adds the value to the counter with an exemplar linking it to the trace of the context.
+// The value is added without exemplar when there is no sampled trace or the counter doesn't support exemplars.
+func AddWithExemplar(ctx context.Context, counter prometheus.Counter, value float64) {
+	if exemplar := ExemplarFromContext(ctx); exemplar != nil {
+		if exemplarAdder, ok := counter.(prometheus.ExemplarAdder); ok {
+			exemplarAdder.AddWithExemplar(value, exemplar)
+			return
+		}
+	}
+	counter.Add(value)
+}
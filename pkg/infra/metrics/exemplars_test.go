@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestObserveWithExemplar(t *testing.T) {
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanCtx := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{0x01},
+			TraceFlags: flags,
+		}))
+	}
+	exemplar := func(t *testing.T, h prometheus.Histogram) *dto.Exemplar {
+		t.Helper()
+		m := &dto.Metric{}
+		require.NoError(t, h.Write(m))
+		for _, b := range m.GetHistogram().GetBucket() {
+			if b.GetExemplar() != nil {
+				return b.GetExemplar()
+			}
+		}
+		return nil
+	}
+
+	t.Run("adds the trace of a sampled request as exemplar", func(t *testing.T) {
+		h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds"})
+		ObserveWithExemplar(spanCtx(trace.FlagsSampled), h, 0.5)
+
+		e := exemplar(t, h)
+		require.NotNil(t, e)
+		require.Equal(t, 0.5, e.GetValue())
+		require.Len(t, e.GetLabel(), 1)
+		require.Equal(t, ExemplarTraceIDLabel, e.GetLabel()[0].GetName())
+		require.Equal(t, traceID.String(), e.GetLabel()[0].GetValue())
+	})
+
+	t.Run("doesn't add an exemplar without sampled trace", func(t *testing.T) {
+		for _, ctx := range []context.Context{context.Background(), spanCtx(0)} {
+			h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds"})
+			ObserveWithExemplar(ctx, h, 0.5)
+
+			require.Nil(t, exemplar(t, h))
+			m := &dto.Metric{}
+			require.NoError(t, h.Write(m))
+			require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+		}
+	})
+
+	t.Run("adds the trace of a sampled request as exemplar of a counter", func(t *testing.T) {
+		c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total"})
+		AddWithExemplar(spanCtx(trace.FlagsSampled), c, 1)
+
+		m := &dto.Metric{}
+		require.NoError(t, c.Write(m))
+		require.Equal(t, 1.0, m.GetCounter().GetValue())
+		require.Equal(t, traceID.String(), m.GetCounter().GetExemplar().GetLabel()[0].GetValue())
+	})
+}
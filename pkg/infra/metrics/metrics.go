@@ -101,6 +101,18 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingBudgetQueue is a metric gauge for the number of renders waiting for the render budget, by render class
+	MRenderingBudgetQueue *prometheus.GaugeVec
+
+	// MRenderingBudgetInFlight is a metric gauge for the number of renders using the render budget, by render class
+	MRenderingBudgetInFlight *prometheus.GaugeVec
+
+	// MRenderingBudgetRejectedTotal is a metric counter for renders rejected by the render budget, by render class and reason
+	MRenderingBudgetRejectedTotal *prometheus.CounterVec
+
+	// MRenderingBudgetWaitSeconds is a metric histogram for the time renders wait for the render budget, by render class
+	MRenderingBudgetWaitSeconds *prometheus.HistogramVec
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
 
@@ -109,6 +121,15 @@ var (
 
 	// MPublicDashboardDatasourceQuerySuccess is a metric counter for successful queries labelled by datasource
 	MPublicDashboardDatasourceQuerySuccess *prometheus.CounterVec
+
+	// MCSRFRejectedRequests is a metric counter for requests rejected by the CSRF check labelled by reason
+	MCSRFRejectedRequests *prometheus.CounterVec
+
+	// MRateLimitedRequests is a metric counter for requests rejected by rate limiting labelled by rule
+	MRateLimitedRequests *prometheus.CounterVec
+
+	// MLoadSheddingShedRequests is a metric counter for requests shed under saturation labelled by class
+	MLoadSheddingShedRequests *prometheus.CounterVec
 )
 
 // Timers
@@ -417,6 +438,31 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingBudgetQueue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rendering_budget_queue_size",
+		Help:      "number of renders waiting for the render budget",
+		Namespace: ExporterName,
+	}, []string{"class"})
+
+	MRenderingBudgetInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rendering_budget_in_flight",
+		Help:      "number of renders using the render budget",
+		Namespace: ExporterName,
+	}, []string{"class"})
+
+	MRenderingBudgetRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "rendering_budget_rejected_total",
+		Help:      "counter for renders rejected by the render budget",
+		Namespace: ExporterName,
+	}, []string{"class", "reason"})
+
+	MRenderingBudgetWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "rendering_budget_wait_duration_seconds",
+		Help:      "histogram of the time renders wait for the render budget",
+		Namespace: ExporterName,
+		Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"class"})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -449,6 +495,24 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"datasource", "status"}, map[string][]string{"status": pubdash.QueryResultStatuses})
 
+	MCSRFRejectedRequests = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
+		Name:      "csrf_rejected_requests_total",
+		Help:      "counter for requests rejected by the CSRF check labelled by reason",
+		Namespace: ExporterName,
+	}, []string{"reason"}, map[string][]string{"reason": {"invalid_host", "invalid_origin", "origin_not_allowed"}})
+
+	MRateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "rate_limited_requests_total",
+		Help:      "counter for requests rejected by rate limiting labelled by rule",
+		Namespace: ExporterName,
+	}, []string{"rule"})
+
+	MLoadSheddingShedRequests = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
+		Name:      "load_shedding_shed_requests_total",
+		Help:      "counter for requests shed under saturation labelled by class",
+		Namespace: ExporterName,
+	}, []string{"class"}, map[string][]string{"class": {"write", "read", "search"}})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -696,6 +760,10 @@ func initMetricVars(reg prometheus.Registerer) {
 		MRenderingSummary,
 		MRenderingUserLookupSummary,
 		MRenderingQueue,
+		MRenderingBudgetQueue,
+		MRenderingBudgetInFlight,
+		MRenderingBudgetRejectedTotal,
+		MRenderingBudgetWaitSeconds,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
@@ -726,5 +794,8 @@ func initMetricVars(reg prometheus.Registerer) {
 		MPublicDashboardRequestCount,
 		MPublicDashboardDatasourceQuerySuccess,
 		MStatTotalCorrelations,
+		MCSRFRejectedRequests,
+		MRateLimitedRequests,
+		MLoadSheddingShedRequests,
 	)
 }
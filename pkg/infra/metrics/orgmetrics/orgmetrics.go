@@ -0,0 +1,225 @@
+// Package orgmetrics exports usage metrics labeled with the organization, such as the number of
+// active users, queries and firing alerts of each organization, for operators to charge back the
+// usage of Grafana to its tenants. The organizations metrics are labeled with are limited by an
+// allow list or a budget, so that the cardinality of the metrics is bounded.
+package orgmetrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// OtherOrgs is the label of the metrics of the organizations out of the allow list or the budget.
+	OtherOrgs = "other"
+
+	defaultMaxOrgs           = 100
+	defaultCollectorInterval = 5 * time.Minute
+	activeUserTimeLimit      = 30 * 24 * time.Hour
+)
+
+// Service collects the usage metrics of the organizations and exports them to Prometheus.
+type Service struct {
+	log      log.Logger
+	store    db.DB
+	enabled  bool
+	interval time.Duration
+	// allowList are the organizations metrics are labeled with, if not empty.
+	allowList map[int64]bool
+	maxOrgs   int
+
+	activeUsersDesc  *prometheus.Desc
+	alertsFiringDesc *prometheus.Desc
+	queriesDesc      *prometheus.Desc
+
+	mu sync.Mutex
+	// labeled are the organizations metrics are labeled with within the budget.
+	labeled      map[int64]bool
+	queries      map[string]float64
+	activeUsers  map[string]float64
+	alertsFiring map[string]float64
+}
+
+func ProvideService(cfg *setting.Cfg, store db.DB, registerer prometheus.Registerer) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("metrics.org_usage")
+	s := &Service{
+		log:          log.New("metrics.orgusage"),
+		store:        store,
+		enabled:      cfg.MetricsEndpointEnabled && section.Key("enabled").MustBool(false),
+		interval:     section.Key("collector_interval").MustDuration(defaultCollectorInterval),
+		allowList:    make(map[int64]bool),
+		maxOrgs:      section.Key("max_orgs").MustInt(defaultMaxOrgs),
+		labeled:      make(map[int64]bool),
+		queries:      make(map[string]float64),
+		activeUsers:  make(map[string]float64),
+		alertsFiring: make(map[string]float64),
+		activeUsersDesc: prometheus.NewDesc(
+			"grafana_org_active_users",
+			"Number of users active in the last 30 days, by organization.",
+			[]string{"org_id"}, nil,
+		),
+		alertsFiringDesc: prometheus.NewDesc(
+			"grafana_org_alerts_firing",
+			"Number of firing alert instances, by organization.",
+			[]string{"org_id"}, nil,
+		),
+		queriesDesc: prometheus.NewDesc(
+			"grafana_org_queries_total",
+			"Number of data source queries run through the query API, by organization.",
+			[]string{"org_id"}, nil,
+		),
+	}
+
+	for _, id := range util.SplitString(section.Key("org_allowlist").String()) {
+		orgID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid organization ID %q in [metrics.org_usage] org_allowlist: %w", id, err)
+		}
+		s.allowList[orgID] = true
+	}
+	if s.interval <= 0 {
+		return nil, fmt.Errorf("invalid [metrics.org_usage] collector_interval %s", s.interval)
+	}
+
+	if s.enabled {
+		if err := registerer.Register(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// IsDisabled returns true if the organization usage metrics are not exported.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled
+}
+
+// Run collects the gauges of the organizations until the context is canceled.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.collectGauges(ctx); err != nil {
+			s.log.Error("Failed to collect organization usage metrics", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// IncQueries counts the queries run by an organization.
+func (s *Service) IncQueries(orgID int64, queries int) {
+	if s == nil || !s.enabled {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[s.orgLabel(orgID)] += float64(queries)
+}
+
+// orgLabel returns the label of the metrics of an organization. Organizations are labeled in the order
+// they are seen until the budget is spent, the metrics of the next ones are aggregated.
+// The caller must hold the lock.
+func (s *Service) orgLabel(orgID int64) string {
+	if len(s.allowList) > 0 {
+		if s.allowList[orgID] {
+			return strconv.FormatInt(orgID, 10)
+		}
+		return OtherOrgs
+	}
+
+	if !s.labeled[orgID] {
+		if len(s.labeled) >= s.maxOrgs {
+			return OtherOrgs
+		}
+		s.labeled[orgID] = true
+	}
+	return strconv.FormatInt(orgID, 10)
+}
+
+type orgCount struct {
+	OrgID int64 `xorm:"org_id"`
+	Count int64 `xorm:"count"`
+}
+
+func (s *Service) collectGauges(ctx context.Context) error {
+	var activeUsers, alertsFiring []orgCount
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		activeUsersSQL := `SELECT org_user.org_id AS org_id, COUNT(DISTINCT u.id) AS count FROM ` + dialect.Quote("user") + ` AS u
+			INNER JOIN org_user ON org_user.user_id = u.id
+			WHERE u.is_service_account = ? AND u.last_seen_at > ?
+			GROUP BY org_user.org_id`
+		if err := sess.SQL(activeUsersSQL, dialect.BooleanStr(false), time.Now().Add(-activeUserTimeLimit)).Find(&activeUsers); err != nil {
+			return err
+		}
+
+		alertsFiringSQL := `SELECT rule_org_id AS org_id, COUNT(*) AS count FROM alert_instance WHERE current_state = ? GROUP BY rule_org_id`
+		return sess.SQL(alertsFiringSQL, string(ngmodels.InstanceStateFiring)).Find(&alertsFiring)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeUsers = s.byLabel(activeUsers)
+	s.alertsFiring = s.byLabel(alertsFiring)
+	return nil
+}
+
+// byLabel aggregates the counts of the organizations by label, the organizations with the lowest
+// IDs being labeled first. The caller must hold the lock.
+func (s *Service) byLabel(counts []orgCount) map[string]float64 {
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].OrgID < counts[j].OrgID
+	})
+
+	values := make(map[string]float64, len(counts))
+	for _, c := range counts {
+		values[s.orgLabel(c.OrgID)] += float64(c.Count)
+	}
+	return values
+}
+
+// Describe implements prometheus.Collector.
+func (s *Service) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.activeUsersDesc
+	ch <- s.alertsFiringDesc
+	ch <- s.queriesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (s *Service) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for org, value := range s.activeUsers {
+		ch <- prometheus.MustNewConstMetric(s.activeUsersDesc, prometheus.GaugeValue, value, org)
+	}
+	for org, value := range s.alertsFiring {
+		ch <- prometheus.MustNewConstMetric(s.alertsFiringDesc, prometheus.GaugeValue, value, org)
+	}
+	for org, value := range s.queries {
+		ch <- prometheus.MustNewConstMetric(s.queriesDesc, prometheus.CounterValue, value, org)
+	}
+}
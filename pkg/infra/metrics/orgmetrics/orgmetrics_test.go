@@ -0,0 +1,148 @@
+package orgmetrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T, store db.DB, settings map[string]string) (*Service, *prometheus.Registry) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.MetricsEndpointEnabled = true
+	section := cfg.Raw.Section("metrics.org_usage")
+	_, err := section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	for key, value := range settings {
+		_, err := section.NewKey(key, value)
+		require.NoError(t, err)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	s, err := ProvideService(cfg, store, registry)
+	require.NoError(t, err)
+	return s, registry
+}
+
+func TestOrgLabels(t *testing.T) {
+	t.Run("labels the organizations seen first within the budget", func(t *testing.T) {
+		s, registry := newTestService(t, nil, map[string]string{"max_orgs": "2"})
+		s.IncQueries(3, 1)
+		s.IncQueries(1, 2)
+		s.IncQueries(2, 4)
+		s.IncQueries(3, 1)
+		s.IncQueries(4, 8)
+
+		require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP grafana_org_queries_total Number of data source queries run through the query API, by organization.
+# TYPE grafana_org_queries_total counter
+grafana_org_queries_total{org_id="1"} 2
+grafana_org_queries_total{org_id="3"} 2
+grafana_org_queries_total{org_id="other"} 12
+`), "grafana_org_queries_total"))
+	})
+
+	t.Run("labels the organizations of the allow list only", func(t *testing.T) {
+		s, registry := newTestService(t, nil, map[string]string{"org_allowlist": "2, 3", "max_orgs": "1"})
+		s.IncQueries(1, 1)
+		s.IncQueries(2, 2)
+		s.IncQueries(3, 4)
+
+		require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP grafana_org_queries_total Number of data source queries run through the query API, by organization.
+# TYPE grafana_org_queries_total counter
+grafana_org_queries_total{org_id="2"} 2
+grafana_org_queries_total{org_id="3"} 4
+grafana_org_queries_total{org_id="other"} 1
+`), "grafana_org_queries_total"))
+	})
+
+	t.Run("is not registered when disabled", func(t *testing.T) {
+		registry := prometheus.NewPedanticRegistry()
+		s, err := ProvideService(setting.NewCfg(), nil, registry)
+		require.NoError(t, err)
+		require.True(t, s.IsDisabled())
+		s.IncQueries(1, 1)
+
+		metrics, err := registry.Gather()
+		require.NoError(t, err)
+		require.Empty(t, metrics)
+	})
+
+	t.Run("rejects an invalid allow list", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		_, err := cfg.Raw.Section("metrics.org_usage").NewKey("org_allowlist", "1,main")
+		require.NoError(t, err)
+		_, err = ProvideService(cfg, nil, prometheus.NewRegistry())
+		require.Error(t, err)
+	})
+}
+
+func TestIntegrationCollectGauges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := sqlstore.InitTestDB(t)
+	s, registry := newTestService(t, store, map[string]string{"max_orgs": "1"})
+
+	now := time.Now()
+	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		users := []struct {
+			id             int64
+			serviceAccount bool
+			lastSeen       time.Time
+		}{
+			{id: 1, lastSeen: now},
+			{id: 2, lastSeen: now.Add(-time.Hour)},
+			{id: 3, lastSeen: now.Add(-60 * 24 * time.Hour)},
+			{id: 4, serviceAccount: true, lastSeen: now},
+		}
+		for _, u := range users {
+			userSQL := "INSERT INTO " + store.GetDialect().Quote("user") + ` (id, version, login, email, org_id, is_admin, is_service_account, created, updated, last_seen_at)
+				VALUES (?, 0, ?, ?, 1, ?, ?, ?, ?, ?)`
+			login := fmt.Sprintf("user%d", u.id)
+			if _, err := sess.Exec(userSQL, u.id, login, login+"@example.org", store.GetDialect().BooleanStr(false),
+				store.GetDialect().BooleanStr(u.serviceAccount), now, now, u.lastSeen); err != nil {
+				return err
+			}
+		}
+		for _, m := range []struct{ orgID, userID int64 }{{1, 1}, {1, 2}, {1, 3}, {1, 4}, {2, 1}, {3, 2}} {
+			if _, err := sess.Exec("INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, 'Viewer', ?, ?)", m.orgID, m.userID, now, now); err != nil {
+				return err
+			}
+		}
+		for i, instance := range []struct {
+			orgID int64
+			state string
+		}{{1, "Alerting"}, {1, "Normal"}, {2, "Alerting"}, {2, "Alerting"}} {
+			if _, err := sess.Exec(`INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time)
+				VALUES (?, 'rule', '{}', ?, ?, '', 0, 0, 0)`, instance.orgID, fmt.Sprint(i), instance.state); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.collectGauges(context.Background()))
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP grafana_org_active_users Number of users active in the last 30 days, by organization.
+# TYPE grafana_org_active_users gauge
+grafana_org_active_users{org_id="1"} 2
+grafana_org_active_users{org_id="other"} 2
+# HELP grafana_org_alerts_firing Number of firing alert instances, by organization.
+# TYPE grafana_org_alerts_firing gauge
+grafana_org_alerts_firing{org_id="1"} 1
+grafana_org_alerts_firing{org_id="other"} 2
+`), "grafana_org_active_users", "grafana_org_alerts_firing"))
+}
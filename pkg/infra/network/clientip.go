@@ -0,0 +1,154 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HeaderForwarded is the standard header of RFC 7239 that reverse proxies set
+// with the addresses of the client and of the proxies a request went through.
+const HeaderForwarded = "Forwarded"
+
+// ClientIPResolver resolves the IP address of the client of a request, from
+// the headers set by the reverse proxies in front of Grafana. The headers are
+// only trusted if the request comes from a trusted proxy.
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+	headers        []string
+}
+
+// NewClientIPResolver returns a resolver that trusts the headers of the
+// requests from the trustedProxies. The first of headers that is set on a
+// request is used, the other ones are ignored.
+func NewClientIPResolver(trustedProxies []*net.IPNet, headers []string) *ClientIPResolver {
+	return &ClientIPResolver{trustedProxies: trustedProxies, headers: headers}
+}
+
+// ClientIP returns the IP address of the client of a request, or nil if it
+// cannot be parsed, for example for the requests on a Unix socket.
+func (r *ClientIPResolver) ClientIP(req *http.Request) net.IP {
+	if ip := r.ForwardedClientIP(req); ip != nil {
+		return ip
+	}
+	ip, err := GetIPFromAddress(req.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	return ip
+}
+
+// ForwardedClientIP returns the IP address of the client of a request set by
+// the trusted proxies it went through, or nil if the request does not come
+// from a trusted proxy or if the header of the proxies is not valid. The
+// requests whose connection has no IP address, such as the requests on a Unix
+// socket, come from a local process and are trusted.
+//
+// The addresses of a header are read from right to left, the proxies
+// appending the address of their client to the ones they received. The client
+// is the first address that is not a trusted proxy, or the leftmost address if
+// all of them are trusted proxies.
+func (r *ClientIPResolver) ForwardedClientIP(req *http.Request) net.IP {
+	if peer, err := GetIPFromAddress(req.RemoteAddr); err == nil && !r.isTrustedProxy(peer) {
+		return nil
+	}
+
+	for _, header := range r.headers {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+
+		var addrs []net.IP
+		if strings.EqualFold(header, HeaderForwarded) {
+			addrs = parseForwarded(values)
+		} else {
+			addrs = parseAddressList(values)
+		}
+		if len(addrs) == 0 {
+			return nil
+		}
+		for i := len(addrs) - 1; i >= 0; i-- {
+			if addrs[i] == nil {
+				// The client cannot be known beyond an invalid or obfuscated address.
+				return nil
+			}
+			if !r.isTrustedProxy(addrs[i]) {
+				return addrs[i]
+			}
+		}
+		return addrs[0]
+	}
+	return nil
+}
+
+func (r *ClientIPResolver) isTrustedProxy(ip net.IP) bool {
+	for _, n := range r.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAddressList parses the comma separated addresses of headers such as
+// X-Forwarded-For. Invalid addresses are nil.
+func parseAddressList(values []string) []net.IP {
+	var addrs []net.IP
+	for _, value := range values {
+		for _, addr := range strings.Split(value, ",") {
+			ip, _ := GetIPFromAddress(strings.TrimSpace(addr))
+			addrs = append(addrs, ip)
+		}
+	}
+	return addrs
+}
+
+// parseForwarded parses the "for" parameters of the Forwarded header, such as
+// `for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"`. The elements
+// without a valid address, such as `for=unknown` or obfuscated identifiers,
+// are nil.
+func parseForwarded(values []string) []net.IP {
+	var addrs []net.IP
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var ip net.IP
+			for _, pair := range strings.Split(element, ";") {
+				key, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				ip, _ = GetIPFromAddress(strings.Trim(v, `"`))
+			}
+			addrs = append(addrs, ip)
+		}
+	}
+	return addrs
+}
+
+// ParseCIDRs parses a list of CIDRs, such as 10.0.0.0/8. IP addresses are
+// parsed as the CIDR of the single address.
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address or CIDR %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address or CIDR %q: %w", value, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	trustedProxies, err := ParseCIDRs([]string{"10.0.0.0/8", "2001:db8::1"})
+	require.NoError(t, err)
+	r := NewClientIPResolver(trustedProxies, []string{"X-Real-IP", "X-Forwarded-For", "Forwarded"})
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		headers    http.Header
+		exp        string
+	}{
+		{
+			desc:       "Uses the address of the connection without headers",
+			remoteAddr: "203.0.113.1:51299",
+			exp:        "203.0.113.1",
+		},
+		{
+			desc:       "Ignores the headers of the requests that are not from a trusted proxy",
+			remoteAddr: "203.0.113.1:51299",
+			headers:    http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "203.0.113.1",
+		},
+		{
+			desc:       "Uses the headers of the requests from a trusted proxy",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "198.51.100.1",
+		},
+		{
+			desc:       "Uses the headers of the requests on a Unix socket",
+			remoteAddr: "@",
+			headers:    http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "198.51.100.1",
+		},
+		{
+			desc:       "Uses the headers of the requests from a trusted IPv6 proxy",
+			remoteAddr: "[2001:db8::1]:51299",
+			headers:    http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "198.51.100.1",
+		},
+		{
+			desc:       "Uses the rightmost address that is not a trusted proxy",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"X-Forwarded-For": {"192.0.2.1, 198.51.100.1", "10.0.0.2"}},
+			exp:        "198.51.100.1",
+		},
+		{
+			desc:       "Uses the leftmost address if all are trusted proxies",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			exp:        "10.0.0.3",
+		},
+		{
+			desc:       "Uses the first header set in order of precedence",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"X-Real-Ip": {"192.0.2.1"}, "X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "192.0.2.1",
+		},
+		{
+			desc:       "Uses the address of the connection if the header is invalid",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"X-Real-Ip": {"not an IP"}, "X-Forwarded-For": {"198.51.100.1"}},
+			exp:        "10.0.0.1",
+		},
+		{
+			desc:       "Parses the Forwarded header",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"Forwarded": {`for=192.0.2.60;proto=http;by=203.0.113.43, For="[2001:db8:cafe::17]:4711"`}},
+			exp:        "2001:db8:cafe::17",
+		},
+		{
+			desc:       "Stops at the unknown addresses of the Forwarded header",
+			remoteAddr: "10.0.0.1:51299",
+			headers:    http.Header{"Forwarded": {"for=192.0.2.60, for=unknown, for=10.0.0.2"}},
+			exp:        "10.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := &http.Request{RemoteAddr: tc.remoteAddr, Header: tc.headers}
+			assert.Equal(t, tc.exp, r.ClientIP(req).String())
+		})
+	}
+
+	t.Run("Returns nil if the address of the connection is not an IP address", func(t *testing.T) {
+		assert.Nil(t, r.ClientIP(&http.Request{RemoteAddr: "@"}))
+	})
+}
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32", "::1"})
+	require.NoError(t, err)
+	require.Len(t, nets, 4)
+	assert.Equal(t, "10.0.0.0/8", nets[0].String())
+	assert.Equal(t, "192.0.2.1/32", nets[1].String())
+	assert.Equal(t, "2001:db8::/32", nets[2].String())
+	assert.Equal(t, "::1/128", nets[3].String())
+
+	_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseCIDRs([]string{"proxy.local"})
+	assert.Error(t, err)
+}
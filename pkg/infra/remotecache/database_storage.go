@@ -2,6 +2,8 @@ package remotecache
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -122,6 +124,67 @@ func (dc *databaseCache) Delete(ctx context.Context, key string) error {
 	})
 }
 
+// Increment updates the counter with a compare-and-swap, and retries when another instance updated it first
+func (dc *databaseCache) Increment(ctx context.Context, key string, expire time.Duration) (int64, error) {
+	var expiresInSeconds int64
+	if expire != 0 {
+		expiresInSeconds = int64(expire) / int64(time.Second)
+	}
+
+	var count int64
+	err := dc.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		for attempt := 0; attempt < maxIncrementAttempts; attempt++ {
+			now := getTime().Unix()
+			current := CacheData{}
+			exist, err := session.Where("cache_key= ?", key).Get(&current)
+			if err != nil {
+				return err
+			}
+
+			if !exist {
+				sql := `INSERT INTO cache_data (cache_key,data,created_at,expires) VALUES(?,?,?,?)`
+				_, err := session.Exec(sql, key, []byte("1"), now, expiresInSeconds)
+				if err == nil {
+					count = 1
+					return nil
+				}
+				if dc.SQLStore.GetDialect().IsUniqueConstraintViolation(err) || dc.SQLStore.GetDialect().IsDeadlock(err) {
+					continue
+				}
+				return err
+			}
+
+			// an expired counter starts over
+			next, createdAt, expires := int64(1), now, expiresInSeconds
+			if current.Expires == 0 || now-current.CreatedAt < current.Expires {
+				value, err := strconv.ParseInt(string(current.Data), 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid counter: %w", err)
+				}
+				next, createdAt, expires = value+1, current.CreatedAt, current.Expires
+			}
+
+			sql := `UPDATE cache_data SET data=?, created_at=?, expires=? WHERE cache_key=? AND data=? AND created_at=?`
+			res, err := session.Exec(sql, []byte(strconv.FormatInt(next, 10)), createdAt, expires, key, current.Data, current.CreatedAt)
+			if err != nil {
+				if dc.SQLStore.GetDialect().IsDeadlock(err) {
+					continue
+				}
+				return err
+			}
+			if affected, err := res.RowsAffected(); err != nil {
+				return err
+			} else if affected == 1 {
+				count = next
+				return nil
+			}
+		}
+		return ErrIncrementConflict
+	})
+
+	return count, err
+}
+
 func (dc *databaseCache) Count(ctx context.Context, prefix string) (int64, error) {
 	res := int64(0)
 	err := dc.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
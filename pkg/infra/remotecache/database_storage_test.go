@@ -2,6 +2,7 @@ package remotecache
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -115,3 +116,28 @@ func TestDatabaseStorageCount(t *testing.T) {
 	require.NoError(t, errC)
 	assert.Equal(t, int64(2), n)
 }
+
+func TestDatabaseStorageConcurrentIncrement(t *testing.T) {
+	sqlstore := db.InitTestDB(t)
+
+	db := &databaseCache{
+		SQLStore: sqlstore,
+		log:      log.New("remotecache.database"),
+	}
+
+	const increments = 5
+	var wg sync.WaitGroup
+	for i := 0; i < increments; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.Increment(context.Background(), "counter", time.Minute)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	data, err := db.Get(context.Background(), "counter")
+	require.NoError(t, err)
+	assert.Equal(t, "5", string(data))
+}
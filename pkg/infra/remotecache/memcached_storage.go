@@ -61,6 +61,34 @@ func (s *memcachedStorage) Count(ctx context.Context, prefix string) (int64, err
 	return 0, ErrNotImplemented
 }
 
+// Increment increments a counter, adding it if it does not exist
+func (s *memcachedStorage) Increment(ctx context.Context, key string, expires time.Duration) (int64, error) {
+	var expiresInSeconds int64
+	if expires != 0 {
+		expiresInSeconds = int64(expires) / int64(time.Second)
+	}
+
+	for attempt := 0; attempt < maxIncrementAttempts; attempt++ {
+		count, err := s.c.Increment(key, 1)
+		if err == nil {
+			return int64(count), nil
+		}
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			return 0, err
+		}
+
+		err = s.c.Add(newItem(key, []byte("1"), int32(expiresInSeconds)))
+		if err == nil {
+			return 1, nil
+		}
+		// another client added the counter in the meantime
+		if !errors.Is(err, memcache.ErrNotStored) {
+			return 0, err
+		}
+	}
+	return 0, ErrIncrementConflict
+}
+
 // Delete delete a key from the cache
 func (s *memcachedStorage) Delete(ctx context.Context, key string) error {
 	return s.c.Delete(key)
@@ -19,6 +19,15 @@ type redisStorage struct {
 	c *redis.Client
 }
 
+// incrementScript increments a counter and sets the expiration of the counters it creates.
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
 // parseRedisConnStr parses k=v pairs in csv and builds a redis Options object
 func parseRedisConnStr(connStr string) (*redis.Options, error) {
 	keyValueCSV := strings.Split(connStr, ",")
@@ -102,6 +111,11 @@ func (s *redisStorage) Delete(ctx context.Context, key string) error {
 	return cmd.Err()
 }
 
+// Increment increments a counter with a script, so that the counter is never left without expiration
+func (s *redisStorage) Increment(ctx context.Context, key string, expires time.Duration) (int64, error) {
+	return incrementScript.Run(ctx, s.c, []string{key}, expires.Milliseconds()).Int64()
+}
+
 func (s *redisStorage) Count(ctx context.Context, prefix string) (int64, error) {
 	cmd := s.c.Keys(ctx, prefix+"*")
 	if cmd.Err() != nil {
@@ -3,6 +3,7 @@ package remotecache
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -19,11 +20,17 @@ var (
 	// ErrInvalidCacheType is returned if the type is invalid
 	ErrInvalidCacheType = errors.New("invalid remote cache name")
 
+	// ErrIncrementConflict is returned if a counter keeps being updated concurrently
+	ErrIncrementConflict = errors.New("too many concurrent updates of the counter")
+
 	defaultMaxCacheExpiration = time.Hour * 24
 )
 
 const (
 	ServiceName = "RemoteCache"
+
+	// maxIncrementAttempts is how many times the storages without an atomic increment retry to update a counter.
+	maxIncrementAttempts = 10
 )
 
 func ProvideService(cfg *setting.Cfg, sqlStore db.DB, usageStats usagestats.Service,
@@ -52,6 +59,7 @@ func (ds *RemoteCache) getUsageStats(ctx context.Context) (map[string]any, error
 	}
 
 	stats["stats.remote_cache.encrypt_enabled.count"] = encryptVal
+	stats["stats.remote_cache.encrypted_namespaces.count"] = len(ds.Cfg.RemoteCacheOptions.EncryptedNamespaces)
 
 	return stats, nil
 }
@@ -74,6 +82,10 @@ type CacheStorage interface {
 	// Optionaly a prefix can be provided to only count items with that prefix
 	// DO NOT USE. Not available for memcached.
 	Count(ctx context.Context, prefix string) (int64, error)
+
+	// Increment atomically increments the counter stored at the key and returns its new value.
+	// A missing counter is created with the expiration and starts at 1.
+	Increment(ctx context.Context, key string, expire time.Duration) (int64, error)
 }
 
 // RemoteCache allows Grafana to cache data outside its own process
@@ -107,6 +119,15 @@ func (ds *RemoteCache) Count(ctx context.Context, prefix string) (int64, error)
 	return ds.client.Count(ctx, prefix)
 }
 
+// Increment atomically increments the counter stored at the key
+func (ds *RemoteCache) Increment(ctx context.Context, key string, expire time.Duration) (int64, error) {
+	if expire == 0 {
+		expire = defaultMaxCacheExpiration
+	}
+
+	return ds.client.Increment(ctx, key, expire)
+}
+
 // Run starts the backend processes for cache clients.
 func (ds *RemoteCache) Run(ctx context.Context) error {
 	// create new interface if more clients need GC jobs
@@ -139,6 +160,11 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore db.DB, secretsServi
 
 	if opts.Encryption {
 		cache = &encryptedCacheStorage{cache: cache, secretsService: secretsService}
+	} else if len(opts.EncryptedNamespaces) > 0 {
+		cache = &namespaceEncryptedCacheStorage{
+			encryptedCacheStorage: encryptedCacheStorage{cache: cache, secretsService: secretsService},
+			namespaces:            opts.EncryptedNamespaces,
+		}
 	}
 	return cache, nil
 }
@@ -177,6 +203,52 @@ func (pcs *encryptedCacheStorage) Count(ctx context.Context, prefix string) (int
 	return pcs.cache.Count(ctx, prefix)
 }
 
+// Increment does not encrypt the counters, they hold no credentials.
+func (pcs *encryptedCacheStorage) Increment(ctx context.Context, key string, expire time.Duration) (int64, error) {
+	return pcs.cache.Increment(ctx, key, expire)
+}
+
+// namespaceEncryptedCacheStorage only encrypts the values of the keys that start with
+// one of the namespaces, which are the items that hold credentials.
+type namespaceEncryptedCacheStorage struct {
+	encryptedCacheStorage
+	namespaces []string
+}
+
+func (ncs *namespaceEncryptedCacheStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	if !ncs.encrypted(key) {
+		return ncs.cache.Get(ctx, key)
+	}
+
+	data, err := ncs.cache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := ncs.secretsService.Decrypt(ctx, data)
+	if err != nil {
+		// the value was cached before its namespace was encrypted, so it has to be fetched again
+		return nil, ErrCacheItemNotFound
+	}
+	return decrypted, nil
+}
+
+func (ncs *namespaceEncryptedCacheStorage) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	if !ncs.encrypted(key) {
+		return ncs.cache.Set(ctx, key, value, expire)
+	}
+	return ncs.encryptedCacheStorage.Set(ctx, key, value, expire)
+}
+
+func (ncs *namespaceEncryptedCacheStorage) encrypted(key string) bool {
+	for _, namespace := range ncs.namespaces {
+		if strings.HasPrefix(key, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
 type prefixCacheStorage struct {
 	cache  CacheStorage
 	prefix string
@@ -195,3 +267,7 @@ func (pcs *prefixCacheStorage) Delete(ctx context.Context, key string) error {
 func (pcs *prefixCacheStorage) Count(ctx context.Context, prefix string) (int64, error) {
 	return pcs.cache.Count(ctx, pcs.prefix+prefix)
 }
+
+func (pcs *prefixCacheStorage) Increment(ctx context.Context, key string, expire time.Duration) (int64, error) {
+	return pcs.cache.Increment(ctx, pcs.prefix+key, expire)
+}
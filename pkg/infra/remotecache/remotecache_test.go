@@ -2,6 +2,7 @@ package remotecache
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -47,6 +48,7 @@ func TestInvalidCacheTypeReturnsError(t *testing.T) {
 func runTestsForClient(t *testing.T, client CacheStorage) {
 	canPutGetAndDeleteCachedObjects(t, client)
 	canNotFetchExpiredItems(t, client)
+	canIncrementCounters(t, client)
 }
 
 func runCountTestsForClient(t *testing.T, opts *setting.RemoteCacheOptions, sqlstore db.DB) {
@@ -116,8 +118,9 @@ func canNotFetchExpiredItems(t *testing.T, client CacheStorage) {
 
 func TestCollectUsageStats(t *testing.T) {
 	wantMap := map[string]any{
-		"stats.remote_cache.redis.count":           1,
-		"stats.remote_cache.encrypt_enabled.count": 1,
+		"stats.remote_cache.redis.count":                1,
+		"stats.remote_cache.encrypt_enabled.count":      1,
+		"stats.remote_cache.encrypted_namespaces.count": 0,
 	}
 	cfg := setting.NewCfg()
 	cfg.RemoteCacheOptions = &setting.RemoteCacheOptions{Name: redisCacheType, Encryption: true}
@@ -171,6 +174,51 @@ func TestEncryptedCache(t *testing.T) {
 	require.Equal(t, "bar", string(v))
 }
 
+func TestNamespaceEncryptedCache(t *testing.T) {
+	cache := NewFakeCacheStorage()
+	encryptedCache := &namespaceEncryptedCacheStorage{
+		encryptedCacheStorage: encryptedCacheStorage{cache: cache, secretsService: &fakeSecretsService{}},
+		namespaces:            []string{"id-token"},
+	}
+
+	t.Run("encrypts the values of the keys in the namespaces", func(t *testing.T) {
+		err := encryptedCache.Set(context.Background(), "id-token-user:1", []byte("token"), time.Hour)
+		require.NoError(t, err)
+
+		v, err := cache.Get(context.Background(), "id-token-user:1")
+		require.NoError(t, err)
+		require.Equal(t, "nekot", string(v))
+
+		v, err = encryptedCache.Get(context.Background(), "id-token-user:1")
+		require.NoError(t, err)
+		require.Equal(t, "token", string(v))
+	})
+
+	t.Run("does not encrypt the values of other keys", func(t *testing.T) {
+		err := encryptedCache.Set(context.Background(), "ratelimit", []byte("10"), time.Hour)
+		require.NoError(t, err)
+
+		v, err := cache.Get(context.Background(), "ratelimit")
+		require.NoError(t, err)
+		require.Equal(t, "10", string(v))
+
+		v, err = encryptedCache.Get(context.Background(), "ratelimit")
+		require.NoError(t, err)
+		require.Equal(t, "10", string(v))
+	})
+
+	t.Run("returns a cache miss for values cached before their namespace was encrypted", func(t *testing.T) {
+		encryptedCache := &namespaceEncryptedCacheStorage{
+			encryptedCacheStorage: encryptedCacheStorage{cache: cache, secretsService: &failingSecretsService{}},
+			namespaces:            []string{"id-token"},
+		}
+		require.NoError(t, cache.Set(context.Background(), "id-token-user:2", []byte("token"), time.Hour))
+
+		_, err := encryptedCache.Get(context.Background(), "id-token-user:2")
+		require.ErrorIs(t, err, ErrCacheItemNotFound)
+	})
+}
+
 type fakeSecretsService struct{}
 
 func (f fakeSecretsService) Encrypt(_ context.Context, payload []byte, _ secrets.EncryptionOptions) ([]byte, error) {
@@ -188,3 +236,29 @@ func (f fakeSecretsService) reverse(input []byte) []byte {
 	}
 	return []byte(string(r))
 }
+
+type failingSecretsService struct {
+	fakeSecretsService
+}
+
+func (f failingSecretsService) Decrypt(_ context.Context, _ []byte) ([]byte, error) {
+	return nil, errors.New("failed to decrypt")
+}
+
+func canIncrementCounters(t *testing.T, client CacheStorage) {
+	for i := int64(1); i <= 3; i++ {
+		count, err := client.Increment(context.Background(), "counter", time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+
+	// the counter starts over once it expired
+	<-time.After(time.Second + time.Millisecond)
+
+	count, err := client.Increment(context.Background(), "counter", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = client.Delete(context.Background(), "counter")
+	require.NoError(t, err)
+}
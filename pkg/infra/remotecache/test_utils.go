@@ -2,6 +2,7 @@ package remotecache
 
 import (
 	"context"
+	"strconv"
 	"time"
 )
 
@@ -32,6 +33,13 @@ func (fcs FakeCacheStorage) Count(_ context.Context, prefix string) (int64, erro
 	return int64(len(fcs.Storage)), nil
 }
 
+func (fcs FakeCacheStorage) Increment(_ context.Context, key string, exp time.Duration) (int64, error) {
+	count, _ := strconv.ParseInt(string(fcs.Storage[key]), 10, 64)
+	count++
+	fcs.Storage[key] = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
 func NewFakeCacheStorage() FakeCacheStorage {
 	return FakeCacheStorage{
 		Storage: map[string][]byte{},
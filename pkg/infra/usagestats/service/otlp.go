@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+const otlpScopeName = "github.com/grafana/grafana/pkg/infra/usagestats"
+
+// exportUsageStatsOTLP pushes the usage report as OTLP gauges to the configured OTLP/HTTP metrics endpoint.
+func (uss *UsageStats) exportUsageStatsOTLP(ctx context.Context) error {
+	ctx, span := uss.tracer.Start(ctx, "UsageStats.ExportOTLP")
+	defer span.End()
+	start := time.Now()
+
+	report, err := uss.GetUsageReport(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := proto.Marshal(otlpMetricsRequest(report, uss.Cfg.BuildVersion, start))
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage stats: %w", err)
+	}
+
+	client := http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uss.Cfg.UsageStatsOTLPEndpoint, bytes.NewReader(body))
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to create request for usage stats: %v", err))
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := client.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to export usage stats: %v", err))
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			uss.log.FromContext(ctx).Warn("Failed to close response body after exporting usage stats", "err", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status code %d from OTLP endpoint: %s", resp.StatusCode, msg)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	uss.log.FromContext(ctx).Debug("Exported usage stats to OTLP endpoint", "duration", time.Since(start))
+	return nil
+}
+
+// otlpMetricsRequest converts the numeric metrics of the usage report into gauges.
+// The fields of the report that describe the instance are set as resource attributes.
+func otlpMetricsRequest(report usagestats.Report, version string, now time.Time) *collectormetrics.ExportMetricsServiceRequest {
+	names := make([]string, 0, len(report.Metrics))
+	for name := range report.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ts := uint64(now.UnixNano())
+	metrics := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		dp := &metricspb.NumberDataPoint{TimeUnixNano: ts}
+		switch v := report.Metrics[name].(type) {
+		case int:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(v)}
+		case int32:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(v)}
+		case int64:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: v}
+		case uint32:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(v)}
+		case uint64:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(v)}
+		case float32:
+			dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: float64(v)}
+		case float64:
+			dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: v}
+		case bool:
+			var i int64
+			if v {
+				i = 1
+			}
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: i}
+		default:
+			// Metrics that are not numbers, such as the names of the configured providers, cannot be gauges.
+			continue
+		}
+		metrics = append(metrics, &metricspb.Metric{
+			Name: name,
+			Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{dp}}},
+		})
+	}
+
+	return &collectormetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				otlpStringAttribute("service.name", "grafana"),
+				otlpStringAttribute("service.version", version),
+				otlpStringAttribute("service.instance.id", report.UsageStatsId),
+				otlpStringAttribute("os.type", report.Os),
+				otlpStringAttribute("host.arch", report.Arch),
+				otlpStringAttribute("grafana.edition", report.Edition),
+				otlpStringAttribute("grafana.packaging", report.Packaging),
+			}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: otlpScopeName},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+func otlpStringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/db/dbtest"
+)
+
+func TestExportUsageStatsOTLP(t *testing.T) {
+	var received *collectormetrics.ExportMetricsServiceRequest
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = &collectormetrics.ExportMetricsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, received))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	uss := createService(t, dbtest.NewFakeDB(), true)
+	uss.Cfg.BuildVersion = "10.3.0"
+	uss.Cfg.UsageStatsOTLPEndpoint = server.URL
+	uss.RegisterMetricsFunc(func(context.Context) (map[string]any, error) {
+		return map[string]any{
+			"stats.dashboards.count": 3,
+			"stats.alerting.ratio":   0.5,
+			"stats.auth_providers":   "github,ldap",
+		}, nil
+	})
+
+	require.NoError(t, uss.exportUsageStatsOTLP(context.Background()))
+	require.NotNil(t, received)
+	require.Len(t, received.ResourceMetrics, 1)
+
+	attrs := map[string]string{}
+	for _, kv := range received.ResourceMetrics[0].Resource.Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	assert.Equal(t, "grafana", attrs["service.name"])
+	assert.Equal(t, "10.3.0", attrs["service.version"])
+	assert.Equal(t, "oss", attrs["grafana.edition"])
+	assert.NotEmpty(t, attrs["service.instance.id"])
+
+	gauges := map[string]any{}
+	for _, m := range received.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		dp := m.GetGauge().DataPoints[0]
+		switch v := dp.Value.(type) {
+		case *metricspb.NumberDataPoint_AsInt:
+			gauges[m.Name] = v.AsInt
+		case *metricspb.NumberDataPoint_AsDouble:
+			gauges[m.Name] = v.AsDouble
+		}
+		assert.NotZero(t, dp.TimeUnixNano)
+	}
+	assert.Equal(t, int64(3), gauges["stats.dashboards.count"])
+	assert.Equal(t, 0.5, gauges["stats.alerting.ratio"])
+	assert.Equal(t, int64(0), gauges["stats.valid_license.count"])
+	assert.NotContains(t, gauges, "stats.auth_providers")
+
+	t.Run("fails when the endpoint rejects the metrics", func(t *testing.T) {
+		status = http.StatusBadRequest
+		require.Error(t, uss.exportUsageStatsOTLP(context.Background()))
+	})
+}
@@ -81,8 +81,24 @@ func (uss *UsageStats) Run(ctx context.Context) error {
 
 	defer sendReportTicker.Stop()
 
+	// the OTLP export is independent of the usage report sent to stats.grafana.org
+	var exportOTLP <-chan time.Time
+	if uss.Cfg.UsageStatsOTLPEndpoint != "" {
+		exportTicker := time.NewTicker(uss.Cfg.UsageStatsOTLPInterval)
+		defer exportTicker.Stop()
+		exportOTLP = exportTicker.C
+	}
+
 	for {
 		select {
+		case <-exportOTLP:
+			if !uss.readyToReport {
+				continue
+			}
+
+			if err := uss.exportUsageStatsOTLP(ctx); err != nil {
+				uss.log.Warn("Failed to export usage stats to OTLP endpoint", "error", err)
+			}
 		case <-sendReportTicker.C:
 			if !uss.readyToReport {
 				nextSendInterval = time.Minute
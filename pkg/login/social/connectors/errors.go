@@ -20,6 +20,10 @@ var (
 		errutil.WithPublicMessage("IdP did not return a valid role attribute, please contact your administrator"))
 )
 
+func init() {
+	errutil.RegisterCode("auth.role-strict-denied", errRoleAttributeStrictViolation)
+}
+
 // SocialError is a custom error type for social connectors to provide a public message when the connector expectaions are not met.
 type SocialError struct {
 	s string
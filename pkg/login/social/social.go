@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/org"
 	"golang.org/x/oauth2"
@@ -61,6 +62,7 @@ type OAuthInfo struct {
 	AuthStyle               string            `mapstructure:"auth_style" toml:"auth_style" json:"authStyle"`
 	AuthUrl                 string            `mapstructure:"auth_url" toml:"auth_url" json:"authUrl"`
 	AutoLogin               bool              `mapstructure:"auto_login" toml:"auto_login" json:"autoLogin"`
+	AutoLoginEmailDomains   []string          `mapstructure:"auto_login_email_domains" toml:"auto_login_email_domains" json:"autoLoginEmailDomains"`
 	ClientId                string            `mapstructure:"client_id" toml:"client_id" json:"clientId"`
 	ClientSecret            string            `mapstructure:"client_secret" toml:"-" json:"clientSecret"`
 	EmailAttributeName      string            `mapstructure:"email_attribute_name" toml:"email_attribute_name" json:"emailAttributeName"`
@@ -97,6 +99,36 @@ func NewOAuthInfo() *OAuthInfo {
 	}
 }
 
+// MatchAutoLoginEmailDomain returns the auto-login email domain pattern matching the domain of the email, preferring
+// the most specific pattern. Patterns are domains, like corp.com, or wildcards matching their subdomains, like
+// *.corp.com. It returns false when no pattern matches.
+func (o *OAuthInfo) MatchAutoLoginEmailDomain(email string) (string, bool) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	if domain == "" {
+		return "", false
+	}
+
+	match := ""
+	for _, pattern := range o.AutoLoginEmailDomains {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if len(pattern) <= len(match) {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if suffix != "" && strings.HasSuffix(domain, "."+suffix) {
+				match = pattern
+			}
+		} else if domain == pattern {
+			match = pattern
+		}
+	}
+	return match, match != ""
+}
+
 type BasicUserInfo struct {
 	Id             string
 	Name           string
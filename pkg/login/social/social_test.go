@@ -0,0 +1,34 @@
+package social
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthInfo_MatchAutoLoginEmailDomain(t *testing.T) {
+	info := &OAuthInfo{AutoLoginEmailDomains: []string{"corp.com", "*.corp.com", "*.eu.corp.com"}}
+
+	tests := []struct {
+		email           string
+		expectedPattern string
+		expectedMatch   bool
+	}{
+		{email: "jane@corp.com", expectedPattern: "corp.com", expectedMatch: true},
+		{email: "Jane@CORP.com", expectedPattern: "corp.com", expectedMatch: true},
+		{email: "jane@us.corp.com", expectedPattern: "*.corp.com", expectedMatch: true},
+		{email: "jane@paris.eu.corp.com", expectedPattern: "*.eu.corp.com", expectedMatch: true},
+		{email: "jane@notcorp.com"},
+		{email: "jane@corp.com.evil.com"},
+		{email: "corp.com"},
+		{email: "jane@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			pattern, ok := info.MatchAutoLoginEmailDomain(tt.email)
+			assert.Equal(t, tt.expectedMatch, ok)
+			assert.Equal(t, tt.expectedPattern, pattern)
+		})
+	}
+}
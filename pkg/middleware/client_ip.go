@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ClientIP resolves the IP address of the client of the requests from the
+// headers of the trusted proxies, and sets it on their context so that it is
+// returned by web.RemoteAddr. It must run before the middlewares that use the
+// address of the client, such as the context handler.
+func ClientIP(cfg *setting.Cfg) web.Middleware {
+	resolver := network.NewClientIPResolver(cfg.TrustedProxies, cfg.ClientIPHeaders)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := resolver.ClientIP(r); ip != nil {
+				*r = *r.WithContext(web.WithClientIP(r.Context(), ip.String()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
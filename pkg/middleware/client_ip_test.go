@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestClientIP(t *testing.T) {
+	cfg := setting.NewCfg()
+	var err error
+	cfg.TrustedProxies, err = network.ParseCIDRs([]string{"10.0.0.1"})
+	require.NoError(t, err)
+	cfg.ClientIPHeaders = []string{"X-Forwarded-For"}
+
+	m := web.New()
+	m.Use(ClientIP(cfg))
+	var remoteAddr string
+	m.Get("/", func(rw http.ResponseWriter, req *http.Request) {
+		remoteAddr = web.RemoteAddr(req)
+	})
+
+	serve := func(peer string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = peer
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		req.Header.Set("X-Real-IP", "192.0.2.1")
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		return remoteAddr
+	}
+
+	assert.Equal(t, "198.51.100.1", serve("10.0.0.1:51299"))
+	assert.Equal(t, "203.0.113.1", serve("203.0.113.1:51299"))
+}
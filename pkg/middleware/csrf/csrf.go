@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -16,28 +19,69 @@ type Service interface {
 	TrustOrigin(origin string)
 	AddAdditionalHeaders(headerName string)
 	AddSafeEndpoint(endpoint string)
+	AddSafeEndpointPrefix(prefix string)
 }
 
+// Strategy selects the requests that are checked.
+type Strategy string
+
+const (
+	// StrategyLoginCookie checks the requests with a login cookie.
+	StrategyLoginCookie Strategy = "login_cookie"
+	// StrategyAlways checks all the requests.
+	StrategyAlways Strategy = "always"
+	// StrategySameSite checks the requests with a login cookie only if the
+	// cookie can be sent with cross-site requests, relying on the browsers
+	// otherwise.
+	StrategySameSite Strategy = "samesite"
+)
+
+const (
+	rejectReasonInvalidHost      = "invalid_host"
+	rejectReasonInvalidOrigin    = "invalid_origin"
+	rejectReasonOriginNotAllowed = "origin_not_allowed"
+)
+
 type CSRF struct {
 	cfg *setting.Cfg
+	log log.Logger
 
-	trustedOrigins map[string]struct{}
-	headers        map[string]struct{}
-	safeEndpoints  map[string]struct{}
-	alwaysCheck    bool
+	trustedOrigins       map[string]struct{}
+	headers              map[string]struct{}
+	safeEndpoints        map[string]struct{}
+	safeEndpointPrefixes map[string]struct{}
+	alwaysCheck          bool
+	strategy             Strategy
 }
 
 func ProvideCSRFFilter(cfg *setting.Cfg) *CSRF {
 	c := &CSRF{
-		cfg:            cfg,
-		trustedOrigins: map[string]struct{}{},
-		headers:        map[string]struct{}{},
-		safeEndpoints:  map[string]struct{}{},
+		cfg:                  cfg,
+		log:                  log.New("csrf"),
+		trustedOrigins:       map[string]struct{}{},
+		headers:              map[string]struct{}{},
+		safeEndpoints:        map[string]struct{}{},
+		safeEndpointPrefixes: map[string]struct{}{},
 	}
 
-	additionalHeaders := cfg.SectionWithEnvOverrides("security").Key("csrf_additional_headers").Strings(" ")
-	trustedOrigins := cfg.SectionWithEnvOverrides("security").Key("csrf_trusted_origins").Strings(" ")
-	c.alwaysCheck = cfg.SectionWithEnvOverrides("security").Key("csrf_always_check").MustBool(false)
+	section := cfg.SectionWithEnvOverrides("security")
+	additionalHeaders := section.Key("csrf_additional_headers").Strings(" ")
+	trustedOrigins := section.Key("csrf_trusted_origins").Strings(" ")
+	exemptPaths := section.Key("csrf_exempt_paths").Strings(" ")
+	c.alwaysCheck = section.Key("csrf_always_check").MustBool(false)
+
+	c.strategy = Strategy(section.Key("csrf_strategy").MustString(string(StrategyLoginCookie)))
+	switch c.strategy {
+	case StrategyLoginCookie, StrategySameSite:
+	case StrategyAlways:
+		c.alwaysCheck = true
+	default:
+		c.log.Warn("Unknown CSRF strategy, using login_cookie", "strategy", c.strategy)
+		c.strategy = StrategyLoginCookie
+	}
+	if c.alwaysCheck {
+		c.strategy = StrategyAlways
+	}
 
 	for _, header := range additionalHeaders {
 		c.headers[header] = struct{}{}
@@ -45,6 +89,13 @@ func ProvideCSRFFilter(cfg *setting.Cfg) *CSRF {
 	for _, origin := range trustedOrigins {
 		c.trustedOrigins[origin] = struct{}{}
 	}
+	for _, path := range exemptPaths {
+		if prefix, ok := strings.CutSuffix(path, "*"); ok {
+			c.AddSafeEndpointPrefix(prefix)
+		} else {
+			c.AddSafeEndpoint(path)
+		}
+	}
 
 	return c
 }
@@ -58,7 +109,10 @@ func (c *CSRF) Middleware() func(http.Handler) http.Handler {
 			if err != nil {
 				if !errors.As(err, &e) {
 					http.Error(w, fmt.Sprintf("internal server error: expected error type errorWithStatus, got %s. Error: %v", reflect.TypeOf(err), err), http.StatusInternalServerError)
+					return
 				}
+				metrics.MCSRFRejectedRequests.WithLabelValues(e.Reason).Inc()
+				c.log.Debug("Request rejected by the CSRF check", "method", r.Method, "path", r.URL.Path, "origin", r.Header.Get("Origin"), "reason", e.Reason)
 				http.Error(w, err.Error(), e.HTTPStatus)
 				return
 			}
@@ -79,6 +133,10 @@ func (c *CSRF) check(r *http.Request) error {
 		if _, err := r.Cookie(c.cfg.LoginCookieName); errors.Is(err, http.ErrNoCookie) {
 			return nil
 		}
+		// Browsers do not send the login cookie with cross-site requests.
+		if c.strategy == StrategySameSite && !c.cfg.CookieSameSiteDisabled && c.cfg.CookieSameSiteMode != http.SameSiteNoneMode {
+			return nil
+		}
 	}
 
 	// Skip CSRF checks for "safe" methods
@@ -93,10 +151,15 @@ func (c *CSRF) check(r *http.Request) error {
 			return nil
 		}
 	}
+	for prefix := range c.safeEndpointPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return nil
+		}
+	}
 	// Otherwise - verify that Origin matches the server origin
 	netAddr, err := util.SplitHostPortDefault(r.Host, "", "0") // we ignore the port
 	if err != nil {
-		return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest}
+		return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest, Reason: rejectReasonInvalidHost}
 	}
 
 	o := r.Header.Get("Origin")
@@ -108,7 +171,7 @@ func (c *CSRF) check(r *http.Request) error {
 
 	originURL, err := url.Parse(o)
 	if err != nil {
-		return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest}
+		return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest, Reason: rejectReasonInvalidOrigin}
 	}
 	origin := originURL.Hostname()
 
@@ -117,7 +180,7 @@ func (c *CSRF) check(r *http.Request) error {
 		customHost := r.Header.Get(h)
 		addr, err := util.SplitHostPortDefault(customHost, "", "0") // we ignore the port
 		if err != nil {
-			return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest}
+			return &errorWithStatus{Underlying: err, HTTPStatus: http.StatusBadRequest, Reason: rejectReasonInvalidHost}
 		}
 		if addr.Host == origin {
 			trustedOrigin = true
@@ -125,21 +188,41 @@ func (c *CSRF) check(r *http.Request) error {
 		}
 	}
 
-	for o := range c.trustedOrigins {
-		if o == origin {
-			trustedOrigin = true
-			break
-		}
+	if !trustedOrigin {
+		trustedOrigin = c.isTrustedOrigin(originURL)
 	}
 
 	hostnameMatches := origin == netAddr.Host
 	if netAddr.Host == "" || !trustedOrigin && !hostnameMatches {
-		return &errorWithStatus{Underlying: errors.New("origin not allowed"), HTTPStatus: http.StatusForbidden}
+		return &errorWithStatus{Underlying: errors.New("origin not allowed"), HTTPStatus: http.StatusForbidden, Reason: rejectReasonOriginNotAllowed}
 	}
 
 	return nil
 }
 
+// isTrustedOrigin returns true if the origin matches a trusted origin. Trusted
+// origins are hostnames, such as grafana.com, wildcards matching the subdomains
+// of a domain, such as *.grafana.com, or origins with a scheme and an optional
+// port, such as https://grafana.com:8443.
+func (c *CSRF) isTrustedOrigin(originURL *url.URL) bool {
+	hostname := originURL.Hostname()
+	for o := range c.trustedOrigins {
+		switch {
+		case strings.Contains(o, "://"):
+			if o == originURL.Scheme+"://"+originURL.Host {
+				return true
+			}
+		case strings.HasPrefix(o, "*."):
+			if strings.HasSuffix(hostname, o[1:]) {
+				return true
+			}
+		case o == hostname:
+			return true
+		}
+	}
+	return false
+}
+
 func (c *CSRF) TrustOrigin(origin string) {
 	c.trustedOrigins[origin] = struct{}{}
 }
@@ -153,9 +236,17 @@ func (c *CSRF) AddSafeEndpoint(endpoint string) {
 	c.safeEndpoints[endpoint] = struct{}{}
 }
 
+// AddSafeEndpointPrefix is used for the requests to the endpoints under a path,
+// such as webhook receivers, to skip CSRF check
+func (c *CSRF) AddSafeEndpointPrefix(prefix string) {
+	c.safeEndpointPrefixes[prefix] = struct{}{}
+}
+
 type errorWithStatus struct {
 	Underlying error
 	HTTPStatus int
+	// Reason is the label of the rejected requests metric.
+	Reason string
 }
 
 func (e errorWithStatus) Error() string {
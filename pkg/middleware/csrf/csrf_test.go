@@ -7,9 +7,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -102,6 +104,16 @@ func TestMiddlewareCSRF(t *testing.T) {
 	}
 }
 
+func TestMiddlewareCSRF_RejectedRequestsMetric(t *testing.T) {
+	before := testutil.ToFloat64(metrics.MCSRFRejectedRequests.WithLabelValues(rejectReasonOriginNotAllowed))
+
+	rr := csrfScenario(t, "foo", "POST", "http://notLocalhost", "localhost")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	after := testutil.ToFloat64(metrics.MCSRFRejectedRequests.WithLabelValues(rejectReasonOriginNotAllowed))
+	assert.Equal(t, before+1, after)
+}
+
 func TestCSRF_Check(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -210,6 +222,94 @@ func TestCSRF_Check(t *testing.T) {
 			request:        postRequest(t, "grafana.localhost", map[string]string{"X-Forwarded-Host": "grafana.org", "Origin": "https://grafana.org"}, false),
 			expectedStatus: http.StatusForbidden,
 		},
+		{
+			name: "grafana.org with csrf_strategy always; will perform csrf check even if login cookie is not present",
+			getCfg: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_strategy").SetValue("always")
+				return cfg
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org"}, false),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "grafana.org with trusted origin including scheme and port",
+			getCfg: func() *setting.Cfg {
+				return setting.NewCfg()
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org:8443"}, true),
+			trustedOrigins: map[string]struct{}{"https://grafana.org:8443": {}},
+			expectedOK:     true,
+		},
+		{
+			name: "grafana.org with trusted origin including a different scheme",
+			getCfg: func() *setting.Cfg {
+				return setting.NewCfg()
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "http://grafana.org:8443"}, true),
+			trustedOrigins: map[string]struct{}{"https://grafana.org:8443": {}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "play.grafana.org with wildcard trusted origin",
+			getCfg: func() *setting.Cfg {
+				return setting.NewCfg()
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://play.grafana.org"}, true),
+			trustedOrigins: map[string]struct{}{"*.grafana.org": {}},
+			expectedOK:     true,
+		},
+		{
+			name: "grafana.org does not match wildcard trusted origin of its subdomains",
+			getCfg: func() *setting.Cfg {
+				return setting.NewCfg()
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://evilgrafana.org"}, true),
+			trustedOrigins: map[string]struct{}{"*.grafana.org": {}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "grafana.org with exempt path prefix",
+			getCfg: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_exempt_paths").SetValue("/api/webhooks/*")
+				return cfg
+			},
+			request:    withPath(postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org"}, true), "/api/webhooks/receiver"),
+			expectedOK: true,
+		},
+		{
+			name: "grafana.org with exempt path prefix not matching",
+			getCfg: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_exempt_paths").SetValue("/api/webhooks/*")
+				return cfg
+			},
+			request:        withPath(postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org"}, true), "/api/dashboards"),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "grafana.org with samesite strategy and lax login cookie",
+			getCfg: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_strategy").SetValue("samesite")
+				cfg.CookieSameSiteMode = http.SameSiteLaxMode
+				return cfg
+			},
+			request:    postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org"}, true),
+			expectedOK: true,
+		},
+		{
+			name: "grafana.org with samesite strategy and login cookie sent cross-site",
+			getCfg: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_strategy").SetValue("samesite")
+				cfg.CookieSameSiteMode = http.SameSiteNoneMode
+				return cfg
+			},
+			request:        postRequest(t, "grafana.localhost", map[string]string{"Origin": "https://grafana.org"}, true),
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
 	for _, tc := range tests {
@@ -262,6 +362,11 @@ func postRequest(t testing.TB, hostname string, headers map[string]string, withL
 	return r
 }
 
+func withPath(r *http.Request, path string) *http.Request {
+	r.URL.Path = path
+	return r
+}
+
 func csrfScenario(t *testing.T, cookieName, method, origin, host string) *httptest.ResponseRecorder {
 	req, err := http.NewRequest(method, "/", nil)
 	if err != nil {
@@ -322,6 +427,15 @@ func TestProvideCSRFFilter(t *testing.T) {
 			// Should be true when config value is set to true.
 			expectedAlwaysCheck: true,
 		},
+		{
+			getInput: func() *setting.Cfg {
+				cfg := setting.NewCfg()
+				cfg.SectionWithEnvOverrides("security").Key("csrf_strategy").SetValue("always")
+				return cfg
+			},
+			// Should be true when the strategy is always.
+			expectedAlwaysCheck: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxErrorBodySize is the maximum size of the error responses that are given a code, the larger
+// responses are written unchanged.
+const maxErrorBodySize = 64 * 1024
+
+// ErrorCode adds a code derived from the HTTP status to the JSON error responses of the API that
+// don't have one, so that every error response has a machine-readable code. Responses written
+// with the response helpers already have the code of the error.
+func ErrorCode(cfg *setting.Cfg) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			path := strings.TrimPrefix(req.URL.Path, cfg.AppSubURL)
+			if !strings.HasPrefix(path, "/api/") {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			ecw := &errorCodeResponseWriter{ResponseWriter: web.Rw(rw, req)}
+			defer ecw.flushError()
+			next.ServeHTTP(ecw, req)
+		})
+	}
+}
+
+type errorCodeResponseWriter struct {
+	web.ResponseWriter
+	status int
+	// body is the buffered error response, nil when the response is not buffered.
+	body *bytes.Buffer
+}
+
+func (w *errorCodeResponseWriter) WriteHeader(status int) {
+	if w.body != nil || w.ResponseWriter.Written() {
+		return
+	}
+	if status >= http.StatusBadRequest && isJSON(w.Header().Get("Content-Type")) {
+		w.status = status
+		w.body = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorCodeResponseWriter) Write(p []byte) (int, error) {
+	if w.body == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.body.Len()+len(p) > maxErrorBodySize {
+		w.writeBuffered(w.body.Bytes())
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+func (w *errorCodeResponseWriter) Status() int {
+	if w.body != nil {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *errorCodeResponseWriter) Written() bool {
+	return w.body != nil || w.ResponseWriter.Written()
+}
+
+// Flush is a no-op while an error response is buffered, the response is written at once when
+// the handler returns.
+func (w *errorCodeResponseWriter) Flush() {
+	if w.body == nil {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *errorCodeResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("error code ResponseWriter doesn't implement the Hijacker interface")
+}
+
+// flushError writes the buffered error response with its code.
+func (w *errorCodeResponseWriter) flushError() {
+	if w.body == nil {
+		return
+	}
+	w.writeBuffered(withErrorCode(w.status, w.body.Bytes()))
+}
+
+func (w *errorCodeResponseWriter) writeBuffered(body []byte) {
+	w.body = nil
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// withErrorCode returns the JSON object in body with a code derived from status, or body
+// unchanged when it is not an object or already has a code.
+func withErrorCode(status int, body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return body
+	}
+	if _, ok := fields["code"]; ok {
+		return body
+	}
+
+	code, err := json.Marshal(errutil.StatusCode(status))
+	if err != nil {
+		return body
+	}
+	// The code is added as the first field so that the rest of the body is unchanged.
+	trimmed := bytes.TrimSpace(body)
+	result := append([]byte(`{"code":`), code...)
+	if len(fields) > 0 {
+		result = append(result, ',')
+	}
+	return append(result, trimmed[1:]...)
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestErrorCodeMiddleware(t *testing.T) {
+	tests := []struct {
+		desc        string
+		path        string
+		status      int
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			desc:        "should add a code to the JSON error responses of the API",
+			path:        "/api/dashboards/uid/abc",
+			status:      http.StatusPreconditionFailed,
+			contentType: "application/json",
+			body:        `{"message":"The dashboard has been changed by someone else"}`,
+			expected:    `{"code":"http.precondition-failed","message":"The dashboard has been changed by someone else"}`,
+		},
+		{
+			desc:        "should add a code to empty JSON objects",
+			path:        "/api/folders",
+			status:      http.StatusNotFound,
+			contentType: "application/json; charset=UTF-8",
+			body:        `{}`,
+			expected:    `{"code":"http.not-found"}`,
+		},
+		{
+			desc:        "should keep the code of the response",
+			path:        "/api/dashboards/uid/abc",
+			status:      http.StatusPreconditionFailed,
+			contentType: "application/json",
+			body:        `{"code":"dashboard.version-conflict","message":"The dashboard has been changed by someone else"}`,
+			expected:    `{"code":"dashboard.version-conflict","message":"The dashboard has been changed by someone else"}`,
+		},
+		{
+			desc:        "should not change successful responses",
+			path:        "/api/dashboards/uid/abc",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"message":"ok"}`,
+			expected:    `{"message":"ok"}`,
+		},
+		{
+			desc:        "should not change the responses that are not JSON objects",
+			path:        "/api/dashboards/uid/abc",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `["invalid"]`,
+			expected:    `["invalid"]`,
+		},
+		{
+			desc:        "should not change the responses that are not JSON",
+			path:        "/api/dashboards/uid/abc",
+			status:      http.StatusBadRequest,
+			contentType: "text/plain",
+			body:        `{"message":"bad request"}`,
+			expected:    `{"message":"bad request"}`,
+		},
+		{
+			desc:        "should not change the responses outside of the API",
+			path:        "/d/abc",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"Not found"}`,
+			expected:    `{"message":"Not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			handler := ErrorCode(setting.NewCfg())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(web.NewResponseWriter(req.Method, recorder), req)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Equal(t, tt.expected, recorder.Body.String())
+		})
+	}
+}
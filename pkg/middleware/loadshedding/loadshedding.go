@@ -0,0 +1,195 @@
+// Package loadshedding rejects the API requests of the least important classes
+// when Grafana is saturated, so that alerting keeps working when, for example,
+// many dashboards are loaded at the same time.
+package loadshedding
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// latencyWindow is the duration of the windows the latency of the requests is
+// averaged over.
+const latencyWindow = 10 * time.Second
+
+// Class is the priority class of a request. Classes with a lower priority are
+// shed first.
+type Class string
+
+const (
+	ClassAlerting Class = "alerting"
+	ClassWrite    Class = "write"
+	ClassRead     Class = "read"
+	ClassSearch   Class = "search"
+)
+
+// shedLevels are the saturation levels, relative to the configured limits,
+// from which the requests of each class are shed. Alerting requests are never
+// shed.
+var shedLevels = map[Class]float64{
+	ClassSearch: 0.7,
+	ClassRead:   0.85,
+	ClassWrite:  1,
+}
+
+var alertingPrefixes = []string{
+	"/api/alertmanager/",
+	"/api/prometheus/",
+	"/api/ruler/",
+	"/api/v1/ngalert",
+	"/api/v1/provisioning/",
+}
+
+// Classify returns the priority class of an API request.
+func Classify(method, path string) Class {
+	for _, prefix := range alertingPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return ClassAlerting
+		}
+	}
+	if strings.HasPrefix(path, "/api/search") {
+		return ClassSearch
+	}
+	// Queries of data sources are mostly the panels of dashboards.
+	if strings.HasPrefix(path, "/api/ds/query") {
+		return ClassRead
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ClassRead
+	}
+	return ClassWrite
+}
+
+type latencyStats struct {
+	start time.Time
+	sum   time.Duration
+	count int64
+}
+
+type LoadShedder struct {
+	enabled       bool
+	maxConcurrent int64
+	latencyTarget time.Duration
+	appSubURL     string
+	log           log.Logger
+
+	inFlight atomic.Int64
+
+	mu       sync.Mutex
+	current  latencyStats
+	previous latencyStats
+
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg) *LoadShedder {
+	section := cfg.SectionWithEnvOverrides("load_shedding")
+	s := &LoadShedder{
+		enabled:       section.Key("enabled").MustBool(false),
+		maxConcurrent: section.Key("max_concurrent_requests").MustInt64(0),
+		latencyTarget: section.Key("latency_target").MustDuration(0),
+		appSubURL:     cfg.AppSubURL,
+		log:           log.New("loadshedding"),
+		now:           time.Now,
+	}
+	if s.enabled && s.maxConcurrent <= 0 && s.latencyTarget <= 0 {
+		s.log.Warn("Load shedding is enabled without max_concurrent_requests or latency_target, no request will be shed")
+		s.enabled = false
+	}
+	return s
+}
+
+// Middleware sheds the API requests whose class is over its saturation level
+// with a 503 response. The saturation is the highest of the ratio of the
+// requests in flight to max_concurrent_requests, and of the average latency
+// of the requests completed during the last window to latency_target.
+func (s *LoadShedder) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		if !s.enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, s.appSubURL)
+			if !strings.HasPrefix(path, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			class := Classify(r.Method, path)
+			if level, ok := shedLevels[class]; ok && s.saturation() >= level {
+				metrics.MLoadSheddingShedRequests.WithLabelValues(string(class)).Inc()
+				w.Header().Set("Retry-After", "1")
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"message":"Grafana is overloaded, try again later"}`))
+				return
+			}
+
+			s.inFlight.Add(1)
+			start := s.now()
+			defer func() {
+				s.inFlight.Add(-1)
+				s.observe(s.now().Sub(start))
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *LoadShedder) saturation() float64 {
+	var level float64
+	if s.maxConcurrent > 0 {
+		// The request being admitted is counted too.
+		level = float64(s.inFlight.Load()+1) / float64(s.maxConcurrent)
+	}
+	if s.latencyTarget > 0 {
+		if latency := float64(s.averageLatency()) / float64(s.latencyTarget); latency > level {
+			level = latency
+		}
+	}
+	return level
+}
+
+// averageLatency returns the average latency of the requests completed during
+// the last full window. It is zero if no request completed during this window,
+// so that the shedding stops once the slow requests are gone.
+func (s *LoadShedder) averageLatency() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotate(s.now())
+	if s.previous.count == 0 {
+		return 0
+	}
+	return s.previous.sum / time.Duration(s.previous.count)
+}
+
+func (s *LoadShedder) observe(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotate(s.now())
+	s.current.sum += d
+	s.current.count++
+}
+
+// rotate must be called with the lock held.
+func (s *LoadShedder) rotate(now time.Time) {
+	start := now.Truncate(latencyWindow)
+	switch {
+	case start.Equal(s.current.start):
+	case start.Sub(s.current.start) == latencyWindow:
+		s.previous = s.current
+		s.current = latencyStats{start: start}
+	default:
+		s.previous = latencyStats{}
+		s.current = latencyStats{start: start}
+	}
+}
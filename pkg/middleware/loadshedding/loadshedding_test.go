@@ -0,0 +1,105 @@
+package loadshedding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		class  Class
+	}{
+		{http.MethodPost, "/api/alertmanager/grafana/api/v2/silences", ClassAlerting},
+		{http.MethodGet, "/api/prometheus/grafana/api/v1/rules", ClassAlerting},
+		{http.MethodPost, "/api/ruler/grafana/api/v1/rules/folder", ClassAlerting},
+		{http.MethodGet, "/api/search", ClassSearch},
+		{http.MethodPost, "/api/ds/query", ClassRead},
+		{http.MethodGet, "/api/dashboards/uid/abc", ClassRead},
+		{http.MethodPost, "/api/dashboards/db", ClassWrite},
+		{http.MethodDelete, "/api/datasources/uid/abc", ClassWrite},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.class, Classify(tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+}
+
+func setupLoadShedder(t *testing.T, keys map[string]string) *LoadShedder {
+	t.Helper()
+	cfg := setting.NewCfg()
+	section := cfg.Raw.Section("load_shedding")
+	section.Key("enabled").SetValue("true")
+	for k, v := range keys {
+		section.Key(k).SetValue(v)
+	}
+	return ProvideService(cfg)
+}
+
+func serve(handler http.Handler, method, path string) int {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec.Code
+}
+
+func TestLoadShedder_Concurrency(t *testing.T) {
+	s := setupLoadShedder(t, map[string]string{"max_concurrent_requests": "10"})
+	handler := s.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// 7 requests in flight, admitting a search request would reach 80% of the limit.
+	s.inFlight.Store(7)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/api/search"))
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/dashboards/uid/abc"))
+
+	s.inFlight.Store(9)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/api/dashboards/uid/abc"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodPost, "/api/dashboards/db"))
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/prometheus/grafana/api/v1/rules"))
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/public/build/app.js"))
+
+	s.inFlight.Store(0)
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/search"))
+	assert.Equal(t, int64(0), s.inFlight.Load())
+}
+
+func TestLoadShedder_Latency(t *testing.T) {
+	s := setupLoadShedder(t, map[string]string{"latency_target": "1s"})
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	var duration time.Duration
+	handler := s.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now = now.Add(duration)
+	}))
+
+	duration = 900 * time.Millisecond
+	require.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/search"))
+	// The latency of the current window is not used until it is over.
+	require.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/search"))
+
+	now = now.Truncate(latencyWindow).Add(latencyWindow)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/api/search"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/api/dashboards/uid/abc"))
+	duration = 0
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodPost, "/api/dashboards/db"))
+
+	// The shedding stops when the slow requests are gone.
+	now = now.Add(2 * latencyWindow)
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/search"))
+}
+
+func TestProvideService_Disabled(t *testing.T) {
+	s := setupLoadShedder(t, nil)
+	assert.False(t, s.enabled)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	s.inFlight.Store(100)
+	assert.Equal(t, http.StatusOK, serve(s.Middleware()(next), http.MethodGet, "/api/search"))
+}
@@ -151,8 +151,8 @@ func TestMiddlewareContext(t *testing.T) {
 				NavTree:  &navtree.NavTreeRoot{},
 				Assets: &dtos.EntryPointAssets{
 					JSFiles:  []dtos.EntryPointAsset{},
-					CSSDark:  "dark.css",
-					CSSLight: "light.css",
+					CSSDark:  dtos.EntryPointAsset{FilePath: "dark.css"},
+					CSSLight: dtos.EntryPointAsset{FilePath: "light.css"},
 				},
 			}
 			t.Log("Calling HTML", "data", data)
@@ -0,0 +1,253 @@
+// Package ratelimit limits the rate of the requests to route groups, such as
+// /api/ds/query, per organization, user, token or client IP address. Counters are incremented
+// atomically in the remote cache so that the limits are shared by all the Grafana instances.
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	rulePrefix     = "rate_limiting.rule."
+	cacheKeyPrefix = "ratelimit-"
+
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+)
+
+// KeyType is what the requests are counted by.
+type KeyType string
+
+const (
+	KeyOrg   KeyType = "org"
+	KeyUser  KeyType = "user"
+	KeyToken KeyType = "token"
+	// KeyIP counts the requests by the IP address of the client, as resolved
+	// from the headers of the trusted proxies.
+	KeyIP KeyType = "ip"
+)
+
+// Rule limits the requests to the paths starting with PathPrefix to Limit
+// requests per Period for each organization, user, token or client IP address.
+type Rule struct {
+	Name       string
+	PathPrefix string
+	// Methods are the methods of the limited requests. All the methods are
+	// limited if empty.
+	Methods []string
+	Key     KeyType
+	Limit   int64
+	Period  time.Duration
+}
+
+func (r Rule) matches(method, path string) bool {
+	if !strings.HasPrefix(path, r.PathPrefix) {
+		return false
+	}
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+type RateLimiter struct {
+	enabled bool
+	// rules are sorted by decreasing length of their path prefix, so that the
+	// most specific rule matching a request is used.
+	rules     []Rule
+	appSubURL string
+	cache     remotecache.CacheStorage
+	log       log.Logger
+	now       func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, cache remotecache.CacheStorage) (*RateLimiter, error) {
+	l := &RateLimiter{
+		enabled:   cfg.SectionWithEnvOverrides("rate_limiting").Key("enabled").MustBool(false),
+		appSubURL: cfg.AppSubURL,
+		cache:     cache,
+		log:       log.New("ratelimit"),
+		now:       time.Now,
+	}
+	if !l.enabled {
+		return l, nil
+	}
+
+	rules, err := readRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l.rules = rules
+
+	for _, rule := range rules {
+		if rule.Key == KeyIP && trustsAllAddresses(cfg.TrustedProxies) {
+			l.log.Warn("Rate limiting rule counts the requests by client IP address, but trusted_proxies trusts all the addresses so the clients can set any address", "rule", rule.Name)
+		}
+	}
+	return l, nil
+}
+
+// trustsAllAddresses returns true if one of the trusted proxies is a network
+// of all the IPv4 or IPv6 addresses.
+func trustsAllAddresses(trustedProxies []*net.IPNet) bool {
+	for _, proxy := range trustedProxies {
+		if ones, _ := proxy.Mask.Size(); ones == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func readRules(cfg *setting.Cfg) ([]Rule, error) {
+	var rules []Rule
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), rulePrefix) {
+			continue
+		}
+
+		rule := Rule{
+			Name:       strings.TrimPrefix(section.Name(), rulePrefix),
+			PathPrefix: section.Key("path_prefix").String(),
+			Methods:    section.Key("methods").Strings(","),
+			Key:        KeyType(section.Key("key").MustString(string(KeyOrg))),
+			Limit:      section.Key("limit").MustInt64(0),
+		}
+		if rule.PathPrefix == "" {
+			return nil, fmt.Errorf("rate limiting rule %q: path_prefix is required", rule.Name)
+		}
+		switch rule.Key {
+		case KeyOrg, KeyUser, KeyToken, KeyIP:
+		default:
+			return nil, fmt.Errorf("rate limiting rule %q: invalid key %q, must be one of org, user, token or ip", rule.Name, rule.Key)
+		}
+		if rule.Limit <= 0 {
+			return nil, fmt.Errorf("rate limiting rule %q: limit must be greater than 0", rule.Name)
+		}
+		period, err := gtime.ParseDuration(section.Key("period").MustString("1m"))
+		if err != nil {
+			return nil, fmt.Errorf("rate limiting rule %q: invalid period: %w", rule.Name, err)
+		}
+		if period < time.Second {
+			return nil, fmt.Errorf("rate limiting rule %q: period must be at least 1s", rule.Name)
+		}
+		rule.Period = period
+
+		rules = append(rules, rule)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].PathPrefix) > len(rules[j].PathPrefix)
+	})
+	return rules, nil
+}
+
+// Middleware limits the rate of the requests matching a rule, and sets the
+// X-RateLimit headers on their responses. It must run after the context
+// handler so that the requests are authenticated. Requests are allowed if the
+// counters cannot be updated.
+func (l *RateLimiter) Middleware() web.Handler {
+	return func(c *contextmodel.ReqContext) {
+		if !l.enabled || len(l.rules) == 0 || c.SignedInUser == nil {
+			return
+		}
+
+		path := strings.TrimPrefix(c.Req.URL.Path, l.appSubURL)
+		rule, ok := l.match(c.Req.Method, path)
+		if !ok {
+			return
+		}
+
+		subject := l.subject(c, rule.Key)
+		if subject == "" {
+			return
+		}
+
+		now := l.now()
+		windowStart := now.Truncate(rule.Period)
+		reset := windowStart.Add(rule.Period)
+
+		// A zero expiration is the default expiration of the cache.
+		expire := reset.Sub(now)
+		if expire < time.Second {
+			expire = time.Second
+		}
+		count, err := l.cache.Increment(c.Req.Context(), fmt.Sprintf("%s%s-%s-%d", cacheKeyPrefix, rule.Name, subject, windowStart.Unix()), expire)
+		if err != nil {
+			l.log.Warn("Failed to update rate limit counter, allowing the request", "rule", rule.Name, "error", err)
+			return
+		}
+
+		remaining := rule.Limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		header := c.Resp.Header()
+		header.Set(HeaderLimit, strconv.FormatInt(rule.Limit, 10))
+		header.Set(HeaderRemaining, strconv.FormatInt(remaining, 10))
+		header.Set(HeaderReset, strconv.FormatInt(reset.Unix(), 10))
+
+		if count > rule.Limit {
+			metrics.MRateLimitedRequests.WithLabelValues(rule.Name).Inc()
+			header.Set("Retry-After", strconv.FormatInt(int64(reset.Sub(now).Round(time.Second)/time.Second), 10))
+			c.JsonApiErr(http.StatusTooManyRequests, "Rate limit exceeded", nil)
+		}
+	}
+}
+
+func (l *RateLimiter) match(method, path string) (Rule, bool) {
+	for _, rule := range l.rules {
+		if rule.matches(method, path) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// subject returns what the request is counted by, or an empty string if the
+// rule does not apply to the request.
+func (l *RateLimiter) subject(c *contextmodel.ReqContext, key KeyType) string {
+	switch key {
+	case KeyOrg:
+		if orgID := c.SignedInUser.GetOrgID(); orgID > 0 {
+			return "org:" + strconv.FormatInt(orgID, 10)
+		}
+	case KeyUser:
+		return "user:" + c.SignedInUser.GetCacheKey()
+	case KeyToken:
+		if c.SignedInUser.GetAuthenticatedBy() != login.APIKeyAuthModule {
+			return ""
+		}
+		// The tokens of a service account are counted separately.
+		sum := sha256.Sum256([]byte(c.Req.Header.Get("Authorization")))
+		return "token:" + hex.EncodeToString(sum[:8])
+	case KeyIP:
+		if addr := c.RemoteAddr(); addr != "" {
+			return "ip:" + addr
+		}
+	}
+	return ""
+}
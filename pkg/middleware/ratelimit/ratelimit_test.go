@@ -0,0 +1,251 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeCache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{items: map[string][]byte{}}
+}
+
+func (f *fakeCache) Get(_ context.Context, key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return v, nil
+}
+
+func (f *fakeCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[key] = value
+	return nil
+}
+
+func (f *fakeCache) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, key)
+	return nil
+}
+
+func (f *fakeCache) Count(_ context.Context, _ string) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeCache) Increment(_ context.Context, key string, _ time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count, err := strconv.ParseInt(string(f.items[key]), 10, 64)
+	if err != nil {
+		count = 0
+	}
+	count++
+	f.items[key] = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
+func setupRateLimiter(t *testing.T, rules map[string]map[string]string) *RateLimiter {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.Raw.Section("rate_limiting").Key("enabled").SetValue("true")
+	for name, keys := range rules {
+		section := cfg.Raw.Section(rulePrefix + name)
+		for k, v := range keys {
+			section.Key(k).SetValue(v)
+		}
+	}
+
+	l, err := ProvideService(cfg, newFakeCache())
+	require.NoError(t, err)
+	now := time.Date(2023, 10, 1, 12, 0, 10, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l
+}
+
+func serve(l *RateLimiter, method, path string, usr *user.SignedInUser, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	c := &contextmodel.ReqContext{
+		Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(method, rec)},
+		SignedInUser: usr,
+	}
+	l.Middleware().(func(*contextmodel.ReqContext))(c)
+	return rec
+}
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	queries := map[string]string{"path_prefix": "/api/ds/query", "methods": "POST", "key": "org", "limit": "2", "period": "1m"}
+
+	t.Run("limits the requests of an organization and sets the headers", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{"queries": queries})
+		usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+		rec := serve(l, http.MethodPost, "/api/ds/query", usr, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(HeaderLimit))
+		assert.Equal(t, "1", rec.Header().Get(HeaderRemaining))
+		assert.Equal(t, "1696161660", rec.Header().Get(HeaderReset))
+
+		rec = serve(l, http.MethodPost, "/api/ds/query", &user.SignedInUser{UserID: 2, OrgID: 1}, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get(HeaderRemaining))
+
+		rec = serve(l, http.MethodPost, "/api/ds/query", usr, nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get(HeaderRemaining))
+		assert.Equal(t, "50", rec.Header().Get("Retry-After"))
+
+		rec = serve(l, http.MethodPost, "/api/ds/query", &user.SignedInUser{UserID: 3, OrgID: 2}, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("resets the counters in the next period", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{"queries": queries})
+		usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+		start := l.now()
+
+		for i := 0; i < 3; i++ {
+			serve(l, http.MethodPost, "/api/ds/query", usr, nil)
+		}
+		l.now = func() time.Time { return start.Add(time.Minute) }
+		rec := serve(l, http.MethodPost, "/api/ds/query", usr, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get(HeaderRemaining))
+	})
+
+	t.Run("skips the requests not matching a rule", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{"queries": queries})
+		usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+		for _, tc := range []struct{ method, path string }{{http.MethodGet, "/api/ds/query"}, {http.MethodPost, "/api/dashboards/db"}} {
+			rec := serve(l, tc.method, tc.path, usr, nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(HeaderLimit))
+		}
+	})
+
+	t.Run("uses the most specific rule", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{
+			"api":     {"path_prefix": "/api/", "key": "user", "limit": "100"},
+			"queries": queries,
+		})
+		rec := serve(l, http.MethodPost, "/api/ds/query", &user.SignedInUser{UserID: 1, OrgID: 1}, nil)
+		assert.Equal(t, "2", rec.Header().Get(HeaderLimit))
+
+		rec = serve(l, http.MethodGet, "/api/search", &user.SignedInUser{UserID: 1, OrgID: 1}, nil)
+		assert.Equal(t, "100", rec.Header().Get(HeaderLimit))
+	})
+
+	t.Run("limits the requests of a token", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{
+			"tokens": {"path_prefix": "/api/", "key": "token", "limit": "1"},
+		})
+		sa := &user.SignedInUser{UserID: 2, OrgID: 1, IsServiceAccount: true, AuthenticatedBy: login.APIKeyAuthModule}
+
+		rec := serve(l, http.MethodGet, "/api/search", sa, map[string]string{"Authorization": "Bearer glsa_first"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = serve(l, http.MethodGet, "/api/search", sa, map[string]string{"Authorization": "Bearer glsa_first"})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		rec = serve(l, http.MethodGet, "/api/search", sa, map[string]string{"Authorization": "Bearer glsa_second"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// Requests not authenticated with a token are not limited by the rule.
+		usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+		for i := 0; i < 2; i++ {
+			rec = serve(l, http.MethodGet, "/api/search", usr, nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(HeaderLimit))
+		}
+	})
+
+	t.Run("limits the requests of a client IP address", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{
+			"logins": {"path_prefix": "/login", "key": "ip", "limit": "1"},
+		})
+		anonymous := &user.SignedInUser{}
+
+		rec := serve(l, http.MethodPost, "/login", anonymous, map[string]string{"X-Forwarded-For": "198.51.100.1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = serve(l, http.MethodPost, "/login", anonymous, map[string]string{"X-Forwarded-For": "198.51.100.1"})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		rec = serve(l, http.MethodPost, "/login", anonymous, map[string]string{"X-Forwarded-For": "198.51.100.2"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		l := setupRateLimiter(t, map[string]map[string]string{"queries": queries})
+		l.enabled = false
+		rec := serve(l, http.MethodPost, "/api/ds/query", &user.SignedInUser{UserID: 1, OrgID: 1}, nil)
+		assert.Empty(t, rec.Header().Get(HeaderLimit))
+	})
+}
+
+func TestProvideService_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule map[string]string
+		err  string
+	}{
+		{name: "missing path prefix", rule: map[string]string{"limit": "1"}, err: "path_prefix is required"},
+		{name: "invalid key", rule: map[string]string{"path_prefix": "/api/", "key": "session", "limit": "1"}, err: `invalid key "session"`},
+		{name: "missing limit", rule: map[string]string{"path_prefix": "/api/"}, err: "limit must be greater than 0"},
+		{name: "invalid period", rule: map[string]string{"path_prefix": "/api/", "limit": "1", "period": "soon"}, err: "invalid period"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.Raw.Section("rate_limiting").Key("enabled").SetValue("true")
+			section := cfg.Raw.Section(rulePrefix + "test")
+			for k, v := range tc.rule {
+				section.Key(k).SetValue(v)
+			}
+
+			_, err := ProvideService(cfg, newFakeCache())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestTrustsAllAddresses(t *testing.T) {
+	parse := func(cidrs ...string) []*net.IPNet {
+		proxies, err := network.ParseCIDRs(cidrs)
+		require.NoError(t, err)
+		return proxies
+	}
+
+	assert.False(t, trustsAllAddresses(nil))
+	assert.False(t, trustsAllAddresses(parse("10.0.0.0/8", "fd00::/8")))
+	assert.True(t, trustsAllAddresses(parse("10.0.0.0/8", "0.0.0.0/0")))
+	assert.True(t, trustsAllAddresses(parse("::/0")))
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
-	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
@@ -123,16 +122,7 @@ func RequestMetrics(features featuremgmt.FeatureToggles, cfg *setting.Cfg, promR
 
 			elapsedTime := time.Since(now).Seconds()
 
-			if traceID := tracing.TraceIDFromContext(r.Context(), true); traceID != "" {
-				// Need to type-convert the Observer to an
-				// ExemplarObserver. This will always work for a
-				// HistogramVec.
-				histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(
-					elapsedTime, prometheus.Labels{"traceID": traceID},
-				)
-			} else {
-				histogram.Observe(elapsedTime)
-			}
+			metrics.ObserveWithExemplar(r.Context(), histogram, elapsedTime)
 
 			switch {
 			case strings.HasPrefix(r.RequestURI, "/api/datasources/proxy"):
@@ -28,6 +28,7 @@ type UserToken struct {
 	PrevAuthToken string
 	UserAgent     string
 	ClientIp      string
+	DeviceId      string
 	AuthTokenSeen bool
 	SeenAt        int64
 	RotatedAt     int64
@@ -39,7 +39,7 @@ var pluginSet = map[int]goplugin.PluginSet{
 	},
 }
 
-func newClientConfig(executablePath string, args []string, env []string, skipHostEnvVars bool, logger log.Logger,
+func newClientConfig(pluginID string, executablePath string, args []string, env []string, skipHostEnvVars bool, logger log.Logger,
 	versionedPlugins map[int]goplugin.PluginSet) *goplugin.ClientConfig {
 	// We can ignore gosec G201 here, since the dynamic part of executablePath comes from the plugin definition
 	// nolint:gosec
@@ -61,6 +61,7 @@ func newClientConfig(executablePath string, args []string, env []string, skipHos
 				grpc_opentracing.StreamClientInterceptor(),
 			),
 			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithStatsHandler(newMetricsStatsHandler(pluginID)),
 		},
 	}
 }
@@ -0,0 +1,98 @@
+package grpcplugin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	pluginGRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "plugin_grpc_request_duration_seconds",
+		Help:      "Histogram of durations of the unary gRPC calls to backend plugins, such as QueryData and CheckHealth",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100},
+	}, []string{"plugin_id", "method", "code"})
+
+	pluginGRPCStreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "plugin_grpc_stream_duration_seconds",
+		Help:      "Histogram of durations of the streaming gRPC calls to backend plugins, such as CallResource and RunStream, from the call until the end of the stream",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100, 300, 900, 3600},
+	}, []string{"plugin_id", "method", "code"})
+
+	pluginGRPCRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "plugin_grpc_request_size_bytes",
+		Help:      "Histogram of sizes of the messages sent to backend plugins",
+		Buckets:   prometheus.ExponentialBuckets(128, 4, 10),
+	}, []string{"plugin_id", "method"})
+
+	pluginGRPCResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "plugin_grpc_response_size_bytes",
+		Help:      "Histogram of sizes of the messages received from backend plugins",
+		Buckets:   prometheus.ExponentialBuckets(128, 4, 10),
+	}, []string{"plugin_id", "method"})
+)
+
+// metricsStatsHandler is a gRPC stats handler that instruments the calls to a backend plugin.
+// Its metrics are measured on the gRPC connection, so the sizes are the sizes of the encoded messages and the
+// durations include the time spent encoding and decoding them.
+type metricsStatsHandler struct {
+	pluginID string
+}
+
+func newMetricsStatsHandler(pluginID string) *metricsStatsHandler {
+	return &metricsStatsHandler{pluginID: pluginID}
+}
+
+type rpcInfoKey struct{}
+
+// rpcInfo is attached to the context of a call to keep track of its method and whether it is a streaming call.
+type rpcInfo struct {
+	method string
+	stream bool
+}
+
+func (h *metricsStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, rpcInfoKey{}, &rpcInfo{method: methodName(info.FullMethodName)})
+}
+
+func (h *metricsStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	info, ok := ctx.Value(rpcInfoKey{}).(*rpcInfo)
+	if !ok {
+		return
+	}
+
+	switch s := s.(type) {
+	case *stats.Begin:
+		info.stream = s.IsClientStream || s.IsServerStream
+	case *stats.OutPayload:
+		pluginGRPCRequestSize.WithLabelValues(h.pluginID, info.method).Observe(float64(s.Length))
+	case *stats.InPayload:
+		pluginGRPCResponseSize.WithLabelValues(h.pluginID, info.method).Observe(float64(s.Length))
+	case *stats.End:
+		duration := pluginGRPCRequestDuration
+		if info.stream {
+			duration = pluginGRPCStreamDuration
+		}
+		code := status.Code(s.Error).String()
+		duration.WithLabelValues(h.pluginID, info.method, code).Observe(s.EndTime.Sub(s.BeginTime).Seconds())
+	}
+}
+
+func (h *metricsStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *metricsStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// methodName returns the name of the method of a gRPC call, for example QueryData for /pluginv2.Data/QueryData.
+func methodName(fullMethodName string) string {
+	return fullMethodName[strings.LastIndex(fullMethodName, "/")+1:]
+}
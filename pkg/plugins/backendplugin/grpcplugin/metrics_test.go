@@ -0,0 +1,54 @@
+package grpcplugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+func histogram(t *testing.T, vec *prometheus.HistogramVec, labels ...string) *dto.Histogram {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, vec.WithLabelValues(labels...).(prometheus.Histogram).Write(&m))
+	return m.GetHistogram()
+}
+
+func TestMetricsStatsHandler(t *testing.T) {
+	h := newMetricsStatsHandler("test-plugin")
+	begin := time.Now()
+
+	t.Run("unary call", func(t *testing.T) {
+		ctx := h.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: "/pluginv2.Data/QueryData"})
+		h.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: begin})
+		h.HandleRPC(ctx, &stats.OutPayload{Client: true, Length: 100})
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, Length: 2000})
+		h.HandleRPC(ctx, &stats.End{Client: true, BeginTime: begin, EndTime: begin.Add(2 * time.Second)})
+
+		duration := histogram(t, pluginGRPCRequestDuration, "test-plugin", "QueryData", "OK")
+		require.Equal(t, uint64(1), duration.GetSampleCount())
+		require.Equal(t, 2.0, duration.GetSampleSum())
+		require.Equal(t, 100.0, histogram(t, pluginGRPCRequestSize, "test-plugin", "QueryData").GetSampleSum())
+		require.Equal(t, 2000.0, histogram(t, pluginGRPCResponseSize, "test-plugin", "QueryData").GetSampleSum())
+	})
+
+	t.Run("streaming call", func(t *testing.T) {
+		ctx := h.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: "/pluginv2.Stream/RunStream"})
+		h.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: begin, IsServerStream: true})
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, Length: 10})
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, Length: 20})
+		h.HandleRPC(ctx, &stats.End{Client: true, BeginTime: begin, EndTime: begin.Add(time.Minute), Error: status.Error(codes.Unavailable, "")})
+
+		duration := histogram(t, pluginGRPCStreamDuration, "test-plugin", "RunStream", "Unavailable")
+		require.Equal(t, uint64(1), duration.GetSampleCount())
+		require.Equal(t, 60.0, duration.GetSampleSum())
+		require.Equal(t, uint64(2), histogram(t, pluginGRPCResponseSize, "test-plugin", "RunStream").GetSampleCount())
+		require.Zero(t, histogram(t, pluginGRPCRequestDuration, "test-plugin", "RunStream", "Unavailable").GetSampleCount())
+	})
+}
@@ -0,0 +1,168 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupBuilder(t *testing.T) (*AdmissionAPIBuilder, *dashboards.FakeDashboardService) {
+	t.Helper()
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	return &AdmissionAPIBuilder{
+		log:              log.NewNopLogger(),
+		ac:               acimpl.ProvideAccessControl(setting.NewCfg()),
+		dashboardService: dashboardService,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{ID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus", OrgID: 1},
+			{ID: 2, UID: "provisioned", Name: "Provisioned", Type: "prometheus", OrgID: 1, ReadOnly: true},
+		}},
+		pluginStore: &pluginstore.FakePluginStore{PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "prometheus", Type: plugins.TypeDataSource}},
+			{JSONData: plugins.JSONData{ID: "my-app", Type: plugins.TypeApp}},
+		}},
+	}, dashboardService
+}
+
+func review(t *testing.T, b *AdmissionAPIBuilder, validate validateFunc, usr *user.SignedInUser, namespace string, object any) *admissionv1.AdmissionResponse {
+	t.Helper()
+	raw, err := json.Marshal(object)
+	require.NoError(t, err)
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{UID: "review-1", Operation: admissionv1.Create, Object: runtime.RawExtension{Raw: raw}},
+	})
+	require.NoError(t, err)
+
+	ctx := appcontext.WithUser(context.Background(), usr)
+	ctx = request.WithRequestInfo(ctx, &request.RequestInfo{Namespace: namespace})
+	req := httptest.NewRequest(http.MethodPost, "/validate?connectivity=false", bytes.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	b.handleReview(validate)(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var res admissionv1.AdmissionReview
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	require.NotNil(t, res.Response)
+	assert.Equal(t, "review-1", string(res.Response.UID))
+	return res.Response
+}
+
+func dataSource(name string, spec map[string]any) map[string]any {
+	return map[string]any{
+		"apiVersion": "v0-0-alpha",
+		"kind":       "DataSource",
+		"metadata":   map[string]any{"name": name},
+		"spec":       spec,
+	}
+}
+
+func TestValidateDataSource(t *testing.T) {
+	editor := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		datasources.ActionCreate: nil,
+		datasources.ActionWrite:  {datasources.ScopeAll},
+	}}}
+	viewer := &user.SignedInUser{UserID: 2, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		datasources.ActionRead: {datasources.ScopeAll},
+	}}}
+
+	tests := []struct {
+		desc      string
+		usr       *user.SignedInUser
+		namespace string
+		manifest  map[string]any
+		code      int32
+	}{
+		{"new data source", editor, "default", dataSource("loki", map[string]any{"name": "Loki", "type": "prometheus", "url": "http://localhost:9090"}), 0},
+		{"update of a data source", editor, "default", dataSource("prom", map[string]any{"name": "Prometheus", "type": "prometheus", "url": "http://localhost:9090"}), 0},
+		{"unknown type", editor, "default", dataSource("loki", map[string]any{"name": "Loki", "type": "my-app"}), http.StatusUnprocessableEntity},
+		{"invalid access", editor, "default", dataSource("loki", map[string]any{"name": "Loki", "type": "prometheus", "access": "server"}), http.StatusUnprocessableEntity},
+		{"invalid JSON data", editor, "default", dataSource("loki", map[string]any{"name": "Loki", "type": "prometheus", "jsonData": []int{1}}), http.StatusUnprocessableEntity},
+		{"invalid UID", editor, "default", dataSource("not a uid", map[string]any{"name": "Loki", "type": "prometheus", "url": "http://localhost:9090"}), http.StatusUnprocessableEntity},
+		{"name of another data source", editor, "default", dataSource("loki", map[string]any{"name": "Prometheus", "type": "prometheus", "url": "http://localhost:9090"}), http.StatusConflict},
+		{"provisioned data source", editor, "default", dataSource("provisioned", map[string]any{"name": "Provisioned", "type": "prometheus", "url": "http://localhost:9090"}), http.StatusForbidden},
+		{"missing permission", viewer, "default", dataSource("loki", map[string]any{"name": "Loki", "type": "prometheus", "url": "http://localhost:9090"}), http.StatusForbidden},
+		{"namespace of another organization", editor, "org-2", dataSource("loki", map[string]any{"name": "Loki", "type": "prometheus", "url": "http://localhost:9090"}), http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			b, _ := setupBuilder(t)
+			res := review(t, b, b.validateDataSource, tc.usr, tc.namespace, tc.manifest)
+			if tc.code == 0 {
+				assert.True(t, res.Allowed, res.Result)
+				return
+			}
+			assert.False(t, res.Allowed)
+			require.NotNil(t, res.Result)
+			assert.Equal(t, tc.code, res.Result.Code, res.Result.Message)
+		})
+	}
+}
+
+func TestValidateDashboard(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+	manifest := func(spec map[string]any) map[string]any {
+		return map[string]any{
+			"apiVersion": "v0-0-alpha",
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": "abc", "annotations": map[string]string{"grafana.app/folder": "folder-1"}},
+			"spec":       spec,
+		}
+	}
+
+	t.Run("saves the dashboard of the manifest in its folder", func(t *testing.T) {
+		b, dashboardService := setupBuilder(t)
+		dashboardService.On("BuildSaveDashboardCommand", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+			return dto.Overwrite && dto.OrgID == 1 && dto.Dashboard.UID == "abc" && dto.Dashboard.FolderUID == "folder-1" && dto.Dashboard.Title == "Dash"
+		}), mock.Anything, true).Return(&dashboards.SaveDashboardCommand{}, nil).Once()
+
+		res := review(t, b, b.validateDashboard, usr, "default", manifest(map[string]any{"title": "Dash", "panels": []any{}}))
+		assert.True(t, res.Allowed, res.Result)
+	})
+
+	t.Run("rejects the dashboards the service would not save", func(t *testing.T) {
+		b, dashboardService := setupBuilder(t)
+		dashboardService.On("BuildSaveDashboardCommand", mock.Anything, mock.Anything, mock.Anything, true).
+			Return(nil, dashboards.ErrDashboardUpdateAccessDenied).Once()
+
+		res := review(t, b, b.validateDashboard, usr, "default", manifest(map[string]any{"title": "Dash"}))
+		assert.False(t, res.Allowed)
+		assert.Equal(t, int32(http.StatusForbidden), res.Result.Code)
+		assert.Equal(t, metav1.StatusReasonForbidden, res.Result.Reason)
+	})
+
+	t.Run("rejects invalid specs", func(t *testing.T) {
+		b, _ := setupBuilder(t)
+		for _, spec := range []map[string]any{
+			{"title": 1},
+			{"title": "Dash", "panels": map[string]any{}},
+			{"title": "Dash", "panels": []any{"panel"}},
+			{"title": "Dash", "schemaVersion": "39"},
+		} {
+			res := review(t, b, b.validateDashboard, usr, "default", manifest(spec))
+			assert.False(t, res.Allowed, spec)
+			assert.Equal(t, int32(http.StatusUnprocessableEntity), res.Result.Code, spec)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/kinds"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type dashboardManifest = kinds.GrafanaResource[simplejson.Json, any]
+
+// dashboardFields are the JSON types of the top level fields of the
+// dashboard model that are checked, when they are set.
+var dashboardFields = map[string]func(any) bool{
+	"title":         isString,
+	"description":   isString,
+	"schemaVersion": isNumber,
+	"panels":        isArray,
+	"links":         isArray,
+	"tags":          isArray,
+	"annotations":   isObject,
+	"templating":    isObject,
+	"time":          isObject,
+	"timepicker":    isObject,
+	"editable":      isBool,
+	"timezone":      isString,
+}
+
+// validateDashboard admits the manifests of the dashboards that would be
+// saved: the name of the manifest is the UID of the dashboard, and its folder
+// is the grafana.app/folder annotation. The manifests overwrite the stored
+// dashboards like the provisioning does.
+func (b *AdmissionAPIBuilder) validateDashboard(ctx context.Context, usr *user.SignedInUser, req *admissionv1.AdmissionRequest, _ *http.Request) error {
+	var manifest dashboardManifest
+	if err := json.Unmarshal(req.Object.Raw, &manifest); err != nil {
+		return invalid("invalid dashboard manifest: %s", err)
+	}
+	if manifest.Kind != "" && manifest.Kind != "Dashboard" {
+		return invalid("expected a Dashboard manifest, got %s", manifest.Kind)
+	}
+	if manifest.Metadata.Name == "" {
+		return invalid("the name of the dashboard manifest is required")
+	}
+	if manifest.Spec == nil {
+		return invalid("the spec of the dashboard manifest is required")
+	}
+	if err := validateDashboardSpec(manifest.Spec); err != nil {
+		return err
+	}
+
+	spec := manifest.Spec
+	spec.Del("id")
+	spec.Set("uid", manifest.Metadata.Name)
+	dash := dashboards.NewDashboardFromJson(spec)
+	dash.FolderUID = manifest.Metadata.GetFolder()
+
+	// Building the command of the save validates the dashboard, its folder, its
+	// conflicts with the stored dashboards and the permissions of the user.
+	_, err := b.dashboardService.BuildSaveDashboardCommand(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     usr.GetOrgID(),
+		User:      usr,
+		Dashboard: dash,
+		Overwrite: true,
+	}, setting.IsLegacyAlertingEnabled(), true)
+	return err
+}
+
+func validateDashboardSpec(spec *simplejson.Json) error {
+	model, err := spec.Map()
+	if err != nil {
+		return invalid("the spec of the dashboard must be an object")
+	}
+	for field, check := range dashboardFields {
+		if value, ok := model[field]; ok && !check(value) {
+			return invalid("invalid type of the %s field of the dashboard", field)
+		}
+	}
+	if panels, ok := model["panels"].([]any); ok {
+		for i, panel := range panels {
+			if !isObject(panel) {
+				return invalid("panel %d of the dashboard must be an object", i)
+			}
+		}
+	}
+	return nil
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+func isNumber(v any) bool {
+	_, ok := v.(json.Number)
+	if !ok {
+		_, ok = v.(float64)
+	}
+	return ok
+}
+
+func isBool(v any) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+func isArray(v any) bool {
+	_, ok := v.([]any)
+	return ok
+}
+
+func isObject(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
@@ -0,0 +1,159 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/grafana/grafana/pkg/api/datasource"
+	"github.com/grafana/grafana/pkg/kinds"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/adapters"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The spec of the data source manifests has the fields of the body of the
+// data source API.
+type dataSourceManifest = kinds.GrafanaResource[datasources.AddDataSourceCommand, any]
+
+// validateDataSource admits the manifests of the data sources that would be
+// saved: the name of the manifest is the UID of the data source.
+func (b *AdmissionAPIBuilder) validateDataSource(ctx context.Context, usr *user.SignedInUser, req *admissionv1.AdmissionRequest, r *http.Request) error {
+	var manifest dataSourceManifest
+	if err := json.Unmarshal(req.Object.Raw, &manifest); err != nil {
+		return invalid("invalid data source manifest: %s", err)
+	}
+	if manifest.Kind != "" && manifest.Kind != "DataSource" {
+		return invalid("expected a DataSource manifest, got %s", manifest.Kind)
+	}
+	uid := manifest.Metadata.Name
+	if !util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid) {
+		return invalid("the name of the data source manifest must be a valid UID")
+	}
+	spec := manifest.Spec
+	if spec == nil {
+		return invalid("the spec of the data source manifest is required")
+	}
+	if err := b.validateDataSourceSpec(ctx, spec); err != nil {
+		return err
+	}
+
+	existing, err := b.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: usr.GetOrgID()})
+	switch {
+	case err == nil:
+		if existing.ReadOnly {
+			return forbidden("the data source %s is provisioned and cannot be updated", uid)
+		}
+		if err := b.evaluate(ctx, usr, accesscontrol.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(uid))); err != nil {
+			return err
+		}
+	case errors.Is(err, datasources.ErrDataSourceNotFound):
+		if err := b.evaluate(ctx, usr, accesscontrol.EvalPermission(datasources.ActionCreate)); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	sameName, err := b.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{Name: spec.Name, OrgID: usr.GetOrgID()})
+	if err == nil && sameName.UID != uid {
+		return &rejection{code: http.StatusConflict, message: "a data source with the same name already exists"}
+	} else if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return err
+	}
+
+	if r.URL.Query().Get("connectivity") == "true" {
+		return b.checkHealth(ctx, usr, uid, spec)
+	}
+	return nil
+}
+
+func (b *AdmissionAPIBuilder) validateDataSourceSpec(ctx context.Context, spec *datasources.AddDataSourceCommand) error {
+	if spec.Name == "" {
+		return invalid("the name of the data source is required")
+	}
+	if spec.Type == "" {
+		return invalid("the type of the data source is required")
+	}
+	if p, ok := b.pluginStore.Plugin(ctx, spec.Type); !ok || p.Type != plugins.TypeDataSource {
+		return invalid("unknown data source type %s", spec.Type)
+	}
+	switch spec.Access {
+	case "", datasources.DS_ACCESS_PROXY, datasources.DS_ACCESS_DIRECT:
+	default:
+		return invalid("invalid access %s, expected proxy or direct", spec.Access)
+	}
+	if _, err := datasource.ValidateURL(spec.Type, spec.URL); err != nil {
+		return invalid("%s", err)
+	}
+	if spec.JsonData != nil {
+		if _, err := spec.JsonData.Map(); err != nil {
+			return invalid("the JSON data of the data source must be an object")
+		}
+	}
+	return nil
+}
+
+// checkHealth rejects the data sources whose health check fails. The plugins
+// without health checks are admitted.
+func (b *AdmissionAPIBuilder) checkHealth(ctx context.Context, usr *user.SignedInUser, uid string, spec *datasources.AddDataSourceCommand) error {
+	pCtx, err := b.pluginContextProvider.Get(ctx, spec.Type, usr, usr.GetOrgID())
+	if err != nil {
+		return err
+	}
+
+	ds := &datasources.DataSource{
+		OrgID: usr.GetOrgID(),
+		// The UID is not the UID of the stored data source, so that the
+		// plugins do not replace its instance with the one of the manifest.
+		UID:             "admission-" + uid,
+		Name:            spec.Name,
+		Type:            spec.Type,
+		Access:          spec.Access,
+		URL:             spec.URL,
+		User:            spec.User,
+		Database:        spec.Database,
+		BasicAuth:       spec.BasicAuth,
+		BasicAuthUser:   spec.BasicAuthUser,
+		WithCredentials: spec.WithCredentials,
+		JsonData:        spec.JsonData,
+		Updated:         time.Now(),
+	}
+	pCtx.DataSourceInstanceSettings, err = adapters.ModelToInstanceSettings(ds, func(*datasources.DataSource) (map[string]string, error) {
+		return spec.SecureJsonData, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pCtx, Headers: map[string]string{}})
+	if err != nil {
+		if errors.Is(err, plugins.ErrMethodNotImplemented) {
+			return nil
+		}
+		return invalid("the health check of the data source failed: %s", err)
+	}
+	if resp.Status != backend.HealthStatusOk {
+		return invalid("the data source is not healthy: %s", resp.Message)
+	}
+	return nil
+}
+
+func (b *AdmissionAPIBuilder) evaluate(ctx context.Context, usr *user.SignedInUser, evaluator accesscontrol.Evaluator) error {
+	ok, err := b.ac.Evaluate(ctx, usr, evaluator)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return forbidden("missing permission %s", evaluator.String())
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package admission
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/registry/generic"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	common "k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	grafanaapiserver "github.com/grafana/grafana/pkg/services/grafana-apiserver"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+// GroupName is the group name for this API.
+const GroupName = "admission.grafana.app"
+const VersionID = "v0alpha1"
+
+var _ grafanaapiserver.APIGroupBuilder = (*AdmissionAPIBuilder)(nil)
+
+// AdmissionAPIBuilder serves validating admission webhooks for dashboard and
+// data source manifests, so that GitOps controllers can reject the manifests
+// Grafana would not save before applying them.
+type AdmissionAPIBuilder struct {
+	gv  schema.GroupVersion
+	log log.Logger
+
+	ac                    accesscontrol.AccessControl
+	dashboardService      dashboards.DashboardService
+	dataSourceService     datasources.DataSourceService
+	pluginStore           pluginstore.Store
+	pluginContextProvider *plugincontext.Provider
+	pluginClient          plugins.Client
+}
+
+func RegisterAPIService(apiregistration grafanaapiserver.APIRegistrar,
+	ac accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService,
+	pluginStore pluginstore.Store,
+	pluginContextProvider *plugincontext.Provider,
+	pluginClient plugins.Client,
+) *AdmissionAPIBuilder {
+	builder := &AdmissionAPIBuilder{
+		gv:                    schema.GroupVersion{Group: GroupName, Version: VersionID},
+		log:                   log.New("grafana-apiserver.admission"),
+		ac:                    ac,
+		dashboardService:      dashboardService,
+		dataSourceService:     dataSourceService,
+		pluginStore:           pluginStore,
+		pluginContextProvider: pluginContextProvider,
+		pluginClient:          pluginClient,
+	}
+	apiregistration.RegisterAPI(builder)
+	return builder
+}
+
+func (b *AdmissionAPIBuilder) GetGroupVersion() schema.GroupVersion {
+	return b.gv
+}
+
+// InstallSchema does nothing, the group has no kinds.
+func (b *AdmissionAPIBuilder) InstallSchema(scheme *runtime.Scheme) error {
+	return nil
+}
+
+// GetAPIGroupInfo returns nil, the group only has the routes of the webhooks.
+func (b *AdmissionAPIBuilder) GetAPIGroupInfo(
+	scheme *runtime.Scheme,
+	codecs serializer.CodecFactory, // pointer?
+	optsGetter generic.RESTOptionsGetter,
+) (*genericapiserver.APIGroupInfo, error) {
+	return nil, nil
+}
+
+func (b *AdmissionAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
+	return nil
+}
+
+// GetAPIRoutes registers the webhooks under the namespace of the organization
+// of the manifests, for example
+// /apis/admission.grafana.app/v0alpha1/namespaces/default/validate-dashboards
+func (b *AdmissionAPIBuilder) GetAPIRoutes() *grafanaapiserver.APIRoutes {
+	return &grafanaapiserver.APIRoutes{
+		Namespace: []grafanaapiserver.APIRouteHandler{
+			{
+				Path:    "/validate-dashboards",
+				Spec:    webhookSpec("Validate a dashboard manifest", "Validates the schema of the dashboard, its folder, its conflicts with the stored dashboards and the permissions of the caller to save it."),
+				Handler: b.handleReview(b.validateDashboard),
+			},
+			{
+				Path:    "/validate-datasources",
+				Spec:    webhookSpec("Validate a data source manifest", "Validates the type, URL and JSON data of the data source and the permissions of the caller to save it. When the connectivity query parameter is true, the health of the data source is checked too."),
+				Handler: b.handleReview(b.validateDataSource),
+			},
+		},
+	}
+}
+
+func webhookSpec(summary, description string) *spec3.PathProps {
+	review := map[string]*spec3.MediaType{
+		"application/json": {
+			MediaTypeProps: spec3.MediaTypeProps{
+				Schema: &spec.Schema{
+					SchemaProps: spec.SchemaProps{
+						Description: "admission.k8s.io/v1 AdmissionReview",
+						Type:        []string{"object"},
+					},
+				},
+			},
+		},
+	}
+	return &spec3.PathProps{
+		Summary:     summary,
+		Description: description,
+		Post: &spec3.Operation{
+			OperationProps: spec3.OperationProps{
+				Parameters: []*spec3.Parameter{
+					{ParameterProps: spec3.ParameterProps{
+						Name:        "connectivity",
+						In:          "query",
+						Description: "Check the health of the data sources",
+						Schema:      spec.BooleanProperty(),
+					}},
+				},
+				RequestBody: &spec3.RequestBody{
+					RequestBodyProps: spec3.RequestBodyProps{
+						Required: true,
+						Content:  review,
+					},
+				},
+				Responses: &spec3.Responses{
+					ResponsesProps: spec3.ResponsesProps{
+						StatusCodeResponses: map[int]*spec3.Response{
+							200: {
+								ResponseProps: spec3.ResponseProps{
+									Description: "The review with the response",
+									Content:     review,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
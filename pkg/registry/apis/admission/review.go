@@ -0,0 +1,138 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	grafanarequest "github.com/grafana/grafana/pkg/services/grafana-apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// validateFunc validates the manifest of a review. The manifest is admitted
+// if it returns nil.
+type validateFunc func(ctx context.Context, usr *user.SignedInUser, review *admissionv1.AdmissionRequest, r *http.Request) error
+
+// rejection is returned by the validations when the manifest is not admitted.
+type rejection struct {
+	code    int32
+	reason  metav1.StatusReason
+	message string
+}
+
+func (r *rejection) Error() string {
+	return r.message
+}
+
+func invalid(format string, args ...any) error {
+	return &rejection{code: http.StatusUnprocessableEntity, reason: metav1.StatusReasonInvalid, message: fmt.Sprintf(format, args...)}
+}
+
+func forbidden(format string, args ...any) error {
+	return &rejection{code: http.StatusForbidden, reason: metav1.StatusReasonForbidden, message: fmt.Sprintf(format, args...)}
+}
+
+// handleReview decodes the AdmissionReview of the request, validates its
+// manifest, and responds with the same review and the result of the validation.
+func (b *AdmissionAPIBuilder) handleReview(validate validateFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		usr, err := appcontext.User(ctx)
+		if err != nil {
+			http.Error(w, "no signed in user", http.StatusUnauthorized)
+			return
+		}
+
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "expected an admission.k8s.io/v1 AdmissionReview with a request", http.StatusBadRequest)
+			return
+		}
+		req := review.Request
+
+		err = b.checkNamespace(ctx, usr)
+		if err == nil {
+			switch req.Operation {
+			case admissionv1.Create, admissionv1.Update:
+				err = validate(ctx, usr, req, r)
+			}
+		}
+
+		review.Request = nil
+		review.Response = &admissionv1.AdmissionResponse{UID: req.UID, Allowed: err == nil}
+		if err != nil {
+			review.Response.Result = b.toStatus(err)
+		}
+		review.APIVersion = admissionv1.SchemeGroupVersion.String()
+		review.Kind = "AdmissionReview"
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&review); err != nil {
+			b.log.Error("Failed to write admission review", "error", err)
+		}
+	}
+}
+
+// checkNamespace checks that the manifests are validated in the organization
+// of the user.
+func (b *AdmissionAPIBuilder) checkNamespace(ctx context.Context, usr *user.SignedInUser) error {
+	info, ok := request.RequestInfoFrom(ctx)
+	if !ok {
+		return fmt.Errorf("no RequestInfo found in the context")
+	}
+	ns, err := grafanarequest.ParseNamespace(info.Namespace)
+	if err != nil || ns.OrgID < 1 {
+		return invalid("invalid namespace %q", info.Namespace)
+	}
+	if ns.OrgID != usr.GetOrgID() {
+		return forbidden("the namespace %q is not the namespace of the organization of the user", info.Namespace)
+	}
+	return nil
+}
+
+// toStatus converts the error of a validation to the result of the review.
+// The errors of the services are rejections unless they are internal errors.
+func (b *AdmissionAPIBuilder) toStatus(err error) *metav1.Status {
+	var r *rejection
+	var dashboardErr dashboards.DashboardErr
+	var grafanaErr errutil.Error
+	switch {
+	case errors.As(err, &r):
+	case errors.As(err, &dashboardErr) && dashboardErr.StatusCode < http.StatusInternalServerError:
+		r = &rejection{code: int32(dashboardErr.StatusCode), message: dashboardErr.Reason}
+	case errors.As(err, &grafanaErr) && grafanaErr.Reason.Status().HTTPStatus() < http.StatusInternalServerError:
+		public := grafanaErr.Public()
+		r = &rejection{code: int32(public.StatusCode), message: public.Message}
+	default:
+		b.log.Error("Failed to validate manifest", "error", err)
+		r = &rejection{code: http.StatusInternalServerError, reason: metav1.StatusReasonInternalError, message: "internal error"}
+	}
+
+	if r.reason == "" {
+		switch r.code {
+		case http.StatusForbidden:
+			r.reason = metav1.StatusReasonForbidden
+		case http.StatusNotFound:
+			r.reason = metav1.StatusReasonNotFound
+		case http.StatusConflict, http.StatusPreconditionFailed:
+			r.reason = metav1.StatusReasonConflict
+		default:
+			r.reason = metav1.StatusReasonInvalid
+		}
+	}
+	return &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    r.code,
+		Reason:  r.reason,
+		Message: r.message,
+	}
+}
@@ -3,6 +3,7 @@ package backgroundsvcs
 import (
 	"github.com/grafana/grafana/pkg/api"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/metrics/orgmetrics"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	uss "github.com/grafana/grafana/pkg/infra/usagestats/service"
@@ -11,25 +12,39 @@ import (
 	apiregistry "github.com/grafana/grafana/pkg/registry/apis"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl"
+	"github.com/grafana/grafana/pkg/services/apiusage/apiusageimpl"
+	"github.com/grafana/grafana/pkg/services/auditlog/auditlogimpl"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/cleanup"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboardrender"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/datasources/invalidation"
+	"github.com/grafana/grafana/pkg/services/datasources/rotation"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/grafana-apiserver"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/grpcserver/resources"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	ldapapi "github.com/grafana/grafana/pkg/services/ldap/api"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/mqttbridge"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
 	"github.com/grafana/grafana/pkg/services/ngalert"
+	remoteeval "github.com/grafana/grafana/pkg/services/ngalert/eval/remote"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angulardetectorsprovider"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/dsquerier"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/keyretriever/dynamic"
 	pluginStore "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	publicdashboardsmetric "github.com/grafana/grafana/pkg/services/publicdashboards/metric"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/queryaudit/queryauditimpl"
+	"github.com/grafana/grafana/pkg/services/recordedqueries"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/savedsearch/savedsearchimpl"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	secretsMigrations "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -47,7 +62,7 @@ import (
 func ProvideBackgroundServiceRegistry(
 	httpServer *api.HTTPServer, ng *ngalert.AlertNG, cleanup *cleanup.CleanUpService, live *live.GrafanaLive,
 	pushGateway *pushhttp.Gateway, notifications *notifications.NotificationService, pluginStore *pluginStore.Service,
-	rendering *rendering.RenderingService, tokenService auth.UserTokenBackgroundService, tracing *tracing.TracingService,
+	rendering *rendering.RenderingService, tracing *tracing.TracingService,
 	provisioning *provisioning.ProvisioningServiceImpl, alerting *alerting.AlertEngine, usageStats *uss.UsageStats,
 	statsCollector *statscollector.Service, grafanaUpdateChecker *updatechecker.GrafanaService,
 	pluginsUpdateChecker *updatechecker.PluginsService, metrics *metrics.InternalMetricsService,
@@ -57,15 +72,22 @@ func ProvideBackgroundServiceRegistry(
 	bundleService *supportbundlesimpl.Service, publicDashboardsMetric *publicdashboardsmetric.Service,
 	keyRetriever *dynamic.KeyRetriever, dynamicAngularDetectorsProvider *angulardetectorsprovider.Dynamic,
 	grafanaAPIServer grafanaapiserver.Service,
-	anon *anonimpl.AnonDeviceService,
+	anon *anonimpl.AnonDeviceService, dataSourceRotation *rotation.Service, recordedQueries *recordedqueries.Service,
+	mqttBridge *mqttbridge.Service, dashboardArchive *dashboardarchive.Service,
+	auditLog *auditlogimpl.Service, queryAudit *queryauditimpl.Service, dataSourceInvalidation *invalidation.Service,
+	orgMetrics *orgmetrics.Service,
+	apiUsage *apiusageimpl.Service,
+	savedSearches *savedsearchimpl.Service,
+	queryService *query.ServiceImpl,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ dashboardsnapshots.Service, _ *alerting.AlertNotificationService,
 	_ serviceaccounts.Service, _ *guardian.Provider,
 	_ *plugindashboardsservice.DashboardUpdater, _ *sanitizer.Provider,
 	_ *grpcserver.HealthService, _ entity.EntityStoreServer, _ *grpcserver.ReflectionService, _ *ldapapi.Service,
 	_ *apiregistry.Service, _ auth.IDService, _ *teamapi.TeamAPI, _ ssosettings.Service,
+	_ *dsquerier.Service, _ *resources.Service, _ *remoteeval.RunnerService, _ *dashboardrender.Service,
 ) *BackgroundServiceRegistry {
-	return NewBackgroundServiceRegistry(
+	r := NewBackgroundServiceRegistry(
 		httpServer,
 		ng,
 		cleanup,
@@ -73,7 +95,6 @@ func ProvideBackgroundServiceRegistry(
 		pushGateway,
 		notifications,
 		rendering,
-		tokenService,
 		provisioning,
 		alerting,
 		grafanaUpdateChecker,
@@ -98,18 +119,50 @@ func ProvideBackgroundServiceRegistry(
 		dynamicAngularDetectorsProvider,
 		grafanaAPIServer,
 		anon,
+		dataSourceRotation,
+		recordedQueries,
+		mqttBridge,
+		dashboardArchive,
+		auditLog,
+		queryAudit,
+		dataSourceInvalidation,
+		orgMetrics,
+		apiUsage,
+		savedSearches,
+		queryService,
 	)
+
+	// Database migrations run when the SQL store is created, before any background service is started.
+	r.DependsOn(httpServer, remoteCache)
+	r.DependsOn(usageStats, statsCollector)
+	r.DependsOn(searchService, entityEventsService)
+
+	return r
 }
 
 // BackgroundServiceRegistry provides background services.
 type BackgroundServiceRegistry struct {
-	Services []registry.BackgroundService
+	Services     []registry.BackgroundService
+	dependencies map[registry.BackgroundService][]registry.BackgroundService
 }
 
 func NewBackgroundServiceRegistry(services ...registry.BackgroundService) *BackgroundServiceRegistry {
-	return &BackgroundServiceRegistry{services}
+	return &BackgroundServiceRegistry{
+		Services:     services,
+		dependencies: make(map[registry.BackgroundService][]registry.BackgroundService),
+	}
 }
 
 func (r *BackgroundServiceRegistry) GetServices() []registry.BackgroundService {
 	return r.Services
 }
+
+// DependsOn declares that the service is only started once the dependencies are ready.
+// Dependencies that are disabled do not delay the start of the service.
+func (r *BackgroundServiceRegistry) DependsOn(service registry.BackgroundService, dependencies ...registry.BackgroundService) {
+	r.dependencies[service] = append(r.dependencies[service], dependencies...)
+}
+
+func (r *BackgroundServiceRegistry) GetDependencies(service registry.BackgroundService) []registry.BackgroundService {
+	return r.dependencies[service]
+}
@@ -0,0 +1,65 @@
+// Package readiness tracks the readiness of the background services of the server.
+package readiness
+
+import (
+	"sync"
+)
+
+type State string
+
+const (
+	// StateWaiting is the state of services waiting for their dependencies to be ready.
+	StateWaiting State = "waiting"
+	// StateStarting is the state of services that are started but not ready yet.
+	StateStarting State = "starting"
+	StateReady    State = "ready"
+	// StateFailed is the state of services that stopped with an error.
+	StateFailed  State = "failed"
+	StateStopped State = "stopped"
+)
+
+// Status is the readiness of a background service.
+type Status struct {
+	State State `json:"state"`
+	// Reason explains why the service is not ready, ex. the dependency it is waiting for or the error it failed with.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Tracker tracks the readiness of the background services by name.
+type Tracker struct {
+	mtx      sync.RWMutex
+	statuses map[string]Status
+}
+
+func ProvideTracker() *Tracker {
+	return &Tracker{statuses: make(map[string]Status)}
+}
+
+func (t *Tracker) Set(service string, state State, reason string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.statuses[service] = Status{State: state, Reason: reason}
+}
+
+// Statuses returns the readiness of the tracked background services by name.
+func (t *Tracker) Statuses() map[string]Status {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	statuses := make(map[string]Status, len(t.statuses))
+	for name, status := range t.statuses {
+		statuses[name] = status
+	}
+	return statuses
+}
+
+// Ready returns true if all the tracked background services are ready.
+func (t *Tracker) Ready() bool {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	for _, status := range t.statuses {
+		if status.State != StateReady {
+			return false
+		}
+	}
+	return true
+}
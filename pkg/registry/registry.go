@@ -11,6 +11,22 @@ type BackgroundServiceRegistry interface {
 	GetServices() []BackgroundService
 }
 
+// BackgroundServiceDependencies is implemented by background service registries
+// that declare which background services have to be ready before another one
+// is started, ex. the remote cache before the HTTP server.
+type BackgroundServiceDependencies interface {
+	// GetDependencies returns the background services the service depends on.
+	GetDependencies(service BackgroundService) []BackgroundService
+}
+
+// CanBeReady allows background services that are not ready as soon as they
+// are started, ex. because they load state first, to tell when they are ready.
+// Other services are ready once they are started.
+type CanBeReady interface {
+	// Ready returns a channel that is closed once the service is ready.
+	Ready() <-chan struct{}
+}
+
 // CanBeDisabled allows the services to decide if it should
 // be started or not by itself. This is useful for services
 // that might not always be started, ex alerting.
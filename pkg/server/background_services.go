@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/registry/backgroundsvcs/readiness"
+)
+
+// backgroundService is a background service together with the background
+// services it waits for before being started.
+type backgroundService struct {
+	registry.BackgroundService
+	name         string
+	dependencies []*backgroundService
+
+	// ready is closed once the service is ready or stopped.
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+func (b *backgroundService) markReady(tracker *readiness.Tracker) {
+	b.readyOnce.Do(func() {
+		tracker.Set(b.name, readiness.StateReady, "")
+		close(b.ready)
+	})
+}
+
+func (b *backgroundService) markStopped() {
+	b.readyOnce.Do(func() {
+		close(b.ready)
+	})
+}
+
+// newBackgroundServices resolves the dependencies between the background
+// services. Dependencies that are disabled are ignored.
+func (s *Server) newBackgroundServices() ([]*backgroundService, error) {
+	services := make([]*backgroundService, 0, len(s.backgroundServices))
+	byService := make(map[registry.BackgroundService]*backgroundService, len(s.backgroundServices))
+	for _, svc := range s.backgroundServices {
+		service := &backgroundService{
+			BackgroundService: svc,
+			name:              reflect.TypeOf(svc).String(),
+			ready:             make(chan struct{}),
+		}
+		services = append(services, service)
+		byService[svc] = service
+	}
+
+	if s.serviceDependencies == nil {
+		return services, nil
+	}
+
+	for _, service := range services {
+		for _, dep := range s.serviceDependencies.GetDependencies(service.BackgroundService) {
+			dependency, ok := byService[dep]
+			if !ok {
+				return nil, fmt.Errorf("background service %s depends on %s which is not registered", service.name, reflect.TypeOf(dep).String())
+			}
+			if registry.IsDisabled(dep) {
+				continue
+			}
+			service.dependencies = append(service.dependencies, dependency)
+		}
+	}
+
+	visited := make(map[*backgroundService]bool, len(services))
+	for _, service := range services {
+		if err := checkDependencyCycle(service, visited, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return services, nil
+}
+
+// checkDependencyCycle returns an error if the service transitively depends on itself.
+func checkDependencyCycle(service *backgroundService, visited map[*backgroundService]bool, path []*backgroundService) error {
+	for i, p := range path {
+		if p == service {
+			names := make([]string, 0, len(path)-i+1)
+			for _, c := range path[i:] {
+				names = append(names, c.name)
+			}
+			names = append(names, service.name)
+			return fmt.Errorf("background services have a dependency cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+	if visited[service] {
+		return nil
+	}
+
+	path = append(path, service)
+	for _, dependency := range service.dependencies {
+		if err := checkDependencyCycle(dependency, visited, path); err != nil {
+			return err
+		}
+	}
+	visited[service] = true
+	return nil
+}
@@ -7,7 +7,6 @@ import (
 	"net"
 	"os"
 	"path/filepath"
-	"reflect"
 	"strconv"
 	"sync"
 
@@ -21,7 +20,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/registry/backgroundsvcs/readiness"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -40,10 +41,10 @@ type Options struct {
 func New(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistry accesscontrol.RoleRegistry,
 	provisioningService provisioning.ProvisioningService, backgroundServiceProvider registry.BackgroundServiceRegistry,
 	usageStatsProvidersRegistry registry.UsageStatsProvidersRegistry, statsCollectorService *statscollector.Service,
-	promReg prometheus.Registerer,
+	promReg prometheus.Registerer, serviceReadiness *readiness.Tracker, drainService *drain.Service,
 ) (*Server, error) {
 	statsCollectorService.RegisterProviders(usageStatsProvidersRegistry.GetServices())
-	s, err := newServer(opts, cfg, httpServer, roleRegistry, provisioningService, backgroundServiceProvider, promReg)
+	s, err := newServer(opts, cfg, httpServer, roleRegistry, provisioningService, backgroundServiceProvider, promReg, serviceReadiness, drainService)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +58,7 @@ func New(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistr
 
 func newServer(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistry accesscontrol.RoleRegistry,
 	provisioningService provisioning.ProvisioningService, backgroundServiceProvider registry.BackgroundServiceRegistry,
-	promReg prometheus.Registerer,
+	promReg prometheus.Registerer, serviceReadiness *readiness.Tracker, drainService *drain.Service,
 ) (*Server, error) {
 	rootCtx, shutdownFn := context.WithCancel(context.Background())
 	childRoutines, childCtx := errgroup.WithContext(rootCtx)
@@ -78,6 +79,12 @@ func newServer(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleR
 		commit:              opts.Commit,
 		buildBranch:         opts.BuildBranch,
 		backgroundServices:  backgroundServiceProvider.GetServices(),
+		serviceReadiness:    serviceReadiness,
+		drainService:        drainService,
+	}
+
+	if dependencies, ok := backgroundServiceProvider.(registry.BackgroundServiceDependencies); ok {
+		s.serviceDependencies = dependencies
 	}
 
 	return s, nil
@@ -102,6 +109,10 @@ type Server struct {
 	commit             string
 	buildBranch        string
 	backgroundServices []registry.BackgroundService
+	// serviceDependencies declares the start-up order of the background services, it may be nil.
+	serviceDependencies registry.BackgroundServiceDependencies
+	serviceReadiness    *readiness.Tracker
+	drainService        *drain.Service
 
 	HTTPServer          *api.HTTPServer
 	roleRegistry        accesscontrol.RoleRegistry
@@ -143,32 +154,63 @@ func (s *Server) Run() error {
 		return err
 	}
 
-	services := s.backgroundServices
+	services, err := s.newBackgroundServices()
+	if err != nil {
+		return err
+	}
 
 	// Start background services.
 	for _, svc := range services {
-		if registry.IsDisabled(svc) {
+		if registry.IsDisabled(svc.BackgroundService) {
 			continue
 		}
 
 		service := svc
-		serviceName := reflect.TypeOf(service).String()
+		s.serviceReadiness.Set(service.name, readiness.StateWaiting, "")
 		s.childRoutines.Go(func() error {
+			for _, dependency := range service.dependencies {
+				s.serviceReadiness.Set(service.name, readiness.StateWaiting, "waiting for "+dependency.name)
+				select {
+				case <-s.context.Done():
+					s.serviceReadiness.Set(service.name, readiness.StateStopped, "")
+					return s.context.Err()
+				case <-dependency.ready:
+				}
+			}
+
 			select {
 			case <-s.context.Done():
+				s.serviceReadiness.Set(service.name, readiness.StateStopped, "")
 				return s.context.Err()
 			default:
 			}
-			s.log.Debug("Starting background service", "service", serviceName)
+			s.log.Debug("Starting background service", "service", service.name)
+			s.serviceReadiness.Set(service.name, readiness.StateStarting, "")
+			if r, ok := service.BackgroundService.(registry.CanBeReady); ok {
+				go func() {
+					select {
+					case <-r.Ready():
+						service.markReady(s.serviceReadiness)
+					case <-service.ready:
+					}
+				}()
+			} else {
+				service.markReady(s.serviceReadiness)
+			}
+
 			err := service.Run(s.context)
+			// Services that stopped do not block the services depending on them.
+			service.markStopped()
 			// Do not return context.Canceled error since errgroup.Group only
 			// returns the first error to the caller - thus we can miss a more
 			// interesting error.
 			if err != nil && !errors.Is(err, context.Canceled) {
-				s.log.Error("Stopped background service", "service", serviceName, "reason", err)
-				return fmt.Errorf("%s run error: %w", serviceName, err)
+				s.log.Error("Stopped background service", "service", service.name, "reason", err)
+				s.serviceReadiness.Set(service.name, readiness.StateFailed, err.Error())
+				return fmt.Errorf("%s run error: %w", service.name, err)
 			}
-			s.log.Debug("Stopped background service", "service", serviceName, "reason", err)
+			s.log.Debug("Stopped background service", "service", service.name, "reason", err)
+			s.serviceReadiness.Set(service.name, readiness.StateStopped, "")
 			return nil
 		})
 	}
@@ -179,6 +221,13 @@ func (s *Server) Run() error {
 	return s.childRoutines.Wait()
 }
 
+// Drain stops accepting new long-lived connections and gives in-flight queries
+// a deadline to finish, so that the server can be restarted without
+// interrupting users.
+func (s *Server) Drain(reason string) {
+	s.drainService.Drain(reason)
+}
+
 // Shutdown initiates Grafana graceful shutdown. This shuts down all
 // running background services. Since Run blocks Shutdown supposed to
 // be run from a separate goroutine.
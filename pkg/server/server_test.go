@@ -12,7 +12,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/registry/backgroundsvcs"
+	"github.com/grafana/grafana/pkg/registry/backgroundsvcs/readiness"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -49,7 +51,12 @@ func (s *testService) IsDisabled() bool {
 
 func testServer(t *testing.T, services ...registry.BackgroundService) *Server {
 	t.Helper()
-	s, err := newServer(Options{}, setting.NewCfg(), nil, &acimpl.Service{}, nil, backgroundsvcs.NewBackgroundServiceRegistry(services...), prometheus.NewRegistry())
+	return testServerWithRegistry(t, backgroundsvcs.NewBackgroundServiceRegistry(services...))
+}
+
+func testServerWithRegistry(t *testing.T, backgroundServices *backgroundsvcs.BackgroundServiceRegistry) *Server {
+	t.Helper()
+	s, err := newServer(Options{}, setting.NewCfg(), nil, &acimpl.Service{}, nil, backgroundServices, prometheus.NewRegistry(), readiness.ProvideTracker(), drain.ProvideService(setting.NewCfg()))
 	require.NoError(t, err)
 	// Required to skip configuration initialization that causes
 	// DI errors in this test.
@@ -91,3 +98,72 @@ func TestServer_Shutdown(t *testing.T) {
 	err = <-ch
 	require.NoError(t, err)
 }
+
+// readyTestService only becomes ready once ready is closed.
+type readyTestService struct {
+	started chan struct{}
+	ready   chan struct{}
+}
+
+func (s *readyTestService) Run(ctx context.Context) error {
+	close(s.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *readyTestService) Ready() <-chan struct{} {
+	return s.ready
+}
+
+func TestServer_Run_Dependencies(t *testing.T) {
+	dependency := &readyTestService{started: make(chan struct{}), ready: make(chan struct{})}
+	dependent := newTestService(nil, false)
+	disabled := newTestService(nil, true)
+	backgroundServices := backgroundsvcs.NewBackgroundServiceRegistry(dependent, disabled, dependency)
+	backgroundServices.DependsOn(dependent, dependency, disabled)
+	s := testServerWithRegistry(t, backgroundServices)
+
+	ch := make(chan error)
+	go func() {
+		ch <- s.Run()
+	}()
+
+	<-dependency.started
+	require.Eventually(t, func() bool {
+		return s.serviceReadiness.Statuses()["*server.testService"] == readiness.Status{
+			State:  readiness.StateWaiting,
+			Reason: "waiting for *server.readyTestService",
+		}
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-dependent.started:
+		t.Fatal("service started before its dependency was ready")
+	default:
+	}
+
+	close(dependency.ready)
+	<-dependent.started
+	require.Eventually(t, s.serviceReadiness.Ready, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Shutdown(context.Background(), "test interrupt"))
+	require.NoError(t, <-ch)
+}
+
+func TestServer_Run_DependencyCycle(t *testing.T) {
+	a, b := newTestService(nil, false), &readyTestService{started: make(chan struct{}), ready: make(chan struct{})}
+	backgroundServices := backgroundsvcs.NewBackgroundServiceRegistry(a, b)
+	backgroundServices.DependsOn(a, b)
+	backgroundServices.DependsOn(b, a)
+	s := testServerWithRegistry(t, backgroundServices)
+
+	err := s.Run()
+	require.ErrorContains(t, err, "dependency cycle")
+}
+
+func TestServer_Run_FailureReason(t *testing.T) {
+	s := testServer(t, newTestService(errors.New("boom"), false))
+	err := s.Run()
+	require.Error(t, err)
+
+	require.Equal(t, readiness.Status{State: readiness.StateFailed, Reason: "boom"}, s.serviceReadiness.Statuses()["*server.testService"])
+}
@@ -10,9 +10,10 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
-	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
@@ -45,7 +46,7 @@ var (
 func ProvideTeamPermissions(
 	features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB,
 	ac accesscontrol.AccessControl, license licensing.Licensing, service accesscontrol.Service,
-	teamService team.Service, userService user.Service,
+	teamService team.Service, userService user.Service, auditLogService auditlog.Service,
 ) (*TeamPermissionsService, error) {
 	options := resourcepermissions.Options{
 		Resource:          "teams",
@@ -101,7 +102,7 @@ func ProvideTeamPermissions(
 		},
 	}
 
-	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService)
+	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService, auditLogService)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +120,7 @@ var DashboardAdminActions = append(DashboardEditActions, []string{dashboards.Act
 func ProvideDashboardPermissions(
 	features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
 	license licensing.Licensing, dashboardStore dashboards.Store, folderService folder.Service, service accesscontrol.Service,
-	teamService team.Service, userService user.Service,
+	teamService team.Service, userService user.Service, auditLogService auditlog.Service,
 ) (*DashboardPermissionsService, error) {
 	getDashboard := func(ctx context.Context, orgID int64, resourceID string) (*dashboards.Dashboard, error) {
 		query := &dashboards.GetDashboardQuery{UID: resourceID, OrgID: orgID}
@@ -183,7 +184,7 @@ func ProvideDashboardPermissions(
 		RoleGroup:      "Dashboards",
 	}
 
-	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService)
+	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService, auditLogService)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +212,7 @@ var FolderAdminActions = append(FolderEditActions, []string{dashboards.ActionFol
 func ProvideFolderPermissions(
 	features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, accesscontrol accesscontrol.AccessControl,
 	license licensing.Licensing, dashboardStore dashboards.Store, folderService folder.Service, service accesscontrol.Service,
-	teamService team.Service, userService user.Service,
+	teamService team.Service, userService user.Service, auditLogService auditlog.Service,
 ) (*FolderPermissionsService, error) {
 	options := resourcepermissions.Options{
 		Resource:          "folders",
@@ -247,48 +248,68 @@ func ProvideFolderPermissions(
 		WriterRoleName: "Folder permission writer",
 		RoleGroup:      "Folders",
 	}
-	srv, err := resourcepermissions.New(options, features, router, license, accesscontrol, service, sql, teamService, userService)
+	srv, err := resourcepermissions.New(options, features, router, license, accesscontrol, service, sql, teamService, userService, auditLogService)
 	if err != nil {
 		return nil, err
 	}
 	return &FolderPermissionsService{srv}, nil
 }
 
-func ProvideDatasourcePermissionsService() *DatasourcePermissionsService {
-	return &DatasourcePermissionsService{}
-}
+var (
+	DatasourceReadActions  = []string{datasources.ActionRead}
+	DatasourceQueryActions = append(DatasourceReadActions, datasources.ActionQuery)
+	DatasourceEditActions  = append(DatasourceQueryActions, []string{datasources.ActionWrite, datasources.ActionDelete}...)
+	DatasourceAdminActions = append(DatasourceEditActions, []string{datasources.ActionPermissionsRead, datasources.ActionPermissionsWrite}...)
+)
 
 var _ accesscontrol.DatasourcePermissionsService = new(DatasourcePermissionsService)
 
-type DatasourcePermissionsService struct{}
-
-func (e DatasourcePermissionsService) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
-	return nil, nil
-}
-
-func (e DatasourcePermissionsService) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
-	return nil, nil
-}
-
-func (e DatasourcePermissionsService) SetTeamPermission(ctx context.Context, orgID, teamID int64, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
-	return nil, nil
-}
-
-func (e DatasourcePermissionsService) SetBuiltInRolePermission(ctx context.Context, orgID int64, builtInRole string, resourceID string, permission string) (*accesscontrol.ResourcePermission, error) {
-	return nil, nil
-}
-
-func (e DatasourcePermissionsService) SetPermissions(ctx context.Context, orgID int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
-	return nil, nil
+type DatasourcePermissionsService struct {
+	*resourcepermissions.Service
 }
 
-func (e DatasourcePermissionsService) DeleteResourcePermissions(ctx context.Context, orgID int64, resourceID string) error {
-	// TODO: implement
-	return nil
-}
+func ProvideDatasourcePermissionsService(
+	features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
+	license licensing.Licensing, service accesscontrol.Service, teamService team.Service, userService user.Service,
+	auditLogService auditlog.Service,
+) (*DatasourcePermissionsService, error) {
+	options := resourcepermissions.Options{
+		Resource:          "datasources",
+		ResourceAttribute: "uid",
+		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
+			return sql.WithDbSession(ctx, func(sess *db.Session) error {
+				exists, err := sess.Table("data_source").Where("org_id = ? AND uid = ?", orgID, resourceID).Exist()
+				if err != nil {
+					return err
+				}
+				if !exists {
+					return datasources.ErrDataSourceNotFound
+				}
+				return nil
+			})
+		},
+		Assignments: resourcepermissions.Assignments{
+			Users:           true,
+			Teams:           true,
+			BuiltInRoles:    true,
+			ServiceAccounts: true,
+		},
+		PermissionsToActions: map[string][]string{
+			"Read":  DatasourceReadActions,
+			"Query": DatasourceQueryActions,
+			"Edit":  DatasourceEditActions,
+			"Admin": DatasourceAdminActions,
+		},
+		ReaderRoleName: "Data source permission reader",
+		WriterRoleName: "Data source permission writer",
+		RoleGroup:      "Data sources",
+	}
 
-func (e DatasourcePermissionsService) MapActions(permission accesscontrol.ResourcePermission) string {
-	return ""
+	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService, auditLogService)
+	if err != nil {
+		return nil, err
+	}
+	return &DatasourcePermissionsService{srv}, nil
 }
 
 var (
@@ -312,7 +333,7 @@ type ServiceAccountPermissionsService struct {
 func ProvideServiceAccountPermissions(
 	features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
 	license licensing.Licensing, serviceAccountRetrieverService *retriever.Service, service accesscontrol.Service,
-	teamService team.Service, userService user.Service,
+	teamService team.Service, userService user.Service, auditLogService auditlog.Service,
 ) (*ServiceAccountPermissionsService, error) {
 	options := resourcepermissions.Options{
 		Resource:          "serviceaccounts",
@@ -339,7 +360,7 @@ func ProvideServiceAccountPermissions(
 		RoleGroup:      "Service accounts",
 	}
 
-	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService)
+	srv, err := resourcepermissions.New(options, features, router, license, ac, service, sql, teamService, userService, auditLogService)
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/web"
@@ -162,6 +163,7 @@ func (a *api) setUserPermission(c *contextmodel.ReqContext) response.Response {
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "failed to set user permission", err)
 	}
+	a.recordPermissionsUpdate(c, resourceID, map[string]any{"userId": userID, "permission": cmd.Permission})
 
 	return permissionSetResponse(cmd)
 }
@@ -182,6 +184,7 @@ func (a *api) setTeamPermission(c *contextmodel.ReqContext) response.Response {
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "failed to set team permission", err)
 	}
+	a.recordPermissionsUpdate(c, resourceID, map[string]any{"teamId": teamID, "permission": cmd.Permission})
 
 	return permissionSetResponse(cmd)
 }
@@ -199,6 +202,7 @@ func (a *api) setBuiltinRolePermission(c *contextmodel.ReqContext) response.Resp
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "failed to set role permission", err)
 	}
+	a.recordPermissionsUpdate(c, resourceID, map[string]any{"builtInRole": builtInRole, "permission": cmd.Permission})
 
 	return permissionSetResponse(cmd)
 }
@@ -215,10 +219,24 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "failed to set permissions", err)
 	}
+	a.recordPermissionsUpdate(c, resourceID, map[string]any{"permissions": cmd.Permissions})
 
 	return response.Success("Permissions updated")
 }
 
+// recordPermissionsUpdate records the permission change in the audit log.
+func (a *api) recordPermissionsUpdate(c *contextmodel.ReqContext, resourceID string, after any) {
+	a.service.auditLogService.Record(c.Req.Context(), &auditlog.RecordCommand{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		Actor:        c.SignedInUser,
+		IPAddress:    c.RemoteAddr(),
+		Action:       auditlog.ActionPermissionsUpdate,
+		ResourceType: a.service.options.Resource,
+		ResourceID:   resourceID,
+		After:        after,
+	})
+}
+
 func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	message := "Permission updated"
 	if cmd.Permission == "" {
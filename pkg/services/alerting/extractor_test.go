@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/alerting/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -32,7 +33,7 @@ func TestAlertRuleExtraction(t *testing.T) {
 	json, err := os.ReadFile("./testdata/graphite-alert.json")
 	require.Nil(t, err)
 
-	dsGuardian := guardian.ProvideGuardian()
+	dsGuardian := guardian.ProvideGuardian(setting.NewCfg(), actest.FakeAccessControl{})
 
 	dsService := &fakeDatasourceService{ExpectedDatasource: defaultDs}
 	db := dbtest.NewFakeDB()
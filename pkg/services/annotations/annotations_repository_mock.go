@@ -72,6 +72,27 @@ func (_m *FakeAnnotationsRepo) FindTags(ctx context.Context, query *TagsQuery) (
 	return r0, r1
 }
 
+// FindTagStats provides a mock function with given fields: ctx, query
+func (_m *FakeAnnotationsRepo) FindTagStats(ctx context.Context, query *TagStatsQuery) (FindTagStatsResult, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 FindTagStatsResult
+	if rf, ok := ret.Get(0).(func(context.Context, *TagStatsQuery) FindTagStatsResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(FindTagStatsResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *TagStatsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, item
 func (_m *FakeAnnotationsRepo) Save(ctx context.Context, item *Item) error {
 	ret := _m.Called(ctx, item)
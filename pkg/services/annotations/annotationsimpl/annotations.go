@@ -66,3 +66,12 @@ func (r *RepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteP
 func (r *RepositoryImpl) FindTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	return r.store.GetTags(ctx, query)
 }
+
+func (r *RepositoryImpl) FindTagStats(ctx context.Context, query *annotations.TagStatsQuery) (annotations.FindTagStatsResult, error) {
+	resources, err := r.authZ.Authorize(ctx, query.OrgID, query.SignedInUser)
+	if err != nil {
+		return annotations.FindTagStatsResult{Tags: []*annotations.TagStatsDTO{}}, err
+	}
+
+	return r.store.GetTagStats(ctx, query, resources)
+}
@@ -2,6 +2,7 @@ package annotationsimpl
 
 import (
 	"context"
+	"errors"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -25,34 +26,39 @@ const (
 	apiAnnotationType       = "alert_id = 0 AND dashboard_id = 0"
 )
 
-// Run deletes old annotations created by alert rules, API
+// Run maintains the partitions of the annotation table when partitioning is
+// enabled, and deletes old annotations created by alert rules, API
 // requests and human made in the UI. It subsequently deletes orphaned rows
 // from the annotation_tag table. Cleanup actions are performed in batches
 // so that no query takes too long to complete.
 //
 // Returns the number of annotation and annotation_tag rows deleted. If an
-// error occurs, it returns the number of rows affected so far.
+// error occurs, it returns the number of rows affected so far. A failure to
+// maintain the partitions does not prevent the cleanup.
 func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64, int64, error) {
+	dropped, partitionErr := cs.store.MaintainPartitions(ctx, cfg.AnnotationPartitioning)
+
 	var totalCleanedAnnotations int64
 	affected, err := cs.store.CleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
-		return totalCleanedAnnotations, 0, err
+		return totalCleanedAnnotations, 0, errors.Join(partitionErr, err)
 	}
 
 	affected, err = cs.store.CleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
-		return totalCleanedAnnotations, 0, err
+		return totalCleanedAnnotations, 0, errors.Join(partitionErr, err)
 	}
 
 	affected, err = cs.store.CleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
-		return totalCleanedAnnotations, 0, err
+		return totalCleanedAnnotations, 0, errors.Join(partitionErr, err)
 	}
-	if totalCleanedAnnotations > 0 {
+	// The tags of the annotations of the dropped partitions are orphaned too.
+	if totalCleanedAnnotations > 0 || dropped > 0 {
 		affected, err = cs.store.CleanOrphanedAnnotationTags(ctx)
 	}
-	return totalCleanedAnnotations, affected, err
+	return totalCleanedAnnotations, affected, errors.Join(partitionErr, err)
 }
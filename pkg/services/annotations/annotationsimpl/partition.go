@@ -0,0 +1,232 @@
+package annotationsimpl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// legacyPartition holds the annotations that ended before the annotation table was partitioned.
+	legacyPartition = "annotation_p_legacy"
+	// defaultPartition holds the annotations that end after the last partition.
+	defaultPartition = "annotation_p_default"
+	partitionPrefix  = "annotation_p"
+)
+
+// partition is a range of the end times of the annotations, in milliseconds. The start is inclusive and the
+// end exclusive.
+type partition struct {
+	name  string
+	start int64
+	end   int64
+}
+
+// isDefault reports whether the partition holds the annotations that end after the last partition.
+func (p partition) isDefault() bool {
+	return p.end == math.MaxInt64
+}
+
+func (p partition) covers(epochEnd int64) bool {
+	return !p.isDefault() && p.start <= epochEnd && epochEnd < p.end
+}
+
+func partitionName(start, end int64) string {
+	return partitionPrefix + partitionStamp(start) + "_" + partitionStamp(end)
+}
+
+func partitionStamp(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format("2006010215")
+}
+
+// periodStart returns the start of the period of the interval containing the time, the periods being aligned on
+// the Unix epoch.
+func periodStart(ms int64, interval time.Duration) int64 {
+	i := interval.Milliseconds()
+	return ms - ((ms%i)+i)%i
+}
+
+// newPartition returns the partition of the period containing the time, shortened so that it does not overlap
+// the existing partitions. ok is false when an existing partition already covers the time.
+func newPartition(existing []partition, at int64, interval time.Duration) (p partition, ok bool) {
+	start := periodStart(at, interval)
+	end := start + interval.Milliseconds()
+	for _, e := range existing {
+		if e.covers(at) {
+			return partition{}, false
+		}
+		if e.isDefault() {
+			continue
+		}
+		if e.end > start && e.end <= at {
+			start = e.end
+		}
+		if e.start > at && e.start < end {
+			end = e.start
+		}
+	}
+	return partition{name: partitionName(start, end), start: start, end: end}, true
+}
+
+func sortPartitions(partitions []partition) {
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].start < partitions[j].start })
+}
+
+// partitioner manages the partitions of the annotation table in a database.
+type partitioner interface {
+	// tables returns the tables of the annotations that end at or after from, the annotation table first.
+	tables(sess *db.Session, from int64) ([]string, error)
+	// isPartitioned reports whether the annotation table is partitioned.
+	isPartitioned(sess *db.Session) (bool, error)
+	// partition partitions the annotation table. The existing annotations that end before the boundary are kept in
+	// the legacy partition.
+	partition(sess *db.Session, boundary int64) error
+	// list returns the partitions ordered by their start.
+	list(sess *db.Session) ([]partition, error)
+	create(sess *db.Session, p partition) error
+	drop(sess *db.Session, p partition) error
+	// archive moves the annotations that end before the boundary to their partitions, when the annotation table
+	// is not partitioned by the database.
+	archive(sess *db.Session, boundary int64, interval time.Duration) error
+	// native reports whether the database routes the annotations to their partitions.
+	native() bool
+}
+
+func newPartitioner(dbType core.DbType) partitioner {
+	switch dbType {
+	case migrator.Postgres:
+		return postgresPartitioner{}
+	case migrator.MySQL:
+		return mysqlPartitioner{}
+	default:
+		return shardedPartitioner{}
+	}
+}
+
+// MaintainPartitions partitions the annotation table on the first run, creates the partitions of the current and
+// next periods and drops the partitions past the retention. It returns the number of dropped partitions.
+func (r *xormRepositoryImpl) MaintainPartitions(ctx context.Context, settings setting.AnnotationPartitionSettings) (int64, error) {
+	if !settings.Enabled {
+		return 0, nil
+	}
+
+	now := timeNow().UnixMilli()
+	current := periodStart(now, settings.Interval)
+
+	err := r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		partitioned, err := r.partitions.isPartitioned(sess)
+		if err != nil || partitioned {
+			return err
+		}
+		r.log.Warn("Partitioning the annotation table, this can take a while with large tables")
+		return r.partitions.partition(sess, current)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to partition the annotation table: %w", err)
+	}
+
+	if !r.partitions.native() {
+		err := r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			return r.partitions.archive(sess, current, settings.Interval)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move annotations to their partitions: %w", err)
+		}
+	}
+
+	var existing []partition
+	err = r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		existing, err = r.partitions.list(sess)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the annotation partitions: %w", err)
+	}
+
+	if r.partitions.native() {
+		for i := 0; i <= settings.Premake; i++ {
+			p, ok := newPartition(existing, current+int64(i)*settings.Interval.Milliseconds(), settings.Interval)
+			if !ok {
+				continue
+			}
+			r.log.Info("Creating annotation partition", "partition", p.name)
+			err := r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				return r.partitions.create(sess, p)
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to create annotation partition %s: %w", p.name, err)
+			}
+			existing = append(existing, p)
+			sortPartitions(existing)
+		}
+	}
+
+	var dropped int64
+	if settings.Retention > 0 {
+		cutoff := now - settings.Retention.Milliseconds()
+		for _, p := range existing {
+			if p.isDefault() || p.end > cutoff {
+				continue
+			}
+			r.log.Info("Dropping annotation partition past the retention", "partition", p.name)
+			err := r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				return r.partitions.drop(sess, p)
+			})
+			if err != nil {
+				return dropped, fmt.Errorf("failed to drop annotation partition %s: %w", p.name, err)
+			}
+			dropped++
+		}
+	}
+	return dropped, nil
+}
+
+// annotationSource returns the table expression of the annotations of the tables, with the alias.
+func annotationSource(tables []string, alias string) string {
+	if len(tables) == 1 {
+		if tables[0] == alias {
+			return alias
+		}
+		return tables[0] + " " + alias
+	}
+	return "(SELECT * FROM " + strings.Join(tables, " UNION ALL SELECT * FROM ") + ") " + alias
+}
+
+// annotationIndices are the columns of the indices of the annotation table, created on the partitions.
+var annotationIndices = [][]string{
+	{"org_id", "alert_id"},
+	{"org_id", "type"},
+	{"org_id", "created"},
+	{"org_id", "updated"},
+	{"org_id", "dashboard_id", "epoch_end", "epoch"},
+	{"org_id", "epoch_end", "epoch"},
+	{"alert_id"},
+}
+
+type partitionRow struct {
+	Name  string `xorm:"name"`
+	Bound string `xorm:"bound"`
+}
+
+// parseBound parses a bound of a partition, MINVALUE and MAXVALUE being the bounds of the legacy and default
+// partitions.
+func parseBound(bound string) (int64, error) {
+	bound = strings.Trim(strings.TrimSpace(bound), "'")
+	switch strings.ToUpper(bound) {
+	case "MINVALUE":
+		return math.MinInt64, nil
+	case "MAXVALUE":
+		return math.MaxInt64, nil
+	}
+	return strconv.ParseInt(bound, 10, 64)
+}
@@ -0,0 +1,190 @@
+package annotationsimpl
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// postgresPartitioner partitions the annotation table with the declarative range partitions of Postgres. The
+// annotations that end after the last partition are stored in the default partition, and moved to their partition
+// when it is created.
+type postgresPartitioner struct{}
+
+func (postgresPartitioner) native() bool {
+	return true
+}
+
+func (postgresPartitioner) tables(*db.Session, int64) ([]string, error) {
+	return []string{"annotation"}, nil
+}
+
+func (postgresPartitioner) isPartitioned(sess *db.Session) (bool, error) {
+	var kind string
+	_, err := sess.SQL("SELECT relkind FROM pg_class WHERE oid = to_regclass('annotation')").Get(&kind)
+	return kind == "p", err
+}
+
+// partition replaces the annotation table by a partitioned table, the existing table becoming the legacy
+// partition. The primary key includes the end time, as required by the partitions.
+func (postgresPartitioner) partition(sess *db.Session, boundary int64) error {
+	var sequence string
+	if _, err := sess.SQL("SELECT COALESCE(pg_get_serial_sequence('annotation', 'id'), '')").Get(&sequence); err != nil {
+		return err
+	}
+
+	stmts := []string{
+		"ALTER TABLE annotation RENAME TO " + legacyPartition,
+		"CREATE TABLE annotation (LIKE " + legacyPartition + " INCLUDING DEFAULTS) PARTITION BY RANGE (epoch_end)",
+		"ALTER TABLE annotation ADD CONSTRAINT annotation_partitioned_pkey PRIMARY KEY (id, epoch_end)",
+	}
+	for _, cols := range annotationIndices {
+		stmts = append(stmts, "CREATE INDEX ON annotation ("+strings.Join(cols, ", ")+")")
+	}
+	if sequence != "" {
+		// The sequence of the ids must not be dropped with the legacy partition.
+		stmts = append(stmts, "ALTER SEQUENCE "+sequence+" OWNED BY annotation.id")
+	}
+	stmts = append(stmts,
+		"CREATE TABLE "+defaultPartition+" PARTITION OF annotation DEFAULT",
+		fmt.Sprintf("WITH moved AS (DELETE FROM %s WHERE epoch_end >= %d RETURNING *) INSERT INTO annotation SELECT * FROM moved", legacyPartition, boundary),
+		fmt.Sprintf("ALTER TABLE annotation ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO (%d)", legacyPartition, boundary),
+	)
+	return execAll(sess, stmts)
+}
+
+var postgresBoundRegexp = regexp.MustCompile(`FROM \((.+)\) TO \((.+)\)`)
+
+func (postgresPartitioner) list(sess *db.Session) ([]partition, error) {
+	var rows []partitionRow
+	err := sess.SQL(`SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i INNER JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass('annotation')`).Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]partition, 0, len(rows))
+	for _, row := range rows {
+		if row.Bound == "DEFAULT" {
+			partitions = append(partitions, partition{name: row.Name, start: math.MaxInt64, end: math.MaxInt64})
+			continue
+		}
+		match := postgresBoundRegexp.FindStringSubmatch(row.Bound)
+		if match == nil {
+			return nil, fmt.Errorf("unexpected bound %q of partition %s", row.Bound, row.Name)
+		}
+		start, err := parseBound(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseBound(match[2])
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, partition{name: row.Name, start: start, end: end})
+	}
+	sortPartitions(partitions)
+	return partitions, nil
+}
+
+func (postgresPartitioner) create(sess *db.Session, p partition) error {
+	return execAll(sess, []string{
+		"CREATE TABLE " + p.name + " (LIKE annotation INCLUDING DEFAULTS)",
+		fmt.Sprintf("WITH moved AS (DELETE FROM %s WHERE epoch_end >= %d AND epoch_end < %d RETURNING *) INSERT INTO %s SELECT * FROM moved", defaultPartition, p.start, p.end, p.name),
+		fmt.Sprintf("ALTER TABLE annotation ATTACH PARTITION %s FOR VALUES FROM (%d) TO (%d)", p.name, p.start, p.end),
+	})
+}
+
+func (postgresPartitioner) drop(sess *db.Session, p partition) error {
+	_, err := sess.Exec("DROP TABLE " + p.name)
+	return err
+}
+
+func (postgresPartitioner) archive(*db.Session, int64, time.Duration) error {
+	return nil
+}
+
+// mysqlPartitioner partitions the annotation table with the range partitions of MySQL. The last partition holds
+// the annotations that end after the other partitions, and is split when a partition is created.
+type mysqlPartitioner struct{}
+
+func (mysqlPartitioner) native() bool {
+	return true
+}
+
+func (mysqlPartitioner) tables(*db.Session, int64) ([]string, error) {
+	return []string{"annotation"}, nil
+}
+
+func (mysqlPartitioner) isPartitioned(sess *db.Session) (bool, error) {
+	var count int64
+	_, err := sess.SQL(`SELECT COUNT(*) FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = 'annotation' AND partition_name IS NOT NULL`).Get(&count)
+	return count > 0, err
+}
+
+// partition partitions the annotation table in place. The primary key includes the end time, as required by
+// the partitions.
+func (mysqlPartitioner) partition(sess *db.Session, boundary int64) error {
+	_, err := sess.Exec(fmt.Sprintf(`ALTER TABLE annotation DROP PRIMARY KEY, ADD PRIMARY KEY (id, epoch_end)
+		PARTITION BY RANGE (epoch_end) (
+			PARTITION %s VALUES LESS THAN (%d),
+			PARTITION %s VALUES LESS THAN MAXVALUE
+		)`, legacyPartition, boundary, defaultPartition))
+	return err
+}
+
+func (mysqlPartitioner) list(sess *db.Session) ([]partition, error) {
+	var rows []partitionRow
+	err := sess.SQL(`SELECT partition_name AS name, partition_description AS bound FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = 'annotation' AND partition_name IS NOT NULL
+		ORDER BY partition_ordinal_position`).Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]partition, 0, len(rows))
+	start := int64(math.MinInt64)
+	for _, row := range rows {
+		end, err := parseBound(row.Bound)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected bound %q of partition %s: %w", row.Bound, row.Name, err)
+		}
+		partitions = append(partitions, partition{name: row.Name, start: start, end: end})
+		start = end
+	}
+	return partitions, nil
+}
+
+// create splits the default partition. The ranges of MySQL partitions are contiguous, the partition starts at the
+// end of the previous one.
+func (mysqlPartitioner) create(sess *db.Session, p partition) error {
+	_, err := sess.Exec(fmt.Sprintf(`ALTER TABLE annotation REORGANIZE PARTITION %[1]s INTO (
+			PARTITION %[2]s VALUES LESS THAN (%[3]d),
+			PARTITION %[1]s VALUES LESS THAN MAXVALUE
+		)`, defaultPartition, p.name, p.end))
+	return err
+}
+
+func (mysqlPartitioner) drop(sess *db.Session, p partition) error {
+	_, err := sess.Exec("ALTER TABLE annotation DROP PARTITION " + p.name)
+	return err
+}
+
+func (mysqlPartitioner) archive(*db.Session, int64, time.Duration) error {
+	return nil
+}
+
+func execAll(sess *db.Session, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := sess.Exec(stmt); err != nil {
+			return fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return nil
+}
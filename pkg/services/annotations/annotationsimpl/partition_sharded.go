@@ -0,0 +1,160 @@
+package annotationsimpl
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// shardedPartitioner partitions the annotations in tables of their own, for the databases without partitions. The
+// annotation table holds the annotations of the current period, the older annotations being moved to the shards
+// by archive.
+type shardedPartitioner struct{}
+
+func (shardedPartitioner) native() bool {
+	return false
+}
+
+// tables returns the annotation table and the shards, even when the partitioning has been disabled since, so that
+// the archived annotations are still queried.
+func (s shardedPartitioner) tables(sess *db.Session, from int64) ([]string, error) {
+	shards, err := s.list(sess)
+	if err != nil {
+		return nil, err
+	}
+	tables := []string{"annotation"}
+	for _, p := range shards {
+		if from <= 0 || p.end > from {
+			tables = append(tables, p.name)
+		}
+	}
+	return tables, nil
+}
+
+func (shardedPartitioner) isPartitioned(*db.Session) (bool, error) {
+	return true, nil
+}
+
+func (shardedPartitioner) partition(*db.Session, int64) error {
+	return nil
+}
+
+func (shardedPartitioner) list(sess *db.Session) ([]partition, error) {
+	var names []string
+	err := sess.SQL(`SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE 'annotation\_p%' ESCAPE '\'`).Find(&names)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]partition, 0, len(names))
+	for _, name := range names {
+		p, err := parseShardName(name)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, p)
+	}
+	sortPartitions(shards)
+	return shards, nil
+}
+
+func parseShardName(name string) (partition, error) {
+	start, end, ok := strings.Cut(strings.TrimPrefix(name, partitionPrefix), "_")
+	if !ok {
+		return partition{}, fmt.Errorf("unexpected annotation shard %s", name)
+	}
+	startTime, err := time.Parse("2006010215", start)
+	if err != nil {
+		return partition{}, fmt.Errorf("unexpected annotation shard %s: %w", name, err)
+	}
+	endTime, err := time.Parse("2006010215", end)
+	if err != nil {
+		return partition{}, fmt.Errorf("unexpected annotation shard %s: %w", name, err)
+	}
+	return partition{name: name, start: startTime.UnixMilli(), end: endTime.UnixMilli()}, nil
+}
+
+func (shardedPartitioner) create(sess *db.Session, p partition) error {
+	return execAll(sess, []string{
+		"CREATE TABLE " + p.name + " AS SELECT * FROM annotation WHERE 1 = 0",
+		fmt.Sprintf("CREATE UNIQUE INDEX UQE_%[1]s_id ON %[1]s (id)", p.name),
+		fmt.Sprintf("CREATE INDEX IDX_%[1]s_org_id_epoch_end_epoch ON %[1]s (org_id, epoch_end, epoch)", p.name),
+		fmt.Sprintf("CREATE INDEX IDX_%[1]s_org_id_dashboard_id_epoch_end_epoch ON %[1]s (org_id, dashboard_id, epoch_end, epoch)", p.name),
+	})
+}
+
+func (shardedPartitioner) drop(sess *db.Session, p partition) error {
+	_, err := sess.Exec("DROP TABLE " + p.name)
+	return err
+}
+
+// archive moves the annotations that end before the boundary from the annotation table to their shards, creating
+// the missing shards.
+func (s shardedPartitioner) archive(sess *db.Session, boundary int64, interval time.Duration) error {
+	shards, err := s.list(sess)
+	if err != nil {
+		return err
+	}
+
+	for {
+		var oldest sql.NullInt64
+		if _, err := sess.SQL("SELECT MIN(epoch_end) FROM annotation WHERE epoch_end < ?", boundary).Get(&oldest); err != nil {
+			return err
+		}
+		if !oldest.Valid {
+			return nil
+		}
+
+		shard, found := partition{}, false
+		for _, p := range shards {
+			if p.covers(oldest.Int64) {
+				shard, found = p, true
+				break
+			}
+		}
+		if !found {
+			shard, _ = newPartition(shards, oldest.Int64, interval)
+			if err := s.create(sess, shard); err != nil {
+				return err
+			}
+			shards = append(shards, shard)
+			sortPartitions(shards)
+		}
+
+		end := min(shard.end, boundary)
+		if _, err := sess.Exec("INSERT INTO "+shard.name+" SELECT * FROM annotation WHERE epoch_end >= ? AND epoch_end < ?", shard.start, end); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("DELETE FROM annotation WHERE epoch_end >= ? AND epoch_end < ?", shard.start, end); err != nil {
+			return err
+		}
+	}
+}
+
+// restore moves an archived annotation back to the annotation table, so that it can be updated. The annotation is
+// archived again by the next maintenance when it still ends before the current period.
+func (s shardedPartitioner) restore(sess *db.Session, id, orgID int64) error {
+	tables, err := s.tables(sess, 0)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables[1:] {
+		res, err := sess.Exec("INSERT INTO annotation SELECT * FROM "+table+" WHERE id = ? AND org_id = ?", id, orgID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			continue
+		}
+		_, err = sess.Exec("DELETE FROM "+table+" WHERE id = ? AND org_id = ?", id, orgID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package annotationsimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	annotation_ac "github.com/grafana/grafana/pkg/services/annotations/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewPartition(t *testing.T) {
+	day := 24 * time.Hour
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC).UnixMilli()
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).UnixMilli()
+	end := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	t.Run("Should align the partition on the interval", func(t *testing.T) {
+		p, ok := newPartition(nil, at, day)
+		require.True(t, ok)
+		assert.Equal(t, partition{name: "annotation_p2024011500_2024011600", start: start, end: end}, p)
+	})
+
+	t.Run("Should not overlap the existing partitions", func(t *testing.T) {
+		legacy := partition{name: legacyPartition, start: -1 << 63, end: start + time.Hour.Milliseconds()}
+		p, ok := newPartition([]partition{legacy, {name: defaultPartition, start: 1<<63 - 1, end: 1<<63 - 1}}, at, day)
+		require.True(t, ok)
+		assert.Equal(t, legacy.end, p.start)
+		assert.Equal(t, end, p.end)
+	})
+
+	t.Run("Should not create a partition when the time is covered", func(t *testing.T) {
+		_, ok := newPartition([]partition{{start: start, end: end}}, at, day)
+		require.False(t, ok)
+	})
+
+	t.Run("Should align the times before the epoch", func(t *testing.T) {
+		assert.Equal(t, -day.Milliseconds(), periodStart(-1, day))
+	})
+}
+
+func TestParseShardName(t *testing.T) {
+	p, err := parseShardName("annotation_p2024011500_2024011600")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).UnixMilli(), p.start)
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC).UnixMilli(), p.end)
+
+	_, err = parseShardName("annotation_p2024011500")
+	require.Error(t, err)
+}
+
+func TestIntegrationAnnotationPartitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := db.InitTestDB(t)
+	if sql.GetDBType() != "sqlite3" {
+		t.Skip("the sharded partitions are only used with SQLite")
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AnnotationMaximumTagsLength = 60
+	cfg.AnnotationCleanupJobBatchSize = 100
+	store := NewXormStore(cfg, log.New("annotation.test"), sql, tagimpl.ProvideService(sql))
+
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	origTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = origTimeNow })
+
+	items := []*annotations.Item{
+		{OrgID: 1, Text: "old", Epoch: now.Add(-78 * time.Hour).UnixMilli(), Tags: []string{"old"}},
+		{OrgID: 1, Text: "yesterday", Epoch: now.Add(-30 * time.Hour).UnixMilli(), Tags: []string{"yesterday"}},
+		{OrgID: 1, Text: "today", Epoch: now.UnixMilli(), Tags: []string{"today"}},
+	}
+	for _, item := range items {
+		require.NoError(t, store.Add(context.Background(), item))
+	}
+
+	settings := setting.AnnotationPartitionSettings{Enabled: true, Interval: 24 * time.Hour, Premake: 2}
+	access := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+	texts := func(t *testing.T, query *annotations.ItemQuery) []string {
+		t.Helper()
+		result, err := store.Get(context.Background(), query, access)
+		require.NoError(t, err)
+		texts := make([]string, 0, len(result))
+		for _, item := range result {
+			texts = append(texts, item.Text)
+		}
+		return texts
+	}
+	tables := func(t *testing.T, from int64) []string {
+		t.Helper()
+		var tables []string
+		err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var err error
+			tables, err = store.partitions.tables(sess, from)
+			return err
+		})
+		require.NoError(t, err)
+		return tables
+	}
+
+	t.Run("Should move the annotations of the past periods to their shards", func(t *testing.T) {
+		dropped, err := store.MaintainPartitions(context.Background(), settings)
+		require.NoError(t, err)
+		assert.Zero(t, dropped)
+
+		assert.Equal(t, []string{"annotation", "annotation_p2024011200_2024011300", "annotation_p2024011400_2024011500"}, tables(t, 0))
+		assertAnnotationCount(t, sql, "", 1)
+	})
+
+	t.Run("Should query the annotations of the shards", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"today", "yesterday", "old"}, texts(t, &annotations.ItemQuery{OrgID: 1}))
+
+		from := now.Add(-36 * time.Hour).UnixMilli()
+		assert.Equal(t, []string{"annotation", "annotation_p2024011400_2024011500"}, tables(t, from))
+		assert.ElementsMatch(t, []string{"today", "yesterday"}, texts(t, &annotations.ItemQuery{OrgID: 1, From: from, To: now.UnixMilli()}))
+
+		result, err := store.GetTags(context.Background(), &annotations.TagsQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Len(t, result.Tags, 3)
+	})
+
+	t.Run("Should update an archived annotation", func(t *testing.T) {
+		err := store.Update(context.Background(), &annotations.Item{ID: items[0].ID, OrgID: 1, Text: "updated", Tags: []string{"old"}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"today", "yesterday", "updated"}, texts(t, &annotations.ItemQuery{OrgID: 1}))
+
+		_, err = store.MaintainPartitions(context.Background(), settings)
+		require.NoError(t, err)
+		assertAnnotationCount(t, sql, "", 1)
+	})
+
+	t.Run("Should delete an archived annotation", func(t *testing.T) {
+		err := store.Delete(context.Background(), &annotations.DeleteParams{ID: items[1].ID, OrgID: 1})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"today", "updated"}, texts(t, &annotations.ItemQuery{OrgID: 1}))
+	})
+
+	t.Run("Should drop the shards past the retention", func(t *testing.T) {
+		settings := settings
+		settings.Retention = 48 * time.Hour
+		dropped, err := store.MaintainPartitions(context.Background(), settings)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), dropped)
+		assert.Equal(t, []string{"annotation", "annotation_p2024011400_2024011500"}, tables(t, 0))
+		assert.Equal(t, []string{"today"}, texts(t, &annotations.ItemQuery{OrgID: 1}))
+
+		affected, err := store.CleanOrphanedAnnotationTags(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+		assertAnnotationTagCount(t, sql, 1)
+	})
+
+	t.Run("Should keep the newest annotations across the shards", func(t *testing.T) {
+		require.NoError(t, store.Add(context.Background(), &annotations.Item{OrgID: 1, Text: "archived", Epoch: now.Add(-30 * time.Hour).UnixMilli()}))
+		_, err := store.MaintainPartitions(context.Background(), settings)
+		require.NoError(t, err)
+		require.NoError(t, store.Add(context.Background(), &annotations.Item{OrgID: 1, Text: "newest", Epoch: now.UnixMilli()}))
+
+		affected, err := store.CleanAnnotations(context.Background(), setting.AnnotationCleanupSettings{MaxCount: 1}, apiAnnotationType)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), affected)
+		assert.Equal(t, []string{"newest"}, texts(t, &annotations.ItemQuery{OrgID: 1}))
+	})
+}
@@ -16,6 +16,8 @@ type store interface {
 	Get(ctx context.Context, query *annotations.ItemQuery, accessResources *accesscontrol.AccessResources) ([]*annotations.ItemDTO, error)
 	Delete(ctx context.Context, params *annotations.DeleteParams) error
 	GetTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error)
+	GetTagStats(ctx context.Context, query *annotations.TagStatsQuery, accessResources *accesscontrol.AccessResources) (annotations.FindTagStatsResult, error)
 	CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error)
 	CleanOrphanedAnnotationTags(ctx context.Context) (int64, error)
+	MaintainPartitions(ctx context.Context, settings setting.AnnotationPartitionSettings) (int64, error)
 }
@@ -42,6 +42,7 @@ type xormRepositoryImpl struct {
 	db         db.DB
 	log        log.Logger
 	tagService tag.Service
+	partitions partitioner
 }
 
 func NewXormStore(cfg *setting.Cfg, l log.Logger, db db.DB, tagService tag.Service) *xormRepositoryImpl {
@@ -50,6 +51,7 @@ func NewXormStore(cfg *setting.Cfg, l log.Logger, db db.DB, tagService tag.Servi
 		db:         db,
 		log:        l.New("store", "xorm"),
 		tagService: tagService,
+		partitions: newPartitioner(db.GetDBType()),
 	}
 }
 
@@ -142,6 +144,12 @@ func (r *xormRepositoryImpl) update(ctx context.Context, item *annotations.Item)
 		)
 		existing := new(annotations.Item)
 
+		if sharded, ok := r.partitions.(shardedPartitioner); ok {
+			if err := sharded.restore(sess, item.ID, item.OrgID); err != nil {
+				return err
+			}
+		}
+
 		isExist, err = sess.Table("annotation").Where("id=? AND org_id=?", item.ID, item.OrgID).Get(existing)
 
 		if err != nil {
@@ -245,6 +253,15 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 	params := make([]interface{}, 0)
 	items := make([]*annotations.ItemDTO, 0)
 	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var from int64
+		if query.From > 0 && query.To > 0 {
+			from = query.From
+		}
+		tables, err := r.partitions.tables(sess, from)
+		if err != nil {
+			return err
+		}
+
 		sql.WriteString(`
 			SELECT
 				annotation.id,
@@ -263,11 +280,11 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 				usr.email,
 				usr.login,
 				alert.name as alert_name
-			FROM annotation
+			FROM ` + annotationSource(tables, "annotation") + `
 			LEFT OUTER JOIN ` + r.db.GetDialect().Quote("user") + ` as usr on usr.id = annotation.user_id
 			LEFT OUTER JOIN alert on alert.id = annotation.alert_id
 			INNER JOIN (
-				SELECT a.id from annotation a
+				SELECT a.id from ` + annotationSource(tables, "a") + `
 			`)
 
 		sql.WriteString(`WHERE a.org_id = ?`)
@@ -352,8 +369,14 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			query.Limit = 100
 		}
 
-		// order of ORDER BY arguments match the order of a sql index for performance
-		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		limit := r.db.GetDialect().Limit(query.Limit)
+		if query.Page > 1 {
+			limit = r.db.GetDialect().LimitOffset(query.Limit, (query.Page-1)*query.Limit)
+		}
+
+		// order of ORDER BY arguments match the order of a sql index for performance,
+		// the id makes the order stable across pages
+		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC, a.id DESC" + limit + " ) dt on dt.id = annotation.id")
 
 		if err := sess.SQL(sql.String(), params...).Find(&items); err != nil {
 			items = nil
@@ -401,33 +424,40 @@ func (r *xormRepositoryImpl) getAccessControlFilter(user identity.Requester, acc
 
 func (r *xormRepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteParams) error {
 	return r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		var (
-			sql        string
-			annoTagSQL string
-		)
+		tables, err := r.partitions.tables(sess, 0)
+		if err != nil {
+			return err
+		}
 
 		r.log.Info("delete", "orgId", params.OrgID)
-		if params.ID != 0 {
-			annoTagSQL = "DELETE FROM annotation_tag WHERE annotation_id IN (SELECT id FROM annotation WHERE id = ? AND org_id = ?)"
-			sql = "DELETE FROM annotation WHERE id = ? AND org_id = ?"
+		for _, table := range tables {
+			var (
+				sql        string
+				annoTagSQL string
+			)
 
-			if _, err := sess.Exec(annoTagSQL, params.ID, params.OrgID); err != nil {
-				return err
-			}
+			if params.ID != 0 {
+				annoTagSQL = "DELETE FROM annotation_tag WHERE annotation_id IN (SELECT id FROM " + table + " WHERE id = ? AND org_id = ?)"
+				sql = "DELETE FROM " + table + " WHERE id = ? AND org_id = ?"
 
-			if _, err := sess.Exec(sql, params.ID, params.OrgID); err != nil {
-				return err
-			}
-		} else {
-			annoTagSQL = "DELETE FROM annotation_tag WHERE annotation_id IN (SELECT id FROM annotation WHERE dashboard_id = ? AND panel_id = ? AND org_id = ?)"
-			sql = "DELETE FROM annotation WHERE dashboard_id = ? AND panel_id = ? AND org_id = ?"
+				if _, err := sess.Exec(annoTagSQL, params.ID, params.OrgID); err != nil {
+					return err
+				}
 
-			if _, err := sess.Exec(annoTagSQL, params.DashboardID, params.PanelID, params.OrgID); err != nil {
-				return err
-			}
+				if _, err := sess.Exec(sql, params.ID, params.OrgID); err != nil {
+					return err
+				}
+			} else {
+				annoTagSQL = "DELETE FROM annotation_tag WHERE annotation_id IN (SELECT id FROM " + table + " WHERE dashboard_id = ? AND panel_id = ? AND org_id = ?)"
+				sql = "DELETE FROM " + table + " WHERE dashboard_id = ? AND panel_id = ? AND org_id = ?"
 
-			if _, err := sess.Exec(sql, params.DashboardID, params.PanelID, params.OrgID); err != nil {
-				return err
+				if _, err := sess.Exec(annoTagSQL, params.DashboardID, params.PanelID, params.OrgID); err != nil {
+					return err
+				}
+
+				if _, err := sess.Exec(sql, params.DashboardID, params.PanelID, params.OrgID); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -442,6 +472,11 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 			query.Limit = 100
 		}
 
+		tables, err := r.partitions.tables(dbSession, 0)
+		if err != nil {
+			return err
+		}
+
 		var sql bytes.Buffer
 		params := make([]interface{}, 0)
 		tagKey := `tag.` + r.db.GetDialect().Quote("key")
@@ -454,7 +489,7 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 			count(*) as count
 		FROM tag
 		INNER JOIN annotation_tag ON tag.id = annotation_tag.tag_id
-		INNER JOIN annotation ON annotation.id = annotation_tag.annotation_id
+		INNER JOIN ` + annotationSource(tables, "annotation") + ` ON annotation.id = annotation_tag.annotation_id
 `)
 
 		sql.WriteString(`WHERE annotation.org_id = ?`)
@@ -467,8 +502,7 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 		sql.WriteString(` ORDER BY ` + tagKey + `,` + tagValue)
 		sql.WriteString(` ` + r.db.GetDialect().Limit(query.Limit))
 
-		err := dbSession.SQL(sql.String(), params...).Find(&items)
-		return err
+		return dbSession.SQL(sql.String(), params...).Find(&items)
 	})
 	if err != nil {
 		return annotations.FindTagsResult{Tags: []*annotations.TagsDTO{}}, err
@@ -488,6 +522,88 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 	return annotations.FindTagsResult{Tags: tags}, nil
 }
 
+// GetTagStats counts the annotations per tag. The filters on org, dashboard
+// and time range are served by the org_id_dashboard_id_epoch_end_epoch and
+// org_id_epoch_end_epoch indices, and the tag join by the unique
+// annotation_id_tag_id index, or by the tag_id_annotation_id index when
+// the annotations are counted from the tags.
+func (r *xormRepositoryImpl) GetTagStats(ctx context.Context, query *annotations.TagStatsQuery, accessResources *accesscontrol.AccessResources) (annotations.FindTagStatsResult, error) {
+	var items []*annotations.TagStat
+	err := r.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		if query.Limit == 0 {
+			query.Limit = 100
+		}
+
+		var from int64
+		if query.From > 0 && query.To > 0 {
+			from = query.From
+		}
+		tables, err := r.partitions.tables(dbSession, from)
+		if err != nil {
+			return err
+		}
+
+		var sql bytes.Buffer
+		params := make([]interface{}, 0)
+		tagKey := `tag.` + r.db.GetDialect().Quote("key")
+		tagValue := `tag.` + r.db.GetDialect().Quote("value")
+
+		sql.WriteString(`
+		SELECT
+			` + tagKey + `,
+			` + tagValue + `,
+			count(*) as count,
+			max(a.epoch) as last_epoch
+		FROM ` + annotationSource(tables, "a") + `
+		INNER JOIN annotation_tag ON annotation_tag.annotation_id = a.id
+		INNER JOIN tag ON tag.id = annotation_tag.tag_id
+`)
+
+		sql.WriteString(`WHERE a.org_id = ?`)
+		params = append(params, query.OrgID)
+
+		if query.DashboardID != 0 {
+			sql.WriteString(` AND a.dashboard_id = ?`)
+			params = append(params, query.DashboardID)
+		}
+
+		if query.From > 0 && query.To > 0 {
+			sql.WriteString(` AND a.epoch <= ? AND a.epoch_end >= ?`)
+			params = append(params, query.To, query.From)
+		}
+
+		acFilter, err := r.getAccessControlFilter(query.SignedInUser, accessResources)
+		if err != nil {
+			return err
+		}
+		sql.WriteString(fmt.Sprintf(" AND (%s)", acFilter))
+
+		sql.WriteString(` GROUP BY ` + tagKey + `,` + tagValue)
+		sql.WriteString(` ORDER BY count DESC, ` + tagKey + `,` + tagValue)
+		sql.WriteString(` ` + r.db.GetDialect().Limit(query.Limit))
+
+		return dbSession.SQL(sql.String(), params...).Find(&items)
+	})
+	if err != nil {
+		return annotations.FindTagStatsResult{Tags: []*annotations.TagStatsDTO{}}, err
+	}
+
+	tags := make([]*annotations.TagStatsDTO, 0, len(items))
+	for _, item := range items {
+		tag := item.Key
+		if len(item.Value) > 0 {
+			tag = item.Key + ":" + item.Value
+		}
+		tags = append(tags, &annotations.TagStatsDTO{
+			Tag:      tag,
+			Count:    item.Count,
+			LastSeen: item.LastEpoch,
+		})
+	}
+
+	return annotations.FindTagStatsResult{Tags: tags}, nil
+}
+
 func (r *xormRepositoryImpl) validateItem(item *annotations.Item) error {
 	if err := validateTimeRange(item); err != nil {
 		return err
@@ -516,20 +632,38 @@ func (r *xormRepositoryImpl) validateTagsLength(item *annotations.Item) error {
 }
 
 func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error) {
+	var tables []string
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		tables, err = r.partitions.tables(sess, 0)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
 	var totalAffected int64
 	if cfg.MaxAge > 0 {
 		cutoffDate := timeNow().Add(-cfg.MaxAge).UnixNano() / int64(time.Millisecond)
-		deleteQuery := `DELETE FROM annotation WHERE id IN (SELECT id FROM (SELECT id FROM annotation WHERE %s AND created < %v ORDER BY id DESC %s) a)`
-		sql := fmt.Sprintf(deleteQuery, annotationType, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+		for _, table := range tables {
+			deleteQuery := `DELETE FROM %[1]s WHERE id IN (SELECT id FROM (SELECT id FROM %[1]s WHERE %[2]s AND created < %[3]v ORDER BY id DESC %[4]s) a)`
+			sql := fmt.Sprintf(deleteQuery, table, annotationType, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
 
-		affected, err := r.executeUntilDoneOrCancelled(ctx, sql)
-		totalAffected += affected
-		if err != nil {
-			return totalAffected, err
+			affected, err := r.executeUntilDoneOrCancelled(ctx, sql)
+			totalAffected += affected
+			if err != nil {
+				return totalAffected, err
+			}
 		}
 	}
 
 	if cfg.MaxCount > 0 {
+		if len(tables) > 1 {
+			affected, err := r.cleanShardedAnnotations(ctx, tables, cfg.MaxCount, annotationType)
+			totalAffected += affected
+			return totalAffected, err
+		}
+
 		deleteQuery := `DELETE FROM annotation WHERE id IN (SELECT id FROM (SELECT id FROM annotation WHERE %s ORDER BY id DESC %s) a)`
 		sql := fmt.Sprintf(deleteQuery, annotationType, r.db.GetDialect().LimitOffset(r.cfg.AnnotationCleanupJobBatchSize, cfg.MaxCount))
 		affected, err := r.executeUntilDoneOrCancelled(ctx, sql)
@@ -540,9 +674,51 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 	return totalAffected, nil
 }
 
+// cleanShardedAnnotations keeps the newest annotations of the type across the annotation table and its shards,
+// deleting the annotations older than the last annotation to keep.
+func (r *xormRepositoryImpl) cleanShardedAnnotations(ctx context.Context, tables []string, maxCount int64, annotationType string) (int64, error) {
+	var threshold int64
+	var found bool
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		query := fmt.Sprintf(`SELECT id FROM %s WHERE %s ORDER BY id DESC %s`, annotationSource(tables, "a"), annotationType, r.db.GetDialect().LimitOffset(1, maxCount-1))
+		found, err = sess.SQL(query).Get(&threshold)
+		return err
+	})
+	if err != nil || !found {
+		return 0, err
+	}
+
+	var totalAffected int64
+	for _, table := range tables {
+		deleteQuery := `DELETE FROM %[1]s WHERE id IN (SELECT id FROM (SELECT id FROM %[1]s WHERE %[2]s AND id < %[3]d ORDER BY id DESC %[4]s) a)`
+		sql := fmt.Sprintf(deleteQuery, table, annotationType, threshold, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+		affected, err := r.executeUntilDoneOrCancelled(ctx, sql)
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+	return totalAffected, nil
+}
+
 func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
-	deleteQuery := `DELETE FROM annotation_tag WHERE id IN ( SELECT id FROM (SELECT id FROM annotation_tag WHERE NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s) a)`
-	sql := fmt.Sprintf(deleteQuery, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+	var tables []string
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		tables, err = r.partitions.tables(sess, 0)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	orphaned := make([]string, 0, len(tables))
+	for _, table := range tables {
+		orphaned = append(orphaned, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s a WHERE annotation_id = a.id)", table))
+	}
+	deleteQuery := `DELETE FROM annotation_tag WHERE id IN ( SELECT id FROM (SELECT id FROM annotation_tag WHERE %s %s) a)`
+	sql := fmt.Sprintf(deleteQuery, strings.Join(orphaned, " AND "), r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
 	return r.executeUntilDoneOrCancelled(ctx, sql)
 }
 
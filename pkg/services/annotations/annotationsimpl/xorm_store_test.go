@@ -313,6 +313,34 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Len(t, items, 2)
 		})
 
+		t.Run("Should return the annotations page by page", func(t *testing.T) {
+			accRes := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+			query := &annotations.ItemQuery{
+				OrgID:        1,
+				From:         1,
+				To:           25,
+				MatchAny:     true,
+				Tags:         []string{"rollback", "deploy"},
+				SignedInUser: testUser,
+				Limit:        1,
+			}
+			first, err := store.Get(context.Background(), query, accRes)
+			require.NoError(t, err)
+			require.Len(t, first, 1)
+			assert.Equal(t, organizationAnnotation2.ID, first[0].ID)
+
+			query.Page = 2
+			second, err := store.Get(context.Background(), query, accRes)
+			require.NoError(t, err)
+			require.Len(t, second, 1)
+			assert.Equal(t, organizationAnnotation1.ID, second[0].ID)
+
+			query.Page = 3
+			third, err := store.Get(context.Background(), query, accRes)
+			require.NoError(t, err)
+			assert.Empty(t, third)
+		})
+
 		t.Run("Should find one when all key value tag filters does match", func(t *testing.T) {
 			accRes := &annotation_ac.AccessResources{
 				Dashboards:               map[string]int64{"foo": 1},
@@ -574,6 +602,79 @@ func TestIntegrationAnnotations(t *testing.T) {
 	})
 }
 
+func TestIntegrationAnnotationTagStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.AnnotationMaximumTagsLength = 60
+	store := NewXormStore(cfg, log.New("annotation.test"), sql, tagimpl.ProvideService(sql))
+
+	items := []annotations.Item{
+		{OrgID: 1, DashboardID: 1, Epoch: 10, Tags: []string{"deploy", "service:api"}},
+		{OrgID: 1, DashboardID: 1, Epoch: 20, Tags: []string{"deploy"}},
+		{OrgID: 1, DashboardID: 2, Epoch: 30, Tags: []string{"deploy", "service:api"}},
+		{OrgID: 1, DashboardID: 0, Epoch: 40, Tags: []string{"incident"}},
+		{OrgID: 2, DashboardID: 3, Epoch: 10, Tags: []string{"deploy"}},
+	}
+	for i := range items {
+		require.NoError(t, store.Add(context.Background(), &items[i]))
+	}
+
+	allAccess := &annotation_ac.AccessResources{
+		Dashboards:               map[string]int64{"dash-1": 1, "dash-2": 2},
+		CanAccessDashAnnotations: true,
+		CanAccessOrgAnnotations:  true,
+	}
+
+	tagCounts := func(result annotations.FindTagStatsResult) map[string]int64 {
+		counts := map[string]int64{}
+		for _, tag := range result.Tags {
+			counts[tag.Tag] = tag.Count
+		}
+		return counts
+	}
+
+	t.Run("Should count tags ordered by count", func(t *testing.T) {
+		result, err := store.GetTagStats(context.Background(), &annotations.TagStatsQuery{OrgID: 1}, allAccess)
+		require.NoError(t, err)
+		require.Len(t, result.Tags, 3)
+		require.Equal(t, "deploy", result.Tags[0].Tag)
+		require.Equal(t, int64(3), result.Tags[0].Count)
+		require.Equal(t, int64(30), result.Tags[0].LastSeen)
+		require.Equal(t, map[string]int64{"deploy": 3, "service:api": 2, "incident": 1}, tagCounts(result))
+	})
+
+	t.Run("Should filter by time range", func(t *testing.T) {
+		result, err := store.GetTagStats(context.Background(), &annotations.TagStatsQuery{OrgID: 1, From: 15, To: 35}, allAccess)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int64{"deploy": 2, "service:api": 1}, tagCounts(result))
+	})
+
+	t.Run("Should filter by dashboard", func(t *testing.T) {
+		result, err := store.GetTagStats(context.Background(), &annotations.TagStatsQuery{OrgID: 1, DashboardID: 1}, allAccess)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int64{"deploy": 2, "service:api": 1}, tagCounts(result))
+	})
+
+	t.Run("Should only count annotations the user can access", func(t *testing.T) {
+		result, err := store.GetTagStats(context.Background(), &annotations.TagStatsQuery{OrgID: 1}, &annotation_ac.AccessResources{
+			Dashboards:               map[string]int64{"dash-2": 2},
+			CanAccessDashAnnotations: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]int64{"deploy": 1, "service:api": 1}, tagCounts(result))
+	})
+
+	t.Run("Should apply limit", func(t *testing.T) {
+		result, err := store.GetTagStats(context.Background(), &annotations.TagStatsQuery{OrgID: 1, Limit: 1}, allAccess)
+		require.NoError(t, err)
+		require.Len(t, result.Tags, 1)
+		require.Equal(t, "deploy", result.Tags[0].Tag)
+	})
+}
+
 func BenchmarkFindTags_10k(b *testing.B) {
 	benchmarkFindTags(b, 10000)
 }
@@ -83,6 +83,13 @@ func (repo *fakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.
 	return result, nil
 }
 
+func (repo *fakeAnnotationsRepo) FindTagStats(_ context.Context, query *annotations.TagStatsQuery) (annotations.FindTagStatsResult, error) {
+	result := annotations.FindTagStatsResult{
+		Tags: []*annotations.TagStatsDTO{},
+	}
+	return result, nil
+}
+
 func (repo *fakeAnnotationsRepo) Len() int {
 	repo.mtx.Lock()
 	defer repo.mtx.Unlock()
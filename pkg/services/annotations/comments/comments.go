@@ -0,0 +1,205 @@
+// Package comments implements threaded comments on annotations, with @login mentions that notify
+// the mentioned users by email.
+package comments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const tmplAnnotationMention = "annotation_mention"
+
+var timeNow = time.Now
+
+type Service struct {
+	log         log.Logger
+	store       *sqlStore
+	userService user.Service
+	orgService  org.Service
+	emailSender notifications.EmailSender
+}
+
+func ProvideService(
+	db db.DB,
+	userService user.Service,
+	orgService org.Service,
+	emailSender notifications.EmailSender,
+	cleanupRegistry janitor.Registry,
+) (*Service, error) {
+	s := &Service{
+		log:         log.New("annotations.comments"),
+		store:       &sqlStore{db: db},
+		userService: userService,
+		orgService:  orgService,
+		emailSender: emailSender,
+	}
+
+	if err := cleanupRegistry.RegisterTask(janitor.Task{
+		Name:     "orphaned_annotation_comments",
+		Schedule: "@every 1h",
+		Run:      s.store.deleteOrphans,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Create creates a comment, or a reply when the command has a parent, and notifies the users it mentions.
+func (s *Service) Create(ctx context.Context, cmd *CreateCommentCommand) (*Comment, error) {
+	text := strings.TrimSpace(cmd.Text)
+	if text == "" {
+		return nil, ErrEmptyText.Errorf("empty comment text")
+	}
+	if len(text) > MaxTextLength {
+		return nil, ErrTextTooLong.Errorf("comment text is %d characters long, the maximum is %d", len(text), MaxTextLength)
+	}
+
+	if cmd.ParentID != 0 {
+		if _, err := s.store.get(ctx, cmd.OrgID, cmd.AnnotationID, cmd.ParentID); err != nil {
+			if errors.Is(err, ErrCommentNotFound) {
+				return nil, ErrInvalidParent.Errorf("parent comment %d not found on annotation %d", cmd.ParentID, cmd.AnnotationID)
+			}
+			return nil, err
+		}
+	}
+
+	now := timeNow().UnixMilli()
+	comment := &Comment{
+		OrgID:        cmd.OrgID,
+		AnnotationID: cmd.AnnotationID,
+		ParentID:     cmd.ParentID,
+		UserID:       cmd.UserID,
+		Text:         text,
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.store.insert(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, comment, cmd.Link)
+
+	return comment, nil
+}
+
+// Get returns a comment of an annotation.
+func (s *Service) Get(ctx context.Context, orgID, annotationID, id int64) (*Comment, error) {
+	return s.store.get(ctx, orgID, annotationID, id)
+}
+
+// List returns the comments of an annotation as threads: the top level comments, oldest first, with
+// their replies.
+func (s *Service) List(ctx context.Context, orgID, annotationID int64) ([]*CommentDTO, error) {
+	rows, err := s.store.list(ctx, orgID, annotationID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*CommentDTO, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = &CommentDTO{
+			Comment:  row.Comment,
+			Login:    row.Login,
+			Name:     row.Name,
+			Email:    row.Email,
+			Mentions: ParseMentions(row.Text),
+			Replies:  []*CommentDTO{},
+		}
+	}
+
+	threads := make([]*CommentDTO, 0)
+	for _, row := range rows {
+		dto := byID[row.ID]
+		if parent, ok := byID[row.ParentID]; ok && row.ParentID != 0 {
+			parent.Replies = append(parent.Replies, dto)
+			continue
+		}
+		threads = append(threads, dto)
+	}
+	return threads, nil
+}
+
+// Delete deletes a comment and its replies.
+func (s *Service) Delete(ctx context.Context, orgID, annotationID, id int64) error {
+	if _, err := s.store.get(ctx, orgID, annotationID, id); err != nil {
+		return err
+	}
+	return s.store.delete(ctx, orgID, annotationID, id)
+}
+
+// notifyMentions emails the members of the organization mentioned in the comment. Failures are
+// logged, they don't fail the creation of the comment.
+func (s *Service) notifyMentions(ctx context.Context, comment *Comment, link string) {
+	mentions := ParseMentions(comment.Text)
+	if len(mentions) == 0 {
+		return
+	}
+
+	logger := s.log.FromContext(ctx)
+	author, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: comment.UserID})
+	if err != nil {
+		logger.Warn("Failed to get the author of the comment", "userID", comment.UserID, "error", err)
+		return
+	}
+
+	for _, login := range mentions {
+		mentioned, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+		if err != nil {
+			if !errors.Is(err, user.ErrUserNotFound) {
+				logger.Warn("Failed to get the mentioned user", "login", login, "error", err)
+			}
+			continue
+		}
+		if mentioned.ID == author.ID || mentioned.Email == "" {
+			continue
+		}
+
+		member, err := s.isOrgMember(ctx, mentioned.ID, comment.OrgID)
+		if err != nil {
+			logger.Warn("Failed to get the organizations of the mentioned user", "userID", mentioned.ID, "error", err)
+			continue
+		}
+		if !member {
+			continue
+		}
+
+		err = s.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+			To:       []string{mentioned.Email},
+			Template: tmplAnnotationMention,
+			OrgID:    comment.OrgID,
+			UserID:   mentioned.ID,
+			Data: map[string]any{
+				"Name":       mentioned.NameOrFallback(),
+				"AuthorName": author.NameOrFallback(),
+				"Text":       comment.Text,
+				"Link":       link,
+			},
+		})
+		if err != nil {
+			logger.Warn("Failed to send the annotation mention email", "userID", mentioned.ID, "error", err)
+		}
+	}
+}
+
+func (s *Service) isOrgMember(ctx context.Context, userID, orgID int64) (bool, error) {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		if o.OrgID == orgID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,106 @@
+package comments
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor/janitortest"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+type fakeUserService struct {
+	usertest.FakeUserService
+	users map[string]*user.User
+}
+
+func (f *fakeUserService) GetByID(ctx context.Context, query *user.GetUserByIDQuery) (*user.User, error) {
+	for _, u := range f.users {
+		if u.ID == query.ID {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (f *fakeUserService) GetByLogin(ctx context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
+	if u, ok := f.users[query.LoginOrEmail]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func TestIntegrationAnnotationComments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	users := &fakeUserService{users: map[string]*user.User{
+		"admin": {ID: 1, Login: "admin", Email: "admin@localhost"},
+		"jane":  {ID: 2, Login: "jane", Email: "jane@localhost", Name: "Jane"},
+	}}
+	orgs := &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}}}
+	emails := notifications.MockNotificationService()
+	registry := &janitortest.FakeRegistry{}
+
+	s, err := ProvideService(sqlStore, users, orgs, emails, registry)
+	require.NoError(t, err)
+	require.Len(t, registry.Tasks, 1)
+
+	t.Run("Should validate the text", func(t *testing.T) {
+		_, err := s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 1, UserID: 1, Text: "  "})
+		require.ErrorIs(t, err, ErrEmptyText)
+
+		_, err = s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 1, UserID: 1, Text: strings.Repeat("a", MaxTextLength+1)})
+		require.ErrorIs(t, err, ErrTextTooLong)
+	})
+
+	t.Run("Should reject replies to comments of other annotations", func(t *testing.T) {
+		other, err := s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 2, UserID: 1, Text: "other"})
+		require.NoError(t, err)
+
+		_, err = s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 1, UserID: 1, ParentID: other.ID, Text: "reply"})
+		require.ErrorIs(t, err, ErrInvalidParent)
+	})
+
+	t.Run("Should list threads and notify mentioned users", func(t *testing.T) {
+		root, err := s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 1, UserID: 1, Text: "@jane can you check?", Link: "http://localhost/d/abc"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"jane@localhost"}, emails.Email.To)
+		require.Equal(t, tmplAnnotationMention, emails.Email.Template)
+		require.Equal(t, "http://localhost/d/abc", emails.Email.Data["Link"])
+
+		reply, err := s.Create(ctx, &CreateCommentCommand{OrgID: 1, AnnotationID: 1, UserID: 2, ParentID: root.ID, Text: "Back to normal"})
+		require.NoError(t, err)
+
+		threads, err := s.List(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, threads, 1)
+		require.Equal(t, []string{"jane"}, threads[0].Mentions)
+		require.Len(t, threads[0].Replies, 1)
+		require.Equal(t, reply.ID, threads[0].Replies[0].ID)
+
+		require.NoError(t, s.Delete(ctx, 1, 1, root.ID))
+		threads, err = s.List(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Empty(t, threads)
+
+		_, err = s.Get(ctx, 1, 1, reply.ID)
+		require.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	t.Run("Should delete the comments of deleted annotations", func(t *testing.T) {
+		deleted, err := registry.Tasks[0].Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+}
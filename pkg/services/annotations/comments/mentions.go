@@ -0,0 +1,26 @@
+package comments
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionRegex matches the @login mentions of a text. The mention must start the text or follow a
+// whitespace or an opening bracket, so that email addresses are not parsed as mentions.
+var mentionRegex = regexp.MustCompile(`(?:^|[\s(\[{])@([A-Za-z0-9_][A-Za-z0-9_.\-]*)`)
+
+// ParseMentions returns the unique logins mentioned in the text, in the order they appear.
+func ParseMentions(text string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionRegex.FindAllStringSubmatch(text, -1) {
+		// Punctuation that ends a sentence is not part of the login.
+		login := strings.TrimRight(match[1], ".-")
+		if login == "" || seen[strings.ToLower(login)] {
+			continue
+		}
+		seen[strings.ToLower(login)] = true
+		mentions = append(mentions, login)
+	}
+	return mentions
+}
@@ -0,0 +1,29 @@
+package comments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "no mentions", text: "Deployed v1.2.3", expected: []string{}},
+		{name: "mention at the start", text: "@jane can you check?", expected: []string{"jane"}},
+		{name: "several mentions", text: "cc @jane, @john.doe and (@ops-team)", expected: []string{"jane", "john.doe", "ops-team"}},
+		{name: "end of sentence", text: "Thanks @jane.", expected: []string{"jane"}},
+		{name: "duplicated mentions", text: "@jane @Jane @jane", expected: []string{"jane"}},
+		{name: "email addresses are ignored", text: "sent to jane@example.com", expected: []string{}},
+		{name: "lone at sign", text: "meet @ 10", expected: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ParseMentions(tc.text))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package comments
+
+import (
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// MaxTextLength is the maximum length of the text of a comment.
+const MaxTextLength = 4096
+
+var (
+	ErrCommentNotFound = errutil.NotFound("annotationcomment.not-found", errutil.WithPublicMessage("Comment not found"))
+	ErrEmptyText       = errutil.ValidationFailed("annotationcomment.empty-text", errutil.WithPublicMessage("Comment text is required"))
+	ErrTextTooLong     = errutil.ValidationFailed("annotationcomment.text-too-long", errutil.WithPublicMessage("Comment text is too long"))
+	ErrInvalidParent   = errutil.ValidationFailed("annotationcomment.invalid-parent", errutil.WithPublicMessage("The parent comment does not belong to the annotation"))
+)
+
+// Comment is a comment, or a reply to a comment when ParentID is set, on an annotation.
+type Comment struct {
+	ID           int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID        int64  `xorm:"org_id" json:"-"`
+	AnnotationID int64  `xorm:"annotation_id" json:"annotationId"`
+	ParentID     int64  `xorm:"parent_id" json:"parentId"`
+	UserID       int64  `xorm:"user_id" json:"userId"`
+	Text         string `xorm:"text" json:"text"`
+	Created      int64  `json:"created"`
+	Updated      int64  `json:"updated"`
+}
+
+func (Comment) TableName() string {
+	return "annotation_comment"
+}
+
+// CommentDTO is a comment with its author, mentions and replies.
+type CommentDTO struct {
+	Comment
+	Login     string        `json:"login"`
+	Name      string        `json:"name"`
+	AvatarURL string        `json:"avatarUrl"`
+	Mentions  []string      `json:"mentions"`
+	Replies   []*CommentDTO `json:"replies"`
+
+	// Email is only used for the avatar of the author, it is not returned by the API.
+	Email string `json:"-"`
+}
+
+// CreateCommentCommand creates a comment on an annotation.
+type CreateCommentCommand struct {
+	OrgID        int64  `json:"-"`
+	AnnotationID int64  `json:"-"`
+	UserID       int64  `json:"-"`
+	ParentID     int64  `json:"parentId"`
+	Text         string `json:"text"`
+	// Link is the URL of the annotation, such as its dashboard, that is sent to the mentioned users.
+	Link string `json:"-"`
+}
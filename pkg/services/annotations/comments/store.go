@@ -0,0 +1,92 @@
+package comments
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+type commentWithAuthor struct {
+	Comment `xorm:"extends"`
+	Login   string `xorm:"login"`
+	Name    string `xorm:"name"`
+	Email   string `xorm:"email"`
+}
+
+func (s *sqlStore) insert(ctx context.Context, comment *Comment) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(comment)
+		return err
+	})
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID, annotationID, id int64) (*Comment, error) {
+	comment := &Comment{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND annotation_id = ? AND id = ?", orgID, annotationID, id).Get(comment)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrCommentNotFound.Errorf("comment %d of annotation %d not found", id, annotationID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *sqlStore) list(ctx context.Context, orgID, annotationID int64) ([]*commentWithAuthor, error) {
+	result := make([]*commentWithAuthor, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := `SELECT annotation_comment.*, u.login, u.name, u.email
+			FROM annotation_comment
+			LEFT OUTER JOIN ` + s.db.GetDialect().Quote("user") + ` AS u ON u.id = annotation_comment.user_id
+			WHERE annotation_comment.org_id = ? AND annotation_comment.annotation_id = ?
+			ORDER BY annotation_comment.created ASC, annotation_comment.id ASC`
+		return sess.SQL(rawSQL, orgID, annotationID).Find(&result)
+	})
+	return result, err
+}
+
+// delete deletes the comment and, recursively, its replies.
+func (s *sqlStore) delete(ctx context.Context, orgID, annotationID, id int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		ids := []int64{id}
+		for len(ids) > 0 {
+			if _, err := sess.Table("annotation_comment").Where("org_id = ? AND annotation_id = ?", orgID, annotationID).In("id", ids).Delete(&Comment{}); err != nil {
+				return err
+			}
+
+			var replies []int64
+			if err := sess.Table("annotation_comment").Where("org_id = ? AND annotation_id = ?", orgID, annotationID).In("parent_id", ids).Cols("id").Find(&replies); err != nil {
+				return err
+			}
+			ids = replies
+		}
+		return nil
+	})
+}
+
+// deleteOrphans deletes the comments of annotations that no longer exist, either deleted through the
+// API or removed by the annotation clean up.
+func (s *sqlStore) deleteOrphans(ctx context.Context) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec(`DELETE FROM annotation_comment WHERE NOT EXISTS (
+			SELECT 1 FROM annotation WHERE annotation.id = annotation_comment.annotation_id
+		)`)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -21,6 +21,8 @@ type ItemQuery struct {
 	SignedInUser identity.Requester
 
 	Limit int64 `json:"limit"`
+	// Page is the 1-based page of Limit annotations to return, the first page is returned if it is not set.
+	Page int64 `json:"page"`
 }
 
 // TagsQuery is the query for a tags search.
@@ -49,6 +51,39 @@ type FindTagsResult struct {
 	Tags []*TagsDTO `json:"tags"`
 }
 
+// TagStatsQuery is the query for per-tag annotation counts.
+type TagStatsQuery struct {
+	OrgID        int64 `json:"orgId"`
+	From         int64 `json:"from"`
+	To           int64 `json:"to"`
+	DashboardID  int64 `json:"dashboardId"`
+	SignedInUser identity.Requester
+
+	Limit int64 `json:"limit"`
+}
+
+// TagStat is the DB result of a tag statistics query.
+type TagStat struct {
+	Key       string
+	Value     string
+	Count     int64
+	LastEpoch int64 `xorm:"last_epoch"`
+}
+
+// TagStatsDTO is the frontend DTO for TagStat.
+type TagStatsDTO struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+	// LastSeen is the epoch in milliseconds of the most recent annotation with the tag.
+	LastSeen int64 `json:"lastSeen"`
+}
+
+// FindTagStatsResult is the result of a tag statistics query, ordered by
+// descending count.
+type FindTagStatsResult struct {
+	Tags []*TagStatsDTO `json:"tags"`
+}
+
 // GetAnnotationTagsResponse is a response struct for FindTagsResult.
 type GetAnnotationTagsResponse struct {
 	Result FindTagsResult `json:"result"`
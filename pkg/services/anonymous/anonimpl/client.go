@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var _ authn.ContextAwareClient = new(Anonymous)
@@ -40,6 +41,8 @@ func (a *Anonymous) Authenticate(ctx context.Context, r *authn.Request) (*authn.
 		// avoid r.HTTPRequest.Clone(context.Background()) as we do not require a full clone
 		httpReqCopy.Header = r.HTTPRequest.Header.Clone()
 		httpReqCopy.RemoteAddr = r.HTTPRequest.RemoteAddr
+		// keep the client IP resolved from the headers of the trusted proxies
+		httpReqCopy = httpReqCopy.WithContext(web.WithClientIP(context.Background(), web.RemoteAddr(r.HTTPRequest)))
 	}
 
 	go func() {
@@ -22,7 +22,6 @@ import (
 )
 
 const thirtyDays = 30 * 24 * time.Hour
-const deviceIDHeader = "X-Grafana-Device-Id"
 const keepFor = time.Hour * 24 * 61
 
 type AnonDeviceService struct {
@@ -102,7 +101,7 @@ func (a *AnonDeviceService) untagDevice(ctx context.Context,
 		return
 	}
 
-	deviceID := r.HTTPRequest.Header.Get(deviceIDHeader)
+	deviceID := r.HTTPRequest.Header.Get(anonymous.DeviceIDHeader)
 	if deviceID == "" {
 		return
 	}
@@ -115,7 +114,7 @@ func (a *AnonDeviceService) untagDevice(ctx context.Context,
 
 // FIXME: Unexport and remove interface
 func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind) error {
-	deviceID := httpReq.Header.Get(deviceIDHeader)
+	deviceID := httpReq.Header.Get(anonymous.DeviceIDHeader)
 	if deviceID == "" {
 		return nil
 	}
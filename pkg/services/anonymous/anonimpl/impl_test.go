@@ -50,9 +50,9 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 			name: "should tag device ID once",
 			req: []tagReq{{httpReq: &http.Request{
 				Header: http.Header{
-					"User-Agent":                            []string{"test"},
-					"X-Forwarded-For":                       []string{"10.30.30.1"},
-					http.CanonicalHeaderKey(deviceIDHeader): []string{"32mdo31deeqwes"},
+					"User-Agent":      []string{"test"},
+					"X-Forwarded-For": []string{"10.30.30.1"},
+					http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"32mdo31deeqwes"},
 				},
 			},
 				kind: anonymous.AnonDeviceUI,
@@ -69,17 +69,17 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 			name: "repeat request should not tag",
 			req: []tagReq{{httpReq: &http.Request{
 				Header: http.Header{
-					"User-Agent":                            []string{"test"},
-					http.CanonicalHeaderKey(deviceIDHeader): []string{"32mdo31deeqwes"},
-					"X-Forwarded-For":                       []string{"10.30.30.1"},
+					"User-Agent": []string{"test"},
+					http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"32mdo31deeqwes"},
+					"X-Forwarded-For": []string{"10.30.30.1"},
 				},
 			},
 				kind: anonymous.AnonDeviceUI,
 			}, {httpReq: &http.Request{
 				Header: http.Header{
-					"User-Agent":                            []string{"test"},
-					http.CanonicalHeaderKey(deviceIDHeader): []string{"32mdo31deeqwes"},
-					"X-Forwarded-For":                       []string{"10.30.30.1"},
+					"User-Agent": []string{"test"},
+					http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"32mdo31deeqwes"},
+					"X-Forwarded-For": []string{"10.30.30.1"},
 				},
 			},
 				kind: anonymous.AnonDeviceUI,
@@ -90,17 +90,17 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 			name: "tag 2 different requests",
 			req: []tagReq{{httpReq: &http.Request{
 				Header: http.Header{
-					http.CanonicalHeaderKey("User-Agent"):      []string{"test"},
-					http.CanonicalHeaderKey("X-Forwarded-For"): []string{"10.30.30.1"},
-					http.CanonicalHeaderKey(deviceIDHeader):    []string{"a"},
+					http.CanonicalHeaderKey("User-Agent"):             []string{"test"},
+					http.CanonicalHeaderKey("X-Forwarded-For"):        []string{"10.30.30.1"},
+					http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"a"},
 				},
 			},
 				kind: anonymous.AnonDeviceUI,
 			}, {httpReq: &http.Request{
 				Header: http.Header{
-					"User-Agent":                            []string{"test"},
-					"X-Forwarded-For":                       []string{"10.30.30.2"},
-					http.CanonicalHeaderKey(deviceIDHeader): []string{"b"},
+					"User-Agent":      []string{"test"},
+					"X-Forwarded-For": []string{"10.30.30.2"},
+					http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"b"},
 				},
 			},
 				kind: anonymous.AnonDeviceUI,
@@ -155,9 +155,9 @@ func TestIntegrationAnonDeviceService_localCacheSafety(t *testing.T) {
 
 	req := &http.Request{
 		Header: http.Header{
-			"User-Agent":                            []string{"test"},
-			"X-Forwarded-For":                       []string{"10.30.30.2"},
-			http.CanonicalHeaderKey(deviceIDHeader): []string{"32mdo31deeqwes"},
+			"User-Agent":      []string{"test"},
+			"X-Forwarded-For": []string{"10.30.30.2"},
+			http.CanonicalHeaderKey(anonymous.DeviceIDHeader): []string{"32mdo31deeqwes"},
 		},
 	}
 
@@ -12,6 +12,10 @@ const (
 	AnonDeviceUI DeviceKind = "ui-anon-session"
 )
 
+// DeviceIDHeader is the header identifying the browser of a request. The same ID is kept by the browser
+// when the anonymous user logs in, so the device is also known by the sessions of the users.
+const DeviceIDHeader = "X-Grafana-Device-Id"
+
 type Service interface {
 	TagDevice(context.Context, *http.Request, DeviceKind) error
 	CountDevices(ctx context.Context, from time.Time, to time.Time) (int64, error)
@@ -35,7 +35,7 @@ func (ss *sqlStore) GetAPIKeys(ctx context.Context, query *apikey.GetApiKeysQuer
 				Asc("name")
 		}
 
-		sess = sess.Where("service_account_id IS NULL")
+		sess = sess.Where("service_account_id IS NULL AND team_id IS NULL")
 
 		filter, err := accesscontrol.Filter(query.User, "id", "apikeys:id:", accesscontrol.ActionAPIKeyRead)
 		if err != nil {
@@ -52,7 +52,7 @@ func (ss *sqlStore) GetAPIKeys(ctx context.Context, query *apikey.GetApiKeysQuer
 func (ss *sqlStore) GetAllAPIKeys(ctx context.Context, orgID int64) ([]*apikey.APIKey, error) {
 	result := make([]*apikey.APIKey, 0)
 	err := ss.db.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sess := dbSession.Where("service_account_id IS NULL").Asc("name")
+		sess := dbSession.Where("service_account_id IS NULL AND team_id IS NULL").Asc("name")
 		if orgID != -1 {
 			sess = sess.Where("org_id=?", orgID)
 		}
@@ -68,7 +68,7 @@ func (ss *sqlStore) CountAPIKeys(ctx context.Context, orgID int64) (int64, error
 
 	r := result{}
 	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		rawSQL := "SELECT COUNT(*) AS count FROM api_key WHERE org_id = ? and service_account_id IS NULL"
+		rawSQL := "SELECT COUNT(*) AS count FROM api_key WHERE org_id = ? and service_account_id IS NULL and team_id IS NULL"
 		if _, err := sess.SQL(rawSQL, orgID).Get(&r); err != nil {
 			return err
 		}
@@ -82,7 +82,7 @@ func (ss *sqlStore) CountAPIKeys(ctx context.Context, orgID int64) (int64, error
 
 func (ss *sqlStore) DeleteApiKey(ctx context.Context, cmd *apikey.DeleteCommand) error {
 	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id IS NULL"
+		rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id IS NULL and team_id IS NULL"
 		result, err := sess.Exec(rawSQL, cmd.ID, cmd.OrgID)
 		if err != nil {
 			return err
@@ -124,6 +124,7 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) (res
 			Updated:          updated,
 			Expires:          expires,
 			ServiceAccountId: cmd.ServiceAccountID,
+			TeamID:           cmd.TeamID,
 			IsRevoked:        &isRevoked,
 		}
 
@@ -27,6 +27,7 @@ type APIKey struct {
 	LastUsedAt       *time.Time   `xorm:"last_used_at" db:"last_used_at"`
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
+	TeamID           *int64       `xorm:"team_id" db:"team_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
 }
 
@@ -40,6 +41,7 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	TeamID           *int64       `json:"-"`
 }
 
 type DeleteCommand struct {
@@ -0,0 +1,56 @@
+// Package apiusage accounts the API requests and the data source queries of
+// each organization per calendar month, and enforces monthly quotas on them.
+// Unlike rate limiting, which smooths bursts of requests, the monthly quotas
+// cap the total usage of the organizations of deployments that bill by usage.
+package apiusage
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	QuotaTargetSrv quota.TargetSrv = "apiusage"
+	// QuotaTargetAPIRequest is the quota of the API requests of an organization per month.
+	QuotaTargetAPIRequest quota.Target = "api_request"
+	// QuotaTargetQuery is the quota of the data source queries of an organization per month.
+	QuotaTargetQuery quota.Target = "query"
+
+	// MonthFormat is the format of the months of the usage, in UTC.
+	MonthFormat = "2006-01"
+)
+
+var (
+	ErrMonthlyQuotaExceeded = errutil.TooManyRequests("apiusage.monthly-quota-exceeded", errutil.WithPublicMessage("Monthly quota of the organization exceeded"))
+	ErrBadRequest           = errutil.BadRequest("apiusage.bad-request")
+)
+
+type Service interface {
+	// RecordQueries counts the data source queries run by an organization in the current month.
+	RecordQueries(orgID int64, queries int)
+	// GetUsage returns the monthly usage of an organization, most recent month first. The usage
+	// of the current month includes the requests and queries that are not stored yet.
+	GetUsage(ctx context.Context, query *GetUsageQuery) ([]*Usage, error)
+	// Middleware counts the API requests of the organizations, and rejects them once the monthly
+	// quotas and their grace threshold are exceeded. It must run after the context handler so
+	// that the requests are authenticated.
+	Middleware() web.Handler
+}
+
+type GetUsageQuery struct {
+	OrgID int64
+	// Months is the number of months of usage to return, including the current month.
+	Months int
+}
+
+// Usage is the usage of an organization in a month.
+type Usage struct {
+	OrgID int64 `json:"orgId"`
+	// Month is the month of the usage, formatted as YYYY-MM in UTC.
+	Month       string `json:"month"`
+	APIRequests int64  `json:"apiRequests"`
+	Queries     int64  `json:"queries"`
+}
@@ -0,0 +1,431 @@
+package apiusageimpl
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiusage"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// HeaderQuotaWarning lists the monthly quotas exceeded by the organization on the responses of
+	// the requests allowed by the grace threshold.
+	HeaderQuotaWarning = "X-Grafana-Quota-Warning"
+
+	flushInterval = 10 * time.Second
+	// stateTTL is how long the usage and the quotas of an organization are cached, the usage
+	// counted by the other instances is taken into account when they are loaded again.
+	stateTTL = time.Minute
+
+	defaultMonths = 12
+	maxMonths     = 120
+)
+
+// queryPaths are the API paths of the data source queries, which are rejected once the query
+// quota of the organization is exceeded.
+var queryPaths = []string{"/api/ds/query"}
+
+// exemptPaths are the API paths that are never rejected, so that the organization admins can
+// check the usage and the quotas of their organization once they are exceeded.
+var exemptPaths = []string{"/api/org/usage", "/api/org/quotas"}
+
+// counts are the API requests and the data source queries of an organization in a month.
+type counts struct {
+	requests int64
+	queries  int64
+}
+
+func (c counts) get(target quota.Target) int64 {
+	if target == apiusage.QuotaTargetQuery {
+		return c.queries
+	}
+	return c.requests
+}
+
+type usageKey struct {
+	orgID int64
+	month string
+}
+
+// orgState is the cached usage and quotas of an organization in a month.
+type orgState struct {
+	month string
+	// stored is the stored usage, which includes the usage counted by the other instances when
+	// the state was loaded, and the usage this instance stored since.
+	stored counts
+	limits map[quota.Target]int64
+	loaded time.Time
+}
+
+// quotaStatus is the status of a monthly quota of an organization.
+type quotaStatus struct {
+	target quota.Target
+	used   int64
+	limit  int64
+	// grace is true when the quota is exceeded but the grace threshold is not.
+	grace bool
+	// exceeded is true when the grace threshold is exceeded.
+	exceeded bool
+}
+
+type Service struct {
+	enabled      bool
+	gracePercent int64
+	appSubURL    string
+	store        store
+	quotaService quota.Service
+	log          log.Logger
+
+	mu sync.Mutex
+	// pending is the usage counted by this instance that is not stored yet.
+	pending map[usageKey]counts
+	orgs    map[int64]*orgState
+	loads   singleflight.Group
+
+	now func() time.Time
+}
+
+var _ apiusage.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, quotaService quota.Service) (*Service, error) {
+	s := &Service{
+		enabled:      cfg.Quota.Enabled,
+		gracePercent: cfg.Quota.MonthlyGracePercent,
+		appSubURL:    cfg.AppSubURL,
+		store:        &sqlStore{db: db},
+		quotaService: quotaService,
+		log:          log.New("apiusage"),
+		pending:      make(map[usageKey]counts),
+		orgs:         make(map[int64]*orgState),
+		now:          time.Now,
+	}
+	if s.gracePercent < 0 {
+		s.gracePercent = 0
+	}
+
+	defaultLimits, err := readQuotaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     apiusage.QuotaTargetSrv,
+		DefaultLimits: defaultLimits,
+		Reporter:      s.Usage,
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+	requestsTag, err := quota.NewTag(apiusage.QuotaTargetSrv, apiusage.QuotaTargetAPIRequest, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+	queriesTag, err := quota.NewTag(apiusage.QuotaTargetSrv, apiusage.QuotaTargetQuery, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+	limits.Set(requestsTag, cfg.Quota.Org.APIRequestMonthly)
+	limits.Set(queriesTag, cfg.Quota.Org.QueryMonthly)
+	return limits, nil
+}
+
+// IsDisabled returns true if the quotas are disabled, in which case the usage is not accounted.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled
+}
+
+// Run stores the counted usage periodically, and once more when the context is canceled.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The context is canceled, the usage is stored with a context of its own.
+			s.flush(context.Background())
+			return ctx.Err()
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *Service) RecordQueries(orgID int64, queries int) {
+	if !s.enabled || orgID <= 0 || queries <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(orgID, counts{queries: int64(queries)})
+}
+
+func (s *Service) GetUsage(ctx context.Context, query *apiusage.GetUsageQuery) ([]*apiusage.Usage, error) {
+	months := query.Months
+	if months == 0 {
+		months = defaultMonths
+	}
+	if months < 0 || months > maxMonths {
+		return nil, apiusage.ErrBadRequest.Errorf("months must be between 1 and %d", maxMonths)
+	}
+
+	now := s.now().UTC()
+	since := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC).Format(apiusage.MonthFormat)
+	stored, err := s.store.List(ctx, query.OrgID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make(map[string]*apiusage.Usage, len(stored))
+	for _, u := range stored {
+		byMonth[u.Month] = u
+	}
+	s.mu.Lock()
+	for key, c := range s.pending {
+		if key.orgID != query.OrgID || key.month < since {
+			continue
+		}
+		u, ok := byMonth[key.month]
+		if !ok {
+			u = &apiusage.Usage{OrgID: query.OrgID, Month: key.month}
+			byMonth[key.month] = u
+		}
+		u.APIRequests += c.requests
+		u.Queries += c.queries
+	}
+	s.mu.Unlock()
+
+	result := make([]*apiusage.Usage, 0, len(byMonth))
+	for _, u := range byMonth {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Month > result[j].Month
+	})
+	return result, nil
+}
+
+// Usage reports the usage of the current month to the quota service.
+func (s *Service) Usage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	if scopeParams == nil || scopeParams.OrgID <= 0 {
+		return u, nil
+	}
+
+	month := s.month()
+	stored, err := s.store.Get(ctx, scopeParams.OrgID, month)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	used := s.pending[usageKey{orgID: scopeParams.OrgID, month: month}]
+	s.mu.Unlock()
+	used.requests += stored.APIRequests
+	used.queries += stored.Queries
+
+	for _, target := range []quota.Target{apiusage.QuotaTargetAPIRequest, apiusage.QuotaTargetQuery} {
+		tag, err := quota.NewTag(apiusage.QuotaTargetSrv, target, quota.OrgScope)
+		if err != nil {
+			return nil, err
+		}
+		u.Set(tag, used.get(target))
+	}
+	return u, nil
+}
+
+func (s *Service) Middleware() web.Handler {
+	return func(c *contextmodel.ReqContext) {
+		if !s.enabled || c.SignedInUser == nil {
+			return
+		}
+		orgID := c.SignedInUser.GetOrgID()
+		path := strings.TrimPrefix(c.Req.URL.Path, s.appSubURL)
+		if orgID <= 0 || !strings.HasPrefix(path, "/api/") {
+			return
+		}
+
+		if !hasPrefix(path, exemptPaths) {
+			targets := []quota.Target{apiusage.QuotaTargetAPIRequest}
+			if hasPrefix(path, queryPaths) {
+				targets = append(targets, apiusage.QuotaTargetQuery)
+			}
+
+			statuses, err := s.check(c.Req.Context(), orgID, targets)
+			if err != nil {
+				s.log.Warn("Failed to check the monthly quotas, allowing the request", "orgId", orgID, "error", err)
+			}
+
+			var warnings []string
+			for _, status := range statuses {
+				switch {
+				case status.exceeded && !c.SignedInUser.GetIsGrafanaAdmin():
+					c.Resp.Header().Set("Retry-After", strconv.FormatInt(int64(s.untilNextMonth()/time.Second), 10))
+					err := apiusage.ErrMonthlyQuotaExceeded.Errorf("organization %d exceeded its monthly %s quota", orgID, status.target)
+					err.PublicPayload = map[string]any{"target": status.target, "limit": status.limit, "used": status.used}
+					c.WriteErr(err)
+					return
+				case status.grace || status.exceeded:
+					warnings = append(warnings, string(status.target))
+				}
+			}
+			if len(warnings) > 0 {
+				c.Resp.Header().Set(HeaderQuotaWarning, strings.Join(warnings, ","))
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.add(orgID, counts{requests: 1})
+	}
+}
+
+// check returns the status of the monthly quotas of an organization.
+func (s *Service) check(ctx context.Context, orgID int64, targets []quota.Target) ([]quotaStatus, error) {
+	state, err := s.state(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	used := state.stored
+	pending := s.pending[usageKey{orgID: orgID, month: state.month}]
+	s.mu.Unlock()
+	used.requests += pending.requests
+	used.queries += pending.queries
+
+	statuses := make([]quotaStatus, 0, len(targets))
+	for _, target := range targets {
+		limit, ok := state.limits[target]
+		if !ok || limit < 0 {
+			continue
+		}
+		status := quotaStatus{target: target, used: used.get(target), limit: limit}
+		switch {
+		case status.used >= limit+limit*s.gracePercent/100:
+			status.exceeded = true
+		case status.used >= limit:
+			status.grace = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// state returns the usage and the quotas of an organization in the current month, which are
+// loaded once they are older than stateTTL.
+func (s *Service) state(ctx context.Context, orgID int64) (*orgState, error) {
+	month := s.month()
+	s.mu.Lock()
+	state, ok := s.orgs[orgID]
+	s.mu.Unlock()
+	if ok && state.month == month && s.now().Sub(state.loaded) < stateTTL {
+		return state, nil
+	}
+
+	v, err, _ := s.loads.Do(strconv.FormatInt(orgID, 10), func() (any, error) {
+		return s.load(ctx, orgID, month)
+	})
+	if err != nil {
+		// The previous state of the month is used until the state can be loaded.
+		if ok && state.month == month {
+			return state, nil
+		}
+		return nil, err
+	}
+	return v.(*orgState), nil
+}
+
+func (s *Service) load(ctx context.Context, orgID int64, month string) (*orgState, error) {
+	quotas, err := s.quotaService.GetQuotasByScope(ctx, quota.OrgScope, orgID)
+	if err != nil {
+		return nil, err
+	}
+	state := &orgState{month: month, limits: make(map[quota.Target]int64), loaded: s.now()}
+	for _, q := range quotas {
+		if q.Service == string(apiusage.QuotaTargetSrv) {
+			state.limits[quota.Target(q.Target)] = q.Limit
+		}
+	}
+
+	stored, err := s.store.Get(ctx, orgID, month)
+	if err != nil {
+		return nil, err
+	}
+	state.stored = counts{requests: stored.APIRequests, queries: stored.Queries}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[orgID] = state
+	return state, nil
+}
+
+// flush stores the pending usage. The usage that cannot be stored is kept to be stored later.
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]counts)
+	s.mu.Unlock()
+
+	for key, c := range pending {
+		err := s.store.Add(ctx, key.orgID, key.month, c.requests, c.queries)
+
+		s.mu.Lock()
+		if err != nil {
+			s.log.Error("Failed to store the usage of the organization", "orgId", key.orgID, "month", key.month, "error", err)
+			s.pendingAdd(key, c)
+		} else if state, ok := s.orgs[key.orgID]; ok && state.month == key.month {
+			state.stored.requests += c.requests
+			state.stored.queries += c.queries
+		}
+		s.mu.Unlock()
+	}
+}
+
+// add counts usage of an organization in the current month. The caller must hold the lock.
+func (s *Service) add(orgID int64, c counts) {
+	s.pendingAdd(usageKey{orgID: orgID, month: s.month()}, c)
+}
+
+// pendingAdd adds usage to the pending usage. The caller must hold the lock.
+func (s *Service) pendingAdd(key usageKey, c counts) {
+	p := s.pending[key]
+	p.requests += c.requests
+	p.queries += c.queries
+	s.pending[key] = p
+}
+
+func (s *Service) month() string {
+	return s.now().UTC().Format(apiusage.MonthFormat)
+}
+
+// untilNextMonth returns the time until the quotas are reset, at the start of the next month in UTC.
+func (s *Service) untilNextMonth() time.Duration {
+	now := s.now().UTC()
+	next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(now).Round(time.Second)
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
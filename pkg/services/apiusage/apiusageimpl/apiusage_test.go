@@ -0,0 +1,247 @@
+package apiusageimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apiusage"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeStore struct {
+	mu          sync.Mutex
+	usage       map[usageKey]counts
+	expectedErr error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{usage: map[usageKey]counts{}}
+}
+
+func (f *fakeStore) Add(_ context.Context, orgID int64, month string, requests, queries int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.expectedErr != nil {
+		return f.expectedErr
+	}
+	key := usageKey{orgID: orgID, month: month}
+	c := f.usage[key]
+	c.requests += requests
+	c.queries += queries
+	f.usage[key] = c
+	return nil
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, month string) (*apiusage.Usage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := f.usage[usageKey{orgID: orgID, month: month}]
+	return &apiusage.Usage{OrgID: orgID, Month: month, APIRequests: c.requests, Queries: c.queries}, nil
+}
+
+func (f *fakeStore) List(_ context.Context, orgID int64, since string) ([]*apiusage.Usage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []*apiusage.Usage
+	for key, c := range f.usage {
+		if key.orgID == orgID && key.month >= since {
+			result = append(result, &apiusage.Usage{OrgID: orgID, Month: key.month, APIRequests: c.requests, Queries: c.queries})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Month > result[j].Month })
+	return result, nil
+}
+
+type fakeQuotaService struct {
+	*quotatest.FakeQuotaService
+	limits map[quota.Target]int64
+}
+
+func (f *fakeQuotaService) GetQuotasByScope(_ context.Context, _ quota.Scope, _ int64) ([]quota.QuotaDTO, error) {
+	quotas := []quota.QuotaDTO{{Target: "org_user", Service: "org", Limit: 10}}
+	for target, limit := range f.limits {
+		quotas = append(quotas, quota.QuotaDTO{Target: string(target), Service: string(apiusage.QuotaTargetSrv), Limit: limit})
+	}
+	return quotas, nil
+}
+
+func setupService(t *testing.T, limits map[quota.Target]int64) (*Service, *fakeStore) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.Quota.Enabled = true
+	cfg.Quota.MonthlyGracePercent = 50
+
+	s, err := ProvideService(cfg, nil, &fakeQuotaService{FakeQuotaService: quotatest.New(false, nil), limits: limits})
+	require.NoError(t, err)
+	store := newFakeStore()
+	s.store = store
+	now := time.Date(2023, 10, 31, 23, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, store
+}
+
+func serve(s *Service, path string, usr *user.SignedInUser) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	rec := httptest.NewRecorder()
+	c := &contextmodel.ReqContext{
+		Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(http.MethodPost, rec)},
+		SignedInUser: usr,
+		Logger:       log.New("test"),
+	}
+	s.Middleware().(func(*contextmodel.ReqContext))(c)
+	return rec
+}
+
+func TestService_Middleware(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("warns in the grace threshold and rejects the requests once it is exceeded", func(t *testing.T) {
+		s, _ := setupService(t, map[quota.Target]int64{apiusage.QuotaTargetAPIRequest: 2})
+
+		for i := 0; i < 2; i++ {
+			rec := serve(s, "/api/dashboards/uid/abc", usr)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(HeaderQuotaWarning))
+		}
+		rec := serve(s, "/api/dashboards/uid/abc", usr)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "api_request", rec.Header().Get(HeaderQuotaWarning))
+
+		rec = serve(s, "/api/dashboards/uid/abc", usr)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "3600", rec.Header().Get("Retry-After"))
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "apiusage.monthly-quota-exceeded", body["messageId"])
+		assert.Equal(t, "api_request", body["extra"].(map[string]any)["target"])
+		assert.Equal(t, float64(3), body["extra"].(map[string]any)["used"])
+
+		// The rejected requests are not counted.
+		usage, err := s.GetUsage(context.Background(), &apiusage.GetUsageQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, int64(3), usage[0].APIRequests)
+	})
+
+	t.Run("rejects the queries once the query quota is exceeded", func(t *testing.T) {
+		s, _ := setupService(t, map[quota.Target]int64{apiusage.QuotaTargetQuery: 2})
+		s.RecordQueries(1, 3)
+
+		rec := serve(s, "/api/ds/query", usr)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		rec = serve(s, "/api/dashboards/uid/abc", usr)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("allows the exempt paths, the server admins and the other organizations", func(t *testing.T) {
+		s, _ := setupService(t, map[quota.Target]int64{apiusage.QuotaTargetAPIRequest: 0})
+
+		assert.Equal(t, http.StatusTooManyRequests, serve(s, "/api/dashboards/uid/abc", usr).Code)
+		assert.Equal(t, http.StatusOK, serve(s, "/api/org/usage", usr).Code)
+		assert.Equal(t, http.StatusOK, serve(s, "/api/org/quotas", usr).Code)
+		assert.Equal(t, http.StatusOK, serve(s, "/api/dashboards/uid/abc", &user.SignedInUser{UserID: 2, OrgID: 1, IsGrafanaAdmin: true}).Code)
+		assert.Equal(t, http.StatusOK, serve(s, "/public/build/app.js", usr).Code)
+	})
+
+	t.Run("does nothing when the quotas are disabled", func(t *testing.T) {
+		s, _ := setupService(t, map[quota.Target]int64{apiusage.QuotaTargetAPIRequest: 0})
+		s.enabled = false
+
+		assert.Equal(t, http.StatusOK, serve(s, "/api/dashboards/uid/abc", usr).Code)
+		assert.Empty(t, s.pending)
+	})
+}
+
+func TestService_Flush(t *testing.T) {
+	t.Run("stores the pending usage", func(t *testing.T) {
+		s, store := setupService(t, nil)
+		serve(s, "/api/ds/query", &user.SignedInUser{UserID: 1, OrgID: 1})
+		s.RecordQueries(1, 2)
+
+		s.flush(context.Background())
+		assert.Empty(t, s.pending)
+		assert.Equal(t, counts{requests: 1, queries: 2}, store.usage[usageKey{orgID: 1, month: "2023-10"}])
+
+		usage, err := s.GetUsage(context.Background(), &apiusage.GetUsageQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []*apiusage.Usage{{OrgID: 1, Month: "2023-10", APIRequests: 1, Queries: 2}}, usage)
+	})
+
+	t.Run("keeps the usage that cannot be stored", func(t *testing.T) {
+		s, store := setupService(t, nil)
+		s.RecordQueries(1, 2)
+		store.expectedErr = errors.New("database is locked")
+
+		s.flush(context.Background())
+		assert.Equal(t, counts{queries: 2}, s.pending[usageKey{orgID: 1, month: "2023-10"}])
+
+		store.expectedErr = nil
+		s.flush(context.Background())
+		assert.Empty(t, s.pending)
+		assert.Equal(t, counts{queries: 2}, store.usage[usageKey{orgID: 1, month: "2023-10"}])
+	})
+}
+
+func TestService_GetUsage(t *testing.T) {
+	s, store := setupService(t, nil)
+	store.usage[usageKey{orgID: 1, month: "2023-08"}] = counts{requests: 5}
+	store.usage[usageKey{orgID: 1, month: "2022-10"}] = counts{requests: 7}
+	store.usage[usageKey{orgID: 2, month: "2023-10"}] = counts{requests: 9}
+	s.RecordQueries(1, 1)
+
+	usage, err := s.GetUsage(context.Background(), &apiusage.GetUsageQuery{OrgID: 1, Months: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []*apiusage.Usage{
+		{OrgID: 1, Month: "2023-10", Queries: 1},
+		{OrgID: 1, Month: "2023-08", APIRequests: 5},
+	}, usage)
+
+	_, err = s.GetUsage(context.Background(), &apiusage.GetUsageQuery{OrgID: 1, Months: maxMonths + 1})
+	assert.ErrorIs(t, err, apiusage.ErrBadRequest)
+}
+
+func TestIntegrationSQLStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := &sqlStore{db: db.InitTestDB(t)}
+
+	require.NoError(t, s.Add(ctx, 1, "2023-09", 1, 0))
+	require.NoError(t, s.Add(ctx, 1, "2023-10", 2, 3))
+	require.NoError(t, s.Add(ctx, 1, "2023-10", 1, 1))
+	require.NoError(t, s.Add(ctx, 2, "2023-10", 5, 5))
+
+	u, err := s.Get(ctx, 1, "2023-10")
+	require.NoError(t, err)
+	assert.Equal(t, &apiusage.Usage{OrgID: 1, Month: "2023-10", APIRequests: 3, Queries: 4}, u)
+
+	u, err = s.Get(ctx, 1, "2023-11")
+	require.NoError(t, err)
+	assert.Equal(t, &apiusage.Usage{OrgID: 1, Month: "2023-11"}, u)
+
+	list, err := s.List(ctx, 1, "2023-09")
+	require.NoError(t, err)
+	assert.Equal(t, []*apiusage.Usage{
+		{OrgID: 1, Month: "2023-10", APIRequests: 3, Queries: 4},
+		{OrgID: 1, Month: "2023-09", APIRequests: 1},
+	}, list)
+}
@@ -0,0 +1,90 @@
+package apiusageimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/apiusage"
+)
+
+// usage is the stored usage of an organization in a month.
+type usage struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	Month       string `xorm:"month"`
+	APIRequests int64  `xorm:"api_requests"`
+	Queries     int64  `xorm:"queries"`
+	Updated     int64  `xorm:"updated"`
+}
+
+func (u usage) TableName() string { return "org_api_usage" }
+
+type store interface {
+	// Add adds requests and queries to the usage of an organization in a month.
+	Add(ctx context.Context, orgID int64, month string, requests, queries int64) error
+	// Get returns the usage of an organization in a month, which is zero if nothing was stored.
+	Get(ctx context.Context, orgID int64, month string) (*apiusage.Usage, error)
+	// List returns the usage of an organization in the months since the given month, most recent first.
+	List(ctx context.Context, orgID int64, since string) ([]*apiusage.Usage, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Add(ctx context.Context, orgID int64, month string, requests, queries int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now().Unix()
+		update := func() (int64, error) {
+			res, err := sess.Exec("UPDATE org_api_usage SET api_requests = api_requests + ?, queries = queries + ?, updated = ? WHERE org_id = ? AND month = ?",
+				requests, queries, now, orgID, month)
+			if err != nil {
+				return 0, err
+			}
+			return res.RowsAffected()
+		}
+
+		// The usage is added to the row of the month, which is created by the first instance that
+		// stores usage of the month. The statements are not run in a transaction, so that the
+		// instance that fails to create the row can update the row created by another instance.
+		if affected, err := update(); err != nil || affected > 0 {
+			return err
+		}
+		_, err := sess.Insert(&usage{OrgID: orgID, Month: month, APIRequests: requests, Queries: queries, Updated: now})
+		if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+			_, err = update()
+		}
+		return err
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, month string) (*apiusage.Usage, error) {
+	var u usage
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND month = ?", orgID, month).Get(&u)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiusage.Usage{OrgID: orgID, Month: month, APIRequests: u.APIRequests, Queries: u.Queries}, nil
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64, since string) ([]*apiusage.Usage, error) {
+	var rows []usage
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND month >= ?", orgID, since).Desc("month").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*apiusage.Usage, 0, len(rows))
+	for _, u := range rows {
+		result = append(result, &apiusage.Usage{OrgID: u.OrgID, Month: u.Month, APIRequests: u.APIRequests, Queries: u.Queries})
+	}
+	return result, nil
+}
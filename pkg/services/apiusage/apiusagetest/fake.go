@@ -0,0 +1,34 @@
+package apiusagetest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/apiusage"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type FakeService struct {
+	ExpectedUsage []*apiusage.Usage
+	ExpectedError error
+	// Queries are the queries recorded by organization.
+	Queries map[int64]int
+}
+
+var _ apiusage.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{Queries: make(map[int64]int)}
+}
+
+func (f *FakeService) RecordQueries(orgID int64, queries int) {
+	f.Queries[orgID] += queries
+}
+
+func (f *FakeService) GetUsage(_ context.Context, _ *apiusage.GetUsageQuery) ([]*apiusage.Usage, error) {
+	return f.ExpectedUsage, f.ExpectedError
+}
+
+func (f *FakeService) Middleware() web.Handler {
+	return func(c *contextmodel.ReqContext) {}
+}
@@ -0,0 +1,6 @@
+package auditlog
+
+const (
+	// ActionRead allows searching the audit log of all organizations.
+	ActionRead = "auditlog:read"
+)
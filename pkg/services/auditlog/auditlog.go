@@ -0,0 +1,15 @@
+// Package auditlog records the mutations of the admin plane of Grafana, such as
+// changes of user roles, data sources, permissions and settings, along with
+// the identity and address of the actor that made them.
+package auditlog
+
+import (
+	"context"
+)
+
+type Service interface {
+	// Record records an admin action once it succeeded. Failing to record an
+	// action is logged and does not fail the action.
+	Record(ctx context.Context, cmd *RecordCommand)
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
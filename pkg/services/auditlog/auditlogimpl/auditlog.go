@@ -0,0 +1,161 @@
+package auditlogimpl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const cleanupInterval = time.Hour
+
+type Service struct {
+	store      store
+	log        log.Logger
+	exporter   *lokiExporter
+	serverLock *serverlock.ServerLockService
+
+	enabled bool
+	maxAge  time.Duration
+
+	now func() time.Time
+}
+
+var _ auditlog.Service = &Service{}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, serverLock *serverlock.ServerLockService) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("audit_log")
+	s := &Service{
+		store:      &sqlStore{db: db},
+		log:        log.New("auditlog"),
+		serverLock: serverLock,
+		enabled:    section.Key("enabled").MustBool(true),
+		now:        time.Now,
+	}
+
+	maxAge, err := gtime.ParseDuration(section.Key("max_age").MustString("90d"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit log max_age: %w", err)
+	}
+	s.maxAge = maxAge
+
+	if lokiURL := section.Key("loki_url").String(); lokiURL != "" {
+		u, err := url.Parse(lokiURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit log loki_url: %w", err)
+		}
+		s.exporter = newLokiExporter(lokiConfig{
+			url:               u,
+			basicAuthUser:     section.Key("loki_basic_auth_user").String(),
+			basicAuthPassword: section.Key("loki_basic_auth_password").String(),
+			tenantID:          section.Key("loki_tenant_id").String(),
+		}, s.log.New("exporter", "loki"))
+	}
+	return s, nil
+}
+
+// IsDisabled returns true if there is nothing to do in the background: the
+// audit log is disabled, or its entries are kept forever and not exported.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled || (s.maxAge <= 0 && s.exporter == nil)
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	if s.exporter != nil {
+		g.Go(func() error { return s.exporter.run(ctx) })
+	}
+	if s.maxAge > 0 {
+		g.Go(func() error { return s.runCleanup(ctx) })
+	}
+	return g.Wait()
+}
+
+func (s *Service) runCleanup(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "cleanup old audit log entries", cleanupInterval, func(ctx context.Context) {
+				if _, err := s.deleteOldEntries(ctx); err != nil {
+					s.log.Error("Failed to delete old audit log entries", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute cleanup of old audit log entries", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) deleteOldEntries(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.maxAge))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted old audit log entries", "count", deleted)
+	}
+	return deleted, nil
+}
+
+func (s *Service) Record(ctx context.Context, cmd *auditlog.RecordCommand) {
+	if !s.enabled {
+		return
+	}
+
+	entry := &auditlog.Entry{
+		OrgID:        cmd.OrgID,
+		IPAddress:    cmd.IPAddress,
+		Action:       cmd.Action,
+		ResourceType: cmd.ResourceType,
+		ResourceID:   cmd.ResourceID,
+		Created:      s.now().UnixMilli(),
+	}
+	if cmd.Actor != nil && !cmd.Actor.IsNil() {
+		namespace, id := cmd.Actor.GetNamespacedID()
+		entry.ActorID = namespace + ":" + id
+		entry.ActorLogin = cmd.Actor.GetLogin()
+	}
+	if cmd.Before != nil {
+		entry.Before = simplejson.NewFromAny(cmd.Before)
+	}
+	if cmd.After != nil {
+		entry.After = simplejson.NewFromAny(cmd.After)
+	}
+
+	if err := s.store.Insert(ctx, entry); err != nil {
+		s.log.Error("Failed to record admin action", "action", cmd.Action, "resourceType", cmd.ResourceType, "resourceId", cmd.ResourceID, "error", err)
+		return
+	}
+	if s.exporter != nil {
+		s.exporter.enqueue(entry)
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = auditlog.DefaultSearchLimit
+	}
+	if query.Limit > auditlog.MaxSearchLimit {
+		query.Limit = auditlog.MaxSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.store.Search(ctx, query)
+}
@@ -0,0 +1,130 @@
+package auditlogimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	setup := func(t *testing.T) (*Service, *time.Time) {
+		t.Helper()
+		sqlStore := db.InitTestDB(t)
+		s, err := ProvideService(sqlStore, setting.NewCfg(), serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()))
+		require.NoError(t, err)
+
+		now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+		return s, &now
+	}
+
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin"}
+	editor := &user.SignedInUser{UserID: 2, OrgID: 2, Login: "editor"}
+
+	t.Run("records entries with the actor and summaries", func(t *testing.T) {
+		s, _ := setup(t)
+		s.Record(context.Background(), &auditlog.RecordCommand{
+			OrgID:        1,
+			Actor:        admin,
+			IPAddress:    "10.0.0.1",
+			Action:       auditlog.ActionOrgUserRoleUpdate,
+			ResourceType: auditlog.ResourceTypeUser,
+			ResourceID:   "3",
+			Before:       map[string]any{"role": "Viewer"},
+			After:        map[string]any{"role": "Editor"},
+		})
+
+		result, err := s.Search(context.Background(), &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		assert.Equal(t, 1, result.Page)
+		assert.Equal(t, auditlog.DefaultSearchLimit, result.PerPage)
+
+		entry := result.Entries[0]
+		assert.Equal(t, "user:1", entry.ActorID)
+		assert.Equal(t, "admin", entry.ActorLogin)
+		assert.Equal(t, "10.0.0.1", entry.IPAddress)
+		assert.Equal(t, auditlog.ActionOrgUserRoleUpdate, entry.Action)
+		assert.Equal(t, "3", entry.ResourceID)
+		assert.Equal(t, "Viewer", entry.Before.Get("role").MustString())
+		assert.Equal(t, "Editor", entry.After.Get("role").MustString())
+	})
+
+	t.Run("filters and pages the search", func(t *testing.T) {
+		s, now := setup(t)
+		start := *now
+		for i := 0; i < 3; i++ {
+			*now = start.Add(time.Duration(i) * time.Minute)
+			s.Record(context.Background(), &auditlog.RecordCommand{
+				OrgID:        1,
+				Actor:        admin,
+				Action:       auditlog.ActionDataSourceUpdate,
+				ResourceType: auditlog.ResourceTypeDataSource,
+				ResourceID:   "ds",
+			})
+		}
+		s.Record(context.Background(), &auditlog.RecordCommand{
+			OrgID:        2,
+			Actor:        editor,
+			Action:       auditlog.ActionDataSourceDelete,
+			ResourceType: auditlog.ResourceTypeDataSource,
+			ResourceID:   "other",
+		})
+
+		orgID := int64(1)
+		result, err := s.Search(context.Background(), &auditlog.SearchQuery{OrgID: &orgID, Limit: 2, Page: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, start.UnixMilli(), result.Entries[0].Created)
+
+		result, err = s.Search(context.Background(), &auditlog.SearchQuery{ActorLogin: "editor"})
+		require.NoError(t, err)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, auditlog.ActionDataSourceDelete, result.Entries[0].Action)
+
+		result, err = s.Search(context.Background(), &auditlog.SearchQuery{Action: auditlog.ActionDataSourceUpdate, From: start.Add(time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.TotalCount)
+	})
+
+	t.Run("cleanup deletes entries older than max age", func(t *testing.T) {
+		s, now := setup(t)
+
+		s.Record(context.Background(), &auditlog.RecordCommand{OrgID: 1, Actor: admin, Action: auditlog.ActionPermissionsUpdate})
+		*now = now.Add(s.maxAge + time.Hour)
+		s.Record(context.Background(), &auditlog.RecordCommand{OrgID: 1, Actor: admin, Action: auditlog.ActionPermissionsUpdate})
+
+		deleted, err := s.deleteOldEntries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		result, err := s.Search(context.Background(), &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.TotalCount)
+	})
+
+	t.Run("does not record when disabled", func(t *testing.T) {
+		s, _ := setup(t)
+		s.enabled = false
+		s.Record(context.Background(), &auditlog.RecordCommand{OrgID: 1, Actor: admin, Action: auditlog.ActionPermissionsUpdate})
+
+		result, err := s.Search(context.Background(), &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalCount)
+	})
+}
@@ -0,0 +1,159 @@
+package auditlogimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+const (
+	lokiBufferSize    = 1000
+	lokiBatchSize     = 100
+	lokiFlushInterval = 5 * time.Second
+)
+
+type lokiConfig struct {
+	url               *url.URL
+	basicAuthUser     string
+	basicAuthPassword string
+	tenantID          string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiExporter pushes the recorded entries to Loki in batches. Entries are
+// dropped if Loki cannot keep up, the audit log of the database remaining the
+// source of truth.
+type lokiExporter struct {
+	cfg     lokiConfig
+	client  *http.Client
+	entries chan *auditlog.Entry
+	log     log.Logger
+}
+
+func newLokiExporter(cfg lokiConfig, logger log.Logger) *lokiExporter {
+	return &lokiExporter{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		entries: make(chan *auditlog.Entry, lokiBufferSize),
+		log:     logger,
+	}
+}
+
+func (e *lokiExporter) enqueue(entry *auditlog.Entry) {
+	select {
+	case e.entries <- entry:
+	default:
+		e.log.Warn("Audit log export buffer is full, dropping entry", "action", entry.Action, "id", entry.ID)
+	}
+}
+
+func (e *lokiExporter) run(ctx context.Context) error {
+	ticker := time.NewTicker(lokiFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*auditlog.Entry, 0, lokiBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Push with a fresh context so that the last batch is flushed on shutdown.
+		if err := e.push(context.Background(), batch); err != nil {
+			e.log.Error("Failed to export audit log entries to Loki", "entries", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry := <-e.entries:
+			batch = append(batch, entry)
+			if len(batch) >= lokiBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for len(e.entries) > 0 {
+				batch = append(batch, <-e.entries)
+			}
+			flush()
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *lokiExporter) push(ctx context.Context, entries []*auditlog.Entry) error {
+	streams := map[string]*lokiStream{}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		orgID := strconv.FormatInt(entry.OrgID, 10)
+		key := orgID + "/" + string(entry.Action)
+		s, ok := streams[key]
+		if !ok {
+			s = &lokiStream{Stream: map[string]string{
+				"job":    "grafana-audit-log",
+				"org_id": orgID,
+				"action": string(entry.Action),
+			}}
+			streams[key] = s
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(time.UnixMilli(entry.Created).UnixNano(), 10), string(line)})
+	}
+
+	body := lokiPushRequest{Streams: make([]lokiStream, 0, len(streams))}
+	for _, s := range streams {
+		body.Streams = append(body.Streams, *s)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.url.JoinPath("/loki/api/v1/push").String(), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.cfg.basicAuthUser != "" || e.cfg.basicAuthPassword != "" {
+		req.SetBasicAuth(e.cfg.basicAuthUser, e.cfg.basicAuthPassword)
+	}
+	if e.cfg.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", e.cfg.tenantID)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			e.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("push request to loki returned status %d: %s", res.StatusCode, msg)
+	}
+	return nil
+}
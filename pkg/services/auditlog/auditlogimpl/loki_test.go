@@ -0,0 +1,78 @@
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+func TestLokiExporter_Push(t *testing.T) {
+	var received lokiPushRequest
+	var user, password, tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		user, password, _ = r.BasicAuth()
+		tenant = r.Header.Get("X-Scope-OrgID")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	exporter := newLokiExporter(lokiConfig{
+		url:               u,
+		basicAuthUser:     "user",
+		basicAuthPassword: "secret",
+		tenantID:          "tenant",
+	}, log.NewNopLogger())
+
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+	err = exporter.push(context.Background(), []*auditlog.Entry{
+		{ID: 1, OrgID: 1, ActorLogin: "admin", Action: auditlog.ActionDataSourceCreate, Created: created},
+		{ID: 2, OrgID: 1, ActorLogin: "admin", Action: auditlog.ActionDataSourceCreate, Created: created + 1000},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "secret", password)
+	assert.Equal(t, "tenant", tenant)
+	require.Len(t, received.Streams, 1)
+	assert.Equal(t, map[string]string{
+		"job":    "grafana-audit-log",
+		"org_id": "1",
+		"action": string(auditlog.ActionDataSourceCreate),
+	}, received.Streams[0].Stream)
+	require.Len(t, received.Streams[0].Values, 2)
+	assert.Equal(t, "1696161600000000000", received.Streams[0].Values[0][0])
+
+	var line auditlog.Entry
+	require.NoError(t, json.Unmarshal([]byte(received.Streams[0].Values[1][1]), &line))
+	assert.Equal(t, int64(2), line.ID)
+	assert.Equal(t, "admin", line.ActorLogin)
+}
+
+func TestLokiExporter_PushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("entry out of order"))
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	exporter := newLokiExporter(lokiConfig{url: u}, log.NewNopLogger())
+
+	err = exporter.push(context.Background(), []*auditlog.Entry{{OrgID: 1, Action: auditlog.ActionDataSourceDelete}})
+	require.ErrorContains(t, err, "status 400: entry out of order")
+}
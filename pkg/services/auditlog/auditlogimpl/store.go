@@ -0,0 +1,101 @@
+package auditlogimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+type store interface {
+	Insert(context.Context, *auditlog.Entry) error
+	Search(context.Context, *auditlog.SearchQuery) (*auditlog.SearchResult, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Insert(ctx context.Context, entry *auditlog.Entry) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	result := &auditlog.SearchResult{
+		Entries: make([]*auditlog.Entry, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+
+	var where []string
+	var params []any
+	if query.OrgID != nil {
+		where = append(where, "org_id = ?")
+		params = append(params, *query.OrgID)
+	}
+	if query.ActorLogin != "" {
+		where = append(where, "actor_login = ?")
+		params = append(params, query.ActorLogin)
+	}
+	if query.Action != "" {
+		where = append(where, "action = ?")
+		params = append(params, query.Action)
+	}
+	if query.ResourceType != "" {
+		where = append(where, "resource_type = ?")
+		params = append(params, query.ResourceType)
+	}
+	if query.ResourceID != "" {
+		where = append(where, "resource_id = ?")
+		params = append(params, query.ResourceID)
+	}
+	if !query.From.IsZero() {
+		where = append(where, "created >= ?")
+		params = append(params, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "created <= ?")
+		params = append(params, query.To.UnixMilli())
+	}
+	filter := func(sess *db.Session) *xorm.Session {
+		if len(where) == 0 {
+			return sess.Table(&auditlog.Entry{})
+		}
+		return sess.Where(strings.Join(where, " AND "), params...)
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		count, err := filter(sess).Count(&auditlog.Entry{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		offset := query.Limit * (query.Page - 1)
+		return filter(sess).Desc("created").Desc("id").Limit(query.Limit, offset).Find(&result.Entries)
+	})
+	return result, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM audit_log WHERE created < ?", before.UnixMilli())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
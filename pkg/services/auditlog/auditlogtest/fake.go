@@ -0,0 +1,32 @@
+package auditlogtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+type FakeService struct {
+	ExpectedResult *auditlog.SearchResult
+	ExpectedError  error
+
+	mu       sync.Mutex
+	Recorded []*auditlog.RecordCommand
+}
+
+var _ auditlog.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Record(_ context.Context, cmd *auditlog.RecordCommand) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Recorded = append(f.Recorded, cmd)
+}
+
+func (f *FakeService) Search(context.Context, *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	return f.ExpectedResult, f.ExpectedError
+}
@@ -0,0 +1,92 @@
+package auditlog
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+)
+
+// Action is the kind of admin mutation recorded in the audit log.
+type Action string
+
+const (
+	ActionOrgUserRoleUpdate      Action = "org-user.role.update"
+	ActionUserGrafanaAdminUpdate Action = "user.grafana-admin.update"
+	ActionDataSourceCreate       Action = "datasource.create"
+	ActionDataSourceUpdate       Action = "datasource.update"
+	ActionDataSourceDelete       Action = "datasource.delete"
+	ActionPermissionsUpdate      Action = "permissions.update"
+	ActionOrgPreferencesUpdate   Action = "org.preferences.update"
+	ActionPluginSettingsUpdate   Action = "plugin.settings.update"
+)
+
+// Types of the resources of the recorded actions. Permission changes use the
+// resource of the managed permissions instead, such as dashboards or teams.
+const (
+	ResourceTypeUser       = "user"
+	ResourceTypeDataSource = "datasource"
+	ResourceTypeOrg        = "org"
+	ResourceTypePlugin     = "plugin"
+)
+
+const (
+	DefaultSearchLimit = 100
+	MaxSearchLimit     = 1000
+)
+
+// Entry is an admin action recorded in the audit log.
+type Entry struct {
+	ID int64 `json:"id" xorm:"pk autoincr 'id'"`
+	// OrgID is the organization of the changed resource, 0 for server wide resources.
+	OrgID int64 `json:"orgId" xorm:"org_id"`
+	// ActorID is the namespaced ID of the actor, such as user:1 or service-account:2.
+	ActorID    string `json:"actorId" xorm:"actor_id"`
+	ActorLogin string `json:"actorLogin" xorm:"actor_login"`
+	IPAddress  string `json:"ipAddress" xorm:"ip_address"`
+	Action     Action `json:"action" xorm:"action"`
+	// example: datasource
+	ResourceType string `json:"resourceType" xorm:"resource_type"`
+	ResourceID   string `json:"resourceId" xorm:"resource_id"`
+	// Before and After summarize the resource before and after the action.
+	Before *simplejson.Json `json:"before,omitempty" xorm:"before_summary"`
+	After  *simplejson.Json `json:"after,omitempty" xorm:"after_summary"`
+	// Created is the time of the action in epoch milliseconds.
+	Created int64 `json:"created"`
+}
+
+func (e Entry) TableName() string { return "audit_log" }
+
+type RecordCommand struct {
+	OrgID        int64
+	Actor        identity.Requester
+	IPAddress    string
+	Action       Action
+	ResourceType string
+	ResourceID   string
+	// Before and After summarize the resource before and after the action, they
+	// are stored as JSON. They must not contain secrets.
+	Before any
+	After  any
+}
+
+type SearchQuery struct {
+	// OrgID filters the entries of an organization, entries of all organizations are returned if nil.
+	OrgID        *int64
+	ActorLogin   string
+	Action       Action
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+	Page         int
+	Limit        int
+}
+
+// swagger:model
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Entries    []*Entry `json:"entries"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
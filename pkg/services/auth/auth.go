@@ -7,7 +7,6 @@ import (
 	"net"
 
 	"github.com/grafana/grafana/pkg/models/usertoken"
-	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -58,6 +57,15 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+type CreateTokenCommand struct {
+	User      *user.User
+	ClientIP  net.IP
+	UserAgent string
+	// DeviceID identifies the browser the token is created for, when it sends one. It is the same ID
+	// as the one of the anonymous devices.
+	DeviceID string
+}
+
 type RotateCommand struct {
 	// token is the un-hashed token
 	UnHashedToken string
@@ -67,7 +75,7 @@ type RotateCommand struct {
 
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
-	CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*UserToken, error)
+	CreateToken(ctx context.Context, cmd *CreateTokenCommand) (*UserToken, error)
 	LookupToken(ctx context.Context, unhashedToken string) (*UserToken, error)
 	// RotateToken will always rotate a valid token
 	RotateToken(ctx context.Context, cmd RotateCommand) (*UserToken, error)
@@ -80,8 +88,4 @@ type UserTokenService interface {
 	GetUserRevokedTokens(ctx context.Context, userID int64) ([]*UserToken, error)
 }
 
-type UserTokenBackgroundService interface {
-	registry.BackgroundService
-}
-
 type JWTVerifierService = jwt.JWTService
@@ -14,11 +14,10 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models/usertoken"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
 	"github.com/grafana/grafana/pkg/services/quota"
-	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -30,15 +29,18 @@ var (
 )
 
 func ProvideUserAuthTokenService(sqlStore db.DB,
-	serverLockService *serverlock.ServerLockService,
+	cleanupRegistry janitor.Registry,
 	quotaService quota.Service,
 	cfg *setting.Cfg) (*UserAuthTokenService, error) {
 	s := &UserAuthTokenService{
-		sqlStore:          sqlStore,
-		serverLockService: serverLockService,
-		cfg:               cfg,
-		log:               log.New("auth"),
-		singleflight:      new(singleflight.Group),
+		sqlStore:     sqlStore,
+		cfg:          cfg,
+		log:          log.New("auth"),
+		singleflight: new(singleflight.Group),
+	}
+
+	if err := cleanupRegistry.RegisterTask(s.expiredTokensCleanupTask()); err != nil {
+		return s, err
 	}
 
 	defaultLimits, err := readQuotaConfig(cfg)
@@ -58,31 +60,31 @@ func ProvideUserAuthTokenService(sqlStore db.DB,
 }
 
 type UserAuthTokenService struct {
-	sqlStore          db.DB
-	serverLockService *serverlock.ServerLockService
-	cfg               *setting.Cfg
-	log               log.Logger
-	singleflight      *singleflight.Group
+	sqlStore     db.DB
+	cfg          *setting.Cfg
+	log          log.Logger
+	singleflight *singleflight.Group
 }
 
-func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+func (s *UserAuthTokenService) CreateToken(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
 	token, hashedToken, err := generateAndHashToken()
 	if err != nil {
 		return nil, err
 	}
 
 	now := getTime().Unix()
-	clientIPStr := clientIP.String()
-	if len(clientIP) == 0 {
+	clientIPStr := cmd.ClientIP.String()
+	if len(cmd.ClientIP) == 0 {
 		clientIPStr = ""
 	}
 
 	userAuthToken := userAuthToken{
-		UserId:        user.ID,
+		UserId:        cmd.User.ID,
 		AuthToken:     hashedToken,
 		PrevAuthToken: hashedToken,
 		ClientIp:      clientIPStr,
-		UserAgent:     userAgent,
+		UserAgent:     cmd.UserAgent,
+		DeviceId:      cmd.DeviceID,
 		RotatedAt:     now,
 		CreatedAt:     now,
 		UpdatedAt:     now,
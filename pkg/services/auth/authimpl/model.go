@@ -13,6 +13,7 @@ type userAuthToken struct {
 	PrevAuthToken string
 	UserAgent     string
 	ClientIp      string
+	DeviceId      string
 	AuthTokenSeen bool
 	SeenAt        int64
 	RotatedAt     int64
@@ -39,6 +40,7 @@ func (uat *userAuthToken) fromUserToken(ut *auth.UserToken) error {
 	uat.PrevAuthToken = ut.PrevAuthToken
 	uat.UserAgent = ut.UserAgent
 	uat.ClientIp = ut.ClientIp
+	uat.DeviceId = ut.DeviceId
 	uat.AuthTokenSeen = ut.AuthTokenSeen
 	uat.SeenAt = ut.SeenAt
 	uat.RotatedAt = ut.RotatedAt
@@ -61,6 +63,7 @@ func (uat *userAuthToken) toUserToken(ut *auth.UserToken) error {
 	ut.PrevAuthToken = uat.PrevAuthToken
 	ut.UserAgent = uat.UserAgent
 	ut.ClientIp = uat.ClientIp
+	ut.DeviceId = uat.DeviceId
 	ut.AuthTokenSeen = uat.AuthTokenSeen
 	ut.SeenAt = uat.SeenAt
 	ut.RotatedAt = uat.RotatedAt
@@ -5,37 +5,16 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
 )
 
-func (s *UserAuthTokenService) Run(ctx context.Context) error {
-	ticker := time.NewTicker(time.Hour)
-	maxInactiveLifetime := s.cfg.LoginMaxInactiveLifetime
-	maxLifetime := s.cfg.LoginMaxLifetime
-
-	err := s.serverLockService.LockAndExecute(ctx, "cleanup expired auth tokens", time.Hour*12, func(context.Context) {
-		if _, err := s.deleteExpiredTokens(ctx, maxInactiveLifetime, maxLifetime); err != nil {
-			s.log.Error("An error occurred while deleting expired tokens", "err", err)
-		}
-	})
-	if err != nil {
-		s.log.Error("Failed to lock and execute cleanup of expired auth token", "error", err)
-	}
-
-	for {
-		select {
-		case <-ticker.C:
-			err = s.serverLockService.LockAndExecute(ctx, "cleanup expired auth tokens", time.Hour*12, func(context.Context) {
-				if _, err := s.deleteExpiredTokens(ctx, maxInactiveLifetime, maxLifetime); err != nil {
-					s.log.Error("An error occurred while deleting expired tokens", "err", err)
-				}
-			})
-			if err != nil {
-				s.log.Error("Failed to lock and execute cleanup of expired auth token", "error", err)
-			}
-
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+func (s *UserAuthTokenService) expiredTokensCleanupTask() janitor.Task {
+	return janitor.Task{
+		Name:     "expired_auth_tokens",
+		Schedule: "@every 1h",
+		Run: func(ctx context.Context) (int64, error) {
+			return s.deleteExpiredTokens(ctx, s.cfg.LoginMaxInactiveLifetime, s.cfg.LoginMaxLifetime)
+		},
 	}
 }
 
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"sync"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+const unknownUserAgent = "Other"
+
+var (
+	uaParser     *uaparser.Parser
+	uaParserOnce sync.Once
+)
+
+// Device is the device a token was created from, as parsed from its user agent.
+type Device struct {
+	Device         string
+	OS             string
+	OSVersion      string
+	Browser        string
+	BrowserVersion string
+}
+
+// ParseDevice parses the device of a user agent.
+func ParseDevice(userAgent string) Device {
+	uaParserOnce.Do(func() {
+		uaParser = uaparser.NewFromSaved()
+	})
+	client := uaParser.Parse(userAgent)
+
+	return Device{
+		Device:         client.Device.ToString(),
+		OS:             client.Os.Family,
+		OSVersion:      joinVersion(client.Os.Major, client.Os.Minor),
+		Browser:        client.UserAgent.Family,
+		BrowserVersion: joinVersion(client.UserAgent.Major, client.UserAgent.Minor),
+	}
+}
+
+// Name returns a name for the device that can be shown to its user, like "Firefox 120.0 on Ubuntu".
+func (d Device) Name() string {
+	browser := d.Browser
+	if browser != "" && browser != unknownUserAgent && d.BrowserVersion != "" {
+		browser += " " + d.BrowserVersion
+	}
+	os := d.OS
+	if os != "" && os != unknownUserAgent && d.OSVersion != "" {
+		os += " " + d.OSVersion
+	}
+
+	switch {
+	case browser != "" && browser != unknownUserAgent && os != "" && os != unknownUserAgent:
+		return browser + " on " + os
+	case browser != "" && browser != unknownUserAgent:
+		return browser
+	case os != "" && os != unknownUserAgent:
+		return os
+	}
+	return "Unknown device"
+}
+
+func joinVersion(major, minor string) string {
+	if major == "" {
+		return ""
+	}
+	if minor == "" {
+		return major
+	}
+	return major + "." + minor
+}
@@ -2,6 +2,7 @@ package authn
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -27,12 +28,17 @@ const (
 	ClientForm        = "auth.client.form"
 	ClientProxy       = "auth.client.proxy"
 	ClientSAML        = "auth.client.saml"
+	ClientWebAuthn    = "auth.client.webauthn"
 )
 
 const (
 	MetaKeyUsername   = "username"
 	MetaKeyAuthModule = "authModule"
 	MetaKeyIsLogin    = "isLogin"
+	// MetaKeyTOTPCode is the second factor entered in the login form, a TOTP code or a recovery code.
+	MetaKeyTOTPCode = "totpCode"
+	// MetaKeyWebAuthnAssertion is the passkey assertion of the login form, encoded in JSON.
+	MetaKeyWebAuthnAssertion = "webauthnAssertion"
 )
 
 // ClientParams are hints to the auth service about how to handle the identity management
@@ -76,6 +82,20 @@ type Service interface {
 	RedirectURL(ctx context.Context, client string, r *Request) (*Redirect, error)
 	// RegisterClient will register a new authn.Client that can be used for authentication
 	RegisterClient(c Client)
+	// MintStateHandoff stores the state of an anonymous session and returns a short-lived token
+	// that can be redeemed once after logging in, to return to the same URL with the same client state.
+	MintStateHandoff(ctx context.Context, orgID int64, handoff *StateHandoff) (string, error)
+	// RedeemStateHandoff returns the state stored for a handoff token and invalidates the token.
+	RedeemStateHandoff(ctx context.Context, orgID int64, token string) (*StateHandoff, error)
+}
+
+// StateHandoff is the state of an anonymous session that is handed off to the session
+// created when the user logs in.
+type StateHandoff struct {
+	// URL is the relative URL the user was on when the handoff was minted
+	URL string `json:"url"`
+	// State is opaque client state, e.g. unsaved changes of a dashboard
+	State json.RawMessage `json:"state,omitempty"`
 }
 
 type IdentitySynchronizer interface {
@@ -1,7 +1,12 @@
 package authnimpl
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 const (
@@ -9,12 +14,23 @@ const (
 	metricsNamespace = "grafana"
 )
 
+const (
+	resultSuccess  = "success"
+	resultFailure  = "failure"
+	resultRejected = "rejected"
+	resultError    = "error"
+)
+
 type metrics struct {
 	failedAuth     prometheus.Counter
 	successfulAuth *prometheus.CounterVec
 
 	failedLogin     *prometheus.CounterVec
 	successfulLogin *prometheus.CounterVec
+
+	clientDuration *prometheus.HistogramVec
+	clientFallback *prometheus.CounterVec
+	loginAttempts  *prometheus.CounterVec
 }
 
 func newMetrics(reg prometheus.Registerer) *metrics {
@@ -43,6 +59,25 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Name:      "authn_successful_login_total",
 			Help:      "Number of successful logins",
 		}, []string{"client"}),
+		clientDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "authn_client_duration_seconds",
+			Help:      "Duration of the authentication of a request by a client, including the post auth hooks",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}, []string{"client", "result"}),
+		clientFallback: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "authn_client_fallback_total",
+			Help:      "Number of failed authentications by a client falling back to the next client",
+		}, []string{"client"}),
+		loginAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "authn_login_attempts_total",
+			Help:      "Number of logins across all clients by result. Logins rejected because of the user, like wrong credentials, are not errors of the login success rate SLO",
+		}, []string{"result"}),
 	}
 
 	if reg != nil {
@@ -51,8 +86,25 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			m.successfulAuth,
 			m.failedLogin,
 			m.successfulLogin,
+			m.clientDuration,
+			m.clientFallback,
+			m.loginAttempts,
 		)
 	}
 
 	return m
 }
+
+// loginResult classifies the error of a login: errors with a client error status are rejected logins, other errors
+// count against the login success rate.
+func loginResult(err error) string {
+	if err == nil {
+		return resultSuccess
+	}
+
+	var grafanaErr errutil.Error
+	if errors.As(err, &grafanaErr) && grafanaErr.Reason.Status().HTTPStatus() < http.StatusInternalServerError {
+		return resultRejected
+	}
+	return resultError
+}
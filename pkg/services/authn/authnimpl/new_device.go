@@ -0,0 +1,98 @@
+package authnimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const tmplNewDeviceLogin = "new_device_login"
+
+// newDeviceNotifier emails the users logging in from a device they have not used before.
+type newDeviceNotifier struct {
+	log            log.Logger
+	sessionService auth.UserTokenService
+	userService    user.Service
+	emailSender    notifications.EmailSender
+}
+
+func (n *newDeviceNotifier) NotifyNewDeviceHook(ctx context.Context, identity *authn.Identity, r *authn.Request, err error) {
+	if err != nil || identity == nil || identity.SessionToken == nil {
+		return
+	}
+	token := identity.SessionToken
+
+	known, err := n.isKnownDevice(ctx, token)
+	if err != nil {
+		n.log.FromContext(ctx).Warn("Failed to check the device of the login", "userID", token.UserId, "error", err)
+		return
+	}
+	if known {
+		return
+	}
+
+	usr, err := n.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: token.UserId})
+	if err != nil {
+		n.log.FromContext(ctx).Warn("Failed to get the user of the login", "userID", token.UserId, "error", err)
+		return
+	}
+	if usr.Email == "" {
+		return
+	}
+
+	err = n.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{usr.Email},
+		Template: tmplNewDeviceLogin,
+		OrgID:    identity.OrgID,
+		UserID:   usr.ID,
+		Data: map[string]any{
+			"Name":        usr.NameOrFallback(),
+			"DeviceName":  auth.ParseDevice(token.UserAgent).Name(),
+			"ClientIP":    token.ClientIp,
+			"Time":        time.Unix(token.CreatedAt, 0).UTC().Format(time.RFC1123),
+			"SessionsUrl": setting.ToAbsUrl("profile"),
+		},
+	})
+	if err != nil {
+		n.log.FromContext(ctx).Warn("Failed to send the new device login email", "userID", usr.ID, "error", err)
+	}
+}
+
+// isKnownDevice returns true if one of the other tokens of the user, active or revoked, was created from
+// the device of the token. The first login of a user is not reported, so its device is known.
+func (n *newDeviceNotifier) isKnownDevice(ctx context.Context, token *auth.UserToken) (bool, error) {
+	active, err := n.sessionService.GetUserTokens(ctx, token.UserId)
+	if err != nil {
+		return false, err
+	}
+	revoked, err := n.sessionService.GetUserRevokedTokens(ctx, token.UserId)
+	if err != nil {
+		return false, err
+	}
+
+	others := 0
+	deviceName := auth.ParseDevice(token.UserAgent).Name()
+	for _, other := range append(active, revoked...) {
+		if other.Id == token.Id {
+			continue
+		}
+		others++
+		// The browsers that send a device ID are recognized by it, the others by their browser and OS.
+		if token.DeviceId != "" && other.DeviceId != "" {
+			if token.DeviceId == other.DeviceId {
+				return true, nil
+			}
+			continue
+		}
+		if auth.ParseDevice(other.UserAgent).Name() == deviceName {
+			return true, nil
+		}
+	}
+	return others == 0, nil
+}
@@ -0,0 +1,90 @@
+package authnimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+const (
+	firefoxLinux = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+	chromeLinux  = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/72.0.3626.119 Safari/537.36"
+)
+
+func TestNewDeviceNotifier_NotifyNewDeviceHook(t *testing.T) {
+	type testCase struct {
+		desc          string
+		token         *auth.UserToken
+		activeTokens  []*auth.UserToken
+		revokedTokens []*auth.UserToken
+		expectedEmail bool
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should not notify the first login",
+			token:        &auth.UserToken{Id: 1, UserId: 1, UserAgent: firefoxLinux},
+			activeTokens: []*auth.UserToken{{Id: 1, UserId: 1, UserAgent: firefoxLinux}},
+		},
+		{
+			desc:         "should not notify a login from a known device id",
+			token:        &auth.UserToken{Id: 2, UserId: 1, UserAgent: chromeLinux, DeviceId: "a"},
+			activeTokens: []*auth.UserToken{{Id: 1, UserId: 1, UserAgent: firefoxLinux, DeviceId: "a"}},
+		},
+		{
+			desc:          "should not notify a login from the browser of a revoked token",
+			token:         &auth.UserToken{Id: 2, UserId: 1, UserAgent: firefoxLinux},
+			revokedTokens: []*auth.UserToken{{Id: 1, UserId: 1, UserAgent: firefoxLinux}},
+		},
+		{
+			desc:          "should notify a login from a new device id",
+			token:         &auth.UserToken{Id: 2, UserId: 1, UserAgent: firefoxLinux, DeviceId: "b"},
+			activeTokens:  []*auth.UserToken{{Id: 1, UserId: 1, UserAgent: firefoxLinux, DeviceId: "a"}},
+			expectedEmail: true,
+		},
+		{
+			desc:          "should notify a login from a new browser",
+			token:         &auth.UserToken{Id: 2, UserId: 1, UserAgent: chromeLinux},
+			activeTokens:  []*auth.UserToken{{Id: 1, UserId: 1, UserAgent: firefoxLinux}},
+			expectedEmail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sessionService := authtest.NewFakeUserAuthTokenService()
+			sessionService.GetUserTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return append(tt.activeTokens, tt.token), nil
+			}
+			sessionService.GetUserRevokedTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return tt.revokedTokens, nil
+			}
+			emailSender := notifications.MockNotificationService()
+
+			notifier := &newDeviceNotifier{
+				log:            log.NewNopLogger(),
+				sessionService: sessionService,
+				userService:    &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Email: "user@grafana.com"}},
+				emailSender:    emailSender,
+			}
+			notifier.NotifyNewDeviceHook(context.Background(), &authn.Identity{SessionToken: tt.token}, &authn.Request{}, nil)
+
+			if !tt.expectedEmail {
+				assert.Empty(t, emailSender.Email.To)
+				return
+			}
+			assert.Equal(t, []string{"user@grafana.com"}, emailSender.Email.To)
+			assert.Equal(t, tmplNewDeviceLogin, emailSender.Email.Template)
+			assert.Equal(t, auth.ParseDevice(tt.token.UserAgent).Name(), emailSender.Email.Data["DeviceName"])
+		})
+	}
+}
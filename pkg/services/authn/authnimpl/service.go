@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
@@ -27,12 +29,17 @@ import (
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/clientcredentials"
 	"github.com/grafana/grafana/pkg/services/signingkeys"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/totp"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
@@ -71,6 +78,8 @@ func ProvideService(
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, registerer prometheus.Registerer,
 	signingKeysService signingkeys.Service, oauthServer oauthserver.OAuth2Server,
+	teamService team.Service, emailSender notifications.EmailSender,
+	saClientCredentials *clientcredentials.Service, totpService totp.Service, webauthnService webauthn.Service,
 ) *Service {
 	s := &Service{
 		log:            log.New("authn.service"),
@@ -82,12 +91,13 @@ func ProvideService(
 		sessionService: sessionService,
 		postAuthHooks:  newQueue[authn.PostAuthHookFn](),
 		postLoginHooks: newQueue[authn.PostLoginHookFn](),
+		stateHandoffs:  &stateHandoffStore{cache: cache},
 	}
 
 	usageStats.RegisterMetricsFunc(s.getUsageStats)
 
 	s.RegisterClient(clients.ProvideRender(userService, renderService))
-	s.RegisterClient(clients.ProvideAPIKey(apikeyService, userService))
+	s.RegisterClient(clients.ProvideAPIKey(cfg, apikeyService, userService, teamService))
 
 	if cfg.LoginCookieName != "" {
 		s.RegisterClient(clients.ProvideSession(cfg, sessionService, features))
@@ -109,7 +119,7 @@ func ProvideService(
 
 	// if we have password clients configure check if basic auth or form auth is enabled
 	if len(passwordClients) > 0 {
-		passwordClient := clients.ProvidePassword(loginAttempts, passwordClients...)
+		passwordClient := clients.ProvidePassword(loginAttempts, totpService, webauthnService, passwordClients...)
 		if s.cfg.BasicAuthEnabled {
 			s.RegisterClient(clients.ProvideBasic(passwordClient))
 		}
@@ -119,6 +129,10 @@ func ProvideService(
 		}
 	}
 
+	if !s.cfg.DisableLogin && webauthnService.IsEnabled() {
+		s.RegisterClient(clients.ProvideWebAuthn(webauthnService, userService))
+	}
+
 	if s.cfg.AuthProxyEnabled && len(proxyClients) > 0 {
 		proxy, err := clients.ProvideProxy(cfg, cache, userService, proxyClients...)
 		if err != nil {
@@ -128,8 +142,8 @@ func ProvideService(
 		}
 	}
 
-	if s.cfg.JWTAuthEnabled {
-		s.RegisterClient(clients.ProvideJWT(jwtService, cfg))
+	if s.cfg.JWTAuthEnabled || saClientCredentials.IsEnabled() {
+		s.RegisterClient(clients.ProvideJWT(jwtService, cfg, saClientCredentials))
 	}
 
 	if s.cfg.ExtendedJWTAuthEnabled && features.IsEnabledGlobally(featuremgmt.FlagExternalServiceAuth) {
@@ -152,7 +166,7 @@ func ProvideService(
 	}
 
 	// FIXME (jguer): move to User package
-	userSyncService := sync.ProvideUserSync(userService, userProtectionService, authInfoService, quotaService)
+	userSyncService := sync.ProvideUserSync(cfg, userService, userProtectionService, authInfoService, quotaService)
 	orgUserSyncService := sync.ProvideOrgSync(userService, orgService, accessControlService)
 	s.RegisterPostAuthHook(userSyncService.SyncUserHook, 10)
 	s.RegisterPostAuthHook(userSyncService.EnableUserHook, 20)
@@ -166,6 +180,16 @@ func ProvideService(
 	s.RegisterPostAuthHook(userSyncService.FetchSyncedUserHook, 100)
 	s.RegisterPostAuthHook(sync.ProvidePermissionsSync(accessControlService).SyncPermissionsHook, 110)
 
+	if cfg.NewDeviceLoginEmail {
+		notifier := &newDeviceNotifier{
+			log:            log.New("authn.new-device"),
+			sessionService: sessionService,
+			userService:    userService,
+			emailSender:    emailSender,
+		}
+		s.RegisterPostLoginHook(notifier.NotifyNewDeviceHook, 50)
+	}
+
 	return s
 }
 
@@ -185,6 +209,8 @@ type Service struct {
 	postAuthHooks *queue[authn.PostAuthHookFn]
 	// postLoginHooks are called after a login request is performed, both for failing and successful requests.
 	postLoginHooks *queue[authn.PostLoginHookFn]
+
+	stateHandoffs *stateHandoffStore
 }
 
 func (s *Service) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
@@ -206,6 +232,7 @@ func (s *Service) Authenticate(ctx context.Context, r *authn.Request) (*authn.Id
 
 				authErr = errors.Join(authErr, err)
 				// try next
+				s.metrics.clientFallback.WithLabelValues(item.v.Name()).Inc()
 				continue
 			}
 
@@ -224,8 +251,17 @@ func (s *Service) Authenticate(ctx context.Context, r *authn.Request) (*authn.Id
 	return nil, errCantAuthenticateReq.Errorf("cannot authenticate request")
 }
 
-func (s *Service) authenticate(ctx context.Context, c authn.Client, r *authn.Request) (*authn.Identity, error) {
-	identity, err := c.Authenticate(ctx, r)
+func (s *Service) authenticate(ctx context.Context, c authn.Client, r *authn.Request) (identity *authn.Identity, err error) {
+	start := time.Now()
+	defer func() {
+		result := resultSuccess
+		if err != nil {
+			result = resultFailure
+		}
+		s.metrics.clientDuration.WithLabelValues(c.Name(), result).Observe(time.Since(start).Seconds())
+	}()
+
+	identity, err = c.Authenticate(ctx, r)
 	if err != nil {
 		s.errorLogFunc(ctx, err)("Failed to authenticate request", "client", c.Name(), "error", err)
 		return nil, err
@@ -272,6 +308,7 @@ func (s *Service) Login(ctx context.Context, client string, r *authn.Request) (i
 	r.OrgID = orgIDFromRequest(r)
 
 	defer func() {
+		s.metrics.loginAttempts.WithLabelValues(loginResult(err)).Inc()
 		for _, hook := range s.postLoginHooks.items {
 			hook.v(ctx, identity, r, err)
 		}
@@ -304,7 +341,12 @@ func (s *Service) Login(ctx context.Context, client string, r *authn.Request) (i
 		s.log.FromContext(ctx).Debug("Failed to parse ip from address", "client", c.Name(), "id", identity.ID, "addr", addr, "error", err)
 	}
 
-	sessionToken, err := s.sessionService.CreateToken(ctx, &user.User{ID: id}, ip, r.HTTPRequest.UserAgent())
+	sessionToken, err := s.sessionService.CreateToken(ctx, &auth.CreateTokenCommand{
+		User:      &user.User{ID: id},
+		ClientIP:  ip,
+		UserAgent: r.HTTPRequest.UserAgent(),
+		DeviceID:  r.HTTPRequest.Header.Get(anonymous.DeviceIDHeader),
+	})
 	if err != nil {
 		s.metrics.failedLogin.WithLabelValues(client).Inc()
 		s.log.FromContext(ctx).Error("Failed to create session", "client", client, "id", identity.ID, "err", err)
@@ -346,6 +388,20 @@ func (s *Service) RegisterClient(c authn.Client) {
 	}
 }
 
+func (s *Service) MintStateHandoff(ctx context.Context, orgID int64, handoff *authn.StateHandoff) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "authn.MintStateHandoff")
+	defer span.End()
+
+	return s.stateHandoffs.mint(ctx, orgID, handoff)
+}
+
+func (s *Service) RedeemStateHandoff(ctx context.Context, orgID int64, token string) (*authn.StateHandoff, error) {
+	ctx, span := s.tracer.Start(ctx, "authn.RedeemStateHandoff")
+	defer span.End()
+
+	return s.stateHandoffs.redeem(ctx, orgID, token)
+}
+
 func (s *Service) SyncIdentity(ctx context.Context, identity *authn.Identity) error {
 	r := &authn.Request{OrgID: identity.OrgID}
 	// hack to not update last seen on external syncs
@@ -3,11 +3,12 @@ package authnimpl
 import (
 	"context"
 	"errors"
-	"net"
 	"net/http"
 	"net/url"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -17,7 +18,6 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
-	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -243,11 +243,11 @@ func TestService_Login(t *testing.T) {
 					ExpectedIdentity: tt.expectedClientIdentity,
 				})
 				svc.sessionService = &authtest.FakeUserAuthTokenService{
-					CreateTokenProvider: func(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+					CreateTokenProvider: func(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
 						if tt.expectedSessionErr != nil {
 							return nil, tt.expectedSessionErr
 						}
-						return &auth.UserToken{UserId: user.ID}, nil
+						return &auth.UserToken{UserId: cmd.User.ID}, nil
 					},
 				}
 			})
@@ -262,6 +262,29 @@ func TestService_Login(t *testing.T) {
 	}
 }
 
+func TestService_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s := setupTests(t, func(svc *Service) {
+		svc.metrics = newMetrics(reg)
+		svc.RegisterClient(&authntest.FakeClient{ExpectedName: "1", ExpectedPriority: 1, ExpectedTest: true, ExpectedErr: errors.New("some error")})
+		svc.RegisterClient(&authntest.FakeClient{ExpectedName: "2", ExpectedPriority: 2, ExpectedTest: true, ExpectedIdentity: &authn.Identity{ID: "user:2"}})
+	})
+
+	_, err := s.Authenticate(context.Background(), &authn.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.clientFallback.WithLabelValues("1")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(s.metrics.clientFallback.WithLabelValues("2")))
+	assert.Equal(t, 2, testutil.CollectAndCount(s.metrics.clientDuration))
+
+	_, err = s.Login(context.Background(), "invalid", &authn.Request{})
+	require.ErrorIs(t, err, authn.ErrClientNotConfigured)
+	_, err = s.Login(context.Background(), "1", &authn.Request{})
+	require.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.loginAttempts.WithLabelValues(resultRejected)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.loginAttempts.WithLabelValues(resultError)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(s.metrics.loginAttempts.WithLabelValues(resultSuccess)))
+}
+
 func TestService_RedirectURL(t *testing.T) {
 	type testCase struct {
 		desc        string
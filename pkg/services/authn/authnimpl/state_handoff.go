@@ -0,0 +1,96 @@
+package authnimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// stateHandoffTTL is how long a state handoff token can be redeemed, it only needs to outlive a login.
+	stateHandoffTTL = 10 * time.Minute
+	// stateHandoffMaxSize is the maximum size of the stored state of a handoff.
+	stateHandoffMaxSize     = 64 * 1024
+	stateHandoffTokenLength = 32
+	stateHandoffCachePrefix = "authn-state-handoff-"
+	stateHandoffClaimSuffix = "-claim"
+)
+
+type storedStateHandoff struct {
+	OrgID int64 `json:"orgId"`
+	authn.StateHandoff
+}
+
+// stateHandoffStore stores the state of anonymous sessions until the users log in.
+// Only the hash of a token is used as key, so that the tokens cannot be read from the cache.
+type stateHandoffStore struct {
+	cache remotecache.CacheStorage
+}
+
+func (s *stateHandoffStore) mint(ctx context.Context, orgID int64, handoff *authn.StateHandoff) (string, error) {
+	value, err := json.Marshal(storedStateHandoff{OrgID: orgID, StateHandoff: *handoff})
+	if err != nil {
+		return "", err
+	}
+	if len(value) > stateHandoffMaxSize {
+		return "", authn.ErrStateHandoffTooLarge.Errorf("state handoff of %d bytes exceeds the limit of %d bytes", len(value), stateHandoffMaxSize)
+	}
+
+	token, err := util.GetRandomString(stateHandoffTokenLength)
+	if err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, stateHandoffKey(token), value, stateHandoffTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *stateHandoffStore) redeem(ctx context.Context, orgID int64, token string) (*authn.StateHandoff, error) {
+	if token == "" {
+		return nil, authn.ErrStateHandoffNotFound.Errorf("empty state handoff token")
+	}
+
+	key := stateHandoffKey(token)
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, authn.ErrStateHandoffNotFound.Errorf("state handoff token not found")
+		}
+		return nil, err
+	}
+	var stored storedStateHandoff
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, err
+	}
+	// A user of another org cannot invalidate the token.
+	if stored.OrgID != orgID {
+		return nil, authn.ErrStateHandoffNotFound.Errorf("state handoff token minted in org %d", stored.OrgID)
+	}
+	// Only the first redeem claims the token, so that concurrent redeems of the token cannot all return the
+	// state. The claim expires after the token.
+	claims, err := s.cache.Increment(ctx, key+stateHandoffClaimSuffix, stateHandoffTTL)
+	if err != nil {
+		return nil, err
+	}
+	if claims != 1 {
+		return nil, authn.ErrStateHandoffNotFound.Errorf("state handoff token already redeemed")
+	}
+	// The token is invalidated before the state is returned so that it cannot be redeemed twice.
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+	return &stored.StateHandoff, nil
+}
+
+func stateHandoffKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return stateHandoffCachePrefix + hex.EncodeToString(hash[:])
+}
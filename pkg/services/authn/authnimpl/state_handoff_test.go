@@ -0,0 +1,105 @@
+package authnimpl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+)
+
+func TestService_StateHandoff(t *testing.T) {
+	cache := remotecache.NewFakeCacheStorage()
+	s := setupTests(t, func(svc *Service) {
+		svc.stateHandoffs = &stateHandoffStore{cache: cache}
+	})
+	ctx := context.Background()
+	handoff := &authn.StateHandoff{URL: "/d/abc?orgId=1&viewPanel=2", State: json.RawMessage(`{"panel":{"title":"Unsaved"}}`)}
+
+	token, err := s.MintStateHandoff(ctx, 1, handoff)
+	require.NoError(t, err)
+	require.Len(t, token, stateHandoffTokenLength)
+	for key := range cache.Storage {
+		assert.NotContains(t, key, token, "the token should not be readable from the cache")
+	}
+
+	t.Run("should not redeem a token in another org", func(t *testing.T) {
+		other, err := s.MintStateHandoff(ctx, 1, handoff)
+		require.NoError(t, err)
+		_, err = s.RedeemStateHandoff(ctx, 2, other)
+		assert.ErrorIs(t, err, authn.ErrStateHandoffNotFound)
+		_, err = s.RedeemStateHandoff(ctx, 1, other)
+		assert.NoError(t, err, "the token should still be redeemable in its org")
+	})
+
+	redeemed, err := s.RedeemStateHandoff(ctx, 1, token)
+	require.NoError(t, err)
+	assert.Equal(t, handoff, redeemed)
+
+	_, err = s.RedeemStateHandoff(ctx, 1, token)
+	assert.ErrorIs(t, err, authn.ErrStateHandoffNotFound, "a token should only be redeemed once")
+
+	_, err = s.RedeemStateHandoff(ctx, 1, "")
+	assert.ErrorIs(t, err, authn.ErrStateHandoffNotFound)
+
+	_, err = s.MintStateHandoff(ctx, 1, &authn.StateHandoff{
+		URL:   "/d/abc",
+		State: json.RawMessage(`"` + strings.Repeat("a", stateHandoffMaxSize) + `"`),
+	})
+	assert.ErrorIs(t, err, authn.ErrStateHandoffTooLarge)
+}
+
+// readBarrierCache returns the items of the cache once all the expected readers read them, so that concurrent
+// readers all read the items before any of them updates the cache.
+type readBarrierCache struct {
+	remotecache.CacheStorage
+	readers *sync.WaitGroup
+}
+
+func (c *readBarrierCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.CacheStorage.Get(ctx, key)
+	c.readers.Done()
+	c.readers.Wait()
+	return value, err
+}
+
+func TestIntegrationService_StateHandoffConcurrentRedeem(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	const concurrency = 10
+	readers := &sync.WaitGroup{}
+	readers.Add(concurrency)
+	s := setupTests(t, func(svc *Service) {
+		svc.stateHandoffs = &stateHandoffStore{cache: &readBarrierCache{CacheStorage: remotecache.NewFakeStore(t), readers: readers}}
+	})
+	ctx := context.Background()
+
+	token, err := s.MintStateHandoff(ctx, 1, &authn.StateHandoff{URL: "/d/abc"})
+	require.NoError(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		redeemed int
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.RedeemStateHandoff(ctx, 1, token); err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, redeemed, "a token should only be redeemed by one of concurrent requests")
+}
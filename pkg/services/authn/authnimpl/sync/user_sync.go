@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -39,6 +40,10 @@ var (
 		"user.sync.fetch-not-found",
 		errutil.WithPublicMessage("User not found"),
 	)
+	errUserIdentityConflict = errutil.Forbidden(
+		"user.sync.identity-conflict",
+		errutil.WithPublicMessage("A user with the same email or login is already linked to another identity provider"),
+	)
 )
 
 var (
@@ -47,10 +52,11 @@ var (
 	errSignupNotAllowed  = errors.New("system administrator has disabled signup")
 )
 
-func ProvideUserSync(userService user.Service,
+func ProvideUserSync(cfg *setting.Cfg, userService user.Service,
 	userProtectionService login.UserProtectionService,
 	authInfoService login.AuthInfoService, quotaService quota.Service) *UserSync {
 	return &UserSync{
+		cfg:                   cfg,
 		userService:           userService,
 		authInfoService:       authInfoService,
 		userProtectionService: userProtectionService,
@@ -60,6 +66,7 @@ func ProvideUserSync(userService user.Service,
 }
 
 type UserSync struct {
+	cfg                   *setting.Cfg
 	userService           user.Service
 	authInfoService       login.AuthInfoService
 	userProtectionService login.UserProtectionService
@@ -94,8 +101,14 @@ func (s *UserSync) SyncUserHook(ctx context.Context, id *authn.Identity, _ *auth
 			return errSyncUserInternal.Errorf("unable to create user: %w", errCreate)
 		}
 	} else {
+		isPrimary, errPrimary := s.resolvePrimaryIdentity(ctx, usr.ID, id, userAuth)
+		if errPrimary != nil {
+			s.log.FromContext(ctx).Warn("Failed to link identity to user", "error", errPrimary, "auth_module", id.AuthenticatedBy, "auth_id", id.AuthID)
+			return errPrimary
+		}
+
 		// update user
-		if errUpdate := s.updateUserAttributes(ctx, usr, id, userAuth); errUpdate != nil {
+		if errUpdate := s.updateUserAttributes(ctx, usr, id, userAuth, isPrimary); errUpdate != nil {
 			s.log.FromContext(ctx).Error("Failed to update user", "error", errUpdate, "auth_module", id.AuthenticatedBy, "auth_id", id.AuthID)
 			return errSyncUserInternal.Errorf("unable to update user")
 		}
@@ -176,7 +189,47 @@ func (s *UserSync) EnableUserHook(ctx context.Context, identity *authn.Identity,
 	return s.userService.Disable(ctx, &user.DisableUserCommand{UserID: id, IsDisabled: false})
 }
 
-func (s *UserSync) upsertAuthConnection(ctx context.Context, userID int64, identity *authn.Identity, createConnection bool) error {
+// resolvePrimaryIdentity returns true if the user is synced from the identity. The attributes and the
+// roles of users linked to identities of several providers are synced from their primary identity
+// only, the first identity linked to a user is primary.
+func (s *UserSync) resolvePrimaryIdentity(ctx context.Context, userID int64, identity *authn.Identity, userAuth *login.UserAuth) (bool, error) {
+	if identity.AuthenticatedBy == "" || (userAuth != nil && userAuth.IsPrimary) {
+		return true, nil
+	}
+
+	userAuths, err := s.authInfoService.GetUserAuthInfos(ctx, &login.GetUserAuthInfosQuery{UserID: userID})
+	if err != nil {
+		return false, errSyncUserInternal.Errorf("unable to retrieve user identities: %w", err)
+	}
+
+	if userAuth == nil {
+		// The identity matched an existing user by its email or login
+		if len(userAuths) == 0 {
+			return true, nil
+		}
+		if s.cfg.IdentityEmailConflict == setting.IdentityEmailConflictReject {
+			return false, errUserIdentityConflict.Errorf("user %d is already linked to a %s identity", userID, userAuths[0].AuthModule)
+		}
+		return false, nil
+	}
+
+	for _, ua := range userAuths {
+		if ua.IsPrimary {
+			return false, nil
+		}
+	}
+
+	// Users linked to identities before they could be primary are synced from the identity they log in with next
+	if err := s.authInfoService.SetPrimaryAuthInfo(ctx, &login.SetPrimaryAuthInfoCommand{
+		UserID:     userID,
+		AuthModule: identity.AuthenticatedBy,
+	}); err != nil {
+		return false, errSyncUserInternal.Errorf("unable to set primary identity: %w", err)
+	}
+	return true, nil
+}
+
+func (s *UserSync) upsertAuthConnection(ctx context.Context, userID int64, identity *authn.Identity, createConnection, isPrimary bool) error {
 	if identity.AuthenticatedBy == "" {
 		return nil
 	}
@@ -190,6 +243,7 @@ func (s *UserSync) upsertAuthConnection(ctx context.Context, userID int64, ident
 			AuthModule: identity.AuthenticatedBy,
 			AuthId:     identity.AuthID,
 			OAuthToken: identity.OAuthToken,
+			IsPrimary:  isPrimary,
 		})
 	}
 
@@ -202,10 +256,18 @@ func (s *UserSync) upsertAuthConnection(ctx context.Context, userID int64, ident
 	})
 }
 
-func (s *UserSync) updateUserAttributes(ctx context.Context, usr *user.User, id *authn.Identity, userAuth *login.UserAuth) error {
+func (s *UserSync) updateUserAttributes(ctx context.Context, usr *user.User, id *authn.Identity, userAuth *login.UserAuth, isPrimary bool) error {
 	if errProtection := s.userProtectionService.AllowUserMapping(usr, id.AuthenticatedBy); errProtection != nil {
 		return errUserProtection.Errorf("user mapping not allowed: %w", errProtection)
 	}
+
+	if !isPrimary {
+		s.log.FromContext(ctx).Debug("Skipping sync of a secondary identity", "id", id.ID, "auth_module", id.AuthenticatedBy)
+		id.ClientParams.SyncOrgRoles = false
+		id.ClientParams.SyncTeams = false
+		return s.upsertAuthConnection(ctx, usr.ID, id, userAuth == nil, false)
+	}
+
 	// sync user info
 	updateCmd := &user.UpdateUserCommand{
 		UserID: usr.ID,
@@ -245,7 +307,7 @@ func (s *UserSync) updateUserAttributes(ctx context.Context, usr *user.User, id
 		}
 	}
 
-	return s.upsertAuthConnection(ctx, usr.ID, id, userAuth == nil)
+	return s.upsertAuthConnection(ctx, usr.ID, id, userAuth == nil, true)
 }
 
 func (s *UserSync) createUser(ctx context.Context, id *authn.Identity) (*user.User, error) {
@@ -279,7 +341,7 @@ func (s *UserSync) createUser(ctx context.Context, id *authn.Identity) (*user.Us
 		return nil, errCreateUser
 	}
 
-	err := s.upsertAuthConnection(ctx, usr.ID, id, true)
+	err := s.upsertAuthConnection(ctx, usr.ID, id, true, true)
 	if err != nil {
 		return nil, err
 	}
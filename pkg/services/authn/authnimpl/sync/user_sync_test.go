@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ptrString(s string) *string {
@@ -427,7 +428,7 @@ func TestUserSync_SyncUserHook(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := ProvideUserSync(tt.fields.userService, userProtection, tt.fields.authInfoService, tt.fields.quotaService)
+			s := ProvideUserSync(setting.NewCfg(), tt.fields.userService, userProtection, tt.fields.authInfoService, tt.fields.quotaService)
 			err := s.SyncUserHook(tt.args.ctx, tt.args.id, nil)
 			if tt.wantErr {
 				require.Error(t, err)
@@ -440,6 +441,134 @@ func TestUserSync_SyncUserHook(t *testing.T) {
 	}
 }
 
+func TestUserSync_SyncUserHook_MultipleIdentities(t *testing.T) {
+	newIdentity := func() *authn.Identity {
+		return &authn.Identity{
+			AuthenticatedBy: login.GithubAuthModule,
+			AuthID:          "gh-42",
+			Login:           "octocat",
+			Email:           "jane@example.com",
+			Name:            "The Octocat",
+			ClientParams: authn.ClientParams{
+				SyncUser:     true,
+				SyncOrgRoles: true,
+				SyncTeams:    true,
+				LookUpParams: login.UserLookupParams{Email: ptrString("jane@example.com")},
+			},
+		}
+	}
+	newUserService := func() *usertest.FakeUserService {
+		return &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "jane", Email: "jane@example.com", Name: "Jane"}}
+	}
+	notLinked := func(ctx context.Context, query *login.GetAuthInfoQuery) (*login.UserAuth, error) {
+		return nil, user.ErrUserNotFound
+	}
+
+	t.Run("secondary identity does not update the user", func(t *testing.T) {
+		var updated *login.UpdateAuthInfoCommand
+		authInfoService := &authinfotest.FakeService{
+			ExpectedUserAuth: &login.UserAuth{UserId: 1, AuthModule: login.GithubAuthModule, AuthId: "gh-42"},
+			ExpectedUserAuths: []*login.UserAuth{
+				{UserId: 1, AuthModule: login.AzureADAuthModule, IsPrimary: true},
+				{UserId: 1, AuthModule: login.GithubAuthModule},
+			},
+			UpdateAuthInfoFn: func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error {
+				updated = cmd
+				return nil
+			},
+		}
+		s := ProvideUserSync(setting.NewCfg(), newUserService(), &authinfoimpl.OSSUserProtectionImpl{}, authInfoService, &quotatest.FakeQuotaService{})
+
+		id := newIdentity()
+		require.NoError(t, s.SyncUserHook(context.Background(), id, nil))
+		assert.Equal(t, "user:1", id.ID)
+		assert.Equal(t, "jane", id.Login)
+		assert.Equal(t, "Jane", id.Name)
+		assert.False(t, id.ClientParams.SyncOrgRoles)
+		assert.False(t, id.ClientParams.SyncTeams)
+		require.NotNil(t, updated)
+		assert.Equal(t, login.GithubAuthModule, updated.AuthModule)
+	})
+
+	t.Run("identity of a user without primary identity becomes primary", func(t *testing.T) {
+		var primary *login.SetPrimaryAuthInfoCommand
+		authInfoService := &authinfotest.FakeService{
+			ExpectedUserAuth:  &login.UserAuth{UserId: 1, AuthModule: login.GithubAuthModule, AuthId: "gh-42"},
+			ExpectedUserAuths: []*login.UserAuth{{UserId: 1, AuthModule: login.GithubAuthModule}},
+			SetPrimaryAuthInfoFn: func(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error {
+				primary = cmd
+				return nil
+			},
+			UpdateAuthInfoFn: func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error { return nil },
+		}
+		s := ProvideUserSync(setting.NewCfg(), newUserService(), &authinfoimpl.OSSUserProtectionImpl{}, authInfoService, &quotatest.FakeQuotaService{})
+
+		id := newIdentity()
+		require.NoError(t, s.SyncUserHook(context.Background(), id, nil))
+		require.NotNil(t, primary)
+		assert.Equal(t, &login.SetPrimaryAuthInfoCommand{UserID: 1, AuthModule: login.GithubAuthModule}, primary)
+		assert.Equal(t, "octocat", id.Login)
+		assert.True(t, id.ClientParams.SyncOrgRoles)
+	})
+
+	t.Run("email collision links a secondary identity", func(t *testing.T) {
+		var linked *login.SetAuthInfoCommand
+		authInfoService := &authinfotest.FakeService{
+			GetAuthInfoFn:     notLinked,
+			ExpectedUserAuths: []*login.UserAuth{{UserId: 1, AuthModule: login.AzureADAuthModule, IsPrimary: true}},
+			SetAuthInfoFn: func(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
+				linked = cmd
+				return nil
+			},
+		}
+		s := ProvideUserSync(setting.NewCfg(), newUserService(), &authinfoimpl.OSSUserProtectionImpl{}, authInfoService, &quotatest.FakeQuotaService{})
+
+		id := newIdentity()
+		require.NoError(t, s.SyncUserHook(context.Background(), id, nil))
+		require.NotNil(t, linked)
+		assert.Equal(t, login.GithubAuthModule, linked.AuthModule)
+		assert.False(t, linked.IsPrimary)
+		assert.Equal(t, "jane", id.Login)
+	})
+
+	t.Run("email collision of a user without identity links a primary identity", func(t *testing.T) {
+		var linked *login.SetAuthInfoCommand
+		authInfoService := &authinfotest.FakeService{
+			GetAuthInfoFn: notLinked,
+			SetAuthInfoFn: func(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
+				linked = cmd
+				return nil
+			},
+		}
+		cfg := setting.NewCfg()
+		cfg.IdentityEmailConflict = setting.IdentityEmailConflictReject
+		s := ProvideUserSync(cfg, newUserService(), &authinfoimpl.OSSUserProtectionImpl{}, authInfoService, &quotatest.FakeQuotaService{})
+
+		id := newIdentity()
+		require.NoError(t, s.SyncUserHook(context.Background(), id, nil))
+		require.NotNil(t, linked)
+		assert.True(t, linked.IsPrimary)
+		assert.Equal(t, "octocat", id.Login)
+	})
+
+	t.Run("email collision is rejected", func(t *testing.T) {
+		authInfoService := &authinfotest.FakeService{
+			GetAuthInfoFn:     notLinked,
+			ExpectedUserAuths: []*login.UserAuth{{UserId: 1, AuthModule: login.AzureADAuthModule, IsPrimary: true}},
+			SetAuthInfoFn: func(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
+				t.Fatal("identity must not be linked")
+				return nil
+			},
+		}
+		cfg := setting.NewCfg()
+		cfg.IdentityEmailConflict = setting.IdentityEmailConflictReject
+		s := ProvideUserSync(cfg, newUserService(), &authinfoimpl.OSSUserProtectionImpl{}, authInfoService, &quotatest.FakeQuotaService{})
+
+		err := s.SyncUserHook(context.Background(), newIdentity(), nil)
+		assert.ErrorIs(t, err, errUserIdentityConflict)
+	})
+}
+
 func TestUserSync_FetchSyncedUserHook(t *testing.T) {
 	type testCase struct {
 		desc        string
@@ -13,6 +13,8 @@ type FakeService struct {
 	ExpectedErr        error
 	ExpectedRedirect   *authn.Redirect
 	ExpectedIdentity   *authn.Identity
+	ExpectedToken      string
+	ExpectedHandoff    *authn.StateHandoff
 	ExpectedErrs       []error
 	ExpectedIdentities []*authn.Identity
 	CurrentIndex       int
@@ -68,6 +70,14 @@ func (f *FakeService) RedirectURL(ctx context.Context, client string, r *authn.R
 
 func (f *FakeService) RegisterClient(c authn.Client) {}
 
+func (f *FakeService) MintStateHandoff(ctx context.Context, orgID int64, handoff *authn.StateHandoff) (string, error) {
+	return f.ExpectedToken, f.ExpectedErr
+}
+
+func (f *FakeService) RedeemStateHandoff(ctx context.Context, orgID int64, token string) (*authn.StateHandoff, error) {
+	return f.ExpectedHandoff, f.ExpectedErr
+}
+
 func (f *FakeService) SyncIdentity(ctx context.Context, identity *authn.Identity) error {
 	return f.ExpectedErr
 }
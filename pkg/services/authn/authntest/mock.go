@@ -12,6 +12,7 @@ var _ authn.IdentitySynchronizer = new(MockService)
 type MockService struct {
 	SyncIdentityFunc         func(ctx context.Context, identity *authn.Identity) error
 	RegisterPostAuthHookFunc func(hook authn.PostAuthHookFn, priority uint)
+	RedeemStateHandoffFunc   func(ctx context.Context, orgID int64, token string) (*authn.StateHandoff, error)
 }
 
 func (m *MockService) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
@@ -40,6 +41,17 @@ func (m *MockService) RegisterPostLoginHook(hook authn.PostLoginHookFn, priority
 	panic("unimplemented")
 }
 
+func (m *MockService) MintStateHandoff(ctx context.Context, orgID int64, handoff *authn.StateHandoff) (string, error) {
+	panic("unimplemented")
+}
+
+func (m *MockService) RedeemStateHandoff(ctx context.Context, orgID int64, token string) (*authn.StateHandoff, error) {
+	if m.RedeemStateHandoffFunc != nil {
+		return m.RedeemStateHandoffFunc(ctx, orgID, token)
+	}
+	panic("unimplemented")
+}
+
 func (m *MockService) SyncIdentity(ctx context.Context, identity *authn.Identity) error {
 	if m.SyncIdentityFunc != nil {
 		return m.SyncIdentityFunc(ctx, identity)
@@ -13,7 +13,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
@@ -23,23 +26,28 @@ var (
 	errAPIKeyExpired     = errutil.Unauthorized("api-key.expired", errutil.WithPublicMessage("Expired API key"))
 	errAPIKeyRevoked     = errutil.Unauthorized("api-key.revoked", errutil.WithPublicMessage("Revoked API key"))
 	errAPIKeyOrgMismatch = errutil.Unauthorized("api-key.organization-mismatch", errutil.WithPublicMessage("API key does not belong to the requested organization"))
+	errAPIKeyInactive    = errutil.Unauthorized("api-key.inactive", errutil.WithPublicMessage("API key was not used for too long"))
 )
 
 var _ authn.HookClient = new(APIKey)
 var _ authn.ContextAwareClient = new(APIKey)
 
-func ProvideAPIKey(apiKeyService apikey.Service, userService user.Service) *APIKey {
+func ProvideAPIKey(cfg *setting.Cfg, apiKeyService apikey.Service, userService user.Service, teamService team.Service) *APIKey {
 	return &APIKey{
+		cfg:           cfg,
 		log:           log.New(authn.ClientAPIKey),
 		userService:   userService,
 		apiKeyService: apiKeyService,
+		teamService:   teamService,
 	}
 }
 
 type APIKey struct {
+	cfg           *setting.Cfg
 	log           log.Logger
 	userService   user.Service
 	apiKeyService apikey.Service
+	teamService   team.Service
 }
 
 func (s *APIKey) Name() string {
@@ -69,6 +77,10 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 		return nil, errAPIKeyOrgMismatch.Errorf("API does not belong in Organization %v", r.OrgID)
 	}
 
+	if apiKey.TeamID != nil && *apiKey.TeamID > 0 {
+		return s.teamTokenIdentity(ctx, apiKey)
+	}
+
 	// if the api key don't belong to a service account construct the identity and return it
 	if apiKey.ServiceAccountId == nil || *apiKey.ServiceAccountId < 1 {
 		return &authn.Identity{
@@ -92,6 +104,33 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 	return authn.IdentityFromSignedInUser(authn.NamespacedID(authn.NamespaceServiceAccount, usr.UserID), usr, authn.ClientParams{SyncPermissions: true}, login.APIKeyAuthModule), nil
 }
 
+// teamTokenIdentity returns the identity of a team token. It has no basic role so that its permissions are the ones
+// of the role assignments of the team, and the team as login to attribute its actions to the team.
+func (s *APIKey) teamTokenIdentity(ctx context.Context, apiKey *apikey.APIKey) (*authn.Identity, error) {
+	if teamtoken.IsInactive(apiKey, s.cfg.TeamTokenInactivityDayLimit, time.Now()) {
+		return nil, errAPIKeyInactive.Errorf("team token was not used for more than %d days", s.cfg.TeamTokenInactivityDayLimit)
+	}
+
+	t, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: apiKey.OrgID, ID: *apiKey.TeamID})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, errAPIKeyInvalid.Errorf("team of the API key does not exist")
+		}
+		return nil, err
+	}
+
+	return &authn.Identity{
+		ID:              authn.NamespacedID(authn.NamespaceAPIKey, apiKey.ID),
+		OrgID:           apiKey.OrgID,
+		OrgRoles:        map[int64]org.RoleType{apiKey.OrgID: org.RoleNone},
+		Teams:           []int64{t.ID},
+		Login:           "team:" + t.Name,
+		Name:            apiKey.Name,
+		ClientParams:    authn.ClientParams{SyncPermissions: true},
+		AuthenticatedBy: login.APIKeyAuthModule,
+	}, nil
+}
+
 func (s *APIKey) getAPIKey(ctx context.Context, token string) (*apikey.APIKey, error) {
 	fn := s.getFromToken
 	if !strings.HasPrefix(token, satokengen.GrafanaPrefix) {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -16,8 +17,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -31,10 +35,13 @@ func TestAPIKey_Authenticate(t *testing.T) {
 		req              *authn.Request
 		expectedKey      *apikey.APIKey
 		expectedUser     *user.SignedInUser
+		expectedTeam     *team.TeamDTO
 		expectedErr      error
 		expectedIdentity *authn.Identity
 	}
 
+	longAgo := time.Now().AddDate(0, 0, -31)
+
 	tests := []TestCase{
 		{
 			desc: "should success for valid token that is not connected to a service account",
@@ -120,14 +127,58 @@ func TestAPIKey_Authenticate(t *testing.T) {
 			},
 			expectedErr: errAPIKeyOrgMismatch,
 		},
+		{
+			desc: "should success for valid token that is owned by a team",
+			req:  &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{"Authorization": {"Bearer " + secret}}}},
+			expectedKey: &apikey.APIKey{
+				ID:      1,
+				OrgID:   1,
+				Name:    "deploy",
+				Key:     hash,
+				Role:    org.RoleNone,
+				TeamID:  intPtr(2),
+				Created: time.Now(),
+			},
+			expectedTeam: &team.TeamDTO{ID: 2, OrgID: 1, Name: "platform"},
+			expectedIdentity: &authn.Identity{
+				ID:       "api-key:1",
+				OrgID:    1,
+				OrgRoles: map[int64]org.RoleType{1: org.RoleNone},
+				Teams:    []int64{2},
+				Login:    "team:platform",
+				Name:     "deploy",
+				ClientParams: authn.ClientParams{
+					SyncPermissions: true,
+				},
+				AuthenticatedBy: login.APIKeyAuthModule,
+			},
+		},
+		{
+			desc: "should fail for team token not used for longer than the inactivity limit",
+			req:  &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{"Authorization": {"Bearer " + secret}}}},
+			expectedKey: &apikey.APIKey{
+				ID:         1,
+				OrgID:      1,
+				Key:        hash,
+				TeamID:     intPtr(2),
+				Created:    longAgo,
+				LastUsedAt: &longAgo,
+			},
+			expectedTeam: &team.TeamDTO{ID: 2, OrgID: 1, Name: "platform"},
+			expectedErr:  errAPIKeyInactive,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{
+			cfg := setting.NewCfg()
+			cfg.TeamTokenInactivityDayLimit = 30
+			c := ProvideAPIKey(cfg, &apikeytest.Service{
 				ExpectedAPIKey: tt.expectedKey,
 			}, &usertest.FakeUserService{
 				ExpectedSignedInUser: tt.expectedUser,
+			}, &teamtest.FakeService{
+				ExpectedTeamDTO: tt.expectedTeam,
 			})
 
 			identity, err := c.Authenticate(context.Background(), tt.req)
@@ -195,7 +246,7 @@ func TestAPIKey_Test(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{}, usertest.NewUserServiceFake())
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{}, usertest.NewUserServiceFake(), teamtest.NewFakeService())
 			assert.Equal(t, tt.expected, c.Test(context.Background(), tt.req))
 		})
 	}
@@ -294,12 +345,12 @@ func TestAPIKey_GetAPIKeyIDFromIdentity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{
 				ExpectedError:  tt.expectedError,
 				ExpectedAPIKey: tt.expectedKey,
 			}, &usertest.FakeUserService{
 				ExpectedSignedInUser: signedInUser,
-			})
+			}, teamtest.NewFakeService())
 			id, exists := c.getAPIKeyID(context.Background(), tt.expectedIdentity, req)
 			assert.Equal(t, tt.expectedExists, exists)
 			assert.Equal(t, tt.expectedKeyID, id)
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -25,6 +26,10 @@ type Form struct {
 type loginForm struct {
 	Username string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
+	// TOTPCode is the code of the authenticator app or a recovery code, for the users with two-factor authentication.
+	TOTPCode string `json:"totpCode"`
+	// WebAuthnAssertion is the passkey assertion, for the users with a passkey when passkeys are a second factor.
+	WebAuthnAssertion json.RawMessage `json:"webauthnAssertion"`
 }
 
 func (c *Form) Name() string {
@@ -36,5 +41,11 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 	if err := web.Bind(r.HTTPRequest, &form); err != nil {
 		return nil, errBadForm.Errorf("failed to parse request: %w", err)
 	}
+	if form.TOTPCode != "" {
+		r.SetMeta(authn.MetaKeyTOTPCode, form.TOTPCode)
+	}
+	if len(form.WebAuthnAssertion) > 0 && string(form.WebAuthnAssertion) != "null" {
+		r.SetMeta(authn.MetaKeyWebAuthnAssertion, string(form.WebAuthnAssertion))
+	}
 	return c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
 }
@@ -32,11 +32,25 @@ var (
 		"jwt.invalid_role", errutil.WithPublicMessage("Invalid Role in claim"))
 )
 
-func ProvideJWT(jwtService auth.JWTVerifierService, cfg *setting.Cfg) *JWT {
+func init() {
+	// The role claim is only validated when role_attribute_strict is enabled.
+	errutil.RegisterCode("auth.role-strict-denied", errJWTInvalidRole)
+}
+
+// ServiceAccountTokenVerifier verifies the access tokens that Grafana issues to service accounts with
+// the OAuth2 client credentials grant.
+type ServiceAccountTokenVerifier interface {
+	// IsServiceAccountToken returns true if the token was issued to a service account, without verifying it.
+	IsServiceAccountToken(rawToken string) bool
+	VerifyServiceAccountToken(ctx context.Context, rawToken string) (*authn.Identity, error)
+}
+
+func ProvideJWT(jwtService auth.JWTVerifierService, cfg *setting.Cfg, saTokens ServiceAccountTokenVerifier) *JWT {
 	return &JWT{
 		cfg:        cfg,
 		log:        log.New(authn.ClientJWT),
 		jwtService: jwtService,
+		saTokens:   saTokens,
 	}
 }
 
@@ -44,6 +58,7 @@ type JWT struct {
 	cfg        *setting.Cfg
 	log        log.Logger
 	jwtService auth.JWTVerifierService
+	saTokens   ServiceAccountTokenVerifier
 }
 
 func (s *JWT) Name() string {
@@ -51,6 +66,15 @@ func (s *JWT) Name() string {
 }
 
 func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	if saToken := s.retrieveServiceAccountToken(r.HTTPRequest); saToken != "" {
+		id, err := s.saTokens.VerifyServiceAccountToken(ctx, saToken)
+		if err != nil {
+			s.log.FromContext(ctx).Debug("Failed to verify service account JWT", "error", err)
+			return nil, errJWTInvalid.Errorf("failed to verify JWT: %w", err)
+		}
+		return id, nil
+	}
+
 	jwtToken := s.retrieveToken(r.HTTPRequest)
 	s.stripSensitiveParam(r.HTTPRequest)
 
@@ -145,7 +169,24 @@ func (s *JWT) retrieveToken(httpRequest *http.Request) string {
 	return strings.TrimPrefix(jwtToken, "Bearer ")
 }
 
+// retrieveServiceAccountToken returns the bearer token of the Authorization header if it is an access
+// token issued to a service account.
+func (s *JWT) retrieveServiceAccountToken(httpRequest *http.Request) string {
+	if s.saTokens == nil {
+		return ""
+	}
+	token, ok := strings.CutPrefix(httpRequest.Header.Get("Authorization"), "Bearer ")
+	if !ok || !s.saTokens.IsServiceAccountToken(token) {
+		return ""
+	}
+	return token
+}
+
 func (s *JWT) Test(ctx context.Context, r *authn.Request) bool {
+	if s.retrieveServiceAccountToken(r.HTTPRequest) != "" {
+		return true
+	}
+
 	if !s.cfg.JWTAuthEnabled || s.cfg.JWTAuthHeaderName == "" {
 		return false
 	}
@@ -71,7 +71,7 @@ func TestAuthenticateJWT(t *testing.T) {
 		JWTAuthRoleAttributeStrict:     true,
 		JWTAuthRoleAttributePath:       "roles",
 	}
-	jwtClient := ProvideJWT(jwtService, cfg)
+	jwtClient := ProvideJWT(jwtService, cfg, nil)
 	validHTTPReq := &http.Request{
 		Header: map[string][]string{
 			jwtHeaderName: {"sample-token"}},
@@ -184,7 +184,7 @@ func TestJWTClaimConfig(t *testing.T) {
 			Header: map[string][]string{
 				jwtHeaderName: {token}},
 		}
-		jwtClient := ProvideJWT(jwtService, cfg)
+		jwtClient := ProvideJWT(jwtService, cfg, nil)
 		_, err := jwtClient.Authenticate(context.Background(), &authn.Request{
 			OrgID:       1,
 			HTTPRequest: httpReq,
@@ -289,7 +289,7 @@ func TestJWTTest(t *testing.T) {
 				JWTAuthAllowAssignGrafanaAdmin: true,
 				JWTAuthRoleAttributeStrict:     true,
 			}
-			jwtClient := ProvideJWT(jwtService, cfg)
+			jwtClient := ProvideJWT(jwtService, cfg, nil)
 			httpReq := &http.Request{
 				URL: &url.URL{RawQuery: "auth_token=" + tc.token},
 				Header: map[string][]string{
@@ -340,7 +340,7 @@ func TestJWTStripParam(t *testing.T) {
 	httpReq := &http.Request{
 		URL: &url.URL{RawQuery: "auth_token=" + token + "&other_param=other_value"},
 	}
-	jwtClient := ProvideJWT(jwtService, cfg)
+	jwtClient := ProvideJWT(jwtService, cfg, nil)
 	_, err := jwtClient.Authenticate(context.Background(), &authn.Request{
 		OrgID:       1,
 		HTTPRequest: httpReq,
@@ -350,3 +350,53 @@ func TestJWTStripParam(t *testing.T) {
 	// auth_token should be removed from the query string
 	assert.Equal(t, "other_param=other_value", httpReq.URL.RawQuery)
 }
+
+type fakeServiceAccountTokenVerifier struct {
+	token            string
+	expectedIdentity *authn.Identity
+	expectedErr      error
+}
+
+func (f *fakeServiceAccountTokenVerifier) IsServiceAccountToken(rawToken string) bool {
+	return rawToken == f.token
+}
+
+func (f *fakeServiceAccountTokenVerifier) VerifyServiceAccountToken(ctx context.Context, rawToken string) (*authn.Identity, error) {
+	return f.expectedIdentity, f.expectedErr
+}
+
+func TestJWTServiceAccountToken(t *testing.T) {
+	jwtService := &jwt.FakeJWTService{
+		VerifyProvider: func(context.Context, string) (jwt.JWTClaims, error) {
+			return nil, fmt.Errorf("unexpected call")
+		},
+	}
+	saIdentity := &authn.Identity{ID: authn.NamespacedID(authn.NamespaceServiceAccount, 2), OrgID: 1}
+
+	newRequest := func(token string) *authn.Request {
+		return &authn.Request{OrgID: 1, HTTPRequest: &http.Request{
+			URL:    &url.URL{},
+			Header: map[string][]string{"Authorization": {"Bearer " + token}},
+		}}
+	}
+
+	t.Run("accepts service account tokens when the JWT authentication is disabled", func(t *testing.T) {
+		verifier := &fakeServiceAccountTokenVerifier{token: "sa-token", expectedIdentity: saIdentity}
+		jwtClient := ProvideJWT(jwtService, &setting.Cfg{}, verifier)
+
+		assert.True(t, jwtClient.Test(context.Background(), newRequest("sa-token")))
+		assert.False(t, jwtClient.Test(context.Background(), newRequest("other-token")))
+
+		id, err := jwtClient.Authenticate(context.Background(), newRequest("sa-token"))
+		require.NoError(t, err)
+		assert.Equal(t, saIdentity, id)
+	})
+
+	t.Run("rejects invalid service account tokens", func(t *testing.T) {
+		verifier := &fakeServiceAccountTokenVerifier{token: "sa-token", expectedErr: fmt.Errorf("expired")}
+		jwtClient := ProvideJWT(jwtService, &setting.Cfg{}, verifier)
+
+		_, err := jwtClient.Authenticate(context.Background(), newRequest("sa-token"))
+		assert.ErrorIs(t, err, errJWTInvalid)
+	})
+}
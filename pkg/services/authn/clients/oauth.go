@@ -25,6 +25,7 @@ import (
 
 const (
 	hostedDomainParamName        = "hd"
+	loginHintParamName           = "login_hint"
 	codeVerifierParamName        = "code_verifier"
 	codeChallengeParamName       = "code_challenge"
 	codeChallengeMethodParamName = "code_challenge_method"
@@ -176,6 +177,12 @@ func (c *OAuth) RedirectURL(ctx context.Context, r *authn.Request) (*authn.Redir
 		opts = append(opts, oauth2.SetAuthURLParam(hostedDomainParamName, c.oauthCfg.HostedDomain))
 	}
 
+	if r != nil && r.HTTPRequest != nil {
+		if hint := r.HTTPRequest.URL.Query().Get(loginHintParamName); hint != "" {
+			opts = append(opts, oauth2.SetAuthURLParam(loginHintParamName, hint))
+		}
+	}
+
 	var plainPKCE string
 	if c.oauthCfg.UsePKCE {
 		pkce, hashedPKCE, err := genPKCECode()
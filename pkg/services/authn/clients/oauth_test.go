@@ -243,6 +243,7 @@ func TestOAuth_RedirectURL(t *testing.T) {
 	type testCase struct {
 		desc        string
 		oauthCfg    *social.OAuthInfo
+		req         *authn.Request
 		expectedErr error
 
 		numCallOptions    int
@@ -267,6 +268,15 @@ func TestOAuth_RedirectURL(t *testing.T) {
 			numCallOptions:    2,
 			authCodeUrlCalled: true,
 		},
+		{
+			desc:     "should generate redirect url with login hint if requested",
+			oauthCfg: &social.OAuthInfo{},
+			req: &authn.Request{HTTPRequest: &http.Request{
+				URL: &url.URL{RawQuery: "login_hint=jane%40corp.com"},
+			}},
+			numCallOptions:    1,
+			authCodeUrlCalled: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,7 +293,7 @@ func TestOAuth_RedirectURL(t *testing.T) {
 				},
 			}, nil)
 
-			redirect, err := c.RedirectURL(context.Background(), nil)
+			redirect, err := c.RedirectURL(context.Background(), tt.req)
 			assert.ErrorIs(t, err, tt.expectedErr)
 			assert.Equal(t, tt.authCodeUrlCalled, authCodeUrlCalled)
 
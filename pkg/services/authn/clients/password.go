@@ -2,11 +2,15 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/totp"
+	"github.com/grafana/grafana/pkg/services/webauthn"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -14,16 +18,20 @@ import (
 var (
 	errInvalidPassword    = errutil.Unauthorized("password-auth.invalid", errutil.WithPublicMessage("Invalid password or username"))
 	errPasswordAuthFailed = errutil.Unauthorized("password-auth.failed", errutil.WithPublicMessage("Invalid username or password"))
+	errSecondFactorUser   = errutil.Unauthorized("password-auth.second-factor",
+		errutil.WithPublicMessage("Basic authentication is not available for users with two-factor authentication, use a service account token"))
 )
 
 var _ authn.PasswordClient = new(Password)
 
-func ProvidePassword(loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
-	return &Password{loginAttempts, clients, log.New("authn.password")}
+func ProvidePassword(loginAttempts loginattempt.Service, totpService totp.Service, webauthnService webauthn.Service, clients ...authn.PasswordClient) *Password {
+	return &Password{loginAttempts, totpService, webauthnService, clients, log.New("authn.password")}
 }
 
 type Password struct {
 	loginAttempts loginattempt.Service
+	totp          totp.Service
+	webauthn      webauthn.Service
 	clients       []authn.PasswordClient
 	log           log.Logger
 }
@@ -53,6 +61,9 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 			continue
 		}
 
+		if err := c.verifySecondFactor(ctx, r, username, identity); err != nil {
+			return nil, err
+		}
 		return identity, nil
 	}
 
@@ -62,3 +73,42 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
 }
+
+// verifySecondFactor verifies the passkey and the TOTP code of the logins of Grafana users before their session is
+// created. The basic auth requests to the API cannot provide a second factor, so they are rejected for the users whose
+// password is not enough.
+func (c *Password) verifySecondFactor(ctx context.Context, r *authn.Request, username string, identity *authn.Identity) error {
+	if identity.AuthenticatedBy != login.PasswordAuthModule {
+		return nil
+	}
+	_, userID := identity.NamespacedID()
+
+	if r.GetMeta(authn.MetaKeyIsLogin) == "" {
+		for _, isRequired := range []func(context.Context, int64) (bool, error){c.webauthn.IsRequired, c.totp.IsRequired} {
+			required, err := isRequired(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if required {
+				return errSecondFactorUser.Errorf("user %d has a second factor", userID)
+			}
+		}
+		return nil
+	}
+
+	var assertion *webauthn.AssertionResponse
+	if raw := r.GetMeta(authn.MetaKeyWebAuthnAssertion); raw != "" {
+		assertion = &webauthn.AssertionResponse{}
+		if err := json.Unmarshal([]byte(raw), assertion); err != nil {
+			return errBadForm.Errorf("failed to parse passkey assertion: %w", err)
+		}
+	}
+	err := c.webauthn.VerifyLogin(ctx, userID, assertion)
+	if err == nil {
+		err = c.totp.VerifyLogin(ctx, userID, r.GetMeta(authn.MetaKeyTOTPCode))
+	}
+	if errors.Is(err, totp.ErrInvalidCode) || errors.Is(err, webauthn.ErrInvalidAssertion) {
+		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+	}
+	return err
+}
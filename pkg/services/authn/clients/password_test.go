@@ -2,13 +2,20 @@ package clients
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/totp"
+	"github.com/grafana/grafana/pkg/services/totp/totptest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
 )
 
 func TestPassword_AuthenticatePassword(t *testing.T) {
@@ -67,7 +74,7 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, tt.clients...)
+			c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, totptest.NewFakeService(), webauthntest.NewFakeService(), tt.clients...)
 
 			identity, err := c.AuthenticatePassword(context.Background(), tt.req, tt.username, tt.password)
 			if tt.expectedErr != nil {
@@ -80,3 +87,96 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPassword_AuthenticatePassword_SecondFactor(t *testing.T) {
+	grafanaIdentity := &authn.Identity{ID: "user:1", AuthenticatedBy: login.PasswordAuthModule}
+	ldapIdentity := &authn.Identity{ID: "user:2", AuthenticatedBy: login.LDAPAuthModule}
+
+	newRequest := func(isLogin bool, code string) *authn.Request {
+		r := &authn.Request{HTTPRequest: &http.Request{}}
+		if isLogin {
+			r.SetMeta(authn.MetaKeyIsLogin, "true")
+		}
+		if code != "" {
+			r.SetMeta(authn.MetaKeyTOTPCode, code)
+		}
+		return r
+	}
+
+	t.Run("should verify the code of the logins of Grafana users", func(t *testing.T) {
+		totpService := totptest.NewFakeService()
+		c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, totpService, webauthntest.NewFakeService(),
+			authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+
+		identity, err := c.AuthenticatePassword(context.Background(), newRequest(true, "123456"), "test", "test")
+		require.NoError(t, err)
+		assert.Equal(t, grafanaIdentity, identity)
+		assert.Equal(t, []string{"123456"}, totpService.Codes)
+	})
+
+	t.Run("should allow basic auth requests of users without second factor", func(t *testing.T) {
+		totpService := totptest.NewFakeService()
+		c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, totpService, webauthntest.NewFakeService(),
+			authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+		_, err := c.AuthenticatePassword(context.Background(), newRequest(false, ""), "test", "test")
+		require.NoError(t, err)
+		assert.Empty(t, totpService.Codes)
+	})
+
+	t.Run("should reject basic auth requests of users with a second factor", func(t *testing.T) {
+		c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, &totptest.FakeService{ExpectedRequired: true},
+			webauthntest.NewFakeService(), authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+		identity, err := c.AuthenticatePassword(context.Background(), newRequest(false, ""), "test", "test")
+		assert.ErrorIs(t, err, errSecondFactorUser)
+		assert.Nil(t, identity)
+
+		c = ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, totptest.NewFakeService(),
+			&webauthntest.FakeService{ExpectedRequired: true}, authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+		identity, err = c.AuthenticatePassword(context.Background(), newRequest(false, ""), "test", "test")
+		assert.ErrorIs(t, err, errSecondFactorUser)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("should not verify a code for other password clients", func(t *testing.T) {
+		totpService := &totptest.FakeService{ExpectedError: totp.ErrCodeRequired.Errorf("code required"), ExpectedRequired: true}
+		c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, totpService, webauthntest.NewFakeService(),
+			authntest.FakePasswordClient{ExpectedIdentity: ldapIdentity})
+		_, err := c.AuthenticatePassword(context.Background(), newRequest(true, ""), "test", "test")
+		require.NoError(t, err)
+		_, err = c.AuthenticatePassword(context.Background(), newRequest(false, ""), "test", "test")
+		require.NoError(t, err)
+		assert.Empty(t, totpService.Codes)
+	})
+
+	t.Run("should fail and count the attempt when the code is invalid", func(t *testing.T) {
+		loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+		totpService := &totptest.FakeService{ExpectedError: totp.ErrInvalidCode.Errorf("invalid code")}
+		c := ProvidePassword(loginAttempts, totpService, webauthntest.NewFakeService(), authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+
+		identity, err := c.AuthenticatePassword(context.Background(), newRequest(true, "000000"), "test", "test")
+		assert.ErrorIs(t, err, totp.ErrInvalidCode)
+		assert.Nil(t, identity)
+		assert.True(t, loginAttempts.AddCalled)
+	})
+
+	t.Run("should verify the passkey of the logins before the TOTP code", func(t *testing.T) {
+		totpService := totptest.NewFakeService()
+		webauthnService := webauthntest.NewFakeService()
+		c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: true}, totpService, webauthnService,
+			authntest.FakePasswordClient{ExpectedIdentity: grafanaIdentity})
+
+		r := newRequest(true, "")
+		r.SetMeta(authn.MetaKeyWebAuthnAssertion, `{"id":"abc","rawId":"abc","type":"public-key"}`)
+		_, err := c.AuthenticatePassword(context.Background(), r, "test", "test")
+		require.NoError(t, err)
+		require.Len(t, webauthnService.Assertions, 1)
+		assert.Equal(t, "abc", webauthnService.Assertions[0].RawID)
+		assert.Equal(t, []string{""}, totpService.Codes)
+
+		webauthnService.ExpectedError = webauthn.ErrAssertionRequired.Errorf("passkey required")
+		_, err = c.AuthenticatePassword(context.Background(), newRequest(true, ""), "test", "test")
+		assert.ErrorIs(t, err, webauthn.ErrAssertionRequired)
+		assert.Nil(t, webauthnService.Assertions[1])
+		assert.Len(t, totpService.Codes, 1)
+	})
+}
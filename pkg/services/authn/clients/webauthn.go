@@ -0,0 +1,49 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var errBadWebAuthnRequest = errutil.BadRequest("webauthn-auth.invalid", errutil.WithPublicMessage("bad login data"))
+
+var _ authn.Client = new(WebAuthn)
+
+func ProvideWebAuthn(webauthnService webauthn.Service, userService user.Service) *WebAuthn {
+	return &WebAuthn{webauthnService, userService}
+}
+
+// WebAuthn authenticates the logins with a passkey, without password.
+type WebAuthn struct {
+	webauthnService webauthn.Service
+	userService     user.Service
+}
+
+func (c *WebAuthn) Name() string {
+	return authn.ClientWebAuthn
+}
+
+func (c *WebAuthn) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	assertion := webauthn.AssertionResponse{}
+	if err := json.NewDecoder(r.HTTPRequest.Body).Decode(&assertion); err != nil {
+		return nil, errBadWebAuthnRequest.Errorf("failed to parse request: %w", err)
+	}
+
+	userID, err := c.webauthnService.Authenticate(ctx, &assertion)
+	if err != nil {
+		return nil, err
+	}
+	r.SetMeta(authn.MetaKeyAuthModule, login.WebAuthnAuthModule)
+
+	signedInUser, err := c.userService.GetSignedInUserWithCacheCtx(ctx, &user.GetSignedInUserQuery{OrgID: r.OrgID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return authn.IdentityFromSignedInUser(authn.NamespacedID(authn.NamespaceUser, userID), signedInUser, authn.ClientParams{SyncPermissions: true}, login.WebAuthnAuthModule), nil
+}
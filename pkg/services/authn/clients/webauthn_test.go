@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+)
+
+func TestWebAuthn_Authenticate(t *testing.T) {
+	newRequest := func(body string) *authn.Request {
+		req, err := http.NewRequest(http.MethodPost, "/login/webauthn", strings.NewReader(body))
+		require.NoError(t, err)
+		return &authn.Request{HTTPRequest: req, OrgID: 1}
+	}
+
+	t.Run("should authenticate the user of the passkey", func(t *testing.T) {
+		webauthnService := &webauthntest.FakeService{ExpectedUserID: 2}
+		userService := &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, Login: "alice"}}
+		c := ProvideWebAuthn(webauthnService, userService)
+
+		r := newRequest(`{"id":"abc","rawId":"abc","type":"public-key","response":{"signature":"c2ln"}}`)
+		identity, err := c.Authenticate(context.Background(), r)
+		require.NoError(t, err)
+		assert.Equal(t, "user:2", identity.ID)
+		assert.Equal(t, login.WebAuthnAuthModule, identity.AuthenticatedBy)
+		assert.Equal(t, login.WebAuthnAuthModule, r.GetMeta(authn.MetaKeyAuthModule))
+		require.Len(t, webauthnService.Assertions, 1)
+		assert.Equal(t, "c2ln", webauthnService.Assertions[0].Response.Signature)
+	})
+
+	t.Run("should fail for an invalid assertion", func(t *testing.T) {
+		webauthnService := &webauthntest.FakeService{ExpectedError: webauthn.ErrInvalidAssertion.Errorf("invalid signature")}
+		c := ProvideWebAuthn(webauthnService, usertest.NewUserServiceFake())
+
+		_, err := c.Authenticate(context.Background(), newRequest(`{"id":"abc"}`))
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion)
+
+		_, err = c.Authenticate(context.Background(), newRequest(`not json`))
+		assert.ErrorIs(t, err, errBadWebAuthnRequest)
+	})
+}
@@ -8,4 +8,7 @@ var (
 	ErrClientNotConfigured = errutil.BadRequest("auth.client.notConfigured")
 	ErrUnsupportedIdentity = errutil.NotImplemented("auth.identity.unsupported")
 	ErrExpiredAccessToken  = errutil.Unauthorized("oauth.expired-token", errutil.WithPublicMessage("OAuth access token expired"))
+
+	ErrStateHandoffNotFound = errutil.NotFound("auth.handoff.notFound", errutil.WithPublicMessage("State handoff token is invalid or expired"))
+	ErrStateHandoffTooLarge = errutil.BadRequest("auth.handoff.tooLarge", errutil.WithPublicMessage("State handoff is too large"))
 )
@@ -7,9 +7,11 @@ import (
 	"io/fs"
 	"os"
 	"path"
-	"strconv"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -17,9 +19,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -29,131 +33,136 @@ import (
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	deleteOldDeliveriesService *notifier.DeleteOldDeliveriesService, tempUserService tempuser.Service, tracer tracing.Tracer,
+	annotationCleaner annotations.Cleaner, reg prometheus.Registerer) (*CleanUpService, error) {
 	s := &CleanUpService{
-		Cfg:                       cfg,
-		ServerLockService:         serverLockService,
-		ShortURLService:           shortURLService,
-		QueryHistoryService:       queryHistoryService,
-		store:                     sqlstore,
-		log:                       log.New("cleanup"),
-		dashboardVersionService:   dashboardVersionService,
-		dashboardSnapshotService:  dashSnapSvc,
-		deleteExpiredImageService: deleteExpiredImageService,
-		tempUserService:           tempUserService,
-		tracer:                    tracer,
-		annotationCleaner:         annotationCleaner,
+		Cfg:                        cfg,
+		ServerLockService:          serverLockService,
+		ShortURLService:            shortURLService,
+		QueryHistoryService:        queryHistoryService,
+		store:                      sqlstore,
+		log:                        log.New("cleanup"),
+		dashboardVersionService:    dashboardVersionService,
+		dashboardSnapshotService:   dashSnapSvc,
+		deleteExpiredImageService:  deleteExpiredImageService,
+		deleteOldDeliveriesService: deleteOldDeliveriesService,
+		tempUserService:            tempUserService,
+		tracer:                     tracer,
+		annotationCleaner:          annotationCleaner,
+		metrics:                    newMetrics(reg),
 	}
-	return s
-}
 
-type CleanUpService struct {
-	log                       log.Logger
-	tracer                    tracing.Tracer
-	store                     db.DB
-	Cfg                       *setting.Cfg
-	ServerLockService         *serverlock.ServerLockService
-	ShortURLService           shorturls.Service
-	QueryHistoryService       queryhistory.Service
-	dashboardVersionService   dashver.Service
-	dashboardSnapshotService  dashboardsnapshots.Service
-	deleteExpiredImageService *image.DeleteExpiredService
-	tempUserService           tempuser.Service
-	annotationCleaner         annotations.Cleaner
-}
+	tasks := []janitor.Task{
+		{Name: "temp_files", Run: s.cleanUpTmpFiles},
+		{Name: "expired_snapshots", Run: s.deleteExpiredSnapshots},
+		{Name: "snapshot_expiry_reminders", Run: s.notifyExpiringSnapshots},
+		{Name: "snapshot_metrics", Run: s.updateSnapshotMetrics},
+		{Name: "expired_dashboard_versions", Run: s.deleteExpiredDashboardVersions},
+		{Name: "expired_alert_images", Run: s.deleteExpiredImages},
+		{Name: "old_notification_deliveries", Run: s.deleteOldNotificationDeliveries},
+		{Name: "old_annotations", Run: s.cleanUpOldAnnotations},
+		{Name: "expired_user_invites", Run: s.expireOldUserInvites},
+		{Name: "stale_short_urls", Run: s.deleteStaleShortURLs},
+		{Name: "expired_short_urls", Run: s.deleteExpiredShortURLs},
+		{Name: "stale_query_history", Run: s.deleteStaleQueryHistory},
+	}
+	for _, task := range tasks {
+		if err := s.RegisterTask(task); err != nil {
+			return nil, err
+		}
+	}
 
-type cleanUpJob struct {
-	name string
-	fn   func(context.Context)
+	return s, nil
 }
 
-func (j cleanUpJob) String() string {
-	return strconv.Quote(j.name)
+type CleanUpService struct {
+	log                        log.Logger
+	tracer                     tracing.Tracer
+	store                      db.DB
+	Cfg                        *setting.Cfg
+	ServerLockService          *serverlock.ServerLockService
+	ShortURLService            shorturls.Service
+	QueryHistoryService        queryhistory.Service
+	dashboardVersionService    dashver.Service
+	dashboardSnapshotService   dashboardsnapshots.Service
+	deleteExpiredImageService  *image.DeleteExpiredService
+	deleteOldDeliveriesService *notifier.DeleteOldDeliveriesService
+	tempUserService            tempuser.Service
+	annotationCleaner          annotations.Cleaner
+	metrics                    *metrics
+
+	mtx     sync.Mutex
+	tasks   []*task
+	started bool
+	// runMtx makes tasks run one at a time, so that they do not compete for the database.
+	runMtx sync.Mutex
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
-	srv.cleanUpTmpFiles(ctx)
-
-	ticker := time.NewTicker(time.Minute * 10)
-	for {
-		select {
-		case <-ticker.C:
-			srv.clean(ctx)
-		case <-ctx.Done():
-			return ctx.Err()
-		}
-	}
-}
-
-func (srv *CleanUpService) clean(ctx context.Context) {
-	const timeout = 9 * time.Minute
-	start := time.Now()
-	ctx, span := srv.tracer.Start(ctx, "cleanup background job")
-	defer span.End()
-	ctx, cancelFn := context.WithTimeout(ctx, timeout)
-	defer cancelFn()
-
-	cleanupJobs := []cleanUpJob{
-		{"clean up temporary files", srv.cleanUpTmpFiles},
-		{"delete expired snapshots", srv.deleteExpiredSnapshots},
-		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
-		{"delete expired images", srv.deleteExpiredImages},
-		{"cleanup old annotations", srv.cleanUpOldAnnotations},
-		{"expire old user invites", srv.expireOldUserInvites},
-		{"delete stale short URLs", srv.deleteStaleShortURLs},
-		{"delete stale query history", srv.deleteStaleQueryHistory},
+	srv.mtx.Lock()
+	srv.started = true
+	tasks := srv.tasks
+	srv.mtx.Unlock()
+
+	scheduler := cron.New()
+	for _, t := range tasks {
+		t := t
+		scheduler.Schedule(t.schedule, cron.FuncJob(func() {
+			if _, err := srv.runTask(ctx, t); errors.Is(err, ErrTaskRunning) {
+				srv.log.Debug("Skipping clean up task since it is still running", "task", t.Name)
+			}
+		}))
 	}
 
-	logger := srv.log.FromContext(ctx)
-	logger.Debug("Starting cleanup jobs", "jobs", fmt.Sprintf("%v", cleanupJobs))
-
-	for _, j := range cleanupJobs {
-		if ctx.Err() != nil {
-			logger.Error("Cancelled cleanup job", "error", ctx.Err(), "duration", time.Since(start))
-			return
+	// Temporary files are cleaned up on start up, they can pile up while Grafana is not running.
+	for _, t := range tasks {
+		if t.Name == "temp_files" {
+			_, _ = srv.runTask(ctx, t)
 		}
-		ctx, span := srv.tracer.Start(ctx, j.name)
-		j.fn(ctx)
-		span.End()
 	}
 
-	logger.Info("Completed cleanup jobs", "duration", time.Since(start))
+	scheduler.Start()
+	<-ctx.Done()
+	<-scheduler.Stop().Done()
+	return ctx.Err()
 }
 
-func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
+func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) (int64, error) {
 	logger := srv.log.FromContext(ctx)
 	affected, affectedTags, err := srv.annotationCleaner.Run(ctx, srv.Cfg)
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
-		logger.Error("failed to clean up old annotations", "error", err)
-	} else {
-		logger.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
+		return 0, fmt.Errorf("failed to clean up old annotations: %w", err)
 	}
+	logger.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
+	return affected + affectedTags, nil
 }
 
-func (srv *CleanUpService) cleanUpTmpFiles(ctx context.Context) {
+func (srv *CleanUpService) cleanUpTmpFiles(ctx context.Context) (int64, error) {
 	folders := []string{
 		srv.Cfg.ImagesDir,
 		srv.Cfg.CSVsDir,
 	}
 
+	var deleted int64
 	for _, f := range folders {
 		ctx, span := srv.tracer.Start(ctx, "delete stale files in temporary directory")
 		span.SetAttributes(attribute.String("directory", f))
-		srv.cleanUpTmpFolder(ctx, f)
+		deleted += srv.cleanUpTmpFolder(ctx, f)
 		span.End()
 	}
+	return deleted, nil
 }
 
-func (srv *CleanUpService) cleanUpTmpFolder(ctx context.Context, folder string) {
+func (srv *CleanUpService) cleanUpTmpFolder(ctx context.Context, folder string) int64 {
 	logger := srv.log.FromContext(ctx)
 	if _, err := os.Stat(folder); os.IsNotExist(err) {
-		return
+		return 0
 	}
 
 	files, err := os.ReadDir(folder)
 	if err != nil {
 		logger.Error("Problem reading dir", "folder", folder, "error", err)
-		return
+		return 0
 	}
 
 	var toDelete []fs.DirEntry
@@ -171,15 +180,19 @@ func (srv *CleanUpService) cleanUpTmpFolder(ctx context.Context, folder string)
 		}
 	}
 
+	var deleted int64
 	for _, file := range toDelete {
 		fullPath := path.Join(folder, file.Name())
 		err := os.Remove(fullPath)
 		if err != nil {
 			logger.Error("Failed to delete temp file", "file", file.Name(), "error", err)
+			continue
 		}
+		deleted++
 	}
 
-	logger.Debug("Found old rendered file to delete", "folder", folder, "deleted", len(toDelete), "kept", len(files))
+	logger.Debug("Found old rendered file to delete", "folder", folder, "deleted", deleted, "kept", len(files))
+	return deleted
 }
 
 func (srv *CleanUpService) shouldCleanupTempFile(filemtime time.Time, now time.Time) bool {
@@ -190,40 +203,60 @@ func (srv *CleanUpService) shouldCleanupTempFile(filemtime time.Time, now time.T
 	return filemtime.Add(srv.Cfg.TempDataLifetime).Before(now)
 }
 
-func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) (int64, error) {
 	cmd := dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
 	if err := srv.dashboardSnapshotService.DeleteExpiredSnapshots(ctx, &cmd); err != nil {
-		logger.Error("Failed to delete expired snapshots", "error", err.Error())
-	} else {
-		logger.Debug("Deleted expired snapshots", "rows affected", cmd.DeletedRows)
+		return 0, fmt.Errorf("failed to delete expired snapshots: %w", err)
 	}
+	return cmd.DeletedRows, nil
 }
 
-func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) notifyExpiringSnapshots(ctx context.Context) (int64, error) {
+	cmd := dashboardsnapshots.NotifyExpiringSnapshotsCommand{}
+	if err := srv.dashboardSnapshotService.NotifyExpiringSnapshots(ctx, &cmd); err != nil {
+		return 0, fmt.Errorf("failed to send snapshot expiry reminders: %w", err)
+	}
+	return cmd.RemindersSent, nil
+}
+
+func (srv *CleanUpService) updateSnapshotMetrics(ctx context.Context) (int64, error) {
+	if err := srv.dashboardSnapshotService.UpdateSnapshotMetrics(ctx); err != nil {
+		return 0, fmt.Errorf("failed to update snapshot metrics: %w", err)
+	}
+	return 0, nil
+}
+
+func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) (int64, error) {
 	cmd := dashver.DeleteExpiredVersionsCommand{}
 	if err := srv.dashboardVersionService.DeleteExpired(ctx, &cmd); err != nil {
-		logger.Error("Failed to delete expired dashboard versions", "error", err.Error())
-	} else {
-		logger.Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows)
+		return 0, fmt.Errorf("failed to delete expired dashboard versions: %w", err)
 	}
+	return cmd.DeletedRows, nil
 }
 
-func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) (int64, error) {
 	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
-		return
+		return 0, nil
 	}
-	if rowsAffected, err := srv.deleteExpiredImageService.DeleteExpired(ctx); err != nil {
-		logger.Error("Failed to delete expired images", "error", err.Error())
-	} else {
-		logger.Debug("Deleted expired images", "rows affected", rowsAffected)
+	rowsAffected, err := srv.deleteExpiredImageService.DeleteExpired(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired images: %w", err)
 	}
+	return rowsAffected, nil
 }
 
-func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteOldNotificationDeliveries(ctx context.Context) (int64, error) {
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
+		return 0, nil
+	}
+	rowsAffected, err := srv.deleteOldDeliveriesService.DeleteOld(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old notification deliveries: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) (int64, error) {
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
 
 	cmd := tempuser.ExpireTempUsersCommand{
@@ -231,51 +264,64 @@ func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	}
 
 	if err := srv.tempUserService.ExpireOldUserInvites(ctx, &cmd); err != nil {
-		logger.Error("Problem expiring user invites", "error", err.Error())
-	} else {
-		logger.Debug("Expired user invites", "rows affected", cmd.NumExpired)
+		return 0, fmt.Errorf("failed to expire user invites: %w", err)
 	}
+	return cmd.NumExpired, nil
 }
 
-func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) (int64, error) {
 	cmd := shorturls.DeleteShortUrlCommand{
 		OlderThan: time.Now().Add(-time.Hour * 24 * 7),
 	}
 	if err := srv.ShortURLService.DeleteStaleShortURLs(ctx, &cmd); err != nil {
-		logger.Error("Problem deleting stale short urls", "error", err.Error())
-	} else {
-		logger.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
+		return 0, fmt.Errorf("failed to delete stale short urls: %w", err)
 	}
+	return cmd.NumDeleted, nil
 }
 
-func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
+func (srv *CleanUpService) deleteExpiredShortURLs(ctx context.Context) (int64, error) {
+	cmd := shorturls.DeleteExpiredShortURLsCommand{}
+	if err := srv.ShortURLService.DeleteExpiredShortURLs(ctx, &cmd); err != nil {
+		return 0, fmt.Errorf("failed to delete expired short urls: %w", err)
+	}
+	return cmd.NumDeleted, nil
+}
+
+func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) (int64, error) {
 	logger := srv.log.FromContext(ctx)
+	var deleted int64
+	var errs []error
+
 	// Delete query history from 14+ days ago with exception of starred queries
 	maxQueryHistoryLifetime := time.Hour * 24 * 14
 	olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
 	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
 	if err != nil {
-		logger.Error("Problem deleting stale query history", "error", err.Error())
+		errs = append(errs, fmt.Errorf("failed to delete stale query history: %w", err))
 	} else {
 		logger.Debug("Deleted stale query history", "rows affected", rowsCount)
+		deleted += int64(rowsCount)
 	}
 
 	// Enforce 200k limit for query_history table
 	queryHistoryLimit := 200000
 	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryLimit, false)
 	if err != nil {
-		logger.Error("Problem with enforcing row limit for query_history", "error", err.Error())
+		errs = append(errs, fmt.Errorf("failed to enforce row limit for query_history: %w", err))
 	} else {
 		logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
+		deleted += int64(rowsCount)
 	}
 
 	// Enforce 150k limit for query_history_star table
 	queryHistoryStarLimit := 150000
 	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryStarLimit, true)
 	if err != nil {
-		logger.Error("Problem with enforcing row limit for query_history_star", "error", err.Error())
+		errs = append(errs, fmt.Errorf("failed to enforce row limit for query_history_star: %w", err))
 	} else {
 		logger.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
+		deleted += int64(rowsCount)
 	}
+
+	return deleted, errors.Join(errs...)
 }
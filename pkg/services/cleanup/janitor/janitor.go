@@ -0,0 +1,25 @@
+// Package janitor defines the clean up tasks that subsystems register with the cleanup service.
+package janitor
+
+import (
+	"context"
+)
+
+// DefaultSchedule is the schedule of tasks that do not define one.
+const DefaultSchedule = "@every 10m"
+
+// Task is a periodic clean up task of a subsystem, such as deleting expired rows or stale files.
+type Task struct {
+	// Name identifies the task in logs, metrics and the admin API, such as expired_snapshots.
+	Name string
+	// Schedule is the cron expression, or a descriptor such as @every 1h, of when the task runs.
+	// It can be overridden per task in the [cleanup.schedules] section of the configuration.
+	Schedule string
+	// Run cleans up and returns the number of deleted rows or files.
+	Run func(ctx context.Context) (int64, error)
+}
+
+// Registry registers clean up tasks.
+type Registry interface {
+	RegisterTask(task Task) error
+}
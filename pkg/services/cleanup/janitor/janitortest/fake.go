@@ -0,0 +1,14 @@
+package janitortest
+
+import (
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
+)
+
+type FakeRegistry struct {
+	Tasks []janitor.Task
+}
+
+func (f *FakeRegistry) RegisterTask(task janitor.Task) error {
+	f.Tasks = append(f.Tasks, task)
+	return nil
+}
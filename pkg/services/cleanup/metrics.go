@@ -0,0 +1,36 @@
+package cleanup
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	taskDuration  *prometheus.HistogramVec
+	deletedTotal  *prometheus.CounterVec
+	failuresTotal *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		taskDuration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "cleanup",
+			Name:      "task_duration_seconds",
+			Help:      "Histogram of the duration of clean up tasks.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, []string{"task"}),
+		deletedTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "cleanup",
+			Name:      "task_deleted_total",
+			Help:      "The total number of rows or files deleted by clean up tasks.",
+		}, []string{"task"}),
+		failuresTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "cleanup",
+			Name:      "task_failures_total",
+			Help:      "The total number of failed runs of clean up tasks.",
+		}, []string{"task"}),
+	}
+}
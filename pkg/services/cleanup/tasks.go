@@ -0,0 +1,174 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// taskTimeout bounds how long a single run of a task can take.
+const taskTimeout = 9 * time.Minute
+
+var (
+	ErrTaskNotFound = errutil.NotFound("cleanup.task-not-found", errutil.WithPublicMessage("Clean up task not found"))
+	ErrTaskRunning  = errutil.Conflict("cleanup.task-running", errutil.WithPublicMessage("Clean up task is already running"))
+)
+
+// TaskStatus is the schedule and the outcome of the last run of a clean up task.
+type TaskStatus struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"nextRun"`
+	Running  bool      `json:"running"`
+	// LastRun is zero if the task has not run since Grafana started.
+	LastRun        time.Time `json:"lastRun"`
+	LastDurationMs int64     `json:"lastDurationMs"`
+	LastDeleted    int64     `json:"lastDeleted"`
+	LastError      string    `json:"lastError,omitempty"`
+}
+
+type task struct {
+	janitor.Task
+	schedule cron.Schedule
+	running  atomic.Bool
+
+	mtx    sync.Mutex
+	status TaskStatus
+}
+
+// RegisterTask registers a clean up task. Tasks must be registered before the service is started,
+// which is the case for tasks registered when the subsystem is constructed.
+func (srv *CleanUpService) RegisterTask(t janitor.Task) error {
+	if t.Name == "" || t.Run == nil {
+		return errors.New("clean up task must have a name and a run function")
+	}
+	if s, ok := srv.Cfg.CleanupSchedules[t.Name]; ok {
+		t.Schedule = s
+	}
+	if t.Schedule == "" {
+		t.Schedule = janitor.DefaultSchedule
+	}
+	schedule, err := cron.ParseStandard(t.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q of clean up task %s: %w", t.Schedule, t.Name, err)
+	}
+
+	srv.mtx.Lock()
+	defer srv.mtx.Unlock()
+	if srv.started {
+		return fmt.Errorf("cannot register clean up task %s after the cleanup service started", t.Name)
+	}
+	for _, existing := range srv.tasks {
+		if existing.Name == t.Name {
+			return fmt.Errorf("clean up task %s is already registered", t.Name)
+		}
+	}
+	srv.tasks = append(srv.tasks, &task{
+		Task:     t,
+		schedule: schedule,
+		status:   TaskStatus{Name: t.Name, Schedule: t.Schedule},
+	})
+	return nil
+}
+
+// Tasks returns the status of the registered clean up tasks sorted by name.
+func (srv *CleanUpService) Tasks() []TaskStatus {
+	srv.mtx.Lock()
+	tasks := srv.tasks
+	srv.mtx.Unlock()
+
+	now := time.Now()
+	result := make([]TaskStatus, 0, len(tasks))
+	for _, t := range tasks {
+		t.mtx.Lock()
+		status := t.status
+		t.mtx.Unlock()
+		status.NextRun = t.schedule.Next(now)
+		status.Running = t.running.Load()
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// RunTask runs the clean up task with the given name now, outside of its schedule.
+func (srv *CleanUpService) RunTask(ctx context.Context, name string) (TaskStatus, error) {
+	srv.mtx.Lock()
+	var found *task
+	for _, t := range srv.tasks {
+		if t.Name == name {
+			found = t
+			break
+		}
+	}
+	srv.mtx.Unlock()
+	if found == nil {
+		return TaskStatus{}, ErrTaskNotFound.Errorf("clean up task %s not found", name)
+	}
+
+	// A failed run is reported in the status of the task.
+	if _, err := srv.runTask(ctx, found); errors.Is(err, ErrTaskRunning) {
+		return TaskStatus{}, err
+	}
+
+	found.mtx.Lock()
+	defer found.mtx.Unlock()
+	status := found.status
+	status.NextRun = found.schedule.Next(time.Now())
+	return status, nil
+}
+
+func (srv *CleanUpService) runTask(ctx context.Context, t *task) (int64, error) {
+	if !t.running.CompareAndSwap(false, true) {
+		return 0, ErrTaskRunning.Errorf("clean up task %s is already running", t.Name)
+	}
+	defer t.running.Store(false)
+
+	srv.runMtx.Lock()
+	defer srv.runMtx.Unlock()
+
+	ctx, cancelFn := context.WithTimeout(ctx, taskTimeout)
+	defer cancelFn()
+	ctx, span := srv.tracer.Start(ctx, "cleanup task")
+	span.SetAttributes(attribute.String("task", t.Name))
+	defer span.End()
+	logger := srv.log.FromContext(ctx).New("task", t.Name)
+
+	start := time.Now()
+	deleted, err := t.Run(ctx)
+	duration := time.Since(start)
+
+	srv.metrics.taskDuration.WithLabelValues(t.Name).Observe(duration.Seconds())
+	srv.metrics.deletedTotal.WithLabelValues(t.Name).Add(float64(deleted))
+	if err != nil {
+		srv.metrics.failuresTotal.WithLabelValues(t.Name).Inc()
+		span.RecordError(err)
+		logger.Error("Clean up task failed", "duration", duration, "error", err)
+	} else {
+		logger.Debug("Completed clean up task", "deleted", deleted, "duration", duration)
+	}
+
+	t.mtx.Lock()
+	t.status.LastRun = start
+	t.status.LastDurationMs = duration.Milliseconds()
+	t.status.LastDeleted = deleted
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+	}
+	t.mtx.Unlock()
+
+	return deleted, err
+}
@@ -0,0 +1,107 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCleanUpTasks(t *testing.T) {
+	newService := func(t *testing.T, schedules map[string]string) *CleanUpService {
+		t.Helper()
+		return &CleanUpService{
+			Cfg:     &setting.Cfg{CleanupSchedules: schedules},
+			log:     log.NewNopLogger(),
+			tracer:  tracing.InitializeTracerForTest(),
+			metrics: newMetrics(prometheus.NewRegistry()),
+		}
+	}
+
+	t.Run("registers tasks with their schedule or the one of the configuration", func(t *testing.T) {
+		srv := newService(t, map[string]string{"expired_tokens": "0 3 * * *"})
+		run := func(context.Context) (int64, error) { return 0, nil }
+
+		require.NoError(t, srv.RegisterTask(janitor.Task{Name: "temp_files", Run: run}))
+		require.NoError(t, srv.RegisterTask(janitor.Task{Name: "expired_tokens", Schedule: "@every 1h", Run: run}))
+		require.Error(t, srv.RegisterTask(janitor.Task{Name: "temp_files", Run: run}))
+		require.Error(t, srv.RegisterTask(janitor.Task{Name: "invalid", Schedule: "every hour", Run: run}))
+		require.Error(t, srv.RegisterTask(janitor.Task{Run: run}))
+
+		tasks := srv.Tasks()
+		require.Len(t, tasks, 2)
+		require.Equal(t, "expired_tokens", tasks[0].Name)
+		require.Equal(t, "0 3 * * *", tasks[0].Schedule)
+		require.Equal(t, 3, tasks[0].NextRun.Hour())
+		require.Equal(t, "temp_files", tasks[1].Name)
+		require.Equal(t, janitor.DefaultSchedule, tasks[1].Schedule)
+		require.True(t, tasks[1].LastRun.IsZero())
+	})
+
+	t.Run("runs a task on demand and records its outcome", func(t *testing.T) {
+		srv := newService(t, nil)
+		var err error
+		require.NoError(t, srv.RegisterTask(janitor.Task{Name: "old_rows", Run: func(context.Context) (int64, error) {
+			return 5, err
+		}}))
+
+		status, runErr := srv.RunTask(context.Background(), "old_rows")
+		require.NoError(t, runErr)
+		require.Equal(t, int64(5), status.LastDeleted)
+		require.False(t, status.LastRun.IsZero())
+		require.Empty(t, status.LastError)
+
+		err = errors.New("database is locked")
+		status, runErr = srv.RunTask(context.Background(), "old_rows")
+		require.NoError(t, runErr)
+		require.Equal(t, "database is locked", status.LastError)
+
+		require.Equal(t, float64(10), testutil.ToFloat64(srv.metrics.deletedTotal.WithLabelValues("old_rows")))
+		require.Equal(t, float64(1), testutil.ToFloat64(srv.metrics.failuresTotal.WithLabelValues("old_rows")))
+		require.Equal(t, 1, testutil.CollectAndCount(srv.metrics.taskDuration))
+
+		_, runErr = srv.RunTask(context.Background(), "unknown")
+		require.ErrorIs(t, runErr, ErrTaskNotFound)
+	})
+
+	t.Run("does not run a task that is already running", func(t *testing.T) {
+		srv := newService(t, nil)
+		started, release := make(chan struct{}), make(chan struct{})
+		require.NoError(t, srv.RegisterTask(janitor.Task{Name: "slow", Run: func(context.Context) (int64, error) {
+			close(started)
+			<-release
+			return 0, nil
+		}}))
+
+		done := make(chan error)
+		go func() {
+			_, err := srv.RunTask(context.Background(), "slow")
+			done <- err
+		}()
+		<-started
+
+		_, err := srv.RunTask(context.Background(), "slow")
+		require.ErrorIs(t, err, ErrTaskRunning)
+		require.True(t, srv.Tasks()[0].Running)
+
+		close(release)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("cannot register tasks once started", func(t *testing.T) {
+		srv := newService(t, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, srv.Run(ctx), context.Canceled)
+
+		require.Error(t, srv.RegisterTask(janitor.Task{Name: "late", Run: func(context.Context) (int64, error) { return 0, nil }}))
+	})
+}
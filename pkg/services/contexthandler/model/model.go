@@ -85,6 +85,7 @@ func (ctx *ReqContext) JsonApiErr(status int, message string, err error) {
 	if message != "" {
 		resp["message"] = message
 	}
+	resp["code"] = errutil.Code(status, err)
 
 	ctx.JSON(status, resp)
 }
@@ -122,6 +123,7 @@ func (ctx *ReqContext) writeErrOrFallback(status int, message string, err error)
 			data["extra"] = publicErr.Extra
 			data["message"] = publicErr.Message
 			data["messageId"] = publicErr.MessageID
+			data["code"] = publicErr.Code
 			data["statusCode"] = publicErr.StatusCode
 
 			statusResponse = publicErr.StatusCode
@@ -148,6 +150,9 @@ func (ctx *ReqContext) writeErrOrFallback(status int, message string, err error)
 	if _, ok := data["message"]; !ok && message != "" {
 		data["message"] = message
 	}
+	if _, ok := data["code"]; !ok {
+		data["code"] = errutil.Code(statusResponse, err)
+	}
 
 	ctx.JSON(statusResponse, data)
 }
@@ -0,0 +1,399 @@
+// Package dashboardarchive captures the JSON model and an image of dashboards on a schedule and stores them
+// in object storage, so that what the dashboards showed at a point in time can be audited later.
+package dashboardarchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/fileblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// captureTimeFormat is the format of the time of the captures in the keys of their objects,
+	// which sorts the captures of a dashboard by time.
+	captureTimeFormat = "20060102T150405Z"
+
+	dashboardObject = "dashboard.json"
+	imageObject     = "dashboard.png"
+	manifestObject  = "manifest.json"
+
+	// maxFolderDashboards is the maximum number of dashboards captured per folder.
+	maxFolderDashboards = 5000
+)
+
+// ErrCaptureExists is returned when a capture would overwrite an object of the archive.
+var ErrCaptureExists = errors.New("dashboard capture already exists")
+
+type Service struct {
+	log              log.Logger
+	dashboardService dashboards.DashboardService
+	renderService    rendering.Service
+	metrics          *metrics
+
+	enabled          bool
+	configPath       string
+	storageURL       string
+	retention        time.Duration
+	renderWidth      int
+	renderHeight     int
+	renderTimeout    time.Duration
+	renderConcurrent int
+
+	// bucket is opened when the service starts, or set in tests.
+	bucket *blob.Bucket
+	// runMtx makes the schedules run one at a time, so that they do not compete for the renderer.
+	runMtx sync.Mutex
+	now    func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, dashboardService dashboards.DashboardService, renderService rendering.Service,
+	registerer prometheus.Registerer) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("dashboard_archive")
+	s := &Service{
+		log:              log.New("dashboard-archive"),
+		dashboardService: dashboardService,
+		renderService:    renderService,
+		metrics:          newMetrics(registerer),
+		enabled:          section.Key("enabled").MustBool(false),
+		configPath:       filepath.Join(cfg.ProvisioningPath, "dashboard_archive"),
+		storageURL:       section.Key("storage_url").String(),
+		renderWidth:      section.Key("render_width").MustInt(1920),
+		renderHeight:     section.Key("render_height").MustInt(1080),
+		renderTimeout:    section.Key("render_timeout").MustDuration(time.Minute),
+		renderConcurrent: cfg.RendererConcurrentRequestLimit,
+		now:              time.Now,
+	}
+
+	if retention := section.Key("retention").MustString("0"); retention != "0" {
+		d, err := gtime.ParseDuration(retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dashboard_archive retention %q: %w", retention, err)
+		}
+		s.retention = d
+	}
+	return s, nil
+}
+
+// IsDisabled returns true if the archive is disabled or if no storage is configured.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled || s.storageURL == ""
+}
+
+// Run captures the dashboards of the schedules until the context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	schedules, err := readConfig(s.configPath)
+	if err != nil {
+		// The archive is not critical for Grafana, so the error is logged instead of stopping the server.
+		s.log.Error("Failed to read dashboard archive schedules", "path", s.configPath, "error", err)
+		return nil
+	}
+
+	bucket, err := blob.OpenBucket(ctx, s.storageURL)
+	if err != nil {
+		s.log.Error("Failed to open dashboard archive storage", "error", err)
+		return nil
+	}
+	s.bucket = bucket
+	defer func() {
+		if err := bucket.Close(); err != nil {
+			s.log.Warn("Failed to close dashboard archive storage", "error", err)
+		}
+	}()
+	s.log.Info("Starting dashboard archive", "schedules", len(schedules))
+
+	scheduler := cron.New()
+	for _, sc := range schedules {
+		sc := sc
+		scheduler.Schedule(sc.schedule, cron.FuncJob(func() {
+			s.runSchedule(ctx, sc)
+		}))
+	}
+
+	scheduler.Start()
+	<-ctx.Done()
+	<-scheduler.Stop().Done()
+	return ctx.Err()
+}
+
+// runSchedule captures the dashboards of a schedule, then deletes their captures older than the retention.
+func (s *Service) runSchedule(ctx context.Context, sc Schedule) {
+	s.runMtx.Lock()
+	defer s.runMtx.Unlock()
+
+	logger := s.log.New("schedule", sc.Name, "org", sc.OrgID)
+	dashboardUIDs, err := s.dashboardUIDs(ctx, sc)
+	if err != nil {
+		logger.Error("Failed to list the dashboards of the schedule", "error", err)
+		return
+	}
+
+	for _, uid := range dashboardUIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := s.capture(ctx, sc, uid); err != nil {
+			s.metrics.captures.WithLabelValues("failed").Inc()
+			logger.Error("Failed to capture dashboard", "dashboardUid", uid, "error", err)
+			continue
+		}
+		s.metrics.captures.WithLabelValues("success").Inc()
+		logger.Debug("Captured dashboard", "dashboardUid", uid)
+
+		if s.retention > 0 {
+			if err := s.deleteExpired(ctx, sc.OrgID, uid); err != nil {
+				logger.Error("Failed to delete expired captures of dashboard", "dashboardUid", uid, "error", err)
+			}
+		}
+	}
+}
+
+// dashboardUIDs returns the UIDs of the dashboards of the schedule and of the dashboards of its folders.
+func (s *Service) dashboardUIDs(ctx context.Context, sc Schedule) ([]string, error) {
+	seen := make(map[string]bool)
+	var uids []string
+	add := func(uid string) {
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+
+	for _, uid := range sc.DashboardUIDs {
+		add(uid)
+	}
+	if len(sc.FolderUIDs) > 0 {
+		hits, err := s.dashboardService.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			OrgId:        sc.OrgID,
+			SignedInUser: archiveUser(sc.OrgID),
+			FolderUIDs:   sc.FolderUIDs,
+			Type:         "dash-db",
+			Limit:        maxFolderDashboards,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			add(hit.UID)
+		}
+	}
+	return uids, nil
+}
+
+// manifest describes a capture of a dashboard. It is written last, so a capture without
+// a manifest is incomplete.
+type manifest struct {
+	Schedule     string            `json:"schedule"`
+	OrgID        int64             `json:"orgId"`
+	DashboardUID string            `json:"dashboardUid"`
+	Title        string            `json:"title"`
+	Version      int               `json:"version"`
+	CapturedAt   time.Time         `json:"capturedAt"`
+	Checksums    map[string]string `json:"sha256"`
+}
+
+func (s *Service) capture(ctx context.Context, sc Schedule, uid string) error {
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: sc.OrgID, UID: uid})
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	prefix := capturePrefix(sc.OrgID, uid) + now.Format(captureTimeFormat) + "/"
+	m := manifest{
+		Schedule:     sc.Name,
+		OrgID:        sc.OrgID,
+		DashboardUID: uid,
+		Title:        dashboard.Title,
+		Version:      dashboard.Version,
+		CapturedAt:   now,
+		Checksums:    make(map[string]string),
+	}
+
+	model, err := dashboard.Data.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := s.write(ctx, prefix+dashboardObject, "application/json", model, &m); err != nil {
+		return err
+	}
+
+	if sc.Render {
+		image, err := s.render(ctx, dashboard)
+		if err != nil {
+			// The JSON model is still archived when the image cannot be rendered.
+			s.log.Warn("Failed to render dashboard", "schedule", sc.Name, "dashboardUid", uid, "error", err)
+		} else if err := s.write(ctx, prefix+imageObject, "image/png", image, &m); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.write(ctx, prefix+manifestObject, "application/json", content, nil)
+}
+
+func (s *Service) render(ctx context.Context, dashboard *dashboards.Dashboard) ([]byte, error) {
+	if !s.renderService.IsAvailable(ctx) {
+		return nil, rendering.ErrRenderUnavailable
+	}
+
+	u := url.URL{Path: path.Join("d", dashboard.UID, dashboard.Slug)}
+	q := u.Query()
+	q.Add("orgId", strconv.FormatInt(dashboard.OrgID, 10))
+	q.Add("kiosk", "")
+	u.RawQuery = q.Encode()
+
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   dashboard.OrgID,
+			OrgRole: org.RoleAdmin,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: s.renderTimeout,
+		},
+		Width:           s.renderWidth,
+		Height:          s.renderHeight,
+		Theme:           models.ThemeLight,
+		ConcurrentLimit: s.renderConcurrent,
+		Class:           rendering.RenderClassReport,
+		Path:            u.String(),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.Remove(result.FilePath); err != nil {
+			s.log.Warn("Failed to remove rendered image", "path", result.FilePath, "error", err)
+		}
+	}()
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path is the one of the rendered image
+	return os.ReadFile(result.FilePath)
+}
+
+// write writes an object of a capture, and adds its checksum to the manifest of the capture.
+// The objects of the archive are never overwritten.
+func (s *Service) write(ctx context.Context, key, contentType string, content []byte, m *manifest) error {
+	exists, err := s.bucket.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrCaptureExists, key)
+	}
+
+	if err := s.bucket.WriteAll(ctx, key, content, &blob.WriterOptions{ContentType: contentType}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	if m != nil {
+		sum := sha256.Sum256(content)
+		m.Checksums[path.Base(key)] = hex.EncodeToString(sum[:])
+	}
+	return nil
+}
+
+// deleteExpired deletes the captures of a dashboard older than the retention.
+func (s *Service) deleteExpired(ctx context.Context, orgID int64, uid string) error {
+	expiry := s.now().Add(-s.retention)
+	prefix := capturePrefix(orgID, uid)
+
+	var expired []string
+	iter := s.bucket.List(&blob.ListOptions{Prefix: prefix, Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		if !obj.IsDir {
+			continue
+		}
+
+		capturedAt, err := time.Parse(captureTimeFormat, strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), "/"))
+		if err != nil {
+			continue
+		}
+		if capturedAt.Before(expiry) {
+			expired = append(expired, obj.Key)
+		}
+	}
+
+	for _, capture := range expired {
+		iter := s.bucket.List(&blob.ListOptions{Prefix: capture})
+		for {
+			obj, err := iter.Next(ctx)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			if err := s.bucket.Delete(ctx, obj.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				return err
+			}
+		}
+		s.metrics.deleted.Inc()
+	}
+	return nil
+}
+
+func capturePrefix(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s/", orgID, uid)
+}
+
+func archiveUser(orgID int64) *user.SignedInUser {
+	return &user.SignedInUser{
+		UserID:           -1,
+		IsServiceAccount: true,
+		Login:            "grafana_dashboard_archive",
+		OrgID:            orgID,
+		OrgRole:          org.RoleViewer,
+		Permissions: map[int64]map[string][]string{
+			orgID: {
+				dashboards.ActionFoldersRead:    []string{dashboards.ScopeFoldersAll},
+				dashboards.ActionDashboardsRead: []string{dashboards.ScopeDashboardsAll},
+			},
+		},
+	}
+}
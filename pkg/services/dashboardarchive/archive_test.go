@@ -0,0 +1,161 @@
+package dashboardarchive
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+func TestReadConfig(t *testing.T) {
+	t.Run("reads the schedules of the directory", func(t *testing.T) {
+		schedules, err := readConfig("testdata/valid")
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+
+		s := schedules[0]
+		assert.Equal(t, "finance-daily", s.Name)
+		assert.Equal(t, int64(2), s.OrgID)
+		assert.Equal(t, []string{"revenue"}, s.DashboardUIDs)
+		assert.Equal(t, []string{"finance"}, s.FolderUIDs)
+		assert.False(t, s.Render)
+		next := s.schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), next)
+
+		s = schedules[1]
+		assert.Equal(t, int64(1), s.OrgID)
+		assert.True(t, s.Render)
+	})
+
+	t.Run("no schedules if the directory does not exist", func(t *testing.T) {
+		schedules, err := readConfig("testdata/missing")
+		require.NoError(t, err)
+		require.Empty(t, schedules)
+	})
+
+	t.Run("validates the schedules", func(t *testing.T) {
+		for _, cfg := range []scheduleConfig{
+			{Cron: "@daily", Dashboards: []string{"a"}},
+			{Name: "a", Cron: "every day", Dashboards: []string{"a"}},
+			{Name: "a", Cron: "@daily"},
+		} {
+			_, err := cfg.schedule()
+			assert.Error(t, err, cfg)
+		}
+	})
+}
+
+func TestCapture(t *testing.T) {
+	now := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	sc := Schedule{Name: "finance-daily", OrgID: 1, DashboardUIDs: []string{"revenue"}, FolderUIDs: []string{"finance"}, Render: true}
+
+	setup := func(t *testing.T) (*Service, *blob.Bucket) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("FindDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.FindPersistedDashboardsQuery) bool {
+			return q.OrgId == 1 && assert.ObjectsAreEqual([]string{"finance"}, q.FolderUIDs)
+		})).Return([]dashboards.DashboardSearchProjection{{UID: "revenue"}, {UID: "costs"}}, nil).Maybe()
+		for _, uid := range []string{"revenue", "costs"} {
+			dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{OrgID: 1, UID: uid}).Return(&dashboards.Dashboard{
+				OrgID:   1,
+				UID:     uid,
+				Slug:    uid,
+				Title:   uid,
+				Version: 3,
+				Data:    simplejson.NewFromAny(map[string]any{"uid": uid, "title": uid}),
+			}, nil).Maybe()
+		}
+
+		renderService := rendering.NewMockService(gomock.NewController(t))
+		renderService.EXPECT().IsAvailable(gomock.Any()).Return(true).AnyTimes()
+		renderService.EXPECT().Render(gomock.Any(), gomock.Any(), nil).DoAndReturn(func(_ context.Context, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+			assert.Equal(t, rendering.RenderClassReport, opts.Class)
+			path := filepath.Join(t.TempDir(), "image.png")
+			require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+			return &rendering.RenderResult{FilePath: path}, nil
+		}).AnyTimes()
+
+		bucket := memblob.OpenBucket(nil)
+		t.Cleanup(func() { _ = bucket.Close() })
+		return &Service{
+			log:              log.NewNopLogger(),
+			dashboardService: dashboardService,
+			renderService:    renderService,
+			metrics:          newMetrics(nil),
+			bucket:           bucket,
+			now:              func() time.Time { return now },
+		}, bucket
+	}
+
+	t.Run("captures the dashboards and the dashboards of the folders", func(t *testing.T) {
+		s, bucket := setup(t)
+		s.runSchedule(context.Background(), sc)
+
+		for _, uid := range []string{"revenue", "costs"} {
+			prefix := "1/" + uid + "/20240301T020000Z/"
+			model, err := bucket.ReadAll(context.Background(), prefix+dashboardObject)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"uid":"`+uid+`","title":"`+uid+`"}`, string(model))
+
+			image, err := bucket.ReadAll(context.Background(), prefix+imageObject)
+			require.NoError(t, err)
+			assert.Equal(t, "png", string(image))
+
+			content, err := bucket.ReadAll(context.Background(), prefix+manifestObject)
+			require.NoError(t, err)
+			var m manifest
+			require.NoError(t, json.Unmarshal(content, &m))
+			assert.Equal(t, "finance-daily", m.Schedule)
+			assert.Equal(t, uid, m.DashboardUID)
+			assert.Equal(t, 3, m.Version)
+			assert.Equal(t, now, m.CapturedAt)
+			assert.Len(t, m.Checksums, 2)
+		}
+	})
+
+	t.Run("does not overwrite captures", func(t *testing.T) {
+		s, bucket := setup(t)
+		require.NoError(t, s.capture(context.Background(), sc, "revenue"))
+
+		err := s.capture(context.Background(), sc, "revenue")
+		require.ErrorIs(t, err, ErrCaptureExists)
+		model, err := bucket.ReadAll(context.Background(), "1/revenue/20240301T020000Z/"+dashboardObject)
+		require.NoError(t, err)
+		assert.NotEmpty(t, model)
+	})
+
+	t.Run("deletes the captures older than the retention", func(t *testing.T) {
+		s, bucket := setup(t)
+		s.retention = 30 * 24 * time.Hour
+		old := now.Add(-31 * 24 * time.Hour)
+		recent := now.Add(-29 * 24 * time.Hour)
+		for _, capturedAt := range []time.Time{old, recent} {
+			key := "1/revenue/" + capturedAt.Format(captureTimeFormat) + "/" + dashboardObject
+			require.NoError(t, bucket.WriteAll(context.Background(), key, []byte("{}"), nil))
+		}
+
+		s.runSchedule(context.Background(), Schedule{Name: "daily", OrgID: 1, DashboardUIDs: []string{"revenue"}})
+
+		exists, err := bucket.Exists(context.Background(), "1/revenue/"+old.Format(captureTimeFormat)+"/"+dashboardObject)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		for _, capturedAt := range []time.Time{recent, now} {
+			exists, err := bucket.Exists(context.Background(), "1/revenue/"+capturedAt.Format(captureTimeFormat)+"/"+dashboardObject)
+			require.NoError(t, err)
+			assert.True(t, exists)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package dashboardarchive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Schedule captures the dashboards, and the dashboards of the folders, of an org on a cron schedule.
+type Schedule struct {
+	// Name identifies the schedule.
+	Name  string
+	OrgID int64
+	// Cron is the cron expression of the schedule, in the standard 5 fields format.
+	Cron     string
+	schedule cron.Schedule
+	// DashboardUIDs are the UIDs of the captured dashboards.
+	DashboardUIDs []string
+	// FolderUIDs are the UIDs of the folders whose dashboards are captured.
+	FolderUIDs []string
+	// Render captures an image of the dashboards in addition to their JSON model.
+	Render bool
+}
+
+type configFile struct {
+	APIVersion int64            `yaml:"apiVersion"`
+	Schedules  []scheduleConfig `yaml:"schedules"`
+}
+
+type scheduleConfig struct {
+	Name       string   `yaml:"name"`
+	OrgID      int64    `yaml:"orgId"`
+	Cron       string   `yaml:"cron"`
+	Dashboards []string `yaml:"dashboards"`
+	Folders    []string `yaml:"folders"`
+	Render     *bool    `yaml:"render"`
+}
+
+// readConfig reads the schedules of the YAML files of a directory. A missing directory has no schedules.
+func readConfig(path string) ([]Schedule, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var schedules []Schedule
+	names := make(map[string]string)
+	for _, file := range files {
+		if file.IsDir() || !(strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml")) {
+			continue
+		}
+
+		filename := filepath.Join(path, file.Name())
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `filename` comes from the provisioning path
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+
+		for _, sc := range cfg.Schedules {
+			s, err := sc.schedule()
+			if err != nil {
+				return nil, fmt.Errorf("invalid dashboard archive schedule in %s: %w", filename, err)
+			}
+			key := fmt.Sprintf("%d/%s", s.OrgID, s.Name)
+			if other, ok := names[key]; ok {
+				return nil, fmt.Errorf("dashboard archive schedule %q of org %d is defined in both %s and %s", s.Name, s.OrgID, other, filename)
+			}
+			names[key] = filename
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules, nil
+}
+
+func (c scheduleConfig) schedule() (Schedule, error) {
+	s := Schedule{
+		Name:          c.Name,
+		OrgID:         c.OrgID,
+		Cron:          c.Cron,
+		DashboardUIDs: c.Dashboards,
+		FolderUIDs:    c.Folders,
+		Render:        true,
+	}
+	if s.Name == "" {
+		return s, errors.New("name is required")
+	}
+	if s.OrgID == 0 {
+		s.OrgID = 1
+	}
+	if c.Render != nil {
+		s.Render = *c.Render
+	}
+
+	var err error
+	if s.schedule, err = cron.ParseStandard(s.Cron); err != nil {
+		return s, fmt.Errorf("invalid cron %q of schedule %q: %w", s.Cron, s.Name, err)
+	}
+	if len(s.DashboardUIDs) == 0 && len(s.FolderUIDs) == 0 {
+		return s, fmt.Errorf("schedule %q has no dashboards or folders", s.Name)
+	}
+	return s, nil
+}
@@ -0,0 +1,38 @@
+package dashboardarchive
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "dashboard_archive"
+)
+
+type metrics struct {
+	captures *prometheus.CounterVec
+	deleted  prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		captures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "captures_total",
+			Help:      "Number of captures of dashboards, by whether they were written to the archive",
+		}, []string{"status"}),
+		deleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "captures_deleted_total",
+			Help:      "Number of captures of dashboards deleted from the archive after their retention",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.captures, m.deleted)
+	}
+
+	return m
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
@@ -65,18 +66,24 @@ func (api *ImportDashboardAPI) ImportDashboard(c *contextmodel.ReqContext) respo
 		return response.Error(http.StatusUnprocessableEntity, "Dashboard must be set", nil)
 	}
 
-	limitReached, err := api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
-	if err != nil {
-		return response.Err(err)
-	}
+	// A dry run does not import the dashboard.
+	if !req.DryRun {
+		limitReached, err := api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+		if err != nil {
+			return response.Err(err)
+		}
 
-	if limitReached {
-		return response.Error(403, "Quota reached", nil)
+		if limitReached {
+			return response.Error(403, "Quota reached", nil)
+		}
 	}
 
 	req.User = c.SignedInUser
 	resp, err := api.dashboardImportService.ImportDashboard(c.Req.Context(), &req)
 	if err != nil {
+		if errors.Is(err, dashboardimport.ErrInvalidDatasourceMapping) {
+			return response.Err(err)
+		}
 		return apierrors.ToDashboardErrorResponse(c.Req.Context(), api.pluginStore, err)
 	}
 
@@ -134,7 +134,13 @@ func TestImportDashboardAPI(t *testing.T) {
 	})
 
 	t.Run("Quota reached", func(t *testing.T) {
-		service := &serviceMock{}
+		importDashboardServiceCalled := false
+		service := &serviceMock{
+			importDashboardFunc: func(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
+				importDashboardServiceCalled = true
+				return &dashboardimport.ImportDashboardResponse{}, nil
+			},
+		}
 		importDashboardAPI := New(service, quotaServiceFunc(quotaReached), nil, actest.FakeAccessControl{ExpectedEvaluate: true})
 
 		routeRegister := routing.NewRouteRegister()
@@ -155,6 +161,25 @@ func TestImportDashboardAPI(t *testing.T) {
 			require.NoError(t, err)
 			require.NoError(t, resp.Body.Close())
 			require.Equal(t, http.StatusForbidden, resp.StatusCode)
+			require.False(t, importDashboardServiceCalled)
+		})
+
+		t.Run("Signed in, dashboard model set, dry run should not check the quota", func(t *testing.T) {
+			cmd := &dashboardimport.ImportDashboardRequest{
+				Dashboard: simplejson.New(),
+				DryRun:    true,
+			}
+			jsonBytes, err := json.Marshal(cmd)
+			require.NoError(t, err)
+			req := s.NewPostRequest("/api/dashboards/import", bytes.NewReader(jsonBytes))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				UserID: 1,
+			})
+			resp, err := s.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.True(t, importDashboardServiceCalled)
 		})
 	})
 }
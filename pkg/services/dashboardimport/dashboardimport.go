@@ -5,8 +5,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
+// ErrInvalidDatasourceMapping is returned when a datasource mapping rule is not valid.
+var ErrInvalidDatasourceMapping = errutil.BadRequest("dashboardimport.invalid-datasource-mapping")
+
 // ImportDashboardInput definition of input parameters when importing a dashboard.
 type ImportDashboardInput struct {
 	Type     string `json:"type"`
@@ -15,6 +19,30 @@ type ImportDashboardInput struct {
 	Value    string `json:"value"`
 }
 
+// DatasourceMappingRule maps the datasources referenced by an imported dashboard to a datasource of the
+// organization. A rule matches a datasource if all of its Type, NameRegex and UID criteria that are set match.
+type DatasourceMappingRule struct {
+	// Type of the datasource plugin, e.g. prometheus. It does not match the references by name, which have no type.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// NameRegex is matched against the name of the datasource, known for datasource inputs and references by name.
+	NameRegex string `json:"nameRegex,omitempty" yaml:"nameRegex,omitempty"`
+	// UID of the datasource.
+	UID string `json:"uid,omitempty" yaml:"uid,omitempty"`
+	// DatasourceUID is the UID of the datasource the matching references are mapped to.
+	DatasourceUID string `json:"datasourceUid" yaml:"datasourceUid"`
+	// DatasourceType is the type of the datasource the matching references are mapped to, the type of the
+	// matching reference by default.
+	DatasourceType string `json:"datasourceType,omitempty" yaml:"datasourceType,omitempty"`
+}
+
+// DatasourceRefChange is a datasource reference of an imported dashboard changed by a mapping rule.
+type DatasourceRefChange struct {
+	// Path of the reference in the dashboard, e.g. panels[0].targets[1].datasource.
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
 // ImportDashboardRequest request object for importing a dashboard.
 type ImportDashboardRequest struct {
 	PluginId  string                 `json:"pluginId"`
@@ -25,6 +53,11 @@ type ImportDashboardRequest struct {
 	// Deprecated: use FolderUID instead
 	FolderId  int64  `json:"folderId"`
 	FolderUid string `json:"folderUid"`
+	// DatasourceMappings are applied in order to the datasource inputs that are not set, and to the
+	// datasource references of the panels and variables. The first matching rule applies.
+	DatasourceMappings []DatasourceMappingRule `json:"datasourceMappings"`
+	// DryRun returns the changes of the datasource references without importing the dashboard.
+	DryRun bool `json:"dryRun"`
 
 	User identity.Requester `json:"-"`
 }
@@ -47,6 +80,8 @@ type ImportDashboardResponse struct {
 	Description      string `json:"description"`
 	Path             string `json:"path"`
 	Removed          bool   `json:"removed"`
+	// DatasourceChanges are the datasource references changed by the mapping rules.
+	DatasourceChanges []DatasourceRefChange `json:"datasourceChanges,omitempty"`
 }
 
 // Service service interface for importing dashboards.
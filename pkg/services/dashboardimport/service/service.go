@@ -60,11 +60,18 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 		draftDashboard = dashboards.NewDashboardFromJson(req.Dashboard)
 	}
 
-	evaluator := utils.NewDashTemplateEvaluator(draftDashboard.Data, req.Inputs)
+	mapper, err := utils.NewDatasourceMapper(req.DatasourceMappings)
+	if err != nil {
+		return nil, err
+	}
+	inputs, datasourceChanges := mapper.Inputs(draftDashboard.Data, req.Inputs)
+
+	evaluator := utils.NewDashTemplateEvaluator(draftDashboard.Data, inputs)
 	generatedDash, err := evaluator.Eval()
 	if err != nil {
 		return nil, err
 	}
+	datasourceChanges = append(datasourceChanges, mapper.Apply(generatedDash)...)
 
 	// Maintain backwards compatibility by transforming array of library elements to map
 	libraryElements := generatedDash.Get("__elements")
@@ -83,6 +90,16 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 	generatedDash.Del("__inputs")
 	generatedDash.Del("__requires")
 
+	if req.DryRun {
+		return &dashboardimport.ImportDashboardResponse{
+			UID:               generatedDash.Get("uid").MustString(),
+			PluginId:          req.PluginId,
+			Title:             generatedDash.Get("title").MustString(),
+			Path:              req.Path,
+			DatasourceChanges: datasourceChanges,
+		}, nil
+	}
+
 	// here we need to get FolderId from FolderUID if it present in the request, if both exist, FolderUID would overwrite FolderID
 	if req.FolderUid != "" {
 		folder, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
@@ -150,18 +167,19 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 
 	revision := savedDashboard.Data.Get("revision").MustInt64(0)
 	return &dashboardimport.ImportDashboardResponse{
-		UID:              savedDashboard.UID,
-		PluginId:         req.PluginId,
-		Title:            savedDashboard.Title,
-		Path:             req.Path,
-		Revision:         revision,                // only used for plugin version tracking
-		FolderId:         savedDashboard.FolderID, // nolint:staticcheck
-		FolderUID:        req.FolderUid,
-		ImportedUri:      "db/" + savedDashboard.Slug,
-		ImportedUrl:      savedDashboard.GetURL(),
-		ImportedRevision: revision,
-		Imported:         true,
-		DashboardId:      savedDashboard.ID,
-		Slug:             savedDashboard.Slug,
+		UID:               savedDashboard.UID,
+		PluginId:          req.PluginId,
+		Title:             savedDashboard.Title,
+		Path:              req.Path,
+		Revision:          revision,                // only used for plugin version tracking
+		FolderId:          savedDashboard.FolderID, // nolint:staticcheck
+		FolderUID:         req.FolderUid,
+		ImportedUri:       "db/" + savedDashboard.Slug,
+		ImportedUrl:       savedDashboard.GetURL(),
+		ImportedRevision:  revision,
+		Imported:          true,
+		DashboardId:       savedDashboard.ID,
+		Slug:              savedDashboard.Slug,
+		DatasourceChanges: datasourceChanges,
 	}, nil
 }
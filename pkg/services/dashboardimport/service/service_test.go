@@ -165,6 +165,52 @@ func TestImportDashboardService(t *testing.T) {
 		panel := importDashboardArg.Dashboard.Data.Get("panels").GetIndex(0)
 		require.Equal(t, "prom", panel.Get("datasource").MustString())
 	})
+
+	t.Run("When importing a dashboard in dry run should map the datasources without saving the dashboard", func(t *testing.T) {
+		dashboardService := &dashboardServiceMock{
+			importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+				require.Fail(t, "the dashboard should not be saved")
+				return nil, nil
+			},
+		}
+		s := &ImportDashboardService{
+			dashboardService: dashboardService,
+		}
+
+		loadResp, err := loadTestDashboard(context.Background(), &plugindashboards.LoadPluginDashboardRequest{
+			Reference: "dashboard.json",
+		})
+		require.NoError(t, err)
+
+		req := &dashboardimport.ImportDashboardRequest{
+			Dashboard: loadResp.Dashboard.Data,
+			DatasourceMappings: []dashboardimport.DatasourceMappingRule{
+				{Type: "prometheus", NameRegex: "^gdev-", DatasourceUID: "mimir"},
+			},
+			DryRun: true,
+			User:   &user.SignedInUser{UserID: 2, OrgRole: org.RoleAdmin, OrgID: 3},
+		}
+		resp, err := s.ImportDashboard(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, resp.Imported)
+		require.Equal(t, "UDdpyzz7z", resp.UID)
+		require.Equal(t, []dashboardimport.DatasourceRefChange{
+			{Path: "__inputs[0]", Before: "${DS_GDEV-PROMETHEUS}", After: "mimir"},
+		}, resp.DatasourceChanges)
+	})
+
+	t.Run("When importing a dashboard with an invalid datasource mapping should return an error", func(t *testing.T) {
+		s := &ImportDashboardService{}
+		req := &dashboardimport.ImportDashboardRequest{
+			Dashboard: simplejson.New(),
+			DatasourceMappings: []dashboardimport.DatasourceMappingRule{
+				{NameRegex: "(", DatasourceUID: "mimir"},
+			},
+			User: &user.SignedInUser{UserID: 2, OrgRole: org.RoleAdmin, OrgID: 3},
+		}
+		_, err := s.ImportDashboard(context.Background(), req)
+		require.ErrorIs(t, err, dashboardimport.ErrInvalidDatasourceMapping)
+	})
 }
 
 func loadTestDashboard(ctx context.Context, req *plugindashboards.LoadPluginDashboardRequest) (*plugindashboards.LoadPluginDashboardResponse, error) {
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+)
+
+// builtInDatasources are referenced by their UID or name, and are never mapped.
+var builtInDatasources = map[string]bool{
+	"grafana":             true,
+	"-- Grafana --":       true,
+	"-- Mixed --":         true,
+	"-- Dashboard --":     true,
+	expr.DatasourceUID:    true,
+	expr.OldDatasourceUID: true,
+}
+
+type datasourceRef struct {
+	typ  string
+	uid  string
+	name string
+}
+
+type datasourceMappingRule struct {
+	dashboardimport.DatasourceMappingRule
+	nameRegex *regexp.Regexp
+}
+
+func (r *datasourceMappingRule) matches(ref datasourceRef) bool {
+	if r.Type != "" && r.Type != ref.typ {
+		return false
+	}
+	if r.UID != "" && r.UID != ref.uid {
+		return false
+	}
+	if r.nameRegex != nil && (ref.name == "" || !r.nameRegex.MatchString(ref.name)) {
+		return false
+	}
+	return true
+}
+
+// DatasourceMapper applies datasource mapping rules to the datasource inputs and references of a dashboard.
+type DatasourceMapper struct {
+	rules []*datasourceMappingRule
+}
+
+// NewDatasourceMapper validates the rules and returns a mapper applying them in order.
+func NewDatasourceMapper(rules []dashboardimport.DatasourceMappingRule) (*DatasourceMapper, error) {
+	m := &DatasourceMapper{rules: make([]*datasourceMappingRule, 0, len(rules))}
+	for i, rule := range rules {
+		if rule.DatasourceUID == "" {
+			return nil, dashboardimport.ErrInvalidDatasourceMapping.Errorf("datasource mapping %d: datasourceUid is required", i)
+		}
+		if rule.Type == "" && rule.NameRegex == "" && rule.UID == "" {
+			return nil, dashboardimport.ErrInvalidDatasourceMapping.Errorf("datasource mapping %d: one of type, nameRegex or uid is required", i)
+		}
+
+		compiled := &datasourceMappingRule{DatasourceMappingRule: rule}
+		if rule.NameRegex != "" {
+			nameRegex, err := regexp.Compile(rule.NameRegex)
+			if err != nil {
+				return nil, dashboardimport.ErrInvalidDatasourceMapping.Errorf("datasource mapping %d: invalid nameRegex: %w", i, err)
+			}
+			compiled.nameRegex = nameRegex
+		}
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+func (m *DatasourceMapper) match(ref datasourceRef) *datasourceMappingRule {
+	for _, rule := range m.rules {
+		if rule.matches(ref) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Inputs returns the inputs completed with the datasource inputs of the template that are not set, and
+// that a rule matches by the plugin type and the label of the input.
+func (m *DatasourceMapper) Inputs(template *simplejson.Json, inputs []dashboardimport.ImportDashboardInput) ([]dashboardimport.ImportDashboardInput, []dashboardimport.DatasourceRefChange) {
+	evaluator := NewDashTemplateEvaluator(template, inputs)
+	result := append([]dashboardimport.ImportDashboardInput{}, inputs...)
+	var changes []dashboardimport.DatasourceRefChange
+
+	for i, inputDef := range template.Get("__inputs").MustArray() {
+		inputDefJson := simplejson.NewFromAny(inputDef)
+		inputName := inputDefJson.Get("name").MustString()
+		inputType := inputDefJson.Get("type").MustString()
+		pluginID := inputDefJson.Get("pluginId").MustString()
+		if inputType != "datasource" || evaluator.findInput(inputName, inputType) != nil {
+			continue
+		}
+
+		rule := m.match(datasourceRef{typ: pluginID, name: inputDefJson.Get("label").MustString()})
+		if rule == nil {
+			continue
+		}
+		result = append(result, dashboardimport.ImportDashboardInput{
+			Type:     inputType,
+			PluginId: pluginID,
+			Name:     inputName,
+			Value:    rule.DatasourceUID,
+		})
+		changes = append(changes, dashboardimport.DatasourceRefChange{
+			Path:   fmt.Sprintf("__inputs[%d]", i),
+			Before: "${" + inputName + "}",
+			After:  rule.DatasourceUID,
+		})
+	}
+
+	return result, changes
+}
+
+// Apply maps the datasource references of the panels, their queries and the variables of the dashboard,
+// and returns the changes.
+func (m *DatasourceMapper) Apply(dash *simplejson.Json) []dashboardimport.DatasourceRefChange {
+	if len(m.rules) == 0 {
+		return nil
+	}
+
+	var changes []dashboardimport.DatasourceRefChange
+	m.applyPanels(dash.Get("panels"), "panels", &changes)
+	for i, variable := range dash.GetPath("templating", "list").MustArray() {
+		variableJson := simplejson.NewFromAny(variable)
+		// The query of a datasource variable is a plugin type, not a reference.
+		if variableJson.Get("type").MustString() == "datasource" {
+			continue
+		}
+		m.applyRef(variableJson, fmt.Sprintf("templating.list[%d]", i), &changes)
+	}
+	return changes
+}
+
+func (m *DatasourceMapper) applyPanels(panels *simplejson.Json, path string, changes *[]dashboardimport.DatasourceRefChange) {
+	for i, panel := range panels.MustArray() {
+		panelJson := simplejson.NewFromAny(panel)
+		panelPath := fmt.Sprintf("%s[%d]", path, i)
+		m.applyRef(panelJson, panelPath, changes)
+		for j, target := range panelJson.Get("targets").MustArray() {
+			m.applyRef(simplejson.NewFromAny(target), fmt.Sprintf("%s.targets[%d]", panelPath, j), changes)
+		}
+		// Collapsed rows hold their panels.
+		m.applyPanels(panelJson.Get("panels"), panelPath+".panels", changes)
+	}
+}
+
+func (m *DatasourceMapper) applyRef(source *simplejson.Json, path string, changes *[]dashboardimport.DatasourceRefChange) {
+	value, ok := source.CheckGet("datasource")
+	if !ok {
+		return
+	}
+
+	var ref datasourceRef
+	switch v := value.Interface().(type) {
+	case string:
+		// Legacy references are by name, or by UID.
+		ref.name, ref.uid = v, v
+	case map[string]any:
+		ref.typ, _ = v["type"].(string)
+		ref.uid, _ = v["uid"].(string)
+	default:
+		return
+	}
+	if ref.uid == "" || builtInDatasources[ref.uid] || strings.HasPrefix(ref.uid, "$") {
+		return
+	}
+
+	rule := m.match(ref)
+	if rule == nil {
+		return
+	}
+	after := map[string]any{"uid": rule.DatasourceUID}
+	for _, typ := range []string{rule.DatasourceType, ref.typ, rule.Type} {
+		if typ != "" {
+			after["type"] = typ
+			break
+		}
+	}
+	before := value.Interface()
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	source.Set("datasource", after)
+	*changes = append(*changes, dashboardimport.DatasourceRefChange{
+		Path:   path + ".datasource",
+		Before: before,
+		After:  after,
+	})
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+)
+
+func TestDatasourceMapper(t *testing.T) {
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		for _, rule := range []dashboardimport.DatasourceMappingRule{
+			{Type: "prometheus"},
+			{DatasourceUID: "mimir"},
+			{NameRegex: "(", DatasourceUID: "mimir"},
+		} {
+			_, err := NewDatasourceMapper([]dashboardimport.DatasourceMappingRule{rule})
+			require.ErrorIs(t, err, dashboardimport.ErrInvalidDatasourceMapping)
+		}
+	})
+
+	t.Run("maps the datasource inputs that are not set", func(t *testing.T) {
+		template, err := simplejson.NewJson([]byte(`{
+			"__inputs": [
+				{"name": "DS_PROM", "label": "prom-eu", "type": "datasource", "pluginId": "prometheus"},
+				{"name": "DS_LOKI", "label": "loki", "type": "datasource", "pluginId": "loki"},
+				{"name": "DS_SET", "label": "prom-us", "type": "datasource", "pluginId": "prometheus"}
+			]
+		}`))
+		require.NoError(t, err)
+
+		mapper, err := NewDatasourceMapper([]dashboardimport.DatasourceMappingRule{
+			{Type: "prometheus", NameRegex: "^prom-", DatasourceUID: "mimir"},
+		})
+		require.NoError(t, err)
+
+		inputs, changes := mapper.Inputs(template, []dashboardimport.ImportDashboardInput{
+			{Name: "DS_SET", Type: "datasource", Value: "prom-us"},
+		})
+		require.Equal(t, []dashboardimport.ImportDashboardInput{
+			{Name: "DS_SET", Type: "datasource", Value: "prom-us"},
+			{Name: "DS_PROM", Type: "datasource", PluginId: "prometheus", Value: "mimir"},
+		}, inputs)
+		require.Equal(t, []dashboardimport.DatasourceRefChange{
+			{Path: "__inputs[0]", Before: "${DS_PROM}", After: "mimir"},
+		}, changes)
+	})
+
+	t.Run("maps the datasource references of the panels and variables", func(t *testing.T) {
+		dash, err := simplejson.NewJson([]byte(`{
+			"panels": [
+				{
+					"datasource": {"type": "prometheus", "uid": "prom-eu"},
+					"targets": [
+						{"datasource": {"type": "prometheus", "uid": "prom-eu"}},
+						{"datasource": {"type": "__expr__", "uid": "__expr__"}}
+					]
+				},
+				{"datasource": "-- Mixed --"},
+				{"datasource": {"type": "loki", "uid": "loki"}},
+				{
+					"type": "row",
+					"panels": [{"datasource": "Prometheus EU"}]
+				}
+			],
+			"templating": {
+				"list": [
+					{"type": "query", "datasource": {"type": "prometheus", "uid": "prom-eu"}},
+					{"type": "query", "datasource": {"uid": "$ds"}},
+					{"type": "datasource", "query": "prometheus"}
+				]
+			}
+		}`))
+		require.NoError(t, err)
+
+		mapper, err := NewDatasourceMapper([]dashboardimport.DatasourceMappingRule{
+			{UID: "prom-eu", DatasourceUID: "mimir"},
+			// The type of a reference by name is not known.
+			{NameRegex: "^Prometheus", DatasourceUID: "mimir", DatasourceType: "prometheus"},
+		})
+		require.NoError(t, err)
+
+		mimir := map[string]any{"type": "prometheus", "uid": "mimir"}
+		promEU := map[string]any{"type": "prometheus", "uid": "prom-eu"}
+		require.Equal(t, []dashboardimport.DatasourceRefChange{
+			{Path: "panels[0].datasource", Before: promEU, After: mimir},
+			{Path: "panels[0].targets[0].datasource", Before: promEU, After: mimir},
+			{Path: "panels[3].panels[0].datasource", Before: "Prometheus EU", After: mimir},
+			{Path: "templating.list[0].datasource", Before: promEU, After: mimir},
+		}, mapper.Apply(dash))
+		require.Equal(t, "mimir", dash.GetPath("panels").GetIndex(0).GetPath("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "loki", dash.GetPath("panels").GetIndex(2).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "$ds", dash.GetPath("templating", "list").GetIndex(1).GetPath("datasource", "uid").MustString())
+	})
+}
@@ -0,0 +1,303 @@
+// Package dashboardrender renders dashboards on demand with the screenshot service and fingerprints the images with
+// a perceptual hash, so that CI pipelines can detect visual regressions of provisioned dashboards by comparing the
+// hash of a render with the hash of a known-good baseline. It also resolves the data links of the rendered panels,
+// so that reports can embed clickable links next to the images.
+package dashboardrender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/screenshot"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// DefaultThreshold is the maximum distance between the hash of a render and the baseline hash for which the
+	// render is considered unchanged.
+	DefaultThreshold = 5
+
+	maxSize       = 4096
+	renderTimeout = time.Minute
+)
+
+var (
+	ErrInvalidBaselineHash = errutil.BadRequest("dashboardrender.invalid-baseline-hash", errutil.WithPublicMessage("Invalid baseline hash, expected 16 hexadecimal characters"))
+	ErrInvalidThreshold    = errutil.BadRequest("dashboardrender.invalid-threshold", errutil.WithPublicMessage("The threshold must be between 0 and 64"))
+	ErrInvalidSize         = errutil.BadRequest("dashboardrender.invalid-size", errutil.WithPublicMessage("The width and height must be at most 4096 pixels"))
+	ErrInvalidTheme        = errutil.BadRequest("dashboardrender.invalid-theme", errutil.WithPublicMessage("The theme must be light or dark"))
+	ErrDashboardNotFound   = errutil.NotFound("dashboardrender.dashboard-not-found", errutil.WithPublicMessage("Dashboard not found"))
+	ErrRendererUnavailable = errutil.NotImplemented("dashboardrender.renderer-unavailable", errutil.WithPublicMessage("No image renderer available"))
+	ErrRendererBusy        = errutil.TooManyRequests("dashboardrender.renderer-busy", errutil.WithPublicMessage("The image renderer is busy, retry later"))
+	ErrRenderTimeout       = errutil.GatewayTimeout("dashboardrender.render-timeout", errutil.WithPublicMessage("The dashboard did not render in time"))
+)
+
+// RenderRequest is the payload of the render endpoint.
+type RenderRequest struct {
+	// PanelID The panel to render. The whole dashboard is rendered when it is not set.
+	PanelID int64 `json:"panelId,omitempty"`
+	// From The start of the time range, the time range saved in the dashboard is used when From or To is not set.
+	// example: 2023-01-01T00:00:00Z
+	From string `json:"from,omitempty"`
+	// To The end of the time range.
+	// example: 2023-01-02T00:00:00Z
+	To string `json:"to,omitempty"`
+	// Variables The values of the dashboard variables, by variable name.
+	Variables map[string][]string `json:"variables,omitempty"`
+	// Width The width of the image in pixels, 1000 by default.
+	Width int `json:"width,omitempty"`
+	// Height The height of the image in pixels, 500 by default.
+	Height int `json:"height,omitempty"`
+	// Theme The theme of the image, light or dark. Dark by default.
+	Theme string `json:"theme,omitempty"`
+	// BaselineHash The perceptual hash of a previous render the render is compared with.
+	BaselineHash string `json:"baselineHash,omitempty"`
+	// Threshold The maximum distance from the baseline hash for which the render is unchanged, 5 by default.
+	Threshold *int `json:"threshold,omitempty"`
+	// IncludeLinks Resolve the data links of the rendered panels, with the time range and variables of the render.
+	IncludeLinks bool `json:"includeLinks,omitempty"`
+}
+
+// RenderResult is the image of a dashboard and its perceptual hash.
+type RenderResult struct {
+	// Image The PNG image, encoded in base64.
+	Image []byte `json:"image"`
+	// Hash The perceptual hash of the image, 16 hexadecimal characters.
+	Hash string `json:"hash"`
+	// Distance The number of bits that differ between the hash and the baseline hash, from 0 to 64. Only set when a
+	// baseline hash is given.
+	Distance *int `json:"distance,omitempty"`
+	// Changed True if the distance is above the threshold. Only set when a baseline hash is given.
+	Changed *bool `json:"changed,omitempty"`
+	// Links The resolved links of the rendered panels. Only set when includeLinks is true.
+	Links []PanelLinks `json:"links,omitempty"`
+}
+
+type Service struct {
+	cfg              *setting.Cfg
+	log              log.Logger
+	dashboardService dashboards.DashboardService
+	screenshots      screenshot.ScreenshotService
+}
+
+func ProvideService(cfg *setting.Cfg, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService,
+	renderService rendering.Service, registerer prometheus.Registerer) *Service {
+	s := &Service{
+		cfg:              cfg,
+		log:              log.New("dashboard-render"),
+		dashboardService: dashboardService,
+		screenshots:      newScreenshotService(dashboardService, renderService, registerer),
+	}
+	s.registerAPIEndpoints(routeRegister, ac)
+	return s
+}
+
+// newScreenshotService returns the screenshot service used for the renders, which fails fast when no renderer is
+// available.
+func newScreenshotService(dashboardService dashboards.DashboardService, renderService rendering.Service,
+	registerer prometheus.Registerer) screenshot.ScreenshotService {
+	return &availableScreenshotService{
+		renderService:     renderService,
+		ScreenshotService: screenshot.NewHeadlessScreenshotService(dashboardService, renderService, registerer),
+	}
+}
+
+type availableScreenshotService struct {
+	screenshot.ScreenshotService
+	renderService rendering.Service
+}
+
+func (s *availableScreenshotService) Take(ctx context.Context, opts screenshot.ScreenshotOptions) (*screenshot.Screenshot, error) {
+	if !s.renderService.IsAvailable(ctx) {
+		return nil, rendering.ErrRenderUnavailable
+	}
+	return s.ScreenshotService.Take(ctx, opts)
+}
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	router.Post("/api/dashboards/uid/:uid/render", middleware.ReqSignedIn,
+		authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), routing.Wrap(s.renderDashboard))
+	router.Post("/api/dashboards/uid/:uid/links", middleware.ReqSignedIn,
+		authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), routing.Wrap(s.resolveDashboardLinks))
+}
+
+// swagger:route POST /dashboards/uid/{uid}/render dashboards renderDashboard
+//
+// Render a dashboard and compute the perceptual hash of the image.
+//
+// Renders the dashboard, or one of its panels, with the given time range and variables, as the signed in user. The
+// response contains the PNG image and its perceptual hash. When a baseline hash is given, the response also tells
+// whether the image differs from the baseline, so CI pipelines can detect visual regressions of dashboards. With
+// includeLinks, the response also contains the resolved data links of the rendered panels. Requires the image
+// renderer.
+//
+// Responses:
+// 200: renderDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) renderDashboard(c *contextmodel.ReqContext) response.Response {
+	req := RenderRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.Render(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], req)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// Render renders a dashboard as the user and returns the image with its perceptual hash.
+func (s *Service) Render(ctx context.Context, user identity.Requester, dashboardUID string, req RenderRequest) (*RenderResult, error) {
+	var baseline uint64
+	if req.BaselineHash != "" {
+		var err error
+		if baseline, err = ParseHash(req.BaselineHash); err != nil {
+			return nil, ErrInvalidBaselineHash.Errorf("%w", err)
+		}
+	}
+	threshold := DefaultThreshold
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+	if threshold < 0 || threshold > 64 {
+		return nil, ErrInvalidThreshold.Errorf("invalid threshold %d", threshold)
+	}
+	if req.Width < 0 || req.Width > maxSize || req.Height < 0 || req.Height > maxSize {
+		return nil, ErrInvalidSize.Errorf("invalid size %dx%d", req.Width, req.Height)
+	}
+	theme := models.Theme(req.Theme)
+	switch theme {
+	case "", models.ThemeDark, models.ThemeLight:
+	default:
+		return nil, ErrInvalidTheme.Errorf("invalid theme %q", req.Theme)
+	}
+
+	userID, err := identity.UserIdentifier(user.GetNamespacedID())
+	if err != nil {
+		return nil, err
+	}
+
+	shot, err := s.screenshots.Take(ctx, screenshot.ScreenshotOptions{
+		OrgID:        user.GetOrgID(),
+		DashboardUID: dashboardUID,
+		PanelID:      req.PanelID,
+		From:         req.From,
+		To:           req.To,
+		Variables:    req.Variables,
+		Width:        req.Width,
+		Height:       req.Height,
+		Theme:        theme,
+		Timeout:      renderTimeout,
+		UserID:       userID,
+		OrgRole:      user.GetOrgRole(),
+		Class:        rendering.RenderClassInteractive,
+	})
+	if err != nil {
+		return nil, renderError(err)
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path is the one of the rendered image
+	image, err := os.ReadFile(shot.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(shot.Path); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to remove rendered image", "path", shot.Path, "error", err)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+	hash := PerceptualHash(decoded)
+	result := &RenderResult{Image: image, Hash: FormatHash(hash)}
+	if req.BaselineHash != "" {
+		distance := Distance(hash, baseline)
+		changed := distance > threshold
+		result.Distance, result.Changed = &distance, &changed
+	}
+	if req.IncludeLinks {
+		if result.Links, err = s.ResolveLinks(ctx, user, dashboardUID, LinksRequest{
+			PanelID:   req.PanelID,
+			From:      req.From,
+			To:        req.To,
+			Variables: req.Variables,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func renderError(err error) error {
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return ErrDashboardNotFound.Errorf("%w", err)
+	case errors.Is(err, rendering.ErrRenderUnavailable), errors.Is(err, screenshot.ErrScreenshotsUnavailable):
+		return ErrRendererUnavailable.Errorf("%w", err)
+	case errors.Is(err, rendering.ErrConcurrentLimitReached):
+		return ErrRendererBusy.Errorf("%w", err)
+	case errors.Is(err, rendering.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrRenderTimeout.Errorf("%w", err)
+	}
+	return err
+}
+
+// swagger:parameters renderDashboard
+type RenderDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body RenderRequest
+}
+
+// swagger:response renderDashboardResponse
+type RenderDashboardResponse struct {
+	// in: body
+	Body RenderResult `json:"body"`
+}
+
+// swagger:parameters resolveDashboardLinks
+type ResolveDashboardLinksParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body LinksRequest
+}
+
+// swagger:response resolveDashboardLinksResponse
+type ResolveDashboardLinksResponse struct {
+	// in: body
+	Body []PanelLinks `json:"body"`
+}
@@ -0,0 +1,181 @@
+package dashboardrender
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/screenshot"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// testImage returns an image with a horizontal gradient, reversed in the bottom half when changed is true.
+func testImage(changed bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 180, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 180; x++ {
+			v := uint8(x)
+			if changed && y >= 40 {
+				v = uint8(179 - x)
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	hash := PerceptualHash(testImage(false))
+	assert.Equal(t, hash, PerceptualHash(testImage(false)))
+
+	t.Run("is robust to small changes", func(t *testing.T) {
+		img := testImage(false).(*image.RGBA)
+		img.Set(10, 10, color.RGBA{R: 255, A: 255})
+		assert.LessOrEqual(t, Distance(hash, PerceptualHash(img)), DefaultThreshold)
+	})
+
+	t.Run("detects visual changes", func(t *testing.T) {
+		assert.Greater(t, Distance(hash, PerceptualHash(testImage(true))), DefaultThreshold)
+	})
+
+	t.Run("formats and parses hashes", func(t *testing.T) {
+		parsed, err := ParseHash(FormatHash(hash))
+		require.NoError(t, err)
+		assert.Equal(t, hash, parsed)
+		assert.Len(t, FormatHash(1), 16)
+
+		_, err = ParseHash("abc")
+		assert.Error(t, err)
+		_, err = ParseHash("zzzzzzzzzzzzzzzz")
+		assert.Error(t, err)
+	})
+}
+
+type fakeScreenshotService struct {
+	dir      string
+	img      image.Image
+	err      error
+	lastOpts screenshot.ScreenshotOptions
+}
+
+func (s *fakeScreenshotService) Take(_ context.Context, opts screenshot.ScreenshotOptions) (*screenshot.Screenshot, error) {
+	s.lastOpts = opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, s.img); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.dir, "render.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	return &screenshot.Screenshot{Path: path}, nil
+}
+
+func TestService_Render(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleViewer}
+	setup := func(t *testing.T) (*Service, *fakeScreenshotService) {
+		shots := &fakeScreenshotService{dir: t.TempDir(), img: testImage(false)}
+		return &Service{log: log.NewNopLogger(), screenshots: shots}, shots
+	}
+
+	t.Run("renders the dashboard as the user", func(t *testing.T) {
+		s, shots := setup(t)
+
+		result, err := s.Render(context.Background(), usr, "dash", RenderRequest{
+			From:      "2023-01-01T00:00:00Z",
+			To:        "2023-01-02T00:00:00Z",
+			Variables: map[string][]string{"env": {"prod"}},
+			Theme:     "light",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, FormatHash(PerceptualHash(testImage(false))), result.Hash)
+		decoded, err := png.Decode(bytes.NewReader(result.Image))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 180, 80), decoded.Bounds())
+		assert.Nil(t, result.Distance)
+		assert.Nil(t, result.Changed)
+		assert.NoFileExists(t, filepath.Join(shots.dir, "render.png"))
+
+		assert.Equal(t, int64(1), shots.lastOpts.OrgID)
+		assert.Equal(t, "dash", shots.lastOpts.DashboardUID)
+		assert.Equal(t, int64(3), shots.lastOpts.UserID)
+		assert.Equal(t, org.RoleViewer, shots.lastOpts.OrgRole)
+		assert.Equal(t, map[string][]string{"env": {"prod"}}, shots.lastOpts.Variables)
+		assert.Equal(t, rendering.RenderClassInteractive, shots.lastOpts.Class)
+	})
+
+	t.Run("compares the render with the baseline", func(t *testing.T) {
+		s, shots := setup(t)
+		baseline := FormatHash(PerceptualHash(testImage(false)))
+
+		result, err := s.Render(context.Background(), usr, "dash", RenderRequest{BaselineHash: baseline})
+		require.NoError(t, err)
+		assert.Equal(t, 0, *result.Distance)
+		assert.False(t, *result.Changed)
+
+		shots.img = testImage(true)
+		result, err = s.Render(context.Background(), usr, "dash", RenderRequest{BaselineHash: baseline})
+		require.NoError(t, err)
+		assert.Greater(t, *result.Distance, DefaultThreshold)
+		assert.True(t, *result.Changed)
+
+		threshold := 64
+		result, err = s.Render(context.Background(), usr, "dash", RenderRequest{BaselineHash: baseline, Threshold: &threshold})
+		require.NoError(t, err)
+		assert.False(t, *result.Changed)
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		s, _ := setup(t)
+		threshold := 65
+		for _, req := range []RenderRequest{
+			{BaselineHash: "not-a-hash"},
+			{Threshold: &threshold},
+			{Width: maxSize + 1},
+			{Theme: "blue"},
+		} {
+			_, err := s.Render(context.Background(), usr, "dash", req)
+			var gfErr errutil.Error
+			require.ErrorAs(t, err, &gfErr)
+			assert.Equal(t, http.StatusBadRequest, gfErr.Reason.Status().HTTPStatus())
+		}
+	})
+
+	t.Run("maps the render errors", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{err: dashboards.ErrDashboardNotFound, status: http.StatusNotFound},
+			{err: rendering.ErrRenderUnavailable, status: http.StatusNotImplemented},
+			{err: rendering.ErrConcurrentLimitReached, status: http.StatusTooManyRequests},
+			{err: rendering.ErrTimeout, status: http.StatusGatewayTimeout},
+		}
+		for _, tt := range tests {
+			s, shots := setup(t)
+			shots.err = tt.err
+			_, err := s.Render(context.Background(), usr, "dash", RenderRequest{})
+			var gfErr errutil.Error
+			require.ErrorAs(t, err, &gfErr)
+			assert.Equal(t, tt.status, gfErr.Reason.Status().HTTPStatus())
+		}
+	})
+}
@@ -0,0 +1,389 @@
+package dashboardrender
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// LinkOriginPanel is the origin of the links of a panel.
+	LinkOriginPanel = "panel"
+	// LinkOriginField is the origin of the data links of the fields of a panel.
+	LinkOriginField = "field"
+)
+
+var (
+	ErrPanelNotFound    = errutil.NotFound("dashboardrender.panel-not-found", errutil.WithPublicMessage("Panel not found"))
+	ErrInvalidTimeRange = errutil.BadRequest("dashboardrender.invalid-time-range", errutil.WithPublicMessage("Invalid time range"))
+)
+
+// variableRegex matches the $var, [[var]], [[var:format]] and ${var.field:format} syntaxes of the variables, as the
+// frontend does.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:}]+))?(?::([^}]+))?\}`)
+
+// dataMacros are the macros of the values of the series, which are only known when the data is rendered.
+var dataMacros = map[string]bool{"__value": true, "__series": true, "__field": true, "__data": true, "__cell": true}
+
+// LinksRequest is the payload of the links endpoint.
+type LinksRequest struct {
+	// PanelID The panel to resolve the links of. The links of all the panels are resolved when it is not set.
+	PanelID int64 `json:"panelId,omitempty"`
+	// From The start of the time range, the time range saved in the dashboard is used when From or To is not set.
+	// example: 2023-01-01T00:00:00Z
+	From string `json:"from,omitempty"`
+	// To The end of the time range.
+	// example: 2023-01-02T00:00:00Z
+	To string `json:"to,omitempty"`
+	// Variables The values of the dashboard variables, by variable name. The current values saved in the dashboard
+	// are used for the other variables.
+	Variables map[string][]string `json:"variables,omitempty"`
+}
+
+// ResolvedLink is a link of a panel whose variables and time range macros are replaced with their values.
+type ResolvedLink struct {
+	// Title The title of the link, with its variables resolved.
+	Title string `json:"title"`
+	// URL The absolute URL of the link.
+	URL string `json:"url"`
+	// TargetBlank True if the link opens in a new tab.
+	TargetBlank bool `json:"targetBlank,omitempty"`
+	// Origin The origin of the link, panel for the links of the panel, field for the data links of its fields.
+	// Enum: panel,field
+	Origin string `json:"origin"`
+	// Unresolved The variables that are kept as is in the link, such as the macros of the values of the series,
+	// which depend on the data point the link is clicked on.
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+// PanelLinks are the resolved links of a panel.
+type PanelLinks struct {
+	PanelID int64          `json:"panelId"`
+	Title   string         `json:"title"`
+	Links   []ResolvedLink `json:"links"`
+}
+
+// swagger:route POST /dashboards/uid/{uid}/links dashboards resolveDashboardLinks
+//
+// Resolve the data links of the panels of a dashboard.
+//
+// Returns the links of the panels of the dashboard, and the data links of their fields, with the dashboard variables
+// and the time range macros replaced with their values, so that the links can be embedded in a rendered report. The
+// macros of the values of the series cannot be resolved without the data, and are kept as is.
+//
+// Responses:
+// 200: resolveDashboardLinksResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) resolveDashboardLinks(c *contextmodel.ReqContext) response.Response {
+	req := LinksRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	links, err := s.ResolveLinks(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], req)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, links)
+}
+
+// ResolveLinks returns the links of the panels of a dashboard, resolved for the user, the time range and the
+// variables of the request.
+func (s *Service) ResolveLinks(ctx context.Context, user identity.Requester, dashboardUID string, req LinksRequest) ([]PanelLinks, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, renderError(err)
+	}
+
+	from, to := req.From, req.To
+	if from == "" || to == "" {
+		from, to = dash.Data.GetPath("time", "from").MustString("now-6h"), dash.Data.GetPath("time", "to").MustString("now")
+	}
+	tr := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := tr.ParseFrom()
+	if err != nil {
+		return nil, ErrInvalidTimeRange.Errorf("invalid from %q: %w", from, err)
+	}
+	toTime, err := tr.ParseTo()
+	if err != nil {
+		return nil, ErrInvalidTimeRange.Errorf("invalid to %q: %w", to, err)
+	}
+
+	r := &linkResolver{
+		appURL:    strings.TrimSuffix(s.cfg.AppURL, "/"),
+		dashboard: dash,
+		user:      user,
+		from:      fromTime,
+		to:        toTime,
+		variables: dashboardVariables(dash.Data, req.Variables),
+	}
+
+	result := []PanelLinks{}
+	for _, panel := range dashboardPanels(dash.Data) {
+		id := panel.Get("id").MustInt64()
+		if req.PanelID != 0 && id != req.PanelID {
+			continue
+		}
+		result = append(result, PanelLinks{PanelID: id, Title: r.interpolate(panel.Get("title").MustString(), nil), Links: r.panelLinks(panel)})
+	}
+	if req.PanelID != 0 && len(result) == 0 {
+		return nil, ErrPanelNotFound.Errorf("panel %d not found in dashboard %s", req.PanelID, dashboardUID)
+	}
+	return result, nil
+}
+
+// dashboardPanels returns the panels of a dashboard, including the panels of the collapsed rows.
+func dashboardPanels(data *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, panel := range data.Get("panels").MustArray() {
+		p := simplejson.NewFromAny(panel)
+		if p.Get("type").MustString() == "row" {
+			for _, nested := range p.Get("panels").MustArray() {
+				panels = append(panels, simplejson.NewFromAny(nested))
+			}
+			continue
+		}
+		panels = append(panels, p)
+	}
+	return panels
+}
+
+// dashboardVariable is a dashboard variable with its values.
+type dashboardVariable struct {
+	name   string
+	values []string
+}
+
+// dashboardVariables returns the variables of a dashboard, in the order of the dashboard, with the values of the
+// request or the current values saved in the dashboard. The variables of the request that are not in the dashboard
+// come last.
+func dashboardVariables(data *simplejson.Json, values map[string][]string) []dashboardVariable {
+	var variables []dashboardVariable
+	seen := map[string]bool{}
+	for _, v := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		value, ok := values[name]
+		if !ok {
+			current := variable.GetPath("current", "value")
+			if s, err := current.String(); err == nil {
+				value = []string{s}
+			} else {
+				value = current.MustStringArray()
+			}
+		}
+		variables = append(variables, dashboardVariable{name: name, values: value})
+	}
+
+	var extra []string
+	for name := range values {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		variables = append(variables, dashboardVariable{name: name, values: values[name]})
+	}
+	return variables
+}
+
+type linkResolver struct {
+	appURL    string
+	dashboard *dashboards.Dashboard
+	user      identity.Requester
+	from, to  time.Time
+	variables []dashboardVariable
+}
+
+// panelLinks returns the links of a panel followed by the data links of its fields.
+func (r *linkResolver) panelLinks(panel *simplejson.Json) []ResolvedLink {
+	links := []ResolvedLink{}
+	for _, link := range panel.Get("links").MustArray() {
+		links = append(links, r.resolve(simplejson.NewFromAny(link), LinkOriginPanel))
+	}
+
+	fieldLinks := panel.GetPath("fieldConfig", "defaults", "links").MustArray()
+	for _, override := range panel.GetPath("fieldConfig", "overrides").MustArray() {
+		for _, property := range simplejson.NewFromAny(override).Get("properties").MustArray() {
+			p := simplejson.NewFromAny(property)
+			if p.Get("id").MustString() == "links" {
+				fieldLinks = append(fieldLinks, p.Get("value").MustArray()...)
+			}
+		}
+	}
+	for _, link := range fieldLinks {
+		links = append(links, r.resolve(simplejson.NewFromAny(link), LinkOriginField))
+	}
+	return links
+}
+
+func (r *linkResolver) resolve(link *simplejson.Json, origin string) ResolvedLink {
+	var unresolved []string
+	resolved := ResolvedLink{
+		Title:       r.interpolate(link.Get("title").MustString(), &unresolved),
+		TargetBlank: link.Get("targetBlank").MustBool(),
+		Origin:      origin,
+	}
+	resolved.URL = r.interpolate(link.Get("url").MustString(), &unresolved)
+	if strings.HasPrefix(resolved.URL, "/") && !strings.HasPrefix(resolved.URL, "//") {
+		resolved.URL = r.appURL + resolved.URL
+	}
+	resolved.Unresolved = unresolved
+	return resolved
+}
+
+// interpolate replaces the variables of s with their values. The variables that cannot be resolved are kept as is
+// and appended to unresolved, if it is not nil.
+func (r *linkResolver) interpolate(s string, unresolved *[]string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := variableRegex.FindStringSubmatch(match)
+		name, field, format := m[1], "", ""
+		switch {
+		case m[2] != "":
+			name, format = m[2], m[3]
+		case m[4] != "":
+			name, field, format = m[4], m[5], m[6]
+		}
+
+		if value, ok := r.value(name, field, format); ok {
+			return value
+		}
+		if unresolved != nil && !slices.Contains(*unresolved, match) {
+			*unresolved = append(*unresolved, match)
+		}
+		return match
+	})
+}
+
+// value returns the value of a variable or of a macro, with the given format.
+func (r *linkResolver) value(name, field, format string) (string, bool) {
+	if dataMacros[name] {
+		return "", false
+	}
+
+	switch name {
+	case "__from":
+		return formatTime(r.from, field, format)
+	case "__to":
+		return formatTime(r.to, field, format)
+	case "__url_time_range":
+		return "from=" + strconv.FormatInt(r.from.UnixMilli(), 10) + "&to=" + strconv.FormatInt(r.to.UnixMilli(), 10), field == "" && format == ""
+	case "__all_variables":
+		params := make([]string, 0, len(r.variables))
+		for _, v := range r.variables {
+			params = append(params, queryParams(v.name, v.values))
+		}
+		return strings.Join(params, "&"), field == "" && format == ""
+	case "__dashboard":
+		switch field {
+		case "":
+			return formatValues("__dashboard", []string{r.dashboard.Title}, format)
+		case "uid":
+			return formatValues("__dashboard", []string{r.dashboard.UID}, format)
+		}
+		return "", false
+	case "__org":
+		if field == "id" {
+			return formatValues("__org", []string{strconv.FormatInt(r.user.GetOrgID(), 10)}, format)
+		}
+		return "", false
+	case "__user":
+		switch field {
+		case "login":
+			return formatValues("__user", []string{r.user.GetLogin()}, format)
+		case "email":
+			return formatValues("__user", []string{r.user.GetEmail()}, format)
+		case "id":
+			id, err := identity.UserIdentifier(r.user.GetNamespacedID())
+			if err != nil {
+				return "", false
+			}
+			return formatValues("__user", []string{strconv.FormatInt(id, 10)}, format)
+		}
+		return "", false
+	}
+
+	if field != "" {
+		return "", false
+	}
+	for _, v := range r.variables {
+		if v.name == name {
+			return formatValues(name, v.values, format)
+		}
+	}
+	return "", false
+}
+
+// formatTime formats the time of the ${__from} and ${__to} macros: the Unix time in milliseconds by default, the ISO
+// 8601 time with the date and date:iso formats, and the Unix time in seconds with the date:seconds format.
+func formatTime(t time.Time, field, format string) (string, bool) {
+	if field != "" {
+		return "", false
+	}
+	switch format {
+	case "":
+		return strconv.FormatInt(t.UnixMilli(), 10), true
+	case "date", "date:iso":
+		return t.UTC().Format(time.RFC3339), true
+	case "date:seconds":
+		return strconv.FormatInt(t.Unix(), 10), true
+	}
+	return "", false
+}
+
+// formatValues formats the values of a variable like the formats of the frontend. Variables with several values are
+// formatted with the glob format by default.
+func formatValues(name string, values []string, format string) (string, bool) {
+	switch format {
+	case "", "glob":
+		if len(values) == 1 {
+			return values[0], true
+		}
+		return "{" + strings.Join(values, ",") + "}", true
+	case "raw", "csv":
+		return strings.Join(values, ","), true
+	case "pipe":
+		return strings.Join(values, "|"), true
+	case "percentencode":
+		if len(values) == 1 {
+			return url.QueryEscape(values[0]), true
+		}
+		return url.QueryEscape("{" + strings.Join(values, ",") + "}"), true
+	case "queryparam":
+		return queryParams(name, values), true
+	}
+	return "", false
+}
+
+// queryParams returns the query parameters of a variable in the URL of a dashboard, such as var-host=a&var-host=b.
+func queryParams(name string, values []string) string {
+	params := make([]string, 0, len(values))
+	for _, value := range values {
+		params = append(params, url.QueryEscape("var-"+name)+"="+url.QueryEscape(value))
+	}
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,182 @@
+package dashboardrender
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const linksDashboard = `{
+	"uid": "dash",
+	"title": "Services",
+	"time": {"from": "2023-01-01T00:00:00Z", "to": "2023-01-02T00:00:00Z"},
+	"templating": {"list": [
+		{"name": "env", "current": {"value": "prod"}},
+		{"name": "host", "current": {"value": ["web-1", "web-2"]}}
+	]},
+	"panels": [
+		{
+			"id": 1,
+			"title": "Latency in $env",
+			"links": [
+				{"title": "Details of ${env}", "url": "/d/details?${__url_time_range}&${__all_variables}", "targetBlank": true},
+				{"title": "Runbook", "url": "https://runbooks.example.com/${__dashboard.uid}?user=${__user.login}&org=${__org.id}"}
+			],
+			"fieldConfig": {
+				"defaults": {"links": [
+					{"title": "Logs", "url": "/explore?from=${__from}&to=${__to:date:seconds}&host=${host:pipe}&series=${__series.name}"}
+				]},
+				"overrides": [
+					{"matcher": {"id": "byName", "options": "p99"}, "properties": [
+						{"id": "links", "value": [{"title": "Traces", "url": "/d/traces?${host:queryparam}&since=${__from:date}&q=${unknown}"}]}
+					]}
+				]
+			}
+		},
+		{
+			"type": "row",
+			"title": "Details",
+			"collapsed": true,
+			"panels": [{"id": 2, "title": "Errors", "links": [{"title": "Hosts", "url": "/d/hosts?host=[[host:csv]]"}]}]
+		}
+	]
+}`
+
+func TestService_ResolveLinks(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleViewer, Login: "alice"}
+	setup := func(t *testing.T) *Service {
+		data, err := simplejson.NewJson([]byte(linksDashboard))
+		require.NoError(t, err)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{UID: "dash", OrgID: 1}).
+			Return(&dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Services", Data: data}, nil).Maybe()
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+		return &Service{
+			cfg:              &setting.Cfg{AppURL: "https://grafana.example.com/"},
+			log:              log.NewNopLogger(),
+			dashboardService: dashboardService,
+		}
+	}
+
+	t.Run("resolves the links of the panels", func(t *testing.T) {
+		s := setup(t)
+
+		panels, err := s.ResolveLinks(context.Background(), usr, "dash", LinksRequest{})
+		require.NoError(t, err)
+		require.Len(t, panels, 2)
+
+		assert.Equal(t, int64(1), panels[0].PanelID)
+		assert.Equal(t, "Latency in prod", panels[0].Title)
+		assert.Equal(t, []ResolvedLink{
+			{
+				Title:       "Details of prod",
+				URL:         "https://grafana.example.com/d/details?from=1672531200000&to=1672617600000&var-env=prod&var-host=web-1&var-host=web-2",
+				TargetBlank: true,
+				Origin:      LinkOriginPanel,
+			},
+			{
+				Title:  "Runbook",
+				URL:    "https://runbooks.example.com/dash?user=alice&org=1",
+				Origin: LinkOriginPanel,
+			},
+			{
+				Title:      "Logs",
+				URL:        "https://grafana.example.com/explore?from=1672531200000&to=1672617600&host=web-1|web-2&series=${__series.name}",
+				Origin:     LinkOriginField,
+				Unresolved: []string{"${__series.name}"},
+			},
+			{
+				Title:      "Traces",
+				URL:        "https://grafana.example.com/d/traces?var-host=web-1&var-host=web-2&since=2023-01-01T00:00:00Z&q=${unknown}",
+				Origin:     LinkOriginField,
+				Unresolved: []string{"${unknown}"},
+			},
+		}, panels[0].Links)
+
+		assert.Equal(t, int64(2), panels[1].PanelID)
+		assert.Equal(t, "https://grafana.example.com/d/hosts?host=web-1,web-2", panels[1].Links[0].URL)
+	})
+
+	t.Run("uses the time range and variables of the request", func(t *testing.T) {
+		s := setup(t)
+
+		panels, err := s.ResolveLinks(context.Background(), usr, "dash", LinksRequest{
+			PanelID:   2,
+			From:      "1700000000000",
+			To:        "1700003600000",
+			Variables: map[string][]string{"host": {"db-1"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, panels, 1)
+		assert.Equal(t, "https://grafana.example.com/d/hosts?host=db-1", panels[0].Links[0].URL)
+
+		panels, err = s.ResolveLinks(context.Background(), usr, "dash", LinksRequest{PanelID: 1, From: "1700000000000", To: "1700003600000"})
+		require.NoError(t, err)
+		assert.Contains(t, panels[0].Links[0].URL, "from=1700000000000&to=1700003600000")
+	})
+
+	t.Run("includes the links of the rendered panel", func(t *testing.T) {
+		s := setup(t)
+		s.screenshots = &fakeScreenshotService{dir: t.TempDir(), img: testImage(false)}
+
+		result, err := s.Render(context.Background(), usr, "dash", RenderRequest{PanelID: 2, IncludeLinks: true})
+		require.NoError(t, err)
+		require.Len(t, result.Links, 1)
+		assert.Equal(t, "Hosts", result.Links[0].Links[0].Title)
+	})
+
+	t.Run("returns errors for unknown dashboards and panels", func(t *testing.T) {
+		s := setup(t)
+		tests := []struct {
+			uid    string
+			req    LinksRequest
+			status int
+		}{
+			{uid: "unknown", status: http.StatusNotFound},
+			{uid: "dash", req: LinksRequest{PanelID: 42}, status: http.StatusNotFound},
+			{uid: "dash", req: LinksRequest{From: "yesterday", To: "now"}, status: http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			_, err := s.ResolveLinks(context.Background(), usr, tt.uid, tt.req)
+			var gfErr errutil.Error
+			require.ErrorAs(t, err, &gfErr)
+			assert.Equal(t, tt.status, gfErr.Reason.Status().HTTPStatus())
+		}
+	})
+}
+
+func TestFormatValues(t *testing.T) {
+	values := []string{"a b", "c"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "", want: "{a b,c}"},
+		{format: "glob", want: "{a b,c}"},
+		{format: "raw", want: "a b,c"},
+		{format: "csv", want: "a b,c"},
+		{format: "pipe", want: "a b|c"},
+		{format: "percentencode", want: "%7Ba+b%2Cc%7D"},
+		{format: "queryparam", want: "var-host=a+b&var-host=c"},
+	}
+	for _, tt := range tests {
+		got, ok := formatValues("host", values, tt.format)
+		assert.True(t, ok, tt.format)
+		assert.Equal(t, tt.want, got, tt.format)
+	}
+
+	_, ok := formatValues("host", values, "regex")
+	assert.False(t, ok)
+}
@@ -0,0 +1,77 @@
+package dashboardrender
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+)
+
+// hashWidth and hashHeight are the size of the grayscale thumbnail the hash is computed from. Each bit of the hash
+// compares two horizontally adjacent pixels of the thumbnail.
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// PerceptualHash returns the difference hash of the image, a 64 bits fingerprint that is robust to small changes
+// like anti-aliasing and compression: similar images have hashes with a small Hamming distance.
+func PerceptualHash(img image.Image) uint64 {
+	var thumbnail [hashHeight][hashWidth]float64
+	bounds := img.Bounds()
+	for y := 0; y < hashHeight; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/hashHeight
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/hashHeight
+		for x := 0; x < hashWidth; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/hashWidth
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/hashWidth
+			thumbnail[y][x] = averageLuminance(img, x0, y0, max(x1, x0+1), max(y1, y0+1))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if thumbnail[y][x] < thumbnail[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// averageLuminance returns the average luminance of the pixels of the rectangle.
+func averageLuminance(img image.Image, x0, y0, x1, y1 int) float64 {
+	var sum float64
+	var count int
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// FormatHash returns the hexadecimal representation of a hash.
+func FormatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseHash parses the hexadecimal representation of a hash.
+func ParseHash(s string) (uint64, error) {
+	if len(s) != 16 {
+		return 0, fmt.Errorf("invalid hash %q: expected 16 hexadecimal characters", s)
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// Distance returns the number of bits that differ between two hashes, from 0 for identical images to 64.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -3,6 +3,7 @@ package dashboards
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	alertmodels "github.com/grafana/grafana/pkg/services/alerting/models"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -25,6 +26,11 @@ type DashboardService interface {
 	SearchDashboards(ctx context.Context, query *FindPersistedDashboardsQuery) (model.HitList, error)
 	CountInFolder(ctx context.Context, orgID int64, folderUID string, user identity.Requester) (int64, error)
 	GetDashboardsSharedWithUser(ctx context.Context, user identity.Requester) ([]*Dashboard, error)
+	// DecryptSecureOptions replaces the encrypted secure options of the panels of the dashboard with their plain text values.
+	DecryptSecureOptions(ctx context.Context, data *simplejson.Json) error
+	// RevealSecureOptions sets the plain text values of the secure options saved for the panels of the dashboard,
+	// which are redacted from the dashboards returned by the service.
+	RevealSecureOptions(ctx context.Context, dash *Dashboard) error
 }
 
 // PluginService is a service for operating on plugin dashboards.
@@ -5,6 +5,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	model "github.com/grafana/grafana/pkg/services/search/model"
 )
@@ -64,6 +65,20 @@ func (_m *FakeDashboardService) CountInFolder(ctx context.Context, orgID int64,
 	return r0, r1
 }
 
+// DecryptSecureOptions provides a mock function with given fields: ctx, data
+func (_m *FakeDashboardService) DecryptSecureOptions(ctx context.Context, data *simplejson.Json) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *simplejson.Json) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteDashboard provides a mock function with given fields: ctx, dashboardId, orgId
 func (_m *FakeDashboardService) DeleteDashboard(ctx context.Context, dashboardId int64, orgId int64) error {
 	ret := _m.Called(ctx, dashboardId, orgId)
@@ -234,6 +249,20 @@ func (_m *FakeDashboardService) ImportDashboard(ctx context.Context, dto *SaveDa
 	return r0, r1
 }
 
+// RevealSecureOptions provides a mock function with given fields: ctx, dash
+func (_m *FakeDashboardService) RevealSecureOptions(ctx context.Context, dash *Dashboard) error {
+	ret := _m.Called(ctx, dash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Dashboard) error); ok {
+		r0 = rf(ctx, dash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveDashboard provides a mock function with given fields: ctx, dto, allowUiUpdate
 func (_m *FakeDashboardService) SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*Dashboard, error) {
 	ret := _m.Called(ctx, dto, allowUiUpdate)
@@ -880,6 +880,10 @@ func (d *dashboardStore) GetDashboards(ctx context.Context, query *dashboards.Ge
 		if query.OrgID > 0 {
 			session = sess.Where("org_id = ?", query.OrgID)
 		}
+		if query.WithoutData {
+			session = session.Cols("id", "uid", "slug", "org_id", "gnet_id", "version", "plugin_id", "created", "updated",
+				"updated_by", "created_by", "folder_id", "folder_uid", "is_folder", "has_acl", "title")
+		}
 
 		err := session.Find(&dashboards)
 		return err
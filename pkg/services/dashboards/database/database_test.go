@@ -177,6 +177,13 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		queryResult, err = dashboardStore.GetDashboards(context.Background(), &query)
 		require.NoError(t, err)
 		assert.Equal(t, len(queryResult), 2)
+
+		query = dashboards.GetDashboardsQuery{DashboardUIDs: []string{savedDash.UID}, WithoutData: true}
+		queryResult, err = dashboardStore.GetDashboards(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, queryResult, 1)
+		assert.Equal(t, savedDash.Version, queryResult[0].Version)
+		assert.Nil(t, queryResult[0].Data)
 	})
 
 	t.Run("Should be able to delete dashboard", func(t *testing.T) {
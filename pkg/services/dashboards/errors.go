@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 // Typed errors
@@ -91,6 +92,10 @@ var (
 		Reason:     "Dashboard refresh interval is too low",
 		StatusCode: 400,
 	}
+	ErrDashboardSecureOptionInvalid = DashboardErr{
+		Reason:     "Dashboard panel secure options must be strings",
+		StatusCode: 400,
+	}
 	ErrDashboardCannotDeleteProvisionedDashboard = DashboardErr{
 		Reason:     "provisioned dashboard cannot be deleted",
 		StatusCode: 400,
@@ -126,6 +131,30 @@ var (
 	ErrFolderAccessDenied      = errors.New("access denied to folder")
 )
 
+// The codes identifying the dashboard and folder errors in API responses.
+func init() {
+	errutil.RegisterCode("dashboard.not-found", ErrDashboardNotFound, ErrProvisionedDashboardNotFound)
+	errutil.RegisterCode("dashboard.corrupt", ErrDashboardCorrupt)
+	errutil.RegisterCode("dashboard.panel-not-found", ErrDashboardPanelNotFound)
+	errutil.RegisterCode("dashboard.folder-not-found", ErrDashboardFolderNotFound)
+	errutil.RegisterCode("dashboard.uid-exists", ErrDashboardWithSameUIDExists)
+	errutil.RegisterCode("dashboard.name-exists", ErrDashboardWithSameNameInFolderExists, ErrDashboardWithSameNameAsFolder)
+	errutil.RegisterCode("dashboard.version-conflict", ErrDashboardVersionMismatch)
+	errutil.RegisterCode("dashboard.title-empty", ErrDashboardTitleEmpty)
+	errutil.RegisterCode("dashboard.type-mismatch", ErrDashboardTypeMismatch)
+	errutil.RegisterCode("dashboard.access-denied", ErrDashboardUpdateAccessDenied)
+	errutil.RegisterCode("dashboard.invalid-uid", ErrDashboardInvalidUid, ErrDashboardUidTooLong)
+	errutil.RegisterCode("dashboard.provisioned", ErrDashboardCannotSaveProvisionedDashboard, ErrDashboardCannotDeleteProvisionedDashboard)
+	errutil.RegisterCode("dashboard.refresh-interval-too-short", ErrDashboardRefreshIntervalTooShort)
+	errutil.RegisterCode("folder.not-found", ErrFolderNotFound)
+	errutil.RegisterCode("folder.version-conflict", ErrFolderVersionMismatch)
+	errutil.RegisterCode("folder.name-exists", ErrFolderSameNameExists)
+	errutil.RegisterCode("folder.uid-exists", ErrFolderWithSameUIDExists)
+	errutil.RegisterCode("folder.invalid-uid", ErrFolderInvalidUID)
+	errutil.RegisterCode("folder.title-empty", ErrFolderTitleEmpty)
+	errutil.RegisterCode("folder.access-denied", ErrFolderAccessDenied)
+}
+
 // DashboardErr represents a dashboard error.
 type DashboardErr struct {
 	StatusCode int
@@ -152,7 +181,7 @@ func (e DashboardErr) Body() util.DynMap {
 		return nil
 	}
 
-	return util.DynMap{"status": e.Status, "message": e.Error()}
+	return util.DynMap{"status": e.Status, "message": e.Error(), "code": errutil.Code(e.StatusCode, e)}
 }
 
 type UpdatePluginDashboardError struct {
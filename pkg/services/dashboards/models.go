@@ -318,6 +318,8 @@ type GetDashboardsQuery struct {
 	DashboardIDs  []int64
 	DashboardUIDs []string
 	OrgID         int64
+	// WithoutData only loads the metadata of the dashboards, such as their version, without their JSON model.
+	WithoutData bool
 }
 
 type GetDashboardsByPluginIDQuery struct {
@@ -0,0 +1,93 @@
+package dashboards
+
+import (
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// builtInDatasourceTypes are the types of the data source references that are not data sources of
+// the org, such as the Mixed, Dashboard and Grafana data sources or expressions. Their UIDs are
+// well known and never redacted.
+var builtInDatasourceTypes = map[string]bool{
+	"datasource": true,
+	"__expr__":   true,
+}
+
+// RedactFields removes the fields selected by the redaction policy from the dashboard: the UIDs of
+// the data source references, the URLs matching the URL patterns and the descriptions of the
+// dashboard and of its panels matching the description patterns.
+func RedactFields(data *simplejson.Json, policy setting.DashboardRedactionSettings) {
+	if data == nil {
+		return
+	}
+
+	redactDescription(data, policy.DescriptionPatterns)
+	_ = ForEachPanel(data, func(panel *simplejson.Json) error {
+		redactDescription(panel, policy.DescriptionPatterns)
+		return nil
+	})
+
+	if policy.DatasourceUIDs || len(policy.URLPatterns) > 0 {
+		redactValue(data.Interface(), policy)
+	}
+}
+
+func redactDescription(obj *simplejson.Json, patterns []*regexp.Regexp) {
+	if description, ok := obj.CheckGet("description"); ok && matchesAny(patterns, description.MustString()) {
+		obj.Del("description")
+	}
+}
+
+func redactValue(v any, policy setting.DashboardRedactionSettings) {
+	switch value := v.(type) {
+	case []any:
+		for _, item := range value {
+			redactValue(item, policy)
+		}
+	case map[string]any:
+		for k, item := range value {
+			switch k {
+			case "datasource":
+				if policy.DatasourceUIDs {
+					redactDatasourceRef(value)
+					continue
+				}
+			case "url":
+				if url, ok := item.(string); ok {
+					if matchesAny(policy.URLPatterns, url) {
+						delete(value, k)
+					}
+					continue
+				}
+			}
+			redactValue(item, policy)
+		}
+	}
+}
+
+// redactDatasourceRef removes the UID of the datasource reference of obj. Legacy references, the
+// name or the UID of the data source as a string, are removed.
+func redactDatasourceRef(obj map[string]any) {
+	switch ref := obj["datasource"].(type) {
+	case string:
+		delete(obj, "datasource")
+	case map[string]any:
+		if typ, _ := ref["type"].(string); !builtInDatasourceTypes[typ] {
+			delete(ref, "uid")
+		}
+	}
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
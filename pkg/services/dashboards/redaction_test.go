@@ -0,0 +1,98 @@
+package dashboards
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const redactionDashboard = `{
+	"title": "Payments",
+	"description": "Runbook: https://wiki.internal/payments",
+	"links": [
+		{"title": "Runbook", "url": "https://wiki.internal/payments"},
+		{"title": "Status", "url": "https://status.example.com"}
+	],
+	"annotations": {"list": [{"name": "Deploys", "datasource": {"type": "loki", "uid": "loki-prod"}}]},
+	"templating": {"list": [{"name": "env", "type": "query", "datasource": "prometheus-prod"}]},
+	"panels": [
+		{
+			"id": 1,
+			"description": "Latency of the checkout API",
+			"datasource": {"type": "datasource", "uid": "-- Mixed --"},
+			"targets": [
+				{"refId": "A", "datasource": {"type": "prometheus", "uid": "prom-prod"}},
+				{"refId": "B", "datasource": {"type": "__expr__", "uid": "__expr__"}}
+			],
+			"fieldConfig": {"defaults": {"links": [{"title": "Trace", "url": "http://tempo.internal/trace/${__value.raw}"}]}}
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"collapsed": true,
+			"panels": [{"id": 3, "description": "Owner: payments-oncall@team.internal", "datasource": {"type": "loki", "uid": "loki-prod"}}]
+		}
+	]
+}`
+
+func TestRedactFields(t *testing.T) {
+	newData := func(t *testing.T) *simplejson.Json {
+		t.Helper()
+		data, err := simplejson.NewJson([]byte(redactionDashboard))
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("removes the UIDs of the data source references", func(t *testing.T) {
+		data := newData(t)
+		RedactFields(data, setting.DashboardRedactionSettings{Enabled: true, DatasourceUIDs: true})
+
+		panel := data.Get("panels").GetIndex(0)
+		assert.Equal(t, map[string]any{"type": "prometheus"}, panel.Get("targets").GetIndex(0).Get("datasource").MustMap())
+		assert.Equal(t, "__expr__", panel.Get("targets").GetIndex(1).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "-- Mixed --", panel.GetPath("datasource", "uid").MustString())
+		assert.Equal(t, map[string]any{"type": "loki"}, data.Get("panels").GetIndex(1).Get("panels").GetIndex(0).Get("datasource").MustMap())
+		assert.Equal(t, map[string]any{"type": "loki"}, data.Get("annotations").Get("list").GetIndex(0).Get("datasource").MustMap())
+		_, ok := data.Get("templating").Get("list").GetIndex(0).CheckGet("datasource")
+		assert.False(t, ok)
+
+		// other fields are kept
+		assert.Equal(t, "https://wiki.internal/payments", data.Get("links").GetIndex(0).Get("url").MustString())
+		assert.Equal(t, "Latency of the checkout API", panel.Get("description").MustString())
+	})
+
+	t.Run("removes the URLs matching the patterns", func(t *testing.T) {
+		data := newData(t)
+		RedactFields(data, setting.DashboardRedactionSettings{
+			Enabled:     true,
+			URLPatterns: []*regexp.Regexp{regexp.MustCompile(`^https?://[^/]+\.internal(/|$)`)},
+		})
+
+		_, ok := data.Get("links").GetIndex(0).CheckGet("url")
+		assert.False(t, ok)
+		assert.Equal(t, "Runbook", data.Get("links").GetIndex(0).Get("title").MustString())
+		assert.Equal(t, "https://status.example.com", data.Get("links").GetIndex(1).Get("url").MustString())
+		_, ok = data.Get("panels").GetIndex(0).GetPath("fieldConfig", "defaults").Get("links").GetIndex(0).CheckGet("url")
+		assert.False(t, ok)
+		assert.Equal(t, "prom-prod", data.Get("panels").GetIndex(0).Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("removes the descriptions matching the patterns", func(t *testing.T) {
+		data := newData(t)
+		RedactFields(data, setting.DashboardRedactionSettings{
+			Enabled:             true,
+			DescriptionPatterns: []*regexp.Regexp{regexp.MustCompile(`\.internal\b`)},
+		})
+
+		_, ok := data.CheckGet("description")
+		assert.False(t, ok)
+		assert.Equal(t, "Latency of the checkout API", data.Get("panels").GetIndex(0).Get("description").MustString())
+		_, ok = data.Get("panels").GetIndex(1).Get("panels").GetIndex(0).CheckGet("description")
+		assert.False(t, ok)
+	})
+}
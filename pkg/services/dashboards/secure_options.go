@@ -0,0 +1,58 @@
+package dashboards
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	// SecureOptionsKey is the panel key holding the plain text values of the panel options marked as secure.
+	// It is accepted when saving a dashboard and only returned to users allowed to read the dashboard secrets.
+	SecureOptionsKey = "secureOptions"
+	// SecureOptionFieldsKey is the panel key listing which secure options are set, without their values.
+	// When saving a dashboard, a secure option listed here but missing from SecureOptionsKey keeps its saved value.
+	SecureOptionFieldsKey = "secureOptionFields"
+	// EncryptedSecureOptionsKey is the panel key the encrypted secure options are stored under.
+	// It is never returned by the API nor accepted from it.
+	EncryptedSecureOptionsKey = "encryptedSecureOptions"
+)
+
+// ForEachPanel calls fn for every panel of the dashboard, including the panels of collapsed rows.
+func ForEachPanel(data *simplejson.Json, fn func(panel *simplejson.Json) error) error {
+	if data == nil {
+		return nil
+	}
+	for _, p := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		if err := fn(panel); err != nil {
+			return err
+		}
+		for _, nested := range panel.Get("panels").MustArray() {
+			if err := fn(simplejson.NewFromAny(nested)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RedactSecureOptions removes the values of the secure options from the panels of the dashboard,
+// keeping only the names of the options that are set under SecureOptionFieldsKey.
+func RedactSecureOptions(data *simplejson.Json) {
+	_ = ForEachPanel(data, func(panel *simplejson.Json) error {
+		fields := make(map[string]any)
+		for k := range panel.Get(EncryptedSecureOptionsKey).MustMap() {
+			fields[k] = true
+		}
+		for k := range panel.Get(SecureOptionsKey).MustMap() {
+			fields[k] = true
+		}
+		panel.Del(EncryptedSecureOptionsKey)
+		panel.Del(SecureOptionsKey)
+		if len(fields) > 0 {
+			panel.Set(SecureOptionFieldsKey, fields)
+		} else {
+			panel.Del(SecureOptionFieldsKey)
+		}
+		return nil
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/store/entity"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -54,6 +55,7 @@ type DashboardServiceImpl struct {
 	folderPermissions    accesscontrol.FolderPermissionsService
 	dashboardPermissions accesscontrol.DashboardPermissionsService
 	ac                   accesscontrol.AccessControl
+	secretsService       secrets.Service
 	metrics              *dashboardsMetrics
 }
 
@@ -62,7 +64,7 @@ func ProvideDashboardServiceImpl(
 	cfg *setting.Cfg, dashboardStore dashboards.Store, folderStore folder.FolderStore, dashAlertExtractor alerting.DashAlertExtractor,
 	features featuremgmt.FeatureToggles, folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, ac accesscontrol.AccessControl,
-	folderSvc folder.Service, r prometheus.Registerer,
+	folderSvc folder.Service, r prometheus.Registerer, secretsService secrets.Service,
 ) (*DashboardServiceImpl, error) {
 	dashSvc := &DashboardServiceImpl{
 		cfg:                  cfg,
@@ -75,6 +77,7 @@ func ProvideDashboardServiceImpl(
 		ac:                   ac,
 		folderStore:          folderStore,
 		folderService:        folderSvc,
+		secretsService:       secretsService,
 		metrics:              newDashboardsMetrics(r),
 	}
 
@@ -210,6 +213,10 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		}
 	}
 
+	if err := dr.encryptSecureOptions(ctx, dash); err != nil {
+		return nil, err
+	}
+
 	userID, err := resolveUserID(dto.User, dr.log)
 	if err != nil {
 		return nil, err
@@ -494,7 +501,12 @@ func (dr *DashboardServiceImpl) UnprovisionDashboard(ctx context.Context, dashbo
 }
 
 func (dr *DashboardServiceImpl) GetDashboardsByPluginID(ctx context.Context, query *dashboards.GetDashboardsByPluginIDQuery) ([]*dashboards.Dashboard, error) {
-	return dr.dashboardStore.GetDashboardsByPluginID(ctx, query)
+	dashes, err := dr.dashboardStore.GetDashboardsByPluginID(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	redactDashboards(dashes)
+	return dashes, nil
 }
 
 func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *dashboards.SaveDashboardDTO, dash *dashboards.Dashboard, provisioned bool) {
@@ -532,8 +544,14 @@ func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *
 	}
 }
 
+// GetDashboard returns the dashboard with its secure options redacted, see RevealSecureOptions.
 func (dr *DashboardServiceImpl) GetDashboard(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
-	return dr.dashboardStore.GetDashboard(ctx, query)
+	dash, err := dr.dashboardStore.GetDashboard(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	dashboards.RedactSecureOptions(dash.Data)
+	return dash, nil
 }
 
 func (dr *DashboardServiceImpl) GetDashboardUIDByID(ctx context.Context, query *dashboards.GetDashboardRefByIDQuery) (*dashboards.DashboardRef, error) {
@@ -541,7 +559,12 @@ func (dr *DashboardServiceImpl) GetDashboardUIDByID(ctx context.Context, query *
 }
 
 func (dr *DashboardServiceImpl) GetDashboards(ctx context.Context, query *dashboards.GetDashboardsQuery) ([]*dashboards.Dashboard, error) {
-	return dr.dashboardStore.GetDashboards(ctx, query)
+	dashes, err := dr.dashboardStore.GetDashboards(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	redactDashboards(dashes)
+	return dashes, nil
 }
 
 func (dr *DashboardServiceImpl) GetDashboardsSharedWithUser(ctx context.Context, user identity.Requester) ([]*dashboards.Dashboard, error) {
@@ -573,6 +596,7 @@ func (dr *DashboardServiceImpl) getDashboardsSharedWithUser(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	redactDashboards(sharedDashboards)
 	return dr.filterUserSharedDashboards(ctx, user, sharedDashboards)
 }
 
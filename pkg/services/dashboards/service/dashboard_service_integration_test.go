@@ -894,6 +894,7 @@ func permissionScenario(t *testing.T, desc string, canSave bool, fn permissionSc
 			ac,
 			foldertest.NewFakeService(),
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 		guardian.InitAccessControlGuardian(cfg, ac, dashboardService)
@@ -963,6 +964,7 @@ func callSaveWithResult(t *testing.T, cmd dashboards.SaveDashboardCommand, sqlSt
 		actest.FakeAccessControl{},
 		foldertest.NewFakeService(),
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	res, err := service.SaveDashboard(context.Background(), &dto, false)
@@ -987,6 +989,7 @@ func callSaveWithError(t *testing.T, cmd dashboards.SaveDashboardCommand, sqlSto
 		actest.FakeAccessControl{},
 		foldertest.NewFakeService(),
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	_, err = service.SaveDashboard(context.Background(), &dto, false)
@@ -1031,6 +1034,7 @@ func saveTestDashboard(t *testing.T, title string, orgID, folderID int64, folder
 		actest.FakeAccessControl{},
 		foldertest.NewFakeService(),
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	res, err := service.SaveDashboard(context.Background(), &dto, false)
@@ -1082,6 +1086,7 @@ func saveTestFolder(t *testing.T, title string, orgID int64, sqlStore db.DB) *da
 		actest.FakeAccessControl{},
 		foldertest.NewFakeService(),
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	res, err := service.SaveDashboard(context.Background(), &dto, false)
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// encryptSecureOptions encrypts the plain text secure options of the panels of the dashboard with a data key of its org.
+// Secure options that are listed as set but not sent keep the value saved in the current version of the dashboard.
+// Panels that send neither secure options nor the list of the set ones, such as the JSON of a dashboard saved by
+// another service, keep all the secure options saved for their ID.
+func (dr *DashboardServiceImpl) encryptSecureOptions(ctx context.Context, dash *dashboards.Dashboard) error {
+	var saved map[int64]map[string]any
+	savedOptions := func(panelID int64) (map[string]any, error) {
+		if saved == nil {
+			var err error
+			if saved, err = dr.savedSecureOptions(ctx, dash); err != nil {
+				return nil, err
+			}
+		}
+		return saved[panelID], nil
+	}
+	return dashboards.ForEachPanel(dash.Data, func(panel *simplejson.Json) error {
+		_, hasPlain := panel.CheckGet(dashboards.SecureOptionsKey)
+		_, hasFields := panel.CheckGet(dashboards.SecureOptionFieldsKey)
+		plain := panel.Get(dashboards.SecureOptionsKey).MustMap()
+		keep := panel.Get(dashboards.SecureOptionFieldsKey).MustMap()
+		// Encrypted values are never accepted from the request, they could have been copied from another org.
+		panel.Del(dashboards.EncryptedSecureOptionsKey)
+		panel.Del(dashboards.SecureOptionsKey)
+		panel.Del(dashboards.SecureOptionFieldsKey)
+
+		if !hasPlain && !hasFields {
+			keep = make(map[string]any)
+			options, err := savedOptions(panel.Get("id").MustInt64())
+			if err != nil {
+				return err
+			}
+			for k := range options {
+				keep[k] = true
+			}
+		}
+
+		encrypted := make(map[string]any, len(plain)+len(keep))
+		for k, v := range keep {
+			if set, _ := v.(bool); !set {
+				continue
+			}
+			if _, ok := plain[k]; ok {
+				continue
+			}
+			options, err := savedOptions(panel.Get("id").MustInt64())
+			if err != nil {
+				return err
+			}
+			if value, ok := options[k]; ok {
+				encrypted[k] = value
+			}
+		}
+		for k, v := range plain {
+			value, ok := v.(string)
+			if !ok {
+				return dashboards.ErrDashboardSecureOptionInvalid
+			}
+			ciphertext, err := dr.secretsService.Encrypt(ctx, []byte(value), secrets.WithScope(fmt.Sprintf("org:%d", dash.OrgID)))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt secure option %q: %w", k, err)
+			}
+			encrypted[k] = base64.StdEncoding.EncodeToString(ciphertext)
+		}
+		if len(encrypted) > 0 {
+			panel.Set(dashboards.EncryptedSecureOptionsKey, encrypted)
+		}
+		return nil
+	})
+}
+
+// savedSecureOptions returns the encrypted secure options of the saved version of the dashboard by panel ID.
+func (dr *DashboardServiceImpl) savedSecureOptions(ctx context.Context, dash *dashboards.Dashboard) (map[int64]map[string]any, error) {
+	result := make(map[int64]map[string]any)
+	if dash.ID == 0 && dash.UID == "" {
+		return result, nil
+	}
+	existing, err := dr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dash.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return result, nil
+		}
+		return nil, err
+	}
+	err = dashboards.ForEachPanel(existing.Data, func(panel *simplejson.Json) error {
+		if options := panel.Get(dashboards.EncryptedSecureOptionsKey).MustMap(); len(options) > 0 {
+			result[panel.Get("id").MustInt64()] = options
+		}
+		return nil
+	})
+	return result, err
+}
+
+// RevealSecureOptions sets the plain text values of the secure options saved for the panels of the dashboard.
+// The dashboards returned by the service have their secure options redacted, callers must check that the user
+// is allowed to read the dashboard secrets before revealing them.
+func (dr *DashboardServiceImpl) RevealSecureOptions(ctx context.Context, dash *dashboards.Dashboard) error {
+	saved, err := dr.savedSecureOptions(ctx, dash)
+	if err != nil {
+		return err
+	}
+	return dashboards.ForEachPanel(dash.Data, func(panel *simplejson.Json) error {
+		return dr.decryptPanelSecureOptions(ctx, panel, saved[panel.Get("id").MustInt64()])
+	})
+}
+
+// DecryptSecureOptions replaces the encrypted secure options of the panels of the dashboard with their plain text values.
+func (dr *DashboardServiceImpl) DecryptSecureOptions(ctx context.Context, data *simplejson.Json) error {
+	return dashboards.ForEachPanel(data, func(panel *simplejson.Json) error {
+		return dr.decryptPanelSecureOptions(ctx, panel, panel.Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+	})
+}
+
+func (dr *DashboardServiceImpl) decryptPanelSecureOptions(ctx context.Context, panel *simplejson.Json, encrypted map[string]any) error {
+	if len(encrypted) == 0 {
+		return nil
+	}
+	plain := make(map[string]any, len(encrypted))
+	fields := make(map[string]any, len(encrypted))
+	for k, v := range encrypted {
+		value, _ := v.(string)
+		ciphertext, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("failed to decode secure option %q: %w", k, err)
+		}
+		decrypted, err := dr.secretsService.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secure option %q: %w", k, err)
+		}
+		plain[k] = string(decrypted)
+		fields[k] = true
+	}
+	panel.Del(dashboards.EncryptedSecureOptionsKey)
+	panel.Set(dashboards.SecureOptionsKey, plain)
+	panel.Set(dashboards.SecureOptionFieldsKey, fields)
+	return nil
+}
+
+func redactDashboards(dashes []*dashboards.Dashboard) {
+	for _, dash := range dashes {
+		dashboards.RedactSecureOptions(dash.Data)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func TestSecureOptions(t *testing.T) {
+	encoded := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	newDashboard := func(t *testing.T, data string) *dashboards.Dashboard {
+		t.Helper()
+		json, err := simplejson.NewJson([]byte(data))
+		require.NoError(t, err)
+		dash := dashboards.NewDashboardFromJson(json)
+		dash.OrgID = 1
+		return dash
+	}
+
+	t.Run("encrypts the secure options of panels and nested panels", func(t *testing.T) {
+		service := &DashboardServiceImpl{log: log.New("test.logger"), secretsService: fakes.NewFakeSecretsService()}
+		dash := newDashboard(t, `{"title": "test", "panels": [
+			{"id": 1, "secureOptions": {"token": "secret"}},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "secureOptions": {"url": "https://example.com/hook"}}]}
+		]}`)
+
+		require.NoError(t, service.encryptSecureOptions(context.Background(), dash))
+
+		panels := dash.Data.Get("panels")
+		require.Equal(t, map[string]any{"token": encoded("secret")}, panels.GetIndex(0).Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+		_, ok := panels.GetIndex(0).CheckGet(dashboards.SecureOptionsKey)
+		require.False(t, ok)
+		nested := panels.GetIndex(1).Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"url": encoded("https://example.com/hook")}, nested.Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+	})
+
+	t.Run("keeps the saved value of secure options that are set but not sent", func(t *testing.T) {
+		fakeStore := dashboards.FakeDashboardStore{}
+		defer fakeStore.AssertExpectations(t)
+		saved := newDashboard(t, `{"uid": "dash", "panels": [
+			{"id": 1, "encryptedSecureOptions": {"token": "c2F2ZWQ=", "removed": "cmVtb3ZlZA=="}}
+		]}`)
+		fakeStore.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+			return q.UID == "dash" && q.OrgID == 1
+		})).Return(saved, nil).Once()
+
+		service := &DashboardServiceImpl{log: log.New("test.logger"), dashboardStore: &fakeStore, secretsService: fakes.NewFakeSecretsService()}
+		dash := newDashboard(t, `{"uid": "dash", "title": "test", "panels": [
+			{"id": 1, "secureOptionFields": {"token": true}, "secureOptions": {"url": "new"}, "encryptedSecureOptions": {"forged": "Zm9yZ2Vk"}}
+		]}`)
+
+		require.NoError(t, service.encryptSecureOptions(context.Background(), dash))
+
+		panel := dash.Data.Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"token": "c2F2ZWQ=", "url": encoded("new")}, panel.Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+		_, ok := panel.CheckGet(dashboards.SecureOptionFieldsKey)
+		require.False(t, ok)
+	})
+
+	t.Run("keeps the saved secure options of panels saved back from GetDashboard", func(t *testing.T) {
+		stored := `{"uid": "dash", "title": "test", "panels": [
+			{"id": 1, "encryptedSecureOptions": {"token": "` + encoded("secret") + `"}},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "encryptedSecureOptions": {"url": "` + encoded("https://example.com/hook") + `"}}]}
+		]}`
+		fakeStore := dashboards.FakeDashboardStore{}
+		fakeStore.On("GetDashboard", mock.Anything, mock.Anything).Return(func(context.Context, *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+			return newDashboard(t, stored), nil
+		})
+		service := &DashboardServiceImpl{log: log.New("test.logger"), dashboardStore: &fakeStore, secretsService: fakes.NewFakeSecretsService()}
+
+		dash, err := service.GetDashboard(context.Background(), &dashboards.GetDashboardQuery{UID: "dash", OrgID: 1})
+		require.NoError(t, err)
+		panel := dash.Data.Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"token": true}, panel.Get(dashboards.SecureOptionFieldsKey).MustMap())
+		_, ok := panel.CheckGet(dashboards.EncryptedSecureOptionsKey)
+		require.False(t, ok)
+
+		require.NoError(t, service.encryptSecureOptions(context.Background(), dash))
+		panels := dash.Data.Get("panels")
+		require.Equal(t, map[string]any{"token": encoded("secret")}, panels.GetIndex(0).Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+		nested := panels.GetIndex(1).Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"url": encoded("https://example.com/hook")}, nested.Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+
+		// the stored JSON, as saved by services reading the dashboard store, keeps the saved values as well
+		raw := newDashboard(t, stored)
+		raw.Data.Get("panels").GetIndex(0).Set(dashboards.EncryptedSecureOptionsKey, map[string]any{"token": "Zm9yZ2Vk"})
+		require.NoError(t, service.encryptSecureOptions(context.Background(), raw))
+		require.Equal(t, map[string]any{"token": encoded("secret")}, raw.Data.Get("panels").GetIndex(0).Get(dashboards.EncryptedSecureOptionsKey).MustMap())
+	})
+
+	t.Run("removes the secure options that are no longer listed", func(t *testing.T) {
+		fakeStore := dashboards.FakeDashboardStore{}
+		fakeStore.On("GetDashboard", mock.Anything, mock.Anything).Return(newDashboard(t, `{"uid": "dash", "panels": [
+			{"id": 1, "encryptedSecureOptions": {"token": "c2F2ZWQ="}}
+		]}`), nil).Maybe()
+		service := &DashboardServiceImpl{log: log.New("test.logger"), dashboardStore: &fakeStore, secretsService: fakes.NewFakeSecretsService()}
+		dash := newDashboard(t, `{"uid": "dash", "title": "test", "panels": [{"id": 1, "secureOptionFields": {}}]}`)
+
+		require.NoError(t, service.encryptSecureOptions(context.Background(), dash))
+
+		_, ok := dash.Data.Get("panels").GetIndex(0).CheckGet(dashboards.EncryptedSecureOptionsKey)
+		require.False(t, ok)
+	})
+
+	t.Run("reveals the saved secure options of a redacted dashboard", func(t *testing.T) {
+		fakeStore := dashboards.FakeDashboardStore{}
+		fakeStore.On("GetDashboard", mock.Anything, mock.Anything).Return(newDashboard(t, `{"uid": "dash", "panels": [
+			{"id": 1, "encryptedSecureOptions": {"token": "`+encoded("secret")+`"}}
+		]}`), nil).Once()
+		service := &DashboardServiceImpl{log: log.New("test.logger"), dashboardStore: &fakeStore, secretsService: fakes.NewFakeSecretsService()}
+		dash := newDashboard(t, `{"uid": "dash", "title": "test", "panels": [{"id": 1, "secureOptionFields": {"token": true}}, {"id": 2}]}`)
+
+		require.NoError(t, service.RevealSecureOptions(context.Background(), dash))
+
+		panels := dash.Data.Get("panels")
+		require.Equal(t, map[string]any{"token": "secret"}, panels.GetIndex(0).Get(dashboards.SecureOptionsKey).MustMap())
+		_, ok := panels.GetIndex(1).CheckGet(dashboards.SecureOptionsKey)
+		require.False(t, ok)
+	})
+
+	t.Run("rejects secure options that are not strings", func(t *testing.T) {
+		service := &DashboardServiceImpl{log: log.New("test.logger"), secretsService: fakes.NewFakeSecretsService()}
+		dash := newDashboard(t, `{"title": "test", "panels": [{"id": 1, "secureOptions": {"token": 1}}]}`)
+
+		require.ErrorIs(t, service.encryptSecureOptions(context.Background(), dash), dashboards.ErrDashboardSecureOptionInvalid)
+	})
+
+	t.Run("decrypts or redacts the secure options", func(t *testing.T) {
+		service := &DashboardServiceImpl{log: log.New("test.logger"), secretsService: fakes.NewFakeSecretsService()}
+		stored := `{"panels": [{"id": 1, "encryptedSecureOptions": {"token": "` + encoded("secret") + `"}}]}`
+
+		decrypted := newDashboard(t, stored).Data
+		require.NoError(t, service.DecryptSecureOptions(context.Background(), decrypted))
+		panel := decrypted.Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"token": "secret"}, panel.Get(dashboards.SecureOptionsKey).MustMap())
+		require.Equal(t, map[string]any{"token": true}, panel.Get(dashboards.SecureOptionFieldsKey).MustMap())
+		_, ok := panel.CheckGet(dashboards.EncryptedSecureOptionsKey)
+		require.False(t, ok)
+
+		redacted := newDashboard(t, stored).Data
+		dashboards.RedactSecureOptions(redacted)
+		panel = redacted.Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"token": true}, panel.Get(dashboards.SecureOptionFieldsKey).MustMap())
+		_, ok = panel.CheckGet(dashboards.EncryptedSecureOptionsKey)
+		require.False(t, ok)
+		_, ok = panel.CheckGet(dashboards.SecureOptionsKey)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type SchemaAPI struct {
+	schemaService dashboardschema.Service
+	ac            accesscontrol.AccessControl
+}
+
+func New(schemaService dashboardschema.Service, ac accesscontrol.AccessControl) *SchemaAPI {
+	return &SchemaAPI{
+		schemaService: schemaService,
+		ac:            ac,
+	}
+}
+
+func (api *SchemaAPI) RegisterAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(api.ac)
+	routeRegister.Group("/api/dashboards", func(route routing.RouteRegister) {
+		route.Post(
+			"/validate",
+			authorize(accesscontrol.EvalAny(
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate),
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite),
+			)),
+			routing.Wrap(api.ValidateDashboard),
+		)
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/validate dashboards validateDashboard
+//
+// Validate dashboard JSON.
+//
+// Validates a dashboard against the current schema, reports deprecated panel
+// types and returns the dashboard migrated to the latest schemaVersion. The
+// response status is 200 for both valid and invalid dashboards.
+//
+// Responses:
+// 200: validateDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *SchemaAPI) ValidateDashboard(c *contextmodel.ReqContext) response.Response {
+	req := dashboardschema.ValidateRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if req.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "Dashboard must be set", nil)
+	}
+
+	result, err := api.schemaService.Validate(c.Req.Context(), req.Dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to validate dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters validateDashboard
+type ValidateDashboardParams struct {
+	// in:body
+	// required:true
+	Body dashboardschema.ValidateRequest
+}
+
+// swagger:response validateDashboardResponse
+type ValidateDashboardResponse struct {
+	// in: body
+	Body dashboardschema.ValidateResult `json:"body"`
+}
@@ -0,0 +1,53 @@
+package dashboardschema
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// LatestSchemaVersion is the schemaVersion produced by the frontend dashboard
+// migrator. It must be kept in sync with DASHBOARD_SCHEMA_VERSION.
+const LatestSchemaVersion = 39
+
+// ValidateRequest is the payload of the dashboard validation endpoint.
+type ValidateRequest struct {
+	// Dashboard The complete dashboard model.
+	// required: true
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+// Problem describes a single validation error or warning. Path is a JSON
+// pointer into the submitted dashboard.
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// DeprecatedPanel is a panel using a panel type that has a core replacement.
+type DeprecatedPanel struct {
+	Path        string `json:"path"`
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Replacement string `json:"replacement"`
+}
+
+type ValidateResult struct {
+	// Valid is false when at least one error was found.
+	Valid            bool              `json:"valid"`
+	Errors           []Problem         `json:"errors"`
+	Warnings         []Problem         `json:"warnings"`
+	DeprecatedPanels []DeprecatedPanel `json:"deprecatedPanels"`
+	// SchemaVersion is the schemaVersion of the submitted dashboard.
+	SchemaVersion int `json:"schemaVersion"`
+	// MigratedSchemaVersion is the schemaVersion of the returned dashboard.
+	MigratedSchemaVersion int `json:"migratedSchemaVersion"`
+	// Dashboard is the submitted dashboard migrated to MigratedSchemaVersion.
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+// Service validates dashboard JSON and migrates it to the latest schema.
+type Service interface {
+	Validate(ctx context.Context, dashboard *simplejson.Json) (*ValidateResult, error)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	kdashboard "github.com/grafana/grafana/pkg/kinds/dashboard"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+)
+
+// migration upgrades a dashboard from schemaVersion-1 to the schemaVersion it
+// is registered for. Migrations mirror the frontend DashboardMigrator.
+type migration func(dash *simplejson.Json)
+
+// minMigratableVersion is the oldest schemaVersion the backend can migrate.
+// Older dashboards rely on frontend-only migrations that need datasource
+// instance settings and panel plugin metadata.
+const minMigratableVersion = kdashboard.HandoffSchemaVersion
+
+var migrations = map[int]migration{
+	37: forEachPanel(migrateLegendVisibility),
+	38: forEachPanel(migrateTableCellOptions),
+	39: forEachPanel(migrateTimeSeriesTableTransform),
+}
+
+// migrate upgrades dash in place and returns the resulting schemaVersion.
+func migrate(dash *simplejson.Json, from int) int {
+	if from < minMigratableVersion {
+		return from
+	}
+	version := from
+	for version < dashboardschema.LatestSchemaVersion {
+		version++
+		if m, ok := migrations[version]; ok {
+			m(dash)
+		}
+	}
+	dash.Set("schemaVersion", version)
+	return version
+}
+
+func forEachPanel(fn func(panel *simplejson.Json)) migration {
+	return func(dash *simplejson.Json) {
+		for _, panel := range panels(dash) {
+			fn(panel)
+		}
+	}
+}
+
+// panels returns the top level panels and the panels of collapsed rows.
+func panels(dash *simplejson.Json) []*simplejson.Json {
+	out := []*simplejson.Json{}
+	for _, p := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		out = append(out, panel)
+		for _, nested := range panel.Get("panels").MustArray() {
+			out = append(out, simplejson.NewFromAny(nested))
+		}
+	}
+	return out
+}
+
+// migrateLegendVisibility normalizes the two ways of hiding a legend to
+// legend.showLegend.
+func migrateLegendVisibility(panel *simplejson.Json) {
+	legend, ok := panel.Get("options").CheckGet("legend")
+	if !ok || legend.Interface() == nil {
+		return
+	}
+	if legend.Get("displayMode").MustString() == "hidden" || !legend.Get("showLegend").MustBool(true) {
+		legend.Set("displayMode", "list")
+		legend.Set("showLegend", false)
+		return
+	}
+	legend.Set("showLegend", true)
+}
+
+// migrateTableCellOptions replaces custom.displayMode of table panels with
+// custom.cellOptions.
+func migrateTableCellOptions(panel *simplejson.Json) {
+	if panel.Get("type").MustString() != "table" {
+		return
+	}
+	fieldConfig, ok := panel.CheckGet("fieldConfig")
+	if !ok {
+		return
+	}
+
+	custom := fieldConfig.GetPath("defaults", "custom")
+	if displayMode, ok := custom.CheckGet("displayMode"); ok {
+		custom.Set("cellOptions", tableCellOptions(displayMode.MustString()))
+		custom.Del("displayMode")
+	}
+
+	for _, o := range fieldConfig.Get("overrides").MustArray() {
+		for _, p := range simplejson.NewFromAny(o).Get("properties").MustArray() {
+			prop := simplejson.NewFromAny(p)
+			if prop.Get("id").MustString() != "custom.displayMode" {
+				continue
+			}
+			prop.Set("id", "custom.cellOptions")
+			prop.Set("value", tableCellOptions(prop.Get("value").MustString()))
+		}
+	}
+}
+
+func tableCellOptions(displayMode string) map[string]any {
+	switch displayMode {
+	case "basic", "gradient-gauge", "lcd-gauge":
+		mode := "basic"
+		if displayMode == "gradient-gauge" {
+			mode = "gradient"
+		} else if displayMode == "lcd-gauge" {
+			mode = "lcd"
+		}
+		return map[string]any{"type": "gauge", "mode": mode}
+	case "color-background", "color-background-solid":
+		mode := "basic"
+		if displayMode == "color-background" {
+			mode = "gradient"
+		}
+		return map[string]any{"type": "color-background", "mode": mode}
+	default:
+		return map[string]any{"type": displayMode}
+	}
+}
+
+// migrateTimeSeriesTableTransform moves the refIdToStat option of the
+// timeSeriesTable transformation to per refId settings.
+func migrateTimeSeriesTableTransform(panel *simplejson.Json) {
+	for _, t := range panel.Get("transformations").MustArray() {
+		transformation := simplejson.NewFromAny(t)
+		if transformation.Get("id").MustString() != "timeSeriesTable" {
+			continue
+		}
+		refIDToStat, ok := transformation.Get("options").CheckGet("refIdToStat")
+		if !ok {
+			continue
+		}
+		options := map[string]any{}
+		for refID, stat := range refIDToStat.MustMap() {
+			options[refID] = map[string]any{"stat": stat}
+		}
+		transformation.Set("options", options)
+	}
+}
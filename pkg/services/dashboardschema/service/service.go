@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+	"github.com/grafana/grafana/pkg/services/dashboardschema/api"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// deprecatedPanelTypes maps deprecated panel plugins to their core replacement.
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+const gridColumnCount = 24
+
+func ProvideService(routeRegister routing.RouteRegister, ac accesscontrol.AccessControl) *SchemaService {
+	s := &SchemaService{}
+
+	schemaAPI := api.New(s, ac)
+	schemaAPI.RegisterAPIEndpoints(routeRegister)
+
+	return s
+}
+
+type SchemaService struct{}
+
+var _ dashboardschema.Service = (*SchemaService)(nil)
+
+// Validate checks the dashboard and returns a migrated copy of it. The
+// submitted dashboard is not modified.
+func (s *SchemaService) Validate(ctx context.Context, dashboard *simplejson.Json) (*dashboardschema.ValidateResult, error) {
+	raw, err := dashboard.Encode()
+	if err != nil {
+		return nil, err
+	}
+	dash, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{
+		result: &dashboardschema.ValidateResult{
+			Errors:           []dashboardschema.Problem{},
+			Warnings:         []dashboardschema.Problem{},
+			DeprecatedPanels: []dashboardschema.DeprecatedPanel{},
+		},
+	}
+	if _, err := dash.Map(); err != nil {
+		v.error("", "dashboard must be a JSON object")
+		v.result.Valid = false
+		return v.result, nil
+	}
+
+	version, err := dash.Get("schemaVersion").Int()
+	switch {
+	case err != nil:
+		v.error("/schemaVersion", "schemaVersion is required and must be a number")
+	case version > dashboardschema.LatestSchemaVersion:
+		v.error("/schemaVersion", fmt.Sprintf("schemaVersion %d is newer than the latest supported version %d", version, dashboardschema.LatestSchemaVersion))
+	case version < minMigratableVersion:
+		v.warning("/schemaVersion", fmt.Sprintf("schemaVersion %d is older than %d and can only be migrated by the frontend", version, minMigratableVersion))
+	}
+	v.result.SchemaVersion = version
+
+	v.validateDashboard(dash)
+
+	v.result.MigratedSchemaVersion = version
+	if err == nil && version <= dashboardschema.LatestSchemaVersion {
+		v.result.MigratedSchemaVersion = migrate(dash, version)
+	}
+	v.result.Dashboard = dash
+	v.result.Valid = len(v.result.Errors) == 0
+	return v.result, nil
+}
+
+type validator struct {
+	result *dashboardschema.ValidateResult
+}
+
+func (v *validator) error(path, msg string) {
+	v.result.Errors = append(v.result.Errors, dashboardschema.Problem{Path: path, Message: msg})
+}
+
+func (v *validator) warning(path, msg string) {
+	v.result.Warnings = append(v.result.Warnings, dashboardschema.Problem{Path: path, Message: msg})
+}
+
+func (v *validator) validateDashboard(dash *simplejson.Json) {
+	if title, err := dash.Get("title").String(); err != nil || title == "" {
+		v.error("/title", "title is required")
+	}
+
+	if uidJSON, ok := dash.CheckGet("uid"); ok && uidJSON.Interface() != nil {
+		uid, err := uidJSON.String()
+		switch {
+		case err != nil:
+			v.error("/uid", "uid must be a string")
+		case util.IsShortUIDTooLong(uid):
+			v.error("/uid", fmt.Sprintf("uid is longer than %d characters", util.MaxUIDLength))
+		case uid != "" && !util.IsValidShortUID(uid):
+			v.error("/uid", "uid contains illegal characters")
+		}
+	}
+
+	if tags, ok := dash.CheckGet("tags"); ok {
+		if _, err := tags.StringArray(); err != nil {
+			v.error("/tags", "tags must be a list of strings")
+		}
+	}
+
+	if _, ok := dash.CheckGet("rows"); ok {
+		v.warning("/rows", "rows are deprecated, panels should be listed in panels")
+	}
+
+	v.validateTemplating(dash)
+
+	ids := map[int64]string{}
+	for i, p := range dash.Get("panels").MustArray() {
+		path := fmt.Sprintf("/panels/%d", i)
+		panel := simplejson.NewFromAny(p)
+		v.validatePanel(path, panel, ids)
+		for j, nested := range panel.Get("panels").MustArray() {
+			v.validatePanel(fmt.Sprintf("%s/panels/%d", path, j), simplejson.NewFromAny(nested), ids)
+		}
+	}
+}
+
+func (v *validator) validateTemplating(dash *simplejson.Json) {
+	names := map[string]bool{}
+	for i, item := range dash.GetPath("templating", "list").MustArray() {
+		path := fmt.Sprintf("/templating/list/%d", i)
+		variable := simplejson.NewFromAny(item)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			v.error(path+"/name", "variable name is required")
+			continue
+		}
+		if variable.Get("type").MustString() == "" {
+			v.error(path+"/type", fmt.Sprintf("variable %q has no type", name))
+		}
+		if names[name] {
+			v.error(path+"/name", fmt.Sprintf("variable %q is defined more than once", name))
+		}
+		names[name] = true
+	}
+}
+
+func (v *validator) validatePanel(path string, panel *simplejson.Json, ids map[int64]string) {
+	if _, err := panel.Map(); err != nil {
+		v.error(path, "panel must be a JSON object")
+		return
+	}
+
+	panelType := panel.Get("type").MustString()
+	if panelType == "" {
+		v.error(path+"/type", "panel type is required")
+	}
+	if replacement, ok := deprecatedPanelTypes[panelType]; ok {
+		v.result.DeprecatedPanels = append(v.result.DeprecatedPanels, dashboardschema.DeprecatedPanel{
+			Path:        path,
+			ID:          panel.Get("id").MustInt64(),
+			Title:       panel.Get("title").MustString(),
+			Type:        panelType,
+			Replacement: replacement,
+		})
+	}
+
+	if id, err := panel.Get("id").Int64(); err != nil {
+		v.warning(path+"/id", "panel id is missing")
+	} else if other, ok := ids[id]; ok {
+		v.error(path+"/id", fmt.Sprintf("panel id %d is already used by %s", id, other))
+	} else {
+		ids[id] = path
+	}
+
+	gridPos, ok := panel.CheckGet("gridPos")
+	if !ok {
+		v.error(path+"/gridPos", "gridPos is required")
+		return
+	}
+	for _, key := range []string{"h", "w", "x", "y"} {
+		n, err := gridPos.Get(key).Int()
+		if err != nil || n < 0 {
+			v.error(path+"/gridPos/"+key, fmt.Sprintf("gridPos.%s must be a non-negative number", key))
+		}
+	}
+	if gridPos.Get("x").MustInt()+gridPos.Get("w").MustInt() > gridColumnCount {
+		v.error(path+"/gridPos", fmt.Sprintf("panel is wider than the %d grid columns", gridColumnCount))
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+)
+
+func TestValidate(t *testing.T) {
+	s := &SchemaService{}
+
+	t.Run("valid dashboard is migrated to the latest schema version", func(t *testing.T) {
+		dash := simplejson.MustJson([]byte(`{
+			"title": "CI",
+			"uid": "ci-dash",
+			"schemaVersion": 36,
+			"panels": [
+				{
+					"id": 1, "type": "timeseries", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+					"options": {"legend": {"displayMode": "hidden"}}
+				},
+				{
+					"id": 2, "type": "row", "collapsed": true, "gridPos": {"h": 1, "w": 24, "x": 0, "y": 8},
+					"panels": [
+						{
+							"id": 3, "type": "table", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 9},
+							"fieldConfig": {
+								"defaults": {"custom": {"displayMode": "lcd-gauge"}},
+								"overrides": [{"properties": [{"id": "custom.displayMode", "value": "color-background"}]}]
+							},
+							"transformations": [{"id": "timeSeriesTable", "options": {"refIdToStat": {"A": "mean"}}}]
+						}
+					]
+				}
+			]
+		}`))
+
+		res, err := s.Validate(context.Background(), dash)
+		require.NoError(t, err)
+		require.True(t, res.Valid)
+		require.Empty(t, res.Errors)
+		require.Equal(t, 36, res.SchemaVersion)
+		require.Equal(t, dashboardschema.LatestSchemaVersion, res.MigratedSchemaVersion)
+
+		migrated := res.Dashboard
+		require.Equal(t, dashboardschema.LatestSchemaVersion, migrated.Get("schemaVersion").MustInt())
+		legend := migrated.Get("panels").GetIndex(0).GetPath("options", "legend")
+		require.False(t, legend.Get("showLegend").MustBool(true))
+		require.Equal(t, "list", legend.Get("displayMode").MustString())
+
+		table := migrated.Get("panels").GetIndex(1).Get("panels").GetIndex(0)
+		require.Equal(t, map[string]any{"type": "gauge", "mode": "lcd"}, table.GetPath("fieldConfig", "defaults", "custom", "cellOptions").Interface())
+		prop := table.Get("fieldConfig").Get("overrides").GetIndex(0).Get("properties").GetIndex(0)
+		require.Equal(t, "custom.cellOptions", prop.Get("id").MustString())
+		require.Equal(t, map[string]any{"stat": "mean"}, table.Get("transformations").GetIndex(0).GetPath("options", "A").Interface())
+
+		// the submitted dashboard is left untouched
+		require.Equal(t, 36, dash.Get("schemaVersion").MustInt())
+	})
+
+	t.Run("reports errors and deprecated panels", func(t *testing.T) {
+		dash := simplejson.MustJson([]byte(`{
+			"uid": "not/valid",
+			"schemaVersion": 30,
+			"templating": {"list": [{"name": "a", "type": "custom"}, {"name": "a", "type": "custom"}, {"type": "query"}]},
+			"panels": [
+				{"id": 1, "type": "graph", "title": "Old", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0}},
+				{"id": 1, "type": "singlestat", "gridPos": {"h": 8, "w": 12, "x": 18, "y": 0}},
+				{"type": "", "gridPos": {"h": 8, "w": 12, "x": 0}}
+			]
+		}`))
+
+		res, err := s.Validate(context.Background(), dash)
+		require.NoError(t, err)
+		require.False(t, res.Valid)
+		require.Equal(t, 30, res.MigratedSchemaVersion)
+
+		paths := []string{}
+		for _, p := range res.Errors {
+			paths = append(paths, p.Path)
+		}
+		require.ElementsMatch(t, []string{
+			"/title",
+			"/uid",
+			"/templating/list/1/name",
+			"/templating/list/2/name",
+			"/panels/1/id",
+			"/panels/1/gridPos",
+			"/panels/2/type",
+			"/panels/2/gridPos/y",
+		}, paths)
+
+		require.Equal(t, []dashboardschema.DeprecatedPanel{
+			{Path: "/panels/0", ID: 1, Title: "Old", Type: "graph", Replacement: "timeseries"},
+			{Path: "/panels/1", ID: 1, Type: "singlestat", Replacement: "stat"},
+		}, res.DeprecatedPanels)
+		require.Len(t, res.Warnings, 2)
+	})
+
+	t.Run("non object dashboard is invalid", func(t *testing.T) {
+		res, err := s.Validate(context.Background(), simplejson.NewFromAny([]any{"a"}))
+		require.NoError(t, err)
+		require.False(t, res.Valid)
+	})
+}
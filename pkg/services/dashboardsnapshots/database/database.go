@@ -124,6 +124,15 @@ func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, q
 		if query.Name != "" {
 			sess.Where("name LIKE ?", query.Name)
 		}
+		if query.CreatedBy != 0 {
+			sess.Where("user_id = ?", query.CreatedBy)
+		}
+		if query.External != nil {
+			sess.Where("external = ?", *query.External)
+		}
+		if !query.CreatedBefore.IsZero() {
+			sess.Where("created < ?", query.CreatedBefore)
+		}
 
 		namespace, id := query.SignedInUser.GetNamespacedID()
 		var userID int64
@@ -156,3 +165,49 @@ func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, q
 	}
 	return queryResult, nil
 }
+
+// DeleteDashboardSnapshots deletes the snapshots of the org with the delete keys of the command.
+func (d *DashboardSnapshotStore) DeleteDashboardSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotsCommand) error {
+	if len(cmd.DeleteKeys) == 0 {
+		return nil
+	}
+	return d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ?", cmd.OrgID).In("delete_key", cmd.DeleteKeys).Delete(&dashboardsnapshots.DashboardSnapshot{})
+		cmd.DeletedRows = deleted
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) GetExpiringDashboardSnapshots(ctx context.Context, query *dashboardsnapshots.GetExpiringDashboardSnapshotsQuery) ([]*dashboardsnapshots.DashboardSnapshotDTO, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshotDTO, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard_snapshot").
+			Where("expires > ? AND expires < ?", time.Now(), query.ExpiresBefore).
+			Where("external = ? AND expiry_reminder_sent = ? AND user_id > 0", false, false).
+			Asc("expires")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+func (d *DashboardSnapshotStore) SetExpiryReminderSent(ctx context.Context, cmd *dashboardsnapshots.SetExpiryReminderSentCommand) error {
+	if len(cmd.IDs) == 0 {
+		return nil
+	}
+	return d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table("dashboard_snapshot").In("id", cmd.IDs).Cols("expiry_reminder_sent").
+			Update(map[string]any{"expiry_reminder_sent": true})
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) CountDashboardSnapshotsByOrg(ctx context.Context) ([]*dashboardsnapshots.OrgSnapshotCount, error) {
+	counts := make([]*dashboardsnapshots.OrgSnapshotCount, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT org_id, COUNT(*) AS count FROM dashboard_snapshot GROUP BY org_id").Find(&counts)
+	})
+	return counts, err
+}
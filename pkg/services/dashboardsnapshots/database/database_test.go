@@ -191,6 +191,75 @@ func TestIntegrationDeleteExpiredSnapshots(t *testing.T) {
 	})
 }
 
+func TestIntegrationDashboardSnapshotsManagement(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	dashStore := ProvideStore(sqlstore, setting.NewCfg())
+	ctx := context.Background()
+	admin := &user.SignedInUser{OrgRole: org.RoleAdmin, UserID: 1, OrgID: 1}
+
+	expiringSnapshot := createTestSnapshot(t, dashStore, "key1", 3600)
+	createTestSnapshot(t, dashStore, "key2", 0)
+
+	t.Run("Should filter the snapshots", func(t *testing.T) {
+		external, local := true, false
+		for _, tc := range []struct {
+			query    dashboardsnapshots.GetDashboardSnapshotsQuery
+			expected int
+		}{
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{CreatedBy: 1000}, expected: 2},
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{CreatedBy: 1}, expected: 0},
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{External: &local}, expected: 2},
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{External: &external}, expected: 0},
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{CreatedBefore: time.Now().Add(time.Hour)}, expected: 2},
+			{query: dashboardsnapshots.GetDashboardSnapshotsQuery{CreatedBefore: time.Now().Add(-time.Hour)}, expected: 0},
+		} {
+			tc.query.OrgID, tc.query.SignedInUser = 1, admin
+			queryResult, err := dashStore.SearchDashboardSnapshots(ctx, &tc.query)
+			require.NoError(t, err)
+			assert.Len(t, queryResult, tc.expected, "query %+v", tc.query)
+		}
+	})
+
+	t.Run("Should count the snapshots per org", func(t *testing.T) {
+		counts, err := dashStore.CountDashboardSnapshotsByOrg(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []*dashboardsnapshots.OrgSnapshotCount{{OrgID: 1, Count: 2}}, counts)
+	})
+
+	t.Run("Should return the expiring snapshots until a reminder is sent", func(t *testing.T) {
+		query := &dashboardsnapshots.GetExpiringDashboardSnapshotsQuery{ExpiresBefore: time.Now().Add(24 * time.Hour)}
+		expiring, err := dashStore.GetExpiringDashboardSnapshots(ctx, query)
+		require.NoError(t, err)
+		require.Len(t, expiring, 1)
+		require.Equal(t, expiringSnapshot.Key, expiring[0].Key)
+
+		err = dashStore.SetExpiryReminderSent(ctx, &dashboardsnapshots.SetExpiryReminderSentCommand{IDs: []int64{expiring[0].ID}})
+		require.NoError(t, err)
+
+		expiring, err = dashStore.GetExpiringDashboardSnapshots(ctx, query)
+		require.NoError(t, err)
+		require.Empty(t, expiring)
+	})
+
+	t.Run("Should only delete the snapshots of the org", func(t *testing.T) {
+		cmd := &dashboardsnapshots.DeleteDashboardSnapshotsCommand{OrgID: 2, DeleteKeys: []string{"deletekey1", "deletekey2"}}
+		require.NoError(t, dashStore.DeleteDashboardSnapshots(ctx, cmd))
+		require.Zero(t, cmd.DeletedRows)
+
+		cmd = &dashboardsnapshots.DeleteDashboardSnapshotsCommand{OrgID: 1, DeleteKeys: []string{"deletekey1", "deletekey3"}}
+		require.NoError(t, dashStore.DeleteDashboardSnapshots(ctx, cmd))
+		require.Equal(t, int64(1), cmd.DeletedRows)
+
+		queryResult, err := dashStore.SearchDashboardSnapshots(ctx, &dashboardsnapshots.GetDashboardSnapshotsQuery{OrgID: 1, SignedInUser: admin})
+		require.NoError(t, err)
+		require.Len(t, queryResult, 1)
+		require.Equal(t, "key2", queryResult[0].Key)
+	})
+}
+
 func createTestSnapshot(t *testing.T, dashStore *DashboardSnapshotStore, key string, expires int64) *dashboardsnapshots.DashboardSnapshot {
 	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
 		Key:       key,
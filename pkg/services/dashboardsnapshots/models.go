@@ -25,6 +25,9 @@ type DashboardSnapshot struct {
 
 	Dashboard          *simplejson.Json
 	DashboardEncrypted []byte
+
+	// ExpiryReminderSent is true once the creator of the snapshot has been reminded of its expiry.
+	ExpiryReminderSent bool
 }
 
 // DashboardSnapshotDTO without dashboard map
@@ -87,6 +90,35 @@ type DeleteExpiredSnapshotsCommand struct {
 	DeletedRows int64
 }
 
+// DeleteDashboardSnapshotsCommand deletes the snapshots of an org with the delete keys.
+type DeleteDashboardSnapshotsCommand struct {
+	OrgID       int64
+	DeleteKeys  []string
+	DeletedRows int64
+}
+
+// GetExpiringDashboardSnapshotsQuery returns the local snapshots created by a user that expire before
+// ExpiresBefore and whose creator has not been reminded of their expiry yet.
+type GetExpiringDashboardSnapshotsQuery struct {
+	ExpiresBefore time.Time
+	Limit         int
+}
+
+type SetExpiryReminderSentCommand struct {
+	IDs []int64
+}
+
+// NotifyExpiringSnapshotsCommand emails the creators of the snapshots expiring soon.
+type NotifyExpiringSnapshotsCommand struct {
+	RemindersSent int64
+}
+
+// OrgSnapshotCount is the number of snapshots of an org.
+type OrgSnapshotCount struct {
+	OrgID int64 `xorm:"org_id"`
+	Count int64 `xorm:"count"`
+}
+
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
@@ -95,8 +127,14 @@ type GetDashboardSnapshotQuery struct {
 type DashboardSnapshotsList []*DashboardSnapshotDTO
 
 type GetDashboardSnapshotsQuery struct {
-	Name         string
-	Limit        int
-	OrgID        int64
-	SignedInUser identity.Requester
+	Name  string
+	Limit int
+	OrgID int64
+	// CreatedBy only returns the snapshots created by the user with this id if set.
+	CreatedBy int64
+	// External only returns the external snapshots if true, or the local snapshots if false.
+	External *bool
+	// CreatedBefore only returns the snapshots created before this time if set.
+	CreatedBefore time.Time
+	SignedInUser  identity.Requester
 }
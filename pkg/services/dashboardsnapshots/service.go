@@ -11,4 +11,7 @@ type Service interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	DeleteDashboardSnapshots(context.Context, *DeleteDashboardSnapshotsCommand) error
+	NotifyExpiringSnapshots(context.Context, *NotifyExpiringSnapshotsCommand) error
+	UpdateSnapshotMetrics(context.Context) error
 }
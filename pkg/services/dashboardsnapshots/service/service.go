@@ -2,24 +2,57 @@ package service
 
 import (
 	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
+// expiryReminderBatchSize is the maximum number of expiry reminders sent by a run of NotifyExpiringSnapshots.
+const expiryReminderBatchSize = 100
+
+const tmplSnapshotExpiryReminder = "snapshot_expiry_reminder"
+
 type ServiceImpl struct {
 	store          dashboardsnapshots.Store
 	secretsService secrets.Service
+	userService    user.Service
+	emailSender    notifications.EmailSender
+	cfg            *setting.Cfg
+	log            log.Logger
+
+	snapshotsGauge *prometheus.GaugeVec
 }
 
 // ServiceImpl implements the dashboardsnapshots Service interface
 var _ dashboardsnapshots.Service = (*ServiceImpl)(nil)
 
-func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service) *ServiceImpl {
+func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service, userService user.Service,
+	emailSender notifications.EmailSender, cfg *setting.Cfg, reg prometheus.Registerer) *ServiceImpl {
 	s := &ServiceImpl{
 		store:          store,
 		secretsService: secretsService,
+		userService:    userService,
+		emailSender:    emailSender,
+		cfg:            cfg,
+		log:            log.New("dashboard-snapshots"),
+		snapshotsGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Name:      "dashboard_snapshots",
+			Help:      "Number of dashboard snapshots per organization",
+		}, []string{"org_id"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(s.snapshotsGauge)
 	}
 
 	return s
@@ -75,3 +108,77 @@ func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashb
 func (s *ServiceImpl) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
 	return s.store.DeleteExpiredSnapshots(ctx, cmd)
 }
+
+func (s *ServiceImpl) DeleteDashboardSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotsCommand) error {
+	return s.store.DeleteDashboardSnapshots(ctx, cmd)
+}
+
+// NotifyExpiringSnapshots emails the creators of the local snapshots that expire within the expiry reminder
+// period. Each creator is reminded once per snapshot. It does nothing if expiry reminders are disabled.
+func (s *ServiceImpl) NotifyExpiringSnapshots(ctx context.Context, cmd *dashboardsnapshots.NotifyExpiringSnapshotsCommand) error {
+	if s.cfg.SnapshotExpiryReminder <= 0 || !s.cfg.Smtp.Enabled {
+		return nil
+	}
+
+	snapshots, err := s.store.GetExpiringDashboardSnapshots(ctx, &dashboardsnapshots.GetExpiringDashboardSnapshotsQuery{
+		ExpiresBefore: time.Now().Add(s.cfg.SnapshotExpiryReminder),
+		Limit:         expiryReminderBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	reminded := make([]int64, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: snapshot.UserID})
+		if err != nil {
+			// The creator may have been deleted since, so the snapshot is skipped and not checked again.
+			s.log.Warn("Failed to get the creator of an expiring snapshot", "snapshot", snapshot.Key, "user", snapshot.UserID, "error", err)
+			reminded = append(reminded, snapshot.ID)
+			continue
+		}
+		if usr.Email == "" {
+			reminded = append(reminded, snapshot.ID)
+			continue
+		}
+
+		name := usr.Name
+		if name == "" {
+			name = usr.Login
+		}
+		err = s.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+			To:       []string{usr.Email},
+			Template: tmplSnapshotExpiryReminder,
+			OrgID:    snapshot.OrgID,
+			UserID:   snapshot.UserID,
+			Data: map[string]any{
+				"Name":         name,
+				"SnapshotName": snapshot.Name,
+				"SnapshotUrl":  s.cfg.AppURL + "dashboard/snapshot/" + snapshot.Key,
+				"Expires":      snapshot.Expires.UTC().Format(time.RFC1123),
+			},
+		})
+		if err != nil {
+			s.log.Error("Failed to send snapshot expiry reminder", "snapshot", snapshot.Key, "user", snapshot.UserID, "error", err)
+			continue
+		}
+		reminded = append(reminded, snapshot.ID)
+		cmd.RemindersSent++
+	}
+
+	return s.store.SetExpiryReminderSent(ctx, &dashboardsnapshots.SetExpiryReminderSentCommand{IDs: reminded})
+}
+
+// UpdateSnapshotMetrics updates the number of snapshots per organization.
+func (s *ServiceImpl) UpdateSnapshotMetrics(ctx context.Context) error {
+	counts, err := s.store.CountDashboardSnapshotsByOrg(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.snapshotsGauge.Reset()
+	for _, c := range counts {
+		s.snapshotsGauge.WithLabelValues(strconv.FormatInt(c.OrgID, 10)).Set(float64(c.Count))
+	}
+	return nil
+}
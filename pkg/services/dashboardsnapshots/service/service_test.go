@@ -3,15 +3,21 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -19,7 +25,7 @@ func TestDashboardSnapshotsService(t *testing.T) {
 	sqlStore := db.InitTestDB(t)
 	dsStore := dashsnapdb.ProvideStore(sqlStore, setting.NewCfg())
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(dsStore, secretsService)
+	s := ProvideService(dsStore, secretsService, usertest.NewUserServiceFake(), &notifications.NotificationServiceMock{}, setting.NewCfg(), nil)
 
 	origSecret := setting.SecretKey
 	setting.SecretKey = "dashboard_snapshot_service_test"
@@ -68,3 +74,57 @@ func TestDashboardSnapshotsService(t *testing.T) {
 		require.Equal(t, rawDashboard, decrypted)
 	})
 }
+
+func TestDashboardSnapshotsExpiryReminders(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	dsStore := dashsnapdb.ProvideStore(sqlStore, setting.NewCfg())
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	userService := &usertest.FakeUserService{ExpectedUser: &user.User{ID: 10, Email: "creator@example.com", Login: "creator"}}
+	emailSender := &notifications.NotificationServiceMock{}
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	reg := prometheus.NewPedanticRegistry()
+	s := ProvideService(dsStore, secretsService, userService, emailSender, cfg, reg)
+	ctx := context.Background()
+
+	for key, expires := range map[string]int64{"expiring": 3600, "later": 7 * 24 * 3600} {
+		_, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Name:      key,
+			Key:       key,
+			DeleteKey: "delete-" + key,
+			Dashboard: simplejson.New(),
+			Expires:   expires,
+			OrgID:     1,
+			UserID:    10,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("should not send reminders if they are disabled", func(t *testing.T) {
+		cmd := &dashboardsnapshots.NotifyExpiringSnapshotsCommand{}
+		require.NoError(t, s.NotifyExpiringSnapshots(ctx, cmd))
+		require.Zero(t, cmd.RemindersSent)
+	})
+
+	cfg.SnapshotExpiryReminder = 24 * time.Hour
+	cfg.Smtp.Enabled = true
+
+	t.Run("should remind the creator of the snapshots expiring soon once", func(t *testing.T) {
+		cmd := &dashboardsnapshots.NotifyExpiringSnapshotsCommand{}
+		require.NoError(t, s.NotifyExpiringSnapshots(ctx, cmd))
+		require.Equal(t, int64(1), cmd.RemindersSent)
+		require.Equal(t, []string{"creator@example.com"}, emailSender.Email.To)
+		require.Equal(t, "snapshot_expiry_reminder", emailSender.Email.Template)
+		require.Equal(t, "expiring", emailSender.Email.Data["SnapshotName"])
+		require.Equal(t, "http://localhost:3000/dashboard/snapshot/expiring", emailSender.Email.Data["SnapshotUrl"])
+
+		cmd = &dashboardsnapshots.NotifyExpiringSnapshotsCommand{}
+		require.NoError(t, s.NotifyExpiringSnapshots(ctx, cmd))
+		require.Zero(t, cmd.RemindersSent)
+	})
+
+	t.Run("should count the snapshots per org", func(t *testing.T) {
+		require.NoError(t, s.UpdateSnapshotMetrics(ctx))
+		require.Equal(t, 2.0, testutil.ToFloat64(s.snapshotsGauge.WithLabelValues("1")))
+	})
+}
@@ -10,4 +10,8 @@ type Store interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	DeleteDashboardSnapshots(context.Context, *DeleteDashboardSnapshotsCommand) error
+	GetExpiringDashboardSnapshots(context.Context, *GetExpiringDashboardSnapshotsQuery) ([]*DashboardSnapshotDTO, error)
+	SetExpiryReminderSent(context.Context, *SetExpiryReminderSentCommand) error
+	CountDashboardSnapshotsByOrg(context.Context) ([]*OrgSnapshotCount, error)
 }
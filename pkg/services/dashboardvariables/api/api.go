@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type VariablesAPI struct {
+	variableService  dashboardvariables.Service
+	dashboardService dashboards.DashboardService
+	ac               accesscontrol.AccessControl
+}
+
+func New(variableService dashboardvariables.Service, dashboardService dashboards.DashboardService,
+	ac accesscontrol.AccessControl) *VariablesAPI {
+	return &VariablesAPI{
+		variableService:  variableService,
+		dashboardService: dashboardService,
+		ac:               ac,
+	}
+}
+
+func (api *VariablesAPI) RegisterAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(api.ac)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	routeRegister.Group("/api/dashboards/uid/:uid/variables", func(route routing.RouteRegister) {
+		route.Post(
+			"/resolve",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+			routing.Wrap(api.ResolveVariables),
+		)
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/uid/{uid}/variables/resolve dashboards resolveDashboardVariables
+//
+// Resolve the template variables of a dashboard.
+//
+// Runs the variable queries in dependency order and returns the options and
+// current value of every variable.
+//
+// Responses:
+// 200: resolveDashboardVariablesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *VariablesAPI) ResolveVariables(c *contextmodel.ReqContext) response.Response {
+	req := dashboardvariables.ResolveRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	dash, err := api.dashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	}
+
+	result, err := api.variableService.Resolve(c.Req.Context(), &dashboardvariables.ResolveQuery{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		Dashboard: dash.Data,
+		From:      req.From,
+		To:        req.To,
+		Values:    req.Variables,
+		User:      c.SignedInUser,
+	})
+	if err != nil {
+		if errors.Is(err, dashboardvariables.ErrVariableCycle) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to resolve dashboard variables", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters resolveDashboardVariables
+type ResolveDashboardVariablesParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body dashboardvariables.ResolveRequest
+}
+
+// swagger:response resolveDashboardVariablesResponse
+type ResolveDashboardVariablesResponse struct {
+	// in: body
+	Body dashboardvariables.ResolveResult `json:"body"`
+}
@@ -0,0 +1,111 @@
+package dashboardvariables
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+)
+
+var (
+	ErrVariableCycle       = errors.New("template variables contain a dependency cycle")
+	ErrVariableUnsupported = errors.New("template variable type cannot be resolved on the server")
+	ErrVariableQueryFailed = errors.New("template variable query failed")
+)
+
+// Variable types that can be found in the dashboard templating list.
+const (
+	TypeQuery      = "query"
+	TypeCustom     = "custom"
+	TypeConstant   = "constant"
+	TypeTextbox    = "textbox"
+	TypeInterval   = "interval"
+	TypeDatasource = "datasource"
+	TypeAdhoc      = "adhoc"
+)
+
+// AllValue is the value used by the frontend to represent the "All" option.
+const AllValue = "$__all"
+
+// ResolveRequest is the payload for resolving the variables of a stored dashboard.
+type ResolveRequest struct {
+	// From Start of the time range used when running variable queries.
+	// example: now-6h
+	From string `json:"from"`
+	// To End of the time range used when running variable queries.
+	// example: now
+	To string `json:"to"`
+	// Variables Selected values keyed by variable name. Variables not listed
+	// fall back to the current value stored in the dashboard.
+	Variables map[string][]string `json:"variables"`
+}
+
+// ResolveQuery resolves the variables of the given dashboard JSON.
+type ResolveQuery struct {
+	OrgID     int64
+	Dashboard *simplejson.Json
+	From      string
+	To        string
+	Values    map[string][]string
+	User      identity.Requester
+}
+
+type Option struct {
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
+
+type Current struct {
+	Text  []string `json:"text"`
+	Value []string `json:"value"`
+}
+
+type ResolvedVariable struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Options []Option `json:"options"`
+	Current Current  `json:"current"`
+	// Error is set when the variable could not be resolved. Variables that
+	// depend on it are still resolved using its stored current value.
+	Error string `json:"error,omitempty"`
+}
+
+// ResolveResult lists the variables in dependency order.
+type ResolveResult struct {
+	Variables []ResolvedVariable `json:"variables"`
+}
+
+// ValidateQuery validates the variables of the given dashboard JSON.
+type ValidateQuery struct {
+	OrgID     int64
+	Dashboard *simplejson.Json
+	// RunQueries also runs the variable queries as User. The validation stops
+	// when Timeout is reached.
+	RunQueries bool
+	Timeout    time.Duration
+	User       identity.Requester
+}
+
+// Warning describes a variable that is broken, and would fail to load when
+// the dashboard is opened.
+type Warning struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ValidateResult lists the warnings in the dashboard order of the variables.
+type ValidateResult struct {
+	Warnings []Warning `json:"warnings"`
+}
+
+// Service resolves dashboard template variables on the backend.
+type Service interface {
+	Resolve(ctx context.Context, query *ResolveQuery) (*ResolveResult, error)
+	// Validate checks the data sources, the regexes and the dependencies of the
+	// variables, and optionally runs their queries.
+	Validate(ctx context.Context, query *ValidateQuery) (*ValidateResult, error)
+}
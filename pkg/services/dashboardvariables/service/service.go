@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables/api"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+const variableRefID = "variable-query"
+
+func ProvideService(routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService,
+	queryService query.Service,
+) *VariableService {
+	s := &VariableService{
+		ac:                ac,
+		dataSourceService: dataSourceService,
+		queryService:      queryService,
+		log:               log.New("dashboard.variables"),
+	}
+
+	variablesAPI := api.New(s, dashboardService, ac)
+	variablesAPI.RegisterAPIEndpoints(routeRegister)
+
+	return s
+}
+
+type VariableService struct {
+	ac                accesscontrol.AccessControl
+	dataSourceService datasources.DataSourceService
+	queryService      query.Service
+	log               log.Logger
+}
+
+var _ dashboardvariables.Service = (*VariableService)(nil)
+
+func (s *VariableService) Resolve(ctx context.Context, q *dashboardvariables.ResolveQuery) (*dashboardvariables.ResolveResult, error) {
+	vars, err := sortByDependencies(parseVariables(q.Dashboard))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dashboardvariables.ResolveResult{Variables: make([]dashboardvariables.ResolvedVariable, 0, len(vars))}
+	s.resolve(ctx, q, vars, func(resolved dashboardvariables.ResolvedVariable, _ error) bool {
+		result.Variables = append(result.Variables, resolved)
+		return true
+	})
+	return result, nil
+}
+
+// resolve resolves the variables in the given order and passes each of them to
+// visit, with the error of its options. It stops when visit returns false.
+func (s *VariableService) resolve(ctx context.Context, q *dashboardvariables.ResolveQuery, vars []*variable,
+	visit func(resolved dashboardvariables.ResolvedVariable, err error) bool) {
+	from, to := q.From, q.To
+	if from == "" {
+		from = q.Dashboard.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = q.Dashboard.GetPath("time", "to").MustString("now")
+	}
+
+	interp := &interpolator{values: map[string][]string{}}
+	for _, v := range vars {
+		wanted, ok := q.Values[v.name]
+		if !ok {
+			wanted = v.current
+		}
+
+		resolved := dashboardvariables.ResolvedVariable{Name: v.name, Type: v.typ}
+		opts, err := s.options(ctx, q, v, interp, from, to)
+		if err != nil {
+			s.log.Debug("Failed to resolve template variable", "name", v.name, "type", v.typ, "error", err)
+			resolved.Error = err.Error()
+			opts = []dashboardvariables.Option{}
+		}
+		if v.includeAll && len(opts) > 0 {
+			opts = append([]dashboardvariables.Option{{Text: "All", Value: dashboardvariables.AllValue}}, opts...)
+		}
+		v.options = opts
+
+		switch {
+		case v.typ == dashboardvariables.TypeConstant || v.typ == dashboardvariables.TypeTextbox:
+			if len(wanted) == 0 {
+				wanted = []string{interp.replace(v.query.MustString())}
+			}
+			resolved.Current = dashboardvariables.Current{Text: wanted, Value: wanted}
+		case resolved.Error != "":
+			resolved.Current = dashboardvariables.Current{Text: wanted, Value: wanted}
+		default:
+			resolved.Current = selectCurrent(opts, wanted)
+		}
+		resolved.Options = opts
+
+		interp.values[v.name] = v.interpolationValues(resolved.Current.Value)
+		if !visit(resolved, err) {
+			return
+		}
+	}
+}
+
+func (s *VariableService) options(ctx context.Context, q *dashboardvariables.ResolveQuery, v *variable, interp *interpolator, from, to string) ([]dashboardvariables.Option, error) {
+	var opts []dashboardvariables.Option
+	switch v.typ {
+	case dashboardvariables.TypeCustom, dashboardvariables.TypeInterval:
+		opts = customOptions(interp.replace(v.query.MustString()))
+	case dashboardvariables.TypeConstant, dashboardvariables.TypeTextbox:
+		return []dashboardvariables.Option{}, nil
+	case dashboardvariables.TypeDatasource:
+		dsOpts, err := s.datasourceOptions(ctx, q, v.query.MustString())
+		if err != nil {
+			return nil, err
+		}
+		opts = dsOpts
+	case dashboardvariables.TypeQuery:
+		queryOpts, err := s.queryOptions(ctx, q, v, interp, from, to)
+		if err != nil {
+			return nil, err
+		}
+		opts = queryOpts
+	default:
+		return nil, fmt.Errorf("%w: %q", dashboardvariables.ErrVariableUnsupported, v.typ)
+	}
+
+	opts, err := applyRegex(opts, interp.replace(v.regex))
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	sortOptions(opts, v.sort)
+	return opts, nil
+}
+
+// datasourceOptions returns the datasources of the given type that the user
+// can read, like the datasource list API.
+func (s *VariableService) datasourceOptions(ctx context.Context, q *dashboardvariables.ResolveQuery, pluginID string) ([]dashboardvariables.Option, error) {
+	dss, err := s.dataSourceService.GetDataSourcesByType(ctx, &datasources.GetDataSourcesByTypeQuery{OrgID: q.OrgID, Type: pluginID})
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]dashboardvariables.Option, 0, len(dss))
+	for _, ds := range dss {
+		canRead, err := s.ac.Evaluate(ctx, q.User, accesscontrol.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(ds.UID)))
+		if err != nil {
+			return nil, err
+		}
+		if canRead {
+			opts = append(opts, dashboardvariables.Option{Text: ds.Name, Value: ds.UID})
+		}
+	}
+	return opts, nil
+}
+
+func (s *VariableService) queryOptions(ctx context.Context, q *dashboardvariables.ResolveQuery, v *variable, interp *interpolator, from, to string) ([]dashboardvariables.Option, error) {
+	dsRef, err := s.datasourceRef(ctx, q.OrgID, interp.replaceJSON(v.datasource))
+	if err != nil {
+		return nil, err
+	}
+
+	target := simplejson.New()
+	if m, err := v.query.Map(); err == nil {
+		target = interp.replaceJSON(simplejson.NewFromAny(m))
+	} else {
+		target.Set("query", interp.replace(v.query.MustString()))
+	}
+	target.Set("refId", variableRefID)
+	target.Set("datasource", dsRef)
+
+	resp, err := s.queryService.QueryData(ctx, q.User, false, dtos.MetricRequest{
+		From:    from,
+		To:      to,
+		Queries: []*simplejson.Json{target},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dashboardvariables.ErrVariableQueryFailed, err)
+	}
+	return framesToOptions(resp.Responses[variableRefID])
+}
+
+// datasourceRef normalizes the variable datasource to a {uid, type}
+// reference, falling back to the default datasource of the organization.
+func (s *VariableService) datasourceRef(ctx context.Context, orgID int64, ref *simplejson.Json) (map[string]any, error) {
+	if uid := ref.Get("uid").MustString(); uid != "" {
+		return map[string]any{"uid": uid, "type": ref.Get("type").MustString()}, nil
+	}
+	if uid := ref.MustString(); uid != "" {
+		return map[string]any{"uid": uid}, nil
+	}
+	ds, err := s.dataSourceService.GetDefaultDataSource(ctx, &datasources.GetDefaultDataSourceQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"uid": ds.UID, "type": ds.Type}, nil
+}
+
+// framesToOptions reads the options from the __text and __value fields when
+// present, otherwise from the first field of every frame.
+func framesToOptions(dr backend.DataResponse) ([]dashboardvariables.Option, error) {
+	if dr.Error != nil {
+		return nil, fmt.Errorf("%w: %s", dashboardvariables.ErrVariableQueryFailed, dr.Error)
+	}
+
+	seen := map[string]bool{}
+	opts := []dashboardvariables.Option{}
+	add := func(text, value string) {
+		if seen[value] {
+			return
+		}
+		seen[value] = true
+		opts = append(opts, dashboardvariables.Option{Text: text, Value: value})
+	}
+
+	for _, frame := range dr.Frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		textField, valueField := frame.Fields[0], frame.Fields[0]
+		for _, f := range frame.Fields {
+			switch strings.ToLower(f.Name) {
+			case "__text":
+				textField = f
+			case "__value":
+				valueField = f
+			}
+		}
+		for i := 0; i < valueField.Len(); i++ {
+			value, ok := valueField.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			text := value
+			if i < textField.Len() {
+				if t, ok := textField.ConcreteAt(i); ok {
+					text = t
+				}
+			}
+			add(fmt.Sprint(text), fmt.Sprint(value))
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testDashboard = `{
+	"time": {"from": "now-1h", "to": "now"},
+	"templating": {
+		"list": [
+			{
+				"name": "server",
+				"type": "query",
+				"datasource": {"uid": "sql", "type": "mysql"},
+				"query": "SELECT host FROM servers WHERE env = '${env}'",
+				"regex": "/^web-(.*)$/",
+				"sort": 2,
+				"current": {"value": "2"}
+			},
+			{
+				"name": "env",
+				"type": "custom",
+				"query": "Production : prod,Staging : staging",
+				"current": {"value": "prod"}
+			},
+			{
+				"name": "ds",
+				"type": "datasource",
+				"query": "prometheus",
+				"includeAll": true
+			},
+			{
+				"name": "prefix",
+				"type": "constant",
+				"query": "${env}-"
+			}
+		]
+	}
+}`
+
+func TestResolve(t *testing.T) {
+	queryService := query.NewFakeQueryService(t)
+	queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+		return req.From == "now-1h" && req.To == "now" &&
+			req.Queries[0].Get("query").MustString() == "SELECT host FROM servers WHERE env = 'staging'" &&
+			req.Queries[0].Get("datasource").Get("uid").MustString() == "sql"
+	})).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{
+			variableRefID: backend.DataResponse{
+				Frames: data.Frames{data.NewFrame("",
+					data.NewField("host", nil, []string{"web-1", "web-2", "db-1", "web-1"}),
+				)},
+			},
+		},
+	}, nil).Once()
+
+	s := &VariableService{
+		ac:           acimpl.ProvideAccessControl(setting.NewCfg()),
+		queryService: queryService,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "prom-a", Name: "Prom A", Type: "prometheus"},
+			{OrgID: 1, UID: "prom-b", Name: "Prom B", Type: "prometheus"},
+			{OrgID: 2, UID: "prom-c", Name: "Prom C", Type: "prometheus"},
+		}},
+		log: log.NewNopLogger(),
+	}
+
+	res, err := s.Resolve(context.Background(), &dashboardvariables.ResolveQuery{
+		OrgID:     1,
+		Dashboard: simplejson.MustJson([]byte(testDashboard)),
+		Values:    map[string][]string{"env": {"staging"}},
+		User:      &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {datasources.ActionRead: {datasources.ScopeAll}}}},
+	})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, v := range res.Variables {
+		names = append(names, v.Name)
+		require.Empty(t, v.Error)
+	}
+	require.Equal(t, []string{"env", "ds", "prefix", "server"}, names)
+
+	env := res.Variables[0]
+	require.Equal(t, []string{"staging"}, env.Current.Value)
+	require.Equal(t, []string{"Staging"}, env.Current.Text)
+
+	ds := res.Variables[1]
+	require.Equal(t, []dashboardvariables.Option{
+		{Text: "All", Value: dashboardvariables.AllValue, Selected: true},
+		{Text: "Prom A", Value: "prom-a"},
+		{Text: "Prom B", Value: "prom-b"},
+	}, ds.Options)
+
+	require.Equal(t, []string{"staging-"}, res.Variables[2].Current.Value)
+
+	server := res.Variables[3]
+	require.Equal(t, []dashboardvariables.Option{
+		{Text: "2", Value: "2", Selected: true},
+		{Text: "1", Value: "1"},
+	}, server.Options)
+}
+
+func TestResolveDatasourceOptionsWithoutReadPermission(t *testing.T) {
+	s := &VariableService{
+		ac: acimpl.ProvideAccessControl(setting.NewCfg()),
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "prom-a", Name: "Prom A", Type: "prometheus"},
+			{OrgID: 1, UID: "prom-b", Name: "Prom B", Type: "prometheus"},
+		}},
+		log: log.NewNopLogger(),
+	}
+
+	res, err := s.Resolve(context.Background(), &dashboardvariables.ResolveQuery{
+		OrgID:     1,
+		Dashboard: simplejson.MustJson([]byte(`{"templating": {"list": [{"name": "ds", "type": "datasource", "query": "prometheus"}]}}`)),
+		User: &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+			1: {datasources.ActionRead: {datasources.ScopeProvider.GetResourceScopeUID("prom-b")}},
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Variables, 1)
+	require.Equal(t, []dashboardvariables.Option{{Text: "Prom B", Value: "prom-b", Selected: true}}, res.Variables[0].Options)
+}
+
+func TestResolveQueryErrorKeepsCurrentValue(t *testing.T) {
+	queryService := query.NewFakeQueryService(t)
+	queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{
+			variableRefID: backend.DataResponse{Error: context.DeadlineExceeded},
+		},
+	}, nil).Once()
+
+	s := &VariableService{queryService: queryService, dataSourceService: &fakeDatasources.FakeDataSourceService{}, log: log.NewNopLogger()}
+	res, err := s.Resolve(context.Background(), &dashboardvariables.ResolveQuery{
+		OrgID:     1,
+		Dashboard: simplejson.MustJson([]byte(testDashboard)),
+		User:      &user.SignedInUser{OrgID: 1},
+	})
+	require.NoError(t, err)
+
+	server := res.Variables[3]
+	require.Contains(t, server.Error, dashboardvariables.ErrVariableQueryFailed.Error())
+	require.Equal(t, []string{"2"}, server.Current.Value)
+}
+
+func TestResolveCycle(t *testing.T) {
+	s := &VariableService{log: log.NewNopLogger()}
+	_, err := s.Resolve(context.Background(), &dashboardvariables.ResolveQuery{
+		Dashboard: simplejson.MustJson([]byte(`{"templating": {"list": [
+			{"name": "a", "type": "custom", "query": "$b"},
+			{"name": "b", "type": "custom", "query": "[[a]]"}
+		]}}`)),
+	})
+	require.ErrorIs(t, err, dashboardvariables.ErrVariableCycle)
+}
+
+func TestFormatValues(t *testing.T) {
+	values := []string{"a.b", "c"}
+	require.Equal(t, "{a.b,c}", formatValues(values, ""))
+	require.Equal(t, "a.b,c", formatValues(values, "csv"))
+	require.Equal(t, "a.b|c", formatValues(values, "pipe"))
+	require.Equal(t, `(a\.b|c)`, formatValues(values, "regex"))
+	require.Equal(t, `["a.b","c"]`, formatValues(values, "json"))
+	require.Equal(t, "'a.b','c'", formatValues(values, "sqlstring"))
+	require.Equal(t, "c", formatValues([]string{"c"}, ""))
+}
+
+func TestValidate(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{"templating": {"list": [
+		{"name": "a", "type": "custom", "query": "$b"},
+		{"name": "b", "type": "custom", "query": "[[a]]"},
+		{"name": "c", "type": "custom", "query": "$a,x"},
+		{"name": "missing", "type": "query", "datasource": {"uid": "missing"}, "query": "up"},
+		{"name": "byname", "type": "query", "datasource": "SQL", "query": "SELECT 1"},
+		{"name": "builtin", "type": "query", "datasource": {"uid": "grafana"}, "query": "list"},
+		{"name": "regex", "type": "query", "datasource": {"uid": "sql"}, "query": "SELECT 1", "regex": "/(/"},
+		{"name": "ds", "type": "datasource", "query": ""},
+		{"name": "filters", "type": "adhoc", "datasource": {"uid": "sql"}}
+	]}}`))
+	s := &VariableService{
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "sql", Name: "SQL", Type: "mysql"},
+		}},
+		log: log.NewNopLogger(),
+	}
+
+	res, err := s.Validate(context.Background(), &dashboardvariables.ValidateQuery{OrgID: 1, Dashboard: dashboard})
+	require.NoError(t, err)
+	require.Equal(t, []dashboardvariables.Warning{
+		{Name: "a", Type: "custom", Message: "the variable is part of, or depends on, a dependency cycle"},
+		{Name: "b", Type: "custom", Message: "the variable is part of, or depends on, a dependency cycle"},
+		{Name: "c", Type: "custom", Message: "the variable is part of, or depends on, a dependency cycle"},
+		{Name: "missing", Type: "query", Message: `data source "missing" not found`},
+		{Name: "regex", Type: "query", Message: "invalid regex: error parsing regexp: missing closing ): `(`"},
+		{Name: "ds", Type: "datasource", Message: "the data source type is not set"},
+	}, res.Warnings)
+}
+
+func TestValidateRunQueries(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{"templating": {"list": [
+		{"name": "env", "type": "custom", "query": "prod,staging"},
+		{"name": "host", "type": "query", "datasource": {"uid": "sql"}, "query": "SELECT host FROM hosts WHERE env = '$env'"},
+		{"name": "slow", "type": "query", "datasource": {"uid": "sql"}, "query": "SELECT sleep(10)"},
+		{"name": "after", "type": "query", "datasource": {"uid": "sql"}, "query": "SELECT 1"},
+		{"name": "filters", "type": "adhoc", "datasource": {"uid": "sql"}}
+	]}}`))
+
+	queryService := query.NewFakeQueryService(t)
+	queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+		return req.Queries[0].Get("query").MustString() == "SELECT host FROM hosts WHERE env = 'prod'"
+	})).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{
+			variableRefID: backend.DataResponse{Error: errors.New("table hosts does not exist")},
+		},
+	}, nil).Once()
+	queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+		return req.Queries[0].Get("query").MustString() == "SELECT sleep(10)"
+	})).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.DeadlineExceeded).Once()
+
+	s := &VariableService{
+		queryService: queryService,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "sql", Name: "SQL", Type: "mysql"},
+		}},
+		log: log.NewNopLogger(),
+	}
+
+	res, err := s.Validate(context.Background(), &dashboardvariables.ValidateQuery{
+		OrgID:      1,
+		Dashboard:  dashboard,
+		RunQueries: true,
+		Timeout:    50 * time.Millisecond,
+		User:       &user.SignedInUser{OrgID: 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []dashboardvariables.Warning{
+		{Name: "host", Type: "query", Message: "template variable query failed: table hosts does not exist"},
+		{Name: "slow", Type: "query", Message: "the query did not complete within 50ms"},
+	}, res.Warnings)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// builtInDatasources are the data sources that are not stored in the
+// database, by UID and by name.
+var builtInDatasources = map[string]bool{
+	"grafana":         true,
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+	"__expr__":        true,
+}
+
+// Validate returns a warning for every variable of the dashboard that would
+// fail to load. Variables that cannot be resolved on the server, such as ad
+// hoc filters, are only checked statically.
+func (s *VariableService) Validate(ctx context.Context, q *dashboardvariables.ValidateQuery) (*dashboardvariables.ValidateResult, error) {
+	vars := parseVariables(q.Dashboard)
+	warnings := make(map[string]string, len(vars))
+
+	sorted, err := sortByDependencies(vars)
+	if err != nil {
+		ordered := make(map[string]bool, len(sorted))
+		for _, v := range sorted {
+			ordered[v.name] = true
+		}
+		for _, v := range vars {
+			if !ordered[v.name] {
+				warnings[v.name] = "the variable is part of, or depends on, a dependency cycle"
+			}
+		}
+	}
+
+	for _, v := range vars {
+		if _, ok := warnings[v.name]; ok {
+			continue
+		}
+		if msg := s.check(ctx, q.OrgID, v); msg != "" {
+			warnings[v.name] = msg
+		}
+	}
+
+	if q.RunQueries {
+		runCtx := ctx
+		if q.Timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, q.Timeout)
+			defer cancel()
+		}
+		resolveQuery := &dashboardvariables.ResolveQuery{OrgID: q.OrgID, Dashboard: q.Dashboard, User: q.User}
+		s.resolve(runCtx, resolveQuery, sorted, func(resolved dashboardvariables.ResolvedVariable, err error) bool {
+			if err == nil || errors.Is(err, dashboardvariables.ErrVariableUnsupported) {
+				return true
+			}
+			if _, ok := warnings[resolved.Name]; ok {
+				return runCtx.Err() == nil
+			}
+			// The following variables would fail because of the timeout too, so they are not reported.
+			if runCtx.Err() != nil {
+				warnings[resolved.Name] = fmt.Sprintf("the query did not complete within %s", q.Timeout)
+				return false
+			}
+			warnings[resolved.Name] = err.Error()
+			return true
+		})
+	}
+
+	result := &dashboardvariables.ValidateResult{Warnings: []dashboardvariables.Warning{}}
+	for _, v := range vars {
+		if msg, ok := warnings[v.name]; ok {
+			result.Warnings = append(result.Warnings, dashboardvariables.Warning{Name: v.name, Type: v.typ, Message: msg})
+		}
+	}
+	return result, nil
+}
+
+// check returns why the variable would fail to load, without running its
+// query, or an empty string.
+func (s *VariableService) check(ctx context.Context, orgID int64, v *variable) string {
+	// Regexes using other variables are only known when the dashboard is loaded.
+	if v.regex != "" && !variableRefRegex.MatchString(v.regex) {
+		if _, err := compileJSRegex(v.regex); err != nil {
+			return fmt.Sprintf("invalid regex: %s", err)
+		}
+	}
+
+	switch v.typ {
+	case dashboardvariables.TypeQuery:
+		return s.checkDatasource(ctx, orgID, v.datasource)
+	case dashboardvariables.TypeDatasource:
+		if v.query.MustString() == "" {
+			return "the data source type is not set"
+		}
+	}
+	return ""
+}
+
+func (s *VariableService) checkDatasource(ctx context.Context, orgID int64, ref *simplejson.Json) string {
+	uid := ref.Get("uid").MustString()
+	if uid == "" {
+		uid = ref.MustString()
+	}
+	if uid == "" {
+		ds, err := s.dataSourceService.GetDefaultDataSource(ctx, &datasources.GetDefaultDataSourceQuery{OrgID: orgID})
+		if err != nil || ds == nil {
+			return "the variable uses the default data source, but the organization has none"
+		}
+		return ""
+	}
+	if builtInDatasources[uid] || variableRefRegex.MatchString(uid) {
+		return ""
+	}
+
+	// Older dashboards reference the data sources by name.
+	for _, query := range []*datasources.GetDataSourceQuery{{OrgID: orgID, UID: uid}, {OrgID: orgID, Name: uid}} {
+		_, err := s.dataSourceService.GetDataSource(ctx, query)
+		if err == nil {
+			return ""
+		}
+		if !errors.Is(err, datasources.ErrDataSourceNotFound) {
+			s.log.Debug("Failed to get the data source of a template variable", "uid", uid, "error", err)
+			return ""
+		}
+	}
+	return fmt.Sprintf("data source %q not found", uid)
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+)
+
+// variableRefRegex matches the $var, ${var}, ${var:format} and [[var]] syntaxes.
+var variableRefRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.[^:^}]+)?(?::([^}]+))?\}`)
+
+// customOptionRegex splits a custom variable query on unescaped commas.
+var customOptionRegex = regexp.MustCompile(`(?:\\,|[^,])+`)
+
+type variable struct {
+	name       string
+	typ        string
+	query      *simplejson.Json
+	datasource *simplejson.Json
+	regex      string
+	sort       int
+	includeAll bool
+	allValue   string
+	current    []string
+	options    []dashboardvariables.Option
+	deps       []string
+}
+
+func parseVariables(dash *simplejson.Json) []*variable {
+	list := dash.Get("templating").Get("list").MustArray()
+	vars := make([]*variable, 0, len(list))
+	for _, item := range list {
+		raw := simplejson.NewFromAny(item)
+		name := raw.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+		v := &variable{
+			name:       name,
+			typ:        raw.Get("type").MustString(),
+			query:      raw.Get("query"),
+			datasource: raw.Get("datasource"),
+			regex:      raw.Get("regex").MustString(),
+			sort:       raw.Get("sort").MustInt(),
+			includeAll: raw.Get("includeAll").MustBool(),
+			allValue:   raw.Get("allValue").MustString(),
+			current:    jsonStrings(raw.Get("current").Get("value")),
+		}
+		vars = append(vars, v)
+	}
+
+	names := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		names[v.name] = true
+	}
+	for _, v := range vars {
+		v.deps = v.references(names)
+	}
+	return vars
+}
+
+// references returns the names of the other variables used by the query,
+// datasource and regex of v.
+func (v *variable) references(names map[string]bool) []string {
+	var sb strings.Builder
+	if b, err := v.query.Encode(); err == nil {
+		sb.Write(b)
+	}
+	if b, err := v.datasource.Encode(); err == nil {
+		sb.Write(b)
+	}
+	sb.WriteString(v.regex)
+
+	seen := map[string]bool{}
+	deps := []string{}
+	for _, m := range variableRefRegex.FindAllStringSubmatch(sb.String(), -1) {
+		name := firstNonEmpty(m[1], m[2], m[4])
+		if name == v.name || !names[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		deps = append(deps, name)
+	}
+	return deps
+}
+
+// sortByDependencies orders the variables so that every variable comes after
+// the variables it references, keeping the dashboard order where possible. On
+// a cycle, it returns the variables that could be ordered with the error.
+func sortByDependencies(vars []*variable) ([]*variable, error) {
+	resolved := make(map[string]bool, len(vars))
+	sorted := make([]*variable, 0, len(vars))
+	for len(sorted) < len(vars) {
+		progress := false
+		for _, v := range vars {
+			if resolved[v.name] {
+				continue
+			}
+			ready := true
+			for _, dep := range v.deps {
+				if !resolved[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				resolved[v.name] = true
+				sorted = append(sorted, v)
+				progress = true
+			}
+		}
+		if !progress {
+			return sorted, dashboardvariables.ErrVariableCycle
+		}
+	}
+	return sorted, nil
+}
+
+// interpolator replaces variable references with the values selected so far.
+type interpolator struct {
+	values map[string][]string
+}
+
+func (i *interpolator) replace(s string) string {
+	return variableRefRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := variableRefRegex.FindStringSubmatch(match)
+		name := firstNonEmpty(m[1], m[2], m[4])
+		values, ok := i.values[name]
+		if !ok {
+			return match
+		}
+		return formatValues(values, firstNonEmpty(m[3], m[5]))
+	})
+}
+
+// replaceJSON interpolates every string found in the given JSON value.
+func (i *interpolator) replaceJSON(j *simplejson.Json) *simplejson.Json {
+	return simplejson.NewFromAny(i.replaceAny(j.Interface()))
+}
+
+func (i *interpolator) replaceAny(val any) any {
+	switch v := val.(type) {
+	case string:
+		return i.replace(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = i.replaceAny(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for idx, item := range v {
+			out[idx] = i.replaceAny(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func formatValues(values []string, format string) string {
+	switch format {
+	case "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "json":
+		b, _ := json.Marshal(values)
+		return string(b)
+	case "regex":
+		escaped := make([]string, len(values))
+		for idx, v := range values {
+			escaped[idx] = regexp.QuoteMeta(v)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote", "sqlstring":
+		quoted := make([]string, len(values))
+		for idx, v := range values {
+			quoted[idx] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		return strings.Join(quoted, ",")
+	case "doublequote":
+		quoted := make([]string, len(values))
+		for idx, v := range values {
+			quoted[idx] = `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+		}
+		return strings.Join(quoted, ",")
+	default:
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+// customOptions parses the comma separated "text : value" syntax used by
+// custom variables.
+func customOptions(query string) []dashboardvariables.Option {
+	opts := []dashboardvariables.Option{}
+	for _, item := range customOptionRegex.FindAllString(query, -1) {
+		item = strings.TrimSpace(strings.ReplaceAll(item, `\,`, ","))
+		if item == "" {
+			continue
+		}
+		text, value := item, item
+		if parts := strings.SplitN(item, " : ", 2); len(parts) == 2 {
+			text, value = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+		opts = append(opts, dashboardvariables.Option{Text: text, Value: value})
+	}
+	return opts
+}
+
+// applyRegex filters options with the variable regex. Named "text" and
+// "value" groups, or the first capture group, extract the option.
+func applyRegex(opts []dashboardvariables.Option, pattern string) ([]dashboardvariables.Option, error) {
+	if pattern == "" {
+		return opts, nil
+	}
+	re, err := compileJSRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	textIdx, valueIdx := re.SubexpIndex("text"), re.SubexpIndex("value")
+	seen := map[string]bool{}
+	out := []dashboardvariables.Option{}
+	for _, opt := range opts {
+		m := re.FindStringSubmatch(opt.Value)
+		if m == nil {
+			continue
+		}
+		text, value := opt.Text, opt.Value
+		switch {
+		case textIdx > 0 || valueIdx > 0:
+			if valueIdx > 0 && m[valueIdx] != "" {
+				value = m[valueIdx]
+			}
+			if textIdx > 0 && m[textIdx] != "" {
+				text = m[textIdx]
+			} else {
+				text = value
+			}
+		case len(m) > 1:
+			text, value = m[1], m[1]
+		}
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, dashboardvariables.Option{Text: text, Value: value})
+	}
+	return out, nil
+}
+
+// compileJSRegex compiles a regex written in the /pattern/flags form used in
+// dashboards. Patterns without slashes are compiled as is.
+func compileJSRegex(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "/") {
+		if end := strings.LastIndex(pattern, "/"); end > 0 {
+			flags := pattern[end+1:]
+			pattern = pattern[1:end]
+			if strings.Contains(flags, "i") {
+				pattern = "(?i)" + pattern
+			}
+		}
+	}
+	return regexp.Compile(pattern)
+}
+
+// sortOptions implements the sort modes of the variable editor.
+func sortOptions(opts []dashboardvariables.Option, mode int) {
+	if mode == 0 {
+		return
+	}
+	desc := mode%2 == 0
+	less := func(a, b dashboardvariables.Option) bool {
+		switch mode {
+		case 3, 4:
+			return numericKey(a.Text) < numericKey(b.Text)
+		case 5, 6:
+			return strings.ToLower(a.Text) < strings.ToLower(b.Text)
+		default:
+			return a.Text < b.Text
+		}
+	}
+	sort.SliceStable(opts, func(i, j int) bool {
+		if desc {
+			return less(opts[j], opts[i])
+		}
+		return less(opts[i], opts[j])
+	})
+}
+
+var numberRegex = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+func numericKey(s string) float64 {
+	n, err := strconv.ParseFloat(numberRegex.FindString(s), 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// selectCurrent returns the options matching the wanted values, defaulting to
+// the first option when none of them is available.
+func selectCurrent(opts []dashboardvariables.Option, wanted []string) dashboardvariables.Current {
+	current := dashboardvariables.Current{Text: []string{}, Value: []string{}}
+	want := make(map[string]bool, len(wanted))
+	for _, w := range wanted {
+		want[w] = true
+	}
+	for idx := range opts {
+		if want[opts[idx].Value] {
+			opts[idx].Selected = true
+			current.Text = append(current.Text, opts[idx].Text)
+			current.Value = append(current.Value, opts[idx].Value)
+		}
+	}
+	if len(current.Value) == 0 && len(opts) > 0 {
+		opts[0].Selected = true
+		current.Text = []string{opts[0].Text}
+		current.Value = []string{opts[0].Value}
+	}
+	return current
+}
+
+// interpolationValues expands the "All" option to the values it represents.
+func (v *variable) interpolationValues(current []string) []string {
+	for _, c := range current {
+		if c != dashboardvariables.AllValue {
+			continue
+		}
+		if v.allValue != "" {
+			return []string{v.allValue}
+		}
+		values := []string{}
+		for _, opt := range v.options {
+			if opt.Value != dashboardvariables.AllValue {
+				values = append(values, opt.Value)
+			}
+		}
+		return values
+	}
+	return current
+}
+
+// jsonStrings reads a string or a list of strings.
+func jsonStrings(j *simplejson.Json) []string {
+	if s, err := j.String(); err == nil {
+		return []string{s}
+	}
+	arr, err := j.Array()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(arr))
+	for _, item := range arr {
+		out = append(out, fmt.Sprint(item))
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
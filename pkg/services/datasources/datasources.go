@@ -12,10 +12,10 @@ import (
 
 // DataSourceService interface for interacting with datasources.
 type DataSourceService interface {
-	// GetDataSource gets a datasource.
+	// GetDataSource gets a datasource, or a datasource shared with the org by another org.
 	GetDataSource(ctx context.Context, query *GetDataSourceQuery) (*DataSource, error)
 
-	// GetDataSources gets datasources.
+	// GetDataSources gets datasources, including the ones shared with the org by other orgs.
 	GetDataSources(ctx context.Context, query *GetDataSourcesQuery) ([]*DataSource, error)
 
 	// GetAllDataSources gets all datasources.
@@ -33,6 +33,21 @@ type DataSourceService interface {
 	// UpdateDataSource updates an existing datasource.
 	UpdateDataSource(ctx context.Context, cmd *UpdateDataSourceCommand) (*DataSource, error)
 
+	// ShareDataSource shares a datasource with another org, or updates the access policy of an org it is shared with.
+	ShareDataSource(ctx context.Context, cmd *ShareDataSourceCommand) (*DataSourceShare, error)
+
+	// UnshareDataSource stops sharing a datasource with another org.
+	UnshareDataSource(ctx context.Context, cmd *UnshareDataSourceCommand) error
+
+	// GetDataSourceShares gets the orgs a datasource is shared with.
+	GetDataSourceShares(ctx context.Context, query *GetDataSourceSharesQuery) ([]*DataSourceShare, error)
+
+	// GetSecureJSONDataVersions gets the previous versions of the secure JSON data of a datasource, the latest first.
+	GetSecureJSONDataVersions(ctx context.Context, query *GetSecureJSONDataVersionsQuery) ([]*SecureJSONDataVersion, error)
+
+	// RestoreSecureJSONDataVersion restores a previous version of the secure JSON data of a datasource.
+	RestoreSecureJSONDataVersion(ctx context.Context, cmd *RestoreSecureJSONDataVersionCommand) (*DataSource, error)
+
 	// GetDefaultDataSource gets the default datasource.
 	GetDefaultDataSource(ctx context.Context, query *GetDefaultDataSourceQuery) (*DataSource, error)
 
@@ -17,4 +17,9 @@ var (
 	ErrDatasourceIsReadOnly              = errors.New("data source is readonly, can only be updated from configuration")
 	ErrDataSourceNameInvalid             = errutil.ValidationFailed("datasource.nameInvalid", errutil.WithPublicMessage("Invalid datasource name."))
 	ErrDataSourceURLInvalid              = errutil.ValidationFailed("datasource.urlInvalid", errutil.WithPublicMessage("Invalid datasource url."))
+	ErrDataSourceShareNotFound           = errutil.NotFound("datasource.shareNotFound", errutil.WithPublicMessage("Data source is not shared with the organization."))
+	ErrDataSourceShareInvalid            = errutil.ValidationFailed("datasource.shareInvalid", errutil.WithPublicMessage("A data source can only be shared with another existing organization."))
+	ErrDataSourceShareRoleInvalid        = errutil.ValidationFailed("datasource.shareRoleInvalid", errutil.WithPublicMessage("Invalid minimum role, use Viewer, Editor or Admin."))
+	ErrSecureJSONDataVersionNotFound     = errutil.NotFound("datasource.secureJsonDataVersionNotFound", errutil.WithPublicMessage("Version of the secure JSON data of the data source not found."))
+	ErrDataSourceIsShared                = errutil.Forbidden("datasource.isShared", errutil.WithPublicMessage("Data source is shared from another organization and can only be changed in its owning organization."))
 )
@@ -11,9 +11,11 @@ import (
 )
 
 type FakeDataSourceService struct {
-	lastID                int64
-	DataSources           []*datasources.DataSource
-	SimulatePluginFailure bool
+	lastID                 int64
+	DataSources            []*datasources.DataSource
+	Shares                 []*datasources.DataSourceShare
+	SecureJSONDataVersions []*datasources.SecureJSONDataVersion
+	SimulatePluginFailure  bool
 }
 
 var _ datasources.DataSourceService = &FakeDataSourceService{}
@@ -102,6 +104,72 @@ func (s *FakeDataSourceService) UpdateDataSource(ctx context.Context, cmd *datas
 	return nil, datasources.ErrDataSourceNotFound
 }
 
+func (s *FakeDataSourceService) ShareDataSource(ctx context.Context, cmd *datasources.ShareDataSourceCommand) (*datasources.DataSourceShare, error) {
+	ds, err := s.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	share := &datasources.DataSourceShare{DataSourceID: ds.ID, OrgID: ds.OrgID, ConsumerOrgID: cmd.ConsumerOrgID, MinRole: cmd.MinRole}
+	s.Shares = append(s.Shares, share)
+	return share, nil
+}
+
+func (s *FakeDataSourceService) UnshareDataSource(ctx context.Context, cmd *datasources.UnshareDataSourceCommand) error {
+	ds, err := s.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return err
+	}
+	for i, share := range s.Shares {
+		if share.DataSourceID == ds.ID && share.ConsumerOrgID == cmd.ConsumerOrgID {
+			s.Shares = append(s.Shares[:i], s.Shares[i+1:]...)
+			return nil
+		}
+	}
+	return datasources.ErrDataSourceShareNotFound
+}
+
+func (s *FakeDataSourceService) GetDataSourceShares(ctx context.Context, query *datasources.GetDataSourceSharesQuery) ([]*datasources.DataSourceShare, error) {
+	ds, err := s.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: query.UID, OrgID: query.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	var shares []*datasources.DataSourceShare
+	for _, share := range s.Shares {
+		if share.DataSourceID == ds.ID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (s *FakeDataSourceService) GetSecureJSONDataVersions(ctx context.Context, query *datasources.GetSecureJSONDataVersionsQuery) ([]*datasources.SecureJSONDataVersion, error) {
+	ds, err := s.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: query.UID, OrgID: query.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	var versions []*datasources.SecureJSONDataVersion
+	for _, version := range s.SecureJSONDataVersions {
+		if version.DataSourceID == ds.ID {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+func (s *FakeDataSourceService) RestoreSecureJSONDataVersion(ctx context.Context, cmd *datasources.RestoreSecureJSONDataVersionCommand) (*datasources.DataSource, error) {
+	ds, err := s.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range s.SecureJSONDataVersions {
+		if version.DataSourceID == ds.ID && version.Version == cmd.Version {
+			ds.SecureJsonData = version.EncryptedSecureJsonData
+			return ds, nil
+		}
+	}
+	return nil, datasources.ErrSecureJSONDataVersionNotFound
+}
+
 func (s *FakeDataSourceService) GetDefaultDataSource(ctx context.Context, query *datasources.GetDefaultDataSourceQuery) (*datasources.DataSource, error) {
 	return nil, nil
 }
@@ -0,0 +1,54 @@
+package guardian
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+var _ DatasourceGuardian = new(AccessControlGuardian)
+
+// AccessControlGuardian is used when the query permissions of data sources are enforced.
+// It only allows users to query the data sources they have the datasources:query permission on.
+type AccessControlGuardian struct {
+	ac          accesscontrol.AccessControl
+	user        identity.Requester
+	dataSources map[int64]datasources.DataSource
+}
+
+func newAccessControlGuardian(ac accesscontrol.AccessControl, user identity.Requester, dataSources ...datasources.DataSource) *AccessControlGuardian {
+	g := &AccessControlGuardian{
+		ac:          ac,
+		user:        user,
+		dataSources: make(map[int64]datasources.DataSource, len(dataSources)),
+	}
+	for _, ds := range dataSources {
+		g.dataSources[ds.ID] = ds
+	}
+	return g
+}
+
+func (g *AccessControlGuardian) CanQuery(datasourceID int64) (bool, error) {
+	scope := datasources.ScopeProvider.GetResourceScope(strconv.FormatInt(datasourceID, 10))
+	if ds, ok := g.dataSources[datasourceID]; ok {
+		scope = datasources.ScopeProvider.GetResourceScopeUID(ds.UID)
+	}
+	return g.ac.Evaluate(context.Background(), g.user, accesscontrol.EvalPermission(datasources.ActionQuery, scope))
+}
+
+func (g *AccessControlGuardian) FilterDatasourcesByQueryPermissions(ds []*datasources.DataSource) ([]*datasources.DataSource, error) {
+	filtered := make([]*datasources.DataSource, 0, len(ds))
+	for _, d := range ds {
+		ok, err := g.ac.Evaluate(context.Background(), g.user, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(d.UID)))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered, nil
+}
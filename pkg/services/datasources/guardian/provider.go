@@ -1,8 +1,10 @@
 package guardian
 
 import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type DatasourceGuardianProvider interface {
@@ -14,12 +16,18 @@ type DatasourceGuardian interface {
 	FilterDatasourcesByQueryPermissions([]*datasources.DataSource) ([]*datasources.DataSource, error)
 }
 
-func ProvideGuardian() *OSSProvider {
-	return &OSSProvider{}
+func ProvideGuardian(cfg *setting.Cfg, ac accesscontrol.AccessControl) *OSSProvider {
+	return &OSSProvider{enforceQueryPermissions: cfg.DataSourceEnforceQueryPermissions, ac: ac}
 }
 
-type OSSProvider struct{}
+type OSSProvider struct {
+	enforceQueryPermissions bool
+	ac                      accesscontrol.AccessControl
+}
 
 func (p *OSSProvider) New(orgID int64, user identity.Requester, dataSources ...datasources.DataSource) DatasourceGuardian {
-	return &AllowGuardian{}
+	if !p.enforceQueryPermissions {
+		return &AllowGuardian{}
+	}
+	return newAccessControlGuardian(p.ac, user, dataSources...)
 }
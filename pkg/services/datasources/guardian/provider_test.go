@@ -0,0 +1,61 @@
+package guardian
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestOSSProvider(t *testing.T) {
+	allowed := datasources.DataSource{ID: 1, UID: "allowed"}
+	denied := datasources.DataSource{ID: 2, UID: "denied"}
+	usr := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {
+			datasources.ActionRead:  {datasources.ScopeAll},
+			datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("allowed")},
+		},
+	}}
+
+	t.Run("allows to query every data source when the query permissions are not enforced", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		g := ProvideGuardian(cfg, acimpl.ProvideAccessControl(cfg)).New(1, usr, denied)
+
+		canQuery, err := g.CanQuery(denied.ID)
+		require.NoError(t, err)
+		assert.True(t, canQuery)
+	})
+
+	t.Run("checks the query permission when the query permissions are enforced", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataSourceEnforceQueryPermissions = true
+		p := ProvideGuardian(cfg, acimpl.ProvideAccessControl(cfg))
+
+		canQuery, err := p.New(1, usr, allowed).CanQuery(allowed.ID)
+		require.NoError(t, err)
+		assert.True(t, canQuery)
+
+		canQuery, err = p.New(1, usr, denied).CanQuery(denied.ID)
+		require.NoError(t, err)
+		assert.False(t, canQuery)
+
+		filtered, err := p.New(1, usr).FilterDatasourcesByQueryPermissions([]*datasources.DataSource{&allowed, &denied})
+		require.NoError(t, err)
+		assert.Equal(t, []*datasources.DataSource{&allowed}, filtered)
+	})
+
+	t.Run("denies users without permissions", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataSourceEnforceQueryPermissions = true
+		g := ProvideGuardian(cfg, acimpl.ProvideAccessControl(cfg)).New(1, &user.SignedInUser{OrgID: 1}, allowed)
+
+		canQuery, err := g.CanQuery(allowed.ID)
+		require.NoError(t, err)
+		assert.False(t, canQuery)
+	})
+}
@@ -0,0 +1,216 @@
+// Package invalidation invalidates the cached settings of data sources when
+// they are updated or deleted. Invalidations are published to the other Grafana
+// instances of a high availability setup through the database, so that changes
+// of credentials take effect on every instance without waiting for the caches
+// to expire.
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "datasource-cache-invalidation"
+	// eventRetention is how long published invalidations are kept for the other instances to read them.
+	eventRetention = time.Hour
+)
+
+// Event is the invalidation of the cached settings of a data source.
+type Event struct {
+	OrgID int64
+	ID    int64
+	UID   string
+	// Timestamp is the time the data source was updated or deleted at.
+	Timestamp time.Time
+}
+
+// Handler removes the settings of an invalidated data source from a cache.
+type Handler func(ctx context.Context, e Event)
+
+// storedEvent is an invalidation published to the other instances, stored with the UID of the data source as key.
+type storedEvent struct {
+	ID        int64 `json:"id"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+type Service struct {
+	log      log.Logger
+	interval time.Duration
+	kv       kvstore.KVStore
+	now      func() time.Time
+
+	handlersMu sync.RWMutex
+	handlers   []Handler
+
+	// seen is the timestamp of the last invalidation of each data source, by org and UID.
+	seenMu sync.Mutex
+	seen   map[string]int64
+}
+
+func ProvideService(cfg *setting.Cfg, bus bus.Bus, kv kvstore.KVStore, dataSourceCache *service.CacheServiceImpl,
+	pluginContextProvider *plugincontext.Provider) *Service {
+	s := newService(cfg.DataSourceCacheInvalidationInterval, bus, kv)
+	s.OnInvalidate(func(_ context.Context, e Event) {
+		dataSourceCache.InvalidateDatasource(e.OrgID, e.ID, e.UID)
+	})
+	s.OnInvalidate(func(_ context.Context, e Event) {
+		pluginContextProvider.InvalidateDataSourceSettings(e.OrgID, e.UID)
+	})
+	return s
+}
+
+func newService(interval time.Duration, bus bus.Bus, kv kvstore.KVStore) *Service {
+	s := &Service{
+		log:      log.New("datasources.invalidation"),
+		interval: interval,
+		kv:       kv,
+		now:      time.Now,
+		seen:     make(map[string]int64),
+	}
+	bus.AddEventListener(s.handleDataSourceUpdated)
+	bus.AddEventListener(s.handleDataSourceDeleted)
+	return s
+}
+
+// OnInvalidate registers a handler called when the settings of a data source are invalidated,
+// by this instance or by another one.
+func (s *Service) OnInvalidate(handler Handler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// IsDisabled returns true if invalidations are not published to other instances.
+func (s *Service) IsDisabled() bool {
+	return s.interval <= 0
+}
+
+// Run reads the invalidations published by the other instances until the context is canceled.
+func (s *Service) Run(ctx context.Context) error {
+	// Invalidations published before this instance started don't concern its caches.
+	if err := s.poll(ctx, false); err != nil {
+		s.log.Warn("Failed to read data source cache invalidations", "error", err)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.poll(ctx, true); err != nil {
+				s.log.Warn("Failed to read data source cache invalidations", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) handleDataSourceUpdated(ctx context.Context, e *events.DataSourceUpdated) error {
+	s.publish(ctx, Event{OrgID: e.OrgID, ID: e.ID, UID: e.UID, Timestamp: e.Timestamp})
+	return nil
+}
+
+func (s *Service) handleDataSourceDeleted(ctx context.Context, e *events.DataSourceDeleted) error {
+	s.publish(ctx, Event{OrgID: e.OrgID, ID: e.ID, UID: e.UID, Timestamp: e.Timestamp})
+	return nil
+}
+
+// publish invalidates the settings of the data source on this instance, and then on the other ones.
+// Failing to publish the invalidation doesn't fail the change of the data source, the caches of the
+// other instances expire eventually.
+func (s *Service) publish(ctx context.Context, e Event) {
+	s.invalidate(ctx, e)
+
+	if s.IsDisabled() || e.UID == "" {
+		return
+	}
+
+	value, err := json.Marshal(storedEvent{ID: e.ID, Timestamp: e.Timestamp.UnixNano()})
+	if err != nil {
+		s.log.Warn("Failed to encode data source cache invalidation", "uid", e.UID, "error", err)
+		return
+	}
+	s.markSeen(e.OrgID, e.UID, e.Timestamp.UnixNano())
+	if err := s.kv.Set(ctx, e.OrgID, kvNamespace, e.UID, string(value)); err != nil {
+		s.log.Warn("Failed to publish data source cache invalidation", "uid", e.UID, "orgId", e.OrgID, "error", err)
+	}
+}
+
+// poll reads the invalidations published by all instances and applies the ones not seen yet.
+func (s *Service) poll(ctx context.Context, apply bool) error {
+	all, err := s.kv.GetAll(ctx, kvstore.AllOrganizations, kvNamespace)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	present := make(map[string]struct{})
+	for orgID, values := range all {
+		for uid, value := range values {
+			var stored storedEvent
+			if err := json.Unmarshal([]byte(value), &stored); err != nil {
+				s.log.Warn("Ignoring invalid data source cache invalidation", "uid", uid, "orgId", orgID, "error", err)
+				continue
+			}
+			present[seenKey(orgID, uid)] = struct{}{}
+
+			if s.markSeen(orgID, uid, stored.Timestamp) && apply {
+				s.invalidate(ctx, Event{OrgID: orgID, ID: stored.ID, UID: uid, Timestamp: time.Unix(0, stored.Timestamp)})
+			}
+
+			if now.Sub(time.Unix(0, stored.Timestamp)) > eventRetention {
+				if err := s.kv.Del(ctx, orgID, kvNamespace, uid); err != nil {
+					s.log.Warn("Failed to delete expired data source cache invalidation", "uid", uid, "orgId", orgID, "error", err)
+				}
+			}
+		}
+	}
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	for key := range s.seen {
+		if _, ok := present[key]; !ok {
+			delete(s.seen, key)
+		}
+	}
+	return nil
+}
+
+// markSeen records the timestamp of the last invalidation of a data source and returns true if it's a new one.
+func (s *Service) markSeen(orgID int64, uid string, timestamp int64) bool {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	key := seenKey(orgID, uid)
+	if last, ok := s.seen[key]; ok && last == timestamp {
+		return false
+	}
+	s.seen[key] = timestamp
+	return true
+}
+
+func (s *Service) invalidate(ctx context.Context, e Event) {
+	s.log.Debug("Invalidating cached data source settings", "uid", e.UID, "id", e.ID, "orgId", e.OrgID)
+
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+	for _, handler := range s.handlers {
+		handler(ctx, e)
+	}
+}
+
+func seenKey(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s", orgID, uid)
+}
@@ -0,0 +1,97 @@
+package invalidation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type instance struct {
+	bus         *bus.InProcBus
+	service     *Service
+	invalidated []Event
+}
+
+func newInstance(t *testing.T, interval time.Duration, kv kvstore.KVStore) *instance {
+	t.Helper()
+	i := &instance{bus: bus.ProvideBus(tracing.InitializeTracerForTest())}
+	i.service = newService(interval, i.bus, kv)
+	i.service.OnInvalidate(func(_ context.Context, e Event) {
+		i.invalidated = append(i.invalidated, e)
+	})
+	return i
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Now()
+
+	t.Run("invalidations are published to the other instances", func(t *testing.T) {
+		kv := kvstore.NewFakeKVStore()
+		first := newInstance(t, time.Second, kv)
+		second := newInstance(t, time.Second, kv)
+		require.NoError(t, second.service.poll(ctx, false))
+
+		require.NoError(t, first.bus.Publish(ctx, &events.DataSourceUpdated{Timestamp: updated, ID: 1, UID: "uid", OrgID: 2}))
+		require.Equal(t, []Event{{OrgID: 2, ID: 1, UID: "uid", Timestamp: updated}}, first.invalidated)
+		require.Empty(t, second.invalidated)
+
+		require.NoError(t, second.service.poll(ctx, true))
+		require.Len(t, second.invalidated, 1)
+		require.Equal(t, int64(2), second.invalidated[0].OrgID)
+		require.Equal(t, int64(1), second.invalidated[0].ID)
+		require.Equal(t, "uid", second.invalidated[0].UID)
+
+		// Invalidations are applied once, and not again by the instance that published them.
+		require.NoError(t, first.service.poll(ctx, true))
+		require.NoError(t, second.service.poll(ctx, true))
+		require.Len(t, first.invalidated, 1)
+		require.Len(t, second.invalidated, 1)
+
+		require.NoError(t, first.bus.Publish(ctx, &events.DataSourceDeleted{Timestamp: updated.Add(time.Second), ID: 1, UID: "uid", OrgID: 2}))
+		require.NoError(t, second.service.poll(ctx, true))
+		require.Len(t, second.invalidated, 2)
+	})
+
+	t.Run("invalidations published before an instance started are not applied", func(t *testing.T) {
+		kv := kvstore.NewFakeKVStore()
+		first := newInstance(t, time.Second, kv)
+		require.NoError(t, first.bus.Publish(ctx, &events.DataSourceUpdated{Timestamp: updated, ID: 1, UID: "uid", OrgID: 1}))
+
+		second := newInstance(t, time.Second, kv)
+		require.NoError(t, second.service.poll(ctx, false))
+		require.NoError(t, second.service.poll(ctx, true))
+		require.Empty(t, second.invalidated)
+	})
+
+	t.Run("expired invalidations are deleted", func(t *testing.T) {
+		kv := kvstore.NewFakeKVStore()
+		i := newInstance(t, time.Second, kv)
+		require.NoError(t, i.bus.Publish(ctx, &events.DataSourceUpdated{Timestamp: updated, ID: 1, UID: "uid", OrgID: 1}))
+
+		i.service.now = func() time.Time { return updated.Add(eventRetention + time.Minute) }
+		require.NoError(t, i.service.poll(ctx, true))
+		_, found, err := kv.Get(ctx, 1, kvNamespace, "uid")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("invalidations are only applied locally when disabled", func(t *testing.T) {
+		kv := kvstore.NewFakeKVStore()
+		i := newInstance(t, 0, kv)
+		require.True(t, i.service.IsDisabled())
+
+		require.NoError(t, i.bus.Publish(ctx, &events.DataSourceDeleted{Timestamp: updated, ID: 1, UID: "uid", OrgID: 1}))
+		require.Len(t, i.invalidated, 1)
+		all, err := kv.GetAll(ctx, kvstore.AllOrganizations, kvNamespace)
+		require.NoError(t, err)
+		require.Empty(t, all)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -65,6 +66,49 @@ type DataSource struct {
 
 	Created time.Time `json:"created,omitempty"`
 	Updated time.Time `json:"updated,omitempty"`
+
+	// Share is set when the data source was resolved in an org it is shared with rather than in its owning org.
+	Share *DataSourceShare `json:"-" xorm:"-"`
+}
+
+// DataSourceShare shares a data source of the owning org with a consumer org, so
+// that the consumer org can query it without duplicating its credentials.
+type DataSourceShare struct {
+	ID            int64 `json:"-" xorm:"pk autoincr 'id'"`
+	DataSourceID  int64 `json:"dataSourceId" xorm:"data_source_id"`
+	OrgID         int64 `json:"orgId" xorm:"org_id"`
+	ConsumerOrgID int64 `json:"consumerOrgId" xorm:"consumer_org_id"`
+	// MinRole is the minimum role users of the consumer org need to query the data source.
+	MinRole org.RoleType `json:"minRole" xorm:"min_role"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// CanQuery returns true if users of the consumer org with the role can query the shared data source.
+func (s *DataSourceShare) CanQuery(role org.RoleType) bool {
+	return role.Includes(s.MinRole)
+}
+
+// SecureJSONDataVersion is a previous version of the secure JSON data of a data source, kept
+// encrypted so that a change of its credentials can be rolled back.
+type SecureJSONDataVersion struct {
+	ID           int64 `json:"-" xorm:"pk autoincr 'id'"`
+	DataSourceID int64 `json:"-" xorm:"data_source_id"`
+	OrgID        int64 `json:"-" xorm:"org_id"`
+	Version      int64 `json:"version"`
+	// SecureJsonFields are the fields of the secure JSON data set in the version.
+	SecureJsonFields map[string]bool `json:"secureJsonFields" xorm:"secure_json_fields"`
+	// EncryptedSecureJsonData are the encrypted values of the fields.
+	EncryptedSecureJsonData map[string][]byte `json:"-" xorm:"encrypted_secure_json_data"`
+
+	// Replaced is when the version was replaced, and ReplacedBy the user who replaced it.
+	Replaced   time.Time `json:"replaced"`
+	ReplacedBy int64     `json:"replacedBy"`
+}
+
+func (SecureJSONDataVersion) TableName() string {
+	return "data_source_secure_json_data_version"
 }
 
 type TeamHTTPHeadersJSONData struct {
@@ -192,6 +236,8 @@ type UpdateDataSourceCommand struct {
 	EncryptedSecureJsonData map[string][]byte `json:"-"`
 	UpdateSecretFn          UpdateSecretFn    `json:"-"`
 	IgnoreOldSecureJsonData bool              `json:"-"`
+	// UserID is the user updating the data source, recorded in the version of the secure JSON data it replaces.
+	UserID int64 `json:"-"`
 }
 
 // DeleteDataSourceCommand will delete a DataSource based on OrgID as well as the UID (preferred), ID, or Name.
@@ -214,6 +260,32 @@ type DeleteDataSourceCommand struct {
 	SkipPublish bool
 }
 
+// ShareDataSourceCommand shares the data source identified by UID in the owning org with the consumer org,
+// or updates the access policy of the consumer org if the data source is already shared with it.
+type ShareDataSourceCommand struct {
+	ConsumerOrgID int64        `json:"orgId" binding:"Required"`
+	MinRole       org.RoleType `json:"minRole"`
+
+	UID   string `json:"-"`
+	OrgID int64  `json:"-"`
+}
+
+type UnshareDataSourceCommand struct {
+	UID           string
+	OrgID         int64
+	ConsumerOrgID int64
+}
+
+// RestoreSecureJSONDataVersionCommand restores a previous version of the secure JSON data of the
+// data source identified by UID. The replaced secure JSON data is kept as a new version.
+type RestoreSecureJSONDataVersionCommand struct {
+	Version int64 `json:"version" binding:"Required"`
+
+	UID    string `json:"-"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+}
+
 // Function for updating secrets along with datasources, to ensure atomicity
 type UpdateSecretFn func() error
 
@@ -249,6 +321,19 @@ type GetDataSourceQuery struct {
 	OrgID int64
 }
 
+// GetDataSourceSharesQuery gets the orgs the data source identified by UID in the owning org is shared with.
+type GetDataSourceSharesQuery struct {
+	UID   string
+	OrgID int64
+}
+
+// GetSecureJSONDataVersionsQuery gets the previous versions of the secure JSON data of the data source
+// identified by UID.
+type GetSecureJSONDataVersionsQuery struct {
+	UID   string
+	OrgID int64
+}
+
 type DatasourcesPermissionFilterQuery struct {
 	User        *user.SignedInUser
 	Datasources []*DataSource
@@ -0,0 +1,40 @@
+package rotation
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func registerCoreHandlers(s *Service) {
+	s.RegisterHandler(datasources.DS_MYSQL, HandlerFunc(rotateUserPassword))
+	s.RegisterHandler(datasources.DS_MSSQL, HandlerFunc(rotateUserPassword))
+	s.RegisterHandler(datasources.DS_ES, HandlerFunc(rotateBasicAuth))
+}
+
+// rotateUserPassword rotates the credentials of SQL data sources connecting with a user and password.
+func rotateUserPassword(_ context.Context, creds *Credentials, cmd *datasources.UpdateDataSourceCommand) error {
+	if creds.Password == "" {
+		return ErrCredentialsInvalid.Errorf("password is missing")
+	}
+	if creds.User != "" {
+		cmd.User = creds.User
+	}
+	cmd.SecureJsonData["password"] = creds.Password
+	return nil
+}
+
+// rotateBasicAuth rotates the credentials of data sources using basic authentication.
+func rotateBasicAuth(_ context.Context, creds *Credentials, cmd *datasources.UpdateDataSourceCommand) error {
+	if !cmd.BasicAuth {
+		return ErrRotationNotSupported.Errorf("basic authentication is not enabled for the data source")
+	}
+	if creds.Password == "" {
+		return ErrCredentialsInvalid.Errorf("password is missing")
+	}
+	if creds.User != "" {
+		cmd.BasicAuthUser = creds.User
+	}
+	cmd.SecureJsonData["basicAuthPassword"] = creds.Password
+	return nil
+}
@@ -0,0 +1,49 @@
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// FileProvider reads the credentials of data sources from files, such as
+// secrets mounted and renewed by a secrets manager. The credentials of a data
+// source are read from <credentials_path>/<org id>/<data source uid>.json.
+type FileProvider struct {
+	path string
+}
+
+func ProvideFileProvider(cfg *setting.Cfg) *FileProvider {
+	return &FileProvider{path: cfg.DataSourceCredentialsPath}
+}
+
+func (p *FileProvider) GetCredentials(_ context.Context, ds *datasources.DataSource) (*Credentials, error) {
+	if p.path == "" {
+		return nil, ErrCredentialsNotFound.Errorf("no credentials path configured")
+	}
+
+	// The uid is only made of safe characters, but make sure it cannot escape the credentials path.
+	name := filepath.Base(ds.UID) + ".json"
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is made of the configured path, the org id and the uid.
+	data, err := os.ReadFile(filepath.Join(p.path, strconv.FormatInt(ds.OrgID, 10), name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrCredentialsNotFound.Errorf("no credentials file for data source %s", ds.UID)
+		}
+		return nil, err
+	}
+
+	creds := &Credentials{}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, ErrCredentialsInvalid.Errorf("failed to parse credentials of data source %s: %w", ds.UID, err)
+	}
+	return creds, nil
+}
@@ -0,0 +1,285 @@
+// Package rotation rotates the credentials of data sources. Fresh credentials
+// are pulled from a secrets provider, applied to the data source by the
+// handler registered for its type and verified with a health check of the
+// data source before they replace the current credentials.
+package rotation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrRotationNotSupported = errutil.BadRequest("datasources.rotationNotSupported", errutil.WithPublicMessage("Credential rotation is not supported for this data source."))
+	ErrCredentialsNotFound  = errutil.NotFound("datasources.credentialsNotFound", errutil.WithPublicMessage("No credentials found for this data source in the secrets provider."))
+	ErrCredentialsInvalid   = errutil.ValidationFailed("datasources.credentialsInvalid", errutil.WithPublicMessage("The credentials of the secrets provider are invalid for this data source."))
+	ErrVerificationFailed   = errutil.ValidationFailed("datasources.credentialsVerificationFailed", errutil.WithPublicMessage("The data source could not be reached with the new credentials."))
+)
+
+// Credentials are the credentials of a data source pulled from a secrets provider.
+type Credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// SecureJSONData are additional secrets of the data source, such as TLS client keys.
+	SecureJSONData map[string]string `json:"secureJsonData"`
+}
+
+// Provider pulls the current credentials of data sources from a secrets provider.
+type Provider interface {
+	// GetCredentials returns ErrCredentialsNotFound if the provider has no credentials for the data source.
+	GetCredentials(ctx context.Context, ds *datasources.DataSource) (*Credentials, error)
+}
+
+// Handler applies fresh credentials to the settings of data sources of a type.
+type Handler interface {
+	// RotateCredentials applies the credentials to the command updating the data source.
+	RotateCredentials(ctx context.Context, creds *Credentials, cmd *datasources.UpdateDataSourceCommand) error
+}
+
+// HandlerFunc is an adapter to use a function as a Handler.
+type HandlerFunc func(ctx context.Context, creds *Credentials, cmd *datasources.UpdateDataSourceCommand) error
+
+func (f HandlerFunc) RotateCredentials(ctx context.Context, creds *Credentials, cmd *datasources.UpdateDataSourceCommand) error {
+	return f(ctx, creds, cmd)
+}
+
+// Result is the outcome of the rotation of the credentials of a data source.
+type Result struct {
+	OrgID int64  `json:"orgId"`
+	UID   string `json:"uid"`
+	Name  string `json:"name"`
+	// Rotated is false if the credentials were already up to date.
+	Rotated bool   `json:"rotated"`
+	Error   string `json:"error,omitempty"`
+}
+
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type Service struct {
+	log           log.Logger
+	interval      time.Duration
+	dataSources   datasources.DataSourceService
+	provider      Provider
+	pluginContext pluginContextProvider
+	pluginClient  plugins.Client
+
+	handlersMu sync.RWMutex
+	handlers   map[string]Handler
+	// rotateMu prevents scheduled and requested rotations from racing each other.
+	rotateMu sync.Mutex
+}
+
+func ProvideService(cfg *setting.Cfg, dataSources datasources.DataSourceService, provider Provider,
+	pluginContextProvider *plugincontext.Provider, pluginClient plugins.Client) *Service {
+	s := &Service{
+		log:           log.New("datasources.rotation"),
+		interval:      cfg.DataSourceCredentialsRotationInterval,
+		dataSources:   dataSources,
+		provider:      provider,
+		pluginContext: pluginContextProvider,
+		pluginClient:  pluginClient,
+		handlers:      make(map[string]Handler),
+	}
+	registerCoreHandlers(s)
+	return s
+}
+
+// RegisterHandler registers the handler rotating the credentials of data sources of the type.
+func (s *Service) RegisterHandler(dsType string, handler Handler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers[dsType] = handler
+}
+
+func (s *Service) handler(dsType string) (Handler, bool) {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+	h, ok := s.handlers[dsType]
+	return h, ok
+}
+
+// IsDisabled returns true if credentials are not rotated on a schedule.
+func (s *Service) IsDisabled() bool {
+	return s.interval <= 0
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.RotateAll(ctx)
+		}
+	}
+}
+
+// RotateAll rotates the credentials of all the data sources the secrets provider has credentials for.
+func (s *Service) RotateAll(ctx context.Context) []Result {
+	results := make([]Result, 0)
+	dataSources, err := s.dataSources.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		s.log.Error("Failed to get data sources to rotate credentials", "error", err)
+		return results
+	}
+
+	for _, ds := range dataSources {
+		if _, ok := s.handler(ds.Type); !ok {
+			continue
+		}
+		result, err := s.Rotate(ctx, ds)
+		if errors.Is(err, ErrCredentialsNotFound) {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Rotate rotates the credentials of the data source. The credentials are
+// only replaced if the data source can be reached with the new ones.
+func (s *Service) Rotate(ctx context.Context, ds *datasources.DataSource) (Result, error) {
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+
+	result := Result{OrgID: ds.OrgID, UID: ds.UID, Name: ds.Name}
+	rotated, err := s.rotate(ctx, ds)
+	result.Rotated = rotated
+	if err != nil {
+		result.Error = err.Error()
+		if !errors.Is(err, ErrCredentialsNotFound) {
+			s.log.Error("Failed to rotate data source credentials", "orgId", ds.OrgID, "uid", ds.UID, "error", err)
+		}
+		return result, err
+	}
+	if rotated {
+		s.log.Info("Rotated data source credentials", "orgId", ds.OrgID, "uid", ds.UID)
+	}
+	return result, nil
+}
+
+func (s *Service) rotate(ctx context.Context, ds *datasources.DataSource) (bool, error) {
+	handler, ok := s.handler(ds.Type)
+	if !ok {
+		return false, ErrRotationNotSupported.Errorf("no credential rotation handler for data source type %s", ds.Type)
+	}
+
+	creds, err := s.provider.GetCredentials(ctx, ds)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := s.dataSources.DecryptedValues(ctx, ds)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := updateCommand(ds)
+	for k, v := range creds.SecureJSONData {
+		cmd.SecureJsonData[k] = v
+	}
+	if err := handler.RotateCredentials(ctx, creds, cmd); err != nil {
+		return false, err
+	}
+
+	if !changed(ds, current, cmd) {
+		return false, nil
+	}
+
+	if err := s.verify(ctx, ds, current, cmd); err != nil {
+		return false, err
+	}
+
+	if _, err := s.dataSources.UpdateDataSource(ctx, cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verify checks the health of the data source with the settings of the command.
+func (s *Service) verify(ctx context.Context, ds *datasources.DataSource, current map[string]string, cmd *datasources.UpdateDataSourceCommand) error {
+	pCtx, err := s.pluginContext.GetWithDataSource(ctx, ds.Type, nil, ds)
+	if err != nil {
+		return err
+	}
+
+	settings := *pCtx.DataSourceInstanceSettings
+	settings.User = cmd.User
+	settings.BasicAuthUser = cmd.BasicAuthUser
+	settings.DecryptedSecureJSONData = make(map[string]string, len(current))
+	for k, v := range current {
+		settings.DecryptedSecureJSONData[k] = v
+	}
+	for k, v := range cmd.SecureJsonData {
+		settings.DecryptedSecureJSONData[k] = v
+	}
+	// A new update time makes the plugin create a new instance with the new credentials.
+	settings.Updated = time.Now()
+	pCtx.DataSourceInstanceSettings = &settings
+	pCtx.OrgID = ds.OrgID
+
+	res, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pCtx})
+	if err != nil {
+		return ErrVerificationFailed.Errorf("failed to check the health of the data source: %w", err)
+	}
+	if res == nil || res.Status != backend.HealthStatusOk {
+		message := "unknown error"
+		if res != nil {
+			message = res.Message
+		}
+		return ErrVerificationFailed.Errorf("health check of the data source failed: %s", message)
+	}
+	return nil
+}
+
+func updateCommand(ds *datasources.DataSource) *datasources.UpdateDataSourceCommand {
+	return &datasources.UpdateDataSourceCommand{
+		ID:              ds.ID,
+		UID:             ds.UID,
+		OrgID:           ds.OrgID,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		URL:             ds.URL,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JsonData,
+		SecureJsonData:  make(map[string]string),
+		ReadOnly:        ds.ReadOnly,
+		// Fail if the data source is updated concurrently
+		Version: ds.Version,
+	}
+}
+
+func changed(ds *datasources.DataSource, current map[string]string, cmd *datasources.UpdateDataSourceCommand) bool {
+	if cmd.User != ds.User || cmd.BasicAuthUser != ds.BasicAuthUser {
+		return true
+	}
+	for k, v := range cmd.SecureJsonData {
+		if current[k] != v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package rotation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRotate(t *testing.T) {
+	setup := func(t *testing.T, creds *Credentials, healthy bool) (*Service, *fakeDataSources, *datasources.DataSource) {
+		ds := &datasources.DataSource{ID: 1, UID: "mysql", OrgID: 1, Name: "MySQL", Type: datasources.DS_MYSQL, User: "old", Version: 3}
+		dataSources := &fakeDataSources{
+			FakeDataSourceService: fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{ds}},
+			decrypted:             map[string]string{"password": "old"},
+		}
+		client := &clienttest.TestClient{CheckHealthFunc: func(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			settings := req.PluginContext.DataSourceInstanceSettings
+			if !healthy || settings.User != creds.User || settings.DecryptedSecureJSONData["password"] != creds.Password {
+				return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "access denied"}, nil
+			}
+			return &backend.CheckHealthResult{Status: backend.HealthStatusOk}, nil
+		}}
+
+		s := &Service{
+			log:           log.NewNopLogger(),
+			dataSources:   dataSources,
+			provider:      fakeProvider{"mysql": creds},
+			pluginContext: fakePluginContextProvider{},
+			pluginClient:  client,
+			handlers:      make(map[string]Handler),
+		}
+		registerCoreHandlers(s)
+		return s, dataSources, ds
+	}
+
+	t.Run("replaces the credentials once verified", func(t *testing.T) {
+		s, dataSources, ds := setup(t, &Credentials{User: "new", Password: "new"}, true)
+		result, err := s.Rotate(context.Background(), ds)
+		require.NoError(t, err)
+		require.True(t, result.Rotated)
+
+		require.NotNil(t, dataSources.updated)
+		require.Equal(t, "new", dataSources.updated.User)
+		require.Equal(t, "new", dataSources.updated.SecureJsonData["password"])
+		require.Equal(t, ds.Version, dataSources.updated.Version)
+	})
+
+	t.Run("does not replace the credentials if the data source cannot be reached", func(t *testing.T) {
+		s, dataSources, ds := setup(t, &Credentials{User: "new", Password: "new"}, false)
+		result, err := s.Rotate(context.Background(), ds)
+		require.ErrorIs(t, err, ErrVerificationFailed)
+		require.False(t, result.Rotated)
+		require.Contains(t, result.Error, "access denied")
+		require.Nil(t, dataSources.updated)
+	})
+
+	t.Run("does nothing if the credentials are up to date", func(t *testing.T) {
+		s, dataSources, ds := setup(t, &Credentials{User: "old", Password: "old"}, true)
+		result, err := s.Rotate(context.Background(), ds)
+		require.NoError(t, err)
+		require.False(t, result.Rotated)
+		require.Nil(t, dataSources.updated)
+	})
+
+	t.Run("fails for data source types without a handler", func(t *testing.T) {
+		s, _, ds := setup(t, &Credentials{User: "new", Password: "new"}, true)
+		ds.Type = datasources.DS_PROMETHEUS
+		_, err := s.Rotate(context.Background(), ds)
+		require.ErrorIs(t, err, ErrRotationNotSupported)
+	})
+
+	t.Run("rotate all skips data sources without credentials", func(t *testing.T) {
+		s, dataSources, _ := setup(t, &Credentials{User: "new", Password: "new"}, true)
+		dataSources.DataSources = append(dataSources.DataSources,
+			&datasources.DataSource{ID: 2, UID: "other", OrgID: 1, Type: datasources.DS_MSSQL})
+
+		results := s.RotateAll(context.Background())
+		require.Len(t, results, 1)
+		require.Equal(t, "mysql", results[0].UID)
+		require.True(t, results[0].Rotated)
+	})
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "1"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1", "mysql.json"), []byte(`{"user":"grafana","password":"secret"}`), 0600))
+
+	p := ProvideFileProvider(&setting.Cfg{DataSourceCredentialsPath: dir})
+	creds, err := p.GetCredentials(context.Background(), &datasources.DataSource{OrgID: 1, UID: "mysql"})
+	require.NoError(t, err)
+	require.Equal(t, &Credentials{User: "grafana", Password: "secret"}, creds)
+
+	_, err = p.GetCredentials(context.Background(), &datasources.DataSource{OrgID: 2, UID: "mysql"})
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+}
+
+type fakeDataSources struct {
+	fakeDatasources.FakeDataSourceService
+	decrypted map[string]string
+	updated   *datasources.UpdateDataSourceCommand
+}
+
+func (f *fakeDataSources) DecryptedValues(_ context.Context, _ *datasources.DataSource) (map[string]string, error) {
+	return f.decrypted, nil
+}
+
+func (f *fakeDataSources) UpdateDataSource(_ context.Context, cmd *datasources.UpdateDataSourceCommand) (*datasources.DataSource, error) {
+	f.updated = cmd
+	return &datasources.DataSource{}, nil
+}
+
+type fakeProvider map[string]*Credentials
+
+func (f fakeProvider) GetCredentials(_ context.Context, ds *datasources.DataSource) (*Credentials, error) {
+	creds, ok := f[ds.UID]
+	if !ok {
+		return nil, ErrCredentialsNotFound.Errorf("no credentials")
+	}
+	return creds, nil
+}
+
+type fakePluginContextProvider struct{}
+
+func (fakePluginContextProvider) GetWithDataSource(_ context.Context, pluginID string, _ identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	return backend.PluginContext{
+		PluginID: pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			UID:  ds.UID,
+			User: ds.User,
+		},
+	}, nil
+}
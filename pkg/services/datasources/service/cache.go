@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -58,16 +59,18 @@ func (dc *CacheServiceImpl) GetDatasource(
 	dc.logger.FromContext(ctx).Debug("Querying for data source via SQL store", "id", datasourceID, "orgId", user.GetOrgID())
 
 	query := &datasources.GetDataSourceQuery{ID: datasourceID, OrgID: user.GetOrgID()}
-	ss := SqlStore{db: dc.SQLStore, logger: dc.logger}
-	ds, err := ss.GetDataSource(ctx, query)
+	ds, err := dc.getDataSource(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	if ds.UID != "" {
-		dc.CacheService.Set(uidKey(ds.OrgID, ds.UID), ds, time.Second*5)
+	// Data sources shared by other orgs are not cached as the cache is keyed by the owning org.
+	if ds.Share == nil {
+		if ds.UID != "" {
+			dc.CacheService.Set(uidKey(ds.OrgID, ds.UID), ds, time.Second*5)
+		}
+		dc.CacheService.Set(cacheKey, ds, dc.cacheTTL)
 	}
-	dc.CacheService.Set(cacheKey, ds, dc.cacheTTL)
 
 	if err = dc.canQuery(user, ds); err != nil {
 		return nil, err
@@ -104,14 +107,15 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 
 	dc.logger.FromContext(ctx).Debug("Querying for data source via SQL store", "uid", datasourceUID, "orgId", user.GetOrgID())
 	query := &datasources.GetDataSourceQuery{UID: datasourceUID, OrgID: user.GetOrgID()}
-	ss := SqlStore{db: dc.SQLStore, logger: dc.logger}
-	ds, err := ss.GetDataSource(ctx, query)
+	ds, err := dc.getDataSource(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	dc.CacheService.Set(uidCacheKey, ds, dc.cacheTTL)
-	dc.CacheService.Set(idKey(ds.ID), ds, dc.cacheTTL)
+	if ds.Share == nil {
+		dc.CacheService.Set(uidCacheKey, ds, dc.cacheTTL)
+		dc.CacheService.Set(idKey(ds.ID), ds, dc.cacheTTL)
+	}
 
 	if err = dc.canQuery(user, ds); err != nil {
 		return nil, err
@@ -120,6 +124,26 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 	return ds, nil
 }
 
+// getDataSource gets a data source of the org of the query, or a data source shared with it by another org.
+func (dc *CacheServiceImpl) getDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error) {
+	ss := SqlStore{db: dc.SQLStore, logger: dc.logger}
+	ds, err := ss.GetDataSource(ctx, query)
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return ss.GetSharedDataSource(ctx, query)
+	}
+	return ds, err
+}
+
+// InvalidateDatasource removes a data source from the cache, so that it is read again from the SQL store.
+func (dc *CacheServiceImpl) InvalidateDatasource(orgID int64, datasourceID int64, datasourceUID string) {
+	if datasourceID > 0 {
+		dc.CacheService.Delete(idKey(datasourceID))
+	}
+	if datasourceUID != "" {
+		dc.CacheService.Delete(uidKey(orgID, datasourceUID))
+	}
+}
+
 func idKey(id int64) string {
 	return fmt.Sprintf("ds-%d", id)
 }
@@ -129,6 +153,10 @@ func uidKey(orgID int64, uid string) string {
 }
 
 func (dc *CacheServiceImpl) canQuery(user identity.Requester, ds *datasources.DataSource) error {
+	if ds.Share != nil && !ds.Share.CanQuery(user.GetOrgRole()) {
+		return datasources.ErrDataSourceAccessDenied
+	}
+
 	guardian := dc.dsGuardian.New(user.GetOrgID(), user, *ds)
 	if canQuery, err := guardian.CanQuery(ds.ID); err != nil || !canQuery {
 		if err != nil {
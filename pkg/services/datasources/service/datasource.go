@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -163,12 +166,45 @@ func NewIDScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAttr
 	})
 }
 
+// GetDataSource gets a data source of the org of the query, or a data source
+// shared with it by another org if the org has no such data source.
 func (s *Service) GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error) {
-	return s.SQLStore.GetDataSource(ctx, query)
+	ds, err := s.SQLStore.GetDataSource(ctx, query)
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return s.SQLStore.GetSharedDataSource(ctx, query)
+	}
+	return ds, err
 }
 
+// GetDataSources gets the data sources of the org of the query and the ones shared with it by other orgs.
+// When the query has a user, the shared data sources the role of the user cannot query are left out.
 func (s *Service) GetDataSources(ctx context.Context, query *datasources.GetDataSourcesQuery) ([]*datasources.DataSource, error) {
-	return s.SQLStore.GetDataSources(ctx, query)
+	dataSources, err := s.SQLStore.GetDataSources(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := s.SQLStore.GetSharedDataSources(ctx, query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if query.User != nil {
+		shared = slices.DeleteFunc(shared, func(ds *datasources.DataSource) bool {
+			return !ds.Share.CanQuery(query.User.GetOrgRole())
+		})
+	}
+	if len(shared) == 0 {
+		return dataSources, nil
+	}
+
+	dataSources = append(dataSources, shared...)
+	sort.SliceStable(dataSources, func(i, j int) bool {
+		return strings.ToLower(dataSources[i].Name) < strings.ToLower(dataSources[j].Name)
+	})
+	if query.DataSourceLimit > 0 && len(dataSources) > query.DataSourceLimit {
+		dataSources = dataSources[:query.DataSourceLimit]
+	}
+	return dataSources, nil
 }
 
 func (s *Service) GetAllDataSources(ctx context.Context, query *datasources.GetAllDataSourcesQuery) (res []*datasources.DataSource, err error) {
@@ -311,11 +347,17 @@ func (s *Service) UpdateDataSource(ctx context.Context, cmd *datasources.UpdateD
 			}
 		}
 
-		err = s.fillWithSecureJSONData(ctx, cmd, dataSource)
+		previous, err := s.fillWithSecureJSONData(ctx, cmd, dataSource)
 		if err != nil {
 			return err
 		}
 
+		if s.cfg.DataSourceSecureJSONDataVersions > 0 && len(previous) > 0 && secureJSONDataChanged(previous, cmd.SecureJsonData) {
+			if err := s.addSecureJSONDataVersion(ctx, dataSource, previous, cmd.UserID); err != nil {
+				return err
+			}
+		}
+
 		if cmd.OrgID > 0 && cmd.Name != "" {
 			cmd.UpdateSecretFn = func() error {
 				secret, err := json.Marshal(cmd.SecureJsonData)
@@ -339,6 +381,100 @@ func (s *Service) UpdateDataSource(ctx context.Context, cmd *datasources.UpdateD
 	})
 }
 
+func (s *Service) ShareDataSource(ctx context.Context, cmd *datasources.ShareDataSourceCommand) (*datasources.DataSourceShare, error) {
+	if cmd.MinRole == "" {
+		cmd.MinRole = org.RoleViewer
+	}
+	if !cmd.MinRole.IsValid() || cmd.MinRole == org.RoleNone {
+		return nil, datasources.ErrDataSourceShareRoleInvalid
+	}
+	if cmd.ConsumerOrgID == cmd.OrgID {
+		return nil, datasources.ErrDataSourceShareInvalid
+	}
+
+	ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	share := &datasources.DataSourceShare{
+		DataSourceID:  ds.ID,
+		OrgID:         ds.OrgID,
+		ConsumerOrgID: cmd.ConsumerOrgID,
+		MinRole:       cmd.MinRole,
+	}
+	if err := s.SQLStore.ShareDataSource(ctx, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func (s *Service) UnshareDataSource(ctx context.Context, cmd *datasources.UnshareDataSourceCommand) error {
+	ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return err
+	}
+	return s.SQLStore.DeleteDataSourceShare(ctx, ds.ID, cmd.ConsumerOrgID)
+}
+
+func (s *Service) GetDataSourceShares(ctx context.Context, query *datasources.GetDataSourceSharesQuery) ([]*datasources.DataSourceShare, error) {
+	ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: query.UID, OrgID: query.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	return s.SQLStore.GetDataSourceShares(ctx, ds.ID)
+}
+
+func (s *Service) GetSecureJSONDataVersions(ctx context.Context, query *datasources.GetSecureJSONDataVersionsQuery) ([]*datasources.SecureJSONDataVersion, error) {
+	ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: query.UID, OrgID: query.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	return s.SQLStore.GetSecureJSONDataVersions(ctx, ds.ID)
+}
+
+func (s *Service) RestoreSecureJSONDataVersion(ctx context.Context, cmd *datasources.RestoreSecureJSONDataVersionCommand) (*datasources.DataSource, error) {
+	ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, datasources.ErrDatasourceIsReadOnly
+	}
+
+	version, err := s.SQLStore.GetSecureJSONDataVersion(ctx, ds.ID, cmd.Version)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.SecretsService.DecryptJsonData(ctx, version.EncryptedSecureJsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UpdateDataSource(ctx, &datasources.UpdateDataSourceCommand{
+		ID:              ds.ID,
+		UID:             ds.UID,
+		OrgID:           ds.OrgID,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		URL:             ds.URL,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JsonData,
+		SecureJsonData:  values,
+		// Fields added after the version are removed
+		IgnoreOldSecureJsonData: true,
+		UserID:                  cmd.UserID,
+		// Fail if the data source is updated concurrently
+		Version: ds.Version,
+	})
+}
+
 func (s *Service) GetDefaultDataSource(ctx context.Context, query *datasources.GetDefaultDataSourceQuery) (*datasources.DataSource, error) {
 	return s.SQLStore.GetDefaultDataSource(ctx, query)
 }
@@ -701,10 +837,12 @@ func awsServiceNamespace(dsType string, jsonData *simplejson.Json) string {
 	}
 }
 
-func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *datasources.UpdateDataSourceCommand, ds *datasources.DataSource) error {
+// fillWithSecureJSONData completes the secure json data of the command with the current values of
+// the data source, encrypts it, and returns the current values.
+func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *datasources.UpdateDataSourceCommand, ds *datasources.DataSource) (map[string]string, error) {
 	decrypted, err := s.DecryptedValues(ctx, ds)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if cmd.SecureJsonData == nil {
@@ -723,11 +861,46 @@ func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *datasources.U
 	if !s.features.IsEnabled(ctx, featuremgmt.FlagDisableSecretsCompatibility) {
 		cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return decrypted, nil
+}
+
+// addSecureJSONDataVersion keeps the secure json data replaced by an update of the data source as
+// its latest version.
+func (s *Service) addSecureJSONDataVersion(ctx context.Context, ds *datasources.DataSource, values map[string]string, userID int64) error {
+	encrypted, err := s.SecretsService.EncryptJsonData(ctx, values, secrets.WithoutScope())
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]bool, len(values))
+	for k := range values {
+		fields[k] = true
+	}
+
+	return s.SQLStore.AddSecureJSONDataVersion(ctx, &datasources.SecureJSONDataVersion{
+		DataSourceID:            ds.ID,
+		OrgID:                   ds.OrgID,
+		SecureJsonFields:        fields,
+		EncryptedSecureJsonData: encrypted,
+		Replaced:                time.Now(),
+		ReplacedBy:              userID,
+	}, s.cfg.DataSourceSecureJSONDataVersions)
+}
+
+func secureJSONDataChanged(previous, current map[string]string) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	for k, v := range previous {
+		if cv, ok := current[k]; !ok || cv != v {
+			return true
+		}
+	}
+	return false
 }
 
 func validateFields(name, url string) error {
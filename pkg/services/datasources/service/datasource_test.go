@@ -24,12 +24,14 @@ import (
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -359,6 +361,166 @@ func TestService_UpdateDataSource(t *testing.T) {
 	})
 }
 
+func TestService_ShareDataSource(t *testing.T) {
+	cfg := &setting.Cfg{}
+
+	setup := func(t *testing.T) (*Service, *datasources.DataSource) {
+		sqlStore := db.InitTestDB(t)
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&org.Org{ID: 2, Name: "consumer", Created: time.Now(), Updated: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		secretsService := secretsmng.SetupTestService(t, fakes.NewFakeSecretsStore())
+		secretsStore := secretskvs.NewSQLSecretsKVStore(sqlStore, secretsService, log.New("test.logger"))
+		quotaService := quotatest.New(false, nil)
+		mockPermission := acmock.NewMockedPermissionsService()
+		mockPermission.On("SetPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]accesscontrol.ResourcePermission{}, nil)
+		dsService, err := ProvideService(sqlStore, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), actest.FakeAccessControl{}, mockPermission, quotaService, &pluginstore.FakePluginStore{})
+		require.NoError(t, err)
+
+		ds, err := dsService.AddDataSource(context.Background(), &datasources.AddDataSourceCommand{
+			OrgID:  1,
+			Name:   "shared",
+			Type:   datasources.DS_PROMETHEUS,
+			Access: datasources.DS_ACCESS_PROXY,
+			URL:    "http://localhost:9090",
+		})
+		require.NoError(t, err)
+		return dsService, ds
+	}
+
+	t.Run("should default to viewer and reject invalid policies", func(t *testing.T) {
+		dsService, ds := setup(t)
+
+		_, err := dsService.ShareDataSource(context.Background(), &datasources.ShareDataSourceCommand{UID: ds.UID, OrgID: 1, ConsumerOrgID: 1})
+		require.ErrorIs(t, err, datasources.ErrDataSourceShareInvalid)
+
+		_, err = dsService.ShareDataSource(context.Background(), &datasources.ShareDataSourceCommand{UID: ds.UID, OrgID: 1, ConsumerOrgID: 2, MinRole: org.RoleNone})
+		require.ErrorIs(t, err, datasources.ErrDataSourceShareRoleInvalid)
+
+		share, err := dsService.ShareDataSource(context.Background(), &datasources.ShareDataSourceCommand{UID: ds.UID, OrgID: 1, ConsumerOrgID: 2})
+		require.NoError(t, err)
+		require.Equal(t, org.RoleViewer, share.MinRole)
+	})
+
+	t.Run("should resolve shared data sources in the consumer org", func(t *testing.T) {
+		dsService, ds := setup(t)
+		_, err := dsService.ShareDataSource(context.Background(), &datasources.ShareDataSourceCommand{UID: ds.UID, OrgID: 1, ConsumerOrgID: 2, MinRole: org.RoleEditor})
+		require.NoError(t, err)
+
+		shared, err := dsService.GetDataSource(context.Background(), &datasources.GetDataSourceQuery{UID: ds.UID, OrgID: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), shared.OrgID)
+		require.False(t, shared.Share.CanQuery(org.RoleViewer))
+		require.True(t, shared.Share.CanQuery(org.RoleAdmin))
+
+		list, err := dsService.GetDataSources(context.Background(), &datasources.GetDataSourcesQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+
+		list, err = dsService.GetDataSources(context.Background(), &datasources.GetDataSourcesQuery{OrgID: 2, User: &user.SignedInUser{OrgID: 2, OrgRole: org.RoleViewer}})
+		require.NoError(t, err)
+		require.Empty(t, list)
+		list, err = dsService.GetDataSources(context.Background(), &datasources.GetDataSourcesQuery{OrgID: 2, User: &user.SignedInUser{OrgID: 2, OrgRole: org.RoleEditor}})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+
+		err = dsService.UnshareDataSource(context.Background(), &datasources.UnshareDataSourceCommand{UID: ds.UID, OrgID: 1, ConsumerOrgID: 2})
+		require.NoError(t, err)
+		_, err = dsService.GetDataSource(context.Background(), &datasources.GetDataSourceQuery{UID: ds.UID, OrgID: 2})
+		require.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+	})
+}
+
+func TestService_SecureJSONDataVersions(t *testing.T) {
+	ctx := context.Background()
+	cfg := &setting.Cfg{DataSourceSecureJSONDataVersions: 2}
+
+	sqlStore := db.InitTestDB(t)
+	secretsService := secretsmng.SetupTestService(t, fakes.NewFakeSecretsStore())
+	secretsStore := secretskvs.NewSQLSecretsKVStore(sqlStore, secretsService, log.New("test.logger"))
+	quotaService := quotatest.New(false, nil)
+	mockPermission := acmock.NewMockedPermissionsService()
+	mockPermission.On("SetPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]accesscontrol.ResourcePermission{}, nil)
+	dsService, err := ProvideService(sqlStore, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), actest.FakeAccessControl{}, mockPermission, quotaService, &pluginstore.FakePluginStore{})
+	require.NoError(t, err)
+
+	ds, err := dsService.AddDataSource(ctx, &datasources.AddDataSourceCommand{
+		OrgID:          1,
+		Name:           "versioned",
+		Type:           datasources.DS_MYSQL,
+		Access:         datasources.DS_ACCESS_PROXY,
+		SecureJsonData: map[string]string{"password": "v1"},
+	})
+	require.NoError(t, err)
+
+	update := func(t *testing.T, secureJsonData map[string]string) {
+		t.Helper()
+		ds, err = dsService.UpdateDataSource(ctx, &datasources.UpdateDataSourceCommand{
+			ID: ds.ID, UID: ds.UID, OrgID: 1, Name: ds.Name, Type: ds.Type, Access: ds.Access, Version: ds.Version,
+			SecureJsonData: secureJsonData,
+			UserID:         10,
+		})
+		require.NoError(t, err)
+	}
+	versions := func(t *testing.T) []*datasources.SecureJSONDataVersion {
+		t.Helper()
+		versions, err := dsService.GetSecureJSONDataVersions(ctx, &datasources.GetSecureJSONDataVersionsQuery{UID: ds.UID, OrgID: 1})
+		require.NoError(t, err)
+		return versions
+	}
+
+	t.Run("should keep the replaced secure json data when it changes", func(t *testing.T) {
+		update(t, map[string]string{})
+		require.Empty(t, versions(t))
+
+		update(t, map[string]string{"password": "v2", "tlsClientKey": "key"})
+		got := versions(t)
+		require.Len(t, got, 1)
+		assert.Equal(t, int64(1), got[0].Version)
+		assert.Equal(t, map[string]bool{"password": true}, got[0].SecureJsonFields)
+		assert.Equal(t, int64(10), got[0].ReplacedBy)
+	})
+
+	t.Run("should only keep the latest versions", func(t *testing.T) {
+		update(t, map[string]string{"password": "v3"})
+		update(t, map[string]string{"password": "v4"})
+
+		got := versions(t)
+		require.Len(t, got, 2)
+		assert.Equal(t, int64(3), got[0].Version)
+		assert.Equal(t, int64(2), got[1].Version)
+	})
+
+	t.Run("should restore a version and keep the replaced secure json data", func(t *testing.T) {
+		restored, err := dsService.RestoreSecureJSONDataVersion(ctx, &datasources.RestoreSecureJSONDataVersionCommand{UID: ds.UID, OrgID: 1, Version: 2, UserID: 20})
+		require.NoError(t, err)
+
+		values, err := dsService.DecryptedValues(ctx, restored)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "v2", "tlsClientKey": "key"}, values)
+
+		got := versions(t)
+		require.Len(t, got, 2)
+		assert.Equal(t, int64(4), got[0].Version)
+		assert.Equal(t, int64(20), got[0].ReplacedBy)
+
+		_, err = dsService.RestoreSecureJSONDataVersion(ctx, &datasources.RestoreSecureJSONDataVersionCommand{UID: ds.UID, OrgID: 1, Version: 1})
+		require.ErrorIs(t, err, datasources.ErrSecureJSONDataVersionNotFound)
+	})
+
+	t.Run("should delete the versions with the data source", func(t *testing.T) {
+		err := dsService.DeleteDataSource(ctx, &datasources.DeleteDataSourceCommand{UID: ds.UID, OrgID: 1, Name: ds.Name})
+		require.NoError(t, err)
+
+		stored, err := dsService.SQLStore.GetSecureJSONDataVersions(ctx, ds.ID)
+		require.NoError(t, err)
+		require.Empty(t, stored)
+	})
+}
+
 func TestService_NameScopeResolver(t *testing.T) {
 	retriever := &dataSourceMockRetriever{[]*datasources.DataSource{
 		{Name: "test-datasource", UID: "1"},
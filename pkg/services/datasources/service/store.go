@@ -31,6 +31,14 @@ type Store interface {
 	AddDataSource(context.Context, *datasources.AddDataSourceCommand) (*datasources.DataSource, error)
 	UpdateDataSource(context.Context, *datasources.UpdateDataSourceCommand) (*datasources.DataSource, error)
 	GetAllDataSources(ctx context.Context, query *datasources.GetAllDataSourcesQuery) (res []*datasources.DataSource, err error)
+	GetSharedDataSource(context.Context, *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+	GetSharedDataSources(ctx context.Context, consumerOrgID int64) ([]*datasources.DataSource, error)
+	GetDataSourceShares(ctx context.Context, dataSourceID int64) ([]*datasources.DataSourceShare, error)
+	ShareDataSource(context.Context, *datasources.DataSourceShare) error
+	DeleteDataSourceShare(ctx context.Context, dataSourceID, consumerOrgID int64) error
+	AddSecureJSONDataVersion(ctx context.Context, version *datasources.SecureJSONDataVersion, keep int) error
+	GetSecureJSONDataVersions(ctx context.Context, dataSourceID int64) ([]*datasources.SecureJSONDataVersion, error)
+	GetSecureJSONDataVersion(ctx context.Context, dataSourceID, version int64) (*datasources.SecureJSONDataVersion, error)
 
 	Count(context.Context, *quota.ScopeParameters) (*quota.Map, error)
 }
@@ -159,6 +167,16 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 
 			cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
 
+			// Stop sharing the data source with other orgs
+			if _, err := sess.Exec("DELETE FROM data_source_share WHERE data_source_id=?", ds.ID); err != nil {
+				return err
+			}
+
+			// Delete the previous versions of its secure json data
+			if _, err := sess.Exec("DELETE FROM data_source_secure_json_data_version WHERE data_source_id=?", ds.ID); err != nil {
+				return err
+			}
+
 			// Remove associated AccessControl permissions
 			if _, errDeletingPerms := sess.Exec("DELETE FROM permission WHERE scope=?",
 				ac.Scope(datasources.ScopeProvider.GetResourceScope(ds.UID))); errDeletingPerms != nil {
@@ -188,6 +206,175 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 	})
 }
 
+// GetSharedDataSource gets a data source shared with the org of the query by
+// its owning org, by either uid (preferred), id, or name.
+func (ss *SqlStore) GetSharedDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error) {
+	if query.OrgID == 0 || (query.ID == 0 && len(query.Name) == 0 && len(query.UID) == 0) {
+		return nil, datasources.ErrDataSourceIdentifierNotSet
+	}
+
+	var dataSource *datasources.DataSource
+	return dataSource, ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		share := datasources.DataSourceShare{}
+		filter := "data_source_share.consumer_org_id = ?"
+		args := []any{query.OrgID}
+		switch {
+		case query.UID != "":
+			filter += " AND data_source.uid = ?"
+			args = append(args, query.UID)
+		case query.ID != 0:
+			filter += " AND data_source.id = ?"
+			args = append(args, query.ID)
+		default:
+			filter += " AND data_source.name = ?"
+			args = append(args, query.Name)
+		}
+
+		has, err := sess.SQL("SELECT data_source_share.* FROM data_source_share "+
+			"INNER JOIN data_source ON data_source.id = data_source_share.data_source_id WHERE "+filter, args...).Get(&share)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return datasources.ErrDataSourceNotFound
+		}
+
+		dataSource = &datasources.DataSource{ID: share.DataSourceID, OrgID: share.OrgID}
+		if has, err := sess.Get(dataSource); err != nil {
+			return err
+		} else if !has {
+			return datasources.ErrDataSourceNotFound
+		}
+		dataSource.Share = &share
+		return nil
+	})
+}
+
+// GetSharedDataSources gets the data sources shared with the consumer org by their owning orgs.
+func (ss *SqlStore) GetSharedDataSources(ctx context.Context, consumerOrgID int64) ([]*datasources.DataSource, error) {
+	dataSources := make([]*datasources.DataSource, 0)
+	return dataSources, ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		shares := make([]*datasources.DataSourceShare, 0)
+		if err := sess.Where("consumer_org_id = ?", consumerOrgID).Find(&shares); err != nil {
+			return err
+		}
+		if len(shares) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(shares))
+		sharesByID := make(map[int64]*datasources.DataSourceShare, len(shares))
+		for _, share := range shares {
+			ids = append(ids, share.DataSourceID)
+			sharesByID[share.DataSourceID] = share
+		}
+		if err := sess.In("id", ids).Asc("name").Find(&dataSources); err != nil {
+			return err
+		}
+		for _, ds := range dataSources {
+			ds.Share = sharesByID[ds.ID]
+		}
+		return nil
+	})
+}
+
+// GetDataSourceShares gets the orgs the data source is shared with.
+func (ss *SqlStore) GetDataSourceShares(ctx context.Context, dataSourceID int64) ([]*datasources.DataSourceShare, error) {
+	shares := make([]*datasources.DataSourceShare, 0)
+	return shares, ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("data_source_id = ?", dataSourceID).Asc("consumer_org_id").Find(&shares)
+	})
+}
+
+// ShareDataSource shares the data source with the consumer org, or updates the
+// access policy of the consumer org if the data source is already shared with it.
+func (ss *SqlStore) ShareDataSource(ctx context.Context, share *datasources.DataSourceShare) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if has, err := sess.Table("org").Where("id = ?", share.ConsumerOrgID).Exist(); err != nil {
+			return err
+		} else if !has {
+			return datasources.ErrDataSourceShareInvalid
+		}
+
+		existing := datasources.DataSourceShare{}
+		has, err := sess.Where("data_source_id = ? AND consumer_org_id = ?", share.DataSourceID, share.ConsumerOrgID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		share.Updated = time.Now()
+		if has {
+			share.ID = existing.ID
+			share.Created = existing.Created
+			_, err := sess.ID(existing.ID).Cols("min_role", "updated").Update(share)
+			return err
+		}
+
+		share.Created = share.Updated
+		_, err = sess.Insert(share)
+		return err
+	})
+}
+
+// DeleteDataSourceShare stops sharing the data source with the consumer org.
+func (ss *SqlStore) DeleteDataSourceShare(ctx context.Context, dataSourceID, consumerOrgID int64) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("DELETE FROM data_source_share WHERE data_source_id = ? AND consumer_org_id = ?", dataSourceID, consumerOrgID)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return datasources.ErrDataSourceShareNotFound
+		}
+		return nil
+	})
+}
+
+// AddSecureJSONDataVersion adds the next version of the secure json data of the data source, and
+// deletes the oldest versions so that only the latest keep versions are kept.
+func (ss *SqlStore) AddSecureJSONDataVersion(ctx context.Context, version *datasources.SecureJSONDataVersion, keep int) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var latest int64
+		if _, err := sess.SQL("SELECT COALESCE(MAX(version), 0) FROM data_source_secure_json_data_version WHERE data_source_id = ?", version.DataSourceID).Get(&latest); err != nil {
+			return err
+		}
+
+		version.Version = latest + 1
+		if _, err := sess.Insert(version); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM data_source_secure_json_data_version WHERE data_source_id = ? AND version <= ?", version.DataSourceID, version.Version-int64(keep))
+		return err
+	})
+}
+
+// GetSecureJSONDataVersions gets the versions of the secure json data of the data source, the latest first.
+func (ss *SqlStore) GetSecureJSONDataVersions(ctx context.Context, dataSourceID int64) ([]*datasources.SecureJSONDataVersion, error) {
+	versions := make([]*datasources.SecureJSONDataVersion, 0)
+	return versions, ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("data_source_id = ?", dataSourceID).Desc("version").Find(&versions)
+	})
+}
+
+func (ss *SqlStore) GetSecureJSONDataVersion(ctx context.Context, dataSourceID, version int64) (*datasources.SecureJSONDataVersion, error) {
+	result := &datasources.SecureJSONDataVersion{}
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("data_source_id = ? AND version = ?", dataSourceID, version).Get(result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return datasources.ErrSecureJSONDataVersionNotFound.Errorf("data source %d has no version %d of its secure json data", dataSourceID, version)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (ss *SqlStore) Count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
 	u := &quota.Map{}
 	type result struct {
@@ -392,6 +579,16 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 			logDeprecatedInvalidDsUid(ss.logger, cmd.UID, cmd.Name)
 		}
 
+		if err == nil {
+			sess.PublishAfterCommit(&events.DataSourceUpdated{
+				Timestamp: ds.Updated,
+				Name:      ds.Name,
+				ID:        ds.ID,
+				UID:       ds.UID,
+				OrgID:     ds.OrgID,
+			})
+		}
+
 		return err
 	})
 }
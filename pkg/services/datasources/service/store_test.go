@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
 )
 
 func TestIntegrationDataAccess(t *testing.T) {
@@ -550,3 +551,89 @@ func TestIntegrationGetDefaultDataSource(t *testing.T) {
 		assert.True(t, errors.Is(err, datasources.ErrDataSourceNotFound))
 	})
 }
+
+func TestIntegrationDataSourceShares(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	setup := func(t *testing.T) (*SqlStore, *datasources.DataSource) {
+		store := db.InitTestDB(t)
+		ss := &SqlStore{db: store}
+		err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&org.Org{ID: 20, Name: "consumer", Created: time.Now(), Updated: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		ds, err := ss.AddDataSource(context.Background(), &datasources.AddDataSourceCommand{
+			OrgID:  10,
+			Name:   "shared",
+			Type:   datasources.DS_GRAPHITE,
+			Access: datasources.DS_ACCESS_PROXY,
+			URL:    "http://test",
+		})
+		require.NoError(t, err)
+		return ss, ds
+	}
+
+	t.Run("shared data source can be resolved by the consumer org only", func(t *testing.T) {
+		ss, ds := setup(t)
+		err := ss.ShareDataSource(context.Background(), &datasources.DataSourceShare{
+			DataSourceID: ds.ID, OrgID: 10, ConsumerOrgID: 20, MinRole: org.RoleEditor,
+		})
+		require.NoError(t, err)
+
+		shared, err := ss.GetSharedDataSource(context.Background(), &datasources.GetDataSourceQuery{UID: ds.UID, OrgID: 20})
+		require.NoError(t, err)
+		require.Equal(t, ds.ID, shared.ID)
+		require.Equal(t, int64(10), shared.OrgID)
+		require.Equal(t, org.RoleEditor, shared.Share.MinRole)
+
+		_, err = ss.GetSharedDataSource(context.Background(), &datasources.GetDataSourceQuery{UID: ds.UID, OrgID: 30})
+		require.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+
+		list, err := ss.GetSharedDataSources(context.Background(), 20)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Equal(t, ds.UID, list[0].UID)
+	})
+
+	t.Run("sharing again updates the access policy", func(t *testing.T) {
+		ss, ds := setup(t)
+		for _, role := range []org.RoleType{org.RoleViewer, org.RoleAdmin} {
+			err := ss.ShareDataSource(context.Background(), &datasources.DataSourceShare{
+				DataSourceID: ds.ID, OrgID: 10, ConsumerOrgID: 20, MinRole: role,
+			})
+			require.NoError(t, err)
+		}
+
+		shares, err := ss.GetDataSourceShares(context.Background(), ds.ID)
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+		require.Equal(t, org.RoleAdmin, shares[0].MinRole)
+	})
+
+	t.Run("cannot share with an org that does not exist", func(t *testing.T) {
+		ss, ds := setup(t)
+		err := ss.ShareDataSource(context.Background(), &datasources.DataSourceShare{
+			DataSourceID: ds.ID, OrgID: 10, ConsumerOrgID: 30, MinRole: org.RoleViewer,
+		})
+		require.ErrorIs(t, err, datasources.ErrDataSourceShareInvalid)
+	})
+
+	t.Run("unsharing and deleting the data source remove the share", func(t *testing.T) {
+		ss, ds := setup(t)
+		share := &datasources.DataSourceShare{DataSourceID: ds.ID, OrgID: 10, ConsumerOrgID: 20, MinRole: org.RoleViewer}
+		require.NoError(t, ss.ShareDataSource(context.Background(), share))
+		require.NoError(t, ss.DeleteDataSourceShare(context.Background(), ds.ID, 20))
+		require.ErrorIs(t, ss.DeleteDataSourceShare(context.Background(), ds.ID, 20), datasources.ErrDataSourceShareNotFound)
+
+		share.ID = 0
+		require.NoError(t, ss.ShareDataSource(context.Background(), share))
+		require.NoError(t, ss.DeleteDataSource(context.Background(), &datasources.DeleteDataSourceCommand{ID: ds.ID, OrgID: 10}))
+		shares, err := ss.GetDataSourceShares(context.Background(), ds.ID)
+		require.NoError(t, err)
+		require.Empty(t, shares)
+	})
+}
@@ -0,0 +1,6 @@
+package diagnostics
+
+const (
+	// ActionProfilesCreate allows capturing profiles of the server.
+	ActionProfilesCreate = "diagnostics.profiles:create"
+)
@@ -0,0 +1,269 @@
+// Package diagnostics captures profiles of the running server on demand, so
+// that the server can be debugged in production where the pprof port is not
+// reachable.
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	// ErrDisabled is returned when the capture of profiles is disabled.
+	ErrDisabled = errors.New("profile capture is disabled")
+	// ErrInvalidProfile is returned when the options of a capture are invalid.
+	ErrInvalidProfile = errors.New("invalid profile capture")
+	// ErrCaptureInProgress is returned when a capture is already in progress.
+	ErrCaptureInProgress = errors.New("a profile capture is already in progress")
+	// ErrRateLimited is returned when profiles were captured too recently.
+	ErrRateLimited = errors.New("profiles were captured too recently")
+)
+
+// Profile is the kind of a captured profile.
+type Profile string
+
+const (
+	ProfileCPU       Profile = "cpu"
+	ProfileHeap      Profile = "heap"
+	ProfileGoroutine Profile = "goroutine"
+	ProfileMutex     Profile = "mutex"
+)
+
+// DefaultProfiles are the profiles captured when none are requested.
+var DefaultProfiles = []Profile{ProfileCPU, ProfileHeap, ProfileGoroutine, ProfileMutex}
+
+const (
+	defaultDuration    = 30 * time.Second
+	defaultMaxDuration = time.Minute
+	defaultMinInterval = time.Minute
+	// mutexProfileFraction is the rate of the mutex contention events reported
+	// while the mutex profile is captured.
+	mutexProfileFraction = 5
+)
+
+// CaptureOptions are the options of a profile capture.
+type CaptureOptions struct {
+	// Duration of the CPU and mutex profiles, 30s by default.
+	Duration time.Duration
+	// Profiles to capture, all of them by default.
+	Profiles []Profile
+}
+
+// Service captures profiles, one capture at a time and at most one every
+// configured interval.
+type Service struct {
+	log         log.Logger
+	enabled     bool
+	maxDuration time.Duration
+	version     string
+	limiter     *rate.Limiter
+
+	// capturing is held while profiles are captured.
+	capturing sync.Mutex
+}
+
+func ProvideService(cfg *setting.Cfg) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("diagnostics")
+	s := &Service{
+		log:         log.New("diagnostics"),
+		enabled:     section.Key("profiling_enabled").MustBool(true),
+		maxDuration: section.Key("profiling_max_duration").MustDuration(defaultMaxDuration),
+		version:     cfg.BuildVersion,
+	}
+
+	minInterval := section.Key("profiling_min_interval").MustDuration(defaultMinInterval)
+	if s.maxDuration <= 0 {
+		return nil, fmt.Errorf("[diagnostics] profiling_max_duration must be positive")
+	}
+	if minInterval < 0 {
+		return nil, fmt.Errorf("[diagnostics] profiling_min_interval can not be negative")
+	}
+	s.limiter = rate.NewLimiter(rate.Every(minInterval), 1)
+	if minInterval == 0 {
+		s.limiter.SetLimit(rate.Inf)
+	}
+	return s, nil
+}
+
+// Capture captures the profiles of the server and returns them in a gzipped
+// tarball, along with a metadata.json file describing the capture.
+func (s *Service) Capture(ctx context.Context, opts CaptureOptions) ([]byte, error) {
+	if !s.enabled {
+		return nil, ErrDisabled
+	}
+
+	opts, err := s.validate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.capturing.TryLock() {
+		return nil, ErrCaptureInProgress
+	}
+	defer s.capturing.Unlock()
+	if !s.limiter.Allow() {
+		return nil, ErrRateLimited
+	}
+
+	s.log.Info("Capturing profiles", "profiles", opts.Profiles, "duration", opts.Duration)
+	profiles, err := capture(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := json.MarshalIndent(struct {
+		Version    string    `json:"version"`
+		CapturedAt time.Time `json:"capturedAt"`
+		Duration   string    `json:"duration"`
+		Profiles   []Profile `json:"profiles"`
+		NumCPU     int       `json:"numCPU"`
+		GOMAXPROCS int       `json:"gomaxprocs"`
+	}{
+		Version:    s.version,
+		CapturedAt: time.Now().UTC(),
+		Duration:   opts.Duration.String(),
+		Profiles:   opts.Profiles,
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{"metadata.json": metadata}
+	for profile, data := range profiles {
+		files[string(profile)+".pprof"] = data
+	}
+	return archive(files)
+}
+
+func (s *Service) validate(opts CaptureOptions) (CaptureOptions, error) {
+	if opts.Duration == 0 {
+		opts.Duration = defaultDuration
+		if opts.Duration > s.maxDuration {
+			opts.Duration = s.maxDuration
+		}
+	}
+	if opts.Duration < time.Second || opts.Duration > s.maxDuration {
+		return opts, fmt.Errorf("%w: duration must be between 1s and %s", ErrInvalidProfile, s.maxDuration)
+	}
+
+	if len(opts.Profiles) == 0 {
+		opts.Profiles = DefaultProfiles
+	}
+	seen := make(map[Profile]bool, len(opts.Profiles))
+	for _, profile := range opts.Profiles {
+		switch profile {
+		case ProfileCPU, ProfileHeap, ProfileGoroutine, ProfileMutex:
+		default:
+			return opts, fmt.Errorf("%w: unknown profile %q, expected one of cpu, heap, goroutine or mutex", ErrInvalidProfile, profile)
+		}
+		if seen[profile] {
+			return opts, fmt.Errorf("%w: profile %q is requested more than once", ErrInvalidProfile, profile)
+		}
+		seen[profile] = true
+	}
+	return opts, nil
+}
+
+// capture captures the CPU and mutex profiles over the duration of the
+// capture, and the snapshot profiles once it is over.
+func capture(ctx context.Context, opts CaptureOptions) (map[Profile][]byte, error) {
+	requested := make(map[Profile]bool, len(opts.Profiles))
+	for _, profile := range opts.Profiles {
+		requested[profile] = true
+	}
+
+	var cpu bytes.Buffer
+	if requested[ProfileCPU] {
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCaptureInProgress, err)
+		}
+	}
+	if requested[ProfileMutex] {
+		previous := runtime.SetMutexProfileFraction(mutexProfileFraction)
+		defer runtime.SetMutexProfileFraction(previous)
+	}
+
+	timer := time.NewTimer(opts.Duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	if requested[ProfileCPU] {
+		pprof.StopCPUProfile()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[Profile][]byte, len(opts.Profiles))
+	if requested[ProfileCPU] {
+		profiles[ProfileCPU] = cpu.Bytes()
+	}
+	for _, profile := range []Profile{ProfileHeap, ProfileGoroutine, ProfileMutex} {
+		if !requested[profile] {
+			continue
+		}
+		if profile == ProfileHeap {
+			// The heap profile reports the allocations as of the last garbage collection.
+			runtime.GC()
+		}
+		var buf bytes.Buffer
+		if err := pprof.Lookup(string(profile)).WriteTo(&buf, 0); err != nil {
+			return nil, fmt.Errorf("failed to write %s profile: %w", profile, err)
+		}
+		profiles[profile] = buf.Bytes()
+	}
+	return profiles, nil
+}
+
+func archive(files map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		data := files[name]
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
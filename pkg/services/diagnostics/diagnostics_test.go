@@ -0,0 +1,119 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T, settings map[string]string) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.BuildVersion = "10.3.0"
+	for key, value := range settings {
+		_, err := cfg.Raw.Section("diagnostics").NewKey(key, value)
+		require.NoError(t, err)
+	}
+	s, err := ProvideService(cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = content
+	}
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("captures the requested profiles in a tarball", func(t *testing.T) {
+		s := newTestService(t, nil)
+		bundle, err := s.Capture(context.Background(), CaptureOptions{Duration: time.Second, Profiles: []Profile{ProfileCPU, ProfileHeap, ProfileMutex}})
+		require.NoError(t, err)
+
+		files := readArchive(t, bundle)
+		require.Len(t, files, 4)
+		for _, name := range []string{"cpu.pprof", "heap.pprof", "mutex.pprof"} {
+			require.NotEmpty(t, files[name], name)
+		}
+
+		var metadata struct {
+			Version  string    `json:"version"`
+			Duration string    `json:"duration"`
+			Profiles []Profile `json:"profiles"`
+		}
+		require.NoError(t, json.Unmarshal(files["metadata.json"], &metadata))
+		require.Equal(t, "10.3.0", metadata.Version)
+		require.Equal(t, "1s", metadata.Duration)
+		require.Equal(t, []Profile{ProfileCPU, ProfileHeap, ProfileMutex}, metadata.Profiles)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		s := newTestService(t, map[string]string{"profiling_max_duration": "10s"})
+		for _, opts := range []CaptureOptions{
+			{Duration: time.Minute},
+			{Duration: time.Millisecond},
+			{Duration: time.Second, Profiles: []Profile{"block"}},
+			{Duration: time.Second, Profiles: []Profile{ProfileHeap, ProfileHeap}},
+		} {
+			_, err := s.Capture(context.Background(), opts)
+			require.ErrorIs(t, err, ErrInvalidProfile)
+		}
+	})
+
+	t.Run("captures profiles at most once per interval", func(t *testing.T) {
+		s := newTestService(t, map[string]string{"profiling_min_interval": "1h"})
+		_, err := s.Capture(context.Background(), CaptureOptions{Duration: time.Second, Profiles: []Profile{ProfileGoroutine}})
+		require.NoError(t, err)
+
+		_, err = s.Capture(context.Background(), CaptureOptions{Duration: time.Second, Profiles: []Profile{ProfileGoroutine}})
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("captures profiles one at a time", func(t *testing.T) {
+		s := newTestService(t, nil)
+		s.capturing.Lock()
+		defer s.capturing.Unlock()
+
+		_, err := s.Capture(context.Background(), CaptureOptions{Duration: time.Second})
+		require.ErrorIs(t, err, ErrCaptureInProgress)
+	})
+
+	t.Run("stops capturing when the context is canceled", func(t *testing.T) {
+		s := newTestService(t, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := s.Capture(ctx, CaptureOptions{Duration: time.Minute, Profiles: []Profile{ProfileCPU}})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("does not capture profiles when disabled", func(t *testing.T) {
+		s := newTestService(t, map[string]string{"profiling_enabled": "false"})
+		_, err := s.Capture(context.Background(), CaptureOptions{})
+		require.ErrorIs(t, err, ErrDisabled)
+	})
+}
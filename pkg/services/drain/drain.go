@@ -0,0 +1,190 @@
+// Package drain implements the drain mode of the server used for rolling
+// restarts: once draining, the server stops accepting new long-lived
+// connections and gives in-flight queries a deadline to finish.
+package drain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrDraining is returned when new work is rejected because the server is draining.
+var ErrDraining = errors.New("server is draining")
+
+// Kind is the kind of work tracked while draining.
+type Kind string
+
+const (
+	// KindLive is a Grafana Live connection.
+	KindLive Kind = "live"
+	// KindRender is a render job.
+	KindRender Kind = "render"
+	// KindQuery is a data source query.
+	KindQuery Kind = "query"
+)
+
+// Status is the progress of the drain.
+type Status struct {
+	Draining  bool       `json:"draining"`
+	Reason    string     `json:"reason,omitempty"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Deadline  *time.Time `json:"deadline,omitempty"`
+	// InFlight is the number of in-flight jobs by kind.
+	InFlight map[Kind]int64 `json:"inFlight"`
+	// Rejected is the number of jobs rejected since the drain started by kind.
+	Rejected map[Kind]int64 `json:"rejected"`
+	// Done is true once the server is draining and no jobs are in flight.
+	Done bool `json:"done"`
+}
+
+// Service tracks the drain of the server. A nil *Service never drains.
+type Service struct {
+	log     log.Logger
+	timeout time.Duration
+	now     func() time.Time
+
+	mtx       sync.Mutex
+	draining  bool
+	reason    string
+	startedAt time.Time
+	deadline  time.Time
+	inFlight  map[Kind]int64
+	rejected  map[Kind]int64
+	// cancels cancels the contexts of the in-flight jobs once the deadline is reached.
+	cancels map[*int]context.CancelFunc
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	return &Service{
+		log:      log.New("drain"),
+		timeout:  cfg.DrainTimeout,
+		now:      time.Now,
+		inFlight: make(map[Kind]int64),
+		rejected: make(map[Kind]int64),
+		cancels:  make(map[*int]context.CancelFunc),
+	}
+}
+
+// Drain starts draining the server. In-flight jobs that are not done once the
+// drain timeout is reached are canceled. Draining a server that is already
+// draining does not extend the deadline.
+func (s *Service) Drain(reason string) Status {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.draining {
+		s.draining = true
+		s.reason = reason
+		s.startedAt = s.now()
+		s.deadline = s.startedAt.Add(s.timeout)
+		s.log.Info("Draining server", "reason", reason, "deadline", s.deadline, "inFlight", s.inFlight)
+		time.AfterFunc(s.timeout, s.cancelInFlight)
+	}
+
+	return s.status()
+}
+
+// IsDraining returns true if the server is draining.
+func (s *Service) IsDraining() bool {
+	if s == nil {
+		return false
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.draining
+}
+
+// Accept returns ErrDraining if new long-lived jobs of the kind are rejected because the server is draining.
+func (s *Service) Accept(kind Kind) error {
+	if s == nil {
+		return nil
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.draining {
+		s.rejected[kind]++
+		return ErrDraining
+	}
+	return nil
+}
+
+// Track tracks an in-flight job until done is called. The returned context is
+// canceled if the job is still running when the drain deadline is reached.
+func (s *Service) Track(ctx context.Context, kind Kind) (context.Context, func()) {
+	if s == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	key := new(int)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.draining && !s.now().Before(s.deadline) {
+		cancel()
+	}
+	s.inFlight[kind]++
+	s.cancels[key] = cancel
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+			s.inFlight[kind]--
+			delete(s.cancels, key)
+			cancel()
+		})
+	}
+}
+
+// Status returns the progress of the drain.
+func (s *Service) Status() Status {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.status()
+}
+
+func (s *Service) status() Status {
+	status := Status{
+		Draining: s.draining,
+		Reason:   s.reason,
+		InFlight: make(map[Kind]int64, len(s.inFlight)),
+		Rejected: make(map[Kind]int64, len(s.rejected)),
+	}
+
+	inFlight := int64(0)
+	for kind, n := range s.inFlight {
+		status.InFlight[kind] = n
+		inFlight += n
+	}
+	for kind, n := range s.rejected {
+		status.Rejected[kind] = n
+	}
+
+	if s.draining {
+		startedAt, deadline := s.startedAt, s.deadline
+		status.StartedAt = &startedAt
+		status.Deadline = &deadline
+		status.Done = inFlight == 0
+	}
+
+	return status
+}
+
+func (s *Service) cancelInFlight() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.cancels) > 0 {
+		s.log.Warn("Drain deadline reached, canceling in-flight jobs", "inFlight", s.inFlight)
+	}
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+}
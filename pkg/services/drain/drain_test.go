@@ -0,0 +1,72 @@
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDrain(t *testing.T) {
+	t.Run("accepts new jobs until the server is draining", func(t *testing.T) {
+		s := ProvideService(&setting.Cfg{DrainTimeout: time.Minute})
+		require.NoError(t, s.Accept(KindLive))
+		require.False(t, s.IsDraining())
+
+		status := s.Drain("test")
+		require.True(t, status.Draining)
+		require.Equal(t, "test", status.Reason)
+		require.True(t, status.Done)
+
+		require.ErrorIs(t, s.Accept(KindLive), ErrDraining)
+		require.ErrorIs(t, s.Accept(KindRender), ErrDraining)
+		require.Equal(t, map[Kind]int64{KindLive: 1, KindRender: 1}, s.Status().Rejected)
+	})
+
+	t.Run("draining again does not extend the deadline", func(t *testing.T) {
+		s := ProvideService(&setting.Cfg{DrainTimeout: time.Minute})
+		first := s.Drain("first")
+		second := s.Drain("second")
+		require.Equal(t, first.Deadline, second.Deadline)
+		require.Equal(t, "first", second.Reason)
+	})
+
+	t.Run("reports in-flight jobs until they are done", func(t *testing.T) {
+		s := ProvideService(&setting.Cfg{DrainTimeout: time.Minute})
+		_, done := s.Track(context.Background(), KindQuery)
+
+		status := s.Drain("test")
+		require.Equal(t, int64(1), status.InFlight[KindQuery])
+		require.False(t, status.Done)
+
+		done()
+		done()
+		status = s.Status()
+		require.Equal(t, int64(0), status.InFlight[KindQuery])
+		require.True(t, status.Done)
+	})
+
+	t.Run("cancels in-flight jobs once the deadline is reached", func(t *testing.T) {
+		s := ProvideService(&setting.Cfg{DrainTimeout: 10 * time.Millisecond})
+		ctx, done := s.Track(context.Background(), KindQuery)
+		defer done()
+
+		s.Drain("test")
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("in-flight job was not canceled")
+		}
+	})
+
+	t.Run("nil service never drains", func(t *testing.T) {
+		var s *Service
+		require.NoError(t, s.Accept(KindRender))
+		ctx, done := s.Track(context.Background(), KindQuery)
+		defer done()
+		require.NoError(t, ctx.Err())
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/explorepanels"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type PanelsAPI struct {
+	panelService explorepanels.Service
+	ac           accesscontrol.AccessControl
+	pluginStore  pluginstore.Store
+}
+
+func New(panelService explorepanels.Service, ac accesscontrol.AccessControl, pluginStore pluginstore.Store) *PanelsAPI {
+	return &PanelsAPI{
+		panelService: panelService,
+		ac:           ac,
+		pluginStore:  pluginStore,
+	}
+}
+
+func (api *PanelsAPI) RegisterAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(api.ac)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	routeRegister.Group("/api/dashboards/uid/:uid/panels", func(route routing.RouteRegister) {
+		route.Post(
+			"/explore",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite, uidScope)),
+			routing.Wrap(api.SavePanelFromExplore),
+		)
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/uid/{uid}/panels/explore dashboards savePanelFromExplore
+//
+// Save Explore queries as a dashboard panel.
+//
+// Adds a panel with the queries of an Explore pane at the top of the
+// dashboard, or replaces the queries of the panel set by panelId, and saves a
+// new version of the dashboard. The user must be allowed to query the
+// datasources of the queries.
+//
+// Responses:
+// 200: savePanelFromExploreResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+func (api *PanelsAPI) SavePanelFromExplore(c *contextmodel.ReqContext) response.Response {
+	req := explorepanels.PanelRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := api.panelService.SavePanel(c.Req.Context(), &explorepanels.PanelCommand{
+		PanelRequest: req,
+		DashboardUID: web.Params(c.Req)[":uid"],
+		OrgID:        c.SignedInUser.GetOrgID(),
+		User:         c.SignedInUser,
+	})
+	if err != nil {
+		var gfErr errutil.Error
+		if errors.As(err, &gfErr) {
+			return response.Err(err)
+		}
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), api.pluginStore, err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters savePanelFromExplore
+type SavePanelFromExploreParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body explorepanels.PanelRequest
+}
+
+// swagger:response savePanelFromExploreResponse
+type SavePanelFromExploreResponse struct {
+	// in: body
+	Body explorepanels.PanelResult `json:"body"`
+}
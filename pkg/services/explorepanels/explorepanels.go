@@ -0,0 +1,90 @@
+package explorepanels
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// DefaultPanelType is the panel type used when the request does not set one.
+// It matches the fallback of Explore when the query results have no preferred
+// visualisation.
+const DefaultPanelType = "table"
+
+// MixedDatasourceUID is the datasource of the panels whose queries use
+// different datasources.
+const MixedDatasourceUID = "-- Mixed --"
+
+var (
+	ErrNoQueries          = errutil.BadRequest("explorepanels.no-queries", errutil.WithPublicMessage("At least one query is required"))
+	ErrDatasourceRequired = errutil.BadRequest("explorepanels.datasource-required", errutil.WithPublicMessage("The datasource of the queries is required"))
+	ErrDatasourceNotFound = errutil.BadRequest("explorepanels.datasource-not-found", errutil.WithPublicMessage("Datasource not found"))
+	ErrDatasourceDenied   = errutil.Forbidden("explorepanels.datasource-denied", errutil.WithPublicMessage("You do not have permission to query the datasource"))
+	ErrPanelNotFound      = errutil.NotFound("explorepanels.panel-not-found", errutil.WithPublicMessage("Panel not found"))
+	ErrInvalidDashboard   = errutil.BadRequest("explorepanels.invalid-dashboard", errutil.WithPublicMessage("The panels of the dashboard are invalid"))
+)
+
+// TimeRange is the time range of an Explore pane.
+type TimeRange struct {
+	// example: now-1h
+	From string `json:"from"`
+	// example: now
+	To string `json:"to"`
+}
+
+// ExploreState is the state of an Explore pane, as found in the Explore URL.
+type ExploreState struct {
+	// Datasource UID of the datasource selected in Explore. Queries that do not
+	// set their own datasource use it.
+	Datasource string `json:"datasource"`
+	// Queries The queries of the pane.
+	// required: true
+	Queries []*simplejson.Json `json:"queries"`
+	// Range The time range of the pane. When set, the dashboard time range is
+	// updated to it.
+	Range *TimeRange `json:"range,omitempty"`
+}
+
+// PanelRequest is the payload of the Explore to dashboard endpoint.
+type PanelRequest struct {
+	// required: true
+	Explore ExploreState `json:"explore"`
+	// PanelID The panel to update. A new panel is added at the top of the
+	// dashboard when it is not set.
+	PanelID int64 `json:"panelId,omitempty"`
+	// Title The title of the panel. New panels default to "New panel", updated
+	// panels keep their title.
+	Title string `json:"title,omitempty"`
+	// Type The panel plugin. New panels default to "table", updated panels
+	// keep their type.
+	Type string `json:"type,omitempty"`
+	// Version The version of the dashboard the request was built against. The
+	// request fails with a 412 when the dashboard has been changed since.
+	Version int `json:"version,omitempty"`
+	// Message The message of the dashboard version.
+	Message string `json:"message,omitempty"`
+}
+
+// PanelCommand adds or updates a panel of a dashboard from an Explore state.
+type PanelCommand struct {
+	PanelRequest
+	DashboardUID string
+	OrgID        int64
+	User         identity.Requester
+}
+
+type PanelResult struct {
+	UID     string `json:"uid"`
+	PanelID int64  `json:"panelId"`
+	// Created is true when a new panel was added to the dashboard.
+	Created bool   `json:"created"`
+	Version int    `json:"version"`
+	URL     string `json:"url"`
+}
+
+// Service saves Explore queries as dashboard panels.
+type Service interface {
+	SavePanel(ctx context.Context, cmd *PanelCommand) (*PanelResult, error)
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/explorepanels"
+	"github.com/grafana/grafana/pkg/services/explorepanels/api"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const (
+	defaultPanelTitle  = "New panel"
+	defaultPanelWidth  = 12
+	defaultPanelHeight = 8
+)
+
+func ProvideService(routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService,
+	pluginStore pluginstore.Store,
+) *PanelService {
+	s := &PanelService{
+		ac:                ac,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		log:               log.New("explorepanels"),
+	}
+
+	panelsAPI := api.New(s, ac, pluginStore)
+	panelsAPI.RegisterAPIEndpoints(routeRegister)
+
+	return s
+}
+
+type PanelService struct {
+	ac                accesscontrol.AccessControl
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	log               log.Logger
+}
+
+var _ explorepanels.Service = (*PanelService)(nil)
+
+// SavePanel adds the Explore queries to the dashboard as a new panel, or
+// replaces the queries of an existing panel, and saves a new version of the
+// dashboard. The dashboard service checks that the user can save the
+// dashboard.
+func (s *PanelService) SavePanel(ctx context.Context, cmd *explorepanels.PanelCommand) (*explorepanels.PanelResult, error) {
+	if len(cmd.Explore.Queries) == 0 {
+		return nil, explorepanels.ErrNoQueries.Errorf("no queries in the Explore state")
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Version != 0 && cmd.Version != dash.Version {
+		return nil, dashboards.ErrDashboardVersionMismatch
+	}
+
+	panelDatasource, targets, err := s.buildTargets(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// the dashboard service redacts the secure options, the saved values are kept for the panels listing them
+	data := dash.Data
+
+	panelID := cmd.PanelID
+	created := panelID == 0
+	if created {
+		panelID, err = addPanel(data, cmd, panelDatasource, targets)
+	} else {
+		err = updatePanel(data, cmd, panelDatasource, targets)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r := cmd.Explore.Range; r != nil && r.From != "" && r.To != "" {
+		data.Set("time", map[string]any{"from": r.From, "to": r.To})
+	}
+	data.Set("version", dash.Version)
+
+	message := cmd.Message
+	if message == "" {
+		message = "Added panel from Explore"
+		if !created {
+			message = "Updated panel from Explore"
+		}
+	}
+
+	saveCmd := dashboards.SaveDashboardCommand{
+		Dashboard: data,
+		OrgID:     cmd.OrgID,
+		FolderUID: dash.FolderUID,
+		FolderID:  dash.FolderID, // nolint:staticcheck
+	}
+	saved, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     cmd.OrgID,
+		User:      cmd.User,
+		Message:   message,
+		Dashboard: saveCmd.GetDashboardModel(),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Debug("Saved panel from Explore", "dashboardUid", saved.UID, "panelId", panelID, "created", created, "version", saved.Version)
+
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(cmd.OrgID, 10))
+	params.Set("viewPanel", strconv.FormatInt(panelID, 10))
+	return &explorepanels.PanelResult{
+		UID:     saved.UID,
+		PanelID: panelID,
+		Created: created,
+		Version: saved.Version,
+		URL:     fmt.Sprintf("%s?%s", dashboards.GetDashboardURL(saved.UID, saved.Slug), params.Encode()),
+	}, nil
+}
+
+// buildTargets returns the datasource of the panel and its targets. Every
+// target gets a datasource reference and a refId, and the user must be allowed
+// to query the datasources of the targets.
+func (s *PanelService) buildTargets(ctx context.Context, cmd *explorepanels.PanelCommand) (map[string]any, []any, error) {
+	refs := map[string]map[string]any{}
+	targets := make([]any, 0, len(cmd.Explore.Queries))
+	var panelRef map[string]any
+	for i, q := range cmd.Explore.Queries {
+		if q == nil {
+			continue
+		}
+		target, err := q.Map()
+		if err != nil {
+			return nil, nil, explorepanels.ErrNoQueries.Errorf("query %d is not an object", i)
+		}
+
+		uid := q.GetPath("datasource", "uid").MustString(cmd.Explore.Datasource)
+		if uid == "" {
+			return nil, nil, explorepanels.ErrDatasourceRequired.Errorf("query %d has no datasource", i)
+		}
+
+		ref, ok := refs[uid]
+		if !ok {
+			ref, err = s.datasourceRef(ctx, cmd, uid)
+			if err != nil {
+				return nil, nil, err
+			}
+			refs[uid] = ref
+		}
+		target["datasource"] = ref
+		if _, ok := target["refId"]; !ok {
+			target["refId"] = refID(i)
+		}
+		targets = append(targets, target)
+
+		if expr.IsDataSource(uid) {
+			continue
+		}
+		if panelRef == nil {
+			panelRef = ref
+		} else if panelRef["uid"] != uid {
+			panelRef = map[string]any{"type": "datasource", "uid": explorepanels.MixedDatasourceUID}
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, nil, explorepanels.ErrNoQueries.Errorf("no queries in the Explore state")
+	}
+	if panelRef == nil {
+		// only expressions, they need a datasource query to run anyway
+		return nil, nil, explorepanels.ErrDatasourceRequired.Errorf("no datasource queries in the Explore state")
+	}
+	return panelRef, targets, nil
+}
+
+func (s *PanelService) datasourceRef(ctx context.Context, cmd *explorepanels.PanelCommand, uid string) (map[string]any, error) {
+	if expr.IsDataSource(uid) {
+		return map[string]any{"type": expr.DatasourceType, "uid": expr.DatasourceUID}, nil
+	}
+	if uid == grafanads.DatasourceUID {
+		return map[string]any{"type": "datasource", "uid": grafanads.DatasourceUID}, nil
+	}
+
+	ds, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, explorepanels.ErrDatasourceNotFound.Errorf("datasource %s: %w", uid, err)
+	}
+
+	evaluator := accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ds.UID))
+	if ok, err := s.ac.Evaluate(ctx, cmd.User, evaluator); err != nil || !ok {
+		if err != nil {
+			return nil, err
+		}
+		return nil, explorepanels.ErrDatasourceDenied.Errorf("user cannot query datasource %s", ds.UID)
+	}
+
+	return map[string]any{"type": ds.Type, "uid": ds.UID}, nil
+}
+
+// addPanel adds the panel at the top of the dashboard and moves the other
+// panels down, as the frontend does when a panel is added from Explore.
+func addPanel(data *simplejson.Json, cmd *explorepanels.PanelCommand, ds map[string]any, targets []any) (int64, error) {
+	panels, err := dashboardPanels(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var maxID int64
+	forEachPanel(panels, func(panel map[string]any) {
+		if id := panelID(panel); id > maxID {
+			maxID = id
+		}
+		if gridPos, ok := panel["gridPos"].(map[string]any); ok {
+			gridPos["y"] = toInt64(gridPos["y"]) + defaultPanelHeight
+		}
+	})
+
+	title := cmd.Title
+	if title == "" {
+		title = defaultPanelTitle
+	}
+	typ := cmd.Type
+	if typ == "" {
+		typ = explorepanels.DefaultPanelType
+	}
+
+	panel := map[string]any{
+		"id":         maxID + 1,
+		"type":       typ,
+		"title":      title,
+		"datasource": ds,
+		"targets":    targets,
+		"gridPos":    map[string]any{"x": 0, "y": 0, "w": defaultPanelWidth, "h": defaultPanelHeight},
+	}
+	data.Set("panels", append([]any{panel}, panels...))
+	return maxID + 1, nil
+}
+
+// updatePanel replaces the datasource and the queries of a panel, including
+// the panels of collapsed rows.
+func updatePanel(data *simplejson.Json, cmd *explorepanels.PanelCommand, ds map[string]any, targets []any) error {
+	panels, err := dashboardPanels(data)
+	if err != nil {
+		return err
+	}
+
+	var found map[string]any
+	forEachPanel(panels, func(panel map[string]any) {
+		if found == nil && panelID(panel) == cmd.PanelID && panel["type"] != "row" {
+			found = panel
+		}
+	})
+	if found == nil {
+		return explorepanels.ErrPanelNotFound.Errorf("panel %d not found in dashboard %s", cmd.PanelID, cmd.DashboardUID)
+	}
+
+	found["datasource"] = ds
+	found["targets"] = targets
+	if cmd.Title != "" {
+		found["title"] = cmd.Title
+	}
+	if cmd.Type != "" {
+		found["type"] = cmd.Type
+	}
+	data.Set("panels", panels)
+	return nil
+}
+
+func dashboardPanels(data *simplejson.Json) ([]any, error) {
+	raw, ok := data.CheckGet("panels")
+	if !ok {
+		return []any{}, nil
+	}
+	panels, err := raw.Array()
+	if err != nil {
+		return nil, explorepanels.ErrInvalidDashboard.Errorf("panels is not an array: %w", err)
+	}
+	return panels, nil
+}
+
+// forEachPanel calls fn for the panels and the panels of collapsed rows.
+func forEachPanel(panels []any, fn func(panel map[string]any)) {
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		fn(panel)
+		if nested, ok := panel["panels"].([]any); ok {
+			forEachPanel(nested, fn)
+		}
+	}
+}
+
+func panelID(panel map[string]any) int64 {
+	return toInt64(panel["id"])
+}
+
+func toInt64(v any) int64 {
+	return simplejson.NewFromAny(v).MustInt64()
+}
+
+// refID returns the refId the frontend gives to the i-th query: A, B, ..., Z,
+// AA, AB, ...
+func refID(i int) string {
+	id := ""
+	for n := i + 1; n > 0; n = (n - 1) / 26 {
+		id = string(rune('A'+(n-1)%26)) + id
+	}
+	return id
+}
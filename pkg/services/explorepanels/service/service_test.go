@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/explorepanels"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const testDashboard = `{
+	"id": 1,
+	"uid": "abc",
+	"title": "Incidents",
+	"version": 3,
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+		{"id": 2, "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 1}, "panels": [
+			{"id": 5, "type": "logs", "title": "Errors", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}
+		]}
+	]
+}`
+
+func setup(t *testing.T, canQuery bool) (*PanelService, *dashboards.FakeDashboardService, **dashboards.SaveDashboardDTO) {
+	t.Helper()
+
+	data, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+
+	var saved *dashboards.SaveDashboardDTO
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{
+		ID: 1, UID: "abc", OrgID: 1, Version: 3, Slug: "incidents", Data: data,
+	}, nil).Maybe()
+	dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, false).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*dashboards.SaveDashboardDTO)
+	}).Return(&dashboards.Dashboard{UID: "abc", Slug: "incidents", Version: 4}, nil).Maybe()
+
+	s := &PanelService{
+		ac:               actest.FakeAccessControl{ExpectedEvaluate: canQuery},
+		dashboardService: dashboardService,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{UID: "prom", Type: "prometheus", OrgID: 1},
+			{UID: "loki", Type: "loki", OrgID: 1},
+		}},
+		log: log.NewNopLogger(),
+	}
+	return s, dashboardService, &saved
+}
+
+func queries(t *testing.T, raw string) []*simplejson.Json {
+	t.Helper()
+	var result []*simplejson.Json
+	j, err := simplejson.NewJson([]byte(raw))
+	require.NoError(t, err)
+	for i := range j.MustArray() {
+		result = append(result, j.GetIndex(i))
+	}
+	return result
+}
+
+func TestSavePanel(t *testing.T) {
+	signedInUser := &user.SignedInUser{OrgID: 1, UserID: 1}
+
+	t.Run("Should add the panel at the top of the dashboard", func(t *testing.T) {
+		s, _, saved := setup(t, true)
+
+		result, err := s.SavePanel(context.Background(), &explorepanels.PanelCommand{
+			PanelRequest: explorepanels.PanelRequest{
+				Explore: explorepanels.ExploreState{
+					Datasource: "prom",
+					Queries:    queries(t, `[{"expr": "rate(errors[5m])"}, {"expr": "up", "refId": "X"}]`),
+					Range:      &explorepanels.TimeRange{From: "now-1h", To: "now"},
+				},
+			},
+			DashboardUID: "abc",
+			OrgID:        1,
+			User:         signedInUser,
+		})
+		require.NoError(t, err)
+		require.True(t, result.Created)
+		require.Equal(t, int64(6), result.PanelID)
+		require.Equal(t, 4, result.Version)
+		require.Contains(t, result.URL, "/d/abc/incidents?orgId=1&viewPanel=6")
+
+		data := (*saved).Dashboard.Data
+		require.Equal(t, "Added panel from Explore", (*saved).Message)
+		require.Equal(t, "now-1h", data.GetPath("time", "from").MustString())
+
+		panel := data.Get("panels").GetIndex(0)
+		require.Equal(t, int64(6), panel.Get("id").MustInt64())
+		require.Equal(t, explorepanels.DefaultPanelType, panel.Get("type").MustString())
+		require.Equal(t, "prom", panel.GetPath("datasource", "uid").MustString())
+		require.Equal(t, "A", panel.Get("targets").GetIndex(0).Get("refId").MustString())
+		require.Equal(t, "X", panel.Get("targets").GetIndex(1).Get("refId").MustString())
+		require.Equal(t, "prometheus", panel.Get("targets").GetIndex(1).GetPath("datasource", "type").MustString())
+
+		// existing panels, and the panels of collapsed rows, are moved down
+		require.Equal(t, int64(8), data.Get("panels").GetIndex(1).GetPath("gridPos", "y").MustInt64())
+		require.Equal(t, int64(17), data.Get("panels").GetIndex(2).Get("panels").GetIndex(0).GetPath("gridPos", "y").MustInt64())
+	})
+
+	t.Run("Should update the queries of a panel of a collapsed row", func(t *testing.T) {
+		s, _, saved := setup(t, true)
+
+		result, err := s.SavePanel(context.Background(), &explorepanels.PanelCommand{
+			PanelRequest: explorepanels.PanelRequest{
+				Explore: explorepanels.ExploreState{
+					Datasource: "loki",
+					Queries:    queries(t, `[{"expr": "{app=\"api\"}"}, {"expr": "up", "datasource": {"uid": "prom"}}]`),
+				},
+				PanelID: 5,
+				Version: 3,
+			},
+			DashboardUID: "abc",
+			OrgID:        1,
+			User:         signedInUser,
+		})
+		require.NoError(t, err)
+		require.False(t, result.Created)
+
+		panel := (*saved).Dashboard.Data.Get("panels").GetIndex(1).Get("panels").GetIndex(0)
+		require.Equal(t, "Errors", panel.Get("title").MustString())
+		require.Equal(t, explorepanels.MixedDatasourceUID, panel.GetPath("datasource", "uid").MustString())
+		require.Len(t, panel.Get("targets").MustArray(), 2)
+	})
+
+	t.Run("Should fail when the panel does not exist", func(t *testing.T) {
+		s, _, _ := setup(t, true)
+
+		_, err := s.SavePanel(context.Background(), &explorepanels.PanelCommand{
+			PanelRequest: explorepanels.PanelRequest{
+				Explore: explorepanels.ExploreState{Datasource: "prom", Queries: queries(t, `[{"expr": "up"}]`)},
+				PanelID: 2,
+			},
+			DashboardUID: "abc",
+			OrgID:        1,
+			User:         signedInUser,
+		})
+		require.ErrorIs(t, err, explorepanels.ErrPanelNotFound)
+	})
+
+	t.Run("Should fail when the dashboard has been changed", func(t *testing.T) {
+		s, _, _ := setup(t, true)
+
+		_, err := s.SavePanel(context.Background(), &explorepanels.PanelCommand{
+			PanelRequest: explorepanels.PanelRequest{
+				Explore: explorepanels.ExploreState{Datasource: "prom", Queries: queries(t, `[{"expr": "up"}]`)},
+				Version: 2,
+			},
+			DashboardUID: "abc",
+			OrgID:        1,
+			User:         signedInUser,
+		})
+		require.ErrorIs(t, err, dashboards.ErrDashboardVersionMismatch)
+	})
+
+	t.Run("Should fail when the user cannot query the datasource", func(t *testing.T) {
+		s, dashboardService, _ := setup(t, false)
+
+		_, err := s.SavePanel(context.Background(), &explorepanels.PanelCommand{
+			PanelRequest: explorepanels.PanelRequest{
+				Explore: explorepanels.ExploreState{Datasource: "prom", Queries: queries(t, `[{"expr": "up"}]`)},
+			},
+			DashboardUID: "abc",
+			OrgID:        1,
+			User:         signedInUser,
+		})
+		require.ErrorIs(t, err, explorepanels.ErrDatasourceDenied)
+		dashboardService.AssertNotCalled(t, "SaveDashboard", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRefID(t *testing.T) {
+	require.Equal(t, "A", refID(0))
+	require.Equal(t, "Z", refID(25))
+	require.Equal(t, "AA", refID(26))
+	require.Equal(t, "AB", refID(27))
+}
@@ -0,0 +1,69 @@
+package featuremgmt
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrFeatureDependencyNotEnabled = errutil.BadRequest("featuremgmt.dependencyNotEnabled").MustTemplate(
+		"feature toggle {{ .Public.Flag }} requires {{ .Public.Dependency }} to be enabled",
+		errutil.WithPublic("Feature toggle {{ .Public.Flag }} requires the feature toggle {{ .Public.Dependency }} to be enabled"),
+	)
+	ErrFeatureConflict = errutil.BadRequest("featuremgmt.conflict").MustTemplate(
+		"feature toggle {{ .Public.Flag }} conflicts with {{ .Public.Conflict }}",
+		errutil.WithPublic("Feature toggle {{ .Public.Flag }} can not be enabled together with the feature toggle {{ .Public.Conflict }}"),
+	)
+)
+
+// ValidateEnabled checks that the dependencies of the enabled feature toggles are enabled and that
+// none of them conflicts with another one. All the unsupported combinations are returned joined.
+func (fm *FeatureManager) ValidateEnabled(enabled map[string]bool) error {
+	names := make([]string, 0, len(enabled))
+	for name, on := range enabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	conflicts := make(map[[2]string]bool)
+	for _, name := range names {
+		flag, ok := fm.flags[name]
+		if !ok {
+			continue
+		}
+
+		for _, dependency := range flag.DependsOn {
+			if !enabled[dependency] {
+				errs = append(errs, ErrFeatureDependencyNotEnabled.Build(errutil.TemplateData{
+					Public: map[string]any{"Flag": name, "Dependency": dependency},
+				}))
+			}
+		}
+
+		for _, conflict := range flag.ConflictsWith {
+			// Conflicts may be declared by both flags, they are reported once.
+			pair := [2]string{name, conflict}
+			if conflict < name {
+				pair = [2]string{conflict, name}
+			}
+			if !enabled[conflict] || conflicts[pair] {
+				continue
+			}
+			conflicts[pair] = true
+			errs = append(errs, ErrFeatureConflict.Build(errutil.TemplateData{
+				Public: map[string]any{"Flag": pair[0], "Conflict": pair[1]},
+			}))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the combination of the feature toggles enabled when Grafana starts.
+func (fm *FeatureManager) validate() error {
+	return fm.ValidateEnabled(fm.enabled)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/licensing"
@@ -66,12 +67,24 @@ func (fm *FeatureManager) registerFlags(flags ...FeatureFlag) {
 		if add.RequiresRestart {
 			flag.RequiresRestart = true
 		}
+
+		flag.DependsOn = appendMissing(flag.DependsOn, add.DependsOn...)
+		flag.ConflictsWith = appendMissing(flag.ConflictsWith, add.ConflictsWith...)
 	}
 
 	// This will evaluate all flags
 	fm.update()
 }
 
+func appendMissing(values []string, add ...string) []string {
+	for _, v := range add {
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // meetsRequirements checks if grafana is able to run the given feature due to dev mode or licensing requirements
 func (fm *FeatureManager) meetsRequirements(ff *FeatureFlag) bool {
 	if ff.RequiresDevMode && !fm.isDevMod {
@@ -74,4 +74,38 @@ func TestFeatureManager(t *testing.T) {
 		require.Equal(t, "second", flag.Description)
 		require.Equal(t, "http://something", flag.DocsURL)
 	})
+
+	t.Run("check dependencies and conflicts", func(t *testing.T) {
+		ft := FeatureManager{
+			flags: map[string]*FeatureFlag{},
+		}
+		ft.registerFlags(FeatureFlag{
+			Name:      "a",
+			DependsOn: []string{"b"},
+		}, FeatureFlag{
+			Name: "b",
+		}, FeatureFlag{
+			Name:          "c",
+			ConflictsWith: []string{"d"},
+		}, FeatureFlag{
+			Name:          "d",
+			ConflictsWith: []string{"c"},
+		}, FeatureFlag{
+			Name:      "a",
+			DependsOn: []string{"b", "e"},
+		})
+		require.Equal(t, []string{"b", "e"}, ft.flags["a"].DependsOn)
+
+		require.NoError(t, ft.ValidateEnabled(map[string]bool{"b": true, "c": true}))
+		require.NoError(t, ft.ValidateEnabled(map[string]bool{"a": true, "b": true, "e": true}))
+
+		err := ft.ValidateEnabled(map[string]bool{"a": true, "b": false, "e": true})
+		require.ErrorIs(t, err, ErrFeatureDependencyNotEnabled)
+		require.ErrorContains(t, err, "feature toggle a requires b to be enabled")
+
+		err = ft.ValidateEnabled(map[string]bool{"c": true, "d": true})
+		require.ErrorIs(t, err, ErrFeatureConflict)
+		// The conflict is declared by both flags but only reported once.
+		require.Equal(t, "[featuremgmt.conflict] feature toggle c conflicts with d", err.Error())
+	})
 }
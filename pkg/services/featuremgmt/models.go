@@ -129,6 +129,10 @@ type FeatureFlag struct {
 	HideFromAdminPage bool  `json:"hideFromAdminPage,omitempty"` // don't display the feature in the admin page - add a comment with the reasoning
 	AllowSelfServe    *bool `json:"allowSelfServe,omitempty"`    // allow admin users to toggle the feature state from the admin page; this is required for GA toggles only
 
+	// Combinations of flags validated at startup and when flags are updated from the admin page
+	DependsOn     []string `json:"dependsOn,omitempty"`     // flags that must be enabled for this flag to be enabled
+	ConflictsWith []string `json:"conflictsWith,omitempty"` // flags that can not be enabled together with this flag
+
 	// This field is only for the feature management API. To enable your feature toggle by default, use `Expression`.
 	Enabled bool `json:"enabled,omitempty"`
 }
@@ -142,6 +146,9 @@ type FeatureToggleDTO struct {
 	Description string `json:"description"`
 	Enabled     bool   `json:"enabled"`
 	ReadOnly    bool   `json:"readOnly,omitempty"`
+
+	DependsOn     []string `json:"dependsOn,omitempty"`
+	ConflictsWith []string `json:"conflictsWith,omitempty"`
 }
 
 type FeatureManagerState struct {
@@ -762,6 +762,7 @@ var (
 			Description: "Enable caching for async queries for Redshift and Athena. Requires that the `useCachingService` feature toggle is enabled and the datasource has caching and async query support enabled",
 			Stage:       FeatureStagePublicPreview,
 			Owner:       awsDatasourcesSquad,
+			DependsOn:   []string{"useCachingService"},
 			Created:     time.Date(2023, time.July, 21, 12, 0, 0, 0, time.UTC),
 		},
 		{
@@ -72,6 +72,10 @@ func ProvideManagerService(cfg *setting.Cfg, licensing licensing.Licensing) (*Fe
 	// update the values
 	mgmt.update()
 
+	if err := mgmt.validate(); err != nil {
+		return mgmt, fmt.Errorf("unsupported combination of feature toggles: %w", err)
+	}
+
 	// Minimum approach to avoid circular dependency
 	// nolint:staticcheck
 	cfg.IsFeatureToggleEnabled = mgmt.IsEnabledGlobally
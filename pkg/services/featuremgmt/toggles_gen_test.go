@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -55,6 +56,28 @@ func TestFeatureToggleFiles(t *testing.T) {
 		}
 	})
 
+	t.Run("check registry dependencies", func(t *testing.T) {
+		flags := make(map[string]FeatureFlag, len(standardFeatureFlags))
+		for _, flag := range standardFeatureFlags {
+			flags[flag.Name] = flag
+		}
+		for _, flag := range standardFeatureFlags {
+			for _, name := range append(append([]string{}, flag.DependsOn...), flag.ConflictsWith...) {
+				other, ok := flags[name]
+				if !ok || name == flag.Name {
+					t.Errorf("flags can only depend on or conflict with other registered flags.  See: %s (%s)", flag.Name, name)
+					continue
+				}
+				if flag.Expression == "true" && slices.Contains(flag.DependsOn, name) && other.Expression != "true" {
+					t.Errorf("flags enabled by default can only depend on flags enabled by default.  See: %s (%s)", flag.Name, name)
+				}
+				if flag.Expression == "true" && slices.Contains(flag.ConflictsWith, name) && other.Expression == "true" {
+					t.Errorf("flags enabled by default can not conflict with flags enabled by default.  See: %s (%s)", flag.Name, name)
+				}
+			}
+		}
+	})
+
 	t.Run("all new features should have an owner", func(t *testing.T) {
 		for _, flag := range standardFeatureFlags {
 			if flag.Owner == "" {
@@ -0,0 +1,257 @@
+package resources
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type dashboardsServer struct {
+	*Service
+}
+
+func (s *dashboardsServer) Get(ctx context.Context, req *GetRequest) (*Dashboard, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	dash, err := s.getDashboard(ctx, usr, req.Uid)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return s.toDashboard(ctx, usr, dash)
+}
+
+func (s *dashboardsServer) List(ctx context.Context, req *ListRequest) (*DashboardList, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.list(ctx, usr, req.FolderUid, false)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &DashboardList{Items: items}, nil
+}
+
+func (s *dashboardsServer) Create(ctx context.Context, req *Dashboard) (*Dashboard, error) {
+	folderUID := req.FolderUid
+	if folderUID == "" {
+		folderUID = folder.GeneralFolderUID
+	}
+	usr, err := s.authorize(ctx, dashboards.ActionDashboardsCreate, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Uid != "" {
+		_, err := s.getDashboard(ctx, usr, req.Uid)
+		if err == nil {
+			return nil, status.Errorf(codes.AlreadyExists, "dashboard %s already exists", req.Uid)
+		}
+		if status.Code(s.toStatus(err)) != codes.NotFound {
+			return nil, s.toStatus(err)
+		}
+	}
+
+	data, err := dashboardData(req)
+	if err != nil {
+		return nil, err
+	}
+	data.Del("id")
+	data.Del("version")
+	return s.save(ctx, usr, req, data)
+}
+
+func (s *dashboardsServer) Update(ctx context.Context, req *Dashboard) (*Dashboard, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.getDashboard(ctx, usr, req.Uid)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	if req.FolderUid != existing.FolderUID {
+		// Moving a dashboard requires to be allowed to create dashboards in the new folder.
+		folderUID := req.FolderUid
+		if folderUID == "" {
+			folderUID = folder.GeneralFolderUID
+		}
+		if err := s.evaluate(ctx, usr, dashboards.ActionDashboardsCreate, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID)); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := dashboardData(req)
+	if err != nil {
+		return nil, err
+	}
+	data.Set("id", existing.ID)
+	data.Set("version", req.Version)
+	return s.save(ctx, usr, req, data)
+}
+
+func (s *dashboardsServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionDashboardsDelete, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	dash, err := s.getDashboard(ctx, usr, req.Uid)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	if err := s.dashboardService.DeleteDashboard(ctx, dash.ID, usr.GetOrgID()); err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *dashboardsServer) Watch(req *WatchRequest, stream Dashboards_WatchServer) error {
+	usr, err := s.user(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = watch(stream.Context(), s.watchInterval,
+		func(ctx context.Context) ([]*Dashboard, error) {
+			return s.list(ctx, usr, req.FolderUid, true)
+		},
+		func(d *Dashboard) (string, int64) {
+			return d.Uid, d.Version
+		},
+		func(ctx context.Context, items []*Dashboard) ([]*Dashboard, error) {
+			uids := make([]string, 0, len(items))
+			for _, item := range items {
+				uids = append(uids, item.Uid)
+			}
+			return s.load(ctx, usr, uids, false)
+		},
+		func(t EventType, d *Dashboard) error {
+			return stream.Send(&DashboardEvent{Type: t, Dashboard: d})
+		},
+	)
+	return s.toStatus(err)
+}
+
+func (s *dashboardsServer) getDashboard(ctx context.Context, usr *user.SignedInUser, uid string) (*dashboards.Dashboard, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: usr.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	if dash.IsFolder {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+	return dash, nil
+}
+
+// list returns the dashboards the user can read. The search filters them by
+// permission, their models are then fetched in one query, unless withoutData
+// is set.
+func (s *dashboardsServer) list(ctx context.Context, usr *user.SignedInUser, folderUID string, withoutData bool) ([]*Dashboard, error) {
+	query := &dashboards.FindPersistedDashboardsQuery{
+		OrgId:        usr.GetOrgID(),
+		SignedInUser: usr,
+		Type:         string(model.DashHitDB),
+	}
+	if folderUID != "" {
+		query.FolderUIDs = []string{folderUID}
+	}
+	hits, err := s.dashboardService.FindDashboards(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return []*Dashboard{}, nil
+	}
+
+	uids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		uids = append(uids, hit.UID)
+	}
+	return s.load(ctx, usr, uids, withoutData)
+}
+
+func (s *dashboardsServer) load(ctx context.Context, usr *user.SignedInUser, uids []string, withoutData bool) ([]*Dashboard, error) {
+	dashes, err := s.dashboardService.GetDashboards(ctx, &dashboards.GetDashboardsQuery{DashboardUIDs: uids, OrgID: usr.GetOrgID(), WithoutData: withoutData})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Dashboard, 0, len(dashes))
+	for _, dash := range dashes {
+		item, err := s.toDashboard(ctx, usr, dash)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *dashboardsServer) save(ctx context.Context, usr *user.SignedInUser, req *Dashboard, data *simplejson.Json) (*Dashboard, error) {
+	dash := dashboards.NewDashboardFromJson(data)
+	dash.OrgID = usr.GetOrgID()
+	dash.FolderUID = req.FolderUid
+	saved, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     usr.GetOrgID(),
+		User:      usr,
+		Message:   req.Message,
+		Dashboard: dash,
+	}, false)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return s.toDashboard(ctx, usr, saved)
+}
+
+// dashboardData returns the model of the dashboard, with the UID and title
+// of the request if they are set.
+func dashboardData(req *Dashboard) (*simplejson.Json, error) {
+	data := simplejson.New()
+	if len(req.Spec) > 0 {
+		var err error
+		if data, err = simplejson.NewJson(req.Spec); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid dashboard spec: %s", err)
+		}
+	}
+	if req.Uid != "" {
+		data.Set("uid", req.Uid)
+	}
+	if req.Title != "" {
+		data.Set("title", req.Title)
+	}
+	return data, nil
+}
+
+// toDashboard applies the redactions of the HTTP API to the dashboard: its
+// secure options are redacted, and the fields of the redaction policy are
+// removed for the users who cannot edit it.
+func (s *dashboardsServer) toDashboard(ctx context.Context, usr *user.SignedInUser, dash *dashboards.Dashboard) (*Dashboard, error) {
+	var spec []byte
+	if dash.Data != nil {
+		dashboards.RedactSecureOptions(dash.Data)
+		if s.dashboardRedaction.Enabled && !s.hasPermission(ctx, usr, dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.UID)) {
+			dashboards.RedactFields(dash.Data, s.dashboardRedaction)
+		}
+
+		var err error
+		if spec, err = dash.Data.MarshalJSON(); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to marshal dashboard %s", dash.UID)
+		}
+	}
+	return &Dashboard{
+		Uid:       dash.UID,
+		FolderUid: dash.FolderUID,
+		Title:     dash.Title,
+		Version:   int64(dash.Version),
+		Spec:      spec,
+		Updated:   dash.Updated.UnixMilli(),
+	}, nil
+}
@@ -0,0 +1,201 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type dataSourcesServer struct {
+	*Service
+}
+
+func (s *dataSourcesServer) Get(ctx context.Context, req *GetRequest) (*DataSource, error) {
+	usr, err := s.authorize(ctx, datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: req.Uid, OrgID: usr.GetOrgID()})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toDataSource(ds)
+}
+
+func (s *dataSourcesServer) List(ctx context.Context, _ *ListRequest) (*DataSourceList, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.list(ctx, usr)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &DataSourceList{Items: items}, nil
+}
+
+func (s *dataSourcesServer) Create(ctx context.Context, req *DataSource) (*DataSource, error) {
+	usr, err := s.authorize(ctx, datasources.ActionCreate, "")
+	if err != nil {
+		return nil, err
+	}
+	jsonData, secureJSONData, err := dataSourceData(req)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.dataSourceService.AddDataSource(ctx, &datasources.AddDataSourceCommand{
+		Name:           req.Name,
+		Type:           req.Type,
+		Access:         datasources.DsAccess(req.Access),
+		URL:            req.Url,
+		IsDefault:      req.IsDefault,
+		JsonData:       jsonData,
+		SecureJsonData: secureJSONData,
+		UID:            req.Uid,
+		OrgID:          usr.GetOrgID(),
+		UserID:         usr.UserID,
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toDataSource(ds)
+}
+
+func (s *dataSourcesServer) Update(ctx context.Context, req *DataSource) (*DataSource, error) {
+	usr, err := s.authorize(ctx, datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: req.Uid, OrgID: usr.GetOrgID()})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	if existing.ReadOnly {
+		return nil, status.Errorf(codes.FailedPrecondition, "data source %s is provisioned and read-only", req.Uid)
+	}
+	jsonData, secureJSONData, err := dataSourceData(req)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.dataSourceService.UpdateDataSource(ctx, &datasources.UpdateDataSourceCommand{
+		Name:           req.Name,
+		Type:           req.Type,
+		Access:         datasources.DsAccess(req.Access),
+		URL:            req.Url,
+		IsDefault:      req.IsDefault,
+		JsonData:       jsonData,
+		SecureJsonData: secureJSONData,
+		Version:        int(req.Version),
+		UID:            req.Uid,
+		OrgID:          usr.GetOrgID(),
+		ID:             existing.ID,
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toDataSource(ds)
+}
+
+func (s *dataSourcesServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	usr, err := s.authorize(ctx, datasources.ActionDelete, datasources.ScopeProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: req.Uid, OrgID: usr.GetOrgID()})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	if ds.ReadOnly {
+		return nil, status.Errorf(codes.FailedPrecondition, "data source %s is provisioned and read-only", req.Uid)
+	}
+	err = s.dataSourceService.DeleteDataSource(ctx, &datasources.DeleteDataSourceCommand{ID: ds.ID, UID: ds.UID, Name: ds.Name, OrgID: usr.GetOrgID()})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *dataSourcesServer) Watch(_ *WatchRequest, stream DataSources_WatchServer) error {
+	usr, err := s.user(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = watch(stream.Context(), s.watchInterval,
+		func(ctx context.Context) ([]*DataSource, error) {
+			return s.list(ctx, usr)
+		},
+		func(ds *DataSource) (string, int64) {
+			return ds.Uid, ds.Version
+		},
+		nil,
+		func(t EventType, ds *DataSource) error {
+			return stream.Send(&DataSourceEvent{Type: t, DataSource: ds})
+		},
+	)
+	return s.toStatus(err)
+}
+
+// list returns the data sources the user can read.
+func (s *dataSourcesServer) list(ctx context.Context, usr *user.SignedInUser) ([]*DataSource, error) {
+	dss, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: usr.GetOrgID(), User: usr})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*DataSource, 0, len(dss))
+	for _, ds := range dss {
+		if !s.hasPermission(ctx, usr, datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(ds.UID)) {
+			continue
+		}
+		item, err := toDataSource(ds)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func dataSourceData(req *DataSource) (*simplejson.Json, map[string]string, error) {
+	jsonData := simplejson.New()
+	if len(req.JsonData) > 0 {
+		var err error
+		if jsonData, err = simplejson.NewJson(req.JsonData); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid data source json_data: %s", err)
+		}
+	}
+	var secureJSONData map[string]string
+	if len(req.SecureJsonData) > 0 {
+		if err := json.Unmarshal(req.SecureJsonData, &secureJSONData); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid data source secure_json_data: %s", err)
+		}
+	}
+	return jsonData, secureJSONData, nil
+}
+
+// toDataSource never returns the secrets of the data source.
+func toDataSource(ds *datasources.DataSource) (*DataSource, error) {
+	var jsonData []byte
+	if ds.JsonData != nil {
+		var err error
+		if jsonData, err = ds.JsonData.MarshalJSON(); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to marshal data source %s", ds.UID)
+		}
+	}
+	return &DataSource{
+		Uid:       ds.UID,
+		Name:      ds.Name,
+		Type:      ds.Type,
+		Url:       ds.URL,
+		Access:    string(ds.Access),
+		IsDefault: ds.IsDefault,
+		JsonData:  jsonData,
+		Version:   int64(ds.Version),
+		Updated:   ds.Updated.UnixMilli(),
+	}, nil
+}
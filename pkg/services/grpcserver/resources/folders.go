@@ -0,0 +1,151 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type foldersServer struct {
+	*Service
+}
+
+func (s *foldersServer) Get(ctx context.Context, req *GetRequest) (*Folder, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &req.Uid, OrgID: usr.GetOrgID(), SignedInUser: usr})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toFolder(f), nil
+}
+
+func (s *foldersServer) List(ctx context.Context, req *ListRequest) (*FolderList, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.list(ctx, usr, req.FolderUid)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &FolderList{Items: items}, nil
+}
+
+func (s *foldersServer) Create(ctx context.Context, req *Folder) (*Folder, error) {
+	parentUID := req.ParentUid
+	if parentUID == "" {
+		parentUID = folder.GeneralFolderUID
+	}
+	usr, err := s.authorize(ctx, dashboards.ActionFoldersCreate, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parentUID))
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.folderService.Create(ctx, &folder.CreateFolderCommand{
+		UID:          req.Uid,
+		OrgID:        usr.GetOrgID(),
+		Title:        req.Title,
+		Description:  req.Description,
+		ParentUID:    req.ParentUid,
+		SignedInUser: usr,
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toFolder(f), nil
+}
+
+// Update updates the title and description of the folder, and moves it if
+// its parent changed.
+func (s *foldersServer) Update(ctx context.Context, req *Folder) (*Folder, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionFoldersWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.folderService.Update(ctx, &folder.UpdateFolderCommand{
+		UID:            req.Uid,
+		OrgID:          usr.GetOrgID(),
+		NewTitle:       &req.Title,
+		NewDescription: &req.Description,
+		Version:        int(req.Version),
+		SignedInUser:   usr,
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	if f.ParentUID != req.ParentUid {
+		f, err = s.folderService.Move(ctx, &folder.MoveFolderCommand{
+			UID:          req.Uid,
+			NewParentUID: req.ParentUid,
+			OrgID:        usr.GetOrgID(),
+			SignedInUser: usr,
+		})
+		if err != nil {
+			return nil, s.toStatus(err)
+		}
+	}
+	return toFolder(f), nil
+}
+
+func (s *foldersServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	usr, err := s.authorize(ctx, dashboards.ActionFoldersDelete, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid))
+	if err != nil {
+		return nil, err
+	}
+	err = s.folderService.Delete(ctx, &folder.DeleteFolderCommand{UID: req.Uid, OrgID: usr.GetOrgID(), SignedInUser: usr})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *foldersServer) Watch(req *WatchRequest, stream Folders_WatchServer) error {
+	usr, err := s.user(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = watch(stream.Context(), s.watchInterval,
+		func(ctx context.Context) ([]*Folder, error) {
+			return s.list(ctx, usr, req.FolderUid)
+		},
+		func(f *Folder) (string, int64) {
+			// The version of the folders is not incremented by all the
+			// updates, their update time is.
+			return f.Uid, f.Updated
+		},
+		nil,
+		func(t EventType, f *Folder) error {
+			return stream.Send(&FolderEvent{Type: t, Folder: f})
+		},
+	)
+	return s.toStatus(err)
+}
+
+// list returns the subfolders of the folder that the user can read.
+func (s *foldersServer) list(ctx context.Context, usr *user.SignedInUser, parentUID string) ([]*Folder, error) {
+	children, err := s.folderService.GetChildren(ctx, &folder.GetChildrenQuery{UID: parentUID, OrgID: usr.GetOrgID(), SignedInUser: usr})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Folder, 0, len(children))
+	for _, f := range children {
+		items = append(items, toFolder(f))
+	}
+	return items, nil
+}
+
+func toFolder(f *folder.Folder) *Folder {
+	return &Folder{
+		Uid:         f.UID,
+		ParentUid:   f.ParentUID,
+		Title:       f.Title,
+		Description: f.Description,
+		Version:     int64(f.Version),
+		Updated:     f.Updated.UnixMilli(),
+	}
+}
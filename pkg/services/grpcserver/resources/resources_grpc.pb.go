@@ -0,0 +1,928 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: resources.proto
+
+package resources
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Dashboards_Get_FullMethodName    = "/resources.Dashboards/Get"
+	Dashboards_List_FullMethodName   = "/resources.Dashboards/List"
+	Dashboards_Create_FullMethodName = "/resources.Dashboards/Create"
+	Dashboards_Update_FullMethodName = "/resources.Dashboards/Update"
+	Dashboards_Delete_FullMethodName = "/resources.Dashboards/Delete"
+	Dashboards_Watch_FullMethodName  = "/resources.Dashboards/Watch"
+)
+
+// DashboardsClient is the client API for Dashboards service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DashboardsClient interface {
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Dashboard, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*DashboardList, error)
+	Create(ctx context.Context, in *Dashboard, opts ...grpc.CallOption) (*Dashboard, error)
+	Update(ctx context.Context, in *Dashboard, opts ...grpc.CallOption) (*Dashboard, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Watch sends the current dashboards, then their changes
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Dashboards_WatchClient, error)
+}
+
+type dashboardsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDashboardsClient(cc grpc.ClientConnInterface) DashboardsClient {
+	return &dashboardsClient{cc}
+}
+
+func (c *dashboardsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Dashboard, error) {
+	out := new(Dashboard)
+	err := c.cc.Invoke(ctx, Dashboards_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dashboardsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*DashboardList, error) {
+	out := new(DashboardList)
+	err := c.cc.Invoke(ctx, Dashboards_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dashboardsClient) Create(ctx context.Context, in *Dashboard, opts ...grpc.CallOption) (*Dashboard, error) {
+	out := new(Dashboard)
+	err := c.cc.Invoke(ctx, Dashboards_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dashboardsClient) Update(ctx context.Context, in *Dashboard, opts ...grpc.CallOption) (*Dashboard, error) {
+	out := new(Dashboard)
+	err := c.cc.Invoke(ctx, Dashboards_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dashboardsClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Dashboards_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dashboardsClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Dashboards_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Dashboards_ServiceDesc.Streams[0], Dashboards_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dashboardsWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Dashboards_WatchClient interface {
+	Recv() (*DashboardEvent, error)
+	grpc.ClientStream
+}
+
+type dashboardsWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *dashboardsWatchClient) Recv() (*DashboardEvent, error) {
+	m := new(DashboardEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DashboardsServer is the server API for Dashboards service.
+// All implementations should embed UnimplementedDashboardsServer
+// for forward compatibility
+type DashboardsServer interface {
+	Get(context.Context, *GetRequest) (*Dashboard, error)
+	List(context.Context, *ListRequest) (*DashboardList, error)
+	Create(context.Context, *Dashboard) (*Dashboard, error)
+	Update(context.Context, *Dashboard) (*Dashboard, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Watch sends the current dashboards, then their changes
+	Watch(*WatchRequest, Dashboards_WatchServer) error
+}
+
+// UnimplementedDashboardsServer should be embedded to have forward compatible implementations.
+type UnimplementedDashboardsServer struct {
+}
+
+func (UnimplementedDashboardsServer) Get(context.Context, *GetRequest) (*Dashboard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedDashboardsServer) List(context.Context, *ListRequest) (*DashboardList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedDashboardsServer) Create(context.Context, *Dashboard) (*Dashboard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedDashboardsServer) Update(context.Context, *Dashboard) (*Dashboard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedDashboardsServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDashboardsServer) Watch(*WatchRequest, Dashboards_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+// UnsafeDashboardsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DashboardsServer will
+// result in compilation errors.
+type UnsafeDashboardsServer interface {
+	mustEmbedUnimplementedDashboardsServer()
+}
+
+func RegisterDashboardsServer(s grpc.ServiceRegistrar, srv DashboardsServer) {
+	s.RegisterService(&Dashboards_ServiceDesc, srv)
+}
+
+func _Dashboards_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DashboardsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dashboards_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DashboardsServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dashboards_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DashboardsServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dashboards_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DashboardsServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dashboards_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Dashboard)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DashboardsServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dashboards_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DashboardsServer).Create(ctx, req.(*Dashboard))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dashboards_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Dashboard)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DashboardsServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dashboards_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DashboardsServer).Update(ctx, req.(*Dashboard))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dashboards_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DashboardsServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dashboards_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DashboardsServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dashboards_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DashboardsServer).Watch(m, &dashboardsWatchServer{stream})
+}
+
+type Dashboards_WatchServer interface {
+	Send(*DashboardEvent) error
+	grpc.ServerStream
+}
+
+type dashboardsWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *dashboardsWatchServer) Send(m *DashboardEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Dashboards_ServiceDesc is the grpc.ServiceDesc for Dashboards service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Dashboards_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resources.Dashboards",
+	HandlerType: (*DashboardsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Dashboards_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Dashboards_List_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _Dashboards_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Dashboards_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Dashboards_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Dashboards_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "resources.proto",
+}
+
+const (
+	Folders_Get_FullMethodName    = "/resources.Folders/Get"
+	Folders_List_FullMethodName   = "/resources.Folders/List"
+	Folders_Create_FullMethodName = "/resources.Folders/Create"
+	Folders_Update_FullMethodName = "/resources.Folders/Update"
+	Folders_Delete_FullMethodName = "/resources.Folders/Delete"
+	Folders_Watch_FullMethodName  = "/resources.Folders/Watch"
+)
+
+// FoldersClient is the client API for Folders service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FoldersClient interface {
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Folder, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*FolderList, error)
+	Create(ctx context.Context, in *Folder, opts ...grpc.CallOption) (*Folder, error)
+	Update(ctx context.Context, in *Folder, opts ...grpc.CallOption) (*Folder, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Watch sends the current folders, then their changes
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Folders_WatchClient, error)
+}
+
+type foldersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFoldersClient(cc grpc.ClientConnInterface) FoldersClient {
+	return &foldersClient{cc}
+}
+
+func (c *foldersClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Folder, error) {
+	out := new(Folder)
+	err := c.cc.Invoke(ctx, Folders_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *foldersClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*FolderList, error) {
+	out := new(FolderList)
+	err := c.cc.Invoke(ctx, Folders_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *foldersClient) Create(ctx context.Context, in *Folder, opts ...grpc.CallOption) (*Folder, error) {
+	out := new(Folder)
+	err := c.cc.Invoke(ctx, Folders_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *foldersClient) Update(ctx context.Context, in *Folder, opts ...grpc.CallOption) (*Folder, error) {
+	out := new(Folder)
+	err := c.cc.Invoke(ctx, Folders_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *foldersClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Folders_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *foldersClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Folders_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Folders_ServiceDesc.Streams[0], Folders_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &foldersWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Folders_WatchClient interface {
+	Recv() (*FolderEvent, error)
+	grpc.ClientStream
+}
+
+type foldersWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *foldersWatchClient) Recv() (*FolderEvent, error) {
+	m := new(FolderEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FoldersServer is the server API for Folders service.
+// All implementations should embed UnimplementedFoldersServer
+// for forward compatibility
+type FoldersServer interface {
+	Get(context.Context, *GetRequest) (*Folder, error)
+	List(context.Context, *ListRequest) (*FolderList, error)
+	Create(context.Context, *Folder) (*Folder, error)
+	Update(context.Context, *Folder) (*Folder, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Watch sends the current folders, then their changes
+	Watch(*WatchRequest, Folders_WatchServer) error
+}
+
+// UnimplementedFoldersServer should be embedded to have forward compatible implementations.
+type UnimplementedFoldersServer struct {
+}
+
+func (UnimplementedFoldersServer) Get(context.Context, *GetRequest) (*Folder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedFoldersServer) List(context.Context, *ListRequest) (*FolderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedFoldersServer) Create(context.Context, *Folder) (*Folder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedFoldersServer) Update(context.Context, *Folder) (*Folder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedFoldersServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedFoldersServer) Watch(*WatchRequest, Folders_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+// UnsafeFoldersServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FoldersServer will
+// result in compilation errors.
+type UnsafeFoldersServer interface {
+	mustEmbedUnimplementedFoldersServer()
+}
+
+func RegisterFoldersServer(s grpc.ServiceRegistrar, srv FoldersServer) {
+	s.RegisterService(&Folders_ServiceDesc, srv)
+}
+
+func _Folders_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FoldersServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Folders_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FoldersServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Folders_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FoldersServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Folders_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FoldersServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Folders_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Folder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FoldersServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Folders_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FoldersServer).Create(ctx, req.(*Folder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Folders_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Folder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FoldersServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Folders_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FoldersServer).Update(ctx, req.(*Folder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Folders_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FoldersServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Folders_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FoldersServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Folders_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FoldersServer).Watch(m, &foldersWatchServer{stream})
+}
+
+type Folders_WatchServer interface {
+	Send(*FolderEvent) error
+	grpc.ServerStream
+}
+
+type foldersWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *foldersWatchServer) Send(m *FolderEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Folders_ServiceDesc is the grpc.ServiceDesc for Folders service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Folders_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resources.Folders",
+	HandlerType: (*FoldersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Folders_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Folders_List_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _Folders_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Folders_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Folders_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Folders_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "resources.proto",
+}
+
+const (
+	DataSources_Get_FullMethodName    = "/resources.DataSources/Get"
+	DataSources_List_FullMethodName   = "/resources.DataSources/List"
+	DataSources_Create_FullMethodName = "/resources.DataSources/Create"
+	DataSources_Update_FullMethodName = "/resources.DataSources/Update"
+	DataSources_Delete_FullMethodName = "/resources.DataSources/Delete"
+	DataSources_Watch_FullMethodName  = "/resources.DataSources/Watch"
+)
+
+// DataSourcesClient is the client API for DataSources service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DataSourcesClient interface {
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*DataSource, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*DataSourceList, error)
+	Create(ctx context.Context, in *DataSource, opts ...grpc.CallOption) (*DataSource, error)
+	Update(ctx context.Context, in *DataSource, opts ...grpc.CallOption) (*DataSource, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Watch sends the current data sources, then their changes
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DataSources_WatchClient, error)
+}
+
+type dataSourcesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataSourcesClient(cc grpc.ClientConnInterface) DataSourcesClient {
+	return &dataSourcesClient{cc}
+}
+
+func (c *dataSourcesClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*DataSource, error) {
+	out := new(DataSource)
+	err := c.cc.Invoke(ctx, DataSources_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourcesClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*DataSourceList, error) {
+	out := new(DataSourceList)
+	err := c.cc.Invoke(ctx, DataSources_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourcesClient) Create(ctx context.Context, in *DataSource, opts ...grpc.CallOption) (*DataSource, error) {
+	out := new(DataSource)
+	err := c.cc.Invoke(ctx, DataSources_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourcesClient) Update(ctx context.Context, in *DataSource, opts ...grpc.CallOption) (*DataSource, error) {
+	out := new(DataSource)
+	err := c.cc.Invoke(ctx, DataSources_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourcesClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, DataSources_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourcesClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DataSources_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataSources_ServiceDesc.Streams[0], DataSources_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataSourcesWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataSources_WatchClient interface {
+	Recv() (*DataSourceEvent, error)
+	grpc.ClientStream
+}
+
+type dataSourcesWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataSourcesWatchClient) Recv() (*DataSourceEvent, error) {
+	m := new(DataSourceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DataSourcesServer is the server API for DataSources service.
+// All implementations should embed UnimplementedDataSourcesServer
+// for forward compatibility
+type DataSourcesServer interface {
+	Get(context.Context, *GetRequest) (*DataSource, error)
+	List(context.Context, *ListRequest) (*DataSourceList, error)
+	Create(context.Context, *DataSource) (*DataSource, error)
+	Update(context.Context, *DataSource) (*DataSource, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Watch sends the current data sources, then their changes
+	Watch(*WatchRequest, DataSources_WatchServer) error
+}
+
+// UnimplementedDataSourcesServer should be embedded to have forward compatible implementations.
+type UnimplementedDataSourcesServer struct {
+}
+
+func (UnimplementedDataSourcesServer) Get(context.Context, *GetRequest) (*DataSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedDataSourcesServer) List(context.Context, *ListRequest) (*DataSourceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedDataSourcesServer) Create(context.Context, *DataSource) (*DataSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedDataSourcesServer) Update(context.Context, *DataSource) (*DataSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedDataSourcesServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDataSourcesServer) Watch(*WatchRequest, DataSources_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+// UnsafeDataSourcesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataSourcesServer will
+// result in compilation errors.
+type UnsafeDataSourcesServer interface {
+	mustEmbedUnimplementedDataSourcesServer()
+}
+
+func RegisterDataSourcesServer(s grpc.ServiceRegistrar, srv DataSourcesServer) {
+	s.RegisterService(&DataSources_ServiceDesc, srv)
+}
+
+func _DataSources_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourcesServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSources_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourcesServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSources_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourcesServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSources_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourcesServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSources_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DataSource)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourcesServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSources_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourcesServer).Create(ctx, req.(*DataSource))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSources_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DataSource)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourcesServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSources_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourcesServer).Update(ctx, req.(*DataSource))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSources_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourcesServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSources_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourcesServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSources_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataSourcesServer).Watch(m, &dataSourcesWatchServer{stream})
+}
+
+type DataSources_WatchServer interface {
+	Send(*DataSourceEvent) error
+	grpc.ServerStream
+}
+
+type dataSourcesWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataSourcesWatchServer) Send(m *DataSourceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// DataSources_ServiceDesc is the grpc.ServiceDesc for DataSources service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataSources_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resources.DataSources",
+	HandlerType: (*DataSourcesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _DataSources_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _DataSources_List_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _DataSources_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _DataSources_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _DataSources_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _DataSources_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "resources.proto",
+}
@@ -0,0 +1,286 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/folder"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupDataSourcesServer(t *testing.T, dss ...*datasources.DataSource) (*dataSourcesServer, grpccontext.ContextHandler) {
+	t.Helper()
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	return &dataSourcesServer{Service: &Service{
+		log:               log.NewNopLogger(),
+		ac:                acimpl.ProvideAccessControl(setting.NewCfg()),
+		contextHandler:    contextHandler,
+		watchInterval:     time.Second,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: dss},
+	}}, contextHandler
+}
+
+func TestDataSourcesServer(t *testing.T) {
+	dss := []*datasources.DataSource{
+		{ID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus", OrgID: 1, SecureJsonData: map[string][]byte{"password": []byte("secret")}},
+		{ID: 2, UID: "loki", Name: "Loki", Type: "loki", OrgID: 1},
+		{ID: 3, UID: "provisioned", Name: "Provisioned", Type: "loki", OrgID: 1, ReadOnly: true},
+	}
+	usr := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		datasources.ActionRead:   {datasources.ScopeProvider.GetResourceScopeUID("prom"), datasources.ScopeProvider.GetResourceScopeUID("provisioned")},
+		datasources.ActionDelete: {datasources.ScopeAll},
+	}}}
+
+	t.Run("requires a signed in user", func(t *testing.T) {
+		s, _ := setupDataSourcesServer(t, dss...)
+		_, err := s.List(context.Background(), &ListRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("get checks the permission of the user", func(t *testing.T) {
+		s, contextHandler := setupDataSourcesServer(t, dss...)
+		ctx := contextHandler.SetUser(context.Background(), usr)
+
+		ds, err := s.Get(ctx, &GetRequest{Uid: "prom"})
+		require.NoError(t, err)
+		assert.Equal(t, "Prometheus", ds.Name)
+		assert.Empty(t, ds.SecureJsonData)
+
+		_, err = s.Get(ctx, &GetRequest{Uid: "loki"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("list only returns the readable data sources", func(t *testing.T) {
+		s, contextHandler := setupDataSourcesServer(t, dss...)
+		list, err := s.List(contextHandler.SetUser(context.Background(), usr), &ListRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Items, 2)
+		assert.Equal(t, "prom", list.Items[0].Uid)
+		assert.Equal(t, "provisioned", list.Items[1].Uid)
+	})
+
+	t.Run("delete rejects provisioned data sources", func(t *testing.T) {
+		s, contextHandler := setupDataSourcesServer(t, dss...)
+		ctx := contextHandler.SetUser(context.Background(), usr)
+
+		_, err := s.Delete(ctx, &DeleteRequest{Uid: "provisioned"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = s.Delete(ctx, &DeleteRequest{Uid: "missing"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("create requires the create permission", func(t *testing.T) {
+		s, contextHandler := setupDataSourcesServer(t)
+		_, err := s.Create(contextHandler.SetUser(context.Background(), usr), &DataSource{Name: "New", Type: "loki"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func setupDashboardsServer(t *testing.T, dashboardService dashboards.DashboardService) (*dashboardsServer, grpccontext.ContextHandler) {
+	t.Helper()
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	return &dashboardsServer{Service: &Service{
+		log:            log.NewNopLogger(),
+		ac:             acimpl.ProvideAccessControl(setting.NewCfg()),
+		contextHandler: contextHandler,
+		watchInterval:  time.Millisecond,
+		dashboardRedaction: setting.DashboardRedactionSettings{
+			Enabled:             true,
+			DescriptionPatterns: []*regexp.Regexp{regexp.MustCompile("internal")},
+		},
+		dashboardService: dashboardService,
+	}}, contextHandler
+}
+
+func TestDashboardsServer(t *testing.T) {
+	newDashboard := func(version int) *dashboards.Dashboard {
+		data := simplejson.NewFromAny(map[string]any{
+			"uid":         "dash",
+			"title":       "Dash",
+			"description": "internal dashboard",
+			"panels":      []any{map[string]any{"id": 1, "encryptedSecureOptions": map[string]any{"token": "c2VjcmV0"}}},
+		})
+		return &dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Dash", Version: version, Data: data}
+	}
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")
+	viewer := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		dashboards.ActionDashboardsRead: {scope},
+	}}}
+	editor := &user.SignedInUser{UserID: 2, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		dashboards.ActionDashboardsRead:  {scope},
+		dashboards.ActionDashboardsWrite: {scope},
+	}}}
+
+	t.Run("get redacts the dashboard like the HTTP API", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(context.Context, *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+			return newDashboard(1), nil
+		})
+		s, contextHandler := setupDashboardsServer(t, dashboardService)
+
+		dash, err := s.Get(contextHandler.SetUser(context.Background(), viewer), &GetRequest{Uid: "dash"})
+		require.NoError(t, err)
+		spec, err := simplejson.NewJson(dash.Spec)
+		require.NoError(t, err)
+		_, ok := spec.CheckGet("description")
+		assert.False(t, ok)
+		panel := spec.Get("panels").GetIndex(0)
+		assert.Equal(t, map[string]any{"token": true}, panel.Get(dashboards.SecureOptionFieldsKey).MustMap())
+		_, ok = panel.CheckGet(dashboards.EncryptedSecureOptionsKey)
+		assert.False(t, ok)
+
+		dash, err = s.Get(contextHandler.SetUser(context.Background(), editor), &GetRequest{Uid: "dash"})
+		require.NoError(t, err)
+		spec, err = simplejson.NewJson(dash.Spec)
+		require.NoError(t, err)
+		assert.Equal(t, "internal dashboard", spec.Get("description").MustString())
+	})
+
+	t.Run("watch only loads the changed dashboards", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{{UID: "dash"}}, nil)
+		var polls int
+		dashboardService.On("GetDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardsQuery) bool {
+			return q.WithoutData
+		})).Return(func(context.Context, *dashboards.GetDashboardsQuery) ([]*dashboards.Dashboard, error) {
+			polls++
+			version := 1
+			if polls >= 3 {
+				version = 2
+			}
+			if polls == 4 {
+				cancel()
+			}
+			dash := newDashboard(version)
+			dash.Data = nil
+			return []*dashboards.Dashboard{dash}, nil
+		})
+		dashboardService.On("GetDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardsQuery) bool {
+			return !q.WithoutData
+		})).Return(func(context.Context, *dashboards.GetDashboardsQuery) ([]*dashboards.Dashboard, error) {
+			version := 1
+			if polls >= 3 {
+				version = 2
+			}
+			return []*dashboards.Dashboard{newDashboard(version)}, nil
+		}).Twice()
+		s, contextHandler := setupDashboardsServer(t, dashboardService)
+
+		stream := &fakeDashboardsWatchServer{ctx: contextHandler.SetUser(ctx, editor)}
+		require.NoError(t, s.Watch(&WatchRequest{}, stream))
+		require.Len(t, stream.events, 2)
+		assert.Equal(t, EventType_ADDED, stream.events[0].Type)
+		assert.Equal(t, EventType_MODIFIED, stream.events[1].Type)
+		assert.Equal(t, int64(2), stream.events[1].Dashboard.Version)
+		assert.NotEmpty(t, stream.events[1].Dashboard.Spec)
+	})
+}
+
+type fakeDashboardsWatchServer struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*DashboardEvent
+}
+
+func (f *fakeDashboardsWatchServer) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeDashboardsWatchServer) Send(e *DashboardEvent) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func TestToStatus(t *testing.T) {
+	s := &Service{log: log.NewNopLogger()}
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{dashboards.ErrDashboardNotFound, codes.NotFound},
+		{folder.ErrFolderNotFound.Errorf("missing"), codes.NotFound},
+		{datasources.ErrDataSourceNotFound, codes.NotFound},
+		{dashboards.ErrDashboardVersionMismatch, codes.Aborted},
+		{datasources.ErrDataSourceNameExists, codes.AlreadyExists},
+		{dashboards.ErrDashboardTitleEmpty, codes.InvalidArgument},
+		{status.Error(codes.InvalidArgument, "invalid"), codes.InvalidArgument},
+		{errors.New("database is locked"), codes.Internal},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.code, status.Code(s.toStatus(tc.err)), tc.err.Error())
+	}
+}
+
+type watchedItem struct {
+	uid     string
+	version int64
+}
+
+type watchEvent struct {
+	eventType EventType
+	uid       string
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	polls := [][]watchedItem{
+		{{"a", 1}, {"b", 1}},
+		{{"a", 1}, {"b", 2}, {"c", 1}},
+		{{"b", 2}, {"c", 1}},
+	}
+	var events []watchEvent
+	var poll int
+	err := watch(ctx, time.Millisecond,
+		func(ctx context.Context) ([]watchedItem, error) {
+			// The ticker can still fire once the context is canceled.
+			if poll == len(polls) {
+				return polls[poll-1], nil
+			}
+			items := polls[poll]
+			poll++
+			if poll == len(polls) {
+				cancel()
+			}
+			return items, nil
+		},
+		func(i watchedItem) (string, int64) { return i.uid, i.version },
+		nil,
+		func(t EventType, i watchedItem) error {
+			events = append(events, watchEvent{t, i.uid})
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []watchEvent{
+		{EventType_ADDED, "a"},
+		{EventType_ADDED, "b"},
+		{EventType_MODIFIED, "b"},
+		{EventType_ADDED, "c"},
+		{EventType_DELETED, "a"},
+	}, events)
+}
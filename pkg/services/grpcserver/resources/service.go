@@ -0,0 +1,252 @@
+// Package resources serves the dashboards, folders and data sources over
+// the GRPC server, so that tooling can manage them and watch their changes
+// without polling the HTTP API. Requests are authenticated by the GRPC server
+// and authorized with the same permissions as the HTTP API.
+package resources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+type Service struct {
+	log            log.Logger
+	ac             accesscontrol.AccessControl
+	contextHandler grpccontext.ContextHandler
+	watchInterval  time.Duration
+	// dashboardRedaction is the policy of the fields removed from the dashboards for the users who cannot edit them.
+	dashboardRedaction setting.DashboardRedactionSettings
+
+	dashboardService  dashboards.DashboardService
+	folderService     folder.Service
+	dataSourceService datasources.DataSourceService
+}
+
+func ProvideService(cfg *setting.Cfg, grpcServerProvider grpcserver.Provider, contextHandler grpccontext.ContextHandler,
+	ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, folderService folder.Service,
+	dataSourceService datasources.DataSourceService) *Service {
+	s := &Service{
+		log:                log.New("grpc-resources"),
+		ac:                 ac,
+		contextHandler:     contextHandler,
+		watchInterval:      cfg.GRPCServerResourcesWatchInterval,
+		dashboardRedaction: cfg.DashboardRedaction,
+		dashboardService:   dashboardService,
+		folderService:      folderService,
+		dataSourceService:  dataSourceService,
+	}
+
+	server := grpcServerProvider.GetServer()
+	RegisterDashboardsServer(server, &dashboardsServer{Service: s})
+	RegisterFoldersServer(server, &foldersServer{Service: s})
+	RegisterDataSourcesServer(server, &dataSourcesServer{Service: s})
+	return s
+}
+
+func (s *Service) user(ctx context.Context) (*user.SignedInUser, error) {
+	usr := s.contextHandler.GetUser(ctx)
+	if usr == nil {
+		return nil, status.Error(codes.Unauthenticated, "no signed in user")
+	}
+	return usr, nil
+}
+
+// authorize returns the signed in user if they have the permission.
+func (s *Service) authorize(ctx context.Context, action string, scope string) (*user.SignedInUser, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.evaluate(ctx, usr, action, scope); err != nil {
+		return nil, err
+	}
+	return usr, nil
+}
+
+// evaluate checks the permission of the user, without scope if the scope is
+// empty.
+func (s *Service) evaluate(ctx context.Context, usr *user.SignedInUser, action string, scope string) error {
+	evaluator := accesscontrol.EvalPermission(action)
+	if scope != "" {
+		evaluator = accesscontrol.EvalPermission(action, scope)
+	}
+	ok, err := s.ac.Evaluate(ctx, usr, evaluator)
+	if err != nil {
+		return s.toStatus(err)
+	}
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "missing permission %s", evaluator.String())
+	}
+	return nil
+}
+
+func (s *Service) hasPermission(ctx context.Context, usr *user.SignedInUser, action string, scope string) bool {
+	ok, err := s.ac.Evaluate(ctx, usr, accesscontrol.EvalPermission(action, scope))
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to evaluate permission", "action", action, "scope", scope, "error", err)
+	}
+	return ok
+}
+
+// toStatus converts the errors of the services to GRPC status errors.
+func (s *Service) toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var dashboardErr dashboards.DashboardErr
+	var grafanaErr errutil.Error
+	code := codes.Internal
+	switch {
+	case errors.Is(err, dashboards.ErrFolderNotFound), errors.Is(err, datasources.ErrDataSourceNotFound):
+		code = codes.NotFound
+	case errors.Is(err, dashboards.ErrFolderAccessDenied):
+		code = codes.PermissionDenied
+	case errors.Is(err, dashboards.ErrDashboardVersionMismatch), errors.Is(err, dashboards.ErrFolderVersionMismatch),
+		errors.Is(err, datasources.ErrDataSourceUpdatingOldVersion):
+		code = codes.Aborted
+	case errors.Is(err, dashboards.ErrDashboardWithSameUIDExists), errors.Is(err, datasources.ErrDataSourceNameExists),
+		errors.Is(err, datasources.ErrDataSourceUidExists):
+		code = codes.AlreadyExists
+	case errors.As(err, &dashboardErr):
+		code = codeFromHTTPStatus(dashboardErr.StatusCode)
+	case errors.As(err, &grafanaErr):
+		code = codeFromHTTPStatus(grafanaErr.Reason.Status().HTTPStatus())
+	}
+
+	if code == codes.Internal {
+		s.log.Error("Failed to serve GRPC resources request", "error", err)
+		return status.Error(code, "internal error")
+	}
+	return status.Error(code, err.Error())
+}
+
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// watch sends the current resources as added, then polls them every
+// interval and sends their changes until the context is done. Resources are
+// identified by their UID and changed when their version changes. If load is
+// set, list only returns the UID and version of the resources, and load is
+// called with the added and modified ones to read them before they are sent.
+func watch[T any](ctx context.Context, interval time.Duration, list func(ctx context.Context) ([]T, error),
+	key func(T) (uid string, version int64), load func(ctx context.Context, items []T) ([]T, error),
+	send func(EventType, T) error) error {
+	type state struct {
+		item    T
+		version int64
+	}
+
+	current := map[string]state{}
+	poll := func() error {
+		items, err := list(ctx)
+		if err != nil {
+			return err
+		}
+
+		next := make(map[string]state, len(items))
+		var changed []T
+		for _, item := range items {
+			uid, version := key(item)
+			previous, ok := current[uid]
+			if ok && previous.version == version {
+				next[uid] = previous
+				continue
+			}
+			next[uid] = state{item: item, version: version}
+			changed = append(changed, item)
+		}
+		if load != nil && len(changed) > 0 {
+			loaded, err := load(ctx, changed)
+			if err != nil {
+				return err
+			}
+			for _, item := range changed {
+				uid, _ := key(item)
+				// The resources deleted since they were listed are not loaded, they are sent as deleted.
+				delete(next, uid)
+			}
+			for _, item := range loaded {
+				uid, version := key(item)
+				next[uid] = state{item: item, version: version}
+			}
+			changed = loaded
+		}
+		for _, item := range changed {
+			uid, _ := key(item)
+			eventType := EventType_MODIFIED
+			if _, ok := current[uid]; !ok {
+				eventType = EventType_ADDED
+			}
+			if err := send(eventType, item); err != nil {
+				return err
+			}
+		}
+		for uid, previous := range current {
+			if _, ok := next[uid]; !ok {
+				if err := send(EventType_DELETED, previous.item); err != nil {
+					return err
+				}
+			}
+		}
+		current = next
+		return nil
+	}
+
+	if err := poll(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -111,7 +111,7 @@ func TestGetUserFromLDAPAPIEndpoint_UserNotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, res.StatusCode)
 	bodyBytes, _ := io.ReadAll(res.Body)
-	assert.JSONEq(t, "{\"message\":\"No user was found in the LDAP server(s) with that username\"}", string(bodyBytes))
+	assert.JSONEq(t, "{\"code\":\"http.not-found\",\"message\":\"No user was found in the LDAP server(s) with that username\"}", string(bodyBytes))
 }
 
 func TestGetUserFromLDAPAPIEndpoint_OrgNotfound(t *testing.T) {
@@ -460,6 +460,7 @@ func TestPostSyncUserWithLDAPAPIEndpoint_WhenUserNotFound(t *testing.T) {
 
 	expected := `
 	{
+		"code": "http.not-found",
 		"message": "user not found"
 	}
 	`
@@ -529,6 +530,7 @@ func TestPostSyncUserWithLDAPAPIEndpoint_WhenUserNotInLDAP(t *testing.T) {
 
 	expected := `
 	{
+		"code": "http.bad-request",
 		"message": "User not found in LDAP. Disabled the user without updating information"
 	}
 	`
@@ -5,12 +5,15 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
 type Converter struct {
-	telegrafConverterWide         *telegraf.Converter
-	telegrafConverterLabelsColumn *telegraf.Converter
+	telegrafConverterWide           *telegraf.Converter
+	telegrafConverterLabelsColumn   *telegraf.Converter
+	prometheusConverterWide         *prometheus.Converter
+	prometheusConverterLabelsColumn *prometheus.Converter
 }
 
 func NewConverter() *Converter {
@@ -22,10 +25,17 @@ func NewConverter() *Converter {
 			telegraf.WithUseLabelsColumn(true),
 			telegraf.WithFloat64Numbers(true),
 		),
+		prometheusConverterWide: prometheus.NewConverter(),
+		prometheusConverterLabelsColumn: prometheus.NewConverter(
+			prometheus.WithUseLabelsColumn(true),
+		),
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrInvalidRemoteWrite     = errors.New("invalid remote write request")
+)
 
 func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
 	var converter telemetry.Converter
@@ -44,3 +54,22 @@ func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameW
 	}
 	return metricFrames, nil
 }
+
+// ConvertRemoteWrite converts a Prometheus remote write request.
+func (c *Converter) ConvertRemoteWrite(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	var converter telemetry.Converter
+	switch frameFormat {
+	case "wide":
+		converter = c.prometheusConverterWide
+	case "labels_column":
+		converter = c.prometheusConverterLabelsColumn
+	default:
+		return nil, ErrUnsupportedFrameFormat
+	}
+
+	metricFrames, err := converter.Convert(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+	return metricFrames, nil
+}
@@ -0,0 +1,61 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/playlist"
+)
+
+// PlaylistHandler manages the `grafana/playlist/device/<uid>` channels. Devices subscribe to their channel
+// to receive the playback commands sent with the playlist devices API.
+type PlaylistHandler struct {
+	PlaylistService playlist.Service
+	AccessControl   accesscontrol.AccessControl
+}
+
+// GetHandlerForPath called on init
+func (h *PlaylistHandler) GetHandlerForPath(_ string) (model.ChannelHandler, error) {
+	return h, nil // all devices share the same handler
+}
+
+// OnSubscribe allows users who can read the playlist devices to subscribe to the channel of a registered device
+func (h *PlaylistHandler) OnSubscribe(ctx context.Context, user identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	parts := strings.Split(e.Path, "/")
+	if len(parts) != 2 || parts[0] != "device" {
+		logger.Error("Unknown playlist channel", "path", e.Path)
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+
+	canRead, err := h.AccessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(playlist.ActionDevicesRead))
+	if err != nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, err
+	}
+	if !canRead {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+
+	_, err = h.PlaylistService.GetDevice(ctx, &playlist.GetDeviceQuery{UID: parts[1], OrgID: user.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, playlist.ErrDeviceNotFound) {
+			return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+		}
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, err
+	}
+
+	// Presence tells the API whether the device is connected
+	return model.SubscribeReply{
+		Presence: true,
+	}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is not allowed, the playback commands are sent with the HTTP API so that they are authorized
+func (h *PlaylistHandler) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
+	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
@@ -0,0 +1,59 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/playlist/playlisttest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPlaylistHandler_OnSubscribe(t *testing.T) {
+	u := &user.SignedInUser{OrgID: 1}
+
+	t.Run("subscribes to the channel of a registered device", func(t *testing.T) {
+		h := &PlaylistHandler{
+			PlaylistService: &playlisttest.FakePlaylistService{ExpectedDevice: &playlist.Device{UID: "wall-1"}},
+			AccessControl:   actest.FakeAccessControl{ExpectedEvaluate: true},
+		}
+		reply, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/playlist/device/wall-1", Path: "device/wall-1"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+		require.True(t, reply.Presence)
+	})
+
+	t.Run("denies users who cannot read the devices", func(t *testing.T) {
+		h := &PlaylistHandler{
+			PlaylistService: &playlisttest.FakePlaylistService{ExpectedDevice: &playlist.Device{UID: "wall-1"}},
+			AccessControl:   actest.FakeAccessControl{ExpectedEvaluate: false},
+		}
+		_, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/playlist/device/wall-1", Path: "device/wall-1"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+	})
+
+	t.Run("does not find unknown devices and paths", func(t *testing.T) {
+		h := &PlaylistHandler{
+			PlaylistService: &playlisttest.FakePlaylistService{ExpectedError: playlist.ErrDeviceNotFound},
+			AccessControl:   actest.FakeAccessControl{ExpectedEvaluate: true},
+		}
+		for _, path := range []string{"device/wall-1", "devices", "device/wall-1/state"} {
+			_, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/playlist/" + path, Path: path})
+			require.NoError(t, err)
+			require.Equal(t, backend.SubscribeStreamStatusNotFound, status, path)
+		}
+	})
+}
+
+func TestPlaylistHandler_OnPublish(t *testing.T) {
+	h := &PlaylistHandler{}
+	_, status, err := h.OnPublish(context.Background(), &user.SignedInUser{OrgID: 1}, model.PublishEvent{Path: "device/wall-1", Data: []byte(`{"action":"next"}`)})
+	require.NoError(t, err)
+	require.Equal(t, backend.PublishStreamStatusPermissionDenied, status)
+}
@@ -0,0 +1,232 @@
+package mqttbridge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType is the type of the values of a field.
+type FieldType string
+
+const (
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeString  FieldType = "string"
+	FieldTypeBoolean FieldType = "boolean"
+)
+
+// Time formats of the time field of the payloads.
+const (
+	TimeFormatUnix    = "unix"
+	TimeFormatUnixMs  = "unix_ms"
+	TimeFormatRFC3339 = "rfc3339"
+)
+
+// Topic is an MQTT topic filter whose messages are published to a Live channel.
+type Topic struct {
+	// Topic is the MQTT topic filter, it may contain the + and # wildcards.
+	Topic string
+	OrgID int64
+	QoS   byte
+	// Channel is the Live channel the frames are published to. Only channels of the stream scope are
+	// supported.
+	Channel liveDto.Channel
+	// TimePath is the path of the time of the payloads. The time of reception is used when it is not set.
+	TimePath   jsonPath
+	TimeFormat string
+	// TopicLabels are labels of the fields whose values are levels of the topic of the message, by
+	// position starting at 0.
+	TopicLabels map[string]int
+	Fields      []Field
+}
+
+// Field is a field of the published frames, read from the payloads of the messages.
+type Field struct {
+	Name string
+	Path jsonPath
+	Type FieldType
+	Unit string
+}
+
+type configFile struct {
+	APIVersion int64         `yaml:"apiVersion"`
+	Topics     []topicConfig `yaml:"topics"`
+}
+
+type topicConfig struct {
+	Topic       string         `yaml:"topic"`
+	OrgID       int64          `yaml:"orgId"`
+	QoS         byte           `yaml:"qos"`
+	Channel     string         `yaml:"channel"`
+	TimeField   string         `yaml:"timeField"`
+	TimeFormat  string         `yaml:"timeFormat"`
+	TopicLabels map[string]int `yaml:"topicLabels"`
+	Fields      []fieldConfig  `yaml:"fields"`
+}
+
+type fieldConfig struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+	Type string `yaml:"type"`
+	Unit string `yaml:"unit"`
+}
+
+// readConfig reads the topics of the YAML files of a directory. A missing directory has no topics.
+func readConfig(path string) ([]Topic, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var topics []Topic
+	filters := make(map[string]string)
+	for _, file := range files {
+		if file.IsDir() || !(strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml")) {
+			continue
+		}
+
+		filename := filepath.Join(path, file.Name())
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `filename` comes from the provisioning path
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+
+		for _, tc := range cfg.Topics {
+			t, err := tc.topic()
+			if err != nil {
+				return nil, fmt.Errorf("invalid topic in %s: %w", filename, err)
+			}
+			// The client has a single handler per topic filter.
+			if other, ok := filters[t.Topic]; ok {
+				return nil, fmt.Errorf("topic %q is defined in both %s and %s", t.Topic, other, filename)
+			}
+			filters[t.Topic] = filename
+			topics = append(topics, t)
+		}
+	}
+	return topics, nil
+}
+
+func (c topicConfig) topic() (Topic, error) {
+	t := Topic{
+		Topic:       c.Topic,
+		OrgID:       c.OrgID,
+		QoS:         c.QoS,
+		TimeFormat:  c.TimeFormat,
+		TopicLabels: c.TopicLabels,
+	}
+	if err := validateTopicFilter(t.Topic); err != nil {
+		return t, err
+	}
+	if t.OrgID == 0 {
+		t.OrgID = 1
+	}
+	if t.QoS > 2 {
+		return t, fmt.Errorf("invalid qos %d of topic %q", t.QoS, t.Topic)
+	}
+
+	var err error
+	t.Channel, err = liveDto.ParseChannel(c.Channel)
+	if err != nil || !t.Channel.IsValid() {
+		return t, fmt.Errorf("invalid channel %q of topic %q", c.Channel, t.Topic)
+	}
+	if t.Channel.Scope != liveDto.ScopeStream {
+		return t, fmt.Errorf("channel %q of topic %q is not in the %s scope", c.Channel, t.Topic, liveDto.ScopeStream)
+	}
+
+	if c.TimeField != "" {
+		if t.TimePath, err = parseJSONPath(c.TimeField); err != nil {
+			return t, fmt.Errorf("invalid time field of topic %q: %w", t.Topic, err)
+		}
+	}
+	switch t.TimeFormat {
+	case "", TimeFormatUnix, TimeFormatUnixMs, TimeFormatRFC3339:
+	default:
+		return t, fmt.Errorf("invalid time format %q of topic %q", t.TimeFormat, t.Topic)
+	}
+
+	levels := len(strings.Split(t.Topic, "/"))
+	for name, level := range c.TopicLabels {
+		if level < 0 || level >= levels {
+			return t, fmt.Errorf("level %d of label %q is not a level of topic %q", level, name, t.Topic)
+		}
+	}
+
+	if len(c.Fields) == 0 {
+		return t, fmt.Errorf("topic %q has no fields", t.Topic)
+	}
+	names := make(map[string]struct{}, len(c.Fields))
+	for _, fc := range c.Fields {
+		f, err := fc.field()
+		if err != nil {
+			return t, fmt.Errorf("invalid field of topic %q: %w", t.Topic, err)
+		}
+		if _, ok := names[f.Name]; ok || f.Name == timeFieldName {
+			return t, fmt.Errorf("field %q of topic %q is defined twice", f.Name, t.Topic)
+		}
+		names[f.Name] = struct{}{}
+		t.Fields = append(t.Fields, f)
+	}
+	return t, nil
+}
+
+func (c fieldConfig) field() (Field, error) {
+	f := Field{
+		Name: c.Name,
+		Type: FieldType(c.Type),
+		Unit: c.Unit,
+	}
+	if f.Name == "" {
+		return f, errors.New("name is required")
+	}
+	switch f.Type {
+	case "":
+		f.Type = FieldTypeNumber
+	case FieldTypeNumber, FieldTypeString, FieldTypeBoolean:
+	default:
+		return f, fmt.Errorf("invalid type %q of field %q", c.Type, c.Name)
+	}
+
+	path := c.Path
+	if path == "" {
+		path = "$." + c.Name
+	}
+	var err error
+	if f.Path, err = parseJSONPath(path); err != nil {
+		return f, fmt.Errorf("invalid path of field %q: %w", c.Name, err)
+	}
+	return f, nil
+}
+
+// validateTopicFilter checks the wildcards of a topic filter: + must be a whole level and # must be
+// the last level.
+func validateTopicFilter(filter string) error {
+	if filter == "" {
+		return errors.New("topic is required")
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.ContainsAny(level, "+#") && len(level) > 1 {
+			return fmt.Errorf("invalid wildcard in topic %q", filter)
+		}
+		if level == "#" && i != len(levels)-1 {
+			return fmt.Errorf("# must be the last level of topic %q", filter)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mqttbridge
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "live_mqtt_bridge"
+)
+
+type metrics struct {
+	connected prometheus.Gauge
+	messages  *prometheus.CounterVec
+	rows      *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		connected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "connected",
+			Help:      "1 if the bridge is connected to the MQTT broker, 0 otherwise",
+		}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "messages_total",
+			Help:      "Number of MQTT messages received, by topic filter and by whether they were published to their Live channel",
+		}, []string{"topic", "status"}),
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "rows_published_total",
+			Help:      "Number of rows of frames published to Live channels, by topic filter",
+		}, []string{"topic"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.connected, m.messages, m.rows)
+	}
+
+	return m
+}
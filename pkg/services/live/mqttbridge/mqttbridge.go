@@ -0,0 +1,166 @@
+// Package mqttbridge subscribes to topics of an MQTT broker and publishes the values read from the
+// JSON payloads of their messages to Live channels, so that devices publishing to MQTT can be shown
+// on dashboards without an external bridge.
+package mqttbridge
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"path/filepath"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// publishFunc publishes a frame to a Live channel of an org.
+type publishFunc func(ctx context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error
+
+type Service struct {
+	log     log.Logger
+	metrics *metrics
+	publish publishFunc
+
+	enabled       bool
+	configPath    string
+	brokerURL     string
+	clientID      string
+	username      string
+	password      string
+	tlsSkipVerify bool
+	maxTopics     int
+
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, grafanaLive *live.GrafanaLive, registerer prometheus.Registerer) *Service {
+	section := cfg.SectionWithEnvOverrides("live.mqtt")
+	s := &Service{
+		log:           log.New("live.mqtt"),
+		metrics:       newMetrics(registerer),
+		enabled:       section.Key("enabled").MustBool(false),
+		configPath:    filepath.Join(cfg.ProvisioningPath, "mqtt"),
+		brokerURL:     section.Key("broker_url").String(),
+		clientID:      section.Key("client_id").MustString("grafana"),
+		username:      section.Key("username").String(),
+		password:      section.Key("password").String(),
+		tlsSkipVerify: section.Key("tls_skip_verify").MustBool(false),
+		maxTopics:     section.Key("max_topics").MustInt(100),
+		now:           time.Now,
+	}
+
+	if grafanaLive != nil && grafanaLive.ManagedStreamRunner != nil {
+		s.publish = func(ctx context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error {
+			stream, err := grafanaLive.ManagedStreamRunner.GetOrCreateStream(orgID, channel.Scope, channel.Namespace)
+			if err != nil {
+				return err
+			}
+			return stream.Push(ctx, channel.Path, frame)
+		}
+	}
+	return s
+}
+
+// IsDisabled returns true if the bridge is disabled, if no broker is set or if Live is not available.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled || s.brokerURL == "" || s.publish == nil
+}
+
+// Run subscribes to the provisioned topics until the context is cancelled. The client reconnects to
+// the broker, and subscribes again, when the connection is lost.
+func (s *Service) Run(ctx context.Context) error {
+	topics, err := readConfig(s.configPath)
+	if err != nil {
+		// The bridge is not critical for Grafana, so the error is logged instead of stopping the server.
+		s.log.Error("Failed to read MQTT topics", "path", s.configPath, "error", err)
+		return nil
+	}
+	if len(topics) == 0 {
+		s.log.Debug("No MQTT topics to subscribe to", "path", s.configPath)
+		return nil
+	}
+	if len(topics) > s.maxTopics {
+		s.log.Warn("Too many MQTT topics, only the first ones are subscribed to", "count", len(topics), "max", s.maxTopics)
+		topics = topics[:s.maxTopics]
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.brokerURL).
+		SetClientID(s.clientID).
+		SetUsername(s.username).
+		SetPassword(s.password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(10 * time.Second).
+		// Messages are handled concurrently, a slow channel must not block the other topics.
+		SetOrderMatters(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			s.metrics.connected.Set(0)
+			s.log.Warn("Lost connection to the MQTT broker", "broker", s.brokerURL, "error", err)
+		}).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			s.metrics.connected.Set(1)
+			s.log.Info("Connected to the MQTT broker", "broker", s.brokerURL, "topics", len(topics))
+			s.subscribe(ctx, client, topics)
+		})
+	if s.tlsSkipVerify {
+		// nolint:gosec
+		opts.SetTLSConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	client := mqtt.NewClient(opts)
+	// With connect retry, the token completes once connected or when the client is disconnected.
+	client.Connect()
+	<-ctx.Done()
+
+	client.Disconnect(250)
+	s.metrics.connected.Set(0)
+	return nil
+}
+
+// subscribe subscribes to the topics. The subscriptions are made again on every connection, as the
+// client starts clean sessions.
+func (s *Service) subscribe(ctx context.Context, client mqtt.Client, topics []Topic) {
+	for _, t := range topics {
+		t := t
+		token := client.Subscribe(t.Topic, t.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+			s.handleMessage(ctx, t, msg.Topic(), msg.Payload())
+		})
+		go func() {
+			<-token.Done()
+			if err := token.Error(); err != nil {
+				s.log.Error("Failed to subscribe to MQTT topic", "topic", t.Topic, "error", err)
+			}
+		}()
+	}
+}
+
+func (s *Service) handleMessage(ctx context.Context, t Topic, topic string, payload []byte) {
+	logger := s.log.New("topic", topic, "channel", t.Channel.String(), "org", t.OrgID)
+	frame, err := t.toFrame(topic, payload, s.now())
+	if err != nil {
+		status := "failed"
+		if errors.Is(err, errInvalidPayload) {
+			status = "invalid"
+		}
+		s.metrics.messages.WithLabelValues(t.Topic, status).Inc()
+		logger.Warn("Failed to convert MQTT message", "error", err)
+		return
+	}
+
+	if err := s.publish(ctx, t.OrgID, t.Channel, frame); err != nil {
+		s.metrics.messages.WithLabelValues(t.Topic, "failed").Inc()
+		logger.Error("Failed to publish MQTT message", "error", err)
+		return
+	}
+	s.metrics.messages.WithLabelValues(t.Topic, "published").Inc()
+	s.metrics.rows.WithLabelValues(t.Topic).Add(float64(frame.Rows()))
+	logger.Debug("Published MQTT message", "rows", frame.Rows())
+}
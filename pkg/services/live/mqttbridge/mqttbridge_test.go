@@ -0,0 +1,203 @@
+package mqttbridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestReadConfig(t *testing.T) {
+	t.Run("reads the topics of the directory", func(t *testing.T) {
+		topics, err := readConfig("testdata/valid")
+		require.NoError(t, err)
+		require.Len(t, topics, 2)
+
+		topic := topics[0]
+		assert.Equal(t, "sensors/+/climate", topic.Topic)
+		assert.Equal(t, int64(2), topic.OrgID)
+		assert.Equal(t, byte(1), topic.QoS)
+		assert.Equal(t, "stream/sensors/climate", topic.Channel.String())
+		assert.Equal(t, TimeFormatUnix, topic.TimeFormat)
+		assert.Equal(t, map[string]int{"device": 1}, topic.TopicLabels)
+		require.Len(t, topic.Fields, 4)
+		assert.Equal(t, FieldTypeNumber, topic.Fields[0].Type)
+		assert.Equal(t, "celsius", topic.Fields[0].Unit)
+		assert.Equal(t, FieldTypeString, topic.Fields[2].Type)
+		assert.Equal(t, jsonPath{{key: "status"}}, topic.Fields[2].Path)
+
+		topic = topics[1]
+		assert.Equal(t, int64(1), topic.OrgID)
+		assert.Nil(t, topic.TimePath)
+	})
+
+	t.Run("no topics if the directory does not exist", func(t *testing.T) {
+		topics, err := readConfig("testdata/missing")
+		require.NoError(t, err)
+		require.Empty(t, topics)
+	})
+
+	t.Run("fails if a topic is defined twice", func(t *testing.T) {
+		_, err := readConfig("testdata/duplicate")
+		require.ErrorContains(t, err, `topic "sensors/#" is defined in both`)
+	})
+}
+
+func TestTopicConfig(t *testing.T) {
+	valid := func() topicConfig {
+		return topicConfig{
+			Topic:   "sensors/+/climate",
+			Channel: "stream/sensors/climate",
+			Fields:  []fieldConfig{{Name: "temperature"}},
+		}
+	}
+
+	testCases := []struct {
+		desc   string
+		modify func(c *topicConfig)
+		err    string
+	}{
+		{desc: "missing topic", modify: func(c *topicConfig) { c.Topic = "" }, err: "topic is required"},
+		{desc: "invalid wildcard", modify: func(c *topicConfig) { c.Topic = "sensors/a+/climate" }, err: "invalid wildcard"},
+		{desc: "multi level wildcard not last", modify: func(c *topicConfig) { c.Topic = "sensors/#/climate" }, err: "must be the last level"},
+		{desc: "invalid qos", modify: func(c *topicConfig) { c.QoS = 3 }, err: "invalid qos"},
+		{desc: "invalid channel", modify: func(c *topicConfig) { c.Channel = "sensors" }, err: "invalid channel"},
+		{desc: "channel out of stream scope", modify: func(c *topicConfig) { c.Channel = "grafana/dashboard/uid" }, err: "is not in the stream scope"},
+		{desc: "invalid time format", modify: func(c *topicConfig) { c.TimeFormat = "iso" }, err: "invalid time format"},
+		{desc: "label of unknown level", modify: func(c *topicConfig) { c.TopicLabels = map[string]int{"device": 3} }, err: "is not a level"},
+		{desc: "no fields", modify: func(c *topicConfig) { c.Fields = nil }, err: "has no fields"},
+		{desc: "invalid field type", modify: func(c *topicConfig) { c.Fields[0].Type = "date" }, err: "invalid type"},
+		{desc: "invalid field path", modify: func(c *topicConfig) { c.Fields[0].Path = "$.values[a]" }, err: "invalid index"},
+		{desc: "duplicate field", modify: func(c *topicConfig) { c.Fields = append(c.Fields, fieldConfig{Name: "temperature"}) }, err: "defined twice"},
+		{desc: "field named time", modify: func(c *topicConfig) { c.Fields[0].Name = "time" }, err: "defined twice"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := valid()
+			tc.modify(&c)
+			_, err := c.topic()
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	path, err := parseJSONPath("$.readings.values[1][0]")
+	require.NoError(t, err)
+	assert.Equal(t, jsonPath{{key: "readings"}, {key: "values"}, {index: 1, isIndex: true}, {index: 0, isIndex: true}}, path)
+
+	path, err = parseJSONPath("temperature")
+	require.NoError(t, err)
+	assert.Equal(t, jsonPath{{key: "temperature"}}, path)
+
+	path, err = parseJSONPath("$[2].value")
+	require.NoError(t, err)
+	assert.Equal(t, jsonPath{{index: 2, isIndex: true}, {key: "value"}}, path)
+
+	for _, invalid := range []string{"$", "", "$.a..b", "$.a[", "$.a[-1]", "$.a[1]b"} {
+		_, err := parseJSONPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestToFrame(t *testing.T) {
+	topics, err := readConfig("testdata/valid")
+	require.NoError(t, err)
+	topic := topics[0]
+	received := time.Date(2023, 11, 7, 10, 0, 0, 0, time.UTC)
+
+	t.Run("converts an object to a row", func(t *testing.T) {
+		payload := `{"ts": 1699351200.5, "readings": {"temperature": 21.5, "values": [10, "45.5"]}, "status": "ok", "state": {"online": true}}`
+		frame, err := topic.toFrame("sensors/kitchen/climate", []byte(payload), received)
+		require.NoError(t, err)
+
+		labels := data.Labels{"device": "kitchen"}
+		temperature := data.NewField("temperature", labels, []*float64{p(21.5)}).SetConfig(&data.FieldConfig{Unit: "celsius"})
+		expected := data.NewFrame("climate",
+			data.NewField("time", nil, []time.Time{time.UnixMilli(1699351200500).UTC()}),
+			temperature,
+			data.NewField("humidity", labels, []*float64{p(45.5)}),
+			data.NewField("status", labels, []*string{p("ok")}),
+			data.NewField("online", labels, []*bool{p(true)}),
+		)
+		assert.Equal(t, expected, frame)
+	})
+
+	t.Run("converts an array to rows with null values for missing values", func(t *testing.T) {
+		payload := `[{"readings": {"temperature": 20}}, {"ts": 1699351200, "status": 3, "state": {"online": "false"}}]`
+		frame, err := topic.toFrame("sensors/garage/climate", []byte(payload), received)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+
+		assert.Equal(t, received, frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1699351200, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, p(20.0), frame.Fields[1].At(0))
+		assert.Nil(t, frame.Fields[1].At(1))
+		assert.Equal(t, p("3"), frame.Fields[3].At(1))
+		assert.Equal(t, p(false), frame.Fields[4].At(1))
+	})
+
+	testCases := []struct {
+		desc    string
+		payload string
+		err     string
+	}{
+		{desc: "invalid JSON", payload: `{"readings":`, err: "invalid payload"},
+		{desc: "row is not an object", payload: `[1]`, err: "row 0 is not an object"},
+		{desc: "invalid number", payload: `{"readings": {"temperature": "warm"}}`, err: `value of field "temperature" is not a number`},
+		{desc: "invalid boolean", payload: `{"state": {"online": 1}}`, err: `value of field "online" is not a boolean`},
+		{desc: "invalid time", payload: `{"ts": "2023-11-07T10:00:00Z"}`, err: "is not in the unix format"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := topic.toFrame("sensors/kitchen/climate", []byte(tc.payload), received)
+			require.ErrorIs(t, err, errInvalidPayload)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	topics, err := readConfig("testdata/valid")
+	require.NoError(t, err)
+	topic := topics[1]
+
+	var published []liveDto.Channel
+	var publishErr error
+	s := &Service{
+		log:     log.NewNopLogger(),
+		metrics: newMetrics(nil),
+		publish: func(_ context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error {
+			assert.Equal(t, int64(1), orgID)
+			published = append(published, channel)
+			return publishErr
+		},
+		now: time.Now,
+	}
+
+	s.handleMessage(context.Background(), topic, "factory/line1", []byte(`[{"count": 1}, {"count": 2}]`))
+	require.Len(t, published, 1)
+	assert.Equal(t, "stream/factory/events", published[0].String())
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.messages.WithLabelValues("factory/#", "published")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.rows.WithLabelValues("factory/#")))
+
+	s.handleMessage(context.Background(), topic, "factory/line1", []byte(`not json`))
+	require.Len(t, published, 1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.messages.WithLabelValues("factory/#", "invalid")))
+
+	publishErr = errors.New("publish failed")
+	s.handleMessage(context.Background(), topic, "factory/line1", []byte(`{"count": 3}`))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.messages.WithLabelValues("factory/#", "failed")))
+}
+
+func p[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,246 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const timeFieldName = "time"
+
+var errInvalidPayload = errors.New("invalid payload")
+
+// jsonPath is a parsed JSON path such as $.sensor.values[0]. Only object keys and array indexes are
+// supported.
+type jsonPath []pathSegment
+
+type pathSegment struct {
+	key   string
+	index int
+	// isIndex is true for array indexes.
+	isIndex bool
+}
+
+func parseJSONPath(path string) (jsonPath, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if rest == "" {
+		return nil, fmt.Errorf("empty path %q", path)
+	}
+
+	var segments jsonPath
+	for _, part := range strings.Split(rest, ".") {
+		key, indexes, hasIndex := strings.Cut(part, "[")
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+		if !hasIndex {
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 || !strings.HasSuffix(indexes, "]") {
+				return nil, fmt.Errorf("invalid index in path %q", path)
+			}
+			segments = append(segments, pathSegment{index: i, isIndex: true})
+		}
+	}
+	return segments, nil
+}
+
+// lookup returns the value at the path, or false if the value does not exist.
+func (p jsonPath) lookup(v any) (any, bool) {
+	for _, s := range p {
+		if s.isIndex {
+			arr, ok := v.([]any)
+			if !ok || s.index >= len(arr) {
+				return nil, false
+			}
+			v = arr[s.index]
+			continue
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[s.key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// toFrame converts the payload of a message to a frame with a row per JSON object. Payloads are an
+// object or an array of objects. Missing and null values are null values of the fields.
+func (t Topic) toFrame(topic string, payload []byte, received time.Time) (*data.Frame, error) {
+	var decoded any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidPayload, err)
+	}
+	rows, ok := decoded.([]any)
+	if !ok {
+		rows = []any{decoded}
+	}
+
+	labels := t.labels(topic)
+	timeField := data.NewField(timeFieldName, nil, make([]time.Time, 0, len(rows)))
+	fields := make([]*data.Field, 0, len(t.Fields)+1)
+	fields = append(fields, timeField)
+	for _, f := range t.Fields {
+		field := newField(f, labels, len(rows))
+		if f.Unit != "" {
+			field.SetConfig(&data.FieldConfig{Unit: f.Unit})
+		}
+		fields = append(fields, field)
+	}
+
+	for i, row := range rows {
+		if _, ok := row.(map[string]any); !ok {
+			return nil, fmt.Errorf("%w: row %d is not an object", errInvalidPayload, i)
+		}
+		ts, err := t.rowTime(row, received)
+		if err != nil {
+			return nil, err
+		}
+		timeField.Append(ts)
+
+		for j, f := range t.Fields {
+			v, err := fieldValue(f, row)
+			if err != nil {
+				return nil, err
+			}
+			fields[j+1].Set(i, v)
+		}
+	}
+	return data.NewFrame(t.Channel.Path, fields...), nil
+}
+
+// labels returns the labels of the fields, read from the levels of the topic of the message.
+func (t Topic) labels(topic string) data.Labels {
+	if len(t.TopicLabels) == 0 {
+		return nil
+	}
+	levels := strings.Split(topic, "/")
+	labels := make(data.Labels, len(t.TopicLabels))
+	for name, level := range t.TopicLabels {
+		if level < len(levels) {
+			labels[name] = levels[level]
+		}
+	}
+	return labels
+}
+
+func (t Topic) rowTime(row any, received time.Time) (time.Time, error) {
+	if t.TimePath == nil {
+		return received, nil
+	}
+	v, ok := t.TimePath.lookup(row)
+	if !ok || v == nil {
+		return received, nil
+	}
+
+	switch ts := v.(type) {
+	case float64:
+		switch t.TimeFormat {
+		case TimeFormatUnix:
+			return time.UnixMilli(int64(ts * 1000)).UTC(), nil
+		case "", TimeFormatUnixMs:
+			return time.UnixMilli(int64(ts)).UTC(), nil
+		}
+	case string:
+		if t.TimeFormat == "" || t.TimeFormat == TimeFormatRFC3339 {
+			parsed, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: invalid time %q", errInvalidPayload, ts)
+			}
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: time %v is not in the %s format", errInvalidPayload, v, t.timeFormat(v))
+}
+
+func (t Topic) timeFormat(v any) string {
+	if t.TimeFormat != "" {
+		return t.TimeFormat
+	}
+	if _, ok := v.(string); ok {
+		return TimeFormatRFC3339
+	}
+	return TimeFormatUnixMs
+}
+
+func newField(f Field, labels data.Labels, length int) *data.Field {
+	switch f.Type {
+	case FieldTypeString:
+		return data.NewField(f.Name, labels, make([]*string, length))
+	case FieldTypeBoolean:
+		return data.NewField(f.Name, labels, make([]*bool, length))
+	default:
+		return data.NewField(f.Name, labels, make([]*float64, length))
+	}
+}
+
+// fieldValue returns the value of a field in a row as a pointer of the type of the field. Numbers and
+// booleans may be sent as strings.
+func fieldValue(f Field, row any) (any, error) {
+	v, ok := f.Path.lookup(row)
+	if !ok || v == nil {
+		switch f.Type {
+		case FieldTypeString:
+			return (*string)(nil), nil
+		case FieldTypeBoolean:
+			return (*bool)(nil), nil
+		default:
+			return (*float64)(nil), nil
+		}
+	}
+
+	switch f.Type {
+	case FieldTypeString:
+		var s string
+		switch value := v.(type) {
+		case string:
+			s = value
+		case float64:
+			s = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			s = strconv.FormatBool(value)
+		default:
+			return nil, fmt.Errorf("%w: value of field %q is not a string", errInvalidPayload, f.Name)
+		}
+		return &s, nil
+	case FieldTypeBoolean:
+		switch value := v.(type) {
+		case bool:
+			return &value, nil
+		case string:
+			if b, err := strconv.ParseBool(value); err == nil {
+				return &b, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: value of field %q is not a boolean", errInvalidPayload, f.Name)
+	default:
+		switch value := v.(type) {
+		case float64:
+			return &value, nil
+		case bool:
+			n := 0.0
+			if value {
+				n = 1
+			}
+			return &n, nil
+		case string:
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				return &n, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: value of field %q is not a number", errInvalidPayload, f.Name)
+	}
+}
@@ -8,8 +8,10 @@ import (
 
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/convert"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
@@ -21,13 +23,22 @@ var (
 	logger = log.New("live.push_http")
 )
 
-func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Gateway {
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive, dataSourceService datasources.DataSourceService,
+	httpClientProvider httpclient.Provider) *Gateway {
 	logger.Info("Live Push Gateway initialization")
 	g := &Gateway{
 		Cfg:         cfg,
 		GrafanaLive: live,
 		converter:   convert.NewConverter(),
 	}
+	if cfg.LiveRemoteWriteDatasourceUID != "" {
+		g.forwarder = &remoteWriteForwarder{
+			dataSources:        dataSourceService,
+			httpClientProvider: httpClientProvider,
+			dataSourceUID:      cfg.LiveRemoteWriteDatasourceUID,
+			path:               cfg.LiveRemoteWritePath,
+		}
+	}
 	return g
 }
 
@@ -37,6 +48,9 @@ type Gateway struct {
 	GrafanaLive *live.GrafanaLive
 
 	converter *convert.Converter
+	// forwarder writes the remote write requests to a data source, nil when
+	// forwarding is disabled.
+	forwarder *remoteWriteForwarder
 }
 
 // Run Gateway.
@@ -98,6 +112,68 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	ctx.Resp.WriteHeader(http.StatusOK)
 }
 
+// HandleRemoteWrite receives a Prometheus remote write request and pushes the
+// samples to the stream, a frame per metric. The request is also written to the
+// configured data source, before the samples are pushed, so that a client
+// retrying a failed request does not publish the samples twice.
+func (g *Gateway) HandleRemoteWrite(ctx *contextmodel.ReqContext) {
+	streamID := web.Params(ctx.Req)[":streamId"]
+
+	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
+	if err != nil {
+		logger.Error("Error getting stream", "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	frameFormat := pushurl.FrameFormatFromValues(ctx.Req.URL.Query())
+
+	body, err := io.ReadAll(ctx.Req.Body)
+	if err != nil {
+		logger.Error("Error reading body", "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logger.Debug("Live Push request",
+		"protocol", "prometheus_remote_write",
+		"streamId", streamID,
+		"bodyLength", len(body),
+		"frameFormat", frameFormat,
+	)
+
+	// Remote write clients retry 5xx responses and drop the requests on 4xx
+	// responses, invalid requests must not be retried.
+	metricFrames, err := g.converter.ConvertRemoteWrite(body, frameFormat)
+	if err != nil {
+		logger.Warn("Error converting remote write request", "error", err, "frameFormat", frameFormat)
+		if errors.Is(err, convert.ErrUnsupportedFrameFormat) || errors.Is(err, convert.ErrInvalidRemoteWrite) {
+			ctx.Resp.WriteHeader(http.StatusBadRequest)
+		} else {
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if g.forwarder != nil {
+		if err := g.forwarder.forward(ctx.Req.Context(), ctx.SignedInUser.OrgID, body); err != nil {
+			logger.Error("Error forwarding remote write request", "error", err, "datasourceUid", g.forwarder.dataSourceUID)
+			ctx.Resp.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	}
+
+	for _, mf := range metricFrames {
+		err := stream.Push(ctx.Req.Context(), mf.Key(), mf.Frame())
+		if err != nil {
+			logger.Error("Error pushing frame", "error", err, "key", mf.Key())
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx.Resp.WriteHeader(http.StatusNoContent)
+}
+
 func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
 
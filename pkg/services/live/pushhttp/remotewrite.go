@@ -0,0 +1,60 @@
+package pushhttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const remoteWriteTimeout = 30 * time.Second
+
+// remoteWriteForwarder writes remote write requests to the remote write endpoint
+// of a Prometheus data source, using the authentication configured for the data
+// source.
+type remoteWriteForwarder struct {
+	dataSources        datasources.DataSourceService
+	httpClientProvider httpclient.Provider
+	dataSourceUID      string
+	path               string
+}
+
+func (f *remoteWriteForwarder) forward(ctx context.Context, orgID int64, body []byte) error {
+	ds, err := f.dataSources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: f.dataSourceUID, OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to get the remote write data source: %w", err)
+	}
+	transport, err := f.dataSources.GetHTTPTransport(ctx, ds, f.httpClientProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create the transport of the remote write data source: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteWriteTimeout)
+	defer cancel()
+	url := strings.TrimSuffix(ds.URL, "/") + "/" + strings.TrimPrefix(f.path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the remote write request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response from the remote write endpoint: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -105,17 +105,17 @@ func sanitize(name string, table table) (string, bool) {
 	return name, true
 }
 
-// sanitizeMetricName checks if the name is a valid Prometheus metric name.  If
+// SanitizeMetricName checks if the name is a valid Prometheus metric name.  If
 // not, it attempts to replaces invalid runes with an underscore to create a
 // valid name.
-func sanitizeMetricName(name string) (string, bool) {
+func SanitizeMetricName(name string) (string, bool) {
 	return sanitize(name, metricNameTable)
 }
 
-// sanitizeLabelName checks if the name is a valid Prometheus label name.  If
+// SanitizeLabelName checks if the name is a valid Prometheus label name.  If
 // not, it attempts to replaces invalid runes with an underscore to create a
 // valid name.
-func sanitizeLabelName(name string) (string, bool) {
+func SanitizeLabelName(name string) (string, bool) {
 	return sanitize(name, labelNameTable)
 }
 
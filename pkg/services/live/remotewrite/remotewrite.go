@@ -40,7 +40,7 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 				continue
 			}
 			metricName := makeMetricName(frame, field)
-			metricName, ok := sanitizeMetricName(metricName)
+			metricName, ok := SanitizeMetricName(metricName)
 			if !ok {
 				continue
 			}
@@ -134,7 +134,7 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 				continue
 			}
 			metricName := makeMetricName(frame, field)
-			metricName, ok := sanitizeMetricName(metricName)
+			metricName, ok := SanitizeMetricName(metricName)
 			if !ok {
 				continue
 			}
@@ -218,6 +218,19 @@ func TimeSeriesToBytes(ts []prompb.TimeSeries) ([]byte, error) {
 	return snappy.Encode(nil, writeRequestData), nil
 }
 
+// TimeSeriesFromBytes decodes a snappy compressed Prometheus remote write request.
+func TimeSeriesFromBytes(body []byte) ([]prompb.TimeSeries, error) {
+	writeRequestData, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress snappy: %w", err)
+	}
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(writeRequestData, &req); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal protobuf: %w", err)
+	}
+	return req.Timeseries, nil
+}
+
 func makeMetricKey(name string, labels []prompb.Label) metricKey {
 	h := fnv.New64a()
 	_, _ = h.Write([]byte(name))
@@ -233,7 +246,7 @@ func makeMetricKey(name string, labels []prompb.Label) metricKey {
 func createLabels(fieldLabels map[string]string) []prompb.Label {
 	labels := make([]prompb.Label, 0, len(fieldLabels))
 	for k, v := range fieldLabels {
-		sanitizedName, ok := sanitizeLabelName(k)
+		sanitizedName, ok := SanitizeLabelName(k)
 		if !ok {
 			continue
 		}
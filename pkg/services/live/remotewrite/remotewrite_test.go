@@ -85,3 +85,20 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTimeSeriesFromBytes(t *testing.T) {
+	t1 := time.Now()
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"host": "a"}, []float64{1.0}),
+	)
+	body, err := Serialize(frame)
+	require.NoError(t, err)
+
+	ts, err := TimeSeriesFromBytes(body)
+	require.NoError(t, err)
+	require.Equal(t, TimeSeriesFromFrames(frame), ts)
+
+	_, err = TimeSeriesFromBytes([]byte("not snappy"))
+	require.Error(t, err)
+}
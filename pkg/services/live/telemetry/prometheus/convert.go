@@ -0,0 +1,187 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+const metricNameLabel = "__name__"
+
+// Converter converts Prometheus remote write requests to data frames.
+type Converter struct {
+	useLabelsColumn bool
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(h *Converter) {
+		h.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from Prometheus remote write format to Grafana Data Frames.
+// The converter produces one frame per metric and timestamp, with a value field per series,
+// or one frame per metric with a labels column when WithUseLabelsColumn is set.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert a snappy compressed remote write request. Stale markers and series without
+// a metric name are skipped.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	series, err := remotewrite.TimeSeriesFromBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing remote write request: %w", err)
+	}
+
+	samples := make([]sample, 0, len(series))
+	for _, ts := range series {
+		name, labels := splitLabels(ts.Labels)
+		if name == "" {
+			continue
+		}
+		for _, s := range ts.Samples {
+			if value.IsStaleNaN(s.Value) {
+				continue
+			}
+			samples = append(samples, sample{
+				name:   name,
+				labels: labels,
+				time:   time.UnixMilli(s.Timestamp).UTC(),
+				value:  s.Value,
+			})
+		}
+	}
+
+	// Frames keep the order of the metrics in the request, rows and fields are ordered by time.
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].time.Before(samples[j].time)
+	})
+
+	if c.useLabelsColumn {
+		return convertWithLabelsColumn(samples, metricOrder(series)), nil
+	}
+	return convertWideFields(samples, metricOrder(series)), nil
+}
+
+type sample struct {
+	name   string
+	labels data.Labels
+	time   time.Time
+	value  float64
+}
+
+type metricFrame struct {
+	key    string
+	fields []*data.Field
+}
+
+// Key returns a key which describes Frame metrics.
+func (s *metricFrame) Key() string {
+	return s.key
+}
+
+// Frame transforms metricFrame to Grafana data.Frame.
+func (s *metricFrame) Frame() *data.Frame {
+	return data.NewFrame(s.key, s.fields...)
+}
+
+func convertWideFields(samples []sample, order []string) []telemetry.FrameWrapper {
+	// A frame per metric and timestamp, holding the value of every series at that time.
+	frames := make(map[string][]*metricFrame)
+	byTime := make(map[string]*metricFrame)
+	for _, s := range samples {
+		frameKey := s.name + "_" + s.time.String()
+		frame, ok := byTime[frameKey]
+		if !ok {
+			frame = &metricFrame{
+				key:    s.name,
+				fields: []*data.Field{data.NewField("time", nil, []time.Time{s.time})},
+			}
+			byTime[frameKey] = frame
+			frames[s.name] = append(frames[s.name], frame)
+		}
+		frame.fields = append(frame.fields, data.NewField("value", s.labels, []float64{s.value}))
+	}
+
+	frameWrappers := make([]telemetry.FrameWrapper, 0, len(byTime))
+	for _, name := range order {
+		for _, frame := range frames[name] {
+			frameWrappers = append(frameWrappers, frame)
+		}
+	}
+	return frameWrappers
+}
+
+func convertWithLabelsColumn(samples []sample, order []string) []telemetry.FrameWrapper {
+	frames := make(map[string]*metricFrame)
+	for _, s := range samples {
+		frame, ok := frames[s.name]
+		if !ok {
+			frame = &metricFrame{
+				key: s.name,
+				fields: []*data.Field{
+					data.NewField("labels", nil, []string{}),
+					data.NewField("time", nil, []time.Time{}),
+					data.NewField("value", nil, []float64{}),
+				},
+			}
+			frames[s.name] = frame
+		}
+		frame.fields[0].Append(s.labels.String())
+		frame.fields[1].Append(s.time)
+		frame.fields[2].Append(s.value)
+	}
+
+	frameWrappers := make([]telemetry.FrameWrapper, 0, len(frames))
+	for _, name := range order {
+		if frame, ok := frames[name]; ok {
+			frameWrappers = append(frameWrappers, frame)
+		}
+	}
+	return frameWrappers
+}
+
+// splitLabels returns the metric name of the series and its other labels.
+func splitLabels(labels []prompb.Label) (string, data.Labels) {
+	var name string
+	result := make(data.Labels, len(labels))
+	for _, l := range labels {
+		if l.Name == metricNameLabel {
+			name = l.Value
+			continue
+		}
+		result[l.Name] = l.Value
+	}
+	return name, result
+}
+
+// metricOrder returns the unique metric names in the order they appear in the request.
+func metricOrder(series []prompb.TimeSeries) []string {
+	seen := make(map[string]struct{})
+	var order []string
+	for _, ts := range series {
+		name, _ := splitLabels(ts.Labels)
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		order = append(order, name)
+	}
+	return order
+}
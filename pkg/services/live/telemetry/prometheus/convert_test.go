@@ -0,0 +1,84 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+func writeRequest(t *testing.T) []byte {
+	t.Helper()
+	body, err := remotewrite.TimeSeriesToBytes([]prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "cpu"}, {Name: "host", Value: "a"}},
+			Samples: []prompb.Sample{{Timestamp: 2000, Value: 2}, {Timestamp: 1000, Value: 1}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "mem"}, {Name: "host", Value: "a"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 10}, {Timestamp: 3000, Value: math.Float64frombits(value.StaleNaN)}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "cpu"}, {Name: "host", Value: "b"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 3}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "host", Value: "c"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 4}},
+		},
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestConverter_Convert(t *testing.T) {
+	converter := NewConverter()
+	frameWrappers, err := converter.Convert(writeRequest(t))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 3)
+
+	// cpu at 1000 with both hosts, cpu at 2000, mem at 1000.
+	require.Equal(t, "cpu", frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, time.UnixMilli(1000).UTC(), frame.Fields[0].At(0))
+	require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+	require.Equal(t, 1.0, frame.Fields[1].At(0))
+	require.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+	require.Equal(t, 3.0, frame.Fields[2].At(0))
+
+	require.Equal(t, "cpu", frameWrappers[1].Key())
+	require.Equal(t, 2.0, frameWrappers[1].Frame().Fields[1].At(0))
+
+	require.Equal(t, "mem", frameWrappers[2].Key())
+	require.Equal(t, 1, frameWrappers[2].Frame().Rows())
+}
+
+func TestConverter_Convert_LabelsColumn(t *testing.T) {
+	converter := NewConverter(WithUseLabelsColumn(true))
+	frameWrappers, err := converter.Convert(writeRequest(t))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	require.Equal(t, "cpu", frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, "host=a", frame.Fields[0].At(0))
+	require.Equal(t, "host=b", frame.Fields[0].At(1))
+	require.Equal(t, time.UnixMilli(2000).UTC(), frame.Fields[1].At(2))
+	require.Equal(t, 2.0, frame.Fields[2].At(2))
+
+	require.Equal(t, "mem", frameWrappers[1].Key())
+	require.Equal(t, 1, frameWrappers[1].Frame().Rows())
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	_, err := NewConverter().Convert([]byte("invalid"))
+	require.Error(t, err)
+}
@@ -8,17 +8,25 @@ import (
 
 type AuthInfoService interface {
 	GetAuthInfo(ctx context.Context, query *GetAuthInfoQuery) (*UserAuth, error)
+	// GetUserAuthInfos returns the identities linked to a user, without their OAuth tokens.
+	GetUserAuthInfos(ctx context.Context, query *GetUserAuthInfosQuery) ([]*UserAuth, error)
 	GetUserLabels(ctx context.Context, query GetUserLabelsQuery) (map[int64]string, error)
 	SetAuthInfo(ctx context.Context, cmd *SetAuthInfoCommand) error
 	UpdateAuthInfo(ctx context.Context, cmd *UpdateAuthInfoCommand) error
+	SetPrimaryAuthInfo(ctx context.Context, cmd *SetPrimaryAuthInfoCommand) error
+	UnlinkAuthInfo(ctx context.Context, cmd *UnlinkAuthInfoCommand) error
 	DeleteUserAuthInfo(ctx context.Context, userID int64) error
 }
 
 type Store interface {
 	GetAuthInfo(ctx context.Context, query *GetAuthInfoQuery) (*UserAuth, error)
+	// GetUserAuthInfos returns the identities linked to a user, without their OAuth tokens.
+	GetUserAuthInfos(ctx context.Context, query *GetUserAuthInfosQuery) ([]*UserAuth, error)
 	GetUserLabels(ctx context.Context, query GetUserLabelsQuery) (map[int64]string, error)
 	SetAuthInfo(ctx context.Context, cmd *SetAuthInfoCommand) error
 	UpdateAuthInfo(ctx context.Context, cmd *UpdateAuthInfoCommand) error
+	SetPrimaryAuthInfo(ctx context.Context, cmd *SetPrimaryAuthInfoCommand) error
+	UnlinkAuthInfo(ctx context.Context, cmd *UnlinkAuthInfoCommand) error
 	DeleteUserAuthInfo(ctx context.Context, userID int64) error
 }
 
@@ -32,6 +40,7 @@ const (
 	JWTModule           = "jwt"
 	ExtendedJWTModule   = "extendedjwt"
 	RenderModule        = "render"
+	WebAuthnAuthModule  = "webauthn"
 	// OAuth provider modules
 	AzureADAuthModule    = "oauth_azuread"
 	GoogleAuthModule     = "oauth_google"
@@ -25,6 +25,10 @@ func (s *Service) GetAuthInfo(ctx context.Context, query *login.GetAuthInfoQuery
 	return s.authInfoStore.GetAuthInfo(ctx, query)
 }
 
+func (s *Service) GetUserAuthInfos(ctx context.Context, query *login.GetUserAuthInfosQuery) ([]*login.UserAuth, error) {
+	return s.authInfoStore.GetUserAuthInfos(ctx, query)
+}
+
 func (s *Service) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuery) (map[int64]string, error) {
 	if len(query.UserIDs) == 0 {
 		return map[int64]string{}, nil
@@ -40,6 +44,25 @@ func (s *Service) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand
 	return s.authInfoStore.SetAuthInfo(ctx, cmd)
 }
 
+func (s *Service) SetPrimaryAuthInfo(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error {
+	return s.authInfoStore.SetPrimaryAuthInfo(ctx, cmd)
+}
+
+// UnlinkAuthInfo removes an identity of a user. The primary identity can only be unlinked after another
+// identity is made primary.
+func (s *Service) UnlinkAuthInfo(ctx context.Context, cmd *login.UnlinkAuthInfoCommand) error {
+	userAuths, err := s.authInfoStore.GetUserAuthInfos(ctx, &login.GetUserAuthInfosQuery{UserID: cmd.UserID})
+	if err != nil {
+		return err
+	}
+	for _, userAuth := range userAuths {
+		if userAuth.AuthModule == cmd.AuthModule && userAuth.IsPrimary {
+			return login.ErrPrimaryAuthInfo
+		}
+	}
+	return s.authInfoStore.UnlinkAuthInfo(ctx, cmd)
+}
+
 func (s *Service) DeleteUserAuthInfo(ctx context.Context, userID int64) error {
 	return s.authInfoStore.DeleteUserAuthInfo(ctx, userID)
 }
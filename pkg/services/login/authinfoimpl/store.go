@@ -47,7 +47,7 @@ func (s *Store) GetAuthInfo(ctx context.Context, query *login.GetAuthInfoQuery)
 	var err error
 
 	err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		has, err = sess.Desc("created").Get(userAuth)
+		has, err = sess.Desc("created", "id").Get(userAuth)
 		return err
 	})
 	if err != nil {
@@ -82,6 +82,21 @@ func (s *Store) GetAuthInfo(ctx context.Context, query *login.GetAuthInfoQuery)
 	return userAuth, nil
 }
 
+func (s *Store) GetUserAuthInfos(ctx context.Context, query *login.GetUserAuthInfosQuery) ([]*login.UserAuth, error) {
+	userAuths := make([]*login.UserAuth, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("user_auth").
+			Cols("id", "user_id", "auth_module", "auth_id", "is_primary", "created").
+			Where("user_id = ?", query.UserID).
+			Desc("is_primary").Asc("created").
+			Find(&userAuths)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userAuths, nil
+}
+
 func (s *Store) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuery) (map[int64]string, error) {
 	userAuths := []login.UserAuth{}
 	params := make([]interface{}, 0, len(query.UserIDs))
@@ -90,7 +105,8 @@ func (s *Store) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuer
 	}
 
 	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.Table("user_auth").In("user_id", params).OrderBy("created").Find(&userAuths)
+		// The primary auth info is last to be the label of the user
+		return sess.Table("user_auth").In("user_id", params).OrderBy("is_primary, created").Find(&userAuths)
 	})
 
 	if err != nil {
@@ -111,6 +127,7 @@ func (s *Store) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand)
 		UserId:     cmd.UserId,
 		AuthModule: cmd.AuthModule,
 		AuthId:     cmd.AuthId,
+		IsPrimary:  cmd.IsPrimary,
 		Created:    GetTime(),
 	}
 
@@ -218,6 +235,39 @@ func (s *Store) UpdateAuthInfo(ctx context.Context, cmd *login.UpdateAuthInfoCom
 	})
 }
 
+func (s *Store) SetPrimaryAuthInfo(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Table("user_auth").Where("user_id = ? AND auth_module = ?", cmd.UserID, cmd.AuthModule).Exist()
+		if err != nil {
+			return err
+		}
+		if !has {
+			return login.ErrAuthInfoNotFound
+		}
+
+		if _, err := sess.Table("user_auth").Cols("is_primary").Where("user_id = ?", cmd.UserID).
+			Update(&login.UserAuth{IsPrimary: false}); err != nil {
+			return err
+		}
+		_, err = sess.Table("user_auth").Cols("is_primary").Where("user_id = ? AND auth_module = ?", cmd.UserID, cmd.AuthModule).
+			Update(&login.UserAuth{IsPrimary: true})
+		return err
+	})
+}
+
+func (s *Store) UnlinkAuthInfo(ctx context.Context, cmd *login.UnlinkAuthInfoCommand) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("user_id = ? AND auth_module = ?", cmd.UserID, cmd.AuthModule).Delete(&login.UserAuth{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return login.ErrAuthInfoNotFound
+		}
+		return nil
+	})
+}
+
 func (s *Store) DeleteUserAuthInfo(ctx context.Context, userID int64) error {
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
 		var rawSQL = "DELETE FROM user_auth WHERE user_id = ?"
@@ -119,6 +119,63 @@ func TestIntegrationAuthInfoStore(t *testing.T) {
 		count = countEntries(t, sql, setCmd.AuthModule, setCmd.AuthId, setCmd.UserId)
 		require.Equal(t, 1, count)
 	})
+
+	t.Run("should label users with their primary auth info", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
+			AuthModule: login.AzureADAuthModule,
+			AuthId:     "azure-20",
+			UserId:     20,
+			IsPrimary:  true,
+		}))
+		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
+			AuthModule: login.GithubAuthModule,
+			AuthId:     "github-20",
+			UserId:     20,
+		}))
+		require.NoError(t, store.UpdateAuthInfo(ctx, &login.UpdateAuthInfoCommand{
+			AuthModule: login.GithubAuthModule,
+			AuthId:     "github-20",
+			UserId:     20,
+		}))
+
+		// the latest used auth info holds the OAuth token of the session
+		info, err := store.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: 20})
+		require.NoError(t, err)
+		assert.Equal(t, login.GithubAuthModule, info.AuthModule)
+		assert.False(t, info.IsPrimary)
+
+		labels, err := store.GetUserLabels(ctx, login.GetUserLabelsQuery{UserIDs: []int64{20}})
+		require.NoError(t, err)
+		assert.Equal(t, login.AzureADAuthModule, labels[20])
+
+		require.NoError(t, store.SetPrimaryAuthInfo(ctx, &login.SetPrimaryAuthInfoCommand{UserID: 20, AuthModule: login.GithubAuthModule}))
+		infos, err := store.GetUserAuthInfos(ctx, &login.GetUserAuthInfosQuery{UserID: 20})
+		require.NoError(t, err)
+		require.Len(t, infos, 2)
+		assert.Equal(t, login.GithubAuthModule, infos[0].AuthModule)
+		assert.True(t, infos[0].IsPrimary)
+		assert.Equal(t, login.AzureADAuthModule, infos[1].AuthModule)
+		assert.False(t, infos[1].IsPrimary)
+
+		err = store.SetPrimaryAuthInfo(ctx, &login.SetPrimaryAuthInfoCommand{UserID: 20, AuthModule: login.OktaAuthModule})
+		require.ErrorIs(t, err, login.ErrAuthInfoNotFound)
+	})
+
+	t.Run("should unlink an auth info", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{AuthModule: login.AzureADAuthModule, AuthId: "azure-30", UserId: 30, IsPrimary: true}))
+		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{AuthModule: login.GithubAuthModule, AuthId: "github-30", UserId: 30}))
+
+		require.NoError(t, store.UnlinkAuthInfo(ctx, &login.UnlinkAuthInfoCommand{UserID: 30, AuthModule: login.GithubAuthModule}))
+		infos, err := store.GetUserAuthInfos(ctx, &login.GetUserAuthInfosQuery{UserID: 30})
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		assert.Equal(t, login.AzureADAuthModule, infos[0].AuthModule)
+
+		err = store.UnlinkAuthInfo(ctx, &login.UnlinkAuthInfoCommand{UserID: 30, AuthModule: login.GithubAuthModule})
+		require.ErrorIs(t, err, login.ErrAuthInfoNotFound)
+	})
 }
 
 func countEntries(t *testing.T, sql db.DB, authModule, authID string, userID int64) int {
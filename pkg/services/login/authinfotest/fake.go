@@ -13,16 +13,27 @@ type FakeService struct {
 	ExpectedExternalUser *login.ExternalUserInfo
 	ExpectedError        error
 	ExpectedLabels       map[int64]string
+	ExpectedUserAuths    []*login.UserAuth
 
-	SetAuthInfoFn    func(ctx context.Context, cmd *login.SetAuthInfoCommand) error
-	UpdateAuthInfoFn func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error
+	GetAuthInfoFn        func(ctx context.Context, query *login.GetAuthInfoQuery) (*login.UserAuth, error)
+	SetAuthInfoFn        func(ctx context.Context, cmd *login.SetAuthInfoCommand) error
+	UpdateAuthInfoFn     func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error
+	SetPrimaryAuthInfoFn func(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error
+	UnlinkAuthInfoFn     func(ctx context.Context, cmd *login.UnlinkAuthInfoCommand) error
 }
 
 func (a *FakeService) GetAuthInfo(ctx context.Context, query *login.GetAuthInfoQuery) (*login.UserAuth, error) {
 	a.LatestUserID = query.UserId
+	if a.GetAuthInfoFn != nil {
+		return a.GetAuthInfoFn(ctx, query)
+	}
 	return a.ExpectedUserAuth, a.ExpectedError
 }
 
+func (a *FakeService) GetUserAuthInfos(ctx context.Context, query *login.GetUserAuthInfosQuery) ([]*login.UserAuth, error) {
+	return a.ExpectedUserAuths, a.ExpectedError
+}
+
 func (a *FakeService) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuery) (map[int64]string, error) {
 	return a.ExpectedLabels, a.ExpectedError
 }
@@ -43,6 +54,22 @@ func (a *FakeService) UpdateAuthInfo(ctx context.Context, cmd *login.UpdateAuthI
 	return a.ExpectedError
 }
 
+func (a *FakeService) SetPrimaryAuthInfo(ctx context.Context, cmd *login.SetPrimaryAuthInfoCommand) error {
+	if a.SetPrimaryAuthInfoFn != nil {
+		return a.SetPrimaryAuthInfoFn(ctx, cmd)
+	}
+
+	return a.ExpectedError
+}
+
+func (a *FakeService) UnlinkAuthInfo(ctx context.Context, cmd *login.UnlinkAuthInfoCommand) error {
+	if a.UnlinkAuthInfoFn != nil {
+		return a.UnlinkAuthInfoFn(ctx, cmd)
+	}
+
+	return a.ExpectedError
+}
+
 func (a *FakeService) DeleteUserAuthInfo(ctx context.Context, userID int64) error {
 	return a.ExpectedError
 }
@@ -1,6 +1,7 @@
 package login
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,11 +13,20 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+var (
+	ErrAuthInfoNotFound = errors.New("auth info not found")
+	// ErrPrimaryAuthInfo is returned when unlinking the primary identity of a user.
+	ErrPrimaryAuthInfo = errors.New("the primary identity of a user cannot be unlinked")
+)
+
 type UserAuth struct {
-	Id                int64
-	UserId            int64
-	AuthModule        string
-	AuthId            string
+	Id         int64
+	UserId     int64
+	AuthModule string
+	AuthId     string
+	// IsPrimary is true for the identity that the user attributes and roles are synced from when the
+	// user has identities of several providers.
+	IsPrimary         bool
 	Created           time.Time
 	OAuthAccessToken  string
 	OAuthRefreshToken string
@@ -70,6 +80,7 @@ type SetAuthInfoCommand struct {
 	AuthId     string
 	UserId     int64
 	OAuthToken *oauth2.Token
+	IsPrimary  bool
 }
 
 type UpdateAuthInfoCommand struct {
@@ -83,6 +94,16 @@ type DeleteAuthInfoCommand struct {
 	UserAuth *UserAuth
 }
 
+type SetPrimaryAuthInfoCommand struct {
+	UserID     int64
+	AuthModule string
+}
+
+type UnlinkAuthInfoCommand struct {
+	UserID     int64
+	AuthModule string
+}
+
 // ----------------------
 // QUERIES
 
@@ -115,6 +136,10 @@ type GetAuthInfoQuery struct {
 	AuthId     string
 }
 
+type GetUserAuthInfosQuery struct {
+	UserID int64
+}
+
 type GetUserLabelsQuery struct {
 	UserIDs []int64
 }
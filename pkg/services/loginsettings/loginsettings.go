@@ -0,0 +1,67 @@
+package loginsettings
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// ProviderLoginForm is the name of the username and password login form in the visible providers.
+	ProviderLoginForm = "grafana"
+	// ProviderSAML is the name of the SAML login in the visible providers.
+	ProviderSAML = "saml"
+
+	// MaxWelcomeTextLength is the maximum length of the welcome text of the login page.
+	MaxWelcomeTextLength = 2000
+)
+
+var (
+	ErrLoginSettingsNotFound    = errutil.NotFound("loginsettings.not-found", errutil.WithPublicMessage("The login page of the organization is not customized"))
+	ErrLoginSettingsInvalidLogo = errutil.ValidationFailed("loginsettings.invalid-logo", errutil.WithPublicMessage("The logo URL must be an http(s) URL or a path starting with /"))
+	ErrLoginSettingsInvalidText = errutil.ValidationFailed("loginsettings.invalid-welcome-text", errutil.WithPublicMessage("The welcome text is too long"))
+	ErrLoginSettingsInvalidHost = errutil.ValidationFailed("loginsettings.invalid-host", errutil.WithPublicMessage("Invalid host"))
+	ErrLoginSettingsHostTaken   = errutil.Conflict("loginsettings.host-taken", errutil.WithPublicMessage("The host already serves the login page of another organization"))
+)
+
+// LoginSettings customizes the login page of an organization.
+type LoginSettings struct {
+	OrgID int64 `json:"orgId"`
+	// LogoURL replaces the logo of the login page.
+	LogoURL string `json:"logoUrl,omitempty"`
+	// WelcomeText is shown below the logo of the login page.
+	WelcomeText string `json:"welcomeText,omitempty"`
+	// Providers are the auth providers shown on the login page, all of them when empty. The login form is
+	// ProviderLoginForm, SAML is ProviderSAML and the OAuth providers are named by their key.
+	Providers []string `json:"providers,omitempty"`
+	// AutoRedirectProvider is the provider the login page redirects to.
+	AutoRedirectProvider string `json:"autoRedirectProvider,omitempty"`
+	// Hosts are the hosts serving the login page of the organization.
+	Hosts []string `json:"hosts,omitempty"`
+	// RequireTOTP requires the users of the organization to log in with a second factor when they log in with a
+	// password.
+	RequireTOTP bool      `json:"requireTotp,omitempty"`
+	Updated     time.Time `json:"updated"`
+}
+
+// ShowsProvider reports whether the login page shows the provider.
+func (s *LoginSettings) ShowsProvider(provider string) bool {
+	if len(s.Providers) == 0 {
+		return true
+	}
+	for _, p := range s.Providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+type Service interface {
+	Get(ctx context.Context, orgID int64) (*LoginSettings, error)
+	// GetByHost returns the login settings of the organization serving the host.
+	GetByHost(ctx context.Context, host string) (*LoginSettings, error)
+	Save(ctx context.Context, settings *LoginSettings) error
+	Delete(ctx context.Context, orgID int64) error
+}
@@ -0,0 +1,165 @@
+package loginsettingsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+)
+
+const (
+	kvNamespace = "login-settings"
+	kvKey       = "settings"
+)
+
+var timeNow = time.Now
+
+// Service stores the login settings of the organizations in the key-value store.
+type Service struct {
+	kv kvstore.KVStore
+}
+
+func ProvideService(kv kvstore.KVStore) *Service {
+	return &Service{kv: kv}
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64) (*loginsettings.LoginSettings, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, loginsettings.ErrLoginSettingsNotFound.Errorf("no login settings for org %d", orgID)
+	}
+	return decode(orgID, value)
+}
+
+func (s *Service) GetByHost(ctx context.Context, host string) (*loginsettings.LoginSettings, error) {
+	host = normalizeHost(host)
+	all, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, settings := range all {
+		for _, h := range settings.Hosts {
+			if h == host {
+				return settings, nil
+			}
+		}
+	}
+	return nil, loginsettings.ErrLoginSettingsNotFound.Errorf("no login settings for host %s", host)
+}
+
+// Save validates and stores the login settings. A host serves the login page of a single organization.
+func (s *Service) Save(ctx context.Context, settings *loginsettings.LoginSettings) error {
+	if err := validate(settings); err != nil {
+		return err
+	}
+
+	if len(settings.Hosts) > 0 {
+		all, err := s.all(ctx)
+		if err != nil {
+			return err
+		}
+		for _, other := range all {
+			if other.OrgID == settings.OrgID {
+				continue
+			}
+			for _, h := range other.Hosts {
+				for _, host := range settings.Hosts {
+					if h == host {
+						return loginsettings.ErrLoginSettingsHostTaken.Errorf("host %s is used by org %d", host, other.OrgID)
+					}
+				}
+			}
+		}
+	}
+
+	settings.Updated = timeNow()
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, settings.OrgID, kvNamespace, kvKey, string(value))
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64) error {
+	return s.kv.Del(ctx, orgID, kvNamespace, kvKey)
+}
+
+func (s *Service) all(ctx context.Context) ([]*loginsettings.LoginSettings, error) {
+	values, err := s.kv.GetAll(ctx, kvstore.AllOrganizations, kvNamespace)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]*loginsettings.LoginSettings, 0, len(values))
+	for orgID, keys := range values {
+		value, ok := keys[kvKey]
+		if !ok {
+			continue
+		}
+		settings, err := decode(orgID, value)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, settings)
+	}
+	return all, nil
+}
+
+func decode(orgID int64, value string) (*loginsettings.LoginSettings, error) {
+	settings := &loginsettings.LoginSettings{}
+	if err := json.Unmarshal([]byte(value), settings); err != nil {
+		return nil, err
+	}
+	settings.OrgID = orgID
+	return settings, nil
+}
+
+// validate checks the login settings, normalizing the hosts.
+func validate(settings *loginsettings.LoginSettings) error {
+	if settings.LogoURL != "" {
+		u, err := url.Parse(settings.LogoURL)
+		switch {
+		case err != nil:
+			return loginsettings.ErrLoginSettingsInvalidLogo.Errorf("invalid logo URL: %w", err)
+		case u.IsAbs() && u.Scheme != "http" && u.Scheme != "https":
+			return loginsettings.ErrLoginSettingsInvalidLogo.Errorf("invalid logo URL scheme %s", u.Scheme)
+		case !u.IsAbs() && (!strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") || u.Host != ""):
+			return loginsettings.ErrLoginSettingsInvalidLogo.Errorf("invalid logo path %s", settings.LogoURL)
+		}
+	}
+
+	if len(settings.WelcomeText) > loginsettings.MaxWelcomeTextLength {
+		return loginsettings.ErrLoginSettingsInvalidText.Errorf("welcome text of %d characters", len(settings.WelcomeText))
+	}
+
+	hosts := make([]string, 0, len(settings.Hosts))
+	seen := make(map[string]bool, len(settings.Hosts))
+	for _, host := range settings.Hosts {
+		normalized := normalizeHost(host)
+		if normalized == "" || strings.ContainsAny(host, "/@ ") || strings.Contains(normalized, ":") {
+			return loginsettings.ErrLoginSettingsInvalidHost.Errorf("invalid host %q", host)
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			hosts = append(hosts, normalized)
+		}
+	}
+	settings.Hosts = hosts
+	return nil
+}
+
+// normalizeHost lowercases the host and strips its port.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
@@ -0,0 +1,70 @@
+package loginsettingsimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+)
+
+func TestIntegrationLoginSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	svc := ProvideService(kvstore.ProvideService(db.InitTestDB(t)))
+	ctx := context.Background()
+
+	t.Run("Should return not found when the login page is not customized", func(t *testing.T) {
+		_, err := svc.Get(ctx, 1)
+		require.ErrorIs(t, err, loginsettings.ErrLoginSettingsNotFound)
+	})
+
+	t.Run("Should save the settings with normalized hosts", func(t *testing.T) {
+		err := svc.Save(ctx, &loginsettings.LoginSettings{
+			OrgID:       1,
+			LogoURL:     "https://cdn.example.com/logo.svg",
+			WelcomeText: "Welcome to tenant one",
+			Hosts:       []string{"Tenant-One.example.com:3000", "tenant-one.example.com"},
+		})
+		require.NoError(t, err)
+
+		settings, err := svc.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Welcome to tenant one", settings.WelcomeText)
+		assert.Equal(t, []string{"tenant-one.example.com"}, settings.Hosts)
+	})
+
+	t.Run("Should get the settings by host", func(t *testing.T) {
+		settings, err := svc.GetByHost(ctx, "TENANT-ONE.example.com:443")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), settings.OrgID)
+
+		_, err = svc.GetByHost(ctx, "tenant-two.example.com")
+		require.ErrorIs(t, err, loginsettings.ErrLoginSettingsNotFound)
+	})
+
+	t.Run("Should reject a host of another organization", func(t *testing.T) {
+		err := svc.Save(ctx, &loginsettings.LoginSettings{OrgID: 2, Hosts: []string{"tenant-one.example.com"}})
+		require.ErrorIs(t, err, loginsettings.ErrLoginSettingsHostTaken)
+	})
+
+	t.Run("Should validate the settings", func(t *testing.T) {
+		for _, logo := range []string{"javascript:alert(1)", "//evil.example.com/logo.svg", "logo.svg"} {
+			err := svc.Save(ctx, &loginsettings.LoginSettings{OrgID: 2, LogoURL: logo})
+			require.ErrorIs(t, err, loginsettings.ErrLoginSettingsInvalidLogo, logo)
+		}
+		err := svc.Save(ctx, &loginsettings.LoginSettings{OrgID: 2, Hosts: []string{"https://tenant.example.com/"}})
+		require.ErrorIs(t, err, loginsettings.ErrLoginSettingsInvalidHost)
+	})
+
+	t.Run("Should delete the settings", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, 1))
+		_, err := svc.Get(ctx, 1)
+		require.ErrorIs(t, err, loginsettings.ErrLoginSettingsNotFound)
+	})
+}
@@ -0,0 +1,45 @@
+package loginsettingstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+)
+
+type FakeLoginSettingsService struct {
+	// ExpectedSettings are returned by Get and GetByHost, which return ErrLoginSettingsNotFound when nil.
+	ExpectedSettings *loginsettings.LoginSettings
+	ExpectedError    error
+	SavedSettings    *loginsettings.LoginSettings
+}
+
+func NewFakeLoginSettingsService() *FakeLoginSettingsService {
+	return &FakeLoginSettingsService{}
+}
+
+func (f *FakeLoginSettingsService) Get(ctx context.Context, orgID int64) (*loginsettings.LoginSettings, error) {
+	return f.get()
+}
+
+func (f *FakeLoginSettingsService) GetByHost(ctx context.Context, host string) (*loginsettings.LoginSettings, error) {
+	return f.get()
+}
+
+func (f *FakeLoginSettingsService) Save(ctx context.Context, settings *loginsettings.LoginSettings) error {
+	f.SavedSettings = settings
+	return f.ExpectedError
+}
+
+func (f *FakeLoginSettingsService) Delete(ctx context.Context, orgID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeLoginSettingsService) get() (*loginsettings.LoginSettings, error) {
+	if f.ExpectedError != nil {
+		return nil, f.ExpectedError
+	}
+	if f.ExpectedSettings == nil {
+		return nil, loginsettings.ErrLoginSettingsNotFound
+	}
+	return f.ExpectedSettings, nil
+}
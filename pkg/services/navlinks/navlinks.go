@@ -0,0 +1,42 @@
+package navlinks
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// MaxLinks is the maximum number of custom navigation links of an organization.
+	MaxLinks = 50
+	// MaxTitleLength is the maximum length of the title of a custom navigation link.
+	MaxTitleLength = 100
+)
+
+var (
+	ErrInvalidTitle  = errutil.ValidationFailed("navlinks.invalid-title", errutil.WithPublicMessage("The title of a navigation link must be set and at most 100 characters long"))
+	ErrInvalidURL    = errutil.ValidationFailed("navlinks.invalid-url", errutil.WithPublicMessage("The URL of a navigation link must be an http(s) URL or a path starting with /"))
+	ErrInvalidAction = errutil.ValidationFailed("navlinks.invalid-action", errutil.WithPublicMessage("The required action of a navigation link must look like resource:verb"))
+	ErrDuplicateID   = errutil.ValidationFailed("navlinks.duplicate-id", errutil.WithPublicMessage("The ids of the navigation links must be unique"))
+	ErrTooManyLinks  = errutil.ValidationFailed("navlinks.too-many", errutil.WithPublicMessage("An organization can have at most 50 navigation links"))
+)
+
+// Link is an additional navigation item defined by an organization, e.g. a runbook or an internal tool.
+type Link struct {
+	// ID identifies the link in the navigation tree, generated when empty.
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Icon is the name of the icon of the link.
+	Icon string `json:"icon,omitempty"`
+	// URL is either an absolute http(s) URL, opened in a new tab, or a path of the Grafana instance.
+	URL string `json:"url"`
+	// Action is the RBAC action required to see the link, everyone sees it when empty.
+	Action string `json:"action,omitempty"`
+}
+
+type Service interface {
+	// Get returns the navigation links of the organization, in menu order.
+	Get(ctx context.Context, orgID int64) ([]*Link, error)
+	// Save validates and replaces the navigation links of the organization.
+	Save(ctx context.Context, orgID int64, links []*Link) error
+}
@@ -0,0 +1,103 @@
+package navlinksimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/navlinks"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	kvNamespace = "nav-links"
+	kvKey       = "links"
+)
+
+var actionPattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+:[a-zA-Z0-9_.\-:]+$`)
+
+// Service stores the navigation links of the organizations in the key-value store.
+type Service struct {
+	kv kvstore.KVStore
+}
+
+func ProvideService(kv kvstore.KVStore) *Service {
+	return &Service{kv: kv}
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64) ([]*navlinks.Link, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	links := []*navlinks.Link{}
+	if !ok {
+		return links, nil
+	}
+	if err := json.Unmarshal([]byte(value), &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (s *Service) Save(ctx context.Context, orgID int64, links []*navlinks.Link) error {
+	if len(links) == 0 {
+		return s.kv.Del(ctx, orgID, kvNamespace, kvKey)
+	}
+
+	if err := validate(links); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(links)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, kvKey, string(value))
+}
+
+// validate checks the links, trimming their fields and generating the missing ids.
+func validate(links []*navlinks.Link) error {
+	if len(links) > navlinks.MaxLinks {
+		return navlinks.ErrTooManyLinks.Errorf("%d navigation links", len(links))
+	}
+
+	ids := make(map[string]bool, len(links))
+	for _, link := range links {
+		link.ID = strings.TrimSpace(link.ID)
+		link.Title = strings.TrimSpace(link.Title)
+		link.Icon = strings.TrimSpace(link.Icon)
+		link.URL = strings.TrimSpace(link.URL)
+		link.Action = strings.TrimSpace(link.Action)
+
+		if link.Title == "" || len(link.Title) > navlinks.MaxTitleLength {
+			return navlinks.ErrInvalidTitle.Errorf("invalid title %q", link.Title)
+		}
+
+		u, err := url.Parse(link.URL)
+		switch {
+		case link.URL == "" || err != nil:
+			return navlinks.ErrInvalidURL.Errorf("invalid URL %q", link.URL)
+		case u.IsAbs() && (u.Scheme != "http" && u.Scheme != "https" || u.Host == ""):
+			return navlinks.ErrInvalidURL.Errorf("invalid URL %q", link.URL)
+		case !u.IsAbs() && (!strings.HasPrefix(u.Path, "/") || strings.HasPrefix(link.URL, "//") || u.Host != ""):
+			return navlinks.ErrInvalidURL.Errorf("invalid path %q", link.URL)
+		}
+
+		if link.Action != "" && !actionPattern.MatchString(link.Action) {
+			return navlinks.ErrInvalidAction.Errorf("invalid action %q", link.Action)
+		}
+
+		if link.ID == "" {
+			link.ID = util.GenerateShortUID()
+		}
+		if ids[link.ID] {
+			return navlinks.ErrDuplicateID.Errorf("duplicate id %q", link.ID)
+		}
+		ids[link.ID] = true
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package navlinksimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/navlinks"
+)
+
+func TestIntegrationNavLinks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	svc := ProvideService(kvstore.ProvideService(db.InitTestDB(t)))
+	ctx := context.Background()
+
+	t.Run("Should return no links when none are defined", func(t *testing.T) {
+		links, err := svc.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+
+	t.Run("Should save the links of an organization", func(t *testing.T) {
+		err := svc.Save(ctx, 1, []*navlinks.Link{
+			{ID: "runbooks", Title: " Runbooks ", Icon: "book", URL: "https://wiki.example.com/runbooks"},
+			{Title: "On-call", URL: "/d/oncall", Action: "dashboards:read"},
+		})
+		require.NoError(t, err)
+
+		links, err := svc.Get(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, links, 2)
+		assert.Equal(t, "Runbooks", links[0].Title)
+		assert.NotEmpty(t, links[1].ID)
+		assert.Equal(t, "dashboards:read", links[1].Action)
+
+		other, err := svc.Get(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	})
+
+	t.Run("Should remove the links when saving an empty list", func(t *testing.T) {
+		require.NoError(t, svc.Save(ctx, 1, nil))
+
+		links, err := svc.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		link navlinks.Link
+		err  error
+	}{
+		{name: "absolute URL", link: navlinks.Link{Title: "Wiki", URL: "https://wiki.example.com"}},
+		{name: "path", link: navlinks.Link{Title: "Wiki", URL: "/d/abc?orgId=1"}},
+		{name: "missing title", link: navlinks.Link{URL: "/d/abc"}, err: navlinks.ErrInvalidTitle},
+		{name: "javascript URL", link: navlinks.Link{Title: "Wiki", URL: "javascript:alert(1)"}, err: navlinks.ErrInvalidURL},
+		{name: "protocol relative URL", link: navlinks.Link{Title: "Wiki", URL: "//evil.example.com"}, err: navlinks.ErrInvalidURL},
+		{name: "relative path", link: navlinks.Link{Title: "Wiki", URL: "d/abc"}, err: navlinks.ErrInvalidURL},
+		{name: "invalid action", link: navlinks.Link{Title: "Wiki", URL: "/d/abc", Action: "read all"}, err: navlinks.ErrInvalidAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := tt.link
+			err := validate([]*navlinks.Link{&link})
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		err := validate([]*navlinks.Link{
+			{ID: "wiki", Title: "Wiki", URL: "/a"},
+			{ID: "wiki", Title: "Wiki", URL: "/b"},
+		})
+		require.ErrorIs(t, err, navlinks.ErrDuplicateID)
+	})
+}
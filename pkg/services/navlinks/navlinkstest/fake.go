@@ -0,0 +1,32 @@
+package navlinkstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/navlinks"
+)
+
+type FakeNavLinksService struct {
+	ExpectedLinks []*navlinks.Link
+	ExpectedError error
+	SavedLinks    []*navlinks.Link
+}
+
+func NewFakeNavLinksService() *FakeNavLinksService {
+	return &FakeNavLinksService{}
+}
+
+func (f *FakeNavLinksService) Get(ctx context.Context, orgID int64) ([]*navlinks.Link, error) {
+	if f.ExpectedError != nil {
+		return nil, f.ExpectedError
+	}
+	if f.ExpectedLinks == nil {
+		return []*navlinks.Link{}, nil
+	}
+	return f.ExpectedLinks, nil
+}
+
+func (f *FakeNavLinksService) Save(ctx context.Context, orgID int64, links []*navlinks.Link) error {
+	f.SavedLinks = links
+	return f.ExpectedError
+}
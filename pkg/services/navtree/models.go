@@ -29,6 +29,9 @@ const (
 	WeightHelp
 )
 
+// WeightCustomLinks places the navigation links of the organization between the apps and the plugins.
+const WeightCustomLinks = WeightApps + 50
+
 const (
 	NavIDRoot               = "root"
 	NavIDDashboards         = "dashboards/browse"
@@ -42,6 +45,7 @@ const (
 	NavIDFrontend           = "frontend"
 	NavIDReporting          = "reports"
 	NavIDApps               = "apps"
+	NavIDCustomLinks        = "custom-links"
 	NavIDCfgGeneral         = "cfg/general"
 	NavIDCfgPlugins         = "cfg/plugins"
 	NavIDCfgAccess          = "cfg/access"
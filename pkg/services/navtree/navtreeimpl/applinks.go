@@ -25,20 +25,8 @@ func (s *ServiceImpl) addAppLinks(treeRoot *navtree.NavTreeRoot, c *contextmodel
 		return err
 	}
 
-	isPluginEnabled := func(plugin pluginstore.Plugin) bool {
-		if plugin.AutoEnabled {
-			return true
-		}
-		for _, ps := range pss {
-			if ps.PluginID == plugin.ID {
-				return ps.Enabled
-			}
-		}
-		return false
-	}
-
 	for _, plugin := range s.pluginStore.Plugins(c.Req.Context(), plugins.TypeApp) {
-		if !isPluginEnabled(plugin) {
+		if !isPluginEnabled(plugin, pss) {
 			continue
 		}
 
@@ -64,6 +52,18 @@ func (s *ServiceImpl) addAppLinks(treeRoot *navtree.NavTreeRoot, c *contextmodel
 	return nil
 }
 
+func isPluginEnabled(plugin pluginstore.Plugin, pss []*pluginsettings.InfoDTO) bool {
+	if plugin.AutoEnabled {
+		return true
+	}
+	for _, ps := range pss {
+		if ps.PluginID == plugin.ID {
+			return ps.Enabled
+		}
+	}
+	return false
+}
+
 func (s *ServiceImpl) processAppPlugin(plugin pluginstore.Plugin, c *contextmodel.ReqContext, treeRoot *navtree.NavTreeRoot) *navtree.NavLink {
 	hasAccessToInclude := s.hasAccessToInclude(c, plugin.ID)
 	appLink := &navtree.NavLink{
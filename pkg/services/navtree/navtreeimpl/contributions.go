@@ -0,0 +1,121 @@
+package navtreeimpl
+
+import (
+	"fmt"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+)
+
+var _ navtree.Registry = &ServiceImpl{}
+
+func (s *ServiceImpl) Register(contributions ...navtree.Contribution) error {
+	s.contributionsMu.Lock()
+	defer s.contributionsMu.Unlock()
+
+	ids := make(map[string]bool, len(s.contributions)+len(contributions))
+	for _, contribution := range s.contributions {
+		ids[contribution.Link.Id] = true
+	}
+	for _, contribution := range contributions {
+		if contribution.Link.Id == "" || contribution.Link.Text == "" {
+			return fmt.Errorf("%w: the link of a contribution must have an id and a text", navtree.ErrInvalidContribution)
+		}
+		if ids[contribution.Link.Id] {
+			return fmt.Errorf("%w: %q", navtree.ErrContributionRegistered, contribution.Link.Id)
+		}
+		ids[contribution.Link.Id] = true
+	}
+
+	s.contributions = append(s.contributions, contributions...)
+	return nil
+}
+
+// visibleContributions returns the registered contributions the user is allowed to see.
+func (s *ServiceImpl) visibleContributions(c *contextmodel.ReqContext) ([]navtree.Contribution, error) {
+	s.contributionsMu.RLock()
+	contributions := s.contributions
+	s.contributionsMu.RUnlock()
+
+	hasAccess := ac.HasAccess(s.accessControl, c)
+	var pss []*pluginsettings.InfoDTO
+	var pssLoaded bool
+
+	visible := make([]navtree.Contribution, 0, len(contributions))
+	for _, contribution := range contributions {
+		if contribution.Enabled != nil && !contribution.Enabled(c) {
+			continue
+		}
+		if contribution.Requires != nil && !hasAccess(contribution.Requires) {
+			continue
+		}
+		if contribution.PluginID != "" {
+			if !pssLoaded {
+				var err error
+				pss, err = s.pluginSettings.GetPluginSettings(c.Req.Context(), &pluginsettings.GetArgs{OrgID: c.SignedInUser.GetOrgID()})
+				if err != nil {
+					return nil, err
+				}
+				pssLoaded = true
+			}
+			if !s.isAppAccessible(c, hasAccess, pss, contribution.PluginID) {
+				continue
+			}
+		}
+		visible = append(visible, contribution)
+	}
+	return visible, nil
+}
+
+// addContributedSections adds the contributed sections to the navigation tree.
+func addContributedSections(treeRoot *navtree.NavTreeRoot, contributions []navtree.Contribution) {
+	for _, contribution := range contributions {
+		if contribution.ParentID == "" {
+			treeRoot.AddSection(copyLink(&contribution.Link))
+		}
+	}
+}
+
+// addContributedItems adds the contributed items to their sections in the navigation tree, and
+// returns the items whose section is not in the tree.
+func addContributedItems(treeRoot *navtree.NavTreeRoot, contributions []navtree.Contribution) []navtree.Contribution {
+	var missing []navtree.Contribution
+	for _, contribution := range contributions {
+		if contribution.ParentID == "" {
+			continue
+		}
+		parent := treeRoot.FindById(contribution.ParentID)
+		if parent == nil {
+			missing = append(missing, contribution)
+			continue
+		}
+		parent.Children = append(parent.Children, copyLink(&contribution.Link))
+	}
+	return missing
+}
+
+// isAppAccessible returns true if the app plugin is enabled in the organization of the user and
+// the user can access it.
+func (s *ServiceImpl) isAppAccessible(c *contextmodel.ReqContext, hasAccess func(ac.Evaluator) bool, pss []*pluginsettings.InfoDTO, pluginID string) bool {
+	plugin, exists := s.pluginStore.Plugin(c.Req.Context(), pluginID)
+	if !exists || !isPluginEnabled(plugin, pss) {
+		return false
+	}
+	return hasAccess(ac.EvalPermission(pluginaccesscontrol.ActionAppAccess, pluginaccesscontrol.ScopeProvider.GetResourceScope(pluginID)))
+}
+
+// copyLink returns a copy of a contributed link, so that the navigation tree of a user can be
+// changed without changing the contribution.
+func copyLink(link *navtree.NavLink) *navtree.NavLink {
+	cp := *link
+	if link.Children != nil {
+		cp.Children = make([]*navtree.NavLink, 0, len(link.Children))
+		for _, child := range link.Children {
+			cp.Children = append(cp.Children, copyLink(child))
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,133 @@
+package navtreeimpl
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRegister(t *testing.T) {
+	service := &ServiceImpl{}
+
+	err := service.Register(navtree.Contribution{Link: navtree.NavLink{Id: "reports", Text: "Reports"}})
+	require.NoError(t, err)
+
+	err = service.Register(navtree.Contribution{Link: navtree.NavLink{Text: "Reports"}})
+	assert.ErrorIs(t, err, navtree.ErrInvalidContribution)
+
+	err = service.Register(navtree.Contribution{Link: navtree.NavLink{Id: "reports", Text: "Reports"}})
+	assert.ErrorIs(t, err, navtree.ErrContributionRegistered)
+
+	err = service.Register(
+		navtree.Contribution{Link: navtree.NavLink{Id: "slo", Text: "SLO"}},
+		navtree.Contribution{Link: navtree.NavLink{Id: "slo", Text: "SLO"}},
+	)
+	assert.ErrorIs(t, err, navtree.ErrContributionRegistered)
+	assert.Len(t, service.contributions, 1)
+}
+
+func TestAddContributions(t *testing.T) {
+	httpReq, _ := http.NewRequest(http.MethodGet, "", nil)
+	reqCtx := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{OrgID: 1}, Context: &web.Context{Req: httpReq}}
+
+	enabledApp := pluginstore.Plugin{JSONData: plugins.JSONData{ID: "enabled-app", Type: plugins.TypeApp}}
+	disabledApp := pluginstore.Plugin{JSONData: plugins.JSONData{ID: "disabled-app", Type: plugins.TypeApp}}
+
+	service := &ServiceImpl{
+		log: log.New("navtree"),
+		cfg: setting.NewCfg(),
+		accessControl: accesscontrolmock.New().WithPermissions([]ac.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: "*"},
+			{Action: pluginaccesscontrol.ActionAppAccess, Scope: "*"},
+		}),
+		pluginSettings: &pluginsettings.FakePluginSettings{Plugins: map[string]*pluginsettings.DTO{
+			enabledApp.ID:  {OrgID: 1, PluginID: enabledApp.ID, Enabled: true},
+			disabledApp.ID: {OrgID: 1, PluginID: disabledApp.ID, Enabled: false},
+		}},
+		pluginStore: &pluginstore.FakePluginStore{PluginList: []pluginstore.Plugin{enabledApp, disabledApp}},
+	}
+
+	err := service.Register(
+		navtree.Contribution{
+			ParentID: "reports",
+			Link:     navtree.NavLink{Id: "reports/daily", Text: "Daily"},
+		},
+		navtree.Contribution{
+			Link:     navtree.NavLink{Id: "reports", Text: "Reports", Children: []*navtree.NavLink{{Id: "reports/weekly", Text: "Weekly"}}},
+			Requires: ac.EvalPermission(dashboards.ActionDashboardsRead),
+		},
+		navtree.Contribution{
+			Link:     navtree.NavLink{Id: "billing", Text: "Billing"},
+			Requires: ac.EvalPermission(ac.ActionOrgsWrite),
+		},
+		navtree.Contribution{
+			Link:    navtree.NavLink{Id: "beta", Text: "Beta"},
+			Enabled: func(c *contextmodel.ReqContext) bool { return c.IsSignedIn },
+		},
+		navtree.Contribution{
+			ParentID: "reports",
+			PluginID: enabledApp.ID,
+			Link:     navtree.NavLink{Id: "reports/enabled-app", Text: "Enabled app reports"},
+		},
+		navtree.Contribution{
+			ParentID: "reports",
+			PluginID: disabledApp.ID,
+			Link:     navtree.NavLink{Id: "reports/disabled-app", Text: "Disabled app reports"},
+		},
+		navtree.Contribution{
+			ParentID: "plugin-page-" + enabledApp.ID,
+			PluginID: enabledApp.ID,
+			Link:     navtree.NavLink{Id: "enabled-app/settings", Text: "Settings"},
+		},
+	)
+	require.NoError(t, err)
+
+	contributions, err := service.visibleContributions(reqCtx)
+	require.NoError(t, err)
+
+	treeRoot := navtree.NavTreeRoot{}
+	addContributedSections(&treeRoot, contributions)
+	missing := addContributedItems(&treeRoot, contributions)
+
+	require.Len(t, treeRoot.Children, 1)
+	reports := treeRoot.Children[0]
+	assert.Equal(t, "reports", reports.Id)
+	require.Len(t, reports.Children, 3)
+	assert.Equal(t, "reports/weekly", reports.Children[0].Id)
+	assert.Equal(t, "reports/daily", reports.Children[1].Id)
+	assert.Equal(t, "reports/enabled-app", reports.Children[2].Id)
+
+	require.Len(t, missing, 1)
+	assert.Equal(t, "enabled-app/settings", missing[0].Link.Id)
+
+	t.Run("Should not change the contributions when the tree changes", func(t *testing.T) {
+		reports.Children[0].Text = "Changed"
+		assert.Equal(t, "Weekly", service.contributions[1].Link.Children[0].Text)
+	})
+
+	t.Run("Should add the contributions enabled for the user", func(t *testing.T) {
+		signedIn := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{OrgID: 1}, Context: &web.Context{Req: httpReq}, IsSignedIn: true}
+		contributions, err := service.visibleContributions(signedIn)
+		require.NoError(t, err)
+
+		treeRoot := navtree.NavTreeRoot{}
+		addContributedSections(&treeRoot, contributions)
+		assert.NotNil(t, treeRoot.FindById("beta"))
+	})
+}
@@ -0,0 +1,133 @@
+package navtreeimpl
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlesimpl"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// coreContributions returns the sections and items of the navigation tree that only depend on
+// the permissions of the user, the feature toggles and the settings.
+func (s *ServiceImpl) coreContributions() []navtree.Contribution {
+	signedIn := func(c *contextmodel.ReqContext) bool {
+		return c.IsSignedIn
+	}
+	featureEnabled := func(flag string, signedInOnly bool) func(c *contextmodel.ReqContext) bool {
+		return func(c *contextmodel.ReqContext) bool {
+			return (!signedInOnly || c.IsSignedIn) && s.features.IsEnabled(c.Req.Context(), flag)
+		}
+	}
+
+	return []navtree.Contribution{
+		{
+			Link: navtree.NavLink{
+				Text:       "Explore",
+				Id:         navtree.NavIDExplore,
+				SubTitle:   "Explore your data",
+				Icon:       "compass",
+				SortWeight: navtree.WeightExplore,
+				Url:        s.cfg.AppSubURL + "/explore",
+			},
+			Requires: ac.EvalPermission(ac.ActionDatasourcesExplore),
+			Enabled: func(c *contextmodel.ReqContext) bool {
+				return setting.ExploreEnabled
+			},
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "Playlists", SubTitle: "Groups of dashboards that are displayed in a sequence", Id: "dashboards/playlists", Url: s.cfg.AppSubURL + "/playlists", Icon: "presentation-play",
+			},
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text:     "Snapshots",
+				SubTitle: "Interactive, publically available, point-in-time representations of dashboards",
+				Id:       "dashboards/snapshots",
+				Url:      s.cfg.AppSubURL + "/dashboard/snapshots",
+				Icon:     "camera",
+			},
+			Enabled: func(c *contextmodel.ReqContext) bool {
+				return c.IsSignedIn && s.cfg.SnapshotEnabled
+			},
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text:     "Library panels",
+				SubTitle: "Reusable panels that can be added to multiple dashboards",
+				Id:       "dashboards/library-panels",
+				Url:      s.cfg.AppSubURL + "/library-panels",
+				Icon:     "library-panel",
+			},
+			Enabled: signedIn,
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "Public dashboards",
+				Id:   "dashboards/public",
+				Url:  s.cfg.AppSubURL + "/dashboard/public",
+				Icon: "library-panel",
+			},
+			Enabled: featureEnabled(featuremgmt.FlagPublicDashboards, true),
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "Scenes",
+				Id:   "scenes",
+				Url:  s.cfg.AppSubURL + "/scenes",
+				Icon: "apps",
+			},
+			Enabled: featureEnabled(featuremgmt.FlagScenes, false),
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "Data trails",
+				Id:   "data-trails",
+				Url:  s.cfg.AppSubURL + "/data-trails",
+				Icon: "code-branch",
+			},
+			Enabled: featureEnabled(featuremgmt.FlagDatatrails, false),
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "New dashboard", Icon: "plus", Url: s.cfg.AppSubURL + "/dashboard/new", HideFromTabs: true, Id: "dashboards/new", IsCreateAction: true,
+			},
+			Requires: ac.EvalPermission(dashboards.ActionDashboardsCreate),
+		},
+		{
+			ParentID: navtree.NavIDDashboards,
+			Link: navtree.NavLink{
+				Text: "Import dashboard", SubTitle: "Import dashboard from file or Grafana.com", Id: "dashboards/import", Icon: "plus",
+				Url: s.cfg.AppSubURL + "/dashboard/import", HideFromTabs: true, IsCreateAction: true,
+			},
+			Requires: ac.EvalPermission(dashboards.ActionDashboardsCreate),
+		},
+		{
+			ParentID: "help",
+			Link: navtree.NavLink{
+				Text:       "Support bundles",
+				Id:         "support-bundles",
+				Url:        "/support-bundles",
+				Icon:       "wrench",
+				SortWeight: navtree.WeightHelp,
+			},
+			Requires: ac.EvalAny(
+				ac.EvalPermission(supportbundlesimpl.ActionRead),
+				ac.EvalPermission(supportbundlesimpl.ActionCreate),
+			),
+			Enabled: func(c *contextmodel.ReqContext) bool {
+				return isSupportBundlesEnabled(s)
+			},
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package navtreeimpl
+
+import (
+	"strings"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/navtree"
+)
+
+// addCustomLinks adds the navigation links defined by the organization that the user is allowed to see.
+func (s *ServiceImpl) addCustomLinks(treeRoot *navtree.NavTreeRoot, c *contextmodel.ReqContext) {
+	links, err := s.navLinksService.Get(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		s.log.Warn("Failed to get the navigation links of the organization", "error", err)
+		return
+	}
+
+	hasAccess := ac.HasAccess(s.accessControl, c)
+	children := make([]*navtree.NavLink, 0, len(links))
+	for _, link := range links {
+		if link.Action != "" && !hasAccess(ac.EvalPermission(link.Action)) {
+			continue
+		}
+
+		node := &navtree.NavLink{
+			Id:   navtree.NavIDCustomLinks + "/" + link.ID,
+			Text: link.Title,
+			Icon: link.Icon,
+			Url:  link.URL,
+		}
+		if strings.HasPrefix(link.URL, "/") {
+			node.Url = s.cfg.AppSubURL + link.URL
+		} else {
+			node.Target = "_blank"
+		}
+		children = append(children, node)
+	}
+
+	if len(children) == 0 {
+		return
+	}
+
+	treeRoot.AddSection(&navtree.NavLink{
+		Text:       "Links",
+		Id:         navtree.NavIDCustomLinks,
+		SubTitle:   "Links to the tools of your organization",
+		Icon:       "link",
+		SortWeight: navtree.WeightCustomLinks,
+		Children:   children,
+	})
+}
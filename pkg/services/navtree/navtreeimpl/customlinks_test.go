@@ -0,0 +1,72 @@
+package navtreeimpl
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/navlinks"
+	"github.com/grafana/grafana/pkg/services/navlinks/navlinkstest"
+	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestAddCustomLinks(t *testing.T) {
+	httpReq, _ := http.NewRequest(http.MethodGet, "", nil)
+	reqCtx := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{OrgID: 1}, Context: &web.Context{Req: httpReq}}
+
+	cfg := setting.NewCfg()
+	cfg.AppSubURL = "/grafana"
+	navLinksService := navlinkstest.NewFakeNavLinksService()
+	navLinksService.ExpectedLinks = []*navlinks.Link{
+		{ID: "runbooks", Title: "Runbooks", Icon: "book", URL: "https://wiki.example.com/runbooks"},
+		{ID: "oncall", Title: "On-call", URL: "/d/oncall", Action: dashboards.ActionDashboardsRead},
+		{ID: "billing", Title: "Billing", URL: "/d/billing", Action: ac.ActionOrgsWrite},
+	}
+
+	service := ServiceImpl{
+		log:             log.New("navtree"),
+		cfg:             cfg,
+		accessControl:   accesscontrolmock.New().WithPermissions([]ac.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "*"}}),
+		navLinksService: navLinksService,
+	}
+
+	t.Run("Should add the links the user is allowed to see", func(t *testing.T) {
+		treeRoot := navtree.NavTreeRoot{}
+		service.addCustomLinks(&treeRoot, reqCtx)
+
+		section := treeRoot.FindById(navtree.NavIDCustomLinks)
+		require.NotNil(t, section)
+		require.Len(t, section.Children, 2)
+
+		assert.Equal(t, "Runbooks", section.Children[0].Text)
+		assert.Equal(t, "https://wiki.example.com/runbooks", section.Children[0].Url)
+		assert.Equal(t, "_blank", section.Children[0].Target)
+
+		assert.Equal(t, "custom-links/oncall", section.Children[1].Id)
+		assert.Equal(t, "/grafana/d/oncall", section.Children[1].Url)
+		assert.Empty(t, section.Children[1].Target)
+	})
+
+	t.Run("Should not add the section without links", func(t *testing.T) {
+		service := ServiceImpl{
+			log:             log.New("navtree"),
+			cfg:             cfg,
+			accessControl:   service.accessControl,
+			navLinksService: navlinkstest.NewFakeNavLinksService(),
+		}
+
+		treeRoot := navtree.NavTreeRoot{}
+		service.addCustomLinks(&treeRoot, reqCtx)
+		assert.Nil(t, treeRoot.FindById(navtree.NavIDCustomLinks))
+	})
+}
@@ -3,6 +3,7 @@ package navtreeimpl
 import (
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
@@ -16,13 +17,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/navlinks"
 	"github.com/grafana/grafana/pkg/services/navtree"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/star"
-	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlesimpl"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -39,10 +40,14 @@ type ServiceImpl struct {
 	kvStore              kvstore.KVStore
 	apiKeyService        apikey.Service
 	license              licensing.Licensing
+	navLinksService      navlinks.Service
 
 	// Navigation
 	navigationAppConfig     map[string]NavigationAppConfig
 	navigationAppPathConfig map[string]NavigationAppConfig
+
+	contributionsMu sync.RWMutex
+	contributions   []navtree.Contribution
 }
 
 type NavigationAppConfig struct {
@@ -52,7 +57,7 @@ type NavigationAppConfig struct {
 	Icon       string
 }
 
-func ProvideService(cfg *setting.Cfg, accessControl ac.AccessControl, pluginStore pluginstore.Store, pluginSettings pluginsettings.Service, starService star.Service, features *featuremgmt.FeatureManager, dashboardService dashboards.DashboardService, accesscontrolService ac.Service, kvStore kvstore.KVStore, apiKeyService apikey.Service, license licensing.Licensing) navtree.Service {
+func ProvideService(cfg *setting.Cfg, accessControl ac.AccessControl, pluginStore pluginstore.Store, pluginSettings pluginsettings.Service, starService star.Service, features *featuremgmt.FeatureManager, dashboardService dashboards.DashboardService, accesscontrolService ac.Service, kvStore kvstore.KVStore, apiKeyService apikey.Service, license licensing.Licensing, navLinksService navlinks.Service) *ServiceImpl {
 	service := &ServiceImpl{
 		cfg:                  cfg,
 		log:                  log.New("navtree service"),
@@ -66,9 +71,11 @@ func ProvideService(cfg *setting.Cfg, accessControl ac.AccessControl, pluginStor
 		kvStore:              kvStore,
 		apiKeyService:        apiKeyService,
 		license:              license,
+		navLinksService:      navLinksService,
 	}
 
 	service.readNavigationSettings()
+	service.contributions = service.coreContributions()
 
 	return service
 }
@@ -101,29 +108,14 @@ func (s *ServiceImpl) GetNavTree(c *contextmodel.ReqContext, prefs *pref.Prefere
 		ac.EvalPermission(dashboards.ActionFoldersRead), ac.EvalPermission(dashboards.ActionFoldersCreate),
 		ac.EvalPermission(dashboards.ActionDashboardsRead), ac.EvalPermission(dashboards.ActionDashboardsCreate)),
 	) {
-		dashboardChildLinks := s.buildDashboardNavLinks(c)
-
-		dashboardLink := &navtree.NavLink{
+		treeRoot.AddSection(&navtree.NavLink{
 			Text:       "Dashboards",
 			Id:         navtree.NavIDDashboards,
 			SubTitle:   "Create and manage dashboards to visualize your data",
 			Icon:       "apps",
 			Url:        s.cfg.AppSubURL + "/dashboards",
 			SortWeight: navtree.WeightDashboard,
-			Children:   dashboardChildLinks,
-		}
-
-		treeRoot.AddSection(dashboardLink)
-	}
-
-	if setting.ExploreEnabled && hasAccess(ac.EvalPermission(ac.ActionDatasourcesExplore)) {
-		treeRoot.AddSection(&navtree.NavLink{
-			Text:       "Explore",
-			Id:         navtree.NavIDExplore,
-			SubTitle:   "Explore your data",
-			Icon:       "compass",
-			SortWeight: navtree.WeightExplore,
-			Url:        s.cfg.AppSubURL + "/explore",
+			Children:   []*navtree.NavLink{},
 		})
 	}
 
@@ -158,10 +150,22 @@ func (s *ServiceImpl) GetNavTree(c *contextmodel.ReqContext, prefs *pref.Prefere
 
 	s.addHelpLinks(treeRoot, c)
 
+	contributions, err := s.visibleContributions(c)
+	if err != nil {
+		return nil, err
+	}
+	addContributedSections(treeRoot, contributions)
+	contributions = addContributedItems(treeRoot, contributions)
+
 	if err := s.addAppLinks(treeRoot, c); err != nil {
 		return nil, err
 	}
 
+	// Items can be added to the sections of app plugins
+	addContributedItems(treeRoot, contributions)
+
+	s.addCustomLinks(treeRoot, c)
+
 	return treeRoot, nil
 }
 
@@ -218,24 +222,6 @@ func (s *ServiceImpl) addHelpLinks(treeRoot *navtree.NavTreeRoot, c *contextmode
 		}
 
 		treeRoot.AddSection(helpNode)
-
-		hasAccess := ac.HasAccess(s.accessControl, c)
-		supportBundleAccess := ac.EvalAny(
-			ac.EvalPermission(supportbundlesimpl.ActionRead),
-			ac.EvalPermission(supportbundlesimpl.ActionCreate),
-		)
-
-		if isSupportBundlesEnabled(s) && hasAccess(supportBundleAccess) {
-			supportBundleNode := &navtree.NavLink{
-				Text:       "Support bundles",
-				Id:         "support-bundles",
-				Url:        "/support-bundles",
-				Icon:       "wrench",
-				SortWeight: navtree.WeightHelp,
-			}
-
-			helpNode.Children = append(helpNode.Children, supportBundleNode)
-		}
 	}
 }
 
@@ -330,76 +316,6 @@ func (s *ServiceImpl) buildStarredItemsNavLinks(c *contextmodel.ReqContext) ([]*
 	return starredItemsChildNavs, nil
 }
 
-func (s *ServiceImpl) buildDashboardNavLinks(c *contextmodel.ReqContext) []*navtree.NavLink {
-	hasAccess := ac.HasAccess(s.accessControl, c)
-
-	dashboardChildNavs := []*navtree.NavLink{}
-
-	dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-		Text: "Playlists", SubTitle: "Groups of dashboards that are displayed in a sequence", Id: "dashboards/playlists", Url: s.cfg.AppSubURL + "/playlists", Icon: "presentation-play",
-	})
-
-	if c.IsSignedIn {
-		if s.cfg.SnapshotEnabled {
-			dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-				Text:     "Snapshots",
-				SubTitle: "Interactive, publically available, point-in-time representations of dashboards",
-				Id:       "dashboards/snapshots",
-				Url:      s.cfg.AppSubURL + "/dashboard/snapshots",
-				Icon:     "camera",
-			})
-		}
-
-		dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-			Text:     "Library panels",
-			SubTitle: "Reusable panels that can be added to multiple dashboards",
-			Id:       "dashboards/library-panels",
-			Url:      s.cfg.AppSubURL + "/library-panels",
-			Icon:     "library-panel",
-		})
-
-		if s.features.IsEnabled(c.Req.Context(), featuremgmt.FlagPublicDashboards) {
-			dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-				Text: "Public dashboards",
-				Id:   "dashboards/public",
-				Url:  s.cfg.AppSubURL + "/dashboard/public",
-				Icon: "library-panel",
-			})
-		}
-	}
-
-	if s.features.IsEnabled(c.Req.Context(), featuremgmt.FlagScenes) {
-		dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-			Text: "Scenes",
-			Id:   "scenes",
-			Url:  s.cfg.AppSubURL + "/scenes",
-			Icon: "apps",
-		})
-	}
-
-	if s.features.IsEnabled(c.Req.Context(), featuremgmt.FlagDatatrails) {
-		dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-			Text: "Data trails",
-			Id:   "data-trails",
-			Url:  s.cfg.AppSubURL + "/data-trails",
-			Icon: "code-branch",
-		})
-	}
-
-	if hasAccess(ac.EvalPermission(dashboards.ActionDashboardsCreate)) {
-		dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-			Text: "New dashboard", Icon: "plus", Url: s.cfg.AppSubURL + "/dashboard/new", HideFromTabs: true, Id: "dashboards/new", IsCreateAction: true,
-		})
-
-		dashboardChildNavs = append(dashboardChildNavs, &navtree.NavLink{
-			Text: "Import dashboard", SubTitle: "Import dashboard from file or Grafana.com", Id: "dashboards/import", Icon: "plus",
-			Url: s.cfg.AppSubURL + "/dashboard/import", HideFromTabs: true, IsCreateAction: true,
-		})
-	}
-
-	return dashboardChildNavs
-}
-
 func (s *ServiceImpl) buildLegacyAlertNavLinks(c *contextmodel.ReqContext) *navtree.NavLink {
 	var alertChildNavs []*navtree.NavLink
 	alertChildNavs = append(alertChildNavs, &navtree.NavLink{
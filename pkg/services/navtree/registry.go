@@ -0,0 +1,39 @@
+package navtree
+
+import (
+	"errors"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+var (
+	ErrInvalidContribution    = errors.New("invalid navigation contribution")
+	ErrContributionRegistered = errors.New("navigation contribution already registered")
+)
+
+// Registry lets app plugins and services contribute sections and items to the navigation tree.
+type Registry interface {
+	// Register adds contributions to the navigation tree of the users allowed to see them. The
+	// contributions are added in the order they are registered.
+	Register(contributions ...Contribution) error
+}
+
+// Contribution is a section or an item of the navigation tree, evaluated for each user when the
+// navigation tree is built.
+type Contribution struct {
+	// ParentID is the id of the section the item is added to. The contribution is a section of
+	// the navigation tree when empty. Items of sections the user cannot see are not added.
+	ParentID string
+	// Link is copied to the navigation tree. Its id must be unique among the contributions.
+	Link NavLink
+	// Requires is the permission the user must have to see the contribution. Every user sees
+	// the contribution when nil.
+	Requires ac.Evaluator
+	// PluginID is the id of the app plugin contributing the link. The contribution is only shown
+	// when the app plugin is enabled in the organization and the user can access it.
+	PluginID string
+	// Enabled is called to check the conditions of the contribution other than its permission,
+	// such as feature toggles or settings. The contribution is always enabled when nil.
+	Enabled func(c *contextmodel.ReqContext) bool
+}
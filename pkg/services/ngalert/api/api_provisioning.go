@@ -27,6 +27,7 @@ type ProvisioningSrv struct {
 	contactPointService ContactPointService
 	templates           TemplateService
 	muteTimings         MuteTimingService
+	maintenanceWindows  MaintenanceWindowService
 	alertRules          AlertRuleService
 }
 
@@ -56,6 +57,14 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type MaintenanceWindowService interface {
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]definitions.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (definitions.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, orgID int64, w definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, orgID int64, w definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, orgID int64) ([]*alerting_models.AlertRule, map[string]alerting_models.Provenance, error)
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -98,6 +107,59 @@ func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext)
 	return exportResponse(c, e)
 }
 
+// RouteGetAlertingExport exports the notification policies, contact points, mute timings and alert rule groups
+// of the organization, so that a configuration built in the UI can be managed as code.
+func (srv *ProvisioningSrv) RouteGetAlertingExport(c *contextmodel.ReqContext) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+
+	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), orgID)
+	if err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	e, err := AlertingFileExportFromRoute(orgID, policies)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+
+	q := provisioning.ContactPointQuery{
+		OrgID:   orgID,
+		Decrypt: c.QueryBoolWithDefault("decrypt", false),
+	}
+	cps, err := srv.contactPointService.GetContactPoints(c.Req.Context(), q, c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrPermissionDenied) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	contactPoints, err := AlertingFileExportFromEmbeddedContactPoints(orgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+	e.ContactPoints = contactPoints.ContactPoints
+
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), orgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timings")
+	}
+	e.MuteTimings = AlertingFileExportFromMuteTimings(orgID, timings).MuteTimings
+
+	groupsWithTitle, err := srv.alertRules.GetAlertGroupsWithFolderTitle(c.Req.Context(), orgID, nil)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	groups, err := AlertingFileExportFromAlertRuleGroupWithFolderTitle(groupsWithTitle)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+	e.Groups = groups.Groups
+
+	return exportResponse(c, e)
+}
+
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree definitions.Route) response.Response {
 	provenance := determineProvenance(c)
 	err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID(), tree, alerting_models.Provenance(provenance))
@@ -299,6 +361,62 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindows(c *contextmodel.ReqContext) response.Response {
+	windows, err := srv.maintenanceWindows.GetMaintenanceWindows(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, windows)
+}
+
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindow(c *contextmodel.ReqContext, UID string) response.Response {
+	window, err := srv.maintenanceWindows.GetMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, window)
+}
+
+func (srv *ProvisioningSrv) RoutePostMaintenanceWindow(c *contextmodel.ReqContext, mw definitions.MaintenanceWindow) response.Response {
+	mw.Provenance = determineProvenance(c)
+	created, err := srv.maintenanceWindows.CreateMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), mw)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutMaintenanceWindow(c *contextmodel.ReqContext, mw definitions.MaintenanceWindow, UID string) response.Response {
+	mw.UID = UID
+	mw.Provenance = determineProvenance(c)
+	updated, err := srv.maintenanceWindows.UpdateMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), mw)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, provisioning.ErrNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMaintenanceWindow(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := determineProvenance(c)
+	err := srv.maintenanceWindows.DeleteMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, alerting_models.Provenance(provenance))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser.GetOrgID())
 	if err != nil {
@@ -551,7 +669,7 @@ func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExp
 }
 
 func exportHcl(download bool, body definitions.AlertingFileExport) response.Response {
-	resources := make([]hcl.Resource, 0, len(body.Groups)+len(body.ContactPoints)+len(body.Policies))
+	resources := make([]hcl.Resource, 0, len(body.Groups)+len(body.ContactPoints)+len(body.Policies)+len(body.MuteTimings))
 	for idx, group := range body.Groups {
 		gr := group
 		resources = append(resources, hcl.Resource{
@@ -581,6 +699,18 @@ func exportHcl(download bool, body definitions.AlertingFileExport) response.Resp
 		})
 	}
 
+	for idx, mt := range body.MuteTimings {
+		upd, err := MuteTimingHclFromMuteTimeIntervalExport(mt)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to convert mute timings to HCL", err)
+		}
+		resources = append(resources, hcl.Resource{
+			Type: "grafana_mute_timing",
+			Name: fmt.Sprintf("mute_timing_%d", idx+1),
+			Body: &upd,
+		})
+	}
+
 	hclBody, err := hcl.Encode(resources...)
 	if err != nil {
 		return response.Error(500, "body hcl encode", err)
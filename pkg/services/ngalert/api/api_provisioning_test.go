@@ -77,7 +77,7 @@ func TestProvisioningApi(t *testing.T) {
 				response := sut.RoutePutPolicyTree(&rc, tree)
 
 				require.Equal(t, 400, response.Status())
-				expBody := `{"message":"invalid object specification: invalid policy tree"}`
+				expBody := `{"code":"http.bad-request","message":"invalid object specification: invalid policy tree"}`
 				require.Equal(t, expBody, string(response.Body()))
 			})
 		})
@@ -1153,6 +1153,67 @@ func TestProvisioningApi(t *testing.T) {
 	})
 }
 
+func TestProvisioningApiAlertingExport(t *testing.T) {
+	createSut := func(t *testing.T) ProvisioningSrv {
+		t.Helper()
+		env := createTestEnv(t, testExportConfig)
+		sut := createProvisioningSrvSutFromEnv(t, &env)
+		sut.policies = createFakeNotificationPolicyService()
+		insertRule(t, sut, createTestAlertRule("rule1", 1))
+		return sut
+	}
+
+	t.Run("json body contains the whole configuration", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createTestRequestCtx()
+		rc.Context.Req.Header.Add("Accept", "application/json")
+
+		response := sut.RouteGetAlertingExport(&rc)
+
+		require.Equal(t, 200, response.Status())
+		var export definitions.AlertingFileExport
+		require.NoError(t, json.Unmarshal(response.Body(), &export))
+		require.Len(t, export.Policies, 1)
+		require.Equal(t, "default-receiver", export.Policies[0].Receiver)
+		require.Len(t, export.ContactPoints, 1)
+		require.Equal(t, "email receiver", export.ContactPoints[0].Name)
+		require.Len(t, export.Groups, 1)
+		require.Equal(t, "my-cool-group", export.Groups[0].Name)
+		require.Len(t, export.MuteTimings, 1)
+		require.Equal(t, int64(1), export.MuteTimings[0].OrgID)
+		require.Equal(t, "business-hours", export.MuteTimings[0].Name)
+		require.Len(t, export.MuteTimings[0].TimeIntervals, 1)
+	})
+
+	t.Run("yaml body can be used as a provisioning file", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createTestRequestCtx()
+		rc.Context.Req.Form.Set("format", "yaml")
+
+		response := sut.RouteGetAlertingExport(&rc)
+
+		require.Equal(t, 200, response.Status())
+		body := string(response.Body())
+		require.Contains(t, body, "muteTimes:\n    - orgId: 1\n      name: business-hours\n")
+		require.Contains(t, body, "weekdays: ['monday:friday']")
+	})
+
+	t.Run("hcl body contains the Terraform resources", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createTestRequestCtx()
+		rc.Context.Req.Form.Set("format", "hcl")
+
+		response := sut.RouteGetAlertingExport(&rc)
+
+		require.Equal(t, 200, response.Status())
+		body := string(response.Body())
+		require.Contains(t, body, `resource "grafana_rule_group" "rule_group_0000" {`)
+		require.Contains(t, body, `resource "grafana_contact_point" "contact_point_0" {`)
+		require.Contains(t, body, `resource "grafana_notification_policy" "notification_policy_1" {`)
+		require.Contains(t, body, "resource \"grafana_mute_timing\" \"mute_timing_1\" {\n  name = \"business-hours\"\n\n  intervals {\n\n    times {\n      start = \"08:00\"\n      end   = \"17:30\"\n    }\n\n    weekdays      = [\"monday:friday\"]\n    days_of_month = [\"1:15\"]\n    location      = \"Europe/Paris\"\n  }\n}\n")
+	})
+}
+
 func TestProvisioningApiContactPointExport(t *testing.T) {
 	t.Run("contact point export", func(t *testing.T) {
 		t.Run("are present, GET returns 200", func(t *testing.T) {
@@ -1770,6 +1831,40 @@ var testConfig = `
 }
 `
 
+var testExportConfig = `
+{
+	"template_files": {
+		"a": "template"
+	},
+	"alertmanager_config": {
+		"route": {
+			"receiver": "grafana-default-email"
+		},
+		"receivers": [{
+			"name": "grafana-default-email",
+			"grafana_managed_receiver_configs": [{
+				"uid": "email-uid",
+				"name": "email receiver",
+				"type": "email",
+				"isDefault": true,
+				"settings": {
+					"addresses": "<example@email.com>"
+				}
+			}]
+		}],
+		"mute_time_intervals": [{
+			"name": "business-hours",
+			"time_intervals": [{
+				"times": [{"start_time": "08:00", "end_time": "17:30"}],
+				"weekdays": ["monday:friday"],
+				"days_of_month": ["1:15"],
+				"location": "Europe/Paris"
+			}]
+		}]
+	}
+}
+`
+
 var testContactPointConfig = `
 {
 	"template_files": {
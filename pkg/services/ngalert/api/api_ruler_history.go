@@ -2,15 +2,20 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 type Historian interface {
@@ -18,8 +23,9 @@ type Historian interface {
 }
 
 type HistorySrv struct {
-	logger log.Logger
-	hist   Historian
+	logger     log.Logger
+	hist       Historian
+	deliveries store.NotificationDeliveryStore
 }
 
 const labelQueryPrefix = "labels_"
@@ -39,6 +45,19 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		}
 	}
 
+	groupBy, err := parseHistoryGroupBy(c.QueryStrings("groupBy"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	var interval time.Duration
+	if raw := c.Query("interval"); raw != "" {
+		interval, err = gtime.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid interval %q", raw), "")
+		}
+	}
+
 	query := models.HistoryQuery{
 		RuleUID:      ruleUID,
 		OrgID:        c.SignedInUser.GetOrgID(),
@@ -49,10 +68,82 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		To:           time.Unix(to, 0),
 		Limit:        limit,
 		Labels:       labels,
+		GroupBy:      groupBy,
+		Interval:     interval,
 	}
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
+		if errors.Is(err, models.ErrHistoryAggregationNotSupported) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 	return response.JSON(http.StatusOK, frame)
 }
+
+func (srv *HistorySrv) RouteQueryNotificationDeliveries(c *contextmodel.ReqContext) response.Response {
+	query := models.NotificationDeliveryQuery{
+		OrgID:    c.SignedInUser.GetOrgID(),
+		RuleUID:  c.Query("ruleUID"),
+		Receiver: c.Query("receiver"),
+		Status:   models.NotificationDeliveryStatus(c.Query("status")),
+		Limit:    c.QueryInt("limit"),
+	}
+	if query.Status != "" && query.Status != models.NotificationDeliverySuccess && query.Status != models.NotificationDeliveryFailed {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid status %q", query.Status), "")
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(to, 0)
+	}
+
+	deliveries, err := srv.deliveries.GetNotificationDeliveries(c.Req.Context(), &query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to query notification deliveries")
+	}
+
+	result := make(apimodels.GettableNotificationDeliveries, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.GettableNotificationDelivery{
+			ID:               d.ID,
+			Receiver:         d.Receiver,
+			IntegrationName:  d.IntegrationName,
+			IntegrationType:  d.IntegrationType,
+			IntegrationIndex: d.IntegrationIndex,
+			GroupKey:         d.GroupKey,
+			RuleUIDs:         d.RuleUIDs,
+			Alerts:           d.Alerts,
+			Status:           string(d.Status),
+			Error:            d.Error,
+			Retry:            d.Retry,
+			DurationMs:       d.Duration.Milliseconds(),
+			SentAt:           d.SentAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// parseHistoryGroupBy accepts the group by dimensions both as repeated and as comma separated query parameters.
+func parseHistoryGroupBy(values []string) ([]models.HistoryGroupBy, error) {
+	groupBy := make([]models.HistoryGroupBy, 0, len(values))
+	seen := make(map[models.HistoryGroupBy]struct{}, len(values))
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			g := models.HistoryGroupBy(strings.TrimSpace(part))
+			if g == "" {
+				continue
+			}
+			if !models.IsValidHistoryGroupBy(g) {
+				return nil, fmt.Errorf("invalid groupBy %q, must be one of: %s, %s, %s", g, models.HistoryGroupByRule, models.HistoryGroupByLabels, models.HistoryGroupByState)
+			}
+			if _, ok := seen[g]; ok {
+				continue
+			}
+			seen[g] = struct{}{}
+			groupBy = append(groupBy, g)
+		}
+	}
+	return groupBy, nil
+}
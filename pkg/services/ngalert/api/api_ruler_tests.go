@@ -0,0 +1,201 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/ruletest"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var errRuleGroupNotFound = errors.New("rule group not found")
+
+// RouteGetRuleGroupTests returns the unit tests of a rule group.
+func (srv RulerSrv) RouteGetRuleGroupTests(c *contextmodel.ReqContext, namespaceTitle string, ruleGroup string) response.Response {
+	key, _, resp := srv.getRuleGroupForTests(c, namespaceTitle, ruleGroup)
+	if resp != nil {
+		return resp
+	}
+
+	tests, err := srv.ruleTests.GetAlertRuleTests(c.Req.Context(), key)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group tests")
+	}
+	return response.JSON(http.StatusOK, toRuleGroupTests(tests))
+}
+
+// RoutePostRuleGroupTests replaces the unit tests of a rule group.
+// Returns http.StatusBadRequest if a test references rules that are not in the group or cannot be run.
+func (srv RulerSrv) RoutePostRuleGroupTests(c *contextmodel.ReqContext, body apimodels.RuleGroupTests, namespaceTitle string, ruleGroup string) response.Response {
+	key, rules, resp := srv.getRuleGroupForTests(c, namespaceTitle, ruleGroup)
+	if resp != nil {
+		return resp
+	}
+
+	existing, err := srv.ruleTests.GetAlertRuleTests(c.Req.Context(), key)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group tests")
+	}
+	existingUIDs := make(map[string]struct{}, len(existing))
+	for _, test := range existing {
+		existingUIDs[test.UID] = struct{}{}
+	}
+
+	now := time.Now()
+	tests := make([]*ngmodels.AlertRuleTest, 0, len(body.Tests))
+	seen := make(map[string]struct{}, len(body.Tests))
+	for _, t := range body.Tests {
+		if t.Title == "" {
+			return ErrResp(http.StatusBadRequest, errors.New("the title of the test must not be empty"), "")
+		}
+		test := toAlertRuleTest(key, t)
+		if _, ok := existingUIDs[test.UID]; !ok {
+			test.UID = util.GenerateShortUID()
+		}
+		if _, ok := seen[test.UID]; ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("test %s is defined more than once", test.UID), "")
+		}
+		seen[test.UID] = struct{}{}
+		test.Updated = now
+		if err := ruletest.Validate(rules, test); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid test %q", t.Title)
+		}
+		tests = append(tests, test)
+	}
+
+	if err := srv.ruleTests.ReplaceAlertRuleTests(c.Req.Context(), key, tests); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save rule group tests")
+	}
+	return response.JSON(http.StatusAccepted, toRuleGroupTests(tests))
+}
+
+// RouteRunRuleGroupTests runs the unit tests of a rule group against the current version of its rules.
+func (srv RulerSrv) RouteRunRuleGroupTests(c *contextmodel.ReqContext, namespaceTitle string, ruleGroup string) response.Response {
+	key, rules, resp := srv.getRuleGroupForTests(c, namespaceTitle, ruleGroup)
+	if resp != nil {
+		return resp
+	}
+
+	tests, err := srv.ruleTests.GetAlertRuleTests(c.Req.Context(), key)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group tests")
+	}
+
+	result := apimodels.RuleGroupTestResults{Passed: true, Tests: make([]apimodels.RuleTestResult, 0, len(tests))}
+	for _, test := range tests {
+		testResult := apimodels.RuleTestResult{UID: test.UID, Title: test.Title}
+		res, err := srv.ruleTestRunner.Run(c.Req.Context(), c.SignedInUser, rules, test)
+		switch {
+		case errors.Is(err, ruletest.ErrInvalidTest):
+			testResult.Error = err.Error()
+		case err != nil:
+			return ErrResp(http.StatusInternalServerError, err, "failed to run test %q", test.Title)
+		default:
+			testResult.Passed = res.Passed()
+			for _, f := range res.Failures {
+				testResult.Failures = append(testResult.Failures, apimodels.RuleTestFailure{
+					Expectation: toRuleTestExpectation(f.Expectation),
+					Message:     f.Message,
+				})
+			}
+		}
+		result.Passed = result.Passed && testResult.Passed
+		result.Tests = append(result.Tests, testResult)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// getRuleGroupForTests returns the rules of the group if the user is authorized to access them, or the error
+// response otherwise.
+func (srv RulerSrv) getRuleGroupForTests(c *contextmodel.ReqContext, namespaceTitle string, ruleGroup string) (ngmodels.AlertRuleGroupKey, ngmodels.RulesGroup, response.Response) {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return ngmodels.AlertRuleGroupKey{}, nil, toNamespaceErrorResponse(err)
+	}
+	key := ngmodels.AlertRuleGroupKey{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		NamespaceUID: namespace.UID,
+		RuleGroup:    ruleGroup,
+	}
+	rules, err := srv.getAuthorizedRuleGroup(c.Req.Context(), c, key)
+	if err != nil {
+		return key, nil, errorToResponse(err)
+	}
+	if len(rules) == 0 {
+		return key, nil, ErrResp(http.StatusNotFound, errRuleGroupNotFound, "")
+	}
+	return key, rules, nil
+}
+
+func toAlertRuleTest(key ngmodels.AlertRuleGroupKey, t apimodels.RuleTest) *ngmodels.AlertRuleTest {
+	test := &ngmodels.AlertRuleTest{
+		OrgID:        key.OrgID,
+		UID:          t.UID,
+		NamespaceUID: key.NamespaceUID,
+		RuleGroup:    key.RuleGroup,
+		Title:        t.Title,
+		Interval:     time.Duration(t.Interval),
+		InputSeries:  make([]ngmodels.AlertRuleTestSeries, 0, len(t.InputSeries)),
+		Expectations: make([]ngmodels.AlertRuleTestExpectation, 0, len(t.Expectations)),
+	}
+	for _, s := range t.InputSeries {
+		test.InputSeries = append(test.InputSeries, ngmodels.AlertRuleTestSeries{
+			RuleUID: s.RuleUID,
+			RefID:   s.RefID,
+			Labels:  s.Labels,
+			Values:  s.Values,
+		})
+	}
+	for _, e := range t.Expectations {
+		test.Expectations = append(test.Expectations, ngmodels.AlertRuleTestExpectation{
+			RuleUID: e.RuleUID,
+			At:      time.Duration(e.At),
+			Labels:  e.Labels,
+			State:   e.State,
+		})
+	}
+	return test
+}
+
+func toRuleGroupTests(tests []*ngmodels.AlertRuleTest) apimodels.RuleGroupTests {
+	result := apimodels.RuleGroupTests{Tests: make([]apimodels.RuleTest, 0, len(tests))}
+	for _, test := range tests {
+		t := apimodels.RuleTest{
+			UID:          test.UID,
+			Title:        test.Title,
+			Interval:     model.Duration(test.Interval),
+			InputSeries:  make([]apimodels.RuleTestSeries, 0, len(test.InputSeries)),
+			Expectations: make([]apimodels.RuleTestExpectation, 0, len(test.Expectations)),
+		}
+		for _, s := range test.InputSeries {
+			t.InputSeries = append(t.InputSeries, apimodels.RuleTestSeries{
+				RuleUID: s.RuleUID,
+				RefID:   s.RefID,
+				Labels:  s.Labels,
+				Values:  s.Values,
+			})
+		}
+		for _, e := range test.Expectations {
+			t.Expectations = append(t.Expectations, toRuleTestExpectation(e))
+		}
+		result.Tests = append(result.Tests, t)
+	}
+	return result
+}
+
+func toRuleTestExpectation(e ngmodels.AlertRuleTestExpectation) apimodels.RuleTestExpectation {
+	return apimodels.RuleTestExpectation{
+		RuleUID: e.RuleUID,
+		At:      model.Duration(e.At),
+		Labels:  e.Labels,
+		State:   e.State,
+	}
+}
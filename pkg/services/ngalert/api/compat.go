@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -279,6 +281,70 @@ func AlertingFileExportFromRoute(orgID int64, route definitions.Route) (definiti
 	return f, nil
 }
 
+// AlertingFileExportFromMuteTimings creates a definitions.AlertingFileExport DTO from []definitions.MuteTimeInterval.
+func AlertingFileExportFromMuteTimings(orgID int64, muteTimings []definitions.MuteTimeInterval) definitions.AlertingFileExport {
+	f := definitions.AlertingFileExport{APIVersion: 1}
+	for _, mt := range muteTimings {
+		f.MuteTimings = append(f.MuteTimings, definitions.MuteTimeIntervalExport{
+			OrgID:            orgID,
+			MuteTimeInterval: mt.MuteTimeInterval,
+		})
+	}
+	return f
+}
+
+// MuteTimingHclFromMuteTimeIntervalExport creates a definitions.MuteTimingHcl DTO from definitions.MuteTimeIntervalExport.
+func MuteTimingHclFromMuteTimeIntervalExport(mt definitions.MuteTimeIntervalExport) (definitions.MuteTimingHcl, error) {
+	result := definitions.MuteTimingHcl{
+		Name:      mt.Name,
+		Intervals: make([]definitions.TimeIntervalHcl, 0, len(mt.TimeIntervals)),
+	}
+	for _, interval := range mt.TimeIntervals {
+		i := definitions.TimeIntervalHcl{}
+		for _, t := range interval.Times {
+			i.Times = append(i.Times, definitions.TimeRangeHcl{
+				From: fmt.Sprintf("%02d:%02d", t.StartMinute/60, t.StartMinute%60),
+				To:   fmt.Sprintf("%02d:%02d", t.EndMinute/60, t.EndMinute%60),
+			})
+		}
+		var err error
+		if i.Weekdays, err = rangesToStrings(interval.Weekdays); err != nil {
+			return definitions.MuteTimingHcl{}, err
+		}
+		if i.DaysOfMonth, err = rangesToStrings(interval.DaysOfMonth); err != nil {
+			return definitions.MuteTimingHcl{}, err
+		}
+		if i.Months, err = rangesToStrings(interval.Months); err != nil {
+			return definitions.MuteTimingHcl{}, err
+		}
+		if i.Years, err = rangesToStrings(interval.Years); err != nil {
+			return definitions.MuteTimingHcl{}, err
+		}
+		if interval.Location != nil {
+			location := interval.Location.String()
+			i.Location = &location
+		}
+		result.Intervals = append(result.Intervals, i)
+	}
+	return result, nil
+}
+
+// rangesToStrings returns the ranges of a time interval in the format of the provisioning files, or nil if there are none.
+func rangesToStrings[T encoding.TextMarshaler](ranges []T) (*[]string, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+	result := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		text, err := r.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, string(text))
+	}
+	return &result, nil
+}
+
 // RouteExportFromRoute creates a definitions.RouteExport DTO from definitions.Route.
 func RouteExportFromRoute(route *definitions.Route) *definitions.RouteExport {
 	toStringIfNotNil := func(d *model.Duration) *string {
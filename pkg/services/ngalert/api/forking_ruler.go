@@ -89,6 +89,18 @@ func (f *RulerApiHandler) handleRouteGetGrafanaRuleGroupConfig(ctx *contextmodel
 	return f.GrafanaRuler.RouteGetRulesGroupConfig(ctx, namespace, group)
 }
 
+func (f *RulerApiHandler) handleRouteGetGrafanaRuleGroupTests(ctx *contextmodel.ReqContext, namespace, group string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleGroupTests(ctx, namespace, group)
+}
+
+func (f *RulerApiHandler) handleRoutePostGrafanaRuleGroupTests(ctx *contextmodel.ReqContext, conf apimodels.RuleGroupTests, namespace, group string) response.Response {
+	return f.GrafanaRuler.RoutePostRuleGroupTests(ctx, conf, namespace, group)
+}
+
+func (f *RulerApiHandler) handleRouteRunGrafanaRuleGroupTests(ctx *contextmodel.ReqContext, namespace, group string) response.Response {
+	return f.GrafanaRuler.RouteRunRuleGroupTests(ctx, namespace, group)
+}
+
 func (f *RulerApiHandler) handleRouteGetGrafanaRulesConfig(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.RouteGetRulesConfig(ctx)
 }
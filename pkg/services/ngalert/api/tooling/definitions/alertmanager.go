@@ -178,6 +178,19 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/routes/test alertmanager RoutePostTestGrafanaRouting
+//
+// Test which notification policies, mute timings and contact points an alert with the given labels would be routed to, without sending any notification.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: TestRoutingResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -344,6 +357,71 @@ type TestTemplatesErrorResult struct {
 // swagger:enum TemplateErrorKind
 type TemplateErrorKind string
 
+// swagger:parameters RoutePostTestGrafanaRouting
+type TestRoutingConfigParams struct {
+	// in:body
+	Body TestRoutingConfigBodyParams
+}
+
+type TestRoutingConfigBodyParams struct {
+	// Labels of the alert to route.
+	Labels model.LabelSet `json:"labels"`
+
+	// Annotations of the alert to route. They do not affect routing and are returned as is.
+	Annotations model.LabelSet `json:"annotations,omitempty"`
+
+	// Time at which mute timings are evaluated. Defaults to the current time.
+	Time *strfmt.DateTime `json:"time,omitempty"`
+
+	// Configuration to route the alert with instead of the saved one, to verify changes before saving them.
+	AlertmanagerConfig *PostableApiAlertingConfig `json:"alertmanager_config,omitempty"`
+}
+
+// swagger:model
+type TestRoutingResult struct {
+	Labels      model.LabelSet `json:"labels"`
+	Annotations model.LabelSet `json:"annotations,omitempty"`
+
+	// Time at which mute timings were evaluated.
+	Time time.Time `json:"time"`
+
+	// Notification policies the alert matches, in the order they are evaluated.
+	Routes []TestRoutingRouteResult `json:"routes"`
+}
+
+type TestRoutingRouteResult struct {
+	// Matchers of the matched policy and its parents, from the root policy down.
+	Path []string `json:"path"`
+
+	Receiver       string         `json:"receiver"`
+	GroupBy        []string       `json:"group_by,omitempty"`
+	GroupWait      model.Duration `json:"group_wait"`
+	GroupInterval  model.Duration `json:"group_interval"`
+	RepeatInterval model.Duration `json:"repeat_interval"`
+
+	// Mute timings of the policy.
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
+
+	// Mute timings of the policy that are in effect at the evaluated time.
+	ActiveMuteTimeIntervals []string `json:"active_mute_time_intervals,omitempty"`
+
+	// Whether notifications for the alert would be muted at the evaluated time.
+	Muted bool `json:"muted"`
+
+	ContactPoint TestRoutingContactPoint `json:"contact_point"`
+}
+
+type TestRoutingContactPoint struct {
+	Name         string                   `json:"name"`
+	Integrations []TestRoutingIntegration `json:"integrations"`
+}
+
+type TestRoutingIntegration struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
 const (
 	InvalidTemplate TemplateErrorKind = "invalid_template"
 	ExecutionError  TemplateErrorKind = "execution_error"
@@ -0,0 +1,134 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/tests ruler RouteGetGrafanaRuleGroupTests
+//
+// Get the unit tests of a rule group
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleGroupTests
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/tests ruler RoutePostGrafanaRuleGroupTests
+//
+// Replace the unit tests of a rule group
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: RuleGroupTests
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/tests/run ruler RouteRunGrafanaRuleGroupTests
+//
+// Run the unit tests of a rule group
+//
+// The rules of the group are evaluated against the input series of each test instead of the data returned by their
+// data sources, and the states of their alert instances are compared with the expected states.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleGroupTestResults
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RouteGetGrafanaRuleGroupTests RouteRunGrafanaRuleGroupTests
+type PathRuleGroupTestsParams struct {
+	// in: path
+	Namespace string
+	// in: path
+	Groupname string
+}
+
+// swagger:parameters RoutePostGrafanaRuleGroupTests
+type RuleGroupTestsParams struct {
+	// in: path
+	Namespace string
+	// in: path
+	Groupname string
+	// in: body
+	Body RuleGroupTests
+}
+
+// swagger:model
+type RuleGroupTests struct {
+	Tests []RuleTest `json:"tests"`
+}
+
+// swagger:model
+type RuleTest struct {
+	// UID identifies an existing test of the group. A new UID is generated for the other tests.
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// Interval is the time between two evaluations of the rules, and between two points of the input series.
+	// The interval of the rule group is used when it is not set.
+	Interval     model.Duration        `json:"interval,omitempty"`
+	InputSeries  []RuleTestSeries      `json:"inputSeries"`
+	Expectations []RuleTestExpectation `json:"expectations"`
+}
+
+// RuleTestSeries is returned to the data source queries with the reference ID instead of the data source response.
+// The points within the time range of a query are returned, instant queries get the last of them.
+// swagger:model
+type RuleTestSeries struct {
+	// RuleUID restricts the series to the queries of a rule. The series is returned to the queries of all the rules
+	// of the group when it is not set.
+	RuleUID string            `json:"ruleUid,omitempty"`
+	RefID   string            `json:"refId"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// Values are the points of the series, one per interval, starting at the time of the first evaluation.
+	// null is a missing point.
+	Values []*float64 `json:"values"`
+}
+
+// RuleTestExpectation is the expected state of the alert instances of a rule at the time of an evaluation.
+// swagger:model
+type RuleTestExpectation struct {
+	RuleUID string `json:"ruleUid"`
+	// At is the time of the evaluation from the first evaluation, a multiple of the interval.
+	At model.Duration `json:"at"`
+	// Labels select the alert instances that have these labels. At least one instance must match.
+	Labels map[string]string `json:"labels,omitempty"`
+	// State is the expected state of the alert instances.
+	// enum: Normal,Pending,Alerting,NoData,Error
+	State string `json:"state"`
+}
+
+// swagger:model
+type RuleGroupTestResults struct {
+	// Passed is true if all the tests of the group passed.
+	Passed bool             `json:"passed"`
+	Tests  []RuleTestResult `json:"tests"`
+}
+
+// swagger:model
+type RuleTestResult struct {
+	UID      string            `json:"uid"`
+	Title    string            `json:"title"`
+	Passed   bool              `json:"passed"`
+	Failures []RuleTestFailure `json:"failures,omitempty"`
+	// Error is the reason why the test could not run, for example because it references rules that were removed
+	// from the group.
+	Error string `json:"error,omitempty"`
+}
+
+// swagger:model
+type RuleTestFailure struct {
+	Expectation RuleTestExpectation `json:"expectation"`
+	Message     string              `json:"message"`
+}
@@ -1,5 +1,15 @@
 package definitions
 
+// swagger:route GET /api/v1/provisioning/export provisioning stable RouteGetAlertingExport
+//
+// Export the notification policies, contact points, mute timings and alert rule groups of the organization
+// in provisioning file format, or as Terraform resources if the format is hcl.
+//
+//     Responses:
+//       200: AlertingFileExport
+//       403: PermissionDenied
+//       404: NotFound
+
 // AlertingFileExport is the full provisioned file export.
 // swagger:model
 type AlertingFileExport struct {
@@ -7,9 +17,10 @@ type AlertingFileExport struct {
 	Groups        []AlertRuleGroupExport     `json:"groups,omitempty" yaml:"groups,omitempty"`
 	ContactPoints []ContactPointExport       `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
 	Policies      []NotificationPolicyExport `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimings   []MuteTimeIntervalExport   `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
 }
 
-// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetContactpointsExport RouteGetContactpointExport RoutePostRulesGroupForExport
+// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetContactpointsExport RouteGetContactpointExport RoutePostRulesGroupForExport RouteGetAlertingExport
 type ExportQueryParams struct {
 	// Whether to initiate a download of the file or not.
 	// in: query
@@ -24,7 +35,7 @@ type ExportQueryParams struct {
 	Format string `json:"format"`
 }
 
-// swagger:parameters RouteGetContactpointsExport RouteGetContactpointExport
+// swagger:parameters RouteGetContactpointsExport RouteGetContactpointExport RouteGetAlertingExport
 type DecryptQueryParams struct {
 	// Whether any contained secure settings should be decrypted or left redacted. Redacted settings will contain RedactedValue instead. Currently, only org admin can view decrypted secure settings.
 	// in: query
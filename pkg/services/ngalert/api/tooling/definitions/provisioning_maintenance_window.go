@@ -0,0 +1,113 @@
+package definitions
+
+import (
+	"errors"
+	"time"
+)
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows provisioning stable RouteGetMaintenanceWindows
+//
+// Get all the maintenance windows.
+//
+//     Responses:
+//       200: MaintenanceWindows
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteGetMaintenanceWindow
+//
+// Get a maintenance window.
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/maintenance-windows provisioning stable RoutePostMaintenanceWindow
+//
+// Create a new maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MaintenanceWindow
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RoutePutMaintenanceWindow
+//
+// Replace an existing maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteDeleteMaintenanceWindow
+//
+// Delete a maintenance window.
+//
+//     Responses:
+//       204: description: The maintenance window was deleted successfully.
+
+// swagger:parameters RouteGetMaintenanceWindow RoutePutMaintenanceWindow RouteDeleteMaintenanceWindow
+type MaintenanceWindowUIDReference struct {
+	// Maintenance window UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostMaintenanceWindow RoutePutMaintenanceWindow
+type MaintenanceWindowPayload struct {
+	// in:body
+	Body MaintenanceWindow
+}
+
+// swagger:model
+type MaintenanceWindows []MaintenanceWindow
+
+// swagger:model
+type MaintenanceWindow struct {
+	// UID is the unique identifier of the maintenance window. It is generated if empty.
+	// example: maintenance-db-upgrade
+	UID string `json:"uid" yaml:"uid"`
+	// required: true
+	// example: Database upgrade
+	Title   string `json:"title" yaml:"title"`
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// Matchers select the alert instances silenced during the window.
+	// required: true
+	Matchers ObjectMatchers `json:"matchers" yaml:"matchers"`
+	// DashboardUIDs are the dashboards annotated in addition to the dashboards of the matching alert rules.
+	DashboardUIDs []string `json:"dashboardUids,omitempty" yaml:"dashboardUids,omitempty"`
+	// required: true
+	StartsAt time.Time `json:"startsAt" yaml:"startsAt"`
+	// required: true
+	EndsAt     time.Time  `json:"endsAt" yaml:"endsAt"`
+	Provenance Provenance `json:"provenance,omitempty" yaml:"-"`
+}
+
+func (w *MaintenanceWindow) ResourceType() string {
+	return "maintenanceWindow"
+}
+
+func (w *MaintenanceWindow) ResourceID() string {
+	return w.UID
+}
+
+// Validate checks that the maintenance window has a title, at least one matcher and a valid time range.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Title == "" {
+		return errors.New("title must not be empty")
+	}
+	if len(w.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if w.StartsAt.IsZero() || w.EndsAt.IsZero() {
+		return errors.New("startsAt and endsAt must be set")
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	return nil
+}
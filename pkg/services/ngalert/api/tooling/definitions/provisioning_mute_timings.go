@@ -79,3 +79,29 @@ func (mt *MuteTimeInterval) ResourceType() string {
 func (mt *MuteTimeInterval) ResourceID() string {
 	return mt.MuteTimeInterval.Name
 }
+
+// MuteTimeIntervalExport is the provisioned file export of MuteTimeInterval.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+}
+
+// MuteTimingHcl is the representation of MuteTimeInterval as the grafana_mute_timing resource of the Terraform provider.
+type MuteTimingHcl struct {
+	Name      string            `hcl:"name"`
+	Intervals []TimeIntervalHcl `hcl:"intervals,block"`
+}
+
+type TimeIntervalHcl struct {
+	Times       []TimeRangeHcl `hcl:"times,block"`
+	Weekdays    *[]string      `hcl:"weekdays,optional"`
+	DaysOfMonth *[]string      `hcl:"days_of_month,optional"`
+	Months      *[]string      `hcl:"months,optional"`
+	Years       *[]string      `hcl:"years,optional"`
+	Location    *string        `hcl:"location,optional"`
+}
+
+type TimeRangeHcl struct {
+	From string `hcl:"start"`
+	To   string `hcl:"end"`
+}
@@ -1,19 +1,98 @@
 package definitions
 
-import "github.com/grafana/grafana-plugin-sdk-go/data"
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
 
 // swagger:route GET /api/v1/rules/history history RouteGetStateHistory
 //
 // Query state history.
 //
+// When groupBy or interval is set, the number of state transitions is aggregated by the state
+// history backend instead of returning every transition.
+//
 //     Produces:
 //     - application/json
 //
 //     Responses:
 //       200: StateHistory
 
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// Dimensions to count state transitions by. Can be repeated or comma separated.
+	// in: query
+	// required: false
+	GroupBy []string `json:"groupBy"`
+	// Size of the time buckets state transitions are counted in, for example 5m.
+	// in: query
+	// required: false
+	Interval string `json:"interval"`
+}
+
 // swagger:response StateHistory
 type StateHistory struct {
 	// in:body
 	Results *data.Frame `json:"results"`
 }
+
+// swagger:route GET /api/v1/notifications/deliveries history RouteGetNotificationDeliveries
+//
+// Query the attempts to deliver notifications to contact points, newest first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableNotificationDeliveries
+//       400: ValidationError
+
+// swagger:parameters RouteGetNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// Only return attempts that notified about alerts of this rule.
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+	// Only return attempts of this contact point.
+	// in: query
+	// required: false
+	Receiver string `json:"receiver"`
+	// Only return attempts with this status.
+	// in: query
+	// required: false
+	// enum: success,failed
+	Status string `json:"status"`
+	// Unix timestamp in seconds of the earliest attempt to return.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+	// Unix timestamp in seconds of the latest attempt to return.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+	// Maximum number of attempts to return, 100 by default and at most 1000.
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableNotificationDeliveries []GettableNotificationDelivery
+
+// swagger:model
+type GettableNotificationDelivery struct {
+	ID               int64     `json:"id"`
+	Receiver         string    `json:"receiver"`
+	IntegrationName  string    `json:"integrationName"`
+	IntegrationType  string    `json:"integrationType"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	GroupKey         string    `json:"groupKey"`
+	RuleUIDs         []string  `json:"ruleUIDs"`
+	Alerts           int       `json:"alerts"`
+	Status           string    `json:"status"`
+	Error            string    `json:"error,omitempty"`
+	Retry            int       `json:"retry"`
+	DurationMs       int64     `json:"durationMs"`
+	SentAt           time.Time `json:"sentAt"`
+}
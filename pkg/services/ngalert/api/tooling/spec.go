@@ -0,0 +1,10 @@
+// Package tooling contains the OpenAPI specification of the Grafana Alerting API and the
+// tools generating it.
+package tooling
+
+import _ "embed"
+
+// Spec is the Swagger 2.0 specification of the Grafana Alerting API, with all its endpoints.
+//
+//go:embed post.json
+var Spec []byte
@@ -112,9 +112,14 @@ type invalidEvalResultFormatError struct {
 	refID  string
 	reason string
 	err    error
+	// message replaces the formatted message when the error was restored from its message.
+	message string
 }
 
 func (e *invalidEvalResultFormatError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
 	s := fmt.Sprintf("invalid format of evaluation results for the alert definition %s: %s", e.refID, e.reason)
 	if e.err != nil {
 		s = fmt.Sprintf("%s: %s", s, e.err.Error())
@@ -126,6 +131,18 @@ func (e *invalidEvalResultFormatError) Unwrap() error {
 	return e.err
 }
 
+// IsNonRetryableError returns true if the error is not fixed by evaluating the condition again.
+func IsNonRetryableError(err error) bool {
+	var nonRetryableError *invalidEvalResultFormatError
+	return errors.As(err, &nonRetryableError)
+}
+
+// NewNonRetryableError restores a non-retryable error from its message, such as the errors
+// of the results evaluated by another instance.
+func NewNonRetryableError(message string) error {
+	return &invalidEvalResultFormatError{message: message}
+}
+
 // ExecutionResults contains the unevaluated results from executing
 // a condition.
 type ExecutionResults struct {
@@ -164,11 +181,8 @@ func (evalResults Results) HasErrors() bool {
 // Our thinking with this approach, is that we don't want to retry errors that have relation with invalid alert definition format.
 func (evalResults Results) HasNonRetryableErrors() bool {
 	for _, r := range evalResults {
-		if r.State == Error && r.Error != nil {
-			var nonRetryableError *invalidEvalResultFormatError
-			if errors.As(r.Error, &nonRetryableError) {
-				return true
-			}
+		if r.State == Error && r.Error != nil && IsNonRetryableError(r.Error) {
+			return true
 		}
 	}
 	return false
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func resultsToProto(results eval.Results) []*Result {
+	out := make([]*Result, 0, len(results))
+	for _, r := range results {
+		result := &Result{
+			Instance:           labelsToProto(r.Instance),
+			State:              int64(r.State),
+			EvaluatedAt:        r.EvaluatedAt.UnixNano(),
+			EvaluationDuration: int64(r.EvaluationDuration),
+			EvaluationString:   r.EvaluationString,
+		}
+		if r.Error != nil {
+			result.Error = r.Error.Error()
+			result.NonRetryable = eval.IsNonRetryableError(r.Error)
+		}
+		refIDs := make([]string, 0, len(r.Values))
+		for refID := range r.Values {
+			refIDs = append(refIDs, refID)
+		}
+		sort.Strings(refIDs)
+		for _, refID := range refIDs {
+			v := r.Values[refID]
+			value := &Value{
+				RefId:  refID,
+				Labels: labelsToProto(v.Labels),
+			}
+			if v.Value != nil {
+				value.HasValue = true
+				value.Value = *v.Value
+			}
+			result.Values = append(result.Values, value)
+		}
+		out = append(out, result)
+	}
+	return out
+}
+
+func resultsFromProto(results []*Result) eval.Results {
+	out := make(eval.Results, 0, len(results))
+	for _, r := range results {
+		result := eval.Result{
+			Instance:           labelsFromProto(r.GetInstance()),
+			State:              eval.State(r.GetState()),
+			EvaluatedAt:        time.Unix(0, r.GetEvaluatedAt()),
+			EvaluationDuration: time.Duration(r.GetEvaluationDuration()),
+			EvaluationString:   r.GetEvaluationString(),
+		}
+		if r.GetError() != "" {
+			if r.GetNonRetryable() {
+				result.Error = eval.NewNonRetryableError(r.GetError())
+			} else {
+				result.Error = errors.New(r.GetError())
+			}
+		}
+		if len(r.GetValues()) > 0 {
+			result.Values = make(map[string]eval.NumberValueCapture, len(r.GetValues()))
+			for _, v := range r.GetValues() {
+				value := eval.NumberValueCapture{
+					Var:    v.GetRefId(),
+					Labels: labelsFromProto(v.GetLabels()),
+				}
+				if v.GetHasValue() {
+					f := v.GetValue()
+					value.Value = &f
+				}
+				result.Values[v.GetRefId()] = value
+			}
+		}
+		out = append(out, result)
+	}
+	return out
+}
+
+func labelsToProto(lbs data.Labels) []*Label {
+	if lbs == nil {
+		return nil
+	}
+	out := make([]*Label, 0, len(lbs))
+	for name, value := range lbs {
+		out = append(out, &Label{Name: name, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func labelsFromProto(lbs []*Label) data.Labels {
+	if lbs == nil {
+		return nil
+	}
+	out := make(data.Labels, len(lbs))
+	for _, l := range lbs {
+		out[l.GetName()] = l.GetValue()
+	}
+	return out
+}
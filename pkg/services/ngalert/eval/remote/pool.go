@@ -0,0 +1,245 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// callTimeoutMargin is added to the evaluation timeout to get the timeout of the calls to the runners,
+// so that the runners report their evaluation timeouts before the calls time out.
+const callTimeoutMargin = 5 * time.Second
+
+// ErrNoRunnerAvailable is returned when all the runners failed to answer and the fallback to the local evaluation is disabled.
+var ErrNoRunnerAvailable = errors.New("no alert rule evaluation runner available")
+
+// Pool is an eval.EvaluatorFactory that dispatches the evaluations of the scheduled rules to a pool of runners.
+// The rule groups are sharded across the runners with rendezvous hashing, so that all the rules of a group are
+// evaluated by the same runner and only the groups of a failed runner move to the other runners.
+type Pool struct {
+	local           eval.EvaluatorFactory
+	runners         []*runner
+	callTimeout     time.Duration
+	failureBackoff  time.Duration
+	fallbackToLocal bool
+	clock           clock.Clock
+	log             log.Logger
+}
+
+type runner struct {
+	address string
+	client  RuleEvaluatorClient
+
+	mtx              sync.Mutex
+	unavailableUntil time.Time
+}
+
+func (r *runner) isAvailable(now time.Time) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return !now.Before(r.unavailableUntil)
+}
+
+func (r *runner) markUnavailable(until time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.unavailableUntil = until
+}
+
+func NewPool(cfg setting.UnifiedAlertingSettings, local eval.EvaluatorFactory, clk clock.Clock, logger log.Logger) (*Pool, error) {
+	settings := cfg.RemoteEvaluation
+	opts := []grpc.DialOption{
+		grpc.WithPerRPCCredentials(tokenCredentials{token: settings.Token, requireTLS: settings.TLS}),
+	}
+	if settings.TLS {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			InsecureSkipVerify: settings.TLSSkipVerify, // #nosec G402 -- opt-in for runners with self-signed certificates
+		})))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	runners := make([]*runner, 0, len(settings.Runners))
+	for _, address := range settings.Runners {
+		conn, err := grpc.Dial(address, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for runner %s: %w", address, err)
+		}
+		runners = append(runners, &runner{address: address, client: NewRuleEvaluatorClient(conn)})
+	}
+	return newPool(cfg, local, runners, clk, logger), nil
+}
+
+func newPool(cfg setting.UnifiedAlertingSettings, local eval.EvaluatorFactory, runners []*runner, clk clock.Clock, logger log.Logger) *Pool {
+	return &Pool{
+		local:           local,
+		runners:         runners,
+		callTimeout:     cfg.EvaluationTimeout + callTimeoutMargin,
+		failureBackoff:  cfg.RemoteEvaluation.FailureBackoff,
+		fallbackToLocal: cfg.RemoteEvaluation.FallbackToLocal,
+		clock:           clk,
+		log:             logger,
+	}
+}
+
+// Validate validates the condition locally.
+func (p *Pool) Validate(ctx eval.EvaluationContext, condition models.Condition) error {
+	return p.local.Validate(ctx, condition)
+}
+
+// Create returns an evaluator that evaluates the condition on a runner. The condition is evaluated
+// locally if the context does not carry the key of the rule group, because it is not a scheduled evaluation.
+func (p *Pool) Create(ctx eval.EvaluationContext, condition models.Condition) (eval.ConditionEvaluator, error) {
+	groupKey, ok := models.RuleGroupKeyFromContext(ctx.Ctx)
+	if !ok {
+		return p.local.Create(ctx, condition)
+	}
+	return &remoteEvaluator{
+		pool:      p,
+		ctx:       ctx,
+		groupKey:  groupKey,
+		condition: condition,
+	}, nil
+}
+
+// runnersFor returns the runners in the order they are tried for the rule group.
+func (p *Pool) runnersFor(groupKey models.AlertRuleGroupKey) []*runner {
+	type scored struct {
+		runner *runner
+		score  uint64
+	}
+	group := hash(groupKey.String())
+	scores := make([]scored, 0, len(p.runners))
+	for _, r := range p.runners {
+		scores = append(scores, scored{runner: r, score: mix(hash(r.address) ^ group)})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+	result := make([]*runner, 0, len(scores))
+	for _, s := range scores {
+		result = append(result, s.runner)
+	}
+	return result
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix is the finalizer of SplitMix64. It spreads the scores of similar keys, which FNV alone does not.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// isRunnerFailure returns true if the error is caused by the runner or the network rather than by the evaluation,
+// in which case the evaluation is retried on the next runner.
+func isRunnerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Unauthenticated, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+type remoteEvaluator struct {
+	pool      *Pool
+	ctx       eval.EvaluationContext
+	groupKey  models.AlertRuleGroupKey
+	condition models.Condition
+}
+
+// EvaluateRaw evaluates the condition locally because the raw responses of the queries are not sent by the runners.
+func (e *remoteEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (*backend.QueryDataResponse, error) {
+	local, err := e.pool.local.Create(e.ctx, e.condition)
+	if err != nil {
+		return nil, err
+	}
+	return local.EvaluateRaw(ctx, now)
+}
+
+// Evaluate evaluates the condition on the first available runner of the rule group,
+// and moves on to the next runner if the runner fails to answer.
+func (e *remoteEvaluator) Evaluate(ctx context.Context, now time.Time) (eval.Results, error) {
+	condition, err := json.Marshal(e.condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal condition: %w", err)
+	}
+	req := &EvaluateRequest{
+		OrgId:     e.groupKey.OrgID,
+		Condition: condition,
+		Now:       now.UnixNano(),
+	}
+	logger := e.pool.log.FromContext(ctx)
+
+	for _, r := range e.pool.runnersFor(e.groupKey) {
+		if !r.isAvailable(e.pool.clock.Now()) {
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, e.pool.callTimeout)
+		resp, err := r.client.Evaluate(callCtx, req)
+		cancel()
+		if err == nil {
+			return resultsFromProto(resp.GetResults()), nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !isRunnerFailure(err) {
+			return nil, errors.New(status.Convert(err).Message())
+		}
+		logger.Warn("Alert rule evaluation runner failed, moving its evaluations to the other runners", "runner", r.address, "backoff", e.pool.failureBackoff, "error", err)
+		r.markUnavailable(e.pool.clock.Now().Add(e.pool.failureBackoff))
+	}
+
+	if !e.pool.fallbackToLocal {
+		return nil, ErrNoRunnerAvailable
+	}
+	logger.Debug("No alert rule evaluation runner available, evaluating the rule locally")
+	local, err := e.pool.local.Create(e.ctx, e.condition)
+	if err != nil {
+		return nil, err
+	}
+	return local.Evaluate(ctx, now)
+}
+
+// tokenCredentials sends the shared token of the runners with every call.
+type tokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": tokenPrefix + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
@@ -0,0 +1,238 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testToken = "secret"
+
+type fakeEvaluatorFactory struct {
+	results eval.Results
+	err     error
+	calls   int
+}
+
+func (f *fakeEvaluatorFactory) Validate(eval.EvaluationContext, models.Condition) error {
+	return nil
+}
+
+func (f *fakeEvaluatorFactory) Create(eval.EvaluationContext, models.Condition) (eval.ConditionEvaluator, error) {
+	return f, nil
+}
+
+func (f *fakeEvaluatorFactory) EvaluateRaw(context.Context, time.Time) (*backend.QueryDataResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeEvaluatorFactory) Evaluate(context.Context, time.Time) (eval.Results, error) {
+	f.calls++
+	return f.results, f.err
+}
+
+// startRunner serves the evaluator on an in-memory listener and returns a runner connected to it.
+func startRunner(t *testing.T, address string, evaluator eval.EvaluatorFactory, token string) (*runner, func()) {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcAuth.UnaryServerInterceptor(func(ctx context.Context) (context.Context, error) {
+		return ctx, status.Error(codes.Unauthenticated, "service accounts are not accepted")
+	})))
+	RegisterRuleEvaluatorServer(server, &runnerServer{RunnerService: &RunnerService{
+		log:       log.NewNopLogger(),
+		token:     testToken,
+		evaluator: evaluator,
+	}})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial(address,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(tokenCredentials{token: token}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+	return &runner{address: address, client: NewRuleEvaluatorClient(conn)}, server.Stop
+}
+
+func testPool(local eval.EvaluatorFactory, fallbackToLocal bool, clk clock.Clock, runners ...*runner) *Pool {
+	cfg := setting.UnifiedAlertingSettings{
+		EvaluationTimeout: 5 * time.Second,
+		RemoteEvaluation: setting.UnifiedAlertingRemoteEvaluationSettings{
+			FailureBackoff:  30 * time.Second,
+			FallbackToLocal: fallbackToLocal,
+		},
+	}
+	return newPool(cfg, local, runners, clk, log.NewNopLogger())
+}
+
+func evaluate(t *testing.T, p *Pool, groupKey models.AlertRuleGroupKey) (eval.Results, error) {
+	t.Helper()
+	ctx := models.WithRuleGroupKey(context.Background(), groupKey)
+	evaluator, err := p.Create(eval.NewContext(ctx, nil), models.Condition{Condition: "A"})
+	require.NoError(t, err)
+	return evaluator.Evaluate(ctx, time.Now())
+}
+
+func TestPool(t *testing.T) {
+	groupKey := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+	value := 42.5
+	results := eval.Results{
+		{
+			Instance: data.Labels{"instance": "a"},
+			State:    eval.Alerting,
+			Values: map[string]eval.NumberValueCapture{
+				"B": {Var: "B", Labels: data.Labels{"instance": "a"}, Value: &value},
+				"C": {Var: "C", Labels: data.Labels{"instance": "a"}},
+			},
+			EvaluatedAt:        time.Unix(0, 1000),
+			EvaluationDuration: time.Second,
+			EvaluationString:   "[ var='B' value=42.5 ]",
+		},
+		{
+			State:       eval.Error,
+			Error:       eval.NewNonRetryableError("invalid format of evaluation results"),
+			EvaluatedAt: time.Unix(0, 1000),
+		},
+		{
+			State:       eval.Error,
+			Error:       errors.New("query failed"),
+			EvaluatedAt: time.Unix(0, 1000),
+		},
+	}
+
+	t.Run("evaluates the condition on the runner", func(t *testing.T) {
+		remote := &fakeEvaluatorFactory{results: results}
+		local := &fakeEvaluatorFactory{}
+		r, _ := startRunner(t, "runner-1", remote, testToken)
+
+		actual, err := evaluate(t, testPool(local, true, clock.NewMock(), r), groupKey)
+		require.NoError(t, err)
+		require.Equal(t, 1, remote.calls)
+		require.Equal(t, 0, local.calls)
+		require.Len(t, actual, len(results))
+		assert.Equal(t, results[0], actual[0])
+		assert.True(t, eval.IsNonRetryableError(actual[1].Error))
+		assert.Equal(t, results[1].Error.Error(), actual[1].Error.Error())
+		assert.False(t, eval.IsNonRetryableError(actual[2].Error))
+		assert.Equal(t, "query failed", actual[2].Error.Error())
+		assert.True(t, actual.HasNonRetryableErrors())
+	})
+
+	t.Run("returns the evaluation errors of the runner", func(t *testing.T) {
+		remote := &fakeEvaluatorFactory{err: errors.New("pipeline failed")}
+		local := &fakeEvaluatorFactory{}
+		r, _ := startRunner(t, "runner-1", remote, testToken)
+
+		_, err := evaluate(t, testPool(local, true, clock.NewMock(), r), groupKey)
+		require.EqualError(t, err, "pipeline failed")
+		require.Equal(t, 0, local.calls)
+	})
+
+	t.Run("evaluates the condition locally if it is not a scheduled evaluation", func(t *testing.T) {
+		remote := &fakeEvaluatorFactory{}
+		local := &fakeEvaluatorFactory{results: results}
+		r, _ := startRunner(t, "runner-1", remote, testToken)
+		p := testPool(local, true, clock.NewMock(), r)
+
+		evaluator, err := p.Create(eval.NewContext(context.Background(), nil), models.Condition{Condition: "A"})
+		require.NoError(t, err)
+		_, err = evaluator.Evaluate(context.Background(), time.Now())
+		require.NoError(t, err)
+		require.Equal(t, 0, remote.calls)
+		require.Equal(t, 1, local.calls)
+	})
+
+	t.Run("moves the evaluations of a failed runner to the other runners", func(t *testing.T) {
+		clk := clock.NewMock()
+		local := &fakeEvaluatorFactory{}
+		remote1 := &fakeEvaluatorFactory{results: results}
+		remote2 := &fakeEvaluatorFactory{results: results}
+		r1, stop1 := startRunner(t, "runner-1", remote1, testToken)
+		r2, _ := startRunner(t, "runner-2", remote2, testToken)
+		p := testPool(local, true, clk, r1, r2)
+
+		// Find a rule group of the first runner.
+		var key models.AlertRuleGroupKey
+		for i := 0; ; i++ {
+			key = models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: fmt.Sprintf("group-%d", i)}
+			if p.runnersFor(key)[0] == r1 {
+				break
+			}
+		}
+		stop1()
+
+		_, err := evaluate(t, p, key)
+		require.NoError(t, err)
+		require.Equal(t, 0, remote1.calls)
+		require.Equal(t, 1, remote2.calls)
+		require.False(t, r1.isAvailable(clk.Now()))
+
+		clk.Add(30 * time.Second)
+		require.True(t, r1.isAvailable(clk.Now()))
+	})
+
+	t.Run("falls back to the local evaluation when no runner is available", func(t *testing.T) {
+		local := &fakeEvaluatorFactory{results: results}
+		r, stop := startRunner(t, "runner-1", &fakeEvaluatorFactory{}, testToken)
+		stop()
+
+		actual, err := evaluate(t, testPool(local, true, clock.NewMock(), r), groupKey)
+		require.NoError(t, err)
+		require.Equal(t, results, actual)
+		require.Equal(t, 1, local.calls)
+	})
+
+	t.Run("fails when no runner is available and the fallback is disabled", func(t *testing.T) {
+		local := &fakeEvaluatorFactory{results: results}
+		r, _ := startRunner(t, "runner-1", &fakeEvaluatorFactory{}, "wrong token")
+
+		_, err := evaluate(t, testPool(local, false, clock.NewMock(), r), groupKey)
+		require.ErrorIs(t, err, ErrNoRunnerAvailable)
+		require.Equal(t, 0, local.calls)
+	})
+}
+
+func TestPool_runnersFor(t *testing.T) {
+	runners := []*runner{{address: "runner-1"}, {address: "runner-2"}, {address: "runner-3"}}
+	p := testPool(&fakeEvaluatorFactory{}, true, clock.NewMock(), runners...)
+
+	counts := map[string]int{}
+	for i := 0; i < 300; i++ {
+		key := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: fmt.Sprintf("group-%d", i)}
+		order := p.runnersFor(key)
+		require.Len(t, order, len(runners))
+		require.Equal(t, order, p.runnersFor(key), "the order of the runners should be stable")
+		counts[order[0].address]++
+	}
+	for _, r := range runners {
+		assert.Greater(t, counts[r.address], 50, "the rule groups should be spread across the runners")
+	}
+}
@@ -0,0 +1,98 @@
+// Package remote evaluates the conditions of alert rules on a pool of runners.
+// A runner is a Grafana instance that shares the database of the scheduler and
+// serves the evaluation requests over the GRPC server, so that a large number
+// of rules does not compete with the API server for CPU.
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const tokenPrefix = "Bearer "
+
+// RunnerService serves the evaluation requests of the schedulers of other instances
+// when the instance is configured as a runner.
+type RunnerService struct {
+	log       log.Logger
+	token     string
+	evaluator eval.EvaluatorFactory
+}
+
+func ProvideRunnerService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, grpcServerProvider grpcserver.Provider,
+	dataSourceCache datasources.CacheService, expressionService *expr.Service, pluginsStore pluginstore.Store) *RunnerService {
+	s := &RunnerService{
+		log:   log.New("ngalert.eval.runner"),
+		token: cfg.UnifiedAlerting.RemoteEvaluation.Token,
+	}
+	if !cfg.UnifiedAlerting.RemoteEvaluation.RunnerEnabled {
+		return s
+	}
+	if !features.IsEnabledGlobally(featuremgmt.FlagGrpcServer) {
+		s.log.Warn("The instance is configured as an alert rule evaluation runner but the GRPC server is disabled", "featureToggle", featuremgmt.FlagGrpcServer)
+		return s
+	}
+
+	s.evaluator = eval.NewEvaluatorFactory(cfg.UnifiedAlerting, dataSourceCache, expressionService, pluginsStore)
+	RegisterRuleEvaluatorServer(grpcServerProvider.GetServer(), &runnerServer{RunnerService: s})
+	return s
+}
+
+type runnerServer struct {
+	*RunnerService
+}
+
+// AuthFuncOverride authenticates the schedulers with the shared token instead of
+// the service account tokens used by the other services.
+func (s *runnerServer) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "no headers in request")
+	}
+	auth := md.Get("authorization")
+	if len(auth) != 1 || !strings.HasPrefix(auth[0], tokenPrefix) {
+		return ctx, status.Error(codes.Unauthenticated, "token required")
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth[0], tokenPrefix)), []byte(s.token)) != 1 {
+		return ctx, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return ctx, nil
+}
+
+func (s *runnerServer) Evaluate(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	var condition models.Condition
+	if err := json.Unmarshal(req.GetCondition(), &condition); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid condition: %s", err)
+	}
+	logger := s.log.FromContext(ctx).New("org_id", req.GetOrgId())
+
+	ruleEval, err := s.evaluator.Create(eval.NewContext(ctx, schedule.SchedulerUserFor(req.GetOrgId())), condition)
+	if err != nil {
+		logger.Debug("Failed to build rule evaluator", "error", err)
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to build rule evaluator: %s", err)
+	}
+	results, err := ruleEval.Evaluate(ctx, time.Unix(0, req.GetNow()))
+	if err != nil {
+		logger.Debug("Failed to evaluate rule", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &EvaluateResponse{Results: resultsToProto(results)}, nil
+}
@@ -3,16 +3,20 @@ package image
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/singleflight"
 
 	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -22,6 +26,7 @@ import (
 
 const (
 	screenshotCacheTTL = time.Minute
+	summaryAnnotation  = "summary"
 )
 
 // DeleteExpiredService is a service to delete expired images.
@@ -40,14 +45,18 @@ func ProvideDeleteExpiredService(store *store.DBstore) *DeleteExpiredService {
 type ImageService interface {
 	// NewImage returns a new image for the alert instance.
 	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
+	// NewSummaryCard returns a new image of the state, values and thresholds of the alert instance.
+	NewSummaryCard(ctx context.Context, r *models.AlertRule, state eval.State, annotations map[string]string, values map[string]float64) (*models.Image, error)
 }
 
 // ScreenshotImageService takes screenshots of the alert rule and saves the
 // image in the store. The image contains a unique token that can be passed
 // as an annotation or label to the Alertmanager. This service cannot take
-// screenshots of alert rules that are not associated with a dashboard panel.
+// screenshots of alert rules that are not associated with a dashboard panel,
+// it renders summary cards of their alert instances instead.
 type ScreenshotImageService struct {
 	cache             CacheService
+	cards             screenshot.CardService
 	limiter           screenshot.RateLimiter
 	logger            log.Logger
 	screenshots       screenshot.ScreenshotService
@@ -63,11 +72,13 @@ func NewScreenshotImageService(
 	limiter screenshot.RateLimiter,
 	logger log.Logger,
 	screenshots screenshot.ScreenshotService,
+	cards screenshot.CardService,
 	screenshotTimeout time.Duration,
 	store store.ImageStore,
 	uploads *UploadingService) ImageService {
 	return &ScreenshotImageService{
 		cache:             cache,
+		cards:             cards,
 		limiter:           limiter,
 		logger:            logger,
 		screenshots:       screenshots,
@@ -80,9 +91,10 @@ func NewScreenshotImageService(
 // NewScreenshotImageServiceFromCfg returns a new ScreenshotImageService
 // from the configuration.
 func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds dashboards.DashboardService,
-	rs rendering.Service, r prometheus.Registerer) (ImageService, error) {
+	rs rendering.Service, cs screenshot.CardService, r prometheus.Registerer) (ImageService, error) {
 	var (
 		cache             CacheService                 = &NoOpCacheService{}
+		cards             screenshot.CardService       = &screenshot.CardUnavailableService{}
 		limiter           screenshot.RateLimiter       = &screenshot.NoOpRateLimiter{}
 		screenshots       screenshot.ScreenshotService = &screenshot.ScreenshotUnavailableService{}
 		screenshotTimeout time.Duration                = 0
@@ -96,6 +108,11 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 		screenshots = screenshot.NewHeadlessScreenshotService(ds, rs, r)
 		screenshotTimeout = cfg.UnifiedAlerting.Screenshots.CaptureTimeout
 
+		// Summary cards are an optional feature
+		if cfg.UnifiedAlerting.Screenshots.CaptureSummaryCards {
+			cards = cs
+		}
+
 		// Image uploading is an optional feature
 		if cfg.UnifiedAlerting.Screenshots.UploadExternalImageStorage {
 			m, err := imguploader.NewImageUploader()
@@ -107,7 +124,7 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 	}
 
 	return NewScreenshotImageService(cache, limiter, log.New("ngalert.image"),
-		screenshots, screenshotTimeout, db, uploads), nil
+		screenshots, cards, screenshotTimeout, db, uploads), nil
 }
 
 // NewImage returns a screenshot of the alert rule or an error.
@@ -172,23 +189,7 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 		}
 
 		logger.Debug("Took screenshot", "path", screenshot.Path)
-		image := models.Image{Path: screenshot.Path}
-
-		// Uploading images is optional
-		if s.uploads != nil {
-			if image, err = s.uploads.Upload(ctx, image); err != nil {
-				logger.Warn("Failed to upload image", "error", err)
-			} else {
-				logger.Debug("Uploaded image", "url", image.URL)
-			}
-		}
-
-		if err := s.store.SaveImage(ctx, &image); err != nil {
-			return nil, fmt.Errorf("failed to save image: %w", err)
-		}
-		logger.Debug("Saved image", "token", image.Token)
-
-		return image, nil
+		return s.saveImage(ctx, logger, screenshot.Path)
 	})
 	if err != nil {
 		return nil, err
@@ -203,3 +204,109 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 
 	return &image, nil
 }
+
+// NewSummaryCard returns a summary card of the alert instance or an error. The card shows
+// the title of the alert rule, the state, the summary annotation and the values of the alert
+// instance, and the thresholds of the threshold expressions of the alert rule. It returns
+// screenshot.ErrScreenshotsUnavailable if summary cards are disabled.
+func (s *ScreenshotImageService) NewSummaryCard(ctx context.Context, r *models.AlertRule, state eval.State, annotations map[string]string, values map[string]float64) (*models.Image, error) {
+	logger := s.logger.FromContext(ctx).New("rule_uid", r.UID)
+
+	refIDs := make([]string, 0, len(values))
+	for refID := range values {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+	cardValues := make([]screenshot.CardValue, 0, len(refIDs))
+	for _, refID := range refIDs {
+		cardValues = append(cardValues, screenshot.CardValue{Name: refID, Value: values[refID]})
+	}
+
+	opts := screenshot.CardOptions{
+		OrgID:      r.OrgID,
+		Title:      r.Title,
+		State:      state.String(),
+		Summary:    annotations[summaryAnnotation],
+		Time:       time.Now(),
+		Values:     cardValues,
+		Thresholds: ruleThresholds(r),
+		Timeout:    s.screenshotTimeout,
+	}
+
+	logger.Debug("Requesting summary card")
+
+	// See NewImage for the timeouts.
+	cardCtx, cancelFunc := context.WithTimeout(ctx, s.screenshotTimeout)
+	defer cancelFunc()
+
+	card, err := s.cards.TakeCard(cardCtx, opts)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Took summary card", "path", card.Path)
+
+	image, err := s.saveImage(ctx, logger, card.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// saveImage uploads the image at the path, if uploading images is enabled, and saves it in the store.
+func (s *ScreenshotImageService) saveImage(ctx context.Context, logger log.Logger, path string) (models.Image, error) {
+	image := models.Image{Path: path}
+
+	// Uploading images is optional
+	if s.uploads != nil {
+		var err error
+		if image, err = s.uploads.Upload(ctx, image); err != nil {
+			logger.Warn("Failed to upload image", "error", err)
+		} else {
+			logger.Debug("Uploaded image", "url", image.URL)
+		}
+	}
+
+	if err := s.store.SaveImage(ctx, &image); err != nil {
+		return models.Image{}, fmt.Errorf("failed to save image: %w", err)
+	}
+	logger.Debug("Saved image", "token", image.Token)
+
+	return image, nil
+}
+
+// ruleThresholds returns the thresholds of the threshold expressions of the alert rule.
+// Ranges are returned as their two bounds.
+func ruleThresholds(r *models.AlertRule) []screenshot.CardThreshold {
+	var thresholds []screenshot.CardThreshold
+	for _, q := range r.Data {
+		if isExpr, _ := q.IsExpression(); !isExpr {
+			continue
+		}
+		var model struct {
+			Type string `json:"type"`
+			expr.ThresholdCommandConfig
+		}
+		if err := json.Unmarshal(q.Model, &model); err != nil || model.Type != "threshold" {
+			continue
+		}
+		for _, condition := range model.Conditions {
+			params := condition.Evaluator.Params
+			name := model.Expression
+			switch {
+			case condition.Evaluator.Type == expr.ThresholdIsAbove && len(params) > 0:
+				thresholds = append(thresholds, screenshot.CardThreshold{Name: name, Operator: ">", Value: params[0]})
+			case condition.Evaluator.Type == expr.ThresholdIsBelow && len(params) > 0:
+				thresholds = append(thresholds, screenshot.CardThreshold{Name: name, Operator: "<", Value: params[0]})
+			case condition.Evaluator.Type == expr.ThresholdIsWithinRange && len(params) > 1:
+				thresholds = append(thresholds,
+					screenshot.CardThreshold{Name: name, Operator: ">", Value: params[0]},
+					screenshot.CardThreshold{Name: name, Operator: "<", Value: params[1]})
+			case condition.Evaluator.Type == expr.ThresholdIsOutsideRange && len(params) > 1:
+				thresholds = append(thresholds,
+					screenshot.CardThreshold{Name: name, Operator: "<", Value: params[0]},
+					screenshot.CardThreshold{Name: name, Operator: ">", Value: params[1]})
+			}
+		}
+	}
+	return thresholds
+}
@@ -2,7 +2,9 @@ package image
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -12,7 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/screenshot"
@@ -31,7 +35,7 @@ func TestScreenshotImageService(t *testing.T) {
 		uploads     = imguploader.NewMockImageUploader(ctrl)
 	)
 
-	s := NewScreenshotImageService(cache, &limiter, log.NewNopLogger(), screenshots, 5*time.Second, images,
+	s := NewScreenshotImageService(cache, &limiter, log.NewNopLogger(), screenshots, &screenshot.CardUnavailableService{}, 5*time.Second, images,
 		NewUploadingService(uploads, prometheus.NewRegistry()))
 
 	ctx := context.Background()
@@ -147,3 +151,72 @@ func TestScreenshotImageService(t *testing.T) {
 		assert.Nil(t, image)
 	})
 }
+
+func TestScreenshotImageServiceSummaryCard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		cards  = &fakeCardService{}
+		images = store.NewFakeImageStore(t)
+	)
+
+	s := NewScreenshotImageService(NewMockCacheService(ctrl), &screenshot.NoOpRateLimiter{}, log.NewNopLogger(),
+		screenshot.NewMockScreenshotService(ctrl), cards, 5*time.Second, images, nil)
+
+	ctx := context.Background()
+	rule := &models.AlertRule{
+		OrgID: 1,
+		UID:   "foo",
+		Title: "High CPU",
+		Data: []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "prometheus", Model: json.RawMessage(`{"expr":"cpu"}`)},
+			{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"reduce","expression":"A","reducer":"last"}`)},
+			{RefID: "C", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"gt","params":[80]}}]}`)},
+			{RefID: "D", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"outside_range","params":[10,90]}}]}`)},
+		},
+	}
+
+	t.Run("summary card is taken and saved to database", func(t *testing.T) {
+		cards.screenshot = &screenshot.Screenshot{Path: "card.png"}
+
+		image, err := s.NewSummaryCard(ctx, rule, eval.Alerting, map[string]string{"summary": "CPU is high"}, map[string]float64{"C": 1, "B": 92.5, "X": math.NaN()})
+		require.NoError(t, err)
+		assert.Equal(t, models.Image{ID: 1, Token: "card", Path: "card.png"}, *image)
+
+		opts := cards.opts
+		assert.Equal(t, int64(1), opts.OrgID)
+		assert.Equal(t, "High CPU", opts.Title)
+		assert.Equal(t, "Alerting", opts.State)
+		assert.Equal(t, "CPU is high", opts.Summary)
+		assert.Equal(t, 5*time.Second, opts.Timeout)
+		require.Len(t, opts.Values, 3)
+		assert.Equal(t, screenshot.CardValue{Name: "B", Value: 92.5}, opts.Values[0])
+		assert.Equal(t, screenshot.CardValue{Name: "C", Value: 1}, opts.Values[1])
+		assert.Equal(t, "X", opts.Values[2].Name)
+		assert.Equal(t, []screenshot.CardThreshold{
+			{Name: "B", Operator: ">", Value: 80},
+			{Name: "B", Operator: "<", Value: 10},
+			{Name: "B", Operator: ">", Value: 90},
+		}, opts.Thresholds)
+	})
+
+	t.Run("error is returned when summary cards are unavailable", func(t *testing.T) {
+		cards.screenshot, cards.err = nil, screenshot.ErrScreenshotsUnavailable
+
+		image, err := s.NewSummaryCard(ctx, rule, eval.Normal, nil, nil)
+		assert.ErrorIs(t, err, screenshot.ErrScreenshotsUnavailable)
+		assert.Nil(t, image)
+	})
+}
+
+type fakeCardService struct {
+	opts       screenshot.CardOptions
+	screenshot *screenshot.Screenshot
+	err        error
+}
+
+func (f *fakeCardService) TakeCard(_ context.Context, opts screenshot.CardOptions) (*screenshot.Screenshot, error) {
+	f.opts = opts
+	return f.screenshot, f.err
+}
@@ -0,0 +1,379 @@
+// Package maintenance applies the maintenance windows of the organizations: it keeps a silence in the
+// Alertmanager of the organization for every window that has not ended, and writes region annotations
+// tagged "maintenance" on the affected dashboards once a window starts.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	alertingModels "github.com/grafana/alerting/models"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+const (
+	// reconcileInterval is how often the maintenance windows are applied.
+	reconcileInterval = 15 * time.Second
+
+	silenceCommentPrefix = "Maintenance window "
+)
+
+type WindowStore interface {
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error)
+	SaveMaintenanceWindowAnnotations(ctx context.Context, window *models.MaintenanceWindow) error
+}
+
+type RuleStore interface {
+	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
+}
+
+type OrgStore interface {
+	GetOrgs(ctx context.Context) ([]int64, error)
+}
+
+type AlertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (notifier.Alertmanager, error)
+}
+
+type DashboardService interface {
+	GetDashboard(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error)
+}
+
+type AnnotationRepository interface {
+	Save(ctx context.Context, item *annotations.Item) error
+	Update(ctx context.Context, item *annotations.Item) error
+	Delete(ctx context.Context, params *annotations.DeleteParams) error
+}
+
+// Reconciler periodically applies the maintenance windows of all the organizations.
+type Reconciler struct {
+	windows       WindowStore
+	rules         RuleStore
+	orgs          OrgStore
+	alertmanagers AlertmanagerProvider
+	dashboards    DashboardService
+	annotations   AnnotationRepository
+	clock         clock.Clock
+	log           log.Logger
+}
+
+func NewReconciler(windows WindowStore, rules RuleStore, orgs OrgStore, alertmanagers AlertmanagerProvider, dashboards DashboardService, annotations AnnotationRepository, clock clock.Clock, log log.Logger) *Reconciler {
+	return &Reconciler{
+		windows:       windows,
+		rules:         rules,
+		orgs:          orgs,
+		alertmanagers: alertmanagers,
+		dashboards:    dashboards,
+		annotations:   annotations,
+		clock:         clock,
+		log:           log,
+	}
+}
+
+// Run applies the maintenance windows every reconcileInterval until the context is canceled.
+func (r *Reconciler) Run(ctx context.Context) error {
+	ticker := r.clock.Ticker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.reconcile(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	orgIDs, err := r.orgs.GetOrgs(ctx)
+	if err != nil {
+		r.log.Error("Failed to get organizations to apply maintenance windows", "error", err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		if err := r.ReconcileOrg(ctx, orgID); err != nil {
+			r.log.Error("Failed to apply maintenance windows", "org", orgID, "error", err)
+		}
+	}
+}
+
+// ReconcileOrg applies the maintenance windows of the organization.
+func (r *Reconciler) ReconcileOrg(ctx context.Context, orgID int64) error {
+	windows, err := r.windows.GetMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	now := r.clock.Now()
+
+	var errs []error
+	am, err := r.alertmanagers.AlertmanagerFor(orgID)
+	switch {
+	case err == nil:
+		if err := r.syncSilences(ctx, am, windows, now); err != nil {
+			errs = append(errs, err)
+		}
+	case errors.Is(err, notifier.ErrNoAlertmanagerForOrg), errors.Is(err, notifier.ErrAlertmanagerNotReady):
+		r.log.Debug("Skipping the silences of maintenance windows", "org", orgID, "reason", err)
+	default:
+		errs = append(errs, err)
+	}
+
+	var rules models.RulesGroup
+	rulesLoaded := false
+	for _, w := range windows {
+		if !needsAnnotations(w, now) {
+			continue
+		}
+		if !rulesLoaded {
+			rules, err = r.rules.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID})
+			if err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			rulesLoaded = true
+		}
+		if err := r.syncAnnotations(ctx, w, rules, now); err != nil {
+			errs = append(errs, fmt.Errorf("maintenance window %s: %w", w.UID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncSilences creates or updates the silence of every window that has not ended, and expires the
+// silences of the windows that were deleted.
+func (r *Reconciler) syncSilences(ctx context.Context, am notifier.Alertmanager, windows []*models.MaintenanceWindow, now time.Time) error {
+	silences, err := am.ListSilences(ctx, nil)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*apimodels.GettableSilence)
+	for _, s := range silences {
+		if s.CreatedBy == nil || *s.CreatedBy != models.MaintenanceWindowSilenceCreatedBy || isExpired(s) {
+			continue
+		}
+		uid, ok := windowUIDFromComment(s.Comment)
+		if !ok {
+			continue
+		}
+		existing[uid] = s
+	}
+
+	var errs []error
+	for _, w := range windows {
+		if w.HasEnded(now) {
+			continue
+		}
+		s, ok := existing[w.UID]
+		delete(existing, w.UID)
+		if ok && silenceUpToDate(s, w, now) {
+			continue
+		}
+		ps := silenceForWindow(w)
+		if ok {
+			ps.ID = *s.ID
+		}
+		if _, err := am.CreateSilence(ctx, ps); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save the silence of maintenance window %s: %w", w.UID, err))
+		}
+	}
+	for uid, s := range existing {
+		if err := am.DeleteSilence(ctx, *s.ID); err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			errs = append(errs, fmt.Errorf("failed to expire the silence of deleted maintenance window %s: %w", uid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// needsAnnotations returns true if the annotations of the window were not written for its current version,
+// either because it has just started or because it was updated since.
+func needsAnnotations(w *models.MaintenanceWindow, now time.Time) bool {
+	if w.AnnotationsUpdated.Equal(w.Updated) {
+		return false
+	}
+	// The annotations of windows that have not started yet are written when they start.
+	return !now.Before(w.StartsAt) || len(w.Annotations) > 0
+}
+
+// syncAnnotations writes a region annotation for the window on each affected dashboard, and removes the
+// annotations of the dashboards that are no longer affected. The annotations of a window that was moved to
+// the future are removed until it starts.
+func (r *Reconciler) syncAnnotations(ctx context.Context, w *models.MaintenanceWindow, rules models.RulesGroup, now time.Time) error {
+	desired := map[string]struct{}{}
+	if !now.Before(w.StartsAt) {
+		for _, uid := range affectedDashboards(w, rules) {
+			desired[uid] = struct{}{}
+		}
+	}
+
+	written := make(map[string]int64, len(desired))
+	for uid, id := range w.Annotations {
+		if _, ok := desired[uid]; ok {
+			written[uid] = id
+			continue
+		}
+		if err := r.annotations.Delete(ctx, &annotations.DeleteParams{OrgID: w.OrgID, ID: id}); err != nil {
+			return fmt.Errorf("failed to delete annotation %d: %w", id, err)
+		}
+	}
+
+	for uid := range desired {
+		item := annotationForWindow(w)
+		if id, ok := written[uid]; ok {
+			item.ID = id
+			if err := r.annotations.Update(ctx, item); err != nil {
+				return fmt.Errorf("failed to update annotation %d: %w", id, err)
+			}
+			continue
+		}
+		dash, err := r.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: w.OrgID})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				r.log.Debug("Skipping the annotation of a missing dashboard", "maintenanceWindow", w.UID, "dashboardUID", uid)
+				continue
+			}
+			return err
+		}
+		item.DashboardID = dash.ID
+		if err := r.annotations.Save(ctx, item); err != nil {
+			return fmt.Errorf("failed to save the annotation of dashboard %s: %w", uid, err)
+		}
+		written[uid] = item.ID
+	}
+
+	w.Annotations = written
+	w.AnnotationsUpdated = w.Updated
+	return r.windows.SaveMaintenanceWindowAnnotations(ctx, w)
+}
+
+// affectedDashboards returns the dashboards of the window and the dashboards of the alert rules whose labels
+// match the matchers of the window.
+func affectedDashboards(w *models.MaintenanceWindow, rules models.RulesGroup) []string {
+	seen := map[string]struct{}{}
+	result := make([]string, 0, len(w.DashboardUIDs))
+	add := func(uid string) {
+		if _, ok := seen[uid]; ok || uid == "" {
+			return
+		}
+		seen[uid] = struct{}{}
+		result = append(result, uid)
+	}
+	for _, uid := range w.DashboardUIDs {
+		add(uid)
+	}
+	for _, rule := range rules {
+		if rule.DashboardUID == nil {
+			continue
+		}
+		lbs := make(map[string]string, len(rule.Labels)+2)
+		for k, v := range rule.Labels {
+			lbs[k] = v
+		}
+		lbs[alertingModels.RuleUIDLabel] = rule.UID
+		lbs[model.AlertNameLabel] = rule.Title
+		if w.Matches(lbs) {
+			add(*rule.DashboardUID)
+		}
+	}
+	return result
+}
+
+func annotationForWindow(w *models.MaintenanceWindow) *annotations.Item {
+	text := w.Title
+	if w.Comment != "" {
+		text += "\n" + w.Comment
+	}
+	return &annotations.Item{
+		OrgID:    w.OrgID,
+		Text:     text,
+		Epoch:    w.StartsAt.UnixMilli(),
+		EpochEnd: w.EndsAt.UnixMilli(),
+		Tags:     []string{models.MaintenanceAnnotationTag, w.AnnotationTag()},
+	}
+}
+
+func silenceForWindow(w *models.MaintenanceWindow) *apimodels.PostableSilence {
+	comment := silenceComment(w)
+	createdBy := models.MaintenanceWindowSilenceCreatedBy
+	startsAt := strfmt.DateTime(w.StartsAt)
+	endsAt := strfmt.DateTime(w.EndsAt)
+	return &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			Matchers:  silenceMatchers(w.Matchers),
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+		},
+	}
+}
+
+// silenceUpToDate returns true if the silence still matches the window. The Alertmanager moves the start of
+// silences created in the past to their creation time, so the start only has to match for future windows.
+func silenceUpToDate(s *apimodels.GettableSilence, w *models.MaintenanceWindow, now time.Time) bool {
+	if s.StartsAt == nil || s.EndsAt == nil || s.Comment == nil {
+		return false
+	}
+	startsAt := time.Time(*s.StartsAt)
+	if !startsAt.Equal(w.StartsAt) && (w.StartsAt.After(now) || startsAt.After(now)) {
+		return false
+	}
+	return time.Time(*s.EndsAt).Equal(w.EndsAt) &&
+		*s.Comment == silenceComment(w) &&
+		matchersKey(s.Matchers) == matchersKey(silenceMatchers(w.Matchers))
+}
+
+func silenceMatchers(matchers labels.Matchers) amv2.Matchers {
+	result := make(amv2.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		name, value := m.Name, m.Value
+		isEqual := m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp
+		isRegex := m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp
+		result = append(result, &amv2.Matcher{Name: &name, Value: &value, IsEqual: &isEqual, IsRegex: &isRegex})
+	}
+	return result
+}
+
+func matchersKey(matchers amv2.Matchers) string {
+	keys := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			continue
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		keys = append(keys, fmt.Sprintf("%s|%t|%t|%s", *m.Name, isEqual, *m.IsRegex, *m.Value))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
+func silenceComment(w *models.MaintenanceWindow) string {
+	return silenceCommentPrefix + w.UID + ": " + w.Title
+}
+
+// windowUIDFromComment returns the UID of the maintenance window of a silence created by the reconciler.
+func windowUIDFromComment(comment *string) (string, bool) {
+	if comment == nil || !strings.HasPrefix(*comment, silenceCommentPrefix) {
+		return "", false
+	}
+	uid, _, ok := strings.Cut(strings.TrimPrefix(*comment, silenceCommentPrefix), ":")
+	return uid, ok && uid != ""
+}
+
+func isExpired(s *apimodels.GettableSilence) bool {
+	return s.Status != nil && s.Status.State != nil && *s.Status.State == amv2.SilenceStatusStateExpired
+}
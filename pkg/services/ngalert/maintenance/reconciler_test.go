@@ -0,0 +1,216 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func TestReconcileOrg(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	dbDashboard := "db-dashboard"
+	webDashboard := "web-dashboard"
+	rules := models.RulesGroup{
+		{UID: "db-rule", Title: "DB down", Labels: map[string]string{"team": "db"}, DashboardUID: &dbDashboard},
+		{UID: "web-rule", Title: "Web down", Labels: map[string]string{"team": "web"}, DashboardUID: &webDashboard},
+	}
+	newWindow := func(startsAt time.Time) *models.MaintenanceWindow {
+		return &models.MaintenanceWindow{
+			OrgID:         1,
+			UID:           "upgrade",
+			Title:         "Upgrade",
+			Matchers:      labels.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "db"}},
+			DashboardUIDs: []string{"ops"},
+			StartsAt:      startsAt,
+			EndsAt:        startsAt.Add(time.Hour),
+			Updated:       now.Add(-time.Hour),
+		}
+	}
+
+	t.Run("future window is silenced but not annotated", func(t *testing.T) {
+		window := newWindow(now.Add(time.Hour))
+		r, am, repo := newTestReconciler(now, rules, window)
+
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+
+		require.Len(t, am.silences, 1)
+		s := am.silences[0]
+		require.Equal(t, models.MaintenanceWindowSilenceCreatedBy, *s.CreatedBy)
+		require.True(t, time.Time(*s.StartsAt).Equal(window.StartsAt))
+		require.True(t, time.Time(*s.EndsAt).Equal(window.EndsAt))
+		require.Equal(t, "team", *s.Matchers[0].Name)
+		require.Empty(t, repo.items)
+	})
+
+	t.Run("active window annotates its dashboards and the dashboards of matching rules once", func(t *testing.T) {
+		window := newWindow(now.Add(-time.Minute))
+		r, am, repo := newTestReconciler(now, rules, window)
+
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+
+		require.Len(t, am.silences, 1)
+		require.Len(t, repo.items, 2)
+		require.ElementsMatch(t, []string{"ops", dbDashboard}, keys(window.Annotations))
+		for _, item := range repo.items {
+			require.Equal(t, []string{models.MaintenanceAnnotationTag, "maintenance_window:upgrade"}, item.Tags)
+			require.Equal(t, window.StartsAt.UnixMilli(), item.Epoch)
+			require.Equal(t, window.EndsAt.UnixMilli(), item.EpochEnd)
+		}
+	})
+
+	t.Run("updated window updates its silence and annotations", func(t *testing.T) {
+		window := newWindow(now.Add(-time.Minute))
+		r, am, repo := newTestReconciler(now, rules, window)
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+
+		window.EndsAt = now.Add(2 * time.Hour)
+		window.DashboardUIDs = nil
+		window.Updated = now
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+
+		require.Len(t, am.silences, 1)
+		require.True(t, time.Time(*am.silences[0].EndsAt).Equal(window.EndsAt))
+		require.Len(t, repo.items, 1)
+		require.Equal(t, []string{dbDashboard}, keys(window.Annotations))
+		require.Equal(t, window.EndsAt.UnixMilli(), repo.items[window.Annotations[dbDashboard]].EpochEnd)
+	})
+
+	t.Run("silence of deleted window is expired", func(t *testing.T) {
+		r, am, _ := newTestReconciler(now, rules)
+		id := "silence-1"
+		comment := silenceCommentPrefix + "deleted: Deleted"
+		createdBy := models.MaintenanceWindowSilenceCreatedBy
+		am.silences = append(am.silences, &apimodels.GettableSilence{ID: &id, Silence: amv2.Silence{Comment: &comment, CreatedBy: &createdBy}})
+
+		require.NoError(t, r.ReconcileOrg(context.Background(), 1))
+
+		require.Equal(t, []string{id}, am.expired)
+	})
+}
+
+func newTestReconciler(now time.Time, rules models.RulesGroup, windows ...*models.MaintenanceWindow) (*Reconciler, *fakeAlertmanager, *fakeAnnotations) {
+	clk := clock.NewMock()
+	clk.Set(now)
+	am := &fakeAlertmanager{now: now}
+	repo := &fakeAnnotations{items: map[int64]*annotations.Item{}}
+	r := NewReconciler(&fakeWindowStore{windows: windows}, &fakeRuleStore{rules: rules}, nil, &fakeAlertmanagers{am: am},
+		&fakeDashboards{}, repo, clk, log.NewNopLogger())
+	return r, am, repo
+}
+
+func keys(m map[string]int64) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+type fakeWindowStore struct {
+	windows []*models.MaintenanceWindow
+}
+
+func (f *fakeWindowStore) GetMaintenanceWindows(_ context.Context, _ int64) ([]*models.MaintenanceWindow, error) {
+	return f.windows, nil
+}
+
+func (f *fakeWindowStore) SaveMaintenanceWindowAnnotations(_ context.Context, _ *models.MaintenanceWindow) error {
+	return nil
+}
+
+type fakeRuleStore struct {
+	rules models.RulesGroup
+}
+
+func (f *fakeRuleStore) ListAlertRules(_ context.Context, _ *models.ListAlertRulesQuery) (models.RulesGroup, error) {
+	return f.rules, nil
+}
+
+type fakeAlertmanagers struct {
+	am notifier.Alertmanager
+}
+
+func (f *fakeAlertmanagers) AlertmanagerFor(_ int64) (notifier.Alertmanager, error) {
+	return f.am, nil
+}
+
+// fakeAlertmanager implements the silences of an Alertmanager. Updating a silence replaces it in place.
+type fakeAlertmanager struct {
+	notifier.Alertmanager
+	now      time.Time
+	silences apimodels.GettableSilences
+	expired  []string
+}
+
+func (f *fakeAlertmanager) ListSilences(_ context.Context, _ []string) (apimodels.GettableSilences, error) {
+	return f.silences, nil
+}
+
+func (f *fakeAlertmanager) CreateSilence(_ context.Context, ps *apimodels.PostableSilence) (string, error) {
+	s := &apimodels.GettableSilence{Silence: ps.Silence}
+	// The Alertmanager starts silences created in the past at their creation.
+	if time.Time(*s.StartsAt).Before(f.now) {
+		startsAt := strfmt.DateTime(f.now)
+		s.StartsAt = &startsAt
+	}
+	if ps.ID != "" {
+		for i, existing := range f.silences {
+			if *existing.ID == ps.ID {
+				s.ID = existing.ID
+				f.silences[i] = s
+				return ps.ID, nil
+			}
+		}
+	}
+	id := "silence-" + time.Time(*s.EndsAt).String()
+	s.ID = &id
+	f.silences = append(f.silences, s)
+	return id, nil
+}
+
+func (f *fakeAlertmanager) DeleteSilence(_ context.Context, id string) error {
+	f.expired = append(f.expired, id)
+	return nil
+}
+
+type fakeDashboards struct{}
+
+func (f *fakeDashboards) GetDashboard(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	return &dashboards.Dashboard{ID: int64(len(query.UID)), UID: query.UID, OrgID: query.OrgID}, nil
+}
+
+type fakeAnnotations struct {
+	items  map[int64]*annotations.Item
+	lastID int64
+}
+
+func (f *fakeAnnotations) Save(_ context.Context, item *annotations.Item) error {
+	f.lastID++
+	item.ID = f.lastID
+	f.items[item.ID] = item
+	return nil
+}
+
+func (f *fakeAnnotations) Update(_ context.Context, item *annotations.Item) error {
+	f.items[item.ID] = item
+	return nil
+}
+
+func (f *fakeAnnotations) Delete(_ context.Context, params *annotations.DeleteParams) error {
+	delete(f.items, params.ID)
+	return nil
+}
@@ -14,6 +14,8 @@ type Historian struct {
 	WritesFailed      *prometheus.CounterVec
 	WriteDuration     *instrument.HistogramCollector
 	BytesWritten      prometheus.Counter
+	BufferBytes       *prometheus.GaugeVec
+	BufferDropped     prometheus.Counter
 }
 
 func NewHistorianMetrics(r prometheus.Registerer, subsystem string) *Historian {
@@ -61,5 +63,17 @@ func NewHistorianMetrics(r prometheus.Registerer, subsystem string) *Historian {
 			Name:      "state_history_writes_bytes_total",
 			Help:      "The total number of bytes sent within a batch to the state history store. Only valid when using the Loki store.",
 		}),
+		BufferBytes: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_buffer_bytes",
+			Help:      "The size of the state history waiting to be written to the state history store. Only valid when using the Loki store with the write buffer.",
+		}, []string{"storage"}),
+		BufferDropped: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_buffer_dropped_batches_total",
+			Help:      "The total number of state history batches dropped because the write buffer was full.",
+		}),
 	}
 }
@@ -661,6 +661,17 @@ func RuleKeyFromContext(ctx context.Context) (AlertRuleKey, bool) {
 	return key, ok
 }
 
+type ruleGroupKeyContextKey struct{}
+
+func WithRuleGroupKey(ctx context.Context, groupKey AlertRuleGroupKey) context.Context {
+	return context.WithValue(ctx, ruleGroupKeyContextKey{}, groupKey)
+}
+
+func RuleGroupKeyFromContext(ctx context.Context) (AlertRuleGroupKey, bool) {
+	key, ok := ctx.Value(ruleGroupKeyContextKey{}).(AlertRuleGroupKey)
+	return key, ok
+}
+
 // GroupByAlertRuleGroupKey groups all rules by AlertRuleGroupKey. Returns map of RulesGroup sorted by AlertRule.RuleGroupIndex
 func GroupByAlertRuleGroupKey(rules []*AlertRule) map[AlertRuleGroupKey]RulesGroup {
 	result := make(map[AlertRuleGroupKey]RulesGroup)
@@ -1,11 +1,27 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
 
+// HistoryGroupBy is a dimension state history can be aggregated by.
+type HistoryGroupBy string
+
+const (
+	// HistoryGroupByRule groups state transitions by alert rule UID.
+	HistoryGroupByRule HistoryGroupBy = "rule"
+	// HistoryGroupByLabels groups state transitions by the label set of the alert instance.
+	HistoryGroupByLabels HistoryGroupBy = "labels"
+	// HistoryGroupByState groups state transitions by the state that was transitioned to.
+	HistoryGroupByState HistoryGroupBy = "state"
+)
+
+// ErrHistoryAggregationNotSupported is returned by state history backends that cannot aggregate state history.
+var ErrHistoryAggregationNotSupported = errors.New("the configured state history backend does not support aggregation")
+
 // HistoryQuery represents a query for alert state history.
 type HistoryQuery struct {
 	RuleUID      string
@@ -17,4 +33,22 @@ type HistoryQuery struct {
 	To           time.Time
 	Limit        int
 	SignedInUser identity.Requester
+	// GroupBy lists the dimensions the number of state transitions is aggregated by.
+	GroupBy []HistoryGroupBy
+	// Interval is the size of the time buckets state transitions are counted in.
+	Interval time.Duration
+}
+
+// IsAggregation returns true if the query asks for aggregated state history rather than the raw transitions.
+func (q HistoryQuery) IsAggregation() bool {
+	return len(q.GroupBy) > 0 || q.Interval > 0
+}
+
+// IsValidHistoryGroupBy returns true if g is a known aggregation dimension.
+func IsValidHistoryGroupBy(g HistoryGroupBy) bool {
+	switch g {
+	case HistoryGroupByRule, HistoryGroupByLabels, HistoryGroupByState:
+		return true
+	}
+	return false
 }
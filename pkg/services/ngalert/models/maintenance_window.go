@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+const (
+	// MaintenanceAnnotationTag is the tag of the region annotations written for maintenance windows.
+	MaintenanceAnnotationTag = "maintenance"
+	// MaintenanceWindowSilenceCreatedBy is the author of the silences created for maintenance windows.
+	MaintenanceWindowSilenceCreatedBy = "Grafana maintenance window"
+)
+
+var (
+	// ErrMaintenanceWindowNotFound is returned when the maintenance window does not exist.
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+)
+
+// MaintenanceWindow is a period of time during which the alert instances that match its matchers are silenced,
+// and that is shown as a region annotation on the affected dashboards.
+type MaintenanceWindow struct {
+	ID      int64
+	OrgID   int64
+	UID     string
+	Title   string
+	Comment string
+	// Matchers select the alert instances silenced during the window.
+	Matchers labels.Matchers
+	// DashboardUIDs are the dashboards annotated in addition to the dashboards of the matching alert rules.
+	DashboardUIDs []string
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Updated       time.Time
+	// Annotations are the IDs of the region annotations written for the window, by dashboard UID.
+	Annotations map[string]int64
+	// AnnotationsUpdated is the Updated time of the version of the window the annotations were written for.
+	AnnotationsUpdated time.Time
+}
+
+// IsActive returns true if t is within the window.
+func (w *MaintenanceWindow) IsActive(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// HasEnded returns true if the window ended before or at t.
+func (w *MaintenanceWindow) HasEnded(t time.Time) bool {
+	return !t.Before(w.EndsAt)
+}
+
+// Matches returns true if the labels match all the matchers of the window.
+func (w *MaintenanceWindow) Matches(lbs map[string]string) bool {
+	for _, m := range w.Matchers {
+		if !m.Matches(lbs[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnnotationTag returns the tag that identifies the annotations written for the window.
+func (w *MaintenanceWindow) AnnotationTag() string {
+	return "maintenance_window:" + w.UID
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliverySuccess NotificationDeliveryStatus = "success"
+	NotificationDeliveryFailed  NotificationDeliveryStatus = "failed"
+)
+
+// NotificationDelivery is a single attempt to send a notification to an integration of a contact point.
+type NotificationDelivery struct {
+	ID    int64
+	OrgID int64
+	// Receiver is the name of the contact point.
+	Receiver         string
+	IntegrationName  string
+	IntegrationType  string
+	IntegrationIndex int
+	// GroupKey identifies the alert group the notification was sent for.
+	GroupKey string
+	// RuleUIDs are the UIDs of the alert rules of the alerts in the notification.
+	RuleUIDs []string
+	Alerts   int
+	Status   NotificationDeliveryStatus
+	Error    string
+	// Retry is the number of failed attempts that preceded this one for the same alert group and integration.
+	Retry    int
+	Duration time.Duration
+	SentAt   time.Time
+}
+
+// NotificationDeliveryQuery filters notification delivery attempts. Results are returned newest first.
+type NotificationDeliveryQuery struct {
+	OrgID    int64
+	RuleUID  string
+	Receiver string
+	Status   NotificationDeliveryStatus
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
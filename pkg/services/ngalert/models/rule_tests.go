@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertRuleTest is a unit test of the alert rules of a rule group. The rules are evaluated against the input series
+// instead of the data returned by their data sources, and the states of their alert instances are compared with the
+// expected states.
+type AlertRuleTest struct {
+	ID           int64
+	OrgID        int64
+	UID          string
+	NamespaceUID string
+	RuleGroup    string
+	Title        string
+	// Interval is the time between two evaluations of the rules, and between two points of the input series.
+	// The interval of the rule group is used when it is zero.
+	Interval     time.Duration
+	InputSeries  []AlertRuleTestSeries
+	Expectations []AlertRuleTestExpectation
+	Updated      time.Time
+}
+
+// GetGroupKey returns the key of the rule group the test belongs to.
+func (t *AlertRuleTest) GetGroupKey() AlertRuleGroupKey {
+	return AlertRuleGroupKey{OrgID: t.OrgID, NamespaceUID: t.NamespaceUID, RuleGroup: t.RuleGroup}
+}
+
+// AlertRuleTestSeries is a series returned to the data source queries of the rules instead of the data source
+// response.
+type AlertRuleTestSeries struct {
+	// RuleUID restricts the series to the queries of a rule. The series is returned to the queries of all the rules
+	// of the group when it is empty.
+	RuleUID string
+	// RefID is the reference ID of the query the series is returned to.
+	RefID  string
+	Labels map[string]string
+	// Values are the points of the series, one per interval, starting at the time of the first evaluation.
+	// A nil value is a missing point.
+	Values []*float64
+}
+
+// AlertRuleTestExpectation is the expected state of the alert instances of a rule at the time of an evaluation.
+type AlertRuleTestExpectation struct {
+	RuleUID string
+	// At is the time of the evaluation, from the first evaluation. It must be a multiple of the interval.
+	At time.Duration
+	// Labels select the alert instances of the rule that have these labels. At least one instance must match.
+	Labels map[string]string
+	// State is the expected state of the instances: Normal, Pending, Alerting, NoData or Error.
+	State string
+}
@@ -27,13 +27,16 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	remoteeval "github.com/grafana/grafana/pkg/services/ngalert/eval/remote"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/maintenance"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/migration"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/remote"
+	"github.com/grafana/grafana/pkg/services/ngalert/ruletest"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
@@ -43,6 +46,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/screenshot"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -65,6 +69,7 @@ func ProvideService(
 	ac accesscontrol.AccessControl,
 	dashboardService dashboards.DashboardService,
 	renderService rendering.Service,
+	cardService screenshot.CardService,
 	bus bus.Bus,
 	accesscontrolService accesscontrol.Service,
 	annotationsRepo annotations.Repository,
@@ -95,6 +100,7 @@ func ProvideService(
 		accesscontrol:        ac,
 		dashboardService:     dashboardService,
 		renderService:        renderService,
+		cardService:          cardService,
 		bus:                  bus,
 		accesscontrolService: accesscontrolService,
 		annotationsRepo:      annotationsRepo,
@@ -139,9 +145,12 @@ type AlertNG struct {
 	NotificationService notifications.Service
 	Log                 log.Logger
 	renderService       rendering.Service
+	cardService         screenshot.CardService
 	ImageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           Historian
+	maintenance         *maintenance.Reconciler
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	api                 *api.API
@@ -189,7 +198,7 @@ func (ng *AlertNG) init() error {
 		return err
 	}
 
-	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.store, ng.dashboardService, ng.renderService, ng.Metrics.Registerer)
+	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.store, ng.dashboardService, ng.renderService, ng.cardService, ng.Metrics.Registerer)
 	if err != nil {
 		return err
 	}
@@ -219,6 +228,16 @@ func (ng *AlertNG) init() error {
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
+	// The scheduled rules are evaluated by the remote runners if any is configured.
+	// The API keeps evaluating the rules locally, for example to test them.
+	schedulerEvalFactory := evalFactory
+	if ng.Cfg.UnifiedAlerting.RemoteEvaluation.IsEnabled() {
+		pool, err := remoteeval.NewPool(ng.Cfg.UnifiedAlerting, evalFactory, clk, log.New("ngalert.eval.remote"))
+		if err != nil {
+			return fmt.Errorf("failed to initialize the alert rule evaluation runners: %w", err)
+		}
+		schedulerEvalFactory = pool
+	}
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -226,7 +245,7 @@ func (ng *AlertNG) init() error {
 		MinRuleInterval:      ng.Cfg.UnifiedAlerting.MinInterval,
 		DisableGrafanaFolder: ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
 		AppURL:               appUrl,
-		EvaluatorFactory:     evalFactory,
+		EvaluatorFactory:     schedulerEvalFactory,
 		RuleStore:            ng.store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
@@ -263,6 +282,7 @@ func (ng *AlertNG) init() error {
 	}
 
 	ng.stateManager = stateManager
+	ng.historian = history
 	ng.schedule = scheduler
 
 	// Provisioning
@@ -270,6 +290,7 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(ng.store, ng.SecretsService, ng.store, ng.store, ng.Log, ng.accesscontrol)
 	templateService := provisioning.NewTemplateService(ng.store, ng.store, ng.store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(ng.store, ng.store, ng.store, ng.Log)
+	maintenanceWindowService := provisioning.NewMaintenanceWindowService(ng.store, ng.store, ng.store, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.dashboardService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
@@ -293,17 +314,23 @@ func (ng *AlertNG) init() error {
 		ContactPointService:  contactPointService,
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
+		MaintenanceWindows:   maintenanceWindowService,
 		AlertRules:           alertRuleService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		Deliveries:           ng.store,
+		RuleTests:            ng.store,
+		RuleTestRunner:       ruletest.NewRunner(ng.Cfg.UnifiedAlerting, ng.ExpressionService, appUrl, ng.tracer),
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
 	ng.api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
+	ng.maintenance = maintenance.NewReconciler(ng.store, ng.store, ng.store, ng.MultiOrgAlertmanager, ng.dashboardService, ng.annotationsRepo, clk, log.New("ngalert.maintenance"))
+
 	if err := RegisterQuotas(ng.Cfg, ng.QuotaService, ng.store); err != nil {
 		return err
 	}
@@ -353,6 +380,14 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.maintenance.Run(subCtx)
+	})
+	if runner, ok := ng.historian.(historian.Runner); ok {
+		children.Go(func() error {
+			return runner.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -433,7 +468,10 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 			return nil, fmt.Errorf("invalid remote loki configuration: %w", err)
 		}
 		req := historian.NewRequester()
-		backend := historian.NewRemoteLokiBackend(lcfg, req, met)
+		backend, err := historian.NewRemoteLokiBackend(lcfg, req, met)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote loki configuration: %w", err)
+		}
 
 		testConnCtx, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
 		defer cancelFunc()
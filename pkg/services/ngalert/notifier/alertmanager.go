@@ -42,6 +42,7 @@ var silenceMaintenanceInterval = 15 * time.Minute
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	store.NotificationDeliveryStore
 }
 
 type alertmanager struct {
@@ -56,6 +57,11 @@ type alertmanager struct {
 
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
+
+	// deliveries records notification delivery attempts. It is nil if the delivery log is disabled.
+	deliveries *deliveryRecorder
+	// digest batches the email notifications of low severity alerts. It is nil if digest emails are disabled.
+	digest *emailDigest
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -144,6 +150,13 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		fileStore:           fileStore,
 		logger:              l,
 	}
+	if cfg.UnifiedAlerting.DeliveryLog.Enabled {
+		am.deliveries = newDeliveryRecorder(orgID, store, l.New("component", "delivery-log"))
+	}
+	if cfg.UnifiedAlerting.EmailDigest.Enabled {
+		am.digest = newEmailDigest(orgID, cfg, ns, l.New("component", "email-digest"))
+		go am.digest.run()
+	}
 
 	return am, nil
 }
@@ -157,6 +170,9 @@ func (am *alertmanager) Ready() bool {
 
 func (am *alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+	if am.digest != nil {
+		am.digest.stop()
+	}
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -358,7 +374,7 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	s := &sender{am.NotificationService}
+	s := &sender{ns: am.NotificationService, orgID: am.orgID}
 	img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
 	integrations, err := alertingNotify.BuildReceiverIntegrations(
 		receiverCfg,
@@ -377,6 +393,12 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+	if am.deliveries != nil {
+		integrations = am.deliveries.wrap(receiver, integrations)
+	}
+	if am.digest != nil {
+		integrations = am.digest.wrap(&receiverCfg, integrations)
+	}
 	return integrations, nil
 }
 
@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// deliverySaveTimeout bounds how long saving a delivery attempt can delay the notification pipeline.
+	deliverySaveTimeout = 5 * time.Second
+	// maxTrackedFailures bounds the number of alert groups whose consecutive failures are counted.
+	maxTrackedFailures = 10000
+)
+
+// DeleteOldDeliveriesService is a service to delete notification delivery attempts older than the retention.
+type DeleteOldDeliveriesService struct {
+	store     store.NotificationDeliveryAdminStore
+	retention time.Duration
+}
+
+func (s *DeleteOldDeliveriesService) DeleteOld(ctx context.Context) (int64, error) {
+	return s.store.DeleteNotificationDeliveriesOlderThan(ctx, time.Now().Add(-s.retention))
+}
+
+func ProvideDeleteOldDeliveriesService(cfg *setting.Cfg, store *store.DBstore) *DeleteOldDeliveriesService {
+	return &DeleteOldDeliveriesService{store: store, retention: cfg.UnifiedAlerting.DeliveryLog.Retention}
+}
+
+// deliveryRecorder records every attempt to send a notification to an integration of a contact point.
+type deliveryRecorder struct {
+	orgID  int64
+	store  store.NotificationDeliveryStore
+	logger log.Logger
+
+	mtx sync.Mutex
+	// failures counts the consecutive failed attempts per alert group and integration.
+	// The notification pipeline retries failed attempts, so this is the retry count of the next attempt.
+	failures map[string]int
+}
+
+func newDeliveryRecorder(orgID int64, s store.NotificationDeliveryStore, logger log.Logger) *deliveryRecorder {
+	return &deliveryRecorder{
+		orgID:    orgID,
+		store:    s,
+		logger:   logger,
+		failures: make(map[string]int),
+	}
+}
+
+// wrap returns integrations that record each notification attempt of the given integrations.
+func (r *deliveryRecorder) wrap(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	// Integrations are indexed per type, in the order they are configured in the receiver.
+	configs := make(map[string][]*alertingNotify.GrafanaIntegrationConfig)
+	for _, cfg := range receiver.Integrations {
+		configs[cfg.Type] = append(configs[cfg.Type], cfg)
+	}
+
+	wrapped := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		name := receiver.Name
+		if cfgs := configs[integration.Name()]; integration.Index() < len(cfgs) {
+			name = cfgs[integration.Index()].Name
+		}
+		n := &recordingNotifier{
+			recorder:    r,
+			integration: integration,
+			receiver:    receiver.Name,
+			name:        name,
+		}
+		wrapped = append(wrapped, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), name))
+	}
+	return wrapped
+}
+
+func (r *deliveryRecorder) record(ctx context.Context, n *recordingNotifier, alerts []*types.Alert, start time.Time, duration time.Duration, notifyErr error) {
+	groupKey, _ := notify.GroupKey(ctx)
+
+	key := groupKey + "/" + n.receiver + "/" + n.integration.String()
+	r.mtx.Lock()
+	retry := r.failures[key]
+	if notifyErr != nil {
+		if len(r.failures) >= maxTrackedFailures {
+			r.failures = make(map[string]int)
+		}
+		r.failures[key] = retry + 1
+	} else {
+		delete(r.failures, key)
+	}
+	r.mtx.Unlock()
+
+	delivery := &models.NotificationDelivery{
+		OrgID:            r.orgID,
+		Receiver:         n.receiver,
+		IntegrationName:  n.name,
+		IntegrationType:  n.integration.Name(),
+		IntegrationIndex: n.integration.Index(),
+		GroupKey:         groupKey,
+		RuleUIDs:         ruleUIDs(alerts),
+		Alerts:           len(alerts),
+		Status:           models.NotificationDeliverySuccess,
+		Retry:            retry,
+		Duration:         duration,
+		SentAt:           start,
+	}
+	if notifyErr != nil {
+		delivery.Status = models.NotificationDeliveryFailed
+		delivery.Error = notifyErr.Error()
+	}
+
+	// The notification context can already be cancelled when the attempt timed out, which is exactly the attempt we want to keep.
+	saveCtx, cancel := context.WithTimeout(context.Background(), deliverySaveTimeout)
+	defer cancel()
+	if err := r.store.SaveNotificationDelivery(saveCtx, delivery); err != nil {
+		r.logger.Warn("Failed to save notification delivery", "receiver", n.receiver, "integration", n.integration.String(), "error", err)
+	}
+}
+
+// ruleUIDs returns the sorted, unique UIDs of the alert rules of the alerts.
+func ruleUIDs(alerts []*types.Alert) []string {
+	seen := make(map[string]struct{}, len(alerts))
+	uids := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		uid := string(a.Labels[alertingModels.RuleUIDLabel])
+		if uid == "" {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+// recordingNotifier is a notify.Notifier that records the outcome of every attempt of the integration it wraps.
+type recordingNotifier struct {
+	recorder    *deliveryRecorder
+	integration *alertingNotify.Integration
+	receiver    string
+	name        string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	start := time.Now()
+	retry, err := n.integration.Notify(ctx, alerts...)
+	n.recorder.record(ctx, n, alerts, start, time.Since(start), err)
+	return retry, err
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeDeliveryStore struct {
+	deliveries []*models.NotificationDelivery
+}
+
+func (f *fakeDeliveryStore) SaveNotificationDelivery(_ context.Context, delivery *models.NotificationDelivery) error {
+	f.deliveries = append(f.deliveries, delivery)
+	return nil
+}
+
+func (f *fakeDeliveryStore) GetNotificationDeliveries(_ context.Context, _ *models.NotificationDeliveryQuery) ([]*models.NotificationDelivery, error) {
+	return f.deliveries, nil
+}
+
+type fakeNotifier struct {
+	errs []error
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, _ ...*types.Alert) (bool, error) {
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err != nil, err
+}
+
+func (f *fakeNotifier) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryRecorder(t *testing.T) {
+	s := &fakeDeliveryStore{}
+	r := newDeliveryRecorder(1, s, log.NewNopLogger())
+
+	receiver := &alertingNotify.APIReceiver{
+		ConfigReceiver: alertingNotify.ConfigReceiver{Name: "ops"},
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{UID: "email-uid", Name: "ops-email", Type: "email"},
+				{UID: "slack-1", Name: "ops-slack-1", Type: "slack"},
+				{UID: "slack-2", Name: "ops-slack-2", Type: "slack"},
+			},
+		},
+	}
+	failing := &fakeNotifier{errs: []error{errors.New("timeout"), errors.New("timeout"), nil}}
+	integrations := r.wrap(receiver, []*alertingNotify.Integration{
+		alertingNotify.NewIntegration(&fakeNotifier{}, &fakeNotifier{}, "email", 0, "ops-email"),
+		alertingNotify.NewIntegration(failing, failing, "slack", 1, "ops-slack-2"),
+	})
+	require.Len(t, integrations, 2)
+	require.Equal(t, "slack", integrations[1].Name())
+	require.Equal(t, 1, integrations[1].Index())
+
+	ctx := notify.WithGroupKey(context.Background(), "{}:{alertname=\"test\"}")
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"__alert_rule_uid__": "rule-b"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"__alert_rule_uid__": "rule-a"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"__alert_rule_uid__": "rule-b"}}},
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = integrations[1].Notify(ctx, alerts...)
+	}
+	require.Len(t, s.deliveries, 3)
+
+	d := s.deliveries[0]
+	require.Equal(t, int64(1), d.OrgID)
+	require.Equal(t, "ops", d.Receiver)
+	require.Equal(t, "ops-slack-2", d.IntegrationName)
+	require.Equal(t, "slack", d.IntegrationType)
+	require.Equal(t, 1, d.IntegrationIndex)
+	require.Equal(t, "{}:{alertname=\"test\"}", d.GroupKey)
+	require.Equal(t, []string{"rule-a", "rule-b"}, d.RuleUIDs)
+	require.Equal(t, 3, d.Alerts)
+	require.Equal(t, models.NotificationDeliveryFailed, d.Status)
+	require.Equal(t, "timeout", d.Error)
+
+	// Failed attempts are retried, the retry count resets once an attempt succeeds.
+	require.Equal(t, []int{0, 1, 2}, []int{s.deliveries[0].Retry, s.deliveries[1].Retry, s.deliveries[2].Retry})
+	require.Equal(t, models.NotificationDeliverySuccess, s.deliveries[2].Status)
+	require.Empty(t, s.deliveries[2].Error)
+	require.Empty(t, r.failures)
+}
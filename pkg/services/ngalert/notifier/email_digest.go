@@ -0,0 +1,276 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	emailDigestTemplate = "ng_alert_digest"
+	// digestSendTimeout bounds how long sending a digest email can take.
+	digestSendTimeout = 30 * time.Second
+)
+
+// emailDigest batches the email notifications of low severity alerts into a periodic digest email per
+// contact point. The notifications of the other alerts are sent immediately by the email integration.
+type emailDigest struct {
+	orgID  int64
+	cfg    setting.UnifiedAlertingEmailDigestSettings
+	appURL string
+	ns     notifications.Service
+	logger log.Logger
+
+	mtx sync.Mutex
+	// batches are the pending notifications per email integration of a contact point.
+	batches map[string]*digestBatch
+
+	stopOnce sync.Once
+	stopc    chan struct{}
+	donec    chan struct{}
+}
+
+// digestBatch holds the latest state of the alerts notified to an email integration since the last digest.
+type digestBatch struct {
+	receiver    string
+	addresses   []string
+	singleEmail bool
+	alerts      map[model.Fingerprint]*types.Alert
+}
+
+func newEmailDigest(orgID int64, cfg *setting.Cfg, ns notifications.Service, logger log.Logger) *emailDigest {
+	return &emailDigest{
+		orgID:   orgID,
+		cfg:     cfg.UnifiedAlerting.EmailDigest,
+		appURL:  cfg.AppURL,
+		ns:      ns,
+		logger:  logger,
+		batches: make(map[string]*digestBatch),
+		stopc:   make(chan struct{}),
+		donec:   make(chan struct{}),
+	}
+}
+
+// run sends the digest emails at the end of every window until stop is called.
+func (d *emailDigest) run() {
+	defer close(d.donec)
+	ticker := time.NewTicker(d.cfg.Window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(time.Now())
+		case <-d.stopc:
+			return
+		}
+	}
+}
+
+// stop stops sending digest emails, and sends the pending notifications so that they are not lost.
+func (d *emailDigest) stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopc)
+		<-d.donec
+		d.flush(time.Now())
+	})
+}
+
+// wrap returns integrations that batch the notifications of the low severity alerts of the given email integrations.
+// The other integrations are returned unchanged.
+func (d *emailDigest) wrap(receiver *alertingNotify.GrafanaReceiverConfig, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	wrapped := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		if integration.Name() != "email" || integration.Index() >= len(receiver.EmailConfigs) {
+			wrapped = append(wrapped, integration)
+			continue
+		}
+		cfg := receiver.EmailConfigs[integration.Index()]
+		n := &digestNotifier{
+			digest:      d,
+			integration: integration,
+			key:         receiver.Name + "/" + integration.String(),
+			receiver:    receiver.Name,
+			addresses:   cfg.Settings.Addresses,
+			singleEmail: cfg.Settings.SingleEmail,
+		}
+		wrapped = append(wrapped, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), cfg.Name))
+	}
+	return wrapped
+}
+
+// add batches the alerts until the next digest. The latest state of each alert is kept.
+func (d *emailDigest) add(n *digestNotifier, alerts []*types.Alert) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	batch, ok := d.batches[n.key]
+	if !ok {
+		batch = &digestBatch{alerts: make(map[model.Fingerprint]*types.Alert)}
+		d.batches[n.key] = batch
+	}
+	// The addresses can change when the configuration is applied again.
+	batch.receiver = n.receiver
+	batch.addresses = n.addresses
+	batch.singleEmail = n.singleEmail
+	for _, a := range alerts {
+		cp := *a
+		batch.alerts[a.Fingerprint()] = &cp
+	}
+}
+
+// flush sends a digest email for each batch of notifications.
+func (d *emailDigest) flush(now time.Time) {
+	d.mtx.Lock()
+	batches := d.batches
+	d.batches = make(map[string]*digestBatch)
+	d.mtx.Unlock()
+
+	for _, batch := range batches {
+		if len(batch.alerts) == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+		if err := d.ns.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+			SendEmailCommand: notifications.SendEmailCommand{
+				To:          batch.addresses,
+				SingleEmail: batch.singleEmail,
+				Template:    emailDigestTemplate,
+				Data:        d.data(batch, now),
+				OrgID:       d.orgID,
+			},
+		}); err != nil {
+			d.logger.Error("Failed to send digest email", "receiver", batch.receiver, "alerts", len(batch.alerts), "error", err)
+		}
+		cancel()
+	}
+}
+
+type digestGroup struct {
+	Title  string
+	Alerts []digestAlert
+}
+
+type digestAlert struct {
+	Name     string
+	Status   string
+	Severity string
+	Summary  string
+	URL      string
+}
+
+// data returns the template data of the digest email of a batch, with the alerts grouped by rule or by folder.
+func (d *emailDigest) data(batch *digestBatch, now time.Time) map[string]any {
+	groups := make(map[string]*digestGroup)
+	firing, resolved := 0, 0
+	for _, a := range batch.alerts {
+		title := string(a.Labels[model.AlertNameLabel])
+		if d.cfg.GroupBy == setting.EmailDigestGroupByFolder {
+			title = string(a.Labels[alertingModels.FolderTitleLabel])
+		}
+		if title == "" {
+			title = "Other"
+		}
+		group, ok := groups[title]
+		if !ok {
+			group = &digestGroup{Title: title}
+			groups[title] = group
+		}
+
+		status := "Firing"
+		if a.ResolvedAt(now) {
+			status = "Resolved"
+			resolved++
+		} else {
+			firing++
+		}
+		group.Alerts = append(group.Alerts, digestAlert{
+			Name:     digestAlertName(a),
+			Status:   status,
+			Severity: string(a.Labels[model.LabelName(d.cfg.SeverityLabel)]),
+			Summary:  string(a.Annotations["summary"]),
+			URL:      a.GeneratorURL,
+		})
+	}
+
+	sorted := make([]digestGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Slice(group.Alerts, func(i, j int) bool {
+			if group.Alerts[i].Name != group.Alerts[j].Name {
+				return group.Alerts[i].Name < group.Alerts[j].Name
+			}
+			return group.Alerts[i].Status < group.Alerts[j].Status
+		})
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Title < sorted[j].Title
+	})
+
+	return map[string]any{
+		"Receiver":     batch.receiver,
+		"Count":        len(batch.alerts),
+		"Firing":       firing,
+		"Resolved":     resolved,
+		"Window":       model.Duration(d.cfg.Window).String(),
+		"Groups":       sorted,
+		"AlertPageUrl": strings.TrimSuffix(d.appURL, "/") + "/alerting/list",
+	}
+}
+
+// digestAlertName returns the name of the alert rule of an alert, followed by the labels that identify the alert
+// among the alerts of the rule.
+func digestAlertName(a *types.Alert) string {
+	name := string(a.Labels[model.AlertNameLabel])
+	var labels []string
+	for label, value := range a.Labels {
+		if label == model.AlertNameLabel || strings.HasPrefix(string(label), "__") || label == alertingModels.FolderTitleLabel {
+			continue
+		}
+		labels = append(labels, string(label)+"="+string(value))
+	}
+	if len(labels) == 0 {
+		return name
+	}
+	sort.Strings(labels)
+	return name + " {" + strings.Join(labels, ", ") + "}"
+}
+
+// digestNotifier is a notify.Notifier that batches the notifications of the low severity alerts into digests, and
+// sends the notifications of the other alerts immediately with the email integration it wraps.
+type digestNotifier struct {
+	digest      *emailDigest
+	integration *alertingNotify.Integration
+	key         string
+	receiver    string
+	addresses   []string
+	singleEmail bool
+}
+
+func (n *digestNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	immediate := make([]*types.Alert, 0, len(alerts))
+	batched := make([]*types.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if n.digest.cfg.IsBatched(string(a.Labels[model.LabelName(n.digest.cfg.SeverityLabel)])) {
+			batched = append(batched, a)
+		} else {
+			immediate = append(immediate, a)
+		}
+	}
+	if len(batched) > 0 {
+		n.digest.add(n, batched)
+	}
+	if len(immediate) == 0 {
+		return false, nil
+	}
+	return n.integration.Notify(ctx, immediate...)
+}
@@ -0,0 +1,209 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingEmail "github.com/grafana/alerting/receivers/email"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type countingNotifier struct {
+	alerts [][]*types.Alert
+}
+
+func (f *countingNotifier) Notify(_ context.Context, alerts ...*types.Alert) (bool, error) {
+	f.alerts = append(f.alerts, alerts)
+	return false, nil
+}
+
+func (f *countingNotifier) SendResolved() bool {
+	return true
+}
+
+func newDigestForTests(t *testing.T, groupBy string, ns notifications.Service) *emailDigest {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost/grafana/"
+	cfg.UnifiedAlerting.EmailDigest = setting.UnifiedAlertingEmailDigestSettings{
+		Enabled:       true,
+		Window:        time.Hour,
+		GroupBy:       groupBy,
+		SeverityLabel: "severity",
+		Severities:    map[string]struct{}{"info": {}, "warning": {}},
+	}
+	return newEmailDigest(1, cfg, ns, log.NewNopLogger())
+}
+
+func digestReceiverForTests() *alertingNotify.GrafanaReceiverConfig {
+	return &alertingNotify.GrafanaReceiverConfig{
+		Name: "ops",
+		EmailConfigs: []*alertingNotify.NotifierConfig[alertingEmail.Config]{
+			{
+				Metadata: receivers.Metadata{Name: "ops-email"},
+				Settings: alertingEmail.Config{Addresses: []string{"ops@example.com", "oncall@example.com"}},
+			},
+		},
+	}
+}
+
+func TestEmailDigest(t *testing.T) {
+	ns := &notifications.NotificationServiceMock{}
+	var sent []*notifications.SendEmailCommandSync
+	ns.EmailHandlerSync = func(_ context.Context, cmd *notifications.SendEmailCommandSync) error {
+		sent = append(sent, cmd)
+		return nil
+	}
+	d := newDigestForTests(t, setting.EmailDigestGroupByRule, ns)
+
+	email := &countingNotifier{}
+	slack := &countingNotifier{}
+	integrations := d.wrap(digestReceiverForTests(), []*alertingNotify.Integration{
+		alertingNotify.NewIntegration(email, email, "email", 0, "ops-email"),
+		alertingNotify.NewIntegration(slack, slack, "slack", 0, "ops-slack"),
+	})
+	require.Len(t, integrations, 2)
+	require.Equal(t, "email", integrations[0].Name())
+
+	now := time.Now()
+	critical := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "DiskFull", "severity": "critical"}}}
+	noSeverity := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "Down"}}}
+	warning := &types.Alert{Alert: model.Alert{
+		Labels:       model.LabelSet{"alertname": "HighLatency", "severity": "Warning", "instance": "a", "__alert_rule_uid__": "rule-a"},
+		Annotations:  model.LabelSet{"summary": "Latency is high"},
+		GeneratorURL: "http://localhost/grafana/alerting/grafana/rule-a/view",
+	}}
+	info := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "Restarted", "severity": "info"}}}
+
+	retry, err := integrations[0].Notify(context.Background(), critical, warning, noSeverity)
+	require.NoError(t, err)
+	require.False(t, retry)
+	require.Len(t, email.alerts, 1)
+	require.Equal(t, []*types.Alert{critical, noSeverity}, email.alerts[0])
+
+	// The notifications with only low severity alerts are not sent.
+	_, err = integrations[0].Notify(context.Background(), info)
+	require.NoError(t, err)
+	require.Len(t, email.alerts, 1)
+
+	// The other integrations are not batched.
+	_, err = integrations[1].Notify(context.Background(), info)
+	require.NoError(t, err)
+	require.Len(t, slack.alerts, 1)
+
+	// The latest state of the alerts is sent.
+	resolvedWarning := *warning
+	resolvedWarning.EndsAt = now.Add(-time.Minute)
+	_, err = integrations[0].Notify(context.Background(), &resolvedWarning)
+	require.NoError(t, err)
+
+	d.flush(now)
+	require.Len(t, sent, 1)
+	cmd := sent[0]
+	require.Equal(t, []string{"ops@example.com", "oncall@example.com"}, cmd.To)
+	require.Equal(t, emailDigestTemplate, cmd.Template)
+	require.Equal(t, int64(1), cmd.OrgID)
+	require.Equal(t, "ops", cmd.Data["Receiver"])
+	require.Equal(t, 2, cmd.Data["Count"])
+	require.Equal(t, 1, cmd.Data["Firing"])
+	require.Equal(t, 1, cmd.Data["Resolved"])
+	require.Equal(t, "1h", cmd.Data["Window"])
+	require.Equal(t, "http://localhost/grafana/alerting/list", cmd.Data["AlertPageUrl"])
+	require.Equal(t, []digestGroup{
+		{Title: "HighLatency", Alerts: []digestAlert{{
+			Name:     "HighLatency {instance=a, severity=Warning}",
+			Status:   "Resolved",
+			Severity: "Warning",
+			Summary:  "Latency is high",
+			URL:      "http://localhost/grafana/alerting/grafana/rule-a/view",
+		}}},
+		{Title: "Restarted", Alerts: []digestAlert{{Name: "Restarted {severity=info}", Status: "Firing", Severity: "info"}}},
+	}, cmd.Data["Groups"])
+
+	// The batches are emptied by each digest.
+	d.flush(now)
+	require.Len(t, sent, 1)
+
+	t.Run("groups the alerts by folder", func(t *testing.T) {
+		d := newDigestForTests(t, setting.EmailDigestGroupByFolder, ns)
+		d.add(&digestNotifier{key: "ops/email[0]", receiver: "ops"}, []*types.Alert{
+			{Alert: model.Alert{Labels: model.LabelSet{"alertname": "A", "grafana_folder": "Team"}}},
+			{Alert: model.Alert{Labels: model.LabelSet{"alertname": "B", "grafana_folder": "Team"}}},
+			{Alert: model.Alert{Labels: model.LabelSet{"alertname": "C"}}},
+		})
+		groups := d.data(d.batches["ops/email[0]"], now)["Groups"].([]digestGroup)
+		require.Len(t, groups, 2)
+		require.Equal(t, "Other", groups[0].Title)
+		require.Equal(t, "Team", groups[1].Title)
+		require.Len(t, groups[1].Alerts, 2)
+		require.Equal(t, "A", groups[1].Alerts[0].Name)
+	})
+
+	t.Run("sends the pending notifications when stopped", func(t *testing.T) {
+		sent = nil
+		d := newDigestForTests(t, setting.EmailDigestGroupByRule, ns)
+		go d.run()
+		d.add(&digestNotifier{key: "ops/email[0]", receiver: "ops"}, []*types.Alert{info})
+		d.stop()
+		d.stop()
+		require.Len(t, sent, 1)
+	})
+
+	t.Run("logs the digests that cannot be sent", func(t *testing.T) {
+		failing := &notifications.NotificationServiceMock{EmailHandlerSync: func(_ context.Context, _ *notifications.SendEmailCommandSync) error {
+			return errors.New("smtp unavailable")
+		}}
+		d := newDigestForTests(t, setting.EmailDigestGroupByRule, failing)
+		d.add(&digestNotifier{key: "ops/email[0]", receiver: "ops"}, []*types.Alert{info})
+		d.flush(now)
+		require.Empty(t, d.batches)
+	})
+}
+
+func TestEmailDigestTemplate(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.StaticRootPath = "../../../../public/"
+	cfg.BuildVersion = "4.0.0"
+	cfg.Smtp.Enabled = true
+	cfg.Smtp.TemplatesPatterns = []string{"emails/*.html", "emails/*.txt"}
+	cfg.Smtp.FromAddress = "from@address.com"
+	cfg.Smtp.FromName = "Grafana Admin"
+	cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
+	cfg.Smtp.Host = "localhost:1234"
+	mailer := notifications.NewFakeMailer()
+	ns, err := notifications.ProvideService(bus.ProvideBus(tracing.InitializeTracerForTest()), cfg, mailer, nil, nil, nil)
+	require.NoError(t, err)
+
+	d := newDigestForTests(t, setting.EmailDigestGroupByRule, ns)
+	d.add(&digestNotifier{key: "ops/email[0]", receiver: "ops", addresses: []string{"ops@example.com"}}, []*types.Alert{
+		{Alert: model.Alert{
+			Labels:       model.LabelSet{"alertname": "HighLatency", "severity": "warning"},
+			Annotations:  model.LabelSet{"summary": "Latency is high"},
+			GeneratorURL: "http://localhost/grafana/alerting/grafana/rule-a/view",
+		}},
+	})
+	d.flush(time.Now())
+
+	require.Len(t, mailer.Sent, 1)
+	msg := mailer.Sent[0]
+	require.Equal(t, "[DIGEST:1] ops", msg.Subject)
+	for _, body := range []string{msg.Body["text/html"], msg.Body["text/plain"]} {
+		require.Contains(t, body, "Alert digest for ops")
+		require.Contains(t, body, "1 firing and 0 resolved alerts were notified in the last 1h.")
+		require.Contains(t, body, "HighLatency {severity=warning}")
+		require.Contains(t, body, "Latency is high")
+		require.Contains(t, body, "http://localhost/grafana/alerting/list")
+	}
+}
@@ -0,0 +1,132 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrNoRoutingTree is returned when testing routing against a configuration without a root notification policy.
+var ErrNoRoutingTree = errors.New("the configuration does not have a root notification policy")
+
+// TestRouting resolves the notification policies, mute timings and contact points an alert with the given labels
+// would be routed to. No notification is sent. The saved configuration of the org is used unless the request
+// carries its own configuration.
+func (moa *MultiOrgAlertmanager) TestRouting(ctx context.Context, org int64, c definitions.TestRoutingConfigBodyParams) (definitions.TestRoutingResult, error) {
+	cfg := c.AlertmanagerConfig
+	if cfg == nil {
+		query := models.GetLatestAlertmanagerConfigurationQuery{OrgID: org}
+		amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, &query)
+		if err != nil {
+			return definitions.TestRoutingResult{}, fmt.Errorf("failed to get latest configuration: %w", err)
+		}
+		saved, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+		if err != nil {
+			return definitions.TestRoutingResult{}, fmt.Errorf("failed to unmarshal latest configuration: %w", err)
+		}
+		cfg = &saved.AlertmanagerConfig
+	}
+
+	at := time.Now()
+	if c.Time != nil {
+		at = time.Time(*c.Time)
+	}
+	return testRouting(cfg, c.Labels, c.Annotations, at)
+}
+
+func testRouting(cfg *definitions.PostableApiAlertingConfig, lbls, annotations model.LabelSet, at time.Time) (definitions.TestRoutingResult, error) {
+	if cfg.Route == nil {
+		return definitions.TestRoutingResult{}, ErrNoRoutingTree
+	}
+
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		intervals[mt.Name] = mt.TimeIntervals
+	}
+	receivers := make(map[string]*definitions.PostableApiReceiver, len(cfg.Receivers))
+	for _, r := range cfg.Receivers {
+		receivers[r.Name] = r
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	matched := root.Match(lbls)
+
+	result := definitions.TestRoutingResult{
+		Labels:      lbls,
+		Annotations: annotations,
+		Time:        at,
+		Routes:      make([]definitions.TestRoutingRouteResult, 0, len(matched)),
+	}
+	for _, route := range matched {
+		opts := route.RouteOpts
+		res := definitions.TestRoutingRouteResult{
+			Path:              routePath(root, route),
+			Receiver:          opts.Receiver,
+			GroupWait:         model.Duration(opts.GroupWait),
+			GroupInterval:     model.Duration(opts.GroupInterval),
+			RepeatInterval:    model.Duration(opts.RepeatInterval),
+			MuteTimeIntervals: opts.MuteTimeIntervals,
+			ContactPoint:      contactPoint(opts.Receiver, receivers),
+		}
+		if opts.GroupByAll {
+			res.GroupBy = []string{"..."}
+		} else {
+			for l := range opts.GroupBy {
+				res.GroupBy = append(res.GroupBy, string(l))
+			}
+			sort.Strings(res.GroupBy)
+		}
+		for _, name := range opts.MuteTimeIntervals {
+			for _, ti := range intervals[name] {
+				if ti.ContainsTime(at.UTC()) {
+					res.ActiveMuteTimeIntervals = append(res.ActiveMuteTimeIntervals, name)
+					break
+				}
+			}
+		}
+		res.Muted = len(res.ActiveMuteTimeIntervals) > 0
+		result.Routes = append(result.Routes, res)
+	}
+	return result, nil
+}
+
+// routePath returns the matchers of every policy from the root down to target.
+func routePath(root, target *dispatch.Route) []string {
+	if root == target {
+		return []string{root.Matchers.String()}
+	}
+	for _, child := range root.Routes {
+		if path := routePath(child, target); path != nil {
+			return append([]string{root.Matchers.String()}, path...)
+		}
+	}
+	return nil
+}
+
+func contactPoint(name string, receivers map[string]*definitions.PostableApiReceiver) definitions.TestRoutingContactPoint {
+	cp := definitions.TestRoutingContactPoint{
+		Name:         name,
+		Integrations: []definitions.TestRoutingIntegration{},
+	}
+	r, ok := receivers[name]
+	if !ok {
+		return cp
+	}
+	for _, integration := range r.GrafanaManagedReceivers {
+		cp.Integrations = append(cp.Integrations, definitions.TestRoutingIntegration{
+			UID:  integration.UID,
+			Name: integration.Name,
+			Type: integration.Type,
+		})
+	}
+	return cp
+}
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const testRoutingConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [
+				{
+					"receiver": "team-a",
+					"object_matchers": [["team", "=", "a"]],
+					"mute_time_intervals": ["weekends"],
+					"continue": true
+				},
+				{
+					"receiver": "critical",
+					"object_matchers": [["severity", "=", "critical"]],
+					"group_wait": "10s"
+				}
+			]
+		},
+		"mute_time_intervals": [
+			{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}
+		],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default-uid", "name": "default", "type": "email", "settings": {"addresses": "ops@example.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "slack-uid", "name": "team-a", "type": "slack", "settings": {"recipient": "#a"}, "secureSettings": {"token": "secret"}}]},
+			{"name": "critical", "grafana_managed_receiver_configs": [{"uid": "pd-uid", "name": "critical", "type": "pagerduty", "secureSettings": {"integrationKey": "secret"}}]}
+		]
+	}
+}`
+
+func TestTestRouting(t *testing.T) {
+	cfg, err := Load([]byte(testRoutingConfig))
+	require.NoError(t, err)
+
+	saturday := time.Date(2023, time.November, 4, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2023, time.November, 6, 12, 0, 0, 0, time.UTC)
+
+	t.Run("unmatched alert goes to the root policy", func(t *testing.T) {
+		res, err := testRouting(&cfg.AlertmanagerConfig, model.LabelSet{"alertname": "test"}, nil, monday)
+		require.NoError(t, err)
+		require.Len(t, res.Routes, 1)
+		route := res.Routes[0]
+		require.Equal(t, []string{"{}"}, route.Path)
+		require.Equal(t, "default", route.Receiver)
+		require.Equal(t, []string{"alertname"}, route.GroupBy)
+		require.Equal(t, definitions.TestRoutingContactPoint{
+			Name:         "default",
+			Integrations: []definitions.TestRoutingIntegration{{UID: "default-uid", Name: "default", Type: "email"}},
+		}, route.ContactPoint)
+		require.False(t, route.Muted)
+	})
+
+	t.Run("continue matches multiple policies", func(t *testing.T) {
+		res, err := testRouting(&cfg.AlertmanagerConfig, model.LabelSet{"team": "a", "severity": "critical"}, nil, monday)
+		require.NoError(t, err)
+		require.Len(t, res.Routes, 2)
+		require.Equal(t, "team-a", res.Routes[0].Receiver)
+		require.Equal(t, []string{"{}", `{team="a"}`}, res.Routes[0].Path)
+		require.Equal(t, []string{"weekends"}, res.Routes[0].MuteTimeIntervals)
+		require.Empty(t, res.Routes[0].ActiveMuteTimeIntervals)
+		require.False(t, res.Routes[0].Muted)
+		require.Equal(t, "critical", res.Routes[1].Receiver)
+		require.Equal(t, model.Duration(10*time.Second), res.Routes[1].GroupWait)
+		require.Equal(t, "pagerduty", res.Routes[1].ContactPoint.Integrations[0].Type)
+	})
+
+	t.Run("reports mute timings in effect", func(t *testing.T) {
+		res, err := testRouting(&cfg.AlertmanagerConfig, model.LabelSet{"team": "a"}, nil, saturday)
+		require.NoError(t, err)
+		require.Len(t, res.Routes, 1)
+		require.Equal(t, []string{"weekends"}, res.Routes[0].ActiveMuteTimeIntervals)
+		require.True(t, res.Routes[0].Muted)
+		require.Equal(t, saturday, res.Time)
+	})
+
+	t.Run("fails without a root policy", func(t *testing.T) {
+		_, err := testRouting(&definitions.PostableApiAlertingConfig{}, model.LabelSet{"a": "b"}, nil, monday)
+		require.ErrorIs(t, err, ErrNoRoutingTree)
+	})
+}
@@ -10,6 +10,9 @@ import (
 
 type sender struct {
 	ns notifications.Service
+	// orgID is the organization of the alerts, the emails use the language of
+	// its preferences.
+	orgID int64
 }
 
 func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
@@ -47,6 +50,7 @@ func (s sender) SendEmail(ctx context.Context, cmd *receivers.SendEmailSettings)
 			ReplyTo:       cmd.ReplyTo,
 			EmbeddedFiles: cmd.EmbeddedFiles,
 			AttachedFiles: attached,
+			OrgID:         s.orgID,
 		},
 	})
 }
@@ -42,6 +42,14 @@ func (f *fakeConfigStore) GetImages(ctx context.Context, tokens []string) ([]mod
 	return nil, nil, alertingImages.ErrImageNotFound
 }
 
+func (f *fakeConfigStore) SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	return nil
+}
+
+func (f *fakeConfigStore) GetNotificationDeliveries(ctx context.Context, query *models.NotificationDeliveryQuery) ([]*models.NotificationDelivery, error) {
+	return nil, nil
+}
+
 func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfiguration) *fakeConfigStore {
 	t.Helper()
 
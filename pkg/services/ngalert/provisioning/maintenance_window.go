@@ -0,0 +1,188 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MaintenanceWindowService manages the maintenance windows. The silences and annotations of the windows are
+// applied asynchronously by the maintenance window reconciler of the alerting service.
+type MaintenanceWindowService struct {
+	store MaintenanceWindowStore
+	prov  ProvisioningStore
+	xact  TransactionManager
+	log   log.Logger
+	now   func() time.Time
+}
+
+func NewMaintenanceWindowService(store MaintenanceWindowStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MaintenanceWindowService {
+	return &MaintenanceWindowService{
+		store: store,
+		prov:  prov,
+		xact:  xact,
+		log:   log,
+		now:   time.Now,
+	}
+}
+
+// GetMaintenanceWindows returns all the maintenance windows of the organization.
+func (svc *MaintenanceWindowService) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]definitions.MaintenanceWindow, error) {
+	windows, err := svc.store.GetMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&definitions.MaintenanceWindow{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.MaintenanceWindow, 0, len(windows))
+	for _, window := range windows {
+		w := maintenanceWindowToAPI(window)
+		w.Provenance = definitions.Provenance(provenances[w.UID])
+		result = append(result, w)
+	}
+	return result, nil
+}
+
+// GetMaintenanceWindow returns the maintenance window with the UID, or ErrNotFound if it does not exist.
+func (svc *MaintenanceWindowService) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (definitions.MaintenanceWindow, error) {
+	window, err := svc.store.GetMaintenanceWindow(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+			return definitions.MaintenanceWindow{}, fmt.Errorf("%w: %s", ErrNotFound, err.Error())
+		}
+		return definitions.MaintenanceWindow{}, err
+	}
+	result := maintenanceWindowToAPI(window)
+	provenance, err := svc.prov.GetProvenance(ctx, &result, orgID)
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	result.Provenance = definitions.Provenance(provenance)
+	return result, nil
+}
+
+// CreateMaintenanceWindow adds a new maintenance window to the organization. A UID is generated if the window has none.
+func (svc *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, orgID int64, w definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error) {
+	if w.UID == "" {
+		w.UID = util.GenerateShortUID()
+	}
+	if err := validateMaintenanceWindow(w); err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+
+	_, err := svc.store.GetMaintenanceWindow(ctx, orgID, w.UID)
+	if err == nil {
+		return definitions.MaintenanceWindow{}, fmt.Errorf("%w: a maintenance window with UID '%s' already exists", ErrValidation, w.UID)
+	}
+	if !errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+		return definitions.MaintenanceWindow{}, err
+	}
+
+	window := maintenanceWindowFromAPI(orgID, w)
+	window.Updated = svc.now()
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertMaintenanceWindow(ctx, window); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &w, orgID, models.Provenance(w.Provenance))
+	})
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	return w, nil
+}
+
+// UpdateMaintenanceWindow replaces the maintenance window with the same UID, or returns ErrNotFound if it does not exist.
+func (svc *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, orgID int64, w definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error) {
+	if err := validateMaintenanceWindow(w); err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+
+	storedProvenance, err := svc.prov.GetProvenance(ctx, &w, orgID)
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	if !canUpdateProvenanceInRuleGroup(storedProvenance, models.Provenance(w.Provenance)) {
+		return definitions.MaintenanceWindow{}, fmt.Errorf("cannot change provenance from '%s' to '%s'", storedProvenance, w.Provenance)
+	}
+
+	window := maintenanceWindowFromAPI(orgID, w)
+	window.Updated = svc.now()
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateMaintenanceWindow(ctx, window); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &w, orgID, models.Provenance(w.Provenance))
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+			return definitions.MaintenanceWindow{}, fmt.Errorf("%w: %s", ErrNotFound, err.Error())
+		}
+		return definitions.MaintenanceWindow{}, err
+	}
+	return w, nil
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the UID. Deleting a window that does not exist is not an error.
+func (svc *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	target := definitions.MaintenanceWindow{UID: uid}
+	storedProvenance, err := svc.prov.GetProvenance(ctx, &target, orgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("cannot delete with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+	}
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteMaintenanceWindow(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, &target, orgID)
+	})
+}
+
+func validateMaintenanceWindow(w definitions.MaintenanceWindow) error {
+	if err := w.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if err := util.ValidateUID(w.UID); err != nil {
+		return fmt.Errorf("%w: cannot create maintenance window with UID '%s': %s", ErrValidation, w.UID, err.Error())
+	}
+	return nil
+}
+
+func maintenanceWindowFromAPI(orgID int64, w definitions.MaintenanceWindow) *models.MaintenanceWindow {
+	return &models.MaintenanceWindow{
+		OrgID:         orgID,
+		UID:           w.UID,
+		Title:         w.Title,
+		Comment:       w.Comment,
+		Matchers:      labels.Matchers(w.Matchers),
+		DashboardUIDs: w.DashboardUIDs,
+		StartsAt:      w.StartsAt,
+		EndsAt:        w.EndsAt,
+	}
+}
+
+func maintenanceWindowToAPI(window *models.MaintenanceWindow) definitions.MaintenanceWindow {
+	return definitions.MaintenanceWindow{
+		UID:           window.UID,
+		Title:         window.Title,
+		Comment:       window.Comment,
+		Matchers:      definitions.ObjectMatchers(window.Matchers),
+		DashboardUIDs: window.DashboardUIDs,
+		StartsAt:      window.StartsAt.UTC(),
+		EndsAt:        window.EndsAt.UTC(),
+	}
+}
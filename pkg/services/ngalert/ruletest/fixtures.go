@@ -0,0 +1,103 @@
+package ruletest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// fixtureDatasourceType is the type of the data sources of the queries during a test.
+const fixtureDatasourceType = "__rule_test__"
+
+// fixtureDataHandler returns the input series of a test to the data source queries of a rule.
+type fixtureDataHandler struct {
+	series   []models.AlertRuleTestSeries
+	start    time.Time
+	interval time.Duration
+}
+
+func newFixtureDataHandler(test *models.AlertRuleTest, ruleUID string, start time.Time, interval time.Duration) *fixtureDataHandler {
+	h := &fixtureDataHandler{start: start, interval: interval}
+	for _, s := range test.InputSeries {
+		if s.RuleUID == "" || s.RuleUID == ruleUID {
+			h.series = append(h.series, s)
+		}
+	}
+	return h
+}
+
+// QueryData returns the points of the input series of the query that are within the time range of the query. The
+// queries that are instant queries get the last point of each series instead. Queries without input series get no
+// data.
+func (h *fixtureDataHandler) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		instant := isInstantQuery(q.JSON)
+		frames := data.Frames{}
+		for _, s := range h.series {
+			if s.RefID != q.RefID {
+				continue
+			}
+			times, values := h.points(s, q.TimeRange)
+			if len(values) == 0 {
+				continue
+			}
+			if instant {
+				frame := data.NewFrame(q.RefID, data.NewField("Value", s.Labels, values[len(values)-1:]))
+				frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericMulti, TypeVersion: data.FrameTypeVersion{0, 1}})
+				frames = append(frames, frame)
+				continue
+			}
+			frame := data.NewFrame(q.RefID, data.NewField("Time", nil, times), data.NewField("Value", s.Labels, values))
+			frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti, TypeVersion: data.FrameTypeVersion{0, 1}})
+			frames = append(frames, frame)
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+	return resp, nil
+}
+
+// points returns the points of the series within the time range, the missing points excluded.
+func (h *fixtureDataHandler) points(s models.AlertRuleTestSeries, tr backend.TimeRange) ([]time.Time, []float64) {
+	var times []time.Time
+	var values []float64
+	for i, v := range s.Values {
+		t := h.start.Add(time.Duration(i) * h.interval)
+		if v == nil || t.Before(tr.From) || t.After(tr.To) {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, *v)
+	}
+	return times, values
+}
+
+func isInstantQuery(raw json.RawMessage) bool {
+	model := struct {
+		Instant bool `json:"instant"`
+	}{}
+	if err := json.Unmarshal(raw, &model); err != nil {
+		return false
+	}
+	return model.Instant
+}
+
+// fixtureDatasources resolves the data sources of the queries during a test. The data sources are not loaded from
+// the database because the queries get the input series of the test.
+type fixtureDatasources struct{}
+
+func (fixtureDatasources) GetDatasource(context.Context, int64, identity.Requester, bool) (*datasources.DataSource, error) {
+	return nil, errors.New("data sources must be referenced by UID in rule tests")
+}
+
+func (fixtureDatasources) GetDatasourceByUID(_ context.Context, uid string, _ identity.Requester, _ bool) (*datasources.DataSource, error) {
+	return &datasources.DataSource{UID: uid, Name: uid, Type: fixtureDatasourceType}, nil
+}
@@ -0,0 +1,242 @@
+// Package ruletest runs the unit tests of the alert rules: the rules of a rule group are evaluated with the expression
+// engine against the input series of a test instead of the data returned by their data sources, and the states of
+// their alert instances are compared with the expected states. This allows to validate changes to the alert rules in
+// CI without depending on the data in the data sources.
+package ruletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxEvaluations is the maximum number of evaluations of a rule during a test.
+const maxEvaluations = 10000
+
+var (
+	// ErrInvalidTest is returned when the test cannot be run because it is inconsistent with itself or the rules.
+	ErrInvalidTest = errors.New("invalid rule test")
+
+	// start is the time of the first evaluation of the tests. The tests do not depend on the current time so that
+	// they give the same results every time they run.
+	start = time.Unix(0, 0).UTC()
+)
+
+// Result is the outcome of a rule test.
+type Result struct {
+	Failures []Failure
+}
+
+// Passed returns true if all the expectations of the test are met.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Failure is an expectation of a test that is not met.
+type Failure struct {
+	Expectation models.AlertRuleTestExpectation
+	Message     string
+}
+
+// Runner runs the rule tests.
+type Runner struct {
+	cfg         setting.UnifiedAlertingSettings
+	expressions *expr.Service
+	appURL      *url.URL
+	tracer      tracing.Tracer
+	log         log.Logger
+}
+
+func NewRunner(cfg setting.UnifiedAlertingSettings, expressionService *expr.Service, appURL *url.URL, tracer tracing.Tracer) *Runner {
+	return &Runner{
+		cfg:         cfg,
+		expressions: expressionService,
+		appURL:      appURL,
+		tracer:      tracer,
+		log:         log.New("ngalert.ruletest"),
+	}
+}
+
+// Validate returns ErrInvalidTest if the test references rules that are not in the rule group or cannot be run.
+func Validate(rules models.RulesGroup, test *models.AlertRuleTest) error {
+	_, err := newPlan(rules, test)
+	return err
+}
+
+// Run runs the test against the rules of its rule group. It returns ErrInvalidTest if the test references rules that
+// are not in the group or cannot be run.
+func (r *Runner) Run(ctx context.Context, user identity.Requester, rules models.RulesGroup, test *models.AlertRuleTest) (*Result, error) {
+	p, err := newPlan(rules, test)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, rule := range rules {
+		expectations := p.expectations[rule.UID]
+		if len(expectations) == 0 {
+			continue
+		}
+		failures, err := r.runRule(ctx, user, rule, test, p.interval, p.evaluations, expectations)
+		if err != nil {
+			return nil, err
+		}
+		result.Failures = append(result.Failures, failures...)
+	}
+	return result, nil
+}
+
+// plan is the number of evaluations of a test and the expectations to check for each rule, in the order of their
+// evaluation.
+type plan struct {
+	interval     time.Duration
+	evaluations  int
+	expectations map[string][]models.AlertRuleTestExpectation
+}
+
+func newPlan(rules models.RulesGroup, test *models.AlertRuleTest) (*plan, error) {
+	p := &plan{interval: test.Interval, expectations: make(map[string][]models.AlertRuleTestExpectation)}
+	if p.interval == 0 && len(rules) > 0 {
+		p.interval = time.Duration(rules[0].IntervalSeconds) * time.Second
+	}
+	if p.interval <= 0 {
+		return nil, fmt.Errorf("%w: the interval must be positive", ErrInvalidTest)
+	}
+
+	byUID := make(map[string]*models.AlertRule, len(rules))
+	for _, rule := range rules {
+		byUID[rule.UID] = rule
+	}
+	for _, s := range test.InputSeries {
+		if s.RefID == "" {
+			return nil, fmt.Errorf("%w: the input series must have a refId", ErrInvalidTest)
+		}
+		if _, ok := byUID[s.RuleUID]; s.RuleUID != "" && !ok {
+			return nil, fmt.Errorf("%w: rule %s of the input series is not in the rule group", ErrInvalidTest, s.RuleUID)
+		}
+		p.evaluations = max(p.evaluations, len(s.Values))
+	}
+	for _, e := range test.Expectations {
+		if _, ok := byUID[e.RuleUID]; !ok {
+			return nil, fmt.Errorf("%w: rule %q of the expectation is not in the rule group", ErrInvalidTest, e.RuleUID)
+		}
+		if e.At < 0 || e.At%p.interval != 0 {
+			return nil, fmt.Errorf("%w: the time %s of the expectation is not a multiple of the interval %s", ErrInvalidTest, e.At, p.interval)
+		}
+		if _, err := parseState(e.State); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTest, err)
+		}
+		p.evaluations = max(p.evaluations, int(e.At/p.interval)+1)
+		p.expectations[e.RuleUID] = append(p.expectations[e.RuleUID], e)
+	}
+	if p.evaluations > maxEvaluations {
+		return nil, fmt.Errorf("%w: the test requires %d evaluations, the maximum is %d", ErrInvalidTest, p.evaluations, maxEvaluations)
+	}
+	for _, expectations := range p.expectations {
+		sort.SliceStable(expectations, func(i, j int) bool { return expectations[i].At < expectations[j].At })
+	}
+	return p, nil
+}
+
+// runRule evaluates the rule at each interval and checks the expectations after the evaluation at their time.
+func (r *Runner) runRule(ctx context.Context, user identity.Requester, rule *models.AlertRule, test *models.AlertRuleTest,
+	interval time.Duration, evaluations int, expectations []models.AlertRuleTestExpectation) ([]Failure, error) {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	evalFactory := eval.NewEvaluatorFactory(r.cfg, fixtureDatasources{},
+		r.expressions.WithDataHandler(newFixtureDataHandler(test, rule.UID, start, interval)), nil)
+	evaluator, err := evalFactory.Create(eval.NewContext(ruleCtx, user), rule.GetEvalCondition())
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to build the evaluator of rule %s: %s", ErrInvalidTest, rule.UID, err)
+	}
+	stateManager := r.newStateManager()
+
+	var failures []Failure
+	for i := 0; i < evaluations && len(expectations) > 0; i++ {
+		now := start.Add(time.Duration(i) * interval)
+		results, err := evaluator.Evaluate(ruleCtx, now)
+		if err != nil {
+			// The errors of the evaluation are alert instances in the Error state, like in the scheduler.
+			results = eval.Results{{State: eval.Error, Error: err, EvaluatedAt: now}}
+		}
+		stateManager.ProcessEvalResults(ruleCtx, now, rule, results, nil)
+
+		at := now.Sub(start)
+		for len(expectations) > 0 && expectations[0].At == at {
+			if failure := check(expectations[0], stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)); failure != nil {
+				failures = append(failures, *failure)
+			}
+			expectations = expectations[1:]
+		}
+	}
+	return failures, nil
+}
+
+func (r *Runner) newStateManager() *state.Manager {
+	return state.NewManager(state.ManagerCfg{
+		ExternalURL:             r.appURL,
+		Images:                  &backtesting.NoopImageService{},
+		Clock:                   clock.New(),
+		MaxStateSaveConcurrency: 1,
+		Tracer:                  r.tracer,
+		Log:                     r.log,
+	})
+}
+
+// check returns a failure if no alert instance has the labels of the expectation, or if one of them is not in the
+// expected state.
+func check(expectation models.AlertRuleTestExpectation, states []*state.State) *Failure {
+	expected, _ := parseState(expectation.State)
+	matched := 0
+	for _, s := range states {
+		if !hasLabels(s.Labels, expectation.Labels) {
+			continue
+		}
+		matched++
+		if s.State != expected {
+			return &Failure{
+				Expectation: expectation,
+				Message:     fmt.Sprintf("alert instance %s is %s, expected %s", s.Labels.String(), s.State, expected),
+			}
+		}
+	}
+	if matched == 0 {
+		return &Failure{
+			Expectation: expectation,
+			Message:     fmt.Sprintf("no alert instance has the labels %v, expected %s", expectation.Labels, expected),
+		}
+	}
+	return nil
+}
+
+func hasLabels(labels map[string]string, subset map[string]string) bool {
+	for k, v := range subset {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func parseState(s string) (eval.State, error) {
+	for _, st := range []eval.State{eval.Normal, eval.Pending, eval.Alerting, eval.NoData, eval.Error} {
+		if st.String() == s {
+			return st, nil
+		}
+	}
+	return eval.Normal, fmt.Errorf("unknown state %q, expected one of Normal, Pending, Alerting, NoData or Error", s)
+}
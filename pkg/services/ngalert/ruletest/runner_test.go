@@ -0,0 +1,126 @@
+package ruletest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestRunner() *Runner {
+	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest())
+	return NewRunner(setting.UnifiedAlertingSettings{}, exprService, nil, tracing.InitializeTracerForTest())
+}
+
+// cpuRule alerts when the last point of the series of the last 10 minutes is above 80 for two evaluations.
+func cpuRule(instant bool) *models.AlertRule {
+	query := models.CreatePrometheusQuery("A", "cpu", 1000, 100, instant, "prom")
+	query.RelativeTimeRange = models.RelativeTimeRange{From: models.Duration(10 * time.Minute)}
+	rule := models.AlertRuleGen(
+		models.WithOrgID(1),
+		models.WithInterval(time.Minute),
+		models.WithFor(time.Minute),
+		models.WithQuery(
+			query,
+			models.CreateReduceExpression("B", "A", "last"),
+			models.AlertQuery{
+				RefID:         "C",
+				QueryType:     expr.DatasourceType,
+				DatasourceUID: expr.DatasourceUID,
+				Model:         json.RawMessage(`{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}`),
+			},
+		),
+	)()
+	rule.Condition = "C"
+	return rule
+}
+
+func values(vs ...float64) []*float64 {
+	result := make([]*float64, 0, len(vs))
+	for i := range vs {
+		result = append(result, &vs[i])
+	}
+	return result
+}
+
+func TestRunner_Run(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	for _, instant := range []bool{false, true} {
+		rule := cpuRule(instant)
+		test := &models.AlertRuleTest{
+			Title: "cpu",
+			InputSeries: []models.AlertRuleTestSeries{
+				{RefID: "A", Labels: map[string]string{"instance": "a"}, Values: values(10, 90, 95, 95, 10)},
+				{RefID: "A", Labels: map[string]string{"instance": "b"}, Values: values(10, 10, 10, 10, 10)},
+			},
+			Expectations: []models.AlertRuleTestExpectation{
+				{RuleUID: rule.UID, At: 0, State: "Normal"},
+				{RuleUID: rule.UID, At: time.Minute, Labels: map[string]string{"instance": "a"}, State: "Pending"},
+				{RuleUID: rule.UID, At: 2 * time.Minute, Labels: map[string]string{"instance": "a"}, State: "Alerting"},
+				{RuleUID: rule.UID, At: 2 * time.Minute, Labels: map[string]string{"instance": "b"}, State: "Normal"},
+				{RuleUID: rule.UID, At: 4 * time.Minute, State: "Normal"},
+			},
+		}
+
+		result, err := newTestRunner().Run(context.Background(), usr, models.RulesGroup{rule}, test)
+		require.NoError(t, err)
+		assert.True(t, result.Passed(), "instant: %t, failures: %v", instant, result.Failures)
+	}
+
+	t.Run("reports the expectations that are not met", func(t *testing.T) {
+		rule := cpuRule(false)
+		test := &models.AlertRuleTest{
+			InputSeries: []models.AlertRuleTestSeries{
+				{RefID: "A", Labels: map[string]string{"instance": "a"}, Values: values(90, 90)},
+			},
+			Expectations: []models.AlertRuleTestExpectation{
+				{RuleUID: rule.UID, At: time.Minute, Labels: map[string]string{"instance": "a"}, State: "Pending"},
+				{RuleUID: rule.UID, At: time.Minute, Labels: map[string]string{"instance": "c"}, State: "Normal"},
+			},
+		}
+
+		result, err := newTestRunner().Run(context.Background(), usr, models.RulesGroup{rule}, test)
+		require.NoError(t, err)
+		require.Len(t, result.Failures, 2)
+		assert.Contains(t, result.Failures[0].Message, "is Alerting, expected Pending")
+		assert.Contains(t, result.Failures[1].Message, "no alert instance has the labels")
+	})
+
+	t.Run("evaluates the queries without input series as no data", func(t *testing.T) {
+		rule := cpuRule(false)
+		test := &models.AlertRuleTest{
+			Expectations: []models.AlertRuleTestExpectation{{RuleUID: rule.UID, State: "NoData"}},
+		}
+		rule.NoDataState = models.NoData
+
+		result, err := newTestRunner().Run(context.Background(), usr, models.RulesGroup{rule}, test)
+		require.NoError(t, err)
+		assert.True(t, result.Passed(), "failures: %v", result.Failures)
+	})
+
+	t.Run("rejects invalid tests", func(t *testing.T) {
+		rule := cpuRule(false)
+		for _, test := range []*models.AlertRuleTest{
+			{InputSeries: []models.AlertRuleTestSeries{{RuleUID: rule.UID}}},
+			{InputSeries: []models.AlertRuleTestSeries{{RuleUID: "unknown", RefID: "A"}}},
+			{Expectations: []models.AlertRuleTestExpectation{{RuleUID: "unknown", State: "Normal"}}},
+			{Expectations: []models.AlertRuleTestExpectation{{RuleUID: rule.UID, At: 90 * time.Second, State: "Normal"}}},
+			{Expectations: []models.AlertRuleTestExpectation{{RuleUID: rule.UID, State: "Firing"}}},
+			{Expectations: []models.AlertRuleTestExpectation{{RuleUID: rule.UID, At: maxEvaluations * time.Minute, State: "Normal"}}},
+		} {
+			_, err := newTestRunner().Run(context.Background(), usr, models.RulesGroup{rule}, test)
+			assert.ErrorIs(t, err, ErrInvalidTest)
+		}
+	})
+}
@@ -91,6 +91,9 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 	if query.RuleUID == "" {
 		return nil, fmt.Errorf("ruleUID is required to query annotations")
 	}
+	if query.IsAggregation() {
+		return nil, ngmodels.ErrHistoryAggregationNotSupported
+	}
 
 	if query.Labels != nil {
 		logger.Warn("Annotation state history backend does not support label queries, ignoring that filter")
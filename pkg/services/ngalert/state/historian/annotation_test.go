@@ -47,6 +47,19 @@ func TestAnnotationHistorian(t *testing.T) {
 		}
 	})
 
+	t.Run("aggregation queries are not supported", func(t *testing.T) {
+		anns := createTestAnnotationBackendSut(t)
+
+		q := models.HistoryQuery{
+			RuleUID: "my-rule",
+			OrgID:   1,
+			GroupBy: []models.HistoryGroupBy{models.HistoryGroupByState},
+		}
+		_, err := anns.Query(context.Background(), q)
+
+		require.ErrorIs(t, err, models.ErrHistoryAggregationNotSupported)
+	})
+
 	t.Run("writing state transitions as annotations succeeds", func(t *testing.T) {
 		anns := createTestAnnotationBackendSut(t)
 		rule := createTestRule()
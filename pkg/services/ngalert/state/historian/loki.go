@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -44,32 +45,53 @@ type remoteLokiClient interface {
 	Ping(context.Context) error
 	Push(context.Context, []Stream) error
 	RangeQuery(ctx context.Context, logQL string, start, end, limit int64) (QueryRes, error)
+	MetricRangeQuery(ctx context.Context, logQL string, start, end, step int64) (MetricQueryRes, error)
 }
 
 // RemoteLokibackend is a state.Historian that records state history to an external Loki instance.
 type RemoteLokiBackend struct {
-	client         remoteLokiClient
+	client remoteLokiClient
+	// buffer is the client when the writes are buffered, nil otherwise.
+	buffer         *lokiWriteBuffer
 	externalLabels map[string]string
 	clock          clock.Clock
 	metrics        *metrics.Historian
 	log            log.Logger
 }
 
-func NewRemoteLokiBackend(cfg LokiConfig, req client.Requester, metrics *metrics.Historian) *RemoteLokiBackend {
+func NewRemoteLokiBackend(cfg LokiConfig, req client.Requester, metrics *metrics.Historian) (*RemoteLokiBackend, error) {
 	logger := log.New("ngalert.state.historian", "backend", "loki")
-	return &RemoteLokiBackend{
+	backend := &RemoteLokiBackend{
 		client:         NewLokiClient(cfg, req, metrics, logger),
 		externalLabels: cfg.ExternalLabels,
 		clock:          clock.New(),
 		metrics:        metrics,
 		log:            logger,
 	}
+	if cfg.WriteBuffer != nil {
+		buffer, err := newLokiWriteBuffer(backend.client, *cfg.WriteBuffer, metrics, logger)
+		if err != nil {
+			return nil, err
+		}
+		backend.client = buffer
+		backend.buffer = buffer
+	}
+	return backend, nil
 }
 
 func (h *RemoteLokiBackend) TestConnection(ctx context.Context) error {
 	return h.client.Ping(ctx)
 }
 
+// Run writes the buffered state history to Loki in the background until the context is cancelled.
+// It returns immediately if the writes are not buffered.
+func (h *RemoteLokiBackend) Run(ctx context.Context) error {
+	if h.buffer == nil {
+		return nil
+	}
+	return h.buffer.Run(ctx)
+}
+
 // Record writes a number of state transitions for a given rule to an external Loki instance.
 func (h *RemoteLokiBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
 	logger := h.log.FromContext(ctx)
@@ -125,6 +147,10 @@ func (h *RemoteLokiBackend) Query(ctx context.Context, query models.HistoryQuery
 		query.From = now.Add(-defaultQueryRange)
 	}
 
+	if query.IsAggregation() {
+		return h.aggregate(ctx, query)
+	}
+
 	// Timestamps are expected in RFC3339Nano.
 	res, err := h.client.RangeQuery(ctx, logQL, query.From.UnixNano(), query.To.UnixNano(), int64(query.Limit))
 	if err != nil {
@@ -133,6 +159,28 @@ func (h *RemoteLokiBackend) Query(ctx context.Context, query models.HistoryQuery
 	return merge(res, query.RuleUID)
 }
 
+// aggregate counts the state transitions matching the query in buckets of the query interval, grouped by the requested dimensions.
+// The counting is done by Loki, so only the aggregated series are transferred.
+func (h *RemoteLokiBackend) aggregate(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	interval := aggregationInterval(query)
+	logQL, err := buildMetricQuery(query, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loki evaluates a metric query at start + n*step over the preceding step,
+	// so the first evaluation has to happen one interval after the start of the range.
+	start := query.From.Add(interval)
+	if start.After(query.To) {
+		start = query.To
+	}
+	res, err := h.client.MetricRangeQuery(ctx, logQL, start.UnixNano(), query.To.UnixNano(), int64(interval))
+	if err != nil {
+		return nil, err
+	}
+	return aggregatedFrame(res, query.GroupBy, interval), nil
+}
+
 func buildSelectors(query models.HistoryQuery) ([]Selector, error) {
 	// OrgID and the state history label are static and will be included in all queries.
 	selectors := make([]Selector, 2)
@@ -405,3 +453,133 @@ func queryHasLogFilters(query models.HistoryQuery) bool {
 		query.PanelID != 0 ||
 		len(query.Labels) > 0
 }
+
+const (
+	// defaultAggregationInterval is the bucket size used when an aggregation query does not specify one.
+	defaultAggregationInterval = time.Minute
+	// maximumAggregationBuckets is the maximum number of points Loki returns per series of a range query.
+	maximumAggregationBuckets = 11000
+	// dfCount is the name of the column holding the number of transitions in a bucket.
+	dfCount = "count"
+)
+
+// aggregationGroupLabels maps the aggregation dimensions to the name of the label they are extracted to, and the field of the log line they are extracted from.
+// The label names are distinct from the ones produced by a plain "| json" stage to avoid collisions with the filters of buildLogQuery.
+var aggregationGroupLabels = map[models.HistoryGroupBy]struct {
+	label string
+	field string
+	frame string
+}{
+	models.HistoryGroupByRule:   {label: "group_rule", field: "ruleUID", frame: "ruleUID"},
+	models.HistoryGroupByLabels: {label: "group_labels", field: "labels", frame: "labels"},
+	models.HistoryGroupByState:  {label: "group_state", field: "current", frame: "state"},
+}
+
+// aggregationInterval returns the bucket size of an aggregation query, making sure it is
+// a whole number of seconds and does not produce more buckets than Loki allows.
+func aggregationInterval(query models.HistoryQuery) time.Duration {
+	interval := query.Interval
+	if interval <= 0 {
+		interval = defaultAggregationInterval
+	}
+	if minInterval := query.To.Sub(query.From) / maximumAggregationBuckets; interval < minInterval {
+		interval = minInterval
+	}
+	if rem := interval % time.Second; rem != 0 {
+		interval += time.Second - rem
+	}
+	return interval
+}
+
+func buildMetricQuery(query models.HistoryQuery, interval time.Duration) (string, error) {
+	logQL, err := buildLogQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	groupLabels := make([]string, 0, len(query.GroupBy))
+	extract := make([]string, 0, len(query.GroupBy))
+	for _, g := range query.GroupBy {
+		l, ok := aggregationGroupLabels[g]
+		if !ok {
+			return "", fmt.Errorf("unsupported group by %q", g)
+		}
+		groupLabels = append(groupLabels, l.label)
+		extract = append(extract, fmt.Sprintf("%s=%q", l.label, l.field))
+	}
+	if len(extract) > 0 {
+		logQL = fmt.Sprintf("%s | json %s", logQL, strings.Join(extract, ", "))
+	}
+
+	rangeQL := fmt.Sprintf("count_over_time(%s [%ds])", logQL, int64(interval/time.Second))
+	if len(groupLabels) == 0 {
+		return fmt.Sprintf("sum(%s)", rangeQL), nil
+	}
+	return fmt.Sprintf("sum by (%s) (%s)", strings.Join(groupLabels, ", "), rangeQL), nil
+}
+
+// aggregatedFrame converts the series of an aggregation query into a single long-format frame.
+// Every row holds the start of a bucket, the value of each group by dimension and the number of transitions in the bucket.
+func aggregatedFrame(res MetricQueryRes, groupBy []models.HistoryGroupBy, interval time.Duration) *data.Frame {
+	type row struct {
+		t      time.Time
+		groups []string
+		count  float64
+	}
+	rows := make([]row, 0)
+	for _, series := range res.Data.Result {
+		groups := make([]string, len(groupBy))
+		for i, g := range groupBy {
+			groups[i] = series.Metric[aggregationGroupLabels[g].label]
+		}
+		for _, sample := range series.Values {
+			rows = append(rows, row{t: sample.T.Add(-interval), groups: groups, count: sample.V})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].t.Equal(rows[j].t) {
+			return rows[i].t.Before(rows[j].t)
+		}
+		for k := range rows[i].groups {
+			if rows[i].groups[k] != rows[j].groups[k] {
+				return rows[i].groups[k] < rows[j].groups[k]
+			}
+		}
+		return false
+	})
+
+	times := make([]time.Time, 0, len(rows))
+	counts := make([]float64, 0, len(rows))
+	groupFields := make([]*data.Field, 0, len(groupBy))
+	for _, g := range groupBy {
+		if g == models.HistoryGroupByLabels {
+			groupFields = append(groupFields, data.NewField(aggregationGroupLabels[g].frame, nil, make([]json.RawMessage, 0, len(rows))))
+			continue
+		}
+		groupFields = append(groupFields, data.NewField(aggregationGroupLabels[g].frame, nil, make([]string, 0, len(rows))))
+	}
+	for _, r := range rows {
+		times = append(times, r.t)
+		counts = append(counts, r.count)
+		for i, v := range r.groups {
+			if groupBy[i] == models.HistoryGroupByLabels {
+				// The label set is extracted from the log line as raw JSON.
+				if v == "" {
+					v = "{}"
+				}
+				groupFields[i].Append(json.RawMessage(v))
+				continue
+			}
+			groupFields[i].Append(v)
+		}
+	}
+
+	frame := data.NewFrame("states")
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, nil, times))
+	frame.Fields = append(frame.Fields, groupFields...)
+	frame.Fields = append(frame.Fields, data.NewField(dfCount, nil, counts))
+	frame.SetMeta(&data.FrameMeta{
+		Custom: map[string]any{"interval": interval.Milliseconds()},
+	})
+	return frame
+}
@@ -0,0 +1,359 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+const (
+	lokiBufferMinRetryInterval = time.Second
+	lokiBufferFileExt          = ".json"
+	// lokiBufferFirstSeq is the sequence number of the first batch spilled to an empty directory. The batches spilled on
+	// shutdown are older than the batches already on disk, so they get lower sequence numbers.
+	lokiBufferFirstSeq int64 = 1 << 32
+)
+
+// LokiBufferConfig configures the write buffer of the Loki backend.
+type LokiBufferConfig struct {
+	MaxMemoryBytes   int64
+	Path             string
+	MaxDiskBytes     int64
+	MaxRetryInterval time.Duration
+}
+
+// bufferedBatch is a batch of streams waiting to be written to Loki. The streams of the batches spilled to disk are
+// only loaded when the batch is written.
+type bufferedBatch struct {
+	streams []Stream
+	size    int64
+	// seq is the sequence number of the file of a batch spilled to disk, zero for a batch in memory.
+	seq int64
+}
+
+// lokiWriteBuffer is a remoteLokiClient that acknowledges the writes once they are buffered, and writes them to Loki
+// in the background, retrying while Loki is unavailable. The batches are kept in memory up to a size limit and are
+// spilled to disk beyond it, so that they survive long outages and restarts. The batches are written in the order they
+// were pushed: a new batch is spilled to disk as long as older batches are on disk.
+type lokiWriteBuffer struct {
+	remoteLokiClient
+	cfg     LokiBufferConfig
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+
+	mtx         sync.Mutex
+	memory      []bufferedBatch
+	memoryBytes int64
+	disk        []bufferedBatch
+	diskBytes   int64
+	nextSeq     int64
+	closed      bool
+	notify      chan struct{}
+}
+
+// newLokiWriteBuffer creates the spill directory if needed and loads the batches spilled to it by a previous run.
+func newLokiWriteBuffer(client remoteLokiClient, cfg LokiBufferConfig, metrics *metrics.Historian, logger log.Logger) (*lokiWriteBuffer, error) {
+	b := &lokiWriteBuffer{
+		remoteLokiClient: client,
+		cfg:              cfg,
+		clock:            clock.New(),
+		metrics:          metrics,
+		log:              logger.New("component", "write-buffer"),
+		nextSeq:          lokiBufferFirstSeq,
+		notify:           make(chan struct{}, 1),
+	}
+	if err := os.MkdirAll(cfg.Path, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create the state history buffer directory: %w", err)
+	}
+	entries, err := os.ReadDir(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state history buffer directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, lokiBufferFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseInt(strings.TrimSuffix(name, lokiBufferFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the state history buffer directory: %w", err)
+		}
+		b.disk = append(b.disk, bufferedBatch{seq: seq, size: info.Size()})
+		b.diskBytes += info.Size()
+	}
+	sort.Slice(b.disk, func(i, j int) bool { return b.disk[i].seq < b.disk[j].seq })
+	if len(b.disk) > 0 {
+		b.nextSeq = b.disk[len(b.disk)-1].seq + 1
+		b.log.Info("Loaded the state history spilled to disk", "batches", len(b.disk), "bytes", b.diskBytes)
+	}
+	b.updateMetrics()
+	return b, nil
+}
+
+// Push buffers the streams. It only fails if the streams can be neither kept in memory nor spilled to disk.
+func (b *lokiWriteBuffer) Push(_ context.Context, streams []Stream) error {
+	size := streamsSize(streams)
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if !b.closed && len(b.disk) == 0 && b.memoryBytes+size <= b.cfg.MaxMemoryBytes {
+		b.memory = append(b.memory, bufferedBatch{streams: streams, size: size})
+		b.memoryBytes += size
+	} else if err := b.spill(streams); err != nil {
+		return err
+	}
+	b.updateMetrics()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run writes the buffered batches to Loki until the context is cancelled, then spills the batches left in memory to
+// disk so that they are written after a restart.
+func (b *lokiWriteBuffer) Run(ctx context.Context) error {
+	var retry time.Duration
+	for {
+		if err := b.flush(ctx); err != nil {
+			retry = nextRetryInterval(retry, b.cfg.MaxRetryInterval)
+			b.log.Warn("Failed to write the buffered state history to Loki, retrying", "retry", retry, "error", err)
+		} else {
+			retry = 0
+		}
+
+		if retry > 0 {
+			// Do not retry before the end of the interval, even if new batches are pushed.
+			select {
+			case <-ctx.Done():
+				b.close()
+				return nil
+			case <-b.clock.After(retry):
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			b.close()
+			return nil
+		case <-b.notify:
+		}
+	}
+}
+
+// flush writes the buffered batches to Loki, oldest first, until the buffer is empty or a write fails.
+func (b *lokiWriteBuffer) flush(ctx context.Context) error {
+	for ctx.Err() == nil {
+		batch, ok := b.head()
+		if !ok {
+			return nil
+		}
+		if batch.seq != 0 {
+			streams, err := b.load(batch.seq)
+			if errors.Is(err, os.ErrNotExist) {
+				// The batch was dropped to make room for newer batches.
+				b.pop(batch)
+				continue
+			}
+			if err != nil {
+				b.log.Error("Dropping a state history batch that cannot be read from disk", "file", b.file(batch.seq), "error", err)
+				b.metrics.BufferDropped.Inc()
+				b.pop(batch)
+				continue
+			}
+			batch.streams = streams
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, StateHistoryWriteTimeout)
+		err := b.remoteLokiClient.Push(writeCtx, batch.streams)
+		cancel()
+		if err != nil {
+			return err
+		}
+		b.pop(batch)
+	}
+	return nil
+}
+
+// head returns the oldest buffered batch. The batches in memory are always older than the batches on disk.
+func (b *lokiWriteBuffer) head() (bufferedBatch, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if len(b.memory) > 0 {
+		return b.memory[0], true
+	}
+	if len(b.disk) > 0 {
+		return b.disk[0], true
+	}
+	return bufferedBatch{}, false
+}
+
+// pop removes the batch returned by head once it is written, unless it was dropped in the meantime.
+func (b *lokiWriteBuffer) pop(batch bufferedBatch) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if batch.seq == 0 {
+		b.memory = b.memory[1:]
+		b.memoryBytes -= batch.size
+	} else if len(b.disk) > 0 && b.disk[0].seq == batch.seq {
+		b.removeOldestFromDisk()
+	}
+	b.updateMetrics()
+}
+
+// close spills the batches left in memory to disk. The batches pushed afterwards are spilled to disk directly.
+func (b *lokiWriteBuffer) close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.closed = true
+	if len(b.memory) == 0 {
+		return
+	}
+
+	seq := b.nextSeq
+	if len(b.disk) > 0 {
+		seq = b.disk[0].seq - int64(len(b.memory))
+	}
+	spilled := make([]bufferedBatch, 0, len(b.memory)+len(b.disk))
+	for _, batch := range b.memory {
+		raw, err := json.Marshal(batch.streams)
+		if err == nil {
+			err = b.write(seq, raw)
+		}
+		if err != nil {
+			b.log.Error("Failed to spill the buffered state history to disk, it is lost", "error", err)
+			b.metrics.BufferDropped.Inc()
+			continue
+		}
+		spilled = append(spilled, bufferedBatch{seq: seq, size: int64(len(raw))})
+		b.diskBytes += int64(len(raw))
+		seq++
+	}
+	b.log.Info("Spilled the buffered state history to disk", "batches", len(spilled))
+	b.disk = append(spilled, b.disk...)
+	if seq > b.nextSeq {
+		b.nextSeq = seq
+	}
+	b.memory = nil
+	b.memoryBytes = 0
+	b.updateMetrics()
+}
+
+// spill writes the streams to disk, dropping the oldest batches on disk to stay within the size limit.
+// It must be called with the lock held.
+func (b *lokiWriteBuffer) spill(streams []Stream) error {
+	raw, err := json.Marshal(streams)
+	if err != nil {
+		return fmt.Errorf("failed to encode the state history batch: %w", err)
+	}
+	size := int64(len(raw))
+	if size > b.cfg.MaxDiskBytes {
+		b.metrics.BufferDropped.Inc()
+		return fmt.Errorf("the state history batch of %d bytes exceeds the size limit of the buffer", size)
+	}
+	for len(b.disk) > 0 && b.diskBytes+size > b.cfg.MaxDiskBytes {
+		b.log.Warn("The state history buffer is full, dropping the oldest batch", "file", b.file(b.disk[0].seq))
+		b.metrics.BufferDropped.Inc()
+		b.removeOldestFromDisk()
+	}
+	if err := b.write(b.nextSeq, raw); err != nil {
+		b.metrics.BufferDropped.Inc()
+		return fmt.Errorf("failed to spill the state history to disk: %w", err)
+	}
+	b.disk = append(b.disk, bufferedBatch{seq: b.nextSeq, size: size})
+	b.diskBytes += size
+	b.nextSeq++
+	return nil
+}
+
+// removeOldestFromDisk must be called with the lock held.
+func (b *lokiWriteBuffer) removeOldestFromDisk() {
+	batch := b.disk[0]
+	if err := os.Remove(b.file(batch.seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		b.log.Warn("Failed to remove a state history batch from disk", "file", b.file(batch.seq), "error", err)
+	}
+	b.disk = b.disk[1:]
+	b.diskBytes -= batch.size
+}
+
+// write writes the encoded streams to the file of the sequence number atomically.
+func (b *lokiWriteBuffer) write(seq int64, raw []byte) error {
+	tmp := b.file(seq) + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, b.file(seq)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (b *lokiWriteBuffer) load(seq int64) ([]Stream, error) {
+	// nolint:gosec
+	// The path is built from the configured directory and a sequence number.
+	raw, err := os.ReadFile(b.file(seq))
+	if err != nil {
+		return nil, err
+	}
+	var streams []Stream
+	if err := json.Unmarshal(raw, &streams); err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+func (b *lokiWriteBuffer) file(seq int64) string {
+	return filepath.Join(b.cfg.Path, fmt.Sprintf("%020d%s", seq, lokiBufferFileExt))
+}
+
+// updateMetrics must be called with the lock held.
+func (b *lokiWriteBuffer) updateMetrics() {
+	b.metrics.BufferBytes.WithLabelValues("memory").Set(float64(b.memoryBytes))
+	b.metrics.BufferBytes.WithLabelValues("disk").Set(float64(b.diskBytes))
+}
+
+// streamsSize estimates the memory used by the streams.
+func streamsSize(streams []Stream) int64 {
+	var size int64
+	for _, s := range streams {
+		for k, v := range s.Stream {
+			size += int64(len(k) + len(v))
+		}
+		for _, sample := range s.Values {
+			// The timestamp of a sample is 24 bytes.
+			size += int64(len(sample.V) + 24)
+		}
+	}
+	return size
+}
+
+func nextRetryInterval(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next < lokiBufferMinRetryInterval {
+		next = lokiBufferMinRetryInterval
+	}
+	if max > 0 && next > max {
+		next = max
+	}
+	return next
+}
@@ -0,0 +1,136 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+// fakeLokiClient records the lines pushed to it, or fails if it is unavailable.
+type fakeLokiClient struct {
+	remoteLokiClient
+	unavailable bool
+	lines       []string
+}
+
+func (c *fakeLokiClient) Push(_ context.Context, streams []Stream) error {
+	if c.unavailable {
+		return errors.New("loki is unavailable")
+	}
+	for _, s := range streams {
+		for _, v := range s.Values {
+			c.lines = append(c.lines, v.V)
+		}
+	}
+	return nil
+}
+
+func createTestWriteBuffer(t *testing.T, client remoteLokiClient, path string, maxMemoryBytes, maxDiskBytes int64) (*lokiWriteBuffer, *metrics.Historian) {
+	t.Helper()
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	b, err := newLokiWriteBuffer(client, LokiBufferConfig{
+		MaxMemoryBytes: maxMemoryBytes,
+		Path:           path,
+		MaxDiskBytes:   maxDiskBytes,
+	}, met, log.NewNopLogger())
+	require.NoError(t, err)
+	return b, met
+}
+
+// testBatch returns a batch with a single line of 100 bytes.
+func testBatch(line string) []Stream {
+	v := line + strings.Repeat(".", 100-len(line))
+	return []Stream{{Stream: map[string]string{}, Values: []Sample{{T: time.Unix(1, 0), V: v}}}}
+}
+
+func trimmed(lines []string) []string {
+	res := make([]string, 0, len(lines))
+	for _, l := range lines {
+		res = append(res, strings.TrimRight(l, "."))
+	}
+	return res
+}
+
+func TestLokiWriteBuffer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes the batches in memory", func(t *testing.T) {
+		client := &fakeLokiClient{}
+		b, met := createTestWriteBuffer(t, client, t.TempDir(), 1000, 10000)
+
+		require.NoError(t, b.Push(ctx, testBatch("a")))
+		require.NoError(t, b.Push(ctx, testBatch("b")))
+		require.Empty(t, client.lines)
+		require.Equal(t, float64(248), testutil.ToFloat64(met.BufferBytes.WithLabelValues("memory")))
+
+		require.NoError(t, b.flush(ctx))
+		require.Equal(t, []string{"a", "b"}, trimmed(client.lines))
+		require.Zero(t, testutil.ToFloat64(met.BufferBytes.WithLabelValues("memory")))
+	})
+
+	t.Run("spills to disk while loki is unavailable and writes in order once it is available", func(t *testing.T) {
+		client := &fakeLokiClient{unavailable: true}
+		b, met := createTestWriteBuffer(t, client, t.TempDir(), 300, 10000)
+
+		for _, line := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, b.Push(ctx, testBatch(line)))
+			require.Error(t, b.flush(ctx))
+		}
+		require.Len(t, b.memory, 2)
+		require.Len(t, b.disk, 2)
+		require.Positive(t, testutil.ToFloat64(met.BufferBytes.WithLabelValues("disk")))
+
+		client.unavailable = false
+		require.NoError(t, b.flush(ctx))
+		require.Equal(t, []string{"a", "b", "c", "d"}, trimmed(client.lines))
+		require.Empty(t, b.disk)
+		entries, err := os.ReadDir(b.cfg.Path)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("keeps the batches after a restart", func(t *testing.T) {
+		path := t.TempDir()
+		client := &fakeLokiClient{unavailable: true}
+		b, _ := createTestWriteBuffer(t, client, path, 300, 10000)
+		for _, line := range []string{"a", "b", "c"} {
+			require.NoError(t, b.Push(ctx, testBatch(line)))
+		}
+		b.close()
+
+		client.unavailable = false
+		b, _ = createTestWriteBuffer(t, client, path, 300, 10000)
+		require.NoError(t, b.Push(ctx, testBatch("d")))
+		require.NoError(t, b.flush(ctx))
+		require.Equal(t, []string{"a", "b", "c", "d"}, trimmed(client.lines))
+	})
+
+	t.Run("drops the oldest batches when the disk is full", func(t *testing.T) {
+		client := &fakeLokiClient{unavailable: true}
+		b, met := createTestWriteBuffer(t, client, t.TempDir(), 0, 500)
+		for _, line := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, b.Push(ctx, testBatch(line)))
+		}
+		require.Equal(t, float64(1), testutil.ToFloat64(met.BufferDropped))
+
+		client.unavailable = false
+		require.NoError(t, b.flush(ctx))
+		require.Equal(t, []string{"b", "c", "d"}, trimmed(client.lines))
+	})
+}
+
+func TestNextRetryInterval(t *testing.T) {
+	require.Equal(t, time.Second, nextRetryInterval(0, time.Minute))
+	require.Equal(t, 4*time.Second, nextRetryInterval(2*time.Second, time.Minute))
+	require.Equal(t, time.Minute, nextRetryInterval(45*time.Second, time.Minute))
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -40,6 +41,8 @@ type LokiConfig struct {
 	TenantID          string
 	ExternalLabels    map[string]string
 	Encoder           encoder
+	// WriteBuffer configures the buffering of the writes, they are not buffered if it is nil.
+	WriteBuffer *LokiBufferConfig
 }
 
 func NewLokiConfig(cfg setting.UnifiedAlertingStateHistorySettings) (LokiConfig, error) {
@@ -67,6 +70,16 @@ func NewLokiConfig(cfg setting.UnifiedAlertingStateHistorySettings) (LokiConfig,
 		return LokiConfig{}, fmt.Errorf("failed to parse loki remote write URL: %w", err)
 	}
 
+	var writeBuffer *LokiBufferConfig
+	if cfg.LokiWriteBuffer.Enabled {
+		writeBuffer = &LokiBufferConfig{
+			MaxMemoryBytes:   cfg.LokiWriteBuffer.MaxMemoryBytes,
+			Path:             cfg.LokiWriteBuffer.Path,
+			MaxDiskBytes:     cfg.LokiWriteBuffer.MaxDiskBytes,
+			MaxRetryInterval: cfg.LokiWriteBuffer.MaxRetryInterval,
+		}
+	}
+
 	return LokiConfig{
 		ReadPathURL:       readURL,
 		WritePathURL:      writeURL,
@@ -75,7 +88,8 @@ func NewLokiConfig(cfg setting.UnifiedAlertingStateHistorySettings) (LokiConfig,
 		TenantID:          cfg.LokiTenantID,
 		ExternalLabels:    cfg.ExternalLabels,
 		// Snappy-compressed protobuf is the default, same goes for Promtail.
-		Encoder: SnappyProtoEncoder{},
+		Encoder:     SnappyProtoEncoder{},
+		WriteBuffer: writeBuffer,
 	}, nil
 }
 
@@ -238,20 +252,67 @@ func (c *HttpLokiClient) RangeQuery(ctx context.Context, logQL string, start, en
 		limit = maximumPageSize
 	}
 
-	queryURL := c.cfg.ReadPathURL.JoinPath("/loki/api/v1/query_range")
-
 	values := url.Values{}
 	values.Set("query", logQL)
 	values.Set("start", fmt.Sprintf("%d", start))
 	values.Set("end", fmt.Sprintf("%d", end))
 	values.Set("limit", fmt.Sprintf("%d", limit))
 
+	data, err := c.queryRange(ctx, values)
+	if err != nil {
+		return QueryRes{}, err
+	}
+
+	result := QueryRes{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		fmt.Println(string(data))
+		return QueryRes{}, fmt.Errorf("error parsing request response: %w", err)
+	}
+
+	return result, nil
+}
+
+// MetricRangeQuery runs a LogQL metric query, evaluating it every step nanoseconds between start and end.
+func (c *HttpLokiClient) MetricRangeQuery(ctx context.Context, logQL string, start, end, step int64) (MetricQueryRes, error) {
+	if start > end {
+		return MetricQueryRes{}, fmt.Errorf("start time cannot be after end time")
+	}
+	if step < 1 {
+		return MetricQueryRes{}, fmt.Errorf("step must be positive")
+	}
+
+	values := url.Values{}
+	values.Set("query", logQL)
+	values.Set("start", fmt.Sprintf("%d", start))
+	values.Set("end", fmt.Sprintf("%d", end))
+	// Loki accepts the step as a float number of seconds.
+	values.Set("step", strconv.FormatFloat(time.Duration(step).Seconds(), 'f', -1, 64))
+
+	data, err := c.queryRange(ctx, values)
+	if err != nil {
+		return MetricQueryRes{}, err
+	}
+
+	result := MetricQueryRes{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return MetricQueryRes{}, fmt.Errorf("error parsing request response: %w", err)
+	}
+	if result.Data.ResultType != "" && result.Data.ResultType != "matrix" {
+		return MetricQueryRes{}, fmt.Errorf("unexpected result type from loki: %s", result.Data.ResultType)
+	}
+
+	return result, nil
+}
+
+func (c *HttpLokiClient) queryRange(ctx context.Context, values url.Values) ([]byte, error) {
+	queryURL := c.cfg.ReadPathURL.JoinPath("/loki/api/v1/query_range")
 	queryURL.RawQuery = values.Encode()
 
 	req, err := http.NewRequest(http.MethodGet,
 		queryURL.String(), nil)
 	if err != nil {
-		return QueryRes{}, fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req = req.WithContext(ctx)
@@ -259,7 +320,7 @@ func (c *HttpLokiClient) RangeQuery(ctx context.Context, logQL string, start, en
 
 	res, err := c.client.Do(req)
 	if err != nil {
-		return QueryRes{}, fmt.Errorf("error executing request: %w", err)
+		return nil, fmt.Errorf("error executing request: %w", err)
 	}
 
 	defer func() {
@@ -268,7 +329,7 @@ func (c *HttpLokiClient) RangeQuery(ctx context.Context, logQL string, start, en
 
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
-		return QueryRes{}, fmt.Errorf("error reading request response: %w", err)
+		return nil, fmt.Errorf("error reading request response: %w", err)
 	}
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
@@ -277,17 +338,10 @@ func (c *HttpLokiClient) RangeQuery(ctx context.Context, logQL string, start, en
 		} else {
 			c.log.Error("Error response from Loki with an empty body", "status", res.StatusCode)
 		}
-		return QueryRes{}, fmt.Errorf("received a non-200 response from loki, status: %d", res.StatusCode)
-	}
-
-	result := QueryRes{}
-	err = json.Unmarshal(data, &result)
-	if err != nil {
-		fmt.Println(string(data))
-		return QueryRes{}, fmt.Errorf("error parsing request response: %w", err)
+		return nil, fmt.Errorf("received a non-200 response from loki, status: %d", res.StatusCode)
 	}
 
-	return result, nil
+	return data, nil
 }
 
 type QueryRes struct {
@@ -297,3 +351,48 @@ type QueryRes struct {
 type QueryData struct {
 	Result []Stream `json:"result"`
 }
+
+type MetricQueryRes struct {
+	Data MetricQueryData `json:"data"`
+}
+
+type MetricQueryData struct {
+	ResultType string         `json:"resultType"`
+	Result     []MetricSeries `json:"result"`
+}
+
+// MetricSeries is a single series of a Loki matrix result.
+type MetricSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []MetricSample    `json:"values"`
+}
+
+type MetricSample struct {
+	T time.Time
+	V float64
+}
+
+func (r *MetricSample) UnmarshalJSON(b []byte) error {
+	// A Loki matrix sample is formatted like a list with two elements, [At, Val]
+	// At is a number holding a timestamp in fractional unix epoch seconds.
+	// Val is a string wrapping the sample value.
+	var tuple [2]json.RawMessage
+	if err := json.Unmarshal(b, &tuple); err != nil {
+		return fmt.Errorf("failed to deserialize metric sample in Loki response: %w", err)
+	}
+	var sec float64
+	if err := json.Unmarshal(tuple[0], &sec); err != nil {
+		return fmt.Errorf("timestamp in Loki metric sample is not a number: %s", tuple[0])
+	}
+	var val string
+	if err := json.Unmarshal(tuple[1], &val); err != nil {
+		return fmt.Errorf("value in Loki metric sample is not a string: %s", tuple[1])
+	}
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fmt.Errorf("value in Loki metric sample is not a number: %v", val)
+	}
+	r.T = time.UnixMilli(int64(math.Round(sec * 1000)))
+	r.V = v
+	return nil
+}
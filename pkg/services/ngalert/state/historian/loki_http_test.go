@@ -104,6 +104,25 @@ func TestLokiConfig(t *testing.T) {
 		require.NoError(t, err)
 		require.Contains(t, res.ExternalLabels, "a")
 	})
+
+	t.Run("captures write buffer only if enabled", func(t *testing.T) {
+		set := setting.UnifiedAlertingStateHistorySettings{
+			LokiRemoteURL: "http://url.com",
+			LokiWriteBuffer: setting.UnifiedAlertingStateHistoryLokiBufferSettings{
+				MaxMemoryBytes: 100,
+				Path:           "/tmp/state-history",
+			},
+		}
+
+		res, err := NewLokiConfig(set)
+		require.NoError(t, err)
+		require.Nil(t, res.WriteBuffer)
+
+		set.LokiWriteBuffer.Enabled = true
+		res, err = NewLokiConfig(set)
+		require.NoError(t, err)
+		require.Equal(t, &LokiBufferConfig{MaxMemoryBytes: 100, Path: "/tmp/state-history"}, res.WriteBuffer)
+	})
 }
 
 func TestLokiHTTPClient(t *testing.T) {
@@ -396,6 +396,106 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestAggregateStateHistory(t *testing.T) {
+	t.Run("buildMetricQuery", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			query    models.HistoryQuery
+			interval time.Duration
+			exp      string
+		}{
+			{
+				name:     "counts all transitions without group by",
+				query:    models.HistoryQuery{OrgID: 123},
+				interval: 5 * time.Minute,
+				exp:      `sum(count_over_time({orgID="123",from="state-history"} [300s]))`,
+			},
+			{
+				name: "groups by the requested dimensions",
+				query: models.HistoryQuery{
+					OrgID:   123,
+					GroupBy: []models.HistoryGroupBy{models.HistoryGroupByRule, models.HistoryGroupByState},
+				},
+				interval: time.Minute,
+				exp:      `sum by (group_rule, group_state) (count_over_time({orgID="123",from="state-history"} | json group_rule="ruleUID", group_state="current" [60s]))`,
+			},
+			{
+				name: "keeps filters",
+				query: models.HistoryQuery{
+					OrgID:   123,
+					RuleUID: "rule-uid",
+					GroupBy: []models.HistoryGroupBy{models.HistoryGroupByLabels},
+				},
+				interval: time.Hour,
+				exp:      `sum by (group_labels) (count_over_time({orgID="123",from="state-history"} | json | ruleUID="rule-uid" | json group_labels="labels" [3600s]))`,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				res, err := buildMetricQuery(tc.query, tc.interval)
+				require.NoError(t, err)
+				require.Equal(t, tc.exp, res)
+			})
+		}
+
+		t.Run("rejects unknown group by", func(t *testing.T) {
+			_, err := buildMetricQuery(models.HistoryQuery{GroupBy: []models.HistoryGroupBy{"fingerprint"}}, time.Minute)
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("aggregationInterval", func(t *testing.T) {
+		from := time.Unix(0, 0)
+		require.Equal(t, defaultAggregationInterval, aggregationInterval(models.HistoryQuery{From: from, To: from.Add(time.Hour)}))
+		require.Equal(t, 2*time.Second, aggregationInterval(models.HistoryQuery{From: from, To: from.Add(time.Hour), Interval: 1500 * time.Millisecond}))
+		// 30 days in 1m buckets exceeds the number of points Loki returns.
+		require.Equal(t, 236*time.Second, aggregationInterval(models.HistoryQuery{From: from, To: from.Add(30 * 24 * time.Hour), Interval: time.Minute}))
+	})
+
+	t.Run("queries loki and builds a long frame", func(t *testing.T) {
+		body := `{"data": {"resultType": "matrix", "result": [
+			{"metric": {"group_rule": "b", "group_labels": "{\"a\":\"1\"}"}, "values": [[120, "2"], [180, "1"]]},
+			{"metric": {"group_rule": "a", "group_labels": "{\"a\":\"2\"}"}, "values": [[120, "4"]]}
+		]}}`
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:        "200 OK",
+			StatusCode:    200,
+			Body:          io.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Header:        make(http.Header, 0),
+		})
+		loki := createTestLokiBackend(req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		frame, err := loki.Query(context.Background(), models.HistoryQuery{
+			OrgID:    1,
+			From:     time.Unix(60, 0),
+			To:       time.Unix(180, 0),
+			GroupBy:  []models.HistoryGroupBy{models.HistoryGroupByRule, models.HistoryGroupByLabels},
+			Interval: time.Minute,
+		})
+		require.NoError(t, err)
+
+		params := req.lastRequest.URL.Query()
+		require.Equal(t, "/loki/api/v1/query_range", req.lastRequest.URL.Path)
+		require.Equal(t, "60", params.Get("step"))
+		require.Equal(t, fmt.Sprint(time.Unix(120, 0).UnixNano()), params.Get("start"))
+		require.Contains(t, params.Get("query"), "sum by (group_rule, group_labels)")
+
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, []string{dfTime, "ruleUID", "labels", dfCount}, []string{frame.Fields[0].Name, frame.Fields[1].Name, frame.Fields[2].Name, frame.Fields[3].Name})
+		require.Equal(t, 3, frame.Rows())
+		// Rows are sorted by bucket start, then by group, and timestamps are shifted to the start of the bucket.
+		require.Equal(t, time.Unix(60, 0).UTC(), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, "a", frame.Fields[1].At(0))
+		require.JSONEq(t, `{"a":"2"}`, string(frame.Fields[2].At(0).(json.RawMessage)))
+		require.Equal(t, 4.0, frame.Fields[3].At(0))
+		require.Equal(t, "b", frame.Fields[1].At(1))
+		require.Equal(t, time.Unix(120, 0).UTC(), frame.Fields[0].At(2).(time.Time).UTC())
+		require.Equal(t, 1.0, frame.Fields[3].At(2))
+	})
+}
+
 func TestRecordStates(t *testing.T) {
 	t.Run("writes state transitions to loki", func(t *testing.T) {
 		req := NewFakeRequester()
@@ -510,7 +610,8 @@ func createTestLokiBackend(req client.Requester, met *metrics.Historian) *Remote
 		Encoder:        JsonEncoder{},
 		ExternalLabels: map[string]string{"externalLabelKey": "externalLabelValue"},
 	}
-	return NewRemoteLokiBackend(cfg, req, met)
+	backend, _ := NewRemoteLokiBackend(cfg, req, met)
+	return backend
 }
 
 func singleFromNormal(st *state.State) []state.StateTransition {
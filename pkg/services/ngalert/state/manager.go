@@ -392,7 +392,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
 	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
-		image, err := takeImage(ctx, st.images, alertRule)
+		image, err := takeImage(ctx, st.images, alertRule, currentState)
 		if err != nil {
 			logger.Warn("Failed to take an image",
 				"dashboard", alertRule.GetDashboardUID(),
@@ -534,7 +534,7 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 
 		if oldState == eval.Alerting {
 			s.Resolved = true
-			image, err := takeImage(ctx, st.images, alertRule)
+			image, err := takeImage(ctx, st.images, alertRule, s)
 			if err != nil {
 				logger.Warn("Failed to take an image",
 					"dashboard", alertRule.GetDashboardUID(),
@@ -3,6 +3,7 @@ package state
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
 )
@@ -35,3 +36,9 @@ type Historian interface {
 type ImageCapturer interface {
 	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
 }
+
+// SummaryCardCapturer captures the summary cards of the alert instances of alert rules
+// that are not associated with a dashboard panel. An ImageCapturer can implement it.
+type SummaryCardCapturer interface {
+	NewSummaryCard(ctx context.Context, r *models.AlertRule, state eval.State, annotations map[string]string, values map[string]float64) (*models.Image, error)
+}
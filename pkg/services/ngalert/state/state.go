@@ -456,14 +456,21 @@ func shouldTakeImage(state, previousState eval.State, previousImage *models.Imag
 		state == eval.Alerting && previousImage == nil
 }
 
-// takeImage takes an image for the alert rule. It returns nil if screenshots are disabled or
-// the rule is not associated with a dashboard panel.
-func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule) (*models.Image, error) {
+// takeImage takes an image for the alert instance. When the rule is not associated with a
+// dashboard panel, it takes a summary card of the alert instance instead if s is a
+// SummaryCardCapturer. It returns nil if screenshots or summary cards are disabled, or the
+// rule is not associated with a dashboard panel and s cannot take summary cards.
+func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule, state *State) (*models.Image, error) {
 	img, err := s.NewImage(ctx, r)
+	if errors.Is(err, models.ErrNoDashboard) || errors.Is(err, models.ErrNoPanel) {
+		cards, ok := s.(SummaryCardCapturer)
+		if !ok {
+			return nil, nil
+		}
+		img, err = cards.NewSummaryCard(ctx, r, state.State, state.Annotations, state.Values)
+	}
 	if err != nil {
-		if errors.Is(err, screenshot.ErrScreenshotsUnavailable) ||
-			errors.Is(err, models.ErrNoDashboard) ||
-			errors.Is(err, models.ErrNoPanel) {
+		if errors.Is(err, screenshot.ErrScreenshotsUnavailable) {
 			return nil, nil
 		}
 		return nil, err
@@ -604,7 +604,7 @@ func TestTakeImage(t *testing.T) {
 		s := NewMockImageCapturer(ctrl)
 
 		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoDashboard)
-		image, err := takeImage(ctx, s, &r)
+		image, err := takeImage(ctx, s, &r, &State{})
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -618,7 +618,7 @@ func TestTakeImage(t *testing.T) {
 		s := NewMockImageCapturer(ctrl)
 
 		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoPanel)
-		image, err := takeImage(ctx, s, &r)
+		image, err := takeImage(ctx, s, &r, &State{})
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -632,7 +632,7 @@ func TestTakeImage(t *testing.T) {
 		s := NewMockImageCapturer(ctrl)
 
 		s.EXPECT().NewImage(ctx, &r).Return(nil, screenshot.ErrScreenshotsUnavailable)
-		image, err := takeImage(ctx, s, &r)
+		image, err := takeImage(ctx, s, &r, &State{})
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -646,11 +646,42 @@ func TestTakeImage(t *testing.T) {
 		s := NewMockImageCapturer(ctrl)
 
 		s.EXPECT().NewImage(ctx, &r).Return(nil, errors.New("unknown error"))
-		image, err := takeImage(ctx, s, &r)
+		image, err := takeImage(ctx, s, &r, &State{})
 		assert.EqualError(t, err, "unknown error")
 		assert.Nil(t, image)
 	})
 
+	t.Run("summary card should be returned when the rule has no panel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		r := ngmodels.AlertRule{DashboardUID: util.Pointer("foo")}
+		st := State{State: eval.Alerting, Annotations: map[string]string{"summary": "foo"}, Values: map[string]float64{"B": 1}}
+		s := &fakeSummaryCardCapturer{MockImageCapturer: NewMockImageCapturer(ctrl), image: &ngmodels.Image{Path: "card.png"}}
+
+		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoPanel)
+		image, err := takeImage(ctx, s, &r, &st)
+		assert.NoError(t, err)
+		require.NotNil(t, image)
+		assert.Equal(t, ngmodels.Image{Path: "card.png"}, *image)
+		assert.Equal(t, &st, s.state)
+	})
+
+	t.Run("summary card ErrScreenshotsUnavailable should return nil", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		r := ngmodels.AlertRule{}
+		s := &fakeSummaryCardCapturer{MockImageCapturer: NewMockImageCapturer(ctrl), err: screenshot.ErrScreenshotsUnavailable}
+
+		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoDashboard)
+		image, err := takeImage(ctx, s, &r, &State{})
+		assert.NoError(t, err)
+		assert.Nil(t, image)
+	})
+
 	t.Run("image should be returned", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -660,9 +691,21 @@ func TestTakeImage(t *testing.T) {
 		s := NewMockImageCapturer(ctrl)
 
 		s.EXPECT().NewImage(ctx, &r).Return(&ngmodels.Image{Path: "foo.png"}, nil)
-		image, err := takeImage(ctx, s, &r)
+		image, err := takeImage(ctx, s, &r, &State{})
 		assert.NoError(t, err)
 		require.NotNil(t, image)
 		assert.Equal(t, ngmodels.Image{Path: "foo.png"}, *image)
 	})
 }
+
+type fakeSummaryCardCapturer struct {
+	*MockImageCapturer
+	image *ngmodels.Image
+	err   error
+	state *State
+}
+
+func (f *fakeSummaryCardCapturer) NewSummaryCard(_ context.Context, _ *ngmodels.AlertRule, state eval.State, annotations map[string]string, values map[string]float64) (*ngmodels.Image, error) {
+	f.state = &State{State: state, Annotations: annotations, Values: values}
+	return f.image, f.err
+}
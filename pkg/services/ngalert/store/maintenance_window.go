@@ -0,0 +1,242 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MaintenanceWindowStore stores the maintenance windows of the organizations.
+type MaintenanceWindowStore interface {
+	// GetMaintenanceWindows returns the maintenance windows of the organization ordered by start time.
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error)
+	// GetMaintenanceWindow returns the maintenance window with the UID. It returns
+	// models.ErrMaintenanceWindowNotFound if the window does not exist.
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error)
+	InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	// UpdateMaintenanceWindow replaces the maintenance window with the same UID. It returns
+	// models.ErrMaintenanceWindowNotFound if the window does not exist.
+	UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+	// SaveMaintenanceWindowAnnotations stores the annotations of the maintenance window and the version of the window they were written for.
+	SaveMaintenanceWindowAnnotations(ctx context.Context, window *models.MaintenanceWindow) error
+}
+
+// maintenanceWindow is the database representation of models.MaintenanceWindow.
+type maintenanceWindow struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	UID     string `xorm:"uid"`
+	Title   string `xorm:"title"`
+	Comment string `xorm:"comment"`
+	// Matchers holds the matchers as a JSON list of [name, type, value] triples.
+	Matchers      string `xorm:"matchers"`
+	DashboardUIDs string `xorm:"dashboard_uids"`
+	StartsAt      int64  `xorm:"starts_at"`
+	EndsAt        int64  `xorm:"ends_at"`
+	Updated       int64  `xorm:"updated"`
+	// Annotations holds the annotation IDs by dashboard UID as a JSON object.
+	Annotations        string `xorm:"annotations"`
+	AnnotationsUpdated int64  `xorm:"annotations_updated"`
+}
+
+// maintenanceWindowColumns are the columns replaced when a maintenance window is updated.
+// The annotations are only written by SaveMaintenanceWindowAnnotations.
+var maintenanceWindowColumns = []string{"title", "comment", "matchers", "dashboard_uids", "starts_at", "ends_at", "updated"}
+
+func (maintenanceWindow) TableName() string {
+	return "alert_maintenance_window"
+}
+
+func (st DBstore) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	var rows []maintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("starts_at", "id").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance windows: %w", err)
+	}
+
+	result := make([]*models.MaintenanceWindow, 0, len(rows))
+	for _, row := range rows {
+		window, err := row.toModel()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, window)
+	}
+	return result, nil
+}
+
+func (st DBstore) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	var row maintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance window: %w", err)
+		}
+		if !exists {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row.toModel()
+}
+
+func (st DBstore) InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	row, err := maintenanceWindowFromModel(window)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&row); err != nil {
+			return fmt.Errorf("failed to insert maintenance window: %w", err)
+		}
+		window.ID = row.ID
+		return nil
+	})
+}
+
+func (st DBstore) UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	row, err := maintenanceWindowFromModel(window)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var existing maintenanceWindow
+		exists, err := sess.Where("org_id = ? AND uid = ?", window.OrgID, window.UID).Get(&existing)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance window: %w", err)
+		}
+		if !exists {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		row.ID = existing.ID
+		if _, err := sess.ID(existing.ID).Cols(maintenanceWindowColumns...).Update(&row); err != nil {
+			return fmt.Errorf("failed to update maintenance window: %w", err)
+		}
+		window.ID = row.ID
+		return nil
+	})
+}
+
+func (st DBstore) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&maintenanceWindow{}); err != nil {
+			return fmt.Errorf("failed to delete maintenance window: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) SaveMaintenanceWindowAnnotations(ctx context.Context, window *models.MaintenanceWindow) error {
+	annotations := window.Annotations
+	if annotations == nil {
+		annotations = map[string]int64{}
+	}
+	rawAnnotations, err := json.Marshal(annotations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance window annotations: %w", err)
+	}
+	row := maintenanceWindow{
+		Annotations:        string(rawAnnotations),
+		AnnotationsUpdated: window.AnnotationsUpdated.UnixMilli(),
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND uid = ?", window.OrgID, window.UID).Cols("annotations", "annotations_updated").Update(&row)
+		if err != nil {
+			return fmt.Errorf("failed to update maintenance window annotations: %w", err)
+		}
+		return nil
+	})
+}
+
+func maintenanceWindowFromModel(window *models.MaintenanceWindow) (maintenanceWindow, error) {
+	matchers := make([][3]string, 0, len(window.Matchers))
+	for _, m := range window.Matchers {
+		matchers = append(matchers, [3]string{m.Name, m.Type.String(), m.Value})
+	}
+	rawMatchers, err := json.Marshal(matchers)
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("failed to marshal maintenance window matchers: %w", err)
+	}
+	dashboardUIDs := window.DashboardUIDs
+	if dashboardUIDs == nil {
+		dashboardUIDs = []string{}
+	}
+	rawDashboardUIDs, err := json.Marshal(dashboardUIDs)
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("failed to marshal maintenance window dashboards: %w", err)
+	}
+	return maintenanceWindow{
+		OrgID:         window.OrgID,
+		UID:           window.UID,
+		Title:         window.Title,
+		Comment:       window.Comment,
+		Matchers:      string(rawMatchers),
+		DashboardUIDs: string(rawDashboardUIDs),
+		StartsAt:      window.StartsAt.UnixMilli(),
+		EndsAt:        window.EndsAt.UnixMilli(),
+		Updated:       window.Updated.UnixMilli(),
+		Annotations:   "{}",
+	}, nil
+}
+
+func (row maintenanceWindow) toModel() (*models.MaintenanceWindow, error) {
+	var rawMatchers [][3]string
+	if err := json.Unmarshal([]byte(row.Matchers), &rawMatchers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal matchers of maintenance window %s: %w", row.UID, err)
+	}
+	matchers := make(labels.Matchers, 0, len(rawMatchers))
+	for _, raw := range rawMatchers {
+		m, err := newMatcher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher of maintenance window %s: %w", row.UID, err)
+		}
+		matchers = append(matchers, m)
+	}
+	var dashboardUIDs []string
+	if row.DashboardUIDs != "" {
+		if err := json.Unmarshal([]byte(row.DashboardUIDs), &dashboardUIDs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dashboards of maintenance window %s: %w", row.UID, err)
+		}
+	}
+	annotations := map[string]int64{}
+	if row.Annotations != "" {
+		if err := json.Unmarshal([]byte(row.Annotations), &annotations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal annotations of maintenance window %s: %w", row.UID, err)
+		}
+	}
+	return &models.MaintenanceWindow{
+		ID:                 row.ID,
+		OrgID:              row.OrgID,
+		UID:                row.UID,
+		Title:              row.Title,
+		Comment:            row.Comment,
+		Matchers:           matchers,
+		DashboardUIDs:      dashboardUIDs,
+		StartsAt:           time.UnixMilli(row.StartsAt),
+		EndsAt:             time.UnixMilli(row.EndsAt),
+		Updated:            time.UnixMilli(row.Updated),
+		Annotations:        annotations,
+		AnnotationsUpdated: time.UnixMilli(row.AnnotationsUpdated),
+	}, nil
+}
+
+func newMatcher(raw [3]string) (*labels.Matcher, error) {
+	for _, t := range []labels.MatchType{labels.MatchEqual, labels.MatchNotEqual, labels.MatchRegexp, labels.MatchNotRegexp} {
+		if t.String() == raw[1] {
+			return labels.NewMatcher(t, raw[0], raw[2])
+		}
+	}
+	return nil, fmt.Errorf("unsupported match type %q", raw[1])
+}
@@ -0,0 +1,78 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationMaintenanceWindows(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	window := &models.MaintenanceWindow{
+		OrgID:         1,
+		UID:           "db-upgrade",
+		Title:         "Database upgrade",
+		Matchers:      labels.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "db"}},
+		DashboardUIDs: []string{"dash-1"},
+		StartsAt:      now,
+		EndsAt:        now.Add(time.Hour),
+		Updated:       now,
+	}
+	require.NoError(t, dbstore.InsertMaintenanceWindow(ctx, window))
+	require.NotZero(t, window.ID)
+
+	t.Run("returns the window", func(t *testing.T) {
+		res, err := dbstore.GetMaintenanceWindow(ctx, 1, "db-upgrade")
+		require.NoError(t, err)
+		require.Equal(t, "Database upgrade", res.Title)
+		require.Equal(t, window.Matchers.String(), res.Matchers.String())
+		require.Equal(t, []string{"dash-1"}, res.DashboardUIDs)
+		require.True(t, res.StartsAt.Equal(now))
+		require.Empty(t, res.Annotations)
+
+		_, err = dbstore.GetMaintenanceWindow(ctx, 2, "db-upgrade")
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("update keeps the annotations", func(t *testing.T) {
+		window.Annotations = map[string]int64{"dash-1": 10}
+		window.AnnotationsUpdated = window.Updated
+		require.NoError(t, dbstore.SaveMaintenanceWindowAnnotations(ctx, window))
+
+		window.Title = "Database migration"
+		window.Updated = now.Add(time.Minute)
+		require.NoError(t, dbstore.UpdateMaintenanceWindow(ctx, window))
+
+		res, err := dbstore.GetMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "Database migration", res[0].Title)
+		require.Equal(t, map[string]int64{"dash-1": 10}, res[0].Annotations)
+		require.True(t, res[0].AnnotationsUpdated.Equal(now))
+	})
+
+	t.Run("update of a missing window fails", func(t *testing.T) {
+		err := dbstore.UpdateMaintenanceWindow(ctx, &models.MaintenanceWindow{OrgID: 1, UID: "missing"})
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("delete removes the window", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteMaintenanceWindow(ctx, 1, "db-upgrade"))
+		res, err := dbstore.GetMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	defaultNotificationDeliveryLimit = 100
+	maximumNotificationDeliveryLimit = 1000
+)
+
+// NotificationDeliveryStore stores attempts to deliver notifications to contact points.
+type NotificationDeliveryStore interface {
+	SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+	GetNotificationDeliveries(ctx context.Context, query *models.NotificationDeliveryQuery) ([]*models.NotificationDelivery, error)
+}
+
+type NotificationDeliveryAdminStore interface {
+	NotificationDeliveryStore
+
+	// DeleteNotificationDeliveriesOlderThan deletes the notification delivery attempts made before t.
+	// It returns the number of deleted attempts or an error.
+	DeleteNotificationDeliveriesOlderThan(ctx context.Context, t time.Time) (int64, error)
+}
+
+// notificationDelivery is the database representation of models.NotificationDelivery.
+type notificationDelivery struct {
+	ID               int64  `xorm:"pk autoincr 'id'"`
+	OrgID            int64  `xorm:"org_id"`
+	Receiver         string `xorm:"receiver"`
+	IntegrationName  string `xorm:"integration_name"`
+	IntegrationType  string `xorm:"integration_type"`
+	IntegrationIndex int    `xorm:"integration_index"`
+	GroupKey         string `xorm:"group_key"`
+	// RuleUIDs holds the rule UIDs delimited by commas, including a leading and a trailing one,
+	// so that a single rule UID can be matched with LIKE.
+	RuleUIDs   string `xorm:"rule_uids"`
+	Alerts     int    `xorm:"alerts"`
+	Status     string `xorm:"status"`
+	Error      string `xorm:"error"`
+	Retry      int    `xorm:"retry"`
+	DurationMs int64  `xorm:"duration_ms"`
+	SentAt     int64  `xorm:"sent_at"`
+}
+
+func (notificationDelivery) TableName() string {
+	return "alert_notification_delivery"
+}
+
+func (st DBstore) SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	row := notificationDelivery{
+		OrgID:            delivery.OrgID,
+		Receiver:         delivery.Receiver,
+		IntegrationName:  delivery.IntegrationName,
+		IntegrationType:  delivery.IntegrationType,
+		IntegrationIndex: delivery.IntegrationIndex,
+		GroupKey:         delivery.GroupKey,
+		RuleUIDs:         "," + strings.Join(delivery.RuleUIDs, ",") + ",",
+		Alerts:           delivery.Alerts,
+		Status:           string(delivery.Status),
+		Error:            delivery.Error,
+		Retry:            delivery.Retry,
+		DurationMs:       delivery.Duration.Milliseconds(),
+		SentAt:           delivery.SentAt.UnixMilli(),
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&row); err != nil {
+			return fmt.Errorf("failed to insert notification delivery: %w", err)
+		}
+		delivery.ID = row.ID
+		return nil
+	})
+}
+
+func (st DBstore) GetNotificationDeliveries(ctx context.Context, query *models.NotificationDeliveryQuery) ([]*models.NotificationDelivery, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultNotificationDeliveryLimit
+	}
+	if limit > maximumNotificationDeliveryLimit {
+		limit = maximumNotificationDeliveryLimit
+	}
+
+	var rows []notificationDelivery
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.Receiver != "" {
+			q = q.And("receiver = ?", query.Receiver)
+		}
+		if query.RuleUID != "" {
+			q = q.And("rule_uids LIKE ? ESCAPE '!'", "%,"+escapeLike(query.RuleUID)+",%")
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", string(query.Status))
+		}
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("sent_at <= ?", query.To.UnixMilli())
+		}
+		return q.Desc("sent_at", "id").Limit(limit).Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+
+	result := make([]*models.NotificationDelivery, 0, len(rows))
+	for _, row := range rows {
+		ruleUIDs := []string{}
+		for _, uid := range strings.Split(row.RuleUIDs, ",") {
+			if uid != "" {
+				ruleUIDs = append(ruleUIDs, uid)
+			}
+		}
+		result = append(result, &models.NotificationDelivery{
+			ID:               row.ID,
+			OrgID:            row.OrgID,
+			Receiver:         row.Receiver,
+			IntegrationName:  row.IntegrationName,
+			IntegrationType:  row.IntegrationType,
+			IntegrationIndex: row.IntegrationIndex,
+			GroupKey:         row.GroupKey,
+			RuleUIDs:         ruleUIDs,
+			Alerts:           row.Alerts,
+			Status:           models.NotificationDeliveryStatus(row.Status),
+			Error:            row.Error,
+			Retry:            row.Retry,
+			Duration:         time.Duration(row.DurationMs) * time.Millisecond,
+			SentAt:           time.UnixMilli(row.SentAt),
+		})
+	}
+	return result, nil
+}
+
+// DeleteNotificationDeliveriesOlderThan deletes the notification delivery attempts made before t.
+// It returns the number of deleted attempts.
+func (st DBstore) DeleteNotificationDeliveriesOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	var n int64
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("sent_at < ?", t.UnixMilli()).Delete(&notificationDelivery{})
+		if err != nil {
+			return fmt.Errorf("failed to delete notification deliveries: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
+
+// escapeLike escapes the LIKE wildcards of s, using ! as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
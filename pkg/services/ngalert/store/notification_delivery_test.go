@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationNotificationDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	deliveries := []*models.NotificationDelivery{
+		{OrgID: 1, Receiver: "ops", IntegrationType: "slack", RuleUIDs: []string{"rule_a", "rule-b"}, Status: models.NotificationDeliveryFailed, Error: "timeout", Duration: 2 * time.Second, SentAt: now.Add(-3 * time.Minute)},
+		{OrgID: 1, Receiver: "ops", IntegrationType: "slack", RuleUIDs: []string{"rule_a", "rule-b"}, Status: models.NotificationDeliverySuccess, Retry: 1, Duration: time.Second, SentAt: now.Add(-2 * time.Minute)},
+		{OrgID: 1, Receiver: "dev", IntegrationType: "email", RuleUIDs: []string{"rule-c"}, Status: models.NotificationDeliverySuccess, SentAt: now.Add(-time.Minute)},
+		{OrgID: 2, Receiver: "ops", IntegrationType: "email", RuleUIDs: []string{"rule_a"}, Status: models.NotificationDeliverySuccess, SentAt: now},
+	}
+	for _, d := range deliveries {
+		require.NoError(t, dbstore.SaveNotificationDelivery(ctx, d))
+		require.NotZero(t, d.ID)
+	}
+
+	t.Run("returns deliveries of the org newest first", func(t *testing.T) {
+		res, err := dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		require.Equal(t, deliveries[2].ID, res[0].ID)
+		require.Equal(t, deliveries[0].ID, res[2].ID)
+		require.Equal(t, []string{"rule_a", "rule-b"}, res[2].RuleUIDs)
+		require.Equal(t, "timeout", res[2].Error)
+		require.Equal(t, 2*time.Second, res[2].Duration)
+		require.Equal(t, now.Add(-3*time.Minute).UnixMilli(), res[2].SentAt.UnixMilli())
+	})
+
+	t.Run("filters by rule and contact point", func(t *testing.T) {
+		res, err := dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, RuleUID: "rule_a"})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+
+		// the underscore must not act as a wildcard
+		res, err = dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, RuleUID: "rule_c"})
+		require.NoError(t, err)
+		require.Empty(t, res)
+
+		res, err = dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, Receiver: "dev"})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, deliveries[2].ID, res[0].ID)
+	})
+
+	t.Run("filters by status, time range and limit", func(t *testing.T) {
+		res, err := dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, Status: models.NotificationDeliveryFailed})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, 0, res[0].Retry)
+
+		res, err = dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, From: now.Add(-150 * time.Second), To: now.Add(-90 * time.Second)})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, deliveries[1].ID, res[0].ID)
+
+		res, err = dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("deletes deliveries older than retention", func(t *testing.T) {
+		n, err := dbstore.DeleteNotificationDeliveriesOlderThan(ctx, now.Add(-90*time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		res, err := dbstore.GetNotificationDeliveries(ctx, &models.NotificationDeliveryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+}
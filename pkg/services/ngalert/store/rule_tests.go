@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleTestStore stores the unit tests of the rule groups.
+type RuleTestStore interface {
+	// GetAlertRuleTests returns the tests of the rule group ordered by title.
+	GetAlertRuleTests(ctx context.Context, key models.AlertRuleGroupKey) ([]*models.AlertRuleTest, error)
+	// ListAlertRuleTests returns the tests of all the rule groups of the organization.
+	ListAlertRuleTests(ctx context.Context, orgID int64) ([]*models.AlertRuleTest, error)
+	// ReplaceAlertRuleTests replaces the tests of the rule group.
+	ReplaceAlertRuleTests(ctx context.Context, key models.AlertRuleGroupKey, tests []*models.AlertRuleTest) error
+}
+
+// alertRuleTest is the database representation of models.AlertRuleTest.
+type alertRuleTest struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	UID          string `xorm:"uid"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	RuleGroup    string `xorm:"rule_group"`
+	Title        string `xorm:"title"`
+	// Data holds the interval, the input series and the expectations of the test as a JSON object.
+	Data string `xorm:"data"`
+	// The column name is quoted because updated is an xorm keyword that would overwrite the value.
+	Updated int64 `xorm:"'updated'"`
+}
+
+func (alertRuleTest) TableName() string {
+	return "alert_rule_test"
+}
+
+type alertRuleTestData struct {
+	IntervalMs   int64                          `json:"intervalMs,omitempty"`
+	InputSeries  []alertRuleTestSeriesData      `json:"inputSeries"`
+	Expectations []alertRuleTestExpectationData `json:"expectations"`
+}
+
+type alertRuleTestSeriesData struct {
+	RuleUID string            `json:"ruleUid,omitempty"`
+	RefID   string            `json:"refId"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Values  []*float64        `json:"values"`
+}
+
+type alertRuleTestExpectationData struct {
+	RuleUID string            `json:"ruleUid"`
+	AtMs    int64             `json:"atMs"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	State   string            `json:"state"`
+}
+
+func (st DBstore) GetAlertRuleTests(ctx context.Context, key models.AlertRuleGroupKey) ([]*models.AlertRuleTest, error) {
+	var rows []alertRuleTest
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND namespace_uid = ? AND rule_group = ?", key.OrgID, key.NamespaceUID, key.RuleGroup).
+			Asc("title", "id").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rule tests: %w", err)
+	}
+	return alertRuleTestsToModels(rows)
+}
+
+func (st DBstore) ListAlertRuleTests(ctx context.Context, orgID int64) ([]*models.AlertRuleTest, error) {
+	var rows []alertRuleTest
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("namespace_uid", "rule_group", "title", "id").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rule tests: %w", err)
+	}
+	return alertRuleTestsToModels(rows)
+}
+
+func (st DBstore) ReplaceAlertRuleTests(ctx context.Context, key models.AlertRuleGroupKey, tests []*models.AlertRuleTest) error {
+	rows := make([]alertRuleTest, 0, len(tests))
+	for _, test := range tests {
+		row, err := alertRuleTestFromModel(key, test)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	return st.SQLStore.InTransaction(ctx, func(ctx context.Context) error {
+		return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Where("org_id = ? AND namespace_uid = ? AND rule_group = ?", key.OrgID, key.NamespaceUID, key.RuleGroup).
+				Delete(&alertRuleTest{})
+			if err != nil {
+				return fmt.Errorf("failed to delete alert rule tests: %w", err)
+			}
+			for i := range rows {
+				if _, err := sess.Insert(&rows[i]); err != nil {
+					return fmt.Errorf("failed to insert alert rule test: %w", err)
+				}
+				tests[i].ID = rows[i].ID
+			}
+			return nil
+		})
+	})
+}
+
+func alertRuleTestFromModel(key models.AlertRuleGroupKey, test *models.AlertRuleTest) (alertRuleTest, error) {
+	data := alertRuleTestData{
+		IntervalMs:   test.Interval.Milliseconds(),
+		InputSeries:  make([]alertRuleTestSeriesData, 0, len(test.InputSeries)),
+		Expectations: make([]alertRuleTestExpectationData, 0, len(test.Expectations)),
+	}
+	for _, s := range test.InputSeries {
+		data.InputSeries = append(data.InputSeries, alertRuleTestSeriesData{
+			RuleUID: s.RuleUID,
+			RefID:   s.RefID,
+			Labels:  s.Labels,
+			Values:  s.Values,
+		})
+	}
+	for _, e := range test.Expectations {
+		data.Expectations = append(data.Expectations, alertRuleTestExpectationData{
+			RuleUID: e.RuleUID,
+			AtMs:    e.At.Milliseconds(),
+			Labels:  e.Labels,
+			State:   e.State,
+		})
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return alertRuleTest{}, fmt.Errorf("failed to marshal alert rule test: %w", err)
+	}
+	return alertRuleTest{
+		OrgID:        key.OrgID,
+		UID:          test.UID,
+		NamespaceUID: key.NamespaceUID,
+		RuleGroup:    key.RuleGroup,
+		Title:        test.Title,
+		Data:         string(raw),
+		Updated:      test.Updated.UnixMilli(),
+	}, nil
+}
+
+func alertRuleTestsToModels(rows []alertRuleTest) ([]*models.AlertRuleTest, error) {
+	result := make([]*models.AlertRuleTest, 0, len(rows))
+	for _, row := range rows {
+		test, err := row.toModel()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, test)
+	}
+	return result, nil
+}
+
+func (row alertRuleTest) toModel() (*models.AlertRuleTest, error) {
+	var data alertRuleTestData
+	if err := json.Unmarshal([]byte(row.Data), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alert rule test %s: %w", row.UID, err)
+	}
+	test := &models.AlertRuleTest{
+		ID:           row.ID,
+		OrgID:        row.OrgID,
+		UID:          row.UID,
+		NamespaceUID: row.NamespaceUID,
+		RuleGroup:    row.RuleGroup,
+		Title:        row.Title,
+		Interval:     time.Duration(data.IntervalMs) * time.Millisecond,
+		InputSeries:  make([]models.AlertRuleTestSeries, 0, len(data.InputSeries)),
+		Expectations: make([]models.AlertRuleTestExpectation, 0, len(data.Expectations)),
+		Updated:      time.UnixMilli(row.Updated),
+	}
+	for _, s := range data.InputSeries {
+		test.InputSeries = append(test.InputSeries, models.AlertRuleTestSeries{
+			RuleUID: s.RuleUID,
+			RefID:   s.RefID,
+			Labels:  s.Labels,
+			Values:  s.Values,
+		})
+	}
+	for _, e := range data.Expectations {
+		test.Expectations = append(test.Expectations, models.AlertRuleTestExpectation{
+			RuleUID: e.RuleUID,
+			At:      time.Duration(e.AtMs) * time.Millisecond,
+			Labels:  e.Labels,
+			State:   e.State,
+		})
+	}
+	return test, nil
+}
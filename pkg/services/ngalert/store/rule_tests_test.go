@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertRuleTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	key := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+	other := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "other"}
+	value := 90.0
+	now := time.Now().Truncate(time.Millisecond)
+	newTest := func(uid, title string) *models.AlertRuleTest {
+		return &models.AlertRuleTest{
+			UID:      uid,
+			Title:    title,
+			Interval: time.Minute,
+			InputSeries: []models.AlertRuleTestSeries{
+				{RefID: "A", Labels: map[string]string{"instance": "a"}, Values: []*float64{nil, &value}},
+			},
+			Expectations: []models.AlertRuleTestExpectation{
+				{RuleUID: "rule", At: time.Minute, Labels: map[string]string{"instance": "a"}, State: "Alerting"},
+			},
+			Updated: now,
+		}
+	}
+
+	require.NoError(t, dbstore.ReplaceAlertRuleTests(ctx, key, []*models.AlertRuleTest{newTest("t2", "b"), newTest("t1", "a")}))
+	require.NoError(t, dbstore.ReplaceAlertRuleTests(ctx, other, []*models.AlertRuleTest{newTest("t3", "c")}))
+
+	t.Run("returns the tests of the group ordered by title", func(t *testing.T) {
+		res, err := dbstore.GetAlertRuleTests(ctx, key)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "t1", res[0].UID)
+		require.Equal(t, "t2", res[1].UID)
+		require.Equal(t, key.NamespaceUID, res[0].NamespaceUID)
+		require.Equal(t, time.Minute, res[0].Interval)
+		require.Nil(t, res[0].InputSeries[0].Values[0])
+		require.Equal(t, value, *res[0].InputSeries[0].Values[1])
+		require.Equal(t, newTest("t1", "a").Expectations, res[0].Expectations)
+		require.True(t, res[0].Updated.Equal(now))
+	})
+
+	t.Run("replace removes the tests that are not in the new list", func(t *testing.T) {
+		require.NoError(t, dbstore.ReplaceAlertRuleTests(ctx, key, []*models.AlertRuleTest{newTest("t2", "b")}))
+
+		res, err := dbstore.GetAlertRuleTests(ctx, key)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "t2", res[0].UID)
+
+		res, err = dbstore.ListAlertRuleTests(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+
+		res, err = dbstore.ListAlertRuleTests(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var ErrDeliveryEventsNotSupported = errors.New("the email provider does not report delivery events")
+var ErrInvalidDeliveryEvents = errors.New("invalid delivery events")
+
+var emailDeliveryEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name:      "email_delivery_events_total",
+	Help:      "Number of delivery events of the emails reported by the email provider",
+	Namespace: "grafana",
+}, []string{"event"})
+
+// DeliveryEventType is the status of the delivery of an email reported by
+// the email provider.
+type DeliveryEventType string
+
+const (
+	DeliveryEventDelivered  DeliveryEventType = "delivered"
+	DeliveryEventDeferred   DeliveryEventType = "deferred"
+	DeliveryEventBounced    DeliveryEventType = "bounced"
+	DeliveryEventDropped    DeliveryEventType = "dropped"
+	DeliveryEventComplained DeliveryEventType = "complained"
+)
+
+// DeliveryEvent is the delivery of an email to a recipient.
+type DeliveryEvent struct {
+	Type      DeliveryEventType
+	Recipient string
+	// Permanent is true for the bounces the provider will not retry, for
+	// example when the address does not exist.
+	Permanent bool
+	Reason    string
+}
+
+// DeliveryEventsParser is implemented by the mailers of the providers that
+// report the delivery of the emails with webhooks.
+type DeliveryEventsParser interface {
+	ParseDeliveryEvents(r *http.Request) ([]DeliveryEvent, error)
+}
+
+// HandleDeliveryWebhook handles the request of the delivery events webhook
+// of the email provider. The emails of the users that permanently bounce or
+// are reported as spam are marked as not verified.
+func (ns *NotificationService) HandleDeliveryWebhook(r *http.Request) error {
+	parser, ok := ns.mailer.(DeliveryEventsParser)
+	if !ok {
+		return ErrDeliveryEventsNotSupported
+	}
+	events, err := parser.ParseDeliveryEvents(r)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDeliveryEvents, err)
+	}
+
+	ctx := r.Context()
+	for _, event := range events {
+		emailDeliveryEvents.WithLabelValues(string(event.Type)).Inc()
+		switch {
+		case event.Type == DeliveryEventBounced && event.Permanent, event.Type == DeliveryEventComplained:
+			ns.log.Info("Email rejected by its recipient", "event", event.Type, "recipient", event.Recipient, "reason", event.Reason)
+			if err := ns.markEmailInvalid(ctx, event.Recipient); err != nil {
+				ns.log.Error("Failed to mark the email of the user as not verified", "recipient", event.Recipient, "error", err)
+			}
+		case event.Type == DeliveryEventBounced, event.Type == DeliveryEventDropped:
+			ns.log.Warn("Email not delivered", "event", event.Type, "recipient", event.Recipient, "reason", event.Reason)
+		default:
+			ns.log.Debug("Email delivery event", "event", event.Type, "recipient", event.Recipient)
+		}
+	}
+	return nil
+}
+
+func (ns *NotificationService) markEmailInvalid(ctx context.Context, email string) error {
+	if ns.userService == nil || email == "" {
+		return nil
+	}
+	usr, err := ns.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: email})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !usr.EmailVerified {
+		return nil
+	}
+	return ns.userService.SetEmailVerified(ctx, &user.SetEmailVerifiedCommand{UserID: usr.ID, EmailVerified: false})
+}
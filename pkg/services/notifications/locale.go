@@ -0,0 +1,188 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/sprig/v3"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+var pluralForms = map[string]plural.Form{
+	"other": plural.Other,
+	"zero":  plural.Zero,
+	"one":   plural.One,
+	"two":   plural.Two,
+	"few":   plural.Few,
+	"many":  plural.Many,
+}
+
+// newMailTemplates returns the templates of the emails of a language, with the
+// template functions.
+func newMailTemplates(tag language.Tag) *template.Template {
+	t := template.New("name")
+	t.Funcs(template.FuncMap{
+		"Subject":                 subjectTemplateFunc,
+		"HiddenSubject":           hiddenSubjectTemplateFunc,
+		"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
+		"Plural":                  pluralTemplateFunc(tag),
+	})
+	t.Funcs(sprig.FuncMap())
+	return t
+}
+
+// pluralTemplateFunc returns the Plural template function, which selects the
+// text of the plural form of the count in the language, for example
+//
+//	{{ Plural .AlertCount "one" "# alert is firing" "other" "# alerts are firing" }}
+//
+// The forms are zero, one, two, few, many and other, and # is replaced with
+// the count. The text of the other form is used for the forms that are not set.
+func pluralTemplateFunc(tag language.Tag) func(count any, forms ...string) (string, error) {
+	return func(count any, forms ...string) (string, error) {
+		if len(forms)%2 != 0 {
+			return "", fmt.Errorf("plural forms must be pairs of form and text")
+		}
+		n, err := strconv.Atoi(fmt.Sprint(count))
+		if err != nil {
+			return "", fmt.Errorf("invalid plural count %v", count)
+		}
+
+		texts := make(map[plural.Form]string, len(forms)/2)
+		for i := 0; i < len(forms); i += 2 {
+			form, ok := pluralForms[forms[i]]
+			if !ok {
+				return "", fmt.Errorf("unknown plural form %q", forms[i])
+			}
+			texts[form] = forms[i+1]
+		}
+
+		abs := n
+		if abs < 0 {
+			abs = -abs
+		}
+		text, ok := texts[plural.Cardinal.MatchPlural(tag, abs, 0, 0, 0, 0)]
+		if !ok {
+			text = texts[plural.Other]
+		}
+		return strings.ReplaceAll(text, "#", strconv.Itoa(n)), nil
+	}
+}
+
+// RegisterTemplatePack registers the email templates of a locale, for example
+// de-DE, parsed from the files of fsys matching the patterns. The templates
+// have the names of the templates they translate, and the default templates
+// are used for the emails missing from the pack.
+func (ns *NotificationService) RegisterTemplatePack(locale string, fsys fs.FS, patterns ...string) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+	templates, err := newMailTemplates(tag).ParseFS(fsys, patterns...)
+	if err != nil {
+		return err
+	}
+
+	ns.templatePacksMu.Lock()
+	defer ns.templatePacksMu.Unlock()
+	ns.templatePacks[tag.String()] = templates
+	return nil
+}
+
+// loadTemplatePacks registers the template packs of the subdirectories of the
+// locales path, named after their locale, with the files matching the
+// templates patterns.
+func (ns *NotificationService) loadTemplatePacks() error {
+	if ns.Cfg.Smtp.TemplatesLocalesPath == "" {
+		return nil
+	}
+	dir := filepath.Join(ns.Cfg.StaticRootPath, ns.Cfg.Smtp.TemplatesLocalesPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		fsys := os.DirFS(filepath.Join(dir, entry.Name()))
+		patterns := make([]string, 0, len(ns.Cfg.Smtp.TemplatesPatterns))
+		for _, pattern := range ns.Cfg.Smtp.TemplatesPatterns {
+			pattern = filepath.Base(pattern)
+			if matches, _ := fs.Glob(fsys, pattern); len(matches) > 0 {
+				patterns = append(patterns, pattern)
+			}
+		}
+		if len(patterns) == 0 {
+			continue
+		}
+		if err := ns.RegisterTemplatePack(entry.Name(), fsys, patterns...); err != nil {
+			return fmt.Errorf("failed to load the email templates of %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// templates returns the templates of the email for the locale. The pack of the
+// locale is used first, then the packs of its parent languages, for example
+// de-DE then de, and the default templates last. A pack is only used when it
+// has the templates of all the names, so that the parts of the email are in
+// the same language.
+func (ns *NotificationService) templates(locale string, names ...string) *template.Template {
+	if locale == "" {
+		return mailTemplates
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		ns.log.Debug("Invalid email locale", "locale", locale, "error", err)
+		return mailTemplates
+	}
+
+	ns.templatePacksMu.RLock()
+	defer ns.templatePacksMu.RUnlock()
+	for ; tag != language.Und; tag = tag.Parent() {
+		if pack, ok := ns.templatePacks[tag.String()]; ok && hasTemplates(pack, names) {
+			return pack
+		}
+	}
+	return mailTemplates
+}
+
+func hasTemplates(t *template.Template, names []string) bool {
+	for _, name := range names {
+		if t.Lookup(name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// locale returns the locale of the email: the locale of the command, or the
+// language of the preferences of its recipient.
+func (ns *NotificationService) locale(ctx context.Context, cmd *SendEmailCommand) string {
+	if cmd.Locale != "" || cmd.OrgID == 0 || ns.prefService == nil {
+		return cmd.Locale
+	}
+	prefs, err := ns.prefService.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: cmd.OrgID, UserID: cmd.UserID})
+	if err != nil {
+		ns.log.Warn("Failed to get the preferences of the email recipient", "orgId", cmd.OrgID, "userId", cmd.UserID, "error", err)
+		return ""
+	}
+	if prefs.JSONData == nil {
+		return ""
+	}
+	return prefs.JSONData.Language
+}
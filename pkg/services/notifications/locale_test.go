@@ -0,0 +1,99 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/preference/preftest"
+)
+
+func TestTemplatePacks(t *testing.T) {
+	bus := newBus(t)
+	pack := fstest.MapFS{
+		"welcome_on_signup.html": {Data: []byte(`{{ HiddenSubject .Subject "Willkommen" }}Hallo {{ .Name }}, {{ Plural .Count "one" "# Alarm" "other" "# Alarme" }}`)},
+		"welcome_on_signup.txt":  {Data: []byte(`{{ HiddenSubject .Subject "Willkommen" }}Hallo {{ .Name }}`)},
+		"reset_password.html":    {Data: []byte(`{{ HiddenSubject .Subject "Passwort" }}`)},
+	}
+
+	send := func(t *testing.T, ns *NotificationService, mailer *FakeMailer, cmd SendEmailCommand) *Message {
+		t.Helper()
+		cmd.To = []string{"asdf@grafana.com"}
+		cmd.Template = "welcome_on_signup"
+		cmd.Data = map[string]any{"Name": "Ada", "Count": 2}
+		require.NoError(t, ns.SendEmailCommandHandlerSync(context.Background(), &SendEmailCommandSync{SendEmailCommand: cmd}))
+		require.NotEmpty(t, mailer.Sent)
+		return mailer.Sent[len(mailer.Sent)-1]
+	}
+
+	t.Run("uses the pack of the language of the locale", func(t *testing.T) {
+		ns, mailer := createSut(t, bus)
+		require.NoError(t, ns.RegisterTemplatePack("de", pack, "*.html", "*.txt"))
+
+		sent := send(t, ns, mailer, SendEmailCommand{Locale: "de-AT"})
+		assert.Equal(t, "Willkommen", sent.Subject)
+		assert.Equal(t, "Hallo Ada, 2 Alarme", sent.Body["text/html"])
+		assert.Equal(t, "Hallo Ada", sent.Body["text/plain"])
+	})
+
+	t.Run("uses the default templates without a pack", func(t *testing.T) {
+		ns, mailer := createSut(t, bus)
+		require.NoError(t, ns.RegisterTemplatePack("de", pack, "*.html", "*.txt"))
+
+		sent := send(t, ns, mailer, SendEmailCommand{Locale: "fr-FR"})
+		assert.Equal(t, "Welcome to Grafana", sent.Subject)
+	})
+
+	t.Run("uses the default templates when the pack misses a content type", func(t *testing.T) {
+		ns, mailer := createSut(t, bus)
+		require.NoError(t, ns.RegisterTemplatePack("de", pack, "*.html", "*.txt"))
+
+		err := ns.SendEmailCommandHandlerSync(context.Background(), &SendEmailCommandSync{SendEmailCommand: SendEmailCommand{
+			To: []string{"asdf@grafana.com"}, Template: "reset_password", Locale: "de",
+			Data: map[string]any{"Name": "Ada", "Code": "code"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "Reset your Grafana password - Ada", mailer.Sent[len(mailer.Sent)-1].Subject)
+	})
+
+	t.Run("uses the language of the preferences of the recipient", func(t *testing.T) {
+		ns, mailer := createSut(t, bus)
+		require.NoError(t, ns.RegisterTemplatePack("de-DE", pack, "*.html", "*.txt"))
+		ns.prefService = &preftest.FakePreferenceService{ExpectedPreference: &pref.Preference{
+			JSONData: &pref.PreferenceJSONData{Language: "de-DE"},
+		}}
+
+		sent := send(t, ns, mailer, SendEmailCommand{OrgID: 1, UserID: 2})
+		assert.Equal(t, "Willkommen", sent.Subject)
+	})
+
+	t.Run("rejects invalid locales", func(t *testing.T) {
+		ns, _ := createSut(t, bus)
+		require.Error(t, ns.RegisterTemplatePack("not a locale", pack, "*.html"))
+	})
+}
+
+func TestPluralTemplateFunc(t *testing.T) {
+	forms := []string{"one", "# plik", "few", "# pliki", "many", "# plików", "other", "# pliku"}
+	polish := pluralTemplateFunc(language.Polish)
+	for count, expected := range map[int]string{1: "1 plik", 3: "3 pliki", 5: "5 plików", 22: "22 pliki"} {
+		text, err := polish(count, forms...)
+		require.NoError(t, err)
+		assert.Equal(t, expected, text)
+	}
+
+	english := pluralTemplateFunc(language.English)
+	text, err := english(0, "one", "# alert", "other", "# alerts")
+	require.NoError(t, err)
+	assert.Equal(t, "0 alerts", text)
+
+	_, err = english(1, "one")
+	require.Error(t, err)
+	_, err = english(1, "single", "# alert")
+	require.Error(t, err)
+}
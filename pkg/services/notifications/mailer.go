@@ -6,6 +6,7 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"net/mail"
@@ -53,7 +54,7 @@ func (ns *NotificationService) Send(msg *Message) (int, error) {
 	return ns.mailer.Send(messages...)
 }
 
-func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Message, error) {
+func (ns *NotificationService) buildEmailMessage(ctx context.Context, cmd *SendEmailCommand) (*Message, error) {
 	if !ns.Cfg.Smtp.Enabled {
 		return nil, ErrSmtpNotEnabled
 	}
@@ -63,16 +64,24 @@ func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Messag
 		data = make(map[string]any, 10)
 	}
 
+	locale := ns.locale(ctx, cmd)
+	data["Locale"] = locale
 	setDefaultTemplateData(ns.Cfg, data, nil)
 
-	body := make(map[string]string)
+	names := make([]string, 0, len(ns.Cfg.Smtp.ContentTypes))
 	for _, contentType := range ns.Cfg.Smtp.ContentTypes {
 		fileExtension, err := getFileExtensionByContentType(contentType)
 		if err != nil {
 			return nil, err
 		}
+		names = append(names, cmd.Template+fileExtension)
+	}
+	templates := ns.templates(locale, names...)
+
+	body := make(map[string]string)
+	for i, contentType := range ns.Cfg.Smtp.ContentTypes {
 		var buffer bytes.Buffer
-		err = mailTemplates.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
+		err := templates.ExecuteTemplate(&buffer, names[i], data)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const mailgunURL = "https://api.mailgun.net"
+
+// MailgunClient sends the emails with the messages API of Mailgun. The
+// messages that only differ by their recipients are sent with one batch
+// request.
+type MailgunClient struct {
+	cfg    setting.SmtpSettings
+	url    string
+	client *http.Client
+}
+
+func NewMailgunClient(cfg setting.SmtpSettings) (*MailgunClient, error) {
+	u := cfg.APIURL
+	if u == "" {
+		u = mailgunURL
+	}
+	return &MailgunClient{cfg: cfg, url: u, client: newAPIClient()}, nil
+}
+
+func (c *MailgunClient) Send(messages ...*Message) (int, error) {
+	return sendBatches(messages, c.send)
+}
+
+func (c *MailgunClient) send(batch []*Message) error {
+	body, contentType, err := c.buildForm(batch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url+"/v3/"+url.PathEscape(c.cfg.MailgunDomain)+"/messages", body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", c.cfg.APIKey)
+	req.Header.Set("Content-Type", contentType)
+	return doAPIRequest(c.client, req)
+}
+
+// buildForm converts the batch to the multipart form of a message. The
+// recipient variables of the batches make Mailgun send a message to each
+// recipient.
+func (c *MailgunClient) buildForm(batch []*Message) (*bytes.Buffer, string, error) {
+	msg := batch[0]
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	fields := [][2]string{{"from", msg.From}, {"subject", msg.Subject}}
+	for _, to := range recipients(batch) {
+		fields = append(fields, [2]string{"to", to})
+	}
+	if len(msg.ReplyTo) > 0 {
+		fields = append(fields, [2]string{"h:Reply-To", strings.Join(msg.ReplyTo, ", ")})
+	}
+	for _, contentType := range c.cfg.ContentTypes {
+		switch contentType {
+		case "text/html":
+			fields = append(fields, [2]string{"html", msg.Body[contentType]})
+		case "text/plain":
+			fields = append(fields, [2]string{"text", msg.Body[contentType]})
+		}
+	}
+	if !msg.SingleEmail && len(batch) > 1 {
+		variables := make(map[string]map[string]string, len(batch))
+		for _, to := range recipients(batch) {
+			variables[to] = map[string]string{}
+		}
+		encoded, err := json.Marshal(variables)
+		if err != nil {
+			return nil, "", err
+		}
+		fields = append(fields, [2]string{"recipient-variables", string(encoded)})
+	}
+	for _, field := range fields {
+		if err := w.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	files, err := attachments(msg)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, file := range files {
+		field := "attachment"
+		if file.inline {
+			field = "inline"
+		}
+		fw, err := w.CreateFormFile(field, file.name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := fw.Write(file.content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, w.FormDataContentType(), nil
+}
+
+type mailgunEvent struct {
+	EventData struct {
+		Event          string `json:"event"`
+		Severity       string `json:"severity"`
+		Recipient      string `json:"recipient"`
+		Reason         string `json:"reason"`
+		DeliveryStatus struct {
+			Description string `json:"description"`
+			Message     string `json:"message"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+// ParseDeliveryEvents parses the event of a webhook of Mailgun.
+func (c *MailgunClient) ParseDeliveryEvents(r *http.Request) ([]DeliveryEvent, error) {
+	var e mailgunEvent
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	data := e.EventData
+	event := DeliveryEvent{Recipient: data.Recipient, Reason: data.Reason}
+	if data.DeliveryStatus.Description != "" {
+		event.Reason = data.DeliveryStatus.Description
+	} else if data.DeliveryStatus.Message != "" {
+		event.Reason = data.DeliveryStatus.Message
+	}
+	switch data.Event {
+	case "delivered":
+		event.Type = DeliveryEventDelivered
+	case "failed":
+		event.Type = DeliveryEventDeferred
+		if data.Severity == "permanent" {
+			event.Type = DeliveryEventBounced
+			event.Permanent = true
+		}
+	case "complained":
+		event.Type = DeliveryEventComplained
+	default:
+		return nil, nil
+	}
+	return []DeliveryEvent{event}, nil
+}
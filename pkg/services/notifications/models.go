@@ -26,6 +26,13 @@ type SendEmailCommand struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*SendEmailAttachFile
+
+	// Locale selects the template pack of the email, for example de-DE.
+	// When it is empty, the language of the preferences of the recipient
+	// user, or of the organization, is used.
+	Locale string
+	OrgID  int64
+	UserID int64
 }
 
 // SendEmailCommandSync is the command for sending emails synchronously
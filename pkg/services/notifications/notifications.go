@@ -9,12 +9,14 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
 
-	"github.com/Masterminds/sprig/v3"
+	"golang.org/x/text/language"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -38,27 +40,24 @@ var tmplResetPassword = "reset_password"
 var tmplSignUpStarted = "signup_started"
 var tmplWelcomeOnSignUp = "welcome_on_signup"
 
-func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore) (*NotificationService, error) {
+func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore, prefService pref.Service, userService user.Service) (*NotificationService, error) {
 	ns := &NotificationService{
-		Bus:          bus,
-		Cfg:          cfg,
-		log:          log.New("notifications"),
-		mailQueue:    make(chan *Message, 10),
-		webhookQueue: make(chan *Webhook, 10),
-		mailer:       mailer,
-		store:        store,
+		Bus:           bus,
+		Cfg:           cfg,
+		log:           log.New("notifications"),
+		mailQueue:     make(chan *Message, 10),
+		webhookQueue:  make(chan *Webhook, 10),
+		mailer:        mailer,
+		store:         store,
+		prefService:   prefService,
+		userService:   userService,
+		templatePacks: map[string]*template.Template{},
 	}
 
 	ns.Bus.AddEventListener(ns.signUpStartedHandler)
 	ns.Bus.AddEventListener(ns.signUpCompletedHandler)
 
-	mailTemplates = template.New("name")
-	mailTemplates.Funcs(template.FuncMap{
-		"Subject":                 subjectTemplateFunc,
-		"HiddenSubject":           hiddenSubjectTemplateFunc,
-		"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
-	})
-	mailTemplates.Funcs(sprig.FuncMap())
+	mailTemplates = newMailTemplates(language.English)
 
 	// Parse invalid templates using 'or' logic. Return an error only if no paths are valid.
 	invalidTemplates := make([]string, 0)
@@ -77,6 +76,10 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		ns.log.Warn("some provided html/template filepaths matched no files: %s", is)
 	}
 
+	if err := ns.loadTemplatePacks(); err != nil {
+		return nil, err
+	}
+
 	if !util.IsEmail(ns.Cfg.Smtp.FromAddress) {
 		return nil, errors.New("invalid email address for SMTP from_address config")
 	}
@@ -100,6 +103,12 @@ type NotificationService struct {
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+	prefService  pref.Service
+	userService  user.Service
+
+	templatePacksMu sync.RWMutex
+	// templatePacks are the email templates of the locales, by language tag.
+	templatePacks map[string]*template.Template
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
@@ -187,7 +196,7 @@ func __dangerouslyInjectHTML(s string) template.HTML {
 }
 
 func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context, cmd *SendEmailCommandSync) error {
-	message, err := ns.buildEmailMessage(&SendEmailCommand{
+	message, err := ns.buildEmailMessage(ctx, &SendEmailCommand{
 		Data:          cmd.Data,
 		Info:          cmd.Info,
 		Template:      cmd.Template,
@@ -197,6 +206,9 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 		AttachedFiles: cmd.AttachedFiles,
 		Subject:       cmd.Subject,
 		ReplyTo:       cmd.ReplyTo,
+		Locale:        cmd.Locale,
+		OrgID:         cmd.OrgID,
+		UserID:        cmd.UserID,
 	})
 
 	if err != nil {
@@ -208,7 +220,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 }
 
 func (ns *NotificationService) SendEmailCommandHandler(ctx context.Context, cmd *SendEmailCommand) error {
-	message, err := ns.buildEmailMessage(cmd)
+	message, err := ns.buildEmailMessage(ctx, cmd)
 
 	if err != nil {
 		return err
@@ -226,6 +238,8 @@ func (ns *NotificationService) SendResetPasswordEmail(ctx context.Context, cmd *
 	return ns.SendEmailCommandHandler(ctx, &SendEmailCommand{
 		To:       []string{cmd.User.Email},
 		Template: tmplResetPassword,
+		OrgID:    cmd.User.OrgID,
+		UserID:   cmd.User.ID,
 		Data: map[string]any{
 			"Code": code,
 			"Name": cmd.User.NameOrFallback(),
@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	providerSMTP     = "smtp"
+	providerSES      = "ses"
+	providerSendGrid = "sendgrid"
+	providerMailgun  = "mailgun"
+)
+
+// maxBatchSize is the maximum number of messages sent with one request by the
+// providers with batch APIs.
+const maxBatchSize = 1000
+
+// ProvideSmtpService returns the Mailer of the provider of the configuration:
+// the SMTP server, or the HTTP API of SES, SendGrid or Mailgun.
+func ProvideSmtpService(cfg *setting.Cfg) (Mailer, error) {
+	if cfg.Smtp.Enabled {
+		switch cfg.Smtp.Provider {
+		case providerSendGrid, providerMailgun:
+			if cfg.Smtp.APIKey == "" {
+				return nil, fmt.Errorf("the api_key of the %s email provider is required", cfg.Smtp.Provider)
+			}
+		}
+		if cfg.Smtp.Provider == providerMailgun && cfg.Smtp.MailgunDomain == "" {
+			return nil, errors.New("the mailgun_domain of the mailgun email provider is required")
+		}
+	}
+
+	switch cfg.Smtp.Provider {
+	case "", providerSMTP:
+		return NewSmtpClient(cfg.Smtp)
+	case providerSES:
+		return NewSESClient(cfg.Smtp)
+	case providerSendGrid:
+		return NewSendGridClient(cfg.Smtp)
+	case providerMailgun:
+		return NewMailgunClient(cfg.Smtp)
+	default:
+		return nil, fmt.Errorf("unknown email provider %q, expected smtp, ses, sendgrid or mailgun", cfg.Smtp.Provider)
+	}
+}
+
+// sendBatches sends the batches of the messages with send, and counts the
+// sent messages like the SMTP client does.
+func sendBatches(messages []*Message, send func(batch []*Message) error) (int, error) {
+	sentEmailsCount := 0
+	var err error
+	for _, batch := range batches(messages) {
+		innerError := send(batch)
+		emailsSentTotal.Add(float64(len(batch)))
+		if innerError != nil {
+			emailsSentFailed.Add(float64(len(batch)))
+			err = fmt.Errorf("failed to send notification to email addresses: %s: %w", strings.Join(recipients(batch), ";"), innerError)
+			continue
+		}
+		sentEmailsCount += len(batch)
+	}
+	return sentEmailsCount, err
+}
+
+// batches groups the consecutive messages that only differ by their
+// recipients, so that they are sent with one request. The recipients of the
+// messages of a batch do not see each other.
+func batches(messages []*Message) [][]*Message {
+	var result [][]*Message
+	for _, msg := range messages {
+		if n := len(result); n > 0 {
+			last := result[n-1]
+			if !msg.SingleEmail && !last[0].SingleEmail && len(last) < maxBatchSize && sameContent(last[0], msg) {
+				result[n-1] = append(last, msg)
+				continue
+			}
+		}
+		result = append(result, []*Message{msg})
+	}
+	return result
+}
+
+func sameContent(a, b *Message) bool {
+	ac, bc := *a, *b
+	ac.To, bc.To = nil, nil
+	return reflect.DeepEqual(ac, bc)
+}
+
+func recipients(batch []*Message) []string {
+	var to []string
+	for _, msg := range batch {
+		to = append(to, msg.To...)
+	}
+	return to
+}
+
+type attachment struct {
+	name    string
+	content []byte
+	// inline attachments are the embedded files, referenced by their name as
+	// content ID.
+	inline bool
+}
+
+func attachments(msg *Message) ([]attachment, error) {
+	result := make([]attachment, 0, len(msg.EmbeddedFiles)+len(msg.AttachedFiles))
+	for _, file := range msg.EmbeddedFiles {
+		//nolint:gosec
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, attachment{name: filepath.Base(file), content: content, inline: true})
+	}
+	for _, file := range msg.AttachedFiles {
+		result = append(result, attachment{name: file.Name, content: file.Content})
+	}
+	return result, nil
+}
+
+func newAPIClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// doAPIRequest sends the request to the API of a provider, and returns an
+// error with the body of the response when it fails.
+func doAPIRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
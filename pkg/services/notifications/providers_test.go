@@ -0,0 +1,253 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+func newBatchMessages(to ...string) []*Message {
+	messages := make([]*Message, 0, len(to))
+	for _, address := range to {
+		messages = append(messages, &Message{
+			To:      []string{address},
+			From:    "Grafana <from@address.com>",
+			Subject: "Some subject",
+			Body: map[string]string{
+				"text/html":  "Some HTML body",
+				"text/plain": "Some plain text body",
+			},
+		})
+	}
+	return messages
+}
+
+func TestProvideSmtpService(t *testing.T) {
+	t.Run("When the provider is unknown", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		cfg.Smtp.Provider = "postmark"
+		_, err := ProvideSmtpService(cfg)
+		require.ErrorContains(t, err, "unknown email provider")
+	})
+
+	t.Run("When the api key of an API provider is missing", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		cfg.Smtp.Provider = providerSendGrid
+		_, err := ProvideSmtpService(cfg)
+		require.ErrorContains(t, err, "api_key")
+	})
+
+	t.Run("When the domain of mailgun is missing", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		cfg.Smtp.Provider = providerMailgun
+		cfg.Smtp.APIKey = "key"
+		_, err := ProvideSmtpService(cfg)
+		require.ErrorContains(t, err, "mailgun_domain")
+	})
+
+	t.Run("When the provider is sendgrid", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		cfg.Smtp.Provider = providerSendGrid
+		cfg.Smtp.APIKey = "key"
+		mailer, err := ProvideSmtpService(cfg)
+		require.NoError(t, err)
+		require.IsType(t, &SendGridClient{}, mailer)
+	})
+}
+
+func TestBatches(t *testing.T) {
+	messages := newBatchMessages("a@example.com", "b@example.com", "c@example.com")
+	messages[2].Subject = "Other subject"
+	single := newBatchMessages("d@example.com", "e@example.com")
+	for _, msg := range single {
+		msg.SingleEmail = true
+	}
+
+	result := batches(append(messages, single...))
+	require.Len(t, result, 4)
+	assert.Len(t, result[0], 2)
+	assert.Len(t, result[1], 1)
+	assert.Len(t, result[2], 1)
+	assert.Len(t, result[3], 1)
+}
+
+func TestSendGridClient(t *testing.T) {
+	var received sendGridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := createSmtpConfig()
+	cfg.Smtp.APIKey = "key"
+	cfg.Smtp.APIURL = server.URL
+	client, err := NewSendGridClient(cfg.Smtp)
+	require.NoError(t, err)
+
+	t.Run("When sending a batch", func(t *testing.T) {
+		count, err := client.Send(newBatchMessages("a@example.com", "b@example.com")...)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		require.Len(t, received.Personalizations, 2)
+		assert.Equal(t, "a@example.com", received.Personalizations[0].To[0].Email)
+		assert.Equal(t, "b@example.com", received.Personalizations[1].To[0].Email)
+		assert.Equal(t, sendGridAddress{Email: "from@address.com", Name: "Grafana"}, received.From)
+		require.Len(t, received.Content, 2)
+		assert.Equal(t, "text/plain", received.Content[0].Type)
+	})
+
+	t.Run("When the API fails", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid key", http.StatusUnauthorized)
+		}))
+		t.Cleanup(failing.Close)
+		failingCfg := cfg.Smtp
+		failingCfg.APIURL = failing.URL
+		client, err := NewSendGridClient(failingCfg)
+		require.NoError(t, err)
+
+		count, err := client.Send(newBatchMessages("a@example.com")...)
+		require.ErrorContains(t, err, "invalid key")
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("When parsing the delivery events", func(t *testing.T) {
+		body := `[
+			{"email": "a@example.com", "event": "delivered"},
+			{"email": "b@example.com", "event": "bounce", "type": "bounce", "reason": "unknown user"},
+			{"email": "c@example.com", "event": "bounce", "type": "blocked"},
+			{"email": "d@example.com", "event": "open"}
+		]`
+		events, err := client.ParseDeliveryEvents(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.NoError(t, err)
+		assert.Equal(t, []DeliveryEvent{
+			{Type: DeliveryEventDelivered, Recipient: "a@example.com"},
+			{Type: DeliveryEventBounced, Recipient: "b@example.com", Permanent: true, Reason: "unknown user"},
+			{Type: DeliveryEventBounced, Recipient: "c@example.com"},
+		}, events)
+	})
+}
+
+func TestMailgunClient(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages", r.URL.Path)
+		_, key, _ := r.BasicAuth()
+		assert.Equal(t, "key", key)
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = io.WriteString(w, `{"message": "Queued. Thank you."}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := createSmtpConfig()
+	cfg.Smtp.APIKey = "key"
+	cfg.Smtp.APIURL = server.URL
+	cfg.Smtp.MailgunDomain = "mg.example.com"
+	client, err := NewMailgunClient(cfg.Smtp)
+	require.NoError(t, err)
+
+	t.Run("When sending a batch", func(t *testing.T) {
+		count, err := client.Send(newBatchMessages("a@example.com", "b@example.com")...)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, form["to"])
+		assert.Equal(t, []string{"Some HTML body"}, form["html"])
+		assert.Equal(t, []string{"Some plain text body"}, form["text"])
+		assert.JSONEq(t, `{"a@example.com": {}, "b@example.com": {}}`, form["recipient-variables"][0])
+	})
+
+	t.Run("When parsing the delivery events", func(t *testing.T) {
+		body := `{"event-data": {"event": "failed", "severity": "permanent", "recipient": "a@example.com", "delivery-status": {"description": "No such user"}}}`
+		events, err := client.ParseDeliveryEvents(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.NoError(t, err)
+		assert.Equal(t, []DeliveryEvent{
+			{Type: DeliveryEventBounced, Recipient: "a@example.com", Permanent: true, Reason: "No such user"},
+		}, events)
+	})
+}
+
+func TestSESClientParseDeliveryEvents(t *testing.T) {
+	client := &SESClient{client: newAPIClient()}
+
+	t.Run("When the notification is a bounce", func(t *testing.T) {
+		message, err := json.Marshal(map[string]any{
+			"notificationType": "Bounce",
+			"bounce": map[string]any{
+				"bounceType":        "Permanent",
+				"bouncedRecipients": []map[string]string{{"emailAddress": "a@example.com", "diagnosticCode": "550 5.1.1"}},
+			},
+		})
+		require.NoError(t, err)
+		body, err := json.Marshal(snsMessage{Type: "Notification", Message: string(message)})
+		require.NoError(t, err)
+
+		events, err := client.ParseDeliveryEvents(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body))))
+		require.NoError(t, err)
+		assert.Equal(t, []DeliveryEvent{
+			{Type: DeliveryEventBounced, Recipient: "a@example.com", Permanent: true, Reason: "550 5.1.1"},
+		}, events)
+	})
+
+	t.Run("When the subscription URL is not an SNS URL", func(t *testing.T) {
+		body := `{"Type": "SubscriptionConfirmation", "SubscribeURL": "https://attacker.example.com/confirm"}`
+		_, err := client.ParseDeliveryEvents(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.ErrorContains(t, err, "invalid SNS subscription URL")
+	})
+}
+
+func TestHandleDeliveryWebhook(t *testing.T) {
+	cfg := createSmtpConfig()
+	cfg.Smtp.APIKey = "key"
+	mailer, err := NewSendGridClient(cfg.Smtp)
+	require.NoError(t, err)
+
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedUser = &user.User{ID: 2, Email: "a@example.com", EmailVerified: true}
+	var verified []*user.SetEmailVerifiedCommand
+	userService.SetEmailVerifiedFn = func(_ context.Context, cmd *user.SetEmailVerifiedCommand) error {
+		verified = append(verified, cmd)
+		return nil
+	}
+
+	ns, err := ProvideService(bus.ProvideBus(nil), cfg, mailer, nil, nil, userService)
+	require.NoError(t, err)
+
+	t.Run("When the email permanently bounces", func(t *testing.T) {
+		body := `[{"email": "a@example.com", "event": "bounce", "type": "bounce"}]`
+		err := ns.HandleDeliveryWebhook(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.NoError(t, err)
+		assert.Equal(t, []*user.SetEmailVerifiedCommand{{UserID: 2, EmailVerified: false}}, verified)
+	})
+
+	t.Run("When the events are invalid", func(t *testing.T) {
+		err := ns.HandleDeliveryWebhook(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{")))
+		require.ErrorIs(t, err, ErrInvalidDeliveryEvents)
+	})
+
+	t.Run("When the provider does not report delivery events", func(t *testing.T) {
+		smtp, err := NewSmtpClient(cfg.Smtp)
+		require.NoError(t, err)
+		ns, err := ProvideService(bus.ProvideBus(nil), cfg, smtp, nil, nil, userService)
+		require.NoError(t, err)
+
+		err = ns.HandleDeliveryWebhook(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("[]")))
+		require.ErrorIs(t, err, ErrDeliveryEventsNotSupported)
+	})
+}
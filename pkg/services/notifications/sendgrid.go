@@ -0,0 +1,186 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const sendGridURL = "https://api.sendgrid.com"
+
+// SendGridClient sends the emails with the v3 mail send API of SendGrid. The
+// messages that only differ by their recipients are sent with one request.
+type SendGridClient struct {
+	cfg    setting.SmtpSettings
+	url    string
+	client *http.Client
+}
+
+func NewSendGridClient(cfg setting.SmtpSettings) (*SendGridClient, error) {
+	url := cfg.APIURL
+	if url == "" {
+		url = sendGridURL
+	}
+	return &SendGridClient{cfg: cfg, url: url, client: newAPIClient()}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyToList      []sendGridAddress         `json:"reply_to_list,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func (c *SendGridClient) Send(messages ...*Message) (int, error) {
+	return sendBatches(messages, c.send)
+}
+
+func (c *SendGridClient) send(batch []*Message) error {
+	body, err := c.buildMail(batch)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url+"/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	return doAPIRequest(c.client, req)
+}
+
+// buildMail converts the batch to a mail with a personalization per message.
+func (c *SendGridClient) buildMail(batch []*Message) (*sendGridMail, error) {
+	msg := batch[0]
+	from, err := sendGridAddresses(msg.From)
+	if err != nil {
+		return nil, err
+	}
+	replyTo, err := sendGridAddresses(msg.ReplyTo...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &sendGridMail{
+		From:        from[0],
+		ReplyToList: replyTo,
+		Subject:     msg.Subject,
+	}
+	for _, msg := range batch {
+		to, err := sendGridAddresses(msg.To...)
+		if err != nil {
+			return nil, err
+		}
+		m.Personalizations = append(m.Personalizations, sendGridPersonalization{To: to})
+	}
+
+	for _, contentType := range c.cfg.ContentTypes {
+		m.Content = append(m.Content, sendGridContent{Type: contentType, Value: msg.Body[contentType]})
+	}
+	// SendGrid requires the plain text content first.
+	sort.SliceStable(m.Content, func(i, j int) bool {
+		return m.Content[i].Type == "text/plain" && m.Content[j].Type != "text/plain"
+	})
+
+	files, err := attachments(msg)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		a := sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(file.content),
+			Filename:    file.name,
+			Disposition: "attachment",
+		}
+		if file.inline {
+			a.Disposition = "inline"
+			a.ContentID = file.name
+		}
+		m.Attachments = append(m.Attachments, a)
+	}
+	return m, nil
+}
+
+func sendGridAddresses(addresses ...string) ([]sendGridAddress, error) {
+	result := make([]sendGridAddress, 0, len(addresses))
+	for _, address := range addresses {
+		a, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sendGridAddress{Email: a.Address, Name: a.Name})
+	}
+	return result, nil
+}
+
+type sendGridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// ParseDeliveryEvents parses the events of the event webhook of SendGrid.
+func (c *SendGridClient) ParseDeliveryEvents(r *http.Request) ([]DeliveryEvent, error) {
+	var events []sendGridEvent
+	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+		return nil, err
+	}
+
+	result := make([]DeliveryEvent, 0, len(events))
+	for _, e := range events {
+		event := DeliveryEvent{Recipient: e.Email, Reason: e.Reason}
+		switch e.Event {
+		case "delivered":
+			event.Type = DeliveryEventDelivered
+		case "deferred":
+			event.Type = DeliveryEventDeferred
+		case "bounce":
+			// The blocked emails are rejected by the server of the recipient
+			// for temporary reasons.
+			event.Type = DeliveryEventBounced
+			event.Permanent = e.Type != "blocked"
+		case "dropped":
+			event.Type = DeliveryEventDropped
+		case "spamreport":
+			event.Type = DeliveryEventComplained
+		default:
+			continue
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// SESClient sends the emails with the SendRawEmail API of Amazon SES, with
+// the default AWS credentials chain. The delivery events are the SNS
+// notifications of SES.
+type SESClient struct {
+	smtp   *SmtpClient
+	api    sesiface.SESAPI
+	client *http.Client
+}
+
+func NewSESClient(cfg setting.SmtpSettings) (*SESClient, error) {
+	awsCfg := &aws.Config{}
+	if cfg.SESRegion != "" {
+		awsCfg.Region = aws.String(cfg.SESRegion)
+	}
+	if cfg.APIURL != "" {
+		awsCfg.Endpoint = aws.String(cfg.APIURL)
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SESClient{smtp: &SmtpClient{cfg: cfg}, api: ses.New(sess), client: newAPIClient()}, nil
+}
+
+func (c *SESClient) Send(messages ...*Message) (int, error) {
+	return sendBatches(messages, c.send)
+}
+
+// send sends the messages one by one: SES has no batch API for raw emails.
+func (c *SESClient) send(batch []*Message) error {
+	for _, msg := range batch {
+		var raw bytes.Buffer
+		if _, err := c.smtp.buildEmail(msg).WriteTo(&raw); err != nil {
+			return err
+		}
+		_, err := c.api.SendRawEmail(&ses.SendRawEmailInput{
+			Destinations: aws.StringSlice(msg.To),
+			RawMessage:   &ses.RawMessage{Data: raw.Bytes()},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type snsMessage struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string         `json:"bounceType"`
+		BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []sesRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Delivery struct {
+		Recipients []string `json:"recipients"`
+	} `json:"delivery"`
+}
+
+// ParseDeliveryEvents parses the SNS notification of SES events. The
+// subscriptions of the SNS topics are confirmed.
+func (c *SESClient) ParseDeliveryEvents(r *http.Request) ([]DeliveryEvent, error) {
+	var msg snsMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		return nil, err
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		return nil, c.confirmSubscription(msg.SubscribeURL)
+	case "Notification":
+	default:
+		return nil, nil
+	}
+
+	var n sesNotification
+	if err := json.Unmarshal([]byte(msg.Message), &n); err != nil {
+		return nil, err
+	}
+	notificationType := n.NotificationType
+	if notificationType == "" {
+		notificationType = n.EventType
+	}
+
+	var events []DeliveryEvent
+	switch notificationType {
+	case "Delivery":
+		for _, recipient := range n.Delivery.Recipients {
+			events = append(events, DeliveryEvent{Type: DeliveryEventDelivered, Recipient: recipient})
+		}
+	case "Bounce":
+		for _, recipient := range n.Bounce.BouncedRecipients {
+			events = append(events, DeliveryEvent{
+				Type:      DeliveryEventBounced,
+				Recipient: recipient.EmailAddress,
+				Permanent: n.Bounce.BounceType == "Permanent",
+				Reason:    recipient.DiagnosticCode,
+			})
+		}
+	case "Complaint":
+		for _, recipient := range n.Complaint.ComplainedRecipients {
+			events = append(events, DeliveryEvent{Type: DeliveryEventComplained, Recipient: recipient.EmailAddress})
+		}
+	}
+	return events, nil
+}
+
+// confirmSubscription confirms the subscription of the webhook to an SNS
+// topic. Only the URLs of SNS are requested.
+func (c *SESClient) confirmSubscription(subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || !strings.HasPrefix(u.Hostname(), "sns.") || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("invalid SNS subscription URL %q", subscribeURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	return doAPIRequest(c.client, req)
+}
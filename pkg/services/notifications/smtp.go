@@ -17,10 +17,6 @@ type SmtpClient struct {
 	cfg setting.SmtpSettings
 }
 
-func ProvideSmtpService(cfg *setting.Cfg) (Mailer, error) {
-	return NewSmtpClient(cfg.Smtp)
-}
-
 func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
 	client := &SmtpClient{
 		cfg: cfg,
@@ -0,0 +1,71 @@
+package oauthtoken
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
+)
+
+const (
+	// TokenExchangeAudienceKey is the JSON data key of the audience the forwarded OAuth token
+	// of a data source is exchanged for, or of its scope for the on-behalf-of flow.
+	TokenExchangeAudienceKey = "oauthTokenExchangeAudience"
+
+	allowedDataSourceTypeKey = "type:"
+)
+
+// GetForwardedOAuthToken returns the OAuth token of the user to forward to the data source, or nil when the
+// forwarding policy does not allow the data source to receive it. The token is exchanged for a token with the
+// audience of the data source when it has one.
+func (o *Service) GetForwardedOAuthToken(ctx context.Context, usr identity.Requester, ds *datasources.DataSource) *oauth2.Token {
+	if !o.IsOAuthPassThruEnabled(ds) {
+		return nil
+	}
+
+	token := o.GetCurrentOAuthToken(ctx, usr)
+	if token == nil {
+		return nil
+	}
+
+	audience := ds.JsonData.Get(TokenExchangeAudienceKey).MustString()
+	if audience == "" {
+		return token
+	}
+
+	namespace, id := usr.GetNamespacedID()
+	exchanged, err := o.TokenExchange.Exchange(ctx, tokenexchange.Request{
+		UserID:        namespace + ":" + id,
+		DataSourceUID: ds.UID,
+		Audience:      audience,
+		SubjectToken:  token,
+	})
+	if err != nil {
+		logger.Error("Failed to exchange the OAuth token", "datasourceUid", ds.UID, "audience", audience, "error", err)
+		return nil
+	}
+	return exchanged
+}
+
+// isForwardingAllowed returns true if the forwarding policy allows the data source to receive the OAuth identity.
+func (o *Service) isForwardingAllowed(ds *datasources.DataSource) bool {
+	allowed := o.Cfg.OAuthTokenForwarding.AllowedDataSources
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		if dsType, ok := strings.CutPrefix(a, allowedDataSourceTypeKey); ok {
+			if dsType == ds.Type {
+				return true
+			}
+		} else if a == ds.UID {
+			return true
+		}
+	}
+	return false
+}
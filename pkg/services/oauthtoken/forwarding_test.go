@@ -0,0 +1,93 @@
+package oauthtoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_GetForwardedOAuthToken(t *testing.T) {
+	usr := &user.SignedInUser{UserID: 1}
+	newDataSource := func(uid, dsType string, jsonData map[string]any) *datasources.DataSource {
+		jsonData["oauthPassThru"] = true
+		return &datasources.DataSource{UID: uid, Type: dsType, JsonData: simplejson.NewFromAny(jsonData)}
+	}
+	setup := func(t *testing.T) *Service {
+		srv, authInfoStore, _ := setupOAuthTokenService(t)
+		authInfoStore.ExpectedOAuth = &login.UserAuth{
+			AuthModule:       "oauth_generic_oauth",
+			OAuthAccessToken: "user-token",
+			OAuthTokenType:   "Bearer",
+			OAuthExpiry:      time.Now().Add(time.Hour),
+		}
+		return srv
+	}
+
+	t.Run("forwards the token to every data source without an allowlist", func(t *testing.T) {
+		srv := setup(t)
+
+		token := srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("a", "prometheus", map[string]any{}))
+		require.NotNil(t, token)
+		assert.Equal(t, "user-token", token.AccessToken)
+	})
+
+	t.Run("does not forward the token to data sources without Forward OAuth Identity", func(t *testing.T) {
+		srv := setup(t)
+
+		token := srv.GetForwardedOAuthToken(context.Background(), usr, &datasources.DataSource{UID: "a", JsonData: simplejson.New()})
+		assert.Nil(t, token)
+	})
+
+	t.Run("only forwards the token to the allowed data sources", func(t *testing.T) {
+		srv := setup(t)
+		srv.Cfg.OAuthTokenForwarding.AllowedDataSources = []string{"a", "type:loki"}
+
+		assert.NotNil(t, srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("a", "prometheus", map[string]any{})))
+		assert.NotNil(t, srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("b", "loki", map[string]any{})))
+		assert.Nil(t, srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("c", "prometheus", map[string]any{})))
+	})
+
+	t.Run("exchanges the token for the audience of the data source", func(t *testing.T) {
+		srv := setup(t)
+		exchange := &fakeTokenExchanger{token: &oauth2.Token{AccessToken: "metrics-token"}}
+		srv.TokenExchange = exchange
+
+		token := srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("a", "prometheus", map[string]any{TokenExchangeAudienceKey: "metrics"}))
+		require.NotNil(t, token)
+		assert.Equal(t, "metrics-token", token.AccessToken)
+		assert.Equal(t, tokenexchange.Request{
+			UserID:        "user:1",
+			DataSourceUID: "a",
+			Audience:      "metrics",
+			SubjectToken:  exchange.req.SubjectToken,
+		}, exchange.req)
+		assert.Equal(t, "user-token", exchange.req.SubjectToken.AccessToken)
+	})
+
+	t.Run("does not forward the token when the token exchange is not configured", func(t *testing.T) {
+		srv := setup(t)
+
+		token := srv.GetForwardedOAuthToken(context.Background(), usr, newDataSource("a", "prometheus", map[string]any{TokenExchangeAudienceKey: "metrics"}))
+		assert.Nil(t, token)
+	})
+}
+
+type fakeTokenExchanger struct {
+	token *oauth2.Token
+	req   tokenexchange.Request
+}
+
+func (f *fakeTokenExchanger) Exchange(_ context.Context, req tokenexchange.Request) (*oauth2.Token, error) {
+	f.req = req
+	return f.token, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -33,6 +34,7 @@ type Service struct {
 	Cfg               *setting.Cfg
 	SocialService     social.Service
 	AuthInfoService   login.AuthInfoService
+	TokenExchange     tokenExchanger
 	singleFlightGroup *singleflight.Group
 
 	tokenRefreshDuration *prometheus.HistogramVec
@@ -40,17 +42,24 @@ type Service struct {
 
 type OAuthTokenService interface {
 	GetCurrentOAuthToken(context.Context, identity.Requester) *oauth2.Token
+	GetForwardedOAuthToken(context.Context, identity.Requester, *datasources.DataSource) *oauth2.Token
 	IsOAuthPassThruEnabled(*datasources.DataSource) bool
 	HasOAuthEntry(context.Context, identity.Requester) (*login.UserAuth, bool, error)
 	TryTokenRefresh(context.Context, *login.UserAuth) error
 	InvalidateOAuthTokens(context.Context, *login.UserAuth) error
 }
 
-func ProvideService(socialService social.Service, authInfoService login.AuthInfoService, cfg *setting.Cfg, registerer prometheus.Registerer) *Service {
+type tokenExchanger interface {
+	Exchange(ctx context.Context, req tokenexchange.Request) (*oauth2.Token, error)
+}
+
+func ProvideService(socialService social.Service, authInfoService login.AuthInfoService, cfg *setting.Cfg, registerer prometheus.Registerer,
+	tokenExchange *tokenexchange.Service) *Service {
 	return &Service{
 		Cfg:                  cfg,
 		SocialService:        socialService,
 		AuthInfoService:      authInfoService,
+		TokenExchange:        tokenExchange,
 		singleFlightGroup:    new(singleflight.Group),
 		tokenRefreshDuration: newTokenRefreshDurationMetric(registerer),
 	}
@@ -99,9 +108,10 @@ func (o *Service) GetCurrentOAuthToken(ctx context.Context, usr identity.Request
 	return token
 }
 
-// IsOAuthPassThruEnabled returns true if Forward OAuth Identity (oauthPassThru) is enabled for the provided data source.
+// IsOAuthPassThruEnabled returns true if Forward OAuth Identity (oauthPassThru) is enabled for the provided data source
+// and the forwarding policy allows the data source to receive the OAuth identity.
 func (o *Service) IsOAuthPassThruEnabled(ds *datasources.DataSource) bool {
-	return IsOAuthPassThruEnabled(ds)
+	return IsOAuthPassThruEnabled(ds) && o.isForwardingAllowed(ds)
 }
 
 // HasOAuthEntry returns true and the UserAuth object when OAuth info exists for the specified User
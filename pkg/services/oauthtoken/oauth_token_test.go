@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/login/social/socialtest"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -230,10 +231,12 @@ func setupOAuthTokenService(t *testing.T) (*Service, *FakeAuthInfoStore, *social
 
 	authInfoStore := &FakeAuthInfoStore{}
 	authInfoService := authinfoimpl.ProvideService(authInfoStore)
+	cfg := setting.NewCfg()
 	return &Service{
-		Cfg:                  setting.NewCfg(),
+		Cfg:                  cfg,
 		SocialService:        socialService,
 		AuthInfoService:      authInfoService,
+		TokenExchange:        tokenexchange.ProvideService(cfg),
 		singleFlightGroup:    &singleflight.Group{},
 		tokenRefreshDuration: newTokenRefreshDurationMetric(prometheus.NewRegistry()),
 	}, authInfoStore, socialConnector
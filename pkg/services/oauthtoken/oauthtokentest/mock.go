@@ -12,6 +12,7 @@ import (
 
 type MockOauthTokenService struct {
 	GetCurrentOauthTokenFunc   func(ctx context.Context, usr identity.Requester) *oauth2.Token
+	GetForwardedOAuthTokenFunc func(ctx context.Context, usr identity.Requester, ds *datasources.DataSource) *oauth2.Token
 	IsOAuthPassThruEnabledFunc func(ds *datasources.DataSource) bool
 	HasOAuthEntryFunc          func(ctx context.Context, usr identity.Requester) (*login.UserAuth, bool, error)
 	InvalidateOAuthTokensFunc  func(ctx context.Context, usr *login.UserAuth) error
@@ -25,6 +26,16 @@ func (m *MockOauthTokenService) GetCurrentOAuthToken(ctx context.Context, usr id
 	return nil
 }
 
+func (m *MockOauthTokenService) GetForwardedOAuthToken(ctx context.Context, usr identity.Requester, ds *datasources.DataSource) *oauth2.Token {
+	if m.GetForwardedOAuthTokenFunc != nil {
+		return m.GetForwardedOAuthTokenFunc(ctx, usr, ds)
+	}
+	if !m.IsOAuthPassThruEnabled(ds) {
+		return nil
+	}
+	return m.GetCurrentOAuthToken(ctx, usr)
+}
+
 func (m *MockOauthTokenService) IsOAuthPassThruEnabled(ds *datasources.DataSource) bool {
 	if m.IsOAuthPassThruEnabledFunc != nil {
 		return m.IsOAuthPassThruEnabledFunc(ds)
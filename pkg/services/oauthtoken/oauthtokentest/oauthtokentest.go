@@ -25,6 +25,13 @@ func (s *Service) GetCurrentOAuthToken(context.Context, identity.Requester) *oau
 	return s.Token
 }
 
+func (s *Service) GetForwardedOAuthToken(_ context.Context, _ identity.Requester, ds *datasources.DataSource) *oauth2.Token {
+	if !s.IsOAuthPassThruEnabled(ds) {
+		return nil
+	}
+	return s.Token
+}
+
 func (s *Service) IsOAuthPassThruEnabled(ds *datasources.DataSource) bool {
 	return oauthtoken.IsOAuthPassThruEnabled(ds)
 }
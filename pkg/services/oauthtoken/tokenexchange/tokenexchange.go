@@ -0,0 +1,188 @@
+// Package tokenexchange exchanges the OAuth tokens of the users for tokens scoped to a data source,
+// so that the data source can authorize the queries on behalf of the user.
+package tokenexchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// TypeRFC8693 exchanges tokens with a security token service implementing RFC 8693.
+	TypeRFC8693 = "rfc8693"
+	// TypeAzureOBO exchanges tokens with the on-behalf-of flow of Microsoft Entra ID.
+	TypeAzureOBO = "azure_obo"
+
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtBearerGrantType     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	accessTokenType        = "urn:ietf:params:oauth:token-type:access_token"
+
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrNotConfigured  = errors.New("token exchange is not configured")
+	ErrUnknownType    = errors.New("unknown token exchange type")
+	ErrExchangeFailed = errors.New("token exchange failed")
+)
+
+// Request is a request to exchange the token of a user for a token scoped to a data source.
+type Request struct {
+	// UserID is the namespaced ID of the user the token belongs to.
+	UserID        string
+	DataSourceUID string
+	// Audience is the audience of the exchanged token, or its scope for the on-behalf-of flow.
+	Audience     string
+	SubjectToken *oauth2.Token
+}
+
+// Service exchanges the tokens of the users and caches the exchanged tokens per user and data source
+// until they expire.
+type Service struct {
+	cfg    setting.OAuthTokenForwardingSettings
+	client *http.Client
+	cache  *localcache.CacheService
+	log    log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	return &Service{
+		cfg:    cfg.OAuthTokenForwarding,
+		client: &http.Client{Timeout: cfg.OAuthTokenForwarding.TokenExchangeTimeout},
+		cache:  localcache.New(5*time.Minute, 10*time.Minute),
+		log:    log.New("oauthtoken.tokenexchange"),
+	}
+}
+
+// IsEnabled returns true if a token endpoint is configured to exchange the tokens.
+func (s *Service) IsEnabled() bool {
+	return s.cfg.TokenExchangeURL != ""
+}
+
+// Exchange returns a token scoped to the audience of the request in exchange for the token of the user.
+func (s *Service) Exchange(ctx context.Context, req Request) (*oauth2.Token, error) {
+	if !s.IsEnabled() {
+		return nil, ErrNotConfigured
+	}
+
+	cacheKey := cacheKey(req)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		return cached.(*oauth2.Token), nil
+	}
+
+	form, err := s.form(req)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.requestToken(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	if token.Expiry.IsZero() {
+		token.Expiry = req.SubjectToken.Expiry
+	}
+
+	if ttl := time.Until(token.Expiry) - 10*time.Second; !token.Expiry.IsZero() && ttl > 0 {
+		s.cache.Set(cacheKey, token, ttl)
+	}
+
+	s.log.Debug("Exchanged token", "userId", req.UserID, "datasourceUid", req.DataSourceUID, "audience", req.Audience)
+	return token, nil
+}
+
+func (s *Service) form(req Request) (url.Values, error) {
+	switch s.cfg.TokenExchangeType {
+	case "", TypeRFC8693:
+		return url.Values{
+			"grant_type":           {tokenExchangeGrantType},
+			"subject_token":        {req.SubjectToken.AccessToken},
+			"subject_token_type":   {accessTokenType},
+			"requested_token_type": {accessTokenType},
+			"audience":             {req.Audience},
+		}, nil
+	case TypeAzureOBO:
+		return url.Values{
+			"grant_type":          {jwtBearerGrantType},
+			"client_id":           {s.cfg.TokenExchangeClientID},
+			"client_secret":       {s.cfg.TokenExchangeClientSecret},
+			"assertion":           {req.SubjectToken.AccessToken},
+			"scope":               {req.Audience},
+			"requested_token_use": {"on_behalf_of"},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, s.cfg.TokenExchangeType)
+	}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (s *Service) requestToken(ctx context.Context, form url.Values) (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenExchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// the on-behalf-of flow authenticates the client in the form
+	if !form.Has("client_id") {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.TokenExchangeClientID), url.QueryEscape(s.cfg.TokenExchangeClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchangeFailed, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchangeFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token endpoint returned status %d: %s", ErrExchangeFailed, resp.StatusCode, body)
+	}
+
+	var res tokenResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse response: %w", ErrExchangeFailed, err)
+	}
+	if res.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in response", ErrExchangeFailed)
+	}
+
+	token := &oauth2.Token{AccessToken: res.AccessToken, TokenType: res.TokenType}
+	if res.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// cacheKey includes the hash of the token of the user, so that a new login of the user
+// or a refresh of their token does not reuse a token exchanged for the previous one.
+func cacheKey(req Request) string {
+	hash := sha256.Sum256([]byte(req.SubjectToken.AccessToken))
+	return strings.Join([]string{req.UserID, req.DataSourceUID, req.Audience, hex.EncodeToString(hash[:])}, ":")
+}
@@ -0,0 +1,128 @@
+package tokenexchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Exchange(t *testing.T) {
+	setup := func(t *testing.T, exchangeType string, handler func(t *testing.T, r *http.Request, form map[string]string)) (*Service, *int) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			require.NoError(t, r.ParseForm())
+			form := make(map[string]string, len(r.PostForm))
+			for k := range r.PostForm {
+				form[k] = r.PostForm.Get(k)
+			}
+			handler(t, r, form)
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"datasource-token","token_type":"Bearer","expires_in":3600}`))
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := setting.NewCfg()
+		cfg.OAuthTokenForwarding = setting.OAuthTokenForwardingSettings{
+			TokenExchangeType:         exchangeType,
+			TokenExchangeURL:          server.URL,
+			TokenExchangeClientID:     "grafana",
+			TokenExchangeClientSecret: "secret",
+			TokenExchangeTimeout:      time.Second,
+		}
+		return ProvideService(cfg), &requests
+	}
+	req := Request{
+		UserID:        "user:1",
+		DataSourceUID: "adx",
+		Audience:      "https://help.kusto.windows.net/.default",
+		SubjectToken:  &oauth2.Token{AccessToken: "user-token", Expiry: time.Now().Add(time.Hour)},
+	}
+
+	t.Run("exchanges the token with RFC 8693", func(t *testing.T) {
+		s, _ := setup(t, TypeRFC8693, func(t *testing.T, r *http.Request, form map[string]string) {
+			clientID, clientSecret, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "grafana", clientID)
+			assert.Equal(t, "secret", clientSecret)
+			assert.Equal(t, map[string]string{
+				"grant_type":           tokenExchangeGrantType,
+				"subject_token":        "user-token",
+				"subject_token_type":   accessTokenType,
+				"requested_token_type": accessTokenType,
+				"audience":             req.Audience,
+			}, form)
+		})
+
+		token, err := s.Exchange(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "datasource-token", token.AccessToken)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+	})
+
+	t.Run("exchanges the token with the Azure on-behalf-of flow", func(t *testing.T) {
+		s, _ := setup(t, TypeAzureOBO, func(t *testing.T, r *http.Request, form map[string]string) {
+			assert.Equal(t, map[string]string{
+				"grant_type":          jwtBearerGrantType,
+				"client_id":           "grafana",
+				"client_secret":       "secret",
+				"assertion":           "user-token",
+				"scope":               req.Audience,
+				"requested_token_use": "on_behalf_of",
+			}, form)
+		})
+
+		token, err := s.Exchange(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "datasource-token", token.AccessToken)
+	})
+
+	t.Run("caches the exchanged tokens per user, data source and token", func(t *testing.T) {
+		s, requests := setup(t, TypeRFC8693, func(t *testing.T, r *http.Request, form map[string]string) {})
+
+		for i := 0; i < 2; i++ {
+			_, err := s.Exchange(context.Background(), req)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, *requests)
+
+		otherDataSource := req
+		otherDataSource.DataSourceUID = "other"
+		_, err := s.Exchange(context.Background(), otherDataSource)
+		require.NoError(t, err)
+		assert.Equal(t, 2, *requests)
+
+		refreshed := req
+		refreshed.SubjectToken = &oauth2.Token{AccessToken: "refreshed-token"}
+		_, err = s.Exchange(context.Background(), refreshed)
+		require.NoError(t, err)
+		assert.Equal(t, 3, *requests)
+	})
+
+	t.Run("returns an error when the token endpoint rejects the token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(server.Close)
+		cfg := setting.NewCfg()
+		cfg.OAuthTokenForwarding.TokenExchangeURL = server.URL
+
+		_, err := ProvideService(cfg).Exchange(context.Background(), req)
+		require.ErrorIs(t, err, ErrExchangeFailed)
+	})
+
+	t.Run("returns an error when the token exchange is not configured", func(t *testing.T) {
+		_, err := ProvideService(setting.NewCfg()).Exchange(context.Background(), req)
+		require.ErrorIs(t, err, ErrNotConfigured)
+	})
+}
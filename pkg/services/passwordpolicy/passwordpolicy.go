@@ -0,0 +1,59 @@
+// Package passwordpolicy enforces the password policy of the basic auth users
+// when their password is set or changed: complexity and entropy rules, the
+// reuse of recent passwords, and an optional check against the passwords of
+// known data breaches.
+package passwordpolicy
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// The rules of the password policy, reported in the violations.
+const (
+	RuleMinLength = "min-length"
+	RuleUppercase = "uppercase"
+	RuleLowercase = "lowercase"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleEntropy   = "entropy"
+	RuleUserInfo  = "user-info"
+	RuleHistory   = "history"
+	RuleBreached  = "breached"
+)
+
+var (
+	ErrPolicyViolation   = errutil.BadRequest("password-policy.violation", errutil.WithPublicMessage("The password does not satisfy the password policy"))
+	ErrBreachCheckFailed = errutil.BadGateway("password-policy.breach-check-failed", errutil.WithPublicMessage("The password could not be checked against known data breaches, retry later"))
+)
+
+type Service interface {
+	// Validate returns an ErrPolicyViolation error listing the violated rules
+	// if the new password of the user does not satisfy the policy. The user
+	// may not be created yet, in which case its ID is zero.
+	Validate(ctx context.Context, usr *user.User, password string) error
+	// RecordPassword records the password of a user in its password history
+	// once it is set, with the salt it is hashed with.
+	RecordPassword(ctx context.Context, userID int64, hashedPassword, salt string) error
+}
+
+// BreachChecker checks whether a password appeared in a known data breach.
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// Violation is a rule of the policy a password does not satisfy.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// NewViolationError returns an ErrPolicyViolation error with the violations
+// in its public payload, so that clients can show them next to the password.
+func NewViolationError(violations []Violation) error {
+	err := ErrPolicyViolation.Errorf("password violates %d rules of the password policy", len(violations))
+	err.PublicPayload = map[string]any{"violations": violations}
+	return err
+}
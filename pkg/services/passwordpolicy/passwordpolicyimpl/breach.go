@@ -0,0 +1,59 @@
+package passwordpolicyimpl
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // #nosec G505 -- SHA-1 is the hash of the range API, not used for security.
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+)
+
+// pwnedPasswordsChecker checks passwords with the range API of Have I Been
+// Pwned. The API uses k-anonymity: only the first 5 characters of the SHA-1
+// hash of the password are sent, and the API returns the suffixes of all the
+// breached hashes with this prefix, so neither the password nor its hash
+// leave Grafana.
+type pwnedPasswordsChecker struct {
+	client *http.Client
+	url    string
+}
+
+var _ passwordpolicy.BreachChecker = &pwnedPasswordsChecker{}
+
+func (c *pwnedPasswordsChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	// #nosec G401 -- SHA-1 is the hash of the range API, not used for security.
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.url, "/")+"/"+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// Padding hides the number of breached hashes with the prefix from observers of the response size.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d from breached passwords API", resp.StatusCode)
+	}
+
+	// Each line is the suffix of a hash and the number of times it was seen, such as
+	// 0018A45C4D1DEF81644B54AB7F969B88D65:21. The padding lines have a count of 0.
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		s, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(s, suffix) {
+			return count != "0", nil
+		}
+	}
+	return false, scanner.Err()
+}
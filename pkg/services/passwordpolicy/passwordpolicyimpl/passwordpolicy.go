@@ -0,0 +1,251 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// minUserInfoLength is the minimum length of the login and of the name of the
+// email of a user for the passwords that contain them to be rejected.
+const minUserInfoLength = 3
+
+type policy struct {
+	enabled          bool
+	minLength        int
+	requireUppercase bool
+	requireLowercase bool
+	requireDigit     bool
+	requireSymbol    bool
+	minEntropyBits   float64
+	disallowUserInfo bool
+	historyCount     int
+	breachCheck      bool
+	breachFailOpen   bool
+}
+
+type Service struct {
+	policy policy
+	store  store
+	breach passwordpolicy.BreachChecker
+	log    log.Logger
+}
+
+var _ passwordpolicy.Service = &Service{}
+
+func ProvideService(db db.DB, cfg *setting.Cfg) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("password_policy")
+	s := &Service{
+		policy: policy{
+			enabled:          section.Key("enabled").MustBool(false),
+			minLength:        section.Key("min_length").MustInt(12),
+			requireUppercase: section.Key("require_uppercase").MustBool(true),
+			requireLowercase: section.Key("require_lowercase").MustBool(true),
+			requireDigit:     section.Key("require_digit").MustBool(true),
+			requireSymbol:    section.Key("require_symbol").MustBool(true),
+			minEntropyBits:   section.Key("min_entropy_bits").MustFloat64(0),
+			disallowUserInfo: section.Key("disallow_user_info").MustBool(true),
+			historyCount:     section.Key("history_count").MustInt(0),
+			breachCheck:      section.Key("breach_check").MustBool(false),
+			breachFailOpen:   section.Key("breach_check_fail_open").MustBool(true),
+		},
+		store: &sqlStore{db: db},
+		log:   log.New("passwordpolicy"),
+	}
+	if s.policy.historyCount < 0 {
+		return nil, fmt.Errorf("password policy history_count must be positive, got %d", s.policy.historyCount)
+	}
+
+	timeout, err := time.ParseDuration(section.Key("breach_check_timeout").MustString("5s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse password policy breach_check_timeout: %w", err)
+	}
+	s.breach = &pwnedPasswordsChecker{
+		client: &http.Client{Timeout: timeout},
+		url:    section.Key("breach_check_url").MustString("https://api.pwnedpasswords.com/range"),
+	}
+	return s, nil
+}
+
+func (s *Service) Validate(ctx context.Context, usr *user.User, password string) error {
+	if !s.policy.enabled {
+		return nil
+	}
+
+	violations := s.checkComplexity(usr, password)
+
+	reused, err := s.isReused(ctx, usr, password)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, passwordpolicy.Violation{
+			Rule:    passwordpolicy.RuleHistory,
+			Message: fmt.Sprintf("The password must differ from the last %d passwords", s.policy.historyCount),
+		})
+	}
+
+	if s.policy.breachCheck {
+		breached, err := s.breach.IsBreached(ctx, password)
+		switch {
+		case err != nil && s.policy.breachFailOpen:
+			s.log.FromContext(ctx).Warn("Failed to check the password against known data breaches, accepting it", "error", err)
+		case err != nil:
+			return passwordpolicy.ErrBreachCheckFailed.Errorf("failed to check the password against known data breaches: %w", err)
+		case breached:
+			violations = append(violations, passwordpolicy.Violation{
+				Rule:    passwordpolicy.RuleBreached,
+				Message: "The password appeared in a known data breach",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return passwordpolicy.NewViolationError(violations)
+	}
+	return nil
+}
+
+// checkComplexity returns the violations of the complexity rules of the policy.
+func (s *Service) checkComplexity(usr *user.User, password string) []passwordpolicy.Violation {
+	var violations []passwordpolicy.Violation
+	add := func(rule, message string) {
+		violations = append(violations, passwordpolicy.Violation{Rule: rule, Message: message})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	if len([]rune(password)) < s.policy.minLength {
+		add(passwordpolicy.RuleMinLength, fmt.Sprintf("The password must have at least %d characters", s.policy.minLength))
+	}
+	if s.policy.requireUppercase && !hasUpper {
+		add(passwordpolicy.RuleUppercase, "The password must contain an uppercase letter")
+	}
+	if s.policy.requireLowercase && !hasLower {
+		add(passwordpolicy.RuleLowercase, "The password must contain a lowercase letter")
+	}
+	if s.policy.requireDigit && !hasDigit {
+		add(passwordpolicy.RuleDigit, "The password must contain a digit")
+	}
+	if s.policy.requireSymbol && !hasSymbol {
+		add(passwordpolicy.RuleSymbol, "The password must contain a symbol")
+	}
+	if s.policy.minEntropyBits > 0 && entropyBits(password) < s.policy.minEntropyBits {
+		add(passwordpolicy.RuleEntropy, "The password is too easy to guess, use a longer password or more kinds of characters")
+	}
+	if s.policy.disallowUserInfo && usr != nil && containsUserInfo(usr, password) {
+		add(passwordpolicy.RuleUserInfo, "The password must not contain the login or the email of the user")
+	}
+	return violations
+}
+
+// isReused returns true if the password is the current password of the user
+// or one of its recent passwords.
+func (s *Service) isReused(ctx context.Context, usr *user.User, password string) (bool, error) {
+	if s.policy.historyCount == 0 || usr == nil || usr.ID == 0 {
+		return false, nil
+	}
+
+	history, err := s.store.GetHistory(ctx, usr.ID, s.policy.historyCount)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the password history: %w", err)
+	}
+	// The current password is not in the history if it was set before the history was enabled.
+	if usr.Password != "" && (len(history) == 0 || history[0].Password != usr.Password) {
+		history = append([]*historyEntry{{Password: usr.Password, Salt: usr.Salt}}, history...)
+	}
+	if len(history) > s.policy.historyCount {
+		history = history[:s.policy.historyCount]
+	}
+
+	for _, entry := range history {
+		hashed, err := util.EncodePassword(password, entry.Salt)
+		if err != nil {
+			return false, err
+		}
+		if hashed == entry.Password {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) RecordPassword(ctx context.Context, userID int64, hashedPassword, salt string) error {
+	if !s.policy.enabled || s.policy.historyCount == 0 {
+		return nil
+	}
+	return s.store.Insert(ctx, &historyEntry{UserID: userID, Password: hashedPassword, Salt: salt}, s.policy.historyCount)
+}
+
+// entropyBits estimates the entropy of a password as the entropy of a random
+// password of the same length, drawn from the kinds of characters it contains.
+// It overestimates the entropy of the passwords made of words, which is what
+// the breach check is for.
+func entropyBits(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, kind := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if kind.present {
+			pool += kind.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(len([]rune(password))) * math.Log2(float64(pool))
+}
+
+func containsUserInfo(usr *user.User, password string) bool {
+	password = strings.ToLower(password)
+	infos := []string{usr.Login}
+	if name, _, ok := strings.Cut(usr.Email, "@"); ok {
+		infos = append(infos, name)
+	}
+	for _, info := range infos {
+		if len(info) >= minUserInfoLength && strings.Contains(password, strings.ToLower(info)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func newService(t *testing.T, sqlStore db.DB, settings map[string]string) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	section := cfg.Raw.Section("password_policy")
+	section.Key("enabled").SetValue("true")
+	for key, value := range settings {
+		section.Key(key).SetValue(value)
+	}
+	s, err := ProvideService(sqlStore, cfg)
+	require.NoError(t, err)
+	return s
+}
+
+// violatedRules returns the rules of the violations of a policy error.
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+	var gfErr errutil.Error
+	require.ErrorAs(t, err, &gfErr)
+	require.Equal(t, "password-policy.violation", gfErr.MessageID)
+	rules := []string{}
+	for _, v := range gfErr.PublicPayload["violations"].([]passwordpolicy.Violation) {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+type fakeBreachChecker struct {
+	breached bool
+	err      error
+}
+
+func (f *fakeBreachChecker) IsBreached(context.Context, string) (bool, error) {
+	return f.breached, f.err
+}
+
+func TestService_Validate(t *testing.T) {
+	usr := &user.User{Login: "alice", Email: "alice.smith@example.com"}
+
+	t.Run("accepts any password when disabled", func(t *testing.T) {
+		s := newService(t, nil, map[string]string{"enabled": "false"})
+		require.NoError(t, s.Validate(context.Background(), usr, "a"))
+	})
+
+	t.Run("checks the complexity rules", func(t *testing.T) {
+		s := newService(t, nil, nil)
+		tests := []struct {
+			password string
+			rules    []string
+		}{
+			{password: "Correct-horse-7", rules: nil},
+			{password: "short-A1", rules: []string{passwordpolicy.RuleMinLength}},
+			{password: "no-uppercase-1", rules: []string{passwordpolicy.RuleUppercase}},
+			{password: "NO-LOWERCASE-1", rules: []string{passwordpolicy.RuleLowercase}},
+			{password: "No-digits-at-all", rules: []string{passwordpolicy.RuleDigit}},
+			{password: "NoSymbolsAtAll1", rules: []string{passwordpolicy.RuleSymbol}},
+			{password: "Alice-password-1", rules: []string{passwordpolicy.RuleUserInfo}},
+			{password: "My-ALICE.SMITH-1", rules: []string{passwordpolicy.RuleUserInfo}},
+			{password: "abc", rules: []string{passwordpolicy.RuleMinLength, passwordpolicy.RuleUppercase, passwordpolicy.RuleDigit, passwordpolicy.RuleSymbol}},
+		}
+		for _, tt := range tests {
+			err := s.Validate(context.Background(), usr, tt.password)
+			if tt.rules == nil {
+				assert.NoError(t, err, tt.password)
+				continue
+			}
+			assert.Equal(t, tt.rules, violatedRules(t, err), tt.password)
+		}
+	})
+
+	t.Run("checks the entropy", func(t *testing.T) {
+		s := newService(t, nil, map[string]string{"min_entropy_bits": "100"})
+		assert.Equal(t, []string{passwordpolicy.RuleEntropy}, violatedRules(t, s.Validate(context.Background(), usr, "Aaaaaaaaaaa-1")))
+		assert.NoError(t, s.Validate(context.Background(), usr, "Correct-horse-battery-7"))
+	})
+
+	t.Run("checks the password against known data breaches", func(t *testing.T) {
+		s := newService(t, nil, map[string]string{"breach_check": "true"})
+		s.breach = &fakeBreachChecker{breached: true}
+		assert.Equal(t, []string{passwordpolicy.RuleBreached}, violatedRules(t, s.Validate(context.Background(), usr, "Correct-horse-7")))
+
+		s.breach = &fakeBreachChecker{err: errors.New("unavailable")}
+		assert.NoError(t, s.Validate(context.Background(), usr, "Correct-horse-7"))
+
+		s.policy.breachFailOpen = false
+		err := s.Validate(context.Background(), usr, "Correct-horse-7")
+		assert.ErrorIs(t, err, passwordpolicy.ErrBreachCheckFailed)
+	})
+}
+
+func TestPwnedPasswordsChecker(t *testing.T) {
+	// The SHA-1 hash of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		_, _ = fmt.Fprint(w, "003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+	}))
+	t.Cleanup(server.Close)
+
+	checker := &pwnedPasswordsChecker{client: server.Client(), url: server.URL + "/range/"}
+	breached, err := checker.IsBreached(context.Background(), "password")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = checker.IsBreached(context.Background(), "Correct-horse-7")
+	assert.Error(t, err)
+	assert.False(t, breached)
+
+	checker.url = server.URL + "/range"
+	breached, err = checker.IsBreached(context.Background(), "password")
+	require.NoError(t, err)
+	assert.True(t, breached)
+}
+
+func TestIntegrationPasswordHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	s := newService(t, sqlStore, map[string]string{"history_count": "2"})
+	ctx := context.Background()
+
+	setPassword := func(t *testing.T, usr *user.User, password string) {
+		t.Helper()
+		require.NoError(t, s.Validate(ctx, usr, password))
+		hashed, err := util.EncodePassword(password, usr.Salt)
+		require.NoError(t, err)
+		require.NoError(t, s.RecordPassword(ctx, usr.ID, hashed, usr.Salt))
+		usr.Password = hashed
+	}
+
+	current, err := util.EncodePassword("Initial-password-0", "salt")
+	require.NoError(t, err)
+	usr := &user.User{ID: 1, Login: "alice", Salt: "salt", Password: current}
+
+	t.Run("rejects the current password set before the history", func(t *testing.T) {
+		assert.Equal(t, []string{passwordpolicy.RuleHistory}, violatedRules(t, s.Validate(ctx, usr, "Initial-password-0")))
+	})
+
+	t.Run("rejects the recent passwords", func(t *testing.T) {
+		setPassword(t, usr, "First-password-1")
+		setPassword(t, usr, "Second-password-2")
+		assert.Equal(t, []string{passwordpolicy.RuleHistory}, violatedRules(t, s.Validate(ctx, usr, "First-password-1")))
+		assert.Equal(t, []string{passwordpolicy.RuleHistory}, violatedRules(t, s.Validate(ctx, usr, "Second-password-2")))
+	})
+
+	t.Run("accepts the passwords older than the history", func(t *testing.T) {
+		setPassword(t, usr, "Third-password-3")
+		assert.NoError(t, s.Validate(ctx, usr, "First-password-1"))
+
+		history, err := s.store.GetHistory(ctx, usr.ID, 10)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("does not reject the passwords of other users", func(t *testing.T) {
+		assert.NoError(t, s.Validate(ctx, &user.User{ID: 2, Login: "bob", Salt: "salt"}, "Third-password-3"))
+	})
+}
@@ -0,0 +1,63 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// historyEntry is a password a user had, hashed with the salt of the user at
+// the time.
+type historyEntry struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	UserID   int64  `xorm:"user_id"`
+	Password string `xorm:"password"`
+	Salt     string `xorm:"salt"`
+	// Created is the time the password was set in epoch milliseconds.
+	Created int64 `xorm:"created"`
+}
+
+func (e historyEntry) TableName() string { return "user_password_history" }
+
+type store interface {
+	// GetHistory returns the most recent passwords of a user, the most recent first.
+	GetHistory(ctx context.Context, userID int64, limit int) ([]*historyEntry, error)
+	// Insert records a password of a user and only keeps its most recent passwords.
+	Insert(ctx context.Context, entry *historyEntry, keep int) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) GetHistory(ctx context.Context, userID int64, limit int) ([]*historyEntry, error) {
+	entries := make([]*historyEntry, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Desc("id").Limit(limit).Find(&entries)
+	})
+	return entries, err
+}
+
+func (s *sqlStore) Insert(ctx context.Context, entry *historyEntry, keep int) error {
+	if entry.Created == 0 {
+		entry.Created = time.Now().UnixMilli()
+	}
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(entry); err != nil {
+			return err
+		}
+
+		var kept []*historyEntry
+		if err := sess.Where("user_id = ?", entry.UserID).Desc("id").Limit(keep).Cols("id").Find(&kept); err != nil {
+			return err
+		}
+		if len(kept) < keep {
+			return nil
+		}
+		_, err := sess.Exec("DELETE FROM user_password_history WHERE user_id = ? AND id < ?", entry.UserID, kept[len(kept)-1].ID)
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+package passwordpolicytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type FakeService struct {
+	ExpectedError error
+	Recorded      []int64
+}
+
+var _ passwordpolicy.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Validate(context.Context, *user.User, string) error {
+	return f.ExpectedError
+}
+
+func (f *FakeService) RecordPassword(_ context.Context, userID int64, _, _ string) error {
+	f.Recorded = append(f.Recorded, userID)
+	return nil
+}
@@ -0,0 +1,10 @@
+package playlist
+
+const (
+	// ActionDevicesRead allows listing the playlist devices and subscribing to their playback commands.
+	ActionDevicesRead = "playlists.devices:read"
+	// ActionDevicesWrite allows registering and removing playlist devices.
+	ActionDevicesWrite = "playlists.devices:write"
+	// ActionDevicesControl allows sending playback commands to the playlist devices.
+	ActionDevicesControl = "playlists.devices:control"
+)
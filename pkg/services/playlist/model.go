@@ -2,12 +2,14 @@ package playlist
 
 import (
 	"errors"
+	"time"
 )
 
 // Typed errors
 var (
 	ErrPlaylistNotFound        = errors.New("Playlist not found")
 	ErrCommandValidationFailed = errors.New("command missing required fields")
+	ErrDeviceNotFound          = errors.New("Playlist device not found")
 )
 
 // Playlist model
@@ -81,6 +83,52 @@ type PlaylistItem struct {
 
 type Playlists []*Playlist
 
+// Device is a screen, such as a kiosk of a video wall, whose playlist playback can be controlled remotely.
+// Devices subscribe to their Grafana Live channel to receive the playback commands.
+type Device struct {
+	ID    int64  `json:"-" xorm:"pk autoincr 'id'"`
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"-" xorm:"org_id"`
+	Name  string `json:"name"`
+	// PlaylistUID is the playlist the device was last asked to play.
+	PlaylistUID string    `json:"playlistUid" xorm:"playlist_uid"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+func (d Device) TableName() string { return "playlist_device" }
+
+// DeviceChannel returns the Grafana Live channel of a device.
+func DeviceChannel(deviceUID string) string {
+	return "grafana/playlist/device/" + deviceUID
+}
+
+// ControlAction is a playback command sent to a device.
+type ControlAction string
+
+const (
+	ControlNext     ControlAction = "next"
+	ControlPrevious ControlAction = "previous"
+	ControlPause    ControlAction = "pause"
+	ControlResume   ControlAction = "resume"
+	// ControlPlay starts a playlist, which replaces the playlist playing on the device.
+	ControlPlay ControlAction = "play"
+)
+
+func (a ControlAction) IsValid() bool {
+	switch a {
+	case ControlNext, ControlPrevious, ControlPause, ControlResume, ControlPlay:
+		return true
+	}
+	return false
+}
+
+// ControlEvent is the message published to the channel of a device.
+type ControlEvent struct {
+	Action      ControlAction `json:"action"`
+	PlaylistUID string        `json:"playlistUid,omitempty"`
+}
+
 //
 // COMMANDS
 //
@@ -107,6 +155,26 @@ type DeletePlaylistCommand struct {
 	OrgId int64
 }
 
+// RegisterDeviceCommand registers a device, or updates the device with the same UID.
+type RegisterDeviceCommand struct {
+	// UID is generated if empty. Devices reuse it to register again after a restart.
+	UID         string `json:"uid"`
+	Name        string `json:"name" binding:"Required"`
+	PlaylistUID string `json:"playlistUid"`
+	OrgID       int64  `json:"-"`
+}
+
+type DeleteDeviceCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type SetDevicePlaylistCommand struct {
+	UID         string
+	OrgID       int64
+	PlaylistUID string
+}
+
 //
 // QUERIES
 //
@@ -127,3 +195,12 @@ type GetPlaylistItemsByUidQuery struct {
 	PlaylistUID string
 	OrgId       int64
 }
+
+type GetDeviceQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type GetDevicesQuery struct {
+	OrgID int64
+}
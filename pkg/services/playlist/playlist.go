@@ -11,4 +11,10 @@ type Service interface {
 	Get(context.Context, *GetPlaylistByUidQuery) (*PlaylistDTO, error)
 	Search(context.Context, *GetPlaylistsQuery) (Playlists, error)
 	Delete(ctx context.Context, cmd *DeletePlaylistCommand) error
+
+	RegisterDevice(context.Context, *RegisterDeviceCommand) (*Device, error)
+	GetDevice(context.Context, *GetDeviceQuery) (*Device, error)
+	SearchDevices(context.Context, *GetDevicesQuery) ([]*Device, error)
+	DeleteDevice(context.Context, *DeleteDeviceCommand) error
+	SetDevicePlaylist(context.Context, *SetDevicePlaylistCommand) error
 }
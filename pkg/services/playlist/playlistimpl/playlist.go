@@ -90,3 +90,33 @@ func (s *Service) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComman
 	defer span.End()
 	return s.store.Delete(ctx, cmd)
 }
+
+func (s *Service) RegisterDevice(ctx context.Context, cmd *playlist.RegisterDeviceCommand) (*playlist.Device, error) {
+	ctx, span := s.tracer.Start(ctx, "playlists.RegisterDevice")
+	defer span.End()
+	return s.store.UpsertDevice(ctx, cmd)
+}
+
+func (s *Service) GetDevice(ctx context.Context, q *playlist.GetDeviceQuery) (*playlist.Device, error) {
+	ctx, span := s.tracer.Start(ctx, "playlists.GetDevice")
+	defer span.End()
+	return s.store.GetDevice(ctx, q)
+}
+
+func (s *Service) SearchDevices(ctx context.Context, q *playlist.GetDevicesQuery) ([]*playlist.Device, error) {
+	ctx, span := s.tracer.Start(ctx, "playlists.SearchDevices")
+	defer span.End()
+	return s.store.ListDevices(ctx, q)
+}
+
+func (s *Service) DeleteDevice(ctx context.Context, cmd *playlist.DeleteDeviceCommand) error {
+	ctx, span := s.tracer.Start(ctx, "playlists.DeleteDevice")
+	defer span.End()
+	return s.store.DeleteDevice(ctx, cmd)
+}
+
+func (s *Service) SetDevicePlaylist(ctx context.Context, cmd *playlist.SetDevicePlaylistCommand) error {
+	ctx, span := s.tracer.Start(ctx, "playlists.SetDevicePlaylist")
+	defer span.End()
+	return s.store.SetDevicePlaylist(ctx, cmd)
+}
@@ -13,4 +13,10 @@ type store interface {
 	GetItems(context.Context, *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error)
 	List(context.Context, *playlist.GetPlaylistsQuery) (playlist.Playlists, error)
 	Update(context.Context, *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error)
+
+	UpsertDevice(context.Context, *playlist.RegisterDeviceCommand) (*playlist.Device, error)
+	GetDevice(context.Context, *playlist.GetDeviceQuery) (*playlist.Device, error)
+	ListDevices(context.Context, *playlist.GetDevicesQuery) ([]*playlist.Device, error)
+	DeleteDevice(context.Context, *playlist.DeleteDeviceCommand) error
+	SetDevicePlaylist(context.Context, *playlist.SetDevicePlaylistCommand) error
 }
@@ -139,6 +139,53 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		})
 	})
 
+	t.Run("Can register playlist devices", func(t *testing.T) {
+		ctx := context.Background()
+		p, err := playlistStore.Insert(ctx, &playlist.CreatePlaylistCommand{Name: "Video wall", Interval: "1m", OrgId: 1, Items: []playlist.PlaylistItem{
+			{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag"},
+		}})
+		require.NoError(t, err)
+
+		device, err := playlistStore.UpsertDevice(ctx, &playlist.RegisterDeviceCommand{Name: "NOC left screen", OrgID: 1, PlaylistUID: p.UID})
+		require.NoError(t, err)
+		require.NotEmpty(t, device.UID)
+
+		t.Run("Registering again with the uid updates the device", func(t *testing.T) {
+			updated, err := playlistStore.UpsertDevice(ctx, &playlist.RegisterDeviceCommand{UID: device.UID, Name: "NOC center screen", OrgID: 1, PlaylistUID: p.UID})
+			require.NoError(t, err)
+			require.Equal(t, device.ID, updated.ID)
+
+			devices, err := playlistStore.ListDevices(ctx, &playlist.GetDevicesQuery{OrgID: 1})
+			require.NoError(t, err)
+			require.Len(t, devices, 1)
+			require.Equal(t, "NOC center screen", devices[0].Name)
+		})
+
+		t.Run("Devices of other orgs are not found", func(t *testing.T) {
+			_, err := playlistStore.GetDevice(ctx, &playlist.GetDeviceQuery{UID: device.UID, OrgID: 2})
+			require.ErrorIs(t, err, playlist.ErrDeviceNotFound)
+			err = playlistStore.SetDevicePlaylist(ctx, &playlist.SetDevicePlaylistCommand{UID: device.UID, OrgID: 2})
+			require.ErrorIs(t, err, playlist.ErrDeviceNotFound)
+		})
+
+		t.Run("Deleting the playlist unsets the playlist of the device", func(t *testing.T) {
+			err := playlistStore.Delete(ctx, &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 1})
+			require.NoError(t, err)
+
+			d, err := playlistStore.GetDevice(ctx, &playlist.GetDeviceQuery{UID: device.UID, OrgID: 1})
+			require.NoError(t, err)
+			require.Empty(t, d.PlaylistUID)
+		})
+
+		t.Run("Can delete the device", func(t *testing.T) {
+			err := playlistStore.DeleteDevice(ctx, &playlist.DeleteDeviceCommand{UID: device.UID, OrgID: 1})
+			require.NoError(t, err)
+
+			err = playlistStore.DeleteDevice(ctx, &playlist.DeleteDeviceCommand{UID: device.UID, OrgID: 1})
+			require.ErrorIs(t, err, playlist.ErrDeviceNotFound)
+		})
+	})
+
 	t.Run("Delete playlist that doesn't exist, should not return error", func(t *testing.T) {
 		deleteQuery := playlist.DeletePlaylistCommand{UID: "654312", OrgId: 1}
 		err := playlistStore.Delete(context.Background(), &deleteQuery)
@@ -154,6 +154,13 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComma
 
 		var rawItemSQL = "DELETE FROM playlist_item WHERE playlist_id = ?"
 		_, err = sess.Exec(rawItemSQL, playlist.Id)
+		if err != nil {
+			return err
+		}
+
+		// The devices playing the playlist keep playing it until they are asked to play another one
+		var rawDeviceSQL = "UPDATE playlist_device SET playlist_uid = '' WHERE playlist_uid = ? and org_id = ?"
+		_, err = sess.Exec(rawDeviceSQL, cmd.UID, cmd.OrgId)
 
 		return err
 	})
@@ -199,3 +206,106 @@ func (s *sqlStore) GetItems(ctx context.Context, query *playlist.GetPlaylistItem
 	})
 	return playlistItems, err
 }
+
+func (s *sqlStore) UpsertDevice(ctx context.Context, cmd *playlist.RegisterDeviceCommand) (*playlist.Device, error) {
+	if cmd.Name == "" || cmd.OrgID == 0 {
+		return nil, playlist.ErrCommandValidationFailed
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	} else if err := util.ValidateUID(cmd.UID); err != nil {
+		return nil, err
+	}
+
+	device := &playlist.Device{UID: cmd.UID, OrgID: cmd.OrgID}
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Get(device)
+		if err != nil {
+			return err
+		}
+
+		device.Name = cmd.Name
+		device.PlaylistUID = cmd.PlaylistUID
+		device.Updated = time.Now()
+		if exists {
+			_, err = sess.ID(device.ID).Cols("name", "playlist_uid", "updated").Update(device)
+			return err
+		}
+		device.Created = device.Updated
+		_, err = sess.Insert(device)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *sqlStore) GetDevice(ctx context.Context, query *playlist.GetDeviceQuery) (*playlist.Device, error) {
+	if query.UID == "" || query.OrgID == 0 {
+		return nil, playlist.ErrCommandValidationFailed
+	}
+
+	device := &playlist.Device{UID: query.UID, OrgID: query.OrgID}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Get(device)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return playlist.ErrDeviceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *sqlStore) ListDevices(ctx context.Context, query *playlist.GetDevicesQuery) ([]*playlist.Device, error) {
+	devices := make([]*playlist.Device, 0)
+	if query.OrgID == 0 {
+		return devices, playlist.ErrCommandValidationFailed
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", query.OrgID).Asc("name").Find(&devices)
+	})
+	return devices, err
+}
+
+func (s *sqlStore) DeleteDevice(ctx context.Context, cmd *playlist.DeleteDeviceCommand) error {
+	if cmd.UID == "" || cmd.OrgID == 0 {
+		return playlist.ErrCommandValidationFailed
+	}
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Delete(&playlist.Device{UID: cmd.UID, OrgID: cmd.OrgID})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return playlist.ErrDeviceNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) SetDevicePlaylist(ctx context.Context, cmd *playlist.SetDevicePlaylistCommand) error {
+	if cmd.UID == "" || cmd.OrgID == 0 {
+		return playlist.ErrCommandValidationFailed
+	}
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.Where("uid = ? AND org_id = ?", cmd.UID, cmd.OrgID).Cols("playlist_uid", "updated").
+			Update(&playlist.Device{PlaylistUID: cmd.PlaylistUID, Updated: time.Now()})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return playlist.ErrDeviceNotFound
+		}
+		return nil
+	})
+}
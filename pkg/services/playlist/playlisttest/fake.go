@@ -11,9 +11,13 @@ type FakePlaylistService struct {
 	ExpectedPlaylistDTO   *playlist.PlaylistDTO
 	ExpectedPlaylistItems []playlist.PlaylistItem
 	ExpectedPlaylists     playlist.Playlists
+	ExpectedDevice        *playlist.Device
+	ExpectedDevices       []*playlist.Device
 	ExpectedError         error
 }
 
+var _ playlist.Service = &FakePlaylistService{}
+
 func NewPlaylistServiveFake() *FakePlaylistService {
 	return &FakePlaylistService{}
 }
@@ -26,10 +30,14 @@ func (f *FakePlaylistService) Update(context.Context, *playlist.UpdatePlaylistCo
 	return f.ExpectedPlaylistDTO, f.ExpectedError
 }
 
-func (f *FakePlaylistService) Get(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
+func (f *FakePlaylistService) GetWithoutItems(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
 	return f.ExpectedPlaylist, f.ExpectedError
 }
 
+func (f *FakePlaylistService) Get(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.PlaylistDTO, error) {
+	return f.ExpectedPlaylistDTO, f.ExpectedError
+}
+
 func (f *FakePlaylistService) GetItems(context.Context, *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error) {
 	return f.ExpectedPlaylistItems, f.ExpectedError
 }
@@ -41,3 +49,23 @@ func (f *FakePlaylistService) Search(context.Context, *playlist.GetPlaylistsQuer
 func (f *FakePlaylistService) Delete(ctx context.Context, cmd *playlist.DeletePlaylistCommand) error {
 	return f.ExpectedError
 }
+
+func (f *FakePlaylistService) RegisterDevice(context.Context, *playlist.RegisterDeviceCommand) (*playlist.Device, error) {
+	return f.ExpectedDevice, f.ExpectedError
+}
+
+func (f *FakePlaylistService) GetDevice(context.Context, *playlist.GetDeviceQuery) (*playlist.Device, error) {
+	return f.ExpectedDevice, f.ExpectedError
+}
+
+func (f *FakePlaylistService) SearchDevices(context.Context, *playlist.GetDevicesQuery) ([]*playlist.Device, error) {
+	return f.ExpectedDevices, f.ExpectedError
+}
+
+func (f *FakePlaylistService) DeleteDevice(context.Context, *playlist.DeleteDeviceCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakePlaylistService) SetDevicePlaylist(context.Context, *playlist.SetDevicePlaylistCommand) error {
+	return f.ExpectedError
+}
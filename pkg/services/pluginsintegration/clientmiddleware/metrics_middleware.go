@@ -7,7 +7,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/prometheus/client_golang/prometheus"
 
-	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/manager/registry"
 	"github.com/grafana/grafana/pkg/plugins/pluginrequestmeta"
@@ -129,23 +129,9 @@ func (m *MetricsMiddleware) instrumentPluginRequest(ctx context.Context, pluginC
 		pluginRequestDurationSecondsLabels = append(pluginRequestDurationSecondsLabels, string(statusSource))
 	}
 
-	pluginRequestDurationWithLabels := m.pluginRequestDuration.WithLabelValues(pluginRequestDurationLabels...)
-	pluginRequestCounterWithLabels := m.pluginRequestCounter.WithLabelValues(pluginRequestCounterLabels...)
-	pluginRequestDurationSecondsWithLabels := m.pluginRequestDurationSeconds.WithLabelValues(pluginRequestDurationSecondsLabels...)
-
-	if traceID := tracing.TraceIDFromContext(ctx, true); traceID != "" {
-		pluginRequestDurationWithLabels.(prometheus.ExemplarObserver).ObserveWithExemplar(
-			float64(elapsed/time.Millisecond), prometheus.Labels{"traceID": traceID},
-		)
-		pluginRequestCounterWithLabels.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"traceID": traceID})
-		pluginRequestDurationSecondsWithLabels.(prometheus.ExemplarObserver).ObserveWithExemplar(
-			elapsed.Seconds(), prometheus.Labels{"traceID": traceID},
-		)
-	} else {
-		pluginRequestDurationWithLabels.Observe(float64(elapsed / time.Millisecond))
-		pluginRequestCounterWithLabels.Inc()
-		pluginRequestDurationSecondsWithLabels.Observe(elapsed.Seconds())
-	}
+	metrics.ObserveWithExemplar(ctx, m.pluginRequestDuration.WithLabelValues(pluginRequestDurationLabels...), float64(elapsed/time.Millisecond))
+	metrics.AddWithExemplar(ctx, m.pluginRequestCounter.WithLabelValues(pluginRequestCounterLabels...), 1)
+	metrics.ObserveWithExemplar(ctx, m.pluginRequestDurationSeconds.WithLabelValues(pluginRequestDurationSecondsLabels...), elapsed.Seconds())
 
 	return err
 }
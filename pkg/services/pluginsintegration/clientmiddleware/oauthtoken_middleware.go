@@ -14,7 +14,8 @@ import (
 
 // NewOAuthTokenMiddleware creates a new plugins.ClientMiddleware that will
 // set OAuth token headers on outgoing plugins.Client requests if the
-// datasource has enabled Forward OAuth Identity (oauthPassThru).
+// datasource has enabled Forward OAuth Identity (oauthPassThru) and the
+// forwarding policy allows it to receive the OAuth identity.
 func NewOAuthTokenMiddleware(oAuthTokenService oauthtoken.OAuthTokenService) plugins.ClientMiddleware {
 	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
 		return &OAuthTokenMiddleware{
@@ -49,37 +50,37 @@ func (m *OAuthTokenMiddleware) applyToken(ctx context.Context, pCtx backend.Plug
 
 	ds := &datasources.DataSource{
 		ID:       settings.ID,
+		UID:      settings.UID,
 		OrgID:    pCtx.OrgID,
+		Type:     pCtx.PluginID,
 		JsonData: jsonDataBytes,
 		Updated:  settings.Updated,
 	}
 
-	if m.oAuthTokenService.IsOAuthPassThruEnabled(ds) {
-		if token := m.oAuthTokenService.GetCurrentOAuthToken(ctx, reqCtx.SignedInUser); token != nil {
-			authorizationHeader := fmt.Sprintf("%s %s", token.Type(), token.AccessToken)
-			idTokenHeader := ""
+	if token := m.oAuthTokenService.GetForwardedOAuthToken(ctx, reqCtx.SignedInUser, ds); token != nil {
+		authorizationHeader := fmt.Sprintf("%s %s", token.Type(), token.AccessToken)
+		idTokenHeader := ""
 
-			idToken, ok := token.Extra("id_token").(string)
-			if ok && idToken != "" {
-				idTokenHeader = idToken
-			}
+		idToken, ok := token.Extra("id_token").(string)
+		if ok && idToken != "" {
+			idTokenHeader = idToken
+		}
 
-			switch t := req.(type) {
-			case *backend.QueryDataRequest:
-				t.Headers[tokenHeaderName] = authorizationHeader
-				if idTokenHeader != "" {
-					t.Headers[idTokenHeaderName] = idTokenHeader
-				}
-			case *backend.CheckHealthRequest:
-				t.Headers[tokenHeaderName] = authorizationHeader
-				if idTokenHeader != "" {
-					t.Headers[idTokenHeaderName] = idTokenHeader
-				}
-			case *backend.CallResourceRequest:
-				t.Headers[tokenHeaderName] = []string{authorizationHeader}
-				if idTokenHeader != "" {
-					t.Headers[idTokenHeaderName] = []string{idTokenHeader}
-				}
+		switch t := req.(type) {
+		case *backend.QueryDataRequest:
+			t.Headers[tokenHeaderName] = authorizationHeader
+			if idTokenHeader != "" {
+				t.Headers[idTokenHeaderName] = idTokenHeader
+			}
+		case *backend.CheckHealthRequest:
+			t.Headers[tokenHeaderName] = authorizationHeader
+			if idTokenHeader != "" {
+				t.Headers[idTokenHeaderName] = idTokenHeader
+			}
+		case *backend.CallResourceRequest:
+			t.Headers[tokenHeaderName] = []string{authorizationHeader}
+			if idTokenHeader != "" {
+				t.Headers[idTokenHeaderName] = []string{idTokenHeader}
 			}
 		}
 	}
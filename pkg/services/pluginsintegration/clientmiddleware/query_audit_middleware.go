@@ -0,0 +1,101 @@
+package clientmiddleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+)
+
+// NewQueryAuditMiddleware creates a new plugins.ClientMiddleware that will
+// record the queries sent to the data sources that have query audit enabled.
+func NewQueryAuditMiddleware(queryAudit queryaudit.Service) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &QueryAuditMiddleware{
+			next:       next,
+			queryAudit: queryAudit,
+		}
+	})
+}
+
+type QueryAuditMiddleware struct {
+	next       plugins.Client
+	queryAudit queryaudit.Service
+}
+
+func (m *QueryAuditMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil || req.PluginContext.DataSourceInstanceSettings == nil ||
+		!queryaudit.IsEnabledForDataSource(req.PluginContext.DataSourceInstanceSettings.JSONData) {
+		return m.next.QueryData(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := m.next.QueryData(ctx, req)
+	m.queryAudit.Record(ctx, newQueryAuditRecordCommand(ctx, req, resp, err, time.Since(start)))
+	return resp, err
+}
+
+func newQueryAuditRecordCommand(ctx context.Context, req *backend.QueryDataRequest, resp *backend.QueryDataResponse, err error, duration time.Duration) *queryaudit.RecordCommand {
+	cmd := &queryaudit.RecordCommand{
+		OrgID:          req.PluginContext.OrgID,
+		DataSourceUID:  req.PluginContext.DataSourceInstanceSettings.UID,
+		DataSourceType: req.PluginContext.PluginID,
+		DashboardUID:   req.GetHTTPHeader(query.HeaderDashboardUID),
+		PanelID:        req.GetHTTPHeader(query.HeaderPanelID),
+		Duration:       duration,
+		Queries:        make([]queryaudit.Query, 0, len(req.Queries)),
+	}
+
+	// Queries executed on behalf of a request are recorded with the signed in
+	// user, the other ones, such as alert rule evaluations, with the user of
+	// the plugin context if any.
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SignedInUser != nil {
+		namespace, id := reqCtx.SignedInUser.GetNamespacedID()
+		cmd.UserID = namespace + ":" + id
+		cmd.UserLogin = reqCtx.SignedInUser.GetLogin()
+	} else if req.PluginContext.User != nil {
+		cmd.UserLogin = req.PluginContext.User.Login
+	}
+
+	for _, q := range req.Queries {
+		auditQuery := queryaudit.Query{RefID: q.RefID, JSON: q.JSON}
+		if err != nil {
+			auditQuery.Error = err.Error()
+		} else if resp != nil {
+			if r, ok := resp.Responses[q.RefID]; ok && r.Error != nil {
+				auditQuery.Error = r.Error.Error()
+			}
+		}
+		cmd.Queries = append(cmd.Queries, auditQuery)
+	}
+	return cmd
+}
+
+func (m *QueryAuditMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *QueryAuditMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return m.next.CheckHealth(ctx, req)
+}
+
+func (m *QueryAuditMiddleware) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	return m.next.CollectMetrics(ctx, req)
+}
+
+func (m *QueryAuditMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	return m.next.SubscribeStream(ctx, req)
+}
+
+func (m *QueryAuditMiddleware) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return m.next.PublishStream(ctx, req)
+}
+
+func (m *QueryAuditMiddleware) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return m.next.RunStream(ctx, req, sender)
+}
@@ -0,0 +1,105 @@
+package clientmiddleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/queryaudit/queryaudittest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestQueryAuditMiddleware(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	require.NoError(t, err)
+
+	newQueryDataRequest := func(jsonData string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:    1,
+				PluginID: "grafana-postgresql-datasource",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:      "postgres",
+					JSONData: []byte(jsonData),
+				},
+			},
+			Headers: map[string]string{
+				"http_" + query.HeaderDashboardUID: "dash",
+				"http_" + query.HeaderPanelID:      "2",
+			},
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"rawSql":"SELECT 1"}`)},
+				{RefID: "B", JSON: []byte(`{"rawSql":"SELECT 2"}`)},
+			},
+		}
+	}
+
+	t.Run("Should record the queries of data sources with query audit enabled", func(t *testing.T) {
+		fake := queryaudittest.NewFakeService()
+		cdt := clienttest.NewClientDecoratorTest(t,
+			clienttest.WithReqContext(req, &user.SignedInUser{UserID: 1, Login: "admin"}),
+			clienttest.WithMiddlewares(NewQueryAuditMiddleware(fake)),
+		)
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {},
+				"B": {Error: errors.New("syntax error")},
+			}}, nil
+		}
+
+		_, err := cdt.Decorator.QueryData(cdt.Context, newQueryDataRequest(`{"auditQueries":true}`))
+		require.NoError(t, err)
+
+		require.Len(t, fake.Recorded, 1)
+		cmd := fake.Recorded[0]
+		require.Equal(t, int64(1), cmd.OrgID)
+		require.Equal(t, "postgres", cmd.DataSourceUID)
+		require.Equal(t, "grafana-postgresql-datasource", cmd.DataSourceType)
+		require.Equal(t, "user:1", cmd.UserID)
+		require.Equal(t, "admin", cmd.UserLogin)
+		require.Equal(t, "dash", cmd.DashboardUID)
+		require.Equal(t, "2", cmd.PanelID)
+		require.Len(t, cmd.Queries, 2)
+		require.Equal(t, "A", cmd.Queries[0].RefID)
+		require.Empty(t, cmd.Queries[0].Error)
+		require.Equal(t, "syntax error", cmd.Queries[1].Error)
+	})
+
+	t.Run("Should record the queries with the error of the request", func(t *testing.T) {
+		fake := queryaudittest.NewFakeService()
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryAuditMiddleware(fake)))
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return nil, errors.New("plugin unavailable")
+		}
+
+		queryDataReq := newQueryDataRequest(`{"auditQueries":true}`)
+		queryDataReq.PluginContext.User = &backend.User{Login: "grafana_scheduler"}
+		_, err := cdt.Decorator.QueryData(context.Background(), queryDataReq)
+		require.Error(t, err)
+
+		require.Len(t, fake.Recorded, 1)
+		cmd := fake.Recorded[0]
+		require.Empty(t, cmd.UserID)
+		require.Equal(t, "grafana_scheduler", cmd.UserLogin)
+		for _, q := range cmd.Queries {
+			require.Equal(t, "plugin unavailable", q.Error)
+		}
+	})
+
+	t.Run("Should not record the queries of data sources without query audit enabled", func(t *testing.T) {
+		fake := queryaudittest.NewFakeService()
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryAuditMiddleware(fake)))
+
+		for _, jsonData := range []string{``, `{}`, `{"auditQueries":false}`} {
+			_, err := cdt.Decorator.QueryData(context.Background(), newQueryDataRequest(jsonData))
+			require.NoError(t, err)
+		}
+		require.Empty(t, fake.Recorded)
+	})
+}
@@ -0,0 +1,55 @@
+// Package dsquerier lets core data source plugins query other data sources of
+// the org from the backend, so that features joining the data of several data
+// sources also work in alerting and rendered reports.
+package dsquerier
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/tsdb/tempo"
+)
+
+type Service struct {
+	dataSources   datasources.DataSourceService
+	pluginContext *plugincontext.Provider
+	pluginClient  plugins.Client
+}
+
+// ProvideService creates the querier and sets it on the core data source plugins that query other data sources.
+// The core plugins are created before the plugin client, which is why the querier is set once both exist.
+func ProvideService(dataSources datasources.DataSourceService, pluginContextProvider *plugincontext.Provider,
+	pluginClient plugins.Client, tempoService *tempo.Service) *Service {
+	s := &Service{
+		dataSources:   dataSources,
+		pluginContext: pluginContextProvider,
+		pluginClient:  pluginClient,
+	}
+	tempoService.SetDataSourceQuerier(s)
+	return s
+}
+
+// QueryDataSource runs the queries against the data source with the UID in the org of the plugin context of the
+// data source making the request.
+func (s *Service) QueryDataSource(ctx context.Context, pCtx backend.PluginContext, uid string, queries []backend.DataQuery) (*backend.QueryDataResponse, error) {
+	ds, err := s.dataSources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: pCtx.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	dsPluginCtx, err := s.pluginContext.GetWithDataSource(ctx, ds.Type, nil, ds)
+	if err != nil {
+		return nil, err
+	}
+	dsPluginCtx.OrgID = pCtx.OrgID
+	dsPluginCtx.User = pCtx.User
+
+	return s.pluginClient.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: dsPluginCtx,
+		Queries:       queries,
+	})
+}
@@ -21,17 +21,22 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/adapters"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/secrets/secretrefs"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
 	pluginSettingsCacheTTL    = 5 * time.Second
 	pluginSettingsCachePrefix = "plugin-setting-"
+
+	// Instance settings of data sources are invalidated when data sources are updated or deleted,
+	// the TTL only bounds how long the settings of unused data sources are kept in memory.
+	instanceSettingsCacheTTL = 10 * time.Minute
 )
 
 func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, pluginStore pluginstore.Store,
 	dataSourceService datasources.DataSourceService, pluginSettingsService pluginsettings.Service,
-	licensing plugins.Licensing, pCfg *config.Cfg) *Provider {
+	licensing plugins.Licensing, pCfg *config.Cfg, secretReferences *secretrefs.Service) *Provider {
 	return &Provider{
 		cfg:                   cfg,
 		cacheService:          cacheService,
@@ -39,6 +44,8 @@ func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, plu
 		dataSourceService:     dataSourceService,
 		pluginSettingsService: pluginSettingsService,
 		pluginEnvVars:         envvars.NewProvider(pCfg, licensing),
+		secretReferences:      secretReferences,
+		instanceSettingsCache: localcache.New(instanceSettingsCacheTTL, 2*instanceSettingsCacheTTL),
 		logger:                log.New("plugin.context"),
 	}
 }
@@ -50,6 +57,9 @@ type Provider struct {
 	pluginStore           pluginstore.Store
 	dataSourceService     datasources.DataSourceService
 	pluginSettingsService pluginsettings.Service
+	secretReferences      *secretrefs.Service
+	// instanceSettingsCache caches the instance settings of data sources with their decrypted secrets.
+	instanceSettingsCache *localcache.CacheService
 	logger                log.Logger
 }
 
@@ -105,13 +115,31 @@ func (p *Provider) GetWithDataSource(ctx context.Context, pluginID string, user
 	if user != nil && !user.IsNil() {
 		pCtx.OrgID = user.GetOrgID()
 		pCtx.User = adapters.BackendUserFromSignedInUser(user)
+
+		// Data sources shared by other orgs can only be used by the users allowed by the access policy of their org.
+		if ds.Share != nil && !ds.Share.CanQuery(user.GetOrgRole()) {
+			return pCtx, datasources.ErrDataSourceAccessDenied
+		}
 	}
 
-	datasourceSettings, err := adapters.ModelToInstanceSettings(ds, p.decryptSecureJsonDataFn(ctx))
+	cachedSettings, err := p.dataSourceInstanceSettings(ctx, ds)
 	if err != nil {
 		return pCtx, err
 	}
-	pCtx.DataSourceInstanceSettings = datasourceSettings
+
+	// Secrets can be references to secrets of external stores. The instance is
+	// marked as updated when one of them changes, so that plugins rebuild it.
+	resolved, changed, err := p.secretReferences.Resolve(ctx, cachedSettings.DecryptedSecureJSONData)
+	if err != nil {
+		return pCtx, err
+	}
+	// The cached settings are shared, so they are copied before being completed.
+	datasourceSettings := *cachedSettings
+	datasourceSettings.DecryptedSecureJSONData = resolved
+	if changed.After(datasourceSettings.Updated) {
+		datasourceSettings.Updated = changed
+	}
+	pCtx.DataSourceInstanceSettings = &datasourceSettings
 
 	settings := p.pluginEnvVars.GetConfigMap(ctx, pluginID, plugin.ExternalService)
 	pCtx.GrafanaConfig = backend.NewGrafanaCfg(settings)
@@ -125,6 +153,39 @@ func (p *Provider) GetWithDataSource(ctx context.Context, pluginID string, user
 	return pCtx, nil
 }
 
+// dataSourceInstanceSettings returns the instance settings of the data source, from the cache if they
+// were built for the same version of the data source.
+func (p *Provider) dataSourceInstanceSettings(ctx context.Context, ds *datasources.DataSource) (*backend.DataSourceInstanceSettings, error) {
+	// Data sources without an update time can't be told apart from their previous versions.
+	cacheable := ds.UID != "" && !ds.Updated.IsZero()
+	cacheKey := getInstanceSettingsCacheKey(ds.OrgID, ds.UID)
+
+	if cacheable {
+		if cached, found := p.instanceSettingsCache.Get(cacheKey); found {
+			settings := cached.(*backend.DataSourceInstanceSettings)
+			if settings.ID == ds.ID && settings.Updated.Equal(ds.Updated) {
+				return settings, nil
+			}
+		}
+	}
+
+	settings, err := adapters.ModelToInstanceSettings(ds, p.decryptSecureJsonDataFn(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		p.instanceSettingsCache.SetDefault(cacheKey, settings)
+	}
+	return settings, nil
+}
+
+// InvalidateDataSourceSettings removes the cached instance settings of a data source, so that
+// the next plugin context of the data source is built with its current settings.
+func (p *Provider) InvalidateDataSourceSettings(orgID int64, uid string) {
+	p.instanceSettingsCache.Delete(getInstanceSettingsCacheKey(orgID, uid))
+}
+
 func (p *Provider) appInstanceSettings(ctx context.Context, pluginID string, orgID int64) (*backend.AppInstanceSettings, error) {
 	jsonData := json.RawMessage{}
 	decryptedSecureJSONData := map[string]string{}
@@ -188,3 +249,7 @@ func (p *Provider) decryptSecureJsonDataFn(ctx context.Context) func(ds *datasou
 func getCacheKey(pluginID string) string {
 	return pluginSettingsCachePrefix + pluginID
 }
+
+func getInstanceSettingsCacheKey(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s", orgID, uid)
+}
@@ -2,7 +2,10 @@ package plugincontext_test
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -20,6 +23,7 @@ import (
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	secretstest "github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/secrets/secretrefs"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -43,7 +47,7 @@ func TestGet(t *testing.T) {
 	db := &dbtest.FakeDB{ExpectedError: pluginsettings.ErrPluginSettingNotFound}
 	pcp := plugincontext.ProvideService(cfg, localcache.ProvideService(),
 		pluginstore.New(preg, &pluginFakes.FakeLoader{}),
-		ds, pluginSettings.ProvideService(db, secretstest.NewFakeSecretsService()), pluginFakes.NewFakeLicensingService(), &config.Cfg{},
+		ds, pluginSettings.ProvideService(db, secretstest.NewFakeSecretsService()), pluginFakes.NewFakeLicensingService(), &config.Cfg{}, secretrefs.ProvideService(cfg),
 	)
 	identity := &user.SignedInUser{OrgID: int64(1), Login: "admin"}
 
@@ -75,3 +79,104 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWithDataSource_SecretReferences(t *testing.T) {
+	const pluginID = "plugin-id"
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"data": {"password": "from-vault"}, "metadata": {"version": 1}}}`))
+	}))
+	t.Cleanup(vault.Close)
+
+	preg := registry.NewInMemory()
+	require.NoError(t, preg.Add(context.Background(), &plugins.Plugin{JSONData: plugins.JSONData{ID: pluginID}}))
+
+	cfg := setting.NewCfg()
+	section := cfg.Raw.Section("secret_references")
+	section.Key("enabled").SetValue("true")
+	section.Key("vault_address").SetValue(vault.URL)
+
+	ds := &secureJSONDataSourceService{values: map[string]string{
+		"password": "ref+vault://secret/data/grafana#password",
+		"token":    "plain",
+	}}
+	db := &dbtest.FakeDB{ExpectedError: pluginsettings.ErrPluginSettingNotFound}
+	pcp := plugincontext.ProvideService(cfg, localcache.ProvideService(),
+		pluginstore.New(preg, &pluginFakes.FakeLoader{}),
+		ds, pluginSettings.ProvideService(db, secretstest.NewFakeSecretsService()), pluginFakes.NewFakeLicensingService(), &config.Cfg{}, secretrefs.ProvideService(cfg),
+	)
+
+	updated := time.Now().Add(-time.Hour)
+	pCtx, err := pcp.GetWithDataSource(context.Background(), pluginID, nil, &datasources.DataSource{
+		ID:       1,
+		OrgID:    1,
+		Name:     "test",
+		Type:     pluginID,
+		JsonData: simplejson.New(),
+		Updated:  updated,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"password": "from-vault", "token": "plain"}, pCtx.DataSourceInstanceSettings.DecryptedSecureJSONData)
+	// The instance is marked as updated when the secret was fetched, so that it is rebuilt when the secret changes.
+	require.True(t, pCtx.DataSourceInstanceSettings.Updated.After(updated))
+}
+
+func TestGetWithDataSource_InstanceSettingsCache(t *testing.T) {
+	const pluginID = "plugin-id"
+
+	preg := registry.NewInMemory()
+	require.NoError(t, preg.Add(context.Background(), &plugins.Plugin{JSONData: plugins.JSONData{ID: pluginID}}))
+
+	cfg := setting.NewCfg()
+	ds := &secureJSONDataSourceService{values: map[string]string{"password": "first"}}
+	db := &dbtest.FakeDB{ExpectedError: pluginsettings.ErrPluginSettingNotFound}
+	pcp := plugincontext.ProvideService(cfg, localcache.ProvideService(),
+		pluginstore.New(preg, &pluginFakes.FakeLoader{}),
+		ds, pluginSettings.ProvideService(db, secretstest.NewFakeSecretsService()), pluginFakes.NewFakeLicensingService(), &config.Cfg{}, secretrefs.ProvideService(cfg),
+	)
+
+	dataSource := &datasources.DataSource{
+		ID:       1,
+		UID:      "uid",
+		OrgID:    1,
+		Name:     "test",
+		Type:     pluginID,
+		JsonData: simplejson.New(),
+		Updated:  time.Now().Add(-time.Hour),
+	}
+	password := func(t *testing.T) string {
+		t.Helper()
+		pCtx, err := pcp.GetWithDataSource(context.Background(), pluginID, nil, dataSource)
+		require.NoError(t, err)
+		return pCtx.DataSourceInstanceSettings.DecryptedSecureJSONData["password"]
+	}
+
+	require.Equal(t, "first", password(t))
+	require.Equal(t, "first", password(t))
+	require.Equal(t, 1, ds.calls)
+
+	t.Run("invalidated settings are decrypted again", func(t *testing.T) {
+		ds.values = map[string]string{"password": "second"}
+		pcp.InvalidateDataSourceSettings(dataSource.OrgID, dataSource.UID)
+		require.Equal(t, "second", password(t))
+		require.Equal(t, 2, ds.calls)
+	})
+
+	t.Run("settings of a previous version of the data source are not used", func(t *testing.T) {
+		ds.values = map[string]string{"password": "third"}
+		dataSource.Updated = time.Now()
+		require.Equal(t, "third", password(t))
+		require.Equal(t, 3, ds.calls)
+	})
+}
+
+type secureJSONDataSourceService struct {
+	fakeDatasources.FakeDataSourceService
+	values map[string]string
+	calls  int
+}
+
+func (s *secureJSONDataSourceService) DecryptedValues(context.Context, *datasources.DataSource) (map[string]string, error) {
+	s.calls++
+	return s.values, nil
+}
@@ -0,0 +1,44 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMaintenanceWindows(t *testing.T) {
+	t.Run("valid maintenance window should map to model", func(t *testing.T) {
+		var mw MaintenanceWindowV1
+		err := yaml.Unmarshal([]byte(`
+uid: db-upgrade
+title: Database upgrade
+matchers:
+  - ["team", "=", "db"]
+dashboardUids: [db-overview]
+startsAt: 2026-10-20T22:00:00Z
+endsAt: 2026-10-21T02:00:00Z
+`), &mw)
+		require.NoError(t, err)
+
+		res, err := mw.mapToModel()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.OrgID)
+		require.Equal(t, "Database upgrade", res.MaintenanceWindow.Title)
+		require.Equal(t, `team="db"`, res.MaintenanceWindow.Matchers[0].String())
+		require.Equal(t, []string{"db-overview"}, res.MaintenanceWindow.DashboardUIDs)
+		require.Equal(t, 4*time.Hour, res.MaintenanceWindow.EndsAt.Sub(res.MaintenanceWindow.StartsAt))
+		require.NoError(t, res.MaintenanceWindow.Validate())
+	})
+	t.Run("maintenance window without uid should error on mapping", func(t *testing.T) {
+		mw := MaintenanceWindowV1{}
+		mw.MaintenanceWindow.Title = "Database upgrade"
+		_, err := mw.mapToModel()
+		require.Error(t, err)
+	})
+	t.Run("delete maintenance window without uid should error on mapping", func(t *testing.T) {
+		_, err := (&DeleteMaintenanceWindowV1{}).mapToModel()
+		require.Error(t, err)
+	})
+}
@@ -15,32 +15,36 @@ type OrgID int64
 
 type AlertingFile struct {
 	configVersion
-	Filename            string
-	Groups              []models.AlertRuleGroupWithFolderTitle
-	DeleteRules         []RuleDelete
-	ContactPoints       []ContactPoint
-	DeleteContactPoints []DeleteContactPoint
-	Policies            []NotificiationPolicy
-	ResetPolicies       []OrgID
-	MuteTimes           []MuteTime
-	DeleteMuteTimes     []DeleteMuteTime
-	Templates           []Template
-	DeleteTemplates     []DeleteTemplate
+	Filename                 string
+	Groups                   []models.AlertRuleGroupWithFolderTitle
+	DeleteRules              []RuleDelete
+	ContactPoints            []ContactPoint
+	DeleteContactPoints      []DeleteContactPoint
+	Policies                 []NotificiationPolicy
+	ResetPolicies            []OrgID
+	MuteTimes                []MuteTime
+	DeleteMuteTimes          []DeleteMuteTime
+	Templates                []Template
+	DeleteTemplates          []DeleteTemplate
+	MaintenanceWindows       []MaintenanceWindow
+	DeleteMaintenanceWindows []DeleteMaintenanceWindow
 }
 
 type AlertingFileV1 struct {
 	configVersion
-	Filename            string
-	Groups              []AlertRuleGroupV1      `json:"groups" yaml:"groups"`
-	DeleteRules         []RuleDeleteV1          `json:"deleteRules" yaml:"deleteRules"`
-	ContactPoints       []ContactPointV1        `json:"contactPoints" yaml:"contactPoints"`
-	DeleteContactPoints []DeleteContactPointV1  `json:"deleteContactPoints" yaml:"deleteContactPoints"`
-	Policies            []NotificiationPolicyV1 `json:"policies" yaml:"policies"`
-	ResetPolicies       []values.Int64Value     `json:"resetPolicies" yaml:"resetPolicies"`
-	MuteTimes           []MuteTimeV1            `json:"muteTimes" yaml:"muteTimes"`
-	DeleteMuteTimes     []DeleteMuteTimeV1      `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
-	Templates           []TemplateV1            `json:"templates" yaml:"templates"`
-	DeleteTemplates     []DeleteTemplateV1      `json:"deleteTemplates" yaml:"deleteTemplates"`
+	Filename                 string
+	Groups                   []AlertRuleGroupV1          `json:"groups" yaml:"groups"`
+	DeleteRules              []RuleDeleteV1              `json:"deleteRules" yaml:"deleteRules"`
+	ContactPoints            []ContactPointV1            `json:"contactPoints" yaml:"contactPoints"`
+	DeleteContactPoints      []DeleteContactPointV1      `json:"deleteContactPoints" yaml:"deleteContactPoints"`
+	Policies                 []NotificiationPolicyV1     `json:"policies" yaml:"policies"`
+	ResetPolicies            []values.Int64Value         `json:"resetPolicies" yaml:"resetPolicies"`
+	MuteTimes                []MuteTimeV1                `json:"muteTimes" yaml:"muteTimes"`
+	DeleteMuteTimes          []DeleteMuteTimeV1          `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
+	Templates                []TemplateV1                `json:"templates" yaml:"templates"`
+	DeleteTemplates          []DeleteTemplateV1          `json:"deleteTemplates" yaml:"deleteTemplates"`
+	MaintenanceWindows       []MaintenanceWindowV1       `json:"maintenanceWindows" yaml:"maintenanceWindows"`
+	DeleteMaintenanceWindows []DeleteMaintenanceWindowV1 `json:"deleteMaintenanceWindows" yaml:"deleteMaintenanceWindows"`
 }
 
 func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
@@ -61,9 +65,30 @@ func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
 	if err := fileV1.mapTemplates(&alertingFile); err != nil {
 		return AlertingFile{}, fmt.Errorf("failure parsing templates: %w", err)
 	}
+	if err := fileV1.mapMaintenanceWindows(&alertingFile); err != nil {
+		return AlertingFile{}, fmt.Errorf("failure parsing maintenance windows: %w", err)
+	}
 	return alertingFile, nil
 }
 
+func (fileV1 *AlertingFileV1) mapMaintenanceWindows(alertingFile *AlertingFile) error {
+	for _, mwV1 := range fileV1.MaintenanceWindows {
+		mw, err := mwV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.MaintenanceWindows = append(alertingFile.MaintenanceWindows, mw)
+	}
+	for _, deleteV1 := range fileV1.DeleteMaintenanceWindows {
+		delReq, err := deleteV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.DeleteMaintenanceWindows = append(alertingFile.DeleteMaintenanceWindows, delReq)
+	}
+	return nil
+}
+
 func (fileV1 *AlertingFileV1) mapTemplates(alertingFile *AlertingFile) error {
 	for _, ttV1 := range fileV1.Templates {
 		alertingFile.Templates = append(alertingFile.Templates, ttV1.mapToModel())
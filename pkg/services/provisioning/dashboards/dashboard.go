@@ -139,6 +139,12 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "oci":
+			ociReader, err := newOCIDashboardReader(config, logger.New("type", config.Type, "name", config.Name), service, store)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create OCI reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, ociReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
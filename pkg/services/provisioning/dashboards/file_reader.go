@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	dashboardimportutils "github.com/grafana/grafana/pkg/services/dashboardimport/utils"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/util"
@@ -34,6 +37,9 @@ type FileReader struct {
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 	dashboardStore               utils.DashboardStore
 	FoldersFromFilesStructure    bool
+	datasourceMapper             *dashboardimportutils.DatasourceMapper
+	// source pulls the dashboards into Path before they are read, for providers of type oci.
+	source *ociSource
 
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
@@ -58,6 +64,11 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
 	}
 
+	datasourceMapper, err := datasourceMapperFromOptions(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileReader{
 		Cfg:                          cfg,
 		Path:                         path,
@@ -65,10 +76,29 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		dashboardProvisioningService: service,
 		dashboardStore:               dashboardStore,
 		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		datasourceMapper:             datasourceMapper,
 		usageTracker:                 newUsageTracker(),
 	}, nil
 }
 
+// datasourceMapperFromOptions returns the mapper of the datasourceMappings option, nil if it is not set.
+func datasourceMapperFromOptions(options map[string]any) (*dashboardimportutils.DatasourceMapper, error) {
+	mappings, ok := options["datasourceMappings"]
+	if !ok {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(mappings)
+	if err != nil {
+		return nil, err
+	}
+	var rules []dashboardimport.DatasourceMappingRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("failed to load dashboards, datasourceMappings param is not valid: %w", err)
+	}
+	return dashboardimportutils.NewDatasourceMapper(rules)
+}
+
 // pollChanges periodically runs walkDisk based on interval specified in the config.
 func (fr *FileReader) pollChanges(ctx context.Context) {
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
@@ -87,6 +117,13 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	if fr.source != nil {
+		// The dashboards pulled before, if any, keep being provisioned while the registry is unavailable.
+		if err := fr.source.sync(ctx); err != nil {
+			fr.log.Error("Failed to pull dashboards from OCI registry", "reference", fr.source.ref.String(), "error", err)
+		}
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -437,6 +474,10 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 		return nil, err
 	}
 
+	if fr.datasourceMapper != nil {
+		data = fr.mapDatasources(path, data)
+	}
+
 	dash, err := createDashboardJSON(data, lastModified, fr.Cfg, folderID, folderUID)
 	if err != nil {
 		return nil, err
@@ -449,6 +490,25 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 	}, nil
 }
 
+// mapDatasources substitutes the datasource inputs of an exported dashboard and maps its datasource references
+// with the rules of the provider.
+func (fr *FileReader) mapDatasources(path string, data *simplejson.Json) *simplejson.Json {
+	if _, ok := data.CheckGet("__inputs"); ok {
+		inputs, _ := fr.datasourceMapper.Inputs(data, nil)
+		evaluated, err := dashboardimportutils.NewDashTemplateEvaluator(data, inputs).Eval()
+		if err != nil {
+			fr.log.Warn("Failed to substitute the inputs of the dashboard", "file", path, "error", err)
+		} else {
+			data = evaluated
+		}
+	}
+
+	for _, change := range fr.datasourceMapper.Apply(data) {
+		fr.log.Debug("Mapped datasource", "file", path, "path", change.Path, "before", change.Before, "after", change.After)
+	}
+	return data
+}
+
 func (fr *FileReader) resolvedPath() string {
 	if _, err := os.Stat(fr.Path); os.IsNotExist(err) {
 		fr.log.Error("Cannot read directory", "error", err)
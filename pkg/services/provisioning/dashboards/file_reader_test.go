@@ -24,6 +24,7 @@ const (
 	containingID              = "testdata/test-dashboards/containing-id"
 	unprovision               = "testdata/test-dashboards/unprovision"
 	foldersFromFilesStructure = "testdata/test-dashboards/folders-from-files-structure"
+	datasourceMappings        = "testdata/test-dashboards/datasource-mappings"
 	configName                = "default"
 )
 
@@ -63,6 +64,23 @@ func TestCreatingNewDashboardFileReader(t *testing.T) {
 		require.NotEqual(t, reader.Path, "")
 	})
 
+	t.Run("using datasourceMappings as options", func(t *testing.T) {
+		cfg := setup()
+		cfg.Options["path"] = defaultDashboards
+		cfg.Options["datasourceMappings"] = []any{
+			map[string]any{"type": "prometheus", "datasourceUid": "mimir"},
+		}
+		reader, err := NewDashboardFileReader(cfg, log.New("test-logger"), nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, reader.datasourceMapper)
+
+		cfg.Options["datasourceMappings"] = []any{
+			map[string]any{"nameRegex": "(", "datasourceUid": "mimir"},
+		}
+		_, err = NewDashboardFileReader(cfg, log.New("test-logger"), nil, nil)
+		require.Error(t, err)
+	})
+
 	t.Run("using full path", func(t *testing.T) {
 		cfg := setup()
 		fullPath := "/var/lib/grafana/dashboards"
@@ -304,6 +322,24 @@ func TestDashboardFileReader(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("Maps the datasources of dashboards", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = datasourceMappings
+			cfg.Options["datasourceMappings"] = []any{
+				map[string]any{"type": "prometheus", "datasourceUid": "mimir"},
+				map[string]any{"uid": "loki-eu", "datasourceUid": "loki"},
+			}
+
+			reader, err := NewDashboardFileReader(cfg, logger, nil, fakeStore)
+			require.NoError(t, err)
+
+			jsonFile, err := reader.readDashboardFromFile(filepath.Join(datasourceMappings, "dashboard.json"), time.Now(), 0, "")
+			require.NoError(t, err)
+			panels := jsonFile.dashboard.Dashboard.Data.Get("panels")
+			require.Equal(t, "mimir", panels.GetIndex(0).Get("datasource").MustString())
+			require.Equal(t, map[string]any{"type": "loki", "uid": "loki"}, panels.GetIndex(1).Get("datasource").MustMap())
+		})
+
 		t.Run("Invalid configuration should return error", func(t *testing.T) {
 			setup()
 			cfg := &config{
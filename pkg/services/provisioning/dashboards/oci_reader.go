@@ -0,0 +1,245 @@
+package dashboards
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// maxOCIBundleSize is the maximum size of the layers of an artifact, and of the dashboards extracted from them.
+const maxOCIBundleSize = 100 << 20
+
+// ociSource pulls the dashboards of an OCI artifact into the path of a FileReader, which
+// provisions them like dashboards stored on disk.
+type ociSource struct {
+	path      string
+	ref       ociReference
+	publicKey crypto.PublicKey
+	client    *ociClient
+	log       log.Logger
+
+	// synced is the digest of the manifest whose dashboards are in path.
+	synced string
+}
+
+// newOCIDashboardReader returns a FileReader provisioning the dashboards of the OCI artifact
+// configured in the options. The dashboards are extracted into the path option.
+func newOCIDashboardReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService, dashboardStore utils.DashboardStore) (*FileReader, error) {
+	reference, _ := cfg.Options["reference"].(string)
+	if reference == "" {
+		return nil, fmt.Errorf("failed to load dashboards, reference param is not a string")
+	}
+	ref, err := parseOCIReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dashboards: %w", err)
+	}
+	if digest, _ := cfg.Options["digest"].(string); digest != "" {
+		if !ociDigestRegex.MatchString(digest) {
+			return nil, fmt.Errorf("failed to load dashboards, invalid digest %q, only sha256 digests are supported", digest)
+		}
+		if ref.Digest != "" && ref.Digest != digest {
+			return nil, fmt.Errorf("failed to load dashboards, digest param does not match the digest of the reference")
+		}
+		ref.Digest = digest
+	}
+
+	var publicKey crypto.PublicKey
+	if keyPath, _ := cfg.Options["publicKeyPath"].(string); keyPath != "" {
+		if publicKey, err = readCosignPublicKey(keyPath); err != nil {
+			return nil, fmt.Errorf("failed to load dashboards: %w", err)
+		}
+	}
+
+	reader, err := NewDashboardFileReader(cfg, log, service, dashboardStore)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := cfg.Options["username"].(string)
+	password, _ := cfg.Options["password"].(string)
+	plainHTTP, _ := cfg.Options["plainHTTP"].(bool)
+	reader.source = &ociSource{
+		path:      reader.Path,
+		ref:       ref,
+		publicKey: publicKey,
+		client: &ociClient{
+			client:    &http.Client{Timeout: time.Minute},
+			plainHTTP: plainHTTP,
+			username:  username,
+			password:  password,
+		},
+		log: log,
+	}
+	return reader, nil
+}
+
+// sync pulls the artifact when its manifest changed since the last pull. The dashboards in
+// path are only replaced once the whole artifact is downloaded and verified.
+func (s *ociSource) sync(ctx context.Context) error {
+	// The content of a pinned artifact cannot change.
+	if s.synced != "" && s.ref.Digest != "" {
+		return nil
+	}
+
+	manifest, digest, err := s.client.manifest(ctx, s.ref)
+	if err != nil {
+		return err
+	}
+	if digest == s.synced {
+		return nil
+	}
+	if s.publicKey != nil {
+		if err := s.client.verifySignature(ctx, s.ref, digest, s.publicKey); err != nil {
+			return fmt.Errorf("failed to verify signature of %s: %w", s.ref, err)
+		}
+	}
+
+	files, err := s.pull(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	if err := replaceDirectory(s.path, files); err != nil {
+		return fmt.Errorf("failed to write dashboards of %s: %w", s.ref, err)
+	}
+
+	s.log.Info("Pulled dashboards from OCI registry", "reference", s.ref.String(), "digest", digest, "files", len(files))
+	s.synced = digest
+	return nil
+}
+
+// pull returns the dashboards of the artifact by path. Layers can either be a single dashboard,
+// named after their title annotation, or a tar archive of dashboards, optionally gzipped.
+func (s *ociSource) pull(ctx context.Context, manifest *ociManifest) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	var size int64
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+		if size > maxOCIBundleSize {
+			return nil, fmt.Errorf("artifact %s is larger than %d bytes", s.ref, maxOCIBundleSize)
+		}
+
+		isJSON := strings.HasSuffix(layer.MediaType, "json")
+		isArchive := strings.Contains(layer.MediaType, "tar") || strings.Contains(layer.MediaType, "gzip")
+		if !isJSON && !isArchive {
+			s.log.Debug("Skipping layer with unsupported media type", "reference", s.ref.String(), "digest", layer.Digest, "mediaType", layer.MediaType)
+			continue
+		}
+
+		content, err := s.client.blob(ctx, s.ref, layer, maxOCIBundleSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pull %s: %w", s.ref, err)
+		}
+
+		if isJSON {
+			name := path.Base(layer.Annotations[ociTitleAnnotation])
+			if !strings.HasSuffix(name, ".json") || strings.HasPrefix(name, ".") {
+				name = strings.TrimPrefix(layer.Digest, "sha256:") + ".json"
+			}
+			files[name] = content
+			continue
+		}
+
+		if err := extractDashboards(content, files); err != nil {
+			return nil, fmt.Errorf("failed to extract layer %s of %s: %w", layer.Digest, s.ref, err)
+		}
+	}
+	return files, nil
+}
+
+// extractDashboards adds the JSON files of a tar archive to files, keeping their directories.
+func extractDashboards(content []byte, files map[string][]byte) error {
+	var r io.Reader = bytes.NewReader(content)
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	var size int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Other entries, such as links, are ignored so that the archive cannot write outside of the path.
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(hdr.Name, ".json") {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid file name %q", hdr.Name)
+		}
+
+		size += hdr.Size
+		if size > maxOCIBundleSize {
+			return fmt.Errorf("archive is larger than %d bytes", maxOCIBundleSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return err
+		}
+		files[name] = data
+	}
+}
+
+// replaceDirectory replaces the content of dir with files. The files are written to a new
+// directory which takes the place of dir, so that dir never contains part of the files.
+func replaceDirectory(dir string, files map[string][]byte) error {
+	dir = filepath.Clean(dir)
+	parent, base := filepath.Split(dir)
+	if parent == "" {
+		parent = "."
+	}
+	if err := os.MkdirAll(parent, 0750); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(parent, "."+base+"-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for name, data := range files {
+		p := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, data, 0600); err != nil {
+			return err
+		}
+	}
+
+	old := tmp + ".old"
+	if err := os.Rename(dir, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// Put the previous dashboards back, so that they keep being provisioned.
+		_ = os.Rename(old, dir)
+		return err
+	}
+	return os.RemoveAll(old)
+}
@@ -0,0 +1,355 @@
+package dashboards
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		reference string
+		expected  ociReference
+		err       string
+	}{
+		{
+			reference: "registry.example.com/team/dashboards:v1",
+			expected:  ociReference{Registry: "registry.example.com", Repository: "team/dashboards", Tag: "v1"},
+		},
+		{
+			reference: "oci://localhost:5000/dashboards@" + digest,
+			expected:  ociReference{Registry: "localhost:5000", Repository: "dashboards", Digest: digest},
+		},
+		{
+			reference: "localhost/dashboards:v1@" + digest,
+			expected:  ociReference{Registry: "localhost", Repository: "dashboards", Tag: "v1", Digest: digest},
+		},
+		{
+			reference: "team/dashboards",
+			expected:  ociReference{Registry: dockerHubRegistry, Repository: "team/dashboards", Tag: "latest"},
+		},
+		{
+			reference: "docker.io/dashboards",
+			expected:  ociReference{Registry: dockerHubRegistry, Repository: "library/dashboards", Tag: "latest"},
+		},
+		{reference: "registry.example.com/dashboards@sha256:abc", err: "invalid digest"},
+		{reference: "registry.example.com/Dashboards", err: "invalid repository"},
+		{reference: "registry.example.com/dashboards:-v1", err: "invalid tag"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.reference, func(t *testing.T) {
+			ref, err := parseOCIReference(tc.reference)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ref)
+		})
+	}
+}
+
+func TestOCIDashboardReader(t *testing.T) {
+	dashboard := []byte(`{"title": "Single", "uid": "single"}`)
+	archive := tarGz(t, map[string]string{
+		"bundle/one.json":     `{"title": "One", "uid": "one"}`,
+		"bundle/sub/two.json": `{"title": "Two", "uid": "two"}`,
+		"bundle/README.md":    "not a dashboard",
+	})
+
+	registry := newFakeOCIRegistry(t)
+	digest := registry.push("v1", []ociDescriptor{
+		registry.blob(ociDescriptor{MediaType: "application/vnd.grafana.dashboard.v1+json", Annotations: map[string]string{ociTitleAnnotation: "single.json"}}, dashboard),
+		registry.blob(ociDescriptor{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip"}, archive),
+		registry.blob(ociDescriptor{MediaType: "text/plain"}, []byte("ignored")),
+	})
+
+	setup := func(t *testing.T, options map[string]any) *FileReader {
+		t.Helper()
+		options["path"] = filepath.Join(t.TempDir(), "dashboards")
+		options["plainHTTP"] = true
+		cfg := &config{Name: "oci", Type: "oci", OrgID: 1, Options: options}
+		reader, err := newOCIDashboardReader(cfg, log.New("test-logger"), nil, nil)
+		require.NoError(t, err)
+		return reader
+	}
+
+	t.Run("should extract the dashboards of the artifact", func(t *testing.T) {
+		reader := setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1"})
+		require.NoError(t, reader.source.sync(context.Background()))
+		assert.Equal(t, digest, reader.source.synced)
+
+		assert.Equal(t, map[string]string{
+			"single.json":         `{"title": "Single", "uid": "single"}`,
+			"bundle/one.json":     `{"title": "One", "uid": "one"}`,
+			"bundle/sub/two.json": `{"title": "Two", "uid": "two"}`,
+		}, readFiles(t, reader.Path))
+
+		// the dashboards are replaced when the tag is updated
+		newDigest := registry.push("v1", []ociDescriptor{
+			registry.blob(ociDescriptor{MediaType: "application/json"}, dashboard),
+		})
+		t.Cleanup(func() { registry.tags["v1"] = digest })
+		require.NoError(t, reader.source.sync(context.Background()))
+		assert.Equal(t, newDigest, reader.source.synced)
+		name := strings.TrimPrefix(registry.manifests[newDigest].Layers[0].Digest, "sha256:") + ".json"
+		assert.Equal(t, map[string]string{name: string(dashboard)}, readFiles(t, reader.Path))
+	})
+
+	t.Run("should pull pinned artifacts only once", func(t *testing.T) {
+		reader := setup(t, map[string]any{"reference": registry.host + "/team/dashboards", "digest": digest})
+		require.NoError(t, reader.source.sync(context.Background()))
+		requests := registry.requests
+		require.NoError(t, reader.source.sync(context.Background()))
+		assert.Equal(t, requests, registry.requests)
+	})
+
+	t.Run("should not extract an artifact that does not match the pinned digest", func(t *testing.T) {
+		reader := setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1@sha256:" + strings.Repeat("0", 64)})
+		require.ErrorContains(t, reader.source.sync(context.Background()), "404 Not Found")
+
+		// a registry serving another manifest for the digest
+		registry.raw["sha256:"+strings.Repeat("0", 64)] = registry.raw[digest]
+		defer delete(registry.raw, "sha256:"+strings.Repeat("0", 64))
+		require.ErrorContains(t, reader.source.sync(context.Background()), "has digest "+digest)
+		assert.NoDirExists(t, reader.Path)
+	})
+
+	t.Run("should verify the signature of the artifact", func(t *testing.T) {
+		key, keyPath := newCosignKey(t)
+		_, otherKeyPath := newCosignKey(t)
+		registry.sign(key, digest)
+
+		reader := setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1", "publicKeyPath": keyPath})
+		require.NoError(t, reader.source.sync(context.Background()))
+		assert.DirExists(t, reader.Path)
+
+		reader = setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1", "publicKeyPath": otherKeyPath})
+		require.ErrorIs(t, reader.source.sync(context.Background()), errOCISignature)
+		assert.NoDirExists(t, reader.Path)
+
+		// a signature of another manifest is not valid
+		otherDigest := registry.push("v2", []ociDescriptor{registry.blob(ociDescriptor{MediaType: "application/json"}, dashboard)})
+		registry.tags[strings.Replace(otherDigest, ":", "-", 1)+".sig"] = registry.tags[strings.Replace(digest, ":", "-", 1)+".sig"]
+		reader = setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v2", "publicKeyPath": keyPath})
+		require.ErrorIs(t, reader.source.sync(context.Background()), errOCISignature)
+	})
+
+	t.Run("should authenticate with a bearer token", func(t *testing.T) {
+		registry.auth = true
+		defer func() { registry.auth = false }()
+
+		reader := setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1", "username": "admin", "password": "wrong"})
+		require.ErrorContains(t, reader.source.sync(context.Background()), "failed to get registry token")
+
+		reader = setup(t, map[string]any{"reference": registry.host + "/team/dashboards:v1", "username": "admin", "password": "secret"})
+		require.NoError(t, reader.source.sync(context.Background()))
+		assert.Equal(t, "token", reader.source.client.token)
+	})
+
+	t.Run("invalid options should return error", func(t *testing.T) {
+		tests := []struct {
+			options  map[string]any
+			expected string
+		}{
+			{options: map[string]any{"path": "dashboards"}, expected: "reference param is not a string"},
+			{options: map[string]any{"path": "dashboards", "reference": "registry.example.com/Dashboards"}, expected: "invalid repository"},
+			{options: map[string]any{"path": "dashboards", "reference": "registry.example.com/dashboards", "digest": "latest"}, expected: "invalid digest"},
+			{options: map[string]any{"path": "dashboards", "reference": "registry.example.com/dashboards@" + digest, "digest": "sha256:" + strings.Repeat("0", 64)}, expected: "does not match"},
+			{options: map[string]any{"path": "dashboards", "reference": "registry.example.com/dashboards", "publicKeyPath": "testdata/missing.pub"}, expected: "no such file"},
+			{options: map[string]any{"reference": "registry.example.com/dashboards"}, expected: "path param is not a string"},
+		}
+		for _, tc := range tests {
+			cfg := &config{Name: "oci", Type: "oci", OrgID: 1, Options: tc.options}
+			_, err := newOCIDashboardReader(cfg, log.New("test-logger"), nil, nil)
+			assert.ErrorContains(t, err, tc.expected)
+		}
+	})
+}
+
+func TestExtractDashboards(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape.json", Typeflag: tar.TypeReg, Mode: 0600}))
+	require.NoError(t, tw.Close())
+
+	err := extractDashboards(buf.Bytes(), map[string][]byte{})
+	require.ErrorContains(t, err, "invalid file name")
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "link.json", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}))
+	require.NoError(t, tw.Close())
+
+	files := map[string][]byte{}
+	require.NoError(t, extractDashboards(buf.Bytes(), files))
+	assert.Empty(t, files)
+}
+
+// fakeOCIRegistry serves the manifests and blobs of the team/dashboards repository.
+type fakeOCIRegistry struct {
+	t         *testing.T
+	host      string
+	auth      bool
+	requests  int
+	tags      map[string]string
+	manifests map[string]ociManifest
+	raw       map[string][]byte
+	blobs     map[string][]byte
+}
+
+func newFakeOCIRegistry(t *testing.T) *fakeOCIRegistry {
+	r := &fakeOCIRegistry{
+		t:         t,
+		tags:      map[string]string{},
+		manifests: map[string]ociManifest{},
+		raw:       map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(server.Close)
+	r.host = strings.TrimPrefix(server.URL, "http://")
+	return r
+}
+
+func (r *fakeOCIRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests++
+	if req.URL.Path == "/token" {
+		if user, password, _ := req.BasicAuth(); user != "admin" || password != "secret" || req.URL.Query().Get("scope") != "repository:team/dashboards:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token": "token"}`))
+		return
+	}
+	if r.auth && req.Header.Get("Authorization") != "Bearer token" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="http://`+r.host+`/token",service="fake"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/team/dashboards/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if reference, ok := strings.CutPrefix(path, "manifests/"); ok {
+		if digest, ok := r.tags[reference]; ok {
+			reference = digest
+		}
+		raw, ok := r.raw[reference]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ociManifestMediaType)
+		_, _ = w.Write(raw)
+		return
+	}
+	if blob, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]; ok {
+		_, _ = w.Write(blob)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (r *fakeOCIRegistry) blob(desc ociDescriptor, content []byte) ociDescriptor {
+	sum := sha256.Sum256(content)
+	desc.Digest = "sha256:" + hex.EncodeToString(sum[:])
+	desc.Size = int64(len(content))
+	r.blobs[desc.Digest] = content
+	return desc
+}
+
+// push stores a manifest with the layers under the tag, and returns its digest.
+func (r *fakeOCIRegistry) push(tag string, layers []ociDescriptor) string {
+	m := ociManifest{MediaType: ociManifestMediaType, Layers: layers}
+	raw, err := json.Marshal(m)
+	require.NoError(r.t, err)
+	sum := sha256.Sum256(raw)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.manifests[digest] = m
+	r.raw[digest] = raw
+	r.tags[tag] = digest
+	return digest
+}
+
+// sign pushes a cosign signature of the manifest.
+func (r *fakeOCIRegistry) sign(key *ecdsa.PrivateKey, digest string) {
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"` + r.host + `/team/dashboards"},"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`)
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(r.t, err)
+
+	layer := r.blob(ociDescriptor{
+		MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+		Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+	}, payload)
+	r.push(strings.Replace(digest, ":", "-", 1)+".sig", []ociDescriptor{layer})
+}
+
+func newCosignKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cosign.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return key, path
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func readFiles(t *testing.T, dir string) map[string]string {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	require.NoError(t, err)
+	return files
+}
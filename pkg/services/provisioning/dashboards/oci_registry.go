@@ -0,0 +1,414 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	ociManifestMediaType       = "application/vnd.oci.image.manifest.v1+json"
+	ociIndexMediaType          = "application/vnd.oci.image.index.v1+json"
+	dockerManifestMediaType    = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerManifestListType     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	ociTitleAnnotation         = "org.opencontainers.image.title"
+	cosignSignatureAnnotation  = "dev.cosignproject.cosign/signature"
+	cosignSignatureType        = "cosign container image signature"
+	dockerHubRegistry          = "registry-1.docker.io"
+	maxOCIManifestSize         = 4 << 20
+	maxOCISignaturePayloadSize = 1 << 20
+)
+
+var (
+	ociDigestRegex     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+	ociRepositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	ociTagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	ociChallengeRegex  = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+	// errOCISignature is returned when none of the signatures of an artifact is valid for the public key.
+	errOCISignature = errors.New("no valid signature found for the artifact")
+)
+
+// ociReference is a reference to an artifact in an OCI registry, such as
+// registry.example.com/team/dashboards:v1 or registry.example.com/team/dashboards@sha256:<digest>.
+type ociReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseOCIReference parses a reference the way container tools do: references without a
+// registry host refer to Docker Hub, and references without a tag or digest to the latest tag.
+func parseOCIReference(s string) (ociReference, error) {
+	ref := ociReference{}
+	rest := strings.TrimPrefix(s, "oci://")
+
+	if name, digest, ok := strings.Cut(rest, "@"); ok {
+		if !ociDigestRegex.MatchString(digest) {
+			return ref, fmt.Errorf("invalid digest %q in reference %q, only sha256 digests are supported", digest, s)
+		}
+		ref.Digest = digest
+		rest = name
+	}
+
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		ref.Tag = rest[i+1:]
+		rest = rest[:i]
+		if !ociTagRegex.MatchString(ref.Tag) {
+			return ref, fmt.Errorf("invalid tag %q in reference %q", ref.Tag, s)
+		}
+	}
+
+	registry, repository, ok := strings.Cut(rest, "/")
+	if !ok || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, repository = dockerHubRegistry, rest
+	}
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = dockerHubRegistry
+	}
+	// The official images of Docker Hub are in the library namespace.
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if !ociRepositoryRegex.MatchString(repository) {
+		return ref, fmt.Errorf("invalid repository %q in reference %q", repository, s)
+	}
+	ref.Registry, ref.Repository = registry, repository
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// String returns the reference in its canonical form.
+func (r ociReference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// reference returns the digest of the reference, or its tag when it is not pinned.
+func (r ociReference) reference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// cosignPayload is the simple signing payload cosign signs for a manifest.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// ociClient pulls manifests and blobs from a registry using the OCI distribution API. It
+// authenticates with basic credentials, or with the bearer tokens of the registry's token
+// service when the registry asks for one.
+type ociClient struct {
+	client    *http.Client
+	plainHTTP bool
+	username  string
+	password  string
+
+	mu    sync.Mutex
+	token string
+}
+
+// manifest returns the manifest of the reference and its digest. The manifest of a pinned
+// reference is only returned when its content matches the digest.
+func (c *ociClient) manifest(ctx context.Context, ref ociReference) (*ociManifest, string, error) {
+	body, err := c.get(ctx, ref, "manifests/"+ref.reference(), []string{ociManifestMediaType, dockerManifestMediaType}, maxOCIManifestSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get manifest of %s: %w", ref, err)
+	}
+
+	sum := sha256.Sum256(body)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if ref.Digest != "" && digest != ref.Digest {
+		return nil, "", fmt.Errorf("manifest of %s has digest %s", ref, digest)
+	}
+
+	var m ociManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest of %s: %w", ref, err)
+	}
+	switch m.MediaType {
+	case ociIndexMediaType, dockerManifestListType:
+		return nil, "", fmt.Errorf("%s is an image index, reference one of its manifests instead", ref)
+	}
+	return &m, digest, nil
+}
+
+// blob returns the content of a blob after checking it matches the size and digest of its descriptor.
+func (c *ociClient) blob(ctx context.Context, ref ociReference, desc ociDescriptor, limit int64) ([]byte, error) {
+	if !ociDigestRegex.MatchString(desc.Digest) {
+		return nil, fmt.Errorf("unsupported digest %q", desc.Digest)
+	}
+	if desc.Size > limit {
+		return nil, fmt.Errorf("blob %s is larger than %d bytes", desc.Digest, limit)
+	}
+
+	body, err := c.get(ctx, ref, "blobs/"+desc.Digest, nil, desc.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", desc.Digest, err)
+	}
+	sum := sha256.Sum256(body)
+	if int64(len(body)) != desc.Size || "sha256:"+hex.EncodeToString(sum[:]) != desc.Digest {
+		return nil, fmt.Errorf("content of blob %s does not match its digest", desc.Digest)
+	}
+	return body, nil
+}
+
+// verifySignature checks that the manifest has a cosign signature made with the key. The
+// signatures are stored by cosign in the repository, under the sha256-<digest>.sig tag.
+func (c *ociClient) verifySignature(ctx context.Context, ref ociReference, digest string, key crypto.PublicKey) error {
+	sigRef := ociReference{Registry: ref.Registry, Repository: ref.Repository, Tag: strings.Replace(digest, ":", "-", 1) + ".sig"}
+	m, _, err := c.manifest(ctx, sigRef)
+	if err != nil {
+		return fmt.Errorf("failed to get signatures: %w", err)
+	}
+
+	for _, layer := range m.Layers {
+		encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		payload, err := c.blob(ctx, sigRef, layer, maxOCISignaturePayloadSize)
+		if err != nil {
+			return err
+		}
+		if verifyCosignSignature(key, payload, sig) != nil {
+			continue
+		}
+
+		// The payload names the signed manifest, so that a signature cannot be reused for another one.
+		var p cosignPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			continue
+		}
+		if p.Critical.Type == cosignSignatureType && p.Critical.Image.DockerManifestDigest == digest {
+			return nil
+		}
+	}
+	return errOCISignature
+}
+
+func (c *ociClient) get(ctx context.Context, ref ociReference, path string, accept []string, limit int64) ([]byte, error) {
+	scheme := "https"
+	if c.plainHTTP {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Registry, ref.Repository, path)
+
+	resp, err := c.do(ctx, u, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		authorization, err := c.authorize(ctx, challenge, ref)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(ctx, u, accept, authorization); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q from registry", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response is larger than %d bytes", limit)
+	}
+	return body, nil
+}
+
+func (c *ociClient) do(ctx context.Context, u string, accept []string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	if authorization == "" {
+		c.mu.Lock()
+		if c.token != "" {
+			authorization = "Bearer " + c.token
+		}
+		c.mu.Unlock()
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.client.Do(req)
+}
+
+// authorize returns the Authorization header answering the challenge of the registry.
+func (c *ociClient) authorize(ctx context.Context, challenge string, ref ociReference) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.username == "" {
+			return "", errors.New("the registry requires credentials")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	values := map[string]string{}
+	for _, m := range ociChallengeRegex.FindAllStringSubmatch(params, -1) {
+		values[m[1]] = m[2]
+	}
+	if values["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	u, err := url.Parse(values["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid authentication realm: %w", err)
+	}
+	q := u.Query()
+	if values["service"] != "" {
+		q.Set("service", values["service"])
+	}
+	scope := values["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.Repository + ":pull"
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get registry token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get registry token: unexpected status %q", resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOCIManifestSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", errors.New("the registry returned an empty token")
+	}
+
+	c.mu.Lock()
+	c.token = token.Token
+	c.mu.Unlock()
+	return "Bearer " + token.Token, nil
+}
+
+// readCosignPublicKey reads a PEM encoded public key, such as the cosign.pub file created by
+// cosign generate-key-pair.
+func readCosignPublicKey(path string) (crypto.PublicKey, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from the provisioning configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(bytes.TrimSpace(data))
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM encoded public key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("public key %s has an unsupported type %T", path, key)
+	}
+}
+
+func verifyCosignSignature(key crypto.PublicKey, payload, sig []byte) error {
+	sum := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, sum[:], sig) {
+			return errOCISignature
+		}
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return errOCISignature
+		}
+	default:
+		return errOCISignature
+	}
+	return nil
+}
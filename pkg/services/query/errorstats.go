@@ -0,0 +1,395 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+const (
+	// maxErrorStatsPanels is the number of panels per org for which the error stats are kept.
+	maxErrorStatsPanels = 1000
+	// errorStatsBucketSize is the period aggregated by each point of the error stats of a panel.
+	errorStatsBucketSize = time.Hour
+	// errorStatsFlushInterval is how often the error stats recorded by the instance are stored.
+	errorStatsFlushInterval = 10 * time.Second
+	// errorStatsPruneInterval is how often the stored error stats are pruned.
+	errorStatsPruneInterval = 10 * time.Minute
+	// maxErrorMessageLength is the length at which the last error message of a panel is truncated.
+	maxErrorMessageLength = 256
+)
+
+// errorStatsLatencyBoundsMs are the upper bounds of the latency histogram used to estimate the percentiles,
+// so the memory used by a panel does not depend on the number of requests.
+var errorStatsLatencyBoundsMs = []int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// PanelErrorStats aggregates the errors and latency of the requests of a dashboard panel over the retention period.
+type PanelErrorStats struct {
+	DashboardUID string  `json:"dashboardUid"`
+	PanelID      string  `json:"panelId"`
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	ErrorRate    float64 `json:"errorRate"`
+	LatencyP50Ms int64   `json:"latencyP50Ms"`
+	LatencyP95Ms int64   `json:"latencyP95Ms"`
+	LatencyP99Ms int64   `json:"latencyP99Ms"`
+	// LastError is the message of the last error, truncated.
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+	LastQueriedAt time.Time  `json:"lastQueriedAt"`
+	// Series is the error rate and latency of the panel per hour, oldest first.
+	Series []PanelErrorStatsPoint `json:"series"`
+}
+
+// PanelErrorStatsPoint is the error rate and latency of the requests of a panel during an hour.
+type PanelErrorStatsPoint struct {
+	Time         time.Time `json:"time"`
+	Requests     int64     `json:"requests"`
+	Errors       int64     `json:"errors"`
+	LatencyP95Ms int64     `json:"latencyP95Ms"`
+}
+
+type panelErrorStatsKey struct {
+	dashboardUID string
+	panelID      string
+}
+
+type errorStatsBucket struct {
+	start    time.Time
+	requests int64
+	errors   int64
+	// latency counts the requests per latency bound, the last element counts the requests above the last bound.
+	latency       []int64
+	lastError     string
+	lastErrorAt   time.Time
+	lastQueriedAt time.Time
+}
+
+func newErrorStatsBucket(start time.Time) *errorStatsBucket {
+	return &errorStatsBucket{start: start, latency: make([]int64, len(errorStatsLatencyBoundsMs)+1)}
+}
+
+// merge adds the requests of another bucket of the same hour to the bucket.
+func (b *errorStatsBucket) merge(other *errorStatsBucket) {
+	b.requests += other.requests
+	b.errors += other.errors
+	for i, count := range other.latency {
+		b.latency[i] += count
+	}
+	if other.lastErrorAt.After(b.lastErrorAt) {
+		b.lastError, b.lastErrorAt = other.lastError, other.lastErrorAt
+	}
+	if other.lastQueriedAt.After(b.lastQueriedAt) {
+		b.lastQueriedAt = other.lastQueriedAt
+	}
+}
+
+type panelErrorStats struct {
+	buckets       []*errorStatsBucket
+	lastQueriedAt time.Time
+}
+
+// bucket returns the bucket of the panel starting at the given time, which is added if the panel has none.
+func (p *panelErrorStats) bucket(start time.Time) *errorStatsBucket {
+	for i := len(p.buckets) - 1; i >= 0; i-- {
+		if p.buckets[i].start.Equal(start) {
+			return p.buckets[i]
+		}
+	}
+	bucket := newErrorStatsBucket(start)
+	p.buckets = append(p.buckets, bucket)
+	return bucket
+}
+
+// errorStatsRecorder keeps the errors and latency of the panel requests in memory in hourly buckets until they are
+// added to the stats stored by all the instances. The number of panels per org is bounded, in memory and in the
+// store.
+type errorStatsRecorder struct {
+	mtx sync.Mutex
+	// pending is the stats recorded by this instance that are not stored yet.
+	pending   map[int64]map[panelErrorStatsKey]*panelErrorStats
+	store     errorStatsStore
+	retention time.Duration
+	log       log.Logger
+	now       func() time.Time
+}
+
+func newErrorStatsRecorder(store errorStatsStore, retention time.Duration, logger log.Logger) *errorStatsRecorder {
+	if retention < errorStatsBucketSize {
+		retention = errorStatsBucketSize
+	}
+	return &errorStatsRecorder{
+		pending:   make(map[int64]map[panelErrorStatsKey]*panelErrorStats),
+		store:     store,
+		retention: retention,
+		log:       logger,
+		now:       time.Now,
+	}
+}
+
+func (r *errorStatsRecorder) record(orgID int64, dashboardUID, panelID string, latency time.Duration, errMsg string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	bucket := r.pendingBucket(orgID, panelErrorStatsKey{dashboardUID: dashboardUID, panelID: panelID}, now.Truncate(errorStatsBucketSize), now)
+	bucket.requests++
+	bucket.latency[latencyBucket(latency.Milliseconds())]++
+	bucket.lastQueriedAt = now
+	if errMsg != "" {
+		bucket.errors++
+		if len(errMsg) > maxErrorMessageLength {
+			errMsg = errMsg[:maxErrorMessageLength]
+		}
+		bucket.lastError, bucket.lastErrorAt = errMsg, now
+	}
+}
+
+// pendingBucket returns the pending bucket of a panel starting at the given time. The caller must hold the lock.
+func (r *errorStatsRecorder) pendingBucket(orgID int64, key panelErrorStatsKey, start, queriedAt time.Time) *errorStatsBucket {
+	panels, ok := r.pending[orgID]
+	if !ok {
+		panels = make(map[panelErrorStatsKey]*panelErrorStats)
+		r.pending[orgID] = panels
+	}
+	panel, ok := panels[key]
+	if !ok {
+		if len(panels) >= maxErrorStatsPanels {
+			evictLeastRecentlyQueried(panels)
+		}
+		panel = &panelErrorStats{}
+		panels[key] = panel
+	}
+	if queriedAt.After(panel.lastQueriedAt) {
+		panel.lastQueriedAt = queriedAt
+	}
+	return panel.bucket(start)
+}
+
+func evictLeastRecentlyQueried(panels map[panelErrorStatsKey]*panelErrorStats) {
+	var oldest panelErrorStatsKey
+	var oldestAt time.Time
+	for key, panel := range panels {
+		if oldestAt.IsZero() || panel.lastQueriedAt.Before(oldestAt) {
+			oldest, oldestAt = key, panel.lastQueriedAt
+		}
+	}
+	delete(panels, oldest)
+}
+
+// run stores the pending stats periodically, and once more when the context is canceled. The stats older than the
+// retention period and of the panels above the limit of the orgs are deleted periodically.
+func (r *errorStatsRecorder) run(ctx context.Context) error {
+	flushTicker := time.NewTicker(errorStatsFlushInterval)
+	defer flushTicker.Stop()
+	pruneTicker := time.NewTicker(errorStatsPruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The context is canceled, the stats are stored with a context of their own.
+			r.flush(context.Background())
+			return ctx.Err()
+		case <-flushTicker.C:
+			r.flush(ctx)
+		case <-pruneTicker.C:
+			if err := r.store.Prune(ctx, r.now().Add(-r.retention), maxErrorStatsPanels); err != nil {
+				r.log.Error("Failed to delete the outdated query error stats of the panels", "error", err)
+			}
+		}
+	}
+}
+
+// flush stores the pending stats. The stats that cannot be stored are kept to be stored later.
+func (r *errorStatsRecorder) flush(ctx context.Context) {
+	r.mtx.Lock()
+	pending := r.pending
+	r.pending = make(map[int64]map[panelErrorStatsKey]*panelErrorStats)
+	r.mtx.Unlock()
+
+	for orgID, panels := range pending {
+		for key, panel := range panels {
+			for _, bucket := range panel.buckets {
+				if err := r.store.Add(ctx, orgID, key, bucket); err != nil {
+					r.log.Error("Failed to store the query error stats of the panel", "orgId", orgID, "dashboardUid", key.dashboardUID, "panelId", key.panelID, "error", err)
+					r.mtx.Lock()
+					r.pendingBucket(orgID, key, bucket.start, bucket.lastQueriedAt).merge(bucket)
+					r.mtx.Unlock()
+				}
+			}
+		}
+	}
+}
+
+// top returns the error stats of the panels of the org with errors during the retention period, the panels with the
+// highest error rate first. The stats stored by all the instances are combined with the pending stats of this one.
+func (r *errorStatsRecorder) top(ctx context.Context, orgID int64) ([]PanelErrorStats, error) {
+	since := r.now().Add(-r.retention)
+	rows, err := r.store.List(ctx, orgID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	panels := make(map[panelErrorStatsKey]*panelErrorStats)
+	add := func(key panelErrorStatsKey, bucket *errorStatsBucket) {
+		if !bucket.start.Add(errorStatsBucketSize).After(since) {
+			return
+		}
+		panel, ok := panels[key]
+		if !ok {
+			panel = &panelErrorStats{}
+			panels[key] = panel
+		}
+		panel.bucket(bucket.start).merge(bucket)
+	}
+	for _, row := range rows {
+		bucket, err := row.toBucket()
+		if err != nil {
+			return nil, err
+		}
+		add(panelErrorStatsKey{dashboardUID: row.DashboardUID, panelID: row.PanelID}, bucket)
+	}
+	r.mtx.Lock()
+	for key, panel := range r.pending[orgID] {
+		for _, bucket := range panel.buckets {
+			add(key, bucket)
+		}
+	}
+	r.mtx.Unlock()
+
+	result := []PanelErrorStats{}
+	for key, panel := range panels {
+		sort.Slice(panel.buckets, func(i, j int) bool { return panel.buckets[i].start.Before(panel.buckets[j].start) })
+		stats := PanelErrorStats{
+			DashboardUID: key.dashboardUID,
+			PanelID:      key.panelID,
+			Series:       make([]PanelErrorStatsPoint, 0, len(panel.buckets)),
+		}
+		latency := make([]int64, len(errorStatsLatencyBoundsMs)+1)
+		var lastErrorAt time.Time
+		for _, bucket := range panel.buckets {
+			stats.Requests += bucket.requests
+			stats.Errors += bucket.errors
+			for i, count := range bucket.latency {
+				latency[i] += count
+			}
+			if bucket.lastQueriedAt.After(stats.LastQueriedAt) {
+				stats.LastQueriedAt = bucket.lastQueriedAt
+			}
+			if bucket.lastErrorAt.After(lastErrorAt) {
+				stats.LastError, lastErrorAt = bucket.lastError, bucket.lastErrorAt
+			}
+			stats.Series = append(stats.Series, PanelErrorStatsPoint{
+				Time:         bucket.start,
+				Requests:     bucket.requests,
+				Errors:       bucket.errors,
+				LatencyP95Ms: latencyPercentile(bucket.latency, bucket.requests, 0.95),
+			})
+		}
+		if stats.Errors == 0 {
+			continue
+		}
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Requests)
+		stats.LatencyP50Ms = latencyPercentile(latency, stats.Requests, 0.5)
+		stats.LatencyP95Ms = latencyPercentile(latency, stats.Requests, 0.95)
+		stats.LatencyP99Ms = latencyPercentile(latency, stats.Requests, 0.99)
+		if !lastErrorAt.IsZero() {
+			stats.LastErrorAt = &lastErrorAt
+		}
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ErrorRate != result[j].ErrorRate {
+			return result[i].ErrorRate > result[j].ErrorRate
+		}
+		if result[i].Errors != result[j].Errors {
+			return result[i].Errors > result[j].Errors
+		}
+		if result[i].DashboardUID != result[j].DashboardUID {
+			return result[i].DashboardUID < result[j].DashboardUID
+		}
+		return result[i].PanelID < result[j].PanelID
+	})
+	return result, nil
+}
+
+func latencyBucket(latencyMs int64) int {
+	return sort.Search(len(errorStatsLatencyBoundsMs), func(i int) bool { return latencyMs <= errorStatsLatencyBoundsMs[i] })
+}
+
+// latencyPercentile estimates the percentile of the latency histogram by linear interpolation within the bucket of
+// the percentile. The requests above the last bound are reported at the last bound.
+func latencyPercentile(histogram []int64, total int64, percentile float64) int64 {
+	if total == 0 {
+		return 0
+	}
+	rank := percentile * float64(total)
+	var cumulative, lower int64
+	for i, count := range histogram {
+		if i == len(errorStatsLatencyBoundsMs) {
+			break
+		}
+		upper := errorStatsLatencyBoundsMs[i]
+		if count > 0 && float64(cumulative+count) >= rank {
+			return lower + int64(float64(upper-lower)*(rank-float64(cumulative))/float64(count))
+		}
+		cumulative += count
+		lower = upper
+	}
+	return errorStatsLatencyBoundsMs[len(errorStatsLatencyBoundsMs)-1]
+}
+
+// observeErrorStats records whether the request of a dashboard panel failed, and its latency.
+func (s *ServiceImpl) observeErrorStats(ctx context.Context, orgID int64, latency time.Duration, resp *backend.QueryDataResponse, err error) {
+	if !s.errorStatsEnabled {
+		return
+	}
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Req == nil {
+		return
+	}
+	dashboardUID, panelID := reqCtx.Req.Header.Get(HeaderDashboardUID), reqCtx.Req.Header.Get(HeaderPanelID)
+	if dashboardUID == "" || panelID == "" {
+		return
+	}
+	// Requests canceled by the browser, e.g. when the time range changes, are not errors of the panel.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	} else if resp != nil {
+		refIDs := make([]string, 0, len(resp.Responses))
+		for refID := range resp.Responses {
+			refIDs = append(refIDs, refID)
+		}
+		sort.Strings(refIDs)
+		for _, refID := range refIDs {
+			if dr := resp.Responses[refID]; dr.Error != nil {
+				errMsg = dr.Error.Error()
+				break
+			}
+		}
+	}
+	s.errorStats.record(orgID, dashboardUID, panelID, latency, errMsg)
+}
+
+// GetPanelErrorStats returns the error stats of the panels of the org with errors during the retention period, the
+// panels with the highest error rate first.
+func (s *ServiceImpl) GetPanelErrorStats(ctx context.Context, orgID int64) ([]PanelErrorStats, error) {
+	if !s.errorStatsEnabled {
+		return []PanelErrorStats{}, nil
+	}
+	return s.errorStats.top(ctx, orgID)
+}
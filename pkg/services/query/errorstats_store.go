@@ -0,0 +1,183 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// maxErrorStatsUpdateAttempts is the number of times the stats of a panel are read and updated again when they are
+// updated concurrently by another instance.
+const maxErrorStatsUpdateAttempts = 10
+
+// storedErrorStats is the stored error stats of a dashboard panel during an hour.
+type storedErrorStats struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      string `xorm:"panel_id"`
+	// Bucket is the start of the hour, in seconds since the epoch.
+	Bucket   int64 `xorm:"bucket"`
+	Requests int64 `xorm:"request_count"`
+	Errors   int64 `xorm:"error_count"`
+	// Latency is the JSON encoded latency histogram of the requests.
+	Latency       string `xorm:"latency"`
+	LastError     string `xorm:"last_error"`
+	LastErrorAt   int64  `xorm:"last_error_at"`
+	LastQueriedAt int64  `xorm:"last_queried_at"`
+	// Version is incremented on every update, so that the concurrent updates of the instances are not lost.
+	Version int64 `xorm:"version"`
+}
+
+func (s storedErrorStats) TableName() string { return "panel_query_error_stats" }
+
+func (s *storedErrorStats) toBucket() (*errorStatsBucket, error) {
+	bucket := newErrorStatsBucket(time.Unix(s.Bucket, 0).UTC())
+	if s.Latency != "" {
+		if err := json.Unmarshal([]byte(s.Latency), &bucket.latency); err != nil {
+			return nil, err
+		}
+	}
+	// The latency bounds may have changed since the stats were stored.
+	if len(bucket.latency) != len(errorStatsLatencyBoundsMs)+1 {
+		bucket.latency = make([]int64, len(errorStatsLatencyBoundsMs)+1)
+	}
+	bucket.requests, bucket.errors = s.Requests, s.Errors
+	bucket.lastError = s.LastError
+	if s.LastErrorAt > 0 {
+		bucket.lastErrorAt = time.Unix(s.LastErrorAt, 0).UTC()
+	}
+	bucket.lastQueriedAt = time.Unix(s.LastQueriedAt, 0).UTC()
+	return bucket, nil
+}
+
+func (s *storedErrorStats) fromBucket(bucket *errorStatsBucket) error {
+	latency, err := json.Marshal(bucket.latency)
+	if err != nil {
+		return err
+	}
+	s.Bucket = bucket.start.Unix()
+	s.Requests, s.Errors = bucket.requests, bucket.errors
+	s.Latency = string(latency)
+	s.LastError = bucket.lastError
+	s.LastErrorAt = 0
+	if !bucket.lastErrorAt.IsZero() {
+		s.LastErrorAt = bucket.lastErrorAt.Unix()
+	}
+	s.LastQueriedAt = bucket.lastQueriedAt.Unix()
+	return nil
+}
+
+type errorStatsStore interface {
+	// Add adds the stats of a panel during an hour to the stored stats.
+	Add(ctx context.Context, orgID int64, key panelErrorStatsKey, bucket *errorStatsBucket) error
+	// List returns the stats of the panels of an org stored for the hours that ended after the given time.
+	List(ctx context.Context, orgID int64, since time.Time) ([]*storedErrorStats, error)
+	// Prune deletes the stats of the hours that ended before the given time, and the stats of the least recently
+	// queried panels of the orgs with more than maxPanels panels.
+	Prune(ctx context.Context, before time.Time, maxPanels int) error
+}
+
+type sqlErrorStatsStore struct {
+	db db.DB
+}
+
+var _ errorStatsStore = &sqlErrorStatsStore{}
+
+func (s *sqlErrorStatsStore) Add(ctx context.Context, orgID int64, key panelErrorStatsKey, bucket *errorStatsBucket) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		// The stats of the hour are read, merged and written back as long as another instance updates them in between.
+		// The statements are not run in a transaction, so that the instance that fails to create the row can update
+		// the row created by another instance.
+		for attempt := 0; attempt < maxErrorStatsUpdateAttempts; attempt++ {
+			var row storedErrorStats
+			has, err := sess.Where("org_id = ? AND dashboard_uid = ? AND panel_id = ? AND bucket = ?",
+				orgID, key.dashboardUID, key.panelID, bucket.start.Unix()).Get(&row)
+			if err != nil {
+				return err
+			}
+
+			merged := newErrorStatsBucket(bucket.start)
+			if has {
+				stored, err := row.toBucket()
+				if err != nil {
+					return err
+				}
+				merged.merge(stored)
+			}
+			merged.merge(bucket)
+			if err := row.fromBucket(merged); err != nil {
+				return err
+			}
+
+			if !has {
+				row.OrgID, row.DashboardUID, row.PanelID = orgID, key.dashboardUID, key.panelID
+				_, err := sess.Insert(&row)
+				if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+					continue
+				}
+				return err
+			}
+
+			res, err := sess.Exec("UPDATE panel_query_error_stats SET request_count = ?, error_count = ?, latency = ?, last_error = ?, last_error_at = ?, last_queried_at = ?, version = ? WHERE id = ? AND version = ?",
+				row.Requests, row.Errors, row.Latency, row.LastError, row.LastErrorAt, row.LastQueriedAt, row.Version+1, row.ID, row.Version)
+			if err != nil {
+				return err
+			}
+			if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+				return err
+			}
+		}
+		return errors.New("the stats of the panel are updated concurrently")
+	})
+}
+
+func (s *sqlErrorStatsStore) List(ctx context.Context, orgID int64, since time.Time) ([]*storedErrorStats, error) {
+	var rows []*storedErrorStats
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND bucket > ?", orgID, since.Add(-errorStatsBucketSize).Unix()).Asc("bucket").Find(&rows)
+	})
+	return rows, err
+}
+
+// storedPanel is a panel with stored error stats.
+type storedPanel struct {
+	OrgID         int64  `xorm:"org_id"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       string `xorm:"panel_id"`
+	LastQueriedAt int64  `xorm:"last_queried_at"`
+}
+
+func (s *sqlErrorStatsStore) Prune(ctx context.Context, before time.Time, maxPanels int) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM panel_query_error_stats WHERE bucket <= ?", before.Add(-errorStatsBucketSize).Unix()); err != nil {
+			return err
+		}
+
+		var panels []storedPanel
+		if err := sess.SQL("SELECT org_id, dashboard_uid, panel_id, MAX(last_queried_at) AS last_queried_at FROM panel_query_error_stats GROUP BY org_id, dashboard_uid, panel_id").Find(&panels); err != nil {
+			return err
+		}
+		byOrg := make(map[int64][]storedPanel)
+		for _, panel := range panels {
+			byOrg[panel.OrgID] = append(byOrg[panel.OrgID], panel)
+		}
+		for _, panels := range byOrg {
+			if len(panels) <= maxPanels {
+				continue
+			}
+			sort.Slice(panels, func(i, j int) bool { return panels[i].LastQueriedAt > panels[j].LastQueriedAt })
+			for _, panel := range panels[maxPanels:] {
+				if _, err := sess.Exec("DELETE FROM panel_query_error_stats WHERE org_id = ? AND dashboard_uid = ? AND panel_id = ?",
+					panel.OrgID, panel.DashboardUID, panel.PanelID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,219 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestObserveErrorStats(t *testing.T) {
+	panelContext := func(t *testing.T, dashboardUID, panelID string) context.Context {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:3000/api/ds/query", nil)
+		require.NoError(t, err)
+		req.Header.Set(HeaderDashboardUID, dashboardUID)
+		req.Header.Set(HeaderPanelID, panelID)
+		return ctxkey.Set(context.Background(), &contextmodel.ReqContext{
+			Context: &web.Context{
+				Resp: web.NewResponseWriter(http.MethodPost, httptest.NewRecorder()),
+				Req:  req,
+			},
+		})
+	}
+	failed := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {},
+		"B": {Error: errors.New("parse error")},
+	}}
+	succeeded := &backend.QueryDataResponse{Responses: backend.Responses{"A": {}}}
+	newService := func(store errorStatsStore, now *time.Time) *ServiceImpl {
+		s := &ServiceImpl{errorStatsEnabled: true, errorStats: newErrorStatsRecorder(store, 24*time.Hour, log.NewNopLogger())}
+		s.errorStats.now = func() time.Time { return *now }
+		return s
+	}
+	setup := func(t *testing.T) (*ServiceImpl, *time.Time) {
+		now := time.Date(2023, 10, 1, 10, 30, 0, 0, time.UTC)
+		return newService(&sqlErrorStatsStore{db: db.InitTestDB(t)}, &now), &now
+	}
+	getStats := func(t *testing.T, s *ServiceImpl, orgID int64) []PanelErrorStats {
+		t.Helper()
+		stats, err := s.GetPanelErrorStats(context.Background(), orgID)
+		require.NoError(t, err)
+		return stats
+	}
+
+	t.Run("aggregates the errors by panel, the most error-prone panels first", func(t *testing.T) {
+		s, now := setup(t)
+
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, 100*time.Millisecond, succeeded, nil)
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, 200*time.Millisecond, failed, nil)
+		s.errorStats.flush(context.Background())
+		s.observeErrorStats(panelContext(t, "dash", "2"), 1, time.Second, nil, errors.New("timeout"))
+		s.observeErrorStats(panelContext(t, "dash", "3"), 1, time.Second, succeeded, nil)
+		s.observeErrorStats(panelContext(t, "dash", "1"), 2, time.Second, failed, nil)
+
+		lastErrorAt := *now
+		bucket := time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC)
+		assert.Equal(t, []PanelErrorStats{
+			{
+				DashboardUID:  "dash",
+				PanelID:       "2",
+				Requests:      1,
+				Errors:        1,
+				ErrorRate:     1,
+				LatencyP50Ms:  750,
+				LatencyP95Ms:  975,
+				LatencyP99Ms:  995,
+				LastError:     "timeout",
+				LastErrorAt:   &lastErrorAt,
+				LastQueriedAt: *now,
+				Series:        []PanelErrorStatsPoint{{Time: bucket, Requests: 1, Errors: 1, LatencyP95Ms: 975}},
+			},
+			{
+				DashboardUID:  "dash",
+				PanelID:       "1",
+				Requests:      2,
+				Errors:        1,
+				ErrorRate:     0.5,
+				LatencyP50Ms:  100,
+				LatencyP95Ms:  235,
+				LatencyP99Ms:  247,
+				LastError:     "parse error",
+				LastErrorAt:   &lastErrorAt,
+				LastQueriedAt: *now,
+				Series:        []PanelErrorStatsPoint{{Time: bucket, Requests: 2, Errors: 1, LatencyP95Ms: 235}},
+			},
+		}, getStats(t, s, 1))
+		assert.Len(t, getStats(t, s, 2), 1)
+		assert.Empty(t, getStats(t, s, 3))
+	})
+
+	t.Run("combines the stats stored by all the instances", func(t *testing.T) {
+		s, now := setup(t)
+		other := newService(s.errorStats.store, now)
+
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, failed, nil)
+		other.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, succeeded, nil)
+		other.observeErrorStats(panelContext(t, "dash", "2"), 1, time.Second, failed, nil)
+		s.errorStats.flush(context.Background())
+		other.errorStats.flush(context.Background())
+
+		stats := getStats(t, s, 1)
+		require.Len(t, stats, 2)
+		assert.Equal(t, "2", stats[0].PanelID)
+		assert.Equal(t, "1", stats[1].PanelID)
+		assert.Equal(t, int64(2), stats[1].Requests)
+		assert.Equal(t, int64(1), stats[1].Errors)
+		assert.Equal(t, "parse error", stats[1].LastError)
+		assert.Equal(t, stats, getStats(t, other, 1))
+	})
+
+	t.Run("keeps hourly points during the retention period", func(t *testing.T) {
+		s, now := setup(t)
+
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, failed, nil)
+		s.errorStats.flush(context.Background())
+		*now = now.Add(time.Hour)
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, succeeded, nil)
+		s.errorStats.flush(context.Background())
+		stats := getStats(t, s, 1)
+		require.Len(t, stats, 1)
+		assert.Len(t, stats[0].Series, 2)
+		assert.Equal(t, int64(2), stats[0].Requests)
+
+		*now = now.Add(24 * time.Hour)
+		stats = getStats(t, s, 1)
+		require.Empty(t, stats, "the panel has no errors during the retention period")
+	})
+
+	t.Run("ignores canceled requests and requests without panel", func(t *testing.T) {
+		s, _ := setup(t)
+
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, nil, context.Canceled)
+		s.observeErrorStats(context.Background(), 1, time.Second, failed, nil)
+		s.observeErrorStats(panelContext(t, "", ""), 1, time.Second, failed, nil)
+
+		assert.Empty(t, getStats(t, s, 1))
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		s := &ServiceImpl{errorStats: newErrorStatsRecorder(nil, time.Hour, log.NewNopLogger())}
+
+		s.observeErrorStats(panelContext(t, "dash", "1"), 1, time.Second, failed, nil)
+
+		assert.Empty(t, getStats(t, s, 1))
+	})
+
+	t.Run("evicts the least recently queried panels of the org", func(t *testing.T) {
+		s, now := setup(t)
+
+		for i := 0; i <= maxErrorStatsPanels; i++ {
+			*now = now.Add(time.Second)
+			s.observeErrorStats(panelContext(t, "dash", fmt.Sprint(i)), 1, time.Second, failed, nil)
+		}
+
+		stats := getStats(t, s, 1)
+		assert.Len(t, stats, maxErrorStatsPanels)
+		for _, panel := range stats {
+			assert.NotEqual(t, "0", panel.PanelID)
+		}
+	})
+}
+
+func TestErrorStatsStore_Prune(t *testing.T) {
+	store := &sqlErrorStatsStore{db: db.InitTestDB(t)}
+	ctx := context.Background()
+	now := time.Date(2023, 10, 1, 10, 30, 0, 0, time.UTC)
+
+	add := func(t *testing.T, orgID int64, panelID string, queriedAt time.Time) {
+		t.Helper()
+		bucket := newErrorStatsBucket(queriedAt.Truncate(errorStatsBucketSize))
+		bucket.requests, bucket.lastQueriedAt = 1, queriedAt
+		require.NoError(t, store.Add(ctx, orgID, panelErrorStatsKey{dashboardUID: "dash", panelID: panelID}, bucket))
+	}
+	panels := func(t *testing.T, orgID int64) []string {
+		t.Helper()
+		rows, err := store.List(ctx, orgID, time.Time{})
+		require.NoError(t, err)
+		ids := []string{}
+		for _, row := range rows {
+			ids = append(ids, row.PanelID)
+		}
+		return ids
+	}
+
+	add(t, 1, "1", now.Add(-48*time.Hour))
+	add(t, 1, "2", now.Add(-2*time.Minute))
+	add(t, 1, "3", now.Add(-time.Minute))
+	add(t, 1, "4", now)
+	add(t, 2, "1", now.Add(-time.Minute))
+
+	require.NoError(t, store.Prune(ctx, now.Add(-24*time.Hour), 2))
+	assert.ElementsMatch(t, []string{"3", "4"}, panels(t, 1), "the outdated stats and the least recently queried panels are deleted")
+	assert.ElementsMatch(t, []string{"1"}, panels(t, 2))
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	histogram := make([]int64, len(errorStatsLatencyBoundsMs)+1)
+	histogram[latencyBucket(5)] = 50
+	histogram[latencyBucket(75)] = 40
+	histogram[latencyBucket(90000)] = 10
+
+	assert.Equal(t, int64(10), latencyPercentile(histogram, 100, 0.5))
+	assert.Equal(t, int64(93), latencyPercentile(histogram, 100, 0.85))
+	assert.Equal(t, int64(60000), latencyPercentile(histogram, 100, 0.99))
+	assert.Equal(t, int64(0), latencyPercentile(histogram, 0, 0.99))
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	inframetrics "github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "query_data"
+
+	queryStatusCompleted = "completed"
+	queryStatusCancelled = "cancelled"
+	queryStatusFailed    = "failed"
+
+	// exprDatasourceType is the datasource_type label of queries handled by server side expressions.
+	exprDatasourceType = "__expr__"
+)
+
+type metrics struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "queries_total",
+			Help:      "Number of queries executed by the query service, by whether they completed, failed or were cancelled by the client",
+		}, []string{"datasource_type", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "duration_seconds",
+			Help:      "Duration of the requests of the query service to a datasource, by whether they completed, failed or were cancelled by the client",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100},
+		}, []string{"datasource_type", "status"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.queries, m.duration)
+	}
+
+	return m
+}
+
+// observe counts the queries of a request to a datasource once it has been executed, and records its duration
+// with the trace of the request as exemplar.
+func (m *metrics) observe(ctx context.Context, dsType string, queries int, duration time.Duration, err error) {
+	status := queryStatus(ctx, err)
+	m.queries.WithLabelValues(dsType, status).Add(float64(queries))
+	inframetrics.ObserveWithExemplar(ctx, m.duration.WithLabelValues(dsType, status), duration.Seconds())
+}
+
+// queryStatus returns whether the queries completed, failed, or were cancelled because the request was aborted.
+func queryStatus(ctx context.Context, err error) string {
+	if errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled) {
+		return queryStatusCancelled
+	}
+	if err != nil {
+		return queryStatusFailed
+	}
+	return queryStatusCompleted
+}
@@ -9,13 +9,17 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics/orgmetrics"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/apiusage"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -43,7 +47,12 @@ func ProvideService(
 	pluginRequestValidator validations.PluginRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	registerer prometheus.Registerer,
+	orgMetrics *orgmetrics.Service,
+	apiUsage apiusage.Service,
+	sqlStore db.DB,
 ) *ServiceImpl {
+	logger := log.New("query_data")
 	g := &ServiceImpl{
 		cfg:                    cfg,
 		dataSourceCache:        dataSourceCache,
@@ -51,8 +60,16 @@ func ProvideService(
 		pluginRequestValidator: pluginRequestValidator,
 		pluginClient:           pluginClient,
 		pCtxProvider:           pCtxProvider,
-		log:                    log.New("query_data"),
+		log:                    logger,
+		metrics:                newMetrics(registerer),
+		orgMetrics:             orgMetrics,
+		apiUsage:               apiUsage,
 		concurrentQueryLimit:   cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
+		resultStatsEnabled:     cfg.SectionWithEnvOverrides("query").Key("result_stats").MustBool(false),
+		resultStats:            newResultStatsRecorder(),
+		errorStatsEnabled:      cfg.SectionWithEnvOverrides("query").Key("error_stats").MustBool(false),
+		errorStats: newErrorStatsRecorder(&sqlErrorStatsStore{db: sqlStore},
+			cfg.SectionWithEnvOverrides("query").Key("error_stats_retention").MustDuration(24*time.Hour), logger),
 	}
 	g.log.Info("Query Service initialization")
 	return g
@@ -62,6 +79,8 @@ func ProvideService(
 type Service interface {
 	Run(ctx context.Context) error
 	QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+	GetDashboardResultStats(orgID int64, dashboardUID string) []PanelResultStats
+	GetPanelErrorStats(ctx context.Context, orgID int64) ([]PanelErrorStats, error)
 }
 
 // Gives us compile time error if the service does not adhere to the contract of the interface
@@ -75,15 +94,30 @@ type ServiceImpl struct {
 	pluginClient           plugins.Client
 	pCtxProvider           *plugincontext.Provider
 	log                    log.Logger
+	metrics                *metrics
+	orgMetrics             *orgmetrics.Service
+	apiUsage               apiusage.Service
 	concurrentQueryLimit   int
+	resultStatsEnabled     bool
+	resultStats            *resultStatsRecorder
+	errorStatsEnabled      bool
+	errorStats             *errorStatsRecorder
 }
 
 // Run ServiceImpl.
 func (s *ServiceImpl) Run(ctx context.Context) error {
+	if s.errorStatsEnabled {
+		return s.errorStats.run(ctx)
+	}
 	<-ctx.Done()
 	return ctx.Err()
 }
 
+// IsDisabled returns true if the service has nothing to do in the background.
+func (s *ServiceImpl) IsDisabled() bool {
+	return !s.errorStatsEnabled
+}
+
 // QueryData processes queries and returns query responses. It handles queries to single or mixed datasources, as well as expressions.
 func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
 	// Parse the request into parsed queries grouped by datasource uid
@@ -91,6 +125,8 @@ func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, sk
 	if err != nil {
 		return nil, err
 	}
+	s.orgMetrics.IncQueries(user.GetOrgID(), len(reqDTO.Queries))
+	s.apiUsage.RecordQueries(user.GetOrgID(), len(reqDTO.Queries))
 
 	// If there are expressions, handle them and return
 	if parsedReq.hasExpression {
@@ -144,6 +180,12 @@ func (s *ServiceImpl) executeConcurrentQueries(ctx context.Context, user identit
 			// Handle panics in the datasource qery
 			defer recoveryFn(subDTO.Queries)
 
+			// Queries waiting for the concurrency limit are not started once the request is aborted
+			if err := ctx.Err(); err != nil {
+				rchan <- buildErrorResponses(err, subDTO.Queries)
+				return nil
+			}
+
 			ctxCopy := contexthandler.CopyWithReqContext(ctx)
 			subResp, err := s.QueryData(ctxCopy, user, skipDSCache, subDTO)
 			if err == nil {
@@ -231,10 +273,14 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 		})
 	}
 
+	start := time.Now()
 	qdr, err := s.expressionService.TransformData(ctx, time.Now(), &exprReq) // use time now because all queries have absolute time range
+	s.metrics.observe(ctx, exprDatasourceType, len(exprReq.Queries), time.Since(start), err)
+	s.observeErrorStats(ctx, exprReq.OrgId, time.Since(start), qdr, err)
 	if err != nil {
 		return nil, fmt.Errorf("expression request error: %w", err)
 	}
+	s.observeResultStats(ctx, exprReq.OrgId, len(exprReq.Queries), time.Since(start), qdr)
 	return qdr, nil
 }
 
@@ -267,7 +313,14 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	start := time.Now()
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	s.metrics.observe(ctx, ds.Type, len(req.Queries), time.Since(start), err)
+	s.observeErrorStats(ctx, ds.OrgID, time.Since(start), resp, err)
+	if err == nil {
+		s.observeResultStats(ctx, ds.OrgID, len(req.Queries), time.Since(start), resp)
+	}
+	return resp, err
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
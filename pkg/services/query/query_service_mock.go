@@ -19,6 +19,45 @@ type FakeQueryService struct {
 	mock.Mock
 }
 
+// GetDashboardResultStats provides a mock function with given fields: orgID, dashboardUID
+func (_m *FakeQueryService) GetDashboardResultStats(orgID int64, dashboardUID string) []PanelResultStats {
+	ret := _m.Called(orgID, dashboardUID)
+
+	var r0 []PanelResultStats
+	if rf, ok := ret.Get(0).(func(int64, string) []PanelResultStats); ok {
+		r0 = rf(orgID, dashboardUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]PanelResultStats)
+		}
+	}
+
+	return r0
+}
+
+// GetPanelErrorStats provides a mock function with given fields: ctx, orgID
+func (_m *FakeQueryService) GetPanelErrorStats(ctx context.Context, orgID int64) ([]PanelErrorStats, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 []PanelErrorStats
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []PanelErrorStats); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]PanelErrorStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryData provides a mock function with given fields: ctx, _a1, skipDSCache, reqDTO
 func (_m *FakeQueryService) QueryData(ctx context.Context, _a1 identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
 	ret := _m.Called(ctx, _a1, skipDSCache, reqDTO)
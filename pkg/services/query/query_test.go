@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/config"
 	pluginFakes "github.com/grafana/grafana/pkg/plugins/manager/fakes"
+	"github.com/grafana/grafana/pkg/services/apiusage/apiusagetest"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
@@ -39,6 +41,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/secrets/secretrefs"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -421,6 +424,24 @@ func TestQueryDataMultipleSources(t *testing.T) {
 		require.NotContains(t, res.Responses, "A")
 	})
 
+	t.Run("does not query datasources once the request is cancelled", func(t *testing.T) {
+		tc := setup(t)
+
+		reqDTO := metricRequestWithQueries(t,
+			`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A"}`,
+			`{"datasource": {"type": "mysql", "uid": "ds2"}, "refId": "B"}`,
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		res, err := tc.queryService.QueryData(ctx, tc.signedInUser, true, reqDTO)
+
+		require.NoError(t, err)
+		require.ErrorIs(t, res.Responses["A"].Error, context.Canceled)
+		require.ErrorIs(t, res.Responses["B"].Error, context.Canceled)
+		require.Nil(t, tc.pluginContext.req)
+	})
+
 	t.Run("ignores a deprecated datasourceID", func(t *testing.T) {
 		tc := setup(t)
 		query1, err := simplejson.NewJson([]byte(`
@@ -448,6 +469,32 @@ func TestQueryDataMultipleSources(t *testing.T) {
 	})
 }
 
+func TestQueryDataMetrics(t *testing.T) {
+	tc := setup(t)
+	reqDTO := metricRequestWithQueries(t,
+		`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A"}`,
+		`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "B"}`,
+	)
+
+	_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tc.queryService.QueryData(ctx, tc.signedInUser, true, reqDTO)
+	require.NoError(t, err)
+
+	failing := metricRequestWithQueries(t, `{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A", "queryType": "FAIL"}`)
+	_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, true, failing)
+	require.Error(t, err)
+
+	queries := tc.queryService.metrics.queries
+	assert.Equal(t, 2.0, testutil.ToFloat64(queries.WithLabelValues("mysql", queryStatusCompleted)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(queries.WithLabelValues("mysql", queryStatusCancelled)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(queries.WithLabelValues("mysql", queryStatusFailed)))
+	assert.Equal(t, 3, testutil.CollectAndCount(tc.queryService.metrics.duration))
+}
+
 func setup(t *testing.T) *testContext {
 	dss := []*datasources.DataSource{
 		{UID: "gIEkMvIVz", Type: "postgres"},
@@ -477,11 +524,11 @@ func setup(t *testing.T) *testContext {
 				{JSONData: plugins.JSONData{ID: "mysql"}},
 			},
 		}, fakeDatasourceService,
-		pluginSettings.ProvideService(sqlStore, secretsService), pluginFakes.NewFakeLicensingService(), &config.Cfg{},
+		pluginSettings.ProvideService(sqlStore, secretsService), pluginFakes.NewFakeLicensingService(), &config.Cfg{}, secretrefs.ProvideService(setting.NewCfg()),
 	)
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, pCtxProvider,
 		&featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest())
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider) // provider belonging to this package
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider, nil, nil, apiusagetest.NewFakeService(), sqlStore) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
 		secretStore:            ss,
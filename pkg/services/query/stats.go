@@ -0,0 +1,278 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/caching"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+const (
+	statRowsReturned    = "Rows returned"
+	statResultSize      = "Result size"
+	statBackendLatency  = "Backend latency"
+	statServedFromCache = "Served from cache"
+
+	// maxStatsDashboards is the number of dashboards per instance for which the result stats are kept.
+	maxStatsDashboards = 1000
+)
+
+// PanelResultStats aggregates the result stats of the queries of a dashboard panel since the instance started.
+type PanelResultStats struct {
+	PanelID          string    `json:"panelId"`
+	Requests         int64     `json:"requests"`
+	Queries          int64     `json:"queries"`
+	Rows             int64     `json:"rows"`
+	Bytes            int64     `json:"bytes"`
+	TotalLatencyMs   int64     `json:"totalLatencyMs"`
+	AverageLatencyMs int64     `json:"averageLatencyMs"`
+	MaxLatencyMs     int64     `json:"maxLatencyMs"`
+	CacheHits        int64     `json:"cacheHits"`
+	CacheMisses      int64     `json:"cacheMisses"`
+	LastQueriedAt    time.Time `json:"lastQueriedAt"`
+}
+
+// resultStats is the result stats of a request to a single datasource.
+type resultStats struct {
+	queries     int
+	rows        int64
+	bytes       int64
+	latency     time.Duration
+	cacheStatus string
+}
+
+type dashboardStatsKey struct {
+	orgID        int64
+	dashboardUID string
+}
+
+type dashboardStats struct {
+	panels    map[string]*PanelResultStats
+	updatedAt time.Time
+}
+
+// resultStatsRecorder keeps the result stats of the dashboard panels in memory, so the stats
+// of an instance do not include the queries served by the other instances of a HA setup.
+type resultStatsRecorder struct {
+	mtx        sync.Mutex
+	dashboards map[dashboardStatsKey]*dashboardStats
+	now        func() time.Time
+}
+
+func newResultStatsRecorder() *resultStatsRecorder {
+	return &resultStatsRecorder{
+		dashboards: make(map[dashboardStatsKey]*dashboardStats),
+		now:        time.Now,
+	}
+}
+
+func (r *resultStatsRecorder) record(orgID int64, dashboardUID, panelID string, stats resultStats) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	key := dashboardStatsKey{orgID: orgID, dashboardUID: dashboardUID}
+	dash, ok := r.dashboards[key]
+	if !ok {
+		if len(r.dashboards) >= maxStatsDashboards {
+			r.evictOldest()
+		}
+		dash = &dashboardStats{panels: make(map[string]*PanelResultStats)}
+		r.dashboards[key] = dash
+	}
+	dash.updatedAt = now
+
+	panel, ok := dash.panels[panelID]
+	if !ok {
+		panel = &PanelResultStats{PanelID: panelID}
+		dash.panels[panelID] = panel
+	}
+	latency := stats.latency.Milliseconds()
+	panel.Requests++
+	panel.Queries += int64(stats.queries)
+	panel.Rows += stats.rows
+	panel.Bytes += stats.bytes
+	panel.TotalLatencyMs += latency
+	if latency > panel.MaxLatencyMs {
+		panel.MaxLatencyMs = latency
+	}
+	switch stats.cacheStatus {
+	case caching.StatusHit:
+		panel.CacheHits++
+	case caching.StatusMiss:
+		panel.CacheMisses++
+	}
+	panel.LastQueriedAt = now
+}
+
+// evictOldest removes the dashboard whose panels were queried the least recently.
+func (r *resultStatsRecorder) evictOldest() {
+	var oldest dashboardStatsKey
+	var oldestAt time.Time
+	for key, dash := range r.dashboards {
+		if oldestAt.IsZero() || dash.updatedAt.Before(oldestAt) {
+			oldest, oldestAt = key, dash.updatedAt
+		}
+	}
+	delete(r.dashboards, oldest)
+}
+
+// get returns the result stats of the panels of the dashboard, the most expensive panels first.
+func (r *resultStatsRecorder) get(orgID int64, dashboardUID string) []PanelResultStats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	dash, ok := r.dashboards[dashboardStatsKey{orgID: orgID, dashboardUID: dashboardUID}]
+	if !ok {
+		return []PanelResultStats{}
+	}
+	result := make([]PanelResultStats, 0, len(dash.panels))
+	for _, panel := range dash.panels {
+		p := *panel
+		if p.Requests > 0 {
+			p.AverageLatencyMs = p.TotalLatencyMs / p.Requests
+		}
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		if result[i].TotalLatencyMs != result[j].TotalLatencyMs {
+			return result[i].TotalLatencyMs > result[j].TotalLatencyMs
+		}
+		return result[i].PanelID < result[j].PanelID
+	})
+	return result
+}
+
+// observeResultStats annotates the frames of the response with the result stats of the request to the datasource,
+// and records them for the dashboard panel that sent the request.
+func (s *ServiceImpl) observeResultStats(ctx context.Context, orgID int64, queries int, latency time.Duration, resp *backend.QueryDataResponse) {
+	if !s.resultStatsEnabled || resp == nil {
+		return
+	}
+
+	stats := resultStats{queries: queries, latency: latency}
+	var dashboardUID, panelID string
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil {
+		stats.cacheStatus = reqCtx.Resp.Header().Get(caching.XCacheHeader)
+		if reqCtx.Req != nil {
+			dashboardUID = reqCtx.Req.Header.Get(HeaderDashboardUID)
+			panelID = reqCtx.Req.Header.Get(HeaderPanelID)
+		}
+	}
+
+	for refID, dr := range resp.Responses {
+		var rows, bytes int64
+		for _, frame := range dr.Frames {
+			rows += int64(frameRows(frame))
+			bytes += frameSize(frame)
+		}
+		stats.rows += rows
+		stats.bytes += bytes
+
+		if len(dr.Frames) == 0 {
+			continue
+		}
+		frame := dr.Frames[0]
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.Stats = append(withoutResultStats(frame.Meta.Stats),
+			data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: statRowsReturned}, Value: float64(rows)},
+			data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: statResultSize, Unit: "decbytes"}, Value: float64(bytes)},
+			data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: statBackendLatency, Unit: "ms"}, Value: float64(latency.Milliseconds())},
+		)
+		if stats.cacheStatus == caching.StatusHit || stats.cacheStatus == caching.StatusMiss {
+			served := 0.0
+			if stats.cacheStatus == caching.StatusHit {
+				served = 1
+			}
+			frame.Meta.Stats = append(frame.Meta.Stats,
+				data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: statServedFromCache, Unit: "bool_yes_no"}, Value: served})
+		}
+		resp.Responses[refID] = dr
+	}
+
+	if dashboardUID != "" && panelID != "" {
+		s.resultStats.record(orgID, dashboardUID, panelID, stats)
+	}
+}
+
+// GetDashboardResultStats returns the result stats of the queries of the dashboard panels served by the instance.
+func (s *ServiceImpl) GetDashboardResultStats(orgID int64, dashboardUID string) []PanelResultStats {
+	return s.resultStats.get(orgID, dashboardUID)
+}
+
+// withoutResultStats removes the result stats that were added to cached frames when they were first returned.
+func withoutResultStats(stats []data.QueryStat) []data.QueryStat {
+	result := stats[:0]
+	for _, stat := range stats {
+		switch stat.DisplayName {
+		case statRowsReturned, statResultSize, statBackendLatency, statServedFromCache:
+		default:
+			result = append(result, stat)
+		}
+	}
+	return result
+}
+
+func frameRows(frame *data.Frame) int {
+	rows, err := frame.RowLen()
+	if err != nil {
+		return 0
+	}
+	return rows
+}
+
+// frameSize estimates the size in bytes of the values of the frame.
+func frameSize(frame *data.Frame) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		size += fieldSize(field)
+	}
+	return size
+}
+
+func fieldSize(field *data.Field) int64 {
+	switch field.Type() {
+	case data.FieldTypeString, data.FieldTypeNullableString, data.FieldTypeJSON, data.FieldTypeNullableJSON:
+		var size int64
+		for i := 0; i < field.Len(); i++ {
+			switch v := field.At(i).(type) {
+			case string:
+				size += int64(len(v))
+			case *string:
+				if v != nil {
+					size += int64(len(*v))
+				}
+			case json.RawMessage:
+				size += int64(len(v))
+			case *json.RawMessage:
+				if v != nil {
+					size += int64(len(*v))
+				}
+			}
+		}
+		return size
+	case data.FieldTypeInt8, data.FieldTypeNullableInt8, data.FieldTypeUint8, data.FieldTypeNullableUint8,
+		data.FieldTypeBool, data.FieldTypeNullableBool:
+		return int64(field.Len())
+	case data.FieldTypeInt16, data.FieldTypeNullableInt16, data.FieldTypeUint16, data.FieldTypeNullableUint16,
+		data.FieldTypeEnum, data.FieldTypeNullableEnum:
+		return 2 * int64(field.Len())
+	case data.FieldTypeInt32, data.FieldTypeNullableInt32, data.FieldTypeUint32, data.FieldTypeNullableUint32,
+		data.FieldTypeFloat32, data.FieldTypeNullableFloat32:
+		return 4 * int64(field.Len())
+	default:
+		return 8 * int64(field.Len())
+	}
+}
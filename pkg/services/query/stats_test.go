@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/caching"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestObserveResultStats(t *testing.T) {
+	panelContext := func(t *testing.T, dashboardUID, panelID, cacheStatus string) context.Context {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:3000/api/ds/query", nil)
+		require.NoError(t, err)
+		req.Header.Set(HeaderDashboardUID, dashboardUID)
+		req.Header.Set(HeaderPanelID, panelID)
+		reqCtx := &contextmodel.ReqContext{
+			Context: &web.Context{
+				Resp: web.NewResponseWriter(http.MethodPost, httptest.NewRecorder()),
+				Req:  req,
+			},
+		}
+		if cacheStatus != "" {
+			reqCtx.Resp.Header().Set(caching.XCacheHeader, cacheStatus)
+		}
+		return ctxkey.Set(context.Background(), reqCtx)
+	}
+	response := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{
+				data.NewFrame("a",
+					data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+					data.NewField("value", nil, []float64{1, 2}),
+					data.NewField("name", nil, []string{"abc", "de"}),
+				),
+				data.NewFrame("b", data.NewField("value", nil, []int32{1})),
+			}},
+			"B": {Frames: data.Frames{}},
+		}}
+	}
+
+	t.Run("annotates the first frame of each response", func(t *testing.T) {
+		s := &ServiceImpl{resultStatsEnabled: true, resultStats: newResultStatsRecorder()}
+		resp := response()
+
+		s.observeResultStats(panelContext(t, "dash", "2", caching.StatusHit), 1, 2, 150*time.Millisecond, resp)
+
+		frames := resp.Responses["A"].Frames
+		require.NotNil(t, frames[0].Meta)
+		assert.Equal(t, []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: statRowsReturned}, Value: 3},
+			{FieldConfig: data.FieldConfig{DisplayName: statResultSize, Unit: "decbytes"}, Value: 41},
+			{FieldConfig: data.FieldConfig{DisplayName: statBackendLatency, Unit: "ms"}, Value: 150},
+			{FieldConfig: data.FieldConfig{DisplayName: statServedFromCache, Unit: "bool_yes_no"}, Value: 1},
+		}, frames[0].Meta.Stats)
+		assert.Nil(t, frames[1].Meta)
+	})
+
+	t.Run("replaces the stats of cached frames", func(t *testing.T) {
+		s := &ServiceImpl{resultStatsEnabled: true, resultStats: newResultStatsRecorder()}
+		resp := response()
+		resp.Responses["A"].Frames[0].Meta = &data.FrameMeta{Stats: []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: "Summary: total bytes processed"}, Value: 1024},
+		}}
+
+		s.observeResultStats(context.Background(), 1, 1, time.Second, resp)
+		s.observeResultStats(context.Background(), 1, 1, 2*time.Second, resp)
+
+		stats := resp.Responses["A"].Frames[0].Meta.Stats
+		require.Len(t, stats, 4)
+		assert.Equal(t, "Summary: total bytes processed", stats[0].DisplayName)
+		assert.Equal(t, statBackendLatency, stats[3].DisplayName)
+		assert.Equal(t, 2000.0, stats[3].Value)
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		s := &ServiceImpl{resultStats: newResultStatsRecorder()}
+		resp := response()
+
+		s.observeResultStats(panelContext(t, "dash", "2", ""), 1, 2, time.Second, resp)
+
+		assert.Nil(t, resp.Responses["A"].Frames[0].Meta)
+		assert.Empty(t, s.GetDashboardResultStats(1, "dash"))
+	})
+
+	t.Run("aggregates the stats by dashboard panel", func(t *testing.T) {
+		s := &ServiceImpl{resultStatsEnabled: true, resultStats: newResultStatsRecorder()}
+		now := time.Unix(1000, 0)
+		s.resultStats.now = func() time.Time { return now }
+
+		s.observeResultStats(panelContext(t, "dash", "1", caching.StatusMiss), 1, 2, 100*time.Millisecond, response())
+		s.observeResultStats(panelContext(t, "dash", "1", caching.StatusHit), 1, 2, 300*time.Millisecond, response())
+		s.observeResultStats(panelContext(t, "dash", "2", ""), 1, 1, 50*time.Millisecond, &backend.QueryDataResponse{})
+		s.observeResultStats(panelContext(t, "other", "1", ""), 1, 1, time.Second, response())
+		s.observeResultStats(panelContext(t, "dash", "1", ""), 2, 1, time.Second, response())
+
+		assert.Equal(t, []PanelResultStats{
+			{
+				PanelID:          "1",
+				Requests:         2,
+				Queries:          4,
+				Rows:             6,
+				Bytes:            82,
+				TotalLatencyMs:   400,
+				AverageLatencyMs: 200,
+				MaxLatencyMs:     300,
+				CacheHits:        1,
+				CacheMisses:      1,
+				LastQueriedAt:    now,
+			},
+			{
+				PanelID:          "2",
+				Requests:         1,
+				Queries:          1,
+				TotalLatencyMs:   50,
+				AverageLatencyMs: 50,
+				MaxLatencyMs:     50,
+				LastQueriedAt:    now,
+			},
+		}, s.GetDashboardResultStats(1, "dash"))
+		assert.Empty(t, s.GetDashboardResultStats(1, "unknown"))
+	})
+
+	t.Run("evicts the least recently queried dashboards", func(t *testing.T) {
+		s := &ServiceImpl{resultStatsEnabled: true, resultStats: newResultStatsRecorder()}
+		now := time.Unix(1000, 0)
+		s.resultStats.now = func() time.Time { return now }
+
+		for i := 0; i <= maxStatsDashboards; i++ {
+			now = now.Add(time.Second)
+			s.observeResultStats(panelContext(t, fmt.Sprintf("dash-%d", i), "1", ""), 1, 1, time.Second, response())
+		}
+
+		assert.Empty(t, s.GetDashboardResultStats(1, "dash-0"))
+		assert.Len(t, s.GetDashboardResultStats(1, "dash-1"), 1)
+		assert.Len(t, s.GetDashboardResultStats(1, fmt.Sprintf("dash-%d", maxStatsDashboards)), 1)
+	})
+}
@@ -0,0 +1,57 @@
+package queryaudit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Entry is a query recorded in the query audit log.
+type Entry struct {
+	ID             int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID          int64  `json:"orgId" xorm:"org_id"`
+	DataSourceUID  string `json:"datasourceUid" xorm:"datasource_uid"`
+	DataSourceType string `json:"datasourceType" xorm:"datasource_type"`
+	RefID          string `json:"refId" xorm:"ref_id"`
+	// Query is the normalized text of the query.
+	Query string `json:"query" xorm:"query"`
+	// UserID is the namespaced ID of the user, such as user:1 or service-account:2.
+	// It is empty for the queries that are not executed on behalf of a request, such as alert rule evaluations.
+	UserID       string `json:"userId" xorm:"user_id"`
+	UserLogin    string `json:"userLogin" xorm:"user_login"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	PanelID      string `json:"panelId" xorm:"panel_id"`
+	// DurationMs is the duration of the request that executed the query.
+	DurationMs int64  `json:"durationMs" xorm:"duration_ms"`
+	Status     string `json:"status" xorm:"status"`
+	Error      string `json:"error,omitempty" xorm:"error"`
+	// Created is the time the query was executed in epoch milliseconds.
+	Created int64 `json:"created"`
+}
+
+func (e Entry) TableName() string { return "query_audit_log" }
+
+type RecordCommand struct {
+	OrgID          int64
+	DataSourceUID  string
+	DataSourceType string
+	UserID         string
+	UserLogin      string
+	DashboardUID   string
+	PanelID        string
+	Duration       time.Duration
+	Queries        []Query
+}
+
+// Query is a query of a query data request.
+type Query struct {
+	RefID string
+	// JSON is the model of the query, as sent to the data source.
+	JSON json.RawMessage
+	// Error is the error returned by the data source for the query, if any.
+	Error string
+}
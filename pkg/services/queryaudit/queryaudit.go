@@ -0,0 +1,32 @@
+// Package queryaudit records the queries executed against the data sources
+// that have query audit enabled, along with the user, the dashboard and the
+// panel they were executed for. Some compliance regimes require such a record
+// for production databases.
+package queryaudit
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// JSONDataKey is the key of the jsonData of a data source that enables query audit.
+const JSONDataKey = "auditQueries"
+
+type Service interface {
+	// Record records the queries of a query data request once it completed.
+	// Failing to record the queries is logged and does not fail the request.
+	Record(ctx context.Context, cmd *RecordCommand)
+}
+
+// IsEnabledForDataSource returns true if the jsonData of a data source enables query audit.
+func IsEnabledForDataSource(jsonData json.RawMessage) bool {
+	if len(jsonData) == 0 {
+		return false
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return false
+	}
+	enabled, _ := settings[JSONDataKey].(bool)
+	return enabled
+}
@@ -0,0 +1,42 @@
+package queryauditimpl
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// textFields are the fields of the query models that hold the text of the
+// query, such as the SQL of the SQL data sources or the expression of
+// Prometheus, in order of precedence.
+var textFields = []string{"rawSql", "expr", "rawQuery", "query", "queryText", "target"}
+
+// ignoredFields are the fields of the query models that do not change what the
+// query reads, and are left out when a query has no text field.
+var ignoredFields = []string{"refId", "datasource", "datasourceId", "intervalMs", "maxDataPoints", "hide", "key"}
+
+// normalizeQuery returns the text of a query with its whitespace collapsed, so
+// that the same query is recorded the same way whatever its formatting. The
+// queries without text, such as the queries built with a visual editor, are
+// recorded as their model without the fields that do not change what they
+// read, with sorted keys.
+func normalizeQuery(model json.RawMessage) string {
+	var fields map[string]any
+	if err := json.Unmarshal(model, &fields); err != nil {
+		return string(model)
+	}
+
+	for _, name := range textFields {
+		if text, ok := fields[name].(string); ok && strings.TrimSpace(text) != "" {
+			return strings.Join(strings.Fields(text), " ")
+		}
+	}
+
+	for _, name := range ignoredFields {
+		delete(fields, name)
+	}
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return string(model)
+	}
+	return string(normalized)
+}
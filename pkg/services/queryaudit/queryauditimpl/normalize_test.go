@@ -0,0 +1,47 @@
+package queryauditimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		model    string
+		expected string
+	}{
+		{
+			name:     "collapses the whitespace of the SQL",
+			model:    `{"refId":"A","rawSql":"SELECT *\n  FROM   users\n\tWHERE id = 1 ","format":"table"}`,
+			expected: "SELECT * FROM users WHERE id = 1",
+		},
+		{
+			name:     "uses the expression of Prometheus queries",
+			model:    `{"refId":"A","expr":"rate(http_requests_total[5m])","intervalMs":15000}`,
+			expected: "rate(http_requests_total[5m])",
+		},
+		{
+			name:     "ignores empty text fields",
+			model:    `{"refId":"A","rawSql":"","query":"select 1"}`,
+			expected: "select 1",
+		},
+		{
+			name:     "records the model of queries without text without the fields that do not change what they read",
+			model:    `{"table":"users","refId":"A","datasource":{"uid":"ds"},"maxDataPoints":100,"columns":["id","login"]}`,
+			expected: `{"columns":["id","login"],"table":"users"}`,
+		},
+		{
+			name:     "records invalid models as they are",
+			model:    `not json`,
+			expected: `not json`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeQuery([]byte(tc.model)))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package queryauditimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const cleanupInterval = time.Hour
+
+type Service struct {
+	store      store
+	log        log.Logger
+	serverLock *serverlock.ServerLockService
+
+	maxAge time.Duration
+
+	now func() time.Time
+}
+
+var _ queryaudit.Service = &Service{}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, serverLock *serverlock.ServerLockService) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("query_audit")
+	s := &Service{
+		store:      &sqlStore{db: db},
+		log:        log.New("queryaudit"),
+		serverLock: serverLock,
+		now:        time.Now,
+	}
+
+	maxAge, err := gtime.ParseDuration(section.Key("max_age").MustString("90d"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query audit max_age: %w", err)
+	}
+	s.maxAge = maxAge
+	return s, nil
+}
+
+// IsDisabled returns true if the recorded queries are kept forever, so that
+// there is nothing to clean up in the background.
+func (s *Service) IsDisabled() bool {
+	return s.maxAge <= 0
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "cleanup old query audit entries", cleanupInterval, func(ctx context.Context) {
+				if _, err := s.deleteOldEntries(ctx); err != nil {
+					s.log.Error("Failed to delete old query audit entries", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute cleanup of old query audit entries", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) deleteOldEntries(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.maxAge))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted old query audit entries", "count", deleted)
+	}
+	return deleted, nil
+}
+
+func (s *Service) Record(ctx context.Context, cmd *queryaudit.RecordCommand) {
+	if len(cmd.Queries) == 0 {
+		return
+	}
+
+	created := s.now().Add(-cmd.Duration).UnixMilli()
+	entries := make([]*queryaudit.Entry, 0, len(cmd.Queries))
+	for _, q := range cmd.Queries {
+		entry := &queryaudit.Entry{
+			OrgID:          cmd.OrgID,
+			DataSourceUID:  cmd.DataSourceUID,
+			DataSourceType: cmd.DataSourceType,
+			RefID:          q.RefID,
+			Query:          normalizeQuery(q.JSON),
+			UserID:         cmd.UserID,
+			UserLogin:      cmd.UserLogin,
+			DashboardUID:   cmd.DashboardUID,
+			PanelID:        cmd.PanelID,
+			DurationMs:     cmd.Duration.Milliseconds(),
+			Status:         queryaudit.StatusOK,
+			Created:        created,
+		}
+		if q.Error != "" {
+			entry.Status = queryaudit.StatusError
+			entry.Error = q.Error
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := s.store.Insert(ctx, entries); err != nil {
+		s.log.Error("Failed to record queries", "datasourceUid", cmd.DataSourceUID, "queries", len(entries), "error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package queryauditimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationQueryAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	setup := func(t *testing.T) (*Service, db.DB, *time.Time) {
+		t.Helper()
+		sqlStore := db.InitTestDB(t)
+		s, err := ProvideService(sqlStore, setting.NewCfg(), serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()))
+		require.NoError(t, err)
+
+		now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+		return s, sqlStore, &now
+	}
+
+	findEntries := func(t *testing.T, sqlStore db.DB) []*queryaudit.Entry {
+		t.Helper()
+		var entries []*queryaudit.Entry
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.Asc("id").Find(&entries)
+		})
+		require.NoError(t, err)
+		return entries
+	}
+
+	t.Run("records an entry per query", func(t *testing.T) {
+		s, sqlStore, now := setup(t)
+		s.Record(context.Background(), &queryaudit.RecordCommand{
+			OrgID:          1,
+			DataSourceUID:  "postgres",
+			DataSourceType: "grafana-postgresql-datasource",
+			UserID:         "user:1",
+			UserLogin:      "admin",
+			DashboardUID:   "dash",
+			PanelID:        "2",
+			Duration:       1500 * time.Millisecond,
+			Queries: []queryaudit.Query{
+				{RefID: "A", JSON: []byte(`{"rawSql":"SELECT  1"}`)},
+				{RefID: "B", JSON: []byte(`{"rawSql":"SELECT * FROM secrets"}`), Error: "permission denied"},
+			},
+		})
+
+		entries := findEntries(t, sqlStore)
+		require.Len(t, entries, 2)
+
+		a := entries[0]
+		assert.Equal(t, int64(1), a.OrgID)
+		assert.Equal(t, "postgres", a.DataSourceUID)
+		assert.Equal(t, "grafana-postgresql-datasource", a.DataSourceType)
+		assert.Equal(t, "A", a.RefID)
+		assert.Equal(t, "SELECT 1", a.Query)
+		assert.Equal(t, "user:1", a.UserID)
+		assert.Equal(t, "admin", a.UserLogin)
+		assert.Equal(t, "dash", a.DashboardUID)
+		assert.Equal(t, "2", a.PanelID)
+		assert.Equal(t, int64(1500), a.DurationMs)
+		assert.Equal(t, queryaudit.StatusOK, a.Status)
+		assert.Equal(t, now.Add(-1500*time.Millisecond).UnixMilli(), a.Created)
+
+		b := entries[1]
+		assert.Equal(t, "B", b.RefID)
+		assert.Equal(t, queryaudit.StatusError, b.Status)
+		assert.Equal(t, "permission denied", b.Error)
+	})
+
+	t.Run("deletes the entries older than max age", func(t *testing.T) {
+		s, sqlStore, now := setup(t)
+		start := *now
+		record := func(refID string) {
+			s.Record(context.Background(), &queryaudit.RecordCommand{
+				OrgID:         1,
+				DataSourceUID: "postgres",
+				Queries:       []queryaudit.Query{{RefID: refID, JSON: []byte(`{"rawSql":"SELECT 1"}`)}},
+			})
+		}
+		record("old")
+		*now = start.Add(s.maxAge)
+		record("recent")
+		*now = start.Add(s.maxAge + time.Minute)
+
+		deleted, err := s.deleteOldEntries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		entries := findEntries(t, sqlStore)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "recent", entries[0].RefID)
+	})
+}
@@ -0,0 +1,44 @@
+package queryauditimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+)
+
+type store interface {
+	Insert(context.Context, []*queryaudit.Entry) error
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Insert(ctx context.Context, entries []*queryaudit.Entry) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, entry := range entries {
+			if _, err := sess.Insert(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM query_audit_log WHERE created < ?", before.UnixMilli())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,25 @@
+package queryaudittest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+)
+
+type FakeService struct {
+	mu       sync.Mutex
+	Recorded []*queryaudit.RecordCommand
+}
+
+var _ queryaudit.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Record(_ context.Context, cmd *queryaudit.RecordCommand) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Recorded = append(f.Recorded, cmd)
+}
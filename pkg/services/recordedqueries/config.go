@@ -0,0 +1,155 @@
+package recordedqueries
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+// RecordedQuery is a query, or a set of queries and expressions, evaluated on a schedule whose results
+// are written to the remote write target.
+type RecordedQuery struct {
+	// Name identifies the recorded query. It is the name of the written metric unless Metric is set.
+	Name string
+	// Metric is the name of the written metric.
+	Metric string
+	OrgID  int64
+	// Interval is the time between two evaluations.
+	Interval time.Duration
+	// Range is the relative time range of the queries, ending at the time of the evaluation.
+	Range time.Duration
+	// RefID is the refId of the query or expression whose results are written. If empty, the results
+	// of all the queries and expressions are written.
+	RefID string
+	// Labels are added to the labels of the written series.
+	Labels  map[string]string
+	Queries []*simplejson.Json
+}
+
+type configFile struct {
+	APIVersion      int64                 `yaml:"apiVersion"`
+	RecordedQueries []recordedQueryConfig `yaml:"recordedQueries"`
+}
+
+type recordedQueryConfig struct {
+	Name     string            `yaml:"name"`
+	Metric   string            `yaml:"metric"`
+	OrgID    int64             `yaml:"orgId"`
+	Interval string            `yaml:"interval"`
+	Range    string            `yaml:"range"`
+	RefID    string            `yaml:"refId"`
+	Labels   map[string]string `yaml:"labels"`
+	Queries  []map[string]any  `yaml:"queries"`
+}
+
+const (
+	defaultInterval = time.Minute
+	defaultRange    = 5 * time.Minute
+)
+
+// readConfig reads the recorded queries of the YAML files of a directory. A missing directory has no
+// recorded queries.
+func readConfig(path string) ([]RecordedQuery, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var queries []RecordedQuery
+	names := make(map[string]string)
+	for _, file := range files {
+		if file.IsDir() || !(strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml")) {
+			continue
+		}
+
+		filename := filepath.Join(path, file.Name())
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `filename` comes from the provisioning path
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+
+		for _, rq := range cfg.RecordedQueries {
+			q, err := rq.recordedQuery()
+			if err != nil {
+				return nil, fmt.Errorf("invalid recorded query in %s: %w", filename, err)
+			}
+			key := fmt.Sprintf("%d/%s", q.OrgID, q.Name)
+			if other, ok := names[key]; ok {
+				return nil, fmt.Errorf("recorded query %q of org %d is defined in both %s and %s", q.Name, q.OrgID, other, filename)
+			}
+			names[key] = filename
+			queries = append(queries, q)
+		}
+	}
+	return queries, nil
+}
+
+func (c recordedQueryConfig) recordedQuery() (RecordedQuery, error) {
+	q := RecordedQuery{
+		Name:     c.Name,
+		OrgID:    c.OrgID,
+		Interval: defaultInterval,
+		Range:    defaultRange,
+		RefID:    c.RefID,
+		Labels:   c.Labels,
+	}
+	if q.Name == "" {
+		return q, errors.New("name is required")
+	}
+	if q.OrgID == 0 {
+		q.OrgID = 1
+	}
+
+	metric := c.Metric
+	if metric == "" {
+		metric = c.Name
+	}
+	var ok bool
+	q.Metric, ok = remotewrite.SanitizeMetricName(metric)
+	if !ok {
+		return q, fmt.Errorf("%q of recorded query %q is not a valid metric name", metric, c.Name)
+	}
+	for name := range c.Labels {
+		if _, ok := remotewrite.SanitizeLabelName(name); !ok {
+			return q, fmt.Errorf("%q of recorded query %q is not a valid label name", name, c.Name)
+		}
+	}
+
+	var err error
+	if c.Interval != "" {
+		if q.Interval, err = time.ParseDuration(c.Interval); err != nil || q.Interval <= 0 {
+			return q, fmt.Errorf("invalid interval %q of recorded query %q", c.Interval, c.Name)
+		}
+	}
+	if c.Range != "" {
+		if q.Range, err = time.ParseDuration(c.Range); err != nil || q.Range <= 0 {
+			return q, fmt.Errorf("invalid range %q of recorded query %q", c.Range, c.Name)
+		}
+	}
+
+	if len(c.Queries) == 0 {
+		return q, fmt.Errorf("recorded query %q has no queries", c.Name)
+	}
+	for _, query := range c.Queries {
+		q.Queries = append(q.Queries, simplejson.NewFromAny(query))
+	}
+	return q, nil
+}
@@ -0,0 +1,38 @@
+package recordedqueries
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "recorded_queries"
+)
+
+type metrics struct {
+	evaluations *prometheus.CounterVec
+	samples     prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		evaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "evaluations_total",
+			Help:      "Number of evaluations of recorded queries, by whether their results were written to the remote write target",
+		}, []string{"status"}),
+		samples: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "samples_written_total",
+			Help:      "Number of samples of recorded queries written to the remote write target",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.evaluations, m.samples)
+	}
+
+	return m
+}
@@ -0,0 +1,270 @@
+// Package recordedqueries evaluates queries on a schedule and writes their results to a Prometheus
+// compatible remote write target, so that long term series of expensive queries can be kept at a
+// lower resolution.
+package recordedqueries
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// recordedQueryLabel is the label with the name of the recorded query added to the written series.
+const recordedQueryLabel = "recorded_query"
+
+type Service struct {
+	log          log.Logger
+	queryService query.Service
+	writer       *remoteWriter
+	metrics      *metrics
+
+	enabled     bool
+	configPath  string
+	minInterval time.Duration
+	maxQueries  int
+
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, queryService query.Service, dataSources datasources.DataSourceService,
+	httpClientProvider httpclient.Provider, registerer prometheus.Registerer) *Service {
+	section := cfg.SectionWithEnvOverrides("recorded_queries")
+	s := &Service{
+		log:          log.New("recorded-queries"),
+		queryService: queryService,
+		metrics:      newMetrics(registerer),
+		enabled:      section.Key("enabled").MustBool(true),
+		configPath:   filepath.Join(cfg.ProvisioningPath, "recorded_queries"),
+		minInterval:  section.Key("min_interval").MustDuration(10 * time.Second),
+		maxQueries:   section.Key("max_queries").MustInt(1000),
+		now:          time.Now,
+	}
+
+	if uid := section.Key("default_remote_write_datasource_uid").String(); uid != "" {
+		s.writer = &remoteWriter{
+			dataSources:        dataSources,
+			httpClientProvider: httpClientProvider,
+			dataSourceUID:      uid,
+			dataSourceOrgID:    section.Key("default_remote_write_datasource_org_id").MustInt64(1),
+			path:               section.Key("default_remote_write_path").MustString("/api/v1/write"),
+		}
+	}
+	return s
+}
+
+// IsDisabled returns true if recorded queries are disabled or if no remote write target is configured.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled || s.writer == nil
+}
+
+// Run evaluates the recorded queries at their interval until the context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	queries, err := readConfig(s.configPath)
+	if err != nil {
+		// Recorded queries are not critical for Grafana, so the error is logged instead of stopping the server.
+		s.log.Error("Failed to read recorded queries", "path", s.configPath, "error", err)
+		return nil
+	}
+	if len(queries) > s.maxQueries {
+		s.log.Warn("Too many recorded queries, only the first ones are evaluated", "count", len(queries), "max", s.maxQueries)
+		queries = queries[:s.maxQueries]
+	}
+	s.log.Info("Starting recorded queries", "count", len(queries))
+
+	var wg sync.WaitGroup
+	for _, q := range queries {
+		if q.Interval < s.minInterval {
+			s.log.Warn("Interval of recorded query is below the minimum interval, using the minimum interval", "name", q.Name, "interval", q.Interval, "minInterval", s.minInterval)
+			q.Interval = s.minInterval
+		}
+
+		wg.Add(1)
+		go func(q RecordedQuery) {
+			defer wg.Done()
+			s.schedule(ctx, q)
+		}(q)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Service) schedule(ctx context.Context, q RecordedQuery) {
+	ticker := time.NewTicker(q.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.evaluate(ctx, q)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) evaluate(ctx context.Context, q RecordedQuery) {
+	logger := s.log.New("name", q.Name, "org", q.OrgID)
+	series, err := s.record(ctx, q)
+	if err != nil {
+		s.metrics.evaluations.WithLabelValues("failed").Inc()
+		logger.Error("Failed to record query", "error", err)
+		return
+	}
+	s.metrics.evaluations.WithLabelValues("success").Inc()
+	s.metrics.samples.Add(float64(len(series)))
+	logger.Debug("Recorded query", "series", len(series))
+}
+
+// record runs the queries of a recorded query and writes a sample of each of the series of its results.
+func (s *Service) record(ctx context.Context, q RecordedQuery) ([]prompb.TimeSeries, error) {
+	now := s.now()
+	resp, err := s.queryService.QueryData(ctx, recordedQueriesUser(q.OrgID), false, dtos.MetricRequest{
+		From:    strconv.FormatInt(now.Add(-q.Range).UnixMilli(), 10),
+		To:      strconv.FormatInt(now.UnixMilli(), 10),
+		Queries: q.Queries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+
+	series, err := timeSeriesFromResponse(q, resp, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	if err := s.writer.write(ctx, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// timeSeriesFromResponse returns a sample for each numeric field of the frames of the response. The sample
+// is the last value of the field, at the time of its point if the frame has a time field, and at the time
+// of the evaluation otherwise.
+func timeSeriesFromResponse(q RecordedQuery, resp *backend.QueryDataResponse, now time.Time) ([]prompb.TimeSeries, error) {
+	var series []prompb.TimeSeries
+	for refID, res := range resp.Responses {
+		if q.RefID != "" && refID != q.RefID {
+			continue
+		}
+		if res.Error != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", refID, res.Error)
+		}
+
+		for _, frame := range res.Frames {
+			timeField := -1
+			for i, field := range frame.Fields {
+				if field.Type().Time() {
+					timeField = i
+					break
+				}
+			}
+
+			for _, field := range frame.Fields {
+				if !field.Type().Numeric() {
+					continue
+				}
+				sample, ok := lastSample(frame, field, timeField, now)
+				if !ok {
+					continue
+				}
+				series = append(series, prompb.TimeSeries{
+					Labels:  seriesLabels(q, field.Labels),
+					Samples: []prompb.Sample{sample},
+				})
+			}
+		}
+	}
+	if q.RefID != "" {
+		if _, ok := resp.Responses[q.RefID]; !ok {
+			return nil, fmt.Errorf("no results for refId %s", q.RefID)
+		}
+	}
+	return series, nil
+}
+
+func lastSample(frame *data.Frame, field *data.Field, timeField int, now time.Time) (prompb.Sample, bool) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		v, err := field.NullableFloatAt(i)
+		if err != nil || v == nil || math.IsNaN(*v) {
+			continue
+		}
+		ts := now
+		if timeField >= 0 {
+			t, ok := frame.Fields[timeField].ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			ts = t.(time.Time)
+		}
+		return prompb.Sample{Timestamp: ts.UnixMilli(), Value: *v}, true
+	}
+	return prompb.Sample{}, false
+}
+
+func seriesLabels(q RecordedQuery, fieldLabels data.Labels) []prompb.Label {
+	labels := make(map[string]string, len(fieldLabels)+len(q.Labels)+2)
+	for _, ls := range []map[string]string{fieldLabels, q.Labels} {
+		for name, value := range ls {
+			if name, ok := remotewrite.SanitizeLabelName(name); ok {
+				labels[name] = value
+			}
+		}
+	}
+	labels[recordedQueryLabel] = q.Name
+	labels["__name__"] = q.Metric
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	// The remote write protocol expects the labels sorted by name.
+	sort.Strings(names)
+
+	promLabels := make([]prompb.Label, 0, len(labels))
+	for _, name := range names {
+		promLabels = append(promLabels, prompb.Label{Name: name, Value: labels[name]})
+	}
+	return promLabels
+}
+
+func recordedQueriesUser(orgID int64) *user.SignedInUser {
+	return &user.SignedInUser{
+		UserID:           -1,
+		IsServiceAccount: true,
+		Login:            "grafana_recorded_queries",
+		OrgID:            orgID,
+		OrgRole:          org.RoleAdmin,
+		Permissions: map[int64]map[string][]string{
+			orgID: {
+				datasources.ActionQuery: []string{
+					datasources.ScopeAll,
+				},
+			},
+		},
+	}
+}
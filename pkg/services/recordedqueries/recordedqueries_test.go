@@ -0,0 +1,202 @@
+package recordedqueries
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+func TestReadConfig(t *testing.T) {
+	t.Run("reads the recorded queries of the directory", func(t *testing.T) {
+		queries, err := readConfig("testdata/valid")
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+
+		q := queries[0]
+		assert.Equal(t, "api error ratio", q.Name)
+		assert.Equal(t, "api_error_ratio", q.Metric)
+		assert.Equal(t, int64(2), q.OrgID)
+		assert.Equal(t, 5*time.Minute, q.Interval)
+		assert.Equal(t, time.Hour, q.Range)
+		assert.Equal(t, "C", q.RefID)
+		assert.Equal(t, map[string]string{"team": "backend"}, q.Labels)
+		require.Len(t, q.Queries, 3)
+		assert.Equal(t, "$A / $B", q.Queries[2].Get("expression").MustString())
+		assert.Equal(t, "prometheus", q.Queries[1].GetPath("datasource", "uid").MustString())
+
+		q = queries[1]
+		assert.Equal(t, "recorded_up", q.Metric)
+		assert.Equal(t, int64(1), q.OrgID)
+		assert.Equal(t, defaultInterval, q.Interval)
+		assert.Equal(t, defaultRange, q.Range)
+	})
+
+	t.Run("no recorded queries if the directory does not exist", func(t *testing.T) {
+		queries, err := readConfig("testdata/missing")
+		require.NoError(t, err)
+		require.Empty(t, queries)
+	})
+
+	t.Run("fails if a recorded query is defined twice", func(t *testing.T) {
+		_, err := readConfig("testdata/duplicate")
+		require.ErrorContains(t, err, `recorded query "up" of org 1 is defined in both`)
+	})
+
+	t.Run("validates the recorded queries", func(t *testing.T) {
+		for _, cfg := range []recordedQueryConfig{
+			{Queries: []map[string]any{{"refId": "A"}}},
+			{Name: "up"},
+			{Name: "up", Interval: "soon", Queries: []map[string]any{{"refId": "A"}}},
+			{Name: "up", Range: "-1m", Queries: []map[string]any{{"refId": "A"}}},
+			{Name: "up", Labels: map[string]string{"-": "a"}, Queries: []map[string]any{{"refId": "A"}}},
+		} {
+			_, err := cfg.recordedQuery()
+			require.Error(t, err, "config %+v", cfg)
+		}
+	})
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	q := RecordedQuery{
+		Name:     "api error ratio",
+		Metric:   "api_error_ratio",
+		OrgID:    2,
+		Interval: time.Minute,
+		Range:    time.Hour,
+		RefID:    "C",
+		Labels:   map[string]string{"team": "backend"},
+		Queries:  []*simplejson.Json{simplejson.NewFromAny(map[string]any{"refId": "C"})},
+	}
+
+	var written *prompb.WriteRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/write", r.URL.Path)
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		written = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(decoded, written))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	queryService := &fakeQueryService{resp: &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("", data.NewField("Value", nil, []float64{100}))}},
+		"C": {Frames: data.Frames{
+			data.NewFrame("",
+				data.NewField("Time", nil, []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute)}),
+				data.NewField("Value", data.Labels{"app": "api", "team": "frontend"}, []*float64{pointer(0.5), nil}),
+			),
+			data.NewFrame("", data.NewField("Value", data.Labels{"app": "db"}, []float64{0.25})),
+		}},
+	}}}
+	s := &Service{
+		log:          log.NewNopLogger(),
+		queryService: queryService,
+		metrics:      newMetrics(nil),
+		now:          func() time.Time { return now },
+		writer: &remoteWriter{
+			dataSources: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+				{UID: "prom", OrgID: 1, URL: server.URL},
+			}},
+			httpClientProvider: sdkhttpclient.NewProvider(),
+			dataSourceUID:      "prom",
+			dataSourceOrgID:    1,
+			path:               "/api/v1/write",
+		},
+	}
+
+	series, err := s.record(context.Background(), q)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+
+	assert.Equal(t, int64(2), queryService.user.GetOrgID())
+	assert.Equal(t, "1699996400000", queryService.req.From)
+	assert.Equal(t, "1700000000000", queryService.req.To)
+
+	require.NotNil(t, written)
+	assert.ElementsMatch(t, []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "api_error_ratio"},
+				{Name: "app", Value: "api"},
+				{Name: "recorded_query", Value: "api error ratio"},
+				{Name: "team", Value: "backend"},
+			},
+			Samples: []prompb.Sample{{Timestamp: now.Add(-2 * time.Minute).UnixMilli(), Value: 0.5}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "api_error_ratio"},
+				{Name: "app", Value: "db"},
+				{Name: "recorded_query", Value: "api error ratio"},
+				{Name: "team", Value: "backend"},
+			},
+			Samples: []prompb.Sample{{Timestamp: now.UnixMilli(), Value: 0.25}},
+		},
+	}, written.Timeseries)
+
+	t.Run("fails if the query fails", func(t *testing.T) {
+		queryService.resp = &backend.QueryDataResponse{Responses: backend.Responses{
+			"C": {Error: assert.AnError},
+		}}
+		_, err := s.record(context.Background(), q)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("fails if the refId has no results", func(t *testing.T) {
+		queryService.resp = &backend.QueryDataResponse{Responses: backend.Responses{}}
+		_, err := s.record(context.Background(), q)
+		require.ErrorContains(t, err, "no results for refId C")
+	})
+}
+
+type fakeQueryService struct {
+	resp *backend.QueryDataResponse
+	user identity.Requester
+	req  dtos.MetricRequest
+}
+
+func (f *fakeQueryService) Run(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeQueryService) QueryData(_ context.Context, user identity.Requester, _ bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	f.user, f.req = user, reqDTO
+	return f.resp, nil
+}
+
+func (f *fakeQueryService) GetDashboardResultStats(int64, string) []query.PanelResultStats {
+	return nil
+}
+
+func (f *fakeQueryService) GetPanelErrorStats(context.Context, int64) ([]query.PanelErrorStats, error) {
+	return nil, nil
+}
+
+func pointer(v float64) *float64 {
+	return &v
+}
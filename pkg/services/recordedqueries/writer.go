@@ -0,0 +1,68 @@
+package recordedqueries
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+const remoteWriteTimeout = 30 * time.Second
+
+// remoteWriter writes series to the remote write endpoint of a Prometheus data source, using the
+// authentication configured for the data source.
+type remoteWriter struct {
+	dataSources        datasources.DataSourceService
+	httpClientProvider httpclient.Provider
+	dataSourceUID      string
+	dataSourceOrgID    int64
+	path               string
+}
+
+func (w *remoteWriter) write(ctx context.Context, series []prompb.TimeSeries) error {
+	ds, err := w.dataSources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: w.dataSourceUID, OrgID: w.dataSourceOrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get the remote write data source: %w", err)
+	}
+	transport, err := w.dataSources.GetHTTPTransport(ctx, ds, w.httpClientProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create the transport of the remote write data source: %w", err)
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteWriteTimeout)
+	defer cancel()
+	url := strings.TrimSuffix(ds.URL, "/") + "/" + strings.TrimPrefix(w.path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the remote write request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response from the remote write endpoint: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
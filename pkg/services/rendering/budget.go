@@ -0,0 +1,204 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// RenderClass is the kind of render job, used to share the render budget between the users of the renderer.
+type RenderClass string
+
+const (
+	// RenderClassInteractive is a render requested by a user, for example from the share panel modal.
+	RenderClassInteractive RenderClass = "interactive"
+	// RenderClassReport is a render of a scheduled report.
+	RenderClassReport RenderClass = "report"
+	// RenderClassAlertScreenshot is a screenshot of a panel attached to an alert notification.
+	RenderClassAlertScreenshot RenderClass = "alert_screenshot"
+
+	budgetRejectedQueueFull = "queue_full"
+	budgetRejectedTimeout   = "timeout"
+)
+
+// renderClasses is the order in which the classes are served when they are equally far from their fair share.
+var renderClasses = []RenderClass{RenderClassAlertScreenshot, RenderClassInteractive, RenderClassReport}
+
+// renderBudget limits the number of renders that run at the same time across all the render classes.
+// When the budget is exhausted the renders wait in a queue per class, and every freed slot goes to the
+// waiting class with the fewest running renders relative to its weight, so that a burst of renders of
+// one class cannot starve the other classes.
+type renderBudget struct {
+	mtx          sync.Mutex
+	capacity     int
+	inFlight     int
+	queueSize    int
+	queueTimeout time.Duration
+	classes      map[RenderClass]*budgetClass
+}
+
+type budgetClass struct {
+	weight  int
+	running int
+	waiting []*budgetWaiter
+}
+
+type budgetWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newRenderBudget(cfg *setting.Cfg) *renderBudget {
+	if cfg.RendererBudget <= 0 {
+		return nil
+	}
+	b := &renderBudget{
+		capacity:     cfg.RendererBudget,
+		queueSize:    cfg.RendererBudgetQueueSize,
+		queueTimeout: cfg.RendererBudgetQueueTimeout,
+		classes:      make(map[RenderClass]*budgetClass, len(renderClasses)),
+	}
+	weights := map[RenderClass]int{
+		RenderClassInteractive:     cfg.RendererBudgetWeightInteractive,
+		RenderClassReport:          cfg.RendererBudgetWeightReport,
+		RenderClassAlertScreenshot: cfg.RendererBudgetWeightAlertScreenshot,
+	}
+	for _, class := range renderClasses {
+		weight := weights[class]
+		if weight < 1 {
+			weight = 1
+		}
+		b.classes[class] = &budgetClass{weight: weight}
+	}
+	return b
+}
+
+// acquire waits for a render slot for the class, and returns the function that releases it.
+// It returns ErrConcurrentLimitReached if the queue of the class is full or the render waited
+// longer than the queue timeout.
+func (b *renderBudget) acquire(ctx context.Context, class RenderClass) (func(), error) {
+	if _, ok := b.classes[class]; !ok {
+		class = RenderClassInteractive
+	}
+	start := time.Now()
+
+	b.mtx.Lock()
+	c := b.classes[class]
+	if b.inFlight < b.capacity && !b.hasWaiters() {
+		b.grant(class, c)
+		b.mtx.Unlock()
+		metrics.MRenderingBudgetWaitSeconds.WithLabelValues(string(class)).Observe(0)
+		return b.releaseFunc(class), nil
+	}
+	if len(c.waiting) >= b.queueSize {
+		b.mtx.Unlock()
+		metrics.MRenderingBudgetRejectedTotal.WithLabelValues(string(class), budgetRejectedQueueFull).Inc()
+		return nil, ErrConcurrentLimitReached
+	}
+	w := &budgetWaiter{ready: make(chan struct{})}
+	c.waiting = append(c.waiting, w)
+	metrics.MRenderingBudgetQueue.WithLabelValues(string(class)).Set(float64(len(c.waiting)))
+	b.mtx.Unlock()
+
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		metrics.MRenderingBudgetWaitSeconds.WithLabelValues(string(class)).Observe(time.Since(start).Seconds())
+		return b.releaseFunc(class), nil
+	case <-timer.C:
+		err = ErrConcurrentLimitReached
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if w.granted {
+		// The slot was granted while giving up, pass it on to the next waiting render.
+		b.releaseLocked(class)
+	} else {
+		b.removeWaiter(class, c, w)
+	}
+	if errors.Is(err, ErrConcurrentLimitReached) {
+		metrics.MRenderingBudgetRejectedTotal.WithLabelValues(string(class), budgetRejectedTimeout).Inc()
+	}
+	return nil, err
+}
+
+func (b *renderBudget) releaseFunc(class RenderClass) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mtx.Lock()
+			defer b.mtx.Unlock()
+			b.releaseLocked(class)
+		})
+	}
+}
+
+func (b *renderBudget) releaseLocked(class RenderClass) {
+	c := b.classes[class]
+	c.running--
+	b.inFlight--
+	metrics.MRenderingBudgetInFlight.WithLabelValues(string(class)).Set(float64(c.running))
+	b.dispatch()
+}
+
+// dispatch grants the free slots to the waiting renders of the classes furthest below their fair share.
+func (b *renderBudget) dispatch() {
+	for b.inFlight < b.capacity {
+		var next RenderClass
+		var nextClass *budgetClass
+		for _, class := range renderClasses {
+			c := b.classes[class]
+			if len(c.waiting) == 0 {
+				continue
+			}
+			// c.running/c.weight < nextClass.running/nextClass.weight
+			if nextClass == nil || c.running*nextClass.weight < nextClass.running*c.weight {
+				next, nextClass = class, c
+			}
+		}
+		if nextClass == nil {
+			return
+		}
+		w := nextClass.waiting[0]
+		nextClass.waiting = nextClass.waiting[1:]
+		metrics.MRenderingBudgetQueue.WithLabelValues(string(next)).Set(float64(len(nextClass.waiting)))
+		b.grant(next, nextClass)
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+func (b *renderBudget) grant(class RenderClass, c *budgetClass) {
+	c.running++
+	b.inFlight++
+	metrics.MRenderingBudgetInFlight.WithLabelValues(string(class)).Set(float64(c.running))
+}
+
+func (b *renderBudget) hasWaiters() bool {
+	for _, c := range b.classes {
+		if len(c.waiting) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *renderBudget) removeWaiter(class RenderClass, c *budgetClass, w *budgetWaiter) {
+	for i, waiting := range c.waiting {
+		if waiting == w {
+			c.waiting = append(c.waiting[:i], c.waiting[i+1:]...)
+			break
+		}
+	}
+	metrics.MRenderingBudgetQueue.WithLabelValues(string(class)).Set(float64(len(c.waiting)))
+}
@@ -0,0 +1,154 @@
+package rendering
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestRenderBudget(t *testing.T, capacity, queueSize int, queueTimeout time.Duration) *renderBudget {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.RendererBudget = capacity
+	cfg.RendererBudgetQueueSize = queueSize
+	cfg.RendererBudgetQueueTimeout = queueTimeout
+	cfg.RendererBudgetWeightInteractive = 3
+	cfg.RendererBudgetWeightReport = 1
+	cfg.RendererBudgetWeightAlertScreenshot = 2
+	b := newRenderBudget(cfg)
+	require.NotNil(t, b)
+	return b
+}
+
+func TestRenderBudget(t *testing.T) {
+	t.Run("disabled when the budget is not set", func(t *testing.T) {
+		assert.Nil(t, newRenderBudget(setting.NewCfg()))
+	})
+
+	t.Run("grants slots up to the budget", func(t *testing.T) {
+		b := newTestRenderBudget(t, 2, 10, time.Minute)
+
+		release1, err := b.acquire(context.Background(), RenderClassInteractive)
+		require.NoError(t, err)
+		release2, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+		assert.Equal(t, 2, b.inFlight)
+
+		release1()
+		release1()
+		release2()
+		assert.Equal(t, 0, b.inFlight)
+	})
+
+	t.Run("rejects renders when the queue of the class is full", func(t *testing.T) {
+		b := newTestRenderBudget(t, 1, 0, time.Minute)
+
+		release, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+		defer release()
+
+		_, err = b.acquire(context.Background(), RenderClassReport)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+	})
+
+	t.Run("rejects renders that wait longer than the queue timeout", func(t *testing.T) {
+		b := newTestRenderBudget(t, 1, 10, 10*time.Millisecond)
+
+		release, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+
+		_, err = b.acquire(context.Background(), RenderClassInteractive)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+
+		release()
+		assert.Equal(t, 0, b.inFlight)
+		assert.False(t, b.hasWaiters())
+	})
+
+	t.Run("stops waiting when the context is canceled", func(t *testing.T) {
+		b := newTestRenderBudget(t, 1, 10, time.Minute)
+
+		release, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = b.acquire(ctx, RenderClassAlertScreenshot)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, b.hasWaiters())
+	})
+
+	t.Run("gives freed slots to the class furthest below its share", func(t *testing.T) {
+		b := newTestRenderBudget(t, 2, 10, time.Minute)
+
+		release1, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+		release2, err := b.acquire(context.Background(), RenderClassReport)
+		require.NoError(t, err)
+		defer release2()
+
+		type grant struct {
+			class   RenderClass
+			release func()
+		}
+		granted := make(chan grant, 2)
+		wait := func(class RenderClass) {
+			go func() {
+				release, err := b.acquire(context.Background(), class)
+				if err == nil {
+					granted <- grant{class: class, release: release}
+				}
+			}()
+			require.Eventually(t, func() bool {
+				b.mtx.Lock()
+				defer b.mtx.Unlock()
+				return len(b.classes[class].waiting) > 0
+			}, time.Second, time.Millisecond)
+		}
+		wait(RenderClassReport)
+		wait(RenderClassAlertScreenshot)
+
+		release1()
+		select {
+		case g := <-granted:
+			assert.Equal(t, RenderClassAlertScreenshot, g.class)
+			defer g.release()
+		case <-time.After(time.Second):
+			t.Fatal("no render was granted the freed slot")
+		}
+		b.mtx.Lock()
+		assert.Len(t, b.classes[RenderClassReport].waiting, 1)
+		b.mtx.Unlock()
+	})
+}
+
+func TestAcquireRenderSlot(t *testing.T) {
+	t.Run("uses the concurrent limit when the budget is disabled", func(t *testing.T) {
+		rs := &RenderingService{Cfg: setting.NewCfg()}
+
+		release, err := rs.acquireRenderSlot(context.Background(), RenderClassInteractive, 1)
+		require.NoError(t, err)
+		release()
+
+		rs.inProgressCount = 2
+		_, err = rs.acquireRenderSlot(context.Background(), RenderClassInteractive, 1)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+	})
+
+	t.Run("ignores the concurrent limit when the budget is enabled", func(t *testing.T) {
+		rs := &RenderingService{Cfg: setting.NewCfg(), budget: newTestRenderBudget(t, 1, 0, time.Minute)}
+		rs.inProgressCount = 2
+
+		release, err := rs.acquireRenderSlot(context.Background(), RenderClassAlertScreenshot, 1)
+		require.NoError(t, err)
+		_, err = rs.acquireRenderSlot(context.Background(), RenderClassAlertScreenshot, 1)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+		release()
+	})
+}
@@ -51,6 +51,7 @@ type Opts struct {
 	Encoding          string
 	Timezone          string
 	ConcurrentLimit   int
+	Class             RenderClass
 	DeviceScaleFactor float64
 	Headers           map[string][]string
 	Theme             models.Theme
@@ -81,6 +82,7 @@ type CSVOpts struct {
 	Encoding        string
 	Timezone        string
 	ConcurrentLimit int
+	Class           RenderClass
 	Headers         map[string][]string
 }
 
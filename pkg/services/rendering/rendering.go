@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -35,6 +36,7 @@ type RenderingService struct {
 	sanitizeURL       string
 	domain            string
 	inProgressCount   int32
+	budget            *renderBudget
 	version           string
 	versionMutex      sync.RWMutex
 	capabilities      []Capability
@@ -44,9 +46,10 @@ type RenderingService struct {
 	features                    *featuremgmt.FeatureManager
 	RemoteCacheService          *remotecache.RemoteCache
 	RendererPluginManager       plugins.RendererManager
+	drainService                *drain.Service
 }
 
-func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager, remoteCache *remotecache.RemoteCache, rm plugins.RendererManager) (*RenderingService, error) {
+func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager, remoteCache *remotecache.RemoteCache, rm plugins.RendererManager, drainService *drain.Service) (*RenderingService, error) {
 	// ensure ImagesDir exists
 	err := os.MkdirAll(cfg.ImagesDir, 0700)
 	if err != nil {
@@ -118,9 +121,11 @@ func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager, remo
 		features:              features,
 		RemoteCacheService:    remoteCache,
 		RendererPluginManager: rm,
+		drainService:          drainService,
 		log:                   logger,
 		domain:                domain,
 		sanitizeURL:           sanitizeURL,
+		budget:                newRenderBudget(cfg),
 	}
 
 	gob.Register(&RenderUser{})
@@ -256,8 +261,15 @@ func (rs *RenderingService) Render(ctx context.Context, opts Opts, session Sessi
 }
 
 func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProvider renderKeyProvider) (*RenderResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
+	ctx, done, err := rs.trackRender(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := rs.acquireRenderSlot(ctx, opts.Class, opts.ConcurrentLimit)
+	if errors.Is(err, ErrConcurrentLimitReached) {
+		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "class", opts.Class, "path", opts.Path)
 		if opts.ErrorConcurrentLimitReached {
 			return nil, ErrConcurrentLimitReached
 		}
@@ -271,6 +283,10 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 			FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
 		}, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	if !rs.IsAvailable(ctx) {
 		rs.log.Warn("Could not render image, no image renderer found/installed. " +
@@ -316,6 +332,28 @@ func (rs *RenderingService) RenderCSV(ctx context.Context, opts CSVOpts, session
 	return result, err
 }
 
+// trackRender rejects new render jobs while the server is draining and
+// tracks the in-flight ones otherwise.
+func (rs *RenderingService) trackRender(ctx context.Context) (context.Context, func(), error) {
+	if err := rs.drainService.Accept(drain.KindRender); err != nil {
+		return nil, nil, err
+	}
+	ctx, done := rs.drainService.Track(ctx, drain.KindRender)
+	return ctx, done, nil
+}
+
+// acquireRenderSlot waits for a slot of the shared render budget if it is enabled. Otherwise, it
+// checks the number of renders in progress against the concurrent limit of the caller.
+func (rs *RenderingService) acquireRenderSlot(ctx context.Context, class RenderClass, concurrentLimit int) (func(), error) {
+	if rs.budget != nil {
+		return rs.budget.acquire(ctx, class)
+	}
+	if int(atomic.LoadInt32(&rs.inProgressCount)) > concurrentLimit {
+		return nil, ErrConcurrentLimitReached
+	}
+	return func() {}, nil
+}
+
 func (rs *RenderingService) SanitizeSVG(ctx context.Context, req *SanitizeSVGRequest) (*SanitizeSVGResponse, error) {
 	capability, err := rs.HasCapability(ctx, SvgSanitization)
 	if err != nil {
@@ -335,9 +373,17 @@ func (rs *RenderingService) SanitizeSVG(ctx context.Context, req *SanitizeSVGReq
 }
 
 func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderKeyProvider renderKeyProvider) (*RenderCSVResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		return nil, ErrConcurrentLimitReached
+	ctx, done, err := rs.trackRender(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := rs.acquireRenderSlot(ctx, opts.Class, opts.ConcurrentLimit)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	if !rs.IsAvailable(ctx) {
 		return nil, ErrRenderUnavailable
@@ -0,0 +1,89 @@
+package savedsearch
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// SavedSearch is a named dashboard search filter of a user.
+type SavedSearch struct {
+	ID     int64  `json:"-" xorm:"pk autoincr 'id'"`
+	UID    string `json:"uid" xorm:"uid"`
+	OrgID  int64  `json:"orgId" xorm:"org_id"`
+	UserID int64  `json:"-" xorm:"user_id"`
+	Name   string `json:"name" xorm:"name"`
+	Filter Filter `json:"filter" xorm:"jsonb filter"`
+	// Subscribed is true when the user is notified by email of the new dashboards matching the filter.
+	Subscribed bool `json:"subscribed" xorm:"subscribed"`
+	// Checked is when the dashboards matching the filter were last checked for a subscribed search
+	// in epoch milliseconds, zero when they were never checked.
+	Checked int64     `json:"-" xorm:"checked"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+func (s SavedSearch) TableName() string { return "saved_search" }
+
+// Filter is the filter of a saved search, with the parameters of the dashboard search API.
+type Filter struct {
+	// Query is the text the titles of the dashboards contain.
+	Query string `json:"query,omitempty"`
+	// Tags are the tags the dashboards all have.
+	Tags []string `json:"tags,omitempty"`
+	// FolderUIDs are the folders of the dashboards, any folder when empty.
+	FolderUIDs []string `json:"folderUids,omitempty"`
+}
+
+// Equal returns true if the filters match the same dashboards.
+func (f Filter) Equal(other Filter) bool {
+	return f.Query == other.Query && equalStrings(f.Tags, other.Tags) && equalStrings(f.FolderUIDs, other.FolderUIDs)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type CreateCommand struct {
+	Name       string `json:"name"`
+	Filter     Filter `json:"filter"`
+	Subscribed bool   `json:"subscribed"`
+
+	OrgID        int64              `json:"-"`
+	SignedInUser *user.SignedInUser `json:"-"`
+}
+
+type UpdateCommand struct {
+	Name       string `json:"name"`
+	Filter     Filter `json:"filter"`
+	Subscribed bool   `json:"subscribed"`
+
+	UID          string             `json:"-"`
+	OrgID        int64              `json:"-"`
+	SignedInUser *user.SignedInUser `json:"-"`
+}
+
+type DeleteCommand struct {
+	UID    string
+	OrgID  int64
+	UserID int64
+}
+
+type GetQuery struct {
+	UID    string
+	OrgID  int64
+	UserID int64
+}
+
+type ListQuery struct {
+	OrgID  int64
+	UserID int64
+}
@@ -0,0 +1,36 @@
+// Package savedsearch stores the dashboard search filters that users save under a name, and
+// notifies the users subscribed to a saved search when new dashboards match its filter.
+package savedsearch
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// MaxPerUser is the maximum number of saved searches of a user in an organization.
+	MaxPerUser = 100
+	// MaxNameLength is the maximum length of the name of a saved search.
+	MaxNameLength = 190
+)
+
+var (
+	ErrNotFound    = errutil.NotFound("savedsearch.not-found", errutil.WithPublicMessage("Saved search not found"))
+	ErrNameExists  = errutil.Conflict("savedsearch.name-exists", errutil.WithPublicMessage("A saved search with the same name already exists"))
+	ErrTooMany     = errutil.BadRequest("savedsearch.too-many", errutil.WithPublicMessage("Too many saved searches"))
+	ErrInvalidName = errutil.BadRequest("savedsearch.invalid-name")
+	ErrBadRequest  = errutil.BadRequest("savedsearch.bad-request")
+)
+
+type Service interface {
+	// Create saves a search of a user. When the user subscribes to the search, the dashboards
+	// that already match its filter are not notified.
+	Create(ctx context.Context, cmd *CreateCommand) (*SavedSearch, error)
+	// Update replaces the name, the filter and the subscription of a saved search.
+	Update(ctx context.Context, cmd *UpdateCommand) (*SavedSearch, error)
+	Delete(ctx context.Context, cmd *DeleteCommand) error
+	Get(ctx context.Context, query *GetQuery) (*SavedSearch, error)
+	// List returns the saved searches of a user in an organization, sorted by name.
+	List(ctx context.Context, query *ListQuery) ([]*SavedSearch, error)
+}
@@ -0,0 +1,173 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	tmplSavedSearchSubscription = "saved_search_subscription"
+
+	// maxHits is the maximum number of dashboards matched by a saved search per check.
+	maxHits = 5000
+	// maxNotifiedDashboards is the maximum number of dashboards listed in a notification.
+	maxNotifiedDashboards = 20
+)
+
+// IsDisabled returns true if the subscriptions to saved searches are not checked.
+func (s *Service) IsDisabled() bool {
+	return s.checkInterval <= 0
+}
+
+// Run checks the subscribed searches for new dashboards periodically. Only one instance checks
+// them at a time.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "check saved search subscriptions", s.checkInterval, func(ctx context.Context) {
+				s.checkSubscriptions(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute the check of saved search subscriptions", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkSubscriptions notifies the users subscribed to saved searches of the dashboards that
+// newly match them.
+func (s *Service) checkSubscriptions(ctx context.Context) {
+	searches, err := s.store.ListSubscribed(ctx)
+	if err != nil {
+		s.log.Error("Failed to list the subscribed saved searches", "error", err)
+		return
+	}
+
+	for _, search := range searches {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.checkSubscription(ctx, search); err != nil {
+			if errors.Is(err, errUserNotMember) {
+				s.log.Debug("Skipping the saved search of a user that is not a member of its organization", "uid", search.UID, "orgId", search.OrgID, "userId", search.UserID)
+				continue
+			}
+			s.log.Error("Failed to check the saved search subscription", "uid", search.UID, "orgId", search.OrgID, "error", err)
+		}
+	}
+}
+
+func (s *Service) checkSubscription(ctx context.Context, search *savedsearch.SavedSearch) error {
+	usr, err := s.signedInUser(ctx, search)
+	if err != nil {
+		return err
+	}
+	hits, err := s.run(ctx, search, usr)
+	if err != nil {
+		return err
+	}
+	known, err := s.store.Matches(ctx, search.ID)
+	if err != nil {
+		return err
+	}
+
+	newHits := make(model.HitList, 0)
+	uids := make([]string, 0)
+	for _, hit := range hits {
+		if !known[hit.UID] {
+			newHits = append(newHits, hit)
+			uids = append(uids, hit.UID)
+		}
+	}
+
+	// The first check of a search records the dashboards it matches without notifying them.
+	if search.Checked != 0 && len(newHits) > 0 {
+		if err := s.notify(ctx, search, usr, newHits); err != nil {
+			return err
+		}
+	}
+	return s.store.AddMatches(ctx, search.ID, uids, s.now().UnixMilli())
+}
+
+// run returns the dashboards that match the filter of a saved search and that the user can view.
+func (s *Service) run(ctx context.Context, saved *savedsearch.SavedSearch, usr *user.SignedInUser) (model.HitList, error) {
+	return s.search.SearchHandler(ctx, &search.Query{
+		Title:        saved.Filter.Query,
+		Tags:         saved.Filter.Tags,
+		FolderUIDs:   saved.Filter.FolderUIDs,
+		Type:         string(model.DashHitDB),
+		OrgId:        saved.OrgID,
+		SignedInUser: usr,
+		Limit:        maxHits,
+	})
+}
+
+// notify emails the user subscribed to a saved search the dashboards that newly match it.
+func (s *Service) notify(ctx context.Context, search *savedsearch.SavedSearch, usr *user.SignedInUser, hits model.HitList) error {
+	if usr.Email == "" {
+		return nil
+	}
+
+	dashboards := make([]map[string]string, 0, maxNotifiedDashboards)
+	for i, hit := range hits {
+		if i == maxNotifiedDashboards {
+			break
+		}
+		dashboards = append(dashboards, map[string]string{
+			"Title":       hit.Title,
+			"FolderTitle": hit.FolderTitle,
+			"URL":         s.absURL(hit.URL),
+		})
+	}
+
+	return s.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{usr.Email},
+		Template: tmplSavedSearchSubscription,
+		OrgID:    search.OrgID,
+		UserID:   usr.UserID,
+		Data: map[string]any{
+			"Name":       usr.NameOrFallback(),
+			"SearchName": search.Name,
+			"Count":      len(hits),
+			"Dashboards": dashboards,
+			"More":       len(hits) - len(dashboards),
+			"Link":       s.searchURL(search.Filter),
+		},
+	})
+}
+
+// absURL returns the absolute URL of a path of the instance, as returned by the search.
+func (s *Service) absURL(path string) string {
+	return strings.TrimSuffix(s.cfg.AppURL, "/") + strings.TrimPrefix(path, s.cfg.AppSubURL)
+}
+
+// searchURL returns the URL of the dashboard search with the filter of a saved search.
+func (s *Service) searchURL(filter savedsearch.Filter) string {
+	params := url.Values{}
+	if filter.Query != "" {
+		params.Set("query", filter.Query)
+	}
+	for _, tag := range filter.Tags {
+		params.Add("tag", tag)
+	}
+	link := strings.TrimSuffix(s.cfg.AppURL, "/") + "/dashboards"
+	if len(params) > 0 {
+		link += "?" + params.Encode()
+	}
+	return link
+}
@@ -0,0 +1,217 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	log           log.Logger
+	search        search.Service
+	userService   user.Service
+	ac            accesscontrol.Service
+	emailSender   notifications.EmailSender
+	serverLock    *serverlock.ServerLockService
+	checkInterval time.Duration
+
+	now func() time.Time
+}
+
+var _ savedsearch.Service = &Service{}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	db db.DB,
+	searchService search.Service,
+	userService user.Service,
+	ac accesscontrol.Service,
+	emailSender notifications.EmailSender,
+	serverLock *serverlock.ServerLockService,
+	cleanupRegistry janitor.Registry,
+) (*Service, error) {
+	s := &Service{
+		cfg:           cfg,
+		store:         &sqlStore{db: db},
+		log:           log.New("savedsearch"),
+		search:        searchService,
+		userService:   userService,
+		ac:            ac,
+		emailSender:   emailSender,
+		serverLock:    serverLock,
+		checkInterval: cfg.SectionWithEnvOverrides("search").Key("saved_search_check_interval").MustDuration(15 * time.Minute),
+		now:           time.Now,
+	}
+
+	if err := cleanupRegistry.RegisterTask(janitor.Task{
+		Name:     "orphaned_saved_searches",
+		Schedule: "@every 1h",
+		Run:      s.store.DeleteOrphans,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Service) Create(ctx context.Context, cmd *savedsearch.CreateCommand) (*savedsearch.SavedSearch, error) {
+	name, err := validate(cmd.Name, cmd.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.Count(ctx, cmd.OrgID, cmd.SignedInUser.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= savedsearch.MaxPerUser {
+		return nil, savedsearch.ErrTooMany.Errorf("user %d has %d saved searches, the maximum is %d", cmd.SignedInUser.UserID, count, savedsearch.MaxPerUser)
+	}
+
+	now := s.now()
+	search := &savedsearch.SavedSearch{
+		UID:        util.GenerateShortUID(),
+		OrgID:      cmd.OrgID,
+		UserID:     cmd.SignedInUser.UserID,
+		Name:       name,
+		Filter:     cmd.Filter,
+		Subscribed: cmd.Subscribed,
+		Created:    now,
+		Updated:    now,
+	}
+	if err := s.store.Insert(ctx, search); err != nil {
+		return nil, err
+	}
+
+	if search.Subscribed {
+		s.recordMatches(ctx, search, cmd.SignedInUser)
+	}
+	return search, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *savedsearch.UpdateCommand) (*savedsearch.SavedSearch, error) {
+	name, err := validate(cmd.Name, cmd.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := s.store.Get(ctx, cmd.OrgID, cmd.SignedInUser.UserID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The dashboards matching the previous filter, or matching while the user was not subscribed,
+	// are not new to the user: they are matched again without being notified.
+	resetMatches := !search.Filter.Equal(cmd.Filter) || (cmd.Subscribed && !search.Subscribed)
+	search.Name = name
+	search.Filter = cmd.Filter
+	search.Subscribed = cmd.Subscribed
+	search.Updated = s.now()
+	if resetMatches {
+		search.Checked = 0
+	}
+	if err := s.store.Update(ctx, search, resetMatches); err != nil {
+		return nil, err
+	}
+
+	if search.Subscribed && resetMatches {
+		s.recordMatches(ctx, search, cmd.SignedInUser)
+	}
+	return search, nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *savedsearch.DeleteCommand) error {
+	return s.store.Delete(ctx, cmd.OrgID, cmd.UserID, cmd.UID)
+}
+
+func (s *Service) Get(ctx context.Context, query *savedsearch.GetQuery) (*savedsearch.SavedSearch, error) {
+	return s.store.Get(ctx, query.OrgID, query.UserID, query.UID)
+}
+
+func (s *Service) List(ctx context.Context, query *savedsearch.ListQuery) ([]*savedsearch.SavedSearch, error) {
+	return s.store.List(ctx, query.OrgID, query.UserID)
+}
+
+// recordMatches records the dashboards matching the filter of a search the user just subscribed
+// to, so that only the dashboards that match later are notified. On failure the matches are
+// recorded by the next check instead.
+func (s *Service) recordMatches(ctx context.Context, search *savedsearch.SavedSearch, usr *user.SignedInUser) {
+	logger := s.log.FromContext(ctx)
+	hits, err := s.run(ctx, search, usr)
+	if err != nil {
+		logger.Warn("Failed to run the saved search", "uid", search.UID, "error", err)
+		return
+	}
+	uids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		uids = append(uids, hit.UID)
+	}
+	checked := s.now().UnixMilli()
+	if err := s.store.AddMatches(ctx, search.ID, uids, checked); err != nil {
+		logger.Warn("Failed to record the dashboards matching the saved search", "uid", search.UID, "error", err)
+		return
+	}
+	search.Checked = checked
+}
+
+func validate(name string, filter savedsearch.Filter) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", savedsearch.ErrInvalidName.Errorf("empty saved search name")
+	}
+	if len(name) > savedsearch.MaxNameLength {
+		return "", savedsearch.ErrInvalidName.Errorf("saved search name is %d characters long, the maximum is %d", len(name), savedsearch.MaxNameLength)
+	}
+	for _, tag := range filter.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return "", savedsearch.ErrBadRequest.Errorf("empty tag in the saved search filter")
+		}
+	}
+	for _, uid := range filter.FolderUIDs {
+		if !util.IsValidShortUID(uid) {
+			return "", savedsearch.ErrBadRequest.Errorf("invalid folder UID %q in the saved search filter", uid)
+		}
+	}
+	return name, nil
+}
+
+// errUserNotMember is returned when the user of a saved search cannot be found in its organization.
+var errUserNotMember = errors.New("user is not a member of the organization")
+
+// signedInUser returns the user of a saved search with the permissions the user has in the
+// organization of the search.
+func (s *Service) signedInUser(ctx context.Context, search *savedsearch.SavedSearch) (*user.SignedInUser, error) {
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: search.UserID, OrgID: search.OrgID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, errUserNotMember
+		}
+		return nil, err
+	}
+	if usr.OrgID != search.OrgID || usr.IsDisabled {
+		return nil, errUserNotMember
+	}
+
+	permissions, err := s.ac.GetUserPermissions(ctx, usr, accesscontrol.Options{ReloadCache: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the permissions of the user: %w", err)
+	}
+	usr.Permissions = map[int64]map[string][]string{search.OrgID: accesscontrol.GroupScopesByAction(permissions)}
+	return usr, nil
+}
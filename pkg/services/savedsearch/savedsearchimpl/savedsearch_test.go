@@ -0,0 +1,234 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/cleanup/janitor/janitortest"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+type fakeSearchService struct {
+	hits        model.HitList
+	expectedErr error
+	queries     []*search.Query
+}
+
+func (f *fakeSearchService) SearchHandler(_ context.Context, query *search.Query) (model.HitList, error) {
+	f.queries = append(f.queries, query)
+	return f.hits, f.expectedErr
+}
+
+func (f *fakeSearchService) SortOptions() []model.SortOption {
+	return nil
+}
+
+func (f *fakeSearchService) setDashboards(uids ...string) {
+	f.hits = make(model.HitList, 0, len(uids))
+	for _, uid := range uids {
+		f.hits = append(f.hits, &model.Hit{UID: uid, Title: "Dashboard " + uid, URL: "/grafana/d/" + uid + "/dashboard", Type: model.DashHitDB, FolderTitle: "Team"})
+	}
+}
+
+type testService struct {
+	*Service
+	db       db.DB
+	search   *fakeSearchService
+	users    *usertest.FakeUserService
+	emails   []*notifications.SendEmailCommand
+	janitors *janitortest.FakeRegistry
+}
+
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.AppURL = "https://grafana.example.com/grafana/"
+	cfg.AppSubURL = "/grafana"
+
+	ts := &testService{
+		db:       db.InitTestDB(t),
+		search:   &fakeSearchService{},
+		users:    &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1, Login: "alice", Name: "Alice", Email: "alice@example.com"}},
+		janitors: &janitortest.FakeRegistry{},
+	}
+	emailSender := &notifications.NotificationServiceMock{
+		EmailHandler: func(_ context.Context, cmd *notifications.SendEmailCommand) error {
+			ts.emails = append(ts.emails, cmd)
+			return nil
+		},
+	}
+
+	s, err := ProvideService(cfg, ts.db, ts.search, ts.users, actest.FakeService{}, emailSender, nil, ts.janitors)
+	require.NoError(t, err)
+	clock := time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	ts.Service = s
+	return ts
+}
+
+func (ts *testService) create(t *testing.T, name string, filter savedsearch.Filter, subscribed bool) *savedsearch.SavedSearch {
+	t.Helper()
+	search, err := ts.Create(context.Background(), &savedsearch.CreateCommand{
+		Name:         name,
+		Filter:       filter,
+		Subscribed:   subscribed,
+		OrgID:        1,
+		SignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1},
+	})
+	require.NoError(t, err)
+	return search
+}
+
+func TestIntegrationSavedSearch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	t.Run("creates, lists, updates and deletes saved searches", func(t *testing.T) {
+		ts := newTestService(t)
+		prod := ts.create(t, " Production ", savedsearch.Filter{Query: "overview", Tags: []string{"prod"}}, false)
+		assert.Equal(t, "Production", prod.Name)
+		assert.NotEmpty(t, prod.UID)
+		ts.create(t, "Billing", savedsearch.Filter{FolderUIDs: []string{"billing"}}, false)
+
+		_, err := ts.Create(ctx, &savedsearch.CreateCommand{Name: "Production", OrgID: 1, SignedInUser: &user.SignedInUser{UserID: 1}})
+		assert.ErrorIs(t, err, savedsearch.ErrNameExists)
+		_, err = ts.Create(ctx, &savedsearch.CreateCommand{Name: " ", OrgID: 1, SignedInUser: &user.SignedInUser{UserID: 1}})
+		assert.ErrorIs(t, err, savedsearch.ErrInvalidName)
+		_, err = ts.Create(ctx, &savedsearch.CreateCommand{Name: "Folders", Filter: savedsearch.Filter{FolderUIDs: []string{"not a uid"}}, OrgID: 1, SignedInUser: &user.SignedInUser{UserID: 1}})
+		assert.ErrorIs(t, err, savedsearch.ErrBadRequest)
+
+		searches, err := ts.List(ctx, &savedsearch.ListQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		require.Len(t, searches, 2)
+		assert.Equal(t, "Billing", searches[0].Name)
+		assert.Equal(t, savedsearch.Filter{Query: "overview", Tags: []string{"prod"}}, searches[1].Filter)
+
+		searches, err = ts.List(ctx, &savedsearch.ListQuery{OrgID: 1, UserID: 2})
+		require.NoError(t, err)
+		assert.Empty(t, searches)
+		_, err = ts.Get(ctx, &savedsearch.GetQuery{UID: prod.UID, OrgID: 1, UserID: 2})
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+
+		updated, err := ts.Update(ctx, &savedsearch.UpdateCommand{
+			UID:          prod.UID,
+			Name:         "Production overviews",
+			Filter:       savedsearch.Filter{Query: "overview"},
+			OrgID:        1,
+			SignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1},
+		})
+		require.NoError(t, err)
+		got, err := ts.Get(ctx, &savedsearch.GetQuery{UID: prod.UID, OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, updated.Name, got.Name)
+		assert.Equal(t, savedsearch.Filter{Query: "overview"}, got.Filter)
+
+		_, err = ts.Update(ctx, &savedsearch.UpdateCommand{UID: prod.UID, Name: "Billing", OrgID: 1, SignedInUser: &user.SignedInUser{UserID: 1}})
+		assert.ErrorIs(t, err, savedsearch.ErrNameExists)
+
+		require.NoError(t, ts.Delete(ctx, &savedsearch.DeleteCommand{UID: prod.UID, OrgID: 1, UserID: 1}))
+		err = ts.Delete(ctx, &savedsearch.DeleteCommand{UID: prod.UID, OrgID: 1, UserID: 1})
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+	})
+
+	t.Run("notifies the new dashboards matching a subscribed search", func(t *testing.T) {
+		ts := newTestService(t)
+		ts.search.setDashboards("a", "b")
+		prod := ts.create(t, "Production", savedsearch.Filter{Query: "overview", Tags: []string{"prod"}}, true)
+		require.Len(t, ts.search.queries, 1)
+		assert.Equal(t, "overview", ts.search.queries[0].Title)
+		assert.Equal(t, []string{"prod"}, ts.search.queries[0].Tags)
+		assert.Equal(t, int64(1), ts.search.queries[0].OrgId)
+
+		ts.checkSubscriptions(ctx)
+		assert.Empty(t, ts.emails)
+
+		ts.search.setDashboards("a", "b", "c")
+		ts.checkSubscriptions(ctx)
+		require.Len(t, ts.emails, 1)
+		email := ts.emails[0]
+		assert.Equal(t, []string{"alice@example.com"}, email.To)
+		assert.Equal(t, tmplSavedSearchSubscription, email.Template)
+		assert.Equal(t, "Production", email.Data["SearchName"])
+		assert.Equal(t, 1, email.Data["Count"])
+		assert.Equal(t, []map[string]string{{"Title": "Dashboard c", "FolderTitle": "Team", "URL": "https://grafana.example.com/grafana/d/c/dashboard"}}, email.Data["Dashboards"])
+		assert.Equal(t, "https://grafana.example.com/grafana/dashboards?query=overview&tag=prod", email.Data["Link"])
+
+		ts.checkSubscriptions(ctx)
+		assert.Len(t, ts.emails, 1)
+
+		// The dashboards matching a new filter are not notified.
+		_, err := ts.Update(ctx, &savedsearch.UpdateCommand{UID: prod.UID, Name: "Production", Subscribed: true, OrgID: 1, SignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1}})
+		require.NoError(t, err)
+		ts.checkSubscriptions(ctx)
+		assert.Len(t, ts.emails, 1)
+	})
+
+	t.Run("records the matching dashboards on the first check when they could not be recorded on subscription", func(t *testing.T) {
+		ts := newTestService(t)
+		ts.search.setDashboards("a")
+		ts.search.expectedErr = errors.New("search failed")
+		ts.create(t, "Production", savedsearch.Filter{Query: "overview"}, true)
+
+		ts.search.expectedErr = nil
+		ts.checkSubscriptions(ctx)
+		assert.Empty(t, ts.emails)
+
+		ts.search.setDashboards("a", "b")
+		ts.checkSubscriptions(ctx)
+		assert.Len(t, ts.emails, 1)
+	})
+
+	t.Run("doesn't notify the users who are no longer members of the organization", func(t *testing.T) {
+		ts := newTestService(t)
+		ts.create(t, "Production", savedsearch.Filter{Query: "overview"}, true)
+
+		ts.search.setDashboards("a")
+		ts.users.ExpectedSignedInUser = &user.SignedInUser{UserID: 1, OrgID: -1, Email: "alice@example.com"}
+		ts.checkSubscriptions(ctx)
+		assert.Empty(t, ts.emails)
+	})
+
+	t.Run("deletes the saved searches of the users who are no longer members of the organization", func(t *testing.T) {
+		ts := newTestService(t)
+		ts.create(t, "Production", savedsearch.Filter{Query: "overview"}, false)
+		require.Len(t, ts.janitors.Tasks, 1)
+
+		deleted, err := ts.janitors.Tasks[0].Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		searches, err := ts.List(ctx, &savedsearch.ListQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, searches)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	_, err := validate(strings.Repeat("a", savedsearch.MaxNameLength+1), savedsearch.Filter{})
+	assert.ErrorIs(t, err, savedsearch.ErrInvalidName)
+
+	_, err = validate("Production", savedsearch.Filter{Tags: []string{"prod", ""}})
+	assert.ErrorIs(t, err, savedsearch.ErrBadRequest)
+	var gfErr errutil.Error
+	require.ErrorAs(t, err, &gfErr)
+	assert.Equal(t, "savedsearch.bad-request", gfErr.MessageID)
+}
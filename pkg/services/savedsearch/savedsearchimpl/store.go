@@ -0,0 +1,190 @@
+package savedsearchimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+)
+
+// match is a dashboard that matched the filter of a subscribed search.
+type match struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	SavedSearchID int64  `xorm:"saved_search_id"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+}
+
+func (m match) TableName() string { return "saved_search_match" }
+
+type store interface {
+	Insert(ctx context.Context, s *savedsearch.SavedSearch) error
+	// Update updates a saved search, and deletes its matches when resetMatches is true.
+	Update(ctx context.Context, s *savedsearch.SavedSearch, resetMatches bool) error
+	Delete(ctx context.Context, orgID, userID int64, uid string) error
+	Get(ctx context.Context, orgID, userID int64, uid string) (*savedsearch.SavedSearch, error)
+	List(ctx context.Context, orgID, userID int64) ([]*savedsearch.SavedSearch, error)
+	Count(ctx context.Context, orgID, userID int64) (int64, error)
+	// ListSubscribed returns the subscribed searches of all the organizations.
+	ListSubscribed(ctx context.Context) ([]*savedsearch.SavedSearch, error)
+	// Matches returns the UIDs of the dashboards that matched a saved search.
+	Matches(ctx context.Context, id int64) (map[string]bool, error)
+	// AddMatches adds the dashboards that matched a saved search and sets when it was checked.
+	AddMatches(ctx context.Context, id int64, dashboardUIDs []string, checked int64) error
+	// DeleteOrphans deletes the saved searches of users that are no longer members of their
+	// organization, and the matches of deleted searches.
+	DeleteOrphans(ctx context.Context) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Insert(ctx context.Context, search *savedsearch.SavedSearch) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(search)
+		if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+			return savedsearch.ErrNameExists.Errorf("saved search %q already exists", search.Name)
+		}
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, search *savedsearch.SavedSearch, resetMatches bool) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(search.ID).AllCols().Update(search)
+		if err != nil {
+			if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+				return savedsearch.ErrNameExists.Errorf("saved search %q already exists", search.Name)
+			}
+			return err
+		}
+		if affected == 0 {
+			return savedsearch.ErrNotFound.Errorf("saved search %s not found", search.UID)
+		}
+		if resetMatches {
+			_, err = sess.Where("saved_search_id = ?", search.ID).Delete(&match{})
+		}
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID, userID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		search := &savedsearch.SavedSearch{}
+		has, err := sess.Where("org_id = ? AND user_id = ? AND uid = ?", orgID, userID, uid).Get(search)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return savedsearch.ErrNotFound.Errorf("saved search %s not found", uid)
+		}
+		if _, err := sess.ID(search.ID).Delete(&savedsearch.SavedSearch{}); err != nil {
+			return err
+		}
+		_, err = sess.Where("saved_search_id = ?", search.ID).Delete(&match{})
+		return err
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID, userID int64, uid string) (*savedsearch.SavedSearch, error) {
+	search := &savedsearch.SavedSearch{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND user_id = ? AND uid = ?", orgID, userID, uid).Get(search)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return savedsearch.ErrNotFound.Errorf("saved search %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID, userID int64) ([]*savedsearch.SavedSearch, error) {
+	result := make([]*savedsearch.SavedSearch, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND user_id = ?", orgID, userID).Asc("name").Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) Count(ctx context.Context, orgID, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("org_id = ? AND user_id = ?", orgID, userID).Count(&savedsearch.SavedSearch{})
+		return err
+	})
+	return count, err
+}
+
+func (s *sqlStore) ListSubscribed(ctx context.Context) ([]*savedsearch.SavedSearch, error) {
+	result := make([]*savedsearch.SavedSearch, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("subscribed = ?", s.db.GetDialect().BooleanStr(true)).Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) Matches(ctx context.Context, id int64) (map[string]bool, error) {
+	var uids []string
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("saved_search_match").Where("saved_search_id = ?", id).Cols("dashboard_uid").Find(&uids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		result[uid] = true
+	}
+	return result, nil
+}
+
+func (s *sqlStore) AddMatches(ctx context.Context, id int64, dashboardUIDs []string, checked int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if len(dashboardUIDs) > 0 {
+			matches := make([]*match, 0, len(dashboardUIDs))
+			for _, uid := range dashboardUIDs {
+				matches = append(matches, &match{SavedSearchID: id, DashboardUID: uid})
+			}
+			if _, err := sess.InsertMulti(matches); err != nil {
+				return err
+			}
+		}
+		_, err := sess.Table("saved_search").Where("id = ?", id).Update(map[string]any{"checked": checked})
+		return err
+	})
+}
+
+func (s *sqlStore) DeleteOrphans(ctx context.Context) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec(`DELETE FROM saved_search WHERE NOT EXISTS (
+			SELECT 1 FROM org_user WHERE org_user.org_id = saved_search.org_id AND org_user.user_id = saved_search.user_id
+		)`)
+		if err != nil {
+			return err
+		}
+		if affected, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		res, err = sess.Exec(`DELETE FROM saved_search_match WHERE NOT EXISTS (
+			SELECT 1 FROM saved_search WHERE saved_search.id = saved_search_match.saved_search_id
+		)`)
+		if err != nil {
+			return err
+		}
+		matches, err := res.RowsAffected()
+		affected += matches
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,39 @@
+package savedsearchtest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+)
+
+type FakeService struct {
+	ExpectedSavedSearch   *savedsearch.SavedSearch
+	ExpectedSavedSearches []*savedsearch.SavedSearch
+	ExpectedError         error
+}
+
+var _ savedsearch.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Create(_ context.Context, _ *savedsearch.CreateCommand) (*savedsearch.SavedSearch, error) {
+	return f.ExpectedSavedSearch, f.ExpectedError
+}
+
+func (f *FakeService) Update(_ context.Context, _ *savedsearch.UpdateCommand) (*savedsearch.SavedSearch, error) {
+	return f.ExpectedSavedSearch, f.ExpectedError
+}
+
+func (f *FakeService) Delete(_ context.Context, _ *savedsearch.DeleteCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeService) Get(_ context.Context, _ *savedsearch.GetQuery) (*savedsearch.SavedSearch, error) {
+	return f.ExpectedSavedSearch, f.ExpectedError
+}
+
+func (f *FakeService) List(_ context.Context, _ *savedsearch.ListQuery) ([]*savedsearch.SavedSearch, error) {
+	return f.ExpectedSavedSearches, f.ExpectedError
+}
@@ -0,0 +1,322 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/drain"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	cardKeyPrefix = "screenshot-card-"
+	cardKeyLength = 32
+	// maxCardRows is the maximum number of values, and of thresholds, shown on a card.
+	maxCardRows = 20
+)
+
+var (
+	DefaultCardHeight = 300
+	DefaultCardWidth  = 600
+
+	ErrInvalidCard = errors.New("a summary card requires an org ID and a title")
+
+	//go:embed card.html
+	cardHTML     string
+	cardTemplate = template.Must(template.New("card").Parse(cardHTML))
+)
+
+// CardOptions are the options for rendering a summary card.
+type CardOptions struct {
+	// OrgID and Title are required.
+	OrgID int64
+	Title string
+
+	// State is the state of the alert, such as Alerting or Normal. It sets the
+	// color of the card.
+	State   string
+	Summary string
+	// Time is shown under the summary when it is set, in UTC.
+	Time time.Time
+
+	// Values and Thresholds are shown in the order they are given, up to 20 of each.
+	Values     []CardValue
+	Thresholds []CardThreshold
+
+	// Width, Height, Theme and Timeout inherit their defaults from
+	// DefaultCardWidth, DefaultCardHeight, DefaultTheme and DefaultTimeout.
+	Width   int
+	Height  int
+	Theme   models.Theme
+	Timeout time.Duration
+}
+
+// CardValue is a value of a summary card, such as the value of an expression of an alert rule.
+type CardValue struct {
+	Name  string
+	Value float64
+}
+
+// CardThreshold is a threshold of a summary card. Operator is the comparison of the
+// threshold, such as > or <.
+type CardThreshold struct {
+	Name     string
+	Operator string
+	Value    float64
+}
+
+// SetDefaults sets default values for missing or invalid options.
+func (s CardOptions) SetDefaults() CardOptions {
+	if s.Width <= 0 {
+		s.Width = DefaultCardWidth
+	}
+	if s.Height <= 0 {
+		s.Height = DefaultCardHeight
+	}
+	switch s.Theme {
+	case models.ThemeDark, models.ThemeLight:
+	default:
+		s.Theme = DefaultTheme
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = DefaultTimeout
+	}
+	if len(s.Values) > maxCardRows {
+		s.Values = s.Values[:maxCardRows]
+	}
+	if len(s.Thresholds) > maxCardRows {
+		s.Thresholds = s.Thresholds[:maxCardRows]
+	}
+	return s
+}
+
+// CardService is an interface for rendering summary cards, the images of the values and
+// thresholds of an alert, for the notifications of alerts without a dashboard panel or when
+// a screenshot of the panel is not needed.
+type CardService interface {
+	TakeCard(ctx context.Context, opts CardOptions) (*Screenshot, error)
+}
+
+// HeadlessCardService renders summary cards using a headless browser. The HTML of the card
+// is generated from a template and kept in the remote cache until the rendering service has
+// loaded it from /render/card/:key, with the render key of the request.
+type HeadlessCardService struct {
+	cache remotecache.CacheStorage
+	rs    rendering.Service
+
+	duration  prometheus.Histogram
+	failures  *prometheus.CounterVec
+	successes prometheus.Counter
+}
+
+// ProvideCardService returns a HeadlessCardService and registers the endpoint the rendering
+// service loads the cards from.
+func ProvideCardService(routeRegister routing.RouteRegister, cache remotecache.CacheStorage, rs rendering.Service, r prometheus.Registerer) *HeadlessCardService {
+	s := &HeadlessCardService{
+		cache: cache,
+		rs:    rs,
+		duration: mustRegisterOrGet(r, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:      "card_duration_seconds",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 15},
+			Namespace: namespace,
+			Subsystem: subsystem,
+		})).(prometheus.Histogram),
+		failures: mustRegisterOrGet(r, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      "card_failures_total",
+			Namespace: namespace,
+			Subsystem: subsystem,
+		}, []string{"reason"})).(*prometheus.CounterVec),
+		successes: mustRegisterOrGet(r, prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      "card_successes_total",
+			Namespace: namespace,
+			Subsystem: subsystem,
+		})).(prometheus.Counter),
+	}
+	routeRegister.Get("/render/card/:key", middleware.ReqSignedIn, routing.Wrap(s.serveCard))
+	return s
+}
+
+// TakeCard returns the image of a summary card. It uses both the context and the timeout
+// in CardOptions, like Take.
+func (s *HeadlessCardService) TakeCard(ctx context.Context, opts CardOptions) (*Screenshot, error) {
+	start := time.Now()
+	defer func() { s.duration.Observe(time.Since(start).Seconds()) }()
+
+	if opts.OrgID <= 0 || opts.Title == "" {
+		return nil, ErrInvalidCard
+	}
+	opts = opts.SetDefaults()
+
+	key, err := util.GetRandomString(cardKeyLength)
+	if err != nil {
+		s.instrumentError(err)
+		return nil, err
+	}
+	// The values are formatted before the card is stored, as JSON cannot encode NaN and infinite values.
+	data, err := json.Marshal(newCard(opts))
+	if err != nil {
+		s.instrumentError(err)
+		return nil, err
+	}
+	// The card is only needed until the rendering service has loaded it, which it must do
+	// within the timeout.
+	if err := s.cache.Set(ctx, cardKeyPrefix+key, data, opts.Timeout); err != nil {
+		s.instrumentError(err)
+		return nil, fmt.Errorf("failed to store summary card: %w", err)
+	}
+	defer func() { _ = s.cache.Delete(ctx, cardKeyPrefix+key) }()
+
+	u := url.URL{Path: path.Join("render", "card", key)}
+	p := u.Query()
+	p.Add("orgId", strconv.FormatInt(opts.OrgID, 10))
+	u.RawQuery = p.Encode()
+
+	renderOpts := rendering.Opts{
+		// The card only contains the options it was rendered with, so it does not need
+		// the permissions of an admin.
+		AuthOpts: rendering.AuthOpts{OrgID: opts.OrgID, OrgRole: org.RoleViewer},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: opts.Timeout,
+		},
+		Width:           opts.Width,
+		Height:          opts.Height,
+		Theme:           opts.Theme,
+		ConcurrentLimit: setting.AlertingRenderLimit,
+		Class:           rendering.RenderClassAlertScreenshot,
+		Path:            u.String(),
+	}
+
+	result, err := s.rs.Render(ctx, renderOpts, nil)
+	if errors.Is(err, drain.ErrDraining) {
+		return nil, ErrScreenshotsUnavailable
+	}
+	if err != nil {
+		s.instrumentError(err)
+		return nil, fmt.Errorf("failed to render summary card: %w", err)
+	}
+
+	defer s.successes.Inc()
+	return &Screenshot{Path: result.FilePath}, nil
+}
+
+func (s *HeadlessCardService) instrumentError(err error) {
+	reason := "error"
+	if errors.Is(err, context.Canceled) {
+		reason = "context_canceled"
+	}
+	s.failures.With(prometheus.Labels{"reason": reason}).Inc()
+}
+
+// serveCard returns the HTML of a summary card to the rendering service.
+func (s *HeadlessCardService) serveCard(c *contextmodel.ReqContext) response.Response {
+	if !c.IsRenderCall {
+		return response.Error(http.StatusForbidden, "Summary cards can only be loaded by the rendering service", nil)
+	}
+
+	data, err := s.cache.Get(c.Req.Context(), cardKeyPrefix+web.Params(c.Req)[":key"])
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return response.Error(http.StatusNotFound, "Summary card not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get summary card", err)
+	}
+	var cd card
+	if err := json.Unmarshal(data, &cd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get summary card", err)
+	}
+	if cd.OrgID != c.SignedInUser.GetOrgID() {
+		return response.Error(http.StatusNotFound, "Summary card not found", nil)
+	}
+
+	var buf bytes.Buffer
+	if err := cardTemplate.Execute(&buf, cd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to render summary card", err)
+	}
+	return response.CreateNormalResponse(http.Header{"Content-Type": []string{"text/html; charset=utf-8"}}, buf.Bytes(), http.StatusOK)
+}
+
+// card is the content of a summary card kept in the remote cache, as it is shown by the template.
+type card struct {
+	OrgID      int64              `json:"orgId"`
+	Title      string             `json:"title"`
+	State      string             `json:"state"`
+	StateClass string             `json:"stateClass"`
+	Summary    string             `json:"summary"`
+	Time       string             `json:"time"`
+	Theme      models.Theme       `json:"theme"`
+	Values     []cardValueRow     `json:"values"`
+	Thresholds []cardThresholdRow `json:"thresholds"`
+}
+
+type cardValueRow struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cardThresholdRow struct {
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+}
+
+func newCard(opts CardOptions) card {
+	c := card{
+		OrgID: opts.OrgID,
+		Title: opts.Title,
+		State: opts.State,
+		// The state class only selects the color of the card, the other states keep the default color.
+		StateClass: strings.ToLower(strings.ReplaceAll(opts.State, " ", "")),
+		Summary:    opts.Summary,
+		Theme:      opts.Theme,
+	}
+	if !opts.Time.IsZero() {
+		c.Time = opts.Time.UTC().Format("2006-01-02 15:04:05 MST")
+	}
+	for _, value := range opts.Values {
+		c.Values = append(c.Values, cardValueRow{Name: value.Name, Value: formatCardValue(value.Value)})
+	}
+	for _, threshold := range opts.Thresholds {
+		c.Thresholds = append(c.Thresholds, cardThresholdRow{
+			Name:      threshold.Name,
+			Condition: strings.TrimSpace(threshold.Operator + " " + formatCardValue(threshold.Value)),
+		})
+	}
+	return c
+}
+
+func formatCardValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// CardUnavailableService is a service that returns ErrScreenshotsUnavailable.
+type CardUnavailableService struct{}
+
+func (s *CardUnavailableService) TakeCard(_ context.Context, _ CardOptions) (*Screenshot, error) {
+	return nil, ErrScreenshotsUnavailable
+}
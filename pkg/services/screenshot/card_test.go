@@ -0,0 +1,143 @@
+package screenshot
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/drain"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestHeadlessCardService(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	cache := remotecache.NewFakeStore(t)
+	r := rendering.NewMockService(c)
+	s := ProvideCardService(routing.NewRouteRegister(), cache, r, prometheus.NewRegistry())
+	ctx := context.Background()
+
+	t.Run("a card without org ID or title should return error", func(t *testing.T) {
+		screenshot, err := s.TakeCard(ctx, CardOptions{OrgID: 1})
+		assert.ErrorIs(t, err, ErrInvalidCard)
+		assert.Nil(t, screenshot)
+	})
+
+	t.Run("should render the card", func(t *testing.T) {
+		opts := CardOptions{
+			OrgID:      2,
+			Title:      "High <b>CPU</b>",
+			State:      "Alerting",
+			Summary:    "CPU usage is above 80%",
+			Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Values:     []CardValue{{Name: "B", Value: 92.5}, {Name: "C", Value: math.NaN()}},
+			Thresholds: []CardThreshold{{Name: "B", Operator: ">", Value: 80}},
+		}
+
+		var html string
+		r.EXPECT().Render(ctx, gomock.Any(), nil).DoAndReturn(func(_ context.Context, renderOpts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+			path, query, _ := strings.Cut(renderOpts.Path, "?")
+			assert.Equal(t, "orgId=2", query)
+			key := strings.TrimPrefix(path, "render/card/")
+			assert.Len(t, key, cardKeyLength)
+
+			assert.Equal(t, rendering.Opts{
+				AuthOpts: rendering.AuthOpts{
+					OrgID:   2,
+					OrgRole: org.RoleViewer,
+				},
+				ErrorOpts: rendering.ErrorOpts{
+					ErrorConcurrentLimitReached: true,
+					ErrorRenderUnavailable:      true,
+				},
+				TimeoutOpts: rendering.TimeoutOpts{
+					Timeout: DefaultTimeout,
+				},
+				Width:           DefaultCardWidth,
+				Height:          DefaultCardHeight,
+				Theme:           DefaultTheme,
+				Path:            renderOpts.Path,
+				ConcurrentLimit: setting.AlertingRenderLimit,
+				Class:           rendering.RenderClassAlertScreenshot,
+			}, renderOpts)
+
+			// the card is served to the rendering service of the org only
+			resp := serveCard(t, s, key, 2, true)
+			require.Equal(t, http.StatusOK, resp.Status())
+			html = string(resp.Body())
+			assert.Equal(t, http.StatusForbidden, serveCard(t, s, key, 2, false).Status())
+			assert.Equal(t, http.StatusNotFound, serveCard(t, s, key, 3, true).Status())
+
+			return &rendering.RenderResult{FilePath: "card.png"}, nil
+		})
+
+		screenshot, err := s.TakeCard(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Screenshot{Path: "card.png"}, *screenshot)
+
+		assert.Contains(t, html, `<body class="dark">`)
+		assert.Contains(t, html, `<div class="card alerting">`)
+		assert.Contains(t, html, "High &lt;b&gt;CPU&lt;/b&gt;")
+		assert.Contains(t, html, "CPU usage is above 80%")
+		assert.Contains(t, html, "2024-01-02 03:04:05 UTC")
+		assert.Contains(t, html, `<tr><td>B</td><td class="value">92.5</td></tr>`)
+		assert.Contains(t, html, `<tr><td>C</td><td class="value">NaN</td></tr>`)
+		assert.Contains(t, html, `<tr><td>B</td><td class="value">&gt; 80</td></tr>`)
+
+		// the card is deleted once it is rendered
+		count, err := cache.Count(ctx, cardKeyPrefix)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should return ErrScreenshotsUnavailable while draining", func(t *testing.T) {
+		r.EXPECT().Render(ctx, gomock.Any(), nil).Return(nil, drain.ErrDraining)
+		screenshot, err := s.TakeCard(ctx, CardOptions{OrgID: 1, Title: "foo"})
+		assert.ErrorIs(t, err, ErrScreenshotsUnavailable)
+		assert.Nil(t, screenshot)
+	})
+
+	t.Run("an unknown card should not be found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serveCard(t, s, "unknown", 1, true).Status())
+	})
+}
+
+func TestCardOptions(t *testing.T) {
+	values := make([]CardValue, maxCardRows+1)
+	o := CardOptions{Values: values, Theme: "blue"}.SetDefaults()
+	assert.Equal(t, DefaultCardWidth, o.Width)
+	assert.Equal(t, DefaultCardHeight, o.Height)
+	assert.Equal(t, DefaultTheme, o.Theme)
+	assert.Equal(t, DefaultTimeout, o.Timeout)
+	assert.Len(t, o.Values, maxCardRows)
+}
+
+func serveCard(t *testing.T, s *HeadlessCardService, key string, orgID int64, isRenderCall bool) response.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "/render/card/"+key, nil)
+	require.NoError(t, err)
+	req = web.SetURLParams(req, map[string]string{":key": key})
+	c := &contextmodel.ReqContext{
+		Context:      &web.Context{Req: req},
+		SignedInUser: &user.SignedInUser{OrgID: orgID},
+		IsRenderCall: isRenderCall,
+	}
+	return s.serveCard(c)
+}
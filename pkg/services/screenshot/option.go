@@ -2,10 +2,13 @@ package screenshot
 
 import (
 	"hash/fnv"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
 )
 
 var (
@@ -17,7 +20,8 @@ var (
 
 // ScreenshotOptions are the options for taking a screenshot.
 type ScreenshotOptions struct {
-	// OrgID, DashboardUID and PanelID are required.
+	// OrgID and DashboardUID are required. The whole dashboard is taken
+	// when PanelID is not set.
 	OrgID        int64
 	DashboardUID string
 	PanelID      int64
@@ -31,6 +35,18 @@ type ScreenshotOptions struct {
 	Height  int
 	Theme   models.Theme
 	Timeout time.Duration
+
+	// Variables are the values of the dashboard variables, by variable name.
+	Variables map[string][]string
+
+	// UserID and OrgRole are the user the screenshot is taken as. It is
+	// taken as an org admin when OrgRole is not set.
+	UserID  int64
+	OrgRole org.RoleType
+
+	// Class is the render class of the screenshot, RenderClassAlertScreenshot
+	// by default.
+	Class rendering.RenderClass
 }
 
 // SetDefaults sets default values for missing or invalid options.
@@ -62,5 +78,19 @@ func (s ScreenshotOptions) Hash() []byte {
 	_, _ = h.Write([]byte(strconv.FormatInt(int64(s.Width), 10)))
 	_, _ = h.Write([]byte(strconv.FormatInt(int64(s.Height), 10)))
 	_, _ = h.Write([]byte(s.Theme))
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = h.Write([]byte(name))
+		for _, value := range s.Variables[name] {
+			_, _ = h.Write([]byte(value))
+		}
+	}
+	if s.UserID > 0 {
+		_, _ = h.Write([]byte(strconv.FormatInt(s.UserID, 10)))
+	}
 	return h.Sum(nil)
 }
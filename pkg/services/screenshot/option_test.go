@@ -112,3 +112,16 @@ func TestScreenshotOptions_Hash(t *testing.T) {
 	o.Timeout = DefaultTimeout + 1
 	assert.Equal(t, []byte{0x3b, 0xd1, 0xfb, 0x3f, 0x3, 0x64, 0xba, 0xad}, o.Hash())
 }
+
+func TestScreenshotOptions_HashVariables(t *testing.T) {
+	o := ScreenshotOptions{Variables: map[string][]string{"env": {"prod"}, "host": {"a", "b"}}}
+	same := ScreenshotOptions{Variables: map[string][]string{"host": {"a", "b"}, "env": {"prod"}}}
+	assert.Equal(t, o.Hash(), same.Hash())
+
+	other := ScreenshotOptions{Variables: map[string][]string{"env": {"dev"}, "host": {"a", "b"}}}
+	assert.NotEqual(t, o.Hash(), other.Hash())
+
+	// the variables and the user should change the sum
+	assert.NotEqual(t, ScreenshotOptions{}.Hash(), o.Hash())
+	assert.NotEqual(t, ScreenshotOptions{}.Hash(), ScreenshotOptions{UserID: 1}.Hash())
+}
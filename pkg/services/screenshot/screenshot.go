@@ -10,9 +10,9 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/drain"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,30 +51,43 @@ type HeadlessScreenshotService struct {
 	successes prometheus.Counter
 }
 
+// NewHeadlessScreenshotService returns a ScreenshotService. The services created with the same registerer share
+// their metrics.
 func NewHeadlessScreenshotService(ds dashboards.DashboardService, rs rendering.Service, r prometheus.Registerer) ScreenshotService {
 	return &HeadlessScreenshotService{
 		ds: ds,
 		rs: rs,
-		duration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+		duration: mustRegisterOrGet(r, prometheus.NewHistogram(prometheus.HistogramOpts{
 			Name:      "duration_seconds",
 			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 15},
 			Namespace: namespace,
 			Subsystem: subsystem,
-		}),
-		failures: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+		})).(prometheus.Histogram),
+		failures: mustRegisterOrGet(r, prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name:      "failures_total",
 			Namespace: namespace,
 			Subsystem: subsystem,
-		}, []string{"reason"}),
-		successes: promauto.With(r).NewCounter(prometheus.CounterOpts{
+		}, []string{"reason"})).(*prometheus.CounterVec),
+		successes: mustRegisterOrGet(r, prometheus.NewCounter(prometheus.CounterOpts{
 			Name:      "successes_total",
 			Namespace: namespace,
 			Subsystem: subsystem,
-		}),
+		})).(prometheus.Counter),
 	}
 }
 
-// Take returns a screenshot of the panel. It returns an error if either the panel,
+func mustRegisterOrGet(r prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := r.Register(c); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			return alreadyRegistered.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Take returns a screenshot of the panel, or of the dashboard when no panel is set. It returns an error if either the panel,
 // or the dashboard that contains the panel, does not exist, or the screenshot could not be
 // taken due to an error. It uses both the context and the timeout in ScreenshotOptions,
 // however the same context is used for both database queries and the request to the
@@ -95,21 +108,37 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 	opts = opts.SetDefaults()
 
 	u := url.URL{}
-	u.Path = path.Join("d-solo", dashboard.UID, dashboard.Slug)
 	p := u.Query()
 	p.Add("orgId", strconv.FormatInt(dashboard.OrgID, 10))
-	p.Add("panelId", strconv.FormatInt(opts.PanelID, 10))
+	if opts.PanelID > 0 {
+		u.Path = path.Join("d-solo", dashboard.UID, dashboard.Slug)
+		p.Add("panelId", strconv.FormatInt(opts.PanelID, 10))
+	} else {
+		u.Path = path.Join("d", dashboard.UID, dashboard.Slug)
+		p.Add("kiosk", "")
+	}
 	if opts.From != "" && opts.To != "" {
 		p.Add("from", opts.From)
 		p.Add("to", opts.To)
 	}
+	for name, values := range opts.Variables {
+		for _, value := range values {
+			p.Add("var-"+name, value)
+		}
+	}
 	u.RawQuery = p.Encode()
 
+	authOpts := rendering.AuthOpts{OrgID: dashboard.OrgID, UserID: opts.UserID, OrgRole: opts.OrgRole}
+	if authOpts.OrgRole == "" {
+		authOpts.UserID, authOpts.OrgRole = 0, org.RoleAdmin
+	}
+	class := opts.Class
+	if class == "" {
+		class = rendering.RenderClassAlertScreenshot
+	}
+
 	renderOpts := rendering.Opts{
-		AuthOpts: rendering.AuthOpts{
-			OrgID:   dashboard.OrgID,
-			OrgRole: org.RoleAdmin,
-		},
+		AuthOpts: authOpts,
 		ErrorOpts: rendering.ErrorOpts{
 			ErrorConcurrentLimitReached: true,
 			ErrorRenderUnavailable:      true,
@@ -121,10 +150,15 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 		Height:          opts.Height,
 		Theme:           opts.Theme,
 		ConcurrentLimit: setting.AlertingRenderLimit,
+		Class:           class,
 		Path:            u.String(),
 	}
 
 	result, err := s.rs.Render(ctx, renderOpts, nil)
+	if errors.Is(err, drain.ErrDraining) {
+		// Alerts are sent without screenshots while the server is draining.
+		return nil, ErrScreenshotsUnavailable
+	}
 	if err != nil {
 		s.instrumentError(err)
 		return nil, fmt.Errorf("failed to take screenshot: %w", err)
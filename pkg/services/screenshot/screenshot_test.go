@@ -54,6 +54,7 @@ func TestHeadlessScreenshotService(t *testing.T) {
 		Theme:           DefaultTheme,
 		Path:            "d-solo/foo/bar?from=now-6h&orgId=2&panelId=4&to=now-2h",
 		ConcurrentLimit: setting.AlertingRenderLimit,
+		Class:           rendering.RenderClassAlertScreenshot,
 	}
 
 	opts.From = "now-6h"
@@ -76,6 +77,55 @@ func TestHeadlessScreenshotService(t *testing.T) {
 	assert.Nil(t, screenshot)
 }
 
+func TestHeadlessScreenshotService_Dashboard(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	d := dashboards.FakeDashboardService{}
+	r := rendering.NewMockService(c)
+	registry := prometheus.NewRegistry()
+	s := NewHeadlessScreenshotService(&d, r, registry)
+	// the services created with the same registerer share their metrics
+	_ = NewHeadlessScreenshotService(&d, r, registry)
+
+	d.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(&dashboards.Dashboard{ID: 1, UID: "foo", Slug: "bar", OrgID: 2}, nil)
+
+	ctx := context.Background()
+	r.EXPECT().
+		Render(ctx, rendering.Opts{
+			AuthOpts: rendering.AuthOpts{
+				OrgID:   2,
+				UserID:  10,
+				OrgRole: org.RoleViewer,
+			},
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			TimeoutOpts: rendering.TimeoutOpts{
+				Timeout: DefaultTimeout,
+			},
+			Width:           DefaultWidth,
+			Height:          DefaultHeight,
+			Theme:           DefaultTheme,
+			Path:            "d/foo/bar?kiosk=&orgId=2&var-env=prod&var-host=a&var-host=b",
+			ConcurrentLimit: setting.AlertingRenderLimit,
+			Class:           rendering.RenderClassInteractive,
+		}, nil).
+		Return(&rendering.RenderResult{FilePath: "dashboard.png"}, nil)
+
+	screenshot, err := s.Take(ctx, ScreenshotOptions{
+		OrgID:        2,
+		DashboardUID: "foo",
+		Variables:    map[string][]string{"env": {"prod"}, "host": {"a", "b"}},
+		UserID:       10,
+		OrgRole:      org.RoleViewer,
+		Class:        rendering.RenderClassInteractive,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Screenshot{Path: "dashboard.png"}, *screenshot)
+}
+
 func TestNoOpScreenshotService(t *testing.T) {
 	s := NoOpScreenshotService{}
 	screenshot, err := s.Take(context.Background(), ScreenshotOptions{})
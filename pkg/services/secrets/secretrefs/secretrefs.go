@@ -0,0 +1,149 @@
+// Package secretrefs resolves the references to secrets of external secret
+// stores, such as ref+vault://secret/data/grafana#password, that can be set
+// instead of the secret values in the secure JSON data of data sources.
+package secretrefs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Prefix is the prefix of the values that are references to secrets of external secret stores.
+const Prefix = "ref+"
+
+var ErrUnsupportedStore = errors.New("unsupported secret store")
+
+// IsReference returns true if the value is a reference to a secret of an external secret store.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// store fetches secrets from an external secret store.
+type store interface {
+	Get(ctx context.Context, path, key string) (string, error)
+}
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+	// changed is the time the value was first fetched, or fetched with a different value.
+	changed time.Time
+}
+
+type Service struct {
+	enabled  bool
+	cacheTTL time.Duration
+	stores   map[string]store
+	log      log.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	section := cfg.SectionWithEnvOverrides("secret_references")
+	s := &Service{
+		enabled:  section.Key("enabled").MustBool(false),
+		cacheTTL: section.Key("cache_ttl").MustDuration(5 * time.Minute),
+		stores:   map[string]store{},
+		log:      log.New("secretrefs"),
+		cache:    map[string]cachedSecret{},
+		now:      time.Now,
+	}
+
+	if address := section.Key("vault_address").String(); address != "" {
+		s.stores["vault"] = newVaultStore(vaultConfig{
+			address:   address,
+			token:     section.Key("vault_token").String(),
+			namespace: section.Key("vault_namespace").String(),
+		})
+	}
+	return s
+}
+
+// Resolve returns the values with the references replaced by the secrets they
+// point to, and the last time one of these secrets changed. Secrets are cached
+// for the configured TTL, and the last fetched value of a secret is used if it
+// cannot be fetched anymore. Values are returned as they are if references are
+// disabled.
+func (s *Service) Resolve(ctx context.Context, values map[string]string) (map[string]string, time.Time, error) {
+	var changed time.Time
+	if !s.enabled {
+		return values, changed, nil
+	}
+
+	resolved := make(map[string]string, len(values))
+	for k, v := range values {
+		if !IsReference(v) {
+			resolved[k] = v
+			continue
+		}
+
+		secret, err := s.get(ctx, v)
+		if err != nil {
+			return nil, changed, fmt.Errorf("failed to resolve secret reference of %s: %w", k, err)
+		}
+		resolved[k] = secret.value
+		if secret.changed.After(changed) {
+			changed = secret.changed
+		}
+	}
+	return resolved, changed, nil
+}
+
+func (s *Service) get(ctx context.Context, ref string) (cachedSecret, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	cached, found := s.cache[ref]
+	s.mu.Unlock()
+	if found && now.Sub(cached.fetched) < s.cacheTTL {
+		return cached, nil
+	}
+
+	value, err := s.fetch(ctx, ref)
+	if err != nil {
+		if found {
+			s.log.Warn("Failed to fetch secret, using the last fetched value", "reference", ref, "error", err)
+			return cached, nil
+		}
+		return cachedSecret{}, err
+	}
+
+	secret := cachedSecret{value: value, fetched: now, changed: now}
+	if found && cached.value == value {
+		secret.changed = cached.changed
+	}
+
+	s.mu.Lock()
+	s.cache[ref] = secret
+	s.mu.Unlock()
+	return secret, nil
+}
+
+func (s *Service) fetch(ctx context.Context, ref string) (string, error) {
+	u, err := url.Parse(strings.TrimPrefix(ref, Prefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+
+	st, ok := s.stores[u.Scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedStore, u.Scheme)
+	}
+	if u.Fragment == "" {
+		return "", errors.New("invalid secret reference: missing key after #")
+	}
+
+	return st.Get(ctx, strings.Trim(u.Host+u.Path, "/"), u.Fragment)
+}
@@ -0,0 +1,146 @@
+package secretrefs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIsReference(t *testing.T) {
+	assert.True(t, IsReference("ref+vault://secret/data/grafana#password"))
+	assert.False(t, IsReference("password"))
+	assert.False(t, IsReference(""))
+}
+
+func TestService_Resolve(t *testing.T) {
+	password := atomic.Value{}
+	password.Store("first")
+	var fail atomic.Bool
+	var requests atomic.Int32
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if fail.Load() || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/secret/data/grafana":
+			_, _ = w.Write([]byte(`{"data": {"data": {"password": "` + password.Load().(string) + `"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/grafana":
+			_, _ = w.Write([]byte(`{"data": {"apiKey": "v1-key"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(vault.Close)
+
+	setup := func(t *testing.T) (*Service, *time.Time) {
+		t.Helper()
+		cfg := setting.NewCfg()
+		section := cfg.Raw.Section("secret_references")
+		section.Key("enabled").SetValue("true")
+		section.Key("cache_ttl").SetValue("1m")
+		section.Key("vault_address").SetValue(vault.URL)
+		section.Key("vault_token").SetValue("token")
+
+		s := ProvideService(cfg)
+		now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+		password.Store("first")
+		fail.Store(false)
+		requests.Store(0)
+		return s, &now
+	}
+
+	t.Run("resolves the references and keeps the other values", func(t *testing.T) {
+		s, now := setup(t)
+		resolved, changed, err := s.Resolve(context.Background(), map[string]string{
+			"password": "ref+vault://secret/data/grafana#password",
+			"apiKey":   "ref+vault://kv/grafana#apiKey",
+			"token":    "plain",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "first", "apiKey": "v1-key", "token": "plain"}, resolved)
+		assert.Equal(t, *now, changed)
+	})
+
+	t.Run("caches the secrets and reports when they change", func(t *testing.T) {
+		s, now := setup(t)
+		values := map[string]string{"password": "ref+vault://secret/data/grafana#password"}
+		start := *now
+
+		_, _, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+
+		password.Store("second")
+		*now = start.Add(30 * time.Second)
+		resolved, changed, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+		assert.Equal(t, "first", resolved["password"])
+		assert.Equal(t, start, changed)
+		assert.Equal(t, int32(1), requests.Load())
+
+		*now = start.Add(2 * time.Minute)
+		resolved, changed, err = s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+		assert.Equal(t, "second", resolved["password"])
+		assert.Equal(t, *now, changed)
+
+		*now = start.Add(4 * time.Minute)
+		_, unchanged, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+		assert.Equal(t, changed, unchanged)
+	})
+
+	t.Run("falls back to the last fetched value if the store fails", func(t *testing.T) {
+		s, now := setup(t)
+		values := map[string]string{"password": "ref+vault://secret/data/grafana#password"}
+
+		_, _, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+
+		fail.Store(true)
+		*now = now.Add(2 * time.Minute)
+		resolved, _, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+		assert.Equal(t, "first", resolved["password"])
+	})
+
+	t.Run("returns an error if a secret was never fetched", func(t *testing.T) {
+		s, _ := setup(t)
+		fail.Store(true)
+		_, _, err := s.Resolve(context.Background(), map[string]string{"password": "ref+vault://secret/data/grafana#password"})
+		require.ErrorContains(t, err, "status 403")
+
+		fail.Store(false)
+		_, _, err = s.Resolve(context.Background(), map[string]string{"password": "ref+vault://secret/data/grafana#missing"})
+		require.ErrorContains(t, err, `key "missing" not found`)
+
+		_, _, err = s.Resolve(context.Background(), map[string]string{"password": "ref+vault://secret/data/grafana"})
+		require.ErrorContains(t, err, "missing key")
+
+		_, _, err = s.Resolve(context.Background(), map[string]string{"password": "ref+awssecrets://grafana#password"})
+		require.ErrorIs(t, err, ErrUnsupportedStore)
+	})
+
+	t.Run("returns the values as they are when disabled", func(t *testing.T) {
+		s, _ := setup(t)
+		s.enabled = false
+		values := map[string]string{"password": "ref+vault://secret/data/grafana#password"}
+		resolved, changed, err := s.Resolve(context.Background(), values)
+		require.NoError(t, err)
+		assert.Equal(t, values, resolved)
+		assert.True(t, changed.IsZero())
+		assert.Zero(t, requests.Load())
+	})
+}
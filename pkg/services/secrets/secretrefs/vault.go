@@ -0,0 +1,84 @@
+package secretrefs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type vaultConfig struct {
+	address   string
+	token     string
+	namespace string
+}
+
+// vaultStore reads secrets from the KV secrets engines of Vault. References
+// are the API path of the secret followed by the key, such as
+// ref+vault://secret/data/grafana#password for the version 2 of the engine.
+type vaultStore struct {
+	cfg    vaultConfig
+	client *http.Client
+}
+
+func newVaultStore(cfg vaultConfig) *vaultStore {
+	return &vaultStore{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type vaultSecretResponse struct {
+	Data map[string]any `json:"data"`
+}
+
+func (v *vaultStore) Get(ctx context.Context, path, key string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(v.cfg.address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.token)
+	if v.cfg.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.namespace)
+	}
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", fmt.Errorf("vault returned status %d for %s: %s", res.StatusCode, path, msg)
+	}
+
+	var body vaultSecretResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	data := body.Data
+	// The version 2 of the KV secrets engine nests the secret with its metadata.
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in vault secret %s", key, path)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -63,6 +65,10 @@ func (api *ServiceAccountsAPI) RegisterAPIEndpoints() {
 		serviceAccountsRoute.Get("/:serviceAccountId/tokens", auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(api.ListTokens))
 		serviceAccountsRoute.Post("/:serviceAccountId/tokens", auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.CreateToken))
 		serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.DeleteToken))
+		serviceAccountsRoute.Get("/migrate/report", auth(accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(serviceaccounts.ActionCreate),
+			accesscontrol.EvalPermission(accesscontrol.ActionAPIKeyRead, accesscontrol.ScopeAPIKeysAll),
+		)), routing.Wrap(api.GetMigrationReport))
 		serviceAccountsRoute.Post("/migrate", auth(accesscontrol.EvalPermission(serviceaccounts.ActionCreate)), routing.Wrap(api.MigrateApiKeysToServiceAccounts))
 		serviceAccountsRoute.Post("/migrate/:keyId", auth(accesscontrol.EvalPermission(serviceaccounts.ActionCreate)), routing.Wrap(api.ConvertToServiceAccount))
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
@@ -324,6 +330,41 @@ func (api *ServiceAccountsAPI) MigrateApiKeysToServiceAccounts(ctx *contextmodel
 	return response.JSON(http.StatusOK, results)
 }
 
+// swagger:route GET /serviceaccounts/migrate/report service_accounts getMigrationReport
+//
+// # Get API key migration report
+//
+// Lists the API keys of the organization that have not been migrated to service accounts yet,
+// and flags the ones that were used recently so they can be migrated before API keys are disabled.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:create` scope: n/a
+// action: `apikeys:read` scope: `apikeys:*`
+//
+// Responses:
+// 200: getMigrationReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) GetMigrationReport(c *contextmodel.ReqContext) response.Response {
+	query := serviceaccounts.GetMigrationReportQuery{OrgID: c.SignedInUser.GetOrgID()}
+	if window := c.Query("inUseWindow"); window != "" {
+		d, err := gtime.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return response.Error(http.StatusBadRequest, "Invalid inUseWindow", err)
+		}
+		query.InUseWindow = d
+	}
+
+	report, err := api.service.GetMigrationReport(c.Req.Context(), &query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get API key migration report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
 // POST /api/serviceaccounts/migrate/:keyId
 func (api *ServiceAccountsAPI) ConvertToServiceAccount(ctx *contextmodel.ReqContext) response.Response {
 	keyId, err := strconv.ParseInt(web.Params(ctx.Req)[":keyId"], 10, 64)
@@ -400,6 +441,21 @@ type DeleteServiceAccountParams struct {
 	ServiceAccountId int64 `json:"serviceAccountId"`
 }
 
+// swagger:parameters getMigrationReport
+type GetMigrationReportParams struct {
+	// How recently an API key must have been used to be reported as in use, for example 7d or 12h.
+	// in:query
+	// required:false
+	// default:30d
+	InUseWindow string `json:"inUseWindow"`
+}
+
+// swagger:response getMigrationReportResponse
+type GetMigrationReportResponse struct {
+	// in:body
+	Body *serviceaccounts.MigrationReport
+}
+
 // swagger:response searchOrgServiceAccountsWithPagingResponse
 type SearchOrgServiceAccountsWithPagingResponse struct {
 	// in:body
@@ -297,6 +297,67 @@ func TestServiceAccountsAPI_MigrateApiKeysToServiceAccounts(t *testing.T) {
 	}
 }
 
+func TestServiceAccountsAPI_GetMigrationReport(t *testing.T) {
+	type TestCase struct {
+		desc         string
+		query        string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}
+
+	tests := []TestCase{
+		{
+			desc:  "should be able to get the migration report with correct permissions",
+			query: "?inUseWindow=7d",
+			permissions: []accesscontrol.Permission{
+				{Action: serviceaccounts.ActionCreate},
+				{Action: accesscontrol.ActionAPIKeyRead, Scope: accesscontrol.ScopeAPIKeysAll},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:  "should reject an invalid in use window",
+			query: "?inUseWindow=soon",
+			permissions: []accesscontrol.Permission{
+				{Action: serviceaccounts.ActionCreate},
+				{Action: accesscontrol.ActionAPIKeyRead, Scope: accesscontrol.ScopeAPIKeysAll},
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not be able to get the migration report without api key read permission",
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionCreate}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			expected := &serviceaccounts.MigrationReport{
+				Total: 1,
+				InUse: 1,
+				Keys:  []*serviceaccounts.APIKeyMigration{{ID: 1, Name: "key", Role: org.RoleViewer, InUse: true, ServiceAccountLogin: "sa-autogen-1-key"}},
+			}
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.service = &satests.FakeServiceAccountService{ExpectedMigrationReport: expected}
+			})
+
+			req := server.NewGetRequest("/api/serviceaccounts/migrate/report" + tt.query)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgRole: org.RoleAdmin, OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tt.permissions)}})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			if tt.expectedCode == http.StatusOK {
+				var result serviceaccounts.MigrationReport
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+				assert.Equal(t, expected, &result)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
 func setupTests(t *testing.T, opts ...func(a *ServiceAccountsAPI)) *webtest.Server {
 	t.Helper()
 	cfg := setting.NewCfg()
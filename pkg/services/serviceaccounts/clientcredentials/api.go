@@ -0,0 +1,66 @@
+package clientcredentials
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister) {
+	router.Group("/oauth2", func(oauthRouter routing.RouteRegister) {
+		oauthRouter.Post("/token", s.handleTokenRequest)
+	})
+}
+
+// handleTokenRequest is the token endpoint of RFC 6749 section 3.2, limited to the client credentials
+// grant. The client authenticates with HTTP Basic authentication or with the client_id and
+// client_secret form parameters.
+func (s *Service) handleTokenRequest(c *contextmodel.ReqContext) {
+	c.Resp.Header().Set("Cache-Control", "no-store")
+	c.Resp.Header().Set("Pragma", "no-cache")
+
+	if err := c.Req.ParseForm(); err != nil {
+		s.writeError(c, &oauthError{Code: errInvalidRequest.Code, Status: http.StatusBadRequest, Description: "Invalid form body"})
+		return
+	}
+	if grantType := c.Req.PostForm.Get("grant_type"); grantType != GrantType {
+		s.writeError(c, errUnsupportedGrantType)
+		return
+	}
+
+	clientID, clientSecret, ok := c.Req.BasicAuth()
+	if ok {
+		// The credentials are form-urlencoded before being encoded with Base64, see RFC 6749 section 2.3.1.
+		var errID, errSecret error
+		clientID, errID = url.QueryUnescape(clientID)
+		clientSecret, errSecret = url.QueryUnescape(clientSecret)
+		if errID != nil || errSecret != nil {
+			s.writeError(c, errInvalidClient)
+			return
+		}
+	} else {
+		clientID, clientSecret = c.Req.PostForm.Get("client_id"), c.Req.PostForm.Get("client_secret")
+	}
+
+	resp, err := s.IssueToken(c.Req.Context(), clientID, clientSecret)
+	if err != nil {
+		s.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) writeError(c *contextmodel.ReqContext, err error) {
+	var oauthErr *oauthError
+	if !errors.As(err, &oauthErr) {
+		s.log.FromContext(c.Req.Context()).Error("Failed to issue service account access token", "error", err)
+		oauthErr = &oauthError{Code: "server_error", Status: http.StatusInternalServerError, Description: "Failed to issue the access token"}
+	}
+	if oauthErr.Status == http.StatusUnauthorized {
+		c.Resp.Header().Set("WWW-Authenticate", `Basic realm="grafana"`)
+	}
+	c.JSON(oauthErr.Status, oauthErr)
+}
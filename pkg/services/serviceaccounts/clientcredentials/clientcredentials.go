@@ -0,0 +1,299 @@
+// Package clientcredentials makes Grafana a minimal OAuth2 token issuer for service accounts. A service
+// account exchanges one of its tokens for a short-lived JWT with the client credentials grant, so that
+// external systems only send their long-lived secret to the token endpoint.
+package clientcredentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/signingkeys"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// GrantType is the only grant type supported by the token endpoint.
+	GrantType = "client_credentials"
+
+	keyPrefix   = "sa-token"
+	headerKeyID = "kid"
+)
+
+var (
+	errInvalidClient        = &oauthError{Code: "invalid_client", Status: http.StatusUnauthorized, Description: "Invalid client credentials"}
+	errInvalidRequest       = &oauthError{Code: "invalid_request", Status: http.StatusBadRequest, Description: "The client_id and client_secret are required"}
+	errUnsupportedGrantType = &oauthError{Code: "unsupported_grant_type", Status: http.StatusBadRequest, Description: "Only the client_credentials grant type is supported"}
+)
+
+// oauthError is an error of the token endpoint, as defined by RFC 6749 section 5.2.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	Status      int    `json:"-"`
+}
+
+func (e *oauthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// Claims are the claims of the access tokens.
+type Claims struct {
+	jwt.Claims
+	OrgID    int64  `json:"org_id"`
+	ClientID string `json:"client_id"`
+	// TokenID is the ID of the service account token exchanged for the access token.
+	TokenID int64 `json:"token_id"`
+}
+
+// TokenResponse is the successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+type Service struct {
+	cfg           *setting.Cfg
+	log           log.Logger
+	apiKeyService apikey.Service
+	userService   user.Service
+	signingKeys   signingkeys.Service
+
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, routeRegister routing.RouteRegister, apiKeyService apikey.Service,
+	userService user.Service, signingKeys signingkeys.Service, features *featuremgmt.FeatureManager) *Service {
+	s := &Service{
+		cfg:           cfg,
+		log:           log.New("serviceaccounts.clientcredentials"),
+		apiKeyService: apiKeyService,
+		userService:   userService,
+		signingKeys:   signingKeys,
+		now:           time.Now,
+	}
+
+	if !cfg.SAClientCredentialsEnabled {
+		return s
+	}
+	// The OAuth2 server of the external services serves the same endpoint.
+	if features.IsEnabledGlobally(featuremgmt.FlagExternalServiceAuth) {
+		s.log.Warn("The client credentials grant of service accounts is not available when the external service auth is enabled")
+		return s
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// IsEnabled returns true if the service accounts can request access tokens.
+func (s *Service) IsEnabled() bool {
+	return s.cfg.SAClientCredentialsEnabled
+}
+
+// IssueToken exchanges the client credentials of a service account, its login and one of its tokens,
+// for an access token.
+func (s *Service) IssueToken(ctx context.Context, clientID, clientSecret string) (*TokenResponse, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, errInvalidRequest
+	}
+
+	key, usr, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	lifetime := s.cfg.SAClientCredentialsTokenLifetime
+	claims := Claims{
+		Claims: jwt.Claims{
+			ID:        uuid.NewString(),
+			Issuer:    s.cfg.AppURL,
+			Subject:   authn.NamespacedID(authn.NamespaceServiceAccount, usr.UserID),
+			Audience:  jwt.Audience{s.cfg.AppURL},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(lifetime)),
+		},
+		OrgID:    usr.OrgID,
+		ClientID: clientID,
+		TokenID:  key.ID,
+	}
+
+	signer, err := s.getSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Debug("Issued service account access token", "serviceAccountId", usr.UserID, "orgId", usr.OrgID, "jti", claims.ID)
+	return &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifetime.Seconds()),
+	}, nil
+}
+
+// authenticateClient returns the client secret, a service account token, and its service account if its
+// login is the client ID.
+func (s *Service) authenticateClient(ctx context.Context, clientID, clientSecret string) (*apikey.APIKey, *user.SignedInUser, error) {
+	if !strings.HasPrefix(clientSecret, satokengen.GrafanaPrefix) {
+		return nil, nil, errInvalidClient
+	}
+	decoded, err := satokengen.Decode(clientSecret)
+	if err != nil {
+		return nil, nil, errInvalidClient
+	}
+	hash, err := decoded.Hash()
+	if err != nil {
+		return nil, nil, errInvalidClient
+	}
+
+	key, err := s.apiKeyService.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) || errors.Is(err, apikey.ErrInvalid) {
+			return nil, nil, errInvalidClient
+		}
+		return nil, nil, err
+	}
+	if !s.isValidKey(key) {
+		return nil, nil, errInvalidClient
+	}
+
+	usr, err := s.userService.GetSignedInUserWithCacheCtx(ctx, &user.GetSignedInUserQuery{UserID: *key.ServiceAccountId, OrgID: key.OrgID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, nil, errInvalidClient
+		}
+		return nil, nil, err
+	}
+	if !usr.IsServiceAccount || usr.IsDisabled || usr.Login != clientID {
+		return nil, nil, errInvalidClient
+	}
+	return key, usr, nil
+}
+
+// isValidKey returns true if the key is a service account token that is neither expired nor revoked.
+func (s *Service) isValidKey(key *apikey.APIKey) bool {
+	if key.ServiceAccountId == nil || *key.ServiceAccountId < 1 {
+		return false
+	}
+	if key.Expires != nil && *key.Expires <= s.now().Unix() {
+		return false
+	}
+	return key.IsRevoked == nil || !*key.IsRevoked
+}
+
+// IsServiceAccountToken returns true if the token looks like an access token issued by the service. The
+// signature is not verified.
+func (s *Service) IsServiceAccountToken(rawToken string) bool {
+	if !s.IsEnabled() || rawToken == "" {
+		return false
+	}
+	parsed, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return false
+	}
+	var claims jwt.Claims
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return false
+	}
+	namespace, _, _ := strings.Cut(claims.Subject, ":")
+	return claims.Issuer == s.cfg.AppURL && namespace == authn.NamespaceServiceAccount
+}
+
+// VerifyServiceAccountToken verifies an access token issued by the service and returns the identity of its service
+// account.
+func (s *Service) VerifyServiceAccountToken(ctx context.Context, rawToken string) (*authn.Identity, error) {
+	claims, err := s.verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, identifier, _ := strings.Cut(claims.Subject, ":")
+	id, err := strconv.ParseInt(identifier, 10, 64)
+	if err != nil || namespace != authn.NamespaceServiceAccount {
+		return nil, fmt.Errorf("invalid subject %q", claims.Subject)
+	}
+
+	// The access token is only valid as long as the service account token it was exchanged for.
+	key, err := s.apiKeyService.GetApiKeyById(ctx, &apikey.GetByIDQuery{ApiKeyID: claims.TokenID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the service account token: %w", err)
+	}
+	if !s.isValidKey(key) || *key.ServiceAccountId != id || key.OrgID != claims.OrgID {
+		return nil, errors.New("service account token is no longer valid")
+	}
+
+	usr, err := s.userService.GetSignedInUserWithCacheCtx(ctx, &user.GetSignedInUserQuery{UserID: id, OrgID: claims.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	if !usr.IsServiceAccount || usr.IsDisabled {
+		return nil, errors.New("service account is disabled")
+	}
+
+	return authn.IdentityFromSignedInUser(claims.Subject, usr, authn.ClientParams{SyncPermissions: true}, login.JWTModule), nil
+}
+
+func (s *Service) verify(ctx context.Context, rawToken string) (*Claims, error) {
+	parsed, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT: %w", err)
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(jose.ES256) {
+		return nil, errors.New("invalid JWT header")
+	}
+
+	_, key, err := s.signingKeys.GetOrCreatePrivateKey(ctx, keyPrefix, jose.ES256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	var claims Claims
+	if err := parsed.Claims(key.Public(), &claims); err != nil {
+		return nil, fmt.Errorf("failed to verify the signature: %w", err)
+	}
+	if claims.Expiry == nil {
+		return nil, errors.New("missing 'exp' claim")
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   s.cfg.AppURL,
+		Audience: jwt.Audience{s.cfg.AppURL},
+		Time:     s.now(),
+	}, 0); err != nil {
+		return nil, fmt.Errorf("failed to validate JWT: %w", err)
+	}
+	return &claims, nil
+}
+
+func (s *Service) getSigner(ctx context.Context) (jose.Signer, error) {
+	id, key, err := s.signingKeys.GetOrCreatePrivateKey(ctx, keyPrefix, jose.ES256)
+	if err != nil {
+		return nil, err
+	}
+
+	return jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{headerKeyID: id},
+	}).WithType("JWT"))
+}
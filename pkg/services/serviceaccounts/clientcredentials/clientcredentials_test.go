@@ -0,0 +1,213 @@
+package clientcredentials
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/apikey/apikeytest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/signingkeys/signingkeystest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const appURL = "https://grafana.example.com/"
+
+type testEnv struct {
+	service *Service
+	secret  string
+	apiKeys *apikeytest.Service
+	users   *usertest.FakeUserService
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token, err := satokengen.New("sa")
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = appURL
+	cfg.SAClientCredentialsEnabled = true
+	cfg.SAClientCredentialsTokenLifetime = 10 * time.Minute
+
+	saID := int64(2)
+	env := &testEnv{
+		secret: token.ClientSecret,
+		apiKeys: &apikeytest.Service{ExpectedAPIKey: &apikey.APIKey{
+			ID: 1, OrgID: 1, Key: token.HashedKey, ServiceAccountId: &saID,
+		}},
+		users: &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{
+			UserID: saID, OrgID: 1, Login: "sa-ci", IsServiceAccount: true,
+		}},
+	}
+	env.service = ProvideService(cfg, routing.NewRouteRegister(), env.apiKeys, env.users,
+		&signingkeystest.FakeSigningKeysService{ExpectedKeyID: "default", ExpectedSinger: key}, featuremgmt.WithFeatures())
+	return env
+}
+
+func TestService_IssueToken(t *testing.T) {
+	t.Run("issues a token accepted by the verifier", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		resp, err := env.service.IssueToken(context.Background(), "sa-ci", env.secret)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.Equal(t, int64(600), resp.ExpiresIn)
+		assert.True(t, env.service.IsServiceAccountToken(resp.AccessToken))
+
+		id, err := env.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, authn.NamespacedID(authn.NamespaceServiceAccount, 2), id.ID)
+		assert.Equal(t, int64(1), id.OrgID)
+		assert.Equal(t, "sa-ci", id.Login)
+	})
+
+	t.Run("rejects invalid client credentials", func(t *testing.T) {
+		revoked := true
+		expired := time.Now().Add(-time.Hour).Unix()
+		tests := []struct {
+			desc     string
+			clientID string
+			secret   func(env *testEnv) string
+			setup    func(env *testEnv)
+		}{
+			{desc: "secret is not a service account token", clientID: "sa-ci", secret: func(*testEnv) string { return "not-a-token" }},
+			{desc: "client id is not the service account login", clientID: "other", secret: func(env *testEnv) string { return env.secret }},
+			{desc: "token is unknown", clientID: "sa-ci", secret: func(env *testEnv) string { return env.secret },
+				setup: func(env *testEnv) { env.apiKeys.ExpectedAPIKey, env.apiKeys.ExpectedError = nil, apikey.ErrNotFound }},
+			{desc: "token is revoked", clientID: "sa-ci", secret: func(env *testEnv) string { return env.secret },
+				setup: func(env *testEnv) { env.apiKeys.ExpectedAPIKey.IsRevoked = &revoked }},
+			{desc: "token is expired", clientID: "sa-ci", secret: func(env *testEnv) string { return env.secret },
+				setup: func(env *testEnv) { env.apiKeys.ExpectedAPIKey.Expires = &expired }},
+			{desc: "service account is disabled", clientID: "sa-ci", secret: func(env *testEnv) string { return env.secret },
+				setup: func(env *testEnv) { env.users.ExpectedSignedInUser.IsDisabled = true }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				env := setupTestEnv(t)
+				if tt.setup != nil {
+					tt.setup(env)
+				}
+				_, err := env.service.IssueToken(context.Background(), tt.clientID, tt.secret(env))
+				assert.ErrorIs(t, err, errInvalidClient)
+			})
+		}
+	})
+}
+
+func TestService_VerifyServiceAccountToken(t *testing.T) {
+	env := setupTestEnv(t)
+	resp, err := env.service.IssueToken(context.Background(), "sa-ci", env.secret)
+	require.NoError(t, err)
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		env.service.now = func() time.Time { return time.Now().Add(time.Hour) }
+		t.Cleanup(func() { env.service.now = time.Now })
+
+		_, err := env.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens of disabled service accounts", func(t *testing.T) {
+		env.users.ExpectedSignedInUser.IsDisabled = true
+		t.Cleanup(func() { env.users.ExpectedSignedInUser.IsDisabled = false })
+
+		_, err := env.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens of deleted service account tokens", func(t *testing.T) {
+		env.apiKeys.ExpectedError = apikey.ErrInvalid
+		t.Cleanup(func() { env.apiKeys.ExpectedError = nil })
+
+		_, err := env.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens of revoked service account tokens", func(t *testing.T) {
+		revoked := true
+		env.apiKeys.ExpectedAPIKey.IsRevoked = &revoked
+		t.Cleanup(func() { env.apiKeys.ExpectedAPIKey.IsRevoked = nil })
+
+		_, err := env.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens signed with another key", func(t *testing.T) {
+		other := setupTestEnv(t)
+		_, err := other.service.VerifyServiceAccountToken(context.Background(), resp.AccessToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("ignores tokens of other issuers", func(t *testing.T) {
+		env.service.cfg.AppURL = "https://other.example.com/"
+		t.Cleanup(func() { env.service.cfg.AppURL = appURL })
+
+		assert.False(t, env.service.IsServiceAccountToken(resp.AccessToken))
+	})
+}
+
+func TestService_handleTokenRequest(t *testing.T) {
+	env := setupTestEnv(t)
+
+	send := func(t *testing.T, form url.Values, basicAuth bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basicAuth {
+			req.SetBasicAuth("sa-ci", env.secret)
+		}
+		rec := httptest.NewRecorder()
+		env.service.handleTokenRequest(&contextmodel.ReqContext{Context: &web.Context{Req: req, Resp: web.NewResponseWriter(req.Method, rec)}})
+		return rec
+	}
+
+	t.Run("authenticates the client with the form parameters", func(t *testing.T) {
+		rec := send(t, url.Values{"grant_type": {GrantType}, "client_id": {"sa-ci"}, "client_secret": {env.secret}}, false)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		var resp TokenResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.True(t, env.service.IsServiceAccountToken(resp.AccessToken))
+	})
+
+	t.Run("authenticates the client with HTTP Basic authentication", func(t *testing.T) {
+		rec := send(t, url.Values{"grant_type": {GrantType}}, true)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("rejects other grant types", func(t *testing.T) {
+		rec := send(t, url.Values{"grant_type": {"password"}}, true)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error": "unsupported_grant_type", "error_description": "Only the client_credentials grant type is supported"}`, rec.Body.String())
+	})
+
+	t.Run("rejects invalid clients", func(t *testing.T) {
+		rec := send(t, url.Values{"grant_type": {GrantType}, "client_id": {"other"}, "client_secret": {env.secret}}, false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"error": "invalid_client", "error_description": "Invalid client credentials"}`, rec.Body.String())
+	})
+}
@@ -432,11 +432,58 @@ func (s *ServiceAccountsStoreImpl) MigrateApiKey(ctx context.Context, orgId int6
 	return nil
 }
 
+// GetMigrationReport lists the API keys of an organization that are not yet
+// service account tokens, and flags the ones used within the requested window.
+func (s *ServiceAccountsStoreImpl) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	basicKeys, err := s.apiKeyService.GetAllAPIKeys(ctx, query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &serviceaccounts.MigrationReport{
+		Total: len(basicKeys),
+		Keys:  make([]*serviceaccounts.APIKeyMigration, 0, len(basicKeys)),
+	}
+	for _, key := range basicKeys {
+		item := &serviceaccounts.APIKeyMigration{
+			ID:                  key.ID,
+			Name:                key.Name,
+			Role:                key.Role,
+			Created:             key.Created,
+			LastUsedAt:          key.LastUsedAt,
+			ServiceAccountLogin: autogenServiceAccountLogin(key),
+		}
+		if key.Expires != nil {
+			expiration := time.Unix(*key.Expires, 0)
+			item.Expiration = &expiration
+			item.IsExpired = expiration.Before(now)
+		}
+		item.InUse = !item.IsExpired && key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) <= query.InUseWindow
+
+		switch {
+		case item.IsExpired:
+			report.Expired++
+		case item.InUse:
+			report.InUse++
+		default:
+			report.Unused++
+		}
+		report.Keys = append(report.Keys, item)
+	}
+	return report, nil
+}
+
+const autogenPrefix = "sa-autogen"
+
+func autogenServiceAccountLogin(key *apikey.APIKey) string {
+	return fmt.Sprintf("%v-%v-%v", autogenPrefix, key.OrgID, key.Name)
+}
+
 func (s *ServiceAccountsStoreImpl) CreateServiceAccountFromApikey(ctx context.Context, key *apikey.APIKey) error {
-	prefix := "sa-autogen"
 	cmd := user.CreateUserCommand{
-		Login:            fmt.Sprintf("%v-%v-%v", prefix, key.OrgID, key.Name),
-		Name:             fmt.Sprintf("%v-%v", prefix, key.Name),
+		Login:            autogenServiceAccountLogin(key),
+		Name:             fmt.Sprintf("%v-%v", autogenPrefix, key.Name),
 		OrgID:            key.OrgID,
 		DefaultOrgRole:   string(key.Role),
 		IsServiceAccount: true,
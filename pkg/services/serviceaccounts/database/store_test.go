@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -369,6 +370,47 @@ func TestStore_MigrateAllApiKeys(t *testing.T) {
 		})
 	}
 }
+func TestStore_GetMigrationReport(t *testing.T) {
+	db, store := setupTestDatabase(t)
+	ctx := context.Background()
+
+	used := tests.SetupApiKey(t, db, tests.TestApiKey{Name: "used", Role: org.RoleEditor, Key: "secret1", OrgId: 1})
+	unused := tests.SetupApiKey(t, db, tests.TestApiKey{Name: "unused", Role: org.RoleViewer, Key: "secret2", OrgId: 1})
+	expired := tests.SetupApiKey(t, db, tests.TestApiKey{Name: "expired", Key: "secret3", OrgId: 1, IsExpired: true})
+	tests.SetupApiKey(t, db, tests.TestApiKey{Name: "other-org", Key: "secret4", OrgId: 2})
+	require.NoError(t, store.apiKeyService.UpdateAPIKeyLastUsedDate(ctx, used.ID))
+	require.NoError(t, store.apiKeyService.UpdateAPIKeyLastUsedDate(ctx, expired.ID))
+
+	report, err := store.GetMigrationReport(ctx, &serviceaccounts.GetMigrationReportQuery{OrgID: 1, InUseWindow: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Total)
+	require.Equal(t, 1, report.InUse)
+	require.Equal(t, 1, report.Unused)
+	require.Equal(t, 1, report.Expired)
+
+	byID := map[int64]*serviceaccounts.APIKeyMigration{}
+	for _, k := range report.Keys {
+		byID[k.ID] = k
+	}
+	require.True(t, byID[used.ID].InUse)
+	require.NotNil(t, byID[used.ID].LastUsedAt)
+	require.Equal(t, "sa-autogen-1-used", byID[used.ID].ServiceAccountLogin)
+	require.False(t, byID[unused.ID].InUse)
+	require.True(t, byID[expired.ID].IsExpired)
+	require.False(t, byID[expired.ID].InUse)
+
+	t.Run("migrated keys are no longer reported", func(t *testing.T) {
+		_, err := store.orgService.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "main"})
+		require.NoError(t, err)
+		require.NoError(t, store.MigrateApiKey(ctx, 1, used.ID))
+
+		report, err := store.GetMigrationReport(ctx, &serviceaccounts.GetMigrationReportQuery{OrgID: 1, InUseWindow: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Total)
+		require.Equal(t, 0, report.InUse)
+	})
+}
+
 func TestServiceAccountsStoreImpl_SearchOrgServiceAccounts(t *testing.T) {
 	initUsers := []tests.TestUser{
 		{Name: "satest-1", Role: string(org.RoleViewer), Login: "sa-satest-1", IsServiceAccount: true},
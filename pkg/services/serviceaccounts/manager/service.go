@@ -245,6 +245,16 @@ func (sa *ServiceAccountsService) MigrateApiKeysToServiceAccounts(ctx context.Co
 	return sa.store.MigrateApiKeysToServiceAccounts(ctx, orgID)
 }
 
+func (sa *ServiceAccountsService) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	if err := validOrgID(query.OrgID); err != nil {
+		return nil, err
+	}
+	if query.InUseWindow <= 0 {
+		query.InUseWindow = serviceaccounts.DefaultMigrationInUseWindow
+	}
+	return sa.store.GetMigrationReport(ctx, query)
+}
+
 func validOrgID(orgID int64) error {
 	if orgID == 0 {
 		return serviceaccounts.ErrServiceAccountInvalidOrgID.Errorf("invalid org ID 0 has been specified")
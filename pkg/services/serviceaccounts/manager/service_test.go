@@ -72,6 +72,11 @@ func (f *FakeServiceAccountStore) MigrateApiKeysToServiceAccounts(ctx context.Co
 	return f.expectedMigratedResults, f.ExpectedError
 }
 
+// GetMigrationReport is a fake reporting api keys left to migrate.
+func (f *FakeServiceAccountStore) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	return &serviceaccounts.MigrationReport{}, f.ExpectedError
+}
+
 // MigrateApiKey is a fake migrating an api key to a service account.
 func (f *FakeServiceAccountStore) MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error {
 	return f.ExpectedError
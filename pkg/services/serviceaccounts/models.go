@@ -51,6 +51,46 @@ type MigrationResult struct {
 	FailedDetails   []string `json:"failedDetails"`
 }
 
+// DefaultMigrationInUseWindow is how recently an API key must have been used
+// to be reported as still in use when no window is requested.
+const DefaultMigrationInUseWindow = 30 * 24 * time.Hour
+
+type GetMigrationReportQuery struct {
+	OrgID int64
+	// InUseWindow is how recently a key must have been used to be considered in use.
+	InUseWindow time.Duration
+}
+
+// MigrationReport summarizes the legacy API keys of an organization that have
+// not been migrated to service accounts yet.
+// swagger:model
+type MigrationReport struct {
+	Total   int `json:"total"`
+	InUse   int `json:"inUse"`
+	Unused  int `json:"unused"`
+	Expired int `json:"expired"`
+
+	Keys []*APIKeyMigration `json:"keys"`
+}
+
+// swagger:model
+type APIKeyMigration struct {
+	// example: 3
+	ID int64 `json:"id"`
+	// example: grafana
+	Name string `json:"name"`
+	// example: Viewer
+	Role       org.RoleType `json:"role"`
+	Created    time.Time    `json:"created"`
+	LastUsedAt *time.Time   `json:"lastUsedAt,omitempty"`
+	Expiration *time.Time   `json:"expiration,omitempty"`
+	IsExpired  bool         `json:"isExpired"`
+	InUse      bool         `json:"inUse"`
+	// Login of the service account the key is migrated to.
+	// example: sa-autogen-1-grafana
+	ServiceAccountLogin string `json:"serviceAccountLogin"`
+}
+
 type ServiceAccount struct {
 	Id int64
 }
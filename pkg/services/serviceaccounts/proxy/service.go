@@ -131,6 +131,10 @@ func (s *ServiceAccountsProxy) MigrateApiKeysToServiceAccounts(ctx context.Conte
 	return s.proxiedService.MigrateApiKeysToServiceAccounts(ctx, orgID)
 }
 
+func (s *ServiceAccountsProxy) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	return s.proxiedService.GetMigrationReport(ctx, query)
+}
+
 func (s *ServiceAccountsProxy) RetrieveServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*serviceaccounts.ServiceAccountProfileDTO, error) {
 	sa, err := s.proxiedService.RetrieveServiceAccount(ctx, orgID, serviceAccountID)
 	if err != nil {
@@ -33,6 +33,7 @@ type Service interface {
 	// API specific functions
 	MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error
 	MigrateApiKeysToServiceAccounts(ctx context.Context, orgID int64) (*MigrationResult, error)
+	GetMigrationReport(ctx context.Context, query *GetMigrationReportQuery) (*MigrationReport, error)
 }
 
 //go:generate mockery --name ExtSvcAccountsService --structname MockExtSvcAccountsService --output tests --outpkg tests --filename extsvcaccmock.go
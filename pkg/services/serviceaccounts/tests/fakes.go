@@ -11,6 +11,7 @@ import (
 type FakeServiceAccountService struct {
 	ExpectedAPIKey                         *apikey.APIKey
 	ExpectedErr                            error
+	ExpectedMigrationReport                *serviceaccounts.MigrationReport
 	ExpectedMigrationResult                *serviceaccounts.MigrationResult
 	ExpectedSearchOrgServiceAccountsResult *serviceaccounts.SearchOrgServiceAccountsResult
 	ExpectedServiceAccount                 *serviceaccounts.ServiceAccountDTO
@@ -62,6 +63,10 @@ func (f *FakeServiceAccountService) MigrateApiKeysToServiceAccounts(ctx context.
 	return f.ExpectedMigrationResult, f.ExpectedErr
 }
 
+func (f *FakeServiceAccountService) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	return f.ExpectedMigrationReport, f.ExpectedErr
+}
+
 func (f *FakeServiceAccountService) SearchOrgServiceAccounts(ctx context.Context, query *serviceaccounts.SearchOrgServiceAccountsQuery) (*serviceaccounts.SearchOrgServiceAccountsResult, error) {
 	return f.ExpectedSearchOrgServiceAccountsResult, f.ExpectedErr
 }
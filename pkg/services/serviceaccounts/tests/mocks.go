@@ -111,6 +111,32 @@ func (_m *MockServiceAccountService) EnableServiceAccount(ctx context.Context, o
 	return r0
 }
 
+// GetMigrationReport provides a mock function with given fields: ctx, query
+func (_m *MockServiceAccountService) GetMigrationReport(ctx context.Context, query *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *serviceaccounts.MigrationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *serviceaccounts.GetMigrationReportQuery) (*serviceaccounts.MigrationReport, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *serviceaccounts.GetMigrationReportQuery) *serviceaccounts.MigrationReport); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*serviceaccounts.MigrationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *serviceaccounts.GetMigrationReportQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListTokens provides a mock function with given fields: ctx, query
 func (_m *MockServiceAccountService) ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error) {
 	ret := _m.Called(ctx, query)
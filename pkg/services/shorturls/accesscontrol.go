@@ -0,0 +1,6 @@
+package shorturls
+
+const (
+	// ActionSlugsCreate allows creating short URLs with a slug chosen by the caller.
+	ActionSlugsCreate = "shorturls.slugs:create"
+)
@@ -9,8 +9,12 @@ import (
 var (
 	ErrShortURLBadRequest   = errutil.BadRequest("shorturl.bad-request")
 	ErrShortURLNotFound     = errutil.NotFound("shorturl.not-found")
+	ErrShortURLExpired      = errutil.NotFound("shorturl.expired", errutil.WithPublicMessage("Short URL has expired"))
 	ErrShortURLAbsolutePath = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
 	ErrShortURLInvalidPath  = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
+	ErrShortURLInvalidUID   = errutil.ValidationFailed("shorturl.invalid-uid", errutil.WithPublicMessage("Invalid short URL slug"))
+	ErrShortURLInvalidTTL   = errutil.ValidationFailed("shorturl.invalid-ttl", errutil.WithPublicMessage("Short URL expiry should be a positive duration"))
+	ErrShortURLUIDExists    = errutil.Conflict("shorturl.uid-exists", errutil.WithPublicMessage("A short URL with this slug already exists"))
 	ErrShortURLInternal     = errutil.Internal("shorturl.internal")
 )
 
@@ -22,6 +26,23 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix timestamp the short URL expires at, or 0 if it never expires.
+	ExpiresAt int64
+	// HitCount is the number of times the short URL was visited.
+	HitCount int64
+}
+
+// IsExpired returns true if the short URL has an expiry that is not after t.
+func (s *ShortUrl) IsExpired(t time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= t.Unix()
+}
+
+type CreateShortURLCommand struct {
+	Path string
+	// UID is the slug chosen by the caller. A random one is generated when empty.
+	UID string
+	// TTL is how long the short URL is valid for. It never expires when zero.
+	TTL time.Duration
 }
 
 type DeleteShortUrlCommand struct {
@@ -29,3 +50,7 @@ type DeleteShortUrlCommand struct {
 
 	NumDeleted int64
 }
+
+type DeleteExpiredShortURLsCommand struct {
+	NumDeleted int64
+}
@@ -8,7 +8,9 @@ import (
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *CreateShortURLCommand) (*ShortUrl, error)
+	// UpdateLastSeenAt records a visit of the short URL, updating when it was last seen and its hit count.
 	UpdateLastSeenAt(ctx context.Context, shortURL *ShortUrl) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *DeleteShortUrlCommand) error
+	DeleteExpiredShortURLs(ctx context.Context, cmd *DeleteExpiredShortURLsCommand) error
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/teris-io/shortid"
 )
 
@@ -27,15 +28,22 @@ func ProvideService(db db.DB) *ShortURLService {
 }
 
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
-	return s.SQLStore.Get(ctx, user, uid)
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	if shortURL.IsExpired(getTime()) {
+		return nil, shorturls.ErrShortURLExpired.Errorf("short URL %s has expired", uid)
+	}
+	return shortURL, nil
 }
 
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.SQLStore.Update(ctx, shortURL)
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, relPath string) (*shorturls.ShortUrl, error) {
-	relPath = strings.TrimSpace(relPath)
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+	relPath := strings.TrimSpace(cmd.Path)
 
 	if path.IsAbs(relPath) {
 		return nil, shorturls.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
@@ -43,19 +51,39 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 	if strings.Contains(relPath, "../") {
 		return nil, shorturls.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
 	}
+	if cmd.TTL < 0 {
+		return nil, shorturls.ErrShortURLInvalidTTL.Errorf("expected a positive expiry: %s", cmd.TTL)
+	}
 
-	uid, err := shortid.Generate()
-	if err != nil {
-		return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+	uid := strings.TrimSpace(cmd.UID)
+	if uid != "" {
+		if !util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid) {
+			return nil, shorturls.ErrShortURLInvalidUID.Errorf("invalid slug: %s", uid)
+		}
+		exists, err := s.SQLStore.Exists(ctx, user.OrgID, uid)
+		if err != nil {
+			return nil, shorturls.ErrShortURLInternal.Errorf("failed to check slug: %w", err)
+		}
+		if exists {
+			return nil, shorturls.ErrShortURLUIDExists.Errorf("slug %s already exists", uid)
+		}
+	} else {
+		var err error
+		if uid, err = shortid.Generate(); err != nil {
+			return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+		}
 	}
 
-	now := time.Now().Unix()
+	now := getTime()
 	shortURL := shorturls.ShortUrl{
 		OrgId:     user.OrgID,
 		Uid:       uid,
 		Path:      relPath,
 		CreatedBy: user.UserID,
-		CreatedAt: now,
+		CreatedAt: now.Unix(),
+	}
+	if cmd.TTL > 0 {
+		shortURL.ExpiresAt = now.Add(cmd.TTL).Unix()
 	}
 
 	if err := s.SQLStore.Insert(ctx, &shortURL); err != nil {
@@ -68,3 +96,7 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.SQLStore.Delete(ctx, cmd)
 }
+
+func (s ShortURLService) DeleteExpiredShortURLs(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return s.SQLStore.DeleteExpired(ctx, getTime(), cmd)
+}
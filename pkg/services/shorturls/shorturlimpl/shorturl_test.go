@@ -21,7 +21,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -48,10 +48,18 @@ func TestShortURLService(t *testing.T) {
 			updatedShortURL, err := service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
 			require.NoError(t, err)
 			require.Equal(t, expectedTime.Unix(), updatedShortURL.LastSeenAt)
+			require.Equal(t, int64(1), updatedShortURL.HitCount)
+
+			err = service.UpdateLastSeenAt(context.Background(), updatedShortURL)
+			require.NoError(t, err)
+
+			updatedShortURL, err = service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), updatedShortURL.HitCount)
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -76,6 +84,72 @@ func TestShortURLService(t *testing.T) {
 		})
 	})
 
+	t.Run("User can create short URLs with a slug", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: "explore?left=1", UID: "incident-42"})
+		require.NoError(t, err)
+		require.Equal(t, "incident-42", shortURL.Uid)
+
+		existingShortURL, err := service.GetShortURLByUID(context.Background(), user, "incident-42")
+		require.NoError(t, err)
+		require.Equal(t, "explore?left=1", existingShortURL.Path)
+
+		t.Run("and cannot reuse the slug", func(t *testing.T) {
+			_, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: "explore", UID: "incident-42"})
+			require.True(t, shorturls.ErrShortURLUIDExists.Is(err))
+		})
+
+		t.Run("and cannot use an invalid slug", func(t *testing.T) {
+			_, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: "explore", UID: "incident/42"})
+			require.True(t, shorturls.ErrShortURLInvalidUID.Is(err))
+		})
+	})
+
+	t.Run("Short URLs with an expiry", func(t *testing.T) {
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		now := time.Date(2020, time.November, 27, 6, 5, 1, 0, time.UTC)
+		getTime = func() time.Time {
+			return now
+		}
+
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: "explore", TTL: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, now.Add(time.Hour).Unix(), shortURL.ExpiresAt)
+
+		_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+		require.NoError(t, err)
+
+		_, err = service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: "explore", TTL: -time.Hour})
+		require.True(t, shorturls.ErrShortURLInvalidTTL.Is(err))
+
+		t.Run("are not stale before they expire", func(t *testing.T) {
+			cmd := shorturls.DeleteShortUrlCommand{OlderThan: now}
+			require.NoError(t, service.DeleteStaleShortURLs(context.Background(), &cmd))
+			_, err := service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+			require.NoError(t, err)
+		})
+
+		t.Run("cannot be looked up and are deleted once expired", func(t *testing.T) {
+			now = now.Add(time.Hour)
+
+			_, err := service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+			require.True(t, shorturls.ErrShortURLExpired.Is(err))
+
+			cmd := shorturls.DeleteExpiredShortURLsCommand{}
+			require.NoError(t, service.DeleteExpiredShortURLs(context.Background(), &cmd))
+			require.Equal(t, int64(1), cmd.NumDeleted)
+
+			_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+			require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+		})
+	})
+
 	t.Run("User cannot look up nonexistent short URLs", func(t *testing.T) {
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
@@ -2,6 +2,7 @@ package shorturlimpl
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -10,9 +11,11 @@ import (
 
 type store interface {
 	Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error)
+	Exists(ctx context.Context, orgID int64, uid string) (bool, error)
 	Update(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error
+	DeleteExpired(ctx context.Context, now time.Time, cmd *shorturls.DeleteExpiredShortURLsCommand) error
 }
 
 type sqlStore struct {
@@ -39,13 +42,24 @@ func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string)
 	return &shortURL, nil
 }
 
+func (s sqlStore) Exists(ctx context.Context, orgID int64, uid string) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var err error
+		exists, err = dbSession.Where("org_id=? AND uid=?", orgID, uid).Exist(&shorturls.ShortUrl{})
+		return err
+	})
+	return exists, err
+}
+
 func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.db.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		_, err := dbSession.Exec("UPDATE short_url SET last_seen_at = ?, hit_count = hit_count + 1 WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.HitCount++
 		return nil
 	})
 }
@@ -59,7 +73,7 @@ func (s sqlStore) Insert(ctx context.Context, shortURL *shorturls.ShortUrl) erro
 
 func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0) AND (expires_at IS NULL OR expires_at = 0)"
 
 		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
 			return err
@@ -69,3 +83,16 @@ func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlComma
 		return nil
 	})
 }
+
+func (s sqlStore) DeleteExpired(ctx context.Context, now time.Time, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var rawSql = "DELETE FROM short_url WHERE expires_at > 0 AND expires_at <= ?"
+
+		if result, err := session.Exec(rawSql, now.Unix()); err != nil {
+			return err
+		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,195 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"time"
+
+	"xorm.io/xorm"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const DatasourcePermissionsMigrationID = "datasource query read write permissions"
+
+// datasourcePermissionTranslation is the actions of the managed data source permissions,
+// from the permission with the fewest actions to the permission with the most actions.
+var datasourcePermissionTranslation = [][]string{
+	{datasources.ActionRead},
+	{datasources.ActionRead, datasources.ActionQuery},
+	{datasources.ActionRead, datasources.ActionQuery, datasources.ActionWrite, datasources.ActionDelete},
+	{datasources.ActionRead, datasources.ActionQuery, datasources.ActionWrite, datasources.ActionDelete, datasources.ActionPermissionsRead, datasources.ActionPermissionsWrite},
+}
+
+// AddDatasourcePermissionsMigrator maps the managed data source permissions to the split of the query, read
+// and write actions, and grants viewers and editors the query permission on the data sources without any
+// managed permission, so that they keep access to them when the query permissions are enforced.
+func AddDatasourcePermissionsMigrator(mg *migrator.Migrator) {
+	mg.AddMigration(DatasourcePermissionsMigrationID, &datasourcePermissionsMigrator{})
+}
+
+var _ migrator.CodeMigration = new(datasourcePermissionsMigrator)
+
+type datasourcePermissionsMigrator struct {
+	permissionMigrator
+}
+
+type dataSource struct {
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+}
+
+type managedDatasourcePermission struct {
+	RoleID int64  `xorm:"role_id"`
+	OrgID  int64  `xorm:"org_id"`
+	Action string `xorm:"action"`
+	Scope  string `xorm:"scope"`
+}
+
+type orgScope struct {
+	orgID int64
+	scope string
+}
+
+type roleScope struct {
+	roleID int64
+	scope  string
+}
+
+func (m *datasourcePermissionsMigrator) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
+	m.sess = sess
+	m.dialect = mg.Dialect
+
+	var dataSources []dataSource
+	if err := m.sess.SQL("SELECT org_id, uid FROM data_source").Find(&dataSources); err != nil {
+		return fmt.Errorf("failed to list data sources: %w", err)
+	}
+
+	var permissions []managedDatasourcePermission
+	if err := m.sess.SQL(`SELECT p.role_id, r.org_id, p.action, p.scope FROM permission AS p
+	INNER JOIN role AS r ON p.role_id = r.id
+	WHERE r.name LIKE ? AND p.scope LIKE ?`, "managed:%", datasources.ScopePrefix+"%").
+		Find(&permissions); err != nil {
+		return fmt.Errorf("failed to list managed data source permissions: %w", err)
+	}
+
+	managed := make(map[orgScope]bool)
+	actions := make(map[roleScope]map[string]bool)
+	for _, p := range permissions {
+		managed[orgScope{orgID: p.OrgID, scope: p.Scope}] = true
+		key := roleScope{roleID: p.RoleID, scope: p.Scope}
+		if actions[key] == nil {
+			actions[key] = make(map[string]bool)
+		}
+		actions[key][p.Action] = true
+	}
+
+	now := time.Now()
+	var toAdd []ac.Permission
+	for key, existing := range actions {
+		for _, action := range missingDatasourceActions(existing) {
+			toAdd = append(toAdd, ac.Permission{RoleID: key.roleID, Action: action, Scope: key.scope, Created: now, Updated: now})
+		}
+	}
+
+	seeded, err := m.seedBuiltInRolePermissions(dataSources, managed, now, mg)
+	if err != nil {
+		return err
+	}
+	toAdd = append(toAdd, seeded...)
+
+	return batch(len(toAdd), batchSize, func(start, end int) error {
+		if _, err := m.sess.InsertMulti(toAdd[start:end]); err != nil {
+			return fmt.Errorf("failed to create data source permissions: %w", err)
+		}
+		return nil
+	})
+}
+
+// seedBuiltInRolePermissions grants viewers and editors the query permission on the data sources without any managed
+// permission, which is what they are granted on new data sources.
+func (m *datasourcePermissionsMigrator) seedBuiltInRolePermissions(dataSources []dataSource, managed map[orgScope]bool, now time.Time, mg *migrator.Migrator) ([]ac.Permission, error) {
+	scopes := make(map[int64][]string)
+	for _, ds := range dataSources {
+		scope := datasources.ScopeProvider.GetResourceScopeUID(ds.UID)
+		if !managed[orgScope{orgID: ds.OrgID, scope: scope}] {
+			scopes[ds.OrgID] = append(scopes[ds.OrgID], scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	roleNames := []string{"managed:builtins:viewer:permissions", "managed:builtins:editor:permissions"}
+	var allRoles, rolesToCreate []*ac.Role
+	for orgID := range scopes {
+		for _, name := range roleNames {
+			role, err := m.findRole(orgID, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find role %s: %w", name, err)
+			}
+			if role.ID == 0 {
+				rolesToCreate = append(rolesToCreate, &ac.Role{OrgID: orgID, Name: name})
+			} else {
+				allRoles = append(allRoles, &role)
+			}
+		}
+	}
+
+	mg.Logger.Debug(fmt.Sprintf("bulk-creating roles %v", rolesToCreate))
+	createdRoles, err := m.bulkCreateRoles(rolesToCreate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk-create roles: %w", err)
+	}
+	if err := m.bulkAssignRoles(createdRoles); err != nil {
+		return nil, fmt.Errorf("failed to bulk-assign roles: %w", err)
+	}
+	allRoles = append(allRoles, createdRoles...)
+
+	var permissions []ac.Permission
+	for _, role := range allRoles {
+		for _, scope := range scopes[role.OrgID] {
+			for _, action := range datasourcePermissionTranslation[1] {
+				permissions = append(permissions, ac.Permission{RoleID: role.ID, Action: action, Scope: scope, Created: now, Updated: now})
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// missingDatasourceActions returns the actions of the smallest managed data source permission that includes
+// every action of the existing permission and that the existing permission lacks, for example the read action
+// of a permission that only allowed to query the data source.
+func missingDatasourceActions(existing map[string]bool) []string {
+	for _, actions := range datasourcePermissionTranslation {
+		includes := true
+		for action := range existing {
+			if !containsAction(actions, action) {
+				includes = false
+				break
+			}
+		}
+		if !includes {
+			continue
+		}
+
+		var missing []string
+		for _, action := range actions {
+			if !existing[action] {
+				missing = append(missing, action)
+			}
+		}
+		return missing
+	}
+	return nil
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	acmig "github.com/grafana/grafana/pkg/services/sqlstore/migrations/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDatasourcePermissionsMigration(t *testing.T) {
+	x := setupTestDB(t)
+
+	_, err := x.Exec(`DELETE FROM migration_log WHERE migration_id = ?`, acmig.DatasourcePermissionsMigrationID)
+	require.NoError(t, err)
+
+	for _, ds := range []struct {
+		orgID int64
+		uid   string
+	}{{1, "a"}, {1, "b"}, {2, "c"}} {
+		_, err := x.Exec(`INSERT INTO data_source (org_id, version, type, name, access, url, basic_auth, is_default, created, updated, uid)
+		VALUES (?, 1, 'prometheus', ?, 'proxy', '', ?, ?, ?, ?, ?)`, ds.orgID, ds.uid, false, false, now, now, ds.uid)
+		require.NoError(t, err)
+	}
+
+	scopeA := datasources.ScopeProvider.GetResourceScopeUID("a")
+	scopeB := datasources.ScopeProvider.GetResourceScopeUID("b")
+	scopeC := datasources.ScopeProvider.GetResourceScopeUID("c")
+	putTestPermissions(t, x, map[int64]map[string][]rawPermission{
+		1: {
+			"managed:users:1:permissions": {
+				{Action: datasources.ActionQuery, Scope: scopeA},
+			},
+			"managed:builtins:viewer:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeA},
+			},
+			"managed:builtins:editor:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeA},
+				{Action: datasources.ActionWrite, Scope: scopeA},
+			},
+		},
+	})
+
+	acmigrator := migrator.NewMigrator(x, &setting.Cfg{Logger: log.New("acmigration.test")})
+	acmig.AddDatasourcePermissionsMigrator(acmigrator)
+	require.NoError(t, acmigrator.Start(false, 0))
+
+	want := map[int64]map[string][]rawPermission{
+		1: {
+			"managed:users:1:permissions": {
+				{Action: datasources.ActionQuery, Scope: scopeA},
+				{Action: datasources.ActionRead, Scope: scopeA},
+			},
+			"managed:builtins:viewer:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeA},
+				{Action: datasources.ActionRead, Scope: scopeB},
+				{Action: datasources.ActionQuery, Scope: scopeB},
+			},
+			"managed:builtins:editor:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeA},
+				{Action: datasources.ActionQuery, Scope: scopeA},
+				{Action: datasources.ActionWrite, Scope: scopeA},
+				{Action: datasources.ActionDelete, Scope: scopeA},
+				{Action: datasources.ActionRead, Scope: scopeB},
+				{Action: datasources.ActionQuery, Scope: scopeB},
+			},
+		},
+		2: {
+			"managed:builtins:viewer:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeC},
+				{Action: datasources.ActionQuery, Scope: scopeC},
+			},
+			"managed:builtins:editor:permissions": {
+				{Action: datasources.ActionRead, Scope: scopeC},
+				{Action: datasources.ActionQuery, Scope: scopeC},
+			},
+		},
+	}
+	for orgID, roles := range want {
+		for roleName, wantPerms := range roles {
+			role := accesscontrol.Role{}
+			hasRole, err := x.Table("role").Where("org_id = ? AND name = ?", orgID, roleName).Get(&role)
+			require.NoError(t, err)
+			require.True(t, hasRole, "expected role to exist", "orgID", orgID, "role", roleName)
+
+			perms := []accesscontrol.Permission{}
+			require.NoError(t, x.Table("permission").Where("role_id = ?", role.ID).Find(&perms))
+			require.ElementsMatch(t, wantPerms, convertToRawPermissions(perms), "orgID", orgID, "role", roleName)
+
+			has, err := x.Table("builtin_role").Where("role_id = ? AND org_id = ?", role.ID, orgID).Exist()
+			require.NoError(t, err)
+			require.True(t, has, "expected assignment of role", "orgID", orgID, "role", roleName)
+		}
+	}
+}
@@ -183,6 +183,34 @@ func addAnnotationMig(mg *Migrator) {
 	mg.AddMigration("Increase tags column to length 4096", NewRawSQLMigration("").
 		Postgres("ALTER TABLE annotation ALTER COLUMN tags TYPE VARCHAR(4096);").
 		Mysql("ALTER TABLE annotation MODIFY tags VARCHAR(4096);"))
+
+	// Threaded comments of annotations. parent_id is the comment a comment replies to, or 0 for
+	// the comments replying to the annotation.
+	annotationCommentTable := Table{
+		Name: "annotation_comment",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "annotation_id", Type: DB_BigInt, Nullable: false},
+			{Name: "parent_id", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "text", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "annotation_id"}},
+		},
+	}
+
+	mg.AddMigration("Create annotation_comment table", NewAddTableMigration(annotationCommentTable))
+	mg.AddMigration("Add index annotation_comment.org_id_annotation_id", NewAddIndexMigration(annotationCommentTable, annotationCommentTable.Indices[0]))
+
+	// The tag statistics group the annotations by tag. This index lets them be counted from the tags,
+	// ordered by the tag.key_value index, instead of from all the annotation_tag rows of the annotations.
+	mg.AddMigration("Add index annotation_tag.tag_id_annotation_id", NewAddIndexMigration(annotationTagTableV3, &Index{
+		Cols: []string{"tag_id", "annotation_id"}, Type: IndexType,
+	}))
 }
 
 type AddMakeRegionSingleRowMigration struct {
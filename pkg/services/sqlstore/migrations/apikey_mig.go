@@ -100,4 +100,12 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	// team_id is set for the tokens owned by a team, which get the permissions of the team.
+	mg.AddMigration("Add team_id column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "team_id", Type: DB_BigInt, Nullable: true,
+	}))
+	mg.AddMigration("Add index api_key.team_id", NewAddIndexMigration(apiKeyV2, &Index{
+		Cols: []string{"team_id"},
+	}))
 }
@@ -0,0 +1,23 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAPIUsageMigrations(mg *Migrator) {
+	usageV1 := Table{
+		Name: "org_api_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "month", Type: DB_NVarchar, Length: 7, Nullable: false},
+			{Name: "api_requests", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "month"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_api_usage table", NewAddTableMigration(usageV1))
+	addTableIndicesMigrations(mg, "v1", usageV1)
+}
@@ -71,4 +71,12 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add expiry_reminder_sent column to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "expiry_reminder_sent", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add index on dashboard_snapshot expires", NewAddIndexMigration(snapshotV5, &Index{
+		Cols: []string{"expires"},
+	}))
 }
@@ -134,4 +134,47 @@ func addDataSourceMigration(mg *Migrator) {
 
 	mg.AddMigration("add unique index datasource_org_id_is_default", NewAddIndexMigration(tableV2, &Index{
 		Cols: []string{"org_id", "is_default"}}))
+
+	// data sources shared from the owning org with consumer orgs
+	dataSourceShareV1 := Table{
+		Name: "data_source_share",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "consumer_org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "min_role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"data_source_id", "consumer_org_id"}, Type: UniqueIndex},
+			{Cols: []string{"consumer_org_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_share table", NewAddTableMigration(dataSourceShareV1))
+	mg.AddMigration("add unique index data_source_share.data_source_id_consumer_org_id", NewAddIndexMigration(dataSourceShareV1, dataSourceShareV1.Indices[0]))
+	mg.AddMigration("add index data_source_share.consumer_org_id", NewAddIndexMigration(dataSourceShareV1, dataSourceShareV1.Indices[1]))
+
+	// previous versions of the secure json data of data sources
+	secureJSONDataVersionV1 := Table{
+		Name: "data_source_secure_json_data_version",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "secure_json_fields", Type: DB_Text, Nullable: false},
+			{Name: "encrypted_secure_json_data", Type: DB_Text, Nullable: false},
+			{Name: "replaced", Type: DB_DateTime, Nullable: false},
+			{Name: "replaced_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"data_source_id", "version"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create data_source_secure_json_data_version table", NewAddTableMigration(secureJSONDataVersionV1))
+	mg.AddMigration("add unique index data_source_secure_json_data_version.data_source_id_version", NewAddIndexMigration(secureJSONDataVersionV1, secureJSONDataVersionV1.Indices[0]))
 }
@@ -113,6 +113,26 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	ssosettings.AddMigration(mg)
 
 	ualert.CreateOrgMigratedKVStoreEntries(mg)
+
+	addPlaylistDeviceMigrations(mg)
+
+	addAuditLogMigrations(mg)
+
+	addQueryAuditMigrations(mg)
+
+	addPasswordPolicyMigrations(mg)
+
+	addTOTPMigrations(mg)
+
+	addWebAuthnMigrations(mg)
+
+	addAPIUsageMigrations(mg)
+
+	addSavedSearchMigrations(mg)
+
+	addQueryErrorStatsMigrations(mg)
+
+	accesscontrol.AddDatasourcePermissionsMigrator(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
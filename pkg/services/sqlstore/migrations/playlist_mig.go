@@ -71,3 +71,24 @@ func playlistV2() Table {
 		},
 	}
 }
+
+func addPlaylistDeviceMigrations(mg *Migrator) {
+	playlistDeviceV1 := Table{
+		Name: "playlist_device",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "playlist_uid", Type: DB_NVarchar, Length: 80, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create playlist_device table", NewAddTableMigration(playlistDeviceV1))
+	mg.AddMigration("add unique index playlist_device.org_id_uid", NewAddIndexMigration(playlistDeviceV1, playlistDeviceV1.Indices[0]))
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addQueryErrorStatsMigrations(mg *Migrator) {
+	statsV1 := Table{
+		Name: "panel_query_error_stats",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "bucket", Type: DB_BigInt, Nullable: false},
+			{Name: "request_count", Type: DB_BigInt, Nullable: false},
+			{Name: "error_count", Type: DB_BigInt, Nullable: false},
+			{Name: "latency", Type: DB_Text, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: false},
+			{Name: "last_error_at", Type: DB_BigInt, Nullable: false},
+			{Name: "last_queried_at", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "bucket"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "bucket"}},
+			{Cols: []string{"bucket"}},
+		},
+	}
+
+	mg.AddMigration("create panel_query_error_stats table", NewAddTableMigration(statsV1))
+	addTableIndicesMigrations(mg, "v1", statsV1)
+}
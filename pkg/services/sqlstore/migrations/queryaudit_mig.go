@@ -0,0 +1,33 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addQueryAuditMigrations(mg *Migrator) {
+	queryAuditLogV1 := Table{
+		Name: "query_audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "datasource_type", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "ref_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "query", Type: DB_MediumText, Nullable: false},
+			{Name: "user_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create query_audit_log table", NewAddTableMigration(queryAuditLogV1))
+	mg.AddMigration("add index query_audit_log.org_id_datasource_uid_created", NewAddIndexMigration(queryAuditLogV1, queryAuditLogV1.Indices[0]))
+	mg.AddMigration("add index query_audit_log.created", NewAddIndexMigration(queryAuditLogV1, queryAuditLogV1.Indices[1]))
+}
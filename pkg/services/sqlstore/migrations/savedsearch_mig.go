@@ -0,0 +1,46 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addSavedSearchMigrations(mg *Migrator) {
+	savedSearchV1 := Table{
+		Name: "saved_search",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "filter", Type: DB_Text, Nullable: false},
+			{Name: "subscribed", Type: DB_Bool, Nullable: false},
+			{Name: "checked", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id", "name"}, Type: UniqueIndex},
+			{Cols: []string{"subscribed"}},
+		},
+	}
+
+	mg.AddMigration("create saved_search table", NewAddTableMigration(savedSearchV1))
+	addTableIndicesMigrations(mg, "v1", savedSearchV1)
+
+	// saved_search_match records the dashboards that matched the filter of a subscribed search,
+	// so that the user is only notified of the new ones.
+	matchV1 := Table{
+		Name: "saved_search_match",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "saved_search_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"saved_search_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create saved_search_match table", NewAddTableMigration(matchV1))
+	addTableIndicesMigrations(mg, "v1", matchV1)
+}
@@ -94,6 +94,16 @@ func addTempUserMigrations(mg *Migrator) {
 
 	// Ensure outstanding invites are given a valid lifetime post-migration
 	mg.AddMigration("Set created for temp users that will otherwise prematurely expire", &SetCreatedForOutstandingInvites{})
+
+	mg.AddMigration("Add sso_provider column to temp_user", NewAddColumnMigration(tempUserV2, &Column{
+		Name: "sso_provider", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+	mg.AddMigration("Add email_domain column to temp_user", NewAddColumnMigration(tempUserV2, &Column{
+		Name: "email_domain", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+	mg.AddMigration("Add expires column to temp_user", NewAddColumnMigration(tempUserV2, &Column{
+		Name: "expires", Type: DB_BigInt, Default: "0", Nullable: false,
+	}))
 }
 
 type SetCreatedForOutstandingInvites struct {
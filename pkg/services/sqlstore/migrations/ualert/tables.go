@@ -24,6 +24,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add last_applied column to alert_configuration_history", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_configuration_history"}, &migrator.Column{
 		Name: "last_applied", Type: migrator.DB_Int, Nullable: false, Default: "0",
 	}))
+
+	addNotificationDeliveryMigrations(mg)
+
+	addMaintenanceWindowMigrations(mg)
+
+	addAlertRuleTestMigrations(mg)
 	// End of migration log, add new migrations above this line.
 }
 
@@ -491,6 +497,86 @@ func addAlertImageMigrations(mg *migrator.Migrator) {
 		Mysql("ALTER TABLE alert_image MODIFY url VARCHAR(2048) NOT NULL;"))
 }
 
+func addNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	deliveryTable := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration_name", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration_type", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "rule_uids", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "retry", Type: migrator.DB_Int, Nullable: false},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "sent_at"}},
+			{Cols: []string{"org_id", "receiver", "sent_at"}},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(deliveryTable))
+	mg.AddMigration("add index on org_id, sent_at to alert_notification_delivery table", migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[0]))
+	mg.AddMigration("add index on org_id, receiver, sent_at to alert_notification_delivery table", migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[1]))
+}
+
+func addMaintenanceWindowMigrations(mg *migrator.Migrator) {
+	windowTable := migrator.Table{
+		Name: "alert_maintenance_window",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "dashboard_uids", Type: migrator.DB_Text, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "annotations", Type: migrator.DB_Text, Nullable: false},
+			{Name: "annotations_updated", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_maintenance_window table", migrator.NewAddTableMigration(windowTable))
+	mg.AddMigration("add unique index on org_id, uid to alert_maintenance_window table", migrator.NewAddIndexMigration(windowTable, windowTable.Indices[0]))
+}
+
+func addAlertRuleTestMigrations(mg *migrator.Migrator) {
+	testTable := migrator.Table{
+		Name: "alert_rule_test",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "namespace_uid", "rule_group"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_test table", migrator.NewAddTableMigration(testTable))
+	mg.AddMigration("add unique index on org_id, uid to alert_rule_test table", migrator.NewAddIndexMigration(testTable, testTable.Indices[0]))
+	mg.AddMigration("add index on org_id, namespace_uid, rule_group to alert_rule_test table", migrator.NewAddIndexMigration(testTable, testTable.Indices[1]))
+}
+
 func extractAlertmanagerConfigurationHistoryMigration(mg *migrator.Migrator) {
 	// Since it's not always consistent as to what state the org ID indexes are in, just drop them all and rebuild from scratch.
 	// This is not expensive since this table is guaranteed to have a small number of rows.
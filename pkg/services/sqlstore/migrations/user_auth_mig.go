@@ -46,4 +46,8 @@ func addUserAuthMigrations(mg *Migrator) {
 	mg.AddMigration("Add OAuth ID token to user_auth", NewAddColumnMigration(userAuthV1, &Column{
 		Name: "o_auth_id_token", Type: DB_Text, Nullable: true,
 	}))
+
+	mg.AddMigration("Add is_primary to user_auth", NewAddColumnMigration(userAuthV1, &Column{
+		Name: "is_primary", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
@@ -48,4 +48,8 @@ func addUserAuthTokenMigrations(mg *Migrator) {
 	mg.AddMigration("add index user_auth_token.revoked_at", NewAddIndexMigration(userAuthTokenV1, &Index{
 		Cols: []string{"revoked_at"},
 	}))
+
+	mg.AddMigration("Add device_id to the user auth token", NewAddColumnMigration(userAuthTokenV1, &Column{
+		Name: "device_id", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
 }
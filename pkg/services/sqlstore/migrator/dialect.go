@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 	"xorm.io/xorm"
@@ -33,6 +34,15 @@ type Dialect interface {
 	OrderBy(order string) string
 
 	CreateIndexSQL(tableName string, index *Index) string
+	// CreateIndexOnlineSQL returns the statement creating an index without blocking the writes to the table,
+	// or an empty string if the database does not support it. The statement can not run in a transaction.
+	CreateIndexOnlineSQL(tableName string, index *Index) string
+	// DropInvalidIndexSQL returns the statement dropping what is left of an index whose online creation
+	// failed, or an empty string if nothing is left by a failed creation.
+	DropInvalidIndexSQL(tableName string, index *Index) string
+	// LockTimeoutSQL returns the statements setting how long the statements of a session wait for a lock on
+	// a table and restoring the default, or empty strings if the database does not support it.
+	LockTimeoutSQL(timeout time.Duration) (set string, reset string)
 	CreateTableSQL(table *Table) string
 	AddColumnSQL(tableName string, col *Column) string
 	CopyTableData(sourceTable string, targetTable string, sourceCols []string, targetCols []string) string
@@ -70,6 +80,11 @@ type Dialect interface {
 	IsUniqueConstraintViolation(err error) bool
 	ErrorMessage(err error) string
 	IsDeadlock(err error) bool
+	// IsLockTimeout returns true if the statement failed waiting for a lock, as set by LockTimeoutSQL.
+	IsLockTimeout(err error) bool
+	// IsSerializationFailure returns true if the transaction failed because it could not be serialized with
+	// concurrent ones, and can be retried.
+	IsSerializationFailure(err error) bool
 	Lock(LockCfg) error
 	Unlock(LockCfg) error
 
@@ -201,12 +216,15 @@ func (b *BaseDialect) CreateIndexSQL(tableName string, index *Index) string {
 
 	idxName := index.XName(tableName)
 
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v);", unique, quote(idxName), quote(tableName), b.quoteIndexCols(index.Cols))
+}
+
+func (b *BaseDialect) quoteIndexCols(cols []string) string {
 	quotedCols := []string{}
-	for _, col := range index.Cols {
+	for _, col := range cols {
 		quotedCols = append(quotedCols, b.dialect.Quote(col))
 	}
-
-	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v);", unique, quote(idxName), quote(tableName), strings.Join(quotedCols, ","))
+	return strings.Join(quotedCols, ",")
 }
 
 func (b *BaseDialect) QuoteColList(cols []string) string {
@@ -325,6 +343,26 @@ func (b *BaseDialect) CleanDB(engine *xorm.Engine) error {
 	return nil
 }
 
+func (b *BaseDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	return ""
+}
+
+func (b *BaseDialect) DropInvalidIndexSQL(tableName string, index *Index) string {
+	return ""
+}
+
+func (b *BaseDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	return "", ""
+}
+
+func (b *BaseDialect) IsLockTimeout(err error) bool {
+	return false
+}
+
+func (b *BaseDialect) IsSerializationFailure(err error) bool {
+	return false
+}
+
 func (b *BaseDialect) NoOpSQL() string {
 	return "SELECT 0;"
 }
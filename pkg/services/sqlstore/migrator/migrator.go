@@ -30,6 +30,8 @@ type Migrator struct {
 	isLocked     atomic.Bool
 	logMap       map[string]MigrationLog
 	tableName    string
+	// OnlineIndexes configures the creation of indexes without blocking the writes to their tables.
+	OnlineIndexes OnlineIndexConfig
 }
 
 type MigrationLog struct {
@@ -199,6 +201,12 @@ func (mg *Migrator) run() (err error) {
 		}
 
 		sql := m.SQL(mg.Dialect)
+		execute := mg.InTransaction
+		if index, ok := mg.onlineIndex(m); ok {
+			// Indexes created online can not be created in a transaction.
+			sql = mg.Dialect.CreateIndexOnlineSQL(index.tableName, index.index)
+			execute = mg.withoutTransaction
+		}
 
 		record := MigrationLog{
 			MigrationID: m.Id(),
@@ -206,7 +214,7 @@ func (mg *Migrator) run() (err error) {
 			Timestamp:   time.Now(),
 		}
 
-		err := mg.InTransaction(func(sess *xorm.Session) error {
+		err := execute(func(sess *xorm.Session) error {
 			err := mg.exec(m, sess)
 			if err != nil {
 				mg.Logger.Error("Exec failed", "error", err, "sql", sql)
@@ -265,6 +273,8 @@ func (mg *Migrator) exec(m Migration, sess *xorm.Session) error {
 	if codeMigration, ok := m.(CodeMigration); ok {
 		mg.Logger.Debug("Executing code migration", "id", m.Id())
 		err = codeMigration.Exec(sess, mg)
+	} else if index, ok := mg.onlineIndex(m); ok {
+		err = mg.createIndexOnline(index)
 	} else {
 		sql := m.SQL(mg.Dialect)
 		mg.Logger.Debug("Executing sql migration", "id", m.Id(), "sql", sql)
@@ -306,6 +316,14 @@ func (mg *Migrator) InTransaction(callback dbTransactionFunc) error {
 	return nil
 }
 
+// withoutTransaction runs the callback in a session that is not in a transaction.
+func (mg *Migrator) withoutTransaction(callback dbTransactionFunc) error {
+	sess := mg.DBEngine.NewSession()
+	defer sess.Close()
+
+	return callback(sess)
+}
+
 func casRestoreOnErr(lock *atomic.Bool, o, n bool, casErr error, f func(LockCfg) error, lockCfg LockCfg) error {
 	if !lock.CompareAndSwap(o, n) {
 		return casErr
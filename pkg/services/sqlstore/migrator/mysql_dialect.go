@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VividCortex/mysqlerr"
 	"github.com/go-sql-driver/mysql"
@@ -219,6 +220,28 @@ func (db *MySQLDialect) IsDeadlock(err error) bool {
 	return db.isThisError(err, mysqlerr.ER_LOCK_DEADLOCK)
 }
 
+// CreateIndexOnlineSQL creates the index in place without locking the table, which does not block the writes to it.
+func (db *MySQLDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	var unique string
+	if index.Type == UniqueIndex {
+		unique = " UNIQUE"
+	}
+	return fmt.Sprintf("ALTER TABLE %v ADD%s INDEX %v (%v), ALGORITHM=INPLACE, LOCK=NONE;", db.Quote(tableName), unique, db.Quote(index.XName(tableName)), db.quoteIndexCols(index.Cols))
+}
+
+// LockTimeoutSQL sets the timeout of the metadata locks, which are held briefly when an index is created in place.
+func (db *MySQLDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	seconds := int64(timeout.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("SET SESSION lock_wait_timeout = %d;", seconds), "SET SESSION lock_wait_timeout = DEFAULT;"
+}
+
+func (db *MySQLDialect) IsLockTimeout(err error) bool {
+	return db.isThisError(err, mysqlerr.ER_LOCK_WAIT_TIMEOUT)
+}
+
 // UpsertSQL returns the upsert sql statement for MySQL dialect
 func (db *MySQLDialect) UpsertSQL(tableName string, keyCols, updateCols []string) string {
 	q, _ := db.UpsertMultipleSQL(tableName, keyCols, updateCols, 1)
@@ -0,0 +1,87 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OnlineIndexConfig configures the creation of indexes without blocking the writes to their tables,
+// on the databases that support it.
+type OnlineIndexConfig struct {
+	Enabled bool
+	// LockTimeout is how long the creation of an index waits for a lock on the table before it fails.
+	LockTimeout time.Duration
+	// Retries is how many times the creation of an index is retried when it fails waiting for a lock.
+	Retries int
+	// Backoff is the delay before the first retry, doubled for each of the next ones.
+	Backoff time.Duration
+}
+
+// onlineIndex returns the index migration if it is created online.
+func (mg *Migrator) onlineIndex(m Migration) (*AddIndexMigration, bool) {
+	if !mg.OnlineIndexes.Enabled {
+		return nil, false
+	}
+	index, ok := m.(*AddIndexMigration)
+	if !ok || mg.Dialect.CreateIndexOnlineSQL(index.tableName, index.index) == "" {
+		return nil, false
+	}
+	return index, true
+}
+
+// createIndexOnline creates an index without blocking the writes to its table. The statement can not run
+// in a transaction, and is retried if it fails waiting for a lock on the table.
+func (mg *Migrator) createIndexOnline(m *AddIndexMigration) error {
+	ctx := context.Background()
+	backoff := mg.OnlineIndexes.Backoff
+	for attempt := 0; ; attempt++ {
+		err := mg.execOnline(ctx, m)
+		if err == nil || !mg.Dialect.IsLockTimeout(err) || attempt >= mg.OnlineIndexes.Retries {
+			return err
+		}
+
+		mg.Logger.Warn("Creating index timed out waiting for a lock, retrying", "id", m.Id(), "attempt", attempt+1, "backoff", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// execOnline creates an index on a dedicated connection, so that the lock timeout of the session applies
+// to the statement and does not leak to the other connections of the pool.
+func (mg *Migrator) execOnline(ctx context.Context, m *AddIndexMigration) (err error) {
+	conn, err := mg.DBEngine.DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	setTimeout, resetTimeout := mg.Dialect.LockTimeoutSQL(mg.OnlineIndexes.LockTimeout)
+	if setTimeout != "" && mg.OnlineIndexes.LockTimeout > 0 {
+		if _, err := conn.ExecContext(ctx, setTimeout); err != nil {
+			return fmt.Errorf("failed to set lock timeout: %w", err)
+		}
+		defer func() {
+			if _, resetErr := conn.ExecContext(ctx, resetTimeout); resetErr != nil && err == nil {
+				err = fmt.Errorf("failed to reset lock timeout: %w", resetErr)
+			}
+		}()
+	}
+
+	sql := mg.Dialect.CreateIndexOnlineSQL(m.tableName, m.index)
+	mg.Logger.Debug("Creating index online", "id", m.Id(), "sql", sql)
+	if _, err := conn.ExecContext(ctx, sql); err != nil {
+		// A failed creation can leave an invalid index behind, which must be dropped before retrying.
+		if dropSQL := mg.Dialect.DropInvalidIndexSQL(m.tableName, m.index); dropSQL != "" {
+			if _, dropErr := conn.ExecContext(ctx, dropSQL); dropErr != nil {
+				mg.Logger.Error("Failed to drop invalid index", "id", m.Id(), "error", dropErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCreateIndexOnlineSQL(t *testing.T) {
+	index := &Index{Cols: []string{"org_id", "epoch"}}
+	unique := &Index{Cols: []string{"org_id", "uid"}, Type: UniqueIndex}
+
+	postgres := NewPostgresDialect()
+	require.Equal(t, `CREATE INDEX CONCURRENTLY "IDX_annotation_org_id_epoch" ON "annotation" ("org_id","epoch");`, postgres.CreateIndexOnlineSQL("annotation", index))
+	require.Equal(t, `CREATE UNIQUE INDEX CONCURRENTLY "UQE_dashboard_org_id_uid" ON "dashboard" ("org_id","uid");`, postgres.CreateIndexOnlineSQL("dashboard", unique))
+	require.Equal(t, `DROP INDEX CONCURRENTLY IF EXISTS "IDX_annotation_org_id_epoch";`, postgres.DropInvalidIndexSQL("annotation", index))
+	set, reset := postgres.LockTimeoutSQL(5 * time.Second)
+	require.Equal(t, "SET lock_timeout = 5000;", set)
+	require.Equal(t, "RESET lock_timeout;", reset)
+	require.True(t, postgres.IsLockTimeout(&pq.Error{Code: "55P03"}))
+	require.False(t, postgres.IsLockTimeout(&pq.Error{Code: "40P01"}))
+
+	mysqlDialect := NewMysqlDialect()
+	require.Equal(t, "ALTER TABLE `annotation` ADD INDEX `IDX_annotation_org_id_epoch` (`org_id`,`epoch`), ALGORITHM=INPLACE, LOCK=NONE;", mysqlDialect.CreateIndexOnlineSQL("annotation", index))
+	require.Equal(t, "ALTER TABLE `dashboard` ADD UNIQUE INDEX `UQE_dashboard_org_id_uid` (`org_id`,`uid`), ALGORITHM=INPLACE, LOCK=NONE;", mysqlDialect.CreateIndexOnlineSQL("dashboard", unique))
+	require.Empty(t, mysqlDialect.DropInvalidIndexSQL("annotation", index))
+	set, reset = mysqlDialect.LockTimeoutSQL(500 * time.Millisecond)
+	require.Equal(t, "SET SESSION lock_wait_timeout = 1;", set)
+	require.Equal(t, "SET SESSION lock_wait_timeout = DEFAULT;", reset)
+	require.True(t, mysqlDialect.IsLockTimeout(&mysql.MySQLError{Number: 1205}))
+
+	require.Empty(t, NewSQLite3Dialect().CreateIndexOnlineSQL("annotation", index))
+}
+
+// onlineSQLite pretends that SQLite creates indexes online, so that the creation of indexes
+// out of a transaction can be tested.
+type onlineSQLite struct {
+	Dialect
+	// locked is how many times the creation of an index fails as if it timed out waiting for a lock.
+	locked int
+}
+
+func (d *onlineSQLite) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	return d.Dialect.CreateIndexSQL(tableName, index)
+}
+
+func (d *onlineSQLite) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	if d.locked > 0 {
+		d.locked--
+		// Fails setting the timeout as if the creation of the index timed out.
+		return "SELECT * FROM locked", ""
+	}
+	return "PRAGMA busy_timeout = 1000;", "PRAGMA busy_timeout = 0;"
+}
+
+func (d *onlineSQLite) IsLockTimeout(err error) bool {
+	return err != nil
+}
+
+func TestMigrator_CreateIndexOnline(t *testing.T) {
+	table := Table{
+		Name: "online",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+		},
+	}
+	index := &Index{Cols: []string{"org_id"}}
+
+	newMigrator := func(t *testing.T, locked int, retries int) *Migrator {
+		t.Helper()
+		engine, err := xorm.NewEngine("sqlite3", ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, engine.Close()) })
+		// The in-memory database is not shared by connections.
+		engine.SetMaxOpenConns(1)
+
+		mg := NewMigrator(engine, setting.NewCfg())
+		mg.Dialect = &onlineSQLite{Dialect: mg.Dialect, locked: locked}
+		mg.OnlineIndexes = OnlineIndexConfig{Enabled: true, LockTimeout: time.Second, Retries: retries, Backoff: time.Millisecond}
+		mg.AddCreateMigration()
+		mg.AddMigration("create online table", NewAddTableMigration(table))
+		mg.AddMigration("add online index", NewAddIndexMigration(table, index))
+		return mg
+	}
+
+	t.Run("creates the index out of a transaction and records it", func(t *testing.T) {
+		mg := newMigrator(t, 0, 0)
+		require.NoError(t, mg.Start(false, 0))
+
+		logs, err := mg.GetMigrationLog()
+		require.NoError(t, err)
+		require.True(t, logs["add online index"].Success)
+		require.Equal(t, "CREATE INDEX `IDX_online_org_id` ON `online` (`org_id`);", logs["add online index"].SQL)
+
+		exists, err := mg.DBEngine.SQL("SELECT 1 FROM sqlite_master WHERE type = 'index' AND name = 'IDX_online_org_id'").Exist()
+		require.NoError(t, err)
+		require.True(t, exists)
+	})
+
+	t.Run("retries when the creation of the index times out waiting for a lock", func(t *testing.T) {
+		mg := newMigrator(t, 2, 2)
+		require.NoError(t, mg.Start(false, 0))
+	})
+
+	t.Run("fails once the retries are exhausted", func(t *testing.T) {
+		mg := newMigrator(t, 2, 1)
+		require.Error(t, mg.Start(false, 0))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"xorm.io/xorm"
@@ -108,6 +109,28 @@ func (db *PostgresDialect) DropIndexSQL(tableName string, index *Index) string {
 	return fmt.Sprintf("DROP INDEX %v CASCADE", quote(idxName))
 }
 
+// CreateIndexOnlineSQL creates the index concurrently, which does not block the writes to the table.
+func (db *PostgresDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	var unique string
+	if index.Type == UniqueIndex {
+		unique = " UNIQUE"
+	}
+	return fmt.Sprintf("CREATE%s INDEX CONCURRENTLY %v ON %v (%v);", unique, db.Quote(index.XName(tableName)), db.Quote(tableName), db.quoteIndexCols(index.Cols))
+}
+
+// DropInvalidIndexSQL drops the invalid index left behind when creating an index concurrently fails.
+func (db *PostgresDialect) DropInvalidIndexSQL(tableName string, index *Index) string {
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %v;", db.Quote(index.XName(tableName)))
+}
+
+func (db *PostgresDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	return fmt.Sprintf("SET lock_timeout = %d;", timeout.Milliseconds()), "RESET lock_timeout;"
+}
+
+func (db *PostgresDialect) IsLockTimeout(err error) bool {
+	return db.isThisError(err, "55P03")
+}
+
 func (db *PostgresDialect) UpdateTableSQL(tableName string, columns []*Column) string {
 	var statements = []string{}
 
@@ -201,6 +224,10 @@ func (db *PostgresDialect) IsDeadlock(err error) bool {
 	return db.isThisError(err, "40P01")
 }
 
+func (db *PostgresDialect) IsSerializationFailure(err error) bool {
+	return db.isThisError(err, "40001")
+}
+
 func (db *PostgresDialect) PostInsertId(table string, sess *xorm.Session) error {
 	if table != "org" {
 		return nil
@@ -70,14 +70,18 @@ func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTr
 // Otherwise it creates a new one that is closed upon completion.
 // A session is stored in the context if sqlstore.InTransaction() has been previously called with the same context (and it's not committed/rolledback yet).
 // In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most five times before giving up.
+// A new session is bounded by the statement timeout, see WithSessionOptions.
 func (ss *SQLStore) WithDbSession(ctx context.Context, callback DBTransactionFunc) error {
 	return ss.withDbSession(ctx, ss.engine, callback)
 }
 
 // WithNewDbSession calls the callback with a new session that is closed upon completion.
 // In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most five times before giving up.
+// The session is bounded by the statement timeout, see WithSessionOptions.
 func (ss *SQLStore) WithNewDbSession(ctx context.Context, callback DBTransactionFunc) error {
-	sess := &DBSession{Session: ss.engine.NewSession(), transactionOpen: false}
+	ctx, cancel := ss.sessionOptions(ctx).statementContext(ctx)
+	defer cancel()
+	sess := &DBSession{Session: ss.engine.NewSession().Context(ctx), transactionOpen: false}
 	defer sess.Close()
 	retry := 0
 	return retryer.Retry(ss.retryOnLocks(ctx, callback, sess, retry), ss.dbCfg.QueryRetries, time.Millisecond*time.Duration(10), time.Second)
@@ -111,6 +115,8 @@ func (ss *SQLStore) retryOnLocks(ctx context.Context, callback DBTransactionFunc
 }
 
 func (ss *SQLStore) withDbSession(ctx context.Context, engine *xorm.Engine, callback DBTransactionFunc) error {
+	ctx, cancel := ss.sessionOptions(ctx).sessionContext(ctx)
+	defer cancel()
 	sess, isNew, span, err := startSessionOrUseExisting(ctx, engine, false, ss.tracer)
 	if err != nil {
 		return err
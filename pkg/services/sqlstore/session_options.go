@@ -0,0 +1,133 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// RetryPolicy controls how a transaction is retried when it fails with a transient error: a locked
+// SQLite database, a deadlock, or a failure to serialize it with concurrent transactions.
+type RetryPolicy struct {
+	// MaxRetries is how many times the transaction is retried, 0 disables the retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry, doubled for each of the next ones up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1, so that the transactions
+	// that conflicted are not retried at the same time.
+	Jitter float64
+}
+
+// backoff returns the delay before the given retry, starting at 0.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 0; i < retry && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 && backoff > 0 {
+		jitter := time.Duration(p.Jitter * float64(backoff))
+		backoff = backoff - jitter + time.Duration(rand.Int63n(int64(2*jitter)+1))
+	}
+	return backoff
+}
+
+// SessionOption overrides the defaults of the [database] section for the sessions started with a context.
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	retryPolicy      RetryPolicy
+	statementTimeout time.Duration
+}
+
+type sessionOptionsKey struct{}
+
+// WithRetryPolicy sets how the transactions are retried when they fail with a transient error.
+func WithRetryPolicy(policy RetryPolicy) SessionOption {
+	return func(o *sessionOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithStatementTimeout bounds how long the session runs, on top of the deadline of the context. A
+// transaction that is retried gets a new timeout for each attempt. 0 disables the timeout.
+func WithStatementTimeout(timeout time.Duration) SessionOption {
+	return func(o *sessionOptions) {
+		o.statementTimeout = timeout
+	}
+}
+
+// WithSessionOptions returns a context that applies the options to the sessions started with it. A session
+// reused from an outer scope keeps the options it was started with.
+func WithSessionOptions(ctx context.Context, opts ...SessionOption) context.Context {
+	parent, _ := ctx.Value(sessionOptionsKey{}).([]SessionOption)
+	merged := make([]SessionOption, 0, len(parent)+len(opts))
+	merged = append(merged, parent...)
+	merged = append(merged, opts...)
+	return context.WithValue(ctx, sessionOptionsKey{}, merged)
+}
+
+func (ss *SQLStore) sessionOptions(ctx context.Context) sessionOptions {
+	o := sessionOptions{
+		retryPolicy: RetryPolicy{
+			MaxRetries: ss.dbCfg.TransactionRetries,
+			MinBackoff: ss.dbCfg.TransactionRetryMinBackoff,
+			MaxBackoff: ss.dbCfg.TransactionRetryMaxBackoff,
+			Jitter:     ss.dbCfg.TransactionRetryJitter,
+		},
+		statementTimeout: ss.dbCfg.StatementTimeout,
+	}
+	if opts, ok := ctx.Value(sessionOptionsKey{}).([]SessionOption); ok {
+		for _, opt := range opts {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// sessionContext bounds the context of a new session by the statement timeout. The context of a session
+// reused from an outer scope is left as it is, since the outer session keeps using it.
+func (o sessionOptions) sessionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Value(ContextSessionKey{}).(*DBSession); ok {
+		return ctx, func() {}
+	}
+	return o.statementContext(ctx)
+}
+
+// statementContext bounds the context by the statement timeout.
+func (o sessionOptions) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.statementTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.statementTimeout)
+}
+
+// isRetryable returns true if the transaction failed with a transient error and can be run again.
+func (ss *SQLStore) isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var sqlError sqlite3.Error
+	if errors.As(err, &sqlError) {
+		return sqlError.Code == sqlite3.ErrLocked || sqlError.Code == sqlite3.ErrBusy
+	}
+	return ss.Dialect.IsDeadlock(err) || ss.Dialect.IsSerializationFailure(err)
+}
+
+// sleepWithContext waits for the delay, or until the context is done.
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	require.Equal(t, 10*time.Millisecond, policy.backoff(0))
+	require.Equal(t, 20*time.Millisecond, policy.backoff(1))
+	require.Equal(t, 40*time.Millisecond, policy.backoff(2))
+	require.Equal(t, 50*time.Millisecond, policy.backoff(3))
+	require.Equal(t, 50*time.Millisecond, policy.backoff(100))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(0)
+		require.GreaterOrEqual(t, backoff, 5*time.Millisecond)
+		require.LessOrEqual(t, backoff, 15*time.Millisecond)
+	}
+}
+
+func TestIntegrationSessionOptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := InitTestDB(t)
+	locked := sqlite3.Error{Code: sqlite3.ErrLocked}
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	t.Run("retries the transaction with the policy of the context", func(t *testing.T) {
+		ctx := WithSessionOptions(context.Background(), WithRetryPolicy(policy))
+		attempts := 0
+		err := store.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+			attempts++
+			return locked
+		})
+		require.ErrorIs(t, err, locked)
+		require.Equal(t, 4, attempts)
+	})
+
+	t.Run("does not retry the transaction when the error is not transient", func(t *testing.T) {
+		ctx := WithSessionOptions(context.Background(), WithRetryPolicy(policy))
+		attempts := 0
+		err := store.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+			attempts++
+			return errors.New("some error")
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("later options override the earlier ones", func(t *testing.T) {
+		ctx := WithSessionOptions(context.Background(), WithRetryPolicy(policy))
+		ctx = WithSessionOptions(ctx, WithRetryPolicy(RetryPolicy{}))
+		attempts := 0
+		err := store.InTransaction(ctx, func(ctx context.Context) error {
+			attempts++
+			return locked
+		})
+		require.ErrorIs(t, err, locked)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("stops retrying the transaction when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithSessionOptions(context.Background(), WithRetryPolicy(RetryPolicy{MaxRetries: 3, MinBackoff: time.Hour})))
+		attempts := 0
+		err := store.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+			attempts++
+			cancel()
+			return locked
+		})
+		require.ErrorIs(t, err, locked)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("cancels the session after the statement timeout", func(t *testing.T) {
+		ctx := WithSessionOptions(context.Background(), WithStatementTimeout(10*time.Millisecond))
+		for name, f := range map[string]func(ctx context.Context, callback DBTransactionFunc) error{
+			"WithDbSession":              store.WithDbSession,
+			"WithNewDbSession":           store.WithNewDbSession,
+			"WithTransactionalDbSession": store.WithTransactionalDbSession,
+		} {
+			err := f(ctx, func(sess *DBSession) error {
+				time.Sleep(20 * time.Millisecond)
+				_, err := sess.Exec("SELECT 1")
+				return err
+			})
+			require.ErrorIs(t, err, context.DeadlineExceeded, name)
+		}
+	})
+}
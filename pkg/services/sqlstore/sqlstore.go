@@ -140,6 +140,7 @@ func (ss *SQLStore) Migrate(isDatabaseLockingEnabled bool) error {
 	}
 
 	migrator := migrator.NewMigrator(ss.engine, ss.Cfg)
+	migrator.OnlineIndexes = ss.dbCfg.MigrationOnlineIndexes
 	ss.migrations.AddMigration(migrator)
 
 	return migrator.Start(isDatabaseLockingEnabled, ss.dbCfg.MigrationLockAttemptTimeout)
@@ -509,9 +510,19 @@ func (ss *SQLStore) readConfig() error {
 	ss.dbCfg.WALEnabled = sec.Key("wal").MustBool(false)
 	ss.dbCfg.SkipMigrations = sec.Key("skip_migrations").MustBool()
 	ss.dbCfg.MigrationLockAttemptTimeout = sec.Key("locking_attempt_timeout_sec").MustInt()
+	ss.dbCfg.MigrationOnlineIndexes = migrator.OnlineIndexConfig{
+		Enabled:     sec.Key("online_index_creation").MustBool(false),
+		LockTimeout: sec.Key("online_index_lock_timeout").MustDuration(10 * time.Second),
+		Retries:     sec.Key("online_index_retries").MustInt(3),
+		Backoff:     time.Second,
+	}
 
 	ss.dbCfg.QueryRetries = sec.Key("query_retries").MustInt()
 	ss.dbCfg.TransactionRetries = sec.Key("transaction_retries").MustInt(5)
+	ss.dbCfg.TransactionRetryMinBackoff = sec.Key("transaction_retry_min_backoff").MustDuration(10 * time.Millisecond)
+	ss.dbCfg.TransactionRetryMaxBackoff = sec.Key("transaction_retry_max_backoff").MustDuration(time.Second)
+	ss.dbCfg.TransactionRetryJitter = sec.Key("transaction_retry_jitter").MustFloat64(0.2)
+	ss.dbCfg.StatementTimeout = sec.Key("statement_timeout").MustDuration(0)
 	return nil
 }
 
@@ -794,8 +805,15 @@ type DatabaseConfig struct {
 	UrlQueryParams              map[string][]string
 	SkipMigrations              bool
 	MigrationLockAttemptTimeout int
+	// MigrationOnlineIndexes configures the creation of indexes without blocking writes, MySQL and Postgres only.
+	MigrationOnlineIndexes migrator.OnlineIndexConfig
 	// SQLite only
 	QueryRetries int
-	// SQLite only
-	TransactionRetries int
+	// TransactionRetries is how many times a transaction is retried when it fails with a transient error.
+	TransactionRetries         int
+	TransactionRetryMinBackoff time.Duration
+	TransactionRetryMaxBackoff time.Duration
+	TransactionRetryJitter     float64
+	// StatementTimeout bounds how long a new session runs, on top of the deadline of its context.
+	StatementTimeout time.Duration
 }
@@ -2,11 +2,7 @@ package sqlstore
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"time"
-
-	"github.com/mattn/go-sqlite3"
 	"xorm.io/xorm"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -33,15 +29,39 @@ func (ss *SQLStore) inTransactionWithRetry(ctx context.Context, fn func(ctx cont
 	}, retry)
 }
 
+// inTransactionWithRetryCtx runs the callback in a transaction, which is retried according to the retry
+// policy of the session when it fails with a transient error.
 func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.Engine, bus bus.Bus, callback DBTransactionFunc, retry int) error {
+	opts := ss.sessionOptions(ctx)
+	for {
+		retryable, err := ss.inTransactionCtx(ctx, engine, bus, callback, opts)
+		if !retryable || retry >= opts.retryPolicy.MaxRetries {
+			return err
+		}
+
+		backoff := opts.retryPolicy.backoff(retry)
+		tsclogger.FromContext(ctx).Info("Transaction failed with a transient error, sleeping then retrying", "error", err, "retry", retry, "backoff", backoff)
+		if sleepErr := sleepWithContext(ctx, backoff); sleepErr != nil {
+			return err
+		}
+		retry++
+	}
+}
+
+// inTransactionCtx runs the callback in a transaction once, and returns whether it failed with an error
+// that can be retried.
+func (ss *SQLStore) inTransactionCtx(ctx context.Context, engine *xorm.Engine, bus bus.Bus, callback DBTransactionFunc, opts sessionOptions) (bool, error) {
+	ctx, cancel := opts.sessionContext(ctx)
+	defer cancel()
+
 	sess, isNew, span, err := startSessionOrUseExisting(ctx, engine, true, ss.tracer)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if !sess.transactionOpen && !isNew {
 		// this should not happen because the only place that creates reusable session begins a new transaction.
-		return fmt.Errorf("cannot reuse existing session that did not start transaction")
+		return false, fmt.Errorf("cannot reuse existing session that did not start transaction")
 	}
 
 	if isNew { // if this call initiated the session, it should be responsible for closing it.
@@ -60,29 +80,18 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 	if !isNew {
 		ctxLogger.Debug("skip committing the transaction because it belongs to a session created in the outer scope")
 		// Do not commit the transaction if the session was reused.
-		return err
-	}
-
-	// special handling of database locked errors for sqlite, then we can retry 5 times
-	var sqlError sqlite3.Error
-	if errors.As(err, &sqlError) && retry < ss.dbCfg.TransactionRetries && (sqlError.Code == sqlite3.ErrLocked || sqlError.Code == sqlite3.ErrBusy) {
-		if rollErr := sess.Rollback(); rollErr != nil {
-			return fmt.Errorf("rolling back transaction due to error failed: %s: %w", rollErr, err)
-		}
-
-		time.Sleep(time.Millisecond * time.Duration(10))
-		ctxLogger.Info("Database locked, sleeping then retrying", "error", err, "retry", retry, "code", sqlError.Code)
-		return ss.inTransactionWithRetryCtx(ctx, engine, bus, callback, retry+1)
+		return false, err
 	}
 
 	if err != nil {
 		if rollErr := sess.Rollback(); rollErr != nil {
-			return fmt.Errorf("rolling back transaction due to error failed: %s: %w", rollErr, err)
+			return false, fmt.Errorf("rolling back transaction due to error failed: %s: %w", rollErr, err)
 		}
-		return err
+		return ss.isRetryable(err), err
 	}
 	if err := sess.Commit(); err != nil {
-		return err
+		// Serialization failures can be reported when committing.
+		return ss.isRetryable(err), err
 	}
 
 	for _, e := range sess.events {
@@ -91,5 +100,5 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 		}
 	}
 
-	return nil
+	return false, nil
 }
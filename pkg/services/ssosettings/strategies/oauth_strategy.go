@@ -67,6 +67,7 @@ func (s *OAuthStrategy) loadSettingsForProvider(provider string) *social.OAuthIn
 		AuthStyle:               section.Key("auth_style").Value(),
 		AuthUrl:                 section.Key("auth_url").Value(),
 		AutoLogin:               section.Key("auto_login").MustBool(false),
+		AutoLoginEmailDomains:   util.SplitString(section.Key("auto_login_email_domains").Value()),
 		ClientId:                section.Key("client_id").Value(),
 		ClientSecret:            section.Key("client_secret").Value(),
 		EmailAttributeName:      section.Key("email_attribute_name").Value(),
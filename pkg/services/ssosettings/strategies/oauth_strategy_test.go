@@ -19,6 +19,7 @@ var (
 	enabled = true
 	allow_sign_up = false
 	auto_login = true
+	auto_login_email_domains = corp.com, *.corp.com
 	client_id = test_client_id
 	client_secret = test_client_secret
 	scopes = ["openid", "profile", "email"]
@@ -60,6 +61,7 @@ var (
 		Enabled:                 true,
 		AllowSignup:             false,
 		AutoLogin:               true,
+		AutoLoginEmailDomains:   []string{"corp.com", "*.corp.com"},
 		ClientId:                "test_client_id",
 		ClientSecret:            "test_client_secret",
 		Scopes:                  []string{"openid", "profile", "email"},
@@ -161,6 +163,7 @@ func setupEnvVars(t *testing.T) {
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_ENABLED", "true")
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_ALLOW_SIGN_UP", "false")
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_AUTO_LOGIN", "true")
+	t.Setenv("GF_AUTH_GENERIC_OAUTH_AUTO_LOGIN_EMAIL_DOMAINS", "corp.com, *.corp.com")
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_CLIENT_ID", "test_client_id")
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_CLIENT_SECRET", "test_client_secret")
 	t.Setenv("GF_AUTH_GENERIC_OAUTH_SCOPES", `["openid", "profile", "email"]`)
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -19,6 +20,7 @@ type TeamAPI struct {
 	cfg                    *setting.Cfg
 	preferenceService      pref.Service
 	ds                     dashboards.DashboardService
+	teamTokenService       teamtoken.Service
 }
 
 func ProvideTeamAPI(
@@ -31,6 +33,7 @@ func ProvideTeamAPI(
 	cfg *setting.Cfg,
 	preferenceService pref.Service,
 	ds dashboards.DashboardService,
+	teamTokenService teamtoken.Service,
 ) *TeamAPI {
 	tapi := &TeamAPI{
 		teamService:            teamService,
@@ -40,6 +43,7 @@ func ProvideTeamAPI(
 		cfg:                    cfg,
 		preferenceService:      preferenceService,
 		ds:                     ds,
+		teamTokenService:       teamTokenService,
 	}
 
 	tapi.registerRoutes(routeRegister, acEvaluator)
@@ -69,6 +73,12 @@ func (tapi *TeamAPI) registerRoutes(router routing.RouteRegister, ac accesscontr
 				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.getTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite,
 				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.updateTeamPreferences))
+			teamsRoute.Get("/:teamId/tokens", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.listTeamTokens))
+			teamsRoute.Post("/:teamId/tokens", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.createTeamToken))
+			teamsRoute.Delete("/:teamId/tokens/:tokenId", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.deleteTeamToken))
 		}, requestmeta.SetOwner(requestmeta.TeamAuth))
 
 		// team without requirement of user to be org admin
@@ -0,0 +1,139 @@
+package teamapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /teams/{team_id}/tokens teams listTeamTokens
+//
+// Get the tokens of a team.
+//
+// Responses:
+// 200: listTeamTokensResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (tapi *TeamAPI) listTeamTokens(c *contextmodel.ReqContext) response.Response {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	tokens, err := tapi.teamTokenService.ListTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list team tokens", err)
+	}
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /teams/{team_id}/tokens teams createTeamToken
+//
+// Add a token to a team.
+//
+// The token has the permissions of the roles assigned to the team. The key of the token is only returned on creation.
+//
+// Responses:
+// 200: createTeamTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (tapi *TeamAPI) createTeamToken(c *contextmodel.ReqContext) response.Response {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	cmd := teamtoken.AddTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.TeamID = teamID
+
+	result, err := tapi.teamTokenService.AddToken(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return response.Error(http.StatusNotFound, "Team not found", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to add team token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /teams/{team_id}/tokens/{token_id} teams deleteTeamToken
+//
+// Delete a token of a team.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (tapi *TeamAPI) deleteTeamToken(c *contextmodel.ReqContext) response.Response {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "tokenId is invalid", err)
+	}
+
+	if err := tapi.teamTokenService.DeleteToken(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID, tokenID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete team token", err)
+	}
+	return response.Success("Team token deleted")
+}
+
+// swagger:parameters listTeamTokens
+type ListTeamTokensParams struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+}
+
+// swagger:parameters createTeamToken
+type CreateTeamTokenParams struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+	// in:body
+	// required:true
+	Body teamtoken.AddTokenCommand `json:"body"`
+}
+
+// swagger:parameters deleteTeamToken
+type DeleteTeamTokenParams struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+	// in:path
+	// required:true
+	TokenID int64 `json:"token_id"`
+}
+
+// swagger:response listTeamTokensResponse
+type ListTeamTokensResponse struct {
+	// in:body
+	Body []*teamtoken.TokenDTO `json:"body"`
+}
+
+// swagger:response createTeamTokenResponse
+type CreateTeamTokenResponse struct {
+	// in:body
+	Body teamtoken.NewTokenResult `json:"body"`
+}
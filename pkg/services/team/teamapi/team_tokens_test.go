@@ -0,0 +1,81 @@
+package teamapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
+	"github.com/grafana/grafana/pkg/services/teamtoken/teamtokentest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestTeamAPIEndpoint_TeamTokens(t *testing.T) {
+	setup := func(t *testing.T) (*webtest.Server, *teamtokentest.FakeService) {
+		fake := teamtokentest.NewFakeService()
+		server := SetupAPITestServer(t, func(a *TeamAPI) {
+			a.teamTokenService = fake
+		})
+		return server, fake
+	}
+	writePermission := []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsWrite, Scope: "teams:id:1"}}
+
+	t.Run("should add a token to the team", func(t *testing.T) {
+		server, fake := setup(t)
+		fake.ExpectedResult = &teamtoken.NewTokenResult{ID: 1, Name: "deploy", Key: "gltt_secret"}
+		req := webtest.RequestWithSignedInUser(
+			server.NewRequest(http.MethodPost, "/api/teams/1/tokens", strings.NewReader(`{"name": "deploy", "secondsToLive": 3600}`)),
+			authedUserWithPermissions(1, 1, writePermission),
+		)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		require.NotNil(t, fake.AddedToken)
+		assert.Equal(t, int64(1), fake.AddedToken.TeamID)
+		assert.Equal(t, int64(1), fake.AddedToken.OrgID)
+		assert.Equal(t, int64(3600), fake.AddedToken.SecondsToLive)
+	})
+
+	t.Run("should return not found for a missing team", func(t *testing.T) {
+		server, fake := setup(t)
+		fake.ExpectedError = team.ErrTeamNotFound
+		req := webtest.RequestWithSignedInUser(
+			server.NewRequest(http.MethodPost, "/api/teams/1/tokens", strings.NewReader(`{"name": "deploy"}`)),
+			authedUserWithPermissions(1, 1, writePermission),
+		)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not add a token to another team", func(t *testing.T) {
+		server, _ := setup(t)
+		req := webtest.RequestWithSignedInUser(
+			server.NewRequest(http.MethodPost, "/api/teams/2/tokens", strings.NewReader(`{"name": "deploy"}`)),
+			authedUserWithPermissions(1, 1, writePermission),
+		)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should delete a token of the team", func(t *testing.T) {
+		server, _ := setup(t)
+		req := webtest.RequestWithSignedInUser(
+			server.NewRequest(http.MethodDelete, "/api/teams/1/tokens/1", nil),
+			authedUserWithPermissions(1, 1, writePermission),
+		)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -0,0 +1,72 @@
+package teamtoken
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ServiceID identifies team tokens in the prefix of their secret, gltt_.
+const ServiceID = "tt"
+
+var (
+	ErrTokenNotFound           = errutil.NotFound("teamtoken.not-found", errutil.WithPublicMessage("Team token not found"))
+	ErrTokenNameTaken          = errutil.Conflict("teamtoken.name-taken", errutil.WithPublicMessage("A token with the same name already exists"))
+	ErrTokenInvalidExpiration  = errutil.BadRequest("teamtoken.invalid-expiration", errutil.WithPublicMessage("Number of seconds before expiration cannot be negative"))
+	ErrTokenExpirationRequired = errutil.BadRequest("teamtoken.expiration-required", errutil.WithPublicMessage("Number of seconds before expiration should be set"))
+	ErrTokenExpirationLimit    = errutil.BadRequest("teamtoken.expiration-limit", errutil.WithPublicMessage("The expiration date exceeds the limit for team tokens"))
+)
+
+// swagger:model AddTeamTokenCommand
+type AddTokenCommand struct {
+	Name          string `json:"name" binding:"Required"`
+	SecondsToLive int64  `json:"secondsToLive"`
+	OrgID         int64  `json:"-"`
+	TeamID        int64  `json:"-"`
+}
+
+// swagger:model TeamTokenDTO
+type TokenDTO struct {
+	// example: 1
+	ID int64 `json:"id"`
+	// example: 2
+	TeamID int64 `json:"teamId"`
+	// example: deploy-pipeline
+	Name string `json:"name"`
+	// example: 2022-03-23T10:31:02Z
+	Created time.Time `json:"created"`
+	// example: 2022-03-23T10:31:02Z
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// example: 2022-03-23T10:31:02Z
+	Expiration *time.Time `json:"expiration"`
+	// example: false
+	HasExpired bool `json:"hasExpired"`
+	// IsInactive is true when the token was not used for longer than the inactivity limit, it is rejected.
+	// example: false
+	IsInactive bool `json:"isInactive"`
+}
+
+// swagger:model NewTeamTokenResult
+type NewTokenResult struct {
+	// example: 1
+	ID int64 `json:"id"`
+	// example: deploy-pipeline
+	Name string `json:"name"`
+	// example: gltt_yDqgvTZP1S9VkXkVC9vxNMJfBCj5cW9v_2c4d1c5a
+	Key string `json:"key"`
+}
+
+// IsInactive returns true if the key was not used, or not created when it was
+// never used, in the last inactivityDayLimit days. The limit is disabled when
+// it is not positive.
+func IsInactive(key *apikey.APIKey, inactivityDayLimit int, now time.Time) bool {
+	if inactivityDayLimit <= 0 {
+		return false
+	}
+	lastActive := key.Created
+	if key.LastUsedAt != nil && key.LastUsedAt.After(lastActive) {
+		lastActive = *key.LastUsedAt
+	}
+	return now.Sub(lastActive) > time.Duration(inactivityDayLimit)*24*time.Hour
+}
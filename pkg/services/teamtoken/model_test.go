@@ -0,0 +1,22 @@
+package teamtoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/services/apikey"
+)
+
+func TestIsInactive(t *testing.T) {
+	now := time.Now()
+	longAgo := now.AddDate(0, 0, -40)
+	recently := now.AddDate(0, 0, -10)
+
+	assert.False(t, IsInactive(&apikey.APIKey{Created: longAgo}, 0, now), "the limit is disabled")
+	assert.True(t, IsInactive(&apikey.APIKey{Created: longAgo}, 30, now), "never used since creation")
+	assert.False(t, IsInactive(&apikey.APIKey{Created: recently}, 30, now))
+	assert.False(t, IsInactive(&apikey.APIKey{Created: longAgo, LastUsedAt: &recently}, 30, now))
+	assert.True(t, IsInactive(&apikey.APIKey{Created: longAgo, LastUsedAt: &longAgo}, 30, now))
+}
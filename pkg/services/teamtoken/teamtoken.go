@@ -0,0 +1,15 @@
+// Package teamtoken manages the API tokens owned by teams. A team token has the
+// permissions of the role assignments of its team, so that the automation shared
+// by a team does not depend on a user or a service account.
+package teamtoken
+
+import (
+	"context"
+)
+
+type Service interface {
+	ListTokens(ctx context.Context, orgID, teamID int64) ([]*TokenDTO, error)
+	// AddToken generates a token for the team. The secret of the token is only returned on creation.
+	AddToken(ctx context.Context, cmd *AddTokenCommand) (*NewTokenResult, error)
+	DeleteToken(ctx context.Context, orgID, teamID, tokenID int64) error
+}
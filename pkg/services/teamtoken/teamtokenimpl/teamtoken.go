@@ -0,0 +1,134 @@
+package teamtokenimpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	db            db.DB
+	cfg           *setting.Cfg
+	apiKeyService apikey.Service
+	teamService   team.Service
+
+	now func() time.Time
+}
+
+var _ teamtoken.Service = &Service{}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, apiKeyService apikey.Service, teamService team.Service) *Service {
+	return &Service{
+		db:            db,
+		cfg:           cfg,
+		apiKeyService: apiKeyService,
+		teamService:   teamService,
+		now:           time.Now,
+	}
+}
+
+func (s *Service) ListTokens(ctx context.Context, orgID, teamID int64) ([]*teamtoken.TokenDTO, error) {
+	keys := make([]*apikey.APIKey, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id=? AND team_id=?", orgID, teamID).Asc("name").Find(&keys)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	tokens := make([]*teamtoken.TokenDTO, 0, len(keys))
+	for _, key := range keys {
+		token := &teamtoken.TokenDTO{
+			ID:         key.ID,
+			TeamID:     teamID,
+			Name:       key.Name,
+			Created:    key.Created,
+			LastUsedAt: key.LastUsedAt,
+			IsInactive: teamtoken.IsInactive(key, s.cfg.TeamTokenInactivityDayLimit, now),
+		}
+		if key.Expires != nil {
+			expiration := time.Unix(*key.Expires, 0)
+			token.Expiration = &expiration
+			token.HasExpired = expiration.Before(now)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (s *Service) AddToken(ctx context.Context, cmd *teamtoken.AddTokenCommand) (*teamtoken.NewTokenResult, error) {
+	if err := s.checkExpiration(cmd.SecondsToLive); err != nil {
+		return nil, err
+	}
+
+	// the team must exist in the organization
+	if _, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: cmd.OrgID, ID: cmd.TeamID}); err != nil {
+		return nil, err
+	}
+
+	newKeyInfo, err := satokengen.New(teamtoken.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID := cmd.TeamID
+	key, err := s.apiKeyService.AddAPIKey(ctx, &apikey.AddCommand{
+		Name:          cmd.Name,
+		Role:          org.RoleNone,
+		OrgID:         cmd.OrgID,
+		Key:           newKeyInfo.HashedKey,
+		SecondsToLive: cmd.SecondsToLive,
+		TeamID:        &teamID,
+	})
+	if errors.Is(err, apikey.ErrDuplicate) {
+		return nil, teamtoken.ErrTokenNameTaken.Errorf("token %s already exists in org %d", cmd.Name, cmd.OrgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &teamtoken.NewTokenResult{ID: key.ID, Name: key.Name, Key: newKeyInfo.ClientSecret}, nil
+}
+
+func (s *Service) DeleteToken(ctx context.Context, orgID, teamID, tokenID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("DELETE FROM api_key WHERE id=? AND org_id=? AND team_id=?", tokenID, orgID, teamID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return teamtoken.ErrTokenNotFound.Errorf("no token %d for team %d", tokenID, teamID)
+		}
+		return nil
+	})
+}
+
+// checkExpiration enforces the expiration limit of team tokens.
+func (s *Service) checkExpiration(secondsToLive int64) error {
+	if secondsToLive < 0 {
+		return teamtoken.ErrTokenInvalidExpiration.Errorf("negative seconds to live %d", secondsToLive)
+	}
+
+	limit := s.cfg.TeamTokenExpirationDayLimit
+	if limit <= 0 {
+		return nil
+	}
+	if secondsToLive == 0 {
+		return teamtoken.ErrTokenExpirationRequired.Errorf("team tokens must expire within %d days", limit)
+	}
+	if time.Duration(secondsToLive)*time.Second > time.Duration(limit)*24*time.Hour {
+		return teamtoken.ErrTokenExpirationLimit.Errorf("team tokens must expire within %d days", limit)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package teamtokenimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/apikey/apikeyimpl"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/teamtoken"
+)
+
+func TestIntegrationTeamTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := db.InitTestDB(t)
+	apiKeyService, err := apikeyimpl.ProvideService(store, store.Cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+	teamService := &teamtest.FakeService{ExpectedTeamDTO: &team.TeamDTO{ID: 2, OrgID: 1, Name: "platform"}}
+	store.Cfg.TeamTokenExpirationDayLimit = 30
+	svc := ProvideService(store, store.Cfg, apiKeyService, teamService)
+	ctx := context.Background()
+
+	t.Run("should enforce the expiration limit", func(t *testing.T) {
+		_, err := svc.AddToken(ctx, &teamtoken.AddTokenCommand{Name: "deploy", OrgID: 1, TeamID: 2})
+		require.ErrorIs(t, err, teamtoken.ErrTokenExpirationRequired)
+
+		_, err = svc.AddToken(ctx, &teamtoken.AddTokenCommand{Name: "deploy", OrgID: 1, TeamID: 2, SecondsToLive: 31 * 24 * 3600})
+		require.ErrorIs(t, err, teamtoken.ErrTokenExpirationLimit)
+	})
+
+	var tokenID int64
+	t.Run("should add a token to the team", func(t *testing.T) {
+		result, err := svc.AddToken(ctx, &teamtoken.AddTokenCommand{Name: "deploy", OrgID: 1, TeamID: 2, SecondsToLive: 3600})
+		require.NoError(t, err)
+		assert.Contains(t, result.Key, "gltt_")
+		tokenID = result.ID
+
+		_, err = svc.AddToken(ctx, &teamtoken.AddTokenCommand{Name: "deploy", OrgID: 1, TeamID: 2, SecondsToLive: 3600})
+		require.ErrorIs(t, err, teamtoken.ErrTokenNameTaken)
+	})
+
+	t.Run("should list the tokens of the team only", func(t *testing.T) {
+		tokens, err := svc.ListTokens(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "deploy", tokens[0].Name)
+		assert.False(t, tokens[0].HasExpired)
+		require.NotNil(t, tokens[0].Expiration)
+
+		tokens, err = svc.ListTokens(ctx, 1, 3)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		keys, err := apiKeyService.GetAllAPIKeys(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, keys, "team tokens are not listed as API keys")
+	})
+
+	t.Run("should delete the token of the team", func(t *testing.T) {
+		require.ErrorIs(t, svc.DeleteToken(ctx, 1, 3, tokenID), teamtoken.ErrTokenNotFound)
+		require.NoError(t, svc.DeleteToken(ctx, 1, 2, tokenID))
+		tokens, err := svc.ListTokens(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
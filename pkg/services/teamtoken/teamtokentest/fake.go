@@ -0,0 +1,31 @@
+package teamtokentest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/teamtoken"
+)
+
+type FakeService struct {
+	ExpectedTokens []*teamtoken.TokenDTO
+	ExpectedResult *teamtoken.NewTokenResult
+	ExpectedError  error
+	AddedToken     *teamtoken.AddTokenCommand
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) ListTokens(ctx context.Context, orgID, teamID int64) ([]*teamtoken.TokenDTO, error) {
+	return f.ExpectedTokens, f.ExpectedError
+}
+
+func (f *FakeService) AddToken(ctx context.Context, cmd *teamtoken.AddTokenCommand) (*teamtoken.NewTokenResult, error) {
+	f.AddedToken = cmd
+	return f.ExpectedResult, f.ExpectedError
+}
+
+func (f *FakeService) DeleteToken(ctx context.Context, orgID, teamID, tokenID int64) error {
+	return f.ExpectedError
+}
@@ -2,6 +2,7 @@ package tempuser
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/org"
@@ -10,6 +11,11 @@ import (
 // Typed errors
 var (
 	ErrTempUserNotFound = errors.New("user not found")
+	ErrInviteExpired    = errors.New("invite has expired")
+	// ErrInviteSSORequired is returned when an invite bound to an SSO provider is accepted without logging in with it.
+	ErrInviteSSORequired = errors.New("invite must be accepted by logging in with its SSO provider")
+	ErrInviteEmailDomain = errors.New("email address is not in the domain of the invite")
+	ErrInviteEmail       = errors.New("email address is different from the address of the invite")
 )
 
 type TempUserStatus string
@@ -37,6 +43,12 @@ type TempUser struct {
 	EmailSentOn time.Time
 	Code        string
 	RemoteAddr  string
+	// SSOProvider is the auth module the invite must be accepted with, e.g. oauth_okta.
+	SSOProvider string `xorm:"sso_provider"`
+	// EmailDomain is the domain of the email address the invite must be accepted with.
+	EmailDomain string
+	// Expires is when the invite expires in unix seconds, 0 if it expires after the max lifetime of invites.
+	Expires int64
 
 	Created int64
 	Updated int64
@@ -54,6 +66,9 @@ type CreateTempUserCommand struct {
 	Code            string
 	Role            org.RoleType
 	RemoteAddr      string
+	SSOProvider     string `xorm:"sso_provider"`
+	EmailDomain     string
+	Expires         int64
 }
 
 type UpdateTempUserStatusCommand struct {
@@ -71,6 +86,11 @@ type UpdateTempUserWithEmailSentCommand struct {
 	Code string
 }
 
+type UpdateTempUserExpiresCommand struct {
+	Code    string
+	Expires int64
+}
+
 type GetTempUsersQuery struct {
 	OrgID  int64 `xorm:"org_id"`
 	Email  string
@@ -95,5 +115,34 @@ type TempUserDTO struct {
 	URL            string         `json:"url"`
 	EmailSent      bool           `json:"emailSent"`
 	EmailSentOn    time.Time      `json:"emailSentOn"`
+	SSOProvider    string         `json:"ssoProvider,omitempty" xorm:"sso_provider"`
+	EmailDomain    string         `json:"emailDomain,omitempty"`
+	Expires        int64          `json:"expires,omitempty"`
 	Created        time.Time      `json:"createdOn"`
 }
+
+// IsExpired returns true if the invite has expired, ignoring the max lifetime of the invites without expiry.
+func (u *TempUserDTO) IsExpired(now time.Time) bool {
+	return u.Expires > 0 && now.Unix() >= u.Expires
+}
+
+// CanBeAcceptedBy returns an error if the invite can not be accepted by a user with the email address,
+// logged in with the auth module.
+func (u *TempUserDTO) CanBeAcceptedBy(email string, authModule string, now time.Time) error {
+	if u.IsExpired(now) {
+		return ErrInviteExpired
+	}
+	if u.SSOProvider != "" && u.SSOProvider != authModule {
+		return ErrInviteSSORequired
+	}
+	if u.EmailDomain != "" && !HasEmailDomain(email, u.EmailDomain) {
+		return ErrInviteEmailDomain
+	}
+	return nil
+}
+
+// HasEmailDomain returns true if the email address is in the domain, ignoring the case.
+func HasEmailDomain(email string, domain string) bool {
+	at := strings.LastIndex(email, "@")
+	return at >= 0 && strings.EqualFold(email[at+1:], strings.TrimPrefix(domain, "@"))
+}
@@ -13,6 +13,7 @@ type store interface {
 	UpdateTempUserStatus(ctx context.Context, cmd *tempuser.UpdateTempUserStatusCommand) error
 	CreateTempUser(ctx context.Context, cmd *tempuser.CreateTempUserCommand) (*tempuser.TempUser, error)
 	UpdateTempUserWithEmailSent(ctx context.Context, cmd *tempuser.UpdateTempUserWithEmailSentCommand) error
+	UpdateTempUserExpires(ctx context.Context, cmd *tempuser.UpdateTempUserExpiresCommand) error
 	GetTempUsersQuery(ctx context.Context, query *tempuser.GetTempUsersQuery) ([]*tempuser.TempUserDTO, error)
 	GetTempUserByCode(ctx context.Context, query *tempuser.GetTempUserByCodeQuery) (*tempuser.TempUserDTO, error)
 	ExpireOldUserInvites(ctx context.Context, cmd *tempuser.ExpireTempUsersCommand) error
@@ -44,6 +45,9 @@ func (ss *xormStore) CreateTempUser(ctx context.Context, cmd *tempuser.CreateTem
 			Status:          cmd.Status,
 			RemoteAddr:      cmd.RemoteAddr,
 			InvitedByUserID: cmd.InvitedByUserID,
+			SSOProvider:     cmd.SSOProvider,
+			EmailDomain:     cmd.EmailDomain,
+			Expires:         cmd.Expires,
 			EmailSentOn:     time.Now(),
 			Created:         time.Now().Unix(),
 			Updated:         time.Now().Unix(),
@@ -76,6 +80,19 @@ func (ss *xormStore) UpdateTempUserWithEmailSent(ctx context.Context, cmd *tempu
 	})
 }
 
+func (ss *xormStore) UpdateTempUserExpires(ctx context.Context, cmd *tempuser.UpdateTempUserExpiresCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		user := &tempuser.TempUser{
+			Expires: cmd.Expires,
+			Updated: time.Now().Unix(),
+		}
+
+		_, err := sess.Where("code = ?", cmd.Code).Cols("expires", "updated").Update(user)
+
+		return err
+	})
+}
+
 func (ss *xormStore) GetTempUsersQuery(ctx context.Context, query *tempuser.GetTempUsersQuery) ([]*tempuser.TempUserDTO, error) {
 	queryResult := make([]*tempuser.TempUserDTO, 0)
 	err := ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
@@ -89,6 +106,9 @@ func (ss *xormStore) GetTempUsersQuery(ctx context.Context, query *tempuser.GetT
 									tu.status         as status,
 									tu.email_sent     as email_sent,
 									tu.email_sent_on  as email_sent_on,
+									tu.sso_provider   as sso_provider,
+									tu.email_domain   as email_domain,
+									tu.expires        as expires,
 									tu.created				as created,
 									u.login						as invited_by_login,
 									u.name						as invited_by_name,
@@ -137,6 +157,9 @@ func (ss *xormStore) GetTempUserByCode(ctx context.Context, query *tempuser.GetT
 									tu.status         as status,
 									tu.email_sent     as email_sent,
 									tu.email_sent_on  as email_sent_on,
+									tu.sso_provider   as sso_provider,
+									tu.email_domain   as email_domain,
+									tu.expires        as expires,
 									tu.created				as created,
 									u.login						as invited_by_login,
 									u.name						as invited_by_name,
@@ -166,8 +189,10 @@ func (ss *xormStore) GetTempUserByCode(ctx context.Context, query *tempuser.GetT
 
 func (ss *xormStore) ExpireOldUserInvites(ctx context.Context, cmd *tempuser.ExpireTempUsersCommand) error {
 	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		var rawSQL = "UPDATE temp_user SET status = ?, updated = ? WHERE created <= ? AND status in (?, ?)"
-		if result, err := sess.Exec(rawSQL, string(tempuser.TmpUserExpired), time.Now().Unix(), cmd.OlderThan.Unix(), string(tempuser.TmpUserSignUpStarted), string(tempuser.TmpUserInvitePending)); err != nil {
+		// The invites without expiry expire after the max lifetime of invites.
+		var rawSQL = "UPDATE temp_user SET status = ?, updated = ? WHERE ((expires = 0 AND created <= ?) OR (expires > 0 AND expires <= ?)) AND status in (?, ?)"
+		now := time.Now().Unix()
+		if result, err := sess.Exec(rawSQL, string(tempuser.TmpUserExpired), now, cmd.OlderThan.Unix(), now, string(tempuser.TmpUserSignUpStarted), string(tempuser.TmpUserInvitePending)); err != nil {
 			return err
 		} else if cmd.NumExpired, err = result.RowsAffected(); err != nil {
 			return err
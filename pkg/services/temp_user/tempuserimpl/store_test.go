@@ -128,4 +128,38 @@ func TestIntegrationTempUserCommandsAndQueries(t *testing.T) {
 			require.Equal(t, int64(0), cmd2.NumExpired)
 		})
 	})
+
+	t.Run("Should be able to update expires", func(t *testing.T) {
+		setup(t)
+		expires := time.Now().Add(time.Hour).Unix()
+		err := store.UpdateTempUserExpires(context.Background(), &tempuser.UpdateTempUserExpiresCommand{Code: tempUser.Code, Expires: expires})
+		require.Nil(t, err)
+
+		queryResult, err := store.GetTempUserByCode(context.Background(), &tempuser.GetTempUserByCodeQuery{Code: tempUser.Code})
+		require.Nil(t, err)
+		require.Equal(t, expires, queryResult.Expires)
+	})
+
+	t.Run("Should expire temp users by their expiry instead of their creation", func(t *testing.T) {
+		setup(t)
+		expiring := cmd
+		expiring.Code = "expiring"
+		expiring.Expires = time.Now().Add(-time.Minute).Unix()
+		_, err := store.CreateTempUser(context.Background(), &expiring)
+		require.Nil(t, err)
+		notExpiring := cmd
+		notExpiring.Code = "not-expiring"
+		notExpiring.Expires = time.Now().Add(time.Hour).Unix()
+		_, err = store.CreateTempUser(context.Background(), &notExpiring)
+		require.Nil(t, err)
+
+		cmd2 := tempuser.ExpireTempUsersCommand{OlderThan: time.Unix(tempUser.Created, 0).Add(-time.Second)}
+		err = store.ExpireOldUserInvites(context.Background(), &cmd2)
+		require.Nil(t, err)
+		require.Equal(t, int64(1), cmd2.NumExpired)
+
+		queryResult, err := store.GetTempUserByCode(context.Background(), &tempuser.GetTempUserByCodeQuery{Code: "expiring"})
+		require.Nil(t, err)
+		require.Equal(t, tempuser.TmpUserExpired, queryResult.Status)
+	})
 }
@@ -45,6 +45,14 @@ func (s *Service) UpdateTempUserWithEmailSent(ctx context.Context, cmd *tempuser
 	return nil
 }
 
+func (s *Service) UpdateTempUserExpires(ctx context.Context, cmd *tempuser.UpdateTempUserExpiresCommand) error {
+	err := s.store.UpdateTempUserExpires(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *Service) GetTempUsersQuery(ctx context.Context, cmd *tempuser.GetTempUsersQuery) ([]*tempuser.TempUserDTO, error) {
 	res, err := s.store.GetTempUsersQuery(ctx, cmd)
 	if err != nil {
@@ -0,0 +1,55 @@
+package tempusertest
+
+import (
+	"context"
+
+	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
+)
+
+var _ tempuser.Service = (*FakeTempUserService)(nil)
+
+type FakeTempUserService struct {
+	ExpectedTempUser    *tempuser.TempUser
+	ExpectedTempUserDTO *tempuser.TempUserDTO
+	ExpectedTempUsers   []*tempuser.TempUserDTO
+	ExpectedError       error
+
+	LatestStatus  tempuser.TempUserStatus
+	LatestExpires int64
+}
+
+func (f *FakeTempUserService) UpdateTempUserStatus(ctx context.Context, cmd *tempuser.UpdateTempUserStatusCommand) error {
+	f.LatestStatus = cmd.Status
+	return f.ExpectedError
+}
+
+func (f *FakeTempUserService) CreateTempUser(ctx context.Context, cmd *tempuser.CreateTempUserCommand) (*tempuser.TempUser, error) {
+	if f.ExpectedTempUser != nil {
+		return f.ExpectedTempUser, f.ExpectedError
+	}
+	return &tempuser.TempUser{OrgID: cmd.OrgID, Email: cmd.Email, Name: cmd.Name, Code: cmd.Code, SSOProvider: cmd.SSOProvider, EmailDomain: cmd.EmailDomain, Expires: cmd.Expires}, f.ExpectedError
+}
+
+func (f *FakeTempUserService) UpdateTempUserWithEmailSent(ctx context.Context, cmd *tempuser.UpdateTempUserWithEmailSentCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeTempUserService) UpdateTempUserExpires(ctx context.Context, cmd *tempuser.UpdateTempUserExpiresCommand) error {
+	f.LatestExpires = cmd.Expires
+	return f.ExpectedError
+}
+
+func (f *FakeTempUserService) GetTempUsersQuery(ctx context.Context, query *tempuser.GetTempUsersQuery) ([]*tempuser.TempUserDTO, error) {
+	return f.ExpectedTempUsers, f.ExpectedError
+}
+
+func (f *FakeTempUserService) GetTempUserByCode(ctx context.Context, query *tempuser.GetTempUserByCodeQuery) (*tempuser.TempUserDTO, error) {
+	if f.ExpectedTempUserDTO == nil {
+		return nil, tempuser.ErrTempUserNotFound
+	}
+	return f.ExpectedTempUserDTO, f.ExpectedError
+}
+
+func (f *FakeTempUserService) ExpireOldUserInvites(ctx context.Context, cmd *tempuser.ExpireTempUsersCommand) error {
+	return f.ExpectedError
+}
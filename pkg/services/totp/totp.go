@@ -0,0 +1,61 @@
+// Package totp adds a second factor to the password logins of the Grafana
+// users: the time-based one-time passwords (TOTP) of RFC 6238 generated by
+// authenticator apps, and single-use recovery codes for when the app is lost.
+package totp
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrCodeRequired       = errutil.Unauthorized("totp.code-required", errutil.WithPublicMessage("Enter the code of your authenticator app or a recovery code"))
+	ErrInvalidCode        = errutil.Unauthorized("totp.invalid-code", errutil.WithPublicMessage("Invalid authentication code"))
+	ErrEnrollmentRequired = errutil.Unauthorized("totp.enrollment-required", errutil.WithPublicMessage("Your organization requires two-factor authentication. Add the secret sent to your email address to your authenticator app and enter its code"))
+	ErrAlreadyEnabled     = errutil.Conflict("totp.already-enabled", errutil.WithPublicMessage("Two-factor authentication is already enabled"))
+	ErrNotEnrolled        = errutil.NotFound("totp.not-enrolled", errutil.WithPublicMessage("Two-factor authentication is not enabled"))
+	ErrRequiredByOrg      = errutil.Forbidden("totp.required-by-org", errutil.WithPublicMessage("Two-factor authentication is required by one of your organizations"))
+)
+
+type Service interface {
+	// VerifyLogin verifies the second factor of a password login of a user
+	// before its session is created. It returns ErrCodeRequired if the user
+	// enabled two-factor authentication and code is empty, and
+	// ErrEnrollmentRequired if an organization of the user requires it and
+	// the user did not enable it yet. The secret to enroll is emailed to the
+	// user, and entering a valid code of this secret completes the enrollment.
+	VerifyLogin(ctx context.Context, userID int64, code string) error
+	// IsRequired returns true if the password of the user is not enough to
+	// authenticate it: the user enabled two-factor authentication, or an
+	// organization of the user requires it.
+	IsRequired(ctx context.Context, userID int64) (bool, error)
+}
+
+// Status is the two-factor authentication status of a user.
+type Status struct {
+	// Enabled is true once the user confirmed its enrollment with a code.
+	Enabled bool `json:"enabled"`
+	// Required is true if an organization of the user requires two-factor authentication.
+	Required bool `json:"required"`
+	// RecoveryCodesRemaining is the number of recovery codes the user did not use yet.
+	RecoveryCodesRemaining int `json:"recoveryCodesRemaining"`
+}
+
+// Enrollment is the secret of a pending enrollment, to add to an authenticator app.
+type Enrollment struct {
+	// Secret is the base32 secret, to enter in the authenticator apps that cannot scan QR codes.
+	Secret string `json:"secret"`
+	// URL is the otpauth URI of the secret, to render as a QR code scanned by the authenticator app.
+	URL string `json:"url"`
+}
+
+// RecoveryCodes are single-use codes that replace the code of the authenticator app, shown once.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// CodeCommand is a code of the authenticator app, or a recovery code.
+type CodeCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
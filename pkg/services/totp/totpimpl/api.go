@@ -0,0 +1,217 @@
+package totpimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/totp"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+	router.Group("/api/user/totp", func(totpRoute routing.RouteRegister) {
+		totpRoute.Get("/", routing.Wrap(s.getStatus))
+		totpRoute.Post("/enroll", routing.Wrap(s.enroll))
+		totpRoute.Post("/confirm", routing.Wrap(s.confirmEnrollment))
+		totpRoute.Post("/recovery-codes", routing.Wrap(s.regenerateRecoveryCodes))
+		totpRoute.Post("/disable", routing.Wrap(s.disable))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	userIDScope := accesscontrol.Scope("global.users", "id", accesscontrol.Parameter(":id"))
+	router.Delete("/api/admin/users/:id/totp", middleware.ReqSignedInNoAnonymous,
+		authorize(accesscontrol.EvalPermission(accesscontrol.ActionUsersWrite, userIDScope)), routing.Wrap(s.resetUser))
+}
+
+// swagger:route GET /user/totp signed_in_user getUserTOTPStatus
+//
+// Get the two-factor authentication status of the signed in user.
+//
+// Responses:
+// 200: getUserTOTPStatusResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) getStatus(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	status, err := s.Status(c.Req.Context(), userID)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /user/totp/enroll signed_in_user enrollUserTOTP
+//
+// Start the two-factor authentication enrollment of the signed in user.
+//
+// Returns a new secret to add to an authenticator app, and its otpauth URI to render as a QR code. Two-factor
+// authentication is enabled once the enrollment is confirmed with a code of the secret.
+//
+// Responses:
+// 200: enrollUserTOTPResponse
+// 401: unauthorisedError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) enroll(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	enrollment, err := s.Enroll(c.Req.Context(), userID)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// swagger:route POST /user/totp/confirm signed_in_user confirmUserTOTP
+//
+// Confirm the two-factor authentication enrollment of the signed in user.
+//
+// Enables two-factor authentication with a code of the secret of the enrollment, and returns the recovery codes.
+// The recovery codes are only shown once.
+//
+// Responses:
+// 200: userTOTPRecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) confirmEnrollment(c *contextmodel.ReqContext) response.Response {
+	cmd := totp.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	codes, err := s.Confirm(c.Req.Context(), userID, cmd.Code)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, codes)
+}
+
+// swagger:route POST /user/totp/recovery-codes signed_in_user regenerateUserTOTPRecoveryCodes
+//
+// Replace the recovery codes of the signed in user.
+//
+// Requires a code of the authenticator app or a recovery code. The previous recovery codes can no longer be used.
+//
+// Responses:
+// 200: userTOTPRecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) regenerateRecoveryCodes(c *contextmodel.ReqContext) response.Response {
+	cmd := totp.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	codes, err := s.RegenerateRecoveryCodes(c.Req.Context(), userID, cmd.Code)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, codes)
+}
+
+// swagger:route POST /user/totp/disable signed_in_user disableUserTOTP
+//
+// Disable the two-factor authentication of the signed in user.
+//
+// Requires a code of the authenticator app or a recovery code. Fails when an organization of the user requires
+// two-factor authentication.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) disable(c *contextmodel.ReqContext) response.Response {
+	cmd := totp.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	if err := s.Disable(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Two-factor authentication disabled")
+}
+
+// swagger:route DELETE /admin/users/{user_id}/totp admin_users adminResetUserTOTP
+//
+// Reset the two-factor authentication of a user.
+//
+// Removes the enrollment of the user, for example when the user lost its authenticator app and its recovery codes.
+// If an organization of the user requires two-factor authentication, the user enrolls again at the next login.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) resetUser(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.Reset(c.Req.Context(), userID); err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Two-factor authentication reset")
+}
+
+// swagger:parameters confirmUserTOTP regenerateUserTOTPRecoveryCodes disableUserTOTP
+type UserTOTPCodeParams struct {
+	// in:body
+	// required:true
+	Body totp.CodeCommand `json:"body"`
+}
+
+// swagger:parameters adminResetUserTOTP
+type AdminResetUserTOTPParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:response getUserTOTPStatusResponse
+type GetUserTOTPStatusResponse struct {
+	// in:body
+	Body totp.Status `json:"body"`
+}
+
+// swagger:response enrollUserTOTPResponse
+type EnrollUserTOTPResponse struct {
+	// in:body
+	Body totp.Enrollment `json:"body"`
+}
+
+// swagger:response userTOTPRecoveryCodesResponse
+type UserTOTPRecoveryCodesResponse struct {
+	// in:body
+	Body totp.RecoveryCodes `json:"body"`
+}
@@ -0,0 +1,98 @@
+package totpimpl
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- HMAC-SHA1 is the algorithm of RFC 6238 supported by all authenticator apps.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// period is the number of seconds a code is valid for.
+	period = 30
+	// digits is the number of digits of a code.
+	digits = 6
+	// skew is the number of periods before and after the current one whose codes are accepted, to allow for clock
+	// drift between the server and the authenticator app.
+	skew = 1
+	// secretSize is the size of the secrets in bytes, as recommended by RFC 4226.
+	secretSize = 20
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateSecret returns a random secret, encoded in base32 as authenticator apps expect it.
+func generateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// counter returns the number of periods since the Unix epoch at t.
+func counter(t time.Time) int64 {
+	return t.Unix() / period
+}
+
+// generateCode returns the code of a secret for a counter, as defined by RFC 4226.
+func generateCode(secret string, counter int64) (string, error) {
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, value%1000000), nil
+}
+
+// validateCode returns the counter of the code of the secret around t, and false if the code is not valid or if
+// its counter is not after lastCounter, so that a code cannot be used twice.
+func validateCode(secret, code string, t time.Time, lastCounter int64) (int64, bool) {
+	if len(code) != digits {
+		return 0, false
+	}
+	now := counter(t)
+	for c := now - skew; c <= now+skew; c++ {
+		if c <= lastCounter {
+			continue
+		}
+		expected, err := generateCode(secret, c)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// keyURI returns the otpauth URI of a secret, which authenticator apps read from a QR code.
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func keyURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(digits))
+	params.Set("period", fmt.Sprint(period))
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: params.Encode(),
+	}).String()
+}
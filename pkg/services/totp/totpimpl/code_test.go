@@ -0,0 +1,78 @@
+package totpimpl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the secret of the test vectors of RFC 6238, "12345678901234567890" encoded in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	// The 6 last digits of the SHA-1 test vectors of RFC 6238.
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1111111111, code: "050471"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	}
+	for _, tt := range tests {
+		code, err := generateCode(rfcSecret, counter(time.Unix(tt.time, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, tt.time)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	c := counter(now)
+
+	t.Run("accepts the codes of the adjacent periods", func(t *testing.T) {
+		for _, offset := range []int64{-1, 0, 1} {
+			code, err := generateCode(rfcSecret, c+offset)
+			require.NoError(t, err)
+			got, ok := validateCode(rfcSecret, code, now, 0)
+			assert.True(t, ok, offset)
+			assert.Equal(t, c+offset, got)
+		}
+	})
+
+	t.Run("rejects the codes of other periods", func(t *testing.T) {
+		code, err := generateCode(rfcSecret, c+2)
+		require.NoError(t, err)
+		_, ok := validateCode(rfcSecret, code, now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects the codes already used", func(t *testing.T) {
+		code, err := generateCode(rfcSecret, c)
+		require.NoError(t, err)
+		_, ok := validateCode(rfcSecret, code, now, c)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects malformed codes", func(t *testing.T) {
+		for _, code := range []string{"", "12345", "1234567"} {
+			_, ok := validateCode(rfcSecret, code, now, 0)
+			assert.False(t, ok, code)
+		}
+	})
+}
+
+func TestKeyURI(t *testing.T) {
+	u, err := url.Parse(keyURI("Grafana", "alice", rfcSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Grafana:alice", u.Path)
+	assert.Equal(t, rfcSecret, u.Query().Get("secret"))
+	assert.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
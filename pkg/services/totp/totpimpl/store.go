@@ -0,0 +1,87 @@
+package totpimpl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/totp"
+)
+
+// enrollment is the TOTP secret of a user and the hashes of its remaining recovery codes.
+type enrollment struct {
+	ID     int64 `xorm:"pk autoincr 'id'"`
+	UserID int64 `xorm:"user_id"`
+	// Secret is the encrypted secret, encoded in base64.
+	Secret string `xorm:"secret"`
+	// Enabled is false until the user confirms the enrollment with a code of the secret.
+	Enabled bool `xorm:"enabled"`
+	// LastCounter is the counter of the last code used, which cannot be used again.
+	LastCounter int64 `xorm:"last_counter"`
+	// RecoveryCodes are the comma separated SHA-256 hashes of the unused recovery codes, encoded in hexadecimal.
+	RecoveryCodes string `xorm:"recovery_codes"`
+	Created       int64  `xorm:"'created'"`
+	Updated       int64  `xorm:"'updated'"`
+}
+
+func (e enrollment) TableName() string { return "user_totp" }
+
+func (e *enrollment) recoveryCodes() []string {
+	if e.RecoveryCodes == "" {
+		return nil
+	}
+	return strings.Split(e.RecoveryCodes, ",")
+}
+
+func (e *enrollment) setRecoveryCodes(hashes []string) {
+	e.RecoveryCodes = strings.Join(hashes, ",")
+}
+
+type store interface {
+	Get(ctx context.Context, userID int64) (*enrollment, error)
+	// Save inserts or updates the enrollment of a user.
+	Save(ctx context.Context, e *enrollment) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Get(ctx context.Context, userID int64) (*enrollment, error) {
+	var e enrollment
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("user_id = ?", userID).Get(&e)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return totp.ErrNotEnrolled.Errorf("no TOTP enrollment for user %d", userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (s *sqlStore) Save(ctx context.Context, e *enrollment) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if e.ID == 0 {
+			_, err := sess.Insert(e)
+			return err
+		}
+		_, err := sess.ID(e.ID).AllCols().Update(e)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_totp WHERE user_id = ?", userID)
+		return err
+	})
+}
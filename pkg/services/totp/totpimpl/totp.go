@@ -0,0 +1,411 @@
+package totpimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/totp"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	// loginEnrollmentTTL is how long the secret emailed to a user at login can complete its enrollment.
+	loginEnrollmentTTL = time.Hour
+	tmplEnrollment     = "totp_enrollment"
+)
+
+type Service struct {
+	enabled       bool
+	issuer        string
+	store         store
+	secrets       secrets.Service
+	orgService    org.Service
+	loginSettings loginsettings.Service
+	userService   user.Service
+	emailSender   notifications.EmailSender
+	log           log.Logger
+
+	now func() time.Time
+}
+
+var _ totp.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	secretsService secrets.Service, orgService org.Service, loginSettings loginsettings.Service, userService user.Service,
+	emailSender notifications.EmailSender) *Service {
+	section := cfg.SectionWithEnvOverrides("auth.totp")
+	s := &Service{
+		enabled:       section.Key("enabled").MustBool(false),
+		issuer:        section.Key("issuer").MustString("Grafana"),
+		store:         &sqlStore{db: db},
+		secrets:       secretsService,
+		orgService:    orgService,
+		loginSettings: loginSettings,
+		userService:   userService,
+		emailSender:   emailSender,
+		log:           log.New("totp"),
+		now:           time.Now,
+	}
+	if s.enabled {
+		s.registerAPIEndpoints(routeRegister, ac)
+	}
+	return s
+}
+
+func (s *Service) VerifyLogin(ctx context.Context, userID int64, code string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	e, err := s.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, totp.ErrNotEnrolled) {
+		return err
+	}
+	if e != nil && e.Enabled {
+		if code == "" {
+			return totp.ErrCodeRequired.Errorf("user %d has two-factor authentication enabled", userID)
+		}
+		return s.verify(ctx, e, code)
+	}
+
+	required, err := s.isRequired(ctx, userID)
+	if err != nil || !required {
+		return err
+	}
+
+	// The user completes the enrollment required by its organization with a code of the pending secret. The secret
+	// is emailed to the user rather than returned, so that its password is not enough to enroll.
+	pending := e != nil && s.now().Before(time.Unix(e.Updated, 0).Add(loginEnrollmentTTL))
+	if pending && code != "" {
+		_, err := s.confirm(ctx, e, code, false)
+		return err
+	}
+	if !pending {
+		if err := s.sendEnrollment(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return totp.ErrEnrollmentRequired.Errorf("an organization of user %d requires two-factor authentication", userID)
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	if !s.enabled {
+		return false, nil
+	}
+	status, err := s.Status(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return status.Enabled || status.Required, nil
+}
+
+// Status returns the two-factor authentication status of a user.
+func (s *Service) Status(ctx context.Context, userID int64) (*totp.Status, error) {
+	required, err := s.isRequired(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &totp.Status{Required: required}
+
+	e, err := s.store.Get(ctx, userID)
+	if errors.Is(err, totp.ErrNotEnrolled) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = e.Enabled
+	if e.Enabled {
+		status.RecoveryCodesRemaining = len(e.recoveryCodes())
+	}
+	return status, nil
+}
+
+// Enroll starts the enrollment of a user with a new secret, replacing the secret of a pending enrollment.
+func (s *Service) Enroll(ctx context.Context, userID int64) (*totp.Enrollment, error) {
+	e, err := s.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, totp.ErrNotEnrolled) {
+		return nil, err
+	}
+	if e != nil && e.Enabled {
+		return nil, totp.ErrAlreadyEnabled.Errorf("user %d has two-factor authentication enabled", userID)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		e = &enrollment{UserID: userID, Created: s.now().Unix()}
+	}
+	if e.Secret, err = s.encrypt(ctx, secret); err != nil {
+		return nil, err
+	}
+	e.LastCounter, e.Updated = 0, s.now().Unix()
+	if err := s.store.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return s.enrollmentOf(ctx, e, userID)
+}
+
+// Confirm enables the two-factor authentication of a user with a code of the pending secret, and returns its
+// recovery codes.
+func (s *Service) Confirm(ctx context.Context, userID int64, code string) (*totp.RecoveryCodes, error) {
+	e, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Enabled {
+		return nil, totp.ErrAlreadyEnabled.Errorf("user %d has two-factor authentication enabled", userID)
+	}
+	return s.confirm(ctx, e, code, true)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user once it entered a valid code.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*totp.RecoveryCodes, error) {
+	e, err := s.enabledEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(ctx, e, code); err != nil {
+		return nil, err
+	}
+	codes, err := s.newRecoveryCodes(e)
+	if err != nil {
+		return nil, err
+	}
+	e.Updated = s.now().Unix()
+	if err := s.store.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable disables the two-factor authentication of a user once it entered a valid code, unless an organization of
+// the user requires it.
+func (s *Service) Disable(ctx context.Context, userID int64, code string) error {
+	e, err := s.enabledEnrollment(ctx, userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.isRequired(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return totp.ErrRequiredByOrg.Errorf("an organization of user %d requires two-factor authentication", userID)
+	}
+	if err := s.verify(ctx, e, code); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, userID)
+}
+
+// Reset removes the enrollment of a user, for example when it lost its authenticator app and its recovery codes.
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	return s.store.Delete(ctx, userID)
+}
+
+func (s *Service) enabledEnrollment(ctx context.Context, userID int64) (*enrollment, error) {
+	e, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Enabled {
+		return nil, totp.ErrNotEnrolled.Errorf("the enrollment of user %d is not confirmed", userID)
+	}
+	return e, nil
+}
+
+// sendEnrollment starts the enrollment of a user with a new secret and emails the secret to the user.
+func (s *Service) sendEnrollment(ctx context.Context, userID int64) error {
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return err
+	}
+	if usr.Email == "" {
+		s.log.FromContext(ctx).Warn("Cannot send the two-factor authentication secret to a user without email address", "userID", userID)
+		return nil
+	}
+
+	enrollment, err := s.Enroll(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = s.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{usr.Email},
+		Template: tmplEnrollment,
+		UserID:   usr.ID,
+		Data: map[string]any{
+			"Name":      usr.NameOrFallback(),
+			"Secret":    enrollment.Secret,
+			"Url":       enrollment.URL,
+			"ExpiresIn": fmt.Sprintf("%d minutes", int(loginEnrollmentTTL.Minutes())),
+		},
+	})
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to send the two-factor authentication secret", "userID", userID, "error", err)
+		// The next login sends a new secret.
+		return s.store.Delete(ctx, userID)
+	}
+	return nil
+}
+
+// enrollmentOf returns the secret of an enrollment and its otpauth URI.
+func (s *Service) enrollmentOf(ctx context.Context, e *enrollment, userID int64) (*totp.Enrollment, error) {
+	secret, err := s.decrypt(ctx, e.Secret)
+	if err != nil {
+		return nil, err
+	}
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return &totp.Enrollment{Secret: secret, URL: keyURI(s.issuer, usr.Login, secret)}, nil
+}
+
+// confirm enables a pending enrollment with a code of its secret. The recovery codes are only generated when
+// withRecoveryCodes is true, as the login responses cannot show them.
+func (s *Service) confirm(ctx context.Context, e *enrollment, code string, withRecoveryCodes bool) (*totp.RecoveryCodes, error) {
+	secret, err := s.decrypt(ctx, e.Secret)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := validateCode(secret, code, s.now(), e.LastCounter)
+	if !ok {
+		return nil, totp.ErrInvalidCode.Errorf("invalid TOTP code for the enrollment of user %d", e.UserID)
+	}
+
+	e.Enabled, e.LastCounter, e.Updated = true, c, s.now().Unix()
+	var codes *totp.RecoveryCodes
+	if withRecoveryCodes {
+		if codes, err = s.newRecoveryCodes(e); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.store.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verify checks a code of the authenticator app or an unused recovery code of an enabled enrollment, and records
+// that the code was used.
+func (s *Service) verify(ctx context.Context, e *enrollment, code string) error {
+	secret, err := s.decrypt(ctx, e.Secret)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := validateCode(secret, strings.TrimSpace(code), s.now(), e.LastCounter); ok {
+		e.LastCounter, e.Updated = c, s.now().Unix()
+		return s.store.Save(ctx, e)
+	}
+
+	hash := hashRecoveryCode(code)
+	hashes := e.recoveryCodes()
+	for i, h := range hashes {
+		if h == hash {
+			e.setRecoveryCodes(append(hashes[:i:i], hashes[i+1:]...))
+			e.Updated = s.now().Unix()
+			s.log.FromContext(ctx).Info("Recovery code used", "userId", e.UserID, "remaining", len(hashes)-1)
+			return s.store.Save(ctx, e)
+		}
+	}
+	return totp.ErrInvalidCode.Errorf("invalid TOTP or recovery code for user %d", e.UserID)
+}
+
+// isRequired returns true if an organization of the user requires two-factor authentication in its login settings.
+func (s *Service) isRequired(ctx context.Context, userID int64) (bool, error) {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		settings, err := s.loginSettings.Get(ctx, o.OrgID)
+		if errors.Is(err, loginsettings.ErrLoginSettingsNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if settings.RequireTOTP {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newRecoveryCodes replaces the recovery codes of an enrollment and returns them.
+func (s *Service) newRecoveryCodes(e *enrollment) (*totp.RecoveryCodes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code := make([]byte, recoveryCodeLength)
+		for j := range code {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+			if err != nil {
+				return nil, err
+			}
+			code[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+		formatted := string(code[:recoveryCodeLength/2]) + "-" + string(code[recoveryCodeLength/2:])
+		codes = append(codes, formatted)
+		hashes = append(hashes, hashRecoveryCode(formatted))
+	}
+	e.setRecoveryCodes(hashes)
+	return &totp.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// hashRecoveryCode returns the hash of a recovery code, ignoring its case, its spaces and its dashes.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) encrypt(ctx context.Context, secret string) (string, error) {
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (s *Service) decrypt(ctx context.Context, secret string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
@@ -0,0 +1,201 @@
+package totpimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/loginsettings"
+	"github.com/grafana/grafana/pkg/services/loginsettings/loginsettingstest"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/totp"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+type testService struct {
+	*Service
+	loginSettings *loginsettingstest.FakeLoginSettingsService
+	userService   *usertest.FakeUserService
+	emailSender   *notifications.NotificationServiceMock
+	clock         time.Time
+}
+
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.Raw.Section("auth.totp").Key("enabled").SetValue("true")
+	loginSettings := loginsettingstest.NewFakeLoginSettingsService()
+	orgService := &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}}}
+	userService := &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "alice", Email: "alice@example.com"}}
+	emailSender := notifications.MockNotificationService()
+
+	s := &testService{loginSettings: loginSettings, userService: userService, emailSender: emailSender, clock: time.Unix(1700000000, 0)}
+	s.Service = ProvideService(cfg, db.InitTestDB(t), routing.NewRouteRegister(), acimpl.ProvideAccessControl(cfg),
+		fakes.NewFakeSecretsService(), orgService, loginSettings, userService, emailSender)
+	s.now = func() time.Time { return s.clock }
+	return s
+}
+
+// code returns the current code of a secret, after moving the clock to the next period so that the code was not
+// used yet.
+func (s *testService) code(t *testing.T, secret string) string {
+	t.Helper()
+	s.clock = s.clock.Add(period * time.Second)
+	code, err := generateCode(secret, counter(s.clock))
+	require.NoError(t, err)
+	return code
+}
+
+func TestIntegrationTOTP(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		s := newTestService(t)
+		s.enabled = false
+		s.loginSettings.ExpectedSettings = &loginsettings.LoginSettings{RequireTOTP: true}
+		require.NoError(t, s.VerifyLogin(ctx, 1, ""))
+	})
+
+	t.Run("does not require a code from users who did not enroll", func(t *testing.T) {
+		s := newTestService(t)
+		require.NoError(t, s.VerifyLogin(ctx, 1, ""))
+
+		enrollment, err := s.Enroll(ctx, 1)
+		require.NoError(t, err)
+		assert.Contains(t, enrollment.URL, enrollment.Secret)
+		require.NoError(t, s.VerifyLogin(ctx, 1, ""), "pending enrollments are not enforced")
+	})
+
+	t.Run("requires a second factor from enrolled users and users whose organization requires it", func(t *testing.T) {
+		s := newTestService(t)
+		required, err := s.IsRequired(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, required)
+
+		enrollment, err := s.Enroll(ctx, 1)
+		require.NoError(t, err)
+		_, err = s.Confirm(ctx, 1, s.code(t, enrollment.Secret))
+		require.NoError(t, err)
+		required, err = s.IsRequired(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, required)
+
+		other := newTestService(t)
+		other.loginSettings.ExpectedSettings = &loginsettings.LoginSettings{OrgID: 1, RequireTOTP: true}
+		required, err = other.IsRequired(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, required)
+	})
+
+	t.Run("enrolls, verifies and disables", func(t *testing.T) {
+		s := newTestService(t)
+		enrollment, err := s.Enroll(ctx, 1)
+		require.NoError(t, err)
+
+		_, err = s.Confirm(ctx, 1, "000000")
+		require.ErrorIs(t, err, totp.ErrInvalidCode)
+		codes, err := s.Confirm(ctx, 1, s.code(t, enrollment.Secret))
+		require.NoError(t, err)
+		require.Len(t, codes.RecoveryCodes, recoveryCodeCount)
+
+		_, err = s.Enroll(ctx, 1)
+		require.ErrorIs(t, err, totp.ErrAlreadyEnabled)
+
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrCodeRequired)
+		code := s.code(t, enrollment.Secret)
+		require.NoError(t, s.VerifyLogin(ctx, 1, code))
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, code), totp.ErrInvalidCode, "codes cannot be used twice")
+
+		require.NoError(t, s.Disable(ctx, 1, s.code(t, enrollment.Secret)))
+		require.NoError(t, s.VerifyLogin(ctx, 1, ""))
+	})
+
+	t.Run("accepts each recovery code once", func(t *testing.T) {
+		s := newTestService(t)
+		enrollment, err := s.Enroll(ctx, 1)
+		require.NoError(t, err)
+		codes, err := s.Confirm(ctx, 1, s.code(t, enrollment.Secret))
+		require.NoError(t, err)
+
+		recoveryCode := codes.RecoveryCodes[0]
+		require.NoError(t, s.VerifyLogin(ctx, 1, " "+recoveryCode[:5]+recoveryCode[6:]+" "))
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, recoveryCode), totp.ErrInvalidCode)
+
+		status, err := s.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &totp.Status{Enabled: true, RecoveryCodesRemaining: recoveryCodeCount - 1}, status)
+
+		regenerated, err := s.RegenerateRecoveryCodes(ctx, 1, codes.RecoveryCodes[1])
+		require.NoError(t, err)
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, codes.RecoveryCodes[2]), totp.ErrInvalidCode)
+		require.NoError(t, s.VerifyLogin(ctx, 1, regenerated.RecoveryCodes[2]))
+	})
+
+	t.Run("enrolls at login when an organization requires it", func(t *testing.T) {
+		s := newTestService(t)
+		s.loginSettings.ExpectedSettings = &loginsettings.LoginSettings{OrgID: 1, RequireTOTP: true}
+
+		err := s.VerifyLogin(ctx, 1, "")
+		require.ErrorIs(t, err, totp.ErrEnrollmentRequired)
+		var gfErr errutil.Error
+		require.ErrorAs(t, err, &gfErr)
+		assert.Empty(t, gfErr.PublicPayload, "the secret is only sent by email")
+		assert.Equal(t, tmplEnrollment, s.emailSender.Email.Template)
+		assert.Equal(t, []string{"alice@example.com"}, s.emailSender.Email.To)
+		secret := s.emailSender.Email.Data["Secret"].(string)
+		assert.Contains(t, s.emailSender.Email.Data["Url"], secret)
+
+		s.emailSender.Email = notifications.SendEmailCommand{}
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrEnrollmentRequired)
+		assert.Empty(t, s.emailSender.Email.To, "the pending secret is not sent again")
+
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, "000000"), totp.ErrInvalidCode)
+		require.NoError(t, s.VerifyLogin(ctx, 1, s.code(t, secret)))
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrCodeRequired)
+
+		require.ErrorIs(t, s.Disable(ctx, 1, s.code(t, secret)), totp.ErrRequiredByOrg)
+		require.NoError(t, s.Reset(ctx, 1))
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrEnrollmentRequired)
+	})
+
+	t.Run("sends a new secret once the emailed secret expired", func(t *testing.T) {
+		s := newTestService(t)
+		s.loginSettings.ExpectedSettings = &loginsettings.LoginSettings{OrgID: 1, RequireTOTP: true}
+
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrEnrollmentRequired)
+		secret := s.emailSender.Email.Data["Secret"].(string)
+
+		s.clock = s.clock.Add(loginEnrollmentTTL)
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, s.code(t, secret)), totp.ErrEnrollmentRequired)
+		newSecret := s.emailSender.Email.Data["Secret"].(string)
+		assert.NotEqual(t, secret, newSecret)
+		require.NoError(t, s.VerifyLogin(ctx, 1, s.code(t, newSecret)))
+	})
+
+	t.Run("does not enroll users without email address at login", func(t *testing.T) {
+		s := newTestService(t)
+		s.loginSettings.ExpectedSettings = &loginsettings.LoginSettings{OrgID: 1, RequireTOTP: true}
+		s.userService.ExpectedUser.Email = ""
+
+		require.ErrorIs(t, s.VerifyLogin(ctx, 1, ""), totp.ErrEnrollmentRequired)
+		assert.Empty(t, s.emailSender.Email.To)
+		status, err := s.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, status.Enabled)
+	})
+}
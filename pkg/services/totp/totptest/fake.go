@@ -0,0 +1,29 @@
+package totptest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/totp"
+)
+
+type FakeService struct {
+	ExpectedError    error
+	ExpectedRequired bool
+	// Codes are the codes of the verified logins.
+	Codes []string
+}
+
+var _ totp.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) VerifyLogin(_ context.Context, _ int64, code string) error {
+	f.Codes = append(f.Codes, code)
+	return f.ExpectedError
+}
+
+func (f *FakeService) IsRequired(_ context.Context, _ int64) (bool, error) {
+	return f.ExpectedRequired, f.ExpectedError
+}
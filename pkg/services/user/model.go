@@ -169,6 +169,11 @@ type DisableUserCommand struct {
 	IsDisabled bool
 }
 
+type SetEmailVerifiedCommand struct {
+	UserID        int64
+	EmailVerified bool
+}
+
 type BatchDisableUsersCommand struct {
 	UserIDs    []int64 `xorm:"user_ids"`
 	IsDisabled bool
@@ -24,6 +24,7 @@ type Service interface {
 	Search(context.Context, *SearchUsersQuery) (*SearchUserQueryResult, error)
 	Disable(context.Context, *DisableUserCommand) error
 	BatchDisableUsers(context.Context, *BatchDisableUsersCommand) error
+	SetEmailVerified(context.Context, *SetEmailVerifiedCommand) error
 	UpdatePermissions(context.Context, int64, bool) error
 	SetUserHelpFlag(context.Context, *SetUserHelpFlagCommand) error
 	GetProfile(context.Context, *GetUserProfileQuery) (*UserProfileDTO, error)
@@ -37,6 +37,7 @@ type store interface {
 	UpdatePermissions(context.Context, int64, bool) error
 	BatchDisableUsers(context.Context, *user.BatchDisableUsersCommand) error
 	Disable(context.Context, *user.DisableUserCommand) error
+	SetEmailVerified(context.Context, *user.SetEmailVerifiedCommand) error
 	Search(context.Context, *user.SearchUsersQuery) (*user.SearchUserQueryResult, error)
 
 	Count(ctx context.Context) (int64, error)
@@ -608,6 +609,25 @@ func (ss *sqlStore) Disable(ctx context.Context, cmd *user.DisableUserCommand) e
 	})
 }
 
+func (ss *sqlStore) SetEmailVerified(ctx context.Context, cmd *user.SetEmailVerifiedCommand) error {
+	return ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
+		usr := user.User{}
+		sess := dbSess.Table("user")
+
+		if has, err := sess.ID(cmd.UserID).Where(ss.notServiceAccountFilter()).Get(&usr); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		usr.EmailVerified = cmd.EmailVerified
+		sess.UseBool("email_verified")
+
+		_, err := sess.ID(cmd.UserID).Cols("email_verified").Update(&usr)
+		return err
+	})
+}
+
 func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*user.SearchUserQueryResult, error) {
 	result := user.SearchUserQueryResult{
 		Users: make([]*user.UserSearchHitDTO, 0),
@@ -622,11 +642,11 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 		whereConditions = append(whereConditions, "u.is_service_account = ?")
 		whereParams = append(whereParams, ss.dialect.BooleanStr(false))
 
-		// Join with only most recent auth module
+		// Join with only the primary or most recent auth module
 		joinCondition := `(
 		SELECT id from user_auth
 			WHERE user_auth.user_id = u.id
-			ORDER BY user_auth.created DESC `
+			ORDER BY user_auth.is_primary DESC, user_auth.created DESC `
 		joinCondition = "user_auth.id=" + joinCondition + ss.dialect.Limit(1) + ")"
 		sess.Join("LEFT", "user_auth", joinCondition)
 		if query.OrgID > 0 {
@@ -691,6 +691,26 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		}
 	})
 
+	t.Run("Testing DB - set email verified", func(t *testing.T) {
+		usr, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{
+			Email:         "verified@test.com",
+			Login:         "verified",
+			EmailVerified: true,
+		})
+		require.NoError(t, err)
+
+		err = userStore.SetEmailVerified(context.Background(), &user.SetEmailVerifiedCommand{UserID: usr.ID, EmailVerified: false})
+		require.NoError(t, err)
+
+		stored, err := userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.False(t, stored.EmailVerified)
+		require.Equal(t, "verified@test.com", stored.Email)
+
+		err = userStore.SetEmailVerified(context.Background(), &user.SetEmailVerifiedCommand{UserID: 9999, EmailVerified: false})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
 	ss = db.InitTestDB(t)
 
 	t.Run("Testing DB - search users", func(t *testing.T) {
@@ -361,6 +361,10 @@ func (s *Service) BatchDisableUsers(ctx context.Context, cmd *user.BatchDisableU
 	return s.store.BatchDisableUsers(ctx, cmd)
 }
 
+func (s *Service) SetEmailVerified(ctx context.Context, cmd *user.SetEmailVerifiedCommand) error {
+	return s.store.SetEmailVerified(ctx, cmd)
+}
+
 func (s *Service) UpdatePermissions(ctx context.Context, userID int64, isAdmin bool) error {
 	return s.store.UpdatePermissions(ctx, userID, isAdmin)
 }
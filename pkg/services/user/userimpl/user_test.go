@@ -319,6 +319,10 @@ func (f *FakeUserStore) Disable(ctx context.Context, cmd *user.DisableUserComman
 	return f.ExpectedError
 }
 
+func (f *FakeUserStore) SetEmailVerified(ctx context.Context, cmd *user.SetEmailVerifiedCommand) error {
+	return f.ExpectedError
+}
+
 func (f *FakeUserStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*user.SearchUserQueryResult, error) {
 	return f.ExpectedSearchUserQueryResult, f.ExpectedError
 }
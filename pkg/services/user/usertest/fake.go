@@ -20,6 +20,7 @@ type FakeUserService struct {
 	CreateFn            func(ctx context.Context, cmd *user.CreateUserCommand) (*user.User, error)
 	DisableFn           func(ctx context.Context, cmd *user.DisableUserCommand) error
 	BatchDisableUsersFn func(ctx context.Context, cmd *user.BatchDisableUsersCommand) error
+	SetEmailVerifiedFn  func(ctx context.Context, cmd *user.SetEmailVerifiedCommand) error
 
 	counter int
 }
@@ -112,6 +113,13 @@ func (f *FakeUserService) BatchDisableUsers(ctx context.Context, cmd *user.Batch
 	return f.ExpectedError
 }
 
+func (f *FakeUserService) SetEmailVerified(ctx context.Context, cmd *user.SetEmailVerifiedCommand) error {
+	if f.SetEmailVerifiedFn != nil {
+		return f.SetEmailVerifiedFn(ctx, cmd)
+	}
+	return f.ExpectedError
+}
+
 func (f *FakeUserService) UpdatePermissions(ctx context.Context, userID int64, isAdmin bool) error {
 	return f.ExpectedError
 }
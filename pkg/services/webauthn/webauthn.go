@@ -0,0 +1,139 @@
+// Package webauthn lets the Grafana users log in with passkeys, the public key
+// credentials of the Web Authentication API (WebAuthn) kept by their browser,
+// their operating system or a security key. Passkeys replace the password of
+// the login, or are a second factor of the password logins.
+package webauthn
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrInvalidCredential  = errutil.BadRequest("webauthn.invalid-credential", errutil.WithPublicMessage("Invalid passkey registration"))
+	ErrInvalidAssertion   = errutil.Unauthorized("webauthn.invalid-assertion", errutil.WithPublicMessage("Passkey authentication failed"))
+	ErrChallengeNotFound  = errutil.BadRequest("webauthn.challenge-not-found", errutil.WithPublicMessage("The passkey request expired, try again"))
+	ErrAssertionRequired  = errutil.Unauthorized("webauthn.assertion-required", errutil.WithPublicMessage("Authenticate with your passkey"))
+	ErrCredentialNotFound = errutil.NotFound("webauthn.credential-not-found", errutil.WithPublicMessage("Passkey not found"))
+	ErrCredentialExists   = errutil.Conflict("webauthn.credential-exists", errutil.WithPublicMessage("The passkey is already registered"))
+	ErrInvalidName        = errutil.ValidationFailed("webauthn.invalid-name", errutil.WithPublicMessage("The name of the passkey must have between 1 and 100 characters"))
+)
+
+type Service interface {
+	// IsEnabled reports whether the users can register passkeys and log in with them.
+	IsEnabled() bool
+	// Authenticate verifies the assertion of a passkey login, without password, and
+	// returns the ID of the user the passkey belongs to.
+	Authenticate(ctx context.Context, assertion *AssertionResponse) (int64, error)
+	// VerifyLogin verifies the passkey of a password login of a user. When assertion is
+	// nil, it returns ErrAssertionRequired if passkeys are a second factor and the user
+	// has one, and nil otherwise.
+	VerifyLogin(ctx context.Context, userID int64, assertion *AssertionResponse) error
+	// IsRequired returns true if passkeys are a second factor and the user has one.
+	IsRequired(ctx context.Context, userID int64) (bool, error)
+}
+
+// CreationOptions are the options of navigator.credentials.create() that register a passkey, in the JSON format of
+// PublicKeyCredential.parseCreationOptionsFromJSON(). The binary values are encoded in base64url.
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              string                 `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the options of navigator.credentials.get() that authenticate with a passkey, in the JSON format
+// of PublicKeyCredential.parseRequestOptionsFromJSON().
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// RegistrationResponse is the credential returned by navigator.credentials.create(), in the JSON format of
+// PublicKeyCredential.toJSON().
+type RegistrationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    string                           `json:"rawId"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// AssertionResponse is the credential returned by navigator.credentials.get(), in the JSON format of
+// PublicKeyCredential.toJSON().
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	RawID    string                         `json:"rawId"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// Credential is a passkey registered by a user.
+type Credential struct {
+	UID        string     `json:"uid"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	Created    time.Time  `json:"created"`
+	LastUsed   *time.Time `json:"lastUsed,omitempty"`
+}
+
+// RegisterCredentialCommand completes the registration of a passkey.
+type RegisterCredentialCommand struct {
+	// Name helps the user to recognize the passkey, for example "Work laptop".
+	Name       string               `json:"name"`
+	Credential RegistrationResponse `json:"credential"`
+}
+
+// UpdateCredentialCommand renames a passkey.
+type UpdateCredentialCommand struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,253 @@
+package webauthnimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+	router.Group("/api/user/webauthn", func(webauthnRoute routing.RouteRegister) {
+		webauthnRoute.Post("/register/begin", routing.Wrap(s.beginRegistration))
+		webauthnRoute.Post("/register/finish", routing.Wrap(s.finishRegistration))
+		webauthnRoute.Get("/credentials", routing.Wrap(s.listCredentials))
+		webauthnRoute.Put("/credentials/:uid", routing.Wrap(s.renameCredential))
+		webauthnRoute.Delete("/credentials/:uid", routing.Wrap(s.deleteCredential))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	// The login options are requested before the user is signed in, next to the login endpoints.
+	router.Post("/login/webauthn/begin", routing.Wrap(s.beginLogin))
+
+	userIDScope := accesscontrol.Scope("global.users", "id", accesscontrol.Parameter(":id"))
+	router.Group("/api/admin/users/:id/webauthn/credentials", func(adminRoute routing.RouteRegister) {
+		adminRoute.Get("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead, userIDScope)), routing.Wrap(s.adminListCredentials))
+		adminRoute.Delete("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionUsersWrite, userIDScope)), routing.Wrap(s.adminDeleteCredentials))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route POST /user/webauthn/register/begin signed_in_user beginWebAuthnRegistration
+//
+// Start the registration of a passkey of the signed in user.
+//
+// Returns the options of navigator.credentials.create(), in the JSON format of
+// PublicKeyCredential.parseCreationOptionsFromJSON().
+//
+// Responses:
+// 200: beginWebAuthnRegistrationResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) beginRegistration(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	options, err := s.BeginRegistration(c.Req.Context(), userID)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, options)
+}
+
+// swagger:route POST /user/webauthn/register/finish signed_in_user finishWebAuthnRegistration
+//
+// Complete the registration of a passkey of the signed in user.
+//
+// Responses:
+// 200: webAuthnCredentialResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) finishRegistration(c *contextmodel.ReqContext) response.Response {
+	cmd := webauthn.RegisterCredentialCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	credential, err := s.FinishRegistration(c.Req.Context(), userID, &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+// swagger:route GET /user/webauthn/credentials signed_in_user listWebAuthnCredentials
+//
+// List the passkeys of the signed in user.
+//
+// Responses:
+// 200: listWebAuthnCredentialsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) listCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	credentials, err := s.List(c.Req.Context(), userID)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, credentials)
+}
+
+// swagger:route PUT /user/webauthn/credentials/{uid} signed_in_user renameWebAuthnCredential
+//
+// Rename a passkey of the signed in user.
+//
+// Responses:
+// 200: webAuthnCredentialResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) renameCredential(c *contextmodel.ReqContext) response.Response {
+	cmd := webauthn.UpdateCredentialCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	credential, err := s.Rename(c.Req.Context(), userID, web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+// swagger:route DELETE /user/webauthn/credentials/{uid} signed_in_user deleteWebAuthnCredential
+//
+// Delete a passkey of the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteCredential(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Err(err)
+	}
+	if err := s.Delete(c.Req.Context(), userID, web.Params(c.Req)[":uid"]); err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Passkey deleted")
+}
+
+// beginLogin returns the options of navigator.credentials.get(), in the JSON format of
+// PublicKeyCredential.parseRequestOptionsFromJSON(). The credential returned by the browser is posted to
+// /login/webauthn, or in the webauthnAssertion field of the login form when passkeys are a second factor.
+func (s *Service) beginLogin(c *contextmodel.ReqContext) response.Response {
+	options, err := s.BeginLogin(c.Req.Context())
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, options)
+}
+
+// swagger:route GET /admin/users/{user_id}/webauthn/credentials admin_users adminListUserWebAuthnCredentials
+//
+// List the passkeys of a user.
+//
+// Responses:
+// 200: listWebAuthnCredentialsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) adminListCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	credentials, err := s.List(c.Req.Context(), userID)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, credentials)
+}
+
+// swagger:route DELETE /admin/users/{user_id}/webauthn/credentials admin_users adminDeleteUserWebAuthnCredentials
+//
+// Delete the passkeys of a user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) adminDeleteCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.DeleteAll(c.Req.Context(), userID); err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Passkeys deleted")
+}
+
+// swagger:parameters finishWebAuthnRegistration
+type FinishWebAuthnRegistrationParams struct {
+	// in:body
+	// required:true
+	Body webauthn.RegisterCredentialCommand `json:"body"`
+}
+
+// swagger:parameters renameWebAuthnCredential
+type RenameWebAuthnCredentialParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body webauthn.UpdateCredentialCommand `json:"body"`
+}
+
+// swagger:parameters deleteWebAuthnCredential
+type DeleteWebAuthnCredentialParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters adminListUserWebAuthnCredentials adminDeleteUserWebAuthnCredentials
+type AdminUserWebAuthnCredentialsParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:response beginWebAuthnRegistrationResponse
+type BeginWebAuthnRegistrationResponse struct {
+	// in:body
+	Body webauthn.CreationOptions `json:"body"`
+}
+
+// swagger:response webAuthnCredentialResponse
+type WebAuthnCredentialResponse struct {
+	// in:body
+	Body webauthn.Credential `json:"body"`
+}
+
+// swagger:response listWebAuthnCredentialsResponse
+type ListWebAuthnCredentialsResponse struct {
+	// in:body
+	Body []*webauthn.Credential `json:"body"`
+}
@@ -0,0 +1,160 @@
+package webauthnimpl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth limits the nesting of the decoded CBOR values, which the authenticators keep shallow.
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("truncated CBOR value")
+
+// decodeCBOR decodes the CBOR value at the start of data, as encoded by the authenticators (RFC 8949), and returns
+// it with the number of bytes it takes. The unsigned and negative integers are decoded as int64, the byte strings as
+// []byte, the text strings as string, the arrays as []any and the maps as map[any]any. The indefinite lengths are
+// not supported, as the CTAP2 canonical encoding does not use them.
+func decodeCBOR(data []byte) (any, int, error) {
+	return decodeCBORValue(data, 0)
+}
+
+func decodeCBORValue(data []byte, depth int) (any, int, error) {
+	if depth > maxCBORDepth {
+		return nil, 0, errors.New("CBOR value too deeply nested")
+	}
+	if len(data) == 0 {
+		return nil, 0, errCBORTruncated
+	}
+
+	major, info := data[0]>>5, data[0]&0x1f
+	if major == 7 {
+		return decodeCBORSimple(data, info)
+	}
+	arg, n, err := decodeCBORArgument(data, info)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer overflow")
+		}
+		return int64(arg), n, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer overflow")
+		}
+		return -1 - int64(arg), n, nil
+	case 2, 3:
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORTruncated
+		}
+		end := n + int(arg)
+		if major == 2 {
+			return append([]byte(nil), data[n:end]...), end, nil
+		}
+		return string(data[n:end]), end, nil
+	case 4:
+		// Each item takes at least one byte.
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORTruncated
+		}
+		items := make([]any, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			item, size, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += size
+		}
+		return items, n, nil
+	case 5:
+		if arg > uint64(len(data)-n)/2 {
+			return nil, 0, errCBORTruncated
+		}
+		entries := make(map[any]any, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			key, size, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += size
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, fmt.Errorf("unsupported CBOR map key of type %T", key)
+			}
+			value, size, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += size
+			entries[key] = value
+		}
+		return entries, n, nil
+	default:
+		// Tags annotate the value that follows them, which is decoded as is.
+		value, size, err := decodeCBORValue(data[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return value, n + size, nil
+	}
+}
+
+// decodeCBORArgument returns the argument of the initial byte of a CBOR value and the size of the header.
+func decodeCBORArgument(data []byte, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24:
+		if len(data) < 2 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(data[1]), 2, nil
+	case info == 25:
+		if len(data) < 3 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data[1:3])), 3, nil
+	case info == 26:
+		if len(data) < 5 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	case info == 27:
+		if len(data) < 9 {
+			return 0, 0, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data[1:9]), 9, nil
+	default:
+		return 0, 0, errors.New("unsupported indefinite length CBOR value")
+	}
+}
+
+func decodeCBORSimple(data []byte, info byte) (any, int, error) {
+	switch info {
+	case 20:
+		return false, 1, nil
+	case 21:
+		return true, 1, nil
+	case 22, 23:
+		return nil, 1, nil
+	case 26:
+		if len(data) < 5 {
+			return nil, 0, errCBORTruncated
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data[1:5]))), 5, nil
+	case 27:
+		if len(data) < 9 {
+			return nil, 0, errCBORTruncated
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data[1:9])), 9, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported CBOR simple value %d", info)
+	}
+}
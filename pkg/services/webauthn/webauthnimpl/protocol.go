@@ -0,0 +1,148 @@
+package webauthnimpl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The flags of the authenticator data.
+const (
+	flagUserPresent       = 0x01
+	flagUserVerified      = 0x04
+	flagAttestedData      = 0x40
+	flagExtensionIncluded = 0x80
+)
+
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// base64url is the encoding of the binary values of the WebAuthn JSON formats.
+var base64url = base64.RawURLEncoding
+
+// decodeBase64URL decodes a base64url value, with or without padding.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64url.DecodeString(strings.TrimRight(s, "="))
+}
+
+// clientData is the data the browser passes to the authenticator, which signs its hash.
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// parseClientData decodes the clientDataJSON of a response and checks its ceremony and its origin.
+func parseClientData(raw []byte, ceremony string, origins []string) (*clientData, error) {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("invalid client data: %w", err)
+	}
+	if data.Type != ceremony {
+		return nil, fmt.Errorf("unexpected ceremony %q", data.Type)
+	}
+	if data.CrossOrigin {
+		return nil, errors.New("cross-origin ceremonies are not allowed")
+	}
+	for _, origin := range origins {
+		if data.Origin == origin {
+			return &data, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected origin %q", data.Origin)
+}
+
+// authenticatorData is the data signed by the authenticator.
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	// The attested credential data, only set for registrations.
+	credentialID []byte
+	publicKey    []byte
+}
+
+func (d *authenticatorData) userVerified() bool {
+	return d.flags&flagUserVerified != 0
+}
+
+// parseAuthenticatorData decodes the authenticator data and checks that the passkey is one of the relying party
+// and that the user was present.
+func parseAuthenticatorData(raw []byte, rpID string) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	hash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(data.rpIDHash, hash[:]) {
+		return nil, errors.New("the passkey belongs to another relying party")
+	}
+	if data.flags&flagUserPresent == 0 {
+		return nil, errors.New("the user was not present")
+	}
+	if data.flags&flagAttestedData == 0 {
+		return data, nil
+	}
+
+	// The attested credential data is the AAGUID of the authenticator, the length of the credential ID, the
+	// credential ID and the public key. Extensions may follow the public key.
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLength {
+		return nil, errors.New("credential ID too short")
+	}
+	data.credentialID, rest = rest[:idLength], rest[idLength:]
+	_, size, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	data.publicKey, rest = rest[:size], rest[size:]
+	if data.flags&flagExtensionIncluded != 0 {
+		if _, size, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("invalid extensions: %w", err)
+		}
+		rest = rest[size:]
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing bytes after the authenticator data")
+	}
+	return data, nil
+}
+
+// parseAttestationObject returns the authenticator data of an attestation object. The attestation statement is
+// not verified: the registrations request no attestation and trust the authenticator of the signed in user, as
+// the passkeys of the major platforms do not provide one.
+func parseAttestationObject(raw []byte) ([]byte, error) {
+	value, _, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+	m, ok := value.(map[any]any)
+	if !ok {
+		return nil, errors.New("the attestation object is not a map")
+	}
+	if _, ok := m["fmt"].(string); !ok {
+		return nil, errors.New("the attestation object has no format")
+	}
+	authData, ok := m["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("the attestation object has no authenticator data")
+	}
+	return authData, nil
+}
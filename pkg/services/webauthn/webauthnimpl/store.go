@@ -0,0 +1,145 @@
+package webauthnimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+// credential is a passkey of a user.
+type credential struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	UID    string `xorm:"uid"`
+	UserID int64  `xorm:"user_id"`
+	// UserHandle is the WebAuthn user ID of the user, encoded in base64url, shared by the passkeys of the user.
+	UserHandle string `xorm:"user_handle"`
+	Name       string `xorm:"name"`
+	// CredentialID is the ID of the passkey, encoded in base64url.
+	CredentialID string `xorm:"credential_id"`
+	// PublicKey is the COSE_Key of the passkey, encoded in base64url.
+	PublicKey string `xorm:"public_key"`
+	SignCount int64  `xorm:"sign_count"`
+	// Transports are the comma separated transports of the authenticator, such as usb or internal.
+	Transports string `xorm:"transports"`
+	Created    int64  `xorm:"created"`
+	Updated    int64  `xorm:"updated"`
+	// LastUsed is 0 until the passkey is used to log in.
+	LastUsed int64 `xorm:"last_used"`
+}
+
+func (c credential) TableName() string { return "user_webauthn_credential" }
+
+func (c *credential) transports() []string {
+	if c.Transports == "" {
+		return []string{}
+	}
+	return strings.Split(c.Transports, ",")
+}
+
+func (c *credential) toDTO() *webauthn.Credential {
+	dto := &webauthn.Credential{
+		UID:        c.UID,
+		Name:       c.Name,
+		Transports: c.transports(),
+		Created:    time.Unix(c.Created, 0),
+	}
+	if c.LastUsed != 0 {
+		lastUsed := time.Unix(c.LastUsed, 0)
+		dto.LastUsed = &lastUsed
+	}
+	return dto
+}
+
+type store interface {
+	List(ctx context.Context, userID int64) ([]*credential, error)
+	GetByUID(ctx context.Context, userID int64, uid string) (*credential, error)
+	GetByCredentialID(ctx context.Context, credentialID string) (*credential, error)
+	Insert(ctx context.Context, c *credential) error
+	Update(ctx context.Context, c *credential) error
+	Delete(ctx context.Context, userID int64, uid string) error
+	DeleteAll(ctx context.Context, userID int64) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) List(ctx context.Context, userID int64) ([]*credential, error) {
+	credentials := make([]*credential, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("created", "id").Find(&credentials)
+	})
+	return credentials, err
+}
+
+func (s *sqlStore) GetByUID(ctx context.Context, userID int64, uid string) (*credential, error) {
+	return s.get(ctx, "user_id = ? AND uid = ?", userID, uid)
+}
+
+func (s *sqlStore) GetByCredentialID(ctx context.Context, credentialID string) (*credential, error) {
+	return s.get(ctx, "credential_id = ?", credentialID)
+}
+
+func (s *sqlStore) get(ctx context.Context, query string, args ...any) (*credential, error) {
+	var c credential
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where(query, args...).Get(&c)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return webauthn.ErrCredentialNotFound.Errorf("passkey not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *sqlStore) Insert(ctx context.Context, c *credential) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("credential_id = ?", c.CredentialID).Exist(&credential{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return webauthn.ErrCredentialExists.Errorf("passkey already registered")
+		}
+		_, err = sess.Insert(c)
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, c *credential) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(c.ID).Cols("name", "sign_count", "updated", "last_used").Update(c)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, userID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_webauthn_credential WHERE user_id = ? AND uid = ?", userID, uid)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return webauthn.ErrCredentialNotFound.Errorf("passkey %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) DeleteAll(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_webauthn_credential WHERE user_id = ?", userID)
+		return err
+	})
+}
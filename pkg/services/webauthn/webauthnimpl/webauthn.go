@@ -0,0 +1,450 @@
+package webauthnimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	challengeSize  = 32
+	userHandleSize = 32
+	// maxCredentialIDSize keeps the base64url encoded credential IDs within their 255 characters column. The
+	// passkeys have shorter IDs.
+	maxCredentialIDSize = 190
+	maxNameLength       = 100
+
+	challengeKeyPrefix = "webauthn-challenge-"
+)
+
+type Service struct {
+	enabled      bool
+	secondFactor bool
+	rpID         string
+	rpName       string
+	origins      []string
+	timeout      time.Duration
+
+	store       store
+	cache       remotecache.CacheStorage
+	userService user.Service
+	log         log.Logger
+
+	now func() time.Time
+}
+
+var _ webauthn.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	cache remotecache.CacheStorage, userService user.Service) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("auth.webauthn")
+	s := &Service{
+		enabled:      section.Key("enabled").MustBool(false),
+		secondFactor: section.Key("second_factor").MustBool(false),
+		rpID:         section.Key("rp_id").MustString(""),
+		rpName:       section.Key("rp_name").MustString("Grafana"),
+		origins:      util.SplitString(section.Key("origins").MustString("")),
+		timeout:      section.Key("timeout").MustDuration(5 * time.Minute),
+		store:        &sqlStore{db: db},
+		cache:        cache,
+		userService:  userService,
+		log:          log.New("webauthn"),
+		now:          time.Now,
+	}
+
+	// The passkeys belong to the domain of the root URL and are used from its origin by default.
+	if s.rpID == "" || len(s.origins) == 0 {
+		appURL, err := url.Parse(cfg.AppURL)
+		if err != nil {
+			return nil, err
+		}
+		if s.rpID == "" {
+			s.rpID = appURL.Hostname()
+		}
+		if len(s.origins) == 0 {
+			s.origins = []string{appURL.Scheme + "://" + appURL.Host}
+		}
+	}
+
+	if s.enabled {
+		s.registerAPIEndpoints(routeRegister, ac)
+	}
+	return s, nil
+}
+
+func (s *Service) IsEnabled() bool {
+	return s.enabled
+}
+
+// challengeState is the state of a ceremony between the creation of its challenge and the response of the browser.
+type challengeState struct {
+	Ceremony   string `json:"ceremony"`
+	UserID     int64  `json:"userId,omitempty"`
+	UserHandle string `json:"userHandle,omitempty"`
+}
+
+// BeginRegistration returns the options that register a new passkey of a user.
+func (s *Service) BeginRegistration(ctx context.Context, userID int64) (*webauthn.CreationOptions, error) {
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := s.store.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The passkeys of a user share its user handle, so that registering a passkey in an authenticator that
+	// already has one replaces it.
+	var userHandle string
+	excluded := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		userHandle = c.UserHandle
+		excluded = append(excluded, webauthn.CredentialDescriptor{Type: "public-key", ID: c.CredentialID, Transports: c.transports()})
+	}
+	if userHandle == "" {
+		if userHandle, err = randomBase64URL(userHandleSize); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, err := s.newChallenge(ctx, challengeState{Ceremony: ceremonyCreate, UserID: userID, UserHandle: userHandle})
+	if err != nil {
+		return nil, err
+	}
+	displayName := usr.Name
+	if displayName == "" {
+		displayName = usr.Login
+	}
+	params := make([]webauthn.CredentialParameter, 0, len(supportedAlgorithms))
+	for _, alg := range supportedAlgorithms {
+		params = append(params, webauthn.CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	return &webauthn.CreationOptions{
+		RP:                 webauthn.RelyingParty{ID: s.rpID, Name: s.rpName},
+		User:               webauthn.UserEntity{ID: userHandle, Name: usr.Login, DisplayName: displayName},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            s.timeout.Milliseconds(),
+		ExcludeCredentials: excluded,
+		AuthenticatorSelection: webauthn.AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "preferred",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies the response of the browser to a registration and saves the passkey.
+func (s *Service) FinishRegistration(ctx context.Context, userID int64, cmd *webauthn.RegisterCredentialCommand) (*webauthn.Credential, error) {
+	name := strings.TrimSpace(cmd.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, webauthn.ErrInvalidName.Errorf("invalid passkey name %q", cmd.Name)
+	}
+
+	response := cmd.Credential.Response
+	rawClientData, err := decodeBase64URL(response.ClientDataJSON)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("invalid client data: %w", err)
+	}
+	data, err := parseClientData(rawClientData, ceremonyCreate, s.origins)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("%w", err)
+	}
+	state, err := s.consumeChallenge(ctx, data.Challenge, ceremonyCreate)
+	if err != nil {
+		return nil, err
+	}
+	if state.UserID != userID {
+		return nil, webauthn.ErrChallengeNotFound.Errorf("the challenge belongs to another user")
+	}
+
+	attestationObject, err := decodeBase64URL(response.AttestationObject)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("invalid attestation object: %w", err)
+	}
+	rawAuthData, err := parseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("%w", err)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData, s.rpID)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("%w", err)
+	}
+	if authData.credentialID == nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("no attested credential data")
+	}
+	if len(authData.credentialID) > maxCredentialIDSize {
+		return nil, webauthn.ErrInvalidCredential.Errorf("credential ID of %d bytes", len(authData.credentialID))
+	}
+	credentialID := base64url.EncodeToString(authData.credentialID)
+	if rawID, err := decodeBase64URL(cmd.Credential.RawID); err != nil || base64url.EncodeToString(rawID) != credentialID {
+		return nil, webauthn.ErrInvalidCredential.Errorf("the raw ID is not the attested credential ID")
+	}
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("%w", err)
+	}
+
+	now := s.now().Unix()
+	c := &credential{
+		UID:          util.GenerateShortUID(),
+		UserID:       userID,
+		UserHandle:   state.UserHandle,
+		Name:         name,
+		CredentialID: credentialID,
+		PublicKey:    base64url.EncodeToString(authData.publicKey),
+		SignCount:    int64(authData.signCount),
+		Transports:   strings.Join(response.Transports, ","),
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.store.Insert(ctx, c); err != nil {
+		return nil, err
+	}
+	s.log.FromContext(ctx).Info("Passkey registered", "userId", userID, "uid", c.UID)
+	return c.toDTO(), nil
+}
+
+// BeginLogin returns the options that authenticate with a passkey. The browser lets the user choose one of the
+// passkeys of the relying party, so the user does not enter its login.
+func (s *Service) BeginLogin(ctx context.Context) (*webauthn.RequestOptions, error) {
+	challenge, err := s.newChallenge(ctx, challengeState{Ceremony: ceremonyGet})
+	if err != nil {
+		return nil, err
+	}
+	return &webauthn.RequestOptions{
+		Challenge:        challenge,
+		Timeout:          s.timeout.Milliseconds(),
+		RPID:             s.rpID,
+		AllowCredentials: []webauthn.CredentialDescriptor{},
+		UserVerification: "preferred",
+	}, nil
+}
+
+func (s *Service) Authenticate(ctx context.Context, assertion *webauthn.AssertionResponse) (int64, error) {
+	// Without password, the passkey is the only factor and the authenticator must verify the user.
+	c, err := s.verifyAssertion(ctx, assertion, true)
+	if err != nil {
+		return 0, err
+	}
+	return c.UserID, nil
+}
+
+func (s *Service) VerifyLogin(ctx context.Context, userID int64, assertion *webauthn.AssertionResponse) error {
+	if !s.enabled {
+		if assertion != nil {
+			return webauthn.ErrInvalidAssertion.Errorf("passkeys are disabled")
+		}
+		return nil
+	}
+	if assertion == nil {
+		required, err := s.IsRequired(ctx, userID)
+		if err != nil || !required {
+			return err
+		}
+		return webauthn.ErrAssertionRequired.Errorf("user %d has a passkey", userID)
+	}
+
+	c, err := s.verifyAssertion(ctx, assertion, false)
+	if err != nil {
+		return err
+	}
+	if c.UserID != userID {
+		return webauthn.ErrInvalidAssertion.Errorf("the passkey belongs to another user")
+	}
+	return nil
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	if !s.enabled || !s.secondFactor {
+		return false, nil
+	}
+	credentials, err := s.store.List(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+// verifyAssertion verifies the response of the browser to an authentication and returns the passkey used.
+func (s *Service) verifyAssertion(ctx context.Context, assertion *webauthn.AssertionResponse, requireUserVerification bool) (*credential, error) {
+	rawID, err := decodeBase64URL(assertion.RawID)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("invalid raw ID: %w", err)
+	}
+	c, err := s.store.GetByCredentialID(ctx, base64url.EncodeToString(rawID))
+	if errors.Is(err, webauthn.ErrCredentialNotFound) {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("unknown passkey")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := assertion.Response
+	rawClientData, err := decodeBase64URL(response.ClientDataJSON)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("invalid client data: %w", err)
+	}
+	data, err := parseClientData(rawClientData, ceremonyGet, s.origins)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("%w", err)
+	}
+	if _, err := s.consumeChallenge(ctx, data.Challenge, ceremonyGet); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := decodeBase64URL(response.AuthenticatorData)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("invalid authenticator data: %w", err)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData, s.rpID)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("%w", err)
+	}
+	if requireUserVerification && !authData.userVerified() {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("the authenticator did not verify the user")
+	}
+
+	signature, err := decodeBase64URL(response.Signature)
+	if err != nil {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("invalid signature: %w", err)
+	}
+	rawKey, err := decodeBase64URL(c.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePublicKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	if !key.verify(append(rawAuthData[:len(rawAuthData):len(rawAuthData)], clientDataHash[:]...), signature) {
+		return nil, webauthn.ErrInvalidAssertion.Errorf("invalid signature")
+	}
+
+	if response.UserHandle != "" {
+		userHandle, err := decodeBase64URL(response.UserHandle)
+		if err != nil || base64url.EncodeToString(userHandle) != c.UserHandle {
+			return nil, webauthn.ErrInvalidAssertion.Errorf("the user handle is not the one of the passkey")
+		}
+	}
+
+	// The authenticators that count their signatures increase the count at each use. A count that does not increase
+	// reveals a cloned authenticator.
+	signCount := int64(authData.signCount)
+	if (signCount != 0 || c.SignCount != 0) && signCount <= c.SignCount {
+		s.log.FromContext(ctx).Warn("Passkey signature count did not increase, the authenticator may be cloned", "userId", c.UserID, "uid", c.UID)
+		return nil, webauthn.ErrInvalidAssertion.Errorf("signature count %d is not above %d", signCount, c.SignCount)
+	}
+
+	c.SignCount, c.LastUsed, c.Updated = signCount, s.now().Unix(), s.now().Unix()
+	if err := s.store.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List returns the passkeys of a user.
+func (s *Service) List(ctx context.Context, userID int64) ([]*webauthn.Credential, error) {
+	credentials, err := s.store.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*webauthn.Credential, 0, len(credentials))
+	for _, c := range credentials {
+		result = append(result, c.toDTO())
+	}
+	return result, nil
+}
+
+// Rename renames a passkey of a user.
+func (s *Service) Rename(ctx context.Context, userID int64, uid string, cmd *webauthn.UpdateCredentialCommand) (*webauthn.Credential, error) {
+	name := strings.TrimSpace(cmd.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, webauthn.ErrInvalidName.Errorf("invalid passkey name %q", cmd.Name)
+	}
+	c, err := s.store.GetByUID(ctx, userID, uid)
+	if err != nil {
+		return nil, err
+	}
+	c.Name, c.Updated = name, s.now().Unix()
+	if err := s.store.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c.toDTO(), nil
+}
+
+// Delete deletes a passkey of a user.
+func (s *Service) Delete(ctx context.Context, userID int64, uid string) error {
+	return s.store.Delete(ctx, userID, uid)
+}
+
+// DeleteAll deletes the passkeys of a user.
+func (s *Service) DeleteAll(ctx context.Context, userID int64) error {
+	return s.store.DeleteAll(ctx, userID)
+}
+
+// newChallenge returns a random challenge and saves the state of its ceremony until it times out.
+func (s *Service) newChallenge(ctx context.Context, state challengeState) (string, error) {
+	challenge, err := randomBase64URL(challengeSize)
+	if err != nil {
+		return "", err
+	}
+	value, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, challengeKeyPrefix+challenge, value, s.timeout); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// consumeChallenge returns the state of the ceremony of a challenge, which cannot be used again.
+func (s *Service) consumeChallenge(ctx context.Context, challenge string, ceremony string) (*challengeState, error) {
+	key := challengeKeyPrefix + challenge
+	value, err := s.cache.Get(ctx, key)
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil, webauthn.ErrChallengeNotFound.Errorf("unknown or expired challenge")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	var state challengeState
+	if err := json.Unmarshal(value, &state); err != nil {
+		return nil, err
+	}
+	if state.Ceremony != ceremony {
+		return nil, webauthn.ErrChallengeNotFound.Errorf("the challenge belongs to a %s ceremony", state.Ceremony)
+	}
+	return &state, nil
+}
+
+func randomBase64URL(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64url.EncodeToString(b), nil
+}
@@ -0,0 +1,364 @@
+package webauthnimpl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testOrigin = "https://grafana.example.com"
+
+// encodeCBOR encodes the values of the tests in CBOR.
+func encodeCBOR(v any) []byte {
+	header := func(major byte, arg uint64) []byte {
+		switch {
+		case arg < 24:
+			return []byte{major<<5 | byte(arg)}
+		case arg < 1<<8:
+			return []byte{major<<5 | 24, byte(arg)}
+		case arg < 1<<16:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+		}
+	}
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return header(1, uint64(-1-v))
+		}
+		return header(0, uint64(v))
+	case []byte:
+		return append(header(2, uint64(len(v))), v...)
+	case string:
+		return append(header(3, uint64(len(v))), v...)
+	case []any:
+		b := header(4, uint64(len(v)))
+		for _, item := range v {
+			b = append(b, encodeCBOR(item)...)
+		}
+		return b
+	case map[any]any:
+		b := header(5, uint64(len(v)))
+		for key, value := range v {
+			b = append(b, encodeCBOR(key)...)
+			b = append(b, encodeCBOR(value)...)
+		}
+		return b
+	default:
+		panic("unsupported CBOR value")
+	}
+}
+
+// testAuthenticator is a software authenticator with a P-256 passkey.
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   string
+	signCount    uint32
+	userVerified bool
+	origin       string
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &testAuthenticator{key: key, credentialID: credentialID, userVerified: true, origin: testOrigin}
+}
+
+func (a *testAuthenticator) authenticatorData(rpID string, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	flags := byte(flagUserPresent)
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+	if attested {
+		flags |= flagAttestedData
+	}
+	data := append(hash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, encodeCBOR(map[any]any{
+			1:  ktyEC2,
+			3:  algES256,
+			-1: crvP256,
+			-2: a.key.X.FillBytes(make([]byte, 32)),
+			-3: a.key.Y.FillBytes(make([]byte, 32)),
+		})...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+	data, err := json.Marshal(clientData{Type: ceremony, Challenge: challenge, Origin: a.origin})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthenticator) register(t *testing.T, options *webauthn.CreationOptions) *webauthn.RegisterCredentialCommand {
+	t.Helper()
+	a.userHandle = options.User.ID
+	attestationObject := encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authenticatorData(options.RP.ID, true),
+	})
+	return &webauthn.RegisterCredentialCommand{
+		Name: "Laptop",
+		Credential: webauthn.RegistrationResponse{
+			ID:    base64url.EncodeToString(a.credentialID),
+			RawID: base64url.EncodeToString(a.credentialID),
+			Type:  "public-key",
+			Response: webauthn.AuthenticatorAttestationResponse{
+				ClientDataJSON:    base64url.EncodeToString(a.clientData(t, ceremonyCreate, options.Challenge)),
+				AttestationObject: base64url.EncodeToString(attestationObject),
+				Transports:        []string{"internal", "hybrid"},
+			},
+		},
+	}
+}
+
+func (a *testAuthenticator) assert(t *testing.T, options *webauthn.RequestOptions) *webauthn.AssertionResponse {
+	t.Helper()
+	a.signCount++
+	authData := a.authenticatorData(options.RPID, false)
+	clientDataJSON := a.clientData(t, ceremonyGet, options.Challenge)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	return &webauthn.AssertionResponse{
+		ID:    base64url.EncodeToString(a.credentialID),
+		RawID: base64url.EncodeToString(a.credentialID),
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    base64url.EncodeToString(clientDataJSON),
+			AuthenticatorData: base64url.EncodeToString(authData),
+			Signature:         base64url.EncodeToString(signature),
+			UserHandle:        a.userHandle,
+		},
+	}
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.AppURL = testOrigin + "/grafana/"
+	cfg.Raw.Section("auth.webauthn").Key("enabled").SetValue("true")
+	s, err := ProvideService(cfg, db.InitTestDB(t), routing.NewRouteRegister(), acimpl.ProvideAccessControl(cfg),
+		remotecache.NewFakeStore(t), &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "alice"}})
+	require.NoError(t, err)
+	return s
+}
+
+// registerPasskey registers the passkey of an authenticator for a user.
+func registerPasskey(t *testing.T, s *Service, userID int64, a *testAuthenticator) *webauthn.Credential {
+	t.Helper()
+	options, err := s.BeginRegistration(context.Background(), userID)
+	require.NoError(t, err)
+	credential, err := s.FinishRegistration(context.Background(), userID, a.register(t, options))
+	require.NoError(t, err)
+	return credential
+}
+
+func TestIntegrationWebAuthn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	t.Run("defaults to the domain and the origin of the root URL", func(t *testing.T) {
+		s := newTestService(t)
+		assert.Equal(t, "grafana.example.com", s.rpID)
+		assert.Equal(t, []string{testOrigin}, s.origins)
+	})
+
+	t.Run("registers a passkey and logs in with it", func(t *testing.T) {
+		s := newTestService(t)
+		a := newTestAuthenticator(t)
+
+		options, err := s.BeginRegistration(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "grafana.example.com", options.RP.ID)
+		assert.Equal(t, "alice", options.User.Name)
+		assert.Empty(t, options.ExcludeCredentials)
+
+		cmd := a.register(t, options)
+		credential, err := s.FinishRegistration(ctx, 1, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "Laptop", credential.Name)
+		assert.Equal(t, []string{"internal", "hybrid"}, credential.Transports)
+		assert.Nil(t, credential.LastUsed)
+
+		_, err = s.FinishRegistration(ctx, 1, cmd)
+		assert.ErrorIs(t, err, webauthn.ErrChallengeNotFound, "the challenge cannot be used twice")
+
+		options, err = s.BeginRegistration(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, options.ExcludeCredentials, 1)
+		assert.Equal(t, a.userHandle, options.User.ID, "the passkeys of a user share its user handle")
+		_, err = s.FinishRegistration(ctx, 1, a.register(t, options))
+		assert.ErrorIs(t, err, webauthn.ErrCredentialExists)
+
+		loginOptions, err := s.BeginLogin(ctx)
+		require.NoError(t, err)
+		userID, err := s.Authenticate(ctx, a.assert(t, loginOptions))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), userID)
+
+		credentials, err := s.List(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		assert.NotNil(t, credentials[0].LastUsed)
+	})
+
+	t.Run("rejects invalid assertions", func(t *testing.T) {
+		s := newTestService(t)
+		a := newTestAuthenticator(t)
+		registerPasskey(t, s, 1, a)
+
+		assertion := func() *webauthn.AssertionResponse {
+			options, err := s.BeginLogin(ctx)
+			require.NoError(t, err)
+			return a.assert(t, options)
+		}
+
+		valid := assertion()
+		_, err := s.Authenticate(ctx, valid)
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, valid)
+		assert.ErrorIs(t, err, webauthn.ErrChallengeNotFound, "the challenge cannot be used twice")
+
+		tampered := assertion()
+		tampered.Response.Signature = base64url.EncodeToString([]byte("invalid"))
+		_, err = s.Authenticate(ctx, tampered)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion)
+
+		a.origin = "https://evil.example.com"
+		_, err = s.Authenticate(ctx, assertion())
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion)
+		a.origin = testOrigin
+
+		a.userVerified = false
+		_, err = s.Authenticate(ctx, assertion())
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion, "passwordless logins require user verification")
+		a.userVerified = true
+
+		a.signCount = 0
+		_, err = s.Authenticate(ctx, assertion())
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion, "the signature count must increase")
+		a.signCount = 100
+
+		unknown := newTestAuthenticator(t)
+		options, err := s.BeginLogin(ctx)
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, unknown.assert(t, options))
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAssertion)
+	})
+
+	t.Run("verifies the passkey of password logins", func(t *testing.T) {
+		s := newTestService(t)
+		a := newTestAuthenticator(t)
+		registerPasskey(t, s, 1, a)
+		require.NoError(t, s.VerifyLogin(ctx, 1, nil), "passkeys are not a second factor by default")
+
+		s.secondFactor = true
+		assert.NoError(t, s.VerifyLogin(ctx, 2, nil), "users without passkey")
+		assert.ErrorIs(t, s.VerifyLogin(ctx, 1, nil), webauthn.ErrAssertionRequired)
+
+		options, err := s.BeginLogin(ctx)
+		require.NoError(t, err)
+		a.userVerified = false
+		require.NoError(t, s.VerifyLogin(ctx, 1, a.assert(t, options)))
+
+		options, err = s.BeginLogin(ctx)
+		require.NoError(t, err)
+		assert.ErrorIs(t, s.VerifyLogin(ctx, 2, a.assert(t, options)), webauthn.ErrInvalidAssertion)
+	})
+
+	t.Run("renames and deletes passkeys", func(t *testing.T) {
+		s := newTestService(t)
+		credential := registerPasskey(t, s, 1, newTestAuthenticator(t))
+		registerPasskey(t, s, 1, newTestAuthenticator(t))
+
+		_, err := s.Rename(ctx, 1, credential.UID, &webauthn.UpdateCredentialCommand{Name: " "})
+		assert.ErrorIs(t, err, webauthn.ErrInvalidName)
+		_, err = s.Rename(ctx, 2, credential.UID, &webauthn.UpdateCredentialCommand{Name: "Phone"})
+		assert.ErrorIs(t, err, webauthn.ErrCredentialNotFound)
+		renamed, err := s.Rename(ctx, 1, credential.UID, &webauthn.UpdateCredentialCommand{Name: "Phone"})
+		require.NoError(t, err)
+		assert.Equal(t, "Phone", renamed.Name)
+
+		assert.ErrorIs(t, s.Delete(ctx, 2, credential.UID), webauthn.ErrCredentialNotFound)
+		require.NoError(t, s.Delete(ctx, 1, credential.UID))
+		credentials, err := s.List(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, credentials, 1)
+
+		require.NoError(t, s.DeleteAll(ctx, 1))
+		credentials, err = s.List(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, credentials)
+	})
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	value, size, err := decodeCBOR(append(encodeCBOR(map[any]any{"a": []any{1, -300, []byte{1, 2}}}), 0xff))
+	require.NoError(t, err)
+	assert.Equal(t, map[any]any{"a": []any{int64(1), int64(-300), []byte{1, 2}}}, value)
+	assert.Equal(t, 11, size)
+
+	for name, data := range map[string][]byte{
+		"empty":              {},
+		"truncated string":   {0x63, 'a'},
+		"truncated array":    {0x82, 0x01},
+		"indefinite length":  {0x9f, 0x01, 0xff},
+		"unsupported key":    {0xa1, 0x80, 0x01},
+		"huge byte string":   {0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"too deeply nested":  {0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x01},
+		"unsupported simple": {0xf8, 0x20},
+	} {
+		_, _, err := decodeCBOR(data)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := parsePublicKey(encodeCBOR(map[any]any{1: ktyOKP, 3: algEdDSA, -1: crvEd25519, -2: []byte(public)}))
+	require.NoError(t, err)
+	assert.True(t, key.verify([]byte("data"), ed25519.Sign(private, []byte("data"))))
+	assert.False(t, key.verify([]byte("other"), ed25519.Sign(private, []byte("data"))))
+
+	_, err = parsePublicKey(encodeCBOR(map[any]any{1: ktyEC2, 3: algES256, -1: crvP256, -2: make([]byte, 32), -3: make([]byte, 32)}))
+	assert.Error(t, err, "the point is not on the curve")
+	_, err = parsePublicKey(encodeCBOR(map[any]any{1: ktyEC2, 3: -35}))
+	assert.Error(t, err, "unsupported algorithm")
+}
@@ -0,0 +1,40 @@
+package webauthntest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+type FakeService struct {
+	ExpectedEnabled  bool
+	ExpectedRequired bool
+	ExpectedUserID   int64
+	ExpectedError    error
+	// Assertions are the assertions of the verified logins.
+	Assertions []*webauthn.AssertionResponse
+}
+
+var _ webauthn.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) IsEnabled() bool {
+	return f.ExpectedEnabled
+}
+
+func (f *FakeService) Authenticate(_ context.Context, assertion *webauthn.AssertionResponse) (int64, error) {
+	f.Assertions = append(f.Assertions, assertion)
+	return f.ExpectedUserID, f.ExpectedError
+}
+
+func (f *FakeService) VerifyLogin(_ context.Context, _ int64, assertion *webauthn.AssertionResponse) error {
+	f.Assertions = append(f.Assertions, assertion)
+	return f.ExpectedError
+}
+
+func (f *FakeService) IsRequired(_ context.Context, _ int64) (bool, error) {
+	return f.ExpectedRequired, nil
+}
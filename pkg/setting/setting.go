@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/models/roletype"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -51,6 +53,13 @@ const (
 	ApplicationName  = "Grafana"
 )
 
+const (
+	// IdentityEmailConflictLink links the identity of the new provider to the existing user.
+	IdentityEmailConflictLink = "link"
+	// IdentityEmailConflictReject refuses the login with the identity of the new provider.
+	IdentityEmailConflictReject = "reject"
+)
+
 // zoneInfo names environment variable for setting the path to look for the timezone database in go
 const zoneInfo = "ZONEINFO"
 
@@ -168,10 +177,21 @@ type Cfg struct {
 	Domain           string
 	CDNRootURL       *url.URL
 	ReadTimeout      time.Duration
+	DrainTimeout     time.Duration
 	EnableGzip       bool
 	EnforceDomain    bool
 	MinTLSVersion    string
 
+	// TrustedProxies are the reverse proxies whose ClientIPHeaders are trusted
+	// to resolve the IP address of the clients, in order of precedence.
+	TrustedProxies  []*net.IPNet
+	ClientIPHeaders []string
+
+	// CDNSigningKey signs the URLs of the static assets under CDNSignedPaths.
+	CDNSigningKey          string
+	CDNSignedPaths         []string
+	CDNSignedURLExpiration time.Duration
+
 	// Security settings
 	SecretKey             string
 	EmailCodeValidMinutes int
@@ -200,13 +220,19 @@ type Cfg struct {
 	Smtp SmtpSettings
 
 	// Rendering
-	ImagesDir                      string
-	CSVsDir                        string
-	RendererUrl                    string
-	RendererCallbackUrl            string
-	RendererAuthToken              string
-	RendererConcurrentRequestLimit int
-	RendererRenderKeyLifeTime      time.Duration
+	ImagesDir                           string
+	CSVsDir                             string
+	RendererUrl                         string
+	RendererCallbackUrl                 string
+	RendererAuthToken                   string
+	RendererConcurrentRequestLimit      int
+	RendererBudget                      int
+	RendererBudgetQueueSize             int
+	RendererBudgetQueueTimeout          time.Duration
+	RendererBudgetWeightInteractive     int
+	RendererBudgetWeightReport          int
+	RendererBudgetWeightAlertScreenshot int
+	RendererRenderKeyLifeTime           time.Duration
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -233,6 +259,8 @@ type Cfg struct {
 	DisableFrontendSandboxForPlugins []string
 
 	TempDataLifetime time.Duration
+	// CleanupSchedules overrides the schedules of the clean up tasks by task name.
+	CleanupSchedules map[string]string
 
 	// Plugins
 	PluginsEnableAlpha               bool
@@ -268,12 +296,23 @@ type Cfg struct {
 
 	// Dashboards
 	DefaultHomeDashboardPath string
+	// DashboardRequireIfMatchOrgs are the orgs where the updates of the
+	// dashboards require the If-Match header.
+	DashboardRequireIfMatchOrgs map[int64]bool
+	// DashboardValidateVariables enables the validation of the template
+	// variables of the dashboards when they are saved.
+	DashboardValidateVariables bool
+	// DashboardValidateVariablesRunQueries runs the variable queries during
+	// the validation, within DashboardValidateVariablesTimeout.
+	DashboardValidateVariablesRunQueries bool
+	DashboardValidateVariablesTimeout    time.Duration
 
 	// Auth
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	NewDeviceLoginEmail          bool
 	SigV4AuthEnabled             bool
 	SigV4VerboseLogging          bool
 	AzureAuthEnabled             bool
@@ -316,6 +355,10 @@ type Cfg struct {
 	OAuthAutoLogin                bool
 	OAuthCookieMaxAge             int
 	OAuthAllowInsecureEmailLookup bool
+	// IdentityEmailConflict controls what happens when an identity of a new provider matches an existing
+	// user that is already linked to another identity, one of IdentityEmailConflictLink or
+	// IdentityEmailConflictReject.
+	IdentityEmailConflict string
 
 	// JWT Auth
 	JWTAuthEnabled                 bool
@@ -381,6 +424,14 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	// SAClientCredentialsEnabled lets service accounts exchange their tokens for short-lived access
+	// tokens with the OAuth2 client credentials grant.
+	SAClientCredentialsEnabled       bool
+	SAClientCredentialsTokenLifetime time.Duration
+
+	// Team tokens
+	TeamTokenExpirationDayLimit int
+	TeamTokenInactivityDayLimit int
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -388,24 +439,46 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationPartitioning             AnnotationPartitionSettings
 
 	// GrafanaJavascriptAgent config
 	GrafanaJavascriptAgent GrafanaJavascriptAgent
 
 	// Data sources
 	DataSourceLimit int
+	// DataSourceCredentialsPath is the directory the credentials of data sources are rotated from.
+	DataSourceCredentialsPath string
+	// DataSourceCredentialsRotationInterval is the interval data source credentials are rotated at, 0 disables it.
+	DataSourceCredentialsRotationInterval time.Duration
+	// DataSourceCacheInvalidationInterval is the interval the invalidations of cached data source settings
+	// published by other instances are read at, 0 disables publishing them.
+	DataSourceCacheInvalidationInterval time.Duration
+	// DataSourceEnforceQueryPermissions restricts the queries of users to the data sources they were granted
+	// the query permission on, instead of letting every user of an org query all its data sources.
+	DataSourceEnforceQueryPermissions bool
+	// DataSourceSecureJSONDataVersions is the number of previous versions of the secure json data of each data
+	// source kept to roll back changes of their credentials, 0 disables keeping them.
+	DataSourceSecureJSONDataVersions int
 
 	// SQL Data sources
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
 
+	// Prometheus data sources
+	PrometheusQueryLimits PrometheusQueryLimits
+	// PrometheusOrgQueryLimits overrides the Prometheus query limits by org id.
+	PrometheusOrgQueryLimits map[int64]PrometheusQueryLimits
+
 	// Snapshots
 	SnapshotEnabled       bool
 	ExternalSnapshotUrl   string
 	ExternalSnapshotName  string
 	ExternalEnabled       bool
 	SnapShotRemoveExpired bool
+	// SnapshotExpiryReminder is how long before their expiry the creators of snapshots are reminded by email.
+	// Reminders are disabled if 0.
+	SnapshotExpiryReminder time.Duration
 
 	SnapshotPublicMode bool
 
@@ -424,6 +497,8 @@ type Cfg struct {
 	CheckForPluginUpdates               bool
 	ReportingDistributor                string
 	ReportingEnabled                    bool
+	UsageStatsOTLPEndpoint              string
+	UsageStatsOTLPInterval              time.Duration
 	ApplicationInsightsConnectionString string
 	ApplicationInsightsEndpointUrl      string
 	FeedbackLinksEnabled                bool
@@ -493,6 +568,13 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveRemoteWriteDatasourceUID is the Prometheus data source the samples
+	// received by the Live remote write endpoint are forwarded to. Empty
+	// value disables forwarding.
+	LiveRemoteWriteDatasourceUID string
+	// LiveRemoteWritePath is the path of the remote write endpoint of the
+	// data source, relative to its URL.
+	LiveRemoteWritePath string
 
 	// GitHub OAuth
 	GitHubAuthEnabled     bool
@@ -530,6 +612,10 @@ type Cfg struct {
 
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
+	OAuthTokenForwarding OAuthTokenForwardingSettings
+
+	DashboardRedaction DashboardRedactionSettings
+
 	// SAML Auth
 	SAMLAuthEnabled            bool
 	SAMLSkipOrgRoleSync        bool
@@ -559,6 +645,8 @@ type Cfg struct {
 	GRPCServerNetwork   string
 	GRPCServerAddress   string
 	GRPCServerTLSConfig *tls.Config
+	// Interval of the polling of the resources by the watch APIs.
+	GRPCServerResourcesWatchInterval time.Duration
 
 	CustomResponseHeaders map[string]string
 
@@ -757,6 +845,37 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 
+	return cfg.readAnnotationPartitionSettings(section)
+}
+
+func (cfg *Cfg) readAnnotationPartitionSettings(section *ini.Section) error {
+	interval, err := gtime.ParseDuration(section.Key("partition_interval").MustString("7d"))
+	if err != nil {
+		return fmt.Errorf("invalid [annotations] partition_interval: %w", err)
+	}
+	retention, err := gtime.ParseDuration(valueAsString(section, "partition_retention", "0"))
+	if err != nil {
+		return fmt.Errorf("invalid [annotations] partition_retention: %w", err)
+	}
+
+	cfg.AnnotationPartitioning = AnnotationPartitionSettings{
+		Enabled:   section.Key("partitioning_enabled").MustBool(false),
+		Interval:  interval,
+		Retention: retention,
+		Premake:   section.Key("partitions_premake").MustInt(2),
+	}
+	if !cfg.AnnotationPartitioning.Enabled {
+		return nil
+	}
+	if interval < time.Hour || interval%time.Hour != 0 {
+		return fmt.Errorf("[annotations] partition_interval must be a whole number of hours, got %s", interval)
+	}
+	if retention != 0 && retention < interval {
+		return fmt.Errorf("[annotations] partition_retention must be 0 or at least the partition_interval, got %s", retention)
+	}
+	if cfg.AnnotationPartitioning.Premake < 1 {
+		return fmt.Errorf("[annotations] partitions_premake must be at least 1")
+	}
 	return nil
 }
 
@@ -770,6 +889,18 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+// AnnotationPartitionSettings configures the partitioning of the annotation table by the end time of the
+// annotations.
+type AnnotationPartitionSettings struct {
+	Enabled bool
+	// Interval is the time range of a partition.
+	Interval time.Duration
+	// Retention is how long the partitions are kept after their time range ended. 0 keeps them forever.
+	Retention time.Duration
+	// Premake is the number of partitions created ahead of the current one.
+	Premake int
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -1097,6 +1228,17 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardRequireIfMatchOrgs = make(map[int64]bool)
+	for _, id := range util.SplitString(dashboards.Key("require_if_match_orgs").MustString("")) {
+		orgID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid org id %q in [dashboards] require_if_match_orgs: %w", id, err)
+		}
+		cfg.DashboardRequireIfMatchOrgs[orgID] = true
+	}
+	cfg.DashboardValidateVariables = dashboards.Key("validate_variables").MustBool(true)
+	cfg.DashboardValidateVariablesRunQueries = dashboards.Key("validate_variables_run_queries").MustBool(false)
+	cfg.DashboardValidateVariablesTimeout = dashboards.Key("validate_variables_timeout").MustDuration(5 * time.Second)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1104,6 +1246,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	if err := readServiceAccountSettings(iniFile, cfg); err != nil {
 		return err
 	}
+	readTeamTokenSettings(iniFile, cfg)
 	if err := readAuthSettings(iniFile, cfg); err != nil {
 		return err
 	}
@@ -1116,6 +1259,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	}
 
 	cfg.TempDataLifetime = iniFile.Section("paths").Key("temp_data_lifetime").MustDuration(time.Second * 3600 * 24)
+	cfg.CleanupSchedules = iniFile.Section("cleanup.schedules").KeysHash()
 	cfg.MetricsEndpointEnabled = iniFile.Section("metrics").Key("enabled").MustBool(true)
 	cfg.MetricsEndpointBasicAuthUsername = valueAsString(iniFile.Section("metrics"), "basic_auth_username", "")
 	cfg.MetricsEndpointBasicAuthPassword = valueAsString(iniFile.Section("metrics"), "basic_auth_password", "")
@@ -1145,6 +1289,12 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		cfg.ReportingDistributor = cfg.ReportingDistributor[:100]
 	}
 
+	cfg.UsageStatsOTLPEndpoint = analytics.Key("usage_stats_otlp_endpoint").String()
+	cfg.UsageStatsOTLPInterval = analytics.Key("usage_stats_otlp_interval").MustDuration(time.Hour)
+	if cfg.UsageStatsOTLPInterval < time.Minute {
+		cfg.UsageStatsOTLPInterval = time.Minute
+	}
+
 	cfg.ApplicationInsightsConnectionString = analytics.Key("application_insights_connection_string").String()
 	cfg.ApplicationInsightsEndpointUrl = analytics.Key("application_insights_endpoint_url").String()
 	cfg.FeedbackLinksEnabled = analytics.Key("feedback_links_enabled").MustBool(true)
@@ -1208,10 +1358,21 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.readDataSourcesSettings()
 	cfg.readSqlDataSourceSettings()
+	cfg.readPrometheusQueryLimits()
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
 
+	cfg.OAuthTokenForwarding, err = readOAuthTokenForwardingSettings(iniFile)
+	if err != nil {
+		return err
+	}
+
+	cfg.DashboardRedaction, err = readDashboardRedactionSettings(iniFile)
+	if err != nil {
+		return err
+	}
+
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
 		// if the proxy is misconfigured, disable it rather than crashing
@@ -1244,12 +1405,14 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	connStr := valueAsString(cacheServer, "connstr", "")
 	prefix := valueAsString(cacheServer, "prefix", "")
 	encryption := cacheServer.Key("encryption").MustBool(false)
+	encryptedNamespaces := util.SplitString(valueAsString(cacheServer, "encrypted_namespaces", DefaultRemoteCacheEncryptedNamespaces))
 
 	cfg.RemoteCacheOptions = &RemoteCacheOptions{
-		Name:       dbName,
-		ConnStr:    connStr,
-		Prefix:     prefix,
-		Encryption: encryption,
+		Name:                dbName,
+		ConnStr:             connStr,
+		Prefix:              prefix,
+		Encryption:          encryption,
+		EncryptedNamespaces: encryptedNamespaces,
 	}
 
 	geomapSection := iniFile.Section("geomap")
@@ -1289,11 +1452,18 @@ func valueAsString(section *ini.Section, keyName string, defaultValue string) st
 	return section.Key(keyName).MustString(defaultValue)
 }
 
+// DefaultRemoteCacheEncryptedNamespaces are the key prefixes of the remote cache items that hold credentials:
+// ID tokens, JSON Web Key Sets, auth proxy sessions and render keys.
+const DefaultRemoteCacheEncryptedNamespaces = "id-token signingkeys-jwks auth-jwt:jwk- azuread_oauth_jwks- authn-proxy-sync-ttl render-"
+
 type RemoteCacheOptions struct {
 	Name       string
 	ConnStr    string
 	Prefix     string
 	Encryption bool
+	// EncryptedNamespaces are the key prefixes of the items that are encrypted
+	// even when the encryption of every item is disabled.
+	EncryptedNamespaces []string
 }
 
 func (cfg *Cfg) readSAMLConfig() {
@@ -1567,6 +1737,11 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	}
 
 	cfg.OAuthAllowInsecureEmailLookup = auth.Key("oauth_allow_insecure_email_lookup").MustBool(false)
+	cfg.IdentityEmailConflict = valueAsString(auth, "identity_email_conflict", IdentityEmailConflictLink)
+	if cfg.IdentityEmailConflict != IdentityEmailConflictLink && cfg.IdentityEmailConflict != IdentityEmailConflictReject {
+		return fmt.Errorf("invalid [auth] identity_email_conflict %q, expected %q or %q",
+			cfg.IdentityEmailConflict, IdentityEmailConflictLink, IdentityEmailConflictReject)
+	}
 
 	const defaultMaxLifetime = "30d"
 	maxLifetimeDurationVal := valueAsString(auth, "login_maximum_lifetime_duration", defaultMaxLifetime)
@@ -1581,6 +1756,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	if cfg.TokenRotationIntervalMinutes < 2 {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
+	cfg.NewDeviceLoginEmail = auth.Key("new_device_login_email").MustBool(false)
 
 	// Do not use
 	cfg.AuthConfigUIAdminAccess = auth.Key("config_ui_admin_access").MustBool(false)
@@ -1776,9 +1952,20 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SAClientCredentialsEnabled = serviceAccount.Key("client_credentials_enabled").MustBool(false)
+	cfg.SAClientCredentialsTokenLifetime = serviceAccount.Key("client_credentials_token_lifetime").MustDuration(10 * time.Minute)
+	if cfg.SAClientCredentialsTokenLifetime <= 0 {
+		return fmt.Errorf("[service_accounts] client_credentials_token_lifetime must be positive")
+	}
 	return nil
 }
 
+func readTeamTokenSettings(iniFile *ini.File, cfg *Cfg) {
+	teamTokens := iniFile.Section("team_tokens")
+	cfg.TeamTokenExpirationDayLimit = teamTokens.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.TeamTokenInactivityDayLimit = teamTokens.Key("inactivity_day_limit").MustInt(-1)
+}
+
 func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	renderSec := iniFile.Section("rendering")
 	cfg.RendererUrl = valueAsString(renderSec, "server_url", "")
@@ -1800,6 +1987,12 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererBudget = renderSec.Key("concurrent_render_budget").MustInt(0)
+	cfg.RendererBudgetQueueSize = renderSec.Key("render_budget_queue_size").MustInt(100)
+	cfg.RendererBudgetQueueTimeout = renderSec.Key("render_budget_queue_timeout").MustDuration(30 * time.Second)
+	cfg.RendererBudgetWeightInteractive = renderSec.Key("render_budget_weight_interactive").MustInt(3)
+	cfg.RendererBudgetWeightReport = renderSec.Key("render_budget_weight_report").MustInt(1)
+	cfg.RendererBudgetWeightAlertScreenshot = renderSec.Key("render_budget_weight_alert_screenshot").MustInt(2)
 	cfg.RendererRenderKeyLifeTime = renderSec.Key("render_key_lifetime").MustDuration(5 * time.Minute)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
@@ -1849,6 +2042,10 @@ func readGRPCServerSettings(cfg *Cfg, iniFile *ini.File) error {
 
 	cfg.GRPCServerNetwork = valueAsString(server, "network", "tcp")
 	cfg.GRPCServerAddress = valueAsString(server, "address", "")
+	cfg.GRPCServerResourcesWatchInterval = server.Key("resources_watch_interval").MustDuration(10 * time.Second)
+	if cfg.GRPCServerResourcesWatchInterval <= 0 {
+		return fmt.Errorf("%s resources_watch_interval must be positive", errPrefix)
+	}
 	switch cfg.GRPCServerNetwork {
 	case "unix":
 		if cfg.GRPCServerAddress != "" {
@@ -1909,6 +2106,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapShotRemoveExpired = snapshots.Key("snapshot_remove_expired").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.SnapshotExpiryReminder = snapshots.Key("expiry_reminder").MustDuration(0)
 
 	return nil
 }
@@ -1958,6 +2156,11 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 
 	cfg.EnableGzip = server.Key("enable_gzip").MustBool(false)
 	cfg.EnforceDomain = server.Key("enforce_domain").MustBool(false)
+	cfg.TrustedProxies, err = network.ParseCIDRs(util.SplitString(valueAsString(server, "trusted_proxies", "")))
+	if err != nil {
+		return fmt.Errorf("invalid trusted_proxies: %w", err)
+	}
+	cfg.ClientIPHeaders = util.SplitString(valueAsString(server, "client_ip_headers", "X-Real-IP, X-Forwarded-For, Forwarded"))
 	staticRoot := valueAsString(server, "static_root_path", "")
 	StaticRootPath = makeAbsolute(staticRoot, HomePath)
 	cfg.StaticRootPath = StaticRootPath
@@ -1973,8 +2176,18 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 			return err
 		}
 	}
+	cfg.CDNSigningKey = valueAsString(server, "cdn_signing_key", "")
+	cfg.CDNSignedPaths = util.SplitString(valueAsString(server, "cdn_signed_paths", ""))
+	cfg.CDNSignedURLExpiration = server.Key("cdn_signed_url_expiration").MustDuration(time.Hour)
+	if len(cfg.CDNSignedPaths) > 0 && cfg.CDNSigningKey == "" {
+		return errors.New("cdn_signing_key is required to sign the URLs of cdn_signed_paths")
+	}
+	if cfg.CDNSignedURLExpiration <= 0 {
+		return errors.New("cdn_signed_url_expiration must be positive")
+	}
 
 	cfg.ReadTimeout = server.Key("read_timeout").MustDuration(0)
+	cfg.DrainTimeout = server.Key("drain_timeout").MustDuration(30 * time.Second)
 
 	headersSection := cfg.Raw.Section("server.custom_response_headers")
 	keys := headersSection.Keys()
@@ -2004,6 +2217,13 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) (string, error) {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	if credentialsPath := datasources.Key("credentials_path").String(); credentialsPath != "" {
+		cfg.DataSourceCredentialsPath = makeAbsolute(credentialsPath, cfg.HomePath)
+	}
+	cfg.DataSourceCredentialsRotationInterval = datasources.Key("credentials_rotation_interval").MustDuration(0)
+	cfg.DataSourceCacheInvalidationInterval = datasources.Key("cache_invalidation_interval").MustDuration(time.Second)
+	cfg.DataSourceEnforceQueryPermissions = datasources.Key("enforce_query_permissions").MustBool(false)
+	cfg.DataSourceSecureJSONDataVersions = datasources.Key("secure_json_data_versions").MustInt(10)
 }
 
 func (cfg *Cfg) readSqlDataSourceSettings() {
@@ -2013,6 +2233,50 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
 }
 
+// PrometheusQueryLimits are the guardrails applied to the queries of Prometheus data sources.
+type PrometheusQueryLimits struct {
+	// MaxSamplesPerSeries is the maximum number of samples per series returned by range queries.
+	// The step of queries that would return more samples is increased. 0 disables the limit.
+	MaxSamplesPerSeries int64
+	// MaxSeries is the maximum number of series returned by a query. 0 disables the limit.
+	MaxSeries int64
+}
+
+const prometheusOrgQueryLimitsPrefix = "prometheus_query_limits.org_"
+
+func (cfg *Cfg) readPrometheusQueryLimits() {
+	section := cfg.Raw.Section("prometheus_query_limits")
+	cfg.PrometheusQueryLimits = PrometheusQueryLimits{
+		MaxSamplesPerSeries: section.Key("max_samples_per_series").MustInt64(11000),
+		MaxSeries:           section.Key("max_series").MustInt64(0),
+	}
+
+	cfg.PrometheusOrgQueryLimits = make(map[int64]PrometheusQueryLimits)
+	for _, section := range cfg.Raw.Sections() {
+		id, ok := strings.CutPrefix(section.Name(), prometheusOrgQueryLimitsPrefix)
+		if !ok {
+			continue
+		}
+		orgID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			cfg.Logger.Warn("Ignoring Prometheus query limits of invalid org id", "section", section.Name())
+			continue
+		}
+		cfg.PrometheusOrgQueryLimits[orgID] = PrometheusQueryLimits{
+			MaxSamplesPerSeries: section.Key("max_samples_per_series").MustInt64(cfg.PrometheusQueryLimits.MaxSamplesPerSeries),
+			MaxSeries:           section.Key("max_series").MustInt64(cfg.PrometheusQueryLimits.MaxSeries),
+		}
+	}
+}
+
+// PrometheusQueryLimitsForOrg returns the Prometheus query limits of the org.
+func (cfg *Cfg) PrometheusQueryLimitsForOrg(orgID int64) PrometheusQueryLimits {
+	if limits, ok := cfg.PrometheusOrgQueryLimits[orgID]; ok {
+		return limits
+	}
+	return cfg.PrometheusQueryLimits
+}
+
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
 	allowedOrigins := originPatterns
 	originGlobs := make([]glob.Glob, 0, len(allowedOrigins))
@@ -2040,6 +2304,8 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveRemoteWriteDatasourceUID = section.Key("remote_write_datasource_uid").MustString("")
+	cfg.LiveRemoteWritePath = section.Key("remote_write_datasource_path").MustString("/api/v1/write")
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -0,0 +1,52 @@
+package setting
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DashboardRedactionSettings is the policy that controls which fields of the dashboard JSON are
+// removed for the users who cannot edit the dashboard.
+type DashboardRedactionSettings struct {
+	Enabled bool
+	// DatasourceUIDs removes the UIDs of the data source references, keeping their type.
+	DatasourceUIDs bool
+	// URLPatterns removes the URLs, of links and data links for example, matching one of the patterns.
+	URLPatterns []*regexp.Regexp
+	// DescriptionPatterns removes the descriptions of the dashboard and of the panels matching one of
+	// the patterns.
+	DescriptionPatterns []*regexp.Regexp
+}
+
+func readDashboardRedactionSettings(iniFile *ini.File) (DashboardRedactionSettings, error) {
+	section := iniFile.Section("dashboard_redaction")
+	s := DashboardRedactionSettings{
+		Enabled:        section.Key("enabled").MustBool(false),
+		DatasourceUIDs: section.Key("datasource_uids").MustBool(false),
+	}
+
+	var err error
+	if s.URLPatterns, err = compilePatterns(section.Key("url_patterns").MustString("")); err != nil {
+		return s, fmt.Errorf("invalid [dashboard_redaction] url_patterns: %w", err)
+	}
+	if s.DescriptionPatterns, err = compilePatterns(section.Key("description_patterns").MustString("")); err != nil {
+		return s, fmt.Errorf("invalid [dashboard_redaction] description_patterns: %w", err)
+	}
+	return s, nil
+}
+
+func compilePatterns(value string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, p := range util.SplitString(value) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,39 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadDashboardRedactionSettings(t *testing.T) {
+	t.Run("compiles the patterns", func(t *testing.T) {
+		iniFile := ini.Empty()
+		section, err := iniFile.NewSection("dashboard_redaction")
+		require.NoError(t, err)
+		_, _ = section.NewKey("enabled", "true")
+		_, _ = section.NewKey("url_patterns", `["^https://[^/]+\\.internal/", "wiki,confluence"]`)
+		_, _ = section.NewKey("description_patterns", `secret owner:`)
+
+		s, err := readDashboardRedactionSettings(iniFile)
+		require.NoError(t, err)
+		assert.True(t, s.Enabled)
+		assert.False(t, s.DatasourceUIDs)
+		require.Len(t, s.URLPatterns, 2)
+		assert.True(t, s.URLPatterns[0].MatchString("https://wiki.internal/page"))
+		assert.Equal(t, "wiki,confluence", s.URLPatterns[1].String())
+		require.Len(t, s.DescriptionPatterns, 2)
+	})
+
+	t.Run("fails on invalid patterns", func(t *testing.T) {
+		iniFile := ini.Empty()
+		section, err := iniFile.NewSection("dashboard_redaction")
+		require.NoError(t, err)
+		_, _ = section.NewKey("description_patterns", `owner(`)
+
+		_, err = readDashboardRedactionSettings(iniFile)
+		require.ErrorContains(t, err, "invalid [dashboard_redaction] description_patterns")
+	})
+}
@@ -0,0 +1,49 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// OAuthTokenForwardingSettings is the policy that controls which data sources receive the
+// forwarded OAuth identity of the users.
+type OAuthTokenForwardingSettings struct {
+	// AllowedDataSources are the UIDs of the data sources, or the types prefixed with "type:",
+	// that may receive the forwarded OAuth identity. Every data source is allowed when empty.
+	AllowedDataSources []string
+
+	// TokenExchangeType is the flow used to exchange the tokens, either "rfc8693" or "azure_obo".
+	TokenExchangeType string
+	// TokenExchangeURL is the token endpoint used to exchange the access token of the users for a token
+	// with the audience of the data source. Tokens are not exchanged when empty.
+	TokenExchangeURL          string
+	TokenExchangeClientID     string
+	TokenExchangeClientSecret string
+	TokenExchangeTimeout      time.Duration
+}
+
+func readOAuthTokenForwardingSettings(iniFile *ini.File) (OAuthTokenForwardingSettings, error) {
+	section := iniFile.Section("oauth_token_forwarding")
+	s := OAuthTokenForwardingSettings{
+		AllowedDataSources:        util.SplitString(section.Key("allowed_datasources").MustString("")),
+		TokenExchangeType:         section.Key("token_exchange_type").MustString("rfc8693"),
+		TokenExchangeURL:          section.Key("token_exchange_url").MustString(""),
+		TokenExchangeClientID:     section.Key("token_exchange_client_id").MustString(""),
+		TokenExchangeClientSecret: section.Key("token_exchange_client_secret").MustString(""),
+		TokenExchangeTimeout:      section.Key("token_exchange_timeout").MustDuration(10 * time.Second),
+	}
+
+	if s.TokenExchangeURL != "" && s.TokenExchangeClientID == "" {
+		return s, errors.New("token_exchange_client_id is required to exchange tokens")
+	}
+	if s.TokenExchangeType != "rfc8693" && s.TokenExchangeType != "azure_obo" {
+		return s, fmt.Errorf("unknown token_exchange_type %q", s.TokenExchangeType)
+	}
+
+	return s, nil
+}
@@ -6,6 +6,10 @@ type OrgQuota struct {
 	Dashboard  int64 `target:"dashboard"`
 	ApiKey     int64 `target:"api_key"`
 	AlertRule  int64 `target:"alert_rule"`
+	// APIRequestMonthly and QueryMonthly are the quotas of the API requests and the data source
+	// queries of an organization per calendar month.
+	APIRequestMonthly int64 `target:"api_request"`
+	QueryMonthly      int64 `target:"query"`
 }
 
 type UserQuota struct {
@@ -29,6 +33,10 @@ type QuotaSettings struct {
 	Org     OrgQuota
 	User    UserQuota
 	Global  GlobalQuota
+
+	// MonthlyGracePercent is the percentage of the monthly quotas that organizations can exceed
+	// before their requests are rejected.
+	MonthlyGracePercent int64
 }
 
 func (cfg *Cfg) readQuotaSettings() {
@@ -49,7 +57,11 @@ func (cfg *Cfg) readQuotaSettings() {
 		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
 		ApiKey:     quota.Key("org_api_key").MustInt64(10),
 		AlertRule:  alertOrgQuota,
+
+		APIRequestMonthly: quota.Key("org_api_request_monthly").MustInt64(-1),
+		QueryMonthly:      quota.Key("org_query_monthly").MustInt64(-1),
 	}
+	cfg.Quota.MonthlyGracePercent = quota.Key("monthly_grace_percent").MustInt64(10)
 
 	// per User limits
 	cfg.Quota.User = UserQuota{
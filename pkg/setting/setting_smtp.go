@@ -15,8 +15,18 @@ type SmtpSettings struct {
 	StartTLSPolicy string
 	SkipVerify     bool
 
+	// Provider is the transport of the emails: smtp, or the HTTP API of ses,
+	// sendgrid or mailgun.
+	Provider           string
+	APIKey             string
+	APIURL             string
+	MailgunDomain      string
+	SESRegion          string
+	EventsWebhookToken string
+
 	SendWelcomeEmailOnSignUp bool
 	TemplatesPatterns        []string
+	TemplatesLocalesPath     string
 	ContentTypes             []string
 }
 
@@ -33,9 +43,16 @@ func (cfg *Cfg) readSmtpSettings() {
 	cfg.Smtp.EhloIdentity = sec.Key("ehlo_identity").String()
 	cfg.Smtp.StartTLSPolicy = sec.Key("startTLS_policy").String()
 	cfg.Smtp.SkipVerify = sec.Key("skip_verify").MustBool(false)
+	cfg.Smtp.Provider = sec.Key("provider").MustString("smtp")
+	cfg.Smtp.APIKey = sec.Key("api_key").String()
+	cfg.Smtp.APIURL = sec.Key("api_url").String()
+	cfg.Smtp.MailgunDomain = sec.Key("mailgun_domain").String()
+	cfg.Smtp.SESRegion = sec.Key("ses_region").String()
+	cfg.Smtp.EventsWebhookToken = sec.Key("events_webhook_token").String()
 
 	emails := cfg.Raw.Section("emails")
 	cfg.Smtp.SendWelcomeEmailOnSignUp = emails.Key("welcome_email_on_sign_up").MustBool(false)
 	cfg.Smtp.TemplatesPatterns = util.SplitString(emails.Key("templates_pattern").MustString("emails/*.html, emails/*.txt"))
+	cfg.Smtp.TemplatesLocalesPath = emails.Key("templates_locales_path").MustString("emails/locales")
 	cfg.Smtp.ContentTypes = util.SplitString(emails.Key("content_types").MustString("text/html"))
 }
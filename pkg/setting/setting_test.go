@@ -325,6 +325,27 @@ func TestParseAppURLAndSubURL(t *testing.T) {
 	}
 }
 
+func TestPrometheusQueryLimits(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[prometheus_query_limits]
+max_series = 100
+
+[prometheus_query_limits.org_2]
+max_samples_per_series = 500
+
+[prometheus_query_limits.org_invalid]
+max_series = 1
+`))
+	require.NoError(t, err)
+	cfg := NewCfg()
+	cfg.Raw = f
+	cfg.readPrometheusQueryLimits()
+
+	require.Equal(t, PrometheusQueryLimits{MaxSamplesPerSeries: 11000, MaxSeries: 100}, cfg.PrometheusQueryLimitsForOrg(1))
+	require.Equal(t, PrometheusQueryLimits{MaxSamplesPerSeries: 500, MaxSeries: 100}, cfg.PrometheusQueryLimitsForOrg(2))
+	require.Len(t, cfg.PrometheusOrgQueryLimits, 1)
+}
+
 func TestAuthDurationSettings(t *testing.T) {
 	const maxInactiveDaysTest = 240 * time.Hour
 
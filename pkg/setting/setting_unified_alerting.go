@@ -3,6 +3,7 @@ package setting
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -50,6 +51,7 @@ const (
 	schedulerDefaultMaxAttempts             = 1
 	schedulerDefaultLegacyMinInterval       = 1
 	screenshotsDefaultCapture               = false
+	screenshotsDefaultCaptureSummaryCards   = false
 	screenshotsDefaultCaptureTimeout        = 10 * time.Second
 	screenshotsMaxCaptureTimeout            = 30 * time.Second
 	screenshotsDefaultMaxConcurrent         = 5
@@ -61,6 +63,18 @@ const (
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
+	deliveryLogDefaultEnabled     = true
+	deliveryLogDefaultRetention   = 7 * 24 * time.Hour
+	emailDigestDefaultWindow      = time.Hour
+	emailDigestDefaultGroupBy     = EmailDigestGroupByRule
+	emailDigestDefaultSeverities  = "info,warning"
+	emailDigestDefaultLabel       = "severity"
+
+	stateHistoryDefaultLokiBufferMaxMemoryBytes   = 16 << 20
+	stateHistoryDefaultLokiBufferMaxDiskBytes     = 256 << 20
+	stateHistoryDefaultLokiBufferMaxRetryInterval = time.Minute
+
+	remoteEvaluationDefaultFailureBackoff = 30 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -95,6 +109,9 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	DeliveryLog                   UnifiedAlertingDeliveryLogSettings
+	EmailDigest                   UnifiedAlertingEmailDigestSettings
+	RemoteEvaluation              UnifiedAlertingRemoteEvaluationSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	Upgrade                       UnifiedAlertingUpgradeSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -110,8 +127,33 @@ type RemoteAlertmanagerSettings struct {
 	Password string
 }
 
+// UnifiedAlertingRemoteEvaluationSettings configures the evaluation of alert rules
+// on a pool of runners, which are Grafana instances that serve evaluation requests
+// over the GRPC server.
+type UnifiedAlertingRemoteEvaluationSettings struct {
+	// RunnerEnabled makes the instance serve the evaluation requests of other instances.
+	RunnerEnabled bool
+	// Runners are the addresses of the runners the scheduler dispatches the evaluations to.
+	// If empty, the rules are evaluated by the scheduler's own instance.
+	Runners []string
+	// Token authenticates the scheduler to the runners.
+	Token         string
+	TLS           bool
+	TLSSkipVerify bool
+	// FailureBackoff is how long a runner is skipped after it failed to answer.
+	FailureBackoff time.Duration
+	// FallbackToLocal evaluates the rules locally when no runner is available.
+	FallbackToLocal bool
+}
+
+// IsEnabled returns true if the evaluations are dispatched to remote runners.
+func (s UnifiedAlertingRemoteEvaluationSettings) IsEnabled() bool {
+	return len(s.Runners) > 0
+}
+
 type UnifiedAlertingScreenshotSettings struct {
 	Capture                    bool
+	CaptureSummaryCards        bool
 	CaptureTimeout             time.Duration
 	MaxConcurrentScreenshots   int64
 	UploadExternalImageStorage bool
@@ -135,6 +177,51 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	LokiWriteBuffer       UnifiedAlertingStateHistoryLokiBufferSettings
+}
+
+// UnifiedAlertingStateHistoryLokiBufferSettings configures the buffering of the state history written to Loki.
+type UnifiedAlertingStateHistoryLokiBufferSettings struct {
+	Enabled bool
+	// MaxMemoryBytes is the maximum size of the state history kept in memory before it is spilled to disk.
+	MaxMemoryBytes int64
+	// Path is the directory where the state history is spilled to.
+	Path string
+	// MaxDiskBytes is the maximum size of the state history spilled to disk. The oldest state history is dropped
+	// when it is exceeded.
+	MaxDiskBytes int64
+	// MaxRetryInterval is the maximum time between two attempts to write to Loki while it is unavailable.
+	MaxRetryInterval time.Duration
+}
+
+type UnifiedAlertingDeliveryLogSettings struct {
+	Enabled bool
+	// Retention is how long notification delivery attempts are kept for.
+	Retention time.Duration
+}
+
+const (
+	EmailDigestGroupByRule   = "rule"
+	EmailDigestGroupByFolder = "folder"
+)
+
+type UnifiedAlertingEmailDigestSettings struct {
+	Enabled bool
+	// Window is how often the batched email notifications are sent in a digest email.
+	Window time.Duration
+	// GroupBy is how the alerts of a digest email are grouped, either by alert rule or by folder.
+	GroupBy string
+	// SeverityLabel is the label of the alerts that holds their severity.
+	SeverityLabel string
+	// Severities are the severities of the alerts whose email notifications are batched. The notifications
+	// of the alerts with another severity, or without severity, are sent immediately.
+	Severities map[string]struct{}
+}
+
+// IsBatched returns true if the email notifications of the alerts with the given severity are batched into digests.
+func (u *UnifiedAlertingEmailDigestSettings) IsBatched(severity string) bool {
+	_, ok := u.Severities[strings.ToLower(severity)]
+	return ok
 }
 
 type UnifiedAlertingUpgradeSettings struct {
@@ -358,6 +445,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfgScreenshots := uaCfg.Screenshots
 
 	uaCfgScreenshots.Capture = screenshots.Key("capture").MustBool(screenshotsDefaultCapture)
+	uaCfgScreenshots.CaptureSummaryCards = screenshots.Key("capture_summary_cards").MustBool(screenshotsDefaultCaptureSummaryCards)
 
 	captureTimeout := screenshots.Key("capture_timeout").MustDuration(screenshotsDefaultCaptureTimeout)
 	if captureTimeout > screenshotsMaxCaptureTimeout {
@@ -393,8 +481,77 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.LokiWriteBuffer = UnifiedAlertingStateHistoryLokiBufferSettings{
+		Enabled:        stateHistory.Key("loki_write_buffer_enabled").MustBool(false),
+		MaxMemoryBytes: stateHistory.Key("loki_write_buffer_max_memory_bytes").MustInt64(stateHistoryDefaultLokiBufferMaxMemoryBytes),
+		Path:           stateHistory.Key("loki_write_buffer_path").MustString(filepath.Join(cfg.DataPath, "alerting", "state-history")),
+		MaxDiskBytes:   stateHistory.Key("loki_write_buffer_max_disk_bytes").MustInt64(stateHistoryDefaultLokiBufferMaxDiskBytes),
+	}
+	uaCfgStateHistory.LokiWriteBuffer.MaxRetryInterval, err = gtime.ParseDuration(valueAsString(stateHistory, "loki_write_buffer_max_retry_interval", stateHistoryDefaultLokiBufferMaxRetryInterval.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'loki_write_buffer_max_retry_interval' in section 'unified_alerting.state_history' as duration: %w", err)
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	deliveryLog := iniFile.Section("unified_alerting.delivery_log")
+	uaCfgDeliveryLog := UnifiedAlertingDeliveryLogSettings{
+		Enabled: deliveryLog.Key("enabled").MustBool(deliveryLogDefaultEnabled),
+	}
+	uaCfgDeliveryLog.Retention, err = gtime.ParseDuration(valueAsString(deliveryLog, "retention", deliveryLogDefaultRetention.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'retention' in section 'unified_alerting.delivery_log' as duration: %w", err)
+	}
+	if uaCfgDeliveryLog.Retention <= 0 {
+		return fmt.Errorf("value of setting 'retention' in section 'unified_alerting.delivery_log' should be greater than 0")
+	}
+	uaCfg.DeliveryLog = uaCfgDeliveryLog
+
+	emailDigest := iniFile.Section("unified_alerting.email_digest")
+	uaCfgEmailDigest := UnifiedAlertingEmailDigestSettings{
+		Enabled:       emailDigest.Key("enabled").MustBool(false),
+		GroupBy:       strings.ToLower(emailDigest.Key("group_by").MustString(emailDigestDefaultGroupBy)),
+		SeverityLabel: emailDigest.Key("severity_label").MustString(emailDigestDefaultLabel),
+		Severities:    make(map[string]struct{}),
+	}
+	for _, severity := range util.SplitString(emailDigest.Key("severities").MustString(emailDigestDefaultSeverities)) {
+		uaCfgEmailDigest.Severities[strings.ToLower(severity)] = struct{}{}
+	}
+	uaCfgEmailDigest.Window, err = gtime.ParseDuration(valueAsString(emailDigest, "window", emailDigestDefaultWindow.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'window' in section 'unified_alerting.email_digest' as duration: %w", err)
+	}
+	if uaCfgEmailDigest.Window <= 0 {
+		return fmt.Errorf("value of setting 'window' in section 'unified_alerting.email_digest' should be greater than 0")
+	}
+	if uaCfgEmailDigest.GroupBy != EmailDigestGroupByRule && uaCfgEmailDigest.GroupBy != EmailDigestGroupByFolder {
+		return fmt.Errorf("value of setting 'group_by' in section 'unified_alerting.email_digest' should be either '%s' or '%s'", EmailDigestGroupByRule, EmailDigestGroupByFolder)
+	}
+	if uaCfgEmailDigest.SeverityLabel == "" {
+		return fmt.Errorf("setting 'severity_label' in section 'unified_alerting.email_digest' cannot be empty")
+	}
+	uaCfg.EmailDigest = uaCfgEmailDigest
+
+	remoteEvaluation := iniFile.Section("unified_alerting.remote_evaluation")
+	uaCfgRemoteEvaluation := UnifiedAlertingRemoteEvaluationSettings{
+		RunnerEnabled:   remoteEvaluation.Key("runner_enabled").MustBool(false),
+		Runners:         util.SplitString(remoteEvaluation.Key("runners").MustString("")),
+		Token:           remoteEvaluation.Key("token").MustString(""),
+		TLS:             remoteEvaluation.Key("tls").MustBool(false),
+		TLSSkipVerify:   remoteEvaluation.Key("tls_skip_verify").MustBool(false),
+		FallbackToLocal: remoteEvaluation.Key("fallback_to_local").MustBool(true),
+	}
+	uaCfgRemoteEvaluation.FailureBackoff, err = gtime.ParseDuration(valueAsString(remoteEvaluation, "failure_backoff", remoteEvaluationDefaultFailureBackoff.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'failure_backoff' in section 'unified_alerting.remote_evaluation' as duration: %w", err)
+	}
+	if uaCfgRemoteEvaluation.FailureBackoff <= 0 {
+		return fmt.Errorf("value of setting 'failure_backoff' in section 'unified_alerting.remote_evaluation' should be greater than 0")
+	}
+	if (uaCfgRemoteEvaluation.RunnerEnabled || uaCfgRemoteEvaluation.IsEnabled()) && uaCfgRemoteEvaluation.Token == "" {
+		return fmt.Errorf("setting 'token' in section 'unified_alerting.remote_evaluation' is required when remote evaluation is used")
+	}
+	uaCfg.RemoteEvaluation = uaCfgRemoteEvaluation
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	upgrade := iniFile.Section("unified_alerting.upgrade")
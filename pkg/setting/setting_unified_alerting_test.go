@@ -72,6 +72,60 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 			require.Equal(t, SchedulerBaseInterval, cfg.UnifiedAlerting.BaseInterval)
 		})
 	})
+
+	t.Run("should read 'unified_alerting.remote_evaluation'", func(t *testing.T) {
+		require.False(t, cfg.UnifiedAlerting.RemoteEvaluation.IsEnabled())
+		require.True(t, cfg.UnifiedAlerting.RemoteEvaluation.FallbackToLocal)
+		require.Equal(t, 30*time.Second, cfg.UnifiedAlerting.RemoteEvaluation.FailureBackoff)
+
+		s, err := cfg.Raw.NewSection("unified_alerting.remote_evaluation")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			cfg.Raw.DeleteSection("unified_alerting.remote_evaluation")
+		})
+		_, err = s.NewKey("runners", "runner1:10000, runner2:10000")
+		require.NoError(t, err)
+
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw), "token")
+
+		_, err = s.NewKey("token", "secret")
+		require.NoError(t, err)
+		_, err = s.NewKey("failure_backoff", "1m")
+		require.NoError(t, err)
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.True(t, cfg.UnifiedAlerting.RemoteEvaluation.IsEnabled())
+		require.Equal(t, []string{"runner1:10000", "runner2:10000"}, cfg.UnifiedAlerting.RemoteEvaluation.Runners)
+		require.Equal(t, time.Minute, cfg.UnifiedAlerting.RemoteEvaluation.FailureBackoff)
+	})
+
+	t.Run("should read 'unified_alerting.email_digest'", func(t *testing.T) {
+		require.False(t, cfg.UnifiedAlerting.EmailDigest.Enabled)
+		require.Equal(t, time.Hour, cfg.UnifiedAlerting.EmailDigest.Window)
+		require.Equal(t, EmailDigestGroupByRule, cfg.UnifiedAlerting.EmailDigest.GroupBy)
+		require.True(t, cfg.UnifiedAlerting.EmailDigest.IsBatched("Warning"))
+		require.False(t, cfg.UnifiedAlerting.EmailDigest.IsBatched("critical"))
+		require.False(t, cfg.UnifiedAlerting.EmailDigest.IsBatched(""))
+
+		s, err := cfg.Raw.NewSection("unified_alerting.email_digest")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			cfg.Raw.DeleteSection("unified_alerting.email_digest")
+		})
+		_, err = s.NewKey("group_by", "team")
+		require.NoError(t, err)
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw), "group_by")
+
+		s.Key("group_by").SetValue("Folder")
+		_, err = s.NewKey("severities", "low, medium")
+		require.NoError(t, err)
+		_, err = s.NewKey("window", "30m")
+		require.NoError(t, err)
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.Equal(t, EmailDigestGroupByFolder, cfg.UnifiedAlerting.EmailDigest.GroupBy)
+		require.Equal(t, 30*time.Minute, cfg.UnifiedAlerting.EmailDigest.Window)
+		require.True(t, cfg.UnifiedAlerting.EmailDigest.IsBatched("medium"))
+		require.False(t, cfg.UnifiedAlerting.EmailDigest.IsBatched("warning"))
+	})
 }
 
 func TestUnifiedAlertingSettings(t *testing.T) {
@@ -363,12 +363,32 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 func (s *Service) executeTimeSeriesQuery(ctx context.Context, req *backend.QueryDataRequest, dsInfo datasourceInfo, queries []cloudMonitoringQueryExecutor) (
 	*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
+	// identical requests of several queries are sent once, see batchedQuery
+	batches := map[string]queryBatch{}
 	for _, queryExecutor := range queries {
-		queryRes, dr, executedQueryString, err := queryExecutor.run(ctx, req, s, dsInfo, s.tracer)
-		if err != nil {
-			return resp, err
+		key := ""
+		if batched, ok := queryExecutor.(batchedQuery); ok {
+			key = batched.batchKey()
+		}
+
+		var queryRes *backend.DataResponse
+		var dr any
+		var executedQueryString string
+		if batch, ok := batches[key]; ok && key != "" {
+			res := batch.queryRes
+			queryRes, dr, executedQueryString = &res, batch.response, batch.executedQueryString
+		} else {
+			var err error
+			queryRes, dr, executedQueryString, err = queryExecutor.run(ctx, req, s, dsInfo, s.tracer)
+			if err != nil {
+				return resp, err
+			}
+			if key != "" {
+				batches[key] = queryBatch{queryRes: *queryRes, response: dr, executedQueryString: executedQueryString}
+			}
 		}
-		err = queryExecutor.parseResponse(queryRes, dr, executedQueryString)
+
+		err := queryExecutor.parseResponse(queryRes, dr, executedQueryString)
 		if err != nil {
 			queryRes.Error = err
 		}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strings"
@@ -14,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/tsdb/cloud-monitoring/kinds/dataquery"
 
 	"github.com/stretchr/testify/assert"
@@ -1167,3 +1170,109 @@ func TestCheckHealth(t *testing.T) {
 		}, res)
 	})
 }
+
+func TestExecuteTimeSeriesQuery(t *testing.T) {
+	response := `{"timeSeries": [{"metric": {"type": "compute.googleapis.com/instance/cpu/usage_time"}, "points": [{"interval": {"endTime": "2018-01-01T00:00:00Z"}, "value": {"doubleValue": 1}}]}]}`
+
+	newService := func(t *testing.T, handler http.HandlerFunc) (*Service, datasourceInfo) {
+		t.Helper()
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+		dsInfo := datasourceInfo{
+			services: map[string]datasourceService{
+				cloudMonitor: {url: srv.URL, client: srv.Client()},
+			},
+		}
+		return &Service{tracer: tracing.InitializeTracerForTest()}, dsInfo
+	}
+
+	newQuery := func(refID string, filter string) *cloudMonitoringTimeSeriesList {
+		query := &cloudMonitoringTimeSeriesList{
+			refID:      refID,
+			logger:     slog,
+			parameters: &dataquery.TimeSeriesList{ProjectName: "test-proj", Filters: []string{filter}},
+		}
+		query.setParams(time.Unix(0, 0), time.Unix(3600, 0), 3600, 60000)
+		return query
+	}
+
+	req := &backend.QueryDataRequest{Queries: []backend.DataQuery{{TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)}}}}
+
+	t.Run("sends identical requests once", func(t *testing.T) {
+		requests := 0
+		s, dsInfo := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_, err := w.Write([]byte(response))
+			require.NoError(t, err)
+		})
+
+		res, err := s.executeTimeSeriesQuery(context.Background(), req, dsInfo, []cloudMonitoringQueryExecutor{
+			newQuery("A", "metric.type=\"a\""),
+			newQuery("B", "metric.type=\"a\""),
+			newQuery("C", "metric.type=\"b\""),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+		for _, refID := range []string{"A", "B", "C"} {
+			require.NoError(t, res.Responses[refID].Error)
+			require.Len(t, res.Responses[refID].Frames, 1)
+			assert.Equal(t, refID, res.Responses[refID].Frames[0].RefID)
+		}
+	})
+
+	t.Run("retries the requests rejected because the quota is exhausted", func(t *testing.T) {
+		requests := 0
+		s, dsInfo := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, err := w.Write([]byte(response))
+			require.NoError(t, err)
+		})
+
+		res, err := s.executeTimeSeriesQuery(context.Background(), req, dsInfo, []cloudMonitoringQueryExecutor{newQuery("A", "metric.type=\"a\"")})
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+	})
+
+	t.Run("gives up once the retries are exhausted", func(t *testing.T) {
+		backoff := quotaRetryBackoff
+		quotaRetryBackoff = time.Millisecond
+		t.Cleanup(func() { quotaRetryBackoff = backoff })
+
+		requests := 0
+		s, dsInfo := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		res, err := s.executeTimeSeriesQuery(context.Background(), req, dsInfo, []cloudMonitoringQueryExecutor{newQuery("A", "metric.type=\"a\"")})
+		require.NoError(t, err)
+		assert.Equal(t, maxQuotaRetries+1, requests)
+		require.Error(t, res.Responses["A"].Error)
+	})
+
+	t.Run("follows the pages of the response", func(t *testing.T) {
+		tokens := []string{}
+		s, dsInfo := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("pageToken")
+			tokens = append(tokens, token)
+			body := response
+			if token == "" {
+				body = strings.Replace(response, `{"timeSeries"`, `{"nextPageToken": "next", "timeSeries"`, 1)
+			}
+			_, err := w.Write([]byte(body))
+			require.NoError(t, err)
+		})
+
+		res, err := s.executeTimeSeriesQuery(context.Background(), req, dsInfo, []cloudMonitoringQueryExecutor{newQuery("A", "metric.type=\"a\"")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "next"}, tokens)
+		require.Len(t, res.Responses["A"].Frames, 2)
+	})
+}
@@ -16,6 +16,10 @@ func (sloQ *cloudMonitoringSLO) run(ctx context.Context, req *backend.QueryDataR
 	return runTimeSeriesRequest(ctx, sloQ.logger, req, s, dsInfo, tracer, sloQ.parameters.ProjectName, sloQ.params, nil)
 }
 
+func (sloQ *cloudMonitoringSLO) batchKey() string {
+	return sloQ.parameters.ProjectName + "?" + sloQ.params.Encode()
+}
+
 func (sloQ *cloudMonitoringSLO) parseResponse(queryRes *backend.DataResponse,
 	response any, executedQueryString string) error {
 	return parseTimeSeriesResponse(queryRes, response.(cloudMonitoringResponse), executedQueryString, sloQ, sloQ.params, []string{})
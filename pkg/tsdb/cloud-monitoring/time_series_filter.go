@@ -20,6 +20,10 @@ func (timeSeriesFilter *cloudMonitoringTimeSeriesList) run(ctx context.Context,
 	return runTimeSeriesRequest(ctx, timeSeriesFilter.logger, req, s, dsInfo, tracer, timeSeriesFilter.parameters.ProjectName, timeSeriesFilter.params, nil)
 }
 
+func (timeSeriesFilter *cloudMonitoringTimeSeriesList) batchKey() string {
+	return timeSeriesFilter.parameters.ProjectName + "?" + timeSeriesFilter.params.Encode()
+}
+
 func parseTimeSeriesResponse(queryRes *backend.DataResponse,
 	response cloudMonitoringResponse, executedQueryString string, query cloudMonitoringQueryExecutor, params url.Values, groupBys []string) error {
 	frames := data.Frames{}
@@ -110,6 +110,19 @@ type (
 	}
 )
 
+// batchedQuery is implemented by the queries sending a single ListTimeSeries request. The queries of a
+// QueryData request with the same batch key send the same request, which is only sent once.
+type batchedQuery interface {
+	batchKey() string
+}
+
+// queryBatch is the response of a request shared by batched queries.
+type queryBatch struct {
+	queryRes            backend.DataResponse
+	response            any
+	executedQueryString string
+}
+
 type pointIterator interface {
 	length() int
 	getPoint(index int) point
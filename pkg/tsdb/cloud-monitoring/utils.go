@@ -71,19 +71,82 @@ func createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInf
 	return req, nil
 }
 
+const (
+	// maxQuotaRetries is the number of times a request is retried when the per-minute quota is exhausted.
+	maxQuotaRetries = 3
+	// maxQuotaRetryWait caps the time waited before retrying a request, whatever the Retry-After header says.
+	maxQuotaRetryWait = 30 * time.Second
+)
+
+// quotaRetryBackoff is the time waited before the first retry when the response has no Retry-After header,
+// doubled for every following retry.
+var quotaRetryBackoff = time.Second
+
+// quotaRetryWait returns how long to wait before retrying a request rejected because the quota is exhausted.
+func quotaRetryWait(res *http.Response, attempt int) time.Duration {
+	wait := quotaRetryBackoff << attempt
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			wait = time.Until(date)
+		}
+	}
+	if wait > maxQuotaRetryWait {
+		wait = maxQuotaRetryWait
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// doRequestWithQuotaRetry sends the request, retrying it when Cloud Monitoring rejects it because the
+// per-minute quota of the project is exhausted.
+func doRequestWithQuotaRetry(ctx context.Context, logger log.Logger, r *http.Request, dsInfo datasourceInfo, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+		res, err := dsInfo.services[cloudMonitor].client.Do(r)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusTooManyRequests || attempt == maxQuotaRetries {
+			return res, nil
+		}
+
+		wait := quotaRetryWait(res, attempt)
+		if _, err := io.Copy(io.Discard, res.Body); err != nil {
+			logger.Warn("Failed to read response body", "error", err)
+		}
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+		logger.Warn("Cloud Monitoring quota exhausted, retrying the request", "attempt", attempt+1, "wait", wait)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
 func doRequestPage(ctx context.Context, logger log.Logger, r *http.Request, dsInfo datasourceInfo, params url.Values, body map[string]any) (cloudMonitoringResponse, error) {
 	if params != nil {
 		r.URL.RawQuery = params.Encode()
 	}
+	var buf []byte
 	if body != nil {
-		buf, err := json.Marshal(body)
+		var err error
+		buf, err = json.Marshal(body)
 		if err != nil {
 			return cloudMonitoringResponse{}, err
 		}
-		r.Body = io.NopCloser(bytes.NewBuffer(buf))
 		r.Method = http.MethodPost
 	}
-	res, err := dsInfo.services[cloudMonitor].client.Do(r)
+	res, err := doRequestWithQuotaRetry(ctx, logger, r, dsInfo, buf)
 	if err != nil {
 		return cloudMonitoringResponse{}, err
 	}
@@ -39,6 +39,8 @@ type DatasourceInfo struct {
 	MaxConcurrentShardRequests int64
 	IncludeFrozen              bool
 	XPack                      bool
+	// LogsMaxDocuments caps the number of log lines returned by a logs query.
+	LogsMaxDocuments int64
 }
 
 type ConfiguredFields struct {
@@ -50,8 +52,11 @@ type ConfiguredFields struct {
 // Client represents a client which can interact with elasticsearch api
 type Client interface {
 	GetConfiguredFields() ConfiguredFields
+	GetLogsMaxDocuments() int64
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	OpenPointInTime(keepAlive string) (string, error)
+	ClosePointInTime(id string) error
 }
 
 // NewClient creates a new elasticsearch client
@@ -95,6 +100,10 @@ func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
 	return c.configuredFields
 }
 
+func (c *baseClientImpl) GetLogsMaxDocuments() int64 {
+	return c.ds.LogsMaxDocuments
+}
+
 type multiRequest struct {
 	header   map[string]any
 	body     any
@@ -106,7 +115,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -138,7 +147,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*http.Response, error) {
 	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
@@ -148,16 +157,16 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	u.RawQuery = uriQuery
 
 	var req *http.Request
-	if method == http.MethodPost {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodPost, u.String(), bytes.NewBuffer(body))
-	} else {
+	if method == http.MethodGet {
 		req, err = http.NewRequestWithContext(c.ctx, http.MethodGet, u.String(), nil)
+	} else {
+		req, err = http.NewRequestWithContext(c.ctx, method, u.String(), bytes.NewBuffer(body))
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	//nolint:bodyclose
 	resp, err := c.ds.HTTPClient.Do(req)
@@ -243,6 +252,12 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 			body:     searchReq,
 			interval: searchReq.Interval,
 		}
+		// the indices of a search in a point in time are the ones of the point in time
+		if searchReq.CustomProps["pit"] != nil {
+			mr.header = map[string]any{
+				"search_type": "query_then_fetch",
+			}
+		}
 
 		multiRequests = append(multiRequests, &mr)
 	}
@@ -266,6 +281,56 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 	return strings.Join(qs, "&")
 }
 
+// OpenPointInTime opens a point in time on the indices of the client, so that the searches of several pages of
+// results see the same documents. It returns the id of the point in time.
+func (c *baseClientImpl) OpenPointInTime(keepAlive string) (string, error) {
+	uriPath := path.Join(strings.Join(c.indices, ","), "_pit")
+	uriQuery := url.Values{"keep_alive": []string{keepAlive}, "ignore_unavailable": []string{"true"}}.Encode()
+	res, err := c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/json", nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return "", fmt.Errorf("failed to open point in time: status %d", res.StatusCode)
+	}
+
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", err
+	}
+	return pit.ID, nil
+}
+
+// ClosePointInTime closes a point in time opened by OpenPointInTime.
+func (c *baseClientImpl) ClosePointInTime(id string) error {
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+	res, err := c.executeRequest(http.MethodDelete, "_pit", "", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to close point in time: status %d", res.StatusCode)
+	}
+	return nil
+}
+
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder()
 }
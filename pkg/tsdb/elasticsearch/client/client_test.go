@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -231,3 +232,70 @@ func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, erro
 	})
 	return msb.Build()
 }
+
+func TestClient_PointInTime(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(buf))
+
+		switch r.Method {
+		case http.MethodPost:
+			if r.URL.Path == "/_msearch" {
+				_, err = rw.Write([]byte(`{"responses": [{"hits": {"hits": []}, "pit_id": "pit-2"}]}`))
+			} else {
+				_, err = rw.Write([]byte(`{"id": "pit-1"}`))
+			}
+		case http.MethodDelete:
+			_, err = rw.Write([]byte(`{"succeeded": true, "num_freed": 1}`))
+		}
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:              ts.URL,
+		HTTPClient:       ts.Client(),
+		Database:         "[metrics-]YYYY.MM.DD",
+		ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+		Interval:         "Daily",
+	}
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC),
+	}
+	c, err := NewClient(context.Background(), &ds, timeRange, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+
+	id, err := c.OpenPointInTime("1m")
+	require.NoError(t, err)
+	require.Equal(t, "pit-1", id)
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "/metrics-2018.05.15/_pit", requests[0].URL.Path)
+	require.Equal(t, "1m", requests[0].URL.Query().Get("keep_alive"))
+
+	ms := c.MultiSearch()
+	ms.Search(15*time.Second).Size(10).PointInTime(id, "1m")
+	req, err := ms.Build()
+	require.NoError(t, err)
+	res, err := c.ExecuteMultisearch(req)
+	require.NoError(t, err)
+	require.Equal(t, "pit-2", res.Responses[0].PitID)
+
+	// searches in a point in time must not specify the indices
+	header, err := simplejson.NewJson([]byte(strings.Split(bodies[1], "\n")[0]))
+	require.NoError(t, err)
+	_, ok := header.CheckGet("index")
+	require.False(t, ok)
+	body, err := simplejson.NewJson([]byte(strings.Split(bodies[1], "\n")[1]))
+	require.NoError(t, err)
+	require.Equal(t, "pit-1", body.GetPath("pit", "id").MustString())
+
+	require.NoError(t, c.ClosePointInTime(id))
+	require.Equal(t, http.MethodDelete, requests[2].Method)
+	require.Equal(t, "/_pit", requests[2].URL.Path)
+	require.JSONEq(t, `{"id": "pit-1"}`, bodies[2])
+}
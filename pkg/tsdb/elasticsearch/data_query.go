@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"regexp"
 	"slices"
 	"strconv"
@@ -20,6 +21,11 @@ import (
 
 const (
 	defaultSize = 500
+	// logsPageSize is the number of log lines fetched by a single search of a logs query, the default maximum
+	// result window of Elasticsearch. Logs queries with a greater limit read the log lines page by page.
+	logsPageSize = 10000
+	// pointInTimeKeepAlive is how long a point in time is kept between the searches of two pages
+	pointInTimeKeepAlive = "1m"
 )
 
 type elasticsearchDataQuery struct {
@@ -51,12 +57,21 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	pitID := e.openPointInTime(queries)
+	if pitID != "" {
+		defer func() {
+			if err := e.client.ClosePointInTime(pitID); err != nil {
+				e.logger.Warn("Failed to close point in time", "error", err)
+			}
+		}()
+	}
+
 	ms := e.client.MultiSearch()
 
 	from := e.dataQueries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
 	to := e.dataQueries[0].TimeRange.To.UnixNano() / int64(time.Millisecond)
 	for _, q := range queries {
-		if err := e.processQuery(q, ms, from, to); err != nil {
+		if err := e.processQuery(q, ms, from, to, pitID); err != nil {
 			mq, _ := json.Marshal(q)
 			e.logger.Error("Failed to process query to multisearch request builder", "error", err, "query", string(mq), "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
 			return errorsource.AddPluginErrorToResponse(q.RefID, response, err), nil
@@ -77,10 +92,77 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	if pitID != "" {
+		for i, q := range queries {
+			if limit := logsQueryLimit(q, e.client.GetLogsMaxDocuments()); isLogsQuery(q) && limit > logsPageSize && i < len(res.Responses) {
+				e.fetchLogsPages(req.Requests[i], res.Responses[i], limit)
+			}
+		}
+	}
+
 	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
 }
 
-func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
+// openPointInTime opens a point in time when a logs query asks for more log lines than a single search returns,
+// and returns its id. The log lines of these queries are searched page by page in the point in time.
+func (e *elasticsearchDataQuery) openPointInTime(queries []*Query) string {
+	for _, q := range queries {
+		if !isLogsQuery(q) || logsQueryLimit(q, e.client.GetLogsMaxDocuments()) <= logsPageSize {
+			continue
+		}
+
+		pitID, err := e.client.OpenPointInTime(pointInTimeKeepAlive)
+		if err != nil {
+			// Elasticsearch versions before 7.10 don't support points in time, the logs are then searched at once
+			e.logger.Warn("Failed to open point in time", "error", err)
+			return ""
+		}
+		return pitID
+	}
+	return ""
+}
+
+// fetchLogsPages adds the next pages of log lines of a logs query searched in a point in time to the response of
+// the first page, until the limit of the query is reached or there are no more log lines.
+func (e *elasticsearchDataQuery) fetchLogsPages(req *es.SearchRequest, res *es.SearchResponse, limit int) {
+	page := res
+	for page.Error == nil && page.Hits != nil && len(page.Hits.Hits) > 0 && len(page.Hits.Hits) == req.Size && len(res.Hits.Hits) < limit {
+		searchAfter := page.Hits.Hits[len(page.Hits.Hits)-1]["sort"]
+		if searchAfter == nil {
+			return
+		}
+
+		next := *req
+		next.Aggs = nil
+		next.Size = min(logsPageSize, limit-len(res.Hits.Hits))
+		next.CustomProps = maps.Clone(req.CustomProps)
+		next.CustomProps["search_after"] = searchAfter
+		// the id of the point in time can change between searches
+		if page.PitID != "" {
+			next.CustomProps["pit"] = map[string]string{
+				"id":         page.PitID,
+				"keep_alive": pointInTimeKeepAlive,
+			}
+		}
+
+		msr, err := e.client.ExecuteMultisearch(&es.MultiSearchRequest{Requests: []*es.SearchRequest{&next}})
+		if err != nil || len(msr.Responses) == 0 {
+			e.logger.Warn("Failed to fetch the next page of log lines", "error", err, "logLines", len(res.Hits.Hits))
+			return
+		}
+		page = msr.Responses[0]
+		if page.Error != nil {
+			e.logger.Warn("Failed to fetch the next page of log lines", "error", page.Error, "logLines", len(res.Hits.Hits))
+			return
+		}
+		if page.Hits != nil {
+			res.Hits.Hits = append(res.Hits.Hits, page.Hits.Hits...)
+		}
+		req = &next
+	}
+}
+
+func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64, pitID string) error {
 	err := isQueryWithError(q)
 	if err != nil {
 		err = fmt.Errorf("received invalid query. %w", err)
@@ -95,7 +177,7 @@ func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchReques
 	filters.AddQueryStringFilter(q.RawQuery, true)
 
 	if isLogsQuery(q) {
-		processLogsQuery(q, b, from, to, defaultTimeField)
+		processLogsQuery(q, b, from, to, defaultTimeField, e.client.GetLogsMaxDocuments(), pitID)
 	} else if isDocumentQuery(q) {
 		processDocumentQuery(q, b, from, to, defaultTimeField)
 	} else {
@@ -344,7 +426,17 @@ func isRawDocumentQuery(query *Query) bool {
 	return query.Metrics[0].Type == rawDocumentType
 }
 
-func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string) {
+// logsQueryLimit returns the number of log lines asked by a logs query, capped by the maximum number of documents
+// of the data source.
+func logsQueryLimit(q *Query, maxDocuments int64) int {
+	limit := stringToIntWithDefaultValue(q.Metrics[0].Settings.Get("limit").MustString(), defaultSize)
+	if maxDocuments > 0 && int64(limit) > maxDocuments {
+		limit = int(maxDocuments)
+	}
+	return limit
+}
+
+func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string, maxDocuments int64, pitID string) {
 	metric := q.Metrics[0]
 	sort := es.SortOrderDesc
 	if metric.Settings.Get("sortDirection").MustString() == "asc" {
@@ -352,12 +444,21 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 		sort = es.SortOrderAsc
 	}
 	b.Sort(sort, defaultTimeField, "boolean")
-	b.Sort(sort, "_doc", "")
 	b.AddDocValueField(defaultTimeField)
 	// We need to add timeField as field with standardized time format to not receive
 	// invalid formats that elasticsearch can parse, but our frontend can't (e.g. yyyy_MM_dd_HH_mm_ss)
 	b.AddTimeFieldWithStandardizedFormat(defaultTimeField)
-	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("limit").MustString(), defaultSize))
+	limit := logsQueryLimit(q, maxDocuments)
+	if pitID != "" && limit > logsPageSize {
+		// The log lines are searched page by page in the point in time. The _shard_doc tiebreaker
+		// replaces _doc, which isn't unique across the shards of a point in time.
+		b.Sort(sort, "_shard_doc", "")
+		b.PointInTime(pitID, pointInTimeKeepAlive)
+		b.Size(logsPageSize)
+	} else {
+		b.Sort(sort, "_doc", "")
+		b.Size(limit)
+	}
 	b.AddHighlight()
 
 	// This is currently used only for log context query to get
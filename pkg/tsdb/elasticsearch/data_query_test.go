@@ -3,6 +3,8 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -1426,6 +1428,71 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 			require.Equal(t, secondSearchAfter, "2")
 		})
 
+		t.Run("With log query with limit above the maximum documents should cap the size", func(t *testing.T) {
+			c := newFakeClient()
+			c.logsMaxDocuments = 800
+			_, err := executeElasticsearchDataQuery(c, `{
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "1000" }}]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Equal(t, 0, c.openedPointsInTime)
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, 800, sr.Size)
+			require.Nil(t, sr.CustomProps["pit"])
+		})
+
+		t.Run("With log query with limit above the page size should search pages in a point in time", func(t *testing.T) {
+			page := func(size int, pitID string) *es.MultiSearchResponse {
+				hits := make([]map[string]any, size)
+				for i := range hits {
+					hits[i] = map[string]any{"_id": strconv.Itoa(i), "sort": []any{i, i}}
+				}
+				return &es.MultiSearchResponse{Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{Hits: hits}, PitID: pitID}}}
+			}
+
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			c.multiSearchResponses = []*es.MultiSearchResponse{page(10000, "pit-2"), page(10000, "pit-3"), page(5000, "pit-4")}
+			_, err := executeElasticsearchDataQuery(c, `{
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "25000" }}]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Equal(t, 1, c.openedPointsInTime)
+			require.Equal(t, []string{"pit-1"}, c.closedPointsInTime)
+			require.Len(t, c.multisearchRequests, 3)
+
+			first := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, 10000, first.Size)
+			require.Equal(t, map[string]string{"id": "pit-1", "keep_alive": "1m"}, first.CustomProps["pit"])
+			require.Equal(t, map[string]string{"order": "desc"}, first.Sort["_shard_doc"])
+			require.Nil(t, first.Sort["_doc"])
+			require.NotEmpty(t, first.Aggs)
+
+			second := c.multisearchRequests[1].Requests[0]
+			require.Equal(t, 10000, second.Size)
+			require.Equal(t, map[string]string{"id": "pit-2", "keep_alive": "1m"}, second.CustomProps["pit"])
+			require.Equal(t, []any{9999, 9999}, second.CustomProps["search_after"])
+			require.Empty(t, second.Aggs)
+
+			third := c.multisearchRequests[2].Requests[0]
+			require.Equal(t, 5000, third.Size)
+			require.Equal(t, map[string]string{"id": "pit-3", "keep_alive": "1m"}, third.CustomProps["pit"])
+		})
+
+		t.Run("With log query with limit above the page size should search at once when points in time are not supported", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeError = fmt.Errorf("not supported")
+			_, err := executeElasticsearchDataQuery(c, `{
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "25000" }}]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, 1)
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, 25000, sr.Size)
+			require.Nil(t, sr.CustomProps["pit"])
+			require.Empty(t, c.closedPointsInTime)
+		})
+
 		t.Run("With invalid query should return error", (func(t *testing.T) {
 			c := newFakeClient()
 			res, err := executeElasticsearchDataQuery(c, `{
@@ -1803,9 +1870,16 @@ func TestSettingsCasting(t *testing.T) {
 type fakeClient struct {
 	configuredFields    es.ConfiguredFields
 	multiSearchResponse *es.MultiSearchResponse
-	multiSearchError    error
-	builder             *es.MultiSearchRequestBuilder
-	multisearchRequests []*es.MultiSearchRequest
+	// multiSearchResponses are returned one after the other before multiSearchResponse
+	multiSearchResponses []*es.MultiSearchResponse
+	multiSearchError     error
+	builder              *es.MultiSearchRequestBuilder
+	multisearchRequests  []*es.MultiSearchRequest
+	logsMaxDocuments     int64
+	pointInTimeID        string
+	pointInTimeError     error
+	openedPointsInTime   int
+	closedPointsInTime   []string
 }
 
 func newFakeClient() *fakeClient {
@@ -1826,8 +1900,27 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 	return c.configuredFields
 }
 
+func (c *fakeClient) GetLogsMaxDocuments() int64 {
+	return c.logsMaxDocuments
+}
+
+func (c *fakeClient) OpenPointInTime(keepAlive string) (string, error) {
+	c.openedPointsInTime++
+	return c.pointInTimeID, c.pointInTimeError
+}
+
+func (c *fakeClient) ClosePointInTime(id string) error {
+	c.closedPointsInTime = append(c.closedPointsInTime, id)
+	return nil
+}
+
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchResponses) > 0 {
+		res := c.multiSearchResponses[0]
+		c.multiSearchResponses = c.multiSearchResponses[1:]
+		return res, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
@@ -28,6 +28,9 @@ import (
 
 var eslog = log.New("tsdb.elasticsearch")
 
+// defaultLogsMaxDocuments is the default maximum number of log lines returned by a logs query
+const defaultLogsMaxDocuments = 50000
+
 type Service struct {
 	httpClientProvider httpclient.Provider
 	im                 instancemgmt.InstanceManager
@@ -152,6 +155,23 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			xpack = false
 		}
 
+		var logsMaxDocuments float64
+
+		switch v := jsonData["logsMaxDocuments"].(type) {
+		case float64:
+			logsMaxDocuments = v
+		case string:
+			logsMaxDocuments, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				logsMaxDocuments = defaultLogsMaxDocuments
+			}
+		default:
+			logsMaxDocuments = defaultLogsMaxDocuments
+		}
+		if logsMaxDocuments <= 0 {
+			logsMaxDocuments = defaultLogsMaxDocuments
+		}
+
 		configuredFields := es.ConfiguredFields{
 			TimeField:       timeField,
 			LogLevelField:   logLevelField,
@@ -168,6 +188,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			Interval:                   interval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogsMaxDocuments:           int64(logsMaxDocuments),
 		}
 		return model, nil
 	}
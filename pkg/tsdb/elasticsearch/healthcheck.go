@@ -0,0 +1,53 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// CheckHealth checks that Elasticsearch can be reached with the credentials of the data source.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := eslog.FromContext(ctx)
+
+	ds, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to get data source info: %s", err),
+		}, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, ds.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := ds.HTTPClient.Do(request)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to connect to Elasticsearch: %s", err),
+		}, nil
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Elasticsearch responded with status %s", response.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Elasticsearch data source is working",
+	}, nil
+}
@@ -0,0 +1,53 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCheckHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "grafana" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":{"number":"8.0.0"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	checkHealth := func(t *testing.T, password string) *backend.CheckHealthResult {
+		t.Helper()
+		s := ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest())
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					URL:                     srv.URL,
+					JSONData:                []byte(`{"timeField":"@timestamp"}`),
+					BasicAuthEnabled:        true,
+					BasicAuthUser:           "grafana",
+					DecryptedSecureJSONData: map[string]string{"basicAuthPassword": password},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("should be ok with valid credentials", func(t *testing.T) {
+		require.Equal(t, backend.HealthStatusOk, checkHealth(t, "secret").Status)
+	})
+
+	t.Run("should fail with invalid credentials", func(t *testing.T) {
+		res := checkHealth(t, "wrong")
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Contains(t, res.Message, "401")
+	})
+}
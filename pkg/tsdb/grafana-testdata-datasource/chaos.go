@@ -0,0 +1,197 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// chaosStreamPath is the path of the stream that disconnects randomly
+	chaosStreamPath = "chaos-stream"
+	// defaultChaosSlowDuration is how long a slow response of the chaos scenario takes by default
+	defaultChaosSlowDuration = 5 * time.Second
+)
+
+// chaosOptions configure the faults injected by the chaos scenario, the chaos stream and the health check. Each
+// fault happens with its probability, between 0 (never) and 1 (always).
+type chaosOptions struct {
+	// SlowProbability is the probability of a query to respond after SlowDuration.
+	SlowProbability float64 `json:"slowProbability"`
+	SlowDuration    string  `json:"slowDuration"`
+	// PartialProbability is the probability of a query to respond with half of its rows and an error.
+	PartialProbability float64 `json:"partialProbability"`
+	// MalformedProbability is the probability of a query to respond with a string value field.
+	MalformedProbability float64 `json:"malformedProbability"`
+	// ErrorProbability is the probability of a query to fail.
+	ErrorProbability float64 `json:"errorProbability"`
+	// DisconnectProbability is the probability of the chaos stream to disconnect after each message.
+	DisconnectProbability float64 `json:"disconnectProbability"`
+	// HealthCheckFailureProbability is the probability of the health check to fail.
+	HealthCheckFailureProbability float64 `json:"healthCheckFailureProbability"`
+}
+
+var errChaosDisconnect = errors.New("chaos stream disconnected")
+
+// chaosRand returns a random number in [0.0,1.0) to decide whether a fault is injected.
+var chaosRand = rand.Float64
+
+func (o chaosOptions) inject(probability float64) bool {
+	return probability > 0 && chaosRand() < probability
+}
+
+func (o chaosOptions) slowDuration() time.Duration {
+	if d, err := time.ParseDuration(o.SlowDuration); err == nil && d > 0 {
+		return d
+	}
+	return defaultChaosSlowDuration
+}
+
+func (s *Service) handleChaosScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			continue
+		}
+		chaos := model.Chaos
+
+		if chaos.inject(chaos.SlowProbability) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(chaos.slowDuration()):
+			}
+		}
+
+		respD := resp.Responses[q.RefID]
+		if chaos.inject(chaos.ErrorProbability) {
+			respD.Error = fmt.Errorf("chaos: injected query error")
+			resp.Responses[q.RefID] = respD
+			continue
+		}
+
+		frame := RandomWalk(q, model, 0)
+		if chaos.inject(chaos.PartialProbability) {
+			frame = partialFrame(frame)
+			respD.Error = fmt.Errorf("chaos: injected partial response")
+		}
+		if chaos.inject(chaos.MalformedProbability) {
+			frame = malformedFrame(frame)
+		}
+		respD.Frames = append(respD.Frames, frame)
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+// partialFrame returns the first half of the rows of the frame.
+func partialFrame(frame *data.Frame) *data.Frame {
+	partial := data.NewFrame(frame.Name)
+	rows := frame.Rows() / 2
+	for _, field := range frame.Fields {
+		partialField := data.NewFieldFromFieldType(field.Type(), rows)
+		partialField.Name = field.Name
+		partialField.Labels = field.Labels
+		for i := 0; i < rows; i++ {
+			partialField.Set(i, field.At(i))
+		}
+		partial.Fields = append(partial.Fields, partialField)
+	}
+	return partial
+}
+
+// malformedFrame returns the frame with its values formatted as strings, instead of the numbers expected from
+// a time series.
+func malformedFrame(frame *data.Frame) *data.Frame {
+	malformed := data.NewFrame(frame.Name)
+	for _, field := range frame.Fields {
+		if !field.Type().Numeric() {
+			malformed.Fields = append(malformed.Fields, field)
+			continue
+		}
+
+		values := make([]*string, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			if v, ok := field.ConcreteAt(i); ok {
+				value := fmt.Sprintf("%v", v)
+				values[i] = &value
+			}
+		}
+		malformed.Fields = append(malformed.Fields, data.NewField(field.Name, field.Labels, values))
+	}
+	return malformed
+}
+
+func newChaosStreamFrame() *data.Frame {
+	return data.NewFrame("chaos",
+		data.NewField("Time", nil, make([]time.Time, 1)),
+		data.NewField("Value", nil, make([]float64, 1)),
+	)
+}
+
+// runChaosStream sends random values like the random walk streams, and disconnects randomly.
+func (s *Service) runChaosStream(ctx context.Context, request *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	var chaos chaosOptions
+	if len(request.Data) > 0 {
+		if err := json.Unmarshal(request.Data, &chaos); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	frame := newChaosStreamFrame()
+	walker := rand.Float64() * 100
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t := <-ticker.C:
+			walker += rand.Float64() - 0.5
+			frame.Fields[0].Set(0, t)
+			frame.Fields[1].Set(0, walker)
+			if err := sender.SendFrame(frame, data.IncludeDataOnly); err != nil {
+				return err
+			}
+			if chaos.inject(chaos.DisconnectProbability) {
+				return errChaosDisconnect
+			}
+		}
+	}
+}
+
+// chaosHealthCheck fails the health check randomly when the data source is configured with a
+// healthCheckFailureProbability in the chaos settings of its JSON data.
+func chaosHealthCheck(req *backend.CheckHealthRequest) *backend.CheckHealthResult {
+	settings := req.PluginContext.DataSourceInstanceSettings
+	if settings == nil || len(settings.JSONData) == 0 {
+		return nil
+	}
+
+	jsonData := struct {
+		Chaos chaosOptions `json:"chaos"`
+	}{}
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		return nil
+	}
+
+	if jsonData.Chaos.inject(jsonData.Chaos.HealthCheckFailureProbability) {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Chaos: injected health check failure (probability " + strconv.FormatFloat(jsonData.Chaos.HealthCheckFailureProbability, 'f', -1, 64) + ")",
+		}
+	}
+	return nil
+}
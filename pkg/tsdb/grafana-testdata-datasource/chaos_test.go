@@ -0,0 +1,97 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosScenario(t *testing.T) {
+	s := &Service{}
+
+	query := func(chaos string) *backend.QueryDataRequest {
+		from := time.Now()
+		return &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				TimeRange:     backend.TimeRange{From: from, To: from.Add(5 * time.Minute)},
+				Interval:      time.Second,
+				MaxDataPoints: 100,
+				JSON:          json.RawMessage(`{"scenarioId": "chaos", "chaos": ` + chaos + `}`),
+			}},
+		}
+	}
+
+	t.Run("without faults returns a random walk", func(t *testing.T) {
+		resp, err := s.handleChaosScenario(context.Background(), query(`{}`))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, data.FieldTypeNullableFloat64, resp.Responses["A"].Frames[0].Fields[1].Type())
+	})
+
+	t.Run("injects errors", func(t *testing.T) {
+		resp, err := s.handleChaosScenario(context.Background(), query(`{"errorProbability": 1}`))
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "chaos: injected query error")
+		require.Empty(t, resp.Responses["A"].Frames)
+	})
+
+	t.Run("injects partial and malformed frames", func(t *testing.T) {
+		full, err := s.handleChaosScenario(context.Background(), query(`{}`))
+		require.NoError(t, err)
+
+		resp, err := s.handleChaosScenario(context.Background(), query(`{"partialProbability": 1, "malformedProbability": 1}`))
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "chaos: injected partial response")
+		frame := resp.Responses["A"].Frames[0]
+		require.Positive(t, frame.Rows())
+		require.Less(t, frame.Rows(), full.Responses["A"].Frames[0].Rows())
+		require.True(t, frame.Fields[0].Type().Time())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+	})
+
+	t.Run("injects slow responses", func(t *testing.T) {
+		start := time.Now()
+		_, err := s.handleChaosScenario(context.Background(), query(`{"slowProbability": 1, "slowDuration": "50ms"}`))
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
+
+func TestChaosHealthCheck(t *testing.T) {
+	s := &Service{}
+	check := func(jsonData string) *backend.CheckHealthResult {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: json.RawMessage(jsonData)},
+			},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	require.Equal(t, backend.HealthStatusOk, check(`{}`).Status)
+	require.Equal(t, backend.HealthStatusOk, check(`{"chaos": {"healthCheckFailureProbability": 0}}`).Status)
+	require.Equal(t, backend.HealthStatusError, check(`{"chaos": {"healthCheckFailureProbability": 1}}`).Status)
+}
+
+func TestChaosStream(t *testing.T) {
+	s := &Service{logger: backend.NewLoggerWith("logger", "tsdb.testdata")}
+	err := s.RunStream(context.Background(), &backend.RunStreamRequest{
+		Path: chaosStreamPath,
+		Data: json.RawMessage(`{"disconnectProbability": 1}`),
+	}, backend.NewStreamSender(&discardPacketSender{}))
+	require.ErrorIs(t, err, errChaosDisconnect)
+}
+
+type discardPacketSender struct{}
+
+func (discardPacketSender) Send(*backend.StreamPacket) error {
+	return nil
+}
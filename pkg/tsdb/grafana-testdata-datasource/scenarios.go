@@ -47,6 +47,7 @@ const (
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
 	traceType                         queryType = "trace"
+	chaosQuery                        queryType = "chaos"
 )
 
 type queryType string
@@ -227,6 +228,14 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		Name: "Trace",
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      string(chaosQuery),
+		Name:    "Chaos",
+		handler: s.handleChaosScenario,
+		Description: `Chaos returns a random walk with faults injected according to the probabilities of the chaos options:
+slow responses, partial frames, malformed field types and errors. Use it to exercise the resilience of dashboards and alert rules.`,
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
@@ -268,10 +277,11 @@ type JSONModel struct {
 	Alias              string    `json:"alias"`
 	// Cannot specify a type for csvWave since legacy queries
 	// does not follow the same format as the new ones (and there is no migration).
-	CSVWave     any     `json:"csvWave"`
-	CSVContent  string  `json:"csvContent"`
-	CSVFileName string  `json:"csvFileName"`
-	DropPercent float64 `json:"dropPercent"`
+	CSVWave     any          `json:"csvWave"`
+	CSVContent  string       `json:"csvContent"`
+	CSVFileName string       `json:"csvFileName"`
+	DropPercent float64      `json:"dropPercent"`
+	Chaos       chaosOptions `json:"chaos"`
 }
 
 type pulseWave struct {
@@ -27,6 +27,13 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 		return nil, err
 	}
 
+	if req.Path == chaosStreamPath {
+		initialData, err = backend.NewInitialFrame(newChaosStreamFrame(), data.IncludeSchemaOnly)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if strings.Contains(req.Path, "-labeled") {
 		initialData, err = backend.NewInitialFrame(s.labelFrame, data.IncludeSchemaOnly)
 		if err != nil {
@@ -82,6 +89,8 @@ func (s *Service) RunStream(ctx context.Context, request *backend.RunStreamReque
 		conf = testStreamConfig{
 			Interval: 50 * time.Millisecond,
 		}
+	case request.Path == chaosStreamPath:
+		return s.runChaosStream(ctx, request, sender)
 	default:
 		return fmt.Errorf("testdata plugin does not support path: %s", request.Path)
 	}
@@ -0,0 +1,105 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const derivedFieldMatcherLabel = "label"
+
+// derivedFieldConfig is a derived field of the data source, see the derivedFields of its json data.
+type derivedFieldConfig struct {
+	Name         string `json:"name"`
+	MatcherRegex string `json:"matcherRegex"`
+	// MatcherType is either regex, matching the log line, or label, reading the label named MatcherRegex.
+	MatcherType string `json:"matcherType"`
+}
+
+type derivedField struct {
+	name  string
+	label string
+	regex *regexp.Regexp
+}
+
+// parseDerivedFields returns the derived fields configured in the json data of the data source. The derived
+// fields with a regular expression that is not supported by the backend are skipped.
+func parseDerivedFields(jsonData json.RawMessage) []derivedField {
+	if len(jsonData) == 0 {
+		return nil
+	}
+
+	settings := struct {
+		DerivedFields []derivedFieldConfig `json:"derivedFields"`
+	}{}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		logger.Warn("Failed to parse the derived fields", "error", err)
+		return nil
+	}
+
+	fields := make([]derivedField, 0, len(settings.DerivedFields))
+	for _, config := range settings.DerivedFields {
+		if config.Name == "" || config.MatcherRegex == "" {
+			continue
+		}
+
+		if config.MatcherType == derivedFieldMatcherLabel {
+			fields = append(fields, derivedField{name: config.Name, label: config.MatcherRegex})
+			continue
+		}
+
+		regex, err := regexp.Compile(config.MatcherRegex)
+		if err != nil {
+			logger.Warn("Skipping derived field with an unsupported regular expression", "name", config.Name, "error", err)
+			continue
+		}
+		fields = append(fields, derivedField{name: config.Name, regex: regex})
+	}
+	return fields
+}
+
+// addDerivedFields adds a field for each derived field to a logs frame, holding the value extracted from the
+// log lines like the frontend does: the first capture group of the regular expression or the label value.
+func addDerivedFields(frame *data.Frame, derivedFields []derivedField) error {
+	fields := frame.Fields
+	// logs frames have "labelsfield, timefield, linefield, ..."
+	if len(derivedFields) == 0 || len(fields) < 3 || fields[0].Type() != data.FieldTypeJSON || fields[2].Type() != data.FieldTypeString {
+		return nil
+	}
+
+	labelsField := fields[0]
+	lineField := fields[2]
+	length := lineField.Len()
+
+	var labels []map[string]string
+	for _, derived := range derivedFields {
+		if derived.label != "" && labels == nil {
+			labels = make([]map[string]string, length)
+			for i := 0; i < length; i++ {
+				if err := json.Unmarshal(labelsField.At(i).(json.RawMessage), &labels[i]); err != nil {
+					return fmt.Errorf("failed to parse the labels of the log line: %w", err)
+				}
+			}
+		}
+
+		values := make([]*string, length)
+		for i := 0; i < length; i++ {
+			if derived.label != "" {
+				if value, ok := labels[i][derived.label]; ok {
+					values[i] = &value
+				}
+				continue
+			}
+
+			match := derived.regex.FindStringSubmatch(lineField.At(i).(string))
+			if len(match) > 1 && match[1] != "" {
+				value := match[1]
+				values[i] = &value
+			}
+		}
+		frame.Fields = append(frame.Fields, data.NewField(derived.name, nil, values))
+	}
+	return nil
+}
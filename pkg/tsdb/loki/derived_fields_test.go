@@ -0,0 +1,82 @@
+package loki
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDerivedFields(t *testing.T) {
+	t.Run("empty json data", func(t *testing.T) {
+		require.Empty(t, parseDerivedFields(nil))
+		require.Empty(t, parseDerivedFields(json.RawMessage(`{}`)))
+	})
+
+	t.Run("skips incomplete fields and unsupported regular expressions", func(t *testing.T) {
+		fields := parseDerivedFields(json.RawMessage(`{"derivedFields": [
+			{"name": "traceID", "matcherRegex": "traceID=(\\w+)"},
+			{"name": "lookahead", "matcherRegex": "(?=a)b"},
+			{"name": "", "matcherRegex": "(\\w+)"},
+			{"name": "app", "matcherRegex": "app", "matcherType": "label"}
+		]}`))
+
+		require.Len(t, fields, 2)
+		require.Equal(t, "traceID", fields[0].name)
+		require.NotNil(t, fields[0].regex)
+		require.Equal(t, "app", fields[1].name)
+		require.Equal(t, "app", fields[1].label)
+	})
+}
+
+func TestAddDerivedFields(t *testing.T) {
+	makeFrame := func() *data.Frame {
+		return data.NewFrame("",
+			data.NewField("labels", nil, []json.RawMessage{
+				json.RawMessage(`{"app":"api"}`),
+				json.RawMessage(`{"app":"web"}`),
+				json.RawMessage(`{}`),
+			}),
+			data.NewField("Time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0), time.Unix(3, 0)}),
+			data.NewField("Line", nil, []string{
+				"status=500 traceID=abc",
+				"status=200",
+				"no status",
+			}),
+		)
+	}
+
+	fields := parseDerivedFields(json.RawMessage(`{"derivedFields": [
+		{"name": "status", "matcherRegex": "status=(\\d+)"},
+		{"name": "application", "matcherRegex": "app", "matcherType": "label"}
+	]}`))
+
+	t.Run("extracts the values from the log lines and labels", func(t *testing.T) {
+		frame := makeFrame()
+		require.NoError(t, addDerivedFields(frame, fields))
+		require.Len(t, frame.Fields, 5)
+
+		status := frame.Fields[3]
+		require.Equal(t, "status", status.Name)
+		require.Equal(t, "500", *status.At(0).(*string))
+		require.Equal(t, "200", *status.At(1).(*string))
+		require.Nil(t, status.At(2))
+
+		application := frame.Fields[4]
+		require.Equal(t, "application", application.Name)
+		require.Equal(t, "api", *application.At(0).(*string))
+		require.Equal(t, "web", *application.At(1).(*string))
+		require.Nil(t, application.At(2))
+	})
+
+	t.Run("ignores frames that are not logs frames", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("Time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("Value", nil, []float64{1}),
+		)
+		require.NoError(t, addDerivedFields(frame, fields))
+		require.Len(t, frame.Fields, 2)
+	})
+}
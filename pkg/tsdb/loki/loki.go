@@ -63,8 +63,9 @@ var (
 )
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient    *http.Client
+	URL           string
+	DerivedFields []derivedField
 
 	// open streams
 	streams   map[string]data.FrameJSONCache
@@ -75,11 +76,15 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	// ExtractDerivedFields asks the backend to extract the derived fields of the data source, which it always
+	// does for the queries of alert rules.
+	ExtractDerivedFields bool `json:"extractDerivedFields,omitempty"`
 }
 
 type ResponseOpts struct {
 	metricDataplane bool
 	logsDataplane   bool
+	derivedFields   []derivedField
 }
 
 func parseQueryModel(raw json.RawMessage) (*QueryJSONModel, error) {
@@ -101,9 +106,10 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 		}
 
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			streams:    make(map[string]data.FrameJSONCache),
+			HTTPClient:    client,
+			URL:           settings.URL,
+			DerivedFields: parseDerivedFields(settings.JSONData),
+			streams:       make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
 	}
@@ -175,6 +181,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	responseOpts := ResponseOpts{
 		metricDataplane: s.features.IsEnabled(ctx, featuremgmt.FlagLokiMetricDataplane),
 		logsDataplane:   s.features.IsEnabled(ctx, featuremgmt.FlagLokiLogsDataplane),
+		derivedFields:   dsInfo.DerivedFields,
 	}
 
 	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, s.features.IsEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), s.features.IsEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata))
@@ -267,6 +274,13 @@ func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts
 			plog.Error("Error adjusting frame", "error", err)
 			return data.Frames{}, err
 		}
+
+		if query.ExtractDerivedFields {
+			if err := addDerivedFields(frame, responseOpts.derivedFields); err != nil {
+				plog.Error("Error extracting derived fields", "error", err)
+				return data.Frames{}, err
+			}
+		}
 	}
 
 	return frames, nil
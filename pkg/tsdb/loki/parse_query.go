@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
+	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
 )
@@ -120,6 +121,7 @@ func parseSupportingQueryType(jsonPointerValue *string) (SupportingQueryType, er
 
 func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 	qs := []*lokiQuery{}
+	_, fromAlert := queryContext.Headers[ngalertmodels.FromAlertHeaderName]
 	for _, query := range queryContext.Queries {
 		model, err := parseQueryModel(query.JSON)
 		if err != nil {
@@ -170,16 +172,17 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 		}
 
 		qs = append(qs, &lokiQuery{
-			Expr:                expr,
-			QueryType:           queryType,
-			Direction:           direction,
-			Step:                step,
-			MaxLines:            int(maxLines),
-			LegendFormat:        legendFormat,
-			Start:               start,
-			End:                 end,
-			RefID:               query.RefID,
-			SupportingQueryType: supportingQueryType,
+			Expr:                 expr,
+			QueryType:            queryType,
+			Direction:            direction,
+			Step:                 step,
+			MaxLines:             int(maxLines),
+			LegendFormat:         legendFormat,
+			Start:                start,
+			End:                  end,
+			RefID:                query.RefID,
+			SupportingQueryType:  supportingQueryType,
+			ExtractDerivedFields: model.ExtractDerivedFields || fromAlert,
 		})
 	}
 
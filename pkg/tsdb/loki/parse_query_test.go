@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
 	"github.com/stretchr/testify/require"
 )
@@ -35,6 +36,37 @@ func TestParseQuery(t *testing.T) {
 		require.Equal(t, time.Second*15, models[0].Step)
 		require.Equal(t, "go_goroutines 15s 15000 3000s 3000 3000000", models[0].Expr)
 	})
+
+	t.Run("extracting derived fields for alert queries", func(t *testing.T) {
+		makeRequest := func(query string, headers map[string]string) *backend.QueryDataRequest {
+			return &backend.QueryDataRequest{
+				Headers: headers,
+				Queries: []backend.DataQuery{
+					{
+						JSON: []byte(query),
+						TimeRange: backend.TimeRange{
+							From: time.Now().Add(-3000 * time.Second),
+							To:   time.Now(),
+						},
+						Interval:      time.Second * 15,
+						MaxDataPoints: 200,
+					},
+				},
+			}
+		}
+
+		models, err := parseQuery(makeRequest(`{"expr": "{app=\"api\"}", "refId": "A"}`, nil))
+		require.NoError(t, err)
+		require.False(t, models[0].ExtractDerivedFields)
+
+		models, err = parseQuery(makeRequest(`{"expr": "{app=\"api\"}", "refId": "A", "extractDerivedFields": true}`, nil))
+		require.NoError(t, err)
+		require.True(t, models[0].ExtractDerivedFields)
+
+		models, err = parseQuery(makeRequest(`{"expr": "{app=\"api\"}", "refId": "A"}`, map[string]string{ngalertmodels.FromAlertHeaderName: "true"}))
+		require.NoError(t, err)
+		require.True(t, models[0].ExtractDerivedFields)
+	})
 	t.Run("interpolate variables, range between 1s and 0.5s", func(t *testing.T) {
 		expr := "go_goroutines $__interval $__interval_ms $__range $__range_s $__range_ms"
 		queryType := dataquery.LokiQueryTypeRange
@@ -38,4 +38,6 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	// ExtractDerivedFields is set when the derived fields of the data source are extracted by the backend.
+	ExtractDerivedFields bool
 }
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -144,6 +145,7 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			QueryCanceler:     mysqlQueryCanceler{},
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
@@ -154,6 +156,21 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 	}
 }
 
+// mysqlQueryCanceler kills the queries of canceled requests, since the driver only closes
+// the connection, which leaves the query running on the server.
+type mysqlQueryCanceler struct{}
+
+func (mysqlQueryCanceler) ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+func (mysqlQueryCanceler) CancelQuery(ctx context.Context, db *sql.DB, connectionID int64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID))
+	return err
+}
+
 func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
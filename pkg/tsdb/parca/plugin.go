@@ -27,6 +27,7 @@ var (
 	_ backend.QueryDataHandler    = (*ParcaDatasource)(nil)
 	_ backend.CallResourceHandler = (*ParcaDatasource)(nil)
 	_ backend.CheckHealthHandler  = (*ParcaDatasource)(nil)
+	_ backend.StreamHandler       = (*ParcaDatasource)(nil)
 )
 
 // ParcaDatasource is a datasource for querying application performance profiles.
@@ -26,6 +26,7 @@ var (
 	_ backend.QueryDataHandler    = (*Service)(nil)
 	_ backend.CallResourceHandler = (*Service)(nil)
 	_ backend.CheckHealthHandler  = (*Service)(nil)
+	_ backend.StreamHandler       = (*Service)(nil)
 )
 
 type Service struct {
@@ -129,3 +130,37 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 	}
 	return check, err
 }
+
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	i, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+	return i.SubscribeStream(ctx, req)
+}
+
+func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	i, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return i.PublishStream(ctx, req)
+}
+
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running stream", "path", req.Path, "function", logEntrypoint())
+
+	i, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	err = i.RunStream(ctx, req, sender)
+	if err != nil {
+		ctxLogger.Error("Failed to run stream", "error", err, "function", logEntrypoint())
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package parca
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// profileStreamPathPrefix is the prefix of the channel paths of the profile streams, profile/${key}
+	profileStreamPathPrefix = "profile/"
+	// profileStreamChunkSize is the number of rows of the nested set data frame sent in a single stream message
+	profileStreamChunkSize = 10000
+)
+
+// profileStreamRequest is the data of a profile stream: the query and the time range, in epoch milliseconds,
+// of the merged profile.
+type profileStreamRequest struct {
+	queryModel
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+func parseProfileStreamRequest(path string, raw json.RawMessage) (*profileStreamRequest, error) {
+	if !strings.HasPrefix(path, profileStreamPathPrefix) {
+		return nil, fmt.Errorf("expected %s in channel path", profileStreamPathPrefix)
+	}
+
+	var req profileStreamRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	if req.ProfileTypeId == "" {
+		return nil, fmt.Errorf("missing profileTypeId in channel")
+	}
+	if req.From >= req.To {
+		return nil, fmt.Errorf("invalid time range in channel")
+	}
+	return &req, nil
+}
+
+func (d *ParcaDatasource) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if _, err := parseProfileStreamRequest(req.Path, req.Data); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+func (d *ParcaDatasource) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream queries the merged profile and sends its nested set data frame in chunks of rows, so that the
+// profiles of long time ranges aren't delivered in a single message. Each chunk is a frame with the same fields,
+// its position is in the custom metadata of the frame.
+func (d *ParcaDatasource) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	ctxLogger := logger.FromContext(ctx)
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.parca.RunStream", trace.WithAttributes(attribute.String("path", req.Path)))
+	defer span.End()
+
+	streamReq, err := parseProfileStreamRequest(req.Path, req.Data)
+	if err != nil {
+		return err
+	}
+
+	query := backend.DataQuery{
+		TimeRange: backend.TimeRange{
+			From: time.UnixMilli(streamReq.From),
+			To:   time.UnixMilli(streamReq.To),
+		},
+	}
+	resp, err := d.client.Query(ctx, makeProfileRequest(streamReq.queryModel, query))
+	if err != nil {
+		ctxLogger.Error("Failed to query profile", "error", err, "function", logEntrypoint())
+		return err
+	}
+
+	chunks := chunkFrame(responseToDataFrames(resp), profileStreamChunkSize)
+	for _, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := sender.SendFrame(chunk, data.IncludeAll); err != nil {
+			ctxLogger.Error("Failed to send profile chunk", "error", err, "function", logEntrypoint())
+			return err
+		}
+	}
+
+	ctxLogger.Debug("RunStream completed", "chunks", len(chunks), "function", logEntrypoint())
+	return nil
+}
+
+// chunkFrame splits a frame into frames of at most size rows.
+func chunkFrame(frame *data.Frame, size int) []*data.Frame {
+	rows := frame.Rows()
+	total := (rows + size - 1) / size
+	if total == 0 {
+		total = 1
+	}
+
+	chunks := make([]*data.Frame, 0, total)
+	for i := 0; i < total; i++ {
+		start := i * size
+		end := min(start+size, rows)
+
+		chunk := data.NewFrame(frame.Name)
+		for _, field := range frame.Fields {
+			chunkField := data.NewFieldFromFieldType(field.Type(), end-start)
+			chunkField.Name = field.Name
+			chunkField.Labels = field.Labels
+			chunkField.Config = field.Config
+			for row := start; row < end; row++ {
+				chunkField.Set(row-start, field.At(row))
+			}
+			chunk.Fields = append(chunk.Fields, chunkField)
+		}
+
+		meta := data.FrameMeta{}
+		if frame.Meta != nil {
+			meta = *frame.Meta
+		}
+		meta.Custom = map[string]any{
+			"chunk":  i,
+			"chunks": total,
+		}
+		chunk.Meta = &meta
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
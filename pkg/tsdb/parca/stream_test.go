@@ -0,0 +1,87 @@
+package parca
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SubscribeStream(t *testing.T) {
+	ds := &ParcaDatasource{client: &FakeClient{}}
+
+	t.Run("accepts profile channels", func(t *testing.T) {
+		resp, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "profile/abc",
+			Data: json.RawMessage(`{"profileTypeId": "memory:alloc_objects:count:space:bytes", "from": 1000, "to": 2000}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, resp.Status)
+	})
+
+	t.Run("rejects other channels", func(t *testing.T) {
+		resp, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "tail/abc",
+			Data: json.RawMessage(`{"profileTypeId": "memory:alloc_objects:count:space:bytes", "from": 1000, "to": 2000}`),
+		})
+		require.Error(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status)
+	})
+
+	t.Run("rejects channels without a profile type", func(t *testing.T) {
+		resp, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "profile/abc",
+			Data: json.RawMessage(`{"from": 1000, "to": 2000}`),
+		})
+		require.Error(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status)
+	})
+}
+
+func Test_RunStream(t *testing.T) {
+	client := &FakeClient{}
+	ds := &ParcaDatasource{client: client}
+	packets := &fakePacketSender{}
+
+	err := ds.RunStream(context.Background(), &backend.RunStreamRequest{
+		Path: "profile/abc",
+		Data: json.RawMessage(`{"profileTypeId": "memory:alloc_objects:count:space:bytes", "labelSelector": "{}", "from": 1000, "to": 2000}`),
+	}, backend.NewStreamSender(packets))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), client.Req.Msg.GetMerge().Start.Seconds)
+	require.Equal(t, int64(2), client.Req.Msg.GetMerge().End.Seconds)
+	require.Len(t, packets.frames, 1)
+	require.Equal(t, 4, packets.frames[0].Rows())
+}
+
+func Test_chunkFrame(t *testing.T) {
+	frame := treeToNestedSetDataFrame(flamegraphResponse.Msg.GetFlamegraph())
+	frame.Meta = &data.FrameMeta{PreferredVisualization: "flamegraph"}
+
+	chunks := chunkFrame(frame, 3)
+	require.Len(t, chunks, 2)
+	require.Equal(t, 3, chunks[0].Rows())
+	require.Equal(t, 1, chunks[1].Rows())
+	require.Equal(t, map[string]any{"chunk": 1, "chunks": 2}, chunks[1].Meta.Custom)
+	require.Equal(t, data.VisType("flamegraph"), chunks[1].Meta.PreferredVisualization)
+	require.Equal(t, "baz", chunks[1].Fields[3].At(0))
+	require.Equal(t, int64(3), chunks[1].Fields[0].At(0))
+	require.Equal(t, "samples", chunks[1].Fields[1].Config.Unit)
+}
+
+type fakePacketSender struct {
+	frames []*data.Frame
+}
+
+func (s *fakePacketSender) Send(packet *backend.StreamPacket) error {
+	var frame data.Frame
+	if err := json.Unmarshal(packet.Data, &frame); err != nil {
+		return err
+	}
+	s.frames = append(s.frames, &frame)
+	return nil
+}
@@ -45,7 +45,17 @@ const (
 	UnknownQueryType  TimeSeriesQueryType = "unknown"
 )
 
-var safeResolution = 11000
+// Limits are the guardrails applied to queries.
+type Limits struct {
+	// MaxSamplesPerSeries is the maximum number of samples per series returned by range queries.
+	// The step of queries that would return more samples is increased. 0 disables the limit.
+	MaxSamplesPerSeries int64
+	// MaxSeries is the maximum number of series returned by a query. 0 disables the limit.
+	MaxSeries int64
+}
+
+// DefaultLimits are the limits applied when none are configured.
+var DefaultLimits = Limits{MaxSamplesPerSeries: 11000}
 
 type QueryModel struct {
 	dataquery.PrometheusDataQuery
@@ -65,8 +75,10 @@ type TimeRange struct {
 }
 
 type Query struct {
-	Expr          string
-	Step          time.Duration
+	Expr string
+	Step time.Duration
+	// RequestedStep is the step of the query before it was increased to stay within the limits.
+	RequestedStep time.Duration
 	LegendFormat  string
 	Start         time.Time
 	End           time.Time
@@ -75,16 +87,17 @@ type Query struct {
 	RangeQuery    bool
 	ExemplarQuery bool
 	UtcOffsetSec  int64
+	Limits        Limits
 }
 
-func Parse(query backend.DataQuery, dsScrapeInterval string, intervalCalculator intervalv2.Calculator, fromAlert bool) (*Query, error) {
+func Parse(query backend.DataQuery, dsScrapeInterval string, intervalCalculator intervalv2.Calculator, fromAlert bool, limits Limits) (*Query, error) {
 	model := &QueryModel{}
 	if err := json.Unmarshal(query.JSON, model); err != nil {
 		return nil, err
 	}
 
 	// Final step value for prometheus
-	calculatedMinStep, err := calculatePrometheusInterval(model.Interval, dsScrapeInterval, model.IntervalMs, model.IntervalFactor, query, intervalCalculator)
+	calculatedMinStep, requestedStep, err := calculatePrometheusInterval(model.Interval, dsScrapeInterval, model.IntervalMs, model.IntervalFactor, query, intervalCalculator, limits.MaxSamplesPerSeries)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +140,7 @@ func Parse(query backend.DataQuery, dsScrapeInterval string, intervalCalculator
 	return &Query{
 		Expr:          expr,
 		Step:          calculatedMinStep,
+		RequestedStep: requestedStep,
 		LegendFormat:  model.LegendFormat,
 		Start:         query.TimeRange.From,
 		End:           query.TimeRange.To,
@@ -135,6 +149,7 @@ func Parse(query backend.DataQuery, dsScrapeInterval string, intervalCalculator
 		RangeQuery:    rangeQuery,
 		ExemplarQuery: exemplarQuery,
 		UtcOffsetSec:  model.UtcOffsetSec,
+		Limits:        limits,
 	}, nil
 }
 
@@ -151,6 +166,11 @@ func (query *Query) Type() TimeSeriesQueryType {
 	return UnknownQueryType
 }
 
+// ReducedResolution returns true if the step of the range query was increased to stay within the limits.
+func (query *Query) ReducedResolution() bool {
+	return query.RangeQuery && query.Step > query.RequestedStep
+}
+
 func (query *Query) TimeRange() TimeRange {
 	return TimeRange{
 		Step: query.Step,
@@ -160,12 +180,15 @@ func (query *Query) TimeRange() TimeRange {
 	}
 }
 
+// calculatePrometheusInterval returns the step of the query, and the step that
+// was requested before it was increased to return at most maxSamples per series.
 func calculatePrometheusInterval(
 	queryInterval, dsScrapeInterval string,
 	intervalMs, intervalFactor int64,
 	query backend.DataQuery,
 	intervalCalculator intervalv2.Calculator,
-) (time.Duration, error) {
+	maxSamples int64,
+) (time.Duration, time.Duration, error) {
 	// we need to compare the original query model after it is overwritten below to variables so that we can
 	// calculate the rateInterval if it is equal to $__rate_interval or ${__rate_interval}
 	originalQueryInterval := queryInterval
@@ -177,26 +200,28 @@ func calculatePrometheusInterval(
 
 	minInterval, err := intervalv2.GetIntervalFrom(dsScrapeInterval, queryInterval, intervalMs, 15*time.Second)
 	if err != nil {
-		return time.Duration(0), err
+		return time.Duration(0), time.Duration(0), err
 	}
 	calculatedInterval := intervalCalculator.Calculate(query.TimeRange, minInterval, query.MaxDataPoints)
-	safeInterval := intervalCalculator.CalculateSafeInterval(query.TimeRange, int64(safeResolution))
 
-	adjustedInterval := safeInterval.Value
-	if calculatedInterval.Value > safeInterval.Value {
-		adjustedInterval = calculatedInterval.Value
+	adjustedInterval := calculatedInterval.Value
+	if maxSamples > 0 {
+		safeInterval := intervalCalculator.CalculateSafeInterval(query.TimeRange, maxSamples)
+		if safeInterval.Value > adjustedInterval {
+			adjustedInterval = safeInterval.Value
+		}
 	}
 
 	// here is where we compare for $__rate_interval or ${__rate_interval}
 	if originalQueryInterval == varRateInterval || originalQueryInterval == varRateIntervalAlt {
 		// Rate interval is final and is not affected by resolution
-		return calculateRateInterval(adjustedInterval, dsScrapeInterval), nil
+		return calculateRateInterval(adjustedInterval, dsScrapeInterval), calculateRateInterval(calculatedInterval.Value, dsScrapeInterval), nil
 	} else {
 		queryIntervalFactor := intervalFactor
 		if queryIntervalFactor == 0 {
 			queryIntervalFactor = 1
 		}
-		return time.Duration(int64(adjustedInterval) * queryIntervalFactor), nil
+		return time.Duration(int64(adjustedInterval) * queryIntervalFactor), time.Duration(int64(calculatedInterval.Value) * queryIntervalFactor), nil
 	}
 }
 
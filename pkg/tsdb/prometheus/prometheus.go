@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/instrumentation"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/resource"
 )
@@ -58,7 +59,7 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider, cfg *setting.C
 		}
 
 		// New version using custom client and better response parsing
-		qd, err := querydata.New(httpClient, features, settings, log)
+		qd, err := querydata.New(httpClient, features, settings, log, queryLimits(cfg))
 		if err != nil {
 			return nil, err
 		}
@@ -77,6 +78,17 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider, cfg *setting.C
 	}
 }
 
+// queryLimits returns the limits applied to the queries of an org.
+func queryLimits(cfg *setting.Cfg) func(orgID int64) models.Limits {
+	return func(orgID int64) models.Limits {
+		limits := cfg.PrometheusQueryLimitsForOrg(orgID)
+		return models.Limits{
+			MaxSamplesPerSeries: limits.MaxSamplesPerSeries,
+			MaxSeries:           limits.MaxSeries,
+		}
+	}
+}
+
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	if len(req.Queries) == 0 {
 		err := fmt.Errorf("query contains no queries")
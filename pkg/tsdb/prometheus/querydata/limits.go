@@ -0,0 +1,79 @@
+package querydata
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+// applyLimits drops the series over the limit of the query, and adds notices to
+// the response if the query was reduced to stay within the limits. The notices
+// are also described in the custom metadata of the first frame so that clients
+// do not need to parse them.
+func applyLimits(q *models.Query, dr *backend.DataResponse) {
+	if len(dr.Frames) == 0 {
+		return
+	}
+
+	var notices []data.Notice
+	custom := map[string]string{}
+
+	if q.ReducedResolution() {
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text: fmt.Sprintf("Query resolution reduced: the step was increased from %s to %s to return at most %d samples per series.",
+				q.RequestedStep, q.Step, q.Limits.MaxSamplesPerSeries),
+		})
+		custom["reducedResolution"] = "true"
+		custom["requestedStep"] = q.RequestedStep.String()
+		custom["step"] = q.Step.String()
+		custom["maxSamplesPerSeries"] = strconv.FormatInt(q.Limits.MaxSamplesPerSeries, 10)
+	}
+
+	if maxSeries := q.Limits.MaxSeries; maxSeries > 0 {
+		frames := make(data.Frames, 0, len(dr.Frames))
+		series := int64(0)
+		for _, frame := range dr.Frames {
+			if isExemplarFrame(frame) || len(frame.Fields) < 2 {
+				frames = append(frames, frame)
+				continue
+			}
+			series++
+			if series <= maxSeries {
+				frames = append(frames, frame)
+			}
+		}
+
+		if series > maxSeries {
+			dr.Frames = frames
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Query returned %d series, only the first %d are shown. Refine the query to return fewer series.", series, maxSeries),
+			})
+			custom["seriesLimit"] = strconv.FormatInt(maxSeries, 10)
+			custom["seriesTotal"] = strconv.FormatInt(series, 10)
+		}
+	}
+
+	if len(notices) == 0 {
+		return
+	}
+
+	frame := dr.Frames[0]
+	frame.AppendNotices(notices...)
+	existing, ok := frame.Meta.Custom.(map[string]string)
+	if frame.Meta.Custom != nil && !ok {
+		return
+	}
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for k, v := range custom {
+		existing[k] = v
+	}
+	frame.Meta.Custom = existing
+}
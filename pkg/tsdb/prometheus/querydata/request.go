@@ -46,6 +46,8 @@ type QueryData struct {
 	TimeInterval       string
 	enableDataplane    bool
 	exemplarSampler    func() exemplar.Sampler
+	// limits returns the limits applied to the queries of the org.
+	limits func(orgID int64) models.Limits
 }
 
 func New(
@@ -53,6 +55,7 @@ func New(
 	features featuremgmt.FeatureToggles,
 	settings backend.DataSourceInstanceSettings,
 	plog log.Logger,
+	limits func(orgID int64) models.Limits,
 ) (*QueryData, error) {
 	jsonData, err := utils.GetJsonData(settings)
 	if err != nil {
@@ -88,6 +91,7 @@ func New(
 		URL:                settings.URL,
 		enableDataplane:    features.IsEnabledGlobally(featuremgmt.FlagPrometheusDataplane),
 		exemplarSampler:    exemplarSampler,
+		limits:             limits,
 	}, nil
 }
 
@@ -97,8 +101,9 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 		Responses: backend.Responses{},
 	}
 
+	limits := s.limits(req.PluginContext.OrgID)
 	for _, q := range req.Queries {
-		query, err := models.Parse(q, s.TimeInterval, s.intervalCalculator, fromAlert)
+		query, err := models.Parse(q, s.TimeInterval, s.intervalCalculator, fromAlert, limits)
 		if err != nil {
 			return &result, err
 		}
@@ -144,6 +149,8 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 		dr.Frames = append(dr.Frames, res.Frames...)
 	}
 
+	applyLimits(q, dr)
+
 	if q.ExemplarQuery {
 		res := s.exemplarQuery(traceCtx, client, q, headers)
 		if res.Error != nil {
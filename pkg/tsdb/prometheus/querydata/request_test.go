@@ -361,6 +361,74 @@ func TestPrometheus_parseTimeSeriesResponse(t *testing.T) {
 	})
 }
 
+func TestPrometheus_queryLimits(t *testing.T) {
+	matrix := func(n int) queryResult {
+		m := p.Matrix{}
+		for i := 0; i < n; i++ {
+			m = append(m, &p.SampleStream{
+				Metric: p.Metric{"instance": p.LabelValue(fmt.Sprintf("host-%d", i))},
+				Values: []p.SamplePair{{Value: 1, Timestamp: 1000}},
+			})
+		}
+		return queryResult{Type: p.ValMatrix, Result: m}
+	}
+
+	query := func(t *testing.T) backend.DataQuery {
+		b, err := json.Marshal(&models.QueryModel{
+			PrometheusDataQuery: dataquery.PrometheusDataQuery{Range: kindsys.Ptr(true)},
+		})
+		require.NoError(t, err)
+		return backend.DataQuery{
+			TimeRange: backend.TimeRange{
+				From: time.Unix(0, 0).UTC(),
+				To:   time.Unix(0, 0).Add(24 * time.Hour).UTC(),
+			},
+			Interval:      15 * time.Second,
+			MaxDataPoints: 10000,
+			JSON:          b,
+		}
+	}
+
+	t.Run("reduces the resolution of queries returning too many samples", func(t *testing.T) {
+		tctx, err := setupWithLimits(models.Limits{MaxSamplesPerSeries: 100})
+		require.NoError(t, err)
+		res, err := execute(tctx, query(t), matrix(1))
+		require.NoError(t, err)
+
+		require.Len(t, res[0].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityInfo, res[0].Meta.Notices[0].Severity)
+		custom := res[0].Meta.Custom.(map[string]string)
+		require.Equal(t, "true", custom["reducedResolution"])
+		require.Equal(t, "15s", custom["requestedStep"])
+		require.Equal(t, "15m0s", custom["step"])
+		require.Equal(t, "matrix", custom["resultType"])
+	})
+
+	t.Run("does not add notices to queries within the limits", func(t *testing.T) {
+		tctx, err := setup()
+		require.NoError(t, err)
+		res, err := execute(tctx, query(t), matrix(3))
+		require.NoError(t, err)
+
+		require.Len(t, res, 3)
+		require.Empty(t, res[0].Meta.Notices)
+	})
+
+	t.Run("drops the series over the limit", func(t *testing.T) {
+		tctx, err := setupWithLimits(models.Limits{MaxSamplesPerSeries: 11000, MaxSeries: 2})
+		require.NoError(t, err)
+		res, err := execute(tctx, query(t), matrix(5))
+		require.NoError(t, err)
+
+		require.Len(t, res, 2)
+		require.Len(t, res[0].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, res[0].Meta.Notices[0].Severity)
+		custom := res[0].Meta.Custom.(map[string]string)
+		require.Equal(t, "2", custom["seriesLimit"])
+		require.Equal(t, "5", custom["seriesTotal"])
+	})
+}
+
 type queryResult struct {
 	Type   p.ValueType `json:"resultType"`
 	Result any         `json:"result"`
@@ -428,6 +496,10 @@ type testContext struct {
 }
 
 func setup() (*testContext, error) {
+	return setupWithLimits(models.DefaultLimits)
+}
+
+func setupWithLimits(limits models.Limits) (*testContext, error) {
 	httpProvider := &fakeHttpClientProvider{
 		opts: httpclient.Options{
 			Timeouts: &httpclient.DefaultTimeoutOptions,
@@ -453,7 +525,7 @@ func setup() (*testContext, error) {
 		return nil, err
 	}
 
-	queryData, _ := querydata.New(httpClient, features, settings, log.New())
+	queryData, _ := querydata.New(httpClient, features, settings, log.New(), func(int64) models.Limits { return limits })
 
 	return &testContext{
 		httpProvider: httpProvider,
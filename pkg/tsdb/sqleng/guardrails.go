@@ -0,0 +1,144 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var ErrQueryTimeout = errutil.Timeout("sqleng.queryTimeout", errutil.WithPublicMessage("The query exceeded the query timeout of the data source"))
+
+// queryTimeout returns the maximum duration of a query of the data source, zero when unlimited.
+func (e *DataSourceHandler) queryTimeout() time.Duration {
+	if e.dsInfo.JsonData.QueryTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(e.dsInfo.JsonData.QueryTimeout) * time.Second
+}
+
+// maxRows returns the maximum number of rows read for a query, the lowest of the row limit of the server and
+// the one of the data source.
+func (e *DataSourceHandler) maxRows() int64 {
+	limit := e.rowLimit
+	if maxRows := e.dsInfo.JsonData.MaxRows; maxRows > 0 && (limit <= 0 || maxRows < limit) {
+		limit = maxRows
+	}
+	return limit
+}
+
+// timedOut reports whether the query context was canceled by the query timeout of the data source rather than
+// by the request.
+func timedOut(queryCtx, requestCtx context.Context) bool {
+	return errors.Is(queryCtx.Err(), context.DeadlineExceeded) && requestCtx.Err() == nil
+}
+
+// frameFromRows converts the rows to a frame like sqlutil.FrameFromRows. It also stops reading the rows once
+// their estimated size reaches maxBytes, attaching a warning notice to the frame, unless maxBytes is not positive.
+func frameFromRows(rows *sql.Rows, rowLimit int64, maxBytes int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	if maxBytes <= 0 {
+		return sqlutil.FrameFromRows(rows, rowLimit, converters...)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var i, size int64
+	limited := false
+	for !limited {
+		for rows.Next() {
+			if i == rowLimit {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+				})
+				limited = true
+				break
+			}
+
+			r := scanRow.NewScannableRow()
+			if err := rows.Scan(r...); err != nil {
+				return nil, err
+			}
+
+			size += rowSize(r)
+			if size > maxBytes {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v rows because the result size limit of %v bytes of the data source was reached", i, maxBytes),
+				})
+				limited = true
+				break
+			}
+
+			if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+				return nil, err
+			}
+
+			i++
+		}
+		if limited || !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return frame, err
+	}
+
+	return frame, nil
+}
+
+// rowSize estimates the memory used by the values of a scanned row.
+func rowSize(values []any) int64 {
+	var size int64
+	for _, v := range values {
+		size += valueSize(reflect.ValueOf(v))
+	}
+	return size
+}
+
+func valueSize(v reflect.Value) int64 {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 8
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		return int64(v.Len()) * int64(v.Type().Elem().Size())
+	case reflect.Struct:
+		// structs such as sql.NullString hold their variable sized data in string fields
+		size := int64(v.Type().Size())
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Kind() == reflect.String {
+				size += int64(f.Len())
+			}
+		}
+		return size
+	}
+	return int64(v.Type().Size())
+}
@@ -0,0 +1,107 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestSQLEngineGuardrails(t *testing.T) {
+	const numbers = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c LIMIT 100) SELECT x, 'value-' || x AS v FROM c"
+
+	run := func(t *testing.T, jsonData JsonData, rawSQL string) backend.DataResponse {
+		t.Helper()
+		db, err := sql.Open("sqlite3", ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		handler := &DataSourceHandler{
+			db:                     db,
+			log:                    log.New("test"),
+			rowLimit:               1000,
+			dsInfo:                 DataSourceInfo{JsonData: jsonData},
+			macroEngine:            noopMacroEngine{},
+			queryResultTransformer: &testQueryResultTransformer{},
+		}
+
+		model, err := json.Marshal(map[string]any{"rawSql": rawSQL, "format": "table"})
+		require.NoError(t, err)
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: model, TimeRange: backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()}}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("limits the rows to the max rows of the data source", func(t *testing.T) {
+		resp := run(t, JsonData{MaxRows: 10}, numbers)
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+
+		frame := resp.Frames[0]
+		assert.Equal(t, 10, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+	})
+
+	t.Run("limits the rows to the max result bytes of the data source", func(t *testing.T) {
+		resp := run(t, JsonData{MaxResultBytes: 500}, numbers)
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+
+		frame := resp.Frames[0]
+		assert.Greater(t, frame.Rows(), 0)
+		assert.Less(t, frame.Rows(), 100)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "result size limit")
+	})
+
+	t.Run("returns all the rows within the limits", func(t *testing.T) {
+		resp := run(t, JsonData{MaxRows: 1000, MaxResultBytes: 1 << 20}, numbers)
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+		assert.Equal(t, 100, resp.Frames[0].Rows())
+		assert.Empty(t, resp.Frames[0].Meta.Notices)
+	})
+
+	t.Run("fails the queries exceeding the query timeout of the data source", func(t *testing.T) {
+		resp := run(t, JsonData{QueryTimeout: 1},
+			"WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c")
+		require.ErrorIs(t, resp.Error, ErrQueryTimeout)
+	})
+}
+
+func TestHandlerMaxRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rowLimit int64
+		maxRows  int64
+		expected int64
+	}{
+		{name: "server limit without data source limit", rowLimit: 1000, expected: 1000},
+		{name: "lower data source limit", rowLimit: 1000, maxRows: 10, expected: 10},
+		{name: "higher data source limit", rowLimit: 1000, maxRows: 5000, expected: 1000},
+		{name: "data source limit without server limit", rowLimit: -1, maxRows: 10, expected: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &DataSourceHandler{rowLimit: tt.rowLimit, dsInfo: DataSourceInfo{JsonData: JsonData{MaxRows: tt.maxRows}}}
+			assert.Equal(t, tt.expected, handler.maxRows())
+		})
+	}
+}
+
+type noopMacroEngine struct{}
+
+func (noopMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
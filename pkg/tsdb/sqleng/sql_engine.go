@@ -41,6 +41,18 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SQLQueryCanceler cancels queries on the database server. It is needed for drivers that only close the
+// connection when the context of a query is canceled, which leaves the query running on the server.
+type SQLQueryCanceler interface {
+	// ConnectionID returns the identifier of the connection on the server.
+	ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error)
+	// CancelQuery cancels the query running on the connection with the identifier.
+	CancelQuery(ctx context.Context, db *sql.DB, connectionID int64) error
+}
+
+// cancelQueryTimeout is the maximum time to wait for the database server to cancel a query.
+const cancelQueryTimeout = 10 * time.Second
+
 var sqlIntervalCalculator = intervalv2.NewCalculator()
 
 // NewDB is a sql.DB factory, that can be stubbed by tests.
@@ -71,6 +83,13 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// QueryTimeout is the maximum duration of a query in seconds, unlimited when zero.
+	QueryTimeout int `json:"queryTimeout"`
+	// MaxRows is the maximum number of rows read for a query. The row limit of the server applies when it is
+	// lower or when MaxRows is zero.
+	MaxRows int64 `json:"maxRows"`
+	// MaxResultBytes is the maximum estimated size in bytes of the rows read for a query, unlimited when zero.
+	MaxResultBytes int64 `json:"maxResultBytes"`
 }
 
 type DataSourceInfo struct {
@@ -91,6 +110,9 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// QueryCanceler cancels the queries on the server when their request is canceled. It is optional for
+	// drivers that cancel the queries themselves.
+	QueryCanceler SQLQueryCanceler
 }
 
 type DataSourceHandler struct {
@@ -103,6 +125,7 @@ type DataSourceHandler struct {
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
 	userError              string
+	queryCanceler          SQLQueryCanceler
 }
 
 type QueryJson struct {
@@ -143,6 +166,7 @@ func NewQueryDataHandler(cfg *setting.Cfg, config DataPluginConfiguration, query
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
 		userError:              cfg.UserFacingDefaultError,
+		queryCanceler:          config.QueryCanceler,
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -273,11 +297,23 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	queryCtx := queryContext
+	if timeout := e.queryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(queryContext, timeout)
+		defer cancel()
+	}
+
+	rows, release, err := e.query(queryCtx, logger, interpolatedQuery)
 	if err != nil {
+		if timedOut(queryCtx, queryContext) {
+			errAppendDebug("db query error", ErrQueryTimeout.Errorf("query exceeded the timeout of %s", e.queryTimeout()), interpolatedQuery)
+			return
+		}
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
 		return
 	}
+	defer release()
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close rows", "err", err)
@@ -292,8 +328,18 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
-	if err != nil {
+	frame, err := frameFromRows(rows, e.maxRows(), e.dsInfo.JsonData.MaxResultBytes, sqlutil.ToConverters(stringConverters...)...)
+	switch {
+	case err != nil && timedOut(queryCtx, queryContext) && frame != nil && frame.Rows() > 0:
+		// the rows read before the timeout are returned as a partial result
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results are partial because the query exceeded the timeout of %s of the data source", e.queryTimeout()),
+		})
+	case err != nil && timedOut(queryCtx, queryContext):
+		errAppendDebug("convert frame from rows error", ErrQueryTimeout.Errorf("query exceeded the timeout of %s", e.queryTimeout()), interpolatedQuery)
+		return
+	case err != nil:
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
 	}
@@ -385,6 +431,62 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	ch <- queryResult
 }
 
+// query runs the query. If the data source has a query canceler, the query runs on a dedicated connection
+// and is canceled on the server when the context is canceled. The returned function must be called once
+// the rows are closed.
+func (e *DataSourceHandler) query(ctx context.Context, logger log.Logger, query string) (*sql.Rows, func(), error) {
+	if e.queryCanceler == nil {
+		rows, err := e.db.QueryContext(ctx, query)
+		return rows, func() {}, err
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeConn := func() {
+		if err := conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+			logger.Warn("Failed to close connection", "err", err)
+		}
+	}
+
+	connectionID, err := e.queryCanceler.ConnectionID(ctx, conn)
+	if err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// The context of the query is canceled, so the server is asked to cancel it with a new one.
+			cancelCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+			defer cancel()
+			if err := e.queryCanceler.CancelQuery(cancelCtx, e.db, connectionID); err != nil {
+				logger.Warn("Failed to cancel query", "connectionId", connectionID, "err", err)
+				return
+			}
+			logger.Debug("Cancelled query", "connectionId", connectionID)
+		}
+	}()
+	release := func() {
+		close(done)
+		<-stopped
+		closeConn()
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
 // Interpolate provides global macros/substitutions for all sql datasources.
 var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, timeInterval string, sql string) (string, error) {
 	minInterval, err := intervalv2.GetIntervalFrom(timeInterval, query.Interval.String(), query.Interval.Milliseconds(), time.Second*60)
@@ -1,6 +1,8 @@
 package sqleng
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net"
 	"testing"
@@ -9,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -426,3 +429,58 @@ func (t *testQueryResultTransformer) TransformQueryError(_ log.Logger, err error
 func (t *testQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return nil
 }
+
+func TestSQLEngineQueryCanceler(t *testing.T) {
+	newHandler := func(t *testing.T, canceler SQLQueryCanceler) *DataSourceHandler {
+		t.Helper()
+		db, err := sql.Open("sqlite3", ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		return &DataSourceHandler{db: db, queryCanceler: canceler, log: log.New("test")}
+	}
+
+	t.Run("does not cancel queries that complete", func(t *testing.T) {
+		canceler := &fakeQueryCanceler{cancelled: make(chan int64, 1)}
+		handler := newHandler(t, canceler)
+
+		rows, release, err := handler.query(context.Background(), handler.log, "SELECT 1")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		release()
+
+		require.Empty(t, canceler.cancelled)
+	})
+
+	t.Run("cancels the query on the server when the context is canceled", func(t *testing.T) {
+		canceler := &fakeQueryCanceler{cancelled: make(chan int64, 1)}
+		handler := newHandler(t, canceler)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, release, err := handler.query(ctx, handler.log,
+			"WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT x FROM c")
+		require.NoError(t, err)
+		cancel()
+
+		select {
+		case id := <-canceler.cancelled:
+			require.Equal(t, int64(42), id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("query was not cancelled")
+		}
+		_ = rows.Close()
+		release()
+	})
+}
+
+type fakeQueryCanceler struct {
+	cancelled chan int64
+}
+
+func (f *fakeQueryCanceler) ConnectionID(_ context.Context, _ *sql.Conn) (int64, error) {
+	return 42, nil
+}
+
+func (f *fakeQueryCanceler) CancelQuery(_ context.Context, _ *sql.DB, connectionID int64) error {
+	f.cancelled <- connectionID
+	return nil
+}
@@ -0,0 +1,327 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Metrics of the service graph generated by Tempo from the spans it receives.
+const (
+	serviceGraphTotalsMetric  = "traces_service_graph_request_total"
+	serviceGraphSecondsMetric = "traces_service_graph_request_server_seconds_sum"
+	serviceGraphFailedMetric  = "traces_service_graph_request_failed_total"
+)
+
+var serviceGraphMetrics = []string{serviceGraphTotalsMetric, serviceGraphSecondsMetric, serviceGraphFailedMetric}
+
+// serviceGraphStats are the statistics of a node or an edge of the service graph.
+type serviceGraphStats struct {
+	total   float64
+	seconds float64
+	failed  float64
+}
+
+func (s *serviceGraphStats) add(metric string, value float64) {
+	switch metric {
+	case serviceGraphTotalsMetric:
+		s.total += value
+	case serviceGraphSecondsMetric:
+		s.seconds += value
+	case serviceGraphFailedMetric:
+		s.failed += value
+	}
+}
+
+// averageResponseTime returns the average response time in milliseconds, or nil if there were no requests.
+func (s *serviceGraphStats) averageResponseTime() *float64 {
+	if s.total == 0 {
+		return nil
+	}
+	v := s.seconds / s.total * 1000
+	return &v
+}
+
+// requestRate returns the requests per second rounded to 2 decimals, or nil if there were no requests.
+func (s *serviceGraphStats) requestRate() *float64 {
+	if s.total == 0 {
+		return nil
+	}
+	v := math.Round(s.total*100) / 100
+	return &v
+}
+
+func (s *serviceGraphStats) failedRatio() float64 {
+	if s.total == 0 {
+		return 0
+	}
+	return math.Min(s.failed, s.total) / s.total
+}
+
+type serviceGraphNode struct {
+	serviceGraphStats
+	name      string
+	namespace string
+}
+
+type serviceGraphEdge struct {
+	serviceGraphStats
+	source          string
+	sourceName      string
+	sourceNamespace string
+	target          string
+	targetName      string
+	targetNamespace string
+}
+
+type serviceGraph struct {
+	nodes map[string]*serviceGraphNode
+	edges map[string]*serviceGraphEdge
+}
+
+// getServiceGraph builds the service graph from the span metrics of the Prometheus data source configured
+// for the service map of the Tempo data source.
+func (s *Service) getServiceGraph(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Getting service graph", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.getServiceGraph", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model := &dataquery.TempoQuery{}
+	err := json.Unmarshal(query.JSON, model)
+	if err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	if dsInfo.ServiceMapDatasourceUID == "" {
+		result.Error = fmt.Errorf("no Prometheus data source is configured for the service graph")
+		return result, nil
+	}
+	if s.dataSources == nil {
+		return result, fmt.Errorf("querying other data sources is not available")
+	}
+
+	includeNamespace := model.ServiceMapIncludeNamespace != nil && *model.ServiceMapIncludeNamespace
+	filters := ""
+	if model.ServiceMapQuery != nil {
+		filters = *model.ServiceMapQuery
+	}
+
+	queries := make([]backend.DataQuery, 0, len(serviceGraphMetrics))
+	for _, metric := range serviceGraphMetrics {
+		promQuery, err := json.Marshal(map[string]any{
+			"refId":   metric,
+			"expr":    serviceGraphExpr(metric, filters, includeNamespace),
+			"instant": true,
+			"range":   false,
+		})
+		if err != nil {
+			return result, err
+		}
+		queries = append(queries, backend.DataQuery{
+			RefID:         metric,
+			MaxDataPoints: query.MaxDataPoints,
+			Interval:      query.Interval,
+			TimeRange:     query.TimeRange,
+			JSON:          promQuery,
+		})
+	}
+
+	resp, err := s.dataSources.QueryDataSource(ctx, pCtx, dsInfo.ServiceMapDatasourceUID, queries)
+	if err != nil {
+		ctxLogger.Error("Failed to query the service graph metrics", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		result.Error = fmt.Errorf("failed to query the service graph metrics: %w", err)
+		return result, nil
+	}
+
+	graph := &serviceGraph{
+		nodes: make(map[string]*serviceGraphNode),
+		edges: make(map[string]*serviceGraphEdge),
+	}
+	for _, metric := range serviceGraphMetrics {
+		res, ok := resp.Responses[metric]
+		if !ok {
+			continue
+		}
+		if res.Error != nil {
+			span.RecordError(res.Error)
+			span.SetStatus(codes.Error, res.Error.Error())
+			result.Error = fmt.Errorf("failed to query %s: %w", metric, res.Error)
+			return result, nil
+		}
+		graph.collect(metric, res.Frames)
+	}
+
+	nodes, edges := graph.frames()
+	nodes.RefID = query.RefID
+	edges.RefID = query.RefID
+	result.Frames = data.Frames{nodes, edges}
+	ctxLogger.Debug("Successfully got service graph", "nodes", len(graph.nodes), "edges", len(graph.edges), "function", logEntrypoint())
+	return result, nil
+}
+
+func serviceGraphExpr(metric string, filters string, includeNamespace bool) string {
+	sumBy := "client, server"
+	if includeNamespace {
+		sumBy += ", client_service_namespace, server_service_namespace"
+	}
+	return fmt.Sprintf("sum by (%s) (rate(%s%s[$__range]))", sumBy, metric, filters)
+}
+
+// collect adds the values of a metric to the edges between the clients and servers of its series. The
+// statistics of an edge are also attributed to its server, so that the statistics of a node are about
+// the requests it handled, not the ones it made.
+func (g *serviceGraph) collect(metric string, frames data.Frames) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, ok := lastValue(field)
+			if !ok {
+				continue
+			}
+
+			client, server := field.Labels["client"], field.Labels["server"]
+			clientNamespace, serverNamespace := field.Labels["client_service_namespace"], field.Labels["server_service_namespace"]
+			clientID, serverID := serviceGraphNodeID(clientNamespace, client), serviceGraphNodeID(serverNamespace, server)
+
+			edgeID := clientID + "_" + serverID
+			edge, ok := g.edges[edgeID]
+			if !ok {
+				edge = &serviceGraphEdge{
+					source:          clientID,
+					sourceName:      client,
+					sourceNamespace: clientNamespace,
+					target:          serverID,
+					targetName:      server,
+					targetNamespace: serverNamespace,
+				}
+				g.edges[edgeID] = edge
+			}
+			edge.add(metric, value)
+
+			g.node(serverID, server, serverNamespace).add(metric, value)
+			g.node(clientID, client, clientNamespace)
+		}
+	}
+}
+
+func (g *serviceGraph) node(id, name, namespace string) *serviceGraphNode {
+	node, ok := g.nodes[id]
+	if !ok {
+		node = &serviceGraphNode{name: name, namespace: namespace}
+		g.nodes[id] = node
+	}
+	return node
+}
+
+func (g *serviceGraph) frames() (*data.Frame, *data.Frame) {
+	nodeIDs := make([]string, 0, len(g.nodes))
+	for id := range g.nodes {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+
+	ids := make([]string, 0, len(nodeIDs))
+	titles := make([]string, 0, len(nodeIDs))
+	subTitles := make([]string, 0, len(nodeIDs))
+	mainStats := make([]*float64, 0, len(nodeIDs))
+	secondaryStats := make([]*float64, 0, len(nodeIDs))
+	success := make([]float64, 0, len(nodeIDs))
+	failed := make([]float64, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		node := g.nodes[id]
+		ids = append(ids, id)
+		titles = append(titles, node.name)
+		subTitles = append(subTitles, node.namespace)
+		mainStats = append(mainStats, node.averageResponseTime())
+		secondaryStats = append(secondaryStats, node.requestRate())
+		success = append(success, 1-node.failedRatio())
+		failed = append(failed, node.failedRatio())
+	}
+
+	nodes := data.NewFrame("Nodes",
+		data.NewField("id", nil, ids),
+		data.NewField("title", nil, titles).SetConfig(&data.FieldConfig{DisplayName: "Service name"}),
+		data.NewField("subtitle", nil, subTitles).SetConfig(&data.FieldConfig{DisplayName: "Service namespace"}),
+		data.NewField("mainstat", nil, mainStats).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+		data.NewField("secondarystat", nil, secondaryStats).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+		data.NewField("arc__success", nil, success).SetConfig(&data.FieldConfig{DisplayName: "Success", Color: fixedColor("green")}),
+		data.NewField("arc__failed", nil, failed).SetConfig(&data.FieldConfig{DisplayName: "Failed", Color: fixedColor("red")}),
+	)
+	nodes.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	edgeIDs := make([]string, 0, len(g.edges))
+	for id := range g.edges {
+		edgeIDs = append(edgeIDs, id)
+	}
+	sort.Strings(edgeIDs)
+
+	edges := data.NewFrame("Edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("sourceName", nil, []string{}),
+		data.NewField("sourceNamespace", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+		data.NewField("targetName", nil, []string{}),
+		data.NewField("targetNamespace", nil, []string{}),
+		data.NewField("mainstat", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+		data.NewField("secondarystat", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+	)
+	edges.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+	for _, id := range edgeIDs {
+		edge := g.edges[id]
+		edges.AppendRow(id, edge.source, edge.sourceName, edge.sourceNamespace, edge.target, edge.targetName,
+			edge.targetNamespace, edge.averageResponseTime(), edge.requestRate())
+	}
+
+	return nodes, edges
+}
+
+func serviceGraphNodeID(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// lastValue returns the last non-null value of a numeric field.
+func lastValue(field *data.Field) (float64, bool) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		v, err := field.NullableFloatAt(i)
+		if err != nil || v == nil || math.IsNaN(*v) {
+			continue
+		}
+		return *v, true
+	}
+	return 0, false
+}
+
+func fixedColor(color string) map[string]any {
+	return map[string]any{"mode": "fixed", "fixedColor": color}
+}
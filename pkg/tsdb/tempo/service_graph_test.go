@@ -0,0 +1,178 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceGraph(t *testing.T) {
+	t.Run("joins the span metrics into nodes and edges", func(t *testing.T) {
+		querier := &fakeDataSourceQuerier{
+			values: map[string][]serviceGraphSeries{
+				serviceGraphTotalsMetric: {
+					{client: "app", server: "db", value: 10},
+					{client: "user", server: "app", value: 4},
+				},
+				serviceGraphSecondsMetric: {
+					{client: "app", server: "db", value: 2},
+					{client: "user", server: "app", value: 2},
+				},
+				serviceGraphFailedMetric: {
+					{client: "app", server: "db", value: 5},
+				},
+			},
+		}
+		service := newTestService(querier, "prom")
+
+		res, err := service.query(context.Background(), backend.PluginContext{OrgID: 1}, backend.DataQuery{
+			RefID:     "A",
+			QueryType: "serviceMap",
+			JSON:      []byte(`{"serviceMapQuery": "{client=\"app\"}"}`),
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+
+		assert.Equal(t, "prom", querier.uid)
+		assert.Equal(t, int64(1), querier.orgID)
+		require.Len(t, querier.queries, 3)
+		model := map[string]any{}
+		require.NoError(t, json.Unmarshal(querier.queries[0].JSON, &model))
+		assert.Equal(t, `sum by (client, server) (rate(traces_service_graph_request_total{client="app"}[$__range]))`, model["expr"])
+		assert.Equal(t, true, model["instant"])
+
+		nodes, edges := res.Frames[0], res.Frames[1]
+		assert.Equal(t, "A", nodes.RefID)
+		assert.Equal(t, data.VisTypeNodeGraph, string(nodes.Meta.PreferredVisualization))
+		require.Equal(t, 3, nodes.Rows())
+		assert.Equal(t, []any{"app", "app", "", pointer(500.0), pointer(4.0), 1.0, 0.0}, nodes.RowCopy(0))
+		assert.Equal(t, []any{"db", "db", "", pointer(200.0), pointer(10.0), 0.5, 0.5}, nodes.RowCopy(1))
+		// The client of the graph did not handle any requests.
+		assert.Equal(t, []any{"user", "user", "", (*float64)(nil), (*float64)(nil), 1.0, 0.0}, nodes.RowCopy(2))
+
+		require.Equal(t, 2, edges.Rows())
+		assert.Equal(t, []any{"app_db", "app", "app", "", "db", "db", "", pointer(200.0), pointer(10.0)}, edges.RowCopy(0))
+		assert.Equal(t, []any{"user_app", "user", "user", "", "app", "app", "", pointer(500.0), pointer(4.0)}, edges.RowCopy(1))
+	})
+
+	t.Run("identifies services by namespace and name", func(t *testing.T) {
+		querier := &fakeDataSourceQuerier{
+			values: map[string][]serviceGraphSeries{
+				serviceGraphTotalsMetric: {
+					{client: "app", clientNamespace: "prod", server: "db", serverNamespace: "infra", value: 1},
+				},
+			},
+		}
+		service := newTestService(querier, "prom")
+
+		res, err := service.query(context.Background(), backend.PluginContext{OrgID: 1}, backend.DataQuery{
+			RefID:     "A",
+			QueryType: "serviceMap",
+			JSON:      []byte(`{"serviceMapIncludeNamespace": true}`),
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		model := map[string]any{}
+		require.NoError(t, json.Unmarshal(querier.queries[0].JSON, &model))
+		assert.Equal(t, `sum by (client, server, client_service_namespace, server_service_namespace) (rate(traces_service_graph_request_total[$__range]))`, model["expr"])
+
+		edges := res.Frames[1]
+		require.Equal(t, 1, edges.Rows())
+		assert.Equal(t, []any{"prod/app_infra/db", "prod/app", "app", "prod", "infra/db", "db", "infra", pointer(0.0), pointer(1.0)}, edges.RowCopy(0))
+	})
+
+	t.Run("returns an error if no Prometheus data source is configured", func(t *testing.T) {
+		service := newTestService(&fakeDataSourceQuerier{}, "")
+
+		res, err := service.query(context.Background(), backend.PluginContext{OrgID: 1}, backend.DataQuery{
+			RefID:     "A",
+			QueryType: "serviceMap",
+			JSON:      []byte(`{}`),
+		})
+		require.NoError(t, err)
+		require.Error(t, res.Error)
+	})
+
+	t.Run("returns the errors of the Prometheus queries", func(t *testing.T) {
+		service := newTestService(&fakeDataSourceQuerier{err: assert.AnError}, "prom")
+
+		res, err := service.query(context.Background(), backend.PluginContext{OrgID: 1}, backend.DataQuery{
+			RefID:     "A",
+			QueryType: "serviceMap",
+			JSON:      []byte(`{}`),
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, res.Error, assert.AnError)
+	})
+}
+
+type serviceGraphSeries struct {
+	client, clientNamespace, server, serverNamespace string
+	value                                            float64
+}
+
+type fakeDataSourceQuerier struct {
+	values  map[string][]serviceGraphSeries
+	err     error
+	uid     string
+	orgID   int64
+	queries []backend.DataQuery
+}
+
+func (f *fakeDataSourceQuerier) QueryDataSource(_ context.Context, pCtx backend.PluginContext, uid string, queries []backend.DataQuery) (*backend.QueryDataResponse, error) {
+	f.uid, f.orgID, f.queries = uid, pCtx.OrgID, queries
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := backend.NewQueryDataResponse()
+	for _, q := range queries {
+		frames := data.Frames{}
+		for _, series := range f.values[q.RefID] {
+			labels := data.Labels{"client": series.client, "server": series.server}
+			if series.clientNamespace != "" {
+				labels["client_service_namespace"] = series.clientNamespace
+				labels["server_service_namespace"] = series.serverNamespace
+			}
+			frames = append(frames, data.NewFrame("",
+				data.NewField("Time", nil, []time.Time{time.Unix(0, 0)}),
+				data.NewField("Value", labels, []float64{series.value}),
+			))
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+	return resp, nil
+}
+
+type fakeInstanceManager struct {
+	instance *Datasource
+}
+
+func (f *fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.instance, nil
+}
+
+func (f *fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newTestService(querier DataSourceQuerier, serviceMapUID string) *Service {
+	return &Service{
+		logger:      backend.NewLoggerWith("logger", "tempo-test"),
+		im:          &fakeInstanceManager{instance: &Datasource{ServiceMapDatasourceUID: serviceMapUID}},
+		dataSources: querier,
+	}
+}
+
+func pointer(v float64) *float64 {
+	return &v
+}
@@ -2,6 +2,7 @@ package tempo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -19,6 +20,14 @@ import (
 type Service struct {
 	im     instancemgmt.InstanceManager
 	logger log.Logger
+	// dataSources queries the data sources the Tempo data source is configured to use, such as the
+	// Prometheus data source with the span metrics of the service graph.
+	dataSources DataSourceQuerier
+}
+
+// DataSourceQuerier queries other data sources of the org on behalf of the Tempo data source.
+type DataSourceQuerier interface {
+	QueryDataSource(ctx context.Context, pCtx backend.PluginContext, uid string, queries []backend.DataQuery) (*backend.QueryDataResponse, error)
 }
 
 // Return the file, line, and (full-path) function name of the caller
@@ -45,10 +54,24 @@ func ProvideService(httpClientProvider *httpclient.Provider) *Service {
 	}
 }
 
+// SetDataSourceQuerier sets the querier used to query the data sources the Tempo data source is configured to use.
+// It is set after the core plugins are created, since querying data sources depends on them.
+func (s *Service) SetDataSourceQuerier(q DataSourceQuerier) {
+	s.dataSources = q
+}
+
 type Datasource struct {
 	HTTPClient      *http.Client
 	StreamingClient tempopb.StreamingQuerierClient
 	URL             string
+	// ServiceMapDatasourceUID is the UID of the Prometheus data source with the span metrics of the service graph.
+	ServiceMapDatasourceUID string
+}
+
+type jsonData struct {
+	ServiceMap struct {
+		DatasourceUID string `json:"datasourceUid"`
+	} `json:"serviceMap"`
 }
 
 func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -72,10 +95,19 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 
+		data := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &data); err != nil {
+				ctxLogger.Error("Failed to parse JSON data", "error", err, "function", logEntrypoint())
+				return nil, err
+			}
+		}
+
 		model := &Datasource{
-			HTTPClient:      client,
-			StreamingClient: streamingClient,
-			URL:             settings.URL,
+			HTTPClient:              client,
+			StreamingClient:         streamingClient,
+			URL:                     settings.URL,
+			ServiceMapDatasourceUID: data.ServiceMap.DatasourceUID,
 		}
 		return model, nil
 	}
@@ -109,8 +141,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
-	if query.QueryType == string(dataquery.TempoQueryTypeTraceId) {
+	switch query.QueryType {
+	case string(dataquery.TempoQueryTypeTraceId):
 		return s.getTrace(ctx, pCtx, query)
+	case string(dataquery.TempoQueryTypeServiceMap):
+		return s.getServiceGraph(ctx, pCtx, query)
 	}
 	return nil, fmt.Errorf("unsupported query type: '%s' for query with refID '%s'", query.QueryType, query.RefID)
 }
@@ -0,0 +1,129 @@
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// codePattern is the format of the codes: dot-separated lowercase
+// segments, the first one being the domain of the error.
+var codePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+(-[a-z0-9]+)*)+$`)
+
+var dashes = regexp.MustCompile(`-+`)
+
+type registeredCode struct {
+	code string
+	err  error
+}
+
+var codeRegistry = struct {
+	sync.RWMutex
+	codes []registeredCode
+}{}
+
+// RegisterCode registers the code returned to API clients for the
+// errors that match any of errs using [errors.Is]. Codes are part of
+// the public API and must not be changed once they are released.
+//
+//	func init() {
+//		errutil.RegisterCode("dashboard.version-conflict", ErrDashboardVersionMismatch)
+//	}
+//
+// RegisterCode panics when the code is malformed or when one of the
+// errors already has a code, since both are programming errors.
+func RegisterCode(code string, errs ...error) {
+	if !codePattern.MatchString(code) {
+		panic(fmt.Sprintf("errutil: malformed error code %q", code))
+	}
+
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+	for _, err := range errs {
+		for _, registered := range codeRegistry.codes {
+			if errors.Is(err, registered.err) {
+				panic(fmt.Sprintf("errutil: error %q is already registered with the code %q", err, registered.code))
+			}
+		}
+		codeRegistry.codes = append(codeRegistry.codes, registeredCode{code: code, err: err})
+	}
+}
+
+// Code returns the stable code identifying err in API responses.
+//
+// The code is the one registered with [RegisterCode] for err, the
+// message ID of an [Error] converted to lowercase words separated by
+// dashes, or a code derived from status if err is not identifiable.
+func Code(status int, err error) string {
+	if err != nil {
+		codeRegistry.RLock()
+		defer codeRegistry.RUnlock()
+		for _, registered := range codeRegistry.codes {
+			if errors.Is(err, registered.err) {
+				return registered.code
+			}
+		}
+
+		var gfErr Error
+		if errors.As(err, &gfErr) {
+			if code := messageIDCode(gfErr.MessageID); code != "" {
+				return code
+			}
+		}
+	}
+	return StatusCode(status)
+}
+
+// StatusCode returns the code of the error responses with the HTTP
+// status that do not identify the error further, such as
+// http.not-found.
+func StatusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		text = strconv.Itoa(status)
+	}
+	return "http." + kebabCase(text)
+}
+
+// messageIDCode converts a message ID such as dashboards.notFound to
+// the code dashboards.not-found.
+func messageIDCode(messageID string) string {
+	segments := strings.Split(messageID, ".")
+	for i, segment := range segments {
+		segments[i] = kebabCase(segment)
+	}
+	code := strings.Join(segments, ".")
+	if !codePattern.MatchString(code) {
+		return ""
+	}
+	return code
+}
+
+// kebabCase converts camel case, snake case and space-separated words
+// to lowercase words separated by dashes.
+func kebabCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// A word starts on an uppercase letter following a lowercase
+			// one, or on the last uppercase letter of an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(dashes.ReplaceAllString(b.String(), "-"), "-")
+}
@@ -0,0 +1,62 @@
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	resetCodeRegistry(t)
+	errRegistered := errors.New("registered")
+	baseRegistered := BadRequest("test.registeredBase")
+	RegisterCode("test.registered", errRegistered)
+	RegisterCode("test.registered-base", baseRegistered)
+
+	tests := []struct {
+		desc     string
+		status   int
+		err      error
+		expected string
+	}{
+		{desc: "registered error", status: http.StatusBadRequest, err: errRegistered, expected: "test.registered"},
+		{desc: "wrapped registered error", status: http.StatusBadRequest, err: fmt.Errorf("failed: %w", errRegistered), expected: "test.registered"},
+		{desc: "registered base", status: http.StatusBadRequest, err: baseRegistered.Errorf("failed"), expected: "test.registered-base"},
+		{desc: "camel case message ID", status: http.StatusNotFound, err: NotFound("dashboards.notFound").Errorf("failed"), expected: "dashboards.not-found"},
+		{desc: "snake case message ID", status: http.StatusBadRequest, err: BadRequest("oauth.role_attribute_path_not_set").Errorf("failed"), expected: "oauth.role-attribute-path-not-set"},
+		{desc: "acronym in message ID", status: http.StatusInternalServerError, err: Internal("core.MalformedJSONError").Errorf("failed"), expected: "core.malformed-json-error"},
+		{desc: "malformed message ID", status: http.StatusInternalServerError, err: Internal("malformed").Errorf("failed"), expected: "http.internal-server-error"},
+		{desc: "unknown error", status: http.StatusPreconditionFailed, err: errors.New("unknown"), expected: "http.precondition-failed"},
+		{desc: "no error", status: http.StatusNotFound, expected: "http.not-found"},
+		{desc: "non-standard status", status: 499, expected: "http.499"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Code(tt.status, tt.err))
+		})
+	}
+
+	t.Run("public errors should have the code of the error", func(t *testing.T) {
+		assert.Equal(t, "test.registered-base", baseRegistered.Errorf("failed").Public().Code)
+	})
+}
+
+func TestRegisterCode(t *testing.T) {
+	resetCodeRegistry(t)
+	errRegistered := errors.New("registered twice")
+	RegisterCode("test.registered-twice", errRegistered)
+
+	assert.Panics(t, func() { RegisterCode("test.registered-again", errRegistered) })
+	assert.Panics(t, func() { RegisterCode("Malformed Code", errors.New("malformed")) })
+	assert.Panics(t, func() { RegisterCode("nodomain", errors.New("no domain")) })
+}
+
+// resetCodeRegistry restores the registered codes once the test is done.
+func resetCodeRegistry(t *testing.T) {
+	codes := codeRegistry.codes
+	t.Cleanup(func() { codeRegistry.codes = codes })
+}
@@ -25,5 +25,5 @@ func TestWrite(t *testing.T) {
 	handler(recorder, req)
 
 	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
-	assert.JSONEq(t, `{"message": "Timeout", "messageId": "test.thisIsExpected", "statusCode": 504}`, recorder.Body.String())
+	assert.JSONEq(t, `{"message": "Timeout", "messageId": "test.thisIsExpected", "code": "test.this-is-expected", "statusCode": 504}`, recorder.Body.String())
 }
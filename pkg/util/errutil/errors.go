@@ -404,6 +404,7 @@ func (e Error) Is(other error) bool {
 type PublicError struct {
 	StatusCode int            `json:"statusCode"`
 	MessageID  string         `json:"messageId"`
+	Code       string         `json:"code"`
 	Message    string         `json:"message,omitempty"`
 	Extra      map[string]any `json:"extra,omitempty"`
 }
@@ -421,9 +422,11 @@ func (e Error) Public() PublicError {
 		}
 	}
 
+	status := e.Reason.Status().HTTPStatus()
 	return PublicError{
-		StatusCode: e.Reason.Status().HTTPStatus(),
+		StatusCode: status,
 		MessageID:  e.MessageID,
+		Code:       Code(status, e),
 		Message:    message,
 		Extra:      e.PublicPayload,
 	}
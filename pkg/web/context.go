@@ -15,6 +15,7 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,6 +27,7 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 )
@@ -68,30 +70,42 @@ func (ctx *Context) RemoteAddr() string {
 	return RemoteAddr(ctx.Req)
 }
 
-func RemoteAddr(req *http.Request) string {
-	addr := req.Header.Get("X-Real-IP")
+type clientIPKey struct{}
+
+// defaultClientIPResolver resolves the client IP of the requests that did not
+// go through the client IP middleware of the HTTP server. It trusts the
+// X-Real-IP and X-Forwarded-For headers of all the requests.
+var defaultClientIPResolver = network.NewClientIPResolver(
+	[]*net.IPNet{
+		{IP: net.IPv4zero, Mask: net.CIDRMask(0, 8*net.IPv4len)},
+		{IP: net.IPv6zero, Mask: net.CIDRMask(0, 8*net.IPv6len)},
+	},
+	[]string{"X-Real-IP", "X-Forwarded-For"},
+)
+
+// WithClientIP returns a copy of ctx with the IP address of the client of its
+// request, as resolved from the headers of the trusted proxies. It is returned
+// by RemoteAddr.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
 
-	if len(addr) == 0 {
-		// X-Forwarded-For may contain multiple IP addresses, separated by
-		// commas.
-		addr = strings.TrimSpace(strings.Split(req.Header.Get("X-Forwarded-For"), ",")[0])
+// RemoteAddr returns the IP address of the client of a request, as resolved
+// by the client IP middleware of the HTTP server.
+func RemoteAddr(req *http.Request) string {
+	if ip, ok := req.Context().Value(clientIPKey{}).(string); ok {
+		return ip
 	}
 
 	// parse user inputs from headers to prevent log forgery
-	if len(addr) > 0 {
-		if parsedIP := net.ParseIP(addr); parsedIP == nil {
-			// if parsedIP is nil we clean addr and populate with RemoteAddr below
-			addr = ""
-		}
+	if ip := defaultClientIPResolver.ForwardedClientIP(req); ip != nil {
+		return ip.String()
 	}
 
-	if len(addr) == 0 {
-		addr = req.RemoteAddr
-		if i := strings.LastIndex(addr, ":"); i > -1 {
-			addr = addr[:i]
-		}
+	addr := req.RemoteAddr
+	if i := strings.LastIndex(addr, ":"); i > -1 {
+		addr = addr[:i]
 	}
-
 	return addr
 }
 